@@ -48,6 +48,7 @@ type Context struct {
 }
 
 var ActionTypes = []string{
+	"alertmanager",
 	"gnmi",
 	"http",
 	"script",
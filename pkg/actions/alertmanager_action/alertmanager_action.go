@@ -0,0 +1,190 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package alertmanager_action
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"text/template"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/actions"
+	"github.com/openconfig/gnmic/pkg/alerts"
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/api/utils"
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/gtemplate"
+)
+
+const (
+	loggingPrefix         = "[alertmanager_action] "
+	actionType            = "alertmanager"
+	defaultTimeout        = 5 * time.Second
+	defaultResolveTimeout = 5 * time.Minute
+)
+
+func init() {
+	actions.Register(actionType, func() actions.Action {
+		return &alertmanagerAction{
+			logger: log.New(io.Discard, "", 0),
+			store:  alerts.Default(),
+		}
+	})
+}
+
+// alertmanagerAction raises an alert built from the triggering event,
+// and pushes it to the configured Alertmanagers.
+type alertmanagerAction struct {
+	Name string `mapstructure:"name,omitempty"`
+	// Alertmanager base URLs
+	URLs      []string `mapstructure:"urls,omitempty"`
+	AlertName string   `mapstructure:"alertname,omitempty"`
+	// event tags added as labels and grouping the alerts,
+	// all the event tags are added if empty.
+	GroupBy []string `mapstructure:"group-by,omitempty"`
+	// static labels
+	Labels map[string]string `mapstructure:"labels,omitempty"`
+	// annotations templates
+	Annotations  map[string]string `mapstructure:"annotations,omitempty"`
+	GeneratorURL string            `mapstructure:"generator-url,omitempty"`
+	// the alert is resolved if it is not raised again within this duration.
+	ResolveTimeout time.Duration     `mapstructure:"resolve-timeout,omitempty"`
+	Headers        map[string]string `mapstructure:"headers,omitempty"`
+	Timeout        time.Duration     `mapstructure:"timeout,omitempty"`
+	Debug          bool              `mapstructure:"debug,omitempty"`
+
+	groupBy     []string
+	annotations map[string]*template.Template
+	client      *http.Client
+	store       *alerts.Store
+	logger      *log.Logger
+}
+
+func (a *alertmanagerAction) Init(cfg map[string]interface{}, opts ...actions.Option) error {
+	err := actions.DecodeConfig(cfg, a)
+	if err != nil {
+		return err
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	if a.Name == "" {
+		return fmt.Errorf("action type %q missing name field", actionType)
+	}
+	if a.AlertName == "" {
+		a.AlertName = a.Name
+	}
+	if a.ResolveTimeout <= 0 {
+		a.ResolveTimeout = defaultResolveTimeout
+	}
+	if a.Timeout <= 0 {
+		a.Timeout = defaultTimeout
+	}
+	a.groupBy = make([]string, 0, len(a.GroupBy))
+	for _, k := range a.GroupBy {
+		a.groupBy = append(a.groupBy, alerts.SanitizeLabelName(k))
+	}
+	a.annotations = make(map[string]*template.Template, len(a.Annotations))
+	for k, v := range a.Annotations {
+		a.annotations[k], err = gtemplate.CreateTemplate(fmt.Sprintf("%s-%s", a.Name, k), v)
+		if err != nil {
+			return fmt.Errorf("annotation %q: %v", k, err)
+		}
+	}
+	a.client = &http.Client{Timeout: a.Timeout}
+	return nil
+}
+
+func (a *alertmanagerAction) Run(ctx context.Context, aCtx *actions.Context) (interface{}, error) {
+	e, ok := aCtx.Input.(*formatters.EventMsg)
+	if !ok {
+		return nil, fmt.Errorf("unexpected input type %T", aCtx.Input)
+	}
+	alert, err := a.alert(e, aCtx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	alert = a.store.Add(alert)
+	a.logger.Printf("alert %s raised: %v", alert.Fingerprint(), alert.Labels)
+
+	errs := make([]error, 0, len(a.URLs))
+	for _, url := range a.URLs {
+		err = alerts.Push(ctx, a.client, url, a.Headers, []*alerts.Alert{alert})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err = errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return alert, nil
+}
+
+// alert builds the alert raised by the event e.
+func (a *alertmanagerAction) alert(e *formatters.EventMsg, aCtx *actions.Context, now time.Time) (*alerts.Alert, error) {
+	alert := &alerts.Alert{
+		Labels:       make(map[string]string, len(e.Tags)+len(a.Labels)+1),
+		Annotations:  make(map[string]string, len(a.annotations)),
+		StartsAt:     now,
+		EndsAt:       now.Add(a.ResolveTimeout),
+		GeneratorURL: a.GeneratorURL,
+		Receiver:     a.Name,
+		GroupBy:      a.groupBy,
+	}
+	if len(a.GroupBy) == 0 {
+		for k, v := range e.Tags {
+			alert.Labels[alerts.SanitizeLabelName(k)] = v
+		}
+	} else {
+		for i, k := range a.GroupBy {
+			if v, ok := e.Tags[k]; ok {
+				alert.Labels[a.groupBy[i]] = v
+			}
+		}
+	}
+	for k, v := range a.Labels {
+		alert.Labels[k] = v
+	}
+	alert.Labels[alerts.AlertNameLabel] = a.AlertName
+
+	in := &actions.Context{
+		Input:   aCtx.Input,
+		Env:     aCtx.Env,
+		Vars:    aCtx.Vars,
+		Targets: aCtx.Targets,
+	}
+	b := new(bytes.Buffer)
+	for k, tpl := range a.annotations {
+		b.Reset()
+		err := tpl.Execute(b, in)
+		if err != nil {
+			return nil, fmt.Errorf("annotation %q: %v", k, err)
+		}
+		alert.Annotations[k] = b.String()
+	}
+	return alert, nil
+}
+
+func (a *alertmanagerAction) NName() string { return a.Name }
+
+func (a *alertmanagerAction) WithTargets(map[string]*types.TargetConfig) {}
+
+func (a *alertmanagerAction) WithLogger(logger *log.Logger) {
+	if a.Debug && logger != nil {
+		a.logger = log.New(logger.Writer(), loggingPrefix, logger.Flags())
+	} else if a.Debug {
+		a.logger = log.New(os.Stderr, loggingPrefix, utils.DefaultLoggingFlags)
+	}
+}
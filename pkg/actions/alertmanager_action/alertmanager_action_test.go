@@ -0,0 +1,89 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package alertmanager_action
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/openconfig/gnmic/pkg/actions"
+	"github.com/openconfig/gnmic/pkg/alerts"
+	"github.com/openconfig/gnmic/pkg/formatters"
+)
+
+func TestAlertmanagerAction(t *testing.T) {
+	var received []*alerts.Alert
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer srv.Close()
+
+	a := actions.Actions[actionType]().(*alertmanagerAction)
+	a.store = alerts.NewStore()
+	err := a.Init(map[string]interface{}{
+		"name":     "high_cpu",
+		"urls":     []string{srv.URL},
+		"group-by": []string{"source", "subscription-name"},
+		"labels":   map[string]string{"severity": "major"},
+		"annotations": map[string]string{
+			"summary": `CPU at {{ index .Input.Values "cpu" }}% on {{ index .Input.Tags "source" }}`,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := &formatters.EventMsg{
+		Tags: map[string]string{
+			"source":            "r1",
+			"subscription-name": "sys",
+			"cpu_id":            "0",
+		},
+		Values: map[string]interface{}{"cpu": 95},
+	}
+	_, err = a.Run(context.Background(), &actions.Context{Input: e})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantLabels := map[string]string{
+		"alertname":         "high_cpu",
+		"severity":          "major",
+		"source":            "r1",
+		"subscription_name": "sys",
+	}
+	if len(received) != 1 {
+		t.Fatalf("received %d alerts, want 1", len(received))
+	}
+	if !reflect.DeepEqual(received[0].Labels, wantLabels) {
+		t.Errorf("unexpected labels: %v", received[0].Labels)
+	}
+	if received[0].Annotations["summary"] != "CPU at 95% on r1" {
+		t.Errorf("unexpected annotations: %v", received[0].Annotations)
+	}
+	as := a.store.Active()
+	if len(as) != 1 || as[0].Receiver != "high_cpu" {
+		t.Fatalf("alert not stored: %v", as)
+	}
+	wantGroup := map[string]string{
+		"alertname":         "high_cpu",
+		"source":            "r1",
+		"subscription_name": "sys",
+	}
+	if gl := as[0].GroupLabels(); !reflect.DeepEqual(gl, wantGroup) {
+		t.Errorf("unexpected group labels: %v", gl)
+	}
+
+	_, err = a.Run(context.Background(), &actions.Context{Input: "target1"})
+	if err == nil {
+		t.Errorf("expected an error for a non event input")
+	}
+}
@@ -9,6 +9,7 @@
 package all
 
 import (
+	_ "github.com/openconfig/gnmic/pkg/actions/alertmanager_action"
 	_ "github.com/openconfig/gnmic/pkg/actions/gnmi_action"
 	_ "github.com/openconfig/gnmic/pkg/actions/http_action"
 	_ "github.com/openconfig/gnmic/pkg/actions/script_action"
@@ -0,0 +1,193 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package alerts holds the alerts raised by the triggers
+// in the Prometheus Alertmanager API v2 format.
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AlertNameLabel is the label holding the alert name.
+const AlertNameLabel = "alertname"
+
+// resolved alerts are kept this long before being purged.
+const resolvedRetention = time.Hour
+
+// Alert is an alert in the Alertmanager API v2 format.
+type Alert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	StartsAt     time.Time         `json:"startsAt,omitempty"`
+	EndsAt       time.Time         `json:"endsAt,omitempty"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+
+	// name of the action that raised the alert.
+	Receiver string `json:"-"`
+	// names of the labels grouping the alert with others of the same receiver.
+	GroupBy   []string  `json:"-"`
+	UpdatedAt time.Time `json:"-"`
+}
+
+// Fingerprint returns a hash of the alert labels, identifying the alert.
+func (a *Alert) Fingerprint() string {
+	names := make([]string, 0, len(a.Labels))
+	for k := range a.Labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	h := fnv.New64a()
+	for _, k := range names {
+		h.Write([]byte(k))
+		h.Write([]byte{0xff})
+		h.Write([]byte(a.Labels[k]))
+		h.Write([]byte{0xff})
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// Resolved returns true if the alert ended before t.
+func (a *Alert) Resolved(t time.Time) bool {
+	return !a.EndsAt.IsZero() && !t.Before(a.EndsAt)
+}
+
+// GroupLabels returns the alert name and grouping labels.
+func (a *Alert) GroupLabels() map[string]string {
+	ls := map[string]string{AlertNameLabel: a.Labels[AlertNameLabel]}
+	for _, k := range a.GroupBy {
+		if v, ok := a.Labels[k]; ok {
+			ls[k] = v
+		}
+	}
+	return ls
+}
+
+// SanitizeLabelName replaces the characters not allowed in a label name with '_'.
+func SanitizeLabelName(s string) string {
+	var sb strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			r = '_'
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// Store holds the alerts by fingerprint.
+type Store struct {
+	m      sync.RWMutex
+	alerts map[string]*Alert
+	now    func() time.Time
+}
+
+func NewStore() *Store {
+	return &Store{
+		alerts: make(map[string]*Alert),
+		now:    time.Now,
+	}
+}
+
+var defaultStore = NewStore()
+
+// Default returns the store of the alerts raised by the triggers.
+func Default() *Store {
+	return defaultStore
+}
+
+// Add records the alert, it keeps the start time of
+// the unresolved alert with the same labels, if any.
+// It returns the stored alert.
+func (st *Store) Add(a *Alert) *Alert {
+	now := st.now()
+	na := *a
+	na.UpdatedAt = now
+	if na.StartsAt.IsZero() {
+		na.StartsAt = now
+	}
+	fp := na.Fingerprint()
+	st.m.Lock()
+	defer st.m.Unlock()
+	if ea, ok := st.alerts[fp]; ok && !ea.Resolved(now) && ea.StartsAt.Before(na.StartsAt) {
+		na.StartsAt = ea.StartsAt
+	}
+	st.alerts[fp] = &na
+	st.purge(now)
+	return &na
+}
+
+// purge removes the alerts resolved for longer than resolvedRetention,
+// it assumes the lock is acquired.
+func (st *Store) purge(now time.Time) {
+	for fp, a := range st.alerts {
+		if a.Resolved(now.Add(-resolvedRetention)) {
+			delete(st.alerts, fp)
+		}
+	}
+}
+
+// Active returns the unresolved alerts sorted by start time.
+func (st *Store) Active() []*Alert {
+	now := st.now()
+	st.m.RLock()
+	defer st.m.RUnlock()
+	as := make([]*Alert, 0, len(st.alerts))
+	for _, a := range st.alerts {
+		if !a.Resolved(now) {
+			as = append(as, a)
+		}
+	}
+	sort.Slice(as, func(i, j int) bool {
+		if as[i].StartsAt.Equal(as[j].StartsAt) {
+			return as[i].Fingerprint() < as[j].Fingerprint()
+		}
+		return as[i].StartsAt.Before(as[j].StartsAt)
+	})
+	return as
+}
+
+// Push sends the alerts to the Alertmanager API at url.
+func Push(ctx context.Context, client *http.Client, url string, headers map[string]string, as []*Alert) error {
+	b, err := json.Marshal(as)
+	if err != nil {
+		return err
+	}
+	url = strings.TrimSuffix(url, "/") + "/api/v2/alerts"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rsp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(rsp.Body, 512))
+		return fmt.Errorf("%s: status code=%d: %s", url, rsp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
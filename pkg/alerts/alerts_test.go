@@ -0,0 +1,103 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	now := time.Unix(1000, 0)
+	st := NewStore()
+	st.now = func() time.Time { return now }
+
+	a := st.Add(&Alert{
+		Labels: map[string]string{AlertNameLabel: "HighCPU", "source": "r1"},
+		EndsAt: now.Add(5 * time.Minute),
+	})
+	if !a.StartsAt.Equal(now) {
+		t.Errorf("start time not set: %v", a.StartsAt)
+	}
+	// the same alert raised again keeps its start time
+	now = now.Add(time.Minute)
+	st.Add(&Alert{
+		Labels: map[string]string{AlertNameLabel: "HighCPU", "source": "r1"},
+		EndsAt: now.Add(5 * time.Minute),
+	})
+	st.Add(&Alert{
+		Labels: map[string]string{AlertNameLabel: "HighCPU", "source": "r2"},
+		EndsAt: now.Add(5 * time.Minute),
+	})
+	as := st.Active()
+	if len(as) != 2 {
+		t.Fatalf("got %d alerts, want 2", len(as))
+	}
+	if as[0].Labels["source"] != "r1" || !as[0].StartsAt.Equal(now.Add(-time.Minute)) {
+		t.Errorf("unexpected first alert: %+v", as[0])
+	}
+	// r1 is resolved, raising it again starts a new alert
+	now = now.Add(5 * time.Minute)
+	if as := st.Active(); len(as) != 0 {
+		t.Errorf("resolved alerts still active: %v", as)
+	}
+	a = st.Add(&Alert{
+		Labels: map[string]string{AlertNameLabel: "HighCPU", "source": "r1"},
+		EndsAt: now.Add(5 * time.Minute),
+	})
+	if !a.StartsAt.Equal(now) {
+		t.Errorf("resolved alert start time kept: %v", a.StartsAt)
+	}
+}
+
+func TestSanitizeLabelName(t *testing.T) {
+	for in, want := range map[string]string{
+		"source":            "source",
+		"subscription-name": "subscription_name",
+		"1st":               "_st",
+		"if/name:0":         "if_name_0",
+	} {
+		if got := SanitizeLabelName(in); got != want {
+			t.Errorf("%s: got %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestPush(t *testing.T) {
+	var got []*Alert
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/alerts" || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+
+	as := []*Alert{{Labels: map[string]string{AlertNameLabel: "HighCPU"}}}
+	err := Push(context.Background(), srv.Client(), srv.URL+"/", map[string]string{"Authorization": "Bearer t"}, as)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Labels[AlertNameLabel] != "HighCPU" {
+		t.Errorf("unexpected alerts received: %v", got)
+	}
+	err = Push(context.Background(), srv.Client(), srv.URL, nil, as)
+	if err == nil {
+		t.Errorf("expected an error")
+	}
+}
@@ -400,6 +400,39 @@ func Extension_CommitSetRollbackDuration(id string, dur time.Duration) func(msg
 	}
 }
 
+// Extension_MasterArbitration creates a GNMIOption that adds a gNMI extension of
+// type MasterArbitration with the supplied election ID and role.
+// An empty role means the default role.
+func Extension_MasterArbitration(high, low uint64, role string) func(msg proto.Message) error {
+	return func(msg proto.Message) error {
+		if msg == nil {
+			return ErrInvalidMsgType
+		}
+		switch msg := msg.ProtoReflect().Interface().(type) {
+		case *gnmi.SetRequest, *gnmi.SubscribeRequest:
+			ma := &gnmi_ext.MasterArbitration{
+				ElectionId: &gnmi_ext.Uint128{
+					High: high,
+					Low:  low,
+				},
+			}
+			if role != "" {
+				ma.Role = &gnmi_ext.Role{Id: role}
+			}
+			fn := Extension(
+				&gnmi_ext.Extension{
+					Ext: &gnmi_ext.Extension_MasterArbitration{
+						MasterArbitration: ma,
+					},
+				},
+			)
+			return fn(msg)
+		default:
+			return fmt.Errorf("option Extension_MasterArbitration: %w: %T", ErrInvalidMsgType, msg)
+		}
+	}
+}
+
 func Extension_Depth(lvl uint32) func(msg proto.Message) error {
 	return func(msg proto.Message) error {
 		if msg == nil {
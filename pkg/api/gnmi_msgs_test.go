@@ -1762,3 +1762,36 @@ func TestSupportedModel(t *testing.T) {
 		}
 	})
 }
+
+func TestExtension_MasterArbitration(t *testing.T) {
+	want := &gnmi_ext.MasterArbitration{
+		ElectionId: &gnmi_ext.Uint128{High: 1, Low: 2},
+		Role:       &gnmi_ext.Role{Id: "controller"},
+	}
+	setReq, err := NewSetRequest(Extension_MasterArbitration(1, 2, "controller"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subReq, err := NewSubscribeRequest(
+		Subscription(Path("interface")),
+		Extension_MasterArbitration(1, 2, "controller"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, exts := range [][]*gnmi_ext.Extension{setReq.GetExtension(), subReq.GetExtension()} {
+		if len(exts) != 1 {
+			t.Fatalf("expected 1 extension, got %d", len(exts))
+		}
+		got := exts[0].GetMasterArbitration()
+		if got.GetElectionId().GetHigh() != want.GetElectionId().GetHigh() ||
+			got.GetElectionId().GetLow() != want.GetElectionId().GetLow() ||
+			got.GetRole().GetId() != want.GetRole().GetId() {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	}
+	_, err = NewGetRequest(Extension_MasterArbitration(1, 2, ""))
+	if !errors.Is(err, ErrInvalidMsgType) {
+		t.Errorf("expected ErrInvalidMsgType, got %v", err)
+	}
+}
@@ -4,11 +4,14 @@ go 1.21.1
 
 require (
 	github.com/AlekSi/pointer v1.2.0
+	github.com/ThalesIgnite/crypto11 v1.2.5
 	github.com/google/go-cmp v0.6.0
+	github.com/google/go-tpm v0.9.0
 	github.com/grpc-ecosystem/go-grpc-middleware v1.4.0
 	github.com/grpc-ecosystem/go-grpc-prometheus v1.2.0
 	github.com/jhump/protoreflect v1.16.0
 	github.com/juju/ratelimit v1.0.2
+	github.com/klauspost/compress v1.17.7
 	github.com/openconfig/gnmi v0.11.0
 	github.com/openconfig/grpctunnel v0.1.0
 	github.com/pkg/errors v0.9.1
@@ -27,9 +30,11 @@ require (
 	github.com/cespare/xxhash/v2 v2.3.0 // indirect
 	github.com/golang/protobuf v1.5.4 // indirect
 	github.com/kr/text v0.2.0 // indirect
+	github.com/miekg/pkcs11 v1.0.3-0.20190429190417-a667d056470f // indirect
 	github.com/prometheus/client_model v0.6.1 // indirect
 	github.com/prometheus/common v0.53.0 // indirect
 	github.com/prometheus/procfs v0.14.0 // indirect
+	github.com/thales-e-security/pool v0.0.2 // indirect
 	golang.org/x/sys v0.19.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20240415180920-8c6c420018be // indirect
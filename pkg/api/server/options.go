@@ -78,6 +78,9 @@ func (r *rateLimiterInterceptor) Limit() bool {
 }
 
 func (s *gNMIServer) tlsServerOpts() (grpc.ServerOption, error) {
+	if s.config.TLSConfig != nil {
+		return grpc.Creds(credentials.NewTLS(s.config.TLSConfig)), nil
+	}
 	if s.config.TLS == nil {
 		return grpc.Creds(insecure.NewCredentials()), nil
 	}
@@ -71,6 +71,9 @@ type Config struct {
 	RateLimit int64
 	// TLS config
 	TLS *types.TLSConfig
+	// TLSConfig is an already built *tls.Config,
+	// if set, it takes precedence over TLS.
+	TLSConfig *tls.Config
 }
 
 type gNMIServer struct {
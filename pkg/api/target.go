@@ -11,10 +11,13 @@ package api
 import (
 	"crypto/tls"
 	"errors"
+	"fmt"
+	"net"
 	"strings"
 	"time"
 
 	"github.com/AlekSi/pointer"
+	"github.com/openconfig/gnmi/proto/gnmi"
 
 	"github.com/openconfig/gnmic/pkg/api/target"
 	"github.com/openconfig/gnmic/pkg/api/types"
@@ -199,3 +202,38 @@ func Token(token string) TargetOption {
 		return nil
 	}
 }
+
+// SourceAddress sets the local IP address
+// used to establish the connection to the target.
+func SourceAddress(addr string) TargetOption {
+	return func(t *target.Target) error {
+		if net.ParseIP(addr) == nil {
+			return fmt.Errorf("invalid source address %q", addr)
+		}
+		t.Config.SourceAddress = addr
+		return nil
+	}
+}
+
+// SourceInterface binds the connection to the target to the given
+// network interface or VRF device (SO_BINDTODEVICE), Linux only.
+func SourceInterface(name string) TargetOption {
+	return func(t *target.Target) error {
+		t.Config.SourceInterface = name
+		return nil
+	}
+}
+
+// EncodingFallback sets the encodings, in preference order, used to retry
+// Get and Subscribe requests rejected by the target because of an unsupported encoding.
+func EncodingFallback(encodings ...string) TargetOption {
+	return func(t *target.Target) error {
+		for _, enc := range encodings {
+			if _, ok := gnmi.Encoding_value[strings.ToUpper(strings.ReplaceAll(enc, "-", "_"))]; !ok {
+				return fmt.Errorf("invalid encoding %q", enc)
+			}
+		}
+		t.Config.EncodingFallback = encodings
+		return nil
+	}
+}
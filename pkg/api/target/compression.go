@@ -0,0 +1,148 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+)
+
+// CompressionStats holds the number of bytes sent and received over a target connection,
+// before (raw) and after (wire) compression.
+type CompressionStats struct {
+	RawSent      uint64 `json:"raw-sent,omitempty"`
+	WireSent     uint64 `json:"wire-sent,omitempty"`
+	RawReceived  uint64 `json:"raw-received,omitempty"`
+	WireReceived uint64 `json:"wire-received,omitempty"`
+	// true if the target rejected the configured compression
+	// and the RPCs are sent uncompressed.
+	Fallback bool `json:"fallback,omitempty"`
+}
+
+// connStats counts the bytes sent and received over a gRPC connection
+// and tracks whether the target supports the configured compression.
+type connStats struct {
+	rawSent      atomic.Uint64
+	wireSent     atomic.Uint64
+	rawReceived  atomic.Uint64
+	wireReceived atomic.Uint64
+	// set when the target does not support the configured compressor.
+	fallback atomic.Bool
+}
+
+func (cs *connStats) dialOpts(compressor string) []grpc.DialOption {
+	opts := []grpc.DialOption{grpc.WithStatsHandler(cs)}
+	if compressor != "" {
+		opts = append(opts,
+			grpc.WithChainUnaryInterceptor(cs.unaryInterceptor),
+			grpc.WithChainStreamInterceptor(cs.streamInterceptor),
+		)
+	}
+	return opts
+}
+
+func (cs *connStats) snapshot() CompressionStats {
+	return CompressionStats{
+		RawSent:      cs.rawSent.Load(),
+		WireSent:     cs.wireSent.Load(),
+		RawReceived:  cs.rawReceived.Load(),
+		WireReceived: cs.wireReceived.Load(),
+		Fallback:     cs.fallback.Load(),
+	}
+}
+
+// stats.Handler interface
+
+func (cs *connStats) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context { return ctx }
+
+func (cs *connStats) HandleRPC(_ context.Context, s stats.RPCStats) {
+	switch s := s.(type) {
+	case *stats.InPayload:
+		cs.rawReceived.Add(uint64(s.Length))
+		cs.wireReceived.Add(uint64(s.WireLength))
+	case *stats.OutPayload:
+		cs.rawSent.Add(uint64(s.Length))
+		cs.wireSent.Add(uint64(s.WireLength))
+	}
+}
+
+func (cs *connStats) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context { return ctx }
+
+func (cs *connStats) HandleConn(context.Context, stats.ConnStats) {}
+
+// unaryInterceptor resends the request uncompressed if the target
+// rejects the compressed one, the following requests are sent uncompressed.
+// The rejected request was not processed by the target so it is safe to resend it.
+func (cs *connStats) unaryInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if cs.fallback.Load() {
+		return invoker(ctx, method, req, reply, cc, append(opts, grpc.UseCompressor(encoding.Identity))...)
+	}
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	if isCompressionUnsupported(err) {
+		cs.fallback.Store(true)
+		return invoker(ctx, method, req, reply, cc, append(opts, grpc.UseCompressor(encoding.Identity))...)
+	}
+	return err
+}
+
+// streamInterceptor opens the streams uncompressed once the target rejected a compressed one.
+// The rejection is received as a stream error so the stream is not reopened here,
+// it is reopened uncompressed by the subscription retry.
+func (cs *connStats) streamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	if cs.fallback.Load() {
+		return streamer(ctx, desc, cc, method, append(opts, grpc.UseCompressor(encoding.Identity))...)
+	}
+	s, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &fallbackStream{ClientStream: s, cs: cs}, nil
+}
+
+type fallbackStream struct {
+	grpc.ClientStream
+	cs *connStats
+}
+
+func (s *fallbackStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if isCompressionUnsupported(err) {
+		s.cs.fallback.Store(true)
+	}
+	return err
+}
+
+// isCompressionUnsupported returns true if err is returned by a server
+// not supporting the request compression.
+func isCompressionUnsupported(err error) bool {
+	if err == nil {
+		return false
+	}
+	st, ok := status.FromError(err)
+	return ok && st.Code() == codes.Unimplemented && strings.Contains(st.Message(), "grpc-encoding")
+}
+
+// CompressionStats returns the number of bytes sent and received over the target connection.
+// If the connection is shared with other targets, the returned values cover all of them.
+func (t *Target) CompressionStats() CompressionStats {
+	t.m.Lock()
+	cs := t.connStats
+	t.m.Unlock()
+	if cs == nil {
+		return CompressionStats{}
+	}
+	return cs.snapshot()
+}
@@ -0,0 +1,99 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+type capabilitiesServer struct {
+	gnmi.UnimplementedGNMIServer
+}
+
+func (s *capabilitiesServer) Capabilities(context.Context, *gnmi.CapabilityRequest) (*gnmi.CapabilityResponse, error) {
+	return &gnmi.CapabilityResponse{GNMIVersion: "0.10.0"}, nil
+}
+
+func TestCompression(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	gnmi.RegisterGNMIServer(s, new(capabilitiesServer))
+	go s.Serve(l)
+	defer s.Stop()
+
+	for _, compression := range []string{"gzip", "zstd", "none"} {
+		t.Run(compression, func(t *testing.T) {
+			insecure := true
+			tg := NewTarget(&types.TargetConfig{
+				Name:        "t1",
+				Address:     l.Addr().String(),
+				Insecure:    &insecure,
+				Timeout:     time.Second,
+				Compression: compression,
+			})
+			if err := tg.CreateGNMIClient(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			defer tg.Close()
+			rsp, err := tg.Capabilities(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rsp.GetGNMIVersion() != "0.10.0" {
+				t.Errorf("unexpected response: %v", rsp)
+			}
+			st := tg.CompressionStats()
+			if st.RawReceived == 0 || st.WireReceived == 0 || st.WireSent == 0 {
+				t.Errorf("unexpected compression stats: %+v", st)
+			}
+			if st.Fallback {
+				t.Errorf("unexpected compression fallback")
+			}
+		})
+	}
+}
+
+func TestCompressionFallback(t *testing.T) {
+	cs := new(connStats)
+	calls := 0
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		calls++
+		if len(opts) == 0 {
+			return status.Error(codes.Unimplemented, `grpc: Decompressor is not installed for grpc-encoding "zstd"`)
+		}
+		return nil
+	}
+	err := cs.unaryInterceptor(context.Background(), "/gnmi.gNMI/Get", nil, nil, nil, invoker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 || !cs.fallback.Load() {
+		t.Fatalf("expected the request to be resent uncompressed, calls=%d", calls)
+	}
+	err = cs.unaryInterceptor(context.Background(), "/gnmi.gNMI/Get", nil, nil, nil, invoker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected the following requests to be sent uncompressed, calls=%d", calls)
+	}
+}
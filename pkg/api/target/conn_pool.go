@@ -0,0 +1,163 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+// ConnPool shares gRPC connections between targets reaching the same device
+// with the same connection settings.
+// Each connection carries up to a maximum number of streams,
+// a new connection is dialed when all the existing ones are full.
+type ConnPool struct {
+	m     *sync.Mutex
+	conns map[string][]*sharedConn
+	// per connection key dial locks
+	dialLocks map[string]*sync.Mutex
+}
+
+type sharedConn struct {
+	key     string
+	conn    *grpc.ClientConn
+	stats   *connStats
+	streams int
+}
+
+// NewConnPool creates a new empty connection pool.
+func NewConnPool() *ConnPool {
+	return &ConnPool{
+		m:         new(sync.Mutex),
+		conns:     make(map[string][]*sharedConn),
+		dialLocks: make(map[string]*sync.Mutex),
+	}
+}
+
+// acquire returns a connection matching key with room for streams additional streams.
+// If none is found, a new connection is created using dial.
+func (p *ConnPool) acquire(ctx context.Context, key string, streams, maxStreams int, dial func(context.Context) (*grpc.ClientConn, *connStats, error)) (*sharedConn, error) {
+	p.m.Lock()
+	dl, ok := p.dialLocks[key]
+	if !ok {
+		dl = new(sync.Mutex)
+		p.dialLocks[key] = dl
+	}
+	p.m.Unlock()
+	// serialize the dials for the same key so that
+	// concurrent targets share the first created connection.
+	dl.Lock()
+	defer dl.Unlock()
+
+	p.m.Lock()
+	for _, sc := range p.conns[key] {
+		if sc.conn.GetState() == connectivity.Shutdown {
+			continue
+		}
+		if sc.streams+streams <= maxStreams {
+			sc.streams += streams
+			p.m.Unlock()
+			return sc, nil
+		}
+	}
+	p.m.Unlock()
+
+	conn, cs, err := dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sc := &sharedConn{key: key, conn: conn, stats: cs, streams: streams}
+	p.m.Lock()
+	p.conns[key] = append(p.conns[key], sc)
+	p.m.Unlock()
+	return sc, nil
+}
+
+// release returns streams streams to the shared connection sc,
+// the connection is closed once it does not carry any stream.
+func (p *ConnPool) release(sc *sharedConn, streams int) error {
+	p.m.Lock()
+	defer p.m.Unlock()
+	sc.streams -= streams
+	if sc.streams > 0 {
+		return nil
+	}
+	conns := p.conns[sc.key]
+	for i, c := range conns {
+		if c == sc {
+			p.conns[sc.key] = append(conns[:i], conns[i+1:]...)
+			break
+		}
+	}
+	if len(p.conns[sc.key]) == 0 {
+		delete(p.conns, sc.key)
+		delete(p.dialLocks, sc.key)
+	}
+	return sc.conn.Close()
+}
+
+// NumConns returns the number of open connections in the pool.
+func (p *ConnPool) NumConns() int {
+	p.m.Lock()
+	defer p.m.Unlock()
+	n := 0
+	for _, conns := range p.conns {
+		n += len(conns)
+	}
+	return n
+}
+
+// connKey returns the key identifying the connections that can be shared,
+// it is built from the target config fields used to create the gRPC connection.
+// The username, password and metadata are sent per RPC so they are not part of the key,
+// the token is part of it when it is set on the connection, see GrpcDialOptions.
+func connKey(tc *types.TargetConfig) string {
+	k := &types.TargetConfig{
+		Address:          tc.Address,
+		Timeout:          tc.Timeout,
+		Insecure:         tc.Insecure,
+		TLSCA:            tc.TLSCA,
+		TLSCert:          tc.TLSCert,
+		TLSKey:           tc.TLSKey,
+		SkipVerify:       tc.SkipVerify,
+		TLSServerName:    tc.TLSServerName,
+		TLSMinVersion:    tc.TLSMinVersion,
+		TLSMaxVersion:    tc.TLSMaxVersion,
+		TLSVersion:       tc.TLSVersion,
+		LogTLSSecret:     tc.LogTLSSecret,
+		Gzip:             tc.Gzip,
+		Compression:      tc.Compression,
+		Proxy:            tc.Proxy,
+		TunnelTargetType: tc.TunnelTargetType,
+		CipherSuites:     tc.CipherSuites,
+		TCPKeepalive:     tc.TCPKeepalive,
+		GRPCKeepalive:    tc.GRPCKeepalive,
+		SourceAddress:    tc.SourceAddress,
+		SourceInterface:  tc.SourceInterface,
+		MaxRecvMsgSize:   tc.MaxRecvMsgSize,
+		MaxSendMsgSize:   tc.MaxSendMsgSize,
+		RetryPolicy:      tc.RetryPolicy,
+	}
+	if tc.Token != nil && *tc.Token != "" && tc.CredentialsCommand == nil {
+		// only a hash of the token is kept in the key
+		h := sha256.Sum256([]byte(*tc.Token))
+		token := hex.EncodeToString(h[:])
+		k.Token = &token
+	}
+	b, _ := json.Marshal(k)
+	return string(b)
+}
@@ -0,0 +1,107 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+func TestConnPool(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	go s.Serve(l)
+	defer s.Stop()
+
+	insecure := true
+	pool := NewConnPool()
+	targets := make([]*Target, 0, 3)
+	for i := 0; i < 3; i++ {
+		tg := NewTarget(&types.TargetConfig{
+			Name:                    fmt.Sprintf("t%d", i),
+			Address:                 l.Addr().String(),
+			Insecure:                &insecure,
+			Timeout:                 time.Second,
+			MaxStreamsPerConnection: 2,
+		})
+		tg.Subscriptions["sub1"] = &types.SubscriptionConfig{Name: "sub1"}
+		tg.SetConnPool(pool)
+		if err := tg.CreateGNMIClient(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		targets = append(targets, tg)
+	}
+	if n := pool.NumConns(); n != 2 {
+		t.Fatalf("expected 2 connections, got %d", n)
+	}
+	if targets[0].conn != targets[1].conn {
+		t.Errorf("expected targets t0 and t1 to share a connection")
+	}
+	if targets[0].conn == targets[2].conn {
+		t.Errorf("expected target t2 to use a separate connection")
+	}
+	// re-creating the client keeps the number of streams unchanged
+	if err := targets[1].CreateGNMIClient(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := pool.NumConns(); n != 2 {
+		t.Fatalf("expected 2 connections, got %d", n)
+	}
+	for _, tg := range targets {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := pool.NumConns(); n != 0 {
+		t.Fatalf("expected all connections to be closed, got %d", n)
+	}
+}
+
+func TestConnKey(t *testing.T) {
+	user1, user2 := "admin", "user"
+	tc1 := &types.TargetConfig{Name: "t1", Address: "10.1.1.1:57400", Username: &user1}
+	tc2 := &types.TargetConfig{Name: "t2", Address: "10.1.1.1:57400", Username: &user2}
+	if connKey(tc1) != connKey(tc2) {
+		t.Errorf("expected targets with different credentials to share a connection key")
+	}
+	tc2.TLSServerName = "router1"
+	if connKey(tc1) == connKey(tc2) {
+		t.Errorf("expected targets with different TLS settings to have different connection keys")
+	}
+	// the token is set on the connection
+	token1, token2 := "t1", "t2"
+	tc3 := &types.TargetConfig{Name: "t3", Address: "10.1.1.1:57400", Token: &token1}
+	tc4 := &types.TargetConfig{Name: "t4", Address: "10.1.1.1:57400", Token: &token2}
+	if connKey(tc3) == connKey(tc4) {
+		t.Errorf("expected targets with different tokens to have different connection keys")
+	}
+	if connKey(tc1) == connKey(tc3) {
+		t.Errorf("expected targets with and without a token to have different connection keys")
+	}
+	if strings.Contains(connKey(tc3), token1) {
+		t.Errorf("connection key contains the token: %s", connKey(tc3))
+	}
+	// unless it is set per RPC by the credentials command
+	tc3.CredentialsCommand = &types.CredentialsCommand{}
+	tc4.CredentialsCommand = &types.CredentialsCommand{}
+	if connKey(tc3) != connKey(tc4) || connKey(tc1) != connKey(tc3) {
+		t.Errorf("expected targets with per RPC tokens to share a connection key")
+	}
+}
@@ -0,0 +1,70 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"context"
+	"encoding/base64"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// commandCredentials sets the credentials returned by the target credentials-command
+// on each RPC, falling back to the configured ones.
+type commandCredentials struct {
+	t *Target
+}
+
+func (c *commandCredentials) GetRequestMetadata(ctx context.Context, _ ...string) (map[string]string, error) {
+	creds, err := c.t.credentials.Get(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	username, password, token := creds.Username, creds.Password, creds.Token
+	if username == "" && c.t.Config.Username != nil {
+		username = *c.t.Config.Username
+	}
+	if password == "" && c.t.Config.Password != nil {
+		password = *c.t.Config.Password
+	}
+	if token == "" && c.t.Config.Token != nil {
+		token = *c.t.Config.Token
+	}
+
+	md := make(map[string]string, 2)
+	if token != "" {
+		md["authorization"] = "Bearer " + token
+	}
+	if c.t.Config.AuthScheme != "" {
+		md["authorization"] = c.t.Config.AuthScheme + " " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+		return md, nil
+	}
+	if username != "" {
+		md["username"] = username
+	}
+	if password != "" {
+		md["password"] = password
+	}
+	return md, nil
+}
+
+// RequireTransportSecurity returns false, like the username and password
+// metadata set without a credentials-command.
+func (c *commandCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// checkCredentials drops the cached credentials if the target rejected them,
+// the credentials-command runs again on the next RPC.
+func (t *Target) checkCredentials(err error) {
+	if status.Code(err) == codes.Unauthenticated {
+		t.credentials.Invalidate()
+	}
+}
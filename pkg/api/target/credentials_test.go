@@ -0,0 +1,98 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+// authServer only accepts the password "2".
+type authServer struct {
+	gnmi.UnimplementedGNMIServer
+}
+
+func (s *authServer) Capabilities(ctx context.Context, _ *gnmi.CapabilityRequest) (*gnmi.CapabilityResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if len(md.Get("username")) == 0 || md.Get("username")[0] != "admin" ||
+		len(md.Get("password")) == 0 || md.Get("password")[0] != "2" {
+		return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+	}
+	return &gnmi.CapabilityResponse{GNMIVersion: "0.10.0"}, nil
+}
+
+func TestCredentialsCommand(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	gnmi.RegisterGNMIServer(s, new(authServer))
+	go s.Serve(l)
+	defer s.Stop()
+
+	// the helper returns the number of times it ran as password
+	dir := t.TempDir()
+	script := filepath.Join(dir, "helper.sh")
+	err = os.WriteFile(script, []byte(`#!/bin/sh
+n=$(cat "`+dir+`/count" 2>/dev/null || echo 0)
+n=$((n+1))
+echo $n > "`+dir+`/count"
+echo "{\"Username\":\"$GNMIC_TARGET_NAME\",\"Secret\":\"$n\"}"
+`), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	insecure := true
+	tg := NewTarget(&types.TargetConfig{
+		Name:               "admin",
+		Address:            l.Addr().String(),
+		Insecure:           &insecure,
+		Timeout:            time.Second,
+		CredentialsCommand: &types.CredentialsCommand{Command: script},
+	})
+	if err := tg.CreateGNMIClient(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer tg.Close()
+
+	// rejected, the credentials are dropped
+	_, err = tg.Capabilities(context.Background())
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected an unauthenticated error, got %v", err)
+	}
+	// the command runs again
+	if _, err = tg.Capabilities(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// cached
+	if _, err = tg.Capabilities(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "count"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "2\n" {
+		t.Errorf("expected the command to run twice, ran %q times", b)
+	}
+}
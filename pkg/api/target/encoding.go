@@ -0,0 +1,200 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// defaultEncodingPreference is the order in which the encodings advertised
+// by the target are tried when no encoding-fallback is configured.
+var defaultEncodingPreference = []string{"json_ietf", "json", "proto", "ascii", "bytes"}
+
+// encodingState holds the result of the encoding negotiation with the target.
+type encodingState struct {
+	// encodings rejected by the target
+	unsupported map[gnmi.Encoding]struct{}
+	// encodings advertised in the target capabilities,
+	// nil if not queried yet.
+	advertised []gnmi.Encoding
+	// the last encoding that replaced a rejected one
+	working *gnmi.Encoding
+}
+
+// Encoding returns the encoding negotiated with the target
+// after it rejected the configured one, or an empty string.
+func (t *Target) Encoding() string {
+	t.m.Lock()
+	defer t.m.Unlock()
+	if t.encoding.working == nil {
+		return ""
+	}
+	return strings.ToLower(t.encoding.working.String())
+}
+
+// isEncodingError returns true if err is returned by a target
+// that does not support the requested encoding.
+func isEncodingError(err error) bool {
+	st, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+	switch st.Code() {
+	case codes.Unimplemented, codes.InvalidArgument:
+		return strings.Contains(strings.ToLower(st.Message()), "encoding")
+	}
+	return false
+}
+
+// canFallback returns true if err indicates that the requested encoding is not supported.
+func (t *Target) canFallback(err error) bool {
+	return isEncodingError(err)
+}
+
+// requestEncoding returns the encoding to use instead of enc,
+// if enc was previously rejected by the target.
+func (t *Target) requestEncoding(enc gnmi.Encoding) gnmi.Encoding {
+	t.m.Lock()
+	defer t.m.Unlock()
+	if _, ok := t.encoding.unsupported[enc]; ok && t.encoding.working != nil {
+		return *t.encoding.working
+	}
+	return enc
+}
+
+// negotiateEncoding marks the encoding failed as unsupported and returns
+// the first fallback encoding advertised by the target that was not rejected yet.
+// Without configured fallback encodings, the advertised encodings are tried
+// in the defaultEncodingPreference order.
+func (t *Target) negotiateEncoding(ctx context.Context, failed gnmi.Encoding) (gnmi.Encoding, error) {
+	t.m.Lock()
+	advertised := t.encoding.advertised
+	t.m.Unlock()
+	if advertised == nil {
+		capRsp, err := t.Capabilities(ctx)
+		if err != nil {
+			return failed, fmt.Errorf("failed to get the target capabilities: %v", err)
+		}
+		advertised = capRsp.GetSupportedEncodings()
+		if advertised == nil {
+			advertised = make([]gnmi.Encoding, 0)
+		}
+	}
+
+	t.m.Lock()
+	defer t.m.Unlock()
+	t.encoding.advertised = advertised
+	if t.encoding.unsupported == nil {
+		t.encoding.unsupported = make(map[gnmi.Encoding]struct{})
+	}
+	t.encoding.unsupported[failed] = struct{}{}
+	preference := t.Config.EncodingFallback
+	if len(preference) == 0 {
+		if len(advertised) == 0 {
+			return failed, fmt.Errorf("no encoding-fallback configured and no encodings advertised by the target")
+		}
+		preference = defaultEncodingPreference
+	}
+	enc, ok := selectEncoding(preference, advertised, t.encoding.unsupported)
+	if !ok {
+		return failed, fmt.Errorf("no fallback encoding supported by the target, advertised encodings: %v", advertised)
+	}
+	t.encoding.working = &enc
+	return enc, nil
+}
+
+// selectEncoding returns the first encoding in preference that is advertised,
+// or any if the advertised list is empty, and is not unsupported.
+func selectEncoding(preference []string, advertised []gnmi.Encoding, unsupported map[gnmi.Encoding]struct{}) (gnmi.Encoding, bool) {
+	for _, name := range preference {
+		v, ok := gnmi.Encoding_value[strings.ToUpper(strings.ReplaceAll(name, "-", "_"))]
+		if !ok {
+			continue
+		}
+		enc := gnmi.Encoding(v)
+		if _, ok := unsupported[enc]; ok {
+			continue
+		}
+		if len(advertised) == 0 {
+			return enc, true
+		}
+		for _, a := range advertised {
+			if a == enc {
+				return enc, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// getWithEncodingFallback sends the GetRequest, retrying with a fallback encoding
+// as long as the target rejects the requested one.
+func (t *Target) getWithEncodingFallback(ctx context.Context, req *gnmi.GetRequest) (*gnmi.GetResponse, error) {
+	if enc := t.requestEncoding(req.GetEncoding()); enc != req.GetEncoding() {
+		req = proto.Clone(req).(*gnmi.GetRequest)
+		req.Encoding = enc
+	}
+	for {
+		rsp, err := t.Client.Get(t.appendRequestMetadata(ctx), req, t.callOpts()...)
+		if err == nil || !t.canFallback(err) {
+			return rsp, err
+		}
+		enc, nerr := t.negotiateEncoding(ctx, req.GetEncoding())
+		if nerr != nil {
+			return nil, fmt.Errorf("%v: %v", err, nerr)
+		}
+		req = proto.Clone(req).(*gnmi.GetRequest)
+		req.Encoding = enc
+	}
+}
+
+// subscribeRequestEncoding returns a copy of req with its encoding replaced
+// if it was previously rejected by the target.
+func (t *Target) subscribeRequestEncoding(req *gnmi.SubscribeRequest) *gnmi.SubscribeRequest {
+	sl := req.GetSubscribe()
+	if sl == nil {
+		return req
+	}
+	enc := t.requestEncoding(sl.GetEncoding())
+	if enc == sl.GetEncoding() {
+		return req
+	}
+	req = proto.Clone(req).(*gnmi.SubscribeRequest)
+	req.GetSubscribe().Encoding = enc
+	return req
+}
+
+// subscribeFallback returns a copy of req using a fallback encoding if err
+// indicates that the target rejected the requested encoding.
+func (t *Target) subscribeFallback(ctx context.Context, req *gnmi.SubscribeRequest, subscriptionName string, err error) (*gnmi.SubscribeRequest, bool) {
+	if !t.canFallback(err) {
+		return req, false
+	}
+	failed := req.GetSubscribe().GetEncoding()
+	enc, nerr := t.negotiateEncoding(ctx, failed)
+	if nerr != nil {
+		t.errors <- &TargetError{
+			SubscriptionName: subscriptionName,
+			Err:              fmt.Errorf("target '%s' encoding negotiation failed: %v", t.Config.Name, nerr),
+		}
+		return req, false
+	}
+	t.errors <- &TargetError{
+		SubscriptionName: subscriptionName,
+		Err:              fmt.Errorf("target '%s' does not support encoding %s, retrying with %s", t.Config.Name, failed, enc),
+	}
+	return t.subscribeRequestEncoding(req), true
+}
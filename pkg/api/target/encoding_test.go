@@ -0,0 +1,189 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+func TestSelectEncoding(t *testing.T) {
+	tests := []struct {
+		name        string
+		preference  []string
+		advertised  []gnmi.Encoding
+		unsupported map[gnmi.Encoding]struct{}
+		exp         gnmi.Encoding
+		expOK       bool
+	}{
+		{
+			name:       "first_advertised",
+			preference: []string{"proto", "json_ietf", "json"},
+			advertised: []gnmi.Encoding{gnmi.Encoding_JSON, gnmi.Encoding_JSON_IETF},
+			exp:        gnmi.Encoding_JSON_IETF,
+			expOK:      true,
+		},
+		{
+			name:        "skip_unsupported",
+			preference:  []string{"json_ietf", "json"},
+			advertised:  []gnmi.Encoding{gnmi.Encoding_JSON, gnmi.Encoding_JSON_IETF},
+			unsupported: map[gnmi.Encoding]struct{}{gnmi.Encoding_JSON_IETF: {}},
+			exp:         gnmi.Encoding_JSON,
+			expOK:       true,
+		},
+		{
+			name:       "nothing_advertised",
+			preference: []string{"ASCII", "json"},
+			exp:        gnmi.Encoding_ASCII,
+			expOK:      true,
+		},
+		{
+			name:       "no_match",
+			preference: []string{"proto"},
+			advertised: []gnmi.Encoding{gnmi.Encoding_JSON},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enc, ok := selectEncoding(tt.preference, tt.advertised, tt.unsupported)
+			if ok != tt.expOK {
+				t.Fatalf("expected ok=%v, got %v", tt.expOK, ok)
+			}
+			if ok && enc != tt.exp {
+				t.Fatalf("expected %s, got %s", tt.exp, enc)
+			}
+		})
+	}
+}
+
+// jsonIETFOnlyServer rejects any encoding other than JSON_IETF.
+type jsonIETFOnlyServer struct {
+	gnmi.UnimplementedGNMIServer
+	requests []gnmi.Encoding
+}
+
+func (s *jsonIETFOnlyServer) Capabilities(context.Context, *gnmi.CapabilityRequest) (*gnmi.CapabilityResponse, error) {
+	return &gnmi.CapabilityResponse{
+		SupportedEncodings: []gnmi.Encoding{gnmi.Encoding_PROTO, gnmi.Encoding_JSON_IETF},
+	}, nil
+}
+
+func (s *jsonIETFOnlyServer) Get(_ context.Context, req *gnmi.GetRequest) (*gnmi.GetResponse, error) {
+	s.requests = append(s.requests, req.GetEncoding())
+	if req.GetEncoding() != gnmi.Encoding_JSON_IETF {
+		return nil, status.Errorf(codes.Unimplemented, "unsupported encoding: %s", req.GetEncoding())
+	}
+	return &gnmi.GetResponse{}, nil
+}
+
+func newEncodingTestTarget(t *testing.T, srv gnmi.GNMIServer, fallback []string) *Target {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := grpc.NewServer()
+	gnmi.RegisterGNMIServer(gs, srv)
+	go gs.Serve(l)
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.Dial(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	tg := NewTarget(&types.TargetConfig{
+		Name:             "t1",
+		Timeout:          time.Second,
+		EncodingFallback: fallback,
+	})
+	tg.Client = gnmi.NewGNMIClient(conn)
+	return tg
+}
+
+func TestGetEncodingFallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		fallback []string
+	}{
+		{name: "configured", fallback: []string{"bytes", "json_ietf", "json"}},
+		// without encoding-fallback, the advertised encodings are tried
+		// in the default preference order.
+		{name: "default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := new(jsonIETFOnlyServer)
+			tg := newEncodingTestTarget(t, srv, tt.fallback)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			req := &gnmi.GetRequest{Encoding: gnmi.Encoding_JSON}
+			_, err := tg.Get(ctx, req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if req.GetEncoding() != gnmi.Encoding_JSON {
+				t.Fatal("the original request must not be modified")
+			}
+			if tg.Encoding() != "json_ietf" {
+				t.Fatalf("expected the negotiated encoding to be json_ietf, got %q", tg.Encoding())
+			}
+			// the negotiated encoding is used directly for the next requests
+			_, err = tg.Get(ctx, req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			exp := []gnmi.Encoding{gnmi.Encoding_JSON, gnmi.Encoding_JSON_IETF, gnmi.Encoding_JSON_IETF}
+			if len(srv.requests) != len(exp) {
+				t.Fatalf("expected requests with encodings %v, got %v", exp, srv.requests)
+			}
+			for i := range exp {
+				if srv.requests[i] != exp[i] {
+					t.Fatalf("expected requests with encodings %v, got %v", exp, srv.requests)
+				}
+			}
+		})
+	}
+}
+
+// noCapabilitiesServer rejects the JSON encoding and advertises no encodings.
+type noCapabilitiesServer struct {
+	gnmi.UnimplementedGNMIServer
+}
+
+func (s *noCapabilitiesServer) Capabilities(context.Context, *gnmi.CapabilityRequest) (*gnmi.CapabilityResponse, error) {
+	return &gnmi.CapabilityResponse{}, nil
+}
+
+func (s *noCapabilitiesServer) Get(_ context.Context, req *gnmi.GetRequest) (*gnmi.GetResponse, error) {
+	return nil, status.Errorf(codes.InvalidArgument, "unsupported encoding: %s", req.GetEncoding())
+}
+
+func TestGetEncodingDefaultNoAdvertised(t *testing.T) {
+	tg := newEncodingTestTarget(t, new(noCapabilitiesServer), nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := tg.Get(ctx, &gnmi.GetRequest{Encoding: gnmi.Encoding_JSON})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if tg.Encoding() != "" {
+		t.Fatalf("unexpected negotiated encoding %q", tg.Encoding())
+	}
+}
@@ -0,0 +1,77 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+// stateSyncGetRequest returns the Get request retrieving the current values
+// of the on-change paths of the stream subscribe request req,
+// nil if it has no on-change paths.
+func stateSyncGetRequest(req *gnmi.SubscribeRequest) *gnmi.GetRequest {
+	subList := req.GetSubscribe()
+	if subList.GetMode() != gnmi.SubscriptionList_STREAM {
+		return nil
+	}
+	var paths []*gnmi.Path
+	for _, sub := range subList.GetSubscription() {
+		if sub.GetMode() == gnmi.SubscriptionMode_ON_CHANGE {
+			paths = append(paths, sub.GetPath())
+		}
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	return &gnmi.GetRequest{
+		Prefix:    subList.GetPrefix(),
+		Path:      paths,
+		Type:      gnmi.GetRequest_ALL,
+		Encoding:  subList.GetEncoding(),
+		UseModels: subList.GetUseModels(),
+	}
+}
+
+// stateSync gets the current values of the on-change paths of the subscribe request req
+// and sends them as subscribe responses flagged as StateSync,
+// so that the consumers can correct the state missed while the subscription was down.
+func (t *Target) stateSync(ctx context.Context, req *gnmi.SubscribeRequest, subscriptionName string, subConfig *types.SubscriptionConfig) {
+	getReq := stateSyncGetRequest(req)
+	if getReq == nil {
+		return
+	}
+	if d := t.Config.RPCTimeout(types.RPCGet); d > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+	rsp, err := t.Get(ctx, getReq)
+	if err != nil {
+		t.errors <- &TargetError{
+			SubscriptionName: subscriptionName,
+			Err:              fmt.Errorf("target '%s' state sync failed: %v", t.Config.Name, err),
+		}
+		return
+	}
+	for _, n := range rsp.GetNotification() {
+		t.sendResponse(&SubscribeResponse{
+			SubscriptionName:   subscriptionName,
+			SubscriptionConfig: subConfig,
+			Response: &gnmi.SubscribeResponse{
+				Response: &gnmi.SubscribeResponse_Update{Update: n},
+			},
+			StateSync: true,
+		})
+	}
+}
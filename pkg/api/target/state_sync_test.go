@@ -0,0 +1,147 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+func TestStateSyncGetRequest(t *testing.T) {
+	onChange := &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "oper-state"}}}
+	sample := &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "counters"}}}
+	req := &gnmi.SubscribeRequest{
+		Request: &gnmi.SubscribeRequest_Subscribe{
+			Subscribe: &gnmi.SubscriptionList{
+				Prefix:   &gnmi.Path{Target: "t1"},
+				Mode:     gnmi.SubscriptionList_STREAM,
+				Encoding: gnmi.Encoding_JSON_IETF,
+				Subscription: []*gnmi.Subscription{
+					{Path: onChange, Mode: gnmi.SubscriptionMode_ON_CHANGE},
+					{Path: sample, Mode: gnmi.SubscriptionMode_SAMPLE},
+				},
+			},
+		},
+	}
+	getReq := stateSyncGetRequest(req)
+	if getReq == nil {
+		t.Fatal("expected a Get request")
+	}
+	if len(getReq.GetPath()) != 1 || getReq.GetPath()[0] != onChange {
+		t.Errorf("unexpected paths: %v", getReq.GetPath())
+	}
+	if getReq.GetPrefix().GetTarget() != "t1" || getReq.GetEncoding() != gnmi.Encoding_JSON_IETF {
+		t.Errorf("unexpected prefix or encoding: %v", getReq)
+	}
+	req.GetSubscribe().Subscription = req.GetSubscribe().Subscription[1:]
+	if getReq := stateSyncGetRequest(req); getReq != nil {
+		t.Errorf("expected no Get request without on-change paths, got %v", getReq)
+	}
+}
+
+// flappingServer closes the first subscribe stream after one update,
+// and answers the Get requests with a single notification.
+type flappingServer struct {
+	gnmi.UnimplementedGNMIServer
+	subscribes atomic.Int32
+	gets       atomic.Int32
+}
+
+func (s *flappingServer) Subscribe(stream gnmi.GNMI_SubscribeServer) error {
+	if _, err := stream.Recv(); err != nil {
+		return err
+	}
+	if s.subscribes.Add(1) == 1 {
+		err := stream.Send(&gnmi.SubscribeResponse{
+			Response: &gnmi.SubscribeResponse_Update{Update: &gnmi.Notification{Timestamp: 1}},
+		})
+		if err != nil {
+			return err
+		}
+		return errors.New("connection reset")
+	}
+	<-stream.Context().Done()
+	return nil
+}
+
+func (s *flappingServer) Get(context.Context, *gnmi.GetRequest) (*gnmi.GetResponse, error) {
+	s.gets.Add(1)
+	return &gnmi.GetResponse{Notification: []*gnmi.Notification{{Timestamp: 2}}}, nil
+}
+
+func TestSubscribeResyncOnReconnect(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := grpc.NewServer()
+	srv := new(flappingServer)
+	gnmi.RegisterGNMIServer(gs, srv)
+	go gs.Serve(l)
+	defer gs.Stop()
+
+	conn, err := grpc.Dial(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	tg := NewTarget(&types.TargetConfig{
+		Name:       "t1",
+		Timeout:    time.Second,
+		RetryTimer: 10 * time.Millisecond,
+		BufferSize: 10,
+	})
+	tg.Client = gnmi.NewGNMIClient(conn)
+	tg.Subscriptions["sub1"] = &types.SubscriptionConfig{Name: "sub1", ResyncOnReconnect: true}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req := &gnmi.SubscribeRequest{
+		Request: &gnmi.SubscribeRequest_Subscribe{
+			Subscribe: &gnmi.SubscriptionList{
+				Mode: gnmi.SubscriptionList_STREAM,
+				Subscription: []*gnmi.Subscription{
+					{Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "oper-state"}}}, Mode: gnmi.SubscriptionMode_ON_CHANGE},
+				},
+			},
+		},
+	}
+	go tg.Subscribe(ctx, req, "sub1")
+
+	rspCh, errCh := tg.ReadSubscriptions()
+	var got []*SubscribeResponse
+	for len(got) < 2 {
+		select {
+		case rsp := <-rspCh:
+			got = append(got, rsp)
+		case <-errCh:
+		case <-ctx.Done():
+			t.Fatalf("timeout, got %d responses", len(got))
+		}
+	}
+	if got[0].StateSync || got[0].Response.GetUpdate().GetTimestamp() != 1 {
+		t.Errorf("expected the stream update first, got %+v", got[0])
+	}
+	if !got[1].StateSync || got[1].Response.GetUpdate().GetTimestamp() != 2 {
+		t.Errorf("expected a state sync update, got %+v", got[1])
+	}
+	if n := srv.gets.Load(); n != 1 {
+		t.Errorf("expected 1 Get request, got %d", n)
+	}
+}
@@ -27,6 +27,11 @@ func (t *Target) Subscribe(ctx context.Context, req *gnmi.SubscribeRequest, subs
 	var nctx context.Context
 	var cancel context.CancelFunc
 	var err error
+	var fallback bool
+	// set once the subscription was established,
+	// the following subscribes are reconnections.
+	var subscribed bool
+	req = t.subscribeRequestEncoding(req)
 	goto SUBSC_NODELAY
 SUBSC:
 	{
@@ -77,12 +82,24 @@ SUBSC_NODELAY:
 
 	switch req.GetSubscribe().GetMode() {
 	case gnmi.SubscriptionList_STREAM:
+		if subscribed && subConfig != nil && subConfig.ResyncOnReconnect {
+			t.stateSync(nctx, req, subscriptionName, subConfig)
+		}
+		subscribed = true
 		err = t.handleStreamSubscriptionRcv(nctx, subscribeClient, subscriptionName, subConfig)
 		if err != nil {
+			if ctx.Err() != nil {
+				// the subscription was canceled by the caller
+				return
+			}
 			t.errors <- &TargetError{
 				SubscriptionName: subscriptionName,
 				Err:              err,
 			}
+			if req, fallback = t.subscribeFallback(ctx, req, subscriptionName, err); fallback {
+				cancel()
+				goto SUBSC_NODELAY
+			}
 			t.errors <- &TargetError{
 				SubscriptionName: subscriptionName,
 				Err:              fmt.Errorf("retrying in %s", t.Config.RetryTimer),
@@ -97,6 +114,10 @@ SUBSC_NODELAY:
 				SubscriptionName: subscriptionName,
 				Err:              err,
 			}
+			if req, fallback = t.subscribeFallback(ctx, req, subscriptionName, err); fallback {
+				cancel()
+				goto SUBSC_NODELAY
+			}
 			if errors.Is(err, io.EOF) {
 				return
 			}
@@ -116,6 +137,10 @@ SUBSC_NODELAY:
 				SubscriptionName: subscriptionName,
 				Err:              err,
 			}
+			if req, fallback = t.subscribeFallback(ctx, req, subscriptionName, err); fallback {
+				cancel()
+				goto SUBSC_NODELAY
+			}
 			cancel()
 			goto SUBSC
 		}
@@ -268,10 +293,33 @@ func (t *Target) ReadSubscriptions() (chan *SubscribeResponse, chan *TargetError
 	return t.subscribeResponses, t.errors
 }
 
+// ReadPrioritySubscriptions returns the channel of the responses classified as high priority
+// by the priority classifier. They are meant to be read ahead of the ReadSubscriptions responses.
+func (t *Target) ReadPrioritySubscriptions() chan *SubscribeResponse {
+	return t.priorityResponses
+}
+
+// SetPriorityClassifier sets the function classifying the received responses as high priority.
+// It must be set before the subscriptions are started.
+func (t *Target) SetPriorityClassifier(f func(*SubscribeResponse) bool) {
+	t.priorityClassifier = f
+}
+
+// sendResponse queues the response rsp, in the high priority queue
+// if the priority classifier selects it.
+func (t *Target) sendResponse(rsp *SubscribeResponse) {
+	if t.priorityClassifier != nil && t.priorityClassifier(rsp) {
+		t.priorityResponses <- rsp
+		return
+	}
+	t.subscribeResponses <- rsp
+}
+
 func (t *Target) NumberOfOnceSubscriptions() int {
 	num := 0
 	for _, sub := range t.Subscriptions {
-		if strings.ToUpper(sub.Mode) == "ONCE" {
+		// scheduled once subscriptions run until the target is stopped
+		if strings.ToUpper(sub.Mode) == "ONCE" && sub.Schedule == nil {
 			num++
 		}
 	}
@@ -323,6 +371,19 @@ func (t *Target) StopSubscription(name string) {
 	delete(t.SubscribeClients, name)
 }
 
+// SubscriptionsState returns, for each subscription of the target,
+// whether it currently has a subscribe stream.
+func (t *Target) SubscriptionsState() map[string]bool {
+	t.m.Lock()
+	defer t.m.Unlock()
+	rs := make(map[string]bool, len(t.Subscriptions))
+	for name := range t.Subscriptions {
+		_, ok := t.SubscribeClients[name]
+		rs[name] = ok
+	}
+	return rs
+}
+
 func (t *Target) listenPolls(ctx context.Context) {
 	for {
 		select {
@@ -349,11 +410,11 @@ func (t *Target) handleStreamSubscriptionRcv(ctx context.Context, stream gnmi.GN
 		if err != nil {
 			return err
 		}
-		t.subscribeResponses <- &SubscribeResponse{
+		t.sendResponse(&SubscribeResponse{
 			SubscriptionName:   subscriptionName,
 			SubscriptionConfig: subConfig,
 			Response:           response,
-		}
+		})
 	}
 }
 
@@ -366,11 +427,11 @@ func (t *Target) handleONCESubscriptionRcv(ctx context.Context, stream gnmi.GNMI
 		if err != nil {
 			return err
 		}
-		t.subscribeResponses <- &SubscribeResponse{
+		t.sendResponse(&SubscribeResponse{
 			SubscriptionName:   subscriptionName,
 			SubscriptionConfig: subConfig,
 			Response:           response,
-		}
+		})
 		switch response.Response.(type) {
 		case *gnmi.SubscribeResponse_SyncResponse:
 			return nil
@@ -388,11 +449,11 @@ func (t *Target) handlePollSubscriptionRcv(ctx context.Context, stream gnmi.GNMI
 			if err != nil {
 				return err
 			}
-			t.subscribeResponses <- &SubscribeResponse{
+			t.sendResponse(&SubscribeResponse{
 				SubscriptionName:   subscriptionName,
 				SubscriptionConfig: subConfig,
 				Response:           response,
-			}
+			})
 		}
 	}
 }
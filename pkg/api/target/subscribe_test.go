@@ -0,0 +1,38 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"testing"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+func TestSendResponsePriority(t *testing.T) {
+	tg := NewTarget(&types.TargetConfig{Name: "t1", BufferSize: 2})
+	rspCh, _ := tg.ReadSubscriptions()
+	prioCh := tg.ReadPrioritySubscriptions()
+	tg.sendResponse(&SubscribeResponse{SubscriptionName: "alarms"})
+	if len(rspCh) != 1 || len(prioCh) != 0 {
+		t.Fatal("expected the response in the normal queue without a classifier")
+	}
+	<-rspCh
+
+	tg.SetPriorityClassifier(func(rsp *SubscribeResponse) bool {
+		return rsp.SubscriptionName == "alarms"
+	})
+	tg.sendResponse(&SubscribeResponse{SubscriptionName: "alarms"})
+	tg.sendResponse(&SubscribeResponse{SubscriptionName: "counters"})
+	if len(prioCh) != 1 {
+		t.Errorf("expected the alarms response in the priority queue")
+	}
+	if len(rspCh) != 1 {
+		t.Errorf("expected the counters response in the normal queue")
+	}
+}
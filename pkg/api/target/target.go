@@ -37,6 +37,9 @@ type SubscribeResponse struct {
 	SubscriptionName   string
 	SubscriptionConfig *types.SubscriptionConfig
 	Response           *gnmi.SubscribeResponse
+	// set if the response was built from a Get request issued
+	// after the subscription reconnected.
+	StateSync bool
 }
 
 // Target represents a gNMI enabled box
@@ -46,16 +49,26 @@ type Target struct {
 
 	m                  *sync.Mutex
 	conn               *grpc.ClientConn
+	connPool           *ConnPool
+	sharedConn         *sharedConn
+	sharedStreams      int
+	connStats          *connStats
 	Client             gnmi.GNMIClient                      `json:"-"`
 	SubscribeClients   map[string]gnmi.GNMI_SubscribeClient `json:"-"` // subscription name to subscribeClient
 	subscribeCancelFn  map[string]context.CancelFunc
 	pollChan           chan string // subscription name to be polled
 	subscribeResponses chan *SubscribeResponse
+	// high priority responses, read ahead of the subscribeResponses
+	priorityResponses  chan *SubscribeResponse
+	priorityClassifier func(*SubscribeResponse) bool
 	errors             chan *TargetError
 	stopped            bool
 	StopChan           chan struct{}      `json:"-"`
 	Cfn                context.CancelFunc `json:"-"`
 	RootDesc           desc.Descriptor    `json:"-"`
+	encoding           encodingState
+	// credentials returned by the target credentials-command, nil if not set.
+	credentials *types.CredentialsCache
 }
 
 // NewTarget //
@@ -68,19 +81,92 @@ func NewTarget(c *types.TargetConfig) *Target {
 		subscribeCancelFn:  make(map[string]context.CancelFunc),
 		pollChan:           make(chan string),
 		subscribeResponses: make(chan *SubscribeResponse, c.BufferSize),
+		priorityResponses:  make(chan *SubscribeResponse, c.BufferSize),
 		errors:             make(chan *TargetError, c.BufferSize),
 		StopChan:           make(chan struct{}),
 	}
+	if c.CredentialsCommand != nil {
+		t.credentials = types.NewCredentialsCache(c.CredentialsCommand, c.Address,
+			"GNMIC_TARGET_NAME="+c.Name,
+			"GNMIC_TARGET_ADDRESS="+c.Address,
+		)
+	}
 	return t
 }
 
+// SetConnPool sets the connection pool used to share the target gRPC connection
+// with the other targets of the pool, if the target max-streams-per-connection is set.
+func (t *Target) SetConnPool(p *ConnPool) {
+	t.connPool = p
+}
+
 // CreateGNMIClient //
 func (t *Target) CreateGNMIClient(ctx context.Context, opts ...grpc.DialOption) error {
-	tOpts, err := t.Config.GrpcDialOptions()
+	if t.connPool == nil || t.Config.MaxStreamsPerConnection <= 0 {
+		cs := new(connStats)
+		conn, err := t.dial(ctx, cs, opts...)
+		if err != nil {
+			return err
+		}
+		t.m.Lock()
+		t.connStats = cs
+		t.m.Unlock()
+		t.conn = conn
+		t.Client = gnmi.NewGNMIClient(conn)
+		return nil
+	}
+	// release the previously acquired connection, if any.
+	t.releaseSharedConn()
+	streams := t.numStreams()
+	sc, err := t.connPool.acquire(ctx, connKey(t.Config), streams, t.Config.MaxStreamsPerConnection,
+		func(ctx context.Context) (*grpc.ClientConn, *connStats, error) {
+			cs := new(connStats)
+			conn, err := t.dial(ctx, cs, opts...)
+			return conn, cs, err
+		})
 	if err != nil {
 		return err
 	}
+	t.m.Lock()
+	t.sharedConn = sc
+	t.sharedStreams = streams
+	t.connStats = sc.stats
+	t.m.Unlock()
+	t.conn = sc.conn
+	t.Client = gnmi.NewGNMIClient(sc.conn)
+	return nil
+}
+
+// numStreams returns the number of streams the target needs on its connection:
+// one per subscription, with a minimum of one for the unary RPCs.
+func (t *Target) numStreams() int {
+	return max(len(t.Subscriptions), 1)
+}
+
+func (t *Target) releaseSharedConn() error {
+	t.m.Lock()
+	sc, streams := t.sharedConn, t.sharedStreams
+	t.sharedConn = nil
+	t.sharedStreams = 0
+	t.m.Unlock()
+	if sc == nil {
+		return nil
+	}
+	return t.connPool.release(sc, streams)
+}
+
+// dial creates a gRPC connection to the first reachable target address.
+func (t *Target) dial(ctx context.Context, cs *connStats, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	tOpts, err := t.Config.GrpcDialOptions()
+	if err != nil {
+		return nil, err
+	}
+	compressor, err := t.Config.Compressor()
+	if err != nil {
+		return nil, err
+	}
 	opts = append(opts, tOpts...)
+	opts = append(opts, cs.dialOpts(compressor)...)
 	opts = append(opts, grpc.WithBlock())
 	// create a gRPC connection
 	addrs := strings.Split(t.Config.Address, ",")
@@ -118,13 +204,11 @@ func (t *Target) CreateGNMIClient(ctx context.Context, opts ...grpc.DialOption)
 		select {
 		case conn := <-connC:
 			close(done)
-			t.conn = conn
-			t.Client = gnmi.NewGNMIClient(conn)
-			return nil
+			return conn, nil
 		case err := <-errC:
 			errs = append(errs, err.Error())
 			if len(errs) == numAddrs {
-				return fmt.Errorf("%s", strings.Join(errs, ", "))
+				return nil, fmt.Errorf("%s", strings.Join(errs, ", "))
 			}
 		}
 	}
@@ -145,14 +229,19 @@ func (t *Target) createDialer(addr string) func(context.Context, string) (net.Co
 	return t.createCustomDialer(addr)
 }
 
+// createProxyDialer returns a socks5 proxy dialer,
+// the connection to the proxy uses the target source address and interface.
 func (t *Target) createProxyDialer(addr string) func(context.Context, string) (net.Conn, error) {
 	return func(context.Context, string) (net.Conn, error) {
-		dialer, err := proxy.SOCKS5("tcp", addr, nil,
-			&net.Dialer{
-				Timeout:   t.Config.Timeout,
-				KeepAlive: t.Config.TCPKeepalive,
-			},
-		)
+		fwd := &net.Dialer{
+			Timeout:   t.Config.Timeout,
+			KeepAlive: t.Config.TCPKeepalive,
+		}
+		err := t.setDialerSource(fwd)
+		if err != nil {
+			return nil, err
+		}
+		dialer, err := proxy.SOCKS5("tcp", addr, nil, fwd)
 		if err != nil {
 			return nil, err
 		}
@@ -176,11 +265,38 @@ func (t *Target) createCustomDialer(addr string) func(context.Context, string) (
 				addr = addr[indx+3:]
 			}
 		}
+		if networkType == "tcp" {
+			err := t.setDialerSource(&dialer)
+			if err != nil {
+				return nil, err
+			}
+		}
 		return dialer.DialContext(ctx, networkType, addr)
 	}
 }
 
+// setDialerSource binds the dialer to the target source address and interface, if set.
+func (t *Target) setDialerSource(dialer *net.Dialer) error {
+	if t.Config.SourceAddress != "" {
+		ip := net.ParseIP(t.Config.SourceAddress)
+		if ip == nil {
+			return fmt.Errorf("invalid source address %q", t.Config.SourceAddress)
+		}
+		dialer.LocalAddr = &net.TCPAddr{IP: ip}
+	}
+	if t.Config.SourceInterface != "" {
+		dialer.Control = bindToDevice(t.Config.SourceInterface)
+	}
+	return nil
+}
+
 func (t *Target) callOpts() []grpc.CallOption {
+	if t.credentials != nil {
+		return []grpc.CallOption{
+			grpc.PerRPCCredentials(&commandCredentials{t: t}),
+			grpc.OnFinish(t.checkCredentials),
+		}
+	}
 	if t.Config.AuthScheme == "" {
 		return nil
 	}
@@ -217,7 +333,8 @@ func (t *Target) appendRequestMetadata(ctx context.Context) context.Context {
 }
 
 func (t *Target) appendCredentials(ctx context.Context) context.Context {
-	if t.Config.AuthScheme != "" {
+	// the credentials-command credentials are set by callOpts.
+	if t.Config.AuthScheme != "" || t.credentials != nil {
 		return ctx
 	}
 
@@ -243,9 +360,11 @@ func (t *Target) Capabilities(ctx context.Context, ext ...*gnmi_ext.Extension) (
 	return t.Client.Capabilities(t.appendRequestMetadata(ctx), &gnmi.CapabilityRequest{Extension: ext}, t.callOpts()...)
 }
 
-// Get sends a gnmi.GetRequest to the target *t and returns a gnmi.GetResponse and an error
+// Get sends a gnmi.GetRequest to the target *t and returns a gnmi.GetResponse and an error.
+// If the target rejects the requested encoding, the request is retried with the target fallback encodings,
+// or with the encodings advertised in its capabilities.
 func (t *Target) Get(ctx context.Context, req *gnmi.GetRequest) (*gnmi.GetResponse, error) {
-	return t.Client.Get(t.appendRequestMetadata(ctx), req, t.callOpts()...)
+	return t.getWithEncodingFallback(ctx, req)
 }
 
 // Set sends a gnmi.SetRequest to the target *t and returns a gnmi.SetResponse and an error
@@ -270,6 +389,9 @@ func (t *Target) StopSubscriptions() {
 
 func (t *Target) Close() error {
 	t.StopSubscriptions()
+	if t.connPool != nil && t.Config.MaxStreamsPerConnection > 0 {
+		return t.releaseSharedConn()
+	}
 	if t.conn != nil {
 		return t.conn.Close()
 	}
@@ -0,0 +1,64 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package target
+
+import (
+	"context"
+	"net"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+func TestProxyDialerSourceAddress(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("requires the 127.0.0.0/8 loopback range")
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	remote := make(chan net.Addr, 2)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			remote <- conn.RemoteAddr()
+			conn.Close()
+		}
+	}()
+
+	tg := NewTarget(&types.TargetConfig{
+		Name:          "t1",
+		Timeout:       time.Second,
+		Proxy:         "socks5://" + l.Addr().String(),
+		SourceAddress: "127.0.0.2",
+	})
+	// the fake proxy closes the connection, failing the socks5 handshake
+	tg.createDialer("t1:57400")(context.Background(), "")
+	select {
+	case addr := <-remote:
+		if ip := addr.(*net.TCPAddr).IP.String(); ip != "127.0.0.2" {
+			t.Errorf("got proxy connection from %s, want 127.0.0.2", ip)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("proxy not dialed")
+	}
+
+	tg.Config.SourceAddress = "not-an-ip"
+	_, err = tg.createDialer("t1:57400")(context.Background(), "")
+	if err == nil || len(remote) != 0 {
+		t.Fatal("expected an invalid source address error")
+	}
+}
@@ -63,6 +63,23 @@ var targetTestSet = map[string]input{
 			Timeout:    DefaultTargetTimeout,
 		},
 	},
+	"source_address_interface": {
+		opts: []TargetOption{
+			Address("10.0.0.1:57400"),
+			Insecure(true),
+			SourceAddress("192.168.1.1"),
+			SourceInterface("mgmt"),
+		},
+		config: &types.TargetConfig{
+			Name:            "10.0.0.1:57400",
+			Address:         "10.0.0.1:57400",
+			Insecure:        pointer.ToBool(true),
+			SkipVerify:      pointer.ToBool(false),
+			Timeout:         DefaultTargetTimeout,
+			SourceAddress:   "192.168.1.1",
+			SourceInterface: "mgmt",
+		},
+	},
 	"skip_verify": {
 		opts: []TargetOption{
 			Address("10.0.0.1:57400"),
@@ -0,0 +1,46 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"errors"
+	"time"
+)
+
+// ClockSkew configures the handling of the target clock skew,
+// measured as the difference between the receive time and the timestamp of the notifications.
+type ClockSkew struct {
+	// skew, in absolute value, above which a notification is considered skewed.
+	Threshold time.Duration `mapstructure:"threshold,omitempty" yaml:"threshold,omitempty" json:"threshold,omitempty"`
+	// if true, the timestamp of the skewed notifications is rewritten to their receive time.
+	Correct bool `mapstructure:"correct,omitempty" yaml:"correct,omitempty" json:"correct,omitempty"`
+}
+
+// Validate checks the clock skew values.
+func (cs *ClockSkew) Validate() error {
+	if cs.Threshold < 0 {
+		return errors.New("clock-skew threshold cannot be negative")
+	}
+	if cs.Correct && cs.Threshold == 0 {
+		return errors.New("clock-skew correct requires a threshold")
+	}
+	return nil
+}
+
+// Exceeded returns true if skew, in absolute value, is above the threshold.
+// It always returns false if no threshold is set.
+func (cs *ClockSkew) Exceeded(skew time.Duration) bool {
+	if cs == nil || cs.Threshold == 0 {
+		return false
+	}
+	if skew < 0 {
+		skew = -skew
+	}
+	return skew > cs.Threshold
+}
@@ -0,0 +1,137 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultCredentialsCommandTimeout = 10 * time.Second
+
+// CredentialsCommand is an external command printing credentials
+// as a JSON object on its stdout, similar to the Docker credential helpers.
+// The command receives the server address on its stdin.
+type CredentialsCommand struct {
+	Command string   `mapstructure:"command,omitempty" yaml:"command,omitempty" json:"command,omitempty"`
+	Args    []string `mapstructure:"args,omitempty" yaml:"args,omitempty" json:"args,omitempty"`
+	// how long the credentials are cached,
+	// if 0 they are cached until they are rejected by the server.
+	TTL     time.Duration `mapstructure:"ttl,omitempty" yaml:"ttl,omitempty" json:"ttl,omitempty"`
+	Timeout time.Duration `mapstructure:"timeout,omitempty" yaml:"timeout,omitempty" json:"timeout,omitempty"`
+}
+
+// Credentials returned by a CredentialsCommand.
+type Credentials struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Token    string `json:"token,omitempty"`
+	// Docker credential helpers return the password as Secret.
+	Secret string `json:"secret,omitempty"`
+}
+
+func (c *CredentialsCommand) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.Command == "" {
+		return errors.New("credentials-command: missing command")
+	}
+	if c.TTL < 0 || c.Timeout < 0 {
+		return errors.New("credentials-command: ttl and timeout cannot be negative")
+	}
+	return nil
+}
+
+// Run executes the command with input written to its stdin
+// and env added to its environment.
+func (c *CredentialsCommand) Run(ctx context.Context, input string, env ...string) (*Credentials, error) {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = defaultCredentialsCommandTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd := exec.CommandContext(ctx, c.Command, c.Args...)
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.Env = append(os.Environ(), env...)
+	err := cmd.Run()
+	if err != nil {
+		return nil, fmt.Errorf("credentials command %q failed: %v: %s", c.Command, err, strings.TrimSpace(stderr.String()))
+	}
+	creds := new(Credentials)
+	err = json.Unmarshal(stdout.Bytes(), creds)
+	if err != nil {
+		return nil, fmt.Errorf("credentials command %q: failed to decode its output: %v", c.Command, err)
+	}
+	if creds.Password == "" {
+		creds.Password = creds.Secret
+	}
+	return creds, nil
+}
+
+// CredentialsCache runs a CredentialsCommand when its credentials
+// are not cached, expired or were invalidated.
+type CredentialsCache struct {
+	cmd   *CredentialsCommand
+	input string
+	env   []string
+
+	m      sync.Mutex
+	creds  *Credentials
+	expiry time.Time
+}
+
+func NewCredentialsCache(cmd *CredentialsCommand, input string, env ...string) *CredentialsCache {
+	return &CredentialsCache{
+		cmd:   cmd,
+		input: input,
+		env:   env,
+	}
+}
+
+// Get returns the cached credentials, running the command if needed.
+func (c *CredentialsCache) Get(ctx context.Context) (*Credentials, error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.creds != nil && (c.expiry.IsZero() || time.Now().Before(c.expiry)) {
+		return c.creds, nil
+	}
+	creds, err := c.cmd.Run(ctx, c.input, c.env...)
+	if err != nil {
+		return nil, err
+	}
+	c.creds = creds
+	c.expiry = time.Time{}
+	if c.cmd.TTL > 0 {
+		c.expiry = time.Now().Add(c.cmd.TTL)
+	}
+	return creds, nil
+}
+
+// Invalidate drops the cached credentials, typically after they were rejected,
+// so that the command runs again on the next Get.
+func (c *CredentialsCache) Invalidate() {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.creds = nil
+}
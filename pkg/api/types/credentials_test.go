@@ -0,0 +1,70 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// credentialsHelper writes a script printing the number of times it ran as password,
+// the server address read from its stdin as username and $GNMIC_TEST_TOKEN as token.
+func credentialsHelper(t *testing.T) string {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "helper.sh")
+	err := os.WriteFile(script, []byte(`#!/bin/sh
+n=$(cat "`+dir+`/count" 2>/dev/null || echo 0)
+n=$((n+1))
+echo $n > "`+dir+`/count"
+read -r addr
+echo "{\"ServerURL\":\"$addr\",\"Username\":\"$addr\",\"Secret\":\"$n\",\"token\":\"$GNMIC_TEST_TOKEN\"}"
+`), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return script
+}
+
+func TestCredentialsCache(t *testing.T) {
+	cmd := &CredentialsCommand{Command: credentialsHelper(t), TTL: 100 * time.Millisecond}
+	if err := cmd.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	c := NewCredentialsCache(cmd, "router1:57400", "GNMIC_TEST_TOKEN=tk")
+
+	get := func(wantPassword string) {
+		t.Helper()
+		creds, err := c.Get(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if creds.Username != "router1:57400" || creds.Password != wantPassword || creds.Token != "tk" {
+			t.Errorf("unexpected credentials: %+v, want password %q", creds, wantPassword)
+		}
+	}
+	get("1")
+	// cached
+	get("1")
+	c.Invalidate()
+	get("2")
+	time.Sleep(150 * time.Millisecond)
+	// expired
+	get("3")
+
+	_, err := NewCredentialsCache(&CredentialsCommand{Command: "false"}, "").Get(context.Background())
+	if err == nil {
+		t.Errorf("expected a failed command error")
+	}
+	if err := (&CredentialsCommand{TTL: time.Minute}).Validate(); err == nil {
+		t.Errorf("expected a missing command error")
+	}
+}
@@ -0,0 +1,150 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	RPCCapabilities = "capabilities"
+	RPCGet          = "get"
+	RPCSet          = "set"
+)
+
+const (
+	defaultRetryInitialBackoff    = 100 * time.Millisecond
+	defaultRetryMaxBackoff        = time.Second
+	defaultRetryBackoffMultiplier = 2
+	// gRPC caps the number of attempts to 5
+	maxRetryAttempts = 5
+)
+
+var defaultRetryableStatusCodes = []string{"UNAVAILABLE"}
+
+// RPCDeadlines sets a deadline per unary RPC,
+// a zero value means the target timeout applies.
+type RPCDeadlines struct {
+	Capabilities time.Duration `mapstructure:"capabilities,omitempty" yaml:"capabilities,omitempty" json:"capabilities,omitempty"`
+	Get          time.Duration `mapstructure:"get,omitempty" yaml:"get,omitempty" json:"get,omitempty"`
+	Set          time.Duration `mapstructure:"set,omitempty" yaml:"set,omitempty" json:"set,omitempty"`
+}
+
+// RetryPolicy configures the gRPC automatic retries of the idempotent RPCs: Capabilities and Get.
+type RetryPolicy struct {
+	MaxAttempts          int           `mapstructure:"max-attempts,omitempty" yaml:"max-attempts,omitempty" json:"max-attempts,omitempty"`
+	InitialBackoff       time.Duration `mapstructure:"initial-backoff,omitempty" yaml:"initial-backoff,omitempty" json:"initial-backoff,omitempty"`
+	MaxBackoff           time.Duration `mapstructure:"max-backoff,omitempty" yaml:"max-backoff,omitempty" json:"max-backoff,omitempty"`
+	BackoffMultiplier    float64       `mapstructure:"backoff-multiplier,omitempty" yaml:"backoff-multiplier,omitempty" json:"backoff-multiplier,omitempty"`
+	RetryableStatusCodes []string      `mapstructure:"retryable-status-codes,omitempty" yaml:"retryable-status-codes,omitempty" json:"retryable-status-codes,omitempty"`
+}
+
+// RPCTimeout returns the deadline duration of the unary RPC rpc.
+func (tc *TargetConfig) RPCTimeout(rpc string) time.Duration {
+	if tc.Deadlines == nil {
+		return tc.Timeout
+	}
+	var d time.Duration
+	switch rpc {
+	case RPCCapabilities:
+		d = tc.Deadlines.Capabilities
+	case RPCGet:
+		d = tc.Deadlines.Get
+	case RPCSet:
+		d = tc.Deadlines.Set
+	}
+	if d <= 0 {
+		return tc.Timeout
+	}
+	return d
+}
+
+// Validate checks the retry policy values and sets the defaults.
+func (rp *RetryPolicy) Validate() error {
+	if rp.MaxAttempts < 2 || rp.MaxAttempts > maxRetryAttempts {
+		return fmt.Errorf("retry-policy max-attempts must be between 2 and %d", maxRetryAttempts)
+	}
+	if rp.InitialBackoff <= 0 {
+		rp.InitialBackoff = defaultRetryInitialBackoff
+	}
+	if rp.MaxBackoff <= 0 {
+		rp.MaxBackoff = defaultRetryMaxBackoff
+	}
+	if rp.MaxBackoff < rp.InitialBackoff {
+		return fmt.Errorf("retry-policy max-backoff cannot be lower than initial-backoff")
+	}
+	if rp.BackoffMultiplier <= 0 {
+		rp.BackoffMultiplier = defaultRetryBackoffMultiplier
+	}
+	if len(rp.RetryableStatusCodes) == 0 {
+		rp.RetryableStatusCodes = defaultRetryableStatusCodes
+	}
+	for i, sc := range rp.RetryableStatusCodes {
+		name := strings.ToUpper(strings.ReplaceAll(sc, "-", "_"))
+		var c codes.Code
+		if err := c.UnmarshalJSON([]byte(`"` + name + `"`)); err != nil {
+			return fmt.Errorf("retry-policy: unknown status code %q", sc)
+		}
+		rp.RetryableStatusCodes[i] = name
+	}
+	return nil
+}
+
+type methodName struct {
+	Service string `json:"service"`
+	Method  string `json:"method"`
+}
+
+type retryPolicyConfig struct {
+	MaxAttempts          int      `json:"maxAttempts"`
+	InitialBackoff       string   `json:"initialBackoff"`
+	MaxBackoff           string   `json:"maxBackoff"`
+	BackoffMultiplier    float64  `json:"backoffMultiplier"`
+	RetryableStatusCodes []string `json:"retryableStatusCodes"`
+}
+
+type methodConfig struct {
+	Name        []methodName       `json:"name"`
+	RetryPolicy *retryPolicyConfig `json:"retryPolicy"`
+}
+
+// serviceConfig returns the gRPC service config JSON applying
+// the retry policy to the gNMI Capabilities and Get RPCs.
+func (rp *RetryPolicy) serviceConfig() (string, error) {
+	sc := map[string][]methodConfig{
+		"methodConfig": {{
+			Name: []methodName{
+				{Service: "gnmi.gNMI", Method: "Capabilities"},
+				{Service: "gnmi.gNMI", Method: "Get"},
+			},
+			RetryPolicy: &retryPolicyConfig{
+				MaxAttempts:          rp.MaxAttempts,
+				InitialBackoff:       durationSeconds(rp.InitialBackoff),
+				MaxBackoff:           durationSeconds(rp.MaxBackoff),
+				BackoffMultiplier:    rp.BackoffMultiplier,
+				RetryableStatusCodes: rp.RetryableStatusCodes,
+			},
+		}},
+	}
+	b, err := json.Marshal(sc)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// durationSeconds formats d as expected by the gRPC service config: "1.5s".
+func durationSeconds(d time.Duration) string {
+	return fmt.Sprintf("%gs", d.Seconds())
+}
@@ -0,0 +1,72 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+func TestRPCTimeout(t *testing.T) {
+	tc := &TargetConfig{Timeout: 10 * time.Second}
+	if d := tc.RPCTimeout(RPCGet); d != 10*time.Second {
+		t.Errorf("expected the target timeout, got %s", d)
+	}
+	tc.Deadlines = &RPCDeadlines{Get: time.Minute}
+	if d := tc.RPCTimeout(RPCGet); d != time.Minute {
+		t.Errorf("expected the get deadline, got %s", d)
+	}
+	if d := tc.RPCTimeout(RPCSet); d != 10*time.Second {
+		t.Errorf("expected the target timeout, got %s", d)
+	}
+}
+
+func TestRetryPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rp      *RetryPolicy
+		wantErr bool
+	}{
+		{name: "defaults", rp: &RetryPolicy{MaxAttempts: 3}},
+		{name: "codes", rp: &RetryPolicy{MaxAttempts: 2, RetryableStatusCodes: []string{"unavailable", "deadline-exceeded"}}},
+		{name: "too_few_attempts", rp: &RetryPolicy{MaxAttempts: 1}, wantErr: true},
+		{name: "too_many_attempts", rp: &RetryPolicy{MaxAttempts: 6}, wantErr: true},
+		{name: "unknown_code", rp: &RetryPolicy{MaxAttempts: 3, RetryableStatusCodes: []string{"foo"}}, wantErr: true},
+		{name: "backoffs", rp: &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Second, MaxBackoff: time.Millisecond}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rp.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err != nil {
+				return
+			}
+			insecure := true
+			tc := &TargetConfig{
+				Insecure:       &insecure,
+				MaxRecvMsgSize: 1024,
+				MaxSendMsgSize: 1024,
+				RetryPolicy:    tt.rp,
+			}
+			opts, err := tc.GrpcDialOptions()
+			if err != nil {
+				t.Fatal(err)
+			}
+			conn, err := grpc.NewClient("localhost:1", opts...)
+			if err != nil {
+				t.Fatalf("invalid dial options: %v", err)
+			}
+			conn.Close()
+		})
+	}
+}
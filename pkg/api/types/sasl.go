@@ -5,4 +5,6 @@ type SASL struct {
 	Password  string `mapstructure:"password,omitempty"`
 	Mechanism string `mapstructure:"mechanism,omitempty"`
 	TokenURL  string `mapstructure:"token-url,omitempty"`
+	// AWS region, used with mechanism AWS_MSK_IAM
+	Region string `mapstructure:"region,omitempty"`
 }
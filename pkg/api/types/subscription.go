@@ -38,7 +38,39 @@ type SubscriptionConfig struct {
 	History             *HistoryConfig        `mapstructure:"history,omitempty" json:"history,omitempty"`
 	StreamSubscriptions []*SubscriptionConfig `mapstructure:"stream-subscriptions,omitempty" json:"stream-subscriptions,omitempty"`
 	Outputs             []string              `mapstructure:"outputs,omitempty" json:"outputs,omitempty"`
+	EventProcessors     []string              `mapstructure:"event-processors,omitempty" json:"event-processors,omitempty"`
 	Depth               uint32                `mapstructure:"depth,omitempty" json:"depth,omitempty"`
+	AdaptiveSampling    *AdaptiveSampling     `mapstructure:"adaptive-sampling,omitempty" json:"adaptive-sampling,omitempty"`
+	Namespace           string                `mapstructure:"namespace,omitempty" json:"namespace,omitempty"`
+	// after a reconnection, get the current values of the on-change paths
+	// and send them as state sync responses.
+	ResyncOnReconnect bool `mapstructure:"resync-on-reconnect,omitempty" json:"resync-on-reconnect,omitempty"`
+	// periodic execution of a once or poll subscription.
+	Schedule *SubscriptionSchedule `mapstructure:"schedule,omitempty" json:"schedule,omitempty"`
+	// target selector expressions, the subscription is added to the targets
+	// matching one of them in addition to the subscriptions they list.
+	TargetSelector []string `mapstructure:"target-selector,omitempty" json:"target-selector,omitempty"`
+}
+
+// AdaptiveSampling adjusts the sample interval of each path of a subscription
+// depending on how often its values change.
+type AdaptiveSampling struct {
+	MinInterval        time.Duration `mapstructure:"min-interval,omitempty" json:"min-interval,omitempty"`
+	MaxInterval        time.Duration `mapstructure:"max-interval,omitempty" json:"max-interval,omitempty"`
+	EvaluationInterval time.Duration `mapstructure:"evaluation-interval,omitempty" json:"evaluation-interval,omitempty"`
+	// the sample interval is increased if the ratio of changed values is lower than or equal to LowChangeRatio
+	LowChangeRatio float64 `mapstructure:"low-change-ratio,omitempty" json:"low-change-ratio,omitempty"`
+	// the sample interval is decreased if the ratio of changed values is higher than or equal to HighChangeRatio
+	HighChangeRatio float64 `mapstructure:"high-change-ratio,omitempty" json:"high-change-ratio,omitempty"`
+}
+
+// SubscriptionSchedule runs a once subscription or polls a poll subscription
+// every Interval or at the times matching the Cron expression.
+type SubscriptionSchedule struct {
+	Interval time.Duration `mapstructure:"interval,omitempty" json:"interval,omitempty"`
+	Cron     string        `mapstructure:"cron,omitempty" json:"cron,omitempty"`
+	// maximum delay added to each run, spreading the targets runs.
+	Splay time.Duration `mapstructure:"splay,omitempty" json:"splay,omitempty"`
 }
 
 type HistoryConfig struct {
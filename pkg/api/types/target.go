@@ -25,6 +25,7 @@ import (
 	"google.golang.org/grpc/keepalive"
 
 	"github.com/openconfig/gnmic/pkg/api/utils"
+	"github.com/openconfig/gnmic/pkg/api/zstd"
 )
 
 // map of supported cipher suites
@@ -152,6 +153,43 @@ type TargetConfig struct {
 	CipherSuites     []string          `mapstructure:"cipher-suites,omitempty" yaml:"cipher-suites,omitempty" json:"cipher-suites,omitempty"`
 	TCPKeepalive     time.Duration     `mapstructure:"tcp-keepalive,omitempty" yaml:"tcp-keepalive,omitempty" json:"tcp-keepalive,omitempty"`
 	GRPCKeepalive    *clientKeepalive  `mapstructure:"grpc-keepalive,omitempty" yaml:"grpc-keepalive,omitempty" json:"grpc-keepalive,omitempty"`
+	SourceAddress    string            `mapstructure:"source-address,omitempty" yaml:"source-address,omitempty" json:"source-address,omitempty"`
+	SourceInterface  string            `mapstructure:"source-interface,omitempty" yaml:"source-interface,omitempty" json:"source-interface,omitempty"`
+	EncodingFallback []string          `mapstructure:"encoding-fallback,omitempty" yaml:"encoding-fallback,omitempty" json:"encoding-fallback,omitempty"`
+	// override the subscriptions suppress-redundant and heartbeat-interval values if set.
+	SuppressRedundant *bool          `mapstructure:"suppress-redundant,omitempty" yaml:"suppress-redundant,omitempty" json:"suppress-redundant,omitempty"`
+	HeartbeatInterval *time.Duration `mapstructure:"heartbeat-interval,omitempty" yaml:"heartbeat-interval,omitempty" json:"heartbeat-interval,omitempty"`
+	// master arbitration extension election ID and role, added to Set and Subscribe requests.
+	ElectionID   string `mapstructure:"election-id,omitempty" yaml:"election-id,omitempty" json:"election-id,omitempty"`
+	ElectionRole string `mapstructure:"election-role,omitempty" yaml:"election-role,omitempty" json:"election-role,omitempty"`
+	// gRPC messages size limits, in bytes.
+	MaxRecvMsgSize int `mapstructure:"max-recv-msg-size,omitempty" yaml:"max-recv-msg-size,omitempty" json:"max-recv-msg-size,omitempty"`
+	MaxSendMsgSize int `mapstructure:"max-send-msg-size,omitempty" yaml:"max-send-msg-size,omitempty" json:"max-send-msg-size,omitempty"`
+	// per RPC deadlines and automatic retries of the idempotent RPCs.
+	Deadlines   *RPCDeadlines `mapstructure:"deadlines,omitempty" yaml:"deadlines,omitempty" json:"deadlines,omitempty"`
+	RetryPolicy *RetryPolicy  `mapstructure:"retry-policy,omitempty" yaml:"retry-policy,omitempty" json:"retry-policy,omitempty"`
+	// maximum number of streams carried by a gRPC connection shared with other targets
+	// reaching the same device. Connection sharing is disabled if 0.
+	MaxStreamsPerConnection int `mapstructure:"max-streams-per-connection,omitempty" yaml:"max-streams-per-connection,omitempty" json:"max-streams-per-connection,omitempty"`
+	// gRPC compression algorithm: gzip or zstd. Takes precedence over Gzip.
+	Compression string `mapstructure:"compression,omitempty" yaml:"compression,omitempty" json:"compression,omitempty"`
+	// namespace the target belongs to, it can only use the subscriptions and outputs of the same namespace.
+	Namespace string `mapstructure:"namespace,omitempty" yaml:"namespace,omitempty" json:"namespace,omitempty"`
+	// built-in network OS profile setting the target defaults and quirks handling, e.g: sonic.
+	Profile string `mapstructure:"profile,omitempty" yaml:"profile,omitempty" json:"profile,omitempty"`
+	// names of the target groups the unset fields are inherited from,
+	// a group takes precedence over the groups listed before it.
+	Groups []string `mapstructure:"groups,omitempty" yaml:"groups,omitempty" json:"groups,omitempty"`
+	// arbitrary key/values describing the target, e.g: inventory attributes set by a loader.
+	// They are available in the subscriptions templates and the outputs target-template.
+	Vars map[string]string `mapstructure:"vars,omitempty" yaml:"vars,omitempty" json:"vars,omitempty"`
+	// if true, the target vars are added to the events as tags.
+	VarsEventTags bool `mapstructure:"vars-event-tags,omitempty" yaml:"vars-event-tags,omitempty" json:"vars-event-tags,omitempty"`
+	// detection and correction of the target clock skew.
+	ClockSkew *ClockSkew `mapstructure:"clock-skew,omitempty" yaml:"clock-skew,omitempty" json:"clock-skew,omitempty"`
+	// external command returning the target username, password and/or token,
+	// they take precedence over the configured ones.
+	CredentialsCommand *CredentialsCommand `mapstructure:"credentials-command,omitempty" yaml:"credentials-command,omitempty" json:"credentials-command,omitempty"`
 
 	tlsConfig *tls.Config
 }
@@ -167,6 +205,10 @@ func (tc TargetConfig) String() string {
 		pwd := "****"
 		tc.Password = &pwd
 	}
+	if tc.TLSKey != nil {
+		key := utils.RedactKeyURI(*tc.TLSKey)
+		tc.TLSKey = &key
+	}
 
 	b, err := json.Marshal(tc)
 	if err != nil {
@@ -237,12 +279,51 @@ func (tc *TargetConfig) NewTLSConfig() (*tls.Config, error) {
 	return tlsConfig, nil
 }
 
+// Compressor returns the name of the gRPC compressor used with the target,
+// an empty string means no compression.
+func (tc *TargetConfig) Compressor() (string, error) {
+	switch strings.ToLower(tc.Compression) {
+	case "":
+		if tc.Gzip != nil && *tc.Gzip {
+			return gzip.Name, nil
+		}
+		return "", nil
+	case "none":
+		return "", nil
+	case gzip.Name:
+		return gzip.Name, nil
+	case zstd.Name:
+		return zstd.Name, nil
+	default:
+		return "", fmt.Errorf("unknown compression %q, must be one of: gzip, zstd or none", tc.Compression)
+	}
+}
+
 // GrpcDialOptions creates the grpc.dialOption list from the target's configuration
 func (tc *TargetConfig) GrpcDialOptions() ([]grpc.DialOption, error) {
 	tOpts := make([]grpc.DialOption, 0, 1)
-	// gzip
-	if tc.Gzip != nil && *tc.Gzip {
-		tOpts = append(tOpts, grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name)))
+	// compression
+	compressor, err := tc.Compressor()
+	if err != nil {
+		return nil, err
+	}
+	if compressor != "" {
+		tOpts = append(tOpts, grpc.WithDefaultCallOptions(grpc.UseCompressor(compressor)))
+	}
+	// messages size limits
+	if tc.MaxRecvMsgSize > 0 {
+		tOpts = append(tOpts, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(tc.MaxRecvMsgSize)))
+	}
+	if tc.MaxSendMsgSize > 0 {
+		tOpts = append(tOpts, grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(tc.MaxSendMsgSize)))
+	}
+	// retry policy
+	if tc.RetryPolicy != nil {
+		sc, err := tc.RetryPolicy.serviceConfig()
+		if err != nil {
+			return nil, err
+		}
+		tOpts = append(tOpts, grpc.WithDefaultServiceConfig(sc))
 	}
 	// gRPC keepalive
 	if tc.GRPCKeepalive != nil {
@@ -267,8 +348,8 @@ func (tc *TargetConfig) GrpcDialOptions() ([]grpc.DialOption, error) {
 		return nil, err
 	}
 	tOpts = append(tOpts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
-	// token credentials
-	if tc.Token != nil && *tc.Token != "" {
+	// token credentials, set per RPC if the credentials-command is set.
+	if tc.Token != nil && *tc.Token != "" && tc.CredentialsCommand == nil {
 		tOpts = append(tOpts,
 			grpc.WithPerRPCCredentials(
 				oauth.TokenSource{
@@ -315,7 +396,7 @@ func (tc *TargetConfig) TLSKeyString() string {
 	if tc.TLSKey == nil || *tc.TLSKey == "" {
 		return notApplicable
 	}
-	return *tc.TLSKey
+	return utils.RedactKeyURI(*tc.TLSKey)
 }
 
 func (tc *TargetConfig) TLSCertString() string {
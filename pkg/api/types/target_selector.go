@@ -0,0 +1,166 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// A target selector is a list of expressions, a target matches the selector if it matches one of them.
+// An expression is a comma separated list of terms, a target matches the expression if it matches all of them:
+//
+//	key=value: the target has the tag or var key with the value, the value can contain '*' wildcards.
+//	key!=value: the target does not have the tag or var key with the value.
+//	key: the target has the tag or var key.
+//	!key: the target does not have the tag or var key.
+//
+// The target tags are either "key=value" or "key" strings.
+// selectorTerm is one of the terms of an expression.
+type selectorTerm struct {
+	key   string
+	value string
+	op    string // "=", "!=", "" or "!"
+}
+
+// TargetSelector is a parsed list of target selector expressions.
+type TargetSelector [][]selectorTerm
+
+// ParseTargetSelector parses the target selector expressions.
+func ParseTargetSelector(selector []string) (TargetSelector, error) {
+	ts := make(TargetSelector, 0, len(selector))
+	for _, expr := range selector {
+		terms, err := parseSelectorExpr(expr)
+		if err != nil {
+			return nil, err
+		}
+		ts = append(ts, terms)
+	}
+	return ts, nil
+}
+
+// Match returns true if the target tc matches one of the selector expressions.
+func (ts TargetSelector) Match(tc *TargetConfig) bool {
+	for _, terms := range ts {
+		if matchSelectorTerms(terms, tc) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTargetSelector checks the syntax of the target selector expressions.
+func ValidateTargetSelector(selector []string) error {
+	_, err := ParseTargetSelector(selector)
+	return err
+}
+
+// MatchTargetSelector returns true if the target tc matches one of the selector expressions.
+// Invalid expressions do not match any target.
+func MatchTargetSelector(selector []string, tc *TargetConfig) bool {
+	for _, expr := range selector {
+		terms, err := parseSelectorExpr(expr)
+		if err != nil {
+			continue
+		}
+		if matchSelectorTerms(terms, tc) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseSelectorExpr(expr string) ([]selectorTerm, error) {
+	items := strings.Split(expr, ",")
+	terms := make([]selectorTerm, 0, len(items))
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		var t selectorTerm
+		switch {
+		case strings.Contains(item, "!="):
+			t.key, t.value, _ = strings.Cut(item, "!=")
+			t.op = "!="
+		case strings.Contains(item, "="):
+			t.key, t.value, _ = strings.Cut(item, "=")
+			t.op = "="
+		case strings.HasPrefix(item, "!"):
+			t.key = item[1:]
+			t.op = "!"
+		default:
+			t.key = item
+		}
+		t.key = strings.TrimSpace(t.key)
+		t.value = strings.TrimSpace(t.value)
+		if t.key == "" {
+			return nil, fmt.Errorf("invalid target selector %q: missing key in %q", expr, item)
+		}
+		if _, err := path.Match(t.value, ""); err != nil {
+			return nil, fmt.Errorf("invalid target selector %q: %v", expr, err)
+		}
+		terms = append(terms, t)
+	}
+	return terms, nil
+}
+
+func matchSelectorTerms(terms []selectorTerm, tc *TargetConfig) bool {
+	for _, t := range terms {
+		switch t.op {
+		case "":
+			if !hasTargetLabel(tc, t.key) {
+				return false
+			}
+		case "!":
+			if hasTargetLabel(tc, t.key) {
+				return false
+			}
+		case "=":
+			if !matchTargetLabel(tc, t.key, t.value) {
+				return false
+			}
+		case "!=":
+			if matchTargetLabel(tc, t.key, t.value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// hasTargetLabel returns true if the target has the tag or var key.
+func hasTargetLabel(tc *TargetConfig, key string) bool {
+	if _, ok := tc.Vars[key]; ok {
+		return true
+	}
+	for _, tag := range tc.Tags {
+		if k, _, _ := strings.Cut(tag, "="); k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// matchTargetLabel returns true if the value of the target tag or var key matches pattern.
+func matchTargetLabel(tc *TargetConfig, key, pattern string) bool {
+	if v, ok := tc.Vars[key]; ok {
+		if ok, _ := path.Match(pattern, v); ok {
+			return true
+		}
+	}
+	for _, tag := range tc.Tags {
+		k, v, _ := strings.Cut(tag, "=")
+		if k != key {
+			continue
+		}
+		if ok, _ := path.Match(pattern, v); ok {
+			return true
+		}
+	}
+	return false
+}
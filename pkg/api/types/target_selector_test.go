@@ -0,0 +1,76 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package types
+
+import "testing"
+
+func TestMatchTargetSelector(t *testing.T) {
+	tc := &TargetConfig{
+		Name: "spine1",
+		Tags: []string{"role=spine", "edge"},
+		Vars: map[string]string{"site": "dc1", "vendor": "nokia"},
+	}
+	tests := []struct {
+		name     string
+		selector []string
+		want     bool
+	}{
+		{name: "empty", selector: nil, want: false},
+		{name: "tag_value", selector: []string{"role=spine"}, want: true},
+		{name: "var_value", selector: []string{"site=dc1"}, want: true},
+		{name: "wildcard", selector: []string{"site=dc*"}, want: true},
+		{name: "and", selector: []string{"role=spine,site=dc1"}, want: true},
+		{name: "and_no_match", selector: []string{"role=spine, site=dc2"}, want: false},
+		{name: "or", selector: []string{"role=leaf", "vendor=nokia"}, want: true},
+		{name: "exists", selector: []string{"edge"}, want: true},
+		{name: "exists_var", selector: []string{"vendor"}, want: true},
+		{name: "not_exists", selector: []string{"!edge"}, want: false},
+		{name: "not_exists_match", selector: []string{"!border"}, want: true},
+		{name: "not_equal", selector: []string{"role!=leaf"}, want: true},
+		{name: "not_equal_no_match", selector: []string{"role!=spine"}, want: false},
+		{name: "invalid", selector: []string{"=spine"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchTargetSelector(tt.selector, tc); got != tt.want {
+				t.Errorf("MatchTargetSelector(%v) = %v, want %v", tt.selector, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTargetSelector(t *testing.T) {
+	for _, sel := range [][]string{{"=spine"}, {"role=spine,"}, {"!"}, {"site=dc[1"}} {
+		if err := ValidateTargetSelector(sel); err == nil {
+			t.Errorf("expected an error for %v", sel)
+		}
+	}
+	if err := ValidateTargetSelector([]string{"role=spine,!edge", "site!=dc*"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestParseTargetSelector(t *testing.T) {
+	ts, err := ParseTargetSelector([]string{"env=prod,!lab", "vendor=nokia"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ts.Match(&TargetConfig{Tags: []string{"env=prod"}}) {
+		t.Errorf("expected env=prod to match")
+	}
+	if ts.Match(&TargetConfig{Tags: []string{"env=prod", "lab"}}) {
+		t.Errorf("expected lab not to match")
+	}
+	if !ts.Match(&TargetConfig{Vars: map[string]string{"vendor": "nokia"}}) {
+		t.Errorf("expected vendor=nokia to match")
+	}
+	if _, err := ParseTargetSelector([]string{"env=prod", "!"}); err == nil {
+		t.Errorf("expected an error")
+	}
+}
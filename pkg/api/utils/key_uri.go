@@ -0,0 +1,262 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"context"
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	pkcs11URIScheme = "pkcs11:"
+	tpm2URIScheme   = "tpm2:"
+)
+
+// IsKeyURI returns true if key identifies a private key stored
+// in a PKCS#11 token or a TPM2 instead of a file:
+//
+//	pkcs11:token=<label>;object=<label>?module-path=<lib>&pin-value=<pin>
+//	tpm2:<persistent handle>?device=<path>&password=<auth>
+func IsKeyURI(key string) bool {
+	return strings.HasPrefix(key, pkcs11URIScheme) || strings.HasPrefix(key, tpm2URIScheme)
+}
+
+// RedactKeyURI masks the PIN and password set in a PKCS#11 or TPM2 key URI.
+func RedactKeyURI(key string) string {
+	if !IsKeyURI(key) {
+		return key
+	}
+	p, q, ok := strings.Cut(key, "?")
+	if !ok {
+		return key
+	}
+	attrs := strings.Split(q, "&")
+	for i, attr := range attrs {
+		k, _, _ := strings.Cut(attr, "=")
+		switch k {
+		case "pin-value", "password":
+			attrs[i] = k + "=****"
+		}
+	}
+	return p + "?" + strings.Join(attrs, "&")
+}
+
+// pkcs11KeyURI is the subset of an RFC 7512 PKCS#11 URI
+// used to find a private key in a token.
+type pkcs11KeyURI struct {
+	modulePath string
+	// one of token, serial or slot selects the token.
+	token  string
+	serial string
+	slot   *int
+	// object label and/or id of the key.
+	object string
+	id     []byte
+	pin    string
+}
+
+func parsePKCS11URI(s string) (*pkcs11KeyURI, error) {
+	p, q, _ := strings.Cut(strings.TrimPrefix(s, pkcs11URIScheme), "?")
+	u := new(pkcs11KeyURI)
+	for _, attr := range strings.Split(p, ";") {
+		if attr == "" {
+			continue
+		}
+		k, v, err := uriAttribute(attr)
+		if err != nil {
+			return nil, err
+		}
+		switch k {
+		case "token":
+			u.token = v
+		case "serial":
+			u.serial = v
+		case "slot-id":
+			slot, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid slot-id %q: %v", v, err)
+			}
+			u.slot = &slot
+		case "object":
+			u.object = v
+		case "id":
+			u.id = []byte(v)
+		}
+	}
+	for _, attr := range strings.Split(q, "&") {
+		if attr == "" {
+			continue
+		}
+		k, v, err := uriAttribute(attr)
+		if err != nil {
+			return nil, err
+		}
+		switch k {
+		case "module-path":
+			u.modulePath = v
+		case "pin-value":
+			u.pin = v
+		case "pin-source":
+			b, err := os.ReadFile(strings.TrimPrefix(v, "file:"))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read pin-source: %v", err)
+			}
+			u.pin = strings.TrimSpace(string(b))
+		}
+	}
+	if u.modulePath == "" {
+		return nil, errors.New("missing module-path")
+	}
+	n := 0
+	for _, set := range []bool{u.token != "", u.serial != "", u.slot != nil} {
+		if set {
+			n++
+		}
+	}
+	if n != 1 {
+		return nil, errors.New("exactly one of token, serial or slot-id must be set")
+	}
+	if u.object == "" && len(u.id) == 0 {
+		return nil, errors.New("missing object or id")
+	}
+	return u, nil
+}
+
+// tpm2KeyURI identifies a signing key persisted in a TPM2.
+type tpm2KeyURI struct {
+	device   string
+	handle   uint32
+	password string
+}
+
+func parseTPM2URI(s string) (*tpm2KeyURI, error) {
+	p, q, _ := strings.Cut(strings.TrimPrefix(s, tpm2URIScheme), "?")
+	handle, err := strconv.ParseUint(p, 0, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid handle %q: %v", p, err)
+	}
+	// persistent objects handles range
+	if handle>>24 != 0x81 {
+		return nil, fmt.Errorf("handle %#x is not a persistent handle", handle)
+	}
+	u := &tpm2KeyURI{handle: uint32(handle)}
+	for _, attr := range strings.Split(q, "&") {
+		if attr == "" {
+			continue
+		}
+		k, v, err := uriAttribute(attr)
+		if err != nil {
+			return nil, err
+		}
+		switch k {
+		case "device":
+			u.device = v
+		case "password":
+			u.password = v
+		}
+	}
+	return u, nil
+}
+
+func uriAttribute(attr string) (string, string, error) {
+	k, v, _ := strings.Cut(attr, "=")
+	v, err := url.PathUnescape(v)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid attribute %q: %v", k, err)
+	}
+	return k, v, nil
+}
+
+// keys loaded from a PKCS#11 token or a TPM2 per URI,
+// they are kept open for the lifetime of the process.
+var uriKeys = struct {
+	sync.Mutex
+	m map[string]crypto.Signer
+}{m: make(map[string]crypto.Signer)}
+
+func loadURIKey(key string) (crypto.Signer, error) {
+	uriKeys.Lock()
+	defer uriKeys.Unlock()
+	if signer, ok := uriKeys.m[key]; ok {
+		return signer, nil
+	}
+	var signer crypto.Signer
+	var err error
+	switch {
+	case strings.HasPrefix(key, pkcs11URIScheme):
+		var u *pkcs11KeyURI
+		u, err = parsePKCS11URI(key)
+		if err != nil {
+			return nil, fmt.Errorf("pkcs11 key: %v", err)
+		}
+		signer, err = openPKCS11Key(u)
+		if err != nil {
+			return nil, fmt.Errorf("pkcs11 key: %v", err)
+		}
+	default:
+		var u *tpm2KeyURI
+		u, err = parseTPM2URI(key)
+		if err != nil {
+			return nil, fmt.Errorf("tpm2 key: %v", err)
+		}
+		signer, err = openTPM2Key(u)
+		if err != nil {
+			return nil, fmt.Errorf("tpm2 key: %v", err)
+		}
+	}
+	uriKeys.m[key] = signer
+	return signer, nil
+}
+
+// uriKeyPair builds a tls.Certificate from the PEM certificate(s) in cert
+// and the private key identified by the URI key.
+func uriKeyPair(ctx context.Context, cert, key string) (tls.Certificate, error) {
+	certificate := tls.Certificate{}
+	certBytes, err := ReadLocalFile(ctx, cert)
+	if err != nil {
+		return certificate, err
+	}
+	for {
+		var block *pem.Block
+		block, certBytes = pem.Decode(certBytes)
+		if block == nil {
+			break
+		}
+		if block.Type == "CERTIFICATE" {
+			certificate.Certificate = append(certificate.Certificate, block.Bytes)
+		}
+	}
+	if len(certificate.Certificate) == 0 {
+		return certificate, fmt.Errorf("no certificate found in %q", cert)
+	}
+	certificate.Leaf, err = x509.ParseCertificate(certificate.Certificate[0])
+	if err != nil {
+		return certificate, err
+	}
+	signer, err := loadURIKey(key)
+	if err != nil {
+		return certificate, err
+	}
+	pub, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(certificate.Leaf.PublicKey) {
+		return certificate, errors.New("private key does not match the certificate public key")
+	}
+	certificate.PrivateKey = signer
+	return certificate, nil
+}
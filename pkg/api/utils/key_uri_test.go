@@ -0,0 +1,154 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParsePKCS11URI(t *testing.T) {
+	pinFile := filepath.Join(t.TempDir(), "pin")
+	if err := os.WriteFile(pinFile, []byte("5678\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		in      string
+		want    *pkcs11KeyURI
+		wantErr bool
+	}{
+		{
+			name: "token_object",
+			in:   "pkcs11:token=gnmic;object=client%20key?module-path=/usr/lib/softhsm/libsofthsm2.so&pin-value=1234",
+			want: &pkcs11KeyURI{modulePath: "/usr/lib/softhsm/libsofthsm2.so", token: "gnmic", object: "client key", pin: "1234"},
+		},
+		{
+			name: "serial_id_pin_source",
+			in:   "pkcs11:serial=abc;id=%01?module-path=/lib/p11.so&pin-source=file:" + pinFile,
+			want: &pkcs11KeyURI{modulePath: "/lib/p11.so", serial: "abc", id: []byte{1}, pin: "5678"},
+		},
+		{
+			name:    "missing_module_path",
+			in:      "pkcs11:token=gnmic;object=client",
+			wantErr: true,
+		},
+		{
+			name:    "missing_token",
+			in:      "pkcs11:object=client?module-path=/lib/p11.so",
+			wantErr: true,
+		},
+		{
+			name:    "token_and_slot",
+			in:      "pkcs11:token=gnmic;slot-id=1;object=client?module-path=/lib/p11.so",
+			wantErr: true,
+		},
+		{
+			name:    "missing_object",
+			in:      "pkcs11:token=gnmic?module-path=/lib/p11.so",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePKCS11URI(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.modulePath != tt.want.modulePath || got.token != tt.want.token ||
+				got.serial != tt.want.serial || got.object != tt.want.object ||
+				string(got.id) != string(tt.want.id) || got.pin != tt.want.pin {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTPM2URI(t *testing.T) {
+	u, err := parseTPM2URI("tpm2:0x81000001?device=/dev/tpmrm0&password=secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.handle != 0x81000001 || u.device != "/dev/tpmrm0" || u.password != "secret" {
+		t.Errorf("unexpected tpm2 key URI: %+v", u)
+	}
+	for _, in := range []string{"tpm2:", "tpm2:key", "tpm2:0x80000001"} {
+		if _, err := parseTPM2URI(in); err == nil {
+			t.Errorf("%q: expected an error", in)
+		}
+	}
+}
+
+func TestRedactKeyURI(t *testing.T) {
+	tests := map[string]string{
+		"/path/to/key.pem":                                "/path/to/key.pem",
+		"pkcs11:token=t;object=o":                         "pkcs11:token=t;object=o",
+		"tpm2:0x81000001?password=secret":                 "tpm2:0x81000001?password=****",
+		"pkcs11:token=t?module-path=/m.so&pin-value=1234": "pkcs11:token=t?module-path=/m.so&pin-value=****",
+	}
+	for in, want := range tests {
+		if got := RedactKeyURI(in); got != want {
+			t.Errorf("RedactKeyURI(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestNewTLSConfigURIKey(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "gnmic"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile := filepath.Join(t.TempDir(), "cert.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// keys already loaded from a token are not opened again
+	uriKeys.Lock()
+	uriKeys.m["tpm2:0x81000001"] = key
+	uriKeys.m["tpm2:0x81000002"] = other
+	uriKeys.Unlock()
+
+	tlsConfig, err := NewTLSConfig("", certFile, "tpm2:0x81000001", "", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tlsConfig.Certificates) != 1 || tlsConfig.Certificates[0].PrivateKey != key {
+		t.Errorf("expected the certificate to use the URI key")
+	}
+	_, err = NewTLSConfig("", certFile, "tpm2:0x81000002", "", true, false)
+	if err == nil {
+		t.Errorf("expected a key mismatch error")
+	}
+}
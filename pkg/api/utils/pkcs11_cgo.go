@@ -0,0 +1,45 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build cgo
+
+package utils
+
+import (
+	"crypto"
+	"errors"
+
+	"github.com/ThalesIgnite/crypto11"
+)
+
+func openPKCS11Key(u *pkcs11KeyURI) (crypto.Signer, error) {
+	ctx, err := crypto11.Configure(&crypto11.Config{
+		Path:        u.modulePath,
+		TokenLabel:  u.token,
+		TokenSerial: u.serial,
+		SlotNumber:  u.slot,
+		Pin:         u.pin,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var label []byte
+	if u.object != "" {
+		label = []byte(u.object)
+	}
+	signer, err := ctx.FindKeyPair(u.id, label)
+	if err != nil {
+		ctx.Close()
+		return nil, err
+	}
+	if signer == nil {
+		ctx.Close()
+		return nil, errors.New("key not found")
+	}
+	return signer, nil
+}
@@ -29,6 +29,7 @@ import (
 // NewTLSConfig generates a *tls.Config based on given CA, certificate, key files and skipVerify flag
 // if certificate and key are missing a self signed key pair is generated.
 // The certificates paths can be local or remote, http(s) and (s)ftp are supported for remote files.
+// The key can also be a PKCS#11 or TPM2 URI, see IsKeyURI.
 func NewTLSConfig(ca, cert, key, clientAuth string, skipVerify, genSelfSigned bool) (*tls.Config, error) {
 	if !(skipVerify || ca != "" || (cert != "" && key != "")) {
 		return nil, nil
@@ -55,7 +56,15 @@ func NewTLSConfig(ca, cert, key, clientAuth string, skipVerify, genSelfSigned bo
 	default:
 		return nil, fmt.Errorf("unknown client-auth mode: %s", clientAuth)
 	}
-	if cert != "" && key != "" {
+	if cert != "" && IsKeyURI(key) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		certificate, err := uriKeyPair(ctx, cert, key)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	} else if cert != "" && key != "" {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
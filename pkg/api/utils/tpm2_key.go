@@ -0,0 +1,101 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+
+	"github.com/google/go-tpm/legacy/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+)
+
+// tpm2Signer signs with a key persisted in a TPM2,
+// the private key never leaves the TPM.
+type tpm2Signer struct {
+	m        sync.Mutex
+	rw       io.ReadWriteCloser
+	handle   tpmutil.Handle
+	password string
+	pub      crypto.PublicKey
+}
+
+func openTPM2Key(u *tpm2KeyURI) (crypto.Signer, error) {
+	var rw io.ReadWriteCloser
+	var err error
+	if u.device != "" {
+		rw, err = tpm2.OpenTPM(u.device)
+	} else {
+		rw, err = tpm2.OpenTPM()
+	}
+	if err != nil {
+		return nil, err
+	}
+	s := &tpm2Signer{
+		rw:       rw,
+		handle:   tpmutil.Handle(u.handle),
+		password: u.password,
+	}
+	pub, _, _, err := tpm2.ReadPublic(rw, s.handle)
+	if err != nil {
+		rw.Close()
+		return nil, err
+	}
+	s.pub, err = pub.Key()
+	if err != nil {
+		rw.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *tpm2Signer) Public() crypto.PublicKey {
+	return s.pub
+}
+
+func (s *tpm2Signer) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	hash, err := tpm2.HashToAlgorithm(opts.HashFunc())
+	if err != nil {
+		return nil, err
+	}
+	scheme := &tpm2.SigScheme{Hash: hash}
+	switch s.pub.(type) {
+	case *rsa.PublicKey:
+		scheme.Alg = tpm2.AlgRSASSA
+		if _, ok := opts.(*rsa.PSSOptions); ok {
+			scheme.Alg = tpm2.AlgRSAPSS
+		}
+	case *ecdsa.PublicKey:
+		scheme.Alg = tpm2.AlgECDSA
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", s.pub)
+	}
+
+	s.m.Lock()
+	sig, err := tpm2.Sign(s.rw, s.handle, s.password, digest, nil, scheme)
+	s.m.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case sig.RSA != nil:
+		return sig.RSA.Signature, nil
+	case sig.ECC != nil:
+		return asn1.Marshal(struct{ R, S *big.Int }{sig.ECC.R, sig.ECC.S})
+	default:
+		return nil, errors.New("empty signature")
+	}
+}
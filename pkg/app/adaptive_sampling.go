@@ -0,0 +1,234 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openconfig/gnmic/pkg/api/path"
+	"github.com/openconfig/gnmic/pkg/api/target"
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+// adaptiveSampler tracks how often the values received for each path
+// of a subscription change and computes the paths sample intervals accordingly.
+type adaptiveSampler struct {
+	m   sync.Mutex
+	cfg *types.AdaptiveSampling
+	// subscription paths including the subscription prefix,
+	// in the same order as the subscribe request subscriptions.
+	paths     [][]*gnmi.PathElem
+	intervals []time.Duration
+	// last value received per leaf
+	last map[string]string
+	// number of received and changed values per path since the last evaluation
+	updates []int
+	changes []int
+}
+
+func newAdaptiveSampler(sc *types.SubscriptionConfig) (*adaptiveSampler, error) {
+	prefix, err := path.ParsePath(sc.Prefix)
+	if err != nil {
+		return nil, err
+	}
+	s := &adaptiveSampler{
+		cfg:       sc.AdaptiveSampling,
+		paths:     make([][]*gnmi.PathElem, 0, len(sc.Paths)),
+		intervals: make([]time.Duration, 0, len(sc.Paths)),
+		last:      make(map[string]string),
+		updates:   make([]int, len(sc.Paths)),
+		changes:   make([]int, len(sc.Paths)),
+	}
+	for _, p := range sc.Paths {
+		gp, err := path.ParsePath(p)
+		if err != nil {
+			return nil, err
+		}
+		s.paths = append(s.paths, path.PathElems(prefix, gp))
+		s.intervals = append(s.intervals, *sc.SampleInterval)
+	}
+	return s, nil
+}
+
+// observe records the values received in notification n.
+// A value is counted as changed if it differs from the previous value received for the same leaf.
+func (s *adaptiveSampler) observe(n *gnmi.Notification) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	for _, upd := range n.GetUpdate() {
+		elems := path.PathElems(n.GetPrefix(), upd.GetPath())
+		idx := s.pathIndex(elems)
+		if idx < 0 {
+			continue
+		}
+		leaf := path.GnmiPathToXPath(&gnmi.Path{Elem: elems}, false)
+		val := upd.GetVal().String()
+		prev, ok := s.last[leaf]
+		s.last[leaf] = val
+		if !ok {
+			continue
+		}
+		s.updates[idx]++
+		if prev != val {
+			s.changes[idx]++
+		}
+	}
+}
+
+// pathIndex returns the index of the first subscription path matching elems, or -1.
+func (s *adaptiveSampler) pathIndex(elems []*gnmi.PathElem) int {
+	for i, p := range s.paths {
+		if matchPathElems(p, elems) {
+			return i
+		}
+	}
+	return -1
+}
+
+// evaluate computes the new sample interval of each path from the ratio of changed values
+// since the previous evaluation, the interval is doubled for stable paths and halved for volatile ones,
+// within the configured bounds.
+// It returns true if any of the intervals changed.
+func (s *adaptiveSampler) evaluate() bool {
+	s.m.Lock()
+	defer s.m.Unlock()
+	var changed bool
+	for i := range s.paths {
+		if s.updates[i] == 0 {
+			continue
+		}
+		ratio := float64(s.changes[i]) / float64(s.updates[i])
+		s.updates[i], s.changes[i] = 0, 0
+		next := s.intervals[i]
+		switch {
+		case ratio >= s.cfg.HighChangeRatio:
+			next = max(next/2, s.cfg.MinInterval)
+		case ratio <= s.cfg.LowChangeRatio:
+			next = min(next*2, s.cfg.MaxInterval)
+		}
+		if next != s.intervals[i] {
+			s.intervals[i] = next
+			changed = true
+		}
+	}
+	return changed
+}
+
+// request returns a copy of req with the current sample intervals.
+func (s *adaptiveSampler) request(req *gnmi.SubscribeRequest) *gnmi.SubscribeRequest {
+	s.m.Lock()
+	defer s.m.Unlock()
+	nreq := proto.Clone(req).(*gnmi.SubscribeRequest)
+	for i, sub := range nreq.GetSubscribe().GetSubscription() {
+		if i < len(s.intervals) {
+			sub.SampleInterval = uint64(s.intervals[i])
+		}
+	}
+	return nreq
+}
+
+func (s *adaptiveSampler) intervalsString() string {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return fmt.Sprint(s.intervals)
+}
+
+// matchPathElems checks if elems falls under the subscription path p.
+func matchPathElems(p, elems []*gnmi.PathElem) bool {
+	for i, pe := range p {
+		if pe.GetName() == "..." {
+			return true
+		}
+		if i >= len(elems) {
+			return false
+		}
+		if pe.GetName() != "*" && pe.GetName() != elems[i].GetName() {
+			return false
+		}
+		for k, v := range pe.GetKey() {
+			if v == "*" {
+				continue
+			}
+			if elems[i].GetKey()[k] != v {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// adaptiveSubscribe runs the subscription request sreq on target t and re-subscribes
+// with new sample intervals each time they are adapted to the values change rate.
+func (a *App) adaptiveSubscribe(ctx context.Context, t *target.Target, sreq subscriptionRequest) {
+	name, req, sc := sreq.name, sreq.req, sreq.config
+	s, err := newAdaptiveSampler(sc)
+	if err != nil {
+		a.Logger.Printf("target %q subscription %q: failed to enable adaptive sampling: %v", t.Config.Name, name, err)
+		t.Subscribe(ctx, req, name)
+		return
+	}
+	key := t.Config.Name + "/" + name
+	a.samplersLock.Lock()
+	a.samplers[key] = s
+	a.samplersLock.Unlock()
+	defer func() {
+		a.samplersLock.Lock()
+		if a.samplers[key] == s {
+			delete(a.samplers, key)
+		}
+		a.samplersLock.Unlock()
+	}()
+
+	sctx, cancel := context.WithCancel(ctx)
+	go t.Subscribe(sctx, req, name)
+	ticker := time.NewTicker(sc.AdaptiveSampling.EvaluationInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			cancel()
+			return
+		case <-ticker.C:
+			if !s.evaluate() {
+				continue
+			}
+			// cancel the current subscription before starting the new one,
+			// so that it does not retry with the previous sample intervals.
+			cancel()
+			sctx, cancel = context.WithCancel(ctx)
+			nreq := s.request(req)
+			a.Logger.Printf("target %q subscription %q: sample intervals adapted, re-subscribing: %v",
+				t.Config.Name, name, s.intervalsString())
+			go t.Subscribe(sctx, nreq, name)
+		}
+	}
+}
+
+// observeSample feeds the adaptive sampler of the target subscription, if any, with response rsp.
+func (a *App) observeSample(targetName string, rsp *target.SubscribeResponse) {
+	if rsp.SubscriptionConfig == nil || rsp.SubscriptionConfig.AdaptiveSampling == nil {
+		return
+	}
+	n := rsp.Response.GetUpdate()
+	if n == nil {
+		return
+	}
+	a.samplersLock.RLock()
+	s, ok := a.samplers[targetName+"/"+rsp.SubscriptionName]
+	a.samplersLock.RUnlock()
+	if ok {
+		s.observe(n)
+	}
+}
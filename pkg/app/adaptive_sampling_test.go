@@ -0,0 +1,130 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AlekSi/pointer"
+	"github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+func counterNotification(name string, counter, mtu uint64) *gnmi.Notification {
+	return &gnmi.Notification{
+		Prefix: &gnmi.Path{
+			Elem: []*gnmi.PathElem{{Name: "interface", Key: map[string]string{"name": name}}},
+		},
+		Update: []*gnmi.Update{
+			{
+				Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "statistics"}, {Name: "in-octets"}}},
+				Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: counter}},
+			},
+			{
+				Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "config"}, {Name: "mtu"}}},
+				Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: mtu}},
+			},
+		},
+	}
+}
+
+func TestAdaptiveSampler(t *testing.T) {
+	sc := &types.SubscriptionConfig{
+		Name:           "sub1",
+		Prefix:         "/interface[name=*]",
+		Paths:          []string{"statistics", "config"},
+		SampleInterval: pointer.ToDuration(10 * time.Second),
+		AdaptiveSampling: &types.AdaptiveSampling{
+			MinInterval:     5 * time.Second,
+			MaxInterval:     30 * time.Second,
+			LowChangeRatio:  0.1,
+			HighChangeRatio: 0.5,
+		},
+	}
+	s, err := newAdaptiveSampler(sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &gnmi.SubscribeRequest{
+		Request: &gnmi.SubscribeRequest_Subscribe{
+			Subscribe: &gnmi.SubscriptionList{
+				Subscription: []*gnmi.Subscription{
+					{Mode: gnmi.SubscriptionMode_SAMPLE, SampleInterval: uint64(10 * time.Second)},
+					{Mode: gnmi.SubscriptionMode_SAMPLE, SampleInterval: uint64(10 * time.Second)},
+				},
+			},
+		},
+	}
+	wants := [][]time.Duration{
+		{5 * time.Second, 20 * time.Second},
+		{5 * time.Second, 30 * time.Second},
+		{5 * time.Second, 30 * time.Second},
+	}
+	var counter uint64
+	for round, want := range wants {
+		for i := 0; i < 5; i++ {
+			counter += 100
+			s.observe(counterNotification("ethernet-1/1", counter, 1500))
+		}
+		changed := s.evaluate()
+		if changed != (round < 2) {
+			t.Errorf("round %d: unexpected evaluate result: %v", round, changed)
+		}
+		for i, sub := range s.request(req).GetSubscribe().GetSubscription() {
+			if got := time.Duration(sub.GetSampleInterval()); got != want[i] {
+				t.Errorf("round %d: path %d: got interval %s, want %s", round, i, got, want[i])
+			}
+		}
+	}
+	// the original request is not modified
+	if req.GetSubscribe().GetSubscription()[0].GetSampleInterval() != uint64(10*time.Second) {
+		t.Errorf("original request modified")
+	}
+	// no values received: intervals are kept
+	if s.evaluate() {
+		t.Errorf("unexpected intervals change without updates")
+	}
+}
+
+func TestMatchPathElems(t *testing.T) {
+	elems := []*gnmi.PathElem{
+		{Name: "interface", Key: map[string]string{"name": "ethernet-1/1"}},
+		{Name: "statistics"},
+		{Name: "in-octets"},
+	}
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "interface", want: true},
+		{path: "interface[name=ethernet-1/1]/statistics", want: true},
+		{path: "interface[name=*]/statistics", want: true},
+		{path: "interface[name=ethernet-1/2]/statistics", want: false},
+		{path: "*/statistics", want: true},
+		{path: "interface/.../in-octets", want: true},
+		{path: "interface/config", want: false},
+		{path: "interface/statistics/in-octets/extra", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			s, err := newAdaptiveSampler(&types.SubscriptionConfig{
+				Paths:          []string{tt.path},
+				SampleInterval: pointer.ToDuration(time.Second),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := matchPathElems(s.paths[0], elems); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/alerts"
+	"github.com/openconfig/gnmic/pkg/silences"
+)
+
+const (
+	alertStateActive     = "active"
+	alertStateSuppressed = "suppressed"
+)
+
+// gettableAlert is an alert as returned by the Alertmanager API v2.
+type gettableAlert struct {
+	*alerts.Alert
+	Fingerprint string        `json:"fingerprint"`
+	UpdatedAt   time.Time     `json:"updatedAt"`
+	Receivers   []alertTarget `json:"receivers"`
+	Status      alertStatus   `json:"status"`
+}
+
+type alertTarget struct {
+	Name string `json:"name"`
+}
+
+type alertStatus struct {
+	State       string   `json:"state"`
+	SilencedBy  []string `json:"silencedBy"`
+	InhibitedBy []string `json:"inhibitedBy"`
+}
+
+type alertGroup struct {
+	Labels   map[string]string `json:"labels"`
+	Receiver alertTarget       `json:"receiver"`
+	Alerts   []*gettableAlert  `json:"alerts"`
+}
+
+// alertsFilter selects the alerts returned by the API,
+// it is read from the Alertmanager API v2 query parameters.
+type alertsFilter struct {
+	active   bool
+	silenced bool
+	matchers *silences.Silence
+	// scope of the request, the namespaced requests only get
+	// the alerts raised by a target of their namespace.
+	scope apiScope
+}
+
+func parseAlertsFilter(r *http.Request) (*alertsFilter, error) {
+	q := r.URL.Query()
+	f := &alertsFilter{
+		active:   true,
+		silenced: true,
+		matchers: new(silences.Silence),
+		scope:    requestScope(r),
+	}
+	var err error
+	if v := q.Get("active"); v != "" {
+		f.active, err = strconv.ParseBool(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if v := q.Get("silenced"); v != "" {
+		f.silenced, err = strconv.ParseBool(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, v := range q["filter"] {
+		v = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(v), "{"), "}")
+		m, err := silences.ParseMatcher(v)
+		if err != nil {
+			return nil, err
+		}
+		f.matchers.Matchers = append(f.matchers.Matchers, m)
+	}
+	return f, nil
+}
+
+// alertsList returns the unresolved alerts selected by f,
+// the alerts matching an active silence are suppressed.
+func (a *App) alertsList(f *alertsFilter) []*gettableAlert {
+	as := a.alerts.Active()
+	rs := make([]*gettableAlert, 0, len(as))
+	for _, al := range as {
+		if !f.matchers.Matches(al.Labels, nil) || !a.alertVisible(f.scope, al) {
+			continue
+		}
+		ga := &gettableAlert{
+			Alert:       al,
+			Fingerprint: al.Fingerprint(),
+			UpdatedAt:   al.UpdatedAt,
+			Receivers:   []alertTarget{{Name: al.Receiver}},
+			Status: alertStatus{
+				State:       alertStateActive,
+				SilencedBy:  []string{},
+				InhibitedBy: []string{},
+			},
+		}
+		if s := a.silences.Match(al.Labels, nil); s != nil {
+			ga.Status.State = alertStateSuppressed
+			ga.Status.SilencedBy = append(ga.Status.SilencedBy, s.ID)
+		}
+		if ga.Status.State == alertStateActive && !f.active ||
+			ga.Status.State == alertStateSuppressed && !f.silenced {
+			continue
+		}
+		rs = append(rs, ga)
+	}
+	return rs
+}
+
+// alertVisible returns true if the alert source is a target
+// of a namespace the scope s has access to.
+func (a *App) alertVisible(s apiScope, al *alerts.Alert) bool {
+	if s.all {
+		return true
+	}
+	a.configLock.RLock()
+	defer a.configLock.RUnlock()
+	tc, ok := a.Config.Targets[al.Labels["source"]]
+	return ok && s.allows(tc.Namespace)
+}
+
+// alertsGroups groups the alerts by receiver and grouping labels.
+func alertsGroups(as []*gettableAlert) []*alertGroup {
+	groups := make(map[string]*alertGroup)
+	keys := make([]string, 0)
+	for _, ga := range as {
+		gl := ga.GroupLabels()
+		names := make([]string, 0, len(gl))
+		for k := range gl {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		var key strings.Builder
+		key.WriteString(ga.Receiver)
+		for _, k := range names {
+			key.WriteString("\xff" + k + "=" + gl[k])
+		}
+		g, ok := groups[key.String()]
+		if !ok {
+			g = &alertGroup{Labels: gl, Receiver: alertTarget{Name: ga.Receiver}}
+			groups[key.String()] = g
+			keys = append(keys, key.String())
+		}
+		g.Alerts = append(g.Alerts, ga)
+	}
+	sort.Strings(keys)
+	rs := make([]*alertGroup, 0, len(keys))
+	for _, k := range keys {
+		rs = append(rs, groups[k])
+	}
+	return rs
+}
+
+func (a *App) handleAlertsGet(w http.ResponseWriter, r *http.Request) {
+	f, err := parseAlertsFilter(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	a.handlerCommonGet(w, a.alertsList(f))
+}
+
+func (a *App) handleAlertGroupsGet(w http.ResponseWriter, r *http.Request) {
+	f, err := parseAlertsFilter(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	a.handlerCommonGet(w, alertsGroups(a.alertsList(f)))
+}
@@ -0,0 +1,185 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/alerts"
+	"github.com/openconfig/gnmic/pkg/silences"
+)
+
+func newAlertsApp(t *testing.T) *App {
+	a := New()
+	a.alerts = alerts.NewStore()
+	a.silences = silences.NewStore()
+	now := time.Now()
+	for _, src := range []string{"r1", "r2", "r3"} {
+		a.alerts.Add(&alerts.Alert{
+			Labels:   map[string]string{alerts.AlertNameLabel: "HighCPU", "source": src, "cpu": "0"},
+			EndsAt:   now.Add(time.Hour),
+			Receiver: "high_cpu",
+			GroupBy:  []string{"source"},
+		})
+	}
+	// resolved
+	a.alerts.Add(&alerts.Alert{
+		Labels:   map[string]string{alerts.AlertNameLabel: "HighCPU", "source": "r4"},
+		StartsAt: now.Add(-time.Hour),
+		EndsAt:   now.Add(-time.Minute),
+	})
+	err := a.silences.Set(&silences.Silence{
+		ID:       "s1",
+		Matchers: []*silences.Matcher{{Name: "source", Value: "r2"}},
+		EndsAt:   now.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.routes()
+	return a
+}
+
+func alertsRequest(a *App, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	w := httptest.NewRecorder()
+	a.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAlertsGet(t *testing.T) {
+	a := newAlertsApp(t)
+	for name, tc := range map[string]struct {
+		query   url.Values
+		sources []string
+	}{
+		"all":          {nil, []string{"r1", "r2", "r3"}},
+		"not_silenced": {url.Values{"silenced": {"false"}}, []string{"r1", "r3"}},
+		"silenced":     {url.Values{"active": {"false"}}, []string{"r2"}},
+		"filter":       {url.Values{"filter": {`source=~"r[12]"`, `alertname="HighCPU"`}}, []string{"r1", "r2"}},
+		"filter_brace": {url.Values{"filter": {`{source!="r1"}`}}, []string{"r2", "r3"}},
+	} {
+		w := alertsRequest(a, "/api/v2/alerts?"+tc.query.Encode())
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: unexpected status %d: %s", name, w.Code, w.Body.String())
+			continue
+		}
+		var as []*gettableAlert
+		err := json.Unmarshal(w.Body.Bytes(), &as)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make(map[string]*gettableAlert)
+		for _, ga := range as {
+			got[ga.Labels["source"]] = ga
+		}
+		if len(got) != len(tc.sources) {
+			t.Errorf("%s: got %d alerts, want %v", name, len(as), tc.sources)
+			continue
+		}
+		for _, src := range tc.sources {
+			ga, ok := got[src]
+			if !ok {
+				t.Errorf("%s: missing alert for %s", name, src)
+				continue
+			}
+			wantState := alertStateActive
+			if src == "r2" {
+				wantState = alertStateSuppressed
+			}
+			if ga.Status.State != wantState || ga.Fingerprint == "" {
+				t.Errorf("%s: unexpected alert %s: %+v", name, src, ga)
+			}
+		}
+	}
+	w := alertsRequest(a, "/api/v2/alerts?filter=source")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an invalid filter, got %d", w.Code)
+	}
+}
+
+func TestAlertGroupsGet(t *testing.T) {
+	a := newAlertsApp(t)
+	w := alertsRequest(a, "/api/v2/alerts/groups")
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", w.Code, w.Body.String())
+	}
+	var gs []*alertGroup
+	err := json.Unmarshal(w.Body.Bytes(), &gs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gs) != 3 {
+		t.Fatalf("got %d groups, want 3: %s", len(gs), w.Body.String())
+	}
+	for i, src := range []string{"r1", "r2", "r3"} {
+		g := gs[i]
+		if g.Receiver.Name != "high_cpu" || g.Labels["source"] != src || len(g.Labels) != 2 || len(g.Alerts) != 1 {
+			t.Errorf("unexpected group %d: %+v", i, g)
+		}
+	}
+}
+
+func TestAlertsGetNamespaces(t *testing.T) {
+	a := newNamespacedApp()
+	a.alerts = alerts.NewStore()
+	a.silences = silences.NewStore()
+	for _, src := range []string{"t1", "t2", "unknown"} {
+		a.alerts.Add(&alerts.Alert{
+			Labels:   map[string]string{alerts.AlertNameLabel: "HighCPU", "source": src},
+			EndsAt:   time.Now().Add(time.Hour),
+			Receiver: "high_cpu",
+			GroupBy:  []string{"source"},
+		})
+	}
+	for _, tc := range []struct {
+		token   string
+		sources []string
+	}{
+		{"admin", []string{"t1", "t2", "unknown"}},
+		{"tk1", []string{"t1"}},
+		{"tk2", []string{"t2"}},
+	} {
+		w := apiRequest(a, http.MethodGet, "/api/v2/alerts", tc.token, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: unexpected status %d: %s", tc.token, w.Code, w.Body.String())
+		}
+		var as []*gettableAlert
+		err := json.Unmarshal(w.Body.Bytes(), &as)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make(map[string]bool)
+		for _, ga := range as {
+			got[ga.Labels["source"]] = true
+		}
+		if len(got) != len(tc.sources) {
+			t.Errorf("%s: got %s, want %v", tc.token, w.Body.String(), tc.sources)
+		}
+		for _, src := range tc.sources {
+			if !got[src] {
+				t.Errorf("%s: missing alert for %s", tc.token, src)
+			}
+		}
+		w = apiRequest(a, http.MethodGet, "/api/v2/alerts/groups", tc.token, "")
+		var gs []*alertGroup
+		err = json.Unmarshal(w.Body.Bytes(), &gs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(gs) != len(tc.sources) {
+			t.Errorf("%s: got %d groups, want %d: %s", tc.token, len(gs), len(tc.sources), w.Body.String())
+		}
+	}
+}
@@ -12,9 +12,11 @@ import (
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"strings"
 
 	"github.com/gorilla/handlers"
@@ -22,15 +24,47 @@ import (
 	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
+	"github.com/openconfig/gnmic/pkg/api/target"
 	"github.com/openconfig/gnmic/pkg/api/types"
 	"github.com/openconfig/gnmic/pkg/api/utils"
+	"github.com/openconfig/gnmic/pkg/cert"
+	"github.com/openconfig/gnmic/pkg/config"
+	"github.com/openconfig/gnmic/pkg/inputs"
+	"github.com/openconfig/gnmic/pkg/outputs"
 )
 
+// errCredentialsCommand is returned when a configuration sent through the API sets
+// a credentials-command while the api-server allow-credentials-command is not set.
+var errCredentialsCommand = errors.New("credentials-command is not allowed through the API, see api-server allow-credentials-command")
+
+// credentialsCommandAllowed returns true if the targets and outputs
+// configurations sent through the API can set a credentials-command.
+func (a *App) credentialsCommandAllowed() bool {
+	return a.Config.APIServer != nil && a.Config.APIServer.AllowCredentialsCommand
+}
+
 func (a *App) newAPIServer() (*http.Server, error) {
 	a.routes()
 	var tlscfg *tls.Config
 	var err error
-	if a.Config.APIServer.TLS != nil {
+	if a.apiServerUsesSpiffe() {
+		tlscfg, err = a.spiffeServerTLSConfig(a.ctx, a.Config.Spiffe.APIServer)
+		if err != nil {
+			return nil, err
+		}
+	} else if a.Config.APIServer.ACME != nil {
+		am := cert.NewACME(a.Config.APIServer.ACME, a.Logger)
+		err = am.Start(a.ctx)
+		if err != nil {
+			return nil, err
+		}
+		tlscfg = am.TLSConfig()
+	} else if a.apiServerUsesCert() {
+		tlscfg, err = a.certServerTLSConfig(certAPIServer, a.Config.Cert.APIServer, a.Config.APIServer.Address)
+		if err != nil {
+			return nil, err
+		}
+	} else if a.Config.APIServer.TLS != nil {
 		tlscfg, err = utils.NewTLSConfig(
 			a.Config.APIServer.TLS.CaFile,
 			a.Config.APIServer.TLS.CertFile,
@@ -49,6 +83,7 @@ func (a *App) newAPIServer() (*http.Server, error) {
 		a.reg.MustRegister(collectors.NewGoCollector())
 		a.reg.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
 		a.reg.MustRegister(subscribeResponseReceivedCounter)
+		a.registerStatsMetrics()
 		go a.startClusterMetrics()
 	}
 	s := &http.Server{
@@ -73,17 +108,24 @@ func (a *App) handleConfigTargetsGet(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 	var err error
+	scope := requestScope(r)
 	a.configLock.RLock()
 	defer a.configLock.RUnlock()
 	if id == "" {
-		err = json.NewEncoder(w).Encode(a.Config.Targets)
+		tcs := make(map[string]*types.TargetConfig, len(a.Config.Targets))
+		for n, tc := range a.Config.Targets {
+			if scope.allows(tc.Namespace) {
+				tcs[n] = tc
+			}
+		}
+		err = json.NewEncoder(w).Encode(tcs)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
 			json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
 		}
 		return
 	}
-	if t, ok := a.Config.Targets[id]; ok {
+	if t, ok := a.Config.Targets[id]; ok && scope.allows(t.Namespace) {
 		err = json.NewEncoder(w).Encode(t)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
@@ -110,13 +152,41 @@ func (a *App) handleConfigTargetsPost(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
 		return
 	}
+	if tc.CredentialsCommand != nil && !a.credentialsCommandAllowed() {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{errCredentialsCommand.Error()}})
+		return
+	}
+	scope := requestScope(r)
+	if !scope.all {
+		if tc.Namespace != "" && tc.Namespace != scope.namespace {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(APIErrors{Errors: []string{fmt.Sprintf("namespace %q not allowed", tc.Namespace)}})
+			return
+		}
+		tc.Namespace = scope.namespace
+	}
+	a.configLock.RLock()
+	err = a.Config.ValidateTargetNamespace(tc)
+	if err == nil {
+		// a target of another namespace cannot be overwritten
+		if etc, ok := a.Config.Targets[tc.Name]; ok && !scope.allows(etc.Namespace) {
+			err = fmt.Errorf("target %q already exists", tc.Name)
+		}
+	}
+	a.configLock.RUnlock()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
 	a.AddTargetConfig(tc)
 }
 
 func (a *App) handleConfigTargetsSubscriptions(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
-	if !a.targetConfigExists(id) {
+	if !a.targetVisible(r, id) {
 		w.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(w).Encode(APIErrors{Errors: []string{fmt.Sprintf("target %q not found", id)}})
 		return
@@ -142,6 +212,20 @@ func (a *App) handleConfigTargetsSubscriptions(w http.ResponseWriter, r *http.Re
 		json.NewEncoder(w).Encode(APIErrors{Errors: []string{"subscriptions not found"}})
 		return
 	}
+	a.configLock.RLock()
+	ns := a.Config.Targets[id].Namespace
+	for _, sub := range subs {
+		if sc, ok := a.Config.Subscriptions[sub]; ok && sc.Namespace != ns {
+			err = fmt.Errorf("subscription %q does not belong to namespace %q", sub, ns)
+			break
+		}
+	}
+	a.configLock.RUnlock()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
 	err = a.UpdateTargetSubscription(a.ctx, id, subs)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
@@ -153,6 +237,11 @@ func (a *App) handleConfigTargetsSubscriptions(w http.ResponseWriter, r *http.Re
 func (a *App) handleConfigTargetsDelete(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
+	if !a.targetVisible(r, id) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{fmt.Sprintf("target %q not found", id)}})
+		return
+	}
 	err := a.DeleteTarget(r.Context(), id)
 	if err != nil {
 		w.WriteHeader(http.StatusNotFound)
@@ -162,11 +251,159 @@ func (a *App) handleConfigTargetsDelete(w http.ResponseWriter, r *http.Request)
 }
 
 func (a *App) handleConfigSubscriptions(w http.ResponseWriter, r *http.Request) {
-	a.handlerCommonGet(w, a.Config.Subscriptions)
+	scope := requestScope(r)
+	if scope.all {
+		a.handlerCommonGet(w, a.Config.Subscriptions)
+		return
+	}
+	a.configLock.RLock()
+	subs := make(map[string]*types.SubscriptionConfig)
+	for n, sc := range a.Config.Subscriptions {
+		if scope.allows(sc.Namespace) {
+			subs[n] = sc
+		}
+	}
+	a.configLock.RUnlock()
+	a.handlerCommonGet(w, subs)
 }
 
 func (a *App) handleConfigOutputs(w http.ResponseWriter, r *http.Request) {
-	a.handlerCommonGet(w, a.Config.Outputs)
+	scope := requestScope(r)
+	if scope.all {
+		a.handlerCommonGet(w, a.Config.Outputs)
+		return
+	}
+	a.configLock.RLock()
+	outs := make(map[string]map[string]interface{})
+	for n, outCfg := range a.Config.Outputs {
+		if scope.allows(config.OutputNamespace(outCfg)) {
+			outs[n] = outCfg
+		}
+	}
+	a.configLock.RUnlock()
+	a.handlerCommonGet(w, outs)
+}
+
+// handleConfigOutputsPost adds and starts the outputs in the request body,
+// a map of output names to output configurations.
+func (a *App) handleConfigOutputsPost(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	defer r.Body.Close()
+	outs := make(map[string]map[string]interface{})
+	err = json.Unmarshal(body, &outs)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	if len(outs) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{"no outputs found"}})
+		return
+	}
+	scope := requestScope(r)
+	// outputs forwarding messages to other outputs are started after them.
+	names := make([]string, 0, len(outs))
+	composite := make([]string, 0)
+	a.configLock.RLock()
+	for name, cfg := range outs {
+		if cfg == nil {
+			cfg = make(map[string]interface{})
+			outs[name] = cfg
+		}
+		if !scope.all {
+			if ns := config.OutputNamespace(cfg); ns != "" && ns != scope.namespace {
+				err = fmt.Errorf("output %q: namespace %q not allowed", name, ns)
+				break
+			}
+			cfg["namespace"] = scope.namespace
+		}
+		if _, ok := cfg["credentials-command"]; ok && !a.credentialsCommandAllowed() {
+			err = fmt.Errorf("output %q: %w", name, errCredentialsCommand)
+			break
+		}
+		if _, ok := a.Config.Outputs[name]; ok {
+			err = fmt.Errorf("output %q already exists", name)
+			break
+		}
+		err = a.Config.ValidateOutputConfig(name, cfg)
+		if err != nil {
+			break
+		}
+		if len(outputs.Members(cfg)) > 0 {
+			composite = append(composite, name)
+			continue
+		}
+		names = append(names, name)
+	}
+	a.configLock.RUnlock()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	sort.Strings(names)
+	sort.Strings(composite)
+	started := make([]string, 0, len(outs))
+	for _, name := range append(names, composite...) {
+		err = a.AddOutputConfig(name, outs[name])
+		if err == nil {
+			err = a.StartOutput(a.ctx, name)
+			if err != nil {
+				a.DeleteOutputConfig(name)
+			}
+		}
+		if err != nil {
+			a.rollbackOutputs(started)
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+			return
+		}
+		started = append(started, name)
+	}
+}
+
+// rollbackOutputs stops and removes the outputs started by a failed batch,
+// in the reverse order, so that the composite outputs are removed before their members.
+func (a *App) rollbackOutputs(names []string) {
+	for i := len(names) - 1; i >= 0; i-- {
+		err := a.DeleteOutput(names[i])
+		if err != nil {
+			a.Logger.Printf("failed to roll back output %q: %v", names[i], err)
+		}
+		a.DeleteOutputConfig(names[i])
+	}
+}
+
+// handleConfigOutputsDelete stops the output {id} once its in-flight writes are completed
+// and removes its configuration.
+func (a *App) handleConfigOutputsDelete(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	a.configLock.RLock()
+	outCfg, ok := a.Config.Outputs[id]
+	a.configLock.RUnlock()
+	if !ok || !requestScope(r).allows(config.OutputNamespace(outCfg)) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{fmt.Sprintf("output %q not found", id)}})
+		return
+	}
+	a.operLock.RLock()
+	_, running := a.Outputs[id]
+	a.operLock.RUnlock()
+	if running {
+		err := a.DeleteOutput(id)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+			return
+		}
+	}
+	a.DeleteOutputConfig(id)
 }
 
 func (a *App) handleConfigClustering(w http.ResponseWriter, r *http.Request) {
@@ -196,11 +433,24 @@ func (a *App) handleConfig(w http.ResponseWriter, r *http.Request) {
 func (a *App) handleTargetsGet(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
+	scope := requestScope(r)
 	if id == "" {
-		a.handlerCommonGet(w, a.Targets)
+		if scope.all {
+			a.handlerCommonGet(w, a.Targets)
+			return
+		}
+		a.operLock.RLock()
+		ts := make(map[string]*target.Target)
+		for n, t := range a.Targets {
+			if scope.allows(t.Config.Namespace) {
+				ts[n] = t
+			}
+		}
+		a.operLock.RUnlock()
+		a.handlerCommonGet(w, ts)
 		return
 	}
-	if t, ok := a.Targets[id]; ok {
+	if t, ok := a.Targets[id]; ok && scope.allows(t.Config.Namespace) {
 		a.handlerCommonGet(w, t)
 		return
 	}
@@ -216,7 +466,7 @@ func (a *App) handleTargetsPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	tc, ok := a.Config.Targets[id]
-	if !ok {
+	if !ok || !requestScope(r).allows(tc.Namespace) {
 		w.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(w).Encode(APIErrors{Errors: []string{fmt.Sprintf("target %q not found", id)}})
 		return
@@ -231,7 +481,7 @@ func (a *App) handleTargetsDelete(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	if _, ok := a.Targets[id]; !ok {
+	if t, ok := a.Targets[id]; !ok || !requestScope(r).allows(t.Config.Namespace) {
 		w.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(w).Encode(APIErrors{Errors: []string{fmt.Sprintf("target %q not found", id)}})
 		return
@@ -244,6 +494,97 @@ func (a *App) handleTargetsDelete(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (a *App) handleTargetsSubscriptionsPollPost(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	name := vars["name"]
+	a.operLock.RLock()
+	t, ok := a.Targets[id]
+	a.operLock.RUnlock()
+	if !ok || !requestScope(r).allows(t.Config.Namespace) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{fmt.Sprintf("target %q not found", id)}})
+		return
+	}
+	sub, ok := t.Subscriptions[name]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{fmt.Sprintf("subscription %q not found", name)}})
+		return
+	}
+	if strings.ToUpper(sub.Mode) != subscriptionModePOLL {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{fmt.Sprintf("subscription %q is not a poll subscription", name)}})
+		return
+	}
+	err := a.clientSubscribePoll(r.Context(), id, name)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+}
+
+func (a *App) offsetsController(w http.ResponseWriter, r *http.Request) (inputs.OffsetsController, bool) {
+	id := mux.Vars(r)["id"]
+	a.operLock.RLock()
+	in, ok := a.Inputs[id]
+	a.operLock.RUnlock()
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{fmt.Sprintf("input %q not found", id)}})
+		return nil, false
+	}
+	oc, ok := in.(inputs.OffsetsController)
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{fmt.Sprintf("input %q does not support offsets", id)}})
+		return nil, false
+	}
+	return oc, true
+}
+
+func (a *App) handleInputsOffsetsGet(w http.ResponseWriter, r *http.Request) {
+	oc, ok := a.offsetsController(w, r)
+	if !ok {
+		return
+	}
+	offsets, err := oc.Offsets()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	a.handlerCommonGet(w, offsets)
+}
+
+func (a *App) handleInputsOffsetsPost(w http.ResponseWriter, r *http.Request) {
+	oc, ok := a.offsetsController(w, r)
+	if !ok {
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	defer r.Body.Close()
+	seek := new(inputs.OffsetSeek)
+	err = json.Unmarshal(body, seek)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	err = oc.SeekOffsets(seek)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+}
+
 type clusteringResponse struct {
 	ClusterName           string          `json:"name,omitempty"`
 	NumberOfLockedTargets int             `json:"number-of-locked-targets"`
@@ -278,15 +619,13 @@ func (a *App) handleClusteringGet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	resp.Leader = leader[leaderKey]
-	lockedNodesPrefix := fmt.Sprintf("gnmic/%s/targets", a.Config.ClusterName)
-
-	lockedNodes, err := a.locker.List(a.ctx, lockedNodesPrefix)
+	instanceNodes, numLockedNodes, err := a.getInstancesTargets(ctx)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
 		return
 	}
-	resp.NumberOfLockedTargets = len(lockedNodes)
+	resp.NumberOfLockedTargets = numLockedNodes
 	services, err := a.locker.GetServices(ctx, fmt.Sprintf("%s-gnmic-api", a.Config.ClusterName), nil)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -294,14 +633,6 @@ func (a *App) handleClusteringGet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	instanceNodes := make(map[string][]string)
-	for k, v := range lockedNodes {
-		name := strings.TrimPrefix(k, fmt.Sprintf("gnmic/%s/targets/", a.Config.ClusterName))
-		if _, ok := instanceNodes[v]; !ok {
-			instanceNodes[v] = make([]string, 0)
-		}
-		instanceNodes[v] = append(instanceNodes[v], name)
-	}
 	resp.Members = make([]clusterMember, len(services))
 	for i, s := range services {
 		resp.Members[i].APIEndpoint = s.Address
@@ -345,9 +676,8 @@ func (a *App) handleClusteringMembersGet(w http.ResponseWriter, r *http.Request)
 		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
 		return
 	}
-	// get locked targets to instance mapping
-	lockedNodesPrefix := fmt.Sprintf("gnmic/%s/targets", a.Config.ClusterName)
-	lockedNodes, err := a.locker.List(a.ctx, lockedNodesPrefix)
+	// get instance to locked targets mapping
+	instanceNodes, _, err := a.getInstancesTargets(ctx)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
@@ -361,14 +691,6 @@ func (a *App) handleClusteringMembersGet(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	instanceNodes := make(map[string][]string)
-	for k, v := range lockedNodes {
-		name := strings.TrimPrefix(k, fmt.Sprintf("gnmic/%s/targets/", a.Config.ClusterName))
-		if _, ok := instanceNodes[v]; !ok {
-			instanceNodes[v] = make([]string, 0)
-		}
-		instanceNodes[v] = append(instanceNodes[v], name)
-	}
 	members := make([]clusterMember, len(services))
 	for i, s := range services {
 		scheme := "http://"
@@ -407,9 +729,8 @@ func (a *App) handleClusteringLeaderGet(w http.ResponseWriter, r *http.Request)
 		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
 		return
 	}
-	// get locked targets to instance mapping
-	lockedNodesPrefix := fmt.Sprintf("gnmic/%s/targets", a.Config.ClusterName)
-	lockedNodes, err := a.locker.List(a.ctx, lockedNodesPrefix)
+	// get instance to locked targets mapping
+	instanceNodes, _, err := a.getInstancesTargets(ctx)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
@@ -423,14 +744,6 @@ func (a *App) handleClusteringLeaderGet(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	instanceNodes := make(map[string][]string)
-	for k, v := range lockedNodes {
-		name := strings.TrimPrefix(k, fmt.Sprintf("gnmic/%s/targets/", a.Config.ClusterName))
-		if _, ok := instanceNodes[v]; !ok {
-			instanceNodes[v] = make([]string, 0)
-		}
-		instanceNodes[v] = append(instanceNodes[v], name)
-	}
 	members := make([]clusterMember, 1)
 	for _, s := range services {
 		if strings.TrimSuffix(s.ID, "-api") != leader[leaderKey] {
@@ -461,7 +774,10 @@ func (a *App) handleClusteringLeaderGet(w http.ResponseWriter, r *http.Request)
 
 func headersMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Add("Content-Type", "application/json")
+		// the UI files content type is set by the file server
+		if !strings.HasPrefix(r.URL.Path, uiPathPrefix) {
+			w.Header().Add("Content-Type", "application/json")
+		}
 		next.ServeHTTP(w, r)
 	})
 }
@@ -18,6 +18,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
@@ -31,21 +32,26 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
 	"golang.org/x/sync/semaphore"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/encoding/gzip"
 	"google.golang.org/grpc/grpclog"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/openconfig/gnmic/pkg/alerts"
 	"github.com/openconfig/gnmic/pkg/api/target"
 	"github.com/openconfig/gnmic/pkg/api/types"
 	"github.com/openconfig/gnmic/pkg/cache"
+	"github.com/openconfig/gnmic/pkg/cert"
 	"github.com/openconfig/gnmic/pkg/config"
 	"github.com/openconfig/gnmic/pkg/formatters"
 	"github.com/openconfig/gnmic/pkg/formatters/plugin_manager"
 	"github.com/openconfig/gnmic/pkg/inputs"
 	"github.com/openconfig/gnmic/pkg/lockers"
 	"github.com/openconfig/gnmic/pkg/outputs"
+	"github.com/openconfig/gnmic/pkg/recorder"
+	"github.com/openconfig/gnmic/pkg/silences"
 )
 
 const (
@@ -74,13 +80,75 @@ type App struct {
 	targetsChan   chan *target.Target
 	activeTargets map[string]struct{}
 	targetsLockFn map[string]context.CancelFunc
-	rootDesc      desc.Descriptor
+	// time at which this instance acquired each target lock
+	targetsLockTime map[string]time.Time
+	// serializes the leader target dispatching and target moves
+	dispatchLock *sync.Mutex
+	rootDesc     desc.Descriptor
+	// outputs receiving messages only through another output
+	memberOutputs map[string]struct{}
+	// event processors configured under subscriptions
+	subProcsLock *sync.Mutex
+	subProcs     map[string]*subscriptionProcessors
+	// adaptive samplers per target and subscription
+	samplersLock *sync.RWMutex
+	samplers     map[string]*adaptiveSampler
+	// gRPC connections shared between targets
+	connPool *target.ConnPool
+	// notifications and events stats per target and output
+	stats *stats
+	// data policies state per output and namespace
+	policiesLock *sync.Mutex
+	policies     map[string]*dataPolicy
+	// service level objectives evaluation state
+	slosLock *sync.Mutex
+	slos     map[string]*sloState
+	// protocol sessions table
+	sessions *sessionTable
+	// targets interfaces, LAGs and optics inventory
+	inventory *inventoryStore
+	// timestamps configuration per output
+	outputsTimestamps map[string]*config.OutputTimestamps
+	// target selector per output
+	outputsSelectors map[string]types.TargetSelector
+	// concurrent writes limit per output
+	outputsQuotas map[string]outputQuota
+	// how GOMAXPROCS was set
+	maxProcsSource string
+	// in-flight writes per output
+	outputWrites *outputWrites
+	// workers exporting the targets stream responses, set by the collector
+	exporters atomic.Pointer[exportShards]
+	// set once the shutdown started, new notifications are rejected
+	draining              atomic.Bool
+	rejectedNotifications atomic.Uint64
 	// end collector
 	router *mux.Router
 	locker lockers.Locker
+	// local view of the cluster targets locks
+	targetLocks *targetLocks
+	// loader changes not applied yet
+	loaderStaging *loaderStaging
+	// loader targets inactive until their delete grace period expires
+	targetTombstones *targetTombstones
+	// components disabled at runtime
+	components *components
+	// silences managed through the API
+	silencesLock *sync.Mutex
+	silences     *silences.Store
+	// alerts raised by the triggers actions
+	alerts *alerts.Store
+	// targets discovered by the loader
+	loadedTargets *loadedTargets
+	// health of the cluster members probed by the leader
+	memberProbes *memberProbes
+	// paces the leader locker operations, nil if not rate limited
+	lockerTicker *time.Ticker
 	// api
 	apiServices map[string]*lockers.Service
 	isLeader    bool
+	// web UI state, set if api-server.enable-ui is true
+	ui *uiState
 	// prometheus registry
 	reg *prometheus.Registry
 	//
@@ -90,12 +158,16 @@ type App struct {
 	PromptMode    bool
 	PromptHistory []string
 	SchemaTree    *yang.Entry
+	// recent events received in prompt mode
+	promptEvents *eventsBuffer
 	// yang
 	modules *yang.Modules
 	//
 	wg        *sync.WaitGroup
 	printLock *sync.Mutex
 	errCh     chan error
+	// outcome of the last request sent to multiple targets
+	targetsRun *targetsRun
 	// gNMI cache, used if a gnmi-server is configured
 	// with subscribe or proxy commands.
 	c cache.Cache
@@ -104,10 +176,18 @@ type App struct {
 	grpcTunnelSrv *grpc.Server
 	tunServer     *tunnel.Server
 	ttm           *sync.RWMutex
-	tunTargets    map[tunnel.Target]struct{}
+	tunTargets    map[tunnel.Target]string
 	tunTargetCfn  map[tunnel.Target]context.CancelFunc
 	// processors plugin manager
 	pm *plugin_manager.PluginManager
+	// SPIFFE workload API X509 source
+	spiffeLock   *sync.Mutex
+	spiffeSource *workloadapi.X509Source
+	// generated servers certificates
+	certLock    *sync.Mutex
+	certManager *cert.Manager
+	// record file writer, set when running the record command
+	recorder *recorder.Writer
 }
 
 func New() *App {
@@ -121,13 +201,33 @@ func New() *App {
 		Config:     config.New(),
 		reg:        prometheus.NewRegistry(),
 		//
-		operLock:      new(sync.RWMutex),
-		Targets:       make(map[string]*target.Target),
-		Outputs:       make(map[string]outputs.Output),
-		Inputs:        make(map[string]inputs.Input),
-		targetsChan:   make(chan *target.Target),
-		activeTargets: make(map[string]struct{}),
-		targetsLockFn: make(map[string]context.CancelFunc),
+		operLock:          new(sync.RWMutex),
+		Targets:           make(map[string]*target.Target),
+		Outputs:           make(map[string]outputs.Output),
+		memberOutputs:     make(map[string]struct{}),
+		subProcsLock:      new(sync.Mutex),
+		subProcs:          make(map[string]*subscriptionProcessors),
+		samplersLock:      new(sync.RWMutex),
+		samplers:          make(map[string]*adaptiveSampler),
+		connPool:          target.NewConnPool(),
+		stats:             newStats(),
+		policiesLock:      new(sync.Mutex),
+		policies:          make(map[string]*dataPolicy),
+		slosLock:          new(sync.Mutex),
+		slos:              make(map[string]*sloState),
+		sessions:          newSessionTable(),
+		inventory:         newInventoryStore(),
+		outputsTimestamps: make(map[string]*config.OutputTimestamps),
+		outputsSelectors:  make(map[string]types.TargetSelector),
+		outputsQuotas:     make(map[string]outputQuota),
+		outputWrites:      newOutputWrites(),
+		Inputs:            make(map[string]inputs.Input),
+		targetsChan:       make(chan *target.Target),
+		activeTargets:     make(map[string]struct{}),
+		targetsLockFn:     make(map[string]context.CancelFunc),
+		//
+		targetsLockTime: make(map[string]time.Time),
+		dispatchLock:    new(sync.Mutex),
 		//
 		router:        mux.NewRouter(),
 		apiServices:   make(map[string]*lockers.Service),
@@ -142,8 +242,22 @@ func New() *App {
 		printLock: new(sync.Mutex),
 		// tunnel server
 		ttm:          new(sync.RWMutex),
-		tunTargets:   make(map[tunnel.Target]struct{}),
+		tunTargets:   make(map[tunnel.Target]string),
 		tunTargetCfn: make(map[tunnel.Target]context.CancelFunc),
+		//
+		spiffeLock: new(sync.Mutex),
+		certLock:   new(sync.Mutex),
+		//
+		targetLocks:  newTargetLocks(),
+		memberProbes: newMemberProbes(),
+		//
+		loaderStaging:    newLoaderStaging(),
+		targetTombstones: newTargetTombstones(),
+		components:       newComponents(),
+		silencesLock:     new(sync.Mutex),
+		silences:         silences.Default(),
+		alerts:           alerts.Default(),
+		loadedTargets:    newLoadedTargets(),
 	}
 	a.router.StrictSlash(true)
 	a.router.Use(headersMiddleware, a.loggingMiddleware)
@@ -177,10 +291,16 @@ func (a *App) InitGlobalFlags() {
 	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.ProxyFromEnv, "proxy-from-env", "", false, "use proxy from environment")
 	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.Format, "format", "", "", fmt.Sprintf("output format, one of: %q", formatNames))
 	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.LogFile, "log-file", "", "", "log file path")
+	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.ErrorFormat, "error-format", "", errorFormatText, fmt.Sprintf("errors report format, one of: %q", errorFormats))
 	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.Log, "log", "", false, "write log messages to stderr")
 	a.RootCmd.PersistentFlags().IntVarP(&a.Config.GlobalFlags.MaxMsgSize, "max-msg-size", "", msgSize, "max grpc msg size")
+	a.RootCmd.PersistentFlags().IntVarP(&a.Config.GlobalFlags.MaxSendMsgSize, "max-send-msg-size", "", 0, "max grpc send msg size, the gRPC default is used if 0")
+	a.RootCmd.PersistentFlags().IntVarP(&a.Config.GlobalFlags.MaxStreamsPerConnection, "max-streams-per-connection", "", 0, "max number of streams per gRPC connection shared between targets with the same address, connection sharing is disabled if 0")
 	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.PrintRequest, "print-request", "", false, "print request as well as the response(s)")
 	a.RootCmd.PersistentFlags().DurationVarP(&a.Config.GlobalFlags.Retry, "retry", "", defaultRetryTimer, "retry timer for RPCs")
+	a.RootCmd.PersistentFlags().IntVarP(&a.Config.GlobalFlags.MaxProcs, "max-procs", "", 0, "number of CPUs used to run gnmic (GOMAXPROCS), set from the container CPU quota if 0, left to the Go runtime default if negative")
+	a.RootCmd.PersistentFlags().IntVarP(&a.Config.GlobalFlags.ExportWorkers, "export-workers", "", 0, "number of workers exporting the targets subscribe responses to the outputs, defaults to the number of CPUs if 0")
+	a.RootCmd.PersistentFlags().DurationVarP(&a.Config.GlobalFlags.DrainTimeout, "drain-timeout", "", defaultDrainTimeout, "maximum time spent draining the inputs, event processors and outputs on shutdown")
 
 	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.TLSMinVersion, "tls-min-version", "", "", fmt.Sprintf("minimum TLS supported version, one of %q", tlsVersions))
 	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.TLSMaxVersion, "tls-max-version", "", "", fmt.Sprintf("maximum TLS supported version, one of %q", tlsVersions))
@@ -195,8 +315,16 @@ func (a *App) InitGlobalFlags() {
 	a.RootCmd.PersistentFlags().StringArrayVarP(&a.Config.GlobalFlags.ProtoFile, "proto-file", "", nil, "proto file(s) name(s)")
 	a.RootCmd.PersistentFlags().StringArrayVarP(&a.Config.GlobalFlags.ProtoDir, "proto-dir", "", nil, "directory to look for proto files specified with --proto-file")
 	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.TargetsFile, "targets-file", "", "", "path to file with targets configuration")
+	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.TargetsFilter, "targets-filter", "", "", "jq expression evaluated against each target configuration, only the matching targets are used")
+	a.RootCmd.PersistentFlags().IntVarP(&a.Config.GlobalFlags.MaxConcurrency, "max-concurrency", "", 0, "maximum number of targets the get, set and capabilities requests are sent to concurrently, unlimited if 0")
+	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.FailFast, "fail-fast", "", false, "stop sending get, set and capabilities requests to the remaining targets after the first failure")
+	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.Summary, "summary", "", false, "print a per target summary of get, set and capabilities requests status and duration to stderr")
 	a.RootCmd.PersistentFlags().BoolVarP(&a.Config.GlobalFlags.Gzip, "gzip", "", false, "enable gzip compression on gRPC connections")
+	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.Compression, "compression", "", "", "gRPC connections compression, one of: gzip, zstd or none. takes precedence over --gzip")
+	a.RootCmd.PersistentFlags().StringSliceVarP(&a.Config.GlobalFlags.EncodingFallback, "encoding-fallback", "", nil, "encodings, in preference order, to retry with when a target rejects the requested encoding")
 	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.Token, "token", "", "", "token value, used for gRPC token based authentication")
+	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.ElectionID, "election-id", "", "", "master arbitration election ID added to Set and Subscribe requests, `[HIGH:]LOW` or 'auto'")
+	a.RootCmd.PersistentFlags().StringVarP(&a.Config.GlobalFlags.ElectionRole, "election-role", "", "", "master arbitration role, the default role is used if empty")
 
 	a.RootCmd.PersistentFlags().StringArrayVarP(&a.Config.GlobalFlags.File, "file", "", nil, "YANG file(s)")
 	a.RootCmd.PersistentFlags().StringArrayVarP(&a.Config.GlobalFlags.Dir, "dir", "", nil, "YANG dir(s)")
@@ -223,6 +351,14 @@ func (a *App) PreRunE(cmd *cobra.Command, args []string) error {
 	a.Logger.SetOutput(logOutput)
 	a.Logger.SetFlags(flags)
 	a.Config.Address = config.SanitizeArrayFlagValue(a.Config.Address)
+	switch a.Config.ErrorFormat {
+	case "", errorFormatText:
+	case errorFormatJSON:
+		// errors are reported as JSON by the caller of Execute.
+		a.RootCmd.SilenceErrors = true
+	default:
+		return fmt.Errorf("unknown error format %q, must be one of: %q", a.Config.ErrorFormat, errorFormats)
+	}
 	a.Logger.Printf("version=%s, commit=%s, date=%s, gitURL=%s, docs=https://gnmic.openconfig.net", version, commit, date, gitURL)
 
 	if a.Config.Debug {
@@ -230,6 +366,23 @@ func (a *App) PreRunE(cmd *cobra.Command, args []string) error {
 	}
 	a.Logger.Printf("using config file %q", a.Config.FileConfig.ConfigFileUsed())
 	a.logConfigKVs()
+	a.setMaxProcs()
+	err = a.Config.GetSpiffe()
+	if err != nil {
+		return err
+	}
+	err = a.Config.GetCert()
+	if err != nil {
+		return err
+	}
+	err = a.Config.GetTemplateLimits()
+	if err != nil {
+		return err
+	}
+	err = a.Config.GetJQLibraries()
+	if err != nil {
+		return err
+	}
 	return a.validateGlobals()
 }
 
@@ -479,6 +632,9 @@ func (a *App) LoadProtoFiles() (desc.Descriptor, error) {
 // If enabled it will load targets from a configured tunnel server.
 func (a *App) GetTargets() (map[string]*types.TargetConfig, error) {
 	targetsConfig, err := a.Config.GetTargets()
+	if errors.Is(err, config.ErrNoTargetsFound) && a.Config.TargetsFile != "" {
+		targetsConfig, err = a.Config.GetTargetsFromFile()
+	}
 	if errors.Is(err, config.ErrNoTargetsFound) {
 		if a.Config.UseTunnelServer {
 			a.Logger.Printf("waiting %s for targets to register with the tunnel server...", a.Config.TunnelServer.TargetWaitTime)
@@ -486,7 +642,7 @@ func (a *App) GetTargets() (map[string]*types.TargetConfig, error) {
 			a.ttm.RLock()
 			defer a.ttm.RUnlock()
 			for tt := range a.tunTargets {
-				tc := a.getTunnelTargetMatch(tt)
+				tc, _ := a.getTunnelTargetMatch(tt)
 				if tc == nil {
 					continue
 				}
@@ -518,12 +674,19 @@ func (a *App) CreateGNMIClient(ctx context.Context, t *target.Target) error {
 		)
 		t.Config.Address = t.Config.Name
 	}
+	if a.targetsUseSpiffe() && (t.Config.Insecure == nil || !*t.Config.Insecure) {
+		tlsConfig, err := a.spiffeClientTLSConfig(ctx, a.Config.Spiffe.Targets)
+		if err != nil {
+			return fmt.Errorf("target %q: %w", t.Config.Name, err)
+		}
+		t.Config.SetTLSConfig(tlsConfig)
+	}
 	a.Logger.Printf("creating gRPC client for target %q", t.Config.Name)
 	if err := t.CreateGNMIClient(ctx, targetDialOpts...); err != nil {
 		if errors.Is(err, context.DeadlineExceeded) {
-			return fmt.Errorf("failed to create a gRPC client for target %q, timeout (%s) reached", t.Config.Name, t.Config.Timeout)
+			return &connectionError{fmt.Errorf("failed to create a gRPC client for target %q, timeout (%s) reached", t.Config.Name, t.Config.Timeout)}
 		}
-		return fmt.Errorf("failed to create a gRPC client for target %q : %w", t.Config.Name, err)
+		return &connectionError{fmt.Errorf("failed to create a gRPC client for target %q : %w", t.Config.Name, err)}
 	}
 	return nil
 }
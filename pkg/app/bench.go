@@ -0,0 +1,462 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/AlekSi/pointer"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/openconfig/gnmic/pkg/api/server"
+	"github.com/openconfig/gnmic/pkg/api/target"
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+const (
+	benchTickInterval      = 10 * time.Millisecond
+	benchSubscriptionName  = "bench"
+	defaultBenchTargetName = "bench"
+)
+
+func (a *App) BenchPreRunE(cmd *cobra.Command, args []string) error {
+	a.Config.SetLocalFlagsFromFile(cmd)
+
+	if a.Config.LocalFlags.BenchRate <= 0 {
+		return errors.New("rate must be a positive number")
+	}
+	if a.Config.LocalFlags.BenchUpdates <= 0 {
+		return errors.New("updates must be a positive number")
+	}
+	if a.Config.LocalFlags.BenchTargets <= 0 {
+		return errors.New("targets must be a positive number")
+	}
+	if a.Config.LocalFlags.BenchDuration <= 0 {
+		return errors.New("duration must be a positive duration")
+	}
+	return a.initPluginManager()
+}
+
+func (a *App) BenchRunE(cmd *cobra.Command, args []string) error {
+	defer a.InitBenchFlags(cmd)
+
+	if len(a.Config.LocalFlags.BenchOutput) > 0 {
+		_, err := a.Config.GetOutputs()
+		if err != nil {
+			return fmt.Errorf("failed reading outputs config: %v", err)
+		}
+		_, err = a.Config.GetActions()
+		if err != nil {
+			return fmt.Errorf("failed reading actions config: %v", err)
+		}
+		_, err = a.Config.GetEventProcessors()
+		if err != nil {
+			return fmt.Errorf("failed reading event processors config: %v", err)
+		}
+		for _, name := range a.Config.LocalFlags.BenchOutput {
+			if _, ok := a.Config.Outputs[name]; !ok {
+				return fmt.Errorf("unknown output %q", name)
+			}
+			a.InitOutput(a.ctx, name, a.Config.Targets)
+		}
+		defer func() {
+			for _, o := range a.Outputs {
+				o.Close()
+			}
+		}()
+	}
+
+	addr := a.Config.LocalFlags.BenchAddress
+	if addr == "" {
+		var err error
+		addr, err = freeLocalAddress()
+		if err != nil {
+			return err
+		}
+	}
+	ctx, cancel := context.WithCancel(a.ctx)
+	defer cancel()
+
+	gen := &benchGenerator{
+		rate:    a.Config.LocalFlags.BenchRate,
+		updates: a.Config.LocalFlags.BenchUpdates,
+		depth:   a.Config.LocalFlags.BenchDepth,
+		keys:    a.Config.LocalFlags.BenchKeys,
+	}
+	s, err := server.New(server.Config{
+		Address:         addr,
+		MaxStreamingRPC: -1,
+		MaxUnaryRPC:     -1,
+	},
+		server.WithLogger(a.Logger),
+		server.WithSubscribeHandler(gen.subscribeHandler),
+	)
+	if err != nil {
+		return err
+	}
+	go s.Start(ctx)
+
+	stats := newBenchStats()
+	numTargets := a.Config.LocalFlags.BenchTargets
+	a.Logger.Printf("starting %d subscription(s) to the synthetic target at %s: rate=%d notifications/s, updates=%d, duration=%s",
+		numTargets, addr, gen.rate, gen.updates, a.Config.LocalFlags.BenchDuration)
+	wg := new(sync.WaitGroup)
+	wg.Add(numTargets)
+	errCh := make(chan error, numTargets)
+	runCtx, runCancel := context.WithTimeout(ctx, a.Config.LocalFlags.BenchDuration)
+	defer runCancel()
+	for i := 0; i < numTargets; i++ {
+		name := defaultBenchTargetName + strconv.Itoa(i)
+		go func(name string) {
+			defer wg.Done()
+			err := a.benchTarget(runCtx, name, addr, stats)
+			if err != nil {
+				errCh <- fmt.Errorf("%s: %v", name, err)
+			}
+		}(name)
+	}
+	wg.Wait()
+	close(errCh)
+	for err := range errCh {
+		return err
+	}
+	return a.printBenchReport(stats.report(a.Config.LocalFlags.BenchDuration))
+}
+
+// InitBenchFlags used to init or reset benchCmd flags for gnmic-prompt mode
+func (a *App) InitBenchFlags(cmd *cobra.Command) {
+	cmd.ResetFlags()
+
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.BenchAddress, "listen", "", "", "synthetic target listen address, defaults to a random local port")
+	cmd.Flags().IntVarP(&a.Config.LocalFlags.BenchRate, "rate", "", 1000, "number of notifications per second sent by the synthetic target on each subscription")
+	cmd.Flags().IntVarP(&a.Config.LocalFlags.BenchUpdates, "updates", "", 10, "number of updates per notification")
+	cmd.Flags().IntVarP(&a.Config.LocalFlags.BenchDepth, "depth", "", 3, "number of path elements in each update path")
+	cmd.Flags().IntVarP(&a.Config.LocalFlags.BenchKeys, "keys", "", 100, "number of distinct list keys the update paths cycle through")
+	cmd.Flags().IntVarP(&a.Config.LocalFlags.BenchTargets, "targets", "", 1, "number of concurrent subscriptions")
+	cmd.Flags().DurationVarP(&a.Config.LocalFlags.BenchDuration, "duration", "", 30*time.Second, "benchmark duration")
+	cmd.Flags().StringSliceVarP(&a.Config.LocalFlags.BenchOutput, "output", "", nil, "outputs, defined in the config file, the received notifications are written to")
+	//
+	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		a.Config.FileConfig.BindPFlag(fmt.Sprintf("%s-%s", cmd.Name(), flag.Name), flag)
+	})
+}
+
+func (a *App) benchTarget(ctx context.Context, name, addr string, stats *benchStats) error {
+	t := target.NewTarget(&types.TargetConfig{
+		Name:       name,
+		Address:    addr,
+		Insecure:   pointer.ToBool(true),
+		SkipVerify: pointer.ToBool(false),
+		Timeout:    a.Config.Timeout,
+		RetryTimer: time.Second,
+		BufferSize: defaultBenchBufferSize,
+	})
+	err := t.CreateGNMIClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer t.Close()
+	req := &gnmi.SubscribeRequest{
+		Request: &gnmi.SubscribeRequest_Subscribe{
+			Subscribe: &gnmi.SubscriptionList{
+				Prefix: &gnmi.Path{Target: name},
+				Subscription: []*gnmi.Subscription{
+					{Path: &gnmi.Path{}, Mode: gnmi.SubscriptionMode_SAMPLE},
+				},
+				Mode: gnmi.SubscriptionList_STREAM,
+			},
+		},
+	}
+	t.Subscriptions[benchSubscriptionName] = &types.SubscriptionConfig{Name: benchSubscriptionName}
+	go t.Subscribe(ctx, req, benchSubscriptionName)
+	rspCh, errCh := t.ReadSubscriptions()
+	m := outputs.Meta{
+		"source":            name,
+		"format":            a.Config.Format,
+		"subscription-name": benchSubscriptionName,
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case tErr := <-errCh:
+			a.Logger.Printf("target %q: %v", name, tErr.Err)
+		case rsp := <-rspCh:
+			n := rsp.Response.GetUpdate()
+			if n == nil {
+				continue
+			}
+			now := time.Now()
+			stats.received(len(n.GetUpdate()), now.Sub(time.Unix(0, n.GetTimestamp())))
+			if len(a.Config.LocalFlags.BenchOutput) == 0 {
+				continue
+			}
+			a.Export(ctx, rsp.Response, m, a.Config.LocalFlags.BenchOutput...)
+			stats.exported(time.Since(now))
+		}
+	}
+}
+
+func (a *App) printBenchReport(r *benchReport) error {
+	if a.Config.Format == formatJSON {
+		b, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Duration:\t%s\n", r.Duration)
+	fmt.Fprintf(tw, "Notifications received:\t%d\n", r.Notifications)
+	fmt.Fprintf(tw, "Updates received:\t%d\n", r.Updates)
+	fmt.Fprintf(tw, "Notifications/s:\t%.1f\n", r.NotificationsRate)
+	fmt.Fprintf(tw, "Updates/s:\t%.1f\n", r.UpdatesRate)
+	fmt.Fprintf(tw, "Latency p50/p90/p99/max:\t%s / %s / %s / %s\n",
+		r.Latency.P50, r.Latency.P90, r.Latency.P99, r.Latency.Max)
+	if r.ExportLatency != nil {
+		fmt.Fprintf(tw, "Export latency p50/p90/p99/max:\t%s / %s / %s / %s\n",
+			r.ExportLatency.P50, r.ExportLatency.P90, r.ExportLatency.P99, r.ExportLatency.Max)
+	}
+	return tw.Flush()
+}
+
+const defaultBenchBufferSize = 1000
+
+// benchGenerator builds the synthetic notifications sent by the bench target.
+type benchGenerator struct {
+	// notifications per second
+	rate int
+	// updates per notification
+	updates int
+	// number of path elements per update
+	depth int
+	// number of list keys to cycle through
+	keys int
+}
+
+func (g *benchGenerator) subscribeHandler(req *gnmi.SubscribeRequest, stream gnmi.GNMI_SubscribeServer) error {
+	if req.GetSubscribe().GetMode() != gnmi.SubscriptionList_STREAM {
+		return errors.New("only STREAM subscriptions are supported by the bench target")
+	}
+	prefix := req.GetSubscribe().GetPrefix()
+	ticker := time.NewTicker(benchTickInterval)
+	defer ticker.Stop()
+	perTick := float64(g.rate) * benchTickInterval.Seconds()
+	var budget float64
+	var seq int
+	synced := false
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-ticker.C:
+			budget += perTick
+			for ; budget >= 1; budget-- {
+				n := g.notification(seq)
+				n.Prefix = prefix
+				err := stream.Send(&gnmi.SubscribeResponse{
+					Response: &gnmi.SubscribeResponse_Update{Update: n},
+				})
+				if err != nil {
+					return err
+				}
+				seq++
+			}
+			if !synced {
+				err := stream.Send(&gnmi.SubscribeResponse{
+					Response: &gnmi.SubscribeResponse_SyncResponse{SyncResponse: true},
+				})
+				if err != nil {
+					return err
+				}
+				synced = true
+			}
+		}
+	}
+}
+
+func (g *benchGenerator) notification(seq int) *gnmi.Notification {
+	n := &gnmi.Notification{
+		Timestamp: time.Now().UnixNano(),
+		Update:    make([]*gnmi.Update, 0, g.updates),
+	}
+	key := "0"
+	if g.keys > 0 {
+		key = strconv.Itoa(seq % g.keys)
+	}
+	depth := g.depth
+	if depth < 2 {
+		depth = 2
+	}
+	for i := 0; i < g.updates; i++ {
+		elems := make([]*gnmi.PathElem, 0, depth)
+		elems = append(elems, &gnmi.PathElem{Name: "bench", Key: map[string]string{"name": key}})
+		for d := 1; d < depth-1; d++ {
+			elems = append(elems, &gnmi.PathElem{Name: "level" + strconv.Itoa(d)})
+		}
+		elems = append(elems, &gnmi.PathElem{Name: "leaf" + strconv.Itoa(i)})
+		n.Update = append(n.Update, &gnmi.Update{
+			Path: &gnmi.Path{Elem: elems},
+			Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_IntVal{IntVal: int64(seq)}},
+		})
+	}
+	return n
+}
+
+// benchLatencySamples is the number of latency samples kept
+// to compute the latency percentiles of a benchmark.
+const benchLatencySamples = 10000
+
+type benchStats struct {
+	m               *sync.Mutex
+	notifications   int64
+	updates         int64
+	latencies       *latencySamples
+	exportLatencies *latencySamples
+}
+
+// latencySamples keeps a fixed size uniform random sample of the observed latencies,
+// and their exact maximum.
+type latencySamples struct {
+	count   int64
+	max     time.Duration
+	samples []time.Duration
+}
+
+type benchReport struct {
+	Duration          string         `json:"duration,omitempty"`
+	Notifications     int64          `json:"notifications,omitempty"`
+	Updates           int64          `json:"updates,omitempty"`
+	NotificationsRate float64        `json:"notifications-rate,omitempty"`
+	UpdatesRate       float64        `json:"updates-rate,omitempty"`
+	Latency           *latencyReport `json:"latency,omitempty"`
+	ExportLatency     *latencyReport `json:"export-latency,omitempty"`
+}
+
+type latencyReport struct {
+	P50 time.Duration `json:"p50,omitempty"`
+	P90 time.Duration `json:"p90,omitempty"`
+	P99 time.Duration `json:"p99,omitempty"`
+	Max time.Duration `json:"max,omitempty"`
+}
+
+func newBenchStats() *benchStats {
+	return &benchStats{
+		m:               new(sync.Mutex),
+		latencies:       newLatencySamples(benchLatencySamples),
+		exportLatencies: newLatencySamples(benchLatencySamples),
+	}
+}
+
+func (s *benchStats) received(numUpdates int, latency time.Duration) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.notifications++
+	s.updates += int64(numUpdates)
+	s.latencies.add(latency)
+}
+
+func (s *benchStats) exported(latency time.Duration) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.exportLatencies.add(latency)
+}
+
+func (s *benchStats) report(d time.Duration) *benchReport {
+	s.m.Lock()
+	defer s.m.Unlock()
+	r := &benchReport{
+		Duration:          d.String(),
+		Notifications:     s.notifications,
+		Updates:           s.updates,
+		NotificationsRate: float64(s.notifications) / d.Seconds(),
+		UpdatesRate:       float64(s.updates) / d.Seconds(),
+		Latency:           s.latencies.report(),
+	}
+	if s.exportLatencies.count > 0 {
+		r.ExportLatency = s.exportLatencies.report()
+	}
+	return r
+}
+
+func newLatencySamples(size int) *latencySamples {
+	return &latencySamples{samples: make([]time.Duration, 0, size)}
+}
+
+// add records the latency d using the algorithm R:
+// the i-th latency replaces a random sample with probability size/i.
+func (l *latencySamples) add(d time.Duration) {
+	l.count++
+	if d > l.max {
+		l.max = d
+	}
+	if len(l.samples) < cap(l.samples) {
+		l.samples = append(l.samples, d)
+		return
+	}
+	if j := rand.Int63n(l.count); j < int64(len(l.samples)) {
+		l.samples[j] = d
+	}
+}
+
+// report returns the percentiles estimated from the samples.
+func (l *latencySamples) report() *latencyReport {
+	if len(l.samples) == 0 {
+		return &latencyReport{}
+	}
+	ds := make([]time.Duration, len(l.samples))
+	copy(ds, l.samples)
+	sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+	return &latencyReport{
+		P50: percentile(ds, 50),
+		P90: percentile(ds, 90),
+		P99: percentile(ds, 99),
+		Max: l.max,
+	}
+}
+
+// percentile returns the p-th percentile of a sorted list of durations.
+func percentile(ds []time.Duration, p float64) time.Duration {
+	if len(ds) == 0 {
+		return 0
+	}
+	idx := int(float64(len(ds))*p/100+0.5) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(ds) {
+		idx = len(ds) - 1
+	}
+	return ds[idx]
+}
+
+// freeLocalAddress returns a loopback address with an available TCP port.
+func freeLocalAddress() (string, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	defer l.Close()
+	return l.Addr().String(), nil
+}
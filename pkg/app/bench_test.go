@@ -0,0 +1,101 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+var percentileTestSet = map[string]struct {
+	in  []time.Duration
+	p   float64
+	out time.Duration
+}{
+	"empty": {
+		in:  nil,
+		p:   50,
+		out: 0,
+	},
+	"single": {
+		in:  []time.Duration{time.Second},
+		p:   99,
+		out: time.Second,
+	},
+	"p50": {
+		in:  []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+		p:   50,
+		out: 5,
+	},
+	"p90": {
+		in:  []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+		p:   90,
+		out: 9,
+	},
+	"p100": {
+		in:  []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+		p:   100,
+		out: 10,
+	},
+}
+
+func TestPercentile(t *testing.T) {
+	for name, tc := range percentileTestSet {
+		t.Run(name, func(t *testing.T) {
+			got := percentile(tc.in, tc.p)
+			if got != tc.out {
+				t.Errorf("expected %v, got %v", tc.out, got)
+			}
+		})
+	}
+}
+
+func TestBenchGeneratorNotification(t *testing.T) {
+	g := &benchGenerator{rate: 10, updates: 5, depth: 4, keys: 3}
+	n := g.notification(4)
+	if len(n.GetUpdate()) != 5 {
+		t.Fatalf("expected 5 updates, got %d", len(n.GetUpdate()))
+	}
+	for _, upd := range n.GetUpdate() {
+		elems := upd.GetPath().GetElem()
+		if len(elems) != 4 {
+			t.Fatalf("expected 4 path elements, got %d", len(elems))
+		}
+		if elems[0].GetKey()["name"] != "1" {
+			t.Fatalf("expected key 1, got %q", elems[0].GetKey()["name"])
+		}
+		if upd.GetVal().GetIntVal() != 4 {
+			t.Fatalf("expected value 4, got %v", upd.GetVal())
+		}
+	}
+}
+
+func TestLatencySamples(t *testing.T) {
+	l := newLatencySamples(100)
+	if r := l.report(); *r != (latencyReport{}) {
+		t.Errorf("expected an empty report, got %+v", r)
+	}
+	for i := 1; i <= 10000; i++ {
+		l.add(time.Duration(i) * time.Millisecond)
+	}
+	if len(l.samples) != 100 || cap(l.samples) != 100 {
+		t.Fatalf("expected 100 samples, got len=%d cap=%d", len(l.samples), cap(l.samples))
+	}
+	r := l.report()
+	if r.Max != 10*time.Second {
+		t.Errorf("expected the exact max, got %s", r.Max)
+	}
+	// the estimated median of a uniform distribution
+	if r.P50 < 3*time.Second || r.P50 > 7*time.Second {
+		t.Errorf("unexpected p50 estimate %s", r.P50)
+	}
+	if r.P50 > r.P90 || r.P90 > r.P99 || r.P99 > r.Max {
+		t.Errorf("unordered percentiles: %+v", r)
+	}
+}
@@ -10,7 +10,14 @@ package app
 
 import (
 	"context"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/openconfig/gnmi/proto/gnmi"
 	"github.com/openconfig/gnmi/proto/gnmi_ext"
@@ -21,8 +28,19 @@ import (
 	"github.com/openconfig/gnmic/pkg/api/types"
 )
 
+const (
+	capReportFormatJSON = "json"
+	capReportFormatCSV  = "csv"
+)
+
 func (a *App) CapPreRunE(cmd *cobra.Command, _ []string) error {
 	a.Config.SetLocalFlagsFromFile(cmd)
+	switch a.Config.LocalFlags.CapabilitiesReportFormat {
+	case "", capReportFormatJSON, capReportFormatCSV:
+	default:
+		return fmt.Errorf("unknown report format %q, must be one of %q or %q",
+			a.Config.LocalFlags.CapabilitiesReportFormat, capReportFormatJSON, capReportFormatCSV)
+	}
 	a.createCollectorDialOpts()
 	return a.initTunnelServer(tunnel.ServerConfig{
 		AddTargetHandler:    a.tunServerAddTargetHandler,
@@ -35,7 +53,7 @@ func (a *App) CapPreRunE(cmd *cobra.Command, _ []string) error {
 func (a *App) CapRunE(cmd *cobra.Command, args []string) error {
 	defer a.InitCapabilitiesFlags(cmd)
 
-	if a.Config.Format == formatEvent {
+	if a.Config.Format == formatEvent && !a.Config.LocalFlags.CapabilitiesAggregate {
 		return fmt.Errorf("format event not supported for Capabilities RPC")
 	}
 	ctx, cancel := context.WithCancel(a.ctx)
@@ -51,38 +69,36 @@ func (a *App) CapRunE(cmd *cobra.Command, args []string) error {
 			a.AddTargetConfig(tc)
 		}
 	}
+	if a.Config.LocalFlags.CapabilitiesAggregate {
+		return a.capabilitiesReport(ctx, cmd)
+	}
 	numTargets := len(a.Config.Targets)
 	a.errCh = make(chan error, numTargets*2)
-	a.wg.Add(numTargets)
-	for _, tc := range a.Config.Targets {
-		go a.ReqCapabilities(ctx, tc)
-	}
-	a.wg.Wait()
+	a.runTargets(ctx, a.ReqCapabilities)
 	return a.checkErrors()
 }
 
 func (a *App) ReqCapabilities(ctx context.Context, tc *types.TargetConfig) {
-	defer a.wg.Done()
 	ext := make([]*gnmi_ext.Extension, 0) //
 	if a.Config.PrintRequest {
 		err := a.PrintMsg(tc.Name, "Capabilities Request:", &gnmi.CapabilityRequest{
 			Extension: ext,
 		})
 		if err != nil {
-			a.logError(fmt.Errorf("target %q: %v", tc.Name, err))
+			a.logTargetError(tc.Name, fmt.Errorf("target %q: %w", tc.Name, err))
 		}
 	}
 
 	a.Logger.Printf("sending gNMI CapabilityRequest: gnmi_ext.Extension='%v' to %s", ext, tc.Name)
 	response, err := a.ClientCapabilities(ctx, tc, ext...)
 	if err != nil {
-		a.logError(fmt.Errorf("target %q, capabilities request failed: %v", tc.Name, err))
+		a.logTargetError(tc.Name, fmt.Errorf("target %q, capabilities request failed: %w", tc.Name, err))
 		return
 	}
 
 	err = a.PrintMsg(tc.Name, "Capabilities Response:", response)
 	if err != nil {
-		a.logError(fmt.Errorf("target %q: %v", tc.Name, err))
+		a.logTargetError(tc.Name, fmt.Errorf("target %q: %w", tc.Name, err))
 	}
 }
 
@@ -90,7 +106,173 @@ func (a *App) InitCapabilitiesFlags(cmd *cobra.Command) {
 	cmd.ResetFlags()
 
 	cmd.Flags().BoolVarP(&a.Config.LocalFlags.CapabilitiesVersion, "version", "", false, "show gnmi version only")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.CapabilitiesAggregate, "aggregate", "", false, "print a consolidated report of the models, versions and encodings supported by all targets")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.CapabilitiesReportFormat, "report-format", "", capReportFormatJSON, "aggregated report format, one of: json, csv")
 	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
 		a.Config.FileConfig.BindPFlag(fmt.Sprintf("%s-%s", cmd.Name(), flag.Name), flag)
 	})
 }
+
+// targetCapabilities is a target entry in the aggregated capabilities report.
+type targetCapabilities struct {
+	Name        string        `json:"name,omitempty"`
+	GNMIVersion string        `json:"gnmi-version,omitempty"`
+	Encodings   []string      `json:"encodings,omitempty"`
+	Models      []*modelEntry `json:"models,omitempty"`
+	// models required by the target subscriptions
+	// but not supported by the target.
+	MissingModels []string `json:"missing-models,omitempty"`
+	Error         string   `json:"error,omitempty"`
+}
+
+type modelEntry struct {
+	Name         string `json:"name,omitempty"`
+	Organization string `json:"organization,omitempty"`
+	Version      string `json:"version,omitempty"`
+}
+
+// capabilitiesReport sends a Capabilities request to all targets and prints
+// an aggregated report of their supported models and encodings.
+func (a *App) capabilitiesReport(ctx context.Context, cmd *cobra.Command) error {
+	// subscriptions are used to find the models required by each target,
+	// a config without subscriptions is not an error.
+	_, err := a.Config.GetSubscriptions(cmd)
+	if err != nil {
+		a.Logger.Printf("failed reading subscriptions config: %v", err)
+	}
+	numTargets := len(a.Config.Targets)
+	a.errCh = make(chan error, numTargets)
+	m := new(sync.Mutex)
+	report := make([]*targetCapabilities, 0, numTargets)
+	a.wg.Add(numTargets)
+	for _, tc := range a.Config.Targets {
+		go func(tc *types.TargetConfig) {
+			defer a.wg.Done()
+			rsp, err := a.ClientCapabilities(ctx, tc)
+			if err != nil {
+				a.logTargetError(tc.Name, fmt.Errorf("target %q, capabilities request failed: %w", tc.Name, err))
+			}
+			tcap := newTargetCapabilities(tc.Name, rsp, err, a.targetRequiredModels(tc))
+			if len(tcap.MissingModels) > 0 {
+				a.Logger.Printf("target %q is missing models required by its subscriptions: %v", tc.Name, tcap.MissingModels)
+			}
+			m.Lock()
+			report = append(report, tcap)
+			m.Unlock()
+		}(tc)
+	}
+	a.wg.Wait()
+	sort.Slice(report, func(i, j int) bool {
+		return report[i].Name < report[j].Name
+	})
+	switch a.Config.LocalFlags.CapabilitiesReportFormat {
+	case capReportFormatCSV:
+		err = writeCapabilitiesCSV(os.Stdout, report)
+	default:
+		var b []byte
+		b, err = json.MarshalIndent(report, "", "  ")
+		if err == nil {
+			fmt.Println(string(b))
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return a.checkErrors()
+}
+
+// targetRequiredModels returns the sorted list of models
+// set in the subscriptions the target is subscribed to.
+func (a *App) targetRequiredModels(tc *types.TargetConfig) []string {
+	models := make(map[string]struct{})
+	for _, sub := range a.targetSubscriptions(tc) {
+		for _, m := range sub.Models {
+			models[m] = struct{}{}
+		}
+	}
+	r := make([]string, 0, len(models))
+	for m := range models {
+		r = append(r, m)
+	}
+	sort.Strings(r)
+	return r
+}
+
+func newTargetCapabilities(name string, rsp *gnmi.CapabilityResponse, err error, required []string) *targetCapabilities {
+	tcap := &targetCapabilities{Name: name}
+	if err != nil {
+		tcap.Error = err.Error()
+		return tcap
+	}
+	if rsp == nil {
+		tcap.Error = "empty capabilities response"
+		return tcap
+	}
+	tcap.GNMIVersion = rsp.GetGNMIVersion()
+	tcap.Encodings = make([]string, 0, len(rsp.GetSupportedEncodings()))
+	for _, enc := range rsp.GetSupportedEncodings() {
+		tcap.Encodings = append(tcap.Encodings, enc.String())
+	}
+	supported := make(map[string]struct{}, len(rsp.GetSupportedModels()))
+	tcap.Models = make([]*modelEntry, 0, len(rsp.GetSupportedModels()))
+	for _, m := range rsp.GetSupportedModels() {
+		supported[m.GetName()] = struct{}{}
+		tcap.Models = append(tcap.Models, &modelEntry{
+			Name:         m.GetName(),
+			Organization: m.GetOrganization(),
+			Version:      m.GetVersion(),
+		})
+	}
+	sort.Slice(tcap.Models, func(i, j int) bool {
+		return tcap.Models[i].Name < tcap.Models[j].Name
+	})
+	for _, m := range required {
+		if _, ok := supported[m]; !ok {
+			tcap.MissingModels = append(tcap.MissingModels, m)
+		}
+	}
+	return tcap
+}
+
+// writeCapabilitiesCSV writes one row per target model,
+// with the status column set to "supported" or "missing".
+// Targets that failed to respond get a single row with the "error" status,
+// targets without models get a single row with the "no-models" status.
+func writeCapabilitiesCSV(w io.Writer, report []*targetCapabilities) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{"target", "gnmi-version", "encodings", "model", "organization", "version", "status"})
+	if err != nil {
+		return err
+	}
+	for _, tcap := range report {
+		if tcap.Error != "" {
+			err = cw.Write([]string{tcap.Name, "", "", "", "", "", "error: " + tcap.Error})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		encodings := strings.Join(tcap.Encodings, ";")
+		if len(tcap.Models) == 0 && len(tcap.MissingModels) == 0 {
+			err = cw.Write([]string{tcap.Name, tcap.GNMIVersion, encodings, "", "", "", "no-models"})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		for _, m := range tcap.Models {
+			err = cw.Write([]string{tcap.Name, tcap.GNMIVersion, encodings, m.Name, m.Organization, m.Version, "supported"})
+			if err != nil {
+				return err
+			}
+		}
+		for _, m := range tcap.MissingModels {
+			err = cw.Write([]string{tcap.Name, tcap.GNMIVersion, encodings, m, "", "", "missing"})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
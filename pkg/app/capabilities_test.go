@@ -0,0 +1,75 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+func TestNewTargetCapabilities(t *testing.T) {
+	rsp := &gnmi.CapabilityResponse{
+		GNMIVersion:        "0.10.0",
+		SupportedEncodings: []gnmi.Encoding{gnmi.Encoding_JSON_IETF, gnmi.Encoding_PROTO},
+		SupportedModels: []*gnmi.ModelData{
+			{Name: "openconfig-system", Organization: "OpenConfig working group", Version: "1.0.0"},
+			{Name: "openconfig-interfaces", Organization: "OpenConfig working group", Version: "3.0.0"},
+		},
+	}
+	tcap := newTargetCapabilities("router1", rsp, nil, []string{"openconfig-bgp", "openconfig-interfaces"})
+	if tcap.GNMIVersion != "0.10.0" {
+		t.Fatalf("unexpected gNMI version %q", tcap.GNMIVersion)
+	}
+	if !reflect.DeepEqual(tcap.Encodings, []string{"JSON_IETF", "PROTO"}) {
+		t.Fatalf("unexpected encodings %v", tcap.Encodings)
+	}
+	if len(tcap.Models) != 2 || tcap.Models[0].Name != "openconfig-interfaces" {
+		t.Fatalf("expected 2 models sorted by name, got %+v", tcap.Models)
+	}
+	if !reflect.DeepEqual(tcap.MissingModels, []string{"openconfig-bgp"}) {
+		t.Fatalf("unexpected missing models %v", tcap.MissingModels)
+	}
+
+	tcap = newTargetCapabilities("router2", nil, errors.New("timeout"), nil)
+	if tcap.Error != "timeout" || len(tcap.Models) != 0 {
+		t.Fatalf("unexpected failed target entry %+v", tcap)
+	}
+}
+
+func TestWriteCapabilitiesCSV(t *testing.T) {
+	report := []*targetCapabilities{
+		{
+			Name:          "router1",
+			GNMIVersion:   "0.10.0",
+			Encodings:     []string{"JSON", "JSON_IETF"},
+			Models:        []*modelEntry{{Name: "openconfig-interfaces", Organization: "OpenConfig working group", Version: "3.0.0"}},
+			MissingModels: []string{"openconfig-bgp"},
+		},
+		{Name: "router2", Error: "timeout"},
+		{Name: "router3", GNMIVersion: "0.8.0", Encodings: []string{"JSON"}},
+	}
+	buf := new(bytes.Buffer)
+	err := writeCapabilitiesCSV(buf, report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `target,gnmi-version,encodings,model,organization,version,status
+router1,0.10.0,JSON;JSON_IETF,openconfig-interfaces,OpenConfig working group,3.0.0,supported
+router1,0.10.0,JSON;JSON_IETF,openconfig-bgp,,,missing
+router2,,,,,,error: timeout
+router3,0.8.0,JSON,,,,no-models
+`
+	if buf.String() != exp {
+		t.Fatalf("unexpected CSV output:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,165 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/cert"
+	"github.com/openconfig/gnmic/pkg/config"
+	"github.com/openconfig/gnmic/pkg/lockers"
+)
+
+const (
+	certAPIServer    = "api-server"
+	certGNMIServer   = "gnmi-server"
+	certTunnelServer = "tunnel-server"
+)
+
+// getCertManager returns the generated certificates manager,
+// it is created on first use and rotates the certificates until the app context is done.
+func (a *App) getCertManager() (*cert.Manager, error) {
+	if a.Config.Cert == nil {
+		return nil, nil
+	}
+	a.certLock.Lock()
+	defer a.certLock.Unlock()
+	if a.certManager != nil {
+		return a.certManager, nil
+	}
+	cfg := &cert.Config{
+		CA: cert.Options{
+			CommonName:   a.Config.Cert.CA.CommonName,
+			Organization: a.Config.Cert.CA.Organization,
+			Validity:     a.Config.Cert.CA.Validity,
+		},
+		Organization:  a.Config.Cert.Organization,
+		Validity:      a.Config.Cert.Validity,
+		RenewBefore:   a.Config.Cert.RenewBefore,
+		CheckInterval: a.Config.Cert.CheckInterval,
+	}
+	opts := []cert.Option{cert.WithLogger(a.Logger)}
+	var store cert.Store
+	if a.Config.Cert.UsesLocker() {
+		if a.locker == nil {
+			return nil, errors.New("cert: store `locker` requires a clustering locker")
+		}
+		kv, ok := a.locker.(lockers.KV)
+		if !ok {
+			return nil, fmt.Errorf("cert: locker type %v does not support storing certificates", a.Config.Clustering.Locker["type"])
+		}
+		store = cert.NewKVStore(kv, a.certKeyPrefix(), a.Config.Cert.EncryptionKey)
+		opts = append(opts, cert.WithLocker(&certLocker{
+			locker: a.locker,
+			key:    a.certKeyPrefix() + "-lock",
+			val:    a.Config.Clustering.InstanceName,
+		}))
+	} else {
+		store = cert.NewFileStore(a.Config.Cert.Dir)
+	}
+	a.certManager = cert.NewManager(cfg, store, opts...)
+	go a.certManager.Start(a.ctx)
+	return a.certManager, nil
+}
+
+func (a *App) certKeyPrefix() string {
+	return fmt.Sprintf("gnmic/%s/certs", a.Config.Clustering.ClusterName)
+}
+
+// certServerTLSConfig returns a TLS config serving the generated certificate
+// of the given server, listening on addr.
+func (a *App) certServerTLSConfig(server string, cs *config.CertServer, addr string) (*tls.Config, error) {
+	m, err := a.getCertManager()
+	if err != nil {
+		return nil, err
+	}
+	name := server
+	// the CA is shared by the cluster members,
+	// while each instance has its own servers certificates.
+	if a.Config.Cert.UsesLocker() {
+		name = fmt.Sprintf("%s/%s", a.Config.Clustering.InstanceName, server)
+	}
+	ctx, cancel := context.WithTimeout(a.ctx, time.Minute)
+	defer cancel()
+	return m.ServerTLSConfig(ctx, name, certHosts(cs, addr))
+}
+
+// certHosts returns the SANs of a server certificate: the configured hosts,
+// the listening address, the hostname, localhost and the loopback addresses.
+func certHosts(cs *config.CertServer, addr string) []string {
+	hosts := make([]string, 0, len(cs.Hosts)+5)
+	hosts = append(hosts, cs.Hosts...)
+	if h, _, err := net.SplitHostPort(addr); err == nil && h != "" {
+		if ip := net.ParseIP(h); ip == nil || !ip.IsUnspecified() {
+			hosts = append(hosts, h)
+		}
+	}
+	if h, err := os.Hostname(); err == nil && h != "" {
+		hosts = append(hosts, h)
+	}
+	hosts = append(hosts, "localhost", "127.0.0.1", "::1")
+	// dedup
+	seen := make(map[string]struct{}, len(hosts))
+	rs := hosts[:0]
+	for _, h := range hosts {
+		if _, ok := seen[h]; ok {
+			continue
+		}
+		seen[h] = struct{}{}
+		rs = append(rs, h)
+	}
+	return rs
+}
+
+func (a *App) apiServerUsesCert() bool {
+	return a.Config.Cert != nil && a.Config.Cert.APIServer != nil
+}
+
+func (a *App) gnmiServerUsesCert() bool {
+	return a.Config.Cert != nil && a.Config.Cert.GNMIServer != nil
+}
+
+func (a *App) tunnelServerUsesCert() bool {
+	return a.Config.Cert != nil && a.Config.Cert.TunnelServer != nil
+}
+
+// certLocker serializes the CA generation between cluster members
+// using the clustering locker.
+type certLocker struct {
+	locker lockers.Locker
+	key    string
+	val    string
+}
+
+func (l *certLocker) Lock(ctx context.Context) error {
+	for {
+		ok, err := l.locker.Lock(ctx, l.key, []byte(l.val))
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(lockWaitTime):
+		}
+	}
+}
+
+func (l *certLocker) Unlock(ctx context.Context) error {
+	return l.locker.Unlock(ctx, l.key)
+}
@@ -0,0 +1,74 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/openconfig/gnmic/pkg/config"
+)
+
+func TestCertHosts(t *testing.T) {
+	hostname, _ := os.Hostname()
+	tests := []struct {
+		name     string
+		cs       *config.CertServer
+		addr     string
+		want     []string
+		unwanted []string
+	}{
+		{
+			name:     "unspecified_address",
+			cs:       &config.CertServer{},
+			addr:     ":57400",
+			want:     []string{"localhost", "127.0.0.1", "::1"},
+			unwanted: []string{""},
+		},
+		{
+			name:     "any_address",
+			cs:       &config.CertServer{},
+			addr:     "0.0.0.0:57400",
+			want:     []string{"localhost"},
+			unwanted: []string{"0.0.0.0"},
+		},
+		{
+			name: "configured_hosts_and_address",
+			cs:   &config.CertServer{Hosts: []string{"gnmic.lab", "localhost"}},
+			addr: "10.0.0.1:7890",
+			want: []string{"gnmic.lab", "10.0.0.1", "localhost", "127.0.0.1", "::1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := certHosts(tt.cs, tt.addr)
+			for _, h := range tt.want {
+				if !slices.Contains(got, h) {
+					t.Errorf("missing host %q in %v", h, got)
+				}
+			}
+			for _, h := range tt.unwanted {
+				if slices.Contains(got, h) {
+					t.Errorf("unexpected host %q in %v", h, got)
+				}
+			}
+			if hostname != "" && !slices.Contains(got, hostname) {
+				t.Errorf("missing hostname %q in %v", hostname, got)
+			}
+			seen := make(map[string]struct{})
+			for _, h := range got {
+				if _, ok := seen[h]; ok {
+					t.Errorf("duplicate host %q in %v", h, got)
+				}
+				seen[h] = struct{}{}
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+// checkClockSkew measures the clock skew of the target tc on the notification of rsp, received at now.
+// If the skew exceeds the target threshold and correction is enabled,
+// the notification timestamp is rewritten to now.
+func (a *App) checkClockSkew(tc *types.TargetConfig, rsp *gnmi.SubscribeResponse, now time.Time) {
+	n := rsp.GetUpdate()
+	if n == nil || n.GetTimestamp() == 0 {
+		return
+	}
+	skew := now.Sub(time.Unix(0, n.GetTimestamp()))
+	exceeded := tc.ClockSkew.Exceeded(skew)
+	corrected := exceeded && tc.ClockSkew.Correct
+	if corrected {
+		n.Timestamp = now.UnixNano()
+	}
+	a.stats.clockSkew(tc.Name, skew, exceeded, corrected)
+}
@@ -0,0 +1,74 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+func TestCheckClockSkew(t *testing.T) {
+	now := time.Unix(1000, 0)
+	tests := []struct {
+		name          string
+		clockSkew     *types.ClockSkew
+		ts            time.Time
+		wantTS        time.Time
+		wantExceeded  uint64
+		wantCorrected uint64
+	}{
+		{
+			name:   "no_threshold",
+			ts:     now.Add(-time.Hour),
+			wantTS: now.Add(-time.Hour),
+		},
+		{
+			name:      "below_threshold",
+			clockSkew: &types.ClockSkew{Threshold: time.Minute, Correct: true},
+			ts:        now.Add(-30 * time.Second),
+			wantTS:    now.Add(-30 * time.Second),
+		},
+		{
+			name:         "detect_only",
+			clockSkew:    &types.ClockSkew{Threshold: time.Minute},
+			ts:           now.Add(time.Hour),
+			wantTS:       now.Add(time.Hour),
+			wantExceeded: 1,
+		},
+		{
+			name:          "correct",
+			clockSkew:     &types.ClockSkew{Threshold: time.Minute, Correct: true},
+			ts:            now.Add(-time.Hour),
+			wantTS:        now,
+			wantExceeded:  1,
+			wantCorrected: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := New()
+			tc := &types.TargetConfig{Name: "r1", ClockSkew: tt.clockSkew}
+			rsp := intResponse(tt.ts.UnixNano(), map[string]int64{"a": 1})
+			a.checkClockSkew(tc, rsp, now)
+			if got := rsp.GetUpdate().GetTimestamp(); got != tt.wantTS.UnixNano() {
+				t.Errorf("timestamp: got %d, want %d", got, tt.wantTS.UnixNano())
+			}
+			st := a.stats.snapshot().Targets["r1"]
+			if want := now.Sub(tt.ts).String(); st.ClockSkew != want {
+				t.Errorf("clock skew: got %q, want %q", st.ClockSkew, want)
+			}
+			if st.ClockSkewExceeded != tt.wantExceeded || st.CorrectedTimestamps != tt.wantCorrected {
+				t.Errorf("got exceeded=%d corrected=%d, want exceeded=%d corrected=%d",
+					st.ClockSkewExceeded, st.CorrectedTimestamps, tt.wantExceeded, tt.wantCorrected)
+			}
+		})
+	}
+}
@@ -17,12 +17,12 @@ import (
 	"fmt"
 	"net"
 	"net/http"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/formatters"
 	"github.com/openconfig/gnmic/pkg/lockers"
 )
 
@@ -55,6 +55,12 @@ func (a *App) InitLocker() error {
 				return err
 			}
 			a.locker = lock
+			if kv, ok := lock.(lockers.KV); ok {
+				formatters.SetKV(kv)
+			}
+			if a.Config.Clustering.LockerRateLimit > 0 {
+				a.lockerTicker = time.NewTicker(time.Second / time.Duration(a.Config.Clustering.LockerRateLimit))
+			}
 			return nil
 		}
 		return fmt.Errorf("unknown locker type %q", lockerType)
@@ -73,7 +79,7 @@ func (a *App) inCluster() bool {
 	return !(a.Config.Clustering == nil)
 }
 
-func (a *App) apiServiceRegistration() {
+func (a *App) apiServiceRegistration(ctx context.Context) {
 	addr, port, _ := net.SplitHostPort(a.Config.APIServer.Address)
 	p, _ := strconv.Atoi(port)
 
@@ -102,10 +108,10 @@ func (a *App) apiServiceRegistration() {
 	a.Logger.Printf("registering service %+v", serviceReg)
 	for {
 		select {
-		case <-a.ctx.Done():
+		case <-ctx.Done():
 			return
 		default:
-			err = a.locker.Register(a.ctx, serviceReg)
+			err = a.locker.Register(ctx, serviceReg)
 			if err != nil {
 				a.Logger.Printf("api service registration failed: %v", err)
 				time.Sleep(retryTimer)
@@ -121,17 +127,38 @@ func (a *App) startCluster() {
 		return
 	}
 
-	// register api service
-	go a.apiServiceRegistration()
+	// keep a local view of the targets locks
+	go a.watchTargetLocks(a.ctx)
+	// keep the API silences in sync with the other members
+	go a.watchSilences(a.ctx)
 
 	leaderKey := a.leaderKey()
 	var err error
 START:
+	if !a.components.waitEnabled(a.ctx, componentClustering) {
+		return
+	}
+	// canceled when the clustering participation is disabled
+	cctx, ccancel := context.WithCancel(a.ctx)
+	defer ccancel()
+	a.components.setCancel(componentClustering, ccancel)
+	// register api service
+	go a.apiServiceRegistration(cctx)
+LEADER:
 	// acquire leader key lock
 	for {
+		select {
+		case <-cctx.Done():
+			if a.ctx.Err() != nil {
+				return
+			}
+			a.leaveCluster(false)
+			goto START
+		default:
+		}
 		a.isLeader = false
 		err = nil
-		a.isLeader, err = a.locker.Lock(a.ctx, leaderKey, []byte(a.Config.Clustering.InstanceName))
+		a.isLeader, err = a.locker.Lock(cctx, leaderKey, []byte(a.Config.Clustering.InstanceName))
 		if err != nil {
 			a.Logger.Printf("failed to acquire leader lock: %v", err)
 			time.Sleep(retryTimer)
@@ -145,7 +172,7 @@ START:
 		a.Logger.Printf("%q became the leader", a.Config.Clustering.InstanceName)
 		break
 	}
-	ctx, cancel := context.WithCancel(a.ctx)
+	ctx, cancel := context.WithCancel(cctx)
 	defer cancel()
 	go func() {
 		go a.watchMembers(ctx)
@@ -154,6 +181,7 @@ START:
 		a.Logger.Printf("leader done waiting, starting loader and dispatching targets")
 		go a.startLoader(ctx)
 		go a.dispatchTargets(ctx)
+		go a.probeMembers(ctx)
 	}()
 
 	doneCh, errCh := a.locker.KeepLock(a.ctx, leaderKey)
@@ -162,14 +190,54 @@ START:
 		a.Logger.Printf("%q lost leader role", a.Config.Clustering.InstanceName)
 		cancel()
 		a.isLeader = false
-		goto START
+		goto LEADER
 	case err := <-errCh:
 		a.Logger.Printf("%q failed to maintain the leader key: %v", a.Config.Clustering.InstanceName, err)
 		cancel()
 		a.isLeader = false
+		goto LEADER
+	case <-cctx.Done():
+		cancel()
+		if a.ctx.Err() != nil {
+			return
+		}
+		a.isLeader = false
+		a.leaveCluster(true)
 		goto START
-	case <-a.ctx.Done():
-		return
+	}
+}
+
+// leaveCluster releases the leader lock if held and the targets locks of the instance,
+// and deregisters its API service, so that its targets are dispatched to the other members.
+func (a *App) leaveCluster(leader bool) {
+	a.Logger.Printf("%q leaving the cluster", a.Config.Clustering.InstanceName)
+	if leader {
+		err := a.locker.Unlock(a.ctx, a.leaderKey())
+		if err != nil {
+			a.Logger.Printf("failed to release the leader lock: %v", err)
+		}
+	}
+	err := a.locker.Deregister(a.Config.Clustering.InstanceName + "-api")
+	if err != nil {
+		a.Logger.Printf("failed to deregister the api service: %v", err)
+	}
+	a.operLock.RLock()
+	names := make([]string, 0, len(a.Targets))
+	for name := range a.Targets {
+		names = append(names, name)
+	}
+	a.operLock.RUnlock()
+	for _, name := range names {
+		a.operLock.Lock()
+		if cfn, ok := a.targetsLockFn[name]; ok {
+			cfn()
+			delete(a.targetsLockFn, name)
+		}
+		a.operLock.Unlock()
+		err = a.stopTarget(a.ctx, name)
+		if err != nil {
+			a.Logger.Printf("failed to stop target %q: %v", name, err)
+		}
 	}
 }
 
@@ -264,7 +332,9 @@ func (a *App) dispatchTargets(ctx context.Context) {
 			//a.m.RLock()
 			dctx, cancel := context.WithTimeout(ctx, a.Config.Clustering.TargetsWatchTimer)
 			for _, tc := range a.Config.Targets {
+				a.dispatchLock.Lock()
 				err = a.dispatchTarget(dctx, tc)
+				a.dispatchLock.Unlock()
 				if err != nil {
 					a.Logger.Printf("failed to dispatch target %q: %v", tc.Name, err)
 				}
@@ -296,7 +366,21 @@ func (a *App) dispatchTarget(ctx context.Context, tc *types.TargetConfig) error
 	if a.Config.Debug {
 		a.Logger.Printf("checking if %q is locked", tc.Name)
 	}
+	// skip the targets removed from the loader source
+	if a.targetTombstones.has(tc.Name) {
+		return nil
+	}
+	// skip the targets known to be locked
+	if a.targetLocks.isSynced() {
+		if _, ok := a.targetLocks.owner(tc.Name); ok {
+			return nil
+		}
+	}
 	key := fmt.Sprintf("gnmic/%s/targets/%s", a.Config.Clustering.ClusterName, tc.Name)
+	err := a.waitLockerOp(ctx)
+	if err != nil {
+		return err
+	}
 	locked, err := a.locker.IsLocked(ctx, key)
 	if err != nil {
 		return err
@@ -308,7 +392,8 @@ func (a *App) dispatchTarget(ctx context.Context, tc *types.TargetConfig) error
 		return nil
 	}
 	a.Logger.Printf("dispatching target %q", tc.Name)
-	denied := make([]string, 0)
+	// avoid the degraded members
+	denied := a.degradedServices()
 SELECTSERVICE:
 	service, err := a.selectService(tc.Tags, denied...)
 	if err != nil {
@@ -337,6 +422,10 @@ SELECTSERVICE:
 	a.Logger.Printf("[cluster-leader] waiting for lock %q to be acquired by %q", key, instanceName)
 	retries := 0
 WAIT:
+	err = a.waitLockerOp(ctx)
+	if err != nil {
+		return err
+	}
 	values, err := a.locker.List(ctx, key)
 	if err != nil {
 		a.Logger.Printf("failed getting value of %q: %v", key, err)
@@ -359,6 +448,7 @@ WAIT:
 	if instance, ok := values[key]; ok {
 		if instance == instanceName {
 			a.Logger.Printf("[cluster-leader] lock %q acquired by %q", key, instanceName)
+			a.targetLocks.set(tc.Name, instanceName)
 			return nil
 		}
 	}
@@ -431,22 +521,11 @@ func (a *App) selectService(tags []string, denied ...string) (*lockers.Service,
 }
 
 func (a *App) getInstancesLoad(instances ...string) (map[string]int, error) {
-	// read all current locks held by the cluster
-	locks, err := a.locker.List(a.ctx, fmt.Sprintf("gnmic/%s/targets", a.Config.Clustering.ClusterName))
+	// get the number of targets each instance has locked
+	load, err := a.getLocksCount(a.ctx)
 	if err != nil {
 		return nil, err
 	}
-	if a.Config.Debug {
-		a.Logger.Println("current locks:", locks)
-	}
-	load := make(map[string]int)
-	// using the read locks, calculate the number of targets each instance has locked
-	for _, instance := range locks {
-		if _, ok := load[instance]; !ok {
-			load[instance] = 0
-		}
-		load[instance]++
-	}
 	// for instances that are registered but do not have any lock,
 	// add a "0" load
 	for _, s := range a.apiServices {
@@ -484,17 +563,13 @@ func (a *App) getLowLoadInstance(load map[string]int) string {
 }
 
 func (a *App) getTargetToInstanceMapping() (map[string]string, error) {
-	locks, err := a.locker.List(a.ctx, fmt.Sprintf("gnmic/%s/targets", a.Config.Clustering.ClusterName))
+	locks, err := a.getTargetLocks(a.ctx)
 	if err != nil {
 		return nil, err
 	}
 	if a.Config.Debug {
 		a.Logger.Println("current locks:", locks)
 	}
-	for k, v := range locks {
-		delete(locks, k)
-		locks[filepath.Base(k)] = v
-	}
 	return locks, nil
 }
 
@@ -566,6 +641,7 @@ func (a *App) deleteTarget(ctx context.Context, name string) error {
 			errs = append(errs, err)
 			continue
 		}
+		a.setClusterAuth(req)
 
 		rsp, err := client.Do(req)
 		if err != nil {
@@ -583,6 +659,15 @@ func (a *App) deleteTarget(ctx context.Context, name string) error {
 	return fmt.Errorf("there was %d error(s) while deleting target %q", len(errs), name)
 }
 
+// setClusterAuth sets the bearer token of a request sent to another cluster member.
+// The members share the api-server configuration, the first admin token is used.
+func (a *App) setClusterAuth(req *http.Request) {
+	if a.Config.APIServer == nil || len(a.Config.APIServer.Tokens) == 0 {
+		return
+	}
+	req.Header.Set("Authorization", "Bearer "+a.Config.APIServer.Tokens[0])
+}
+
 func (a *App) assignTarget(ctx context.Context, tc *types.TargetConfig, service *lockers.Service) error {
 	// encode target config
 	buffer := new(bytes.Buffer)
@@ -612,6 +697,7 @@ func (a *App) assignTarget(ctx context.Context, tc *types.TargetConfig, service
 		return err
 	}
 	req.Header.Set("Content-Type", "application/json")
+	a.setClusterAuth(req)
 	resp, err := client.Do(req)
 	if err != nil {
 		return err
@@ -626,6 +712,7 @@ func (a *App) assignTarget(ctx context.Context, tc *types.TargetConfig, service
 	if err != nil {
 		return err
 	}
+	a.setClusterAuth(req)
 	resp, err = client.Do(req)
 	if err != nil {
 		return err
@@ -668,6 +755,7 @@ func (a *App) unassignTarget(ctx context.Context, name string, serviceID string)
 			a.Logger.Printf("failed to create HTTP request: %v", err)
 			continue
 		}
+		a.setClusterAuth(req)
 		rsp, err := client.Do(req)
 		if err != nil {
 			// don't close the body here since Body will be nil
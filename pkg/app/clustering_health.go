@@ -0,0 +1,275 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/api/target"
+	"github.com/openconfig/gnmic/pkg/config"
+	"github.com/openconfig/gnmic/pkg/lockers"
+)
+
+// memberHealth is the health report of a cluster member,
+// returned by its /api/v1/cluster/health endpoint and probed by the leader.
+type memberHealth struct {
+	Instance string `json:"instance"`
+	// number of targets locked by the member
+	Targets int `json:"targets"`
+	// total number of subscription errors of the locked targets
+	SubscriptionErrors uint64 `json:"subscription-errors"`
+	// locked targets with a subscription without subscribe stream
+	// and without notifications for `clustering.health-probe.stale-timeout`
+	StaleTargets []string `json:"stale-targets,omitempty"`
+}
+
+func (a *App) handleClusterHealthGet(w http.ResponseWriter, r *http.Request) {
+	if a.Config.Clustering == nil {
+		return
+	}
+	a.handlerCommonGet(w, a.localMemberHealth(time.Now()))
+}
+
+func (a *App) localMemberHealth(now time.Time) *memberHealth {
+	var staleTimeout time.Duration
+	if a.Config.Clustering.HealthProbe != nil {
+		staleTimeout = a.Config.Clustering.HealthProbe.StaleTimeout
+	}
+	st := a.stats.snapshot()
+	mh := &memberHealth{Instance: a.Config.Clustering.InstanceName}
+	a.operLock.RLock()
+	defer a.operLock.RUnlock()
+	for name, t := range a.Targets {
+		lockedAt, ok := a.targetsLockTime[name]
+		if !ok {
+			continue
+		}
+		mh.Targets++
+		var lastNotification time.Time
+		if ts, ok := st.Targets[name]; ok {
+			mh.SubscriptionErrors += ts.SubscriptionErrors
+			lastNotification = ts.lastNotification
+		}
+		if staleTimeout > 0 && targetStale(t, lockedAt, lastNotification, now, staleTimeout) {
+			mh.StaleTargets = append(mh.StaleTargets, name)
+		}
+	}
+	sort.Strings(mh.StaleTargets)
+	return mh
+}
+
+// targetStale returns true if the target has a subscription without subscribe stream
+// and did not receive any notification for staleTimeout since it was locked.
+func targetStale(t *target.Target, lockedAt, lastNotification, now time.Time, staleTimeout time.Duration) bool {
+	last := lockedAt
+	if lastNotification.After(last) {
+		last = lastNotification
+	}
+	if now.Sub(last) < staleTimeout {
+		return false
+	}
+	for _, active := range t.SubscriptionsState() {
+		if !active {
+			return true
+		}
+	}
+	return false
+}
+
+// memberProbe is the leader view of a probed member.
+type memberProbe struct {
+	failures int
+	errors   uint64
+	probedAt time.Time
+	degraded bool
+}
+
+// memberProbes tracks the health of the cluster members probed by the leader.
+type memberProbes struct {
+	m       *sync.RWMutex
+	members map[string]*memberProbe
+}
+
+func newMemberProbes() *memberProbes {
+	return &memberProbes{
+		m:       new(sync.RWMutex),
+		members: make(map[string]*memberProbe),
+	}
+}
+
+// record updates the member state with the result of a probe.
+// It returns a non empty reason if the member is degraded,
+// and whether all its targets should be moved or only the stale ones.
+func (mp *memberProbes) record(instance string, h *memberHealth, err error, now time.Time, cfg *config.ClusterHealthProbe) (string, bool) {
+	mp.m.Lock()
+	defer mp.m.Unlock()
+	p, ok := mp.members[instance]
+	if !ok {
+		p = new(memberProbe)
+		mp.members[instance] = p
+	}
+	if err != nil {
+		p.failures++
+		p.degraded = p.failures >= cfg.FailureThreshold
+		if p.degraded {
+			return fmt.Sprintf("%d consecutive failed probes: %v", p.failures, err), true
+		}
+		return "", false
+	}
+	p.failures = 0
+	var rate float64
+	if !p.probedAt.IsZero() && h.SubscriptionErrors > p.errors {
+		rate = float64(h.SubscriptionErrors-p.errors) / now.Sub(p.probedAt).Seconds()
+	}
+	p.errors = h.SubscriptionErrors
+	p.probedAt = now
+	switch {
+	case cfg.MaxErrorRate > 0 && rate > cfg.MaxErrorRate:
+		p.degraded = true
+		return fmt.Sprintf("subscription error rate %.2f/s above %.2f/s", rate, cfg.MaxErrorRate), true
+	case len(h.StaleTargets) > 0:
+		p.degraded = true
+		return fmt.Sprintf("%d stale target(s)", len(h.StaleTargets)), false
+	}
+	p.degraded = false
+	return "", false
+}
+
+func (mp *memberProbes) isDegraded(instance string) bool {
+	mp.m.RLock()
+	defer mp.m.RUnlock()
+	p, ok := mp.members[instance]
+	return ok && p.degraded
+}
+
+// keep deletes the state of the members not in instances.
+func (mp *memberProbes) keep(instances map[string]struct{}) {
+	mp.m.Lock()
+	defer mp.m.Unlock()
+	for n := range mp.members {
+		if _, ok := instances[n]; !ok {
+			delete(mp.members, n)
+		}
+	}
+}
+
+// degradedServices returns the IDs of the registered API services of the degraded members.
+// It returns nil if all the members are degraded so that the targets are still dispatched.
+func (a *App) degradedServices() []string {
+	var degraded []string
+	for id := range a.apiServices {
+		if a.memberProbes.isDegraded(strings.TrimSuffix(id, "-api")) {
+			degraded = append(degraded, id)
+		}
+	}
+	if len(degraded) == len(a.apiServices) {
+		return nil
+	}
+	return degraded
+}
+
+// probeMembers periodically probes the health of the cluster members
+// and moves the targets away from the degraded ones.
+// It runs on the leader until ctx is done.
+func (a *App) probeMembers(ctx context.Context) {
+	hp := a.Config.Clustering.HealthProbe
+	if hp == nil {
+		return
+	}
+	ticker := time.NewTicker(hp.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		a.configLock.RLock()
+		services := make([]*lockers.Service, 0, len(a.apiServices))
+		for _, s := range a.apiServices {
+			services = append(services, s)
+		}
+		a.configLock.RUnlock()
+		instances := make(map[string]struct{}, len(services))
+		for _, s := range services {
+			instance := strings.TrimSuffix(s.ID, "-api")
+			instances[instance] = struct{}{}
+			h, err := a.probeMember(ctx, s, hp.Timeout)
+			reason, all := a.memberProbes.record(instance, h, err, time.Now(), hp)
+			if reason == "" {
+				continue
+			}
+			a.Logger.Printf("[cluster-leader] member %q is degraded: %s", instance, reason)
+			var targets []string
+			if all {
+				it, _, err := a.getInstancesTargets(ctx)
+				if err != nil {
+					a.Logger.Printf("[cluster-leader] failed to get member %q targets: %v", instance, err)
+					continue
+				}
+				targets = it[instance]
+			} else {
+				targets = h.StaleTargets
+			}
+			a.redistributeTargets(ctx, instance, targets)
+		}
+		a.memberProbes.keep(instances)
+	}
+}
+
+func (a *App) probeMember(ctx context.Context, s *lockers.Service, timeout time.Duration) (*memberHealth, error) {
+	client, scheme := apiServiceClient(s, timeout)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s://%s/api/v1/cluster/health", scheme, s.Address), nil)
+	if err != nil {
+		return nil, err
+	}
+	a.setClusterAuth(req)
+	rsp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status code=%d", rsp.StatusCode)
+	}
+	h := new(memberHealth)
+	err = json.NewDecoder(rsp.Body).Decode(h)
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// redistributeTargets unassigns the targets from the degraded member instance,
+// the dispatching loop assigns them to the other members.
+func (a *App) redistributeTargets(ctx context.Context, instance string, targets []string) {
+	if len(targets) == 0 {
+		return
+	}
+	if len(a.degradedServices()) == 0 {
+		a.Logger.Printf("[cluster-leader] no healthy member to move the targets of %q to", instance)
+		return
+	}
+	a.dispatchLock.Lock()
+	defer a.dispatchLock.Unlock()
+	for _, name := range targets {
+		a.Logger.Printf("[cluster-leader] unassigning target %q from degraded member %q", name, instance)
+		err := a.unassignTarget(ctx, name, instance+"-api")
+		if err != nil {
+			a.Logger.Printf("[cluster-leader] failed to unassign target %q from %q: %v", name, instance, err)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/api/target"
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/config"
+)
+
+func TestMemberProbesRecord(t *testing.T) {
+	cfg := &config.ClusterHealthProbe{
+		FailureThreshold: 2,
+		MaxErrorRate:     1,
+	}
+	mp := newMemberProbes()
+	now := time.Now()
+	errProbe := errors.New("connection refused")
+
+	steps := []struct {
+		name   string
+		health *memberHealth
+		err    error
+		after  time.Duration
+		reason bool
+		all    bool
+	}{
+		{name: "healthy", health: &memberHealth{SubscriptionErrors: 10}},
+		{name: "low_error_rate", health: &memberHealth{SubscriptionErrors: 15}, after: 10 * time.Second},
+		{name: "high_error_rate", health: &memberHealth{SubscriptionErrors: 50}, after: 10 * time.Second, reason: true, all: true},
+		{name: "recovered", health: &memberHealth{SubscriptionErrors: 50}, after: 10 * time.Second},
+		{name: "stale_targets", health: &memberHealth{SubscriptionErrors: 50, StaleTargets: []string{"t1"}}, after: 10 * time.Second, reason: true},
+		{name: "first_failure", err: errProbe, after: 10 * time.Second},
+		{name: "second_failure", err: errProbe, after: 10 * time.Second, reason: true, all: true},
+	}
+	for _, s := range steps {
+		now = now.Add(s.after)
+		reason, all := mp.record("gnmic1", s.health, s.err, now, cfg)
+		if (reason != "") != s.reason || all != s.all {
+			t.Errorf("step %q: got reason=%q all=%v", s.name, reason, all)
+		}
+		if mp.isDegraded("gnmic1") != s.reason {
+			t.Errorf("step %q: unexpected degraded state", s.name)
+		}
+	}
+	mp.keep(map[string]struct{}{})
+	if mp.isDegraded("gnmic1") {
+		t.Errorf("removed member should not be degraded")
+	}
+}
+
+func TestTargetStale(t *testing.T) {
+	tg := target.NewTarget(&types.TargetConfig{Name: "t1"})
+	tg.Subscriptions["sub1"] = &types.SubscriptionConfig{Name: "sub1"}
+	now := time.Now()
+	timeout := time.Minute
+	if targetStale(tg, now.Add(-30*time.Second), time.Time{}, now, timeout) {
+		t.Errorf("target locked recently should not be stale")
+	}
+	if !targetStale(tg, now.Add(-2*time.Minute), time.Time{}, now, timeout) {
+		t.Errorf("target without subscribe stream nor notifications should be stale")
+	}
+	if targetStale(tg, now.Add(-2*time.Minute), now.Add(-10*time.Second), now, timeout) {
+		t.Errorf("target with recent notifications should not be stale")
+	}
+	tg.Subscriptions = map[string]*types.SubscriptionConfig{}
+	if targetStale(tg, now.Add(-2*time.Minute), time.Time{}, now, timeout) {
+		t.Errorf("target without subscriptions should not be stale")
+	}
+}
@@ -0,0 +1,234 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/lockers"
+)
+
+// targetLocks is a local view of the targets locks held by the cluster members.
+// It is kept up to date by watching (or periodically listing) the locker,
+// so that the dispatching logic and the clustering API endpoints
+// do not list all the locks on each use.
+type targetLocks struct {
+	m      *sync.RWMutex
+	synced bool
+	// target name to instance name
+	owners map[string]string
+	// instance name to number of locked targets
+	load map[string]int
+}
+
+func newTargetLocks() *targetLocks {
+	return &targetLocks{
+		m:      new(sync.RWMutex),
+		owners: make(map[string]string),
+		load:   make(map[string]int),
+	}
+}
+
+// update applies the differences between the current view
+// and the given locks snapshot, indexed by target name.
+func (tl *targetLocks) update(snapshot map[string]string) {
+	tl.m.Lock()
+	defer tl.m.Unlock()
+	for t, instance := range tl.owners {
+		if ni, ok := snapshot[t]; !ok || ni != instance {
+			tl.remove(t)
+		}
+	}
+	for t, instance := range snapshot {
+		if _, ok := tl.owners[t]; !ok {
+			tl.add(t, instance)
+		}
+	}
+	tl.synced = true
+}
+
+// set records a lock acquired by instance on target.
+func (tl *targetLocks) set(target, instance string) {
+	tl.m.Lock()
+	defer tl.m.Unlock()
+	tl.remove(target)
+	tl.add(target, instance)
+}
+
+func (tl *targetLocks) add(target, instance string) {
+	tl.owners[target] = instance
+	tl.load[instance]++
+}
+
+func (tl *targetLocks) remove(target string) {
+	instance, ok := tl.owners[target]
+	if !ok {
+		return
+	}
+	delete(tl.owners, target)
+	tl.load[instance]--
+	if tl.load[instance] <= 0 {
+		delete(tl.load, instance)
+	}
+}
+
+func (tl *targetLocks) isSynced() bool {
+	tl.m.RLock()
+	defer tl.m.RUnlock()
+	return tl.synced
+}
+
+func (tl *targetLocks) owner(target string) (string, bool) {
+	tl.m.RLock()
+	defer tl.m.RUnlock()
+	instance, ok := tl.owners[target]
+	return instance, ok
+}
+
+// mapping returns a copy of the target to instance mapping.
+func (tl *targetLocks) mapping() map[string]string {
+	tl.m.RLock()
+	defer tl.m.RUnlock()
+	rs := make(map[string]string, len(tl.owners))
+	for t, instance := range tl.owners {
+		rs[t] = instance
+	}
+	return rs
+}
+
+// instancesLoad returns a copy of the number of targets locked by each instance.
+func (tl *targetLocks) instancesLoad() map[string]int {
+	tl.m.RLock()
+	defer tl.m.RUnlock()
+	rs := make(map[string]int, len(tl.load))
+	for instance, l := range tl.load {
+		rs[instance] = l
+	}
+	return rs
+}
+
+func (a *App) targetsLockPrefix() string {
+	return "gnmic/" + a.Config.Clustering.ClusterName + "/targets"
+}
+
+// trimLockKeys converts a locker List result to a target to instance mapping.
+func trimLockKeys(prefix string, locks map[string]string) map[string]string {
+	rs := make(map[string]string, len(locks))
+	for k, v := range locks {
+		rs[strings.TrimPrefix(k, prefix+"/")] = v
+	}
+	return rs
+}
+
+// watchTargetLocks keeps the local view of the targets locks in sync with the locker.
+// It uses the locker watch API if available, otherwise it lists
+// the locks every `clustering.locks-watch-timer`.
+func (a *App) watchTargetLocks(ctx context.Context) {
+	prefix := a.targetsLockPrefix()
+	if w, ok := a.locker.(lockers.Watcher); ok {
+		ch := make(chan map[string]string)
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case locks := <-ch:
+					a.targetLocks.update(trimLockKeys(prefix, locks))
+				}
+			}
+		}()
+		for {
+			err := w.WatchPrefix(ctx, prefix, ch, a.Config.Clustering.ServicesWatchTimer)
+			if ctx.Err() != nil {
+				return
+			}
+			a.Logger.Printf("failed to watch targets locks: %v", err)
+			time.Sleep(retryTimer)
+		}
+	}
+	ticker := time.NewTicker(a.Config.Clustering.LocksWatchTimer)
+	defer ticker.Stop()
+	for {
+		locks, err := a.locker.List(ctx, prefix)
+		if err != nil {
+			a.Logger.Printf("failed to list targets locks: %v", err)
+		} else {
+			a.targetLocks.update(trimLockKeys(prefix, locks))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// getTargetLocks returns the target to instance mapping,
+// from the local view if it is synced with the locker, otherwise from the locker.
+func (a *App) getTargetLocks(ctx context.Context) (map[string]string, error) {
+	if a.targetLocks.isSynced() {
+		return a.targetLocks.mapping(), nil
+	}
+	prefix := a.targetsLockPrefix()
+	locks, err := a.locker.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	return trimLockKeys(prefix, locks), nil
+}
+
+// getLocksCount returns the number of targets locked by each instance.
+func (a *App) getLocksCount(ctx context.Context) (map[string]int, error) {
+	if a.targetLocks.isSynced() {
+		return a.targetLocks.instancesLoad(), nil
+	}
+	locks, err := a.getTargetLocks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	load := make(map[string]int)
+	for _, instance := range locks {
+		load[instance]++
+	}
+	return load, nil
+}
+
+// getInstancesTargets returns the sorted list of targets locked by each instance.
+func (a *App) getInstancesTargets(ctx context.Context) (map[string][]string, int, error) {
+	locks, err := a.getTargetLocks(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	rs := make(map[string][]string)
+	for t, instance := range locks {
+		rs[instance] = append(rs[instance], t)
+	}
+	for _, ts := range rs {
+		sort.Strings(ts)
+	}
+	return rs, len(locks), nil
+}
+
+// waitLockerOp blocks until the next locker operation
+// is allowed by `clustering.locker-rate-limit`.
+func (a *App) waitLockerOp(ctx context.Context) error {
+	if a.lockerTicker == nil {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-a.lockerTicker.C:
+		return nil
+	}
+}
@@ -0,0 +1,70 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTargetLocks(t *testing.T) {
+	tl := newTargetLocks()
+	if tl.isSynced() {
+		t.Fatalf("new target locks should not be synced")
+	}
+	tl.update(map[string]string{
+		"t1": "gnmic1",
+		"t2": "gnmic1",
+		"t3": "gnmic2",
+	})
+	if !tl.isSynced() {
+		t.Fatalf("target locks should be synced after an update")
+	}
+	if diff := cmp.Diff(map[string]int{"gnmic1": 2, "gnmic2": 1}, tl.instancesLoad()); diff != "" {
+		t.Errorf("unexpected load after first update: %s", diff)
+	}
+	// t1 moved, t2 released, t4 added
+	tl.update(map[string]string{
+		"t1": "gnmic2",
+		"t3": "gnmic2",
+		"t4": "gnmic3",
+	})
+	if diff := cmp.Diff(map[string]int{"gnmic2": 2, "gnmic3": 1}, tl.instancesLoad()); diff != "" {
+		t.Errorf("unexpected load after second update: %s", diff)
+	}
+	if diff := cmp.Diff(map[string]string{"t1": "gnmic2", "t3": "gnmic2", "t4": "gnmic3"}, tl.mapping()); diff != "" {
+		t.Errorf("unexpected mapping after second update: %s", diff)
+	}
+	tl.set("t4", "gnmic1")
+	tl.set("t5", "gnmic1")
+	if diff := cmp.Diff(map[string]int{"gnmic1": 2, "gnmic2": 2}, tl.instancesLoad()); diff != "" {
+		t.Errorf("unexpected load after set: %s", diff)
+	}
+	if owner, ok := tl.owner("t5"); !ok || owner != "gnmic1" {
+		t.Errorf("unexpected owner of t5: %q, %v", owner, ok)
+	}
+	if _, ok := tl.owner("t2"); ok {
+		t.Errorf("t2 should not be locked")
+	}
+}
+
+func TestTrimLockKeys(t *testing.T) {
+	got := trimLockKeys("gnmic/c1/targets", map[string]string{
+		"gnmic/c1/targets/t1":             "gnmic1",
+		"gnmic/c1/targets/10.0.0.1:57400": "gnmic2",
+	})
+	want := map[string]string{
+		"t1":             "gnmic1",
+		"10.0.0.1:57400": "gnmic2",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected result: %s", diff)
+	}
+}
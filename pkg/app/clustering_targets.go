@@ -0,0 +1,297 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/openconfig/gnmic/pkg/lockers"
+)
+
+type clusterTarget struct {
+	Name     string `json:"name"`
+	Instance string `json:"instance"`
+	// time at which the instance acquired the target lock
+	LockedSince   *time.Time                  `json:"locked-since,omitempty"`
+	LockAge       string                      `json:"lock-age,omitempty"`
+	ConnState     string                      `json:"connection-state,omitempty"`
+	Subscriptions []clusterTargetSubscription `json:"subscriptions,omitempty"`
+}
+
+type clusterTargetSubscription struct {
+	Name string `json:"name"`
+	// true if the subscription has an active subscribe stream
+	Active bool `json:"active"`
+}
+
+type clusterTargetMoveRequest struct {
+	Instance string `json:"instance,omitempty"`
+}
+
+// handleClusterTargetGet returns the instance owning a target lock,
+// the lock age and the target subscriptions health.
+// The details are built by the owner instance, the request is forwarded to it if needed.
+func (a *App) handleClusterTargetGet(w http.ResponseWriter, r *http.Request) {
+	if a.Config.Clustering == nil {
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if !a.targetVisible(r, id) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{fmt.Sprintf("target %q not found", id)}})
+		return
+	}
+	locks, err := a.getTargetLocks(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	instance, ok := locks[id]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{fmt.Sprintf("target %q is not locked by any instance", id)}})
+		return
+	}
+	if instance != a.Config.Clustering.InstanceName && r.URL.Query().Get("local") != "true" {
+		a.forwardRequest(w, r, instance, r.URL.Path+"?local=true", nil)
+		return
+	}
+	a.handlerCommonGet(w, a.localClusterTarget(id, instance))
+}
+
+func (a *App) localClusterTarget(name, instance string) *clusterTarget {
+	ct := &clusterTarget{
+		Name:     name,
+		Instance: instance,
+	}
+	a.operLock.RLock()
+	defer a.operLock.RUnlock()
+	if lt, ok := a.targetsLockTime[name]; ok {
+		ct.LockedSince = &lt
+		ct.LockAge = time.Since(lt).Round(time.Second).String()
+	}
+	t, ok := a.Targets[name]
+	if !ok {
+		return ct
+	}
+	ct.ConnState = t.ConnState()
+	for sub, active := range t.SubscriptionsState() {
+		ct.Subscriptions = append(ct.Subscriptions, clusterTargetSubscription{Name: sub, Active: active})
+	}
+	sort.Slice(ct.Subscriptions, func(i, j int) bool {
+		return ct.Subscriptions[i].Name < ct.Subscriptions[j].Name
+	})
+	return ct
+}
+
+// handleClusterTargetMovePost moves a target to the instance named in the request body.
+// The move is run by the leader, the request is forwarded to it if needed.
+func (a *App) handleClusterTargetMovePost(w http.ResponseWriter, r *http.Request) {
+	if a.Config.Clustering == nil {
+		return
+	}
+	id := mux.Vars(r)["id"]
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	defer r.Body.Close()
+	req := new(clusterTargetMoveRequest)
+	err = json.Unmarshal(body, req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	if req.Instance == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{"missing instance name"}})
+		return
+	}
+	if !a.isLeader {
+		leaderKey := a.leaderKey()
+		leader, err := a.locker.List(r.Context(), leaderKey)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+			return
+		}
+		if leader[leaderKey] == "" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(APIErrors{Errors: []string{"cluster has no leader"}})
+			return
+		}
+		a.forwardRequest(w, r, leader[leaderKey], r.URL.Path, body)
+		return
+	}
+	err = a.moveTarget(r.Context(), id, req.Instance)
+	if err != nil {
+		if errors.Is(err, errNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	a.handlerCommonGet(w, &clusterTarget{Name: id, Instance: req.Instance})
+}
+
+// moveTarget unassigns the target from its current instance
+// and assigns it to instance, waiting for the new lock to be acquired.
+func (a *App) moveTarget(ctx context.Context, name, instance string) error {
+	a.configLock.RLock()
+	tc, ok := a.Config.Targets[name]
+	service, sok := a.apiServices[instance+"-api"]
+	a.configLock.RUnlock()
+	if !ok {
+		return fmt.Errorf("target %q %w", name, errNotFound)
+	}
+	if !sok {
+		return fmt.Errorf("instance %q %w", instance, errNotFound)
+	}
+	// prevent the dispatching loop from assigning the target
+	// while it is being moved
+	a.dispatchLock.Lock()
+	defer a.dispatchLock.Unlock()
+
+	key := a.targetLockKey(name)
+	values, err := a.locker.List(ctx, key)
+	if err != nil {
+		return err
+	}
+	owner := values[key]
+	if owner == instance {
+		return nil
+	}
+	if owner != "" {
+		a.Logger.Printf("[cluster-leader] moving target %q from %q to %q", name, owner, instance)
+		err = a.unassignTarget(ctx, name, owner+"-api")
+		if err != nil {
+			return err
+		}
+		err = a.waitTargetLock(ctx, key, "")
+		if err != nil {
+			return fmt.Errorf("target %q not released by %q: %w", name, owner, err)
+		}
+	}
+	err = a.assignTarget(ctx, tc, service)
+	if err != nil {
+		return err
+	}
+	err = a.waitTargetLock(ctx, key, instance)
+	if err != nil {
+		return fmt.Errorf("target %q not locked by %q: %w", name, instance, err)
+	}
+	a.targetLocks.set(name, instance)
+	a.Logger.Printf("[cluster-leader] target %q moved to %q", name, instance)
+	return nil
+}
+
+// waitTargetLock waits for the lock key to be held by instance,
+// or to be released if instance is empty,
+// for up to `clustering.target-assignment-timeout`.
+func (a *App) waitTargetLock(ctx context.Context, key, instance string) error {
+	ctx, cancel := context.WithTimeout(ctx, a.Config.Clustering.TargetAssignmentTimeout)
+	defer cancel()
+	ticker := time.NewTicker(lockWaitTime)
+	defer ticker.Stop()
+	for {
+		values, err := a.locker.List(ctx, key)
+		if err == nil && values[key] == instance {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// forwardRequest sends the API request r to the cluster instance API,
+// using path and body, and copies the response to w.
+func (a *App) forwardRequest(w http.ResponseWriter, r *http.Request, instance, path string, body []byte) {
+	services, err := a.locker.GetServices(r.Context(), fmt.Sprintf("%s-%s", a.Config.Clustering.ClusterName, apiServiceName), nil)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	var service *lockers.Service
+	for _, s := range services {
+		if s.ID == instance+"-api" {
+			service = s
+			break
+		}
+	}
+	if service == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{fmt.Sprintf("instance %q API service not found", instance)}})
+		return
+	}
+	client, scheme := apiServiceClient(service, defaultHTTPClientTimeout)
+	req, err := http.NewRequestWithContext(r.Context(), r.Method, fmt.Sprintf("%s://%s%s", scheme, service.Address, path), bytes.NewReader(body))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	rsp, err := client.Do(req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadGateway)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	defer rsp.Body.Close()
+	w.WriteHeader(rsp.StatusCode)
+	io.Copy(w, rsp.Body)
+}
+
+// apiServiceClient returns an HTTP client and the URL scheme
+// to use to reach the API service s.
+func apiServiceClient(s *lockers.Service, timeout time.Duration) (*http.Client, string) {
+	scheme := "http"
+	client := &http.Client{
+		Timeout: timeout,
+	}
+	for _, t := range s.Tags {
+		if strings.HasPrefix(t, "protocol=") {
+			scheme = strings.Split(t, "=")[1]
+			break
+		}
+	}
+	if scheme == "https" {
+		client.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		}
+	}
+	return client, scheme
+}
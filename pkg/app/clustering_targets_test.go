@@ -0,0 +1,175 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/api/target"
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/config"
+	"github.com/openconfig/gnmic/pkg/lockers"
+)
+
+func newClusteredApp(t *testing.T) *App {
+	a := New()
+	a.Config.FileConfig.Set("clustering", map[string]interface{}{
+		"cluster-name":  "c1",
+		"instance-name": "gnmic1",
+		"locker": map[string]interface{}{
+			"type": "consul",
+		},
+	})
+	err := a.Config.GetClustering()
+	if err != nil {
+		t.Fatalf("failed to get clustering config: %v", err)
+	}
+	a.Config.Targets = map[string]*types.TargetConfig{
+		"t1": {Name: "t1"},
+		"t2": {Name: "t2"},
+	}
+	a.routes()
+	return a
+}
+
+func TestClusterTargetGet(t *testing.T) {
+	a := newClusteredApp(t)
+	a.targetLocks.update(map[string]string{"t1": "gnmic1"})
+	tg := target.NewTarget(a.Config.Targets["t1"])
+	tg.Subscriptions["sub2"] = &types.SubscriptionConfig{Name: "sub2"}
+	tg.Subscriptions["sub1"] = &types.SubscriptionConfig{Name: "sub1"}
+	a.Targets["t1"] = tg
+	a.targetsLockTime["t1"] = time.Now().Add(-time.Minute)
+
+	rec := apiRequest(a, http.MethodGet, "/api/v1/cluster/targets/t1", "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+	}
+	ct := new(clusterTarget)
+	err := json.Unmarshal(rec.Body.Bytes(), ct)
+	if err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if ct.Instance != "gnmic1" {
+		t.Errorf("unexpected instance %q", ct.Instance)
+	}
+	if ct.LockedSince == nil || ct.LockAge != "1m0s" {
+		t.Errorf("unexpected lock age %q", ct.LockAge)
+	}
+	if len(ct.Subscriptions) != 2 || ct.Subscriptions[0].Name != "sub1" || ct.Subscriptions[0].Active {
+		t.Errorf("unexpected subscriptions: %+v", ct.Subscriptions)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{name: "not_locked", method: http.MethodGet, path: "/api/v1/cluster/targets/t2", status: http.StatusNotFound},
+		{name: "unknown_target", method: http.MethodGet, path: "/api/v1/cluster/targets/t3", status: http.StatusNotFound},
+		{name: "move_missing_instance", method: http.MethodPost, path: "/api/v1/cluster/targets/t1/move", body: "{}", status: http.StatusBadRequest},
+		{name: "move_bad_body", method: http.MethodPost, path: "/api/v1/cluster/targets/t1/move", body: "{", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := apiRequest(a, tt.method, tt.path, "", tt.body)
+			if rec.Code != tt.status {
+				t.Errorf("got status %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestMoveTargetNotFound(t *testing.T) {
+	a := newClusteredApp(t)
+	a.isLeader = true
+	for _, name := range []string{"t3", "t1"} {
+		rec := apiRequest(a, http.MethodPost, "/api/v1/cluster/targets/"+name+"/move", "", `{"instance":"gnmic2"}`)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("target %q: got status %d, want %d: %s", name, rec.Code, http.StatusNotFound, rec.Body.String())
+		}
+	}
+}
+
+// statusRecorder records the status codes returned by a cluster member API per request.
+type statusRecorder struct {
+	m        sync.Mutex
+	statuses map[string]int
+}
+
+func (s *statusRecorder) wrap(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		s.m.Lock()
+		s.statuses[r.Method+" "+r.URL.Path] = rec.Code
+		s.m.Unlock()
+		for k, v := range rec.Header() {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(rec.Code)
+		w.Write(rec.Body.Bytes())
+	})
+}
+
+func TestClusterRequestsAuth(t *testing.T) {
+	withTokens := func(a *App) *App {
+		a.Config.APIServer = &config.APIServer{Tokens: []string{"admin"}}
+		a.Config.Namespaces = map[string]*config.Namespace{"team1": {Tokens: []string{"tk1"}}}
+		a.routes()
+		return a
+	}
+	member := withTokens(newClusteredApp(t))
+	sr := &statusRecorder{statuses: make(map[string]int)}
+	srv := httptest.NewServer(sr.wrap(member.router))
+	defer srv.Close()
+	leader := withTokens(newClusteredApp(t))
+	service := &lockers.Service{ID: "gnmic2-api", Address: strings.TrimPrefix(srv.URL, "http://")}
+	leader.apiServices = map[string]*lockers.Service{service.ID: service}
+
+	ctx := context.Background()
+	if _, err := leader.probeMember(ctx, service, time.Second); err != nil {
+		t.Errorf("failed to probe member: %v", err)
+	}
+	// no subscriptions, the target is not started
+	err := leader.assignTarget(ctx, &types.TargetConfig{Name: "t3", Address: "127.0.0.1:1"}, service)
+	if err != nil {
+		t.Errorf("failed to assign target: %v", err)
+	}
+	leader.unassignTarget(ctx, "t3", service.ID)
+	leader.deleteTarget(ctx, "t3")
+
+	for _, req := range []string{
+		"GET /api/v1/cluster/health",
+		"POST /api/v1/config/targets",
+		"POST /api/v1/targets/t3",
+		"DELETE /api/v1/targets/t3",
+		"DELETE /api/v1/config/targets/t3",
+	} {
+		sr.m.Lock()
+		status, ok := sr.statuses[req]
+		sr.m.Unlock()
+		if !ok {
+			t.Errorf("%s: request not received", req)
+			continue
+		}
+		if status == http.StatusUnauthorized || status == http.StatusForbidden {
+			t.Errorf("%s: request not authorized: status %d", req, status)
+		}
+	}
+}
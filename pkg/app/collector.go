@@ -15,6 +15,7 @@ import (
 	"io"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/openconfig/gnmi/proto/gnmi"
 	"google.golang.org/protobuf/proto"
@@ -22,6 +23,7 @@ import (
 	"github.com/openconfig/gnmic/pkg/api/target"
 	"github.com/openconfig/gnmic/pkg/api/types"
 	"github.com/openconfig/gnmic/pkg/api/utils"
+	"github.com/openconfig/gnmic/pkg/formatters"
 	"github.com/openconfig/gnmic/pkg/outputs"
 )
 
@@ -36,6 +38,10 @@ func (a *App) StartCollector(ctx context.Context) {
 			o.Close()
 		}
 	}()
+	exporters := newExportShards(ctx, a.Config.ExportWorkers, func(j *exportJob) {
+		a.Export(j.ctx, j.rsp, j.m, j.outs...)
+	})
+	a.exporters.Store(exporters)
 
 	for t := range a.targetsChan {
 		if a.Config.Debug {
@@ -63,59 +69,105 @@ func (a *App) StartCollector(ctx context.Context) {
 			remainingOnceSubscriptions := numOnceSubscriptions
 			numSubscriptions := len(t.Subscriptions)
 			rspChan, errChan := t.ReadSubscriptions()
-			for {
-				select {
-				case rsp := <-rspChan:
-					subscribeResponseReceivedCounter.WithLabelValues(t.Config.Name, rsp.SubscriptionConfig.Name).Add(1)
-					if a.Config.Debug {
-						a.Logger.Printf("target %q: gNMI Subscribe Response: %+v", t.Config.Name, rsp)
-					}
-					err := t.DecodeProtoBytes(rsp.Response)
-					if err != nil {
-						a.Logger.Printf("target %q: failed to decode proto bytes: %v", t.Config.Name, err)
-						continue
-					}
-					m := outputs.Meta{
-						"source":            t.Config.Name,
-						"format":            a.Config.Format,
-						"subscription-name": rsp.SubscriptionName,
-					}
-					if rsp.SubscriptionConfig.Target != "" {
-						m["subscription-target"] = rsp.SubscriptionConfig.Target
-					}
-					for k, v := range t.Config.EventTags {
+			prioChan := t.ReadPrioritySubscriptions()
+			// handleResponse returns true once all the once subscriptions are done
+			handleResponse := func(rsp *target.SubscribeResponse) bool {
+				subscribeResponseReceivedCounter.WithLabelValues(t.Config.Name, rsp.SubscriptionConfig.Name).Add(1)
+				if a.Config.Debug {
+					a.Logger.Printf("target %q: gNMI Subscribe Response: %+v", t.Config.Name, rsp)
+				}
+				if _, ok := rsp.Response.GetResponse().(*gnmi.SubscribeResponse_Update); ok {
+					a.stats.notificationReceived(t.Config.Name)
+				}
+				err := t.DecodeProtoBytes(rsp.Response)
+				if err != nil {
+					a.Logger.Printf("target %q: failed to decode proto bytes: %v", t.Config.Name, err)
+					a.stats.targetDropped(t.Config.Name, dropReasonDecodeError)
+					return false
+				}
+				applyProfile(t.Config, rsp.Response)
+				a.checkClockSkew(t.Config, rsp.Response, time.Now())
+				m := outputs.Meta{
+					"source":            t.Config.Name,
+					"format":            a.Config.Format,
+					"subscription-name": rsp.SubscriptionName,
+				}
+				if rsp.SubscriptionConfig.Target != "" {
+					m["subscription-target"] = rsp.SubscriptionConfig.Target
+				}
+				if rsp.StateSync {
+					m["state-sync"] = "true"
+				}
+				for k, v := range t.Config.Vars {
+					m[formatters.MetaVarPrefix+k] = v
+					if _, ok := m[k]; !ok && t.Config.VarsEventTags {
 						m[k] = v
 					}
+				}
+				for k, v := range t.Config.EventTags {
+					m[k] = v
+				}
+				a.evaluateSLOs(ctx, rsp.Response, m, time.Now())
+				a.trackSessions(ctx, rsp.Response, m, time.Now())
+				a.updateInventory(rsp.Response, m, time.Now())
 
-					// Allow overridden outputs per subscription
-					// If both target and subscription have a specified Output, the subscription's Output will be used
-					var outs []string
-					if len(rsp.SubscriptionConfig.Outputs) > 0 {
-						outs = rsp.SubscriptionConfig.Outputs
-					} else {
-						outs = t.Config.Outputs
-					}
+				// Allow overridden outputs per subscription
+				// If both target and subscription have a specified Output, the subscription's Output will be used
+				var outs []string
+				if len(rsp.SubscriptionConfig.Outputs) > 0 {
+					outs = rsp.SubscriptionConfig.Outputs
+				} else {
+					outs = t.Config.Outputs
+				}
 
+				a.observeSample(t.Config.Name, rsp)
+				a.recordResponse(rsp.Response, m)
+				a.capturePromptEvents(rsp.Response, m)
+				a.updateUIState(rsp.Response, m)
+				if a.subscriptionMode(rsp.SubscriptionName) == subscriptionModeONCE {
+					a.Export(ctx, rsp.Response, m, outs...)
+				} else {
+					exporters.enqueue(&exportJob{ctx: ctx, rsp: rsp.Response, m: m, outs: outs})
+				}
+				if remainingOnceSubscriptions > 0 {
 					if a.subscriptionMode(rsp.SubscriptionName) == subscriptionModeONCE {
-						a.Export(ctx, rsp.Response, m, outs...)
-					} else {
-						go a.Export(ctx, rsp.Response, m, outs...)
-					}
-					if remainingOnceSubscriptions > 0 {
-						if a.subscriptionMode(rsp.SubscriptionName) == subscriptionModeONCE {
-							switch rsp.Response.Response.(type) {
-							case *gnmi.SubscribeResponse_SyncResponse:
-								remainingOnceSubscriptions--
-							}
+						switch rsp.Response.Response.(type) {
+						case *gnmi.SubscribeResponse_SyncResponse:
+							remainingOnceSubscriptions--
 						}
 					}
-					if remainingOnceSubscriptions == 0 && numSubscriptions == numOnceSubscriptions {
-						a.operLock.Lock()
-						delete(a.activeTargets, t.Config.Name)
-						a.operLock.Unlock()
+				}
+				if remainingOnceSubscriptions == 0 && numSubscriptions == numOnceSubscriptions {
+					a.operLock.Lock()
+					delete(a.activeTargets, t.Config.Name)
+					a.operLock.Unlock()
+					return true
+				}
+				return false
+			}
+			for {
+				// the high priority responses are handled ahead of the others
+				select {
+				case rsp := <-prioChan:
+					a.stats.priorityNotification(t.Config.Name)
+					if handleResponse(rsp) {
+						return
+					}
+					continue
+				default:
+				}
+				select {
+				case rsp := <-prioChan:
+					a.stats.priorityNotification(t.Config.Name)
+					if handleResponse(rsp) {
+						return
+					}
+				case rsp := <-rspChan:
+					if handleResponse(rsp) {
 						return
 					}
 				case tErr := <-errChan:
+					a.stats.subscriptionError(t.Config.Name)
 					if errors.Is(tErr.Err, io.EOF) {
 						a.Logger.Printf("target %q: subscription %s closed stream(EOF)", t.Config.Name, tErr.SubscriptionName)
 					} else {
@@ -156,37 +208,153 @@ func (a *App) Export(ctx context.Context, rsp *gnmi.SubscribeResponse, m outputs
 	if rsp == nil {
 		return
 	}
+	if a.draining.Load() {
+		a.rejectedNotifications.Add(1)
+		a.stats.targetDropped(m["source"], dropReasonShutdown)
+		return
+	}
 	go a.updateCache(ctx, rsp, m)
+	ns := a.targetNamespace(m["source"])
+	// subscriptions with event processors write events to the outputs
+	if _, ok := rsp.Response.(*gnmi.SubscribeResponse_Update); ok {
+		if evps := a.subscriptionProcessors(m["subscription-name"]); len(evps) > 0 {
+			evs, err := formatters.ResponseToEventMsgs(m["subscription-name"], rsp, m, evps...)
+			if err != nil {
+				a.Logger.Printf("subscription %q: failed to convert response to events: %v", m["subscription-name"], err)
+				a.stats.targetDropped(m["source"], dropReasonConversionError)
+				return
+			}
+			a.stats.eventsConverted(m["source"], len(evs))
+			a.writeOutputs(ctx, m["source"], ns, outs, 0, len(evs), func(name string, o outputs.Output) (int, int) {
+				// the outputs run their own processors concurrently,
+				// each of them gets its own copy of the events.
+				oevs := make([]*formatters.EventMsg, 0, len(evs))
+				for _, ev := range evs {
+					oevs = append(oevs, ev.Clone())
+				}
+				if ts := a.outputTimestamps(name); ts != nil {
+					oevs = a.applyTimestampsEvents(name, m["source"], ts, oevs, time.Now())
+					if len(oevs) < len(evs) {
+						a.stats.outputDropped(name, dropReasonClockSkew, len(evs)-len(oevs))
+					}
+				}
+				if p := a.dataPolicy(name, ns); p != nil {
+					n := len(oevs)
+					oevs = p.applyEvents(oevs, time.Now())
+					if len(oevs) < n {
+						a.stats.outputDropped(name, dropReasonPolicy, n-len(oevs))
+					}
+				}
+				for _, ev := range oevs {
+					o.WriteEvent(ctx, ev)
+				}
+				return 0, len(oevs)
+			})
+			return
+		}
+	}
+	a.writeOutputs(ctx, m["source"], ns, outs, 1, 0, func(name string, o outputs.Output) (int, int) {
+		r, om := rsp, m
+		if ts := a.outputTimestamps(name); ts != nil {
+			r, om = a.applyTimestampsResponse(name, ts, r, om, time.Now())
+			if r == nil {
+				a.stats.outputDropped(name, dropReasonClockSkew, 1)
+				return 0, 0
+			}
+		}
+		if p := a.dataPolicy(name, ns); p != nil {
+			r = p.applyResponse(r, om, time.Now())
+			if r == nil {
+				a.stats.outputDropped(name, dropReasonPolicy, 1)
+				return 0, 0
+			}
+		}
+		o.Write(ctx, r, om)
+		return 1, 0
+	})
+}
+
+// writeOutputs calls write for each of the outputs outs,
+// or for all the outputs of the namespace ns if outs is empty.
+// The outputs with a target selector the target source does not match are skipped,
+// no selection happens if source is empty.
+// msgs and events are the number of messages and events
+// to be written by write, used to update the outputs stats if the write does not happen.
+// write returns the number of messages and events actually written to the output.
+func (a *App) writeOutputs(ctx context.Context, source, ns string, outs []string, msgs, events int, write func(name string, o outputs.Output) (int, int)) {
 	wg := new(sync.WaitGroup)
+	nsOutputs := a.namespaceOutputs(ns)
+	var tc *types.TargetConfig
+	if source != "" {
+		a.configLock.RLock()
+		tc = a.Config.Targets[source]
+		a.configLock.RUnlock()
+		if tc == nil {
+			// an unknown target has no tags or vars
+			tc = &types.TargetConfig{Name: source}
+		}
+	}
+	a.operLock.RLock()
 	// target has no outputs explicitly defined
 	if len(outs) == 0 {
-		wg.Add(len(a.Outputs))
-		for _, o := range a.Outputs {
-			go func(o outputs.Output) {
-				defer wg.Done()
-				defer a.operLock.RUnlock()
-				a.operLock.RLock()
-				o.Write(ctx, rsp, m)
-			}(o)
+		outs = make([]string, 0, len(a.Outputs))
+		for name := range a.Outputs {
+			if _, ok := a.memberOutputs[name]; ok {
+				continue
+			}
+			if _, ok := nsOutputs[name]; nsOutputs != nil && !ok {
+				continue
+			}
+			outs = append(outs, name)
 		}
-		wg.Wait()
-		return
 	}
-	// write to the outputs defined under the target
 	for _, name := range outs {
-		a.operLock.RLock()
-		if o, ok := a.Outputs[name]; ok {
-			wg.Add(1)
-			go func(o outputs.Output) {
-				defer wg.Done()
-				o.Write(ctx, rsp, m)
-			}(o)
+		o, ok := a.Outputs[name]
+		if !ok {
+			if a.components.isDisabled(componentOutputs, name) {
+				a.stats.outputDropped(name, dropReasonDisabled, msgs+events)
+				continue
+			}
+			a.stats.outputDropped(name, dropReasonUnknownOutput, msgs+events)
+			continue
 		}
-		a.operLock.RUnlock()
+		if sel, ok := a.outputsSelectors[name]; ok && tc != nil && !sel.Match(tc) {
+			a.stats.outputDropped(name, dropReasonTargetSelector, msgs+events)
+			continue
+		}
+		// the in-flight writes are waited for before the output is closed
+		inFlight := a.outputWrites.add(name)
+		wg.Add(1)
+		go func(name string, o outputs.Output, quota outputQuota) {
+			defer wg.Done()
+			defer inFlight.Done()
+			ok, throttled := quota.acquire(ctx)
+			if throttled {
+				a.stats.outputThrottled(name)
+			}
+			if !ok {
+				a.stats.outputDropped(name, dropReasonCanceled, msgs+events)
+				return
+			}
+			defer quota.release()
+			a.timedWrite(ctx, name, o, msgs, events, write)
+		}(name, o, a.outputsQuotas[name])
 	}
+	a.operLock.RUnlock()
 	wg.Wait()
 }
 
+// timedWrite calls write for the output o and records the write stats.
+func (a *App) timedWrite(ctx context.Context, name string, o outputs.Output, msgs, events int, write func(name string, o outputs.Output) (int, int)) {
+	if ctx.Err() != nil {
+		a.stats.outputDropped(name, dropReasonCanceled, msgs+events)
+		return
+	}
+	start := time.Now()
+	msgs, events = write(name, o)
+	a.stats.outputWritten(name, msgs, events, time.Since(start))
+}
+
 func (a *App) updateCache(ctx context.Context, rsp *gnmi.SubscribeResponse, m outputs.Meta) {
 	if a.c == nil {
 		return
@@ -249,8 +417,10 @@ func (a *App) CreateTarget(name string) error {
 	defer a.configLock.RUnlock()
 	if tc, ok := a.Config.Targets[name]; ok {
 		if _, ok := a.Targets[name]; !ok {
+			t := target.NewTarget(tc)
+			t.SetConnPool(a.connPool)
 			a.operLock.Lock()
-			a.Targets[tc.Name] = target.NewTarget(tc)
+			a.Targets[tc.Name] = t
 			a.operLock.Unlock()
 		}
 		return nil
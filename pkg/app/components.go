@@ -0,0 +1,382 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mitchellh/go-homedir"
+)
+
+const componentsStateFileName = ".gnmic.components"
+
+// components kinds that can be disabled at runtime
+const (
+	componentOutputs    = "outputs"
+	componentInputs     = "inputs"
+	componentLoader     = "loader"
+	componentClustering = "clustering"
+)
+
+var errInvalidComponent = errors.New("invalid component")
+
+// components holds the components disabled at runtime,
+// the state is persisted to file so that it survives restarts.
+type components struct {
+	m    *sync.Mutex
+	file string
+	// disabled components, indexed by kind or kind/name, with the time they were disabled at
+	disabled map[string]time.Time
+	// closed and replaced on each state change
+	changed chan struct{}
+	// cancel functions of the loader and clustering runs
+	cancelFns map[string]context.CancelFunc
+}
+
+type componentsStateFile struct {
+	Disabled map[string]time.Time `json:"disabled,omitempty"`
+}
+
+type componentState struct {
+	Kind          string     `json:"kind"`
+	Name          string     `json:"name,omitempty"`
+	Enabled       bool       `json:"enabled"`
+	DisabledSince *time.Time `json:"disabled-since,omitempty"`
+}
+
+func newComponents() *components {
+	return &components{
+		m:         new(sync.Mutex),
+		disabled:  make(map[string]time.Time),
+		changed:   make(chan struct{}),
+		cancelFns: make(map[string]context.CancelFunc),
+	}
+}
+
+func componentKey(kind, name string) string {
+	if name == "" {
+		return kind
+	}
+	return kind + "/" + name
+}
+
+// load reads the components state from file, the state is persisted to it from then on.
+func (c *components) load(file string) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.file = file
+	b, err := os.ReadFile(file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if len(b) == 0 {
+		return nil
+	}
+	st := new(componentsStateFile)
+	err = json.Unmarshal(b, st)
+	if err != nil {
+		return fmt.Errorf("failed to parse components state file %q: %w", file, err)
+	}
+	for k, since := range st.Disabled {
+		c.disabled[k] = since
+	}
+	return nil
+}
+
+// persist writes the components state to file, it assumes the lock is acquired.
+func (c *components) persist() error {
+	if c.file == "" {
+		return nil
+	}
+	b, err := json.MarshalIndent(&componentsStateFile{Disabled: c.disabled}, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := c.file + ".tmp"
+	err = os.WriteFile(tmp, b, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.file)
+}
+
+func (c *components) isDisabled(kind, name string) bool {
+	c.m.Lock()
+	defer c.m.Unlock()
+	_, ok := c.disabled[componentKey(kind, name)]
+	return ok
+}
+
+func (c *components) state(kind, name string) componentState {
+	c.m.Lock()
+	defer c.m.Unlock()
+	st := componentState{Kind: kind, Name: name, Enabled: true}
+	if since, ok := c.disabled[componentKey(kind, name)]; ok {
+		st.Enabled = false
+		st.DisabledSince = &since
+	}
+	return st
+}
+
+// set enables or disables the component and persists the state.
+// The in-memory state is updated even if it fails to be persisted.
+func (c *components) set(kind, name string, enabled bool, now time.Time) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	k := componentKey(kind, name)
+	_, disabled := c.disabled[k]
+	if disabled != enabled {
+		return nil
+	}
+	if enabled {
+		delete(c.disabled, k)
+	} else {
+		c.disabled[k] = now
+	}
+	close(c.changed)
+	c.changed = make(chan struct{})
+	err := c.persist()
+	if err != nil {
+		return fmt.Errorf("failed to persist the components state: %w", err)
+	}
+	return nil
+}
+
+// waitEnabled blocks until the component kind is enabled,
+// it returns false if ctx is done before.
+func (c *components) waitEnabled(ctx context.Context, kind string) bool {
+	for {
+		c.m.Lock()
+		_, disabled := c.disabled[kind]
+		ch := c.changed
+		c.m.Unlock()
+		if !disabled {
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ch:
+		}
+	}
+}
+
+// setCancel stores the cancel function of the current run of the component kind,
+// the run is canceled right away if the component was disabled in the meantime.
+func (c *components) setCancel(kind string, cfn context.CancelFunc) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if _, ok := c.disabled[kind]; ok {
+		cfn()
+		return
+	}
+	c.cancelFns[kind] = cfn
+}
+
+// cancel stops the current run of the component kind, if any.
+func (c *components) cancel(kind string) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if cfn, ok := c.cancelFns[kind]; ok {
+		cfn()
+		delete(c.cancelFns, kind)
+	}
+}
+
+// loadComponentsState reads the components disabled at runtime before the previous restart.
+func (a *App) loadComponentsState() error {
+	file := ""
+	if a.Config.APIServer != nil {
+		file = a.Config.APIServer.ComponentsStateFile
+	}
+	if file == "" {
+		home, err := homedir.Dir()
+		if err != nil {
+			return err
+		}
+		file = filepath.Join(home, componentsStateFileName)
+	}
+	err := a.components.load(file)
+	if err != nil {
+		return err
+	}
+	for _, st := range a.componentsList() {
+		if !st.Enabled {
+			a.Logger.Printf("%s is disabled since %s", componentKey(st.Kind, st.Name), st.DisabledSince)
+		}
+	}
+	return nil
+}
+
+// componentsList returns the state of the configured components.
+func (a *App) componentsList() []componentState {
+	a.configLock.RLock()
+	outs := make([]string, 0, len(a.Config.Outputs))
+	for name := range a.Config.Outputs {
+		outs = append(outs, name)
+	}
+	ins := make([]string, 0, len(a.Config.Inputs))
+	for name := range a.Config.Inputs {
+		ins = append(ins, name)
+	}
+	hasLoader := len(a.Config.Loader) > 0
+	hasClustering := a.Config.Clustering != nil
+	a.configLock.RUnlock()
+	sort.Strings(outs)
+	sort.Strings(ins)
+
+	rs := make([]componentState, 0, len(outs)+len(ins)+2)
+	for _, name := range outs {
+		rs = append(rs, a.components.state(componentOutputs, name))
+	}
+	for _, name := range ins {
+		rs = append(rs, a.components.state(componentInputs, name))
+	}
+	if hasLoader {
+		rs = append(rs, a.components.state(componentLoader, ""))
+	}
+	if hasClustering {
+		rs = append(rs, a.components.state(componentClustering, ""))
+	}
+	return rs
+}
+
+// checkComponent returns an error if the component is not configured.
+func (a *App) checkComponent(kind, name string) error {
+	a.configLock.RLock()
+	defer a.configLock.RUnlock()
+	var ok bool
+	switch kind {
+	case componentOutputs:
+		_, ok = a.Config.Outputs[name]
+	case componentInputs:
+		_, ok = a.Config.Inputs[name]
+	case componentLoader:
+		ok = len(a.Config.Loader) > 0
+	case componentClustering:
+		ok = a.Config.Clustering != nil
+	default:
+		return fmt.Errorf("%w: unknown component kind %q", errInvalidComponent, kind)
+	}
+	if !ok {
+		return fmt.Errorf("%s %w", componentKey(kind, name), errNotFound)
+	}
+	return nil
+}
+
+// disableComponent stops the component and keeps it stopped until it is enabled,
+// including across restarts.
+func (a *App) disableComponent(kind, name string) error {
+	err := a.checkComponent(kind, name)
+	if err != nil {
+		return err
+	}
+	switch kind {
+	case componentOutputs:
+		a.operLock.RLock()
+		_, running := a.Outputs[name]
+		_, member := a.memberOutputs[name]
+		a.operLock.RUnlock()
+		if member {
+			return fmt.Errorf("%w: output %q is a member of another output", errInvalidComponent, name)
+		}
+		if running {
+			err = a.DeleteOutput(name)
+			if err != nil {
+				return err
+			}
+		}
+	case componentInputs:
+		a.operLock.Lock()
+		in, running := a.Inputs[name]
+		delete(a.Inputs, name)
+		a.operLock.Unlock()
+		if running {
+			err = in.Close()
+			if err != nil {
+				a.Logger.Printf("failed to close input %q: %v", name, err)
+			}
+		}
+	}
+	a.Logger.Printf("disabling %s", componentKey(kind, name))
+	err = a.components.set(kind, name, false, time.Now())
+	// the loader and clustering runs wait for the component to be enabled again.
+	a.components.cancel(kind)
+	return err
+}
+
+// enableComponent starts the component disabled by disableComponent.
+func (a *App) enableComponent(kind, name string) error {
+	err := a.checkComponent(kind, name)
+	if err != nil {
+		return err
+	}
+	a.Logger.Printf("enabling %s", componentKey(kind, name))
+	err = a.components.set(kind, name, true, time.Now())
+	switch kind {
+	case componentOutputs:
+		a.operLock.RLock()
+		_, running := a.Outputs[name]
+		a.operLock.RUnlock()
+		if !running {
+			if serr := a.StartOutput(a.ctx, name); serr != nil {
+				return serr
+			}
+		}
+	case componentInputs:
+		a.InitInput(a.ctx, name, a.Config.Targets)
+	}
+	return err
+}
+
+func (a *App) handleComponentsGet(w http.ResponseWriter, r *http.Request) {
+	a.handlerCommonGet(w, a.componentsList())
+}
+
+func (a *App) handleComponentsActionPost(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	kind, name := vars["kind"], vars["name"]
+	if (kind == componentLoader || kind == componentClustering) != (name == "") {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{fmt.Sprintf("invalid component %q", componentKey(kind, name))}})
+		return
+	}
+	var err error
+	if vars["action"] == "disable" {
+		err = a.disableComponent(kind, name)
+	} else {
+		err = a.enableComponent(kind, name)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, errNotFound):
+			w.WriteHeader(http.StatusNotFound)
+		case errors.Is(err, errInvalidComponent):
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	a.handlerCommonGet(w, a.components.state(kind, name))
+}
@@ -0,0 +1,133 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/openconfig/gnmic/pkg/config"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+func TestComponentsState(t *testing.T) {
+	file := filepath.Join(t.TempDir(), componentsStateFileName)
+	c := newComponents()
+	if err := c.load(file); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := c.set(componentOutputs, "o1", false, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.set(componentLoader, "", false, now); err != nil {
+		t.Fatal(err)
+	}
+
+	// the state survives a restart
+	c = newComponents()
+	if err := c.load(file); err != nil {
+		t.Fatal(err)
+	}
+	if !c.isDisabled(componentOutputs, "o1") || !c.isDisabled(componentLoader, "") || c.isDisabled(componentOutputs, "o2") {
+		t.Fatalf("unexpected disabled components: %v", c.disabled)
+	}
+	if st := c.state(componentOutputs, "o1"); st.Enabled || !st.DisabledSince.Equal(now) {
+		t.Errorf("unexpected output o1 state: %+v", st)
+	}
+
+	enabled := make(chan bool)
+	go func() { enabled <- c.waitEnabled(context.Background(), componentLoader) }()
+	select {
+	case <-enabled:
+		t.Fatal("disabled loader reported as enabled")
+	case <-time.After(20 * time.Millisecond):
+	}
+	if err := c.set(componentLoader, "", true, now); err != nil {
+		t.Fatal(err)
+	}
+	if !<-enabled {
+		t.Error("expected the loader to be enabled")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c.set(componentLoader, "", false, now)
+	if c.waitEnabled(ctx, componentLoader) {
+		t.Error("expected waitEnabled to return false once ctx is done")
+	}
+}
+
+func TestAPIComponentsOutputs(t *testing.T) {
+	outputs.Register("test-components-output", func() outputs.Output { return new(testOutput) })
+	defer delete(outputs.Outputs, "test-components-output")
+
+	a := New()
+	a.Config.APIServer = &config.APIServer{Tokens: []string{"admin"}}
+	a.Config.Outputs = map[string]map[string]interface{}{
+		"o1": {"type": "test-components-output"},
+	}
+	if err := a.components.load(filepath.Join(t.TempDir(), componentsStateFileName)); err != nil {
+		t.Fatal(err)
+	}
+	a.routes()
+	a.InitOutputs(context.Background())
+
+	for path, status := range map[string]int{
+		"/api/v1/components/outputs/o2/disable":  http.StatusNotFound,
+		"/api/v1/components/targets/t1/disable":  http.StatusBadRequest,
+		"/api/v1/components/loader/disable":      http.StatusNotFound,
+		"/api/v1/components/outputs/o1/shutdown": http.StatusNotFound,
+	} {
+		if rec := apiRequest(a, http.MethodPost, path, "admin", ""); rec.Code != status {
+			t.Errorf("%s: expected status %d, got %d", path, status, rec.Code)
+		}
+	}
+
+	rec := apiRequest(a, http.MethodPost, "/api/v1/components/outputs/o1/disable", "admin", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body.String())
+	}
+	if _, ok := a.Outputs["o1"]; ok {
+		t.Fatal("disabled output o1 still running")
+	}
+	a.Export(context.Background(), &gnmi.SubscribeResponse{
+		Response: &gnmi.SubscribeResponse_Update{Update: &gnmi.Notification{}},
+	}, outputs.Meta{"source": "r1"}, "o1")
+	if d := a.stats.snapshot().Outputs["o1"].DroppedEvents[dropReasonDisabled]; d != 1 {
+		t.Errorf("expected 1 message dropped by the disabled output, got %d", d)
+	}
+
+	// the output stays disabled across restarts
+	a.InitOutputs(context.Background())
+	if _, ok := a.Outputs["o1"]; ok {
+		t.Fatal("disabled output o1 started")
+	}
+	rec = apiRequest(a, http.MethodGet, "/api/v1/components", "admin", "")
+	var sts []componentState
+	if err := json.Unmarshal(rec.Body.Bytes(), &sts); err != nil {
+		t.Fatal(err)
+	}
+	if len(sts) != 1 || sts[0].Enabled || sts[0].DisabledSince == nil {
+		t.Errorf("unexpected components: %s", rec.Body.String())
+	}
+
+	rec = apiRequest(a, http.MethodPost, "/api/v1/components/outputs/o1/enable", "admin", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body.String())
+	}
+	if _, ok := a.Outputs["o1"]; !ok {
+		t.Fatal("enabled output o1 not started")
+	}
+}
@@ -0,0 +1,660 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/api/utils"
+	"github.com/openconfig/gnmic/pkg/config"
+	"github.com/openconfig/gnmic/pkg/loaders"
+)
+
+// redactedValue replaces the secrets in the exported configuration.
+const redactedValue = "<redacted>"
+
+// configuration keys holding secrets,
+// a key is a secret if it is one of them or ends with '-' followed by one of them,
+// e.g. the SNMPv3 auth-passphrase and priv-passphrase.
+var secretKeys = []string{"password", "passphrase", "token", "tokens", "secret", "credentials", "api-key", "community"}
+
+// configExportSections are the exported configuration sections, in the order they are imported.
+var configExportSections = []string{
+	"processors", "actions", "subscriptions", "outputs", "targets", "target-groups",
+	"inputs", "loader", "clustering", "gnmi-server", "api-server", "tunnel-server",
+	"namespaces", "policies", "slos", "session-tracking", "inventory",
+}
+
+// configImportResult lists the configuration items applied by an import,
+// each item is named section/name.
+type configImportResult struct {
+	Added     []string `json:"added,omitempty"`
+	Updated   []string `json:"updated,omitempty"`
+	Unchanged []string `json:"unchanged,omitempty"`
+	// sections that are not applied at runtime
+	Ignored []string `json:"ignored,omitempty"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+func isSecretKey(k string) bool {
+	for _, s := range secretKeys {
+		if k == s || strings.HasSuffix(k, "-"+s) {
+			return true
+		}
+	}
+	return false
+}
+
+// isRedactedKeyURI returns true if s is a key URI with a PIN or password masked by utils.RedactKeyURI.
+func isRedactedKeyURI(s string) bool {
+	return utils.IsKeyURI(s) && strings.Contains(s, "=****")
+}
+
+// redactSecrets replaces the non empty string values of the secret keys in v.
+func redactSecrets(v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, iv := range v {
+			if !isSecretKey(k) {
+				// the PIN or password of a PKCS#11 or TPM2 key URI
+				if s, ok := iv.(string); ok && utils.IsKeyURI(s) {
+					v[k] = utils.RedactKeyURI(s)
+					continue
+				}
+				redactSecrets(iv)
+				continue
+			}
+			switch iv := iv.(type) {
+			case string:
+				if iv != "" {
+					v[k] = redactedValue
+				}
+			case []interface{}:
+				for i := range iv {
+					if s, ok := iv[i].(string); ok && s != "" {
+						iv[i] = redactedValue
+					}
+				}
+			default:
+				redactSecrets(iv)
+			}
+		}
+	case []interface{}:
+		for _, iv := range v {
+			redactSecrets(iv)
+		}
+	}
+}
+
+// restoreSecrets replaces the redacted values in v by the values found
+// at the same place in current, it fails if a redacted value is not found.
+func restoreSecrets(path string, v, current interface{}) error {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		cm, _ := current.(map[string]interface{})
+		for k, iv := range v {
+			if s, ok := iv.(string); ok && s == redactedValue {
+				cv, ok := cm[k]
+				if !ok {
+					return fmt.Errorf("%s/%s: redacted value has no current value", path, k)
+				}
+				v[k] = cv
+				continue
+			}
+			if s, ok := iv.(string); ok && isRedactedKeyURI(s) {
+				cv, _ := cm[k].(string)
+				if cv == "" || utils.RedactKeyURI(cv) != s {
+					return fmt.Errorf("%s/%s: redacted key URI has no current value", path, k)
+				}
+				v[k] = cv
+				continue
+			}
+			err := restoreSecrets(path+"/"+k, iv, cm[k])
+			if err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		cs, _ := current.([]interface{})
+		for i, iv := range v {
+			if s, ok := iv.(string); ok && s == redactedValue {
+				if i >= len(cs) {
+					return fmt.Errorf("%s[%d]: redacted value has no current value", path, i)
+				}
+				v[i] = cs[i]
+				continue
+			}
+			var cv interface{}
+			if i < len(cs) {
+				cv = cs[i]
+			}
+			err := restoreSecrets(fmt.Sprintf("%s[%d]", path, i), iv, cv)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// configValue converts v to the values of a configuration file:
+// the structs are converted to maps keyed by their mapstructure tags
+// and the durations to strings.
+// It returns false if v is empty and can be omitted, unless keepZero is set.
+func configValue(v reflect.Value, keepZero bool) (interface{}, bool) {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return nil, false
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil, false
+		}
+		// values set through a pointer are kept, even if zero
+		return configValue(v.Elem(), true)
+	}
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d := time.Duration(v.Int())
+		return d.String(), keepZero || d != 0
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		m := make(map[string]interface{})
+		structConfigValues(m, v)
+		return m, keepZero || len(m) > 0
+	case reflect.Map:
+		if v.Len() == 0 {
+			return nil, keepZero
+		}
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			cv, _ := configValue(iter.Value(), false)
+			m[fmt.Sprint(iter.Key().Interface())] = cv
+		}
+		return m, true
+	case reflect.Slice, reflect.Array:
+		if v.Len() == 0 {
+			return nil, keepZero
+		}
+		s := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			cv, _ := configValue(v.Index(i), true)
+			s = append(s, cv)
+		}
+		return s, true
+	default:
+		return v.Interface(), keepZero || !v.IsZero()
+	}
+}
+
+func structConfigValues(m map[string]interface{}, v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("mapstructure"), ",")
+		if strings.Contains(opts, "squash") {
+			if fv := reflect.Indirect(v.Field(i)); fv.Kind() == reflect.Struct {
+				structConfigValues(m, fv)
+			}
+			continue
+		}
+		// fields without a mapstructure tag are not read from the configuration
+		if name == "" || name == "-" {
+			continue
+		}
+		if cv, ok := configValue(v.Field(i), false); ok {
+			m[name] = cv
+		}
+	}
+}
+
+// exportConfig returns the normalized configuration sections with their secrets redacted.
+// The targets discovered by the loader are exported only if loaderTargets is true.
+func (a *App) exportConfig(loaderTargets bool) map[string]interface{} {
+	a.configLock.RLock()
+	targets := make(map[string]*types.TargetConfig, len(a.Config.Targets))
+	for name, tc := range a.Config.Targets {
+		if !loaderTargets && a.loadedTargets.has(name) {
+			continue
+		}
+		targets[name] = tc
+	}
+	sections := map[string]interface{}{
+		"processors":       a.Config.Processors,
+		"actions":          a.Config.Actions,
+		"subscriptions":    a.Config.Subscriptions,
+		"outputs":          a.Config.Outputs,
+		"targets":          targets,
+		"target-groups":    a.Config.TargetGroups,
+		"inputs":           a.Config.Inputs,
+		"loader":           a.Config.Loader,
+		"clustering":       a.Config.Clustering,
+		"gnmi-server":      a.Config.GnmiServer,
+		"api-server":       a.Config.APIServer,
+		"tunnel-server":    a.Config.TunnelServer,
+		"namespaces":       a.Config.Namespaces,
+		"policies":         a.Config.Policies,
+		"slos":             a.Config.SLOs,
+		"session-tracking": a.Config.SessionTracking,
+		"inventory":        a.Config.Inventory,
+	}
+	rs := make(map[string]interface{}, len(sections))
+	for name, s := range sections {
+		if cv, ok := configValue(reflect.ValueOf(s), false); ok {
+			rs[name] = cv
+		}
+	}
+	a.configLock.RUnlock()
+	redactSecrets(rs)
+	return rs
+}
+
+// importConfig applies the processors, actions, subscriptions, outputs and targets
+// of the configuration cfg, the other sections require a restart and are ignored.
+// The redacted secrets are restored from the current configuration.
+func (a *App) importConfig(cfg map[string]interface{}) *configImportResult {
+	rs := new(configImportResult)
+	for name := range cfg {
+		switch name {
+		case "processors", "actions", "subscriptions", "outputs", "targets":
+		default:
+			rs.Ignored = append(rs.Ignored, name)
+		}
+	}
+	sort.Strings(rs.Ignored)
+
+	a.configLock.RLock()
+	current := map[string]interface{}{
+		"processors":    a.Config.Processors,
+		"actions":       a.Config.Actions,
+		"subscriptions": a.Config.Subscriptions,
+		"outputs":       a.Config.Outputs,
+		"targets":       a.Config.Targets,
+	}
+	cvs := make(map[string]map[string]interface{}, len(current))
+	for name, s := range current {
+		cv, _ := configValue(reflect.ValueOf(s), false)
+		cvs[name], _ = cv.(map[string]interface{})
+	}
+	a.configLock.RUnlock()
+
+	// items of each section to apply, by name
+	changed := make(map[string]map[string]interface{})
+	for _, section := range []string{"processors", "actions", "subscriptions", "outputs", "targets"} {
+		items, ok := cfg[section].(map[string]interface{})
+		if !ok {
+			if cfg[section] != nil {
+				rs.Errors = append(rs.Errors, fmt.Sprintf("%s: unexpected format %T", section, cfg[section]))
+			}
+			continue
+		}
+		changed[section] = make(map[string]interface{})
+		names := make([]string, 0, len(items))
+		for name := range items {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			item := section + "/" + name
+			v := items[name]
+			cv, exists := cvs[section][name]
+			err := restoreSecrets(item, v, cv)
+			if err != nil {
+				rs.Errors = append(rs.Errors, err.Error())
+				continue
+			}
+			if (section == "outputs" || section == "targets") &&
+				!a.credentialsCommandAllowed() && credentialsCommandChanged(v, cv) {
+				rs.Errors = append(rs.Errors, fmt.Sprintf("%s: %v", item, errCredentialsCommand))
+				continue
+			}
+			if exists && sameConfig(v, cv) {
+				rs.Unchanged = append(rs.Unchanged, item)
+				continue
+			}
+			changed[section][name] = v
+			if exists {
+				rs.Updated = append(rs.Updated, item)
+			} else {
+				rs.Added = append(rs.Added, item)
+			}
+		}
+	}
+	var err error
+	a.configLock.Lock()
+	for name, v := range changed["processors"] {
+		if a.Config.Processors == nil {
+			a.Config.Processors = make(map[string]map[string]interface{})
+		}
+		a.Config.Processors[name], _ = v.(map[string]interface{})
+	}
+	for name, v := range changed["actions"] {
+		if a.Config.Actions == nil {
+			a.Config.Actions = make(map[string]map[string]interface{})
+		}
+		a.Config.Actions[name], _ = v.(map[string]interface{})
+	}
+	for name, v := range changed["subscriptions"] {
+		sc := new(types.SubscriptionConfig)
+		err = decodeConfigItem(v, sc)
+		if err != nil {
+			rs.Errors = append(rs.Errors, fmt.Sprintf("subscriptions/%s: %v", name, err))
+			continue
+		}
+		sc.Name = name
+		a.Config.Subscriptions[name] = sc
+	}
+	a.configLock.Unlock()
+
+	for name, v := range changed["outputs"] {
+		cfg, ok := v.(map[string]interface{})
+		if !ok {
+			rs.Errors = append(rs.Errors, fmt.Sprintf("outputs/%s: unexpected format %T", name, v))
+			continue
+		}
+		a.operLock.RLock()
+		_, running := a.Outputs[name]
+		a.operLock.RUnlock()
+		if running {
+			err = a.DeleteOutput(name)
+			if err != nil {
+				rs.Errors = append(rs.Errors, fmt.Sprintf("outputs/%s: %v", name, err))
+				continue
+			}
+		}
+		a.DeleteOutputConfig(name)
+		err = a.AddOutputConfig(name, cfg)
+		if err == nil && !a.components.isDisabled(componentOutputs, name) {
+			err = a.StartOutput(a.ctx, name)
+		}
+		if err != nil {
+			rs.Errors = append(rs.Errors, fmt.Sprintf("outputs/%s: %v", name, err))
+		}
+	}
+
+	op := &loaders.TargetOperation{Add: make(map[string]*types.TargetConfig)}
+	for name, v := range changed["targets"] {
+		tc := new(types.TargetConfig)
+		err = decodeConfigItem(v, tc)
+		if err != nil {
+			rs.Errors = append(rs.Errors, fmt.Sprintf("targets/%s: %v", name, err))
+			continue
+		}
+		tc.Name = name
+		if a.targetConfigExists(name) {
+			op.Del = append(op.Del, name)
+		}
+		op.Add[name] = tc
+	}
+	if len(op.Add) > 0 {
+		a.applyTargetOperation(a.ctx, op)
+	}
+	sort.Strings(rs.Errors)
+	return rs
+}
+
+// sameConfig compares two configuration values, ignoring the numbers types.
+// credentialsCommandChanged returns true if the imported item v sets
+// a credentials-command different from the one of the current item cv.
+func credentialsCommandChanged(v, cv interface{}) bool {
+	vm, _ := v.(map[string]interface{})
+	cc, ok := vm["credentials-command"]
+	if !ok || cc == nil {
+		return false
+	}
+	cm, _ := cv.(map[string]interface{})
+	return !sameConfig(cc, cm["credentials-command"])
+}
+
+func sameConfig(a, b interface{}) bool {
+	ba, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bb, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(ba, bb)
+}
+
+func decodeConfigItem(v, dst interface{}) error {
+	decoder, err := mapstructure.NewDecoder(
+		&mapstructure.DecoderConfig{
+			DecodeHook: mapstructure.StringToTimeDurationHookFunc(),
+			Result:     dst,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(v)
+}
+
+func (a *App) handleConfigExportGet(w http.ResponseWriter, r *http.Request) {
+	a.handlerCommonGet(w, a.exportConfig(r.URL.Query().Get("loader-targets") == "true"))
+}
+
+func (a *App) handleConfigImportPost(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	defer r.Body.Close()
+	cfg, err := parseConfigDocument(body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	rs := a.importConfig(cfg)
+	if len(rs.Errors) > 0 {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	json.NewEncoder(w).Encode(rs)
+}
+
+// parseConfigDocument parses a YAML or JSON configuration document,
+// YAML keeps the integers types the outputs configurations may rely on.
+func parseConfigDocument(b []byte) (map[string]interface{}, error) {
+	var v interface{}
+	err := yaml.Unmarshal(b, &v)
+	if err != nil {
+		return nil, err
+	}
+	cfg, ok := utils.Convert(v).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected configuration format %T", v)
+	}
+	return cfg, nil
+}
+
+// config export and import commands
+
+func (a *App) InitConfigExportFlags(cmd *cobra.Command) {
+	cmd.ResetFlags()
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.ConfigExportOutput, "output", "o", "", "file the configuration is written to, defaults to stdout")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.ConfigExportLoaderTargets, "loader-targets", "", false, "export the targets discovered by the loader, requires --api")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.ConfigAPIToken, "api-token", "", "", "token sent to the gnmic API")
+}
+
+func (a *App) InitConfigImportFlags(cmd *cobra.Command) {
+	cmd.ResetFlags()
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.ConfigImportFile, "file", "", "", "configuration file to import, '-' reads it from stdin")
+	cmd.MarkFlagRequired("file")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.ConfigAPIToken, "api-token", "", "", "token sent to the gnmic API")
+}
+
+// ConfigExportRunE writes the configuration of the gnmic instance reachable at --api if set,
+// or the configuration read from the config file otherwise.
+func (a *App) ConfigExportRunE(cmd *cobra.Command, args []string) error {
+	var cfg map[string]interface{}
+	if a.Config.API != "" {
+		path := "/api/v1/config/export"
+		if a.Config.LocalFlags.ConfigExportLoaderTargets {
+			path += "?loader-targets=true"
+		}
+		b, err := a.configAPIRequest(cmd, http.MethodGet, path, nil)
+		if err != nil {
+			return err
+		}
+		cfg, err = parseConfigDocument(b)
+		if err != nil {
+			return err
+		}
+	} else {
+		if a.Config.LocalFlags.ConfigExportLoaderTargets {
+			return errors.New("--loader-targets requires --api")
+		}
+		err := a.readExportedConfig(cmd)
+		if err != nil {
+			return err
+		}
+		cfg = a.exportConfig(false)
+	}
+	b, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	if a.Config.LocalFlags.ConfigExportOutput == "" {
+		_, err = a.out.Write(b)
+		return err
+	}
+	return os.WriteFile(a.Config.LocalFlags.ConfigExportOutput, b, 0600)
+}
+
+// readExportedConfig reads the exported sections from the config file.
+func (a *App) readExportedConfig(cmd *cobra.Command) error {
+	_, err := a.Config.GetTargets()
+	if err != nil && !errors.Is(err, config.ErrNoTargetsFound) {
+		return err
+	}
+	_, err = a.Config.GetSubscriptions(cmd)
+	if err != nil {
+		return err
+	}
+	for _, get := range []func() (map[string]map[string]interface{}, error){
+		a.Config.GetOutputs,
+		a.Config.GetInputs,
+		a.Config.GetActions,
+		a.Config.GetEventProcessors,
+	} {
+		if _, err = get(); err != nil {
+			return err
+		}
+	}
+	for _, get := range []func() error{
+		a.Config.GetLoader,
+		a.Config.GetClustering,
+		a.Config.GetGNMIServer,
+		a.Config.GetAPIServer,
+		a.Config.GetTunnelServer,
+		a.Config.GetNamespaces,
+		a.Config.GetPolicies,
+		a.Config.GetSLOs,
+		a.Config.GetSessionTracking,
+		a.Config.GetInventory,
+	} {
+		if err = get(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConfigImportRunE applies a configuration file to the gnmic instance reachable at --api.
+func (a *App) ConfigImportRunE(cmd *cobra.Command, args []string) error {
+	if a.Config.API == "" {
+		return errors.New("--api is required")
+	}
+	var b []byte
+	var err error
+	if a.Config.LocalFlags.ConfigImportFile == "-" {
+		b, err = io.ReadAll(os.Stdin)
+	} else {
+		b, err = os.ReadFile(a.Config.LocalFlags.ConfigImportFile)
+	}
+	if err != nil {
+		return err
+	}
+	// validate the document before sending it
+	if _, err = parseConfigDocument(b); err != nil {
+		return fmt.Errorf("failed to parse %q: %v", a.Config.LocalFlags.ConfigImportFile, err)
+	}
+	rb, err := a.configAPIRequest(cmd, http.MethodPost, "/api/v1/config/import", b)
+	rs := new(configImportResult)
+	if jerr := json.Unmarshal(rb, rs); jerr != nil {
+		if err != nil {
+			return err
+		}
+		return jerr
+	}
+	out, merr := yaml.Marshal(rs)
+	if merr != nil {
+		return merr
+	}
+	a.out.Write(out)
+	if len(rs.Errors) > 0 {
+		return fmt.Errorf("%d configuration items not imported", len(rs.Errors))
+	}
+	return err
+}
+
+// configAPIRequest sends a request to the gnmic API at --api and returns the response body.
+func (a *App) configAPIRequest(cmd *cobra.Command, method, path string, body []byte) ([]byte, error) {
+	addr := a.Config.API
+	if !strings.HasPrefix(addr, "http://") && !strings.HasPrefix(addr, "https://") {
+		addr = "http://" + addr
+	}
+	req, err := http.NewRequestWithContext(cmd.Context(), method, strings.TrimSuffix(addr, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if a.Config.LocalFlags.ConfigAPIToken != "" {
+		req.Header.Set("Authorization", "Bearer "+a.Config.LocalFlags.ConfigAPIToken)
+	}
+	client := &http.Client{Timeout: a.Config.Timeout}
+	rsp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	b, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if rsp.StatusCode != http.StatusOK {
+		apiErrs := new(APIErrors)
+		if json.Unmarshal(b, apiErrs) == nil && len(apiErrs.Errors) > 0 {
+			return b, fmt.Errorf("%s %s: %s: %s", method, path, rsp.Status, strings.Join(apiErrs.Errors, ", "))
+		}
+		return b, fmt.Errorf("%s %s: %s", method, path, rsp.Status)
+	}
+	return b, nil
+}
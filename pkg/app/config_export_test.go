@@ -0,0 +1,256 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/config"
+	"github.com/openconfig/gnmic/pkg/loaders"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+func newExportApp() *App {
+	a := New()
+	pwd := "secret"
+	a.Config.APIServer = &config.APIServer{Address: ":7890", Timeout: 10 * time.Second, Tokens: []string{"admin"}}
+	a.Config.Targets = map[string]*types.TargetConfig{
+		"r1": {Name: "r1", Address: "10.0.0.1:57400", Password: &pwd, Timeout: 5 * time.Second},
+		"r2": {Name: "r2", Address: "10.0.0.2:57400"},
+	}
+	si := 10 * time.Second
+	a.Config.Subscriptions = map[string]*types.SubscriptionConfig{
+		"sub1": {Name: "sub1", Paths: []string{"/interfaces"}, SampleInterval: &si},
+	}
+	a.Config.Outputs = map[string]map[string]interface{}{
+		"o1": {"type": "test-export-output", "token": "tk", "batch-size": 100},
+	}
+	a.loadedTargets.apply(&loaders.TargetOperation{Add: map[string]*types.TargetConfig{"r2": nil}})
+	return a
+}
+
+func TestExportConfig(t *testing.T) {
+	a := newExportApp()
+	cfg := a.exportConfig(false)
+	want := map[string]interface{}{
+		"api-server": map[string]interface{}{
+			"address": ":7890",
+			"timeout": "10s",
+			"tokens":  []interface{}{redactedValue},
+		},
+		"targets": map[string]interface{}{
+			"r1": map[string]interface{}{
+				"name":     "r1",
+				"address":  "10.0.0.1:57400",
+				"password": redactedValue,
+				"timeout":  "5s",
+			},
+		},
+		"subscriptions": map[string]interface{}{
+			"sub1": map[string]interface{}{
+				"name":            "sub1",
+				"paths":           []interface{}{"/interfaces"},
+				"sample-interval": "10s",
+			},
+		},
+		"outputs": map[string]interface{}{
+			"o1": map[string]interface{}{"type": "test-export-output", "token": redactedValue, "batch-size": 100},
+		},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		got, _ := yaml.Marshal(cfg)
+		t.Fatalf("unexpected exported config:\n%s", got)
+	}
+	if _, ok := a.exportConfig(true)["targets"].(map[string]interface{})["r2"]; !ok {
+		t.Errorf("expected the loader target r2 to be exported")
+	}
+	// the running config secrets are not modified
+	if *a.Config.Targets["r1"].Password != "secret" || a.Config.Outputs["o1"]["token"] != "tk" {
+		t.Errorf("running config secrets modified by the export")
+	}
+}
+
+func TestImportConfig(t *testing.T) {
+	outputs.Register("test-export-output", func() outputs.Output { return new(testOutput) })
+	defer delete(outputs.Outputs, "test-export-output")
+
+	a := newExportApp()
+	a.routes()
+	b, err := yaml.Marshal(a.exportConfig(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := strings.Replace(string(b), "sample-interval: 10s", "sample-interval: 30s", 1) + `
+processors:
+  add-role:
+    event-add-tag:
+      add:
+        role: core
+`
+	rec := apiRequest(a, http.MethodPost, "/api/v1/config/import", "admin", doc)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body.String())
+	}
+	rs := new(configImportResult)
+	if err := yaml.Unmarshal(rec.Body.Bytes(), rs); err != nil {
+		t.Fatal(err)
+	}
+	want := &configImportResult{
+		Added:     []string{"processors/add-role"},
+		Updated:   []string{"subscriptions/sub1"},
+		Unchanged: []string{"outputs/o1", "targets/r1"},
+		Ignored:   []string{"api-server"},
+	}
+	if !reflect.DeepEqual(rs, want) {
+		t.Errorf("unexpected import result: %s", rec.Body.String())
+	}
+	if si := a.Config.Subscriptions["sub1"].SampleInterval; si == nil || *si != 30*time.Second {
+		t.Errorf("subscription sub1 not updated: %v", si)
+	}
+	if *a.Config.Targets["r1"].Password != "secret" {
+		t.Errorf("target r1 password not restored")
+	}
+
+	// a redacted secret without a current value is rejected
+	rec = apiRequest(a, http.MethodPost, "/api/v1/config/import", "admin", `
+outputs:
+  o2:
+    type: test-export-output
+    token: <redacted>
+`)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "outputs/o2/token") {
+		t.Errorf("expected the redacted token to be rejected, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if _, ok := a.Outputs["o2"]; ok {
+		t.Errorf("output o2 with a redacted token started")
+	}
+}
+
+func TestAPICredentialsCommand(t *testing.T) {
+	outputs.Register("test-export-output", func() outputs.Output { return new(testOutput) })
+	defer delete(outputs.Outputs, "test-export-output")
+
+	a := newExportApp()
+	cc := types.CredentialsCommand{Command: "/usr/local/bin/broker"}
+	a.Config.Targets["r1"].CredentialsCommand = &cc
+	a.routes()
+	reqs := []struct {
+		path string
+		body string
+	}{
+		{
+			path: "/api/v1/config/targets",
+			body: `{"name": "r3", "address": "10.0.0.3:57400", "credentials-command": {"command": "touch"}}`,
+		},
+		{
+			path: "/api/v1/config/outputs",
+			body: `{"o2": {"type": "test-export-output", "credentials-command": {"command": "touch"}}}`,
+		},
+		{
+			path: "/api/v1/config/import",
+			body: "targets:\n  r1:\n    address: 10.0.0.1:57400\n    credentials-command:\n      command: touch\n",
+		},
+	}
+	for _, req := range reqs {
+		rec := apiRequest(a, http.MethodPost, req.path, "admin", req.body)
+		if rec.Code == http.StatusOK || !strings.Contains(rec.Body.String(), "credentials-command is not allowed") {
+			t.Errorf("%s: expected the credentials-command to be rejected, got %d: %s", req.path, rec.Code, rec.Body.String())
+		}
+	}
+	if _, ok := a.Config.Targets["r3"]; ok {
+		t.Errorf("target r3 added")
+	}
+	if _, ok := a.Outputs["o2"]; ok {
+		t.Errorf("output o2 started")
+	}
+	if a.Config.Targets["r1"].CredentialsCommand.Command != "/usr/local/bin/broker" {
+		t.Errorf("target r1 credentials-command replaced")
+	}
+
+	// the current credentials-command can be imported back
+	b, err := yaml.Marshal(a.exportConfig(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := apiRequest(a, http.MethodPost, "/api/v1/config/import", "admin", string(b))
+	if rec.Code != http.StatusOK {
+		t.Errorf("unexpected status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	a.Config.APIServer.AllowCredentialsCommand = true
+	for _, req := range reqs {
+		rec := apiRequest(a, http.MethodPost, req.path, "admin", req.body)
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: unexpected status %d: %s", req.path, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestIsSecretKey(t *testing.T) {
+	for k, want := range map[string]bool{
+		"password":        true,
+		"auth-password":   true,
+		"auth-passphrase": true,
+		"priv-passphrase": true,
+		"community":       true,
+		"token":           true,
+		"api-key":         true,
+		"tokens":          true,
+		"username":        false,
+		"passwords-file":  false,
+		"insert-key":      false,
+		"tls-key":         false,
+	} {
+		if got := isSecretKey(k); got != want {
+			t.Errorf("%s: got %v, want %v", k, got, want)
+		}
+	}
+}
+
+func TestExportKeyURI(t *testing.T) {
+	a := newExportApp()
+	key := "pkcs11:token=gnmic;object=client-key?module-path=/usr/lib/softhsm/libsofthsm2.so&pin-value=1234"
+	a.Config.Targets["r1"].TLSKey = &key
+	a.routes()
+	cfg := a.exportConfig(false)
+	b, err := yaml.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "1234") || !strings.Contains(string(b), "pin-value=****") {
+		t.Fatalf("key URI PIN not redacted:\n%s", b)
+	}
+	if *a.Config.Targets["r1"].TLSKey != key {
+		t.Errorf("running config key URI modified by the export")
+	}
+
+	rec := apiRequest(a, http.MethodPost, "/api/v1/config/import", "admin", string(b))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "targets/r1") {
+		t.Fatalf("unexpected import result %d: %s", rec.Code, rec.Body.String())
+	}
+	if *a.Config.Targets["r1"].TLSKey != key {
+		t.Errorf("target r1 key URI not restored: %s", *a.Config.Targets["r1"].TLSKey)
+	}
+	rec = apiRequest(a, http.MethodPost, "/api/v1/config/import", "admin", `
+targets:
+  r3:
+    address: 10.0.0.3:57400
+    tls-key: pkcs11:token=gnmic;object=client-key?module-path=/lib.so&pin-value=****
+`)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "targets/r3/tls-key") {
+		t.Errorf("expected the redacted key URI to be rejected, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
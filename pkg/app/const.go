@@ -21,6 +21,7 @@ const (
 	formatEvent     = "event"
 	formatPROTO     = "proto"
 	formatFLAT      = "flat"
+	formatTable     = "table"
 )
 
 var encodingNames = []string{
@@ -38,6 +39,7 @@ var formatNames = []string{
 	formatEvent,
 	formatPROTO,
 	formatFLAT,
+	formatTable,
 }
 
 var tlsVersions = []string{"1.3", "1.2", "1.1", "1.0", "1"}
@@ -274,7 +274,7 @@ func (a *App) getBasedDiff(ctx context.Context, ref *types.TargetConfig, compare
 			getReq.Prefix, getReq.Path, getReq.Type, getReq.Encoding, getReq.UseModels, getReq.Extension, ref)
 		refResponse, err = a.ClientGet(ctx, ref, getReq)
 		if err != nil {
-			a.logError(fmt.Errorf("target %q get request failed: %v", ref, err))
+			a.logTargetError(ref.Name, fmt.Errorf("target %q get request failed: %w", ref.Name, err))
 			return
 		}
 	}()
@@ -286,7 +286,7 @@ func (a *App) getBasedDiff(ctx context.Context, ref *types.TargetConfig, compare
 				getReq.Prefix, getReq.Path, getReq.Type, getReq.Encoding, getReq.UseModels, getReq.Extension, tc.Name)
 			response, err := a.ClientGet(ctx, tc, getReq)
 			if err != nil {
-				a.logError(fmt.Errorf("target %q get request failed: %v", tc.Name, err))
+				a.logTargetError(tc.Name, fmt.Errorf("target %q get request failed: %w", tc.Name, err))
 				return
 			}
 			rspChan <- &targetDiffResponse{
@@ -0,0 +1,222 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"google.golang.org/grpc/status"
+)
+
+// gnmic exit codes.
+const (
+	ExitCodeOK = 0
+	// generic failure: invalid flags or config, or errors not related to a target.
+	ExitCodeError = 1
+	// all the failed targets could not be connected to.
+	ExitCodeConnection = 2
+	// at least one target returned an RPC error, none succeeded.
+	ExitCodeRPC = 3
+	// some targets succeeded while others failed.
+	ExitCodePartial = 4
+)
+
+const (
+	errorFormatText = "text"
+	errorFormatJSON = "json"
+)
+
+var errorFormats = []string{errorFormatText, errorFormatJSON}
+
+// target error types
+const (
+	errorTypeConnection = "connection"
+	errorTypeRPC        = "rpc"
+	errorTypeOther      = "error"
+)
+
+// connectionError wraps errors raised while establishing a gRPC connection to a target.
+type connectionError struct {
+	err error
+}
+
+func (e *connectionError) Error() string { return e.err.Error() }
+func (e *connectionError) Unwrap() error { return e.err }
+
+// targetError is an error returned by a request sent to a target.
+type targetError struct {
+	target string
+	err    error
+}
+
+func (e *targetError) Error() string { return e.err.Error() }
+func (e *targetError) Unwrap() error { return e.err }
+
+// ExitError is returned by the commands when one or more targets failed.
+// It carries the process exit code.
+type ExitError struct {
+	Code int
+	err  error
+}
+
+func (e *ExitError) Error() string { return e.err.Error() }
+func (e *ExitError) Unwrap() error { return e.err }
+
+// ExitCode returns the process exit code matching the error returned by a command.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitCodeOK
+	}
+	ee := new(ExitError)
+	if errors.As(err, &ee) {
+		return ee.Code
+	}
+	return ExitCodeError
+}
+
+// ErrorReport is the machine readable errors report, written to stderr with `--error-format json`.
+type ErrorReport struct {
+	ExitCode int                  `json:"exit-code"`
+	Targets  []*TargetErrorReport `json:"targets,omitempty"`
+	Errors   []string             `json:"errors,omitempty"`
+}
+
+// TargetErrorReport is the status of a single target.
+type TargetErrorReport struct {
+	Name     string   `json:"name"`
+	Status   string   `json:"status"`
+	Duration string   `json:"duration,omitempty"`
+	Type     string   `json:"type,omitempty"`
+	Code     string   `json:"code,omitempty"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+func errorType(err error) string {
+	ce := new(connectionError)
+	if errors.As(err, &ce) {
+		return errorTypeConnection
+	}
+	if _, ok := status.FromError(err); ok {
+		return errorTypeRPC
+	}
+	return errorTypeOther
+}
+
+// buildErrorReport classifies the errors per target and derives the exit code.
+func buildErrorReport(targets []string, errs []error) *ErrorReport {
+	r := &ErrorReport{}
+	failed := make(map[string]*TargetErrorReport)
+	for _, err := range errs {
+		te := new(targetError)
+		if !errors.As(err, &te) {
+			r.Errors = append(r.Errors, err.Error())
+			continue
+		}
+		tr, ok := failed[te.target]
+		if !ok {
+			tr = &TargetErrorReport{Name: te.target, Status: "failed"}
+			failed[te.target] = tr
+		}
+		tr.Errors = append(tr.Errors, err.Error())
+		typ := errorType(err)
+		// a connection or RPC error takes precedence
+		// over other errors of the same target.
+		if tr.Type == "" || tr.Type == errorTypeOther {
+			tr.Type = typ
+			if typ == errorTypeRPC {
+				st, _ := status.FromError(err)
+				tr.Code = st.Code().String()
+			}
+		}
+	}
+	for _, name := range targets {
+		if tr, ok := failed[name]; ok {
+			r.Targets = append(r.Targets, tr)
+			continue
+		}
+		r.Targets = append(r.Targets, &TargetErrorReport{Name: name, Status: "ok"})
+	}
+	// failed targets not part of the configured targets list
+	for name, tr := range failed {
+		if !contains(targets, name) {
+			r.Targets = append(r.Targets, tr)
+		}
+	}
+	sort.Slice(r.Targets, func(i, j int) bool {
+		return r.Targets[i].Name < r.Targets[j].Name
+	})
+	r.ExitCode = exitCodeFromReport(r, len(failed))
+	return r
+}
+
+func exitCodeFromReport(r *ErrorReport, numFailed int) int {
+	if numFailed == 0 {
+		if len(r.Errors) == 0 {
+			return ExitCodeOK
+		}
+		return ExitCodeError
+	}
+	if numFailed < len(r.Targets) {
+		return ExitCodePartial
+	}
+	allConn := true
+	for _, tr := range r.Targets {
+		switch tr.Type {
+		case errorTypeRPC:
+			return ExitCodeRPC
+		case errorTypeConnection:
+		default:
+			allConn = false
+		}
+	}
+	if allConn {
+		return ExitCodeConnection
+	}
+	return ExitCodeError
+}
+
+func (r *ErrorReport) write(w io.Writer) error {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
+
+func contains(ss []string, s string) bool {
+	for _, e := range ss {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ReportError writes a JSON errors report for the errors returned
+// by a command before any target was contacted, if the errors format is JSON.
+// The errors related to targets are reported by the command itself.
+func (a *App) ReportError(err error) {
+	if err == nil || a.Config.ErrorFormat != errorFormatJSON {
+		return
+	}
+	ee := new(ExitError)
+	if errors.As(err, &ee) {
+		return
+	}
+	r := &ErrorReport{ExitCode: ExitCode(err), Errors: []string{err.Error()}}
+	if werr := r.write(os.Stderr); werr != nil {
+		a.Logger.Printf("failed to write errors report: %v", werr)
+	}
+}
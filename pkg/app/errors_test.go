@@ -0,0 +1,99 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBuildErrorReport(t *testing.T) {
+	connErr := func(name string) error {
+		return &targetError{target: name, err: &connectionError{fmt.Errorf("failed to create a gRPC client for target %q", name)}}
+	}
+	rpcErr := func(name string) error {
+		return &targetError{target: name, err: fmt.Errorf("target %q Get request failed: %w", name, status.Error(codes.NotFound, "path not found"))}
+	}
+	tests := []struct {
+		name     string
+		targets  []string
+		errs     []error
+		wantCode int
+	}{
+		{
+			name:     "all_connection",
+			targets:  []string{"r1", "r2"},
+			errs:     []error{connErr("r1"), connErr("r2")},
+			wantCode: ExitCodeConnection,
+		},
+		{
+			name:     "all_rpc",
+			targets:  []string{"r1"},
+			errs:     []error{rpcErr("r1")},
+			wantCode: ExitCodeRPC,
+		},
+		{
+			name:     "connection_and_rpc",
+			targets:  []string{"r1", "r2"},
+			errs:     []error{connErr("r1"), rpcErr("r2")},
+			wantCode: ExitCodeRPC,
+		},
+		{
+			name:     "partial",
+			targets:  []string{"r1", "r2", "r3"},
+			errs:     []error{connErr("r1"), rpcErr("r2")},
+			wantCode: ExitCodePartial,
+		},
+		{
+			name:     "not_target_related",
+			targets:  []string{"r1"},
+			errs:     []error{errors.New("failed to init outputs")},
+			wantCode: ExitCodeError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := buildErrorReport(tt.targets, tt.errs)
+			if r.ExitCode != tt.wantCode {
+				t.Errorf("got exit code %d, want %d", r.ExitCode, tt.wantCode)
+			}
+			if len(r.Targets) != len(tt.targets) {
+				t.Errorf("got %d targets in report, want %d", len(r.Targets), len(tt.targets))
+			}
+		})
+	}
+}
+
+func TestBuildErrorReportTargetStatus(t *testing.T) {
+	r := buildErrorReport([]string{"r2", "r1"}, []error{
+		&targetError{target: "r1", err: fmt.Errorf("target %q: %w", "r1", status.Error(codes.PermissionDenied, "denied"))},
+	})
+	if r.Targets[0].Name != "r1" || r.Targets[0].Status != "failed" || r.Targets[0].Type != errorTypeRPC || r.Targets[0].Code != "PermissionDenied" {
+		t.Errorf("unexpected report for r1: %+v", r.Targets[0])
+	}
+	if r.Targets[1].Name != "r2" || r.Targets[1].Status != "ok" {
+		t.Errorf("unexpected report for r2: %+v", r.Targets[1])
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	if c := ExitCode(nil); c != ExitCodeOK {
+		t.Errorf("got %d, want %d", c, ExitCodeOK)
+	}
+	if c := ExitCode(errors.New("unknown flag")); c != ExitCodeError {
+		t.Errorf("got %d, want %d", c, ExitCodeError)
+	}
+	if c := ExitCode(fmt.Errorf("wrapped: %w", &ExitError{Code: ExitCodePartial, err: errors.New("x")})); c != ExitCodePartial {
+		t.Errorf("got %d, want %d", c, ExitCodePartial)
+	}
+}
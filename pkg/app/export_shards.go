@@ -0,0 +1,123 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"hash/fnv"
+	"runtime"
+	"sync"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+// number of responses queued per export worker
+// before the targets reading into it are blocked.
+const exportQueueSize = 1024
+
+// exportJob is a subscribe response queued for export to the outputs.
+type exportJob struct {
+	ctx  context.Context
+	rsp  *gnmi.SubscribeResponse
+	m    outputs.Meta
+	outs []string
+}
+
+// exportShards exports the targets subscribe responses with a fixed number of workers,
+// each reading from its own queue.
+// The responses of a target are always queued to the same worker,
+// so they are exported in the order they were received.
+// A full queue blocks the targets it is shared by until the worker catches up.
+type exportShards struct {
+	queues []chan *exportJob
+	// queued jobs not exported yet
+	pending sync.WaitGroup
+}
+
+// newExportShards starts n workers calling export for the queued jobs until ctx is done.
+// n defaults to the number of usable CPUs.
+func newExportShards(ctx context.Context, n int, export func(*exportJob)) *exportShards {
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	s := &exportShards{queues: make([]chan *exportJob, n)}
+	for i := range s.queues {
+		s.queues[i] = make(chan *exportJob, exportQueueSize)
+		go s.work(ctx, s.queues[i], export)
+	}
+	return s
+}
+
+func (s *exportShards) work(ctx context.Context, q chan *exportJob, export func(*exportJob)) {
+	for {
+		select {
+		case j := <-q:
+			export(j)
+			s.pending.Done()
+		case <-ctx.Done():
+			// the jobs still queued are exported with the canceled context
+			// so that they are accounted for in the outputs stats.
+			for {
+				select {
+				case j := <-q:
+					export(j)
+					s.pending.Done()
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// shard returns the queue of the target source.
+func (s *exportShards) shard(source string) chan *exportJob {
+	h := fnv.New32a()
+	h.Write([]byte(source))
+	return s.queues[h.Sum32()%uint32(len(s.queues))]
+}
+
+// enqueue queues the job j to its target worker,
+// it blocks while the queue is full, until j.ctx is done.
+func (s *exportShards) enqueue(j *exportJob) bool {
+	s.pending.Add(1)
+	select {
+	case s.shard(j.m["source"]) <- j:
+		return true
+	case <-j.ctx.Done():
+		s.pending.Done()
+		return false
+	}
+}
+
+// wait waits for the queued jobs to be exported, until ctx is done.
+func (s *exportShards) wait(ctx context.Context) bool {
+	done := make(chan struct{})
+	go func() {
+		s.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// queued returns the number of jobs queued per worker.
+func (s *exportShards) queued() []int {
+	r := make([]int, len(s.queues))
+	for i, q := range s.queues {
+		r[i] = len(q)
+	}
+	return r
+}
@@ -0,0 +1,156 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+func TestExportShardsOrder(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mu := new(sync.Mutex)
+	got := make(map[string][]int)
+	s := newExportShards(ctx, 4, func(j *exportJob) {
+		i, _ := strconv.Atoi(j.m["seq"])
+		mu.Lock()
+		got[j.m["source"]] = append(got[j.m["source"]], i)
+		mu.Unlock()
+	})
+	numTargets, numRsps := 16, 500
+	wg := new(sync.WaitGroup)
+	for i := 0; i < numTargets; i++ {
+		wg.Add(1)
+		go func(source string) {
+			defer wg.Done()
+			for seq := 0; seq < numRsps; seq++ {
+				s.enqueue(&exportJob{ctx: ctx, m: outputs.Meta{"source": source, "seq": strconv.Itoa(seq)}})
+			}
+		}(fmt.Sprintf("t%d", i))
+	}
+	wg.Wait()
+	wctx, wcancel := context.WithTimeout(ctx, 5*time.Second)
+	defer wcancel()
+	if !s.wait(wctx) {
+		t.Fatal("queued responses not exported")
+	}
+	if len(got) != numTargets {
+		t.Fatalf("got %d targets, want %d", len(got), numTargets)
+	}
+	for source, seqs := range got {
+		if len(seqs) != numRsps {
+			t.Fatalf("target %s: got %d responses, want %d", source, len(seqs), numRsps)
+		}
+		for i, seq := range seqs {
+			if seq != i {
+				t.Fatalf("target %s: response %d exported at position %d", source, seq, i)
+			}
+		}
+	}
+}
+
+func TestExportShardsCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	var exported atomic.Int64
+	s := newExportShards(ctx, 1, func(j *exportJob) {
+		<-release
+		exported.Add(1)
+	})
+	for i := 0; i < 3; i++ {
+		if !s.enqueue(&exportJob{ctx: ctx, m: outputs.Meta{"source": "t1"}}) {
+			t.Fatal("job not queued")
+		}
+	}
+	cancel()
+	close(release)
+	wctx, wcancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer wcancel()
+	if !s.wait(wctx) {
+		t.Fatal("queued responses not exported after cancel")
+	}
+	if exported.Load() != 3 {
+		t.Errorf("got %d exported responses, want 3", exported.Load())
+	}
+}
+
+// benchmarkExport exports b.N responses from numTargets targets
+// through numWorkers export workers to a single output.
+func benchmarkExport(b *testing.B, numWorkers, numTargets int) {
+	a := New()
+	a.Outputs["o1"] = &testOutput{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := newExportShards(ctx, numWorkers, func(j *exportJob) {
+		a.Export(j.ctx, j.rsp, j.m, j.outs...)
+	})
+	rsp := &gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_Update{Update: &gnmi.Notification{
+		Update: []*gnmi.Update{{
+			Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "interfaces"}, {Name: "interface", Key: map[string]string{"name": "1/1"}}, {Name: "in-octets"}}},
+			Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: 42}},
+		}},
+	}}}
+	metas := make([]outputs.Meta, numTargets)
+	for i := range metas {
+		metas[i] = outputs.Meta{"source": fmt.Sprintf("t%d", i), "subscription-name": "sub1"}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	wg := new(sync.WaitGroup)
+	for i := 0; i < numTargets; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for n := i; n < b.N; n += numTargets {
+				s.enqueue(&exportJob{ctx: ctx, rsp: rsp, m: metas[i]})
+			}
+		}(i)
+	}
+	wg.Wait()
+	s.wait(ctx)
+	b.StopTimer()
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "rsp/s")
+}
+
+func BenchmarkExport(b *testing.B) {
+	for _, numWorkers := range []int{1, 4, 0} {
+		for _, numTargets := range []int{1, 64, 1024} {
+			b.Run(fmt.Sprintf("workers=%d/targets=%d", numWorkers, numTargets), func(b *testing.B) {
+				benchmarkExport(b, numWorkers, numTargets)
+			})
+		}
+	}
+}
+
+// BenchmarkExportShardsEnqueue measures the handoff of the responses to the export workers.
+func BenchmarkExportShardsEnqueue(b *testing.B) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := newExportShards(ctx, 0, func(*exportJob) {})
+	var id atomic.Int64
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		j := &exportJob{ctx: ctx, m: outputs.Meta{"source": fmt.Sprintf("t%d", id.Add(1))}}
+		for pb.Next() {
+			s.enqueue(j)
+		}
+	})
+	s.wait(ctx)
+}
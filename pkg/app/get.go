@@ -78,11 +78,7 @@ func (a *App) GetRun(cmd *cobra.Command, args []string) error {
 	// other formats
 	numTargets := len(a.Config.Targets)
 	a.errCh = make(chan error, numTargets*3)
-	a.wg.Add(numTargets)
-	for _, tc := range a.Config.Targets {
-		go a.GetRequest(ctx, tc)
-	}
-	a.wg.Wait()
+	a.runTargets(ctx, a.GetRequest)
 	err = a.checkErrors()
 	if err != nil {
 		return err
@@ -91,20 +87,19 @@ func (a *App) GetRun(cmd *cobra.Command, args []string) error {
 }
 
 func (a *App) GetRequest(ctx context.Context, tc *types.TargetConfig) {
-	defer a.wg.Done()
 	req, err := a.Config.CreateGetRequest(tc)
 	if err != nil {
-		a.logError(fmt.Errorf("target %q building Get request failed: %v", tc.Name, err))
+		a.logTargetError(tc.Name, fmt.Errorf("target %q building Get request failed: %w", tc.Name, err))
 		return
 	}
 	response, err := a.getRequest(ctx, tc, req)
 	if err != nil {
-		a.logError(fmt.Errorf("target %q Get request failed: %v", tc.Name, err))
+		a.logTargetError(tc.Name, fmt.Errorf("target %q Get request failed: %w", tc.Name, err))
 		return
 	}
 	err = a.PrintMsg(tc.Name, "Get Response:", response)
 	if err != nil {
-		a.logError(fmt.Errorf("target %q: %v", tc.Name, err))
+		a.logTargetError(tc.Name, fmt.Errorf("target %q: %w", tc.Name, err))
 	}
 }
 
@@ -126,7 +121,7 @@ func (a *App) getRequest(ctx context.Context, tc *types.TargetConfig, req *gnmi.
 	if a.Config.PrintRequest {
 		err := a.PrintMsg(tc.Name, "Get Request:", req)
 		if err != nil {
-			a.logError(fmt.Errorf("target %q Get Request printing failed: %v", tc.Name, err))
+			a.logTargetError(tc.Name, fmt.Errorf("target %q Get Request printing failed: %w", tc.Name, err))
 		}
 	}
 	a.Logger.Printf("sending gNMI GetRequest: prefix='%v', path='%v', type='%v', encoding='%v', models='%+v', extension='%+v' to %s",
@@ -189,28 +184,16 @@ func (a *App) intializeEventProcessors() ([]formatters.EventProcessor, error) {
 	}
 	var evps = make([]formatters.EventProcessor, 0)
 	for _, epName := range a.Config.GetProcessor {
-		if epCfg, ok := a.Config.Processors[epName]; ok {
-			epType := ""
-			for k := range epCfg {
-				epType = k
-				break
-			}
-			if in, ok := formatters.EventProcessors[epType]; ok {
-				ep := in()
-				err := ep.Init(epCfg[epType],
-					formatters.WithLogger(a.Logger),
-					formatters.WithTargets(a.Config.Targets),
-					formatters.WithActions(a.Config.Actions),
-				)
-				if err != nil {
-					return nil, fmt.Errorf("failed initializing event processor '%s' of type='%s': %v", epName, epType, err)
-				}
-				evps = append(evps, ep)
-				continue
-			}
-			return nil, fmt.Errorf("%q event processor has an unknown type=%q", epName, epType)
+		ep, err := formatters.NewEventProcessor(epName, a.Config.Processors,
+			formatters.WithLogger(a.Logger),
+			formatters.WithTargets(a.Config.Targets),
+			formatters.WithActions(a.Config.Actions),
+			formatters.WithProcessors(a.Config.Processors),
+		)
+		if err != nil {
+			return nil, err
 		}
-		return nil, fmt.Errorf("%q event processor not found", epName)
+		evps = append(evps, ep)
 	}
 	return evps, nil
 }
@@ -218,29 +201,24 @@ func (a *App) intializeEventProcessors() ([]formatters.EventProcessor, error) {
 func (a *App) handleGetRequestEvent(ctx context.Context, evps []formatters.EventProcessor) error {
 	numTargets := len(a.Config.Targets)
 	a.errCh = make(chan error, numTargets*3)
-	a.wg.Add(numTargets)
 	rsps := make(chan *getResponseEvents, numTargets)
-	for _, tc := range a.Config.Targets {
-		go func(tc *types.TargetConfig) {
-			defer a.wg.Done()
-			req, err := a.Config.CreateGetRequest(tc)
-			if err != nil {
-				a.errCh <- err
-				return
-			}
-			resp, err := a.getRequest(ctx, tc, req)
-			if err != nil {
-				a.errCh <- err
-				return
-			}
-			evs, err := formatters.GetResponseToEventMsgs(resp, map[string]string{"source": tc.Name}, evps...)
-			if err != nil {
-				a.errCh <- err
-			}
-			rsps <- &getResponseEvents{name: tc.Name, rsp: evs}
-		}(tc)
-	}
-	a.wg.Wait()
+	a.runTargets(ctx, func(ctx context.Context, tc *types.TargetConfig) {
+		req, err := a.Config.CreateGetRequest(tc)
+		if err != nil {
+			a.targetErr(tc.Name, err)
+			return
+		}
+		resp, err := a.getRequest(ctx, tc, req)
+		if err != nil {
+			a.targetErr(tc.Name, err)
+			return
+		}
+		evs, err := formatters.GetResponseToEventMsgs(resp, map[string]string{"source": tc.Name}, evps...)
+		if err != nil {
+			a.targetErr(tc.Name, err)
+		}
+		rsps <- &getResponseEvents{name: tc.Name, rsp: evs}
+	})
 	close(rsps)
 
 	responses := make(map[string][]*formatters.EventMsg)
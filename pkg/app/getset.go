@@ -14,7 +14,6 @@ import (
 	"errors"
 	"fmt"
 
-	"github.com/itchyny/gojq"
 	"github.com/openconfig/gnmi/proto/gnmi"
 	"github.com/openconfig/grpctunnel/tunnel"
 	"github.com/spf13/cobra"
@@ -89,27 +88,22 @@ func (a *App) GetSetRequest(ctx context.Context, tc *types.TargetConfig, req *gn
 	if a.Config.PrintRequest {
 		err := a.PrintMsg(tc.Name, "Get Request:", req)
 		if err != nil {
-			a.logError(fmt.Errorf("target %q Get Request printing failed: %v", tc.Name, err))
+			a.logTargetError(tc.Name, fmt.Errorf("target %q Get Request printing failed: %w", tc.Name, err))
 		}
 	}
 	a.Logger.Printf("sending gNMI GetRequest: prefix='%v', path='%v', type='%v', encoding='%v', models='%+v', extension='%+v' to %s",
 		xreq.Prefix, xreq.Path, xreq.Type, xreq.Encoding, xreq.UseModels, xreq.Extension, tc.Name)
 	response, err := a.ClientGet(ctx, tc, xreq)
 	if err != nil {
-		a.logError(fmt.Errorf("target %q get request failed: %v", tc.Name, err))
+		a.logTargetError(tc.Name, fmt.Errorf("target %q get request failed: %w", tc.Name, err))
 		return
 	}
 	err = a.PrintMsg(tc.Name, "Get Response:", response)
 	if err != nil {
-		a.logError(fmt.Errorf("target %q: %v", tc.Name, err))
+		a.logTargetError(tc.Name, fmt.Errorf("target %q: %w", tc.Name, err))
 	}
 	//
-	q, err := gojq.Parse(a.Config.LocalFlags.GetSetCondition)
-	if err != nil {
-		a.logError(err)
-		return
-	}
-	code, err := gojq.Compile(q)
+	code, err := formatters.CompileJQ(a.Config.LocalFlags.GetSetCondition)
 	if err != nil {
 		a.logError(err)
 		return
@@ -33,11 +33,11 @@ func (a *App) ClientCapabilities(ctx context.Context, tc *types.TargetConfig, ex
 	if err != nil {
 		return nil, err
 	}
-	ctx, cancel := context.WithTimeout(ctx, t.Config.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, t.Config.RPCTimeout(types.RPCCapabilities))
 	defer cancel()
 	capResponse, err := t.Capabilities(ctx, ext...)
 	if err != nil {
-		return nil, fmt.Errorf("%q CapabilitiesRequest failed: %v", t.Config.Address, err)
+		return nil, fmt.Errorf("%q CapabilitiesRequest failed: %w", t.Config.Address, err)
 	}
 	return capResponse, nil
 
@@ -57,11 +57,11 @@ func (a *App) ClientGet(ctx context.Context, tc *types.TargetConfig, req *gnmi.G
 	if err != nil {
 		return nil, err
 	}
-	ctx, cancel := context.WithTimeout(ctx, t.Config.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, t.Config.RPCTimeout(types.RPCGet))
 	defer cancel()
 	getResponse, err := t.Get(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("%q GetRequest failed: %v", t.Config.Address, err)
+		return nil, fmt.Errorf("%q GetRequest failed: %w", t.Config.Address, err)
 	}
 	return getResponse, nil
 }
@@ -80,11 +80,11 @@ func (a *App) ClientSet(ctx context.Context, tc *types.TargetConfig, req *gnmi.S
 	if err != nil {
 		return nil, err
 	}
-	ctx, cancel := context.WithTimeout(ctx, t.Config.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, t.Config.RPCTimeout(types.RPCSet))
 	defer cancel()
 	setResponse, err := t.Set(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("target %q SetRequest failed: %v", t.Config.Name, err)
+		return nil, fmt.Errorf("target %q SetRequest failed: %w", t.Config.Name, err)
 	}
 	return setResponse, nil
 }
@@ -31,6 +31,8 @@ type subscriptionRequest struct {
 	name string
 	// gNMI subscription request
 	req *gnmi.SubscribeRequest
+	// subscription config
+	config *types.SubscriptionConfig
 }
 
 func (a *App) TargetSubscribeStream(ctx context.Context, tc *types.TargetConfig) {
@@ -70,6 +72,9 @@ START:
 				goto START
 			}
 			a.Logger.Printf("acquired lock for target %q", tc.Name)
+			a.operLock.Lock()
+			a.targetsLockTime[tc.Name] = time.Now()
+			a.operLock.Unlock()
 		}
 		a.Logger.Printf("queuing target %q", tc.Name)
 		a.targetsChan <- t
@@ -173,7 +178,10 @@ func (a *App) clientSubscribe(ctx context.Context, tc *types.TargetConfig) error
 				os.Exit(1)
 			}
 		}
-		subRequests = append(subRequests, subscriptionRequest{name: scName, req: req})
+		for _, w := range config.SubscriptionWarnings(sc, tc) {
+			a.Logger.Printf("target %q subscription %q: %s", tc.Name, scName, w)
+		}
+		subRequests = append(subRequests, subscriptionRequest{name: scName, req: req, config: sc})
 	}
 	if t.Cfn != nil {
 		t.Cfn()
@@ -209,10 +217,19 @@ CRCLIENT:
 		}
 	}
 	a.Logger.Printf("target %q gNMI client created", t.Config.Name)
+	go a.checkStreamOptionsSupport(gnmiCtx, t, subRequests)
 
 	for _, sreq := range subRequests {
 		a.Logger.Printf("sending gNMI SubscribeRequest: subscribe='%+v', mode='%+v', encoding='%+v', to %s",
 			sreq.req, sreq.req.GetSubscribe().GetMode(), sreq.req.GetSubscribe().GetEncoding(), t.Config.Name)
+		if sreq.config.AdaptiveSampling != nil {
+			go a.adaptiveSubscribe(gnmiCtx, t, sreq)
+			continue
+		}
+		if sreq.config.Schedule != nil {
+			go a.scheduledSubscribe(gnmiCtx, t, sreq)
+			continue
+		}
 		go t.Subscribe(gnmiCtx, sreq.req, sreq.name)
 	}
 	return nil
@@ -292,6 +309,8 @@ OUTER:
 					continue
 				default:
 					m := outputs.Meta{"source": t.Config.Name, "format": a.Config.Format, "subscription-name": sreq.name}
+					a.recordResponse(rsp, m)
+					a.capturePromptEvents(rsp, m)
 					a.Export(ctx, rsp, m, t.Config.Outputs...)
 				}
 			}
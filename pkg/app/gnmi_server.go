@@ -10,6 +10,7 @@ package app
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -53,6 +54,10 @@ func (a *App) startGnmiServer() error {
 		a.Logger.Printf("failed to initialize gNMI cache: %v", err)
 		return err
 	}
+	tlsConfig, err := a.gnmiServerTLSConfig()
+	if err != nil {
+		return err
+	}
 
 	s, err := server.New(server.Config{
 		Address:              a.Config.GnmiServer.Address,
@@ -66,6 +71,7 @@ func (a *App) startGnmiServer() error {
 		RateLimit:            a.Config.GnmiServer.RateLimit,
 		HealthEnabled:        true,
 		TLS:                  a.Config.GnmiServer.TLS,
+		TLSConfig:            tlsConfig,
 	}, server.WithLogger(a.Logger),
 		server.WithGetHandler(a.serverGetHandler),
 		server.WithSetHandler(a.serverSetHandler),
@@ -89,6 +95,19 @@ func (a *App) startGnmiServer() error {
 	return nil
 }
 
+// gnmiServerTLSConfig returns a SPIFFE based TLS config if
+// the gNMI server is configured to use the Workload API SVIDs,
+// or a TLS config serving a generated certificate if it is configured to use one.
+func (a *App) gnmiServerTLSConfig() (*tls.Config, error) {
+	if a.gnmiServerUsesSpiffe() {
+		return a.spiffeServerTLSConfig(a.ctx, a.Config.Spiffe.GNMIServer)
+	}
+	if a.gnmiServerUsesCert() {
+		return a.certServerTLSConfig(certGNMIServer, a.Config.Cert.GNMIServer, a.Config.GnmiServer.Address)
+	}
+	return nil, nil
+}
+
 func (a *App) registerGNMIServer(ctx context.Context, defaultTags ...string) {
 	if a.Config.GnmiServer.ServiceRegistration == nil {
 		return
@@ -21,6 +21,10 @@ func (a *App) InitInput(ctx context.Context, name string, tcs map[string]*types.
 	if _, ok := a.Inputs[name]; ok {
 		return
 	}
+	if a.components.isDisabled(componentInputs, name) {
+		a.Logger.Printf("input %q is disabled", name)
+		return
+	}
 	if cfg, ok := a.Config.Inputs[name]; ok {
 		if inputType, ok := cfg["type"]; ok {
 			a.Logger.Printf("starting input type %s", inputType)
@@ -0,0 +1,318 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/openconfig/gnmic/pkg/api/path"
+	"github.com/openconfig/gnmic/pkg/config"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+// inventoryList is a list of items of an inventory kind,
+// identified by the "name" key of the list element.
+type inventoryList struct {
+	kind  string
+	elems []string
+}
+
+// inventoryLeaf is a state leaf of an inventory list, stored as field.
+type inventoryLeaf struct {
+	list  inventoryList
+	elems []string
+	field string
+	// the module prefix of identity values is removed
+	identity bool
+}
+
+var (
+	inventoryInterfaces = inventoryList{kind: config.InventoryKindInterfaces, elems: []string{"interfaces", "interface"}}
+	inventoryLAGs       = inventoryList{kind: config.InventoryKindLAGs, elems: []string{"interfaces", "interface"}}
+	inventoryOptics     = inventoryList{kind: config.InventoryKindOptics, elems: []string{"components", "component"}}
+)
+
+var inventoryLeaves = []inventoryLeaf{
+	{list: inventoryInterfaces, elems: []string{"state", "description"}, field: "description"},
+	{list: inventoryInterfaces, elems: []string{"state", "type"}, field: "type", identity: true},
+	{list: inventoryInterfaces, elems: []string{"state", "admin-status"}, field: "admin-status"},
+	{list: inventoryInterfaces, elems: []string{"state", "oper-status"}, field: "oper-status"},
+	{list: inventoryInterfaces, elems: []string{"state", "mtu"}, field: "mtu"},
+	{list: inventoryInterfaces, elems: []string{"state", "ifindex"}, field: "ifindex"},
+	{list: inventoryInterfaces, elems: []string{"ethernet", "state", "port-speed"}, field: "speed", identity: true},
+	{list: inventoryInterfaces, elems: []string{"ethernet", "state", "mac-address"}, field: "mac-address"},
+	{list: inventoryInterfaces, elems: []string{"ethernet", "state", "aggregate-id"}, field: "lag"},
+	{list: inventoryLAGs, elems: []string{"aggregation", "state", "lag-type"}, field: "type", identity: true},
+	{list: inventoryLAGs, elems: []string{"aggregation", "state", "min-links"}, field: "min-links"},
+	{list: inventoryLAGs, elems: []string{"aggregation", "state", "lag-speed"}, field: "speed"},
+	{list: inventoryLAGs, elems: []string{"aggregation", "state", "member"}, field: "members"},
+	{list: inventoryOptics, elems: []string{"transceiver", "state", "form-factor"}, field: "form-factor", identity: true},
+	{list: inventoryOptics, elems: []string{"transceiver", "state", "ethernet-pmd"}, field: "pmd", identity: true},
+	{list: inventoryOptics, elems: []string{"transceiver", "state", "present"}, field: "present"},
+	{list: inventoryOptics, elems: []string{"transceiver", "state", "serial-no"}, field: "serial-no"},
+	{list: inventoryOptics, elems: []string{"transceiver", "state", "vendor"}, field: "vendor"},
+	{list: inventoryOptics, elems: []string{"transceiver", "state", "vendor-part"}, field: "part-no"},
+}
+
+// inventoryStore holds the inventory of each target.
+type inventoryStore struct {
+	m           sync.RWMutex
+	inventories map[string]*targetInventory
+}
+
+// targetInventory holds the fields of the items of a target per kind and item name.
+type targetInventory struct {
+	items      map[string]map[string]map[string]any
+	lastUpdate time.Time
+}
+
+func newInventoryStore() *inventoryStore {
+	return &inventoryStore{inventories: make(map[string]*targetInventory)}
+}
+
+// matchElems returns true if the names of elems are names.
+func matchElems(elems []*gnmi.PathElem, names []string) bool {
+	if len(elems) != len(names) {
+		return false
+	}
+	for i, n := range names {
+		if elemName(elems[i]) != n {
+			return false
+		}
+	}
+	return true
+}
+
+// updateInventory updates the inventory of the target with the state leaves of rsp.
+func (a *App) updateInventory(rsp *gnmi.SubscribeResponse, m outputs.Meta, now time.Time) {
+	a.configLock.RLock()
+	inv := a.Config.Inventory
+	a.configLock.RUnlock()
+	n := rsp.GetUpdate()
+	if inv == nil || n == nil {
+		return
+	}
+	a.inventory.update(inv, m["source"], n, now)
+}
+
+// update applies the updates and deletes of the notification n of target to its inventory.
+func (s *inventoryStore) update(inv *config.Inventory, target string, n *gnmi.Notification, now time.Time) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	ti := s.inventories[target]
+	for _, del := range n.GetDelete() {
+		if ti == nil {
+			break
+		}
+		elems := path.PathElems(n.GetPrefix(), del)
+		for _, l := range []inventoryList{inventoryInterfaces, inventoryLAGs, inventoryOptics} {
+			if matchElems(elems, l.elems) {
+				delete(ti.items[l.kind], elems[len(elems)-1].GetKey()["name"])
+			}
+		}
+		for _, leaf := range inventoryLeaves {
+			if !leaf.match(elems) {
+				continue
+			}
+			if item := ti.item(leaf, elems, false); item != nil {
+				delete(item, leaf.field)
+			}
+		}
+		ti.lastUpdate = now
+	}
+	for _, upd := range n.GetUpdate() {
+		elems := path.PathElems(n.GetPrefix(), upd.GetPath())
+		for _, leaf := range inventoryLeaves {
+			if !inv.Includes(leaf.list.kind) || !leaf.match(elems) {
+				continue
+			}
+			if ti == nil {
+				ti = &targetInventory{items: make(map[string]map[string]map[string]any)}
+				s.inventories[target] = ti
+			}
+			item := ti.item(leaf, elems, true)
+			if item == nil {
+				continue
+			}
+			v := leafValue(upd.GetVal())
+			if str, ok := v.(string); ok && leaf.identity {
+				v = stripPrefix(str)
+			}
+			item[leaf.field] = v
+			ti.lastUpdate = now
+		}
+	}
+}
+
+// match returns true if elems is the path of the leaf.
+func (leaf inventoryLeaf) match(elems []*gnmi.PathElem) bool {
+	nl := len(leaf.list.elems)
+	return len(elems) == nl+len(leaf.elems) &&
+		matchElems(elems[:nl], leaf.list.elems) &&
+		matchElems(elems[nl:], leaf.elems)
+}
+
+// item returns the fields of the item the leaf path elems belongs to,
+// the item is created if create is true.
+func (ti *targetInventory) item(leaf inventoryLeaf, elems []*gnmi.PathElem, create bool) map[string]any {
+	name := elems[len(leaf.list.elems)-1].GetKey()["name"]
+	if name == "" {
+		return nil
+	}
+	items, ok := ti.items[leaf.list.kind]
+	if !ok {
+		if !create {
+			return nil
+		}
+		items = make(map[string]map[string]any)
+		ti.items[leaf.list.kind] = items
+	}
+	item, ok := items[name]
+	if !ok {
+		if !create {
+			return nil
+		}
+		item = make(map[string]any)
+		items[name] = item
+	}
+	return item
+}
+
+// deleteTarget removes the inventory of target.
+func (s *inventoryStore) deleteTarget(target string) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	delete(s.inventories, target)
+}
+
+// get returns the inventory of target restricted to kinds and fields, all are returned if empty.
+func (s *inventoryStore) get(target string, kinds, fields []string) (map[string]any, bool) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+	ti, ok := s.inventories[target]
+	if !ok {
+		return nil, false
+	}
+	res := map[string]any{
+		"target":      target,
+		"last-update": ti.lastUpdate,
+	}
+	for kind, items := range ti.items {
+		if len(kinds) > 0 && !slices.Contains(kinds, kind) {
+			continue
+		}
+		kItems := make(map[string]map[string]any, len(items))
+		for name, item := range items {
+			c := make(map[string]any, len(item))
+			for f, v := range item {
+				if len(fields) > 0 && !slices.Contains(fields, f) {
+					continue
+				}
+				c[f] = v
+			}
+			kItems[name] = c
+		}
+		res[kind] = kItems
+	}
+	return res, true
+}
+
+// targets returns the names of the targets with an inventory.
+func (s *inventoryStore) targets() []string {
+	s.m.RLock()
+	defer s.m.RUnlock()
+	res := make([]string, 0, len(s.inventories))
+	for t := range s.inventories {
+		res = append(res, t)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// inventoryFilters returns the kinds and fields query parameters of r.
+func inventoryFilters(r *http.Request) ([]string, []string, error) {
+	var kinds, fields []string
+	q := r.URL.Query()
+	for _, k := range q["kinds"] {
+		for _, kind := range strings.Split(k, ",") {
+			switch kind {
+			case config.InventoryKindInterfaces, config.InventoryKindLAGs, config.InventoryKindOptics:
+				kinds = append(kinds, kind)
+			default:
+				return nil, nil, fmt.Errorf("unknown inventory kind %q", kind)
+			}
+		}
+	}
+	for _, f := range q["fields"] {
+		fields = append(fields, strings.Split(f, ",")...)
+	}
+	return kinds, fields, nil
+}
+
+func (a *App) inventoryEnabled(w http.ResponseWriter, r *http.Request) bool {
+	a.configLock.RLock()
+	enabled := a.Config.Inventory != nil
+	a.configLock.RUnlock()
+	if !enabled {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{"inventory is not enabled"}})
+	}
+	return enabled
+}
+
+func (a *App) handleInventoryGet(w http.ResponseWriter, r *http.Request) {
+	if !a.inventoryEnabled(w, r) {
+		return
+	}
+	kinds, fields, err := inventoryFilters(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	id := mux.Vars(r)["target"]
+	var res any
+	if id != "" {
+		inv, ok := a.inventory.get(id, kinds, fields)
+		if !ok || !a.targetVisible(r, id) {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(APIErrors{Errors: []string{fmt.Sprintf("target %q inventory not found", id)}})
+			return
+		}
+		res = inv
+	} else {
+		invs := make([]map[string]any, 0)
+		for _, t := range a.inventory.targets() {
+			if !a.targetVisible(r, t) {
+				continue
+			}
+			if inv, ok := a.inventory.get(t, kinds, fields); ok {
+				invs = append(invs, inv)
+			}
+		}
+		res = invs
+	}
+	b, err := json.Marshal(res)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	w.Write(b)
+}
@@ -0,0 +1,165 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/openconfig/gnmic/pkg/api/path"
+	"github.com/openconfig/gnmic/pkg/config"
+)
+
+func inventoryNotification(t *testing.T, upds map[string]*gnmi.TypedValue, dels ...string) *gnmi.Notification {
+	n := new(gnmi.Notification)
+	for p, v := range upds {
+		gp, err := path.ParsePath(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n.Update = append(n.Update, &gnmi.Update{Path: gp, Val: v})
+	}
+	for _, p := range dels {
+		gp, err := path.ParsePath(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n.Delete = append(n.Delete, gp)
+	}
+	return n
+}
+
+func testInventoryNotification(t *testing.T) *gnmi.Notification {
+	return inventoryNotification(t, map[string]*gnmi.TypedValue{
+		"/interfaces/interface[name=ethernet-1/1]/state/oper-status":           stringVal("UP"),
+		"/interfaces/interface[name=ethernet-1/1]/state/type":                  stringVal("iana-if-type:ethernetCsmacd"),
+		"/interfaces/interface[name=ethernet-1/1]/ethernet/state/port-speed":   stringVal("openconfig-if-ethernet:SPEED_100GB"),
+		"/interfaces/interface[name=ethernet-1/1]/ethernet/state/aggregate-id": stringVal("lag1"),
+		"/interfaces/interface[name=ethernet-1/1]/state/counters/in-octets":    {Value: &gnmi.TypedValue_UintVal{UintVal: 10}},
+		"/interfaces/interface[name=lag1]/aggregation/state/member": {Value: &gnmi.TypedValue_LeaflistVal{LeaflistVal: &gnmi.ScalarArray{
+			Element: []*gnmi.TypedValue{stringVal("ethernet-1/1"), stringVal("ethernet-1/2")},
+		}}},
+		"/components/component[name=transceiver-1/1]/transceiver/state/serial-no": stringVal("SN123"),
+		"/components/component[name=transceiver-1/1]/transceiver/state/present":   stringVal("PRESENT"),
+		"/components/component[name=chassis]/state/serial-no":                     stringVal("CH1"),
+	})
+}
+
+func TestInventoryStore(t *testing.T) {
+	s := newInventoryStore()
+	inv := &config.Inventory{Kinds: []string{config.InventoryKindInterfaces, config.InventoryKindLAGs, config.InventoryKindOptics}}
+	now := time.Unix(1000, 0)
+	s.update(inv, "r1", testInventoryNotification(t), now)
+	got, ok := s.get("r1", nil, nil)
+	if !ok {
+		t.Fatal("missing r1 inventory")
+	}
+	want := map[string]any{
+		"target":      "r1",
+		"last-update": now,
+		"interfaces": map[string]map[string]any{
+			"ethernet-1/1": {"oper-status": "UP", "type": "ethernetCsmacd", "speed": "SPEED_100GB", "lag": "lag1"},
+		},
+		"lags": map[string]map[string]any{
+			"lag1": {"members": []any{"ethernet-1/1", "ethernet-1/2"}},
+		},
+		"optics": map[string]map[string]any{
+			"transceiver-1/1": {"serial-no": "SN123", "present": "PRESENT"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	// filters
+	got, _ = s.get("r1", []string{config.InventoryKindInterfaces}, []string{"oper-status"})
+	want = map[string]any{
+		"target":      "r1",
+		"last-update": now,
+		"interfaces": map[string]map[string]any{
+			"ethernet-1/1": {"oper-status": "UP"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	// deletes
+	s.update(inv, "r1", inventoryNotification(t, nil,
+		"/interfaces/interface[name=lag1]",
+		"/interfaces/interface[name=ethernet-1/1]/ethernet/state/aggregate-id",
+	), now)
+	got, _ = s.get("r1", []string{config.InventoryKindInterfaces, config.InventoryKindLAGs}, nil)
+	if lags := got["lags"].(map[string]map[string]any); len(lags) != 0 {
+		t.Errorf("unexpected LAGs after delete: %v", lags)
+	}
+	if _, ok := got["interfaces"].(map[string]map[string]any)["ethernet-1/1"]["lag"]; ok {
+		t.Errorf("unexpected lag field after delete")
+	}
+	// kinds not included
+	s.update(&config.Inventory{Kinds: []string{config.InventoryKindOptics}}, "r2", testInventoryNotification(t), now)
+	got, _ = s.get("r2", nil, nil)
+	if _, ok := got["interfaces"]; ok {
+		t.Errorf("unexpected interfaces in r2 inventory")
+	}
+}
+
+func TestAPIInventory(t *testing.T) {
+	a := newNamespacedApp()
+	if rec := apiRequest(a, http.MethodGet, "/api/v1/inventory/t1", "admin", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("got status %d with inventory disabled, want 404", rec.Code)
+	}
+	a.Config.Inventory = &config.Inventory{Kinds: []string{config.InventoryKindInterfaces, config.InventoryKindOptics}}
+	for _, tg := range []string{"t1", "t2"} {
+		a.inventory.update(a.Config.Inventory, tg, testInventoryNotification(t), time.Now())
+	}
+	tests := []struct {
+		name   string
+		path   string
+		token  string
+		status int
+	}{
+		{name: "target", path: "/api/v1/inventory/t1?kinds=optics&fields=serial-no", token: "tk1", status: http.StatusOK},
+		{name: "other_namespace", path: "/api/v1/inventory/t2", token: "tk1", status: http.StatusNotFound},
+		{name: "unknown_target", path: "/api/v1/inventory/t3", token: "admin", status: http.StatusNotFound},
+		{name: "unknown_kind", path: "/api/v1/inventory/t1?kinds=vlans", token: "admin", status: http.StatusBadRequest},
+		{name: "all", path: "/api/v1/inventory", token: "tk1", status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := apiRequest(a, http.MethodGet, tt.path, tt.token, "")
+			if rec.Code != tt.status {
+				t.Fatalf("got status %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+		})
+	}
+	rec := apiRequest(a, http.MethodGet, "/api/v1/inventory/t1?kinds=optics&fields=serial-no", "tk1", "")
+	var inv struct {
+		Target     string                       `json:"target"`
+		Interfaces map[string]any               `json:"interfaces"`
+		Optics     map[string]map[string]string `json:"optics"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &inv); err != nil {
+		t.Fatal(err)
+	}
+	if inv.Target != "t1" || inv.Interfaces != nil || !reflect.DeepEqual(inv.Optics, map[string]map[string]string{"transceiver-1/1": {"serial-no": "SN123"}}) {
+		t.Errorf("unexpected inventory %s", rec.Body.String())
+	}
+	rec = apiRequest(a, http.MethodGet, "/api/v1/inventory", "tk1", "")
+	var invs []map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &invs); err != nil {
+		t.Fatal(err)
+	}
+	if len(invs) != 1 || invs[0]["target"] != "t1" {
+		t.Errorf("expected only the t1 inventory, got %s", rec.Body.String())
+	}
+}
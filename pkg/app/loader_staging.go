@@ -0,0 +1,173 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/loaders"
+)
+
+// loaderStagingConfig is read from the loader configuration,
+// next to the loader type specific fields.
+type loaderStagingConfig struct {
+	// compute and expose the loader changes, never apply them
+	DryRun bool `mapstructure:"dry-run,omitempty" json:"dry-run,omitempty"`
+	// hold all the loader changes until they are approved through the API
+	RequireApproval bool `mapstructure:"require-approval,omitempty" json:"require-approval,omitempty"`
+	// hold the loader changes if they add or delete more than max-changes targets,
+	// until they are approved through the API
+	MaxChanges int `mapstructure:"max-changes,omitempty" json:"max-changes,omitempty"`
+}
+
+func (c *loaderStagingConfig) enabled() bool {
+	return c.DryRun || c.RequireApproval || c.MaxChanges > 0
+}
+
+// loaderStaging holds the loader target operations not applied yet.
+type loaderStaging struct {
+	m   *sync.Mutex
+	cfg *loaderStagingConfig
+	// changes not applied, relative to the applied targets
+	pending *loaders.TargetOperation
+	since   time.Time
+	reason  string
+	// set once a loader operation was applied
+	loaded bool
+	// receives the approved operations
+	approved chan *loaders.TargetOperation
+}
+
+type loaderPendingResponse struct {
+	DryRun bool      `json:"dry-run,omitempty"`
+	Since  time.Time `json:"since,omitempty"`
+	Reason string    `json:"reason,omitempty"`
+	Add    []string  `json:"add,omitempty"`
+	Del    []string  `json:"del,omitempty"`
+	// targets removed from the loader source,
+	// waiting for the delete grace period to expire
+	Inactive []inactiveTarget `json:"inactive,omitempty"`
+}
+
+func newLoaderStaging() *loaderStaging {
+	return &loaderStaging{
+		m:        new(sync.Mutex),
+		cfg:      new(loaderStagingConfig),
+		approved: make(chan *loaders.TargetOperation, 1),
+	}
+}
+
+func (s *loaderStaging) setConfig(cfg *loaderStagingConfig) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.cfg = cfg
+}
+
+// stage adds op to the pending changes.
+// It returns the operation to apply now, if any.
+func (s *loaderStaging) stage(op *loaders.TargetOperation) (*loaders.TargetOperation, string) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if !s.cfg.enabled() {
+		s.loaded = true
+		return op, ""
+	}
+	if s.pending == nil {
+		s.pending = new(loaders.TargetOperation)
+		s.since = time.Now()
+	}
+	s.pending.Merge(op)
+	switch {
+	case s.cfg.DryRun:
+		s.reason = "dry-run"
+	case s.cfg.RequireApproval:
+		s.reason = "approval required"
+	case s.loaded && s.pending.Len() > s.cfg.MaxChanges:
+		s.reason = fmt.Sprintf("%d changes above max-changes %d", s.pending.Len(), s.cfg.MaxChanges)
+	default:
+		return s.take(), ""
+	}
+	return nil, s.reason
+}
+
+func (s *loaderStaging) take() *loaders.TargetOperation {
+	op := s.pending
+	s.pending = nil
+	s.reason = ""
+	s.loaded = true
+	return op
+}
+
+func (s *loaderStaging) pendingResponse() *loaderPendingResponse {
+	s.m.Lock()
+	defer s.m.Unlock()
+	rsp := &loaderPendingResponse{DryRun: s.cfg.DryRun}
+	if s.pending == nil {
+		return rsp
+	}
+	rsp.Since = s.since
+	rsp.Reason = s.reason
+	for n := range s.pending.Add {
+		rsp.Add = append(rsp.Add, n)
+	}
+	sort.Strings(rsp.Add)
+	rsp.Del = append(rsp.Del, s.pending.Del...)
+	sort.Strings(rsp.Del)
+	return rsp
+}
+
+// approve passes the pending changes to the loader loop to be applied.
+func (s *loaderStaging) approve() error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.cfg.DryRun {
+		return errors.New("loader is in dry-run mode")
+	}
+	if s.pending == nil {
+		return errors.New("no pending loader changes")
+	}
+	if len(s.approved) == cap(s.approved) {
+		return errors.New("previously approved changes are being applied")
+	}
+	s.approved <- s.take()
+	return nil
+}
+
+// discard drops the pending changes.
+func (s *loaderStaging) discard() {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.pending = nil
+	s.reason = ""
+}
+
+func (a *App) handleLoaderPendingGet(w http.ResponseWriter, r *http.Request) {
+	rsp := a.loaderStaging.pendingResponse()
+	rsp.Inactive = a.targetTombstones.list()
+	a.handlerCommonGet(w, rsp)
+}
+
+func (a *App) handleLoaderPendingApprovePost(w http.ResponseWriter, r *http.Request) {
+	err := a.loaderStaging.approve()
+	if err != nil {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+}
+
+func (a *App) handleLoaderPendingDelete(w http.ResponseWriter, r *http.Request) {
+	a.loaderStaging.discard()
+}
@@ -0,0 +1,100 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/loaders"
+)
+
+func addOp(names ...string) *loaders.TargetOperation {
+	op := &loaders.TargetOperation{Add: make(map[string]*types.TargetConfig)}
+	for _, n := range names {
+		op.Add[n] = &types.TargetConfig{Name: n}
+	}
+	return op
+}
+
+func TestLoaderStagingMaxChanges(t *testing.T) {
+	s := newLoaderStaging()
+	s.setConfig(&loaderStagingConfig{MaxChanges: 2})
+	// the initial load is not held
+	op, _ := s.stage(addOp("t1", "t2", "t3"))
+	if op == nil || op.Len() != 3 {
+		t.Fatalf("initial load should be applied, got %+v", op)
+	}
+	op, _ = s.stage(addOp("t4"))
+	if op == nil || op.Len() != 1 {
+		t.Fatalf("change below max-changes should be applied, got %+v", op)
+	}
+	op, reason := s.stage(&loaders.TargetOperation{Del: []string{"t1", "t2", "t3"}})
+	if op != nil || reason == "" {
+		t.Fatalf("change above max-changes should be held, got %+v", op)
+	}
+	// changes received while held are merged
+	op, _ = s.stage(addOp("t1"))
+	if op != nil {
+		t.Fatalf("change should be held, got %+v", op)
+	}
+	rsp := s.pendingResponse()
+	if !cmp.Equal(rsp.Add, []string{"t1"}) || !cmp.Equal(rsp.Del, []string{"t1", "t2", "t3"}) {
+		t.Errorf("unexpected pending changes: %+v", rsp)
+	}
+	err := s.approve()
+	if err != nil {
+		t.Fatalf("failed to approve: %v", err)
+	}
+	op = <-s.approved
+	if op.Len() != 4 {
+		t.Errorf("unexpected approved operation: %+v", op)
+	}
+	if err = s.approve(); err == nil {
+		t.Errorf("approve without pending changes should fail")
+	}
+}
+
+func TestLoaderStagingDryRun(t *testing.T) {
+	s := newLoaderStaging()
+	s.setConfig(&loaderStagingConfig{DryRun: true})
+	op, _ := s.stage(addOp("t1"))
+	if op != nil {
+		t.Fatalf("dry-run should not apply changes, got %+v", op)
+	}
+	if err := s.approve(); err == nil {
+		t.Errorf("approve in dry-run mode should fail")
+	}
+	s.discard()
+	if rsp := s.pendingResponse(); len(rsp.Add) != 0 || !rsp.DryRun {
+		t.Errorf("unexpected pending changes after discard: %+v", rsp)
+	}
+}
+
+func TestLoaderPendingAPI(t *testing.T) {
+	a := New()
+	a.routes()
+	a.loaderStaging.setConfig(&loaderStagingConfig{RequireApproval: true})
+	a.loaderStaging.stage(addOp("t1"))
+	rec := apiRequest(a, http.MethodGet, "/api/v1/loader/pending", "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+	}
+	rec = apiRequest(a, http.MethodPost, "/api/v1/loader/pending/approve", "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+	}
+	rec = apiRequest(a, http.MethodPost, "/api/v1/loader/pending/approve", "", "")
+	if rec.Code != http.StatusConflict {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusConflict)
+	}
+}
@@ -0,0 +1,154 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/loaders"
+)
+
+// loaderTombstonesConfig is read from the loader configuration,
+// next to the loader type specific fields.
+type loaderTombstonesConfig struct {
+	// time a target removed from the loader source stays inactive
+	// before it is deleted, 0 deletes the targets immediately
+	DeleteGracePeriod time.Duration `mapstructure:"delete-grace-period,omitempty" json:"delete-grace-period,omitempty"`
+}
+
+// targetTombstones holds the targets removed from the loader source
+// and not deleted yet, with the time they were deactivated.
+type targetTombstones struct {
+	m       *sync.Mutex
+	targets map[string]time.Time
+}
+
+type inactiveTarget struct {
+	Name  string    `json:"name"`
+	Since time.Time `json:"since"`
+}
+
+func newTargetTombstones() *targetTombstones {
+	return &targetTombstones{
+		m:       new(sync.Mutex),
+		targets: make(map[string]time.Time),
+	}
+}
+
+func (tt *targetTombstones) add(name string, now time.Time) {
+	tt.m.Lock()
+	defer tt.m.Unlock()
+	if _, ok := tt.targets[name]; !ok {
+		tt.targets[name] = now
+	}
+}
+
+// remove deletes the target tombstone, it returns true if it existed.
+func (tt *targetTombstones) remove(name string) bool {
+	tt.m.Lock()
+	defer tt.m.Unlock()
+	_, ok := tt.targets[name]
+	delete(tt.targets, name)
+	return ok
+}
+
+func (tt *targetTombstones) has(name string) bool {
+	tt.m.Lock()
+	defer tt.m.Unlock()
+	_, ok := tt.targets[name]
+	return ok
+}
+
+// expired removes and returns the targets inactive for more than gracePeriod.
+func (tt *targetTombstones) expired(now time.Time, gracePeriod time.Duration) []string {
+	tt.m.Lock()
+	defer tt.m.Unlock()
+	var rs []string
+	for name, since := range tt.targets {
+		if now.Sub(since) >= gracePeriod {
+			rs = append(rs, name)
+			delete(tt.targets, name)
+		}
+	}
+	sort.Strings(rs)
+	return rs
+}
+
+func (tt *targetTombstones) list() []inactiveTarget {
+	tt.m.Lock()
+	defer tt.m.Unlock()
+	rs := make([]inactiveTarget, 0, len(tt.targets))
+	for name, since := range tt.targets {
+		rs = append(rs, inactiveTarget{Name: name, Since: since})
+	}
+	sort.Slice(rs, func(i, j int) bool {
+		return rs[i].Name < rs[j].Name
+	})
+	return rs
+}
+
+// tombstoneTargets deactivates the targets deleted by op instead of deleting them,
+// and reactivates the targets added back by op.
+// It returns the operation left to apply.
+func (a *App) tombstoneTargets(ctx context.Context, op *loaders.TargetOperation) *loaders.TargetOperation {
+	for name := range op.Add {
+		if a.targetTombstones.remove(name) {
+			a.Logger.Printf("target %q is back in the loader source, reactivating it", name)
+		}
+	}
+	rs := &loaders.TargetOperation{Add: op.Add}
+	now := time.Now()
+	for _, name := range op.Del {
+		// deleted and added back, recreate it
+		if _, ok := op.Add[name]; ok {
+			rs.Del = append(rs.Del, name)
+			continue
+		}
+		a.Logger.Printf("target %q removed from the loader source, deactivating it", name)
+		a.targetTombstones.add(name, now)
+		a.deactivateTarget(ctx, name)
+	}
+	return rs
+}
+
+// deactivateTarget stops the target subscriptions and keeps its configuration.
+func (a *App) deactivateTarget(ctx context.Context, name string) {
+	// not clustered, stop local target
+	if !a.inCluster() {
+		a.operLock.Lock()
+		if cfn, ok := a.targetsLockFn[name]; ok {
+			cfn()
+			delete(a.targetsLockFn, name)
+		}
+		a.operLock.Unlock()
+		err := a.stopTarget(ctx, name)
+		if err != nil {
+			a.Logger.Printf("failed to stop target %q: %v", name, err)
+		}
+		return
+	}
+	// clustered, unassign target from its instance,
+	// it is not dispatched again while inactive
+	locks, err := a.getTargetLocks(ctx)
+	if err != nil {
+		a.Logger.Printf("failed to get target %q owner: %v", name, err)
+		return
+	}
+	instance, ok := locks[name]
+	if !ok {
+		return
+	}
+	err = a.unassignTarget(ctx, name, instance+"-api")
+	if err != nil {
+		a.Logger.Printf("failed to unassign target %q from %q: %v", name, instance, err)
+	}
+}
@@ -0,0 +1,65 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/loaders"
+)
+
+func TestTargetTombstonesExpired(t *testing.T) {
+	tt := newTargetTombstones()
+	now := time.Now()
+	tt.add("t1", now.Add(-2*time.Minute))
+	tt.add("t2", now.Add(-30*time.Second))
+	// adding an existing tombstone keeps its time
+	tt.add("t1", now)
+	if got := tt.expired(now, time.Minute); !cmp.Equal(got, []string{"t1"}) {
+		t.Errorf("unexpected expired targets: %v", got)
+	}
+	if tt.has("t1") || !tt.has("t2") {
+		t.Errorf("unexpected tombstones: %+v", tt.list())
+	}
+	if !tt.remove("t2") || tt.remove("t2") {
+		t.Errorf("unexpected remove result")
+	}
+}
+
+func TestTombstoneTargets(t *testing.T) {
+	a := New()
+	a.Config.Targets = map[string]*types.TargetConfig{
+		"t1": {Name: "t1"},
+		"t2": {Name: "t2"},
+	}
+	a.targetTombstones.add("t3", time.Now())
+	op := a.tombstoneTargets(context.Background(), &loaders.TargetOperation{
+		Add: map[string]*types.TargetConfig{
+			"t2": {Name: "t2"},
+			"t3": {Name: "t3"},
+		},
+		Del: []string{"t1", "t2"},
+	})
+	// t1 is deactivated, t2 is recreated, t3 is reactivated
+	if !cmp.Equal(op.Del, []string{"t2"}) || len(op.Add) != 2 {
+		t.Errorf("unexpected operation: add=%v, del=%v", op.Add, op.Del)
+	}
+	if !a.targetTombstones.has("t1") || a.targetTombstones.has("t3") {
+		t.Errorf("unexpected tombstones: %+v", a.targetTombstones.list())
+	}
+	// t1 configuration is kept
+	if _, ok := a.Config.Targets["t1"]; !ok {
+		t.Errorf("inactive target configuration should be kept")
+	}
+}
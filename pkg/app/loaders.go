@@ -10,11 +10,15 @@ package app
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/openconfig/gnmic/pkg/loaders"
 )
 
+// interval between two checks of the inactive targets delete grace period
+const tombstonesCheckInterval = 5 * time.Second
+
 func (a *App) startLoader(ctx context.Context) {
 	if len(a.Config.Loader) == 0 {
 		return
@@ -31,10 +35,17 @@ func (a *App) startLoader(ctx context.Context) {
 	}
 	ldTypeS := a.Config.Loader["type"].(string)
 START:
+	if !a.components.waitEnabled(ctx, componentLoader) {
+		return
+	}
 	a.Logger.Printf("initializing loader type %q", ldTypeS)
+	// canceled when the loader is disabled
+	lctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	a.components.setCancel(componentLoader, cancel)
 
 	ld := loaders.Loaders[ldTypeS]()
-	err := ld.Init(ctx, a.Config.Loader, a.Logger,
+	err := ld.Init(lctx, a.Config.Loader, a.Logger,
 		loaders.WithRegistry(a.reg),
 		loaders.WithActions(a.Config.Actions),
 		loaders.WithTargetsDefaults(a.Config.SetTargetConfigDefaults),
@@ -43,48 +54,66 @@ START:
 		a.Logger.Printf("failed to init loader type %q: %v", ldTypeS, err)
 		return
 	}
+	stagingCfg := new(loaderStagingConfig)
+	err = loaders.DecodeConfig(a.Config.Loader, stagingCfg)
+	if err != nil {
+		a.Logger.Printf("failed to decode loader staging config: %v", err)
+		return
+	}
+	a.loaderStaging.setConfig(stagingCfg)
+	tombstonesCfg := new(loaderTombstonesConfig)
+	err = loaders.DecodeConfig(a.Config.Loader, tombstonesCfg)
+	if err != nil {
+		a.Logger.Printf("failed to decode loader delete grace period: %v", err)
+		return
+	}
+	var graceCh <-chan time.Time
+	if tombstonesCfg.DeleteGracePeriod > 0 {
+		graceTicker := time.NewTicker(tombstonesCheckInterval)
+		defer graceTicker.Stop()
+		graceCh = graceTicker.C
+	}
 	a.Logger.Printf("starting loader type %q", ldTypeS)
-	for targetOp := range ld.Start(ctx) {
-		for _, del := range targetOp.Del {
-			// not clustered, delete local target
-			if !a.inCluster() {
-				err = a.DeleteTarget(ctx, del)
-				if err != nil {
-					a.Logger.Printf("failed deleting target %q: %v", del, err)
-				}
+	opCh := ld.Start(lctx)
+OPS:
+	for {
+		select {
+		case <-lctx.Done():
+			break OPS
+		case targetOp, ok := <-opCh:
+			if !ok {
+				break OPS
+			}
+			op, reason := a.loaderStaging.stage(targetOp)
+			if op == nil {
+				a.Logger.Printf("loader changes not applied: %s", reason)
 				continue
 			}
-			// clustered, delete target in all instances of the cluster
-			err = a.deleteTarget(ctx, del)
-			if err != nil {
-				a.Logger.Printf("failed to delete target %q: %v", del, err)
+			if tombstonesCfg.DeleteGracePeriod > 0 {
+				op = a.tombstoneTargets(ctx, op)
 			}
-		}
-		for _, add := range targetOp.Add {
-			err = a.Config.SetTargetConfigDefaults(add)
-			if err != nil {
-				a.Logger.Printf("failed parsing new target configuration %#v: %v", add, err)
-				continue
+			a.loadedTargets.apply(op)
+			a.applyTargetOperation(ctx, op)
+		case op := <-a.loaderStaging.approved:
+			a.Logger.Printf("applying approved loader changes")
+			if tombstonesCfg.DeleteGracePeriod > 0 {
+				op = a.tombstoneTargets(ctx, op)
 			}
-			// not clustered, add target and subscribe
-			if !a.inCluster() {
-				a.Config.Targets[add.Name] = add
-				a.AddTargetConfig(add)
-				a.wg.Add(1)
-				go a.TargetSubscribeStream(ctx, add)
+			a.loadedTargets.apply(op)
+			a.applyTargetOperation(ctx, op)
+		case now := <-graceCh:
+			expired := a.targetTombstones.expired(now, tombstonesCfg.DeleteGracePeriod)
+			if len(expired) == 0 {
 				continue
 			}
-			// clustered, dispatch
-			a.configLock.Lock()
-			a.Config.Targets[add.Name] = add
-			err = a.dispatchTarget(ctx, add)
-			if err != nil {
-				a.Logger.Printf("failed dispatching target %q: %v", add.Name, err)
-			}
-			a.configLock.Unlock()
+			a.Logger.Printf("deleting targets inactive for more than %s: %v", tombstonesCfg.DeleteGracePeriod, expired)
+			op := &loaders.TargetOperation{Del: expired}
+			a.loadedTargets.apply(op)
+			a.applyTargetOperation(ctx, op)
 		}
 	}
 	a.Logger.Printf("target loader stopped")
+	cancel()
 	select {
 	case <-ctx.Done():
 		return
@@ -93,6 +122,48 @@ START:
 	}
 }
 
+func (a *App) applyTargetOperation(ctx context.Context, targetOp *loaders.TargetOperation) {
+	var err error
+	for _, del := range targetOp.Del {
+		// not clustered, delete local target
+		if !a.inCluster() {
+			err = a.DeleteTarget(ctx, del)
+			if err != nil {
+				a.Logger.Printf("failed deleting target %q: %v", del, err)
+			}
+			continue
+		}
+		// clustered, delete target in all instances of the cluster
+		err = a.deleteTarget(ctx, del)
+		if err != nil {
+			a.Logger.Printf("failed to delete target %q: %v", del, err)
+		}
+	}
+	for _, add := range targetOp.Add {
+		err = a.Config.SetTargetConfigDefaults(add)
+		if err != nil {
+			a.Logger.Printf("failed parsing new target configuration %#v: %v", add, err)
+			continue
+		}
+		// not clustered, add target and subscribe
+		if !a.inCluster() {
+			a.Config.Targets[add.Name] = add
+			a.AddTargetConfig(add)
+			a.wg.Add(1)
+			go a.TargetSubscribeStream(ctx, add)
+			continue
+		}
+		// clustered, dispatch
+		a.configLock.Lock()
+		a.Config.Targets[add.Name] = add
+		err = a.dispatchTarget(ctx, add)
+		if err != nil {
+			a.Logger.Printf("failed dispatching target %q: %v", add.Name, err)
+		}
+		a.configLock.Unlock()
+	}
+}
+
 func (a *App) startLoaderProxy(ctx context.Context) {
 	if len(a.Config.Loader) == 0 {
 		return
@@ -149,3 +220,34 @@ START:
 		goto START
 	}
 }
+
+// loadedTargets holds the names of the targets discovered by the loader.
+type loadedTargets struct {
+	m       *sync.Mutex
+	targets map[string]struct{}
+}
+
+func newLoadedTargets() *loadedTargets {
+	return &loadedTargets{
+		m:       new(sync.Mutex),
+		targets: make(map[string]struct{}),
+	}
+}
+
+func (lt *loadedTargets) apply(op *loaders.TargetOperation) {
+	lt.m.Lock()
+	defer lt.m.Unlock()
+	for _, name := range op.Del {
+		delete(lt.targets, name)
+	}
+	for name := range op.Add {
+		lt.targets[name] = struct{}{}
+	}
+}
+
+func (lt *loadedTargets) has(name string) bool {
+	lt.m.Lock()
+	defer lt.m.Unlock()
+	_, ok := lt.targets[name]
+	return ok
+}
@@ -19,7 +19,7 @@ func (a *App) logError(err error) {
 		return
 	}
 	a.Logger.Print(err)
-	if !a.Config.Log {
+	if !a.Config.Log && a.Config.ErrorFormat != errorFormatJSON {
 		fmt.Fprintln(os.Stderr, err)
 	}
 	if a.errCh == nil {
@@ -40,10 +40,55 @@ func (a *App) checkErrors() error {
 	if len(errs) == 0 {
 		return nil
 	}
-	if a.Config.Log {
+	run := a.targetsRun
+	a.targetsRun = nil
+	var skipped []string
+	if run != nil {
+		skipped = run.skipped()
+	}
+	targets := make([]string, 0, len(a.Config.Targets))
+	for n := range a.Config.Targets {
+		if !contains(skipped, n) {
+			targets = append(targets, n)
+		}
+	}
+	r := buildErrorReport(targets, errs)
+	if run != nil {
+		run.annotateReport(r, skipped)
+	}
+	if a.Config.ErrorFormat == errorFormatJSON {
+		err := r.write(os.Stderr)
+		if err != nil {
+			a.Logger.Printf("failed to write errors report: %v", err)
+		}
+	} else if a.Config.Log {
 		for _, err := range errs {
 			fmt.Fprintln(os.Stderr, err)
 		}
 	}
-	return errors.New("one or more requests failed")
+	return &ExitError{
+		Code: r.ExitCode,
+		err:  errors.New("one or more requests failed"),
+	}
+}
+
+// targetErr records an error returned by a request sent to the named target
+// without printing it.
+func (a *App) targetErr(name string, err error) {
+	if a.targetsRun != nil {
+		a.targetsRun.failed(name, err)
+	}
+	a.errCh <- &targetError{target: name, err: err}
+}
+
+// logTargetError logs an error returned by a request sent to the named target,
+// the error is reported under that target.
+func (a *App) logTargetError(name string, err error) {
+	if err == nil {
+		return
+	}
+	if a.targetsRun != nil {
+		a.targetsRun.failed(name, err)
+	}
+	a.logError(&targetError{target: name, err: err})
 }
@@ -11,9 +11,16 @@ package app
 import (
 	"context"
 	"fmt"
+	"runtime"
+	"strconv"
 	"time"
 
+	"github.com/openconfig/grpctunnel/tunnel"
 	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/openconfig/gnmic/pkg/gtemplate"
+	"github.com/openconfig/gnmic/pkg/inputs"
+	"github.com/openconfig/gnmic/pkg/outputs"
 )
 
 const (
@@ -28,6 +35,225 @@ var subscribeResponseReceivedCounter = prometheus.NewCounterVec(prometheus.Count
 	Help:      "Total number of received subscribe response messages",
 }, []string{"source", "subscription"})
 
+// targets
+var targetReceivedNotifications = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "target",
+	Name:      "received_notifications_total",
+	Help:      "Total number of received notifications per target",
+}, []string{"source"})
+var targetConvertedEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "target",
+	Name:      "converted_events_total",
+	Help:      "Total number of events converted from the target notifications by the subscriptions processors",
+}, []string{"source"})
+var targetDroppedEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "target",
+	Name:      "dropped_events_total",
+	Help:      "Total number of dropped target notifications per reason",
+}, []string{"source", "reason"})
+
+var targetPriorityNotifications = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "target",
+	Name:      "priority_notifications_total",
+	Help:      "Total number of responses classified as high priority by the qos rules per target",
+}, []string{"source"})
+
+var targetSubscriptionErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "target",
+	Name:      "subscription_errors_total",
+	Help:      "Total number of subscription errors per target",
+}, []string{"source"})
+
+var targetSkewedTimestamps = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "target",
+	Name:      "skewed_timestamps_total",
+	Help:      "Total number of notifications and events with a timestamp out of an output bounds, per target, output and action",
+}, []string{"source", "output", "action"})
+
+var targetClockSkew = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "gnmic",
+	Subsystem: "target",
+	Name:      "clock_skew_seconds",
+	Help:      "Difference between the receive time and the timestamp of the last notification per target",
+}, []string{"source"})
+var targetClockSkewExceeded = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "target",
+	Name:      "clock_skew_exceeded_total",
+	Help:      "Total number of notifications with a clock skew above the target threshold",
+}, []string{"source"})
+var targetCorrectedTimestamps = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "target",
+	Name:      "corrected_timestamps_total",
+	Help:      "Total number of notifications with a timestamp rewritten to their receive time",
+}, []string{"source"})
+
+// slos
+var sloSamples = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "slo",
+	Name:      "samples_total",
+	Help:      "Total number of samples evaluated per SLO, target and result",
+}, []string{"slo", "source", "result"})
+var sloSLIRatio = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "gnmic",
+	Subsystem: "slo",
+	Name:      "sli_ratio",
+	Help:      "Ratio of good samples over the SLO window per SLO and target",
+}, []string{"slo", "source"})
+var sloErrorBudgetRemaining = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "gnmic",
+	Subsystem: "slo",
+	Name:      "error_budget_remaining_ratio",
+	Help:      "Ratio of the error budget not consumed over the SLO window per SLO and target",
+}, []string{"slo", "source"})
+var sloBurnRate = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "gnmic",
+	Subsystem: "slo",
+	Name:      "burn_rate",
+	Help:      "Rate at which the error budget is consumed over a burn rate window per SLO and target",
+}, []string{"slo", "source", "window"})
+
+var targetBytesDesc = prometheus.NewDesc(
+	"gnmic_target_bytes_total",
+	"Total number of bytes sent and received per target, before (raw) and after (wire) compression",
+	[]string{"source", "direction", "stage"}, nil,
+)
+
+// targetBytesCollector collects the targets gRPC connections bytes counters.
+type targetBytesCollector struct {
+	a *App
+}
+
+func (c *targetBytesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- targetBytesDesc
+}
+
+func (c *targetBytesCollector) Collect(ch chan<- prometheus.Metric) {
+	c.a.operLock.RLock()
+	defer c.a.operLock.RUnlock()
+	for name, t := range c.a.Targets {
+		st := t.CompressionStats()
+		for _, v := range []struct {
+			direction, stage string
+			value            uint64
+		}{
+			{"sent", "raw", st.RawSent},
+			{"sent", "wire", st.WireSent},
+			{"received", "raw", st.RawReceived},
+			{"received", "wire", st.WireReceived},
+		} {
+			ch <- prometheus.MustNewConstMetric(targetBytesDesc, prometheus.CounterValue, float64(v.value), name, v.direction, v.stage)
+		}
+	}
+}
+
+var exportQueuedDesc = prometheus.NewDesc(
+	"gnmic_export_queued_responses",
+	"Number of subscribe responses queued per export worker",
+	[]string{"worker"}, nil,
+)
+
+// exportQueueCollector collects the export workers queues length.
+type exportQueueCollector struct {
+	a *App
+}
+
+func (c *exportQueueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- exportQueuedDesc
+}
+
+func (c *exportQueueCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.a.exporters.Load()
+	if s == nil {
+		return
+	}
+	for i, n := range s.queued() {
+		ch <- prometheus.MustNewConstMetric(exportQueuedDesc, prometheus.GaugeValue, float64(n), strconv.Itoa(i))
+	}
+}
+
+var runtimeMaxProcsDesc = prometheus.NewDesc(
+	"gnmic_runtime_gomaxprocs",
+	"Number of CPUs gnmic runs on (GOMAXPROCS), the source label tells how it was set",
+	[]string{"source"}, nil,
+)
+var runtimeNumCPUDesc = prometheus.NewDesc(
+	"gnmic_runtime_num_cpu",
+	"Number of CPUs of the host",
+	nil, nil,
+)
+var outputCPUQuotaDesc = prometheus.NewDesc(
+	"gnmic_output_cpu_quota",
+	"Maximum number of concurrent writes per output",
+	[]string{"output"}, nil,
+)
+
+// runtimeCollector collects the runtime tuning settings.
+type runtimeCollector struct {
+	a *App
+}
+
+func (c *runtimeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- runtimeMaxProcsDesc
+	ch <- runtimeNumCPUDesc
+	ch <- outputCPUQuotaDesc
+}
+
+func (c *runtimeCollector) Collect(ch chan<- prometheus.Metric) {
+	source := c.a.maxProcsSource
+	if source == "" {
+		source = maxProcsSourceDefault
+	}
+	ch <- prometheus.MustNewConstMetric(runtimeMaxProcsDesc, prometheus.GaugeValue, float64(runtime.GOMAXPROCS(0)), source)
+	ch <- prometheus.MustNewConstMetric(runtimeNumCPUDesc, prometheus.GaugeValue, float64(runtime.NumCPU()))
+	c.a.operLock.RLock()
+	defer c.a.operLock.RUnlock()
+	for name, q := range c.a.outputsQuotas {
+		ch <- prometheus.MustNewConstMetric(outputCPUQuotaDesc, prometheus.GaugeValue, float64(cap(q)), name)
+	}
+}
+
+// outputs
+var outputWrittenMessages = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "output",
+	Name:      "written_messages_total",
+	Help:      "Total number of messages written to the output",
+}, []string{"output"})
+var outputWrittenEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "output",
+	Name:      "written_events_total",
+	Help:      "Total number of events written to the output",
+}, []string{"output"})
+var outputDroppedEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "output",
+	Name:      "dropped_events_total",
+	Help:      "Total number of messages and events not written to the output per reason",
+}, []string{"output", "reason"})
+var outputThrottledWrites = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "output",
+	Name:      "throttled_writes_total",
+	Help:      "Total number of writes delayed by the output cpu-quota",
+}, []string{"output"})
+var outputWriteLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "gnmic",
+	Subsystem: "output",
+	Name:      "write_latency_seconds",
+	Help:      "Duration of the writes to the output",
+	Buckets:   []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5},
+}, []string{"output"})
+
 // cluster
 var clusterNumberOfLockedTargets = prometheus.NewGauge(prometheus.GaugeOpts{
 	Namespace: "gnmic",
@@ -42,6 +268,111 @@ var clusterIsLeader = prometheus.NewGauge(prometheus.GaugeOpts{
 	Help:      "Has value 1 if this gnmic instance is the cluster leader, 0 otherwise",
 })
 
+// tunnel server
+var tunnelServerTargetRegistrations = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "tunnel_server",
+	Name:      "target_registrations_total",
+	Help:      "Total number of tunnel target registrations per group and result",
+}, []string{"group", "type", "result"})
+var tunnelServerSessionUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "gnmic",
+	Subsystem: "tunnel_server",
+	Name:      "target_session_up",
+	Help:      "Has value 1 if the tunnel target is currently registered",
+}, []string{"target", "type", "group"})
+var tunnelServerSessionStartTime = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "gnmic",
+	Subsystem: "tunnel_server",
+	Name:      "target_session_start_time_seconds",
+	Help:      "Unix time at which the tunnel target registered",
+}, []string{"target", "type", "group"})
+
+func (a *App) registerStatsMetrics() {
+	for _, c := range []prometheus.Collector{
+		targetReceivedNotifications,
+		targetConvertedEvents,
+		targetDroppedEvents,
+		targetPriorityNotifications,
+		targetSubscriptionErrors,
+		targetSkewedTimestamps,
+		targetClockSkew,
+		targetClockSkewExceeded,
+		targetCorrectedTimestamps,
+		sloSamples,
+		sloSLIRatio,
+		sloErrorBudgetRemaining,
+		sloBurnRate,
+		outputWrittenMessages,
+		outputWrittenEvents,
+		outputDroppedEvents,
+		outputWriteLatency,
+		outputThrottledWrites,
+		&targetBytesCollector{a: a},
+		&exportQueueCollector{a: a},
+		&runtimeCollector{a: a},
+	} {
+		if err := a.reg.Register(c); err != nil {
+			a.Logger.Printf("failed to register metric: %v", err)
+		}
+	}
+	for _, c := range inputs.Collectors() {
+		if err := a.reg.Register(c); err != nil {
+			a.Logger.Printf("failed to register metric: %v", err)
+		}
+	}
+	for _, c := range outputs.Collectors() {
+		if err := a.reg.Register(c); err != nil {
+			a.Logger.Printf("failed to register metric: %v", err)
+		}
+	}
+	for _, c := range gtemplate.Collectors() {
+		if err := a.reg.Register(c); err != nil {
+			a.Logger.Printf("failed to register metric: %v", err)
+		}
+	}
+}
+
+func (a *App) registerTunnelServerMetrics() {
+	for _, c := range []prometheus.Collector{
+		tunnelServerTargetRegistrations,
+		tunnelServerSessionUp,
+		tunnelServerSessionStartTime,
+	} {
+		if err := a.reg.Register(c); err != nil {
+			a.Logger.Printf("failed to register metric: %v", err)
+		}
+	}
+}
+
+func (a *App) tunnelServerMetricsEnabled() bool {
+	return a.reg != nil && a.Config.TunnelServer != nil && a.Config.TunnelServer.EnableMetrics
+}
+
+func (a *App) tunnelSessionUp(tt tunnel.Target, group string) {
+	if !a.tunnelServerMetricsEnabled() {
+		return
+	}
+	tunnelServerTargetRegistrations.WithLabelValues(group, tt.Type, "accepted").Inc()
+	tunnelServerSessionUp.WithLabelValues(tt.ID, tt.Type, group).Set(1)
+	tunnelServerSessionStartTime.WithLabelValues(tt.ID, tt.Type, group).Set(float64(time.Now().Unix()))
+}
+
+func (a *App) tunnelSessionDown(tt tunnel.Target, group string) {
+	if !a.tunnelServerMetricsEnabled() {
+		return
+	}
+	tunnelServerSessionUp.DeleteLabelValues(tt.ID, tt.Type, group)
+	tunnelServerSessionStartTime.DeleteLabelValues(tt.ID, tt.Type, group)
+}
+
+func (a *App) tunnelTargetRejected(tt tunnel.Target, group string) {
+	if !a.tunnelServerMetricsEnabled() {
+		return
+	}
+	tunnelServerTargetRegistrations.WithLabelValues(group, tt.Type, "rejected").Inc()
+}
+
 func (a *App) startClusterMetrics() {
 	if a.Config.APIServer == nil || !a.Config.APIServer.EnableMetrics || a.Config.Clustering == nil {
 		return
@@ -75,20 +406,13 @@ func (a *App) startClusterMetrics() {
 				clusterIsLeader.Set(0)
 			}
 
-			lockedNodesPrefix := fmt.Sprintf("gnmic/%s/targets", a.Config.ClusterName)
 			ctx, cancel = context.WithTimeout(a.ctx, clusterMetricsUpdatePeriod/2)
-			lockedNodes, err := a.locker.List(ctx, lockedNodesPrefix)
+			load, err := a.getLocksCount(ctx)
 			cancel()
 			if err != nil {
 				a.Logger.Printf("failed to get locked nodes key: %v", err)
 			}
-			numLockedNodes := 0
-			for _, v := range lockedNodes {
-				if v == a.Config.Clustering.InstanceName {
-					numLockedNodes++
-				}
-			}
-			clusterNumberOfLockedTargets.Set(float64(numLockedNodes))
+			clusterNumberOfLockedTargets.Set(float64(load[a.Config.Clustering.InstanceName]))
 		}
 	}
 }
@@ -0,0 +1,147 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/openconfig/gnmic/pkg/config"
+)
+
+type apiScopeKey struct{}
+
+// apiScope is the set of namespaces an API request has access to.
+type apiScope struct {
+	namespace string
+	// access to all the namespaces and to the admin endpoints
+	all bool
+}
+
+func (s apiScope) allows(ns string) bool {
+	return s.all || s.namespace == ns
+}
+
+// requestScope returns the scope of the API request r,
+// a request without scope has access to everything.
+func requestScope(r *http.Request) apiScope {
+	if s, ok := r.Context().Value(apiScopeKey{}).(apiScope); ok {
+		return s
+	}
+	return apiScope{all: true}
+}
+
+// authMiddleware sets the request scope from its bearer token
+// if the api-server tokens or the namespaces are configured.
+func (a *App) authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.Config.APIAuthEnabled() || strings.HasSuffix(r.URL.Path, "/healthz") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		tk, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(APIErrors{Errors: []string{"missing bearer token"}})
+			return
+		}
+		ns, all, ok := a.Config.TokenNamespace(strings.TrimSpace(tk))
+		if !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(APIErrors{Errors: []string{"invalid token"}})
+			return
+		}
+		ctx := context.WithValue(r.Context(), apiScopeKey{}, apiScope{namespace: ns, all: all})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// adminOnly restricts the handler h to the requests with access to all the namespaces.
+func adminOnly(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !requestScope(r).all {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(APIErrors{Errors: []string{"endpoint restricted to admin tokens"}})
+			return
+		}
+		h(w, r)
+	}
+}
+
+// targetVisible returns true if the target name is configured
+// and belongs to a namespace the request has access to.
+func (a *App) targetVisible(r *http.Request, name string) bool {
+	a.configLock.RLock()
+	defer a.configLock.RUnlock()
+	tc, ok := a.Config.Targets[name]
+	return ok && requestScope(r).allows(tc.Namespace)
+}
+
+// pipelineVisible returns true if the pipeline id resolves to an output or a subscription
+// of a namespace the request has access to.
+func (a *App) pipelineVisible(r *http.Request, id string) bool {
+	s := requestScope(r)
+	if s.all {
+		return true
+	}
+	kinds := []string{"output", "subscription"}
+	for _, kind := range kinds {
+		if n, ok := strings.CutPrefix(id, kind+":"); ok {
+			id = n
+			kinds = []string{kind}
+			break
+		}
+	}
+	for _, kind := range kinds {
+		switch kind {
+		case "output":
+			if outCfg, ok := a.Config.Outputs[id]; ok && !s.allows(config.OutputNamespace(outCfg)) {
+				return false
+			}
+		case "subscription":
+			if sc, ok := a.Config.Subscriptions[id]; ok && !s.allows(sc.Namespace) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// targetNamespace returns the namespace of the target name.
+func (a *App) targetNamespace(name string) string {
+	if len(a.Config.Namespaces) == 0 {
+		return ""
+	}
+	a.configLock.RLock()
+	defer a.configLock.RUnlock()
+	if tc, ok := a.Config.Targets[name]; ok {
+		return tc.Namespace
+	}
+	return ""
+}
+
+// namespaceOutputs returns the names of the outputs belonging to the namespace ns,
+// or nil if no namespaces are configured.
+func (a *App) namespaceOutputs(ns string) map[string]struct{} {
+	if len(a.Config.Namespaces) == 0 {
+		return nil
+	}
+	a.configLock.RLock()
+	defer a.configLock.RUnlock()
+	outs := make(map[string]struct{})
+	for name, outCfg := range a.Config.Outputs {
+		if config.OutputNamespace(outCfg) == ns {
+			outs[name] = struct{}{}
+		}
+	}
+	return outs
+}
@@ -0,0 +1,134 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/config"
+)
+
+func newNamespacedApp() *App {
+	a := New()
+	a.Config.APIServer = &config.APIServer{Tokens: []string{"admin"}}
+	a.Config.Namespaces = map[string]*config.Namespace{
+		"team1": {Tokens: []string{"tk1"}},
+		"team2": {Tokens: []string{"tk2"}},
+	}
+	a.Config.Targets = map[string]*types.TargetConfig{
+		"t1": {Name: "t1", Namespace: "team1"},
+		"t2": {Name: "t2", Namespace: "team2"},
+	}
+	a.Config.Subscriptions = map[string]*types.SubscriptionConfig{
+		"sub1": {Name: "sub1", Namespace: "team1"},
+		"sub2": {Name: "sub2", Namespace: "team2"},
+	}
+	a.Config.Outputs = map[string]map[string]interface{}{
+		"out1": {"type": "file", "namespace": "team1"},
+		"out2": {"type": "file", "namespace": "team2"},
+	}
+	a.routes()
+	return a
+}
+
+func apiRequest(a *App, method, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	a.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAPINamespacesAuth(t *testing.T) {
+	a := newNamespacedApp()
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		status int
+	}{
+		{name: "no_token", method: http.MethodGet, path: "/api/v1/config/targets", status: http.StatusUnauthorized},
+		{name: "unknown_token", method: http.MethodGet, path: "/api/v1/config/targets", token: "x", status: http.StatusUnauthorized},
+		{name: "healthz_without_token", method: http.MethodGet, path: "/api/v1/healthz", status: http.StatusOK},
+		{name: "admin_config", method: http.MethodGet, path: "/api/v1/config", token: "admin", status: http.StatusOK},
+		{name: "namespace_config", method: http.MethodGet, path: "/api/v1/config", token: "tk1", status: http.StatusForbidden},
+		{name: "namespace_cluster", method: http.MethodGet, path: "/api/v1/cluster", token: "tk1", status: http.StatusForbidden},
+		{name: "own_target", method: http.MethodGet, path: "/api/v1/config/targets/t1", token: "tk1", status: http.StatusOK},
+		{name: "other_target", method: http.MethodGet, path: "/api/v1/config/targets/t2", token: "tk1", status: http.StatusNotFound},
+		{name: "admin_other_target", method: http.MethodGet, path: "/api/v1/config/targets/t2", token: "admin", status: http.StatusOK},
+		{name: "delete_other_target", method: http.MethodDelete, path: "/api/v1/config/targets/t2", token: "tk1", status: http.StatusNotFound},
+		{name: "start_other_target", method: http.MethodPost, path: "/api/v1/targets/t2", token: "tk1", status: http.StatusNotFound},
+		{name: "namespace_silences", method: http.MethodGet, path: "/api/v1/silences", token: "tk1", status: http.StatusForbidden},
+		{name: "namespace_silence", method: http.MethodGet, path: "/api/v1/silences/s1", token: "tk1", status: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := apiRequest(a, tt.method, tt.path, tt.token, "")
+			if rec.Code != tt.status {
+				t.Errorf("got status %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+		})
+	}
+	if _, ok := a.Config.Targets["t2"]; !ok {
+		t.Errorf("target t2 deleted by a token of another namespace")
+	}
+}
+
+func TestAPINamespacesFilter(t *testing.T) {
+	a := newNamespacedApp()
+	for path, want := range map[string]string{
+		"/api/v1/config/targets":       "t1",
+		"/api/v1/config/subscriptions": "sub1",
+		"/api/v1/config/outputs":       "out1",
+	} {
+		rec := apiRequest(a, http.MethodGet, path, "tk1", "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: unexpected status %d", path, rec.Code)
+		}
+		m := make(map[string]json.RawMessage)
+		if err := json.Unmarshal(rec.Body.Bytes(), &m); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if _, ok := m[want]; !ok || len(m) != 1 {
+			t.Errorf("%s: got %v, want only %q", path, rec.Body.String(), want)
+		}
+	}
+}
+
+func TestAPINamespacesTargetsPost(t *testing.T) {
+	a := newNamespacedApp()
+	rec := apiRequest(a, http.MethodPost, "/api/v1/config/targets", "tk1", `{"name":"t3","subscriptions":["sub1"]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body.String())
+	}
+	if tc, ok := a.Config.Targets["t3"]; !ok || tc.Namespace != "team1" {
+		t.Errorf("expected target t3 to be added to namespace team1, got %+v", tc)
+	}
+	for _, body := range []string{
+		`{"name":"t4","namespace":"team2"}`,
+		`{"name":"t4","subscriptions":["sub2"]}`,
+		`{"name":"t4","outputs":["out2"]}`,
+	} {
+		rec = apiRequest(a, http.MethodPost, "/api/v1/config/targets", "tk1", body)
+		if rec.Code == http.StatusOK {
+			t.Errorf("%s: expected the target to be rejected", body)
+		}
+	}
+	if _, ok := a.Config.Targets["t4"]; ok {
+		t.Errorf("target t4 added to another namespace")
+	}
+}
@@ -11,9 +11,12 @@ package app
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/config"
 	"github.com/openconfig/gnmic/pkg/outputs"
 )
 
@@ -23,12 +26,22 @@ func (a *App) InitOutput(ctx context.Context, name string, tcs map[string]*types
 	if _, ok := a.Outputs[name]; ok {
 		return
 	}
+	if a.components.isDisabled(componentOutputs, name) {
+		a.Logger.Printf("output %q is disabled", name)
+		return
+	}
 	wg := new(sync.WaitGroup)
 	if cfg, ok := a.Config.Outputs[name]; ok {
 		if outType, ok := cfg["type"]; ok {
 			a.Logger.Printf("starting output type %s", outType)
 			if initializer, ok := outputs.Outputs[outType.(string)]; ok {
 				out := initializer()
+				a.operLock.RLock()
+				initialized := make(map[string]outputs.Output, len(a.Outputs))
+				for n, o := range a.Outputs {
+					initialized[n] = o
+				}
+				a.operLock.RUnlock()
 				wg.Add(1)
 				go func() {
 					defer wg.Done()
@@ -44,13 +57,38 @@ func (a *App) InitOutput(ctx context.Context, name string, tcs map[string]*types
 						outputs.WithName(a.Config.InstanceName),
 						outputs.WithClusterName(a.Config.ClusterName),
 						outputs.WithTargetsConfig(tcs),
+						outputs.WithOutputs(initialized),
 					)
 					if err != nil {
 						a.Logger.Printf("failed to init output type %q: %v", outType, err)
 					}
 				}()
+				ts, err := config.GetOutputTimestamps(cfg)
+				if err != nil {
+					a.Logger.Printf("output %q: %v", name, err)
+				}
+				sel, err := config.GetOutputTargetSelector(cfg)
+				if err != nil {
+					a.Logger.Printf("output %q: %v", name, err)
+				}
+				quota, err := config.GetOutputCPUQuota(cfg)
+				if err != nil {
+					a.Logger.Printf("output %q: %v", name, err)
+				}
 				a.operLock.Lock()
 				a.Outputs[name] = out
+				if ts != nil {
+					a.outputsTimestamps[name] = ts
+				}
+				if sel != nil {
+					a.outputsSelectors[name] = sel
+				}
+				if quota > 0 {
+					a.outputsQuotas[name] = newOutputQuota(quota)
+				}
+				for _, n := range outputs.Members(cfg) {
+					a.memberOutputs[n] = struct{}{}
+				}
 				a.operLock.Unlock()
 			}
 		}
@@ -59,9 +97,125 @@ func (a *App) InitOutput(ctx context.Context, name string, tcs map[string]*types
 }
 
 func (a *App) InitOutputs(ctx context.Context) {
-	for name := range a.Config.Outputs {
+	// outputs forwarding messages to other outputs
+	// are initialized after them.
+	pending := make(map[string][]string)
+	for name, cfg := range a.Config.Outputs {
+		if members := outputs.Members(cfg); len(members) > 0 {
+			pending[name] = members
+			continue
+		}
 		a.InitOutput(ctx, name, a.Config.Targets)
 	}
+	for len(pending) > 0 {
+		initialized := 0
+	PENDING:
+		for name, members := range pending {
+			for _, m := range members {
+				if _, ok := pending[m]; ok {
+					continue PENDING
+				}
+			}
+			a.InitOutput(ctx, name, a.Config.Targets)
+			delete(pending, name)
+			initialized++
+		}
+		if initialized == 0 {
+			// circular references, init the remaining outputs to report the errors.
+			for name := range pending {
+				a.InitOutput(ctx, name, a.Config.Targets)
+			}
+			return
+		}
+	}
+}
+
+// StartOutput creates and initializes the output name from its configuration.
+// Unlike InitOutput, the output is only registered once its initialization succeeds,
+// so that no messages are written to it before.
+func (a *App) StartOutput(ctx context.Context, name string) error {
+	a.configLock.RLock()
+	cfg, ok := a.Config.Outputs[name]
+	if !ok {
+		a.configLock.RUnlock()
+		return fmt.Errorf("output %q config not found", name)
+	}
+	outType, _ := cfg["type"].(string)
+	initializer, ok := outputs.Outputs[outType]
+	if !ok {
+		a.configLock.RUnlock()
+		return fmt.Errorf("output %q: unknown output type %q", name, outType)
+	}
+	opts := []outputs.Option{
+		outputs.WithLogger(a.Logger),
+		outputs.WithEventProcessors(
+			a.Config.Processors,
+			a.Logger,
+			a.Config.Targets,
+			a.Config.Actions,
+		),
+		outputs.WithRegistry(a.reg),
+		outputs.WithName(a.Config.InstanceName),
+		outputs.WithClusterName(a.Config.ClusterName),
+		outputs.WithTargetsConfig(a.Config.Targets),
+	}
+	a.configLock.RUnlock()
+
+	a.operLock.RLock()
+	if _, ok := a.Outputs[name]; ok {
+		a.operLock.RUnlock()
+		return fmt.Errorf("output %q already exists", name)
+	}
+	initialized := make(map[string]outputs.Output, len(a.Outputs))
+	for n, o := range a.Outputs {
+		initialized[n] = o
+	}
+	a.operLock.RUnlock()
+	a.configLock.RLock()
+	for _, m := range outputs.Members(cfg) {
+		if _, ok := initialized[m]; !ok {
+			a.configLock.RUnlock()
+			return fmt.Errorf("output %q: unknown member output %q", name, m)
+		}
+		if config.OutputNamespace(a.Config.Outputs[m]) != config.OutputNamespace(cfg) {
+			a.configLock.RUnlock()
+			return fmt.Errorf("output %q: member output %q does not belong to namespace %q", name, m, config.OutputNamespace(cfg))
+		}
+	}
+	a.configLock.RUnlock()
+	sel, err := config.GetOutputTargetSelector(cfg)
+	if err != nil {
+		return fmt.Errorf("output %q: %v", name, err)
+	}
+	quota, err := config.GetOutputCPUQuota(cfg)
+	if err != nil {
+		return fmt.Errorf("output %q: %v", name, err)
+	}
+	opts = append(opts, outputs.WithOutputs(initialized))
+
+	a.Logger.Printf("starting output type %s", outType)
+	out := initializer()
+	err = out.Init(ctx, name, cfg, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to init output type %q: %v", outType, err)
+	}
+	a.operLock.Lock()
+	defer a.operLock.Unlock()
+	if _, ok := a.Outputs[name]; ok {
+		out.Close()
+		return fmt.Errorf("output %q already exists", name)
+	}
+	a.Outputs[name] = out
+	if sel != nil {
+		a.outputsSelectors[name] = sel
+	}
+	if quota > 0 {
+		a.outputsQuotas[name] = newOutputQuota(quota)
+	}
+	for _, n := range outputs.Members(cfg) {
+		a.memberOutputs[n] = struct{}{}
+	}
+	return nil
 }
 
 // AddOutputConfig adds an output called name, with config cfg if it does not already exist
@@ -75,26 +229,133 @@ func (a *App) AddOutputConfig(name string, cfg map[string]interface{}) error {
 	if _, ok := a.Outputs[name]; ok {
 		return fmt.Errorf("output %q already exists", name)
 	}
+	if _, err := config.GetOutputTimestamps(cfg); err != nil {
+		return err
+	}
+	if _, err := config.GetOutputTargetSelector(cfg); err != nil {
+		return err
+	}
+	if _, err := config.GetOutputCPUQuota(cfg); err != nil {
+		return err
+	}
 	a.configLock.Lock()
 	defer a.configLock.Unlock()
 	a.Config.Outputs[name] = cfg
 	return nil
 }
 
+// DeleteOutput stops writing to the output name, waits for the in-flight writes to complete
+// and closes it.
 func (a *App) DeleteOutput(name string) error {
 	if a.Outputs == nil {
 		return nil
 	}
 	a.operLock.Lock()
-	defer a.operLock.Unlock()
-	if _, ok := a.Outputs[name]; !ok {
+	o, ok := a.Outputs[name]
+	if !ok {
+		a.operLock.Unlock()
 		return fmt.Errorf("output %q does not exist", name)
 	}
-	o := a.Outputs[name]
+	if _, ok := a.memberOutputs[name]; ok {
+		a.operLock.Unlock()
+		return fmt.Errorf("output %q is a member of another output", name)
+	}
+	delete(a.Outputs, name)
+	delete(a.outputsTimestamps, name)
+	delete(a.outputsSelectors, name)
+	delete(a.outputsQuotas, name)
+	a.operLock.Unlock()
+
+	a.outputWrites.wait(name)
 	err := o.Close()
 	if err != nil {
 		a.Logger.Printf("failed to close output %q: %v", name, err)
 	}
-	delete(a.Outputs, name)
+	// the deleted output members may still be members of other outputs
+	a.configLock.RLock()
+	a.operLock.Lock()
+	a.memberOutputs = make(map[string]struct{})
+	for n := range a.Outputs {
+		for _, m := range outputs.Members(a.Config.Outputs[n]) {
+			a.memberOutputs[m] = struct{}{}
+		}
+	}
+	a.operLock.Unlock()
+	a.configLock.RUnlock()
+	a.policiesLock.Lock()
+	for k := range a.policies {
+		if strings.HasPrefix(k, name+"/") {
+			delete(a.policies, k)
+		}
+	}
+	a.policiesLock.Unlock()
 	return nil
 }
+
+// DeleteOutputConfig removes the output name configuration.
+func (a *App) DeleteOutputConfig(name string) {
+	a.configLock.Lock()
+	defer a.configLock.Unlock()
+	delete(a.Config.Outputs, name)
+}
+
+// outputWrites tracks the in-flight writes per output,
+// so that an output is only closed once they are completed.
+type outputWrites struct {
+	m   sync.Mutex
+	wgs map[string]*sync.WaitGroup
+}
+
+func newOutputWrites() *outputWrites {
+	return &outputWrites{wgs: make(map[string]*sync.WaitGroup)}
+}
+
+// add registers a write to the output name, the returned WaitGroup
+// Done method must be called once the write completes.
+// It assumes the operLock is acquired, so that the output cannot be deleted concurrently.
+func (w *outputWrites) add(name string) *sync.WaitGroup {
+	w.m.Lock()
+	defer w.m.Unlock()
+	wg, ok := w.wgs[name]
+	if !ok {
+		wg = new(sync.WaitGroup)
+		w.wgs[name] = wg
+	}
+	wg.Add(1)
+	return wg
+}
+
+// wait waits for the in-flight writes to the deleted output name.
+func (w *outputWrites) wait(name string) {
+	w.m.Lock()
+	wg, ok := w.wgs[name]
+	delete(w.wgs, name)
+	w.m.Unlock()
+	if ok {
+		wg.Wait()
+	}
+}
+
+// waitAll waits for the in-flight writes to all the outputs, or for ctx to be done.
+// It returns the names of the outputs with writes still in flight.
+func (w *outputWrites) waitAll(ctx context.Context) []string {
+	w.m.Lock()
+	wgs := w.wgs
+	w.wgs = make(map[string]*sync.WaitGroup)
+	w.m.Unlock()
+	pending := make([]string, 0)
+	for name, wg := range wgs {
+		done := make(chan struct{})
+		go func(wg *sync.WaitGroup) {
+			wg.Wait()
+			close(done)
+		}(wg)
+		select {
+		case <-done:
+		case <-ctx.Done():
+			pending = append(pending, name)
+		}
+	}
+	sort.Strings(pending)
+	return pending
+}
@@ -0,0 +1,308 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+// blockingOutput blocks its writes until released and records its Close call.
+type blockingOutput struct {
+	testOutput
+	writing chan struct{}
+	release chan struct{}
+	closed  atomic.Bool
+}
+
+func (o *blockingOutput) Write(ctx context.Context, m proto.Message, meta outputs.Meta) {
+	o.writing <- struct{}{}
+	<-o.release
+	if o.closed.Load() {
+		panic("write to a closed output")
+	}
+	o.testOutput.Write(ctx, m, meta)
+}
+
+func (o *blockingOutput) Close() error {
+	o.closed.Store(true)
+	return nil
+}
+
+func TestDeleteOutputDrainsWrites(t *testing.T) {
+	a := New()
+	o := &blockingOutput{writing: make(chan struct{}), release: make(chan struct{})}
+	a.Outputs["o1"] = o
+	a.Config.Outputs["o1"] = map[string]interface{}{"type": "test"}
+
+	go a.Export(context.Background(), &gnmi.SubscribeResponse{
+		Response: &gnmi.SubscribeResponse_Update{Update: &gnmi.Notification{}},
+	}, outputs.Meta{"source": "r1"}, "o1")
+	<-o.writing
+
+	deleted := make(chan error)
+	go func() { deleted <- a.DeleteOutput("o1") }()
+	select {
+	case err := <-deleted:
+		t.Fatalf("output deleted before the in-flight write completed: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if o.closed.Load() {
+		t.Fatal("output closed before the in-flight write completed")
+	}
+	close(o.release)
+	if err := <-deleted; err != nil {
+		t.Fatal(err)
+	}
+	if !o.closed.Load() || o.msgs != 1 {
+		t.Errorf("expected the write to complete before the output is closed, closed=%v msgs=%d", o.closed.Load(), o.msgs)
+	}
+	if _, ok := a.Outputs["o1"]; ok {
+		t.Errorf("output o1 not removed")
+	}
+}
+
+func TestAPIOutputsPostDelete(t *testing.T) {
+	outputs.Register("test-api-output", func() outputs.Output { return new(testOutput) })
+	defer delete(outputs.Outputs, "test-api-output")
+
+	a := newNamespacedApp()
+	rec := apiRequest(a, http.MethodPost, "/api/v1/config/outputs", "tk1", `{"out3": {"type": "test-api-output"}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body.String())
+	}
+	if _, ok := a.Outputs["out3"]; !ok {
+		t.Fatalf("output out3 not started")
+	}
+	if ns := a.Config.Outputs["out3"]["namespace"]; ns != "team1" {
+		t.Errorf("expected output out3 in namespace team1, got %v", ns)
+	}
+	for _, body := range []string{
+		`{"out3": {"type": "test-api-output"}}`,
+		`{"out4": {"type": "unknown"}}`,
+		`{"out4": {"type": "test-api-output", "namespace": "team2"}}`,
+		`{"out4": {"type": "test-api-output", "policy": "unknown"}}`,
+	} {
+		rec = apiRequest(a, http.MethodPost, "/api/v1/config/outputs", "tk1", body)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", body, rec.Code)
+		}
+	}
+	if _, ok := a.Outputs["out4"]; ok {
+		t.Errorf("invalid output out4 started")
+	}
+
+	rec = apiRequest(a, http.MethodDelete, "/api/v1/config/outputs/out2", "tk1", "")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 deleting an output of another namespace, got %d", rec.Code)
+	}
+	rec = apiRequest(a, http.MethodDelete, "/api/v1/config/outputs/out3", "tk1", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body.String())
+	}
+	if _, ok := a.Outputs["out3"]; ok {
+		t.Errorf("output out3 not stopped")
+	}
+	if _, ok := a.Config.Outputs["out3"]; ok {
+		t.Errorf("output out3 config not removed")
+	}
+}
+
+// failingOutput fails its initialization.
+type failingOutput struct {
+	testOutput
+}
+
+func (o *failingOutput) Init(context.Context, string, map[string]interface{}, ...outputs.Option) error {
+	return errors.New("init failed")
+}
+
+// testMembers returns the names listed in the outputs field of a test composite output.
+func testMembers(cfg map[string]interface{}) []string {
+	ms, _ := cfg["outputs"].([]interface{})
+	names := make([]string, 0, len(ms))
+	for _, m := range ms {
+		names = append(names, m.(string))
+	}
+	return names
+}
+
+func TestAPIOutputsPostRollback(t *testing.T) {
+	outputs.Register("test-api-output", func() outputs.Output { return new(testOutput) })
+	outputs.Register("test-failing-output", func() outputs.Output { return new(failingOutput) })
+	outputs.Register("test-composite-output", func() outputs.Output { return new(testOutput) })
+	outputs.RegisterMembers("test-composite-output", testMembers)
+	outputs.RegisterMembers("test-failing-output", testMembers)
+	defer delete(outputs.Outputs, "test-api-output")
+	defer delete(outputs.Outputs, "test-failing-output")
+	defer delete(outputs.Outputs, "test-composite-output")
+
+	a := newNamespacedApp()
+	// out4 and out5 forwarding to it are started before out6 fails
+	body := `{
+		"out4": {"type": "test-api-output"},
+		"out5": {"type": "test-composite-output", "outputs": ["out4"]},
+		"out6": {"type": "test-failing-output", "outputs": ["out4"]}
+	}`
+	rec := apiRequest(a, http.MethodPost, "/api/v1/config/outputs", "tk1", body)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d: %s", rec.Code, rec.Body.String())
+	}
+	for _, name := range []string{"out4", "out5", "out6"} {
+		if _, ok := a.Outputs[name]; ok {
+			t.Errorf("output %s of the failed batch still running", name)
+		}
+		if _, ok := a.Config.Outputs[name]; ok {
+			t.Errorf("output %s of the failed batch still configured", name)
+		}
+	}
+	if _, ok := a.memberOutputs["out4"]; ok {
+		t.Errorf("output out4 still recorded as a member")
+	}
+}
+
+func TestAPIOutputsPostMembersNamespace(t *testing.T) {
+	outputs.Register("test-api-output", func() outputs.Output { return new(testOutput) })
+	outputs.Register("test-composite-output", func() outputs.Output { return new(testOutput) })
+	outputs.RegisterMembers("test-composite-output", testMembers)
+	defer delete(outputs.Outputs, "test-api-output")
+	defer delete(outputs.Outputs, "test-composite-output")
+
+	a := newNamespacedApp()
+	a.Config.Outputs["out2"]["type"] = "test-api-output"
+	a.Config.Outputs["out1"]["type"] = "test-api-output"
+	a.InitOutputs(context.Background())
+	tests := []struct {
+		name   string
+		token  string
+		body   string
+		status int
+	}{
+		{
+			name:   "member_of_another_namespace",
+			token:  "tk1",
+			body:   `{"out5": {"type": "test-composite-output", "outputs": ["out2"]}}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "admin_member_of_another_namespace",
+			token:  "admin",
+			body:   `{"out5": {"type": "test-composite-output", "namespace": "team1", "outputs": ["out2"]}}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "admin_batch_member_of_another_namespace",
+			token:  "admin",
+			body:   `{"out5": {"type": "test-composite-output", "outputs": ["out6"]}, "out6": {"type": "test-api-output", "namespace": "team2"}}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "member_of_the_same_namespace",
+			token:  "tk1",
+			body:   `{"out5": {"type": "test-composite-output", "outputs": ["out1"]}}`,
+			status: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := apiRequest(a, http.MethodPost, "/api/v1/config/outputs", tt.token, tt.body)
+			if rec.Code != tt.status {
+				t.Fatalf("got status %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+			if _, ok := a.memberOutputs["out2"]; ok {
+				t.Errorf("output out2 of another namespace became a member")
+			}
+		})
+	}
+	if _, ok := a.memberOutputs["out1"]; !ok {
+		t.Errorf("output out1 not recorded as a member")
+	}
+}
+
+func TestOutputTargetSelector(t *testing.T) {
+	outputs.Register("test-selector-output", func() outputs.Output { return new(testOutput) })
+	defer delete(outputs.Outputs, "test-selector-output")
+
+	a := New()
+	a.Config.Targets = map[string]*types.TargetConfig{
+		"prod1": {Name: "prod1", Tags: []string{"env=prod"}},
+		"lab1":  {Name: "lab1", Vars: map[string]string{"env": "lab"}},
+	}
+	a.Config.Outputs = map[string]map[string]interface{}{
+		"kafka-prod": {"type": "test-selector-output", "target-selector": []interface{}{"env=prod"}},
+		"all":        {"type": "test-selector-output"},
+	}
+	a.InitOutputs(context.Background())
+	for _, source := range []string{"prod1", "lab1", "unknown"} {
+		a.Export(context.Background(), &gnmi.SubscribeResponse{
+			Response: &gnmi.SubscribeResponse_Update{Update: &gnmi.Notification{}},
+		}, outputs.Meta{"source": source})
+	}
+	if n := a.Outputs["kafka-prod"].(*testOutput).msgs; n != 1 {
+		t.Errorf("expected the prod1 messages only in kafka-prod, got %d", n)
+	}
+	if n := a.Outputs["all"].(*testOutput).msgs; n != 3 {
+		t.Errorf("expected all the messages in output all, got %d", n)
+	}
+	if n := a.stats.snapshot().Outputs["kafka-prod"].DroppedEvents[dropReasonTargetSelector]; n != 2 {
+		t.Errorf("expected 2 messages filtered by the kafka-prod target selector, got %d", n)
+	}
+
+	err := a.AddOutputConfig("invalid", map[string]interface{}{"type": "test-selector-output", "target-selector": []interface{}{"=prod"}})
+	if err == nil {
+		t.Errorf("expected an invalid target selector error")
+	}
+}
+
+func TestOutputCPUQuota(t *testing.T) {
+	a := New()
+	o := &blockingOutput{writing: make(chan struct{}), release: make(chan struct{})}
+	a.Outputs["o1"] = o
+	a.outputsQuotas["o1"] = newOutputQuota(1)
+
+	done := make(chan struct{})
+	for i := 0; i < 2; i++ {
+		go func() {
+			a.Export(context.Background(), &gnmi.SubscribeResponse{
+				Response: &gnmi.SubscribeResponse_Update{Update: &gnmi.Notification{}},
+			}, outputs.Meta{"source": "r1"}, "o1")
+			done <- struct{}{}
+		}()
+	}
+	<-o.writing
+	select {
+	case <-o.writing:
+		t.Fatal("second write started while the output cpu-quota is used")
+	case <-time.After(50 * time.Millisecond):
+	}
+	o.release <- struct{}{}
+	<-o.writing
+	o.release <- struct{}{}
+	<-done
+	<-done
+	st := a.stats.snapshot().Outputs["o1"]
+	if st.WrittenMessages != 2 || st.ThrottledWrites != 1 {
+		t.Errorf("expected 2 written messages and 1 throttled write, got %d and %d", st.WrittenMessages, st.ThrottledWrites)
+	}
+
+	err := a.AddOutputConfig("invalid", map[string]interface{}{"type": "file", "cpu-quota": -1})
+	if err == nil {
+		t.Errorf("expected an invalid cpu-quota error")
+	}
+}
@@ -195,6 +195,9 @@ func (a *App) PathPreRunE(cmd *cobra.Command, args []string) error {
 }
 
 func (a *App) PathRunE(cmd *cobra.Command, args []string) error {
+	if a.Config.LocalFlags.PathLookup != "" || a.Config.LocalFlags.PathJSONSchema {
+		return a.pathLookup()
+	}
 	return a.PathCmdRun(
 		a.Config.GlobalFlags.Dir,
 		a.Config.GlobalFlags.File,
@@ -219,6 +222,8 @@ func (a *App) InitPathFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolVarP(&a.Config.LocalFlags.PathSearch, "search", "", false, "search through path list")
 	cmd.Flags().BoolVarP(&a.Config.LocalFlags.PathState, "state-only", "", false, "generate paths only for YANG leafs representing state data")
 	cmd.Flags().BoolVarP(&a.Config.LocalFlags.PathConfig, "config-only", "", false, "generate paths only for YANG leafs representing config data")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.PathLookup, "lookup", "", "", "print the description, type, units and list keys of the schema node matching the given path")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.PathJSONSchema, "json-schema", "", false, "print the JSON schema of the subtree matching the --lookup path, or of the whole tree")
 	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
 		a.Config.FileConfig.BindPFlag(fmt.Sprintf("%s-%s", cmd.Name(), flag.Name), flag)
 	})
@@ -0,0 +1,337 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/goyang/pkg/yang"
+
+	"github.com/openconfig/gnmic/pkg/api/path"
+)
+
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// pathLookupResult describes the schema node a concrete path points to.
+type pathLookupResult struct {
+	Path        string           `json:"path,omitempty"`
+	SchemaPath  string           `json:"schema-path,omitempty"`
+	Module      string           `json:"module,omitempty"`
+	Kind        string           `json:"kind,omitempty"`
+	Type        string           `json:"type,omitempty"`
+	EnumValues  []string         `json:"enum-values,omitempty"`
+	Units       string           `json:"units,omitempty"`
+	Default     string           `json:"default,omitempty"`
+	IsState     bool             `json:"is-state,omitempty"`
+	Description string           `json:"description,omitempty"`
+	Keys        []*pathLookupKey `json:"keys,omitempty"`
+}
+
+// pathLookupKey is a key leaf of one of the lists found along the looked up path.
+type pathLookupKey struct {
+	List        string `json:"list,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Value       string `json:"value,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// pathLookup prints the schema information of the node matching the --lookup path,
+// or its JSON schema if --json-schema is set.
+func (a *App) pathLookup() error {
+	err := a.generateYangSchema(a.Config.GlobalFlags.File, a.Config.GlobalFlags.Exclude)
+	if err != nil {
+		return err
+	}
+	p, err := path.ParsePath(a.Config.LocalFlags.PathLookup)
+	if err != nil {
+		return err
+	}
+	entry, err := findSchemaEntry(a.SchemaTree, p.GetElem())
+	if err != nil {
+		return err
+	}
+	if a.Config.LocalFlags.PathJSONSchema {
+		schema := jsonSchema(entry)
+		schema["$schema"] = jsonSchemaDraft
+		if len(p.GetElem()) > 0 {
+			schema["title"] = a.Config.LocalFlags.PathLookup
+		}
+		b, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stdout, string(b))
+		return nil
+	}
+	if len(p.GetElem()) == 0 {
+		return fmt.Errorf("missing path to lookup")
+	}
+	r, err := a.lookupResult(entry, p)
+	if err != nil {
+		return err
+	}
+	if a.Config.Format == formatJSON {
+		b, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stdout, string(b))
+		return nil
+	}
+	sb := new(strings.Builder)
+	fmt.Fprintf(sb, "path: %s\n", r.Path)
+	fmt.Fprintf(sb, "schema path: %s\n", r.SchemaPath)
+	fmt.Fprintf(sb, "module: %s\n", r.Module)
+	fmt.Fprintf(sb, "kind: %s\n", r.Kind)
+	fmt.Fprintf(sb, "state: %t\n", r.IsState)
+	if entry.Type != nil {
+		sb.WriteString(a.generateTypeInfo(entry))
+	}
+	if r.Units != "" && (entry.Type == nil || entry.Type.Units == "") {
+		fmt.Fprintf(sb, "- units: %s\n", r.Units)
+	}
+	if r.Description != "" {
+		fmt.Fprintf(sb, "description:\n%s\n", indent("\t", r.Description))
+	}
+	if len(r.Keys) > 0 {
+		sb.WriteString("keys:\n")
+		for _, k := range r.Keys {
+			fmt.Fprintf(sb, "- %s: %s (type=%s)", k.List, k.Name, k.Type)
+			if k.Value != "" {
+				fmt.Fprintf(sb, " = %q", k.Value)
+			}
+			sb.WriteString("\n")
+		}
+	}
+	fmt.Fprint(os.Stdout, sb.String())
+	return nil
+}
+
+func (a *App) lookupResult(entry *yang.Entry, p *gnmi.Path) (*pathLookupResult, error) {
+	gp := a.generatePath(entry, "xpath")
+	r := &pathLookupResult{
+		Path:        fmt.Sprintf("/%s", path.GnmiPathToXPath(p, false)),
+		SchemaPath:  gp.Path,
+		Kind:        schemaEntryKind(entry),
+		Type:        gp.Type,
+		EnumValues:  gp.EnumValues,
+		Default:     gp.Default,
+		IsState:     gp.IsState,
+		Description: entry.Description,
+		Units:       entry.Units,
+	}
+	if r.Units == "" && entry.Type != nil {
+		r.Units = entry.Type.Units
+	}
+	if m := yang.RootNode(entry.Node); m != nil {
+		r.Module = m.Name
+	}
+	// walk the path again to collect the lists keys
+	lists := make([]*yang.Entry, 0)
+	for e := entry; e != nil && e.Parent != nil; e = e.Parent {
+		if e.IsList() {
+			lists = append([]*yang.Entry{e}, lists...)
+		}
+	}
+	elemKeys := make(map[string]map[string]string)
+	for _, pe := range p.GetElem() {
+		_, name := getPrefixElem(pe.GetName())
+		if len(pe.GetKey()) > 0 {
+			elemKeys[name] = pe.GetKey()
+		}
+	}
+	for _, l := range lists {
+		listPath := a.generatePath(l, "xpath").Path
+		keyNames := strings.Fields(l.Key)
+		given := elemKeys[l.Name]
+		for k := range given {
+			if !slices.Contains(keyNames, k) {
+				return nil, fmt.Errorf("invalid key %q for list %q, valid keys: %v", k, listPath, keyNames)
+			}
+		}
+		for _, k := range keyNames {
+			lk := &pathLookupKey{
+				List:  listPath,
+				Name:  k,
+				Value: given[k],
+			}
+			if ke, ok := l.Dir[k]; ok {
+				lk.Description = ke.Description
+				if ke.Type != nil {
+					lk.Type = ke.Type.Name
+				}
+			}
+			r.Keys = append(r.Keys, lk)
+		}
+	}
+	return r, nil
+}
+
+// findSchemaEntry returns the schema entry matching the path elements,
+// choice and case nodes as well as modules are transparent.
+func findSchemaEntry(root *yang.Entry, elems []*gnmi.PathElem) (*yang.Entry, error) {
+	current := root
+	for _, pe := range elems {
+		_, name := getPrefixElem(pe.GetName())
+		var next *yang.Entry
+		if current == root {
+			for _, mod := range sortedEntries(root.Dir) {
+				if next = childSchemaEntry(mod, name); next != nil {
+					break
+				}
+			}
+		} else {
+			next = childSchemaEntry(current, name)
+		}
+		if next == nil {
+			if current == root {
+				return nil, fmt.Errorf("unknown top level element %q", name)
+			}
+			return nil, fmt.Errorf("unknown element %q under %q", name, current.Name)
+		}
+		current = next
+	}
+	return current, nil
+}
+
+func childSchemaEntry(e *yang.Entry, name string) *yang.Entry {
+	if c, ok := e.Dir[name]; ok && !c.IsChoice() && !c.IsCase() {
+		return c
+	}
+	for _, c := range sortedEntries(e.Dir) {
+		if c.IsChoice() || c.IsCase() {
+			if r := childSchemaEntry(c, name); r != nil {
+				return r
+			}
+		}
+	}
+	return nil
+}
+
+func sortedEntries(m map[string]*yang.Entry) []*yang.Entry {
+	names := make([]string, 0, len(m))
+	for n := range m {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	entries := make([]*yang.Entry, 0, len(m))
+	for _, n := range names {
+		entries = append(entries, m[n])
+	}
+	return entries
+}
+
+func schemaEntryKind(e *yang.Entry) string {
+	switch {
+	case e.IsLeafList():
+		return "leaf-list"
+	case e.IsLeaf():
+		return "leaf"
+	case e.IsList():
+		return "list"
+	default:
+		return "container"
+	}
+}
+
+// jsonSchema returns the JSON schema (draft-07) of the subtree rooted at e,
+// following the RFC 7951 JSON encoding of YANG data.
+func jsonSchema(e *yang.Entry) map[string]any {
+	var s map[string]any
+	switch {
+	case e.IsLeafList():
+		s = map[string]any{
+			"type":  "array",
+			"items": jsonSchemaType(e.Type),
+		}
+	case e.IsLeaf():
+		s = jsonSchemaType(e.Type)
+	case e.IsList():
+		item := jsonSchemaObject(e)
+		if keys := strings.Fields(e.Key); len(keys) > 0 {
+			item["required"] = keys
+		}
+		s = map[string]any{
+			"type":  "array",
+			"items": item,
+		}
+	default:
+		s = jsonSchemaObject(e)
+	}
+	if e.Description != "" {
+		s["description"] = e.Description
+	}
+	if e.Config == yang.TSFalse {
+		s["readOnly"] = true
+	}
+	return s
+}
+
+func jsonSchemaObject(e *yang.Entry) map[string]any {
+	props := make(map[string]any)
+	var collect func(e *yang.Entry)
+	collect = func(e *yang.Entry) {
+		for _, c := range sortedEntries(e.Dir) {
+			// choice, case and module nodes do not appear in the data tree
+			if c.IsChoice() || c.IsCase() || (c.Parent == nil && e.Annotation["root"] == true) {
+				collect(c)
+				continue
+			}
+			props[c.Name] = jsonSchema(c)
+		}
+	}
+	collect(e)
+	return map[string]any{
+		"type":       "object",
+		"properties": props,
+	}
+}
+
+func jsonSchemaType(t *yang.YangType) map[string]any {
+	if t == nil {
+		return map[string]any{}
+	}
+	switch t.Kind {
+	case yang.Yint8, yang.Yint16, yang.Yint32,
+		yang.Yuint8, yang.Yuint16, yang.Yuint32:
+		return map[string]any{"type": "integer"}
+	case yang.Yint64, yang.Yuint64:
+		// 64 bits integers are encoded as strings in RFC 7951
+		return map[string]any{"type": []string{"integer", "string"}}
+	case yang.Ydecimal64:
+		return map[string]any{"type": []string{"number", "string"}}
+	case yang.Ybool:
+		return map[string]any{"type": "boolean"}
+	case yang.Yempty:
+		return map[string]any{
+			"type":  "array",
+			"items": map[string]any{"type": "null"},
+		}
+	case yang.Yenum:
+		return map[string]any{
+			"type": "string",
+			"enum": t.Enum.Names(),
+		}
+	case yang.Yunion:
+		oneOf := make([]map[string]any, 0, len(t.Type))
+		for _, ut := range t.Type {
+			oneOf = append(oneOf, jsonSchemaType(ut))
+		}
+		return map[string]any{"oneOf": oneOf}
+	default:
+		return map[string]any{"type": "string"}
+	}
+}
@@ -0,0 +1,119 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/openconfig/goyang/pkg/yang"
+
+	"github.com/openconfig/gnmic/pkg/api/path"
+)
+
+const testLookupModule = `
+module test-intf {
+  namespace "urn:test:intf";
+  prefix ti;
+  container interfaces {
+    list interface {
+      key "name";
+      leaf name { type string; }
+      choice mode {
+        case bridged { leaf vlan { type uint16; } }
+      }
+      container state {
+        config false;
+        leaf in-octets { type uint64; }
+      }
+    }
+  }
+}
+`
+
+func testSchemaTree(t *testing.T) *yang.Entry {
+	ms := yang.NewModules()
+	if err := ms.Parse(testLookupModule, "test-intf.yang"); err != nil {
+		t.Fatal(err)
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	root := buildRootEntry()
+	root.Dir["test-intf"] = yang.ToEntry(ms.Modules["test-intf"])
+	return root
+}
+
+func TestFindSchemaEntry(t *testing.T) {
+	root := testSchemaTree(t)
+	tests := []struct {
+		path    string
+		expName string
+		expErr  bool
+	}{
+		{path: "/interfaces/interface[name=eth0]/state/in-octets", expName: "in-octets"},
+		{path: "/ti:interfaces/interface", expName: "interface"},
+		// leaf under a choice/case
+		{path: "/interfaces/interface/vlan", expName: "vlan"},
+		{path: "/interfaces/interface/unknown", expErr: true},
+		{path: "/unknown", expErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			p, err := path.ParsePath(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			e, err := findSchemaEntry(root, p.GetElem())
+			if tt.expErr {
+				if err == nil {
+					t.Fatalf("expected an error, got entry %q", e.Name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if e.Name != tt.expName {
+				t.Fatalf("expected entry %q, got %q", tt.expName, e.Name)
+			}
+		})
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	root := testSchemaTree(t)
+	p, _ := path.ParsePath("/interfaces/interface")
+	e, err := findSchemaEntry(root, p.GetElem())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := jsonSchema(e)
+	if s["type"] != "array" {
+		t.Fatalf("expected a list to be an array, got %v", s["type"])
+	}
+	item := s["items"].(map[string]any)
+	if !reflect.DeepEqual(item["required"], []string{"name"}) {
+		t.Fatalf("expected the list keys to be required, got %v", item["required"])
+	}
+	props := item["properties"].(map[string]any)
+	for _, n := range []string{"name", "vlan", "state"} {
+		if _, ok := props[n]; !ok {
+			t.Fatalf("missing property %q in %v", n, props)
+		}
+	}
+	state := props["state"].(map[string]any)
+	if state["readOnly"] != true {
+		t.Fatal("expected the state container to be read only")
+	}
+	inOctets := state["properties"].(map[string]any)["in-octets"].(map[string]any)
+	if !reflect.DeepEqual(inOctets["type"], []string{"integer", "string"}) {
+		t.Fatalf("unexpected uint64 schema type %v", inOctets["type"])
+	}
+}
@@ -0,0 +1,317 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openconfig/gnmic/pkg/api/path"
+	"github.com/openconfig/gnmic/pkg/config"
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+// dataPolicy holds the sample windows of a policy applied to an output.
+type dataPolicy struct {
+	cfg *config.Policy
+	m   sync.Mutex
+	// sample window per source, subscription and path
+	windows map[string]*sampleWindow
+}
+
+// sampleWindow accumulates the values received for a path during a sample interval.
+type sampleWindow struct {
+	start int64
+	count int
+	// values as received
+	last, min, max any
+	// numeric values
+	sum, minF, maxF float64
+	numeric         bool
+}
+
+// dataPolicy returns the policy applied to the data of namespace ns written to the output name.
+// The policy state is created on first use and re-created if the policy configuration changes.
+func (a *App) dataPolicy(name, ns string) *dataPolicy {
+	a.configLock.RLock()
+	cfg := a.Config.DataPolicy(name, ns)
+	a.configLock.RUnlock()
+	if cfg == nil {
+		return nil
+	}
+	key := name + "/" + ns
+	a.policiesLock.Lock()
+	defer a.policiesLock.Unlock()
+	if p, ok := a.policies[key]; ok && p.cfg == cfg {
+		return p
+	}
+	p := &dataPolicy{
+		cfg:     cfg,
+		windows: make(map[string]*sampleWindow),
+	}
+	a.policies[key] = p
+	return p
+}
+
+// applyResponse returns the response to write to the output after applying the policy,
+// or nil if all the updates are dropped.
+// rsp is shared between the outputs so it is cloned before being modified.
+func (p *dataPolicy) applyResponse(rsp *gnmi.SubscribeResponse, m outputs.Meta, now time.Time) *gnmi.SubscribeResponse {
+	n := rsp.GetUpdate()
+	if n == nil {
+		return rsp
+	}
+	ts := n.GetTimestamp()
+	if ts == 0 {
+		ts = now.UnixNano()
+	}
+	if p.expired(ts, now) {
+		return nil
+	}
+	if p.cfg.SampleInterval == 0 && (p.cfg.MaxUpdates == 0 || len(n.GetUpdate()) <= p.cfg.MaxUpdates) {
+		return rsp
+	}
+	r := proto.Clone(rsp).(*gnmi.SubscribeResponse)
+	n = r.GetUpdate()
+	if p.cfg.SampleInterval > 0 {
+		keyPrefix := m["source"] + "/" + m["subscription-name"] + "/" + n.GetPrefix().GetTarget()
+		upds := n.Update[:0]
+		p.m.Lock()
+		for _, upd := range n.GetUpdate() {
+			key := keyPrefix + path.GnmiPathToXPath(&gnmi.Path{Elem: path.PathElems(n.GetPrefix(), upd.GetPath())}, false)
+			v, ok := p.sample(key, ts, upd.GetVal(), typedValueConv)
+			if !ok {
+				continue
+			}
+			upd.Val = v.(*gnmi.TypedValue)
+			upds = append(upds, upd)
+		}
+		p.m.Unlock()
+		n.Update = upds
+	}
+	if p.cfg.MaxUpdates > 0 && len(n.Update) > p.cfg.MaxUpdates {
+		n.Update = n.Update[:p.cfg.MaxUpdates]
+	}
+	if len(n.GetUpdate()) == 0 && len(n.GetDelete()) == 0 {
+		return nil
+	}
+	return r
+}
+
+// applyEvents returns the events to write to the output after applying the policy.
+// evs are shared between the outputs so the modified events are copied.
+func (p *dataPolicy) applyEvents(evs []*formatters.EventMsg, now time.Time) []*formatters.EventMsg {
+	res := make([]*formatters.EventMsg, 0, len(evs))
+	for _, ev := range evs {
+		ts := ev.Timestamp
+		if ts == 0 {
+			ts = now.UnixNano()
+		}
+		if p.expired(ts, now) {
+			continue
+		}
+		if p.cfg.SampleInterval == 0 && (p.cfg.MaxUpdates == 0 || len(ev.Values) <= p.cfg.MaxUpdates) {
+			res = append(res, ev)
+			continue
+		}
+		names := make([]string, 0, len(ev.Values))
+		for k := range ev.Values {
+			names = append(names, k)
+		}
+		slices.Sort(names)
+		c := *ev
+		c.Values = make(map[string]interface{}, len(ev.Values))
+		keyPrefix := eventKey(ev)
+		p.m.Lock()
+		for _, k := range names {
+			if p.cfg.MaxUpdates > 0 && len(c.Values) == p.cfg.MaxUpdates {
+				break
+			}
+			v := ev.Values[k]
+			if p.cfg.SampleInterval > 0 {
+				var ok bool
+				v, ok = p.sample(keyPrefix+k, ts, v, eventValueConv)
+				if !ok {
+					continue
+				}
+			}
+			c.Values[k] = v
+		}
+		p.m.Unlock()
+		if len(c.Values) == 0 && len(c.Deletes) == 0 {
+			continue
+		}
+		res = append(res, &c)
+	}
+	return res
+}
+
+// expired returns true if the timestamp ts is older than the policy max-age.
+func (p *dataPolicy) expired(ts int64, now time.Time) bool {
+	return p.cfg.MaxAge > 0 && now.UnixNano()-ts > p.cfg.MaxAge.Nanoseconds()
+}
+
+// sample adds the value v received at ts to the sample window of key.
+// It returns the value to write and true once per sample interval:
+// the first value of the interval with the "first" aggregation,
+// otherwise the aggregate of the previous interval when a value past its end is received.
+func (p *dataPolicy) sample(key string, ts int64, v any, conv valueConv) (any, bool) {
+	first := p.cfg.Aggregation == config.PolicyAggregationFirst
+	w, ok := p.windows[key]
+	if !ok {
+		w = new(sampleWindow)
+		p.windows[key] = w
+		w.reset(ts)
+		if !first {
+			w.add(v, conv)
+		}
+		return v, first
+	}
+	if ts-w.start < p.cfg.SampleInterval.Nanoseconds() {
+		if !first {
+			w.add(v, conv)
+		}
+		return nil, false
+	}
+	out := v
+	if !first {
+		out = w.result(p.cfg.Aggregation, conv)
+	}
+	w.reset(ts)
+	if !first {
+		w.add(v, conv)
+	}
+	return out, true
+}
+
+func (w *sampleWindow) reset(ts int64) {
+	*w = sampleWindow{start: ts, numeric: true}
+}
+
+func (w *sampleWindow) add(v any, conv valueConv) {
+	w.last = v
+	w.count++
+	if !w.numeric {
+		return
+	}
+	f, ok := conv.toFloat(v)
+	if !ok {
+		w.numeric = false
+		return
+	}
+	w.sum += f
+	if w.count == 1 || f < w.minF {
+		w.minF, w.min = f, v
+	}
+	if w.count == 1 || f > w.maxF {
+		w.maxF, w.max = f, v
+	}
+}
+
+// result returns the aggregate of the window values,
+// non numeric values are aggregated as their last value.
+func (w *sampleWindow) result(agg string, conv valueConv) any {
+	if !w.numeric {
+		return w.last
+	}
+	switch agg {
+	case config.PolicyAggregationAvg:
+		return conv.fromFloat(w.sum / float64(w.count))
+	case config.PolicyAggregationMin:
+		return w.min
+	case config.PolicyAggregationMax:
+		return w.max
+	default:
+		return w.last
+	}
+}
+
+// valueConv converts gNMI TypedValues or event values from and to float64
+// for the numeric aggregations.
+type valueConv struct {
+	toFloat   func(v any) (float64, bool)
+	fromFloat func(f float64) any
+}
+
+var typedValueConv = valueConv{
+	toFloat: func(v any) (float64, bool) {
+		switch val := v.(*gnmi.TypedValue).GetValue().(type) {
+		case *gnmi.TypedValue_IntVal:
+			return float64(val.IntVal), true
+		case *gnmi.TypedValue_UintVal:
+			return float64(val.UintVal), true
+		case *gnmi.TypedValue_FloatVal:
+			return float64(val.FloatVal), true
+		case *gnmi.TypedValue_DoubleVal:
+			return val.DoubleVal, true
+		//lint:ignore SA1019 still need DecimalVal for backward compatibility
+		case *gnmi.TypedValue_DecimalVal:
+			return float64(val.DecimalVal.GetDigits()) / math.Pow10(int(val.DecimalVal.GetPrecision())), true
+		}
+		return 0, false
+	},
+	fromFloat: func(f float64) any {
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_DoubleVal{DoubleVal: f}}
+	},
+}
+
+var eventValueConv = valueConv{
+	toFloat: func(v any) (float64, bool) {
+		switch val := v.(type) {
+		case float64:
+			return val, true
+		case float32:
+			return float64(val), true
+		case int:
+			return float64(val), true
+		case int8:
+			return float64(val), true
+		case int16:
+			return float64(val), true
+		case int32:
+			return float64(val), true
+		case int64:
+			return float64(val), true
+		case uint:
+			return float64(val), true
+		case uint8:
+			return float64(val), true
+		case uint16:
+			return float64(val), true
+		case uint32:
+			return float64(val), true
+		case uint64:
+			return float64(val), true
+		case string:
+			f, err := strconv.ParseFloat(val, 64)
+			return f, err == nil
+		}
+		return 0, false
+	},
+	fromFloat: func(f float64) any { return f },
+}
+
+// eventKey returns the sample windows key prefix of the event values,
+// built from the event name and tags.
+func eventKey(ev *formatters.EventMsg) string {
+	tags := make([]string, 0, len(ev.Tags))
+	for k, v := range ev.Tags {
+		tags = append(tags, k+"="+v)
+	}
+	slices.Sort(tags)
+	return ev.Name + "/" + strings.Join(tags, ",") + "/"
+}
@@ -0,0 +1,151 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/openconfig/gnmic/pkg/config"
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+func intResponse(ts int64, vals map[string]int64) *gnmi.SubscribeResponse {
+	n := &gnmi.Notification{Timestamp: ts}
+	for name, v := range vals {
+		n.Update = append(n.Update, &gnmi.Update{
+			Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: name}}},
+			Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_IntVal{IntVal: v}},
+		})
+	}
+	return &gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_Update{Update: n}}
+}
+
+func TestDataPolicySampling(t *testing.T) {
+	now := time.Unix(1000, 0)
+	sec := time.Second.Nanoseconds()
+	m := outputs.Meta{"source": "r1", "subscription-name": "sub1"}
+	tests := []struct {
+		name        string
+		aggregation string
+		// expected value written for each of the values 1..6, received every 20s,
+		// nil if the value is dropped
+		want []*gnmi.TypedValue
+	}{
+		{
+			name:        "first",
+			aggregation: config.PolicyAggregationFirst,
+			want: []*gnmi.TypedValue{
+				{Value: &gnmi.TypedValue_IntVal{IntVal: 1}}, nil, nil,
+				{Value: &gnmi.TypedValue_IntVal{IntVal: 4}}, nil, nil,
+			},
+		},
+		{
+			name:        "avg",
+			aggregation: config.PolicyAggregationAvg,
+			want: []*gnmi.TypedValue{
+				nil, nil, nil,
+				{Value: &gnmi.TypedValue_DoubleVal{DoubleVal: 2}}, nil, nil,
+			},
+		},
+		{
+			name:        "max",
+			aggregation: config.PolicyAggregationMax,
+			want: []*gnmi.TypedValue{
+				nil, nil, nil,
+				{Value: &gnmi.TypedValue_IntVal{IntVal: 3}}, nil, nil,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &dataPolicy{
+				cfg:     &config.Policy{SampleInterval: time.Minute, Aggregation: tt.aggregation},
+				windows: make(map[string]*sampleWindow),
+			}
+			for i, want := range tt.want {
+				ts := now.UnixNano() + int64(i)*20*sec
+				rsp := intResponse(ts, map[string]int64{"counter": int64(i + 1)})
+				r := p.applyResponse(rsp, m, time.Unix(0, ts))
+				if want == nil {
+					if r != nil {
+						t.Errorf("value %d: expected it to be dropped, got %v", i+1, r)
+					}
+					continue
+				}
+				if r == nil {
+					t.Fatalf("value %d: expected %v, got nothing", i+1, want)
+				}
+				if got := r.GetUpdate().GetUpdate()[0].GetVal(); got.String() != want.String() {
+					t.Errorf("value %d: got %v, want %v", i+1, got, want)
+				}
+				if rsp.GetUpdate().GetUpdate()[0].GetVal().GetIntVal() != int64(i+1) {
+					t.Errorf("value %d: the original response was modified", i+1)
+				}
+			}
+		})
+	}
+}
+
+func TestDataPolicyTruncate(t *testing.T) {
+	now := time.Now()
+	p := &dataPolicy{
+		cfg:     &config.Policy{MaxAge: time.Minute, MaxUpdates: 2},
+		windows: make(map[string]*sampleWindow),
+	}
+	m := outputs.Meta{"source": "r1", "subscription-name": "sub1"}
+	rsp := intResponse(now.UnixNano(), map[string]int64{"a": 1, "b": 2, "c": 3})
+	r := p.applyResponse(rsp, m, now)
+	if n := len(r.GetUpdate().GetUpdate()); n != 2 {
+		t.Errorf("expected 2 updates, got %d", n)
+	}
+	if n := len(rsp.GetUpdate().GetUpdate()); n != 3 {
+		t.Errorf("the original response was modified, got %d updates", n)
+	}
+	if r := p.applyResponse(intResponse(now.Add(-2*time.Minute).UnixNano(), map[string]int64{"a": 1}), m, now); r != nil {
+		t.Errorf("expected the expired response to be dropped, got %v", r)
+	}
+
+	evs := []*formatters.EventMsg{
+		{Name: "sub1", Timestamp: now.UnixNano(), Values: map[string]interface{}{"a": 1, "b": 2, "c": 3}},
+		{Name: "sub1", Timestamp: now.Add(-2 * time.Minute).UnixNano(), Values: map[string]interface{}{"a": 1}},
+	}
+	res := p.applyEvents(evs, now)
+	if len(res) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(res))
+	}
+	if len(res[0].Values) != 2 || res[0].Values["a"] != 1 || res[0].Values["b"] != 2 {
+		t.Errorf("unexpected event values: %v", res[0].Values)
+	}
+	if len(evs[0].Values) != 3 {
+		t.Errorf("the original event was modified: %v", evs[0].Values)
+	}
+}
+
+func TestDataPolicyEventsAggregation(t *testing.T) {
+	now := time.Unix(1000, 0)
+	p := &dataPolicy{
+		cfg:     &config.Policy{SampleInterval: time.Minute, Aggregation: config.PolicyAggregationMin},
+		windows: make(map[string]*sampleWindow),
+	}
+	var got []interface{}
+	for i, v := range []float64{5, 2, 7, 9} {
+		ts := now.Add(time.Duration(i) * 30 * time.Second).UnixNano()
+		ev := &formatters.EventMsg{Name: "sub1", Timestamp: ts, Tags: map[string]string{"source": "r1"}, Values: map[string]interface{}{"v": v}}
+		for _, e := range p.applyEvents([]*formatters.EventMsg{ev}, time.Unix(0, ts)) {
+			got = append(got, e.Values["v"])
+		}
+	}
+	if len(got) != 1 || got[0] != float64(2) {
+		t.Errorf("expected a single min value 2, got %v", got)
+	}
+}
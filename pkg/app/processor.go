@@ -2,6 +2,7 @@ package app
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -54,32 +55,10 @@ func (a *App) ProcessorRunE(cmd *cobra.Command, args []string) error {
 		return err
 	}
 	// read input file
-	inputBytes, err := file.ReadFile(cmd.Context(), a.Config.LocalFlags.ProcessorInput)
+	evInput, err := readEventsInput(cmd.Context(), a.Config.LocalFlags.ProcessorInput, a.Config.LocalFlags.ProcessorInputDelimiter)
 	if err != nil {
 		return err
 	}
-	evInput := make([][]*formatters.EventMsg, 0)
-	msgs := bytes.Split(inputBytes, []byte(a.Config.LocalFlags.ProcessorInputDelimiter))
-	for i, bg := range msgs {
-		if len(bg) == 0 {
-			continue
-		}
-		mevs := make([]map[string]any, 0)
-		err = json.Unmarshal(bg, &mevs)
-		if err != nil {
-			return fmt.Errorf("failed json Unmarshal at msg index %d: %s: %v", i, bg, err)
-		}
-
-		evs := make([]*formatters.EventMsg, 0, len(mevs))
-		for _, mev := range mevs {
-			ev, err := formatters.EventFromMap(mev)
-			if err != nil {
-				return err
-			}
-			evs = append(evs, ev)
-		}
-		evInput = append(evInput, evs)
-	}
 	rrevs := make([][]*formatters.EventMsg, 0, len(evInput))
 	for _, evs := range evInput {
 		revs := evs
@@ -112,6 +91,38 @@ func (a *App) ProcessorRunE(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// readEventsInput reads groups of event messages from path,
+// each group is a JSON list of events, groups are separated by delimiter.
+func readEventsInput(ctx context.Context, path string, delimiter string) ([][]*formatters.EventMsg, error) {
+	inputBytes, err := file.ReadFile(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	evInput := make([][]*formatters.EventMsg, 0)
+	msgs := bytes.Split(inputBytes, []byte(delimiter))
+	for i, bg := range msgs {
+		if len(bytes.TrimSpace(bg)) == 0 {
+			continue
+		}
+		mevs := make([]map[string]any, 0)
+		err = json.Unmarshal(bg, &mevs)
+		if err != nil {
+			return nil, fmt.Errorf("failed json Unmarshal at msg index %d: %s: %v", i, bg, err)
+		}
+
+		evs := make([]*formatters.EventMsg, 0, len(mevs))
+		for _, mev := range mevs {
+			ev, err := formatters.EventFromMap(mev)
+			if err != nil {
+				return nil, err
+			}
+			evs = append(evs, ev)
+		}
+		evInput = append(evInput, evs)
+	}
+	return evInput, nil
+}
+
 func (a *App) InitProcessorFlags(cmd *cobra.Command) {
 	cmd.ResetFlags()
 
@@ -0,0 +1,193 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/cobra"
+
+	"github.com/openconfig/gnmic/pkg/config"
+	"github.com/openconfig/gnmic/pkg/formatters"
+)
+
+func (a *App) ProcessorTestRunE(cmd *cobra.Command, args []string) error {
+	actionsConfig, err := a.Config.GetActions()
+	if err != nil {
+		return fmt.Errorf("failed reading actions config: %v", err)
+	}
+	pConfig, err := a.Config.GetEventProcessors()
+	if err != nil {
+		return fmt.Errorf("failed reading event processors config: %v", err)
+	}
+	tcs, err := a.Config.GetTargets()
+	if err != nil {
+		if !errors.Is(err, config.ErrNoTargetsFound) {
+			return err
+		}
+	}
+	names, err := a.pipelineProcessors(a.Config.LocalFlags.ProcessorTestPipeline)
+	if err != nil {
+		return err
+	}
+	evps, err := formatters.MakeEventProcessors(
+		a.Logger,
+		names,
+		pConfig,
+		tcs,
+		actionsConfig,
+	)
+	if err != nil {
+		return err
+	}
+	evInput, err := readEventsInput(cmd.Context(), a.Config.LocalFlags.ProcessorTestInput, a.Config.LocalFlags.ProcessorTestDelimiter)
+	if err != nil {
+		return err
+	}
+	rrevs := make([][]*formatters.EventMsg, 0, len(evInput))
+	for _, evs := range evInput {
+		for _, p := range evps {
+			evs = p.Apply(evs...)
+		}
+		rrevs = append(rrevs, evs)
+	}
+	b, err := json.MarshalIndent(rrevs, "", "  ")
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	golden := a.Config.LocalFlags.ProcessorTestGolden
+	switch {
+	case golden == "":
+		_, err = a.out.Write(b)
+		return err
+	case a.Config.LocalFlags.ProcessorTestUpdate:
+		err = os.WriteFile(golden, b, 0644)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(a.out, "golden file %s updated\n", golden)
+		return nil
+	}
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		return err
+	}
+	diff, err := diffEventGroups(expected, b)
+	if err != nil {
+		return fmt.Errorf("golden file %s: %v", golden, err)
+	}
+	if diff != "" {
+		fmt.Fprintf(a.out, "pipeline %q output differs from golden file %s (-want +got):\n%s", a.Config.LocalFlags.ProcessorTestPipeline, golden, diff)
+		return fmt.Errorf("pipeline %q output does not match golden file %s", a.Config.LocalFlags.ProcessorTestPipeline, golden)
+	}
+	fmt.Fprintf(a.out, "pipeline %q output matches golden file %s\n", a.Config.LocalFlags.ProcessorTestPipeline, golden)
+	return nil
+}
+
+func (a *App) InitProcessorTestFlags(cmd *cobra.Command) {
+	cmd.ResetFlags()
+
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.ProcessorTestInput, "input", "", "", "file containing the events to run through the pipeline")
+	cmd.MarkFlagRequired("input")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.ProcessorTestDelimiter, "delimiter", "", "\n", "input events groups delimiter")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.ProcessorTestPipeline, "pipeline", "", "", "name of the output or subscription whose event processors are tested, optionally prefixed with 'output:' or 'subscription:'")
+	cmd.MarkFlagRequired("pipeline")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.ProcessorTestGolden, "golden", "", "", "file containing the expected pipeline output, the command fails if the output differs")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.ProcessorTestUpdate, "update", "", false, "write the pipeline output to the golden file instead of comparing it")
+}
+
+// pipelineProcessors returns the event processors names configured under
+// the output or the subscription called name.
+func (a *App) pipelineProcessors(name string) ([]string, error) {
+	kinds := []string{"output", "subscription"}
+	for _, kind := range kinds {
+		if n, ok := strings.CutPrefix(name, kind+":"); ok {
+			name = n
+			kinds = []string{kind}
+			break
+		}
+	}
+	found := make([]string, 0, 1)
+	var names []string
+	for _, kind := range kinds {
+		kindNames, ok := a.pipelineKindProcessors(kind, name)
+		if !ok {
+			continue
+		}
+		found = append(found, kind)
+		names = kindNames
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("unknown pipeline %q: no output or subscription with that name", name)
+	case 1:
+	default:
+		return nil, fmt.Errorf("pipeline %q is both an output and a subscription, prefix it with 'output:' or 'subscription:'", name)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%s %q has no event processors", found[0], name)
+	}
+	return names, nil
+}
+
+// pipelineKindProcessors looks up the output or subscription called name in the loaded config,
+// then in the config file.
+func (a *App) pipelineKindProcessors(kind, name string) ([]string, bool) {
+	switch kind {
+	case "output":
+		if cfg, ok := a.Config.Outputs[name]; ok {
+			names := make([]string, 0)
+			switch eps := cfg["event-processors"].(type) {
+			case []string:
+				names = append(names, eps...)
+			case []interface{}:
+				for _, ep := range eps {
+					if s, ok := ep.(string); ok {
+						names = append(names, s)
+					}
+				}
+			}
+			return names, true
+		}
+	case "subscription":
+		if sub, ok := a.Config.Subscriptions[name]; ok {
+			return sub.EventProcessors, true
+		}
+	}
+	key := kind + "s/" + name
+	if !a.Config.FileConfig.IsSet(key) {
+		return nil, false
+	}
+	return a.Config.FileConfig.GetStringSlice(key + "/event-processors"), true
+}
+
+// diffEventGroups compares two JSON encoded lists of events groups,
+// it returns an empty string if they are equal.
+func diffEventGroups(want, got []byte) (string, error) {
+	var w, g interface{}
+	dec := json.NewDecoder(bytes.NewReader(want))
+	dec.UseNumber()
+	if err := dec.Decode(&w); err != nil {
+		return "", err
+	}
+	dec = json.NewDecoder(bytes.NewReader(got))
+	dec.UseNumber()
+	if err := dec.Decode(&g); err != nil {
+		return "", err
+	}
+	return cmp.Diff(w, g), nil
+}
@@ -0,0 +1,80 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"testing"
+)
+
+func TestPipelineProcessors(t *testing.T) {
+	a := New()
+	a.Config.FileConfig.Set("outputs/out1/event-processors", []string{"p1", "p2"})
+	a.Config.FileConfig.Set("subscriptions/sub1/event-processors", []string{"p3"})
+	a.Config.FileConfig.Set("outputs/both/event-processors", []string{"p1"})
+	a.Config.FileConfig.Set("subscriptions/both/event-processors", []string{"p3"})
+	a.Config.FileConfig.Set("outputs/empty/type", "file")
+
+	tests := []struct {
+		pipeline string
+		want     []string
+		wantErr  bool
+	}{
+		{pipeline: "out1", want: []string{"p1", "p2"}},
+		{pipeline: "sub1", want: []string{"p3"}},
+		{pipeline: "output:both", want: []string{"p1"}},
+		{pipeline: "subscription:both", want: []string{"p3"}},
+		{pipeline: "both", wantErr: true},
+		{pipeline: "subscription:out1", wantErr: true},
+		{pipeline: "empty", wantErr: true},
+		{pipeline: "unknown", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pipeline, func(t *testing.T) {
+			got, err := a.pipelineProcessors(tt.pipeline)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestDiffEventGroups(t *testing.T) {
+	want := []byte(`[[{"name":"sub1","timestamp":1710890476202665500,"values":{"/x":1}}]]`)
+	diff, err := diffEventGroups(want, []byte(`[
+  [
+    {
+      "name": "sub1",
+      "timestamp": 1710890476202665500,
+      "values": {"/x": 1}
+    }
+  ]
+]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff != "" {
+		t.Errorf("unexpected diff: %s", diff)
+	}
+	// timestamps differing in the last digit
+	diff, err = diffEventGroups(want, []byte(`[[{"name":"sub1","timestamp":1710890476202665501,"values":{"/x":1}}]]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff == "" {
+		t.Error("expected a diff")
+	}
+}
@@ -0,0 +1,182 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+)
+
+type pipelineTraceResponse struct {
+	Pipeline   string               `json:"pipeline,omitempty"`
+	Processors []string             `json:"processors,omitempty"`
+	Input      json.RawMessage      `json:"input,omitempty"`
+	Steps      []*pipelineTraceStep `json:"steps,omitempty"`
+	Output     json.RawMessage      `json:"output,omitempty"`
+	Duration   string               `json:"duration,omitempty"`
+}
+
+type pipelineTraceStep struct {
+	Name     string          `json:"name,omitempty"`
+	Type     string          `json:"type,omitempty"`
+	Duration string          `json:"duration,omitempty"`
+	Output   json.RawMessage `json:"output,omitempty"`
+	// actions the processor would have run, they are not run by the trace.
+	Actions []string `json:"actions,omitempty"`
+}
+
+// handlePipelinesTracePost runs the events in the request body through the event processors
+// of the output or subscription {id} and returns the events after each processor.
+// The processors are created for the request, their state is not shared with the running pipelines,
+// and their actions are recorded instead of being run.
+func (a *App) handlePipelinesTracePost(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	defer r.Body.Close()
+	evs, err := eventsFromBody(body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	a.configLock.RLock()
+	names, err := a.pipelineProcessors(id)
+	if err == nil && !a.pipelineVisible(r, id) {
+		err = fmt.Errorf("unknown pipeline %q: no output or subscription with that name", id)
+	}
+	if err != nil {
+		a.configLock.RUnlock()
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	epTypes := make([]string, len(names))
+	for i, n := range names {
+		for epType := range a.Config.Processors[n] {
+			epTypes[i] = epType
+		}
+	}
+	evps, err := formatters.MakeEventProcessors(
+		log.New(io.Discard, "", 0),
+		names,
+		dryRunProcessors(a.Config.Processors),
+		a.Config.Targets,
+		a.Config.Actions,
+	)
+	a.configLock.RUnlock()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+
+	rsp := &pipelineTraceResponse{
+		Pipeline:   id,
+		Processors: names,
+		Steps:      make([]*pipelineTraceStep, 0, len(evps)),
+	}
+	// the events are marshaled after each step since processors modify them in place
+	rsp.Input, err = json.Marshal(evs)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	var total time.Duration
+	for i, ep := range evps {
+		start := time.Now()
+		evs = ep.Apply(evs...)
+		d := time.Since(start)
+		total += d
+		step := &pipelineTraceStep{
+			Name:     names[i],
+			Type:     epTypes[i],
+			Duration: d.String(),
+		}
+		if ar, ok := ep.(formatters.ActionsRecorder); ok {
+			step.Actions = ar.RecordedActions()
+		}
+		step.Output, err = json.Marshal(evs)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+			return
+		}
+		rsp.Steps = append(rsp.Steps, step)
+	}
+	rsp.Output, err = json.Marshal(evs)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	rsp.Duration = total.String()
+	json.NewEncoder(w).Encode(rsp)
+}
+
+// dryRunProcessors returns a copy of the processors configurations ps
+// with the processors running actions set in dry-run mode.
+func dryRunProcessors(ps map[string]map[string]interface{}) map[string]map[string]interface{} {
+	dps := make(map[string]map[string]interface{}, len(ps))
+	for name, epCfg := range ps {
+		dps[name] = make(map[string]interface{}, len(epCfg))
+		for epType, cfg := range epCfg {
+			if m, ok := cfg.(map[string]interface{}); ok && epType == "event-trigger" {
+				dm := make(map[string]interface{}, len(m)+1)
+				for k, v := range m {
+					dm[k] = v
+				}
+				dm["dry-run"] = true
+				cfg = dm
+			}
+			dps[name][epType] = cfg
+		}
+	}
+	return dps
+}
+
+// eventsFromBody decodes a single event or a list of events.
+func eventsFromBody(body []byte) ([]*formatters.EventMsg, error) {
+	body = bytes.TrimSpace(body)
+	mevs := make([]map[string]any, 0, 1)
+	var err error
+	if len(body) > 0 && body[0] == '{' {
+		mev := make(map[string]any)
+		err = json.Unmarshal(body, &mev)
+		mevs = append(mevs, mev)
+	} else {
+		err = json.Unmarshal(body, &mevs)
+	}
+	if err != nil {
+		return nil, err
+	}
+	evs := make([]*formatters.EventMsg, 0, len(mevs))
+	for _, mev := range mevs {
+		ev, err := formatters.EventFromMap(mev)
+		if err != nil {
+			return nil, err
+		}
+		evs = append(evs, ev)
+	}
+	return evs, nil
+}
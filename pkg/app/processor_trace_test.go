@@ -0,0 +1,130 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	_ "github.com/openconfig/gnmic/pkg/formatters/all"
+)
+
+func TestPipelinesTrace(t *testing.T) {
+	a := New()
+	a.Config.Outputs = map[string]map[string]interface{}{
+		"out1": {
+			"type":             "file",
+			"event-processors": []interface{}{"upper", "drop-name"},
+		},
+	}
+	a.Config.Processors = map[string]map[string]interface{}{
+		"upper": {
+			"event-strings": map[string]interface{}{
+				"tag-names": []interface{}{"interface_name"},
+				"transforms": []interface{}{
+					map[string]interface{}{
+						"to-upper": map[string]interface{}{"apply-on": "value"},
+					},
+				},
+			},
+		},
+		"drop-name": {
+			"event-delete": map[string]interface{}{
+				"tag-names": []interface{}{"^interface_name$"},
+			},
+		},
+	}
+	a.routes()
+
+	body := `{"name":"sub1","timestamp":1,"tags":{"interface_name":"mgmt0"},"values":{"/x":1}}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/pipelines/out1/trace", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	a.router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", w.Code, w.Body.String())
+	}
+	rsp := new(pipelineTraceResponse)
+	err := json.Unmarshal(w.Body.Bytes(), rsp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rsp.Steps) != 2 {
+		t.Fatalf("expected 2 steps, got %d", len(rsp.Steps))
+	}
+	if rsp.Steps[0].Name != "upper" || rsp.Steps[0].Type != "event-strings" {
+		t.Errorf("unexpected first step: %+v", rsp.Steps[0])
+	}
+	if !strings.Contains(string(rsp.Steps[0].Output), `"interface_name":"MGMT0"`) {
+		t.Errorf("unexpected first step output: %s", rsp.Steps[0].Output)
+	}
+	if strings.Contains(string(rsp.Steps[1].Output), "interface_name") {
+		t.Errorf("unexpected second step output: %s", rsp.Steps[1].Output)
+	}
+	if !strings.Contains(string(rsp.Input), `"interface_name":"mgmt0"`) {
+		t.Errorf("input was modified: %s", rsp.Input)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/pipelines/unknown/trace", strings.NewReader(body))
+	w = httptest.NewRecorder()
+	a.router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", w.Code)
+	}
+}
+
+func TestPipelinesTraceActions(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) { calls++ }))
+	defer srv.Close()
+
+	a := New()
+	a.Config.Outputs = map[string]map[string]interface{}{
+		"out1": {
+			"type":             "file",
+			"event-processors": []interface{}{"notify"},
+		},
+	}
+	a.Config.Processors = map[string]map[string]interface{}{
+		"notify": {
+			"event-trigger": map[string]interface{}{
+				"condition": `.tags.source == "r1"`,
+				"actions":   []interface{}{"webhook"},
+			},
+		},
+	}
+	a.Config.Actions = map[string]map[string]interface{}{
+		"webhook": {"type": "http", "name": "webhook", "url": srv.URL},
+	}
+	a.routes()
+
+	body := `{"name":"sub1","timestamp":1,"tags":{"source":"r1"},"values":{"/x":1}}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/pipelines/out1/trace", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	a.router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", w.Code, w.Body.String())
+	}
+	rsp := new(pipelineTraceResponse)
+	err := json.Unmarshal(w.Body.Bytes(), rsp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rsp.Steps) != 1 || len(rsp.Steps[0].Actions) != 1 || rsp.Steps[0].Actions[0] != "webhook" {
+		t.Errorf("unexpected steps: %s", w.Body.String())
+	}
+	if calls != 0 {
+		t.Errorf("the trace ran the action")
+	}
+	if _, ok := a.Config.Processors["notify"]["event-trigger"].(map[string]interface{})["dry-run"]; ok {
+		t.Errorf("the processor configuration was modified")
+	}
+}
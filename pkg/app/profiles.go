@@ -0,0 +1,85 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"strings"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/openconfig/gnmic/pkg/api/path"
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/config"
+)
+
+// applyProfile handles the quirks of the target profile, if any,
+// in the subscribe response rsp received from target tc.
+func applyProfile(tc *types.TargetConfig, rsp *gnmi.SubscribeResponse) {
+	if tc.Profile == "" {
+		return
+	}
+	p, ok := config.GetTargetProfile(tc.Profile)
+	if !ok || !p.NormalizeKeys {
+		return
+	}
+	notif := rsp.GetUpdate()
+	if notif == nil {
+		return
+	}
+	normalizePathKeys(notif.GetPrefix())
+	for _, upd := range notif.GetUpdate() {
+		normalizePathKeys(upd.GetPath())
+	}
+	for _, del := range notif.GetDelete() {
+		normalizePathKeys(del)
+	}
+}
+
+// normalizePathKeys moves the keys embedded in the elements names of p to the elements keys,
+// e.g: an element named "interface[name='ge-0/0/0']" becomes an element "interface"
+// with key name=ge-0/0/0, and removes the quotes around the keys values.
+func normalizePathKeys(p *gnmi.Path) {
+	if p == nil {
+		return
+	}
+	elems := make([]*gnmi.PathElem, 0, len(p.GetElem()))
+	for _, pe := range p.GetElem() {
+		if !strings.ContainsAny(pe.GetName(), "[/") {
+			unquoteKeys(pe)
+			elems = append(elems, pe)
+			continue
+		}
+		pp, err := path.ParsePath("/" + pe.GetName())
+		if err != nil || len(pp.GetElem()) == 0 {
+			elems = append(elems, pe)
+			continue
+		}
+		// the element own keys apply to the last parsed element
+		last := pp.GetElem()[len(pp.GetElem())-1]
+		for k, v := range pe.GetKey() {
+			if last.Key == nil {
+				last.Key = make(map[string]string)
+			}
+			last.Key[k] = v
+		}
+		for _, npe := range pp.GetElem() {
+			unquoteKeys(npe)
+		}
+		elems = append(elems, pp.GetElem()...)
+	}
+	p.Elem = elems
+}
+
+func unquoteKeys(pe *gnmi.PathElem) {
+	for k, v := range pe.GetKey() {
+		if len(v) >= 2 && (v[0] == '\'' || v[0] == '"') && v[len(v)-1] == v[0] {
+			pe.Key[k] = v[1 : len(v)-1]
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"testing"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+func TestApplyProfileNormalizeKeys(t *testing.T) {
+	rsp := &gnmi.SubscribeResponse{
+		Response: &gnmi.SubscribeResponse_Update{
+			Update: &gnmi.Notification{
+				Prefix: &gnmi.Path{Elem: []*gnmi.PathElem{
+					{Name: "interfaces"},
+					{Name: "interface[name='ge-0/0/0']"},
+				}},
+				Update: []*gnmi.Update{
+					{Path: &gnmi.Path{Elem: []*gnmi.PathElem{
+						{Name: "subinterfaces/subinterface[index=0]"},
+						{Name: "state"},
+					}}},
+				},
+				Delete: []*gnmi.Path{
+					{Elem: []*gnmi.PathElem{
+						{Name: "config", Key: map[string]string{"id": `"1"`}},
+					}},
+				},
+			},
+		},
+	}
+	want := &gnmi.Notification{
+		Prefix: &gnmi.Path{Elem: []*gnmi.PathElem{
+			{Name: "interfaces"},
+			{Name: "interface", Key: map[string]string{"name": "ge-0/0/0"}},
+		}},
+		Update: []*gnmi.Update{
+			{Path: &gnmi.Path{Elem: []*gnmi.PathElem{
+				{Name: "subinterfaces"},
+				{Name: "subinterface", Key: map[string]string{"index": "0"}},
+				{Name: "state"},
+			}}},
+		},
+		Delete: []*gnmi.Path{
+			{Elem: []*gnmi.PathElem{
+				{Name: "config", Key: map[string]string{"id": "1"}},
+			}},
+		},
+	}
+	// no profile, no changes
+	unchanged := proto.Clone(rsp).(*gnmi.SubscribeResponse)
+	applyProfile(&types.TargetConfig{Name: "t1"}, unchanged)
+	if !proto.Equal(unchanged, rsp) {
+		t.Errorf("response changed without a profile: %v", unchanged)
+	}
+	// a profile without keys normalization
+	applyProfile(&types.TargetConfig{Name: "t1", Profile: "sros"}, unchanged)
+	if !proto.Equal(unchanged, rsp) {
+		t.Errorf("response changed by profile sros: %v", unchanged)
+	}
+
+	applyProfile(&types.TargetConfig{Name: "t1", Profile: "junos"}, rsp)
+	if !proto.Equal(rsp.GetUpdate(), want) {
+		t.Errorf("unexpected normalized notification:\ngot : %v\nwant: %v", rsp.GetUpdate(), want)
+	}
+}
@@ -29,6 +29,7 @@ func (a *App) PromptRunE(cmd *cobra.Command, args []string) error {
 		}
 	}
 	a.PromptMode = true
+	a.promptEvents = newEventsBuffer(a.Config.LocalFlags.PromptEventsBufferSize)
 	// load history
 	a.PromptHistory = make([]string, 0, 256)
 	home, err := homedir.Dir()
@@ -87,6 +88,7 @@ func (a *App) InitPromptFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolVar(&a.Config.LocalFlags.PromptDescriptionWithPrefix, "description-with-prefix", false, "show YANG module prefix in XPATH suggestion description")
 	cmd.Flags().BoolVar(&a.Config.LocalFlags.PromptDescriptionWithTypes, "description-with-types", false, "show YANG types in XPATH suggestion description")
 	cmd.Flags().BoolVar(&a.Config.LocalFlags.PromptSuggestWithOrigin, "suggest-with-origin", false, "suggest XPATHs with origin prepended ")
+	cmd.Flags().IntVar(&a.Config.LocalFlags.PromptEventsBufferSize, "events-buffer-size", defaultPromptEventsBufferSize, "number of recent subscription events kept for inspection with the events commands")
 	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
 		a.Config.FileConfig.BindPFlag(fmt.Sprintf("%s-%s", cmd.Name(), flag.Name), flag)
 	})
@@ -0,0 +1,208 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+const defaultPromptEventsBufferSize = 1000
+
+// eventsBuffer is a fixed size ring buffer holding the most recent events
+// received in prompt mode.
+type eventsBuffer struct {
+	m      *sync.RWMutex
+	events []*formatters.EventMsg
+	next   int
+	full   bool
+}
+
+func newEventsBuffer(size int) *eventsBuffer {
+	if size <= 0 {
+		size = defaultPromptEventsBufferSize
+	}
+	return &eventsBuffer{
+		m:      new(sync.RWMutex),
+		events: make([]*formatters.EventMsg, size),
+	}
+}
+
+func (b *eventsBuffer) add(evs ...*formatters.EventMsg) {
+	b.m.Lock()
+	defer b.m.Unlock()
+	for _, ev := range evs {
+		b.events[b.next] = ev
+		b.next = (b.next + 1) % len(b.events)
+		if b.next == 0 {
+			b.full = true
+		}
+	}
+}
+
+// last returns up to n events matching filter in the order they were received,
+// all matching events are returned if n <= 0.
+func (b *eventsBuffer) last(n int, filter func(*formatters.EventMsg) bool) []*formatters.EventMsg {
+	b.m.RLock()
+	defer b.m.RUnlock()
+	size := b.next
+	if b.full {
+		size = len(b.events)
+	}
+	result := make([]*formatters.EventMsg, 0)
+	// walk the buffer backwards starting from the most recent event
+	for i := 0; i < size; i++ {
+		if n > 0 && len(result) == n {
+			break
+		}
+		ev := b.events[(b.next-1-i+len(b.events))%len(b.events)]
+		if filter == nil || filter(ev) {
+			result = append(result, ev)
+		}
+	}
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result
+}
+
+// capturePromptEvents converts the subscribe response to events and stores them
+// in the prompt events buffer.
+func (a *App) capturePromptEvents(rsp *gnmi.SubscribeResponse, m outputs.Meta) {
+	if a.promptEvents == nil {
+		return
+	}
+	evs, err := formatters.ResponseToEventMsgs(m["subscription-name"], rsp, m)
+	if err != nil {
+		a.Logger.Printf("failed to convert response from %q to events: %v", m["source"], err)
+		return
+	}
+	a.promptEvents.add(evs...)
+}
+
+func promptEventsFilter(target, subscription string) func(*formatters.EventMsg) bool {
+	return func(ev *formatters.EventMsg) bool {
+		if target != "" && ev.Tags["source"] != target {
+			return false
+		}
+		if subscription != "" && ev.Name != subscription {
+			return false
+		}
+		return true
+	}
+}
+
+// PromptEvents returns the last n events received from target
+// for subscription, empty values match all targets and subscriptions.
+func (a *App) PromptEvents(target, subscription string, n int) []*formatters.EventMsg {
+	if a.promptEvents == nil {
+		return nil
+	}
+	return a.promptEvents.last(n, promptEventsFilter(target, subscription))
+}
+
+// PromptEventsJQ runs the jq expression against each of the last n events
+// matching target and subscription, and returns the non null results.
+func (a *App) PromptEventsJQ(expr, target, subscription string, n int) ([]any, error) {
+	code, err := formatters.CompileJQ(strings.TrimSpace(expr))
+	if err != nil {
+		return nil, err
+	}
+	results := make([]any, 0)
+	for _, ev := range a.PromptEvents(target, subscription, n) {
+		// round trip through JSON to get an input gojq can process
+		b, err := json.Marshal(ev)
+		if err != nil {
+			return nil, err
+		}
+		var input any
+		err = json.Unmarshal(b, &input)
+		if err != nil {
+			return nil, err
+		}
+		iter := code.Run(input)
+		for {
+			r, ok := iter.Next()
+			if !ok {
+				break
+			}
+			if err, ok := r.(error); ok {
+				return nil, fmt.Errorf("jq expression failed: %v", err)
+			}
+			if r != nil {
+				results = append(results, r)
+			}
+		}
+	}
+	return results, nil
+}
+
+// PromptEventPaths returns the sorted list of distinct value paths
+// found in the buffered events that start with prefix.
+func (a *App) PromptEventPaths(prefix string) []string {
+	if a.promptEvents == nil {
+		return nil
+	}
+	seen := make(map[string]struct{})
+	for _, ev := range a.promptEvents.last(0, nil) {
+		for p := range ev.Values {
+			if strings.HasPrefix(p, prefix) {
+				seen[p] = struct{}{}
+			}
+		}
+	}
+	paths := make([]string, 0, len(seen))
+	for p := range seen {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// PromptTargetsStatus returns a row per target known to the collector with its name,
+// address, connection state, subscriptions, buffered events count and last event time.
+func (a *App) PromptTargetsStatus() [][]string {
+	counts := make(map[string]int)
+	lastSeen := make(map[string]int64)
+	if a.promptEvents != nil {
+		for _, ev := range a.promptEvents.last(0, nil) {
+			src := ev.Tags["source"]
+			counts[src]++
+			if ev.Timestamp > lastSeen[src] {
+				lastSeen[src] = ev.Timestamp
+			}
+		}
+	}
+	targets := a.targetsStatus()
+	tabData := make([][]string, 0, len(targets))
+	for _, ts := range targets {
+		last := ""
+		if t, ok := lastSeen[ts.Name]; ok {
+			last = time.Unix(0, t).Format(time.RFC3339)
+		}
+		tabData = append(tabData, []string{
+			ts.Name,
+			ts.Address,
+			ts.State,
+			strings.Join(ts.Subscriptions, "\n"),
+			fmt.Sprintf("%d", counts[ts.Name]),
+			last,
+		})
+	}
+	return tabData
+}
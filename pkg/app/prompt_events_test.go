@@ -0,0 +1,73 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+)
+
+func testPromptEvent(i int, target string) *formatters.EventMsg {
+	return &formatters.EventMsg{
+		Name:      "sub1",
+		Timestamp: int64(i),
+		Tags:      map[string]string{"source": target},
+		Values:    map[string]interface{}{fmt.Sprintf("/counters/c%d", i%2): i},
+	}
+}
+
+func TestEventsBuffer(t *testing.T) {
+	b := newEventsBuffer(5)
+	for i := 0; i < 7; i++ {
+		b.add(testPromptEvent(i, "t1"))
+	}
+	all := b.last(0, nil)
+	if len(all) != 5 {
+		t.Fatalf("expected 5 events, got %d", len(all))
+	}
+	for i, ev := range all {
+		if ev.Timestamp != int64(i+2) {
+			t.Fatalf("event %d: expected timestamp %d, got %d", i, i+2, ev.Timestamp)
+		}
+	}
+	last := b.last(2, nil)
+	if len(last) != 2 || last[0].Timestamp != 5 || last[1].Timestamp != 6 {
+		t.Fatalf("unexpected last events: %v", last)
+	}
+}
+
+func TestPromptEventsJQ(t *testing.T) {
+	a := New()
+	a.promptEvents = newEventsBuffer(10)
+	for i := 0; i < 4; i++ {
+		target := "t1"
+		if i%2 == 1 {
+			target = "t2"
+		}
+		a.promptEvents.add(testPromptEvent(i, target))
+	}
+	results, err := a.PromptEventsJQ(`select(.values["/counters/c0"] >= 2) | .timestamp`, "", "", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0] != float64(2) {
+		t.Fatalf("unexpected results: %v", results)
+	}
+	if evs := a.PromptEvents("t2", "", 0); len(evs) != 2 {
+		t.Fatalf("expected 2 events from t2, got %d", len(evs))
+	}
+	if paths := a.PromptEventPaths("/counters/c1"); len(paths) != 1 {
+		t.Fatalf("unexpected paths: %v", paths)
+	}
+	if _, err := a.PromptEventsJQ(`.values[`, "", "", 0); err == nil {
+		t.Fatal("expected a parse error")
+	}
+}
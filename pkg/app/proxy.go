@@ -83,6 +83,10 @@ func (a *App) ProxyRunE(cmd *cobra.Command, args []string) error {
 }
 
 func (a *App) startGNMIProxyServer(ctx context.Context) error {
+	tlsConfig, err := a.gnmiServerTLSConfig()
+	if err != nil {
+		return err
+	}
 	s, err := server.New(server.Config{
 		Address:              a.Config.GnmiServer.Address,
 		MaxUnaryRPC:          a.Config.GnmiServer.MaxUnaryRPC,
@@ -95,6 +99,7 @@ func (a *App) startGNMIProxyServer(ctx context.Context) error {
 		HealthEnabled:        true,
 		RateLimit:            a.Config.GnmiServer.RateLimit,
 		TLS:                  a.Config.GnmiServer.TLS,
+		TLSConfig:            tlsConfig,
 	}, server.WithLogger(a.Logger),
 		server.WithRegistry(a.reg),
 		server.WithGetHandler(a.proxyGetHandler),
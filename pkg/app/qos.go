@@ -0,0 +1,60 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/openconfig/gnmic/pkg/api/path"
+	"github.com/openconfig/gnmic/pkg/api/target"
+	"github.com/openconfig/gnmic/pkg/config"
+)
+
+// priorityClassifier returns the function classifying the targets responses
+// as high priority using the qos rules, nil if there are none.
+func (a *App) priorityClassifier() func(*target.SubscribeResponse) bool {
+	q := a.Config.QoS
+	if q == nil || len(q.HighPriority) == 0 {
+		return nil
+	}
+	return func(rsp *target.SubscribeResponse) bool {
+		n := rsp.Response.GetUpdate()
+		if n == nil {
+			return false
+		}
+		for _, r := range q.HighPriority {
+			if r.MatchesSubscription(rsp.SubscriptionName) && matchesNotificationPath(r, n) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// matchesNotificationPath returns true if one of the update or delete paths
+// of the notification n matches the rule r paths.
+func matchesNotificationPath(r *config.QoSRule, n *gnmi.Notification) bool {
+	if len(r.Paths) == 0 {
+		return true
+	}
+	xpath := func(p *gnmi.Path) string {
+		return "/" + path.GnmiPathToXPath(&gnmi.Path{Elem: path.PathElems(n.GetPrefix(), p)}, false)
+	}
+	for _, u := range n.GetUpdate() {
+		if r.MatchesPath(xpath(u.GetPath())) {
+			return true
+		}
+	}
+	for _, d := range n.GetDelete() {
+		if r.MatchesPath(xpath(d)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/openconfig/gnmic/pkg/api/path"
+	"github.com/openconfig/gnmic/pkg/api/target"
+)
+
+func TestPriorityClassifier(t *testing.T) {
+	a := New()
+	if a.priorityClassifier() != nil {
+		t.Fatal("expected a nil classifier without qos rules")
+	}
+	a.Config.FileConfig.SetConfigType("yaml")
+	in := `
+qos:
+  high-priority:
+    - paths:
+        - /session-state$
+    - subscriptions: [alarms]
+`
+	if err := a.Config.FileConfig.ReadConfig(bytes.NewBufferString(in)); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Config.GetQoS(); err != nil {
+		t.Fatal(err)
+	}
+	classify := a.priorityClassifier()
+	if classify == nil {
+		t.Fatal("expected a classifier")
+	}
+	notification := func(prefix string, paths ...string) *gnmi.SubscribeResponse {
+		n := &gnmi.Notification{}
+		if prefix != "" {
+			n.Prefix, _ = path.ParsePath(prefix)
+		}
+		for _, p := range paths {
+			gp, _ := path.ParsePath(p)
+			n.Update = append(n.Update, &gnmi.Update{Path: gp})
+		}
+		return &gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_Update{Update: n}}
+	}
+	tests := []struct {
+		name string
+		sub  string
+		rsp  *gnmi.SubscribeResponse
+		want bool
+	}{
+		{
+			name: "path",
+			sub:  "bgp",
+			rsp:  notification("", "/network-instance[name=default]/protocols/bgp/neighbors/neighbor[neighbor-address=10.0.0.1]/state/session-state"),
+			want: true,
+		},
+		{
+			name: "prefix_and_path",
+			sub:  "bgp",
+			rsp:  notification("/network-instance[name=default]/protocols/bgp", "neighbors/neighbor[neighbor-address=10.0.0.1]/state/session-state"),
+			want: true,
+		},
+		{
+			name: "bulk",
+			sub:  "counters",
+			rsp:  notification("", "/interfaces/interface[name=1/1/1]/state/counters/in-octets"),
+			want: false,
+		},
+		{
+			name: "subscription",
+			sub:  "alarms",
+			rsp:  notification("", "/system/alarms/alarm[id=1]/state/text"),
+			want: true,
+		},
+		{
+			name: "sync_response",
+			sub:  "alarms",
+			rsp:  &gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_SyncResponse{SyncResponse: true}},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := classify(&target.SubscribeResponse{SubscriptionName: tt.sub, Response: tt.rsp})
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
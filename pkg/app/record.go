@@ -0,0 +1,226 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/openconfig/gnmic/pkg/outputs"
+	"github.com/openconfig/gnmic/pkg/recorder"
+)
+
+// record
+
+func (a *App) RecordPreRunE(cmd *cobra.Command, args []string) error {
+	return a.SubscribePreRunE(cmd, args)
+}
+
+func (a *App) RecordRunE(cmd *cobra.Command, args []string) error {
+	defer a.InitRecordFlags(cmd)
+
+	if a.Config.LocalFlags.RecordFile == "" {
+		return errors.New("missing record file name")
+	}
+	var err error
+	a.recorder, err = recorder.NewWriter(a.Config.LocalFlags.RecordFile)
+	if err != nil {
+		return fmt.Errorf("failed to create record file: %v", err)
+	}
+	a.Logger.Printf("recording subscribe responses to %q", a.Config.LocalFlags.RecordFile)
+	defer a.StopRecorder()
+	return a.SubscribeRunE(cmd, args)
+}
+
+// InitRecordFlags used to init or reset recordCmd flags for gnmic-prompt mode
+func (a *App) InitRecordFlags(cmd *cobra.Command) {
+	// the record command takes the same flags as the subscribe command
+	a.InitSubscribeFlags(cmd)
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.RecordFile, "file", "", "", "record file name")
+	a.Config.FileConfig.BindPFlag(fmt.Sprintf("%s-file", cmd.Name()), cmd.Flags().Lookup("file"))
+}
+
+// StopRecorder writes the record file index and closes it.
+func (a *App) StopRecorder() {
+	if a.recorder == nil {
+		return
+	}
+	err := a.recorder.Close()
+	if err != nil {
+		a.Logger.Printf("failed to close record file: %v", err)
+	}
+}
+
+func (a *App) recordResponse(rsp *gnmi.SubscribeResponse, m outputs.Meta) {
+	if a.recorder == nil {
+		return
+	}
+	err := a.recorder.Write(&recorder.Record{
+		Timestamp:    time.Now(),
+		Target:       m["source"],
+		Subscription: m["subscription-name"],
+		Response:     rsp,
+	})
+	if err != nil {
+		a.Logger.Printf("failed to record response from %q: %v", m["source"], err)
+	}
+}
+
+// replay
+
+func (a *App) ReplayPreRunE(cmd *cobra.Command, args []string) error {
+	a.Config.SetLocalFlagsFromFile(cmd)
+
+	if a.Config.LocalFlags.ReplayFile == "" {
+		return errors.New("missing replay file name")
+	}
+	if a.Config.LocalFlags.ReplaySpeed < 0 {
+		return errors.New("replay speed must be a positive number")
+	}
+	return a.initPluginManager()
+}
+
+func (a *App) ReplayRunE(cmd *cobra.Command, args []string) error {
+	defer a.InitReplayFlags(cmd)
+
+	start, err := parseReplayTime(a.Config.LocalFlags.ReplayStart)
+	if err != nil {
+		return fmt.Errorf("invalid start time: %v", err)
+	}
+	end, err := parseReplayTime(a.Config.LocalFlags.ReplayEnd)
+	if err != nil {
+		return fmt.Errorf("invalid end time: %v", err)
+	}
+	r, err := recorder.NewReader(a.Config.LocalFlags.ReplayFile)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	err = a.readConfigs()
+	if err != nil {
+		return err
+	}
+	err = a.Config.GetGNMIServer()
+	if err != nil {
+		return err
+	}
+	err = a.Config.GetAPIServer()
+	if err != nil {
+		return err
+	}
+	a.startAPIServer()
+	a.startGnmiServer()
+	a.InitOutputs(a.ctx)
+	defer func() {
+		for _, o := range a.Outputs {
+			o.Close()
+		}
+	}()
+
+	for {
+		n, err := a.replay(a.ctx, r, start, end)
+		if err != nil {
+			return err
+		}
+		a.Logger.Printf("replayed %d subscribe responses from %q", n, a.Config.LocalFlags.ReplayFile)
+		if !a.Config.LocalFlags.ReplayLoop {
+			break
+		}
+	}
+	// keep serving the replayed data
+	if a.Config.GnmiServer != nil {
+		<-a.ctx.Done()
+	}
+	return nil
+}
+
+// InitReplayFlags used to init or reset replayCmd flags for gnmic-prompt mode
+func (a *App) InitReplayFlags(cmd *cobra.Command) {
+	cmd.ResetFlags()
+
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.ReplayFile, "file", "", "", "record file name")
+	cmd.MarkFlagRequired("file")
+	cmd.Flags().Float64VarP(&a.Config.LocalFlags.ReplaySpeed, "speed", "", 1, "replay speed factor relative to the recording, 0 replays as fast as possible")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.ReplayStart, "start", "", "", "replay the responses recorded after this time, nanoseconds since Unix epoch or RFC3339 format")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.ReplayEnd, "end", "", "", "replay the responses recorded before this time, nanoseconds since Unix epoch or RFC3339 format")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.ReplayLoop, "loop", "", false, "replay the record file in a loop")
+	//
+	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		a.Config.FileConfig.BindPFlag(fmt.Sprintf("%s-%s", cmd.Name(), flag.Name), flag)
+	})
+}
+
+// replay reads the records between start and end, and exports them to the configured outputs
+// respecting the recorded time intervals adjusted by the replay speed.
+func (a *App) replay(ctx context.Context, r *recorder.Reader, start, end time.Time) (int, error) {
+	var err error
+	if !start.IsZero() {
+		err = r.SeekTime(start)
+	} else {
+		err = r.Rewind()
+	}
+	if err != nil {
+		return 0, err
+	}
+	speed := a.Config.LocalFlags.ReplaySpeed
+	var prev time.Time
+	n := 0
+	for {
+		rec, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		if !start.IsZero() && rec.Timestamp.Before(start) {
+			continue
+		}
+		if !end.IsZero() && rec.Timestamp.After(end) {
+			return n, nil
+		}
+		if speed > 0 && !prev.IsZero() {
+			wait := time.Duration(float64(rec.Timestamp.Sub(prev)) / speed)
+			if wait > 0 {
+				select {
+				case <-ctx.Done():
+					return n, ctx.Err()
+				case <-time.After(wait):
+				}
+			}
+		}
+		prev = rec.Timestamp
+		m := outputs.Meta{
+			"source":            rec.Target,
+			"format":            a.Config.Format,
+			"subscription-name": rec.Subscription,
+		}
+		a.Export(ctx, rec.Response, m)
+		n++
+	}
+}
+
+func parseReplayTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if ts, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(0, ts), nil
+	}
+	return time.Parse(time.RFC3339Nano, s)
+}
@@ -16,21 +16,43 @@ import (
 
 func (a *App) routes() {
 	apiV1 := a.router.PathPrefix("/api/v1").Subrouter()
+	apiV1.Use(a.authMiddleware)
 	a.clusterRoutes(apiV1)
 	a.configRoutes(apiV1)
 	a.targetRoutes(apiV1)
+	a.inputRoutes(apiV1)
+	a.loaderRoutes(apiV1)
+	a.pipelineRoutes(apiV1)
 	a.healthRoutes(apiV1)
+	a.statsRoutes(apiV1)
+	a.sessionRoutes(apiV1)
+	a.inventoryRoutes(apiV1)
+	a.componentRoutes(apiV1)
+	a.silenceRoutes(apiV1)
+	// Alertmanager API v2 compatible endpoints
+	apiV2 := a.router.PathPrefix("/api/v2").Subrouter()
+	apiV2.Use(a.authMiddleware)
+	a.alertRoutes(apiV2)
+	if a.Config.APIServer != nil && a.Config.APIServer.EnableUI {
+		a.ui = newUIState()
+		a.uiRoutes(apiV1)
+	}
 }
 
 func (a *App) clusterRoutes(r *mux.Router) {
-	r.HandleFunc("/cluster", a.handleClusteringGet).Methods(http.MethodGet)
-	r.HandleFunc("/cluster/members", a.handleClusteringMembersGet).Methods(http.MethodGet)
-	r.HandleFunc("/cluster/leader", a.handleClusteringLeaderGet).Methods(http.MethodGet)
+	r.HandleFunc("/cluster", adminOnly(a.handleClusteringGet)).Methods(http.MethodGet)
+	r.HandleFunc("/cluster/members", adminOnly(a.handleClusteringMembersGet)).Methods(http.MethodGet)
+	r.HandleFunc("/cluster/leader", adminOnly(a.handleClusteringLeaderGet)).Methods(http.MethodGet)
+	r.HandleFunc("/cluster/health", adminOnly(a.handleClusterHealthGet)).Methods(http.MethodGet)
+	r.HandleFunc("/cluster/targets/{id}", adminOnly(a.handleClusterTargetGet)).Methods(http.MethodGet)
+	r.HandleFunc("/cluster/targets/{id}/move", adminOnly(a.handleClusterTargetMovePost)).Methods(http.MethodPost)
 }
 
 func (a *App) configRoutes(r *mux.Router) {
 	// config
-	r.HandleFunc("/config", a.handleConfig).Methods(http.MethodGet)
+	r.HandleFunc("/config", adminOnly(a.handleConfig)).Methods(http.MethodGet)
+	r.HandleFunc("/config/export", adminOnly(a.handleConfigExportGet)).Methods(http.MethodGet)
+	r.HandleFunc("/config/import", adminOnly(a.handleConfigImportPost)).Methods(http.MethodPost)
 	// config/targets
 	r.HandleFunc("/config/targets", a.handleConfigTargetsGet).Methods(http.MethodGet)
 	r.HandleFunc("/config/targets/{id}", a.handleConfigTargetsGet).Methods(http.MethodGet)
@@ -41,16 +63,18 @@ func (a *App) configRoutes(r *mux.Router) {
 	r.HandleFunc("/config/subscriptions", a.handleConfigSubscriptions).Methods(http.MethodGet)
 	// config/outputs
 	r.HandleFunc("/config/outputs", a.handleConfigOutputs).Methods(http.MethodGet)
+	r.HandleFunc("/config/outputs", a.handleConfigOutputsPost).Methods(http.MethodPost)
+	r.HandleFunc("/config/outputs/{id}", a.handleConfigOutputsDelete).Methods(http.MethodDelete)
 	// config/inputs
-	r.HandleFunc("/config/inputs", a.handleConfigInputs).Methods(http.MethodGet)
+	r.HandleFunc("/config/inputs", adminOnly(a.handleConfigInputs)).Methods(http.MethodGet)
 	// config/processors
-	r.HandleFunc("/config/processors", a.handleConfigProcessors).Methods(http.MethodGet)
+	r.HandleFunc("/config/processors", adminOnly(a.handleConfigProcessors)).Methods(http.MethodGet)
 	// config/clustering
-	r.HandleFunc("/config/clustering", a.handleConfigClustering).Methods(http.MethodGet)
+	r.HandleFunc("/config/clustering", adminOnly(a.handleConfigClustering)).Methods(http.MethodGet)
 	// config/api-server
-	r.HandleFunc("/config/api-server", a.handleConfigAPIServer).Methods(http.MethodGet)
+	r.HandleFunc("/config/api-server", adminOnly(a.handleConfigAPIServer)).Methods(http.MethodGet)
 	// config/gnmi-server
-	r.HandleFunc("/config/gnmi-server", a.handleConfigGNMIServer).Methods(http.MethodGet)
+	r.HandleFunc("/config/gnmi-server", adminOnly(a.handleConfigGNMIServer)).Methods(http.MethodGet)
 }
 
 func (a *App) targetRoutes(r *mux.Router) {
@@ -59,8 +83,58 @@ func (a *App) targetRoutes(r *mux.Router) {
 	r.HandleFunc("/targets/{id}", a.handleTargetsGet).Methods(http.MethodGet)
 	r.HandleFunc("/targets/{id}", a.handleTargetsPost).Methods(http.MethodPost)
 	r.HandleFunc("/targets/{id}", a.handleTargetsDelete).Methods(http.MethodDelete)
+	r.HandleFunc("/targets/{id}/subscriptions/{name}/poll", a.handleTargetsSubscriptionsPollPost).Methods(http.MethodPost)
+}
+
+func (a *App) inputRoutes(r *mux.Router) {
+	// inputs
+	r.HandleFunc("/inputs/{id}/offsets", adminOnly(a.handleInputsOffsetsGet)).Methods(http.MethodGet)
+	r.HandleFunc("/inputs/{id}/offsets", adminOnly(a.handleInputsOffsetsPost)).Methods(http.MethodPost)
+}
+
+func (a *App) loaderRoutes(r *mux.Router) {
+	// loader pending changes
+	r.HandleFunc("/loader/pending", adminOnly(a.handleLoaderPendingGet)).Methods(http.MethodGet)
+	r.HandleFunc("/loader/pending", adminOnly(a.handleLoaderPendingDelete)).Methods(http.MethodDelete)
+	r.HandleFunc("/loader/pending/approve", adminOnly(a.handleLoaderPendingApprovePost)).Methods(http.MethodPost)
+}
+
+func (a *App) pipelineRoutes(r *mux.Router) {
+	// pipelines
+	r.HandleFunc("/pipelines/{id}/trace", a.handlePipelinesTracePost).Methods(http.MethodPost)
 }
 
 func (a *App) healthRoutes(r *mux.Router) {
 	r.HandleFunc("/healthz", a.handleHealthzGet).Methods(http.MethodGet)
 }
+
+func (a *App) statsRoutes(r *mux.Router) {
+	r.HandleFunc("/stats", a.handleStatsGet).Methods(http.MethodGet)
+}
+
+func (a *App) sessionRoutes(r *mux.Router) {
+	r.HandleFunc("/sessions", a.handleSessionsGet).Methods(http.MethodGet)
+}
+
+func (a *App) inventoryRoutes(r *mux.Router) {
+	r.HandleFunc("/inventory", a.handleInventoryGet).Methods(http.MethodGet)
+	r.HandleFunc("/inventory/{target}", a.handleInventoryGet).Methods(http.MethodGet)
+}
+
+func (a *App) componentRoutes(r *mux.Router) {
+	r.HandleFunc("/components", adminOnly(a.handleComponentsGet)).Methods(http.MethodGet)
+	r.HandleFunc("/components/{kind}/{name}/{action:enable|disable}", adminOnly(a.handleComponentsActionPost)).Methods(http.MethodPost)
+	r.HandleFunc("/components/{kind:loader|clustering}/{action:enable|disable}", adminOnly(a.handleComponentsActionPost)).Methods(http.MethodPost)
+}
+
+func (a *App) silenceRoutes(r *mux.Router) {
+	r.HandleFunc("/silences", adminOnly(a.handleSilencesGet)).Methods(http.MethodGet)
+	r.HandleFunc("/silences/{id}", adminOnly(a.handleSilencesGet)).Methods(http.MethodGet)
+	r.HandleFunc("/silences", adminOnly(a.handleSilencesPost)).Methods(http.MethodPost)
+	r.HandleFunc("/silences/{id}", adminOnly(a.handleSilencesDelete)).Methods(http.MethodDelete)
+}
+
+func (a *App) alertRoutes(r *mux.Router) {
+	r.HandleFunc("/alerts", a.handleAlertsGet).Methods(http.MethodGet)
+	r.HandleFunc("/alerts/groups", a.handleAlertGroupsGet).Methods(http.MethodGet)
+}
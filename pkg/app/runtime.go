@@ -0,0 +1,90 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"os"
+	"runtime"
+
+	"go.uber.org/automaxprocs/maxprocs"
+)
+
+// how GOMAXPROCS was set
+const (
+	maxProcsSourceDefault  = "default"
+	maxProcsSourceFlag     = "flag"
+	maxProcsSourceEnv      = "env"
+	maxProcsSourceCPUQuota = "cpu-quota"
+)
+
+// setMaxProcs sets GOMAXPROCS from the max-procs flag if positive,
+// from the container CPU quota if 0, unless the GOMAXPROCS environment variable is set.
+// A negative max-procs leaves the Go runtime default: the number of CPUs of the host.
+func (a *App) setMaxProcs() {
+	switch {
+	case a.Config.MaxProcs > 0:
+		runtime.GOMAXPROCS(a.Config.MaxProcs)
+		a.maxProcsSource = maxProcsSourceFlag
+	case a.Config.MaxProcs < 0:
+		a.maxProcsSource = maxProcsSourceDefault
+	default:
+		if _, ok := os.LookupEnv("GOMAXPROCS"); ok {
+			a.maxProcsSource = maxProcsSourceEnv
+			break
+		}
+		prev := runtime.GOMAXPROCS(0)
+		_, err := maxprocs.Set(maxprocs.Logger(a.Logger.Printf))
+		if err != nil {
+			// the CPU quota is a tuning hint, gnmic runs with the Go runtime default.
+			a.Logger.Printf("failed to set GOMAXPROCS from the CPU quota: %v", err)
+		}
+		a.maxProcsSource = maxProcsSourceDefault
+		if runtime.GOMAXPROCS(0) != prev {
+			a.maxProcsSource = maxProcsSourceCPUQuota
+		}
+	}
+	a.Logger.Printf("running with GOMAXPROCS=%d, set from %s", runtime.GOMAXPROCS(0), a.maxProcsSource)
+}
+
+// outputQuota limits the number of concurrent writes to an output,
+// a nil outputQuota is unlimited.
+type outputQuota chan struct{}
+
+func newOutputQuota(n int) outputQuota {
+	if n <= 0 {
+		return nil
+	}
+	return make(outputQuota, n)
+}
+
+// acquire waits for a write slot until ctx is done.
+// throttled is true if all the slots were in use.
+func (q outputQuota) acquire(ctx context.Context) (ok, throttled bool) {
+	if q == nil {
+		return true, false
+	}
+	select {
+	case q <- struct{}{}:
+		return true, false
+	default:
+	}
+	select {
+	case q <- struct{}{}:
+		return true, true
+	case <-ctx.Done():
+		return false, true
+	}
+}
+
+func (q outputQuota) release() {
+	if q != nil {
+		<-q
+	}
+}
@@ -0,0 +1,32 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestSetMaxProcs(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+
+	a := New()
+	a.Config.MaxProcs = 3
+	a.setMaxProcs()
+	if n := runtime.GOMAXPROCS(0); n != 3 || a.maxProcsSource != maxProcsSourceFlag {
+		t.Errorf("got GOMAXPROCS=%d from %s, want 3 from %s", n, a.maxProcsSource, maxProcsSourceFlag)
+	}
+
+	t.Setenv("GOMAXPROCS", "2")
+	a.Config.MaxProcs = 0
+	a.setMaxProcs()
+	if n := runtime.GOMAXPROCS(0); n != 3 || a.maxProcsSource != maxProcsSourceEnv {
+		t.Errorf("got GOMAXPROCS=%d from %s, want it unchanged from %s", n, a.maxProcsSource, maxProcsSourceEnv)
+	}
+}
@@ -0,0 +1,112 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"hash/fnv"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/openconfig/gnmic/pkg/api/target"
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/cron"
+)
+
+// runSchedule computes the run times of a scheduled subscription on a target.
+type runSchedule struct {
+	interval time.Duration
+	cron     *cron.Schedule
+	// delay added to each run, derived from the target and subscription names
+	// so that the targets runs are spread over the schedule splay.
+	offset time.Duration
+}
+
+func newRunSchedule(sch *types.SubscriptionSchedule, key string) (*runSchedule, error) {
+	rs := &runSchedule{interval: sch.Interval}
+	if sch.Cron != "" {
+		var err error
+		rs.cron, err = cron.Parse(sch.Cron)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if sch.Splay > 0 {
+		h := fnv.New64a()
+		h.Write([]byte(key))
+		rs.offset = time.Duration(h.Sum64() % uint64(sch.Splay))
+	}
+	return rs, nil
+}
+
+// first returns the time of the first run.
+func (rs *runSchedule) first(now time.Time) time.Time {
+	if rs.cron != nil {
+		return rs.next(time.Time{}, now)
+	}
+	return now.Add(rs.offset)
+}
+
+// next returns the time of the run following the one at last,
+// the zero time if there is none.
+func (rs *runSchedule) next(last, now time.Time) time.Time {
+	if rs.cron != nil {
+		n := rs.cron.Next(now.Add(-rs.offset))
+		if n.IsZero() {
+			return n
+		}
+		return n.Add(rs.offset)
+	}
+	n := last.Add(rs.interval)
+	if n.Before(now) {
+		// the previous run took longer than the interval
+		return now
+	}
+	return n
+}
+
+// scheduledSubscribe runs the once subscription sreq on the target t,
+// or polls the poll subscription sreq, at the times set by the subscription schedule,
+// until ctx is canceled.
+func (a *App) scheduledSubscribe(ctx context.Context, t *target.Target, sreq subscriptionRequest) {
+	rs, err := newRunSchedule(sreq.config.Schedule, t.Config.Name+"/"+sreq.name)
+	if err != nil {
+		a.Logger.Printf("target %q subscription %q: %v", t.Config.Name, sreq.name, err)
+		return
+	}
+	poll := sreq.req.GetSubscribe().GetMode() == gnmi.SubscriptionList_POLL
+	if poll {
+		// the poll stream is kept open and polled at each run
+		go t.Subscribe(ctx, sreq.req, sreq.name)
+	}
+	runAt := rs.first(time.Now())
+	for !runAt.IsZero() {
+		timer := time.NewTimer(time.Until(runAt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if a.Config.Debug {
+			a.Logger.Printf("target %q: running scheduled subscription %q", t.Config.Name, sreq.name)
+		}
+		if poll {
+			if err := t.SubscribePoll(ctx, sreq.name); err != nil {
+				a.Logger.Printf("target %q: failed to poll subscription %q: %v", t.Config.Name, sreq.name, err)
+			}
+		} else {
+			// returns once the target closes the stream
+			t.Subscribe(ctx, sreq.req, sreq.name)
+		}
+		runAt = rs.next(runAt, time.Now())
+	}
+	a.Logger.Printf("target %q: subscription %q schedule has no next run", t.Config.Name, sreq.name)
+}
@@ -0,0 +1,63 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+func TestRunScheduleInterval(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	rs, err := newRunSchedule(&types.SubscriptionSchedule{Interval: time.Hour, Splay: 10 * time.Minute}, "t1/sub1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs.offset < 0 || rs.offset >= 10*time.Minute {
+		t.Fatalf("offset %s out of the splay", rs.offset)
+	}
+	first := rs.first(now)
+	if want := now.Add(rs.offset); !first.Equal(want) {
+		t.Errorf("first run: got %s, want %s", first, want)
+	}
+	if got, want := rs.next(first, first.Add(time.Minute)), first.Add(time.Hour); !got.Equal(want) {
+		t.Errorf("next run: got %s, want %s", got, want)
+	}
+	// a run longer than the interval
+	late := first.Add(2 * time.Hour)
+	if got := rs.next(first, late); !got.Equal(late) {
+		t.Errorf("late next run: got %s, want %s", got, late)
+	}
+	other, err := newRunSchedule(&types.SubscriptionSchedule{Interval: time.Hour, Splay: 10 * time.Minute}, "t2/sub1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.offset == rs.offset {
+		t.Errorf("expected different offsets per target")
+	}
+}
+
+func TestRunScheduleCron(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 5, 0, 0, time.UTC)
+	rs, err := newRunSchedule(&types.SubscriptionSchedule{Cron: "0 * * * *"}, "t1/sub1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs.offset = 2 * time.Minute
+	first := rs.first(now)
+	if want := time.Date(2024, 1, 1, 11, 2, 0, 0, time.UTC); !first.Equal(want) {
+		t.Errorf("first run: got %s, want %s", first, want)
+	}
+	// computed right after the first run
+	if got, want := rs.next(first, first.Add(time.Second)), time.Date(2024, 1, 1, 12, 2, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("next run: got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,79 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/openconfig/gnmic/pkg/api/server"
+	"github.com/openconfig/gnmic/pkg/mock"
+)
+
+const defaultServerAddress = ":57400"
+
+func (a *App) ServerPreRunE(cmd *cobra.Command, args []string) error {
+	a.Config.SetLocalFlagsFromFile(cmd)
+
+	if !a.Config.LocalFlags.ServerMock {
+		return errors.New("the server command only supports the --mock mode")
+	}
+	if a.Config.LocalFlags.ServerFixtures == "" {
+		return errors.New("missing fixtures directory")
+	}
+	if a.Config.LocalFlags.ServerJitter < 0 {
+		return errors.New("jitter must be a positive number")
+	}
+	return nil
+}
+
+func (a *App) ServerRunE(cmd *cobra.Command, args []string) error {
+	defer a.InitServerFlags(cmd)
+
+	fixtures, err := mock.LoadFixtures(a.Config.LocalFlags.ServerFixtures)
+	if err != nil {
+		return fmt.Errorf("failed to load fixtures: %v", err)
+	}
+	mt := mock.New(fixtures, a.Config.LocalFlags.ServerJitter, a.Config.LocalFlags.ServerSampleInterval)
+	s, err := server.New(server.Config{
+		Address:         a.Config.LocalFlags.ServerAddress,
+		MaxStreamingRPC: -1,
+		MaxUnaryRPC:     -1,
+	},
+		server.WithLogger(a.Logger),
+		server.WithCapabilitiesHandler(mt.Capabilities),
+		server.WithGetHandler(mt.Get),
+		server.WithSubscribeHandler(mt.Subscribe),
+	)
+	if err != nil {
+		return err
+	}
+	a.Logger.Printf("serving %d fixture file(s) from %q on %s",
+		len(fixtures), a.Config.LocalFlags.ServerFixtures, a.Config.LocalFlags.ServerAddress)
+	return s.Start(a.ctx)
+}
+
+// InitServerFlags used to init or reset serverCmd flags for gnmic-prompt mode
+func (a *App) InitServerFlags(cmd *cobra.Command) {
+	cmd.ResetFlags()
+
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.ServerAddress, "address", "", defaultServerAddress, "gNMI server listen address")
+	cmd.Flags().BoolVarP(&a.Config.LocalFlags.ServerMock, "mock", "", false, "serve gNMI RPCs from fixture files")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.ServerFixtures, "fixtures", "", "", "directory containing YAML or JSON fixture files, or a single fixture file")
+	cmd.Flags().Float64VarP(&a.Config.LocalFlags.ServerJitter, "jitter", "", 0, "ratio by which numeric values are randomly varied each time they are sent, e.g: 0.1 for +/-10%")
+	cmd.Flags().DurationVarP(&a.Config.LocalFlags.ServerSampleInterval, "sample-interval", "", 10*time.Second, "sample interval used for STREAM subscriptions that do not set one")
+	//
+	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		a.Config.FileConfig.BindPFlag(fmt.Sprintf("%s-%s", cmd.Name(), flag.Name), flag)
+	})
+}
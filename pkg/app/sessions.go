@@ -0,0 +1,411 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/openconfig/gnmic/pkg/api/path"
+	"github.com/openconfig/gnmic/pkg/config"
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+const (
+	sessionEventName = "session-state"
+
+	sessionTransitionUp   = "up"
+	sessionTransitionDown = "down"
+)
+
+// protocolSession is a row of the sessions table.
+type protocolSession struct {
+	Target          string                     `json:"target"`
+	Protocol        string                     `json:"protocol"`
+	NetworkInstance string                     `json:"network-instance,omitempty"`
+	Peer            string                     `json:"peer"`
+	Interface       string                     `json:"interface,omitempty"`
+	Level           string                     `json:"level,omitempty"`
+	State           string                     `json:"state,omitempty"`
+	Up              bool                       `json:"up"`
+	LastChange      *time.Time                 `json:"last-change,omitempty"`
+	Transitions     uint64                     `json:"transitions"`
+	AfiSafis        map[string]*sessionAfiSafi `json:"afi-safis,omitempty"`
+}
+
+type sessionAfiSafi struct {
+	Active           bool   `json:"active"`
+	PrefixesReceived uint64 `json:"prefixes-received"`
+}
+
+// sessionTable holds the protocol sessions per target.
+type sessionTable struct {
+	m sync.RWMutex
+	// sessions per target and session key
+	sessions map[string]map[string]*protocolSession
+}
+
+func newSessionTable() *sessionTable {
+	return &sessionTable{sessions: make(map[string]map[string]*protocolSession)}
+}
+
+// sessionPath is a session state leaf, matched on the last path elements names.
+type sessionPath struct {
+	protocol string
+	elems    []string
+	leaf     string
+}
+
+var sessionPaths = []sessionPath{
+	{
+		protocol: config.SessionProtocolBGP,
+		elems:    []string{"bgp", "neighbors", "neighbor", "state", "session-state"},
+		leaf:     "session-state",
+	},
+	{
+		protocol: config.SessionProtocolBGP,
+		elems:    []string{"bgp", "neighbors", "neighbor", "afi-safis", "afi-safi", "state", "active"},
+		leaf:     "active",
+	},
+	{
+		protocol: config.SessionProtocolBGP,
+		elems:    []string{"bgp", "neighbors", "neighbor", "afi-safis", "afi-safi", "state", "prefixes", "received"},
+		leaf:     "received",
+	},
+	{
+		protocol: config.SessionProtocolISIS,
+		elems:    []string{"isis", "interfaces", "interface", "levels", "level", "adjacencies", "adjacency", "state", "adjacency-state"},
+		leaf:     "adjacency-state",
+	},
+}
+
+// sessionDeletePaths are the list entries a session is removed with.
+var sessionDeletePaths = []sessionPath{
+	{protocol: config.SessionProtocolBGP, elems: []string{"bgp", "neighbors", "neighbor"}},
+	{protocol: config.SessionProtocolISIS, elems: []string{"isis", "interfaces", "interface", "levels", "level", "adjacencies", "adjacency"}},
+}
+
+// match returns true if the names of the last elements of elems are the path elements.
+func (sp sessionPath) match(elems []*gnmi.PathElem) bool {
+	if len(elems) < len(sp.elems) {
+		return false
+	}
+	offset := len(elems) - len(sp.elems)
+	for i, name := range sp.elems {
+		if elemName(elems[offset+i]) != name {
+			return false
+		}
+	}
+	return true
+}
+
+// elemName returns the path element name without its module prefix.
+func elemName(e *gnmi.PathElem) string {
+	n := e.GetName()
+	if i := strings.LastIndex(n, ":"); i >= 0 {
+		return n[i+1:]
+	}
+	return n
+}
+
+// sessionRow returns a session identified by the keys of the path elements.
+func sessionRow(target, protocol string, elems []*gnmi.PathElem) *protocolSession {
+	s := &protocolSession{Target: target, Protocol: protocol}
+	for _, e := range elems {
+		switch elemName(e) {
+		case "network-instance":
+			s.NetworkInstance = e.GetKey()["name"]
+		case "neighbor":
+			s.Peer = e.GetKey()["neighbor-address"]
+		case "interface":
+			if protocol == config.SessionProtocolISIS {
+				s.Interface = e.GetKey()["interface-id"]
+			}
+		case "level":
+			s.Level = e.GetKey()["level-number"]
+		case "adjacency":
+			s.Peer = e.GetKey()["system-id"]
+		}
+	}
+	return s
+}
+
+func (s *protocolSession) key() string {
+	return strings.Join([]string{s.Protocol, s.NetworkInstance, s.Peer, s.Interface, s.Level}, "/")
+}
+
+// trackSessions updates the sessions table with the session state leaves of rsp.
+// An event is written to the session tracking outputs for each session going up or down.
+func (a *App) trackSessions(ctx context.Context, rsp *gnmi.SubscribeResponse, m outputs.Meta, now time.Time) {
+	a.configLock.RLock()
+	st := a.Config.SessionTracking
+	a.configLock.RUnlock()
+	n := rsp.GetUpdate()
+	if st == nil || n == nil {
+		return
+	}
+	ts := n.GetTimestamp()
+	if ts == 0 {
+		ts = now.UnixNano()
+	}
+	evs := a.sessions.update(st, m["source"], n, time.Unix(0, ts))
+	for _, ev := range evs {
+		a.Logger.Printf("target %q: %s session %s is %s", m["source"], ev.Tags["protocol"], ev.Tags["peer"], ev.Tags["transition"])
+		if len(st.Outputs) == 0 {
+			continue
+		}
+		ev := ev
+		go a.writeOutputs(ctx, m["source"], a.targetNamespace(m["source"]), st.Outputs, 0, 1, func(name string, o outputs.Output) (int, int) {
+			o.WriteEvent(ctx, ev)
+			return 0, 1
+		})
+	}
+}
+
+// update applies the updates and deletes of the notification n of target to the table.
+// It returns an event for each known session going up or down.
+func (t *sessionTable) update(st *config.SessionTracking, target string, n *gnmi.Notification, ts time.Time) []*formatters.EventMsg {
+	var evs []*formatters.EventMsg
+	t.m.Lock()
+	defer t.m.Unlock()
+	for _, del := range n.GetDelete() {
+		elems := path.PathElems(n.GetPrefix(), del)
+		for _, sp := range sessionDeletePaths {
+			if st.Tracks(sp.protocol) && sp.match(elems) {
+				delete(t.sessions[target], sessionRow(target, sp.protocol, elems).key())
+			}
+		}
+	}
+	for _, upd := range n.GetUpdate() {
+		elems := path.PathElems(n.GetPrefix(), upd.GetPath())
+		for _, sp := range sessionPaths {
+			if !st.Tracks(sp.protocol) || !sp.match(elems) {
+				continue
+			}
+			s := t.session(sessionRow(target, sp.protocol, elems))
+			if ev := s.set(sp.leaf, elems, leafValue(upd.GetVal()), ts); ev != nil {
+				evs = append(evs, ev)
+			}
+			break
+		}
+	}
+	return evs
+}
+
+// session returns the table session matching row, row is added if it is not known yet.
+func (t *sessionTable) session(row *protocolSession) *protocolSession {
+	ts, ok := t.sessions[row.Target]
+	if !ok {
+		ts = make(map[string]*protocolSession)
+		t.sessions[row.Target] = ts
+	}
+	k := row.key()
+	if s, ok := ts[k]; ok {
+		return s
+	}
+	ts[k] = row
+	return row
+}
+
+// set sets the session leaf value v received at ts,
+// it returns an event if the session goes up or down.
+func (s *protocolSession) set(leaf string, elems []*gnmi.PathElem, v any, ts time.Time) *formatters.EventMsg {
+	switch leaf {
+	case "session-state", "adjacency-state":
+		state := stripPrefix(fmt.Sprint(v))
+		up := s.Protocol == config.SessionProtocolBGP && strings.EqualFold(state, "ESTABLISHED") ||
+			s.Protocol == config.SessionProtocolISIS && strings.EqualFold(state, "UP")
+		known := s.State != ""
+		prev := s.State
+		s.State = state
+		if known && up == s.Up {
+			return nil
+		}
+		s.Up = up
+		s.LastChange = &ts
+		if !known {
+			return nil
+		}
+		s.Transitions++
+		return s.event(prev, ts)
+	case "active", "received":
+		var afiSafi string
+		for _, e := range elems {
+			if elemName(e) == "afi-safi" {
+				afiSafi = stripPrefix(e.GetKey()["afi-safi-name"])
+			}
+		}
+		if s.AfiSafis == nil {
+			s.AfiSafis = make(map[string]*sessionAfiSafi)
+		}
+		as, ok := s.AfiSafis[afiSafi]
+		if !ok {
+			as = new(sessionAfiSafi)
+			s.AfiSafis[afiSafi] = as
+		}
+		if leaf == "active" {
+			as.Active = v == true || v == "true"
+		} else if f, ok := eventValueConv.toFloat(v); ok && f >= 0 {
+			as.PrefixesReceived = uint64(f)
+		}
+	}
+	return nil
+}
+
+func (s *protocolSession) event(prev string, ts time.Time) *formatters.EventMsg {
+	transition := sessionTransitionDown
+	if s.Up {
+		transition = sessionTransitionUp
+	}
+	ev := &formatters.EventMsg{
+		Name:      sessionEventName,
+		Timestamp: ts.UnixNano(),
+		Tags: map[string]string{
+			"source":     s.Target,
+			"protocol":   s.Protocol,
+			"peer":       s.Peer,
+			"transition": transition,
+		},
+		Values: map[string]interface{}{
+			"state":          s.State,
+			"previous-state": prev,
+			"transitions":    s.Transitions,
+		},
+	}
+	for k, v := range map[string]string{
+		"network-instance": s.NetworkInstance,
+		"interface":        s.Interface,
+		"level":            s.Level,
+	} {
+		if v != "" {
+			ev.Tags[k] = v
+		}
+	}
+	return ev
+}
+
+// stripPrefix removes the module prefix of an identity or enumeration value.
+func stripPrefix(s string) string {
+	if i := strings.LastIndex(s, ":"); i >= 0 {
+		return s[i+1:]
+	}
+	return s
+}
+
+// leafValue returns the value of a state leaf or leaf-list.
+func leafValue(tv *gnmi.TypedValue) any {
+	switch v := tv.GetValue().(type) {
+	case *gnmi.TypedValue_StringVal:
+		return v.StringVal
+	case *gnmi.TypedValue_AsciiVal:
+		return v.AsciiVal
+	case *gnmi.TypedValue_BoolVal:
+		return v.BoolVal
+	case *gnmi.TypedValue_UintVal:
+		return v.UintVal
+	case *gnmi.TypedValue_IntVal:
+		return v.IntVal
+	case *gnmi.TypedValue_JsonIetfVal:
+		var val any
+		json.Unmarshal(v.JsonIetfVal, &val)
+		return val
+	case *gnmi.TypedValue_JsonVal:
+		var val any
+		json.Unmarshal(v.JsonVal, &val)
+		return val
+	case *gnmi.TypedValue_LeaflistVal:
+		vals := make([]any, 0, len(v.LeaflistVal.GetElement()))
+		for _, e := range v.LeaflistVal.GetElement() {
+			vals = append(vals, leafValue(e))
+		}
+		return vals
+	}
+	return nil
+}
+
+// deleteTarget removes the sessions of target from the table.
+func (t *sessionTable) deleteTarget(target string) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	delete(t.sessions, target)
+}
+
+// list returns a copy of the sessions matching the filter function, sorted by target and key.
+func (t *sessionTable) list(filter func(s *protocolSession) bool) []*protocolSession {
+	t.m.RLock()
+	defer t.m.RUnlock()
+	res := make([]*protocolSession, 0)
+	for _, ts := range t.sessions {
+		for _, s := range ts {
+			if !filter(s) {
+				continue
+			}
+			c := *s
+			c.AfiSafis = make(map[string]*sessionAfiSafi, len(s.AfiSafis))
+			for k, as := range s.AfiSafis {
+				asc := *as
+				c.AfiSafis[k] = &asc
+			}
+			res = append(res, &c)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Target != res[j].Target {
+			return res[i].Target < res[j].Target
+		}
+		return res[i].key() < res[j].key()
+	})
+	return res
+}
+
+func (a *App) handleSessionsGet(w http.ResponseWriter, r *http.Request) {
+	a.configLock.RLock()
+	enabled := a.Config.SessionTracking != nil
+	a.configLock.RUnlock()
+	if !enabled {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{"session tracking is not enabled"}})
+		return
+	}
+	q := r.URL.Query()
+	target, protocol, state := q.Get("target"), q.Get("protocol"), q.Get("state")
+	if state != "" && state != sessionTransitionUp && state != sessionTransitionDown {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{fmt.Sprintf("invalid state %q, must be %q or %q", state, sessionTransitionUp, sessionTransitionDown)}})
+		return
+	}
+	sessions := a.sessions.list(func(s *protocolSession) bool {
+		if target != "" && s.Target != target {
+			return false
+		}
+		if protocol != "" && s.Protocol != protocol {
+			return false
+		}
+		if state != "" && s.Up != (state == sessionTransitionUp) {
+			return false
+		}
+		return a.targetVisible(r, s.Target)
+	})
+	b, err := json.Marshal(sessions)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	w.Write(b)
+}
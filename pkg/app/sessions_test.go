@@ -0,0 +1,147 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/openconfig/gnmic/pkg/api/path"
+	"github.com/openconfig/gnmic/pkg/config"
+)
+
+const (
+	testBGPNeighbor = "/network-instances/network-instance[name=default]/protocols/protocol[identifier=BGP][name=bgp]/bgp/neighbors/neighbor[neighbor-address=10.0.0.1]"
+	testISISAdj     = "/network-instances/network-instance[name=default]/protocols/protocol[identifier=ISIS][name=isis]/isis/interfaces/interface[interface-id=ethernet-1/1]/levels/level[level-number=2]/adjacencies/adjacency[system-id=0100.0000.0002]"
+)
+
+func sessionNotification(t *testing.T, p string, v *gnmi.TypedValue) *gnmi.Notification {
+	gp, err := path.ParsePath(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &gnmi.Notification{Update: []*gnmi.Update{{Path: gp, Val: v}}}
+}
+
+func stringVal(s string) *gnmi.TypedValue {
+	return &gnmi.TypedValue{Value: &gnmi.TypedValue_StringVal{StringVal: s}}
+}
+
+func TestSessionTable(t *testing.T) {
+	st := &config.SessionTracking{Protocols: []string{config.SessionProtocolBGP, config.SessionProtocolISIS}}
+	tb := newSessionTable()
+	ts := time.Unix(1000, 0)
+	steps := []struct {
+		path  string
+		val   *gnmi.TypedValue
+		event string
+	}{
+		// first state, no transition
+		{path: testBGPNeighbor + "/state/session-state", val: stringVal("ACTIVE")},
+		{path: testBGPNeighbor + "/state/session-state", val: stringVal("CONNECT")},
+		{path: testBGPNeighbor + "/state/session-state", val: &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonIetfVal{JsonIetfVal: []byte(`"openconfig-bgp-types:ESTABLISHED"`)}}, event: sessionTransitionUp},
+		{path: testBGPNeighbor + "/afi-safis/afi-safi[afi-safi-name=openconfig-bgp-types:IPV4_UNICAST]/state/active", val: &gnmi.TypedValue{Value: &gnmi.TypedValue_BoolVal{BoolVal: true}}},
+		{path: testBGPNeighbor + "/afi-safis/afi-safi[afi-safi-name=openconfig-bgp-types:IPV4_UNICAST]/state/prefixes/received", val: &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: 42}}},
+		{path: testISISAdj + "/state/adjacency-state", val: stringVal("UP")},
+		{path: testISISAdj + "/state/adjacency-state", val: stringVal("DOWN"), event: sessionTransitionDown},
+		// not a session state leaf
+		{path: testBGPNeighbor + "/state/peer-as", val: &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: 65001}}},
+	}
+	for i, s := range steps {
+		evs := tb.update(st, "r1", sessionNotification(t, s.path, s.val), ts)
+		if s.event == "" {
+			if len(evs) != 0 {
+				t.Fatalf("step %d: unexpected events %v", i, evs)
+			}
+			continue
+		}
+		if len(evs) != 1 || evs[0].Tags["transition"] != s.event {
+			t.Fatalf("step %d: got events %v, want a %q transition", i, evs, s.event)
+		}
+	}
+	sessions := tb.list(func(*protocolSession) bool { return true })
+	if len(sessions) != 2 {
+		t.Fatalf("got %d sessions, want 2", len(sessions))
+	}
+	bgp, isis := sessions[0], sessions[1]
+	if bgp.Protocol != config.SessionProtocolBGP || bgp.Peer != "10.0.0.1" || bgp.NetworkInstance != "default" ||
+		bgp.State != "ESTABLISHED" || !bgp.Up || bgp.Transitions != 1 {
+		t.Errorf("unexpected BGP session %+v", bgp)
+	}
+	if as := bgp.AfiSafis["IPV4_UNICAST"]; as == nil || !as.Active || as.PrefixesReceived != 42 {
+		t.Errorf("unexpected BGP AFI-SAFIs %+v", bgp.AfiSafis)
+	}
+	if isis.Peer != "0100.0000.0002" || isis.Interface != "ethernet-1/1" || isis.Level != "2" || isis.Up {
+		t.Errorf("unexpected ISIS session %+v", isis)
+	}
+	// deleting the neighbor removes its session
+	gp, _ := path.ParsePath(testBGPNeighbor)
+	tb.update(st, "r1", &gnmi.Notification{Delete: []*gnmi.Path{gp}}, ts)
+	if sessions := tb.list(func(*protocolSession) bool { return true }); len(sessions) != 1 {
+		t.Errorf("got %d sessions after delete, want 1", len(sessions))
+	}
+	// untracked protocol
+	tb.update(&config.SessionTracking{Protocols: []string{config.SessionProtocolISIS}}, "r2",
+		sessionNotification(t, testBGPNeighbor+"/state/session-state", stringVal("IDLE")), ts)
+	if _, ok := tb.sessions["r2"]; ok {
+		t.Errorf("unexpected session of an untracked protocol")
+	}
+}
+
+func TestAPISessions(t *testing.T) {
+	a := newNamespacedApp()
+	if rec := apiRequest(a, http.MethodGet, "/api/v1/sessions", "admin", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("got status %d with session tracking disabled, want 404", rec.Code)
+	}
+	a.Config.SessionTracking = &config.SessionTracking{Protocols: []string{config.SessionProtocolBGP}}
+	for _, tg := range []string{"t1", "t2"} {
+		a.sessions.update(a.Config.SessionTracking, tg,
+			sessionNotification(t, testBGPNeighbor+"/state/session-state", stringVal("ESTABLISHED")), time.Now())
+	}
+	tests := []struct {
+		name    string
+		query   string
+		token   string
+		status  int
+		targets []string
+	}{
+		{name: "admin", token: "admin", status: http.StatusOK, targets: []string{"t1", "t2"}},
+		{name: "namespace", token: "tk1", status: http.StatusOK, targets: []string{"t1"}},
+		{name: "target", query: "?target=t2", token: "admin", status: http.StatusOK, targets: []string{"t2"}},
+		{name: "state_down", query: "?state=down", token: "admin", status: http.StatusOK, targets: []string{}},
+		{name: "invalid_state", query: "?state=x", token: "admin", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := apiRequest(a, http.MethodGet, "/api/v1/sessions"+tt.query, tt.token, "")
+			if rec.Code != tt.status {
+				t.Fatalf("got status %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var sessions []*protocolSession
+			if err := json.Unmarshal(rec.Body.Bytes(), &sessions); err != nil {
+				t.Fatal(err)
+			}
+			if len(sessions) != len(tt.targets) {
+				t.Fatalf("got %d sessions, want %d", len(sessions), len(tt.targets))
+			}
+			for i, s := range sessions {
+				if s.Target != tt.targets[i] {
+					t.Errorf("got target %q, want %q", s.Target, tt.targets[i])
+				}
+			}
+		})
+	}
+}
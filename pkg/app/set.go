@@ -61,19 +61,14 @@ func (a *App) SetRunE(cmd *cobra.Command, args []string) error {
 	}
 	numTargets := len(a.Config.Targets)
 	a.errCh = make(chan error, numTargets*2)
-	a.wg.Add(numTargets)
-	for _, tc := range a.Config.Targets {
-		go a.SetRequest(ctx, tc)
-	}
-	a.wg.Wait()
+	a.runTargets(ctx, a.SetRequest)
 	return a.checkErrors()
 }
 
 func (a *App) SetRequest(ctx context.Context, tc *types.TargetConfig) {
-	defer a.wg.Done()
 	reqs, err := a.Config.CreateSetRequest(tc.Name)
 	if err != nil {
-		a.logError(fmt.Errorf("target %q: failed to create set request: %v", tc.Name, err))
+		a.logTargetError(tc.Name, fmt.Errorf("target %q: failed to create set request: %w", tc.Name, err))
 		return
 	}
 	for _, req := range reqs {
@@ -82,12 +77,17 @@ func (a *App) SetRequest(ctx context.Context, tc *types.TargetConfig) {
 }
 
 func (a *App) setRequest(ctx context.Context, tc *types.TargetConfig, req *gnmi.SetRequest) {
+	err := a.addMasterArbitration(tc, req)
+	if err != nil {
+		a.logTargetError(tc.Name, fmt.Errorf("target %q: %w", tc.Name, err))
+		return
+	}
 	a.Logger.Printf("sending gNMI SetRequest: prefix='%v', delete='%v', replace='%v', update='%v', extension='%v' to %s",
 		req.Prefix, req.Delete, req.Replace, req.Update, req.Extension, tc.Name)
 	if a.Config.PrintRequest || a.Config.SetDryRun {
-		err := a.PrintMsg(tc.Name, "Set Request:", req)
+		err = a.PrintMsg(tc.Name, "Set Request:", req)
 		if err != nil {
-			a.logError(fmt.Errorf("target %q: %v", tc.Name, err))
+			a.logTargetError(tc.Name, fmt.Errorf("target %q: %w", tc.Name, err))
 		}
 	}
 	if a.Config.SetDryRun {
@@ -95,13 +95,29 @@ func (a *App) setRequest(ctx context.Context, tc *types.TargetConfig, req *gnmi.
 	}
 	response, err := a.ClientSet(ctx, tc, req)
 	if err != nil {
-		a.logError(fmt.Errorf("target %q set request failed: %v", tc.Name, err))
+		a.logTargetError(tc.Name, fmt.Errorf("target %q set request failed: %w", tc.Name, err))
 		return
 	}
 	err = a.PrintMsg(tc.Name, "Set Response:", response)
 	if err != nil {
-		a.logError(fmt.Errorf("target %q: %v", tc.Name, err))
+		a.logTargetError(tc.Name, fmt.Errorf("target %q: %w", tc.Name, err))
+	}
+	a.trackCommit(tc.Name, req)
+}
+
+// addMasterArbitration adds the master arbitration extension to req
+// if an election ID is configured and req does not already carry one.
+func (a *App) addMasterArbitration(tc *types.TargetConfig, req *gnmi.SetRequest) error {
+	for _, ext := range req.GetExtension() {
+		if ext.GetMasterArbitration() != nil {
+			return nil
+		}
+	}
+	opt, err := a.Config.MasterArbitrationOpt(tc)
+	if err != nil || opt == nil {
+		return err
 	}
+	return opt(req)
 }
 
 // InitSetFlags used to init or reset setCmd flags for gnmic-prompt mode
@@ -0,0 +1,275 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/openconfig/gnmi/proto/gnmi_ext"
+	"github.com/openconfig/grpctunnel/tunnel"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/openconfig/gnmic/pkg/api"
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+const commitsFileName = ".gnmic.commits"
+
+// commitsFileLock serializes the updates of the pending commits file
+// by the Set requests sent concurrently to multiple targets.
+var commitsFileLock sync.Mutex
+
+// pendingCommit is a commit confirmed transaction started on a target
+// and not yet confirmed or canceled.
+type pendingCommit struct {
+	ID string `json:"id,omitempty"`
+	// time after which the target rolls back the commit,
+	// zero if the rollback duration is the target default.
+	Deadline time.Time `json:"deadline,omitempty"`
+}
+
+type commitAction int
+
+const (
+	commitActionConfirm commitAction = iota
+	commitActionCancel
+)
+
+func (c commitAction) String() string {
+	if c == commitActionCancel {
+		return "cancel"
+	}
+	return "confirm"
+}
+
+func commitsFile() (string, error) {
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, commitsFileName), nil
+}
+
+// readPendingCommits reads the pending commits per target name from file.
+func readPendingCommits(file string) (map[string]*pendingCommit, error) {
+	commits := make(map[string]*pendingCommit)
+	b, err := os.ReadFile(file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return commits, nil
+		}
+		return nil, err
+	}
+	if len(b) == 0 {
+		return commits, nil
+	}
+	err = json.Unmarshal(b, &commits)
+	if err != nil {
+		return nil, err
+	}
+	return commits, nil
+}
+
+func writePendingCommits(file string, commits map[string]*pendingCommit) error {
+	if len(commits) == 0 {
+		err := os.Remove(file)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	b, err := json.MarshalIndent(commits, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, b, 0600)
+}
+
+// commitExtension returns the commit confirmed extension of a SetRequest, if any.
+func commitExtension(req *gnmi.SetRequest) *gnmi_ext.Commit {
+	for _, ext := range req.GetExtension() {
+		if c := ext.GetCommit(); c != nil {
+			return c
+		}
+	}
+	return nil
+}
+
+// updatePendingCommits applies the commit confirmed action of commit,
+// successfully sent to target, to the pending commits.
+// It returns the pending commit of the target after the update, if any.
+func updatePendingCommits(commits map[string]*pendingCommit, target string, commit *gnmi_ext.Commit, now time.Time) *pendingCommit {
+	switch action := commit.GetAction().(type) {
+	case *gnmi_ext.Commit_Commit:
+		pc := &pendingCommit{ID: commit.GetId()}
+		if d := action.Commit.GetRollbackDuration(); d != nil && d.AsDuration() > 0 {
+			pc.Deadline = now.Add(d.AsDuration())
+		}
+		commits[target] = pc
+	case *gnmi_ext.Commit_SetRollbackDuration:
+		pc, ok := commits[target]
+		if !ok || pc.ID != commit.GetId() {
+			pc = &pendingCommit{ID: commit.GetId()}
+			commits[target] = pc
+		}
+		if d := action.SetRollbackDuration.GetRollbackDuration(); d != nil && d.AsDuration() > 0 {
+			pc.Deadline = now.Add(d.AsDuration())
+		} else {
+			pc.Deadline = time.Time{}
+		}
+	case *gnmi_ext.Commit_Confirm, *gnmi_ext.Commit_Cancel:
+		if pc, ok := commits[target]; ok && pc.ID == commit.GetId() {
+			delete(commits, target)
+		}
+	}
+	return commits[target]
+}
+
+// trackCommit records the commit confirmed transaction carried by req,
+// successfully sent to target, in the pending commits file.
+func (a *App) trackCommit(target string, req *gnmi.SetRequest) {
+	commit := commitExtension(req)
+	if commit == nil {
+		return
+	}
+	file, err := commitsFile()
+	if err != nil {
+		a.Logger.Printf("failed to locate the pending commits file: %v", err)
+		return
+	}
+	commitsFileLock.Lock()
+	defer commitsFileLock.Unlock()
+	commits, err := readPendingCommits(file)
+	if err != nil {
+		a.Logger.Printf("failed to read pending commits file %s: %v", file, err)
+		return
+	}
+	pc := updatePendingCommits(commits, target, commit, time.Now())
+	err = writePendingCommits(file, commits)
+	if err != nil {
+		a.Logger.Printf("failed to write pending commits file %s: %v", file, err)
+	}
+	if pc == nil {
+		return
+	}
+	msg := fmt.Sprintf("target %q: commit %q will be rolled back after the target default duration unless confirmed", target, pc.ID)
+	if !pc.Deadline.IsZero() {
+		msg = fmt.Sprintf("target %q: commit %q will be rolled back at %s unless confirmed", target, pc.ID, pc.Deadline.Format(time.RFC3339))
+	}
+	a.Logger.Print(msg)
+	if !a.Config.Log {
+		fmt.Fprintln(os.Stderr, msg)
+	}
+}
+
+func (a *App) SetCommitPreRunE(cmd *cobra.Command, args []string) error {
+	a.Config.SetLocalFlagsFromFile(cmd)
+	a.createCollectorDialOpts()
+	return a.initTunnelServer(tunnel.ServerConfig{
+		AddTargetHandler:    a.tunServerAddTargetHandler,
+		DeleteTargetHandler: a.tunServerDeleteTargetHandler,
+		RegisterHandler:     a.tunServerRegisterHandler,
+		Handler:             a.tunServerHandler,
+	})
+}
+
+func (a *App) SetConfirmRunE(cmd *cobra.Command, args []string) error {
+	defer a.InitSetCommitFlags(cmd)
+	return a.setCommitAction(commitActionConfirm)
+}
+
+func (a *App) SetCancelRunE(cmd *cobra.Command, args []string) error {
+	defer a.InitSetCommitFlags(cmd)
+	return a.setCommitAction(commitActionCancel)
+}
+
+// setCommitAction confirms or cancels the commit set with --commit-id on all targets,
+// if not set, the pending commit of each target is used.
+func (a *App) setCommitAction(action commitAction) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	targetsConfig, err := a.GetTargets()
+	if err != nil {
+		return fmt.Errorf("failed getting targets config: %v", err)
+	}
+	if !a.PromptMode {
+		for _, tc := range targetsConfig {
+			a.AddTargetConfig(tc)
+		}
+	}
+	commits := make(map[string]*pendingCommit)
+	if a.Config.LocalFlags.SetCommitId == "" {
+		file, err := commitsFile()
+		if err != nil {
+			return err
+		}
+		commitsFileLock.Lock()
+		commits, err = readPendingCommits(file)
+		commitsFileLock.Unlock()
+		if err != nil {
+			return fmt.Errorf("failed to read pending commits file %s: %v", file, err)
+		}
+	}
+	numTargets := len(a.Config.Targets)
+	a.errCh = make(chan error, numTargets*2)
+	a.wg.Add(numTargets)
+	for _, tc := range a.Config.Targets {
+		id := a.Config.LocalFlags.SetCommitId
+		if id == "" {
+			pc, ok := commits[tc.Name]
+			if !ok {
+				a.wg.Done()
+				a.logTargetError(tc.Name, fmt.Errorf("target %q: no pending commit found, set the commit ID with --commit-id", tc.Name))
+				continue
+			}
+			id = pc.ID
+			if !pc.Deadline.IsZero() && time.Now().After(pc.Deadline) {
+				a.Logger.Printf("target %q: commit %q rollback time %s has passed, it was probably rolled back",
+					tc.Name, pc.ID, pc.Deadline.Format(time.RFC3339))
+			}
+		}
+		ext := api.Extension_CommitConfirm(id)
+		if action == commitActionCancel {
+			ext = api.Extension_CommitCancel(id)
+		}
+		req, err := api.NewSetRequest(ext)
+		if err != nil {
+			a.wg.Done()
+			a.logTargetError(tc.Name, fmt.Errorf("target %q: failed to create %s commit request: %w", tc.Name, action, err))
+			continue
+		}
+		go func(tc *types.TargetConfig, req *gnmi.SetRequest) {
+			defer a.wg.Done()
+			a.setRequest(ctx, tc, req)
+		}(tc, req)
+	}
+	a.wg.Wait()
+	return a.checkErrors()
+}
+
+// InitSetCommitFlags used to init or reset the set confirm and cancel commands flags for gnmic-prompt mode
+func (a *App) InitSetCommitFlags(cmd *cobra.Command) {
+	cmd.ResetFlags()
+
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.SetCommitId, "commit-id", "", "", "commit ID value, defaults to the commit pending on each target")
+
+	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		a.Config.FileConfig.BindPFlag(fmt.Sprintf("%s-%s", cmd.Name(), flag.Name), flag)
+	})
+}
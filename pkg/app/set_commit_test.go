@@ -0,0 +1,107 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/openconfig/gnmic/pkg/api"
+)
+
+func TestUpdatePendingCommits(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	commits := make(map[string]*pendingCommit)
+	steps := []struct {
+		name string
+		opt  api.GNMIOption
+		want *pendingCommit
+	}{
+		{
+			name: "request",
+			opt:  api.Extension_CommitRequest("c1", time.Minute),
+			want: &pendingCommit{ID: "c1", Deadline: now.Add(time.Minute)},
+		},
+		{
+			name: "set_rollback_duration",
+			opt:  api.Extension_CommitSetRollbackDuration("c1", 10*time.Minute),
+			want: &pendingCommit{ID: "c1", Deadline: now.Add(10 * time.Minute)},
+		},
+		{
+			name: "confirm_other_id",
+			opt:  api.Extension_CommitConfirm("c2"),
+			want: &pendingCommit{ID: "c1", Deadline: now.Add(10 * time.Minute)},
+		},
+		{
+			name: "confirm",
+			opt:  api.Extension_CommitConfirm("c1"),
+		},
+		{
+			name: "request_default_duration",
+			opt:  api.Extension_CommitRequest("c3", 0),
+			want: &pendingCommit{ID: "c3"},
+		},
+		{
+			name: "cancel",
+			opt:  api.Extension_CommitCancel("c3"),
+		},
+	}
+	for _, st := range steps {
+		req, err := api.NewSetRequest(st.opt)
+		if err != nil {
+			t.Fatalf("%s: %v", st.name, err)
+		}
+		got := updatePendingCommits(commits, "t1", commitExtension(req), now)
+		if (got == nil) != (st.want == nil) {
+			t.Fatalf("%s: got %+v, want %+v", st.name, got, st.want)
+		}
+		if got != nil && (got.ID != st.want.ID || !got.Deadline.Equal(st.want.Deadline)) {
+			t.Fatalf("%s: got %+v, want %+v", st.name, got, st.want)
+		}
+	}
+	if commitExtension(&gnmi.SetRequest{}) != nil {
+		t.Errorf("unexpected commit extension in empty request")
+	}
+}
+
+func TestPendingCommitsFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), commitsFileName)
+	commits, err := readPendingCommits(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 0 {
+		t.Fatalf("expected no pending commits, got %v", commits)
+	}
+	deadline := time.Now().Add(time.Minute).Truncate(time.Second)
+	commits["t1"] = &pendingCommit{ID: "c1", Deadline: deadline}
+	err = writePendingCommits(file, commits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commits, err = readPendingCommits(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pc := commits["t1"]; pc == nil || pc.ID != "c1" || !pc.Deadline.Equal(deadline) {
+		t.Fatalf("unexpected pending commits: %v", commits)
+	}
+	// writing no commits removes the file
+	err = writePendingCommits(file, map[string]*pendingCommit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = writePendingCommits(file, map[string]*pendingCommit{})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
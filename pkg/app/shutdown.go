@@ -0,0 +1,250 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+const (
+	defaultDrainTimeout = 10 * time.Second
+	// time given to the inputs and outputs to close
+	// once the pipeline is drained.
+	shutdownCloseTimeout = 5 * time.Second
+)
+
+// ShutdownReport summarizes what happened to the messages
+// still in the pipeline when the shutdown started.
+type ShutdownReport struct {
+	Duration string `json:"duration"`
+	// notifications received from the targets after the drain started
+	RejectedNotifications uint64 `json:"rejected-notifications,omitempty"`
+	// events flushed from the subscriptions event processors buffers
+	FlushedEvents int `json:"flushed-events,omitempty"`
+	// outputs with writes still in flight at the drain timeout
+	PendingWrites []string `json:"pending-writes,omitempty"`
+	// messages still queued per output at the drain timeout
+	DroppedMessages map[string]int `json:"dropped-messages,omitempty"`
+	// drain and close errors per input or output
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// Lossless reports whether all the messages in the pipeline were written.
+func (r *ShutdownReport) Lossless() bool {
+	return r.RejectedNotifications == 0 && len(r.PendingWrites) == 0 && len(r.DroppedMessages) == 0
+}
+
+func (r *ShutdownReport) String() string {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Sprintf("%+v", *r)
+	}
+	return string(b)
+}
+
+// Shutdown stops the targets subscriptions and the inputs, then drains the pipeline:
+// it waits for the in-flight outputs writes, flushes the subscriptions event processors
+// and the outputs queues before closing the outputs.
+// The drain is bounded by timeout, the messages that could not be written are reported.
+func (a *App) Shutdown(timeout time.Duration) *ShutdownReport {
+	start := time.Now()
+	r := &ShutdownReport{
+		DroppedMessages: make(map[string]int),
+		Errors:          make(map[string]string),
+	}
+	a.draining.Store(true)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	a.Logger.Printf("shutting down, draining the pipeline for up to %s", timeout)
+
+	// stop receiving new notifications and messages
+	a.operLock.RLock()
+	targets := make([]string, 0, len(a.Targets))
+	for name := range a.Targets {
+		targets = append(targets, name)
+	}
+	closers := make(map[string]func() error, len(a.Inputs))
+	for name, in := range a.Inputs {
+		closers["input/"+name] = in.Close
+	}
+	a.operLock.RUnlock()
+	for _, name := range targets {
+		if err := a.stopTarget(ctx, name); err != nil {
+			a.Logger.Printf("failed to stop target %q: %v", name, err)
+		}
+	}
+	for name, err := range runBounded(ctx, closers) {
+		r.Errors[name] = err.Error()
+	}
+
+	// wait for the responses queued to the export workers
+	if s := a.exporters.Load(); s != nil && !s.wait(ctx) {
+		r.Errors["export"] = "queued responses not exported"
+	}
+	// wait for the messages already handed to the outputs
+	r.PendingWrites = a.outputWrites.waitAll(ctx)
+	for _, name := range r.PendingWrites {
+		r.Errors["output/"+name] = "in-flight writes not completed"
+	}
+
+	r.FlushedEvents = a.flushSubscriptionsProcessors(ctx)
+
+	// drain the outputs queues
+	a.operLock.Lock()
+	outs := a.Outputs
+	a.Outputs = make(map[string]outputs.Output)
+	a.operLock.Unlock()
+	drainers := make(map[string]func() error)
+	// the drainers that did not return before the timeout may still write to dropped
+	mu := new(sync.Mutex)
+	dropped := make(map[string]int)
+	for name, o := range outs {
+		d, ok := o.(outputs.Drainer)
+		if !ok {
+			continue
+		}
+		name := name
+		drainers["output/"+name] = func() error {
+			n, err := d.Drain(ctx)
+			if n > 0 {
+				mu.Lock()
+				dropped[name] = n
+				mu.Unlock()
+			}
+			return err
+		}
+	}
+	for name, err := range runBounded(ctx, drainers) {
+		r.Errors[name] = err.Error()
+	}
+
+	// close the outputs
+	cctx, ccancel := context.WithTimeout(context.Background(), shutdownCloseTimeout)
+	defer ccancel()
+	closers = make(map[string]func() error, len(outs))
+	for name, o := range outs {
+		closers["output/"+name] = o.Close
+	}
+	for name, err := range runBounded(cctx, closers) {
+		if _, ok := r.Errors[name]; !ok {
+			r.Errors[name] = err.Error()
+		}
+	}
+
+	// the targets and outputs TLS connections are closed,
+	// stop watching the SVIDs updates.
+	if err := a.closeSpiffeSource(); err != nil {
+		r.Errors["spiffe"] = err.Error()
+	}
+
+	mu.Lock()
+	for name, n := range dropped {
+		r.DroppedMessages[name] = n
+	}
+	mu.Unlock()
+	r.RejectedNotifications = a.rejectedNotifications.Load()
+	r.Duration = time.Since(start).String()
+	a.Logger.Printf("shutdown report: %s", r)
+	return r
+}
+
+// flushSubscriptionsProcessors writes the events buffered by the subscriptions
+// event processors to the subscriptions outputs, it returns the number of flushed events.
+func (a *App) flushSubscriptionsProcessors(ctx context.Context) int {
+	a.subProcsLock.Lock()
+	subs := make(map[string][]formatters.EventProcessor, len(a.subProcs))
+	for name, sp := range a.subProcs {
+		subs[name] = sp.evps
+	}
+	a.subProcsLock.Unlock()
+
+	var total int
+	for name, evps := range subs {
+		evs := formatters.FlushEventProcessors(evps)
+		if len(evs) == 0 {
+			continue
+		}
+		total += len(evs)
+		var ns string
+		var outs []string
+		a.configLock.RLock()
+		if sc, ok := a.Config.Subscriptions[name]; ok {
+			ns = sc.Namespace
+			outs = sc.Outputs
+		}
+		a.configLock.RUnlock()
+		runBounded(ctx, map[string]func() error{
+			name: func() error {
+				a.writeOutputs(ctx, "", ns, outs, 0, len(evs), func(_ string, o outputs.Output) (int, int) {
+					for _, ev := range evs {
+						o.WriteEvent(ctx, ev)
+					}
+					return 0, len(evs)
+				})
+				return nil
+			},
+		})
+	}
+	return total
+}
+
+// runBounded calls the functions fns concurrently and waits for them to return,
+// or for ctx to be done. It returns the errors of the functions,
+// and the ctx error for the ones that did not return in time.
+func runBounded(ctx context.Context, fns map[string]func() error) map[string]error {
+	errs := make(map[string]error)
+	if len(fns) == 0 {
+		return errs
+	}
+	mu := new(sync.Mutex)
+	returned := make(map[string]struct{}, len(fns))
+	wg := new(sync.WaitGroup)
+	wg.Add(len(fns))
+	for name, fn := range fns {
+		go func(name string, fn func() error) {
+			defer wg.Done()
+			err := fn()
+			mu.Lock()
+			defer mu.Unlock()
+			returned[name] = struct{}{}
+			if err != nil {
+				errs[name] = err
+			}
+		}(name, fn)
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	result := make(map[string]error, len(errs))
+	for name := range fns {
+		if _, ok := returned[name]; !ok {
+			result[name] = ctx.Err()
+			continue
+		}
+		if err, ok := errs[name]; ok {
+			result[name] = err
+		}
+	}
+	return result
+}
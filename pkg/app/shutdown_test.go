@@ -0,0 +1,119 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+// drainOutput reports queued messages left when drained and records its Close call.
+type drainOutput struct {
+	testOutput
+	queued  int
+	drained atomic.Bool
+	closed  atomic.Bool
+}
+
+func (o *drainOutput) Drain(ctx context.Context) (int, error) {
+	o.drained.Store(true)
+	return o.queued, nil
+}
+
+func (o *drainOutput) Close() error {
+	o.closed.Store(true)
+	return nil
+}
+
+func TestShutdown(t *testing.T) {
+	a := New()
+	a.Config.Subscriptions = map[string]*types.SubscriptionConfig{
+		"sub1": {Name: "sub1", EventProcessors: []string{"group"}, Outputs: []string{"o1"}},
+	}
+	a.Config.Processors = map[string]map[string]interface{}{
+		"group": {
+			"event-group-by": map[string]interface{}{
+				"tags":            []interface{}{"source"},
+				"expected-values": []interface{}{"counter$", "missing$"},
+			},
+		},
+	}
+	o1 := new(drainOutput)
+	o2 := &drainOutput{queued: 3}
+	a.Outputs["o1"] = o1
+	a.Outputs["o2"] = o2
+
+	rsp := &gnmi.SubscribeResponse{
+		Response: &gnmi.SubscribeResponse_Update{
+			Update: &gnmi.Notification{
+				Timestamp: 1,
+				Update: []*gnmi.Update{
+					{
+						Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "counter"}}},
+						Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_IntVal{IntVal: 42}},
+					},
+				},
+			},
+		},
+	}
+	ctx := context.Background()
+	a.Export(ctx, rsp, outputs.Meta{"source": "r1", "subscription-name": "sub1"})
+	if len(o1.events) != 0 {
+		t.Fatalf("expected the incomplete group to be buffered, got %v", o1.events)
+	}
+
+	r := a.Shutdown(time.Second)
+	if r.FlushedEvents != 1 || len(o1.events) != 1 || o1.events[0].Values["/counter"] == nil {
+		t.Errorf("expected the buffered group to be flushed to o1, report=%s events=%v", r, o1.events)
+	}
+	if !reflect.DeepEqual(r.DroppedMessages, map[string]int{"o2": 3}) || r.Lossless() {
+		t.Errorf("unexpected dropped messages: %s", r)
+	}
+	if !o1.drained.Load() || !o2.drained.Load() || !o1.closed.Load() || !o2.closed.Load() {
+		t.Errorf("expected the outputs to be drained and closed")
+	}
+	if len(a.Outputs) != 0 {
+		t.Errorf("expected the outputs to be removed, got %v", a.Outputs)
+	}
+
+	// notifications received after the shutdown started are rejected.
+	a.Export(ctx, rsp, outputs.Meta{"source": "r1", "subscription-name": "sub1"})
+	if n := a.rejectedNotifications.Load(); n != 1 {
+		t.Errorf("expected 1 rejected notification, got %d", n)
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	a := New()
+	// the write is never released.
+	o := &blockingOutput{writing: make(chan struct{}), release: make(chan struct{})}
+	a.Outputs["o1"] = o
+
+	go a.Export(context.Background(), &gnmi.SubscribeResponse{
+		Response: &gnmi.SubscribeResponse_Update{Update: &gnmi.Notification{}},
+	}, outputs.Meta{"source": "r1"}, "o1")
+	<-o.writing
+
+	start := time.Now()
+	r := a.Shutdown(50 * time.Millisecond)
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("shutdown not bounded by the drain timeout: %s", d)
+	}
+	if !reflect.DeepEqual(r.PendingWrites, []string{"o1"}) || r.Lossless() {
+		t.Errorf("expected pending writes to o1: %s", r)
+	}
+}
@@ -0,0 +1,303 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"github.com/openconfig/gnmic/pkg/lockers"
+	"github.com/openconfig/gnmic/pkg/silences"
+)
+
+// expired silences are kept this long before being purged.
+const silencesRetention = 24 * time.Hour
+
+const (
+	silenceStatusPending = "pending"
+	silenceStatusActive  = "active"
+	silenceStatusExpired = "expired"
+)
+
+var errInvalidSilence = errors.New("invalid silence")
+
+// silenceRequest is the body of a silence creation or update request,
+// the silence end is set either with EndsAt or with Duration.
+type silenceRequest struct {
+	ID        string              `json:"id,omitempty"`
+	Matchers  []*silences.Matcher `json:"matchers,omitempty"`
+	StartsAt  time.Time           `json:"starts-at,omitempty"`
+	EndsAt    time.Time           `json:"ends-at,omitempty"`
+	Duration  string              `json:"duration,omitempty"`
+	CreatedBy string              `json:"created-by,omitempty"`
+	Comment   string              `json:"comment,omitempty"`
+}
+
+type silenceState struct {
+	*silences.Silence
+	Status string `json:"status"`
+}
+
+func newSilenceState(s *silences.Silence, now time.Time) *silenceState {
+	st := &silenceState{Silence: s, Status: silenceStatusActive}
+	switch {
+	case s.Expired(now):
+		st.Status = silenceStatusExpired
+	case now.Before(s.StartsAt):
+		st.Status = silenceStatusPending
+	}
+	return st
+}
+
+// silence builds the silence from the request, generating its ID if not set.
+func (sr *silenceRequest) silence(now time.Time) (*silences.Silence, error) {
+	s := &silences.Silence{
+		ID:        sr.ID,
+		Matchers:  sr.Matchers,
+		StartsAt:  sr.StartsAt,
+		EndsAt:    sr.EndsAt,
+		CreatedBy: sr.CreatedBy,
+		Comment:   sr.Comment,
+	}
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	if s.StartsAt.IsZero() {
+		s.StartsAt = now
+	}
+	if sr.Duration != "" {
+		if !s.EndsAt.IsZero() {
+			return nil, fmt.Errorf("%w: only one of ends-at or duration can be set", errInvalidSilence)
+		}
+		d, err := time.ParseDuration(sr.Duration)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errInvalidSilence, err)
+		}
+		s.EndsAt = s.StartsAt.Add(d)
+	}
+	err := s.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidSilence, err)
+	}
+	return s, nil
+}
+
+func (a *App) silencesKey() string {
+	return fmt.Sprintf("gnmic/%s/silences", a.Config.Clustering.ClusterName)
+}
+
+// silencesKV returns the locker KV store the silences are persisted to,
+// nil if the locker is not set or cannot store values.
+func (a *App) silencesKV() lockers.KV {
+	if a.locker == nil || a.Config.Clustering == nil {
+		return nil
+	}
+	kv, _ := a.locker.(lockers.KV)
+	return kv
+}
+
+// syncSilences replaces the local silences with the ones persisted in the locker,
+// it assumes the silences lock is acquired.
+func (a *App) syncSilences(ctx context.Context) error {
+	kv := a.silencesKV()
+	if kv == nil {
+		return nil
+	}
+	b, err := kv.Get(ctx, a.silencesKey())
+	if err != nil {
+		return err
+	}
+	if len(b) == 0 {
+		return nil
+	}
+	var ss []*silences.Silence
+	err = json.Unmarshal(b, &ss)
+	if err != nil {
+		return fmt.Errorf("failed to decode silences: %w", err)
+	}
+	return a.silences.Replace(ss)
+}
+
+// persistSilences purges the silences expired for longer than silencesRetention
+// and writes the remaining ones to the locker, it assumes the silences lock is acquired.
+func (a *App) persistSilences(ctx context.Context, now time.Time) error {
+	ss := a.silences.List()
+	rs := ss[:0]
+	for _, s := range ss {
+		if s.Expired(now.Add(-silencesRetention)) {
+			a.silences.Delete(s.ID)
+			continue
+		}
+		rs = append(rs, s)
+	}
+	kv := a.silencesKV()
+	if kv == nil {
+		return nil
+	}
+	b, err := json.Marshal(rs)
+	if err != nil {
+		return err
+	}
+	err = kv.Put(ctx, a.silencesKey(), b)
+	if err != nil {
+		return fmt.Errorf("failed to persist silences: %w", err)
+	}
+	return nil
+}
+
+// updateSilences applies fn to the latest silences and persists the result.
+func (a *App) updateSilences(ctx context.Context, fn func(now time.Time) error) error {
+	a.silencesLock.Lock()
+	defer a.silencesLock.Unlock()
+	err := a.syncSilences(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to sync silences: %w", err)
+	}
+	now := time.Now()
+	err = fn(now)
+	if err != nil {
+		return err
+	}
+	return a.persistSilences(ctx, now)
+}
+
+// setSilence creates or updates the silence s.
+func (a *App) setSilence(ctx context.Context, s *silences.Silence, update bool) error {
+	return a.updateSilences(ctx, func(time.Time) error {
+		if _, ok := a.silences.Get(s.ID); update && !ok {
+			return fmt.Errorf("silence %q %w", s.ID, errNotFound)
+		}
+		return a.silences.Set(s)
+	})
+}
+
+// expireSilence ends the silence with the given ID now,
+// a silence that did not start yet is removed.
+func (a *App) expireSilence(ctx context.Context, id string) error {
+	return a.updateSilences(ctx, func(now time.Time) error {
+		s, ok := a.silences.Get(id)
+		if !ok {
+			return fmt.Errorf("silence %q %w", id, errNotFound)
+		}
+		if s.Expired(now) {
+			return nil
+		}
+		if !s.StartsAt.Before(now) {
+			a.silences.Delete(id)
+			return nil
+		}
+		// the stored silence might be read concurrently, replace it with a copy.
+		es := *s
+		es.EndsAt = now
+		return a.silences.Set(&es)
+	})
+}
+
+// watchSilences keeps the local silences in sync with the ones
+// created by the other cluster members.
+func (a *App) watchSilences(ctx context.Context) {
+	if a.silencesKV() == nil {
+		return
+	}
+	ticker := time.NewTicker(a.Config.Clustering.LocksWatchTimer)
+	defer ticker.Stop()
+	for {
+		a.silencesLock.Lock()
+		err := a.syncSilences(ctx)
+		a.silencesLock.Unlock()
+		if err != nil {
+			a.Logger.Printf("failed to sync silences: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *App) handleSilencesGet(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		ss := a.silences.List()
+		rs := make([]*silenceState, 0, len(ss))
+		for _, s := range ss {
+			rs = append(rs, newSilenceState(s, now))
+		}
+		a.handlerCommonGet(w, rs)
+		return
+	}
+	s, ok := a.silences.Get(id)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{fmt.Sprintf("silence %q not found", id)}})
+		return
+	}
+	a.handlerCommonGet(w, newSilenceState(s, now))
+}
+
+func (a *App) handleSilencesPost(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	defer r.Body.Close()
+	sr := new(silenceRequest)
+	err = json.Unmarshal(body, sr)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	now := time.Now()
+	s, err := sr.silence(now)
+	if err == nil {
+		err = a.setSilence(r.Context(), s, sr.ID != "")
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, errNotFound):
+			w.WriteHeader(http.StatusNotFound)
+		case errors.Is(err, errInvalidSilence):
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	a.Logger.Printf("silence %q set by %q until %s", s.ID, s.CreatedBy, s.EndsAt)
+	a.handlerCommonGet(w, newSilenceState(s, now))
+}
+
+func (a *App) handleSilencesDelete(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	err := a.expireSilence(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, errNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	a.Logger.Printf("silence %q expired", id)
+}
@@ -0,0 +1,151 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/lockers"
+	"github.com/openconfig/gnmic/pkg/silences"
+)
+
+// kvLocker is a locker only implementing the KV interface.
+type kvLocker struct {
+	lockers.Locker
+	m    sync.Mutex
+	vals map[string][]byte
+}
+
+func (l *kvLocker) Get(_ context.Context, key string) ([]byte, error) {
+	l.m.Lock()
+	defer l.m.Unlock()
+	return l.vals[key], nil
+}
+
+func (l *kvLocker) Put(_ context.Context, key string, val []byte) error {
+	l.m.Lock()
+	defer l.m.Unlock()
+	l.vals[key] = val
+	return nil
+}
+
+func newSilencesApp(t *testing.T, kv *kvLocker) *App {
+	a := New()
+	a.silences = silences.NewStore()
+	a.Config.FileConfig.Set("clustering", map[string]interface{}{
+		"cluster-name": "c1",
+		"locker": map[string]interface{}{
+			"type": "consul",
+		},
+	})
+	err := a.Config.GetClustering()
+	if err != nil {
+		t.Fatalf("failed to get clustering config: %v", err)
+	}
+	a.locker = kv
+	a.routes()
+	return a
+}
+
+func silencesRequest(a *App, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	a.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestSilencesAPI(t *testing.T) {
+	kv := &kvLocker{vals: make(map[string][]byte)}
+	a := newSilencesApp(t, kv)
+
+	w := silencesRequest(a, http.MethodPost, "/api/v1/silences",
+		`{"matchers":[{"name":"source","value":"r1"}],"duration":"1h","comment":"upgrade"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", w.Code, w.Body.String())
+	}
+	created := new(silenceState)
+	err := json.Unmarshal(w.Body.Bytes(), created)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID == "" || created.Status != silenceStatusActive {
+		t.Fatalf("unexpected silence: %+v", created)
+	}
+	if a.silences.Match(map[string]string{"source": "r1"}, nil) == nil {
+		t.Errorf("silence not applied")
+	}
+	if len(kv.vals["gnmic/c1/silences"]) == 0 {
+		t.Errorf("silence not persisted")
+	}
+
+	// another instance sharing the locker gets the silence
+	b := newSilencesApp(t, kv)
+	b.silencesLock.Lock()
+	err = b.syncSilences(context.Background())
+	b.silencesLock.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w = silencesRequest(b, http.MethodGet, "/api/v1/silences/"+created.ID, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("silence not synced: %d: %s", w.Code, w.Body.String())
+	}
+
+	// expire it from the other instance
+	w = silencesRequest(b, http.MethodDelete, "/api/v1/silences/"+created.ID, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", w.Code, w.Body.String())
+	}
+	a.silencesLock.Lock()
+	err = a.syncSilences(context.Background())
+	a.silencesLock.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.silences.Match(map[string]string{"source": "r1"}, nil) != nil {
+		t.Errorf("expired silence still applied")
+	}
+	w = silencesRequest(a, http.MethodGet, "/api/v1/silences", "")
+	var ss []*silenceState
+	err = json.Unmarshal(w.Body.Bytes(), &ss)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ss) != 1 || ss[0].Status != silenceStatusExpired {
+		t.Errorf("unexpected silences list: %s", w.Body.String())
+	}
+}
+
+func TestSilencesAPIErrors(t *testing.T) {
+	a := newSilencesApp(t, &kvLocker{vals: make(map[string][]byte)})
+	for name, tc := range map[string]struct {
+		method, path, body string
+		code               int
+	}{
+		"no_matchers":    {http.MethodPost, "/api/v1/silences", `{"duration":"1h"}`, http.StatusBadRequest},
+		"no_end":         {http.MethodPost, "/api/v1/silences", `{"matchers":[{"name":"source","value":"r1"}]}`, http.StatusBadRequest},
+		"end_and_dur":    {http.MethodPost, "/api/v1/silences", `{"matchers":[{"name":"source","value":"r1"}],"duration":"1h","ends-at":"` + time.Now().Add(time.Hour).Format(time.RFC3339) + `"}`, http.StatusBadRequest},
+		"bad_regex":      {http.MethodPost, "/api/v1/silences", `{"matchers":[{"name":"source","value":"(","is-regex":true}],"duration":"1h"}`, http.StatusBadRequest},
+		"unknown_id":     {http.MethodPost, "/api/v1/silences", `{"id":"x","matchers":[{"name":"source","value":"r1"}],"duration":"1h"}`, http.StatusNotFound},
+		"get_unknown":    {http.MethodGet, "/api/v1/silences/x", "", http.StatusNotFound},
+		"expire_unknown": {http.MethodDelete, "/api/v1/silences/x", "", http.StatusNotFound},
+	} {
+		w := silencesRequest(a, tc.method, tc.path, tc.body)
+		if w.Code != tc.code {
+			t.Errorf("%s: got status %d, want %d: %s", name, w.Code, tc.code, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,312 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/itchyny/gojq"
+	"github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/openconfig/gnmic/pkg/config"
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+const (
+	// number of buckets the SLO windows are split in
+	sloBuckets = 60
+
+	sloEventName    = "slo"
+	sloStateBurning = "burning"
+	sloStateOK      = "ok"
+)
+
+// sloState holds the evaluation state of an SLO.
+type sloState struct {
+	name       string
+	cfg        *config.SLO
+	code       *gojq.Code
+	valueNames []*regexp.Regexp
+	m          sync.Mutex
+	// series per source
+	series map[string]*sloSeries
+}
+
+// sloSeries counts the samples of a source over the SLO window and burn rate windows.
+type sloSeries struct {
+	window    *sloRing
+	burnRates []*sloRing
+	// true if the burn rate over the corresponding window is above the threshold
+	burning []bool
+}
+
+// sloRing counts the good and total samples over a sliding window
+// split in sloBuckets buckets.
+type sloRing struct {
+	width   int64
+	buckets [sloBuckets]sloBucket
+}
+
+type sloBucket struct {
+	epoch       int64
+	good, total uint64
+}
+
+func newSLOState(name string, cfg *config.SLO) (*sloState, error) {
+	code, err := formatters.CompileJQ(cfg.Condition)
+	if err != nil {
+		return nil, err
+	}
+	s := &sloState{
+		name:       name,
+		cfg:        cfg,
+		code:       code,
+		valueNames: make([]*regexp.Regexp, 0, len(cfg.ValueNames)),
+		series:     make(map[string]*sloSeries),
+	}
+	for _, vn := range cfg.ValueNames {
+		re, err := regexp.Compile(vn)
+		if err != nil {
+			return nil, err
+		}
+		s.valueNames = append(s.valueNames, re)
+	}
+	return s, nil
+}
+
+// sloStates returns the evaluation state of the configured SLOs.
+// The state is created on first use and re-created if the SLO configuration changes.
+func (a *App) sloStates() []*sloState {
+	a.configLock.RLock()
+	defer a.configLock.RUnlock()
+	if len(a.Config.SLOs) == 0 {
+		return nil
+	}
+	a.slosLock.Lock()
+	defer a.slosLock.Unlock()
+	res := make([]*sloState, 0, len(a.Config.SLOs))
+	for name, cfg := range a.Config.SLOs {
+		s, ok := a.slos[name]
+		if !ok || s.cfg != cfg {
+			var err error
+			s, err = newSLOState(name, cfg)
+			if err != nil {
+				a.Logger.Printf("slo %q: %v", name, err)
+				continue
+			}
+			a.slos[name] = s
+		}
+		res = append(res, s)
+	}
+	return res
+}
+
+// evaluateSLOs counts the values of rsp selected by the SLOs as good or bad samples.
+// An event is written to the SLO outputs each time a burn rate crosses the SLO threshold.
+func (a *App) evaluateSLOs(ctx context.Context, rsp *gnmi.SubscribeResponse, m outputs.Meta, now time.Time) {
+	if rsp.GetUpdate() == nil {
+		return
+	}
+	slos := a.sloStates()
+	if len(slos) == 0 {
+		return
+	}
+	evs, err := formatters.ResponseToEventMsgs(m["subscription-name"], rsp, m)
+	if err != nil {
+		a.Logger.Printf("target %q: failed to convert response to SLO samples: %v", m["source"], err)
+		return
+	}
+	for _, s := range slos {
+		sevs, err := s.evaluate(m["source"], evs, now)
+		if err != nil && a.Config.Debug {
+			a.Logger.Printf("slo %q: failed to evaluate condition: %v", s.name, err)
+		}
+		for _, ev := range sevs {
+			a.Logger.Printf("slo %q: target %q burn rate over %s is %s: %.2f",
+				s.name, m["source"], ev.Tags["window"], ev.Tags["state"], ev.Values["burn-rate"])
+			if len(s.cfg.Outputs) == 0 {
+				continue
+			}
+			ev := ev
+			go a.writeOutputs(ctx, m["source"], a.targetNamespace(m["source"]), s.cfg.Outputs, 0, 1, func(name string, o outputs.Output) (int, int) {
+				o.WriteEvent(ctx, ev)
+				return 0, 1
+			})
+		}
+	}
+}
+
+// evaluate adds the values of evs selected by the SLO to the samples of source,
+// and updates the SLO metrics.
+// It returns the events of the burn rate windows crossing the threshold,
+// and the last condition evaluation error.
+func (s *sloState) evaluate(source string, evs []*formatters.EventMsg, now time.Time) ([]*formatters.EventMsg, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	var err error
+	var sr *sloSeries
+	nowNano := now.UnixNano()
+	for _, ev := range evs {
+		ts := ev.Timestamp
+		if ts == 0 {
+			ts = nowNano
+		}
+		for vn, v := range ev.Values {
+			if !s.selects(vn) {
+				continue
+			}
+			good, cerr := formatters.CheckCondition(s.code, &formatters.EventMsg{
+				Name:      ev.Name,
+				Timestamp: ev.Timestamp,
+				Tags:      ev.Tags,
+				Values:    map[string]interface{}{vn: v},
+			})
+			if cerr != nil {
+				err = cerr
+				continue
+			}
+			if sr == nil {
+				sr = s.seriesOf(source)
+			}
+			sr.window.add(ts, nowNano, good)
+			for _, r := range sr.burnRates {
+				r.add(ts, nowNano, good)
+			}
+			result := "good"
+			if !good {
+				result = "bad"
+			}
+			sloSamples.WithLabelValues(s.name, source, result).Inc()
+		}
+	}
+	if sr == nil {
+		return nil, err
+	}
+	return s.update(source, sr, nowNano), err
+}
+
+func (s *sloState) selects(valueName string) bool {
+	for _, re := range s.valueNames {
+		if re.MatchString(valueName) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *sloState) seriesOf(source string) *sloSeries {
+	sr, ok := s.series[source]
+	if ok {
+		return sr
+	}
+	sr = &sloSeries{
+		window:    newSLORing(s.cfg.Window),
+		burnRates: make([]*sloRing, 0, len(s.cfg.BurnRateWindows)),
+		burning:   make([]bool, len(s.cfg.BurnRateWindows)),
+	}
+	for _, w := range s.cfg.BurnRateWindows {
+		sr.burnRates = append(sr.burnRates, newSLORing(w))
+	}
+	s.series[source] = sr
+	return sr
+}
+
+// update sets the SLO metrics of source and returns an event
+// for each burn rate window crossing the threshold.
+func (s *sloState) update(source string, sr *sloSeries, now int64) []*formatters.EventMsg {
+	sli, windowBurnRate := s.rates(sr.window, now)
+	remaining := 1 - windowBurnRate
+	sloSLIRatio.WithLabelValues(s.name, source).Set(sli)
+	sloErrorBudgetRemaining.WithLabelValues(s.name, source).Set(remaining)
+	var evs []*formatters.EventMsg
+	for i, r := range sr.burnRates {
+		window := s.cfg.BurnRateWindows[i].String()
+		_, br := s.rates(r, now)
+		sloBurnRate.WithLabelValues(s.name, source, window).Set(br)
+		burning := br > s.cfg.BurnRateThreshold
+		if burning == sr.burning[i] {
+			continue
+		}
+		sr.burning[i] = burning
+		state := sloStateOK
+		if burning {
+			state = sloStateBurning
+		}
+		evs = append(evs, &formatters.EventMsg{
+			Name:      sloEventName,
+			Timestamp: now,
+			Tags: map[string]string{
+				"source": source,
+				"slo":    s.name,
+				"window": window,
+				"state":  state,
+			},
+			Values: map[string]interface{}{
+				"burn-rate":              br,
+				"sli":                    sli,
+				"error-budget-remaining": remaining,
+			},
+		})
+	}
+	return evs
+}
+
+// rates returns the ratio of good samples of the ring r,
+// and the rate at which they consume the error budget.
+// A burn rate of 1 consumes exactly the error budget over the SLO window.
+func (s *sloState) rates(r *sloRing, now int64) (float64, float64) {
+	good, total := r.counts(now)
+	if total == 0 {
+		return 1, 0
+	}
+	sli := float64(good) / float64(total)
+	return sli, (1 - sli) / (1 - s.cfg.Objective/100)
+}
+
+func newSLORing(window time.Duration) *sloRing {
+	width := window.Nanoseconds() / sloBuckets
+	if width == 0 {
+		width = 1
+	}
+	return &sloRing{width: width}
+}
+
+// add counts a sample received at ts,
+// the samples older than the window ending at now are ignored.
+func (r *sloRing) add(ts, now int64, good bool) {
+	epoch := ts / r.width
+	if ts < 0 || epoch <= now/r.width-sloBuckets {
+		return
+	}
+	b := &r.buckets[epoch%sloBuckets]
+	if b.epoch != epoch {
+		*b = sloBucket{epoch: epoch}
+	}
+	b.total++
+	if good {
+		b.good++
+	}
+}
+
+// counts returns the number of good and total samples in the window ending at now.
+func (r *sloRing) counts(now int64) (uint64, uint64) {
+	cur := now / r.width
+	var good, total uint64
+	for _, b := range r.buckets {
+		if b.epoch > cur-sloBuckets && b.epoch <= cur {
+			good += b.good
+			total += b.total
+		}
+	}
+	return good, total
+}
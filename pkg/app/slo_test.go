@@ -0,0 +1,99 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/config"
+	"github.com/openconfig/gnmic/pkg/formatters"
+)
+
+func TestSLORing(t *testing.T) {
+	r := newSLORing(time.Hour)
+	now := time.Unix(10000, 0)
+	r.add(now.UnixNano(), now.UnixNano(), true)
+	r.add(now.Add(-30*time.Minute).UnixNano(), now.UnixNano(), false)
+	// out of the window
+	r.add(now.Add(-2*time.Hour).UnixNano(), now.UnixNano(), false)
+	if good, total := r.counts(now.UnixNano()); good != 1 || total != 2 {
+		t.Errorf("got %d/%d good samples, want 1/2", good, total)
+	}
+	// the samples slide out of the window
+	later := now.Add(40 * time.Minute).UnixNano()
+	if good, total := r.counts(later); good != 1 || total != 1 {
+		t.Errorf("got %d/%d good samples, want 1/1", good, total)
+	}
+	if _, total := r.counts(now.Add(2 * time.Hour).UnixNano()); total != 0 {
+		t.Errorf("got %d samples, want 0", total)
+	}
+}
+
+func TestSLOEvaluate(t *testing.T) {
+	cfg := &config.SLO{
+		ValueNames:        []string{"in-errors$"},
+		Condition:         ".values[] == 0",
+		Objective:         90,
+		Window:            24 * time.Hour,
+		BurnRateWindows:   []time.Duration{time.Hour},
+		BurnRateThreshold: 2,
+	}
+	s, err := newSLOState("errors", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(100000, 0)
+	sample := func(v int) []*formatters.EventMsg {
+		return []*formatters.EventMsg{{
+			Name:      "sub1",
+			Timestamp: now.UnixNano(),
+			Tags:      map[string]string{"source": "r1", "interface_name": "e1"},
+			Values: map[string]interface{}{
+				"/interfaces/interface/state/counters/in-errors": v,
+				"/interfaces/interface/state/counters/in-octets": 100,
+			},
+		}}
+	}
+	for i := 0; i < 8; i++ {
+		evs, err := s.evaluate("r1", sample(0), now)
+		if err != nil || len(evs) != 0 {
+			t.Fatalf("unexpected events %v or error %v", evs, err)
+		}
+	}
+	// 3 bad samples out of 11: 27% errors, burn rate 2.7
+	var evs []*formatters.EventMsg
+	for i := 0; i < 3; i++ {
+		rEvs, err := s.evaluate("r1", sample(1), now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		evs = append(evs, rEvs...)
+	}
+	if len(evs) != 1 || evs[0].Tags["state"] != sloStateBurning || evs[0].Tags["window"] != "1h0m0s" {
+		t.Fatalf("expected a single burning event, got %v", evs)
+	}
+	// the bad samples leave the burn rate window, not the SLO window
+	now = now.Add(2 * time.Hour)
+	evs, err = s.evaluate("r1", sample(0), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evs) != 1 || evs[0].Tags["state"] != sloStateOK || evs[0].Values["burn-rate"] != 0.0 {
+		t.Fatalf("expected a single ok event, got %v", evs)
+	}
+	sli, br := s.rates(s.series["r1"].window, now.UnixNano())
+	if sli != 0.75 || math.Abs(br-2.5) > 1e-9 {
+		t.Errorf("got sli %v and burn rate %v, want 0.75 and 2.5", sli, br)
+	}
+	if _, ok := s.series["r2"]; ok {
+		t.Errorf("unexpected series for target r2")
+	}
+}
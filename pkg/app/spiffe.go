@@ -0,0 +1,124 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/spiffetls/tlsconfig"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+
+	"github.com/openconfig/gnmic/pkg/config"
+)
+
+// getSpiffeSource returns the X509 source connected to the SPIFFE Workload API,
+// it is created on first use.
+// The source keeps the SVIDs and trust bundles up to date, so the TLS configs built from it
+// pick up rotated certificates without any restart.
+func (a *App) getSpiffeSource(ctx context.Context) (*workloadapi.X509Source, error) {
+	if a.Config.Spiffe == nil {
+		return nil, nil
+	}
+	a.spiffeLock.Lock()
+	defer a.spiffeLock.Unlock()
+	if a.spiffeSource != nil {
+		return a.spiffeSource, nil
+	}
+	clientOpts := make([]workloadapi.ClientOption, 0, 1)
+	if a.Config.Spiffe.SocketPath != "" {
+		clientOpts = append(clientOpts, workloadapi.WithAddr(a.Config.Spiffe.SocketPath))
+	}
+	ctx, cancel := context.WithTimeout(ctx, a.Config.Spiffe.Timeout)
+	defer cancel()
+	a.Logger.Printf("fetching X509-SVID from the SPIFFE Workload API")
+	src, err := workloadapi.NewX509Source(ctx, workloadapi.WithClientOptions(clientOpts...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SPIFFE X509 source: %w", err)
+	}
+	svid, err := src.GetX509SVID()
+	if err != nil {
+		src.Close()
+		return nil, fmt.Errorf("failed to get X509-SVID: %w", err)
+	}
+	a.Logger.Printf("received X509-SVID with SPIFFE ID %q", svid.ID)
+	a.spiffeSource = src
+	return src, nil
+}
+
+// closeSpiffeSource closes the X509 source, if it was created,
+// stopping its Workload API watch.
+func (a *App) closeSpiffeSource() error {
+	a.spiffeLock.Lock()
+	defer a.spiffeLock.Unlock()
+	if a.spiffeSource == nil {
+		return nil
+	}
+	err := a.spiffeSource.Close()
+	a.spiffeSource = nil
+	return err
+}
+
+// spiffeAuthorizer builds a tlsconfig.Authorizer from the configured peers.
+// Explicit IDs are preferred, then the trust domain, then any SPIFFE ID.
+func (a *App) spiffeAuthorizer(sp *config.SpiffePeers) (tlsconfig.Authorizer, error) {
+	if ids := sp.IDs(); len(ids) > 0 {
+		return tlsconfig.AuthorizeOneOf(ids...), nil
+	}
+	if a.Config.Spiffe.TrustDomain != "" {
+		td, err := spiffeid.TrustDomainFromString(a.Config.Spiffe.TrustDomain)
+		if err != nil {
+			return nil, err
+		}
+		return tlsconfig.AuthorizeMemberOf(td), nil
+	}
+	return tlsconfig.AuthorizeAny(), nil
+}
+
+// spiffeServerTLSConfig returns an mTLS server config using the workload SVID,
+// clients are authorized based on their SPIFFE ID.
+func (a *App) spiffeServerTLSConfig(ctx context.Context, sp *config.SpiffePeers) (*tls.Config, error) {
+	src, err := a.getSpiffeSource(ctx)
+	if err != nil {
+		return nil, err
+	}
+	authorizer, err := a.spiffeAuthorizer(sp)
+	if err != nil {
+		return nil, err
+	}
+	return tlsconfig.MTLSServerConfig(src, src, authorizer), nil
+}
+
+// spiffeClientTLSConfig returns an mTLS client config using the workload SVID,
+// servers are authorized based on their SPIFFE ID.
+func (a *App) spiffeClientTLSConfig(ctx context.Context, sp *config.SpiffePeers) (*tls.Config, error) {
+	src, err := a.getSpiffeSource(ctx)
+	if err != nil {
+		return nil, err
+	}
+	authorizer, err := a.spiffeAuthorizer(sp)
+	if err != nil {
+		return nil, err
+	}
+	return tlsconfig.MTLSClientConfig(src, src, authorizer), nil
+}
+
+func (a *App) apiServerUsesSpiffe() bool {
+	return a.Config.Spiffe != nil && a.Config.Spiffe.APIServer != nil
+}
+
+func (a *App) gnmiServerUsesSpiffe() bool {
+	return a.Config.Spiffe != nil && a.Config.Spiffe.GNMIServer != nil
+}
+
+func (a *App) targetsUseSpiffe() bool {
+	return a.Config.Spiffe != nil && a.Config.Spiffe.Targets != nil
+}
@@ -0,0 +1,283 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/api/target"
+	"github.com/openconfig/gnmic/pkg/config"
+)
+
+// events drop reasons
+const (
+	dropReasonDecodeError     = "decode-error"
+	dropReasonConversionError = "conversion-error"
+	dropReasonUnknownOutput   = "unknown-output"
+	dropReasonCanceled        = "canceled"
+	dropReasonPolicy          = "policy"
+	dropReasonClockSkew       = "clock-skew"
+	dropReasonShutdown        = "shutdown"
+	dropReasonDisabled        = "disabled"
+	dropReasonTargetSelector  = "target-selector"
+)
+
+// stats tracks the number of notifications and events handled per target and per output.
+// The same values are exposed as Prometheus metrics and through the /api/v1/stats endpoint.
+type stats struct {
+	m       *sync.Mutex
+	targets map[string]*targetStats
+	outputs map[string]*outputStats
+}
+
+type targetStats struct {
+	ReceivedNotifications uint64            `json:"received-notifications"`
+	ConvertedEvents       uint64            `json:"converted-events"`
+	DroppedEvents         map[string]uint64 `json:"dropped-events,omitempty"`
+	SubscriptionErrors    uint64            `json:"subscription-errors,omitempty"`
+	// responses classified as high priority by the qos rules
+	PriorityNotifications uint64 `json:"priority-notifications,omitempty"`
+	// notifications and events with a timestamp out of an output bounds
+	SkewedTimestamps uint64 `json:"skewed-timestamps,omitempty"`
+	// receive time minus timestamp of the last notification
+	ClockSkew string `json:"clock-skew,omitempty"`
+	// notifications with a clock skew above the target threshold,
+	// and the ones with a timestamp rewritten to their receive time.
+	ClockSkewExceeded   uint64 `json:"clock-skew-exceeded,omitempty"`
+	CorrectedTimestamps uint64 `json:"corrected-timestamps,omitempty"`
+	// bytes sent and received over the target gRPC connection
+	Bytes *target.CompressionStats `json:"bytes,omitempty"`
+
+	lastNotification time.Time
+	clockSkew        time.Duration
+}
+
+type outputStats struct {
+	WrittenMessages uint64            `json:"written-messages"`
+	WrittenEvents   uint64            `json:"written-events"`
+	DroppedEvents   map[string]uint64 `json:"dropped-events,omitempty"`
+	WriteLatency    *writeLatency     `json:"write-latency,omitempty"`
+	ThrottledWrites uint64            `json:"throttled-writes,omitempty"`
+
+	writes       uint64
+	totalLatency time.Duration
+	maxLatency   time.Duration
+}
+
+type writeLatency struct {
+	Count   uint64 `json:"count"`
+	Average string `json:"average"`
+	Max     string `json:"max"`
+}
+
+type statsResponse struct {
+	Targets map[string]*targetStats `json:"targets"`
+	Outputs map[string]*outputStats `json:"outputs"`
+}
+
+func newStats() *stats {
+	return &stats{
+		m:       new(sync.Mutex),
+		targets: make(map[string]*targetStats),
+		outputs: make(map[string]*outputStats),
+	}
+}
+
+func (s *stats) target(name string) *targetStats {
+	ts, ok := s.targets[name]
+	if !ok {
+		ts = &targetStats{DroppedEvents: make(map[string]uint64)}
+		s.targets[name] = ts
+	}
+	return ts
+}
+
+func (s *stats) output(name string) *outputStats {
+	ost, ok := s.outputs[name]
+	if !ok {
+		ost = &outputStats{DroppedEvents: make(map[string]uint64)}
+		s.outputs[name] = ost
+	}
+	return ost
+}
+
+func (s *stats) notificationReceived(target string) {
+	targetReceivedNotifications.WithLabelValues(target).Inc()
+	s.m.Lock()
+	defer s.m.Unlock()
+	ts := s.target(target)
+	ts.ReceivedNotifications++
+	ts.lastNotification = time.Now()
+}
+
+func (s *stats) subscriptionError(target string) {
+	targetSubscriptionErrors.WithLabelValues(target).Inc()
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.target(target).SubscriptionErrors++
+}
+
+func (s *stats) priorityNotification(target string) {
+	targetPriorityNotifications.WithLabelValues(target).Inc()
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.target(target).PriorityNotifications++
+}
+
+func (s *stats) eventsConverted(target string, n int) {
+	targetConvertedEvents.WithLabelValues(target).Add(float64(n))
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.target(target).ConvertedEvents += uint64(n)
+}
+
+func (s *stats) targetDropped(target, reason string) {
+	targetDroppedEvents.WithLabelValues(target, reason).Inc()
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.target(target).DroppedEvents[reason]++
+}
+
+// clockSkew records the clock skew measured on a notification of target,
+// whether it exceeds the target threshold and whether its timestamp was corrected.
+func (s *stats) clockSkew(target string, skew time.Duration, exceeded, corrected bool) {
+	targetClockSkew.WithLabelValues(target).Set(skew.Seconds())
+	if exceeded {
+		targetClockSkewExceeded.WithLabelValues(target).Inc()
+	}
+	if corrected {
+		targetCorrectedTimestamps.WithLabelValues(target).Inc()
+	}
+	s.m.Lock()
+	defer s.m.Unlock()
+	ts := s.target(target)
+	ts.clockSkew = skew
+	if exceeded {
+		ts.ClockSkewExceeded++
+	}
+	if corrected {
+		ts.CorrectedTimestamps++
+	}
+}
+
+// timestampSkewed records n notifications or events of target
+// with a timestamp out of the bounds of output, handled with action.
+func (s *stats) timestampSkewed(target, output, action string, n int) {
+	targetSkewedTimestamps.WithLabelValues(target, output, action).Add(float64(n))
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.target(target).SkewedTimestamps += uint64(n)
+}
+
+func (s *stats) outputDropped(output, reason string, n int) {
+	outputDroppedEvents.WithLabelValues(output, reason).Add(float64(n))
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.output(output).DroppedEvents[reason] += uint64(n)
+}
+
+// outputThrottled records a write to the output delayed by its cpu-quota.
+func (s *stats) outputThrottled(output string) {
+	outputThrottledWrites.WithLabelValues(output).Inc()
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.output(output).ThrottledWrites++
+}
+
+// outputWritten records a write of msgs messages and events events to the output,
+// which took d.
+func (s *stats) outputWritten(output string, msgs, events int, d time.Duration) {
+	outputWrittenMessages.WithLabelValues(output).Add(float64(msgs))
+	outputWrittenEvents.WithLabelValues(output).Add(float64(events))
+	outputWriteLatency.WithLabelValues(output).Observe(d.Seconds())
+	s.m.Lock()
+	defer s.m.Unlock()
+	ost := s.output(output)
+	ost.WrittenMessages += uint64(msgs)
+	ost.WrittenEvents += uint64(events)
+	ost.writes++
+	ost.totalLatency += d
+	if d > ost.maxLatency {
+		ost.maxLatency = d
+	}
+}
+
+// snapshot returns a copy of the current stats.
+func (s *stats) snapshot() *statsResponse {
+	s.m.Lock()
+	defer s.m.Unlock()
+	rsp := &statsResponse{
+		Targets: make(map[string]*targetStats, len(s.targets)),
+		Outputs: make(map[string]*outputStats, len(s.outputs)),
+	}
+	for n, ts := range s.targets {
+		c := *ts
+		c.DroppedEvents = copyCounters(ts.DroppedEvents)
+		if ts.clockSkew != 0 {
+			c.ClockSkew = ts.clockSkew.String()
+		}
+		rsp.Targets[n] = &c
+	}
+	for n, ost := range s.outputs {
+		c := *ost
+		c.DroppedEvents = copyCounters(ost.DroppedEvents)
+		if ost.writes > 0 {
+			c.WriteLatency = &writeLatency{
+				Count:   ost.writes,
+				Average: (ost.totalLatency / time.Duration(ost.writes)).String(),
+				Max:     ost.maxLatency.String(),
+			}
+		}
+		rsp.Outputs[n] = &c
+	}
+	return rsp
+}
+
+func copyCounters(m map[string]uint64) map[string]uint64 {
+	if len(m) == 0 {
+		return nil
+	}
+	c := make(map[string]uint64, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+func (a *App) handleStatsGet(w http.ResponseWriter, r *http.Request) {
+	rsp := a.stats.snapshot()
+	a.operLock.RLock()
+	for name, t := range a.Targets {
+		st := t.CompressionStats()
+		ts, ok := rsp.Targets[name]
+		if !ok {
+			ts = new(targetStats)
+			rsp.Targets[name] = ts
+		}
+		ts.Bytes = &st
+	}
+	a.operLock.RUnlock()
+	if scope := requestScope(r); !scope.all {
+		a.configLock.RLock()
+		for name := range rsp.Targets {
+			if tc, ok := a.Config.Targets[name]; !ok || !scope.allows(tc.Namespace) {
+				delete(rsp.Targets, name)
+			}
+		}
+		for name := range rsp.Outputs {
+			if outCfg, ok := a.Config.Outputs[name]; !ok || !scope.allows(config.OutputNamespace(outCfg)) {
+				delete(rsp.Outputs, name)
+			}
+		}
+		a.configLock.RUnlock()
+	}
+	a.handlerCommonGet(w, rsp)
+}
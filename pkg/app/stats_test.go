@@ -0,0 +1,88 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+func TestExportStats(t *testing.T) {
+	a := New()
+	a.Config.Subscriptions = map[string]*types.SubscriptionConfig{
+		"sub1": {Name: "sub1", EventProcessors: []string{"add-role"}},
+		"sub2": {Name: "sub2"},
+	}
+	a.Config.Processors = map[string]map[string]interface{}{
+		"add-role": {
+			"event-add-tag": map[string]interface{}{
+				"value-names": []interface{}{".*"},
+				"add":         map[string]interface{}{"role": "core"},
+			},
+		},
+	}
+	if err := a.validateSubscriptionsProcessors(); err != nil {
+		t.Fatal(err)
+	}
+	a.Outputs["o1"] = new(testOutput)
+
+	rsp := &gnmi.SubscribeResponse{
+		Response: &gnmi.SubscribeResponse_Update{
+			Update: &gnmi.Notification{
+				Timestamp: 1,
+				Update: []*gnmi.Update{
+					{
+						Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "counter"}}},
+						Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_IntVal{IntVal: 42}},
+					},
+				},
+			},
+		},
+	}
+	ctx := context.Background()
+	a.Export(ctx, rsp, outputs.Meta{"source": "r1", "subscription-name": "sub1"})
+	a.Export(ctx, rsp, outputs.Meta{"source": "r1", "subscription-name": "sub2"})
+	a.Export(ctx, rsp, outputs.Meta{"source": "r1", "subscription-name": "sub2"}, "o2")
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	a.Export(cctx, rsp, outputs.Meta{"source": "r1", "subscription-name": "sub2"})
+
+	rec := httptest.NewRecorder()
+	a.handleStatsGet(rec, httptest.NewRequest("GET", "/api/v1/stats", nil))
+	st := new(statsResponse)
+	if err := json.Unmarshal(rec.Body.Bytes(), st); err != nil {
+		t.Fatal(err)
+	}
+	if ts := st.Targets["r1"]; ts == nil || ts.ConvertedEvents != 1 {
+		t.Errorf("unexpected target stats: %+v", ts)
+	}
+	o1 := st.Outputs["o1"]
+	if o1 == nil {
+		t.Fatalf("missing output o1 stats: %+v", st.Outputs)
+	}
+	if o1.WrittenMessages != 1 || o1.WrittenEvents != 1 {
+		t.Errorf("unexpected output o1 written counts: %+v", o1)
+	}
+	if o1.DroppedEvents[dropReasonCanceled] != 1 {
+		t.Errorf("expected 1 canceled write, got %v", o1.DroppedEvents)
+	}
+	if o1.WriteLatency == nil || o1.WriteLatency.Count != 2 {
+		t.Errorf("unexpected output o1 write latency: %+v", o1.WriteLatency)
+	}
+	if o2 := st.Outputs["o2"]; o2 == nil || o2.DroppedEvents[dropReasonUnknownOutput] != 1 {
+		t.Errorf("unexpected output o2 stats: %+v", o2)
+	}
+}
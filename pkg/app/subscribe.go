@@ -34,6 +34,9 @@ const (
 
 func (a *App) SubscribePreRunE(cmd *cobra.Command, args []string) error {
 	a.Config.SetLocalFlagsFromFile(cmd)
+	if a.Config.LocalFlags.SubscribeLive && a.Config.Format != formatTable {
+		return fmt.Errorf("flag --live requires --format %s", formatTable)
+	}
 
 	err := a.initPluginManager()
 	if err != nil {
@@ -73,6 +76,30 @@ func (a *App) SubscribeRunE(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	err = a.Config.GetNamespaces()
+	if err != nil {
+		return err
+	}
+	err = a.Config.GetPolicies()
+	if err != nil {
+		return err
+	}
+	err = a.Config.GetSLOs()
+	if err != nil {
+		return err
+	}
+	err = a.Config.GetSessionTracking()
+	if err != nil {
+		return err
+	}
+	err = a.Config.GetInventory()
+	if err != nil {
+		return err
+	}
+	err = a.Config.GetQoS()
+	if err != nil {
+		return err
+	}
 	err = a.Config.GetLoader()
 	if err != nil {
 		return err
@@ -85,20 +112,12 @@ func (a *App) SubscribeRunE(cmd *cobra.Command, args []string) error {
 	if allSubscriptionsModeOnce(subCfg) {
 		return a.SubscribeRunONCE(cmd, args)
 	}
-	// only poll mode subscriptions requested
-	if allSubscriptionsModePoll(subCfg) {
+	// only poll mode subscriptions requested,
+	// with an API server configured the polls are triggered through it.
+	if allSubscriptionsModePoll(subCfg) && a.Config.APIServer == nil {
 		return a.SubscribeRunPoll(cmd, args)
 	}
 	// stream subscriptions
-	err = a.initTunnelServer(tunnel.ServerConfig{
-		AddTargetHandler:    a.tunServerAddTargetSubscribeHandler,
-		DeleteTargetHandler: a.tunServerDeleteTargetHandler,
-		RegisterHandler:     a.tunServerRegisterHandler,
-		Handler:             a.tunServerHandler,
-	})
-	if err != nil {
-		return err
-	}
 	_, err = a.Config.GetTargets()
 	if errors.Is(err, config.ErrNoTargetsFound) {
 		if !a.Config.LocalFlags.SubscribeWatchConfig &&
@@ -110,6 +129,42 @@ func (a *App) SubscribeRunE(cmd *cobra.Command, args []string) error {
 	} else if err != nil {
 		return fmt.Errorf("failed reading targets config: %v", err)
 	}
+	err = a.Config.ValidateNamespaces()
+	if err != nil {
+		return err
+	}
+	err = a.Config.ValidatePolicies()
+	if err != nil {
+		return err
+	}
+	err = a.Config.ValidateSLOs()
+	if err != nil {
+		return err
+	}
+	err = a.Config.ValidateSessionTracking()
+	if err != nil {
+		return err
+	}
+	err = a.Config.ValidateOutputsTimestamps()
+	if err != nil {
+		return err
+	}
+	err = a.Config.ValidateOutputsTargetSelectors()
+	if err != nil {
+		return err
+	}
+	err = a.Config.ValidateOutputsCPUQuotas()
+	if err != nil {
+		return err
+	}
+	err = a.Config.ValidateQoS()
+	if err != nil {
+		return err
+	}
+	err = a.loadComponentsState()
+	if err != nil {
+		return err
+	}
 
 	//
 	for {
@@ -121,6 +176,17 @@ func (a *App) SubscribeRunE(cmd *cobra.Command, args []string) error {
 		}
 		break
 	}
+	// the tunnel server is started after the locker,
+	// its generated certificate might be stored in it.
+	err = a.initTunnelServer(tunnel.ServerConfig{
+		AddTargetHandler:    a.tunServerAddTargetSubscribeHandler,
+		DeleteTargetHandler: a.tunServerDeleteTargetHandler,
+		RegisterHandler:     a.tunServerRegisterHandler,
+		Handler:             a.tunServerHandler,
+	})
+	if err != nil {
+		return err
+	}
 
 	a.startAPIServer()
 	a.startGnmiServer()
@@ -146,7 +212,7 @@ func (a *App) subscribeOnce(ctx context.Context, tc *types.TargetConfig) {
 	defer a.wg.Done()
 	err := a.TargetSubscribeOnce(ctx, tc)
 	if err != nil {
-		a.logError(err)
+		a.logTargetError(tc.Name, err)
 	}
 }
 
@@ -172,10 +238,12 @@ func (a *App) InitSubscribeFlags(cmd *cobra.Command) {
 	cmd.Flags().DurationVarP(&a.Config.LocalFlags.SubscribeHeartbeatInterval, "heartbeat-interval", "", 0, "heartbeat interval in case suppress-redundant is enabled")
 	cmd.Flags().StringSliceVarP(&a.Config.LocalFlags.SubscribeModel, "model", "", []string{}, "subscribe request used model(s)")
 	cmd.Flags().BoolVar(&a.Config.LocalFlags.SubscribeQuiet, "quiet", false, "suppress stdout printing")
+	cmd.Flags().BoolVar(&a.Config.LocalFlags.SubscribeLive, "live", false, "with --format table, print the received updates as a single live-updating table")
 	cmd.Flags().StringVarP(&a.Config.LocalFlags.SubscribeTarget, "target", "", "", "subscribe request target")
 	cmd.Flags().BoolVarP(&a.Config.LocalFlags.SubscribeSetTarget, "set-target", "", false, "set target name in gNMI Path prefix")
 	cmd.Flags().StringSliceVarP(&a.Config.LocalFlags.SubscribeName, "name", "n", []string{}, "reference subscriptions by name, must be defined in gnmic config file")
 	cmd.Flags().StringSliceVarP(&a.Config.LocalFlags.SubscribeOutput, "output", "", []string{}, "reference to output groups by name, must be defined in gnmic config file")
+	cmd.Flags().StringSliceVarP(&a.Config.LocalFlags.SubscribeStreamTo, "stream-to", "", []string{}, "reference to outputs by name, must be defined in gnmic config file. The received updates are written to them in addition to stdout, unless --quiet is set")
 	cmd.Flags().BoolVarP(&a.Config.LocalFlags.SubscribeWatchConfig, "watch-config", "", false, "watch configuration changes, add or delete subscribe targets accordingly")
 	cmd.Flags().DurationVarP(&a.Config.LocalFlags.SubscribeBackoff, "backoff", "", 0, "backoff time between subscribe requests")
 	cmd.Flags().DurationVarP(&a.Config.LocalFlags.SubscribeLockRetry, "lock-retry", "", 5*time.Second, "time to wait between target lock attempts")
@@ -195,6 +263,10 @@ func (a *App) readConfigs() error {
 	if err != nil {
 		return fmt.Errorf("failed reading outputs config: %v", err)
 	}
+	if a.recorder != nil {
+		// the responses are only written to the record file
+		a.Config.Outputs = make(map[string]map[string]interface{})
+	}
 	_, err = a.Config.GetInputs()
 	if err != nil {
 		return fmt.Errorf("failed reading inputs config: %v", err)
@@ -207,6 +279,10 @@ func (a *App) readConfigs() error {
 	if err != nil {
 		return fmt.Errorf("failed reading event processors config: %v", err)
 	}
+	err = a.validateSubscriptionsProcessors()
+	if err != nil {
+		return err
+	}
 	_, err = a.LoadProtoFiles()
 	if err != nil {
 		return fmt.Errorf("failed loading proto files: %v", err)
@@ -240,6 +316,7 @@ func (a *App) handlePolledSubscriptions() error {
 			for {
 				select {
 				case rsp := <-rspCh:
+					applyProfile(t.Config, rsp.Response)
 					b, err := mo.Marshal(rsp.Response, nil)
 					if err != nil {
 						return fmt.Errorf("target '%s', subscription '%s': poll response formatting error: %v", targetName, rsp.SubscriptionName, err)
@@ -332,6 +409,7 @@ OUTER:
 						waitChan <- struct{}{}
 						continue OUTER
 					}
+					applyProfile(t.Config, rsp.Response)
 					b, err := mo.Marshal(rsp.Response, nil)
 					if err != nil {
 						fmt.Printf("target '%s', subscription '%s': poll response formatting error:%v\n", name, subName, err)
@@ -381,7 +459,8 @@ func allSubscriptionsModeOnce(subs map[string]*types.SubscriptionConfig) bool {
 		return false
 	}
 	for _, sub := range subs {
-		if strings.ToUpper(sub.Mode) != "ONCE" {
+		// scheduled subscriptions run until gnmic is stopped
+		if strings.ToUpper(sub.Mode) != "ONCE" || sub.Schedule != nil {
 			return false
 		}
 	}
@@ -393,7 +472,8 @@ func allSubscriptionsModePoll(subs map[string]*types.SubscriptionConfig) bool {
 		return false
 	}
 	for _, sub := range subs {
-		if strings.ToUpper(sub.Mode) != "POLL" {
+		// scheduled subscriptions run until gnmic is stopped
+		if strings.ToUpper(sub.Mode) != "POLL" || sub.Schedule != nil {
 			return false
 		}
 	}
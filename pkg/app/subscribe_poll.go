@@ -9,12 +9,21 @@
 package app
 
 import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/openconfig/grpctunnel/tunnel"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
+const defaultPollAPIAddress = "localhost:7890"
+
 func (a *App) SubscribeRunPoll(cmd *cobra.Command, args []string) error {
 	a.initTunnelServer(tunnel.ServerConfig{
 		AddTargetHandler:    a.tunServerAddTargetHandler,
@@ -41,3 +50,87 @@ func (a *App) SubscribeRunPoll(cmd *cobra.Command, args []string) error {
 	a.wg.Wait()
 	return a.handlePolledSubscriptions()
 }
+
+func (a *App) SubscribePollPreRunE(cmd *cobra.Command, args []string) error {
+	a.Config.SetLocalFlagsFromFile(cmd)
+	if a.Config.LocalFlags.PollTarget == "" {
+		return errors.New("missing --target flag")
+	}
+	if a.Config.LocalFlags.PollName == "" {
+		return errors.New("missing --name flag")
+	}
+	return nil
+}
+
+// SubscribePollRunE triggers a poll of a poll mode subscription
+// run by a gNMIc instance, using its API server.
+func (a *App) SubscribePollRunE(cmd *cobra.Command, args []string) error {
+	defer a.InitSubscribePollFlags(cmd)
+	rawURL, err := pollTriggerURL(a.Config.LocalFlags.PollAPIAddress, a.Config.LocalFlags.PollTarget, a.Config.LocalFlags.PollName)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(cmd.Context(), http.MethodPost, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	if a.Config.LocalFlags.PollAPIToken != "" {
+		req.Header.Set("Authorization", "Bearer "+a.Config.LocalFlags.PollAPIToken)
+	}
+	client := &http.Client{
+		Timeout: a.Config.Timeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: a.Config.SkipVerify,
+			},
+		},
+	}
+	rsp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode == http.StatusOK {
+		a.Logger.Printf("triggered poll of subscription %q on target %q", a.Config.LocalFlags.PollName, a.Config.LocalFlags.PollTarget)
+		return nil
+	}
+	apiErrs := new(APIErrors)
+	if err := json.NewDecoder(rsp.Body).Decode(apiErrs); err == nil && len(apiErrs.Errors) > 0 {
+		return fmt.Errorf("%s: %s", rsp.Status, strings.Join(apiErrs.Errors, ", "))
+	}
+	return fmt.Errorf("unexpected response status: %s", rsp.Status)
+}
+
+// pollTriggerURL builds the API URL triggering a poll of subscription name on target.
+// The API address defaults to the http scheme if none is set.
+func pollTriggerURL(address, target, name string) (string, error) {
+	if address == "" {
+		address = defaultPollAPIAddress
+	}
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+	u, err := url.Parse(address)
+	if err != nil {
+		return "", fmt.Errorf("invalid API address %q: %v", address, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("invalid API address %q: unsupported scheme %q", address, u.Scheme)
+	}
+	u = u.JoinPath("/api/v1/targets", target, "subscriptions", name, "poll")
+	return u.String(), nil
+}
+
+// InitSubscribePollFlags used to init or reset the subscribe poll command flags for gnmic-prompt mode
+func (a *App) InitSubscribePollFlags(cmd *cobra.Command) {
+	cmd.ResetFlags()
+
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.PollAPIAddress, "api-address", "", defaultPollAPIAddress, "address of the gnmic instance API server, prefixed with https:// if it uses TLS")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.PollAPIToken, "api-token", "", "", "bearer token sent to the API server")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.PollTarget, "target", "", "", "name of the target to poll")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.PollName, "name", "n", "", "name of the poll subscription")
+
+	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		a.Config.FileConfig.BindPFlag(fmt.Sprintf("%s-%s", cmd.Name(), flag.Name), flag)
+	})
+}
@@ -0,0 +1,94 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/openconfig/gnmic/pkg/api/target"
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+type pollSubscribeClient struct {
+	gnmi.GNMI_SubscribeClient
+	polls int
+}
+
+func (c *pollSubscribeClient) Send(req *gnmi.SubscribeRequest) error {
+	if req.GetPoll() != nil {
+		c.polls++
+	}
+	return nil
+}
+
+func TestAPITargetsSubscriptionsPoll(t *testing.T) {
+	a := newNamespacedApp()
+	tg := target.NewTarget(a.Config.Targets["t1"])
+	tg.Subscriptions = map[string]*types.SubscriptionConfig{
+		"polled":   {Name: "polled", Mode: "poll"},
+		"streamed": {Name: "streamed", Mode: "stream"},
+		"stopped":  {Name: "stopped", Mode: "poll"},
+	}
+	stream := new(pollSubscribeClient)
+	tg.SubscribeClients["polled"] = stream
+	a.Targets["t1"] = tg
+	a.Targets["t2"] = target.NewTarget(a.Config.Targets["t2"])
+
+	tests := []struct {
+		name   string
+		path   string
+		token  string
+		status int
+	}{
+		{name: "poll", path: "/api/v1/targets/t1/subscriptions/polled/poll", token: "tk1", status: http.StatusOK},
+		{name: "admin_poll", path: "/api/v1/targets/t1/subscriptions/polled/poll", token: "admin", status: http.StatusOK},
+		{name: "unknown_target", path: "/api/v1/targets/t3/subscriptions/polled/poll", token: "admin", status: http.StatusNotFound},
+		{name: "other_namespace", path: "/api/v1/targets/t2/subscriptions/polled/poll", token: "tk1", status: http.StatusNotFound},
+		{name: "unknown_subscription", path: "/api/v1/targets/t1/subscriptions/sub9/poll", token: "tk1", status: http.StatusNotFound},
+		{name: "stream_subscription", path: "/api/v1/targets/t1/subscriptions/streamed/poll", token: "tk1", status: http.StatusBadRequest},
+		{name: "not_running", path: "/api/v1/targets/t1/subscriptions/stopped/poll", token: "tk1", status: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := apiRequest(a, http.MethodPost, tt.path, tt.token, "")
+			if rec.Code != tt.status {
+				t.Errorf("got status %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+		})
+	}
+	if stream.polls != 2 {
+		t.Errorf("got %d poll requests, want 2", stream.polls)
+	}
+}
+
+func TestPollTriggerURL(t *testing.T) {
+	tests := []struct {
+		address string
+		want    string
+		wantErr bool
+	}{
+		{address: "", want: "http://localhost:7890/api/v1/targets/r1:57400/subscriptions/sub1/poll"},
+		{address: "gnmic1:7890", want: "http://gnmic1:7890/api/v1/targets/r1:57400/subscriptions/sub1/poll"},
+		{address: "https://gnmic1:7890/", want: "https://gnmic1:7890/api/v1/targets/r1:57400/subscriptions/sub1/poll"},
+		{address: "ftp://gnmic1", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := pollTriggerURL(tt.address, "r1:57400", "sub1")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: unexpected error: %v", tt.address, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q: got %q, want %q", tt.address, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/openconfig/gnmic/pkg/api/target"
+)
+
+// minStreamOptionsGNMIVersion is the gNMI version that added
+// the suppress_redundant and heartbeat_interval subscription fields.
+const minStreamOptionsGNMIVersion = "0.4.0"
+
+// checkStreamOptionsSupport logs a warning if the target capabilities indicate
+// that the suppress-redundant or heartbeat-interval options set in the
+// subscribe requests are not supported.
+func (a *App) checkStreamOptionsSupport(ctx context.Context, t *target.Target, subRequests []subscriptionRequest) {
+	names := make([]string, 0, len(subRequests))
+	for _, sreq := range subRequests {
+		if usesStreamOptions(sreq.req) {
+			names = append(names, sreq.name)
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+	capRsp, err := t.Capabilities(ctx)
+	if err != nil {
+		a.Logger.Printf("target %q: failed to get capabilities to validate subscriptions options: %v", t.Config.Name, err)
+		return
+	}
+	if msg := streamOptionsWarning(capRsp); msg != "" {
+		a.Logger.Printf("target %q subscriptions %v: %s", t.Config.Name, names, msg)
+	}
+}
+
+// usesStreamOptions checks if any of the request subscriptions sets
+// suppress_redundant or heartbeat_interval.
+func usesStreamOptions(req *gnmi.SubscribeRequest) bool {
+	for _, sub := range req.GetSubscribe().GetSubscription() {
+		if sub.GetSuppressRedundant() || sub.GetHeartbeatInterval() > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// streamOptionsWarning returns a warning if the gNMI version advertised
+// in capRsp is older than minStreamOptionsGNMIVersion.
+func streamOptionsWarning(capRsp *gnmi.CapabilityResponse) string {
+	version := capRsp.GetGNMIVersion()
+	older, ok := olderVersion(version, minStreamOptionsGNMIVersion)
+	if !ok || !older {
+		return ""
+	}
+	return fmt.Sprintf("target gNMI version %s is older than %s, suppress-redundant and heartbeat-interval might not be supported",
+		version, minStreamOptionsGNMIVersion)
+}
+
+// olderVersion reports whether the dot separated version v is older than ref.
+// The second returned value is false if v cannot be parsed.
+func olderVersion(v, ref string) (bool, bool) {
+	vs := strings.Split(strings.TrimPrefix(v, "v"), ".")
+	refs := strings.Split(ref, ".")
+	for i, r := range refs {
+		rn, _ := strconv.Atoi(r)
+		if i >= len(vs) {
+			return rn > 0, true
+		}
+		n, err := strconv.Atoi(vs[i])
+		if err != nil {
+			return false, false
+		}
+		if n != rn {
+			return n < rn, true
+		}
+	}
+	return false, true
+}
@@ -0,0 +1,38 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"testing"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+func TestStreamOptionsWarning(t *testing.T) {
+	tests := []struct {
+		version string
+		warn    bool
+	}{
+		{version: "0.7.0"},
+		{version: "0.4.0"},
+		{version: "0.3.1", warn: true},
+		{version: "0.2", warn: true},
+		{version: "1"},
+		{version: ""},
+		{version: "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			got := streamOptionsWarning(&gnmi.CapabilityResponse{GNMIVersion: tt.version})
+			if (got != "") != tt.warn {
+				t.Errorf("version %q: got warning %q, want warning: %v", tt.version, got, tt.warn)
+			}
+		})
+	}
+}
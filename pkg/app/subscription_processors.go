@@ -0,0 +1,66 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+)
+
+// subscriptionProcessors holds the event processors created for a subscription.
+type subscriptionProcessors struct {
+	names []string
+	evps  []formatters.EventProcessor
+}
+
+// subscriptionProcessors returns the event processors configured under the subscription name.
+// They are created on first use and re-created if the subscription processors list changes.
+func (a *App) subscriptionProcessors(name string) []formatters.EventProcessor {
+	sub, ok := a.Config.Subscriptions[name]
+	if !ok || len(sub.EventProcessors) == 0 {
+		return nil
+	}
+	a.subProcsLock.Lock()
+	defer a.subProcsLock.Unlock()
+	if sp, ok := a.subProcs[name]; ok && slices.Equal(sp.names, sub.EventProcessors) {
+		return sp.evps
+	}
+	evps, err := formatters.MakeEventProcessors(
+		a.Logger,
+		sub.EventProcessors,
+		a.Config.Processors,
+		a.Config.Targets,
+		a.Config.Actions,
+	)
+	if err != nil {
+		// cache the failure to avoid logging it for every response
+		a.Logger.Printf("subscription %q: failed to create event processors: %v", name, err)
+		evps = nil
+	}
+	a.subProcs[name] = &subscriptionProcessors{
+		names: slices.Clone(sub.EventProcessors),
+		evps:  evps,
+	}
+	return evps
+}
+
+// validateSubscriptionsProcessors checks that the event processors
+// configured under the subscriptions are defined.
+func (a *App) validateSubscriptionsProcessors() error {
+	for name, sub := range a.Config.Subscriptions {
+		for _, ep := range sub.EventProcessors {
+			if _, ok := a.Config.Processors[ep]; !ok {
+				return fmt.Errorf("subscription %q: unknown event processor %q", name, ep)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"log"
+	"sync"
+	"testing"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/formatters"
+	_ "github.com/openconfig/gnmic/pkg/formatters/all"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+type testOutput struct {
+	m      sync.Mutex
+	msgs   int
+	events []*formatters.EventMsg
+}
+
+func (o *testOutput) Init(context.Context, string, map[string]interface{}, ...outputs.Option) error {
+	return nil
+}
+func (o *testOutput) Write(context.Context, proto.Message, outputs.Meta) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	o.msgs++
+}
+func (o *testOutput) WriteEvent(_ context.Context, ev *formatters.EventMsg) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	o.events = append(o.events, ev)
+}
+func (o *testOutput) Close() error                         { return nil }
+func (o *testOutput) RegisterMetrics(*prometheus.Registry) {}
+func (o *testOutput) String() string                       { return "" }
+func (o *testOutput) SetLogger(*log.Logger)                {}
+func (o *testOutput) SetEventProcessors(map[string]map[string]interface{}, *log.Logger, map[string]*types.TargetConfig, map[string]map[string]interface{}) error {
+	return nil
+}
+func (o *testOutput) SetName(string)                                  {}
+func (o *testOutput) SetClusterName(string)                           {}
+func (o *testOutput) SetTargetsConfig(map[string]*types.TargetConfig) {}
+
+func TestExportSubscriptionProcessors(t *testing.T) {
+	a := New()
+	a.Config.Subscriptions = map[string]*types.SubscriptionConfig{
+		"sub1": {Name: "sub1", EventProcessors: []string{"add-role"}},
+		"sub2": {Name: "sub2"},
+	}
+	a.Config.Processors = map[string]map[string]interface{}{
+		"add-role": {
+			"event-add-tag": map[string]interface{}{
+				"value-names": []interface{}{".*"},
+				"add":         map[string]interface{}{"role": "core"},
+			},
+		},
+	}
+	if err := a.validateSubscriptionsProcessors(); err != nil {
+		t.Fatal(err)
+	}
+	o := new(testOutput)
+	a.Outputs["o1"] = o
+
+	rsp := &gnmi.SubscribeResponse{
+		Response: &gnmi.SubscribeResponse_Update{
+			Update: &gnmi.Notification{
+				Timestamp: 1,
+				Update: []*gnmi.Update{
+					{
+						Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "counter"}}},
+						Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_IntVal{IntVal: 42}},
+					},
+				},
+			},
+		},
+	}
+	o2 := new(testOutput)
+	a.Outputs["o2"] = o2
+	ctx := context.Background()
+	a.Export(ctx, rsp, outputs.Meta{"source": "r1", "subscription-name": "sub1"})
+	a.Export(ctx, rsp, outputs.Meta{"source": "r1", "subscription-name": "sub2"})
+	// sync responses are written as is
+	a.Export(ctx, &gnmi.SubscribeResponse{
+		Response: &gnmi.SubscribeResponse_SyncResponse{SyncResponse: true},
+	}, outputs.Meta{"source": "r1", "subscription-name": "sub1"})
+
+	if o.msgs != 2 {
+		t.Errorf("expected 2 messages, got %d", o.msgs)
+	}
+	if len(o.events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(o.events))
+	}
+	ev := o.events[0]
+	if ev.Tags["role"] != "core" || ev.Tags["source"] != "r1" || ev.Values["/counter"] != int64(42) {
+		t.Errorf("unexpected event: %v", ev)
+	}
+	// the outputs process their events concurrently, they must not share them
+	if len(o2.events) != 1 || o2.events[0] == ev {
+		t.Fatalf("the outputs share the same events: %v", o2.events)
+	}
+	ev.Tags["output"] = "o1"
+	if _, ok := o2.events[0].Tags["output"]; ok {
+		t.Errorf("the outputs share the same event tags")
+	}
+}
+
+func TestValidateSubscriptionsProcessors(t *testing.T) {
+	a := New()
+	a.Config.Subscriptions = map[string]*types.SubscriptionConfig{
+		"sub1": {Name: "sub1", EventProcessors: []string{"unknown"}},
+	}
+	if err := a.validateSubscriptionsProcessors(); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -25,15 +25,14 @@ func (a *App) initTarget(tc *types.TargetConfig) (*target.Target, error) {
 	t, ok := a.Targets[tc.Name]
 	if !ok {
 		t := target.NewTarget(tc)
-		for _, subName := range tc.Subscriptions {
-			if sub, ok := a.Config.Subscriptions[subName]; ok {
-				t.Subscriptions[subName] = sub
-			}
-		}
-		if len(t.Subscriptions) == 0 {
-			for n, sub := range a.Config.Subscriptions {
-				t.Subscriptions[n] = sub
+		t.SetConnPool(a.connPool)
+		t.SetPriorityClassifier(a.priorityClassifier())
+		for n, sub := range a.targetSubscriptions(tc) {
+			rsub, err := renderSubscription(sub, tc)
+			if err != nil {
+				return nil, fmt.Errorf("target %q: subscription %q: %v", tc.Name, n, err)
 			}
+			t.Subscriptions[n] = rsub
 		}
 		err := a.parseProtoFiles(t)
 		if err != nil {
@@ -45,6 +44,36 @@ func (a *App) initTarget(tc *types.TargetConfig) (*target.Target, error) {
 	return t, nil
 }
 
+// targetSubscriptions returns the subscriptions of the target tc:
+// the subscriptions it lists, or all the subscriptions of its namespace without a target selector if none of them exists,
+// and the subscriptions of its namespace with a target selector it matches.
+// it assumes that the configLock is acquired.
+func (a *App) targetSubscriptions(tc *types.TargetConfig) map[string]*types.SubscriptionConfig {
+	subs := make(map[string]*types.SubscriptionConfig)
+	for _, subName := range tc.Subscriptions {
+		if sub, ok := a.Config.Subscriptions[subName]; ok {
+			subs[subName] = sub
+		}
+	}
+	// default to the subscriptions of the target namespace
+	dflt := len(subs) == 0
+	for n, sub := range a.Config.Subscriptions {
+		if sub.Namespace != tc.Namespace {
+			continue
+		}
+		if len(sub.TargetSelector) == 0 {
+			if dflt {
+				subs[n] = sub
+			}
+			continue
+		}
+		if types.MatchTargetSelector(sub.TargetSelector, tc) {
+			subs[n] = sub
+		}
+	}
+	return subs
+}
+
 func (a *App) stopTarget(ctx context.Context, name string) error {
 	if a.Targets == nil {
 		return nil
@@ -57,8 +86,9 @@ func (a *App) stopTarget(ctx context.Context, name string) error {
 
 	a.Logger.Printf("stopping target %q", name)
 	t := a.Targets[name]
-	t.StopSubscriptions()
+	t.Close()
 	delete(a.Targets, name)
+	delete(a.targetsLockTime, name)
 	if a.locker == nil {
 		return nil
 	}
@@ -85,8 +115,12 @@ func (a *App) DeleteTarget(ctx context.Context, name string) error {
 	if a.c != nil {
 		a.c.DeleteTarget(name)
 	}
+	a.sessions.deleteTarget(name)
+	a.inventory.deleteTarget(name)
+	a.ui.deleteTarget(name)
 	if t, ok := a.Targets[name]; ok {
 		delete(a.Targets, name)
+		delete(a.targetsLockTime, name)
 		t.Close()
 		if a.locker != nil {
 			return a.locker.Unlock(ctx, a.targetLockKey(name))
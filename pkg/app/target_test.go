@@ -0,0 +1,78 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+func TestTargetSubscriptions(t *testing.T) {
+	a := New()
+	a.Config.Subscriptions = map[string]*types.SubscriptionConfig{
+		"system": {Name: "system"},
+		"ifaces": {Name: "ifaces"},
+		"fabric": {Name: "fabric", TargetSelector: []string{"role=spine", "role=leaf,site=dc1"}},
+		"border": {Name: "border", TargetSelector: []string{"role=border"}},
+		"team1":  {Name: "team1", Namespace: "team1", TargetSelector: []string{"role=spine"}},
+	}
+	tests := []struct {
+		name string
+		tc   *types.TargetConfig
+		want []string
+	}{
+		{
+			name: "no_tags",
+			tc:   &types.TargetConfig{Name: "t1"},
+			want: []string{"ifaces", "system"},
+		},
+		{
+			name: "selector_var",
+			tc:   &types.TargetConfig{Name: "t1", Vars: map[string]string{"role": "spine"}},
+			want: []string{"fabric", "ifaces", "system"},
+		},
+		{
+			name: "selector_tags",
+			tc:   &types.TargetConfig{Name: "t1", Tags: []string{"role=leaf", "site=dc1"}},
+			want: []string{"fabric", "ifaces", "system"},
+		},
+		{
+			name: "listed_and_selector",
+			tc:   &types.TargetConfig{Name: "t1", Subscriptions: []string{"system"}, Tags: []string{"role=border"}},
+			want: []string{"border", "system"},
+		},
+		{
+			name: "listed_selector",
+			tc:   &types.TargetConfig{Name: "t1", Subscriptions: []string{"border"}},
+			want: []string{"border"},
+		},
+		{
+			name: "namespace",
+			tc:   &types.TargetConfig{Name: "t1", Namespace: "team1", Tags: []string{"role=spine"}},
+			want: []string{"team1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subs := a.targetSubscriptions(tt.tc)
+			got := make([]string, 0, len(subs))
+			for n := range subs {
+				got = append(got, n)
+			}
+			sort.Strings(got)
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("got subscriptions %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"strings"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/gtemplate"
+)
+
+// renderSubscription executes the Go templates found in the subscription prefix, target and paths
+// using the target config as input, e.g: {{ .Vars.site }}.
+// The subscription is returned as is if it does not contain any template.
+func renderSubscription(sub *types.SubscriptionConfig, tc *types.TargetConfig) (*types.SubscriptionConfig, error) {
+	if !isSubscriptionTemplate(sub) {
+		return sub, nil
+	}
+	nsub := *sub
+	var err error
+	nsub.Prefix, err = renderTargetTemplate(sub.Prefix, tc)
+	if err != nil {
+		return nil, err
+	}
+	nsub.Target, err = renderTargetTemplate(sub.Target, tc)
+	if err != nil {
+		return nil, err
+	}
+	nsub.Paths = make([]string, 0, len(sub.Paths))
+	for _, p := range sub.Paths {
+		rp, err := renderTargetTemplate(p, tc)
+		if err != nil {
+			return nil, err
+		}
+		nsub.Paths = append(nsub.Paths, rp)
+	}
+	if len(sub.StreamSubscriptions) > 0 {
+		nsub.StreamSubscriptions = make([]*types.SubscriptionConfig, 0, len(sub.StreamSubscriptions))
+		for _, ssub := range sub.StreamSubscriptions {
+			rssub, err := renderSubscription(ssub, tc)
+			if err != nil {
+				return nil, err
+			}
+			nsub.StreamSubscriptions = append(nsub.StreamSubscriptions, rssub)
+		}
+	}
+	return &nsub, nil
+}
+
+func isSubscriptionTemplate(sub *types.SubscriptionConfig) bool {
+	if isTemplate(sub.Prefix) || isTemplate(sub.Target) {
+		return true
+	}
+	for _, p := range sub.Paths {
+		if isTemplate(p) {
+			return true
+		}
+	}
+	for _, ssub := range sub.StreamSubscriptions {
+		if isSubscriptionTemplate(ssub) {
+			return true
+		}
+	}
+	return false
+}
+
+func isTemplate(s string) bool {
+	return strings.Contains(s, "{{")
+}
+
+func renderTargetTemplate(s string, tc *types.TargetConfig) (string, error) {
+	if !isTemplate(s) {
+		return s, nil
+	}
+	tpl, err := gtemplate.CreateTemplate("subscription", s)
+	if err != nil {
+		return "", err
+	}
+	sb := new(strings.Builder)
+	err = tpl.Execute(sb, tc)
+	if err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,57 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+func TestRenderSubscription(t *testing.T) {
+	tc := &types.TargetConfig{
+		Name: "router1",
+		Vars: map[string]string{"uplink": "ethernet-1/49"},
+	}
+	sub := &types.SubscriptionConfig{
+		Name:  "uplink",
+		Paths: []string{`/interface[name={{ .Vars.uplink }}]/statistics`, "/system/name"},
+		StreamSubscriptions: []*types.SubscriptionConfig{
+			{Paths: []string{`/interface[name={{ .Vars.uplink }}]/oper-state`}},
+		},
+	}
+	rsub, err := renderSubscription(sub, tc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rsub == sub {
+		t.Fatal("expected a rendered copy of the subscription")
+	}
+	if want := []string{"/interface[name=ethernet-1/49]/statistics", "/system/name"}; !cmp.Equal(rsub.Paths, want) {
+		t.Errorf("got paths %v, want %v", rsub.Paths, want)
+	}
+	if got := rsub.StreamSubscriptions[0].Paths[0]; got != "/interface[name=ethernet-1/49]/oper-state" {
+		t.Errorf("got stream subscription path %q", got)
+	}
+	// the original subscription is not modified
+	if sub.Paths[0] != `/interface[name={{ .Vars.uplink }}]/statistics` {
+		t.Errorf("original subscription modified: %v", sub.Paths)
+	}
+	// subscriptions without templates are shared
+	plain := &types.SubscriptionConfig{Name: "system", Paths: []string{"/system"}}
+	rplain, err := renderSubscription(plain, tc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rplain != plain {
+		t.Error("expected the subscription without templates to be returned as is")
+	}
+}
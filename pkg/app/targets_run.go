@@ -0,0 +1,220 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"golang.org/x/sync/semaphore"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+// target run statuses
+const (
+	runStatusOK      = "ok"
+	runStatusFailed  = "failed"
+	runStatusSkipped = "skipped"
+)
+
+// targetsRun tracks the outcome of a request sent to multiple targets.
+type targetsRun struct {
+	m        *sync.Mutex
+	failFast bool
+	cancel   context.CancelFunc
+	start    time.Time
+	results  map[string]*targetRunResult
+}
+
+type targetRunResult struct {
+	name     string
+	status   string
+	start    time.Time
+	duration time.Duration
+	err      error // first error
+}
+
+func newTargetsRun(names []string, failFast bool, cancel context.CancelFunc) *targetsRun {
+	r := &targetsRun{
+		m:        new(sync.Mutex),
+		failFast: failFast,
+		cancel:   cancel,
+		start:    time.Now(),
+		results:  make(map[string]*targetRunResult, len(names)),
+	}
+	for _, n := range names {
+		r.results[n] = &targetRunResult{name: n, status: runStatusSkipped}
+	}
+	return r
+}
+
+func (r *targetsRun) started(name string) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	if tr, ok := r.results[name]; ok {
+		tr.status = runStatusOK
+		tr.start = time.Now()
+	}
+}
+
+func (r *targetsRun) done(name string) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	if tr, ok := r.results[name]; ok {
+		tr.duration = time.Since(tr.start)
+	}
+}
+
+// failed marks the target as failed, and cancels the run if fail-fast is set.
+func (r *targetsRun) failed(name string, err error) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	tr, ok := r.results[name]
+	if !ok {
+		return
+	}
+	if tr.status != runStatusFailed {
+		tr.status = runStatusFailed
+		tr.err = err
+	}
+	if r.failFast {
+		r.cancel()
+	}
+}
+
+// skipped returns the names of the targets the request was not sent to.
+func (r *targetsRun) skipped() []string {
+	r.m.Lock()
+	defer r.m.Unlock()
+	names := make([]string, 0)
+	for n, tr := range r.results {
+		if tr.status == runStatusSkipped {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (r *targetsRun) duration(name string) time.Duration {
+	r.m.Lock()
+	defer r.m.Unlock()
+	if tr, ok := r.results[name]; ok {
+		return tr.duration
+	}
+	return 0
+}
+
+// writeSummary writes a table with the status and duration of the request sent to each target,
+// followed by the totals.
+func (r *targetsRun) writeSummary(w io.Writer) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	names := make([]string, 0, len(r.results))
+	for n := range r.results {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	counts := make(map[string]int)
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Target", "Status", "Duration", "Error"})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAutoFormatHeaders(false)
+	table.SetAutoWrapText(false)
+	for _, n := range names {
+		tr := r.results[n]
+		counts[tr.status]++
+		dur, errMsg := "", ""
+		if tr.status != runStatusSkipped {
+			dur = tr.duration.Round(time.Millisecond).String()
+		}
+		if tr.err != nil {
+			errMsg = tr.err.Error()
+		}
+		table.Append([]string{n, tr.status, dur, errMsg})
+	}
+	table.Render()
+	fmt.Fprintf(w, "%d targets: %d succeeded, %d failed, %d skipped in %s\n",
+		len(names), counts[runStatusOK], counts[runStatusFailed], counts[runStatusSkipped],
+		time.Since(r.start).Round(time.Millisecond))
+}
+
+// runTargets calls fn for each configured target, in target name order,
+// with at most `max-concurrency` concurrent calls.
+// If `fail-fast` is set, the first target error cancels the calls in progress
+// and the remaining targets are skipped.
+// fn reports its errors using a.logTargetError.
+func (a *App) runTargets(ctx context.Context, fn func(ctx context.Context, tc *types.TargetConfig)) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	names := make([]string, 0, len(a.Config.Targets))
+	for n := range a.Config.Targets {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	r := newTargetsRun(names, a.Config.FailFast, cancel)
+	a.targetsRun = r
+
+	var sem *semaphore.Weighted
+	if a.Config.MaxConcurrency > 0 {
+		sem = semaphore.NewWeighted(int64(a.Config.MaxConcurrency))
+	}
+	wg := new(sync.WaitGroup)
+	for _, n := range names {
+		if sem != nil {
+			if err := sem.Acquire(ctx, 1); err != nil {
+				break
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		tc := a.Config.Targets[n]
+		r.started(n)
+		wg.Add(1)
+		go func(tc *types.TargetConfig) {
+			defer wg.Done()
+			if sem != nil {
+				defer sem.Release(1)
+			}
+			fn(ctx, tc)
+			r.done(tc.Name)
+		}(tc)
+	}
+	wg.Wait()
+	if a.Config.Summary {
+		a.printLock.Lock()
+		r.writeSummary(os.Stderr)
+		a.printLock.Unlock()
+	}
+}
+
+// annotateReport adds the targets durations and the skipped targets to the errors report.
+func (r *targetsRun) annotateReport(er *ErrorReport, skipped []string) {
+	for _, tr := range er.Targets {
+		if d := r.duration(tr.Name); d > 0 {
+			tr.Duration = d.Round(time.Millisecond).String()
+		}
+	}
+	for _, n := range skipped {
+		er.Targets = append(er.Targets, &TargetErrorReport{Name: n, Status: runStatusSkipped})
+	}
+	sort.Slice(er.Targets, func(i, j int) bool {
+		return er.Targets[i].Name < er.Targets[j].Name
+	})
+}
@@ -0,0 +1,78 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+func newTargetsRunTestApp(numTargets int) *App {
+	a := New()
+	for i := 0; i < numTargets; i++ {
+		n := fmt.Sprintf("t%02d", i)
+		a.Config.Targets[n] = &types.TargetConfig{Name: n}
+	}
+	return a
+}
+
+func TestRunTargetsMaxConcurrency(t *testing.T) {
+	a := newTargetsRunTestApp(10)
+	a.Config.MaxConcurrency = 3
+	var running, maxRunning int32
+	calls := new(sync.Map)
+	a.runTargets(context.Background(), func(ctx context.Context, tc *types.TargetConfig) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		calls.Store(tc.Name, true)
+	})
+	if maxRunning > 3 {
+		t.Errorf("got %d concurrent calls, want at most 3", maxRunning)
+	}
+	for n := range a.Config.Targets {
+		if _, ok := calls.Load(n); !ok {
+			t.Errorf("target %q not called", n)
+		}
+	}
+}
+
+func TestRunTargetsFailFast(t *testing.T) {
+	a := newTargetsRunTestApp(5)
+	a.Config.MaxConcurrency = 1
+	a.Config.FailFast = true
+	a.Config.Log = true // do not print errors to stderr
+	a.errCh = make(chan error, 5)
+	a.runTargets(context.Background(), func(ctx context.Context, tc *types.TargetConfig) {
+		if tc.Name == "t01" {
+			a.logTargetError(tc.Name, errors.New("failed"))
+		}
+	})
+	skipped := a.targetsRun.skipped()
+	if len(skipped) != 3 || skipped[0] != "t02" {
+		t.Errorf("unexpected skipped targets: %v", skipped)
+	}
+	err := a.checkErrors()
+	if ExitCode(err) != ExitCodePartial {
+		t.Errorf("got exit code %d, want %d", ExitCode(err), ExitCodePartial)
+	}
+}
@@ -0,0 +1,153 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/openconfig/gnmic/pkg/api/utils"
+	"github.com/openconfig/gnmic/pkg/gtemplate"
+)
+
+const defaultTemplateTestField = "msg-template"
+
+func (a *App) TemplateTestPreRunE(cmd *cobra.Command, args []string) error {
+	lf := a.Config.LocalFlags
+	n := 0
+	for _, s := range []string{lf.TemplateTestTemplate, lf.TemplateTestTemplateFile, lf.TemplateTestOutput} {
+		if s != "" {
+			n++
+		}
+	}
+	if n != 1 {
+		return errors.New("exactly one of --template, --template-file or --output must be set")
+	}
+	if lf.TemplateTestTimeout < 0 {
+		return fmt.Errorf("invalid timeout %s", lf.TemplateTestTimeout)
+	}
+	if lf.TemplateTestMaxOutputSize < 0 {
+		return fmt.Errorf("invalid max-output-size %d", lf.TemplateTestMaxOutputSize)
+	}
+	return nil
+}
+
+func (a *App) TemplateTestRunE(cmd *cobra.Command, args []string) error {
+	name, text, err := a.templateTestText()
+	if err != nil {
+		return err
+	}
+	tpl, err := gtemplate.CreateTemplate(name, text)
+	if err != nil {
+		fmt.Fprint(os.Stderr, gtemplate.FormatError(text, err))
+		return fmt.Errorf("failed to parse template %q", name)
+	}
+	input, err := readTemplateInput(a.Config.LocalFlags.TemplateTestInput)
+	if err != nil {
+		return err
+	}
+	limits := gtemplate.DefaultLimits()
+	if a.Config.LocalFlags.TemplateTestTimeout > 0 {
+		limits.Timeout = a.Config.LocalFlags.TemplateTestTimeout
+	}
+	if a.Config.LocalFlags.TemplateTestMaxOutputSize > 0 {
+		limits.MaxOutputSize = a.Config.LocalFlags.TemplateTestMaxOutputSize
+	}
+	b := new(bytes.Buffer)
+	start := time.Now()
+	err = gtemplate.ExecuteWithLimits(b, tpl, input, limits)
+	took := time.Since(start)
+	if err != nil {
+		fmt.Fprint(os.Stderr, gtemplate.FormatError(text, err))
+		return fmt.Errorf("failed to execute template %q", name)
+	}
+	_, err = a.out.Write(b.Bytes())
+	if err != nil {
+		return err
+	}
+	if b.Len() > 0 && b.Bytes()[b.Len()-1] != '\n' {
+		fmt.Fprintln(a.out)
+	}
+	fmt.Fprintf(os.Stderr, "template %q rendered %d bytes in %s\n", name, b.Len(), took)
+	return nil
+}
+
+// templateTestText returns the name and text of the tested template,
+// from the command flags or from a configured output.
+func (a *App) templateTestText() (string, string, error) {
+	lf := a.Config.LocalFlags
+	switch {
+	case lf.TemplateTestTemplate != "":
+		return "template", lf.TemplateTestTemplate, nil
+	case lf.TemplateTestTemplateFile != "":
+		b, err := os.ReadFile(lf.TemplateTestTemplateFile)
+		if err != nil {
+			return "", "", err
+		}
+		return lf.TemplateTestTemplateFile, string(b), nil
+	}
+	outs, err := a.Config.GetOutputs()
+	if err != nil {
+		return "", "", err
+	}
+	outCfg, ok := outs[lf.TemplateTestOutput]
+	if !ok {
+		return "", "", fmt.Errorf("unknown output %q", lf.TemplateTestOutput)
+	}
+	field := lf.TemplateTestField
+	if field == "" {
+		field = defaultTemplateTestField
+	}
+	text, ok := outCfg[field].(string)
+	if !ok || text == "" {
+		return "", "", fmt.Errorf("output %q has no %q", lf.TemplateTestOutput, field)
+	}
+	return fmt.Sprintf("%s/%s", lf.TemplateTestOutput, field), text, nil
+}
+
+// readTemplateInput reads the template input data from a JSON or YAML file.
+// It returns nil if path is empty.
+func readTemplateInput(path string) (interface{}, error) {
+	if path == "" {
+		return nil, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var input interface{}
+	err = json.Unmarshal(b, &input)
+	if err == nil {
+		return input, nil
+	}
+	err = yaml.Unmarshal(b, &input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input file %s: not valid JSON or YAML: %v", path, err)
+	}
+	return utils.Convert(input), nil
+}
+
+func (a *App) InitTemplateTestFlags(cmd *cobra.Command) {
+	cmd.ResetFlags()
+
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.TemplateTestTemplate, "template", "", "", "template text to render")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.TemplateTestTemplateFile, "template-file", "", "", "file containing the template to render")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.TemplateTestOutput, "output", "", "", "name of a configured output whose template is rendered")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.TemplateTestField, "field", "", defaultTemplateTestField, "output template field to render when --output is set, e.g msg-template or target-template")
+	cmd.Flags().StringVarP(&a.Config.LocalFlags.TemplateTestInput, "input", "", "", "JSON or YAML file containing the sample data the template is rendered against")
+	cmd.Flags().DurationVarP(&a.Config.LocalFlags.TemplateTestTimeout, "timeout", "", 0, "template execution timeout, defaults to the template-limits config")
+	cmd.Flags().IntVarP(&a.Config.LocalFlags.TemplateTestMaxOutputSize, "max-output-size", "", 0, "maximum rendered output size in bytes, defaults to the template-limits config")
+}
@@ -0,0 +1,45 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadTemplateInput(t *testing.T) {
+	want := map[string]interface{}{
+		"source": "router1",
+		"values": map[string]interface{}{"a": float64(1)},
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"input.json": `{"source": "router1", "values": {"a": 1}}`,
+		"input.yaml": "source: router1\nvalues:\n  a: 1.0\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := readTemplateInput(path)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %#v, want %#v", name, got, want)
+		}
+	}
+	got, err := readTemplateInput("")
+	if err != nil || got != nil {
+		t.Errorf("empty path: got %v, %v", got, err)
+	}
+}
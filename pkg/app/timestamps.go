@@ -0,0 +1,96 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openconfig/gnmic/pkg/config"
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+// outputTimestamps returns the timestamps configuration of the output name,
+// nil if it has none.
+func (a *App) outputTimestamps(name string) *config.OutputTimestamps {
+	a.operLock.RLock()
+	defer a.operLock.RUnlock()
+	return a.outputsTimestamps[name]
+}
+
+// applyTimestampsResponse returns the response and meta to write to the output name
+// after applying its timestamps configuration ts, or a nil response if the response is rejected.
+// rsp and m are shared between the outputs so they are copied before being modified.
+func (a *App) applyTimestampsResponse(name string, ts *config.OutputTimestamps, rsp *gnmi.SubscribeResponse, m outputs.Meta, now time.Time) (*gnmi.SubscribeResponse, outputs.Meta) {
+	n := rsp.GetUpdate()
+	if n == nil || n.GetTimestamp() == 0 {
+		return rsp, m
+	}
+	t, ok := ts.Bound(n.GetTimestamp(), now)
+	if !ok {
+		a.stats.timestampSkewed(m["source"], name, ts.SkewAction, 1)
+		return nil, m
+	}
+	if t != n.GetTimestamp() {
+		a.stats.timestampSkewed(m["source"], name, ts.SkewAction, 1)
+		rsp = proto.Clone(rsp).(*gnmi.SubscribeResponse)
+		rsp.GetUpdate().Timestamp = t
+	}
+	if ts.Field != "" {
+		nm := make(outputs.Meta, len(m)+1)
+		for k, v := range m {
+			nm[k] = v
+		}
+		nm[ts.Field] = ts.Format(t)
+		m = nm
+	}
+	return rsp, m
+}
+
+// applyTimestampsEvents returns the events of target source to write to the output name
+// after applying its timestamps configuration ts.
+// evs are shared between the outputs so the modified events are copied.
+func (a *App) applyTimestampsEvents(name, source string, ts *config.OutputTimestamps, evs []*formatters.EventMsg, now time.Time) []*formatters.EventMsg {
+	res := make([]*formatters.EventMsg, 0, len(evs))
+	skewed := 0
+	for _, ev := range evs {
+		if ev.Timestamp == 0 {
+			res = append(res, ev)
+			continue
+		}
+		t, ok := ts.Bound(ev.Timestamp, now)
+		if t != ev.Timestamp || !ok {
+			skewed++
+		}
+		if !ok {
+			continue
+		}
+		if t == ev.Timestamp && ts.Field == "" {
+			res = append(res, ev)
+			continue
+		}
+		c := *ev
+		c.Timestamp = t
+		if ts.Field != "" {
+			c.Tags = make(map[string]string, len(ev.Tags)+1)
+			for k, v := range ev.Tags {
+				c.Tags[k] = v
+			}
+			c.Tags[ts.Field] = ts.Format(t)
+		}
+		res = append(res, &c)
+	}
+	if skewed > 0 {
+		a.stats.timestampSkewed(source, name, ts.SkewAction, skewed)
+	}
+	return res
+}
@@ -0,0 +1,90 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/config"
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+func TestApplyTimestampsResponse(t *testing.T) {
+	now := time.Unix(1000, 0)
+	ts, err := config.GetOutputTimestamps(map[string]interface{}{
+		"timestamps": map[string]interface{}{
+			"field":       "time",
+			"layout":      "RFC3339",
+			"max-past":    "1h",
+			"skew-action": "clamp",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := New()
+	m := outputs.Meta{"source": "r1"}
+	rsp := intResponse(now.Add(-2*time.Hour).UnixNano(), map[string]int64{"a": 1})
+	r, om := a.applyTimestampsResponse("out1", ts, rsp, m, now)
+	if r == nil {
+		t.Fatalf("response rejected")
+	}
+	if got := r.GetUpdate().GetTimestamp(); got != now.UnixNano() {
+		t.Errorf("timestamp: got %d, want %d", got, now.UnixNano())
+	}
+	if got := rsp.GetUpdate().GetTimestamp(); got != now.Add(-2*time.Hour).UnixNano() {
+		t.Errorf("shared response modified")
+	}
+	if got := om["time"]; got != "1970-01-01T00:16:40Z" {
+		t.Errorf("field: got %q", got)
+	}
+	if _, ok := m["time"]; ok {
+		t.Errorf("shared meta modified")
+	}
+	ts.SkewAction = config.TimestampsSkewActionReject
+	if r, _ := a.applyTimestampsResponse("out1", ts, rsp, m, now); r != nil {
+		t.Errorf("expected the response to be rejected")
+	}
+	if got := a.stats.snapshot().Targets["r1"].SkewedTimestamps; got != 2 {
+		t.Errorf("skewed timestamps: got %d, want 2", got)
+	}
+}
+
+func TestApplyTimestampsEvents(t *testing.T) {
+	now := time.Unix(1000, 0)
+	ts, err := config.GetOutputTimestamps(map[string]interface{}{
+		"timestamps": map[string]interface{}{
+			"field":      "time",
+			"max-future": "1m",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := New()
+	evs := []*formatters.EventMsg{
+		{Name: "ok", Timestamp: now.UnixNano(), Tags: map[string]string{"source": "r1"}},
+		{Name: "future", Timestamp: now.Add(time.Hour).UnixNano(), Tags: map[string]string{"source": "r1"}},
+	}
+	res := a.applyTimestampsEvents("out1", "r1", ts, evs, now)
+	if len(res) != 1 || res[0].Name != "ok" {
+		t.Fatalf("unexpected events: %+v", res)
+	}
+	if got := res[0].Tags["time"]; got != "1970-01-01T00:16:40Z" {
+		t.Errorf("field: got %q", got)
+	}
+	if _, ok := evs[0].Tags["time"]; ok {
+		t.Errorf("shared event modified")
+	}
+	if got := a.stats.snapshot().Targets["r1"].SkewedTimestamps; got != 1 {
+		t.Errorf("skewed timestamps: got %d, want 1", got)
+	}
+}
@@ -14,6 +14,8 @@ import (
 	"io"
 	"net"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -105,8 +107,16 @@ func (a *App) gRPCTunnelServerOpts() ([]grpc.ServerOption, error) {
 			grpc.UnaryInterceptor(grpcMetrics.UnaryServerInterceptor()),
 		)
 		a.reg.MustRegister(grpcMetrics)
+		a.registerTunnelServerMetrics()
 	}
 
+	if a.tunnelServerUsesCert() {
+		tlscfg, err := a.certServerTLSConfig(certTunnelServer, a.Config.Cert.TunnelServer, a.Config.TunnelServer.Address)
+		if err != nil {
+			return nil, err
+		}
+		return append(opts, grpc.Creds(credentials.NewTLS(tlscfg))), nil
+	}
 	if a.Config.TunnelServer.TLS == nil {
 		return opts, nil
 	}
@@ -131,28 +141,32 @@ func (a *App) gRPCTunnelServerOpts() ([]grpc.ServerOption, error) {
 
 func (a *App) tunServerAddTargetHandler(tt tunnel.Target) error {
 	a.Logger.Printf("tunnel server discovered target %+v", tt)
-	tc := a.getTunnelTargetMatch(tt)
+	tc, group := a.getTunnelTargetMatch(tt)
 	if tc == nil {
 		a.Logger.Printf("target %+v ignored", tt)
+		a.tunnelTargetRejected(tt, group)
 		return nil
 	}
 	a.ttm.Lock()
-	a.tunTargets[tt] = struct{}{}
+	a.tunTargets[tt] = group
 	a.ttm.Unlock()
+	a.tunnelSessionUp(tt, group)
 	return nil
 }
 
 func (a *App) tunServerAddTargetSubscribeHandler(tt tunnel.Target) error {
 	a.Logger.Printf("tunnel server discovered target %+v", tt)
-	tc := a.getTunnelTargetMatch(tt)
+	tc, group := a.getTunnelTargetMatch(tt)
 	if tc == nil {
 		a.Logger.Printf("target %+v ignored", tt)
+		a.tunnelTargetRejected(tt, group)
 		return nil
 	}
 	a.ttm.Lock()
-	a.tunTargets[tt] = struct{}{}
+	a.tunTargets[tt] = group
 	a.AddTargetConfig(tc)
 	a.ttm.Unlock()
+	a.tunnelSessionUp(tt, group)
 
 	a.operLock.Lock()
 	t, err := a.initTarget(tc)
@@ -170,10 +184,13 @@ func (a *App) tunServerDeleteTargetHandler(tt tunnel.Target) error {
 	a.Logger.Printf("tunnel server target %+v deregister request", tt)
 	a.ttm.Lock()
 	defer a.ttm.Unlock()
+	if group, ok := a.tunTargets[tt]; ok {
+		a.tunnelSessionDown(tt, group)
+		delete(a.tunTargets, tt)
+	}
 	if cfn, ok := a.tunTargetCfn[tt]; ok {
 		cfn()
 		delete(a.tunTargetCfn, tt)
-		delete(a.tunTargets, tt)
 		a.configLock.Lock()
 		delete(a.Config.Targets, tt.ID)
 		a.configLock.Unlock()
@@ -208,7 +225,10 @@ func (a *App) tunDialerFn(ctx context.Context, tc *types.TargetConfig) func(cont
 	}
 }
 
-func (a *App) getTunnelTargetMatch(tt tunnel.Target) *types.TargetConfig {
+// getTunnelTargetMatch returns the target config built for the discovered tunnel target
+// and the name of the group it matched.
+// A nil config is returned if the target is not accepted.
+func (a *App) getTunnelTargetMatch(tt tunnel.Target) (*types.TargetConfig, string) {
 	if len(a.Config.TunnelServer.Targets) == 0 {
 		// no target matches defined, accept only GNMI_GNOI type
 		if tt.Type == "GNMI_GNOI" {
@@ -217,14 +237,14 @@ func (a *App) getTunnelTargetMatch(tt tunnel.Target) *types.TargetConfig {
 			err := a.Config.SetTargetConfigDefaults(tc)
 			if err != nil {
 				a.Logger.Printf("failed to set target %q config defaults: %v", tt.ID, err)
-				return nil
+				return nil, ""
 			}
 			tc.Address = tc.Name
-			return tc
+			return tc, ""
 		}
-		return nil
+		return nil, ""
 	}
-	for _, tm := range a.Config.TunnelServer.Targets {
+	for idx, tm := range a.Config.TunnelServer.Targets {
 		// check if the discovered target matches one of the configured types
 		ok, err := regexp.MatchString(tm.Type, tt.Type)
 		if err != nil {
@@ -244,20 +264,38 @@ func (a *App) getTunnelTargetMatch(tt tunnel.Target) *types.TargetConfig {
 			continue
 		}
 		// target has a match
+		group := tm.Name
+		if group == "" {
+			group = strconv.Itoa(idx)
+		}
 		if a.Config.Debug {
-			a.Logger.Printf("target %+v matches %+v", tt, tm)
+			a.Logger.Printf("target %+v matches group %q: %+v", tt, group, tm)
+		}
+		if tm.Reject() {
+			a.Logger.Printf("target %+v rejected by group %q", tt, group)
+			return nil, group
 		}
 		tc := new(types.TargetConfig)
 		*tc = tm.Config
 		tc.Name = tt.ID
 		tc.TunnelTargetType = tt.Type
+		// copy the slices and maps, since they are shared
+		// between the targets of the same group.
+		tc.Subscriptions = append(make([]string, 0, len(tm.Config.Subscriptions)), tm.Config.Subscriptions...)
+		tc.Outputs = append(make([]string, 0, len(tm.Config.Outputs)), tm.Config.Outputs...)
+		tc.Tags = append(make([]string, 0, len(tm.Config.Tags)), tm.Config.Tags...)
+		for _, sub := range a.Config.TunnelTargetSubscriptions(tm) {
+			if !slices.Contains(tc.Subscriptions, sub) {
+				tc.Subscriptions = append(tc.Subscriptions, sub)
+			}
+		}
 		err = a.Config.SetTargetConfigDefaults(tc)
 		if err != nil {
 			a.Logger.Printf("failed to set target %q config defaults: %v", tt.ID, err)
 			continue
 		}
 		tc.Address = tc.Name
-		return tc
+		return tc, group
 	}
-	return nil
+	return nil, ""
 }
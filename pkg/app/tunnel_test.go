@@ -0,0 +1,190 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/openconfig/grpctunnel/tunnel"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+func newTunnelApp(t *testing.T, in string) *App {
+	a := New()
+	a.Config.FileConfig.SetConfigType("yaml")
+	if err := a.Config.FileConfig.ReadConfig(bytes.NewBufferString(in)); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Config.GetTunnelServer(); err != nil {
+		t.Fatal(err)
+	}
+	a.Config.UseTunnelServer = true
+	a.Config.Subscriptions = map[string]*types.SubscriptionConfig{
+		"sub1":   {Name: "sub1"},
+		"sub2":   {Name: "sub2"},
+		"alarms": {Name: "alarms"},
+	}
+	return a
+}
+
+func TestGetTunnelTargetMatch(t *testing.T) {
+	type want struct {
+		accepted bool
+		group    string
+		subs     []string
+		tags     []string
+	}
+	tests := []struct {
+		name string
+		in   string
+		tt   tunnel.Target
+		want want
+	}{
+		{
+			name: "no_matches_gnmi",
+			in:   "tunnel-server: {}\n",
+			tt:   tunnel.Target{ID: "leaf1", Type: "GNMI_GNOI"},
+			want: want{accepted: true},
+		},
+		{
+			name: "no_matches_other_type",
+			in:   "tunnel-server: {}\n",
+			tt:   tunnel.Target{ID: "leaf1", Type: "SSH"},
+		},
+		{
+			name: "rejected_group",
+			in: `
+tunnel-server:
+  targets:
+    - name: lab
+      id: ^lab
+      action: reject
+    - id: .*
+`,
+			tt:   tunnel.Target{ID: "lab1", Type: "GNMI_GNOI"},
+			want: want{group: "lab"},
+		},
+		{
+			name: "first_match_wins",
+			in: `
+tunnel-server:
+  targets:
+    - type: GNMI_GNOI
+      id: ^leaf
+      subscriptions: [^sub]
+      config:
+        subscriptions: [alarms, sub1]
+        tags: [leaf]
+    - id: .*
+`,
+			tt: tunnel.Target{ID: "leaf1", Type: "GNMI_GNOI"},
+			want: want{
+				accepted: true,
+				group:    "0",
+				subs:     []string{"alarms", "sub1", "sub2"},
+				tags:     []string{"leaf"},
+			},
+		},
+		{
+			name: "unnamed_group_index",
+			in: `
+tunnel-server:
+  targets:
+    - id: ^leaf
+    - type: GNMI_GNOI
+`,
+			tt:   tunnel.Target{ID: "spine1", Type: "GNMI_GNOI"},
+			want: want{accepted: true, group: "1", subs: []string{}, tags: []string{}},
+		},
+		{
+			name: "type_mismatch",
+			in: `
+tunnel-server:
+  targets:
+    - type: ^GNMI_GNOI$
+`,
+			tt: tunnel.Target{ID: "leaf1", Type: "SSH"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTunnelApp(t, tt.in)
+			tc, group := a.getTunnelTargetMatch(tt.tt)
+			if (tc != nil) != tt.want.accepted {
+				t.Fatalf("got accepted=%v, want %v", tc != nil, tt.want.accepted)
+			}
+			if group != tt.want.group {
+				t.Errorf("got group %q, want %q", group, tt.want.group)
+			}
+			if tc == nil {
+				return
+			}
+			if tc.Name != tt.tt.ID || tc.Address != tt.tt.ID || tc.TunnelTargetType != tt.tt.Type {
+				t.Errorf("unexpected target name=%q address=%q type=%q", tc.Name, tc.Address, tc.TunnelTargetType)
+			}
+			if tt.want.subs != nil && !reflect.DeepEqual(tc.Subscriptions, tt.want.subs) {
+				t.Errorf("got subscriptions %v, want %v", tc.Subscriptions, tt.want.subs)
+			}
+			if tt.want.tags != nil && !reflect.DeepEqual(tc.Tags, tt.want.tags) {
+				t.Errorf("got tags %v, want %v", tc.Tags, tt.want.tags)
+			}
+		})
+	}
+}
+
+func TestGetTunnelTargetMatchCopiesGroupConfig(t *testing.T) {
+	a := newTunnelApp(t, `
+tunnel-server:
+  targets:
+    - id: .*
+      config:
+        subscriptions: [sub1]
+`)
+	tc1, _ := a.getTunnelTargetMatch(tunnel.Target{ID: "leaf1", Type: "GNMI_GNOI"})
+	tc1.Subscriptions[0] = "modified"
+	tc2, _ := a.getTunnelTargetMatch(tunnel.Target{ID: "leaf2", Type: "GNMI_GNOI"})
+	if !reflect.DeepEqual(tc2.Subscriptions, []string{"sub1"}) {
+		t.Errorf("targets of the same group share their config: %v", tc2.Subscriptions)
+	}
+}
+
+func TestTunnelTargetRejectedMetric(t *testing.T) {
+	a := newTunnelApp(t, `
+tunnel-server:
+  enable-metrics: true
+  targets:
+    - name: lab
+      id: ^lab
+      action: reject
+`)
+	a.reg = prometheus.NewRegistry()
+	tt := tunnel.Target{ID: "lab1", Type: "GNMI_GNOI"}
+	rejected := tunnelServerTargetRegistrations.WithLabelValues("lab", tt.Type, "rejected")
+	before := testutil.ToFloat64(rejected)
+	// matching the target again, e.g. when listing the targets, is not a registration
+	for i := 0; i < 3; i++ {
+		if tc, _ := a.getTunnelTargetMatch(tt); tc != nil {
+			t.Fatal("expected the target to be rejected")
+		}
+	}
+	if n := testutil.ToFloat64(rejected) - before; n != 0 {
+		t.Fatalf("got %v rejected registrations after matching, want 0", n)
+	}
+	if err := a.tunServerAddTargetHandler(tt); err != nil {
+		t.Fatal(err)
+	}
+	if n := testutil.ToFloat64(rejected) - before; n != 1 {
+		t.Fatalf("got %v rejected registrations, want 1", n)
+	}
+}
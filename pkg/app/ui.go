@@ -0,0 +1,245 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+const (
+	uiPathPrefix = "/ui/"
+	// size of the channel buffering the events sent to a single UI events stream,
+	// events are dropped for that stream if it is full.
+	uiEventsStreamBufferSize = 100
+	uiEventsKeepAlive        = 15 * time.Second
+)
+
+//go:embed ui
+var uiFiles embed.FS
+
+// uiState holds the data collected for the web UI,
+// it is only created if api-server.enable-ui is set.
+type uiState struct {
+	m           *sync.RWMutex
+	stats       map[string]*subscriptionStats
+	subscribers map[chan *formatters.EventMsg]struct{}
+}
+
+type subscriptionStats struct {
+	Target        string    `json:"target,omitempty"`
+	Subscription  string    `json:"subscription,omitempty"`
+	Responses     uint64    `json:"responses"`
+	SyncResponses uint64    `json:"sync-responses"`
+	Updates       uint64    `json:"updates"`
+	Deletes       uint64    `json:"deletes"`
+	LastResponse  time.Time `json:"last-response,omitempty"`
+}
+
+type targetStatus struct {
+	Name          string   `json:"name,omitempty"`
+	Address       string   `json:"address,omitempty"`
+	State         string   `json:"state,omitempty"`
+	Subscriptions []string `json:"subscriptions,omitempty"`
+}
+
+func newUIState() *uiState {
+	return &uiState{
+		m:           new(sync.RWMutex),
+		stats:       make(map[string]*subscriptionStats),
+		subscribers: make(map[chan *formatters.EventMsg]struct{}),
+	}
+}
+
+func (a *App) uiRoutes(r *mux.Router) {
+	r.HandleFunc("/status/targets", adminOnly(a.handleStatusTargetsGet)).Methods(http.MethodGet)
+	r.HandleFunc("/status/subscriptions", adminOnly(a.handleStatusSubscriptionsGet)).Methods(http.MethodGet)
+	r.HandleFunc("/events", adminOnly(a.handleEventsStream)).Methods(http.MethodGet)
+
+	files, err := fs.Sub(uiFiles, "ui")
+	if err != nil {
+		a.Logger.Printf("failed to load UI files: %v", err)
+		return
+	}
+	a.router.PathPrefix(uiPathPrefix).Handler(http.StripPrefix(uiPathPrefix, http.FileServer(http.FS(files))))
+	a.router.Handle("/", http.RedirectHandler(uiPathPrefix, http.StatusFound))
+}
+
+// updateUIState updates the subscription statistics with the received response
+// and sends the corresponding events to the connected UI event streams.
+func (a *App) updateUIState(rsp *gnmi.SubscribeResponse, m outputs.Meta) {
+	if a.ui == nil {
+		return
+	}
+	key := m["source"] + "/" + m["subscription-name"]
+	a.ui.m.Lock()
+	st, ok := a.ui.stats[key]
+	if !ok {
+		st = &subscriptionStats{
+			Target:       m["source"],
+			Subscription: m["subscription-name"],
+		}
+		a.ui.stats[key] = st
+	}
+	st.Responses++
+	st.LastResponse = time.Now()
+	switch rsp := rsp.GetResponse().(type) {
+	case *gnmi.SubscribeResponse_SyncResponse:
+		st.SyncResponses++
+	case *gnmi.SubscribeResponse_Update:
+		st.Updates += uint64(len(rsp.Update.GetUpdate()))
+		st.Deletes += uint64(len(rsp.Update.GetDelete()))
+	}
+	numSubscribers := len(a.ui.subscribers)
+	a.ui.m.Unlock()
+
+	if numSubscribers == 0 {
+		return
+	}
+	evs, err := formatters.ResponseToEventMsgs(m["subscription-name"], rsp, m)
+	if err != nil {
+		a.Logger.Printf("failed to convert response from %q to events: %v", m["source"], err)
+		return
+	}
+	a.ui.m.RLock()
+	defer a.ui.m.RUnlock()
+	for ch := range a.ui.subscribers {
+		for _, ev := range evs {
+			select {
+			case ch <- ev:
+			default:
+			}
+		}
+	}
+}
+
+// deleteTarget removes the subscription statistics of target.
+func (s *uiState) deleteTarget(target string) {
+	if s == nil {
+		return
+	}
+	s.m.Lock()
+	defer s.m.Unlock()
+	for key, st := range s.stats {
+		if st.Target == target {
+			delete(s.stats, key)
+		}
+	}
+}
+
+// targetsStatus returns the status of the targets known to the collector sorted by name.
+func (a *App) targetsStatus() []*targetStatus {
+	a.operLock.RLock()
+	defer a.operLock.RUnlock()
+	result := make([]*targetStatus, 0, len(a.Targets))
+	for name, t := range a.Targets {
+		ts := &targetStatus{
+			Name:          name,
+			Address:       t.Config.Address,
+			State:         t.ConnState(),
+			Subscriptions: make([]string, 0, len(t.Subscriptions)),
+		}
+		if ts.State == "" {
+			ts.State = "NOT CONNECTED"
+		}
+		for sn := range t.Subscriptions {
+			ts.Subscriptions = append(ts.Subscriptions, sn)
+		}
+		sort.Strings(ts.Subscriptions)
+		result = append(result, ts)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func (a *App) handleStatusTargetsGet(w http.ResponseWriter, r *http.Request) {
+	a.handlerCommonGet(w, a.targetsStatus())
+}
+
+func (a *App) handleStatusSubscriptionsGet(w http.ResponseWriter, r *http.Request) {
+	a.ui.m.RLock()
+	stats := make([]subscriptionStats, 0, len(a.ui.stats))
+	for _, st := range a.ui.stats {
+		stats = append(stats, *st)
+	}
+	a.ui.m.RUnlock()
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Target == stats[j].Target {
+			return stats[i].Subscription < stats[j].Subscription
+		}
+		return stats[i].Target < stats[j].Target
+	})
+	a.handlerCommonGet(w, stats)
+}
+
+// handleEventsStream streams the received events as Server-Sent Events,
+// optionally filtered by the target and subscription query parameters.
+func (a *App) handleEventsStream(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+	// the stream outlives the API server write timeout
+	err := rc.SetWriteDeadline(time.Time{})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(APIErrors{Errors: []string{err.Error()}})
+		return
+	}
+	filter := promptEventsFilter(r.URL.Query().Get("target"), r.URL.Query().Get("subscription"))
+
+	ch := make(chan *formatters.EventMsg, uiEventsStreamBufferSize)
+	a.ui.m.Lock()
+	a.ui.subscribers[ch] = struct{}{}
+	a.ui.m.Unlock()
+	defer func() {
+		a.ui.m.Lock()
+		delete(a.ui.subscribers, ch)
+		a.ui.m.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	rc.Flush()
+
+	ticker := time.NewTicker(uiEventsKeepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			fmt.Fprint(w, ": keepalive\n\n")
+		case ev := <-ch:
+			if !filter(ev) {
+				continue
+			}
+			b, err := json.Marshal(ev)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "data: %s\n\n", b)
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,186 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/openconfig/gnmic/pkg/api/target"
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/config"
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+func newUIApp() *App {
+	a := New()
+	a.Config.APIServer = &config.APIServer{Tokens: []string{"admin"}, EnableUI: true}
+	a.Config.Targets = map[string]*types.TargetConfig{
+		"r1": {Name: "r1", Address: "10.0.0.1:57400"},
+		"r2": {Name: "r2", Address: "10.0.0.2:57400"},
+	}
+	for name, tc := range a.Config.Targets {
+		tg := target.NewTarget(tc)
+		tg.Subscriptions = map[string]*types.SubscriptionConfig{"sub1": {Name: "sub1"}}
+		a.Targets[name] = tg
+	}
+	a.routes()
+	return a
+}
+
+func uiUpdate(names ...string) *gnmi.SubscribeResponse {
+	n := &gnmi.Notification{Timestamp: 1}
+	for _, name := range names {
+		n.Update = append(n.Update, &gnmi.Update{
+			Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: name}}},
+			Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_IntVal{IntVal: 1}},
+		})
+	}
+	return &gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_Update{Update: n}}
+}
+
+func TestUIStatus(t *testing.T) {
+	a := newUIApp()
+	sync := &gnmi.SubscribeResponse{Response: &gnmi.SubscribeResponse_SyncResponse{SyncResponse: true}}
+	r1 := outputs.Meta{"source": "r1", "subscription-name": "sub1"}
+	r2 := outputs.Meta{"source": "r2", "subscription-name": "sub1"}
+	a.updateUIState(uiUpdate("a", "b"), r1)
+	a.updateUIState(sync, r1)
+	a.updateUIState(uiUpdate("a"), r2)
+
+	rec := apiRequest(a, http.MethodGet, "/api/v1/status/targets", "admin", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body.String())
+	}
+	var ts []*targetStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &ts); err != nil {
+		t.Fatal(err)
+	}
+	if len(ts) != 2 || ts[0].Name != "r1" || ts[1].Name != "r2" {
+		t.Fatalf("unexpected targets status: %+v", ts)
+	}
+	if ts[0].State != "NOT CONNECTED" || ts[0].Address != "10.0.0.1:57400" || len(ts[0].Subscriptions) != 1 {
+		t.Errorf("unexpected target r1 status: %+v", ts[0])
+	}
+
+	rec = apiRequest(a, http.MethodGet, "/api/v1/status/subscriptions", "admin", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body.String())
+	}
+	var stats []subscriptionStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("got %d subscription stats, want 2", len(stats))
+	}
+	if st := stats[0]; st.Target != "r1" || st.Responses != 2 || st.SyncResponses != 1 || st.Updates != 2 {
+		t.Errorf("unexpected r1 stats: %+v", st)
+	}
+	if st := stats[1]; st.Target != "r2" || st.Responses != 1 || st.Updates != 1 {
+		t.Errorf("unexpected r2 stats: %+v", st)
+	}
+
+	// the stats of a deleted target are removed
+	if err := a.DeleteTarget(context.Background(), "r1"); err != nil {
+		t.Fatal(err)
+	}
+	rec = apiRequest(a, http.MethodGet, "/api/v1/status/subscriptions", "admin", "")
+	stats = nil
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 1 || stats[0].Target != "r2" {
+		t.Errorf("unexpected stats after deleting r1: %+v", stats)
+	}
+
+	rec = apiRequest(a, http.MethodGet, "/api/v1/status/targets", "", "")
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401 without a token, got %d", rec.Code)
+	}
+}
+
+func TestUIFiles(t *testing.T) {
+	a := newUIApp()
+	rec := apiRequest(a, http.MethodGet, "/", "", "")
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != uiPathPrefix {
+		t.Errorf("expected a redirect to %s, got %d %q", uiPathPrefix, rec.Code, rec.Header().Get("Location"))
+	}
+	rec = apiRequest(a, http.MethodGet, uiPathPrefix, "", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "<html") {
+		t.Errorf("expected the UI index, got %d", rec.Code)
+	}
+}
+
+func TestUIEventsStream(t *testing.T) {
+	a := newUIApp()
+	srv := httptest.NewServer(a.router)
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/api/v1/events?target=r2", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer admin")
+	rsp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK || rsp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected response %d %q", rsp.StatusCode, rsp.Header.Get("Content-Type"))
+	}
+	// the headers are sent once the stream is registered
+	a.updateUIState(uiUpdate("a"), outputs.Meta{"source": "r1", "subscription-name": "sub1"})
+	a.updateUIState(uiUpdate("b"), outputs.Meta{"source": "r2", "subscription-name": "sub1"})
+
+	lines := make(chan string)
+	go func() {
+		sc := bufio.NewScanner(rsp.Body)
+		for sc.Scan() {
+			lines <- sc.Text()
+		}
+		close(lines)
+	}()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case l, ok := <-lines:
+			if !ok {
+				t.Fatal("events stream closed")
+			}
+			data, ok := strings.CutPrefix(l, "data: ")
+			if !ok {
+				continue
+			}
+			ev := new(formatters.EventMsg)
+			if err = json.Unmarshal([]byte(data), ev); err != nil {
+				t.Fatal(err)
+			}
+			if ev.Tags["source"] != "r2" {
+				t.Fatalf("unexpected event from %q", ev.Tags["source"])
+			}
+			if _, ok := ev.Values["/b"]; !ok {
+				t.Errorf("unexpected event values: %v", ev.Values)
+			}
+			return
+		case <-timeout:
+			t.Fatal("timeout waiting for an event")
+		}
+	}
+}
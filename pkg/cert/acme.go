@@ -0,0 +1,448 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cert
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+const (
+	ACMEChallengeHTTP01    = "http-01"
+	ACMEChallengeTLSALPN01 = "tls-alpn-01"
+	ACMEChallengeDNS01     = "dns-01"
+
+	defaultACMEHTTPAddress      = ":80"
+	defaultACMERenewBefore      = 30 * 24 * time.Hour
+	defaultACMEPropagationDelay = 30 * time.Second
+	defaultACMECommandTimeout   = time.Minute
+	acmeCheckInterval           = time.Hour
+	acmeAccountKeyName          = "acme_account+key"
+)
+
+// ACMEConfig defines how a listener certificate is obtained
+// and renewed from an ACME CA, e.g: Let's Encrypt.
+type ACMEConfig struct {
+	// domain names the certificate is requested for.
+	Domains []string `mapstructure:"domains,omitempty" json:"domains,omitempty"`
+	// contact email of the ACME account.
+	Email string `mapstructure:"email,omitempty" json:"email,omitempty"`
+	// ACME directory URL, defaults to Let's Encrypt production.
+	DirectoryURL string `mapstructure:"directory-url,omitempty" json:"directory-url,omitempty"`
+	// must be set to true to agree to the ACME CA terms of service.
+	AcceptTOS bool `mapstructure:"accept-tos,omitempty" json:"accept-tos,omitempty"`
+	// one of http-01, tls-alpn-01 or dns-01, defaults to tls-alpn-01.
+	Challenge string `mapstructure:"challenge,omitempty" json:"challenge,omitempty"`
+	// address of the HTTP server answering the http-01 challenges.
+	HTTPAddress string `mapstructure:"http-address,omitempty" json:"http-address,omitempty"`
+	// directory where the account key and certificates are cached.
+	CacheDir string `mapstructure:"cache-dir,omitempty" json:"cache-dir,omitempty"`
+	// the certificate is renewed when it expires in less than renew-before.
+	RenewBefore time.Duration `mapstructure:"renew-before,omitempty" json:"renew-before,omitempty"`
+	// dns-01 challenge configuration.
+	DNS *ACMEDNS `mapstructure:"dns,omitempty" json:"dns,omitempty"`
+}
+
+// ACMEDNS defines how the dns-01 challenges TXT records are managed.
+type ACMEDNS struct {
+	// command run to create and delete the TXT records.
+	// it is called with the arguments `present <fqdn> <value>`
+	// and `cleanup <fqdn> <value>`.
+	Command string `mapstructure:"command,omitempty" json:"command,omitempty"`
+	// time to wait after the TXT record creation before
+	// asking the ACME CA to validate it.
+	PropagationDelay time.Duration `mapstructure:"propagation-delay,omitempty" json:"propagation-delay,omitempty"`
+	// max duration of a command run.
+	Timeout time.Duration `mapstructure:"timeout,omitempty" json:"timeout,omitempty"`
+}
+
+// SetDefaults validates the ACME configuration and sets its default values.
+func (c *ACMEConfig) SetDefaults() error {
+	if len(c.Domains) == 0 {
+		return errors.New("acme: missing domains")
+	}
+	if !c.AcceptTOS {
+		return errors.New("acme: accept-tos must be set to agree to the ACME CA terms of service")
+	}
+	if c.DirectoryURL == "" {
+		c.DirectoryURL = autocert.DefaultACMEDirectory
+	}
+	if c.Challenge == "" {
+		c.Challenge = ACMEChallengeTLSALPN01
+	}
+	switch c.Challenge {
+	case ACMEChallengeHTTP01, ACMEChallengeTLSALPN01:
+		for _, d := range c.Domains {
+			if strings.HasPrefix(d, "*.") {
+				return fmt.Errorf("acme: wildcard domain %q requires the %s challenge", d, ACMEChallengeDNS01)
+			}
+		}
+		if c.Challenge == ACMEChallengeHTTP01 && c.HTTPAddress == "" {
+			c.HTTPAddress = defaultACMEHTTPAddress
+		}
+	case ACMEChallengeDNS01:
+		if c.DNS == nil || c.DNS.Command == "" {
+			return fmt.Errorf("acme: the %s challenge requires a dns command", ACMEChallengeDNS01)
+		}
+		if c.DNS.PropagationDelay <= 0 {
+			c.DNS.PropagationDelay = defaultACMEPropagationDelay
+		}
+		if c.DNS.Timeout <= 0 {
+			c.DNS.Timeout = defaultACMECommandTimeout
+		}
+	default:
+		return fmt.Errorf("acme: unknown challenge %q", c.Challenge)
+	}
+	if c.CacheDir == "" {
+		home, err := homedir.Dir()
+		if err != nil {
+			return err
+		}
+		c.CacheDir = filepath.Join(home, ".gnmic", "acme")
+	}
+	var err error
+	c.CacheDir, err = homedir.Expand(c.CacheDir)
+	if err != nil {
+		return fmt.Errorf("acme: cache-dir %q: %v", c.CacheDir, err)
+	}
+	if c.RenewBefore <= 0 {
+		c.RenewBefore = defaultACMERenewBefore
+	}
+	return nil
+}
+
+// ACME obtains and renews a certificate from an ACME CA.
+// The http-01 and tls-alpn-01 challenges are handled by an autocert.Manager,
+// the certificate is obtained on the first TLS handshake.
+// The dns-01 challenge certificate is obtained on Start.
+type ACME struct {
+	cfg    *ACMEConfig
+	logger *log.Logger
+	cache  autocert.DirCache
+
+	// http-01 and tls-alpn-01
+	m *autocert.Manager
+	// dns-01
+	client *acme.Client
+	cert   atomic.Pointer[tls.Certificate]
+}
+
+// NewACME returns an ACME certificate manager,
+// the config is expected to have been validated with SetDefaults.
+func NewACME(cfg *ACMEConfig, logger *log.Logger) *ACME {
+	a := &ACME{
+		cfg:    cfg,
+		logger: logger,
+		cache:  autocert.DirCache(cfg.CacheDir),
+	}
+	if a.logger == nil {
+		a.logger = log.New(io.Discard, "", 0)
+	}
+	client := &acme.Client{DirectoryURL: cfg.DirectoryURL}
+	if cfg.Challenge == ACMEChallengeDNS01 {
+		a.client = client
+		return a
+	}
+	a.m = &autocert.Manager{
+		Prompt:      autocert.AcceptTOS,
+		Cache:       a.cache,
+		HostPolicy:  autocert.HostWhitelist(cfg.Domains...),
+		RenewBefore: cfg.RenewBefore,
+		Client:      client,
+		Email:       cfg.Email,
+	}
+	return a
+}
+
+// TLSConfig returns a TLS config serving the ACME certificate.
+func (a *ACME) TLSConfig() *tls.Config {
+	if a.m != nil {
+		return a.m.TLSConfig()
+	}
+	return &tls.Config{
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			c := a.cert.Load()
+			if c == nil {
+				return nil, errors.New("acme: certificate not obtained yet")
+			}
+			return c, nil
+		},
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+}
+
+// Start starts the http-01 challenge server or the dns-01 certificate renewal loop,
+// they run until ctx is done.
+func (a *ACME) Start(ctx context.Context) error {
+	switch a.cfg.Challenge {
+	case ACMEChallengeHTTP01:
+		l, err := net.Listen("tcp", a.cfg.HTTPAddress)
+		if err != nil {
+			return fmt.Errorf("acme: failed to start the http-01 challenge server: %w", err)
+		}
+		s := &http.Server{Handler: a.m.HTTPHandler(nil)}
+		go func() {
+			<-ctx.Done()
+			s.Close()
+		}()
+		go func() {
+			err := s.Serve(l)
+			if err != nil && err != http.ErrServerClosed {
+				a.logger.Printf("acme: http-01 challenge server error: %v", err)
+			}
+		}()
+	case ACMEChallengeDNS01:
+		c, err := a.loadCertificate(ctx)
+		if err != nil {
+			a.logger.Printf("acme: ignoring cached certificate: %v", err)
+		}
+		if c != nil {
+			a.cert.Store(c)
+		}
+		go a.renewLoop(ctx)
+	}
+	return nil
+}
+
+func (a *ACME) renewLoop(ctx context.Context) {
+	ticker := time.NewTicker(acmeCheckInterval)
+	defer ticker.Stop()
+	for {
+		if c := a.cert.Load(); c == nil || time.Now().Add(a.cfg.RenewBefore).After(c.Leaf.NotAfter) {
+			c, err := a.obtain(ctx)
+			if err != nil {
+				a.logger.Printf("acme: failed to obtain a certificate for %v: %v", a.cfg.Domains, err)
+			} else {
+				a.cert.Store(c)
+				a.logger.Printf("acme: obtained a certificate for %v, valid until %s", a.cfg.Domains, c.Leaf.NotAfter)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *ACME) certName() string {
+	return a.cfg.Domains[0] + "+" + ACMEChallengeDNS01
+}
+
+func (a *ACME) loadCertificate(ctx context.Context) (*tls.Certificate, error) {
+	b, err := a.cache.Get(ctx, a.certName())
+	if errors.Is(err, autocert.ErrCacheMiss) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	c := new(Certificate)
+	err = json.Unmarshal(b, c)
+	if err != nil {
+		return nil, err
+	}
+	tlsCert, err := c.TLSCertificate()
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range a.cfg.Domains {
+		// a wildcard domain is checked using one of its subdomains
+		h := d
+		if strings.HasPrefix(d, "*.") {
+			h = "x" + d[1:]
+		}
+		if tlsCert.Leaf.VerifyHostname(h) != nil {
+			return nil, fmt.Errorf("cached certificate does not cover domain %q", d)
+		}
+	}
+	return tlsCert, nil
+}
+
+// obtain runs an ACME order solving the dns-01 challenges.
+func (a *ACME) obtain(ctx context.Context) (*tls.Certificate, error) {
+	err := a.register(ctx)
+	if err != nil {
+		return nil, err
+	}
+	order, err := a.client.AuthorizeOrder(ctx, acme.DomainIDs(a.cfg.Domains...))
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range order.AuthzURLs {
+		z, err := a.client.GetAuthorization(ctx, u)
+		if err != nil {
+			return nil, err
+		}
+		if z.Status == acme.StatusValid {
+			continue
+		}
+		err = a.solveDNS01(ctx, z)
+		if err != nil {
+			return nil, err
+		}
+	}
+	order, err = a.client.WaitOrder(ctx, order.URI)
+	if err != nil {
+		return nil, err
+	}
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{DNSNames: a.cfg.Domains}, priv)
+	if err != nil {
+		return nil, err
+	}
+	der, _, err := a.client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return nil, err
+	}
+	keyDer, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		return nil, err
+	}
+	c := &Certificate{Key: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})}
+	for _, b := range der {
+		c.Cert = append(c.Cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: b})...)
+	}
+	tlsCert, err := c.TLSCertificate()
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	err = a.cache.Put(ctx, a.certName(), b)
+	if err != nil {
+		a.logger.Printf("acme: failed to cache certificate: %v", err)
+	}
+	return tlsCert, nil
+}
+
+func (a *ACME) solveDNS01(ctx context.Context, z *acme.Authorization) error {
+	var chal *acme.Challenge
+	for _, c := range z.Challenges {
+		if c.Type == ACMEChallengeDNS01 {
+			chal = c
+			break
+		}
+	}
+	if chal == nil {
+		return fmt.Errorf("no %s challenge offered for %q", ACMEChallengeDNS01, z.Identifier.Value)
+	}
+	val, err := a.client.DNS01ChallengeRecord(chal.Token)
+	if err != nil {
+		return err
+	}
+	fqdn := "_acme-challenge." + strings.TrimPrefix(z.Identifier.Value, "*.") + "."
+	err = a.runDNSCommand(ctx, "present", fqdn, val)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := a.runDNSCommand(ctx, "cleanup", fqdn, val); err != nil {
+			a.logger.Printf("acme: %v", err)
+		}
+	}()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(a.cfg.DNS.PropagationDelay):
+	}
+	_, err = a.client.Accept(ctx, chal)
+	if err != nil {
+		return err
+	}
+	_, err = a.client.WaitAuthorization(ctx, z.URI)
+	return err
+}
+
+func (a *ACME) runDNSCommand(ctx context.Context, action, fqdn, value string) error {
+	ctx, cancel := context.WithTimeout(ctx, a.cfg.DNS.Timeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, a.cfg.DNS.Command, action, fqdn, value).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("dns command %q %s failed: %v: %s", a.cfg.DNS.Command, action, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// register creates the ACME account, the account key is kept in the cache.
+func (a *ACME) register(ctx context.Context) error {
+	if a.client.Key != nil {
+		return nil
+	}
+	key, err := a.accountKey(ctx)
+	if err != nil {
+		return err
+	}
+	a.client.Key = key
+	acct := new(acme.Account)
+	if a.cfg.Email != "" {
+		acct.Contact = []string{"mailto:" + a.cfg.Email}
+	}
+	_, err = a.client.Register(ctx, acct, acme.AcceptTOS)
+	if err != nil && !errors.Is(err, acme.ErrAccountAlreadyExists) {
+		a.client.Key = nil
+		return err
+	}
+	return nil
+}
+
+func (a *ACME) accountKey(ctx context.Context) (crypto.Signer, error) {
+	b, err := a.cache.Get(ctx, acmeAccountKeyName)
+	switch {
+	case err == nil:
+		block, _ := pem.Decode(b)
+		if block == nil {
+			return nil, errors.New("invalid cached account key")
+		}
+		return x509.ParseECPrivateKey(block.Bytes)
+	case errors.Is(err, autocert.ErrCacheMiss):
+	default:
+		return nil, err
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	err = a.cache.Put(ctx, acmeAccountKeyName, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
@@ -0,0 +1,168 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cert
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestACMEConfigSetDefaults(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *ACMEConfig
+		check   func(*ACMEConfig) bool
+		wantErr bool
+	}{
+		{
+			name:    "missing_domains",
+			cfg:     &ACMEConfig{AcceptTOS: true},
+			wantErr: true,
+		},
+		{
+			name:    "tos_not_accepted",
+			cfg:     &ACMEConfig{Domains: []string{"gnmic.example.com"}},
+			wantErr: true,
+		},
+		{
+			name: "defaults",
+			cfg:  &ACMEConfig{Domains: []string{"gnmic.example.com"}, AcceptTOS: true, CacheDir: "/tmp/acme"},
+			check: func(c *ACMEConfig) bool {
+				return c.Challenge == ACMEChallengeTLSALPN01 &&
+					c.DirectoryURL != "" &&
+					c.RenewBefore == defaultACMERenewBefore &&
+					c.HTTPAddress == ""
+			},
+		},
+		{
+			name: "http01_default_address",
+			cfg:  &ACMEConfig{Domains: []string{"gnmic.example.com"}, AcceptTOS: true, Challenge: ACMEChallengeHTTP01},
+			check: func(c *ACMEConfig) bool {
+				return c.HTTPAddress == defaultACMEHTTPAddress
+			},
+		},
+		{
+			name:    "wildcard_without_dns01",
+			cfg:     &ACMEConfig{Domains: []string{"*.example.com"}, AcceptTOS: true},
+			wantErr: true,
+		},
+		{
+			name:    "dns01_without_command",
+			cfg:     &ACMEConfig{Domains: []string{"*.example.com"}, AcceptTOS: true, Challenge: ACMEChallengeDNS01},
+			wantErr: true,
+		},
+		{
+			name: "dns01",
+			cfg: &ACMEConfig{
+				Domains:   []string{"*.example.com"},
+				AcceptTOS: true,
+				Challenge: ACMEChallengeDNS01,
+				DNS:       &ACMEDNS{Command: "/usr/local/bin/txt-record"},
+			},
+			check: func(c *ACMEConfig) bool {
+				return c.DNS.PropagationDelay == defaultACMEPropagationDelay &&
+					c.DNS.Timeout == defaultACMECommandTimeout
+			},
+		},
+		{
+			name:    "unknown_challenge",
+			cfg:     &ACMEConfig{Domains: []string{"gnmic.example.com"}, AcceptTOS: true, Challenge: "tls-sni-01"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.SetDefaults()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.check != nil && !tt.check(tt.cfg) {
+				t.Errorf("unexpected config: %+v", tt.cfg)
+			}
+		})
+	}
+}
+
+func TestACMERunDNSCommand(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	script := filepath.Join(dir, "txt.sh")
+	err := os.WriteFile(script, []byte("#!/bin/sh\necho \"$@\" >> "+out+"\n"), 0o700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := NewACME(&ACMEConfig{
+		Challenge: ACMEChallengeDNS01,
+		CacheDir:  dir,
+		DNS:       &ACMEDNS{Command: script, Timeout: 5 * time.Second},
+	}, nil)
+	err = a.runDNSCommand(context.Background(), "present", "_acme-challenge.example.com.", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(b)); got != "present _acme-challenge.example.com. token" {
+		t.Errorf("unexpected command arguments: %q", got)
+	}
+	a.cfg.DNS.Command = filepath.Join(dir, "missing")
+	if err = a.runDNSCommand(context.Background(), "cleanup", "x", "y"); err == nil {
+		t.Error("expected an error running a missing command")
+	}
+}
+
+func TestACMELoadCertificate(t *testing.T) {
+	ctx := context.Background()
+	ca, err := GenerateCA(&Options{Validity: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := GenerateServerCert(ca, &Options{Hosts: []string{"gnmic.example.com", "*.lab.example.com"}, Validity: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := json.Marshal(c)
+
+	a := NewACME(&ACMEConfig{
+		Domains:   []string{"gnmic.example.com", "*.lab.example.com"},
+		Challenge: ACMEChallengeDNS01,
+		CacheDir:  t.TempDir(),
+	}, nil)
+	// not obtained yet
+	if _, err = a.TLSConfig().GetCertificate(&tls.ClientHelloInfo{}); err == nil {
+		t.Error("expected an error before the certificate is obtained")
+	}
+	tlsCert, err := a.loadCertificate(ctx)
+	if err != nil || tlsCert != nil {
+		t.Fatalf("expected a cache miss, got %v, %v", tlsCert, err)
+	}
+	if err = a.cache.Put(ctx, a.certName(), b); err != nil {
+		t.Fatal(err)
+	}
+	tlsCert, err = a.loadCertificate(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tlsCert == nil {
+		t.Fatal("expected a cached certificate")
+	}
+	// a cached certificate not covering the configured domains is ignored
+	a.cfg.Domains = append(a.cfg.Domains, "other.example.com")
+	if _, err = a.loadCertificate(ctx); err == nil {
+		t.Error("expected an error for a certificate not covering all the domains")
+	}
+}
@@ -0,0 +1,163 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cert
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+)
+
+const (
+	defaultOrganization = "gNMIc"
+	// certificates NotBefore is backdated to tolerate small clock skews.
+	clockSkew = 5 * time.Minute
+)
+
+// Certificate is a PEM encoded certificate and its private key.
+type Certificate struct {
+	Cert []byte `json:"cert,omitempty"`
+	Key  []byte `json:"key,omitempty"`
+}
+
+// Options defines the attributes of a generated certificate.
+type Options struct {
+	CommonName   string
+	Organization []string
+	// DNS names and/or IP addresses added as SANs.
+	Hosts    []string
+	Validity time.Duration
+}
+
+// X509 parses the PEM encoded certificate.
+func (c *Certificate) X509() (*x509.Certificate, error) {
+	if c == nil {
+		return nil, errors.New("missing certificate")
+	}
+	block, _ := pem.Decode(c.Cert)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("failed to decode PEM certificate")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// TLSCertificate returns the certificate and key as a tls.Certificate
+// with its Leaf populated.
+func (c *Certificate) TLSCertificate() (*tls.Certificate, error) {
+	tlsCert, err := tls.X509KeyPair(c.Cert, c.Key)
+	if err != nil {
+		return nil, err
+	}
+	tlsCert.Leaf, err = x509.ParseCertificate(tlsCert.Certificate[0])
+	if err != nil {
+		return nil, err
+	}
+	return &tlsCert, nil
+}
+
+// GenerateCA creates a self signed CA certificate.
+func GenerateCA(opts *Options) (*Certificate, error) {
+	tpl, err := certTemplate(opts)
+	if err != nil {
+		return nil, err
+	}
+	tpl.IsCA = true
+	tpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &priv.PublicKey, priv)
+	if err != nil {
+		return nil, err
+	}
+	return encode(der, priv)
+}
+
+// GenerateServerCert creates a server certificate signed by the given CA.
+func GenerateServerCert(ca *Certificate, opts *Options) (*Certificate, error) {
+	caTLS, err := ca.TLSCertificate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CA: %w", err)
+	}
+	if !caTLS.Leaf.IsCA {
+		return nil, errors.New("invalid CA: not a CA certificate")
+	}
+	tpl, err := certTemplate(opts)
+	if err != nil {
+		return nil, err
+	}
+	tpl.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+	tpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	for _, h := range opts.Hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			tpl.IPAddresses = append(tpl.IPAddresses, ip)
+			continue
+		}
+		tpl.DNSNames = append(tpl.DNSNames, h)
+	}
+	// do not outlive the CA
+	if tpl.NotAfter.After(caTLS.Leaf.NotAfter) {
+		tpl.NotAfter = caTLS.Leaf.NotAfter
+	}
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, caTLS.Leaf, &priv.PublicKey, caTLS.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return encode(der, priv)
+}
+
+func certTemplate(opts *Options) (*x509.Certificate, error) {
+	if opts.Validity <= 0 {
+		return nil, errors.New("certificate validity must be positive")
+	}
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	org := opts.Organization
+	if len(org) == 0 {
+		org = []string{defaultOrganization}
+	}
+	now := time.Now()
+	return &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			CommonName:   opts.CommonName,
+			Organization: org,
+		},
+		NotBefore:             now.Add(-clockSkew),
+		NotAfter:              now.Add(opts.Validity),
+		BasicConstraintsValid: true,
+	}, nil
+}
+
+func encode(der []byte, priv *ecdsa.PrivateKey) (*Certificate, error) {
+	keyDer, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		return nil, err
+	}
+	return &Certificate{
+		Cert: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		Key:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}),
+	}, nil
+}
@@ -0,0 +1,337 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cert
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	caName = "ca"
+
+	defaultCAValidity    = 10 * 365 * 24 * time.Hour
+	defaultValidity      = 90 * 24 * time.Hour
+	defaultRenewBefore   = 30 * 24 * time.Hour
+	defaultCheckInterval = time.Hour
+	defaultCACommonName  = "gNMIc CA"
+	loggingPrefix        = "[cert] "
+)
+
+// Config defines the generated certificates attributes
+// and their rotation schedule.
+type Config struct {
+	CA Options
+	// server certificates organization and validity.
+	Organization []string
+	Validity     time.Duration
+	// a certificate is renewed when it expires in less than RenewBefore.
+	RenewBefore time.Duration
+	// the certificates expiry check interval.
+	CheckInterval time.Duration
+}
+
+// Locker serializes the CA generation between
+// instances sharing the same Store.
+type Locker interface {
+	Lock(ctx context.Context) error
+	Unlock(ctx context.Context) error
+}
+
+// Manager generates a CA and server certificates signed by it,
+// saves them in a Store and renews them before they expire.
+type Manager struct {
+	cfg    *Config
+	store  Store
+	locker Locker
+	logger *log.Logger
+
+	m       *sync.RWMutex
+	ca      *Certificate
+	caLeaf  *x509.Certificate
+	servers map[string]*server
+}
+
+type server struct {
+	hosts []string
+	cert  *tls.Certificate
+}
+
+type Option func(*Manager)
+
+func WithLogger(logger *log.Logger) Option {
+	return func(m *Manager) {
+		if logger == nil {
+			return
+		}
+		m.logger.SetOutput(logger.Writer())
+		m.logger.SetFlags(logger.Flags())
+	}
+}
+
+func WithLocker(l Locker) Option {
+	return func(m *Manager) {
+		m.locker = l
+	}
+}
+
+func NewManager(cfg *Config, store Store, opts ...Option) *Manager {
+	m := &Manager{
+		cfg:     cfg,
+		store:   store,
+		logger:  log.New(io.Discard, loggingPrefix, log.LstdFlags|log.Lmicroseconds),
+		m:       new(sync.RWMutex),
+		servers: make(map[string]*server),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	m.setDefaults()
+	return m
+}
+
+func (m *Manager) setDefaults() {
+	if m.cfg.CA.CommonName == "" {
+		m.cfg.CA.CommonName = defaultCACommonName
+	}
+	if m.cfg.CA.Validity <= 0 {
+		m.cfg.CA.Validity = defaultCAValidity
+	}
+	if m.cfg.Validity <= 0 {
+		m.cfg.Validity = defaultValidity
+	}
+	if m.cfg.RenewBefore <= 0 {
+		m.cfg.RenewBefore = defaultRenewBefore
+	}
+	if m.cfg.RenewBefore >= min(m.cfg.Validity, m.cfg.CA.Validity) {
+		m.cfg.RenewBefore = min(m.cfg.Validity, m.cfg.CA.Validity) / 3
+	}
+	if m.cfg.CheckInterval <= 0 {
+		m.cfg.CheckInterval = defaultCheckInterval
+	}
+}
+
+// ServerTLSConfig makes sure a valid certificate exists for the named server
+// and returns a TLS config serving it.
+// The returned config picks up the rotated certificates without a restart.
+func (m *Manager) ServerTLSConfig(ctx context.Context, name string, hosts []string) (*tls.Config, error) {
+	err := m.AddServer(ctx, name, hosts)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return m.Certificate(name)
+		},
+	}, nil
+}
+
+// AddServer loads or generates the named server certificate
+// and adds it to the set of rotated certificates.
+func (m *Manager) AddServer(ctx context.Context, name string, hosts []string) error {
+	if name == caName {
+		return fmt.Errorf("invalid server name %q", name)
+	}
+	m.m.Lock()
+	defer m.m.Unlock()
+	err := m.ensureCA(ctx)
+	if err != nil {
+		return err
+	}
+	s := &server{hosts: hosts}
+	err = m.ensureServer(ctx, name, s)
+	if err != nil {
+		return err
+	}
+	m.servers[name] = s
+	return nil
+}
+
+// Certificate returns the current certificate of the named server.
+func (m *Manager) Certificate(name string) (*tls.Certificate, error) {
+	m.m.RLock()
+	defer m.m.RUnlock()
+	s, ok := m.servers[name]
+	if !ok || s.cert == nil {
+		return nil, fmt.Errorf("unknown server %q", name)
+	}
+	return s.cert, nil
+}
+
+// CA returns the PEM encoded CA certificate.
+func (m *Manager) CA() []byte {
+	m.m.RLock()
+	defer m.m.RUnlock()
+	if m.ca == nil {
+		return nil
+	}
+	return m.ca.Cert
+}
+
+// Start checks the certificates every CheckInterval
+// and rotates them, until ctx is done.
+func (m *Manager) Start(ctx context.Context) {
+	ticker := time.NewTicker(m.cfg.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := m.Rotate(ctx)
+			if err != nil {
+				m.logger.Printf("failed to rotate certificates: %v", err)
+			}
+		}
+	}
+}
+
+// Rotate reloads the CA from the store and renews it if it is about to expire.
+// It then renews the server certificates that are about to expire
+// or that are not signed by the current CA.
+func (m *Manager) Rotate(ctx context.Context) error {
+	m.m.Lock()
+	defer m.m.Unlock()
+	err := m.ensureCA(ctx)
+	if err != nil {
+		return err
+	}
+	errs := make([]error, 0)
+	for name, s := range m.servers {
+		err = m.ensureServer(ctx, name, s)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *Manager) ensureCA(ctx context.Context) error {
+	ok, err := m.loadCA(ctx)
+	if err != nil || ok {
+		return err
+	}
+	if m.locker != nil {
+		err = m.locker.Lock(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to acquire certificates lock: %w", err)
+		}
+		defer m.locker.Unlock(ctx)
+		// another instance might have renewed the CA
+		ok, err = m.loadCA(ctx)
+		if err != nil || ok {
+			return err
+		}
+	}
+	ca, err := GenerateCA(&m.cfg.CA)
+	if err != nil {
+		return fmt.Errorf("failed to generate CA: %w", err)
+	}
+	err = m.store.Save(ctx, caName, ca)
+	if err != nil {
+		return fmt.Errorf("failed to save CA: %w", err)
+	}
+	m.ca = ca
+	m.caLeaf, err = ca.X509()
+	if err != nil {
+		return err
+	}
+	m.logger.Printf("generated CA %q valid until %s", m.caLeaf.Subject.CommonName, m.caLeaf.NotAfter)
+	return nil
+}
+
+// loadCA loads the CA from the store,
+// it returns false if it does not exist or is about to expire.
+func (m *Manager) loadCA(ctx context.Context) (bool, error) {
+	ca, err := m.store.Load(ctx, caName)
+	if err != nil {
+		return false, fmt.Errorf("failed to load CA: %w", err)
+	}
+	if ca == nil {
+		return false, nil
+	}
+	caTLS, err := ca.TLSCertificate()
+	if err != nil {
+		m.logger.Printf("ignoring invalid stored CA: %v", err)
+		return false, nil
+	}
+	if !caTLS.Leaf.IsCA || m.expiring(caTLS.Leaf) {
+		return false, nil
+	}
+	m.ca = ca
+	m.caLeaf = caTLS.Leaf
+	return true, nil
+}
+
+func (m *Manager) ensureServer(ctx context.Context, name string, s *server) error {
+	if s.cert != nil && m.validServer(s.cert.Leaf, s.hosts) {
+		return nil
+	}
+	c, err := m.store.Load(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to load certificate: %w", err)
+	}
+	if c != nil {
+		tlsCert, err := c.TLSCertificate()
+		if err == nil && m.validServer(tlsCert.Leaf, s.hosts) {
+			s.cert = tlsCert
+			return nil
+		}
+	}
+	opts := &Options{
+		Organization: m.cfg.Organization,
+		Hosts:        s.hosts,
+		Validity:     m.cfg.Validity,
+	}
+	if len(s.hosts) > 0 {
+		opts.CommonName = s.hosts[0]
+	}
+	c, err = GenerateServerCert(m.ca, opts)
+	if err != nil {
+		return fmt.Errorf("failed to generate certificate: %w", err)
+	}
+	err = m.store.Save(ctx, name, c)
+	if err != nil {
+		return fmt.Errorf("failed to save certificate: %w", err)
+	}
+	s.cert, err = c.TLSCertificate()
+	if err != nil {
+		return err
+	}
+	m.logger.Printf("generated %q certificate for hosts %v, valid until %s", name, s.hosts, s.cert.Leaf.NotAfter)
+	return nil
+}
+
+// validServer returns true if the certificate is signed by the current CA,
+// is not about to expire and covers all the hosts.
+func (m *Manager) validServer(leaf *x509.Certificate, hosts []string) bool {
+	if leaf == nil || m.expiring(leaf) {
+		return false
+	}
+	if leaf.CheckSignatureFrom(m.caLeaf) != nil {
+		return false
+	}
+	for _, h := range hosts {
+		if leaf.VerifyHostname(h) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *Manager) expiring(c *x509.Certificate) bool {
+	return time.Now().Add(m.cfg.RenewBefore).After(c.NotAfter)
+}
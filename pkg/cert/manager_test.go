@@ -0,0 +1,174 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cert
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memKV struct {
+	m  sync.Mutex
+	kv map[string][]byte
+}
+
+func (k *memKV) Get(_ context.Context, key string) ([]byte, error) {
+	k.m.Lock()
+	defer k.m.Unlock()
+	return k.kv[key], nil
+}
+
+func (k *memKV) Put(_ context.Context, key string, val []byte) error {
+	k.m.Lock()
+	defer k.m.Unlock()
+	k.kv[key] = val
+	return nil
+}
+
+func TestGenerateServerCert(t *testing.T) {
+	ca, err := GenerateCA(&Options{CommonName: "test CA", Validity: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := GenerateServerCert(ca, &Options{
+		Hosts:    []string{"router1", "127.0.0.1"},
+		Validity: 2 * time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	caLeaf, _ := ca.X509()
+	leaf, err := c.X509()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = leaf.CheckSignatureFrom(caLeaf); err != nil {
+		t.Errorf("certificate not signed by the CA: %v", err)
+	}
+	if err = leaf.VerifyHostname("router1"); err != nil {
+		t.Error(err)
+	}
+	if err = leaf.VerifyHostname("127.0.0.1"); err != nil {
+		t.Error(err)
+	}
+	if leaf.NotAfter.After(caLeaf.NotAfter) {
+		t.Errorf("certificate outlives its CA: %s > %s", leaf.NotAfter, caLeaf.NotAfter)
+	}
+	if _, err = GenerateServerCert(c, &Options{Validity: time.Hour}); err == nil {
+		t.Error("expected an error when signing with a non CA certificate")
+	}
+}
+
+func TestManager(t *testing.T) {
+	ctx := context.Background()
+	stores := map[string]func(t *testing.T) Store{
+		"file": func(t *testing.T) Store { return NewFileStore(t.TempDir()) },
+		"kv":   func(t *testing.T) Store { return NewKVStore(&memKV{kv: map[string][]byte{}}, "gnmic/certs", "") },
+		"kv_encrypted": func(t *testing.T) Store {
+			return NewKVStore(&memKV{kv: map[string][]byte{}}, "gnmic/certs", "secret")
+		},
+	}
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			m := NewManager(&Config{}, store)
+			tlsCfg, err := m.ServerTLSConfig(ctx, "api-server", []string{"localhost", "127.0.0.1"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			c1, err := tlsCfg.GetCertificate(&tls.ClientHelloInfo{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// a second manager using the same store reuses the certificates
+			m2 := NewManager(&Config{}, store)
+			if err = m2.AddServer(ctx, "api-server", []string{"localhost"}); err != nil {
+				t.Fatal(err)
+			}
+			c2, _ := m2.Certificate("api-server")
+			if c1.Leaf.SerialNumber.Cmp(c2.Leaf.SerialNumber) != 0 {
+				t.Errorf("expected the stored certificate to be reused")
+			}
+			// a new host triggers a new certificate
+			if err = m2.AddServer(ctx, "api-server", []string{"localhost", "gnmic1"}); err != nil {
+				t.Fatal(err)
+			}
+			c3, _ := m2.Certificate("api-server")
+			if c1.Leaf.SerialNumber.Cmp(c3.Leaf.SerialNumber) == 0 {
+				t.Errorf("expected a new certificate covering the new host")
+			}
+			verifyChain(t, m2.CA(), c3.Leaf)
+		})
+	}
+}
+
+func TestManagerRotate(t *testing.T) {
+	ctx := context.Background()
+	store := NewKVStore(&memKV{kv: map[string][]byte{}}, "certs", "")
+	m := NewManager(&Config{
+		CA:          Options{Validity: 24 * time.Hour},
+		Validity:    2 * time.Hour,
+		RenewBefore: time.Hour,
+	}, store)
+	err := m.AddServer(ctx, "gnmi-server", []string{"localhost"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c1, _ := m.Certificate("gnmi-server")
+	// nothing to rotate
+	if err = m.Rotate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	c2, _ := m.Certificate("gnmi-server")
+	if c1 != c2 {
+		t.Fatal("unexpected certificate rotation")
+	}
+	// a certificate about to expire is renewed
+	m.cfg.RenewBefore = 3 * time.Hour
+	if err = m.Rotate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	c3, _ := m.Certificate("gnmi-server")
+	if c1.Leaf.SerialNumber.Cmp(c3.Leaf.SerialNumber) == 0 {
+		t.Fatal("expected the certificate to be renewed")
+	}
+	// a new CA in the store triggers a certificate renewal
+	ca, _ := GenerateCA(&Options{CommonName: "new CA", Validity: 24 * time.Hour})
+	if err = store.Save(ctx, caName, ca); err != nil {
+		t.Fatal(err)
+	}
+	if err = m.Rotate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	c4, _ := m.Certificate("gnmi-server")
+	if c4.Leaf.Issuer.CommonName != "new CA" {
+		t.Errorf("expected the certificate to be signed by the new CA, got issuer %q", c4.Leaf.Issuer.CommonName)
+	}
+	verifyChain(t, m.CA(), c4.Leaf)
+}
+
+func verifyChain(t *testing.T, caPEM []byte, leaf *x509.Certificate) {
+	t.Helper()
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		t.Fatal("failed to parse CA")
+	}
+	_, err := leaf.Verify(x509.VerifyOptions{
+		Roots:     pool,
+		DNSName:   "localhost",
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	if err != nil {
+		t.Errorf("failed to verify certificate: %v", err)
+	}
+}
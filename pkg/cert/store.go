@@ -0,0 +1,237 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cert
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/openconfig/gnmic/pkg/lockers"
+)
+
+// Store persists certificates by name.
+type Store interface {
+	// Load returns the named certificate, or nil if it does not exist.
+	Load(ctx context.Context, name string) (*Certificate, error)
+	// Save writes the named certificate, replacing any existing one.
+	Save(ctx context.Context, name string, c *Certificate) error
+}
+
+type fileStore struct {
+	dir string
+}
+
+// NewFileStore returns a Store that writes the certificates
+// under dir as `<name>.crt` and `<name>.key`.
+func NewFileStore(dir string) Store {
+	return &fileStore{dir: dir}
+}
+
+func (s *fileStore) paths(name string) (string, string) {
+	p := filepath.Join(s.dir, filepath.FromSlash(name))
+	return p + ".crt", p + ".key"
+}
+
+func (s *fileStore) Load(_ context.Context, name string) (*Certificate, error) {
+	certFile, keyFile := s.paths(name)
+	certb, err := os.ReadFile(certFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	keyb, err := os.ReadFile(keyFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Certificate{Cert: certb, Key: keyb}, nil
+}
+
+func (s *fileStore) Save(_ context.Context, name string, c *Certificate) error {
+	certFile, keyFile := s.paths(name)
+	err := os.MkdirAll(filepath.Dir(certFile), 0o700)
+	if err != nil {
+		return err
+	}
+	// write the key first, so that a reader never finds
+	// a new certificate next to an old key.
+	err = writeFile(keyFile, c.Key, 0o600)
+	if err != nil {
+		return err
+	}
+	return writeFile(certFile, c.Cert, 0o644)
+}
+
+// writeFile atomically replaces the file content.
+func writeFile(name string, b []byte, perm os.FileMode) error {
+	f, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err = f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Chmod(perm); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), name)
+}
+
+type kvStore struct {
+	kv     lockers.KV
+	prefix string
+	// passphrase the private keys encryption key is derived from,
+	// the keys are stored in clear if empty.
+	encryptionKey string
+}
+
+// kvCertificate is the stored form of a Certificate,
+// with either a clear or an encrypted private key.
+type kvCertificate struct {
+	Cert         []byte `json:"cert,omitempty"`
+	Key          []byte `json:"key,omitempty"`
+	EncryptedKey []byte `json:"encrypted-key,omitempty"`
+}
+
+// NewKVStore returns a Store that writes the certificates
+// as JSON objects under `<prefix>/<name>`.
+// If encryptionKey is not empty, the private keys are encrypted
+// with AES-256-GCM using a key derived from it.
+func NewKVStore(kv lockers.KV, prefix, encryptionKey string) Store {
+	return &kvStore{
+		kv:            kv,
+		prefix:        strings.TrimSuffix(prefix, "/"),
+		encryptionKey: encryptionKey,
+	}
+}
+
+func (s *kvStore) key(name string) string {
+	return fmt.Sprintf("%s/%s", s.prefix, name)
+}
+
+func (s *kvStore) Load(ctx context.Context, name string) (*Certificate, error) {
+	b, err := s.kv.Get(ctx, s.key(name))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, nil
+	}
+	kc := new(kvCertificate)
+	err = json.Unmarshal(b, kc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode certificate %q: %w", name, err)
+	}
+	c := &Certificate{Cert: kc.Cert, Key: kc.Key}
+	switch {
+	case len(kc.EncryptedKey) > 0:
+		if s.encryptionKey == "" {
+			return nil, fmt.Errorf("certificate %q private key is encrypted and no encryption key is set", name)
+		}
+		c.Key, err = decryptKey(s.encryptionKey, kc.EncryptedKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt certificate %q private key: %w", name, err)
+		}
+	case s.encryptionKey != "":
+		// stored before the encryption was enabled,
+		// write it back with an encrypted key.
+		err = s.Save(ctx, name, c)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (s *kvStore) Save(ctx context.Context, name string, c *Certificate) error {
+	kc := &kvCertificate{Cert: c.Cert, Key: c.Key}
+	if s.encryptionKey != "" {
+		var err error
+		kc.EncryptedKey, err = encryptKey(s.encryptionKey, c.Key)
+		if err != nil {
+			return err
+		}
+		kc.Key = nil
+	}
+	b, err := json.Marshal(kc)
+	if err != nil {
+		return err
+	}
+	return s.kv.Put(ctx, s.key(name), b)
+}
+
+const keySaltSize = 16
+
+// keyCipher returns the AES-256-GCM cipher using the key derived from passphrase and salt.
+func keyCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	k, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(k)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptKey returns the salt, the nonce and the encrypted key, in that order.
+func encryptKey(passphrase string, key []byte) ([]byte, error) {
+	salt := make([]byte, keySaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	aead, err := keyCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	b := append(salt, nonce...)
+	return aead.Seal(b, nonce, key, nil), nil
+}
+
+func decryptKey(passphrase string, b []byte) ([]byte, error) {
+	if len(b) < keySaltSize {
+		return nil, errors.New("encrypted key too short")
+	}
+	aead, err := keyCipher(passphrase, b[:keySaltSize])
+	if err != nil {
+		return nil, err
+	}
+	b = b[keySaltSize:]
+	if len(b) < aead.NonceSize() {
+		return nil, errors.New("encrypted key too short")
+	}
+	return aead.Open(nil, b[:aead.NonceSize()], b[aead.NonceSize():], nil)
+}
@@ -0,0 +1,57 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestKVStoreEncryption(t *testing.T) {
+	ctx := context.Background()
+	kv := &memKV{kv: map[string][]byte{}}
+	c := &Certificate{Cert: []byte("cert"), Key: []byte("private key")}
+
+	// a clear key is read and encrypted once encryption is enabled
+	err := NewKVStore(kv, "certs", "").Save(ctx, "ca", c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := NewKVStore(kv, "certs", "secret")
+	got, err := store.Load(ctx, "ca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Key, c.Key) {
+		t.Errorf("got key %q, want %q", got.Key, c.Key)
+	}
+	kc := new(kvCertificate)
+	if err = json.Unmarshal(kv.kv["certs/ca"], kc); err != nil {
+		t.Fatal(err)
+	}
+	if len(kc.Key) != 0 || len(kc.EncryptedKey) == 0 || bytes.Contains(kc.EncryptedKey, c.Key) {
+		t.Fatalf("private key not encrypted: %s", kv.kv["certs/ca"])
+	}
+	got, err = store.Load(ctx, "ca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Key, c.Key) || !bytes.Equal(got.Cert, c.Cert) {
+		t.Errorf("got %q, want %q", got, c)
+	}
+	// an encrypted key is not read without the right encryption key
+	if _, err = NewKVStore(kv, "certs", "other").Load(ctx, "ca"); err == nil {
+		t.Error("expected an error with the wrong encryption key")
+	}
+	if _, err = NewKVStore(kv, "certs", "").Load(ctx, "ca"); err == nil {
+		t.Error("expected an error without an encryption key")
+	}
+}
@@ -0,0 +1,50 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"github.com/openconfig/gnmic/pkg/app"
+	"github.com/spf13/cobra"
+)
+
+// configCmd represents the config command
+func New(gApp *app.App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "config",
+		Short:        "export and import gnmic configurations",
+		SilenceUsage: true,
+	}
+	cmd.AddCommand(newConfigExportCmd(gApp))
+	cmd.AddCommand(newConfigImportCmd(gApp))
+	return cmd
+}
+
+// newConfigExportCmd represents the config export command
+func newConfigExportCmd(gApp *app.App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "export",
+		Short:        "export the configuration as a single YAML file, with its secrets redacted",
+		RunE:         gApp.ConfigExportRunE,
+		SilenceUsage: true,
+	}
+	gApp.InitConfigExportFlags(cmd)
+	return cmd
+}
+
+// newConfigImportCmd represents the config import command
+func newConfigImportCmd(gApp *app.App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "import",
+		Short:        "apply a configuration file to a running gnmic instance through its API",
+		RunE:         gApp.ConfigImportRunE,
+		SilenceUsage: true,
+	}
+	gApp.InitConfigImportFlags(cmd)
+	return cmd
+}
@@ -18,6 +18,7 @@ func New(gApp *app.App) *cobra.Command {
 		},
 		SilenceUsage: true,
 	}
+	cmd.AddCommand(newProcessorTestCmd(gApp))
 	gApp.InitProcessorFlags(cmd)
 	return cmd
 }
@@ -0,0 +1,30 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package processor
+
+import (
+	"github.com/openconfig/gnmic/pkg/app"
+	"github.com/spf13/cobra"
+)
+
+// newProcessorTestCmd represents the processor test command
+func newProcessorTestCmd(gApp *app.App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "test",
+		Short:   "run events through a configured processors pipeline and compare the result to a golden file",
+		PreRunE: gApp.ProcessorPreRunE,
+		RunE:    gApp.ProcessorTestRunE,
+		PostRun: func(cmd *cobra.Command, args []string) {
+			gApp.CleanupPlugins()
+		},
+		SilenceUsage: true,
+	}
+	gApp.InitProcessorTestFlags(cmd)
+	return cmd
+}
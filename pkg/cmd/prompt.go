@@ -9,6 +9,7 @@
 package cmd
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -52,6 +53,8 @@ var colorMapping = map[string]goprompt.Color{
 var targetListHeader = []string{
 	"Name", "Address", "Username", "Password", "Insecure", "Skip Verify", "TLS CA", "TLS Certificate", "TLS Key"}
 
+var targetStatusHeader = []string{"Name", "Address", "State", "Subscriptions", "Buffered Events", "Last Event"}
+
 var subscriptionListHeader = []string{"Name", "Mode", "Prefix", "Paths", "Interval", "Encoding"}
 
 func getColor(flagName string) goprompt.Color {
@@ -175,6 +178,14 @@ var targetShowCmd = &cobra.Command{
 	},
 }
 
+var targetStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "show the connection state and subscriptions of the running targets",
+	Run: func(_ *cobra.Command, _ []string) {
+		renderTable(gApp.PromptTargetsStatus(), targetStatusHeader)
+	},
+}
+
 var subscriptionCmd = &cobra.Command{
 	Use:   "subscription",
 	Short: "manipulate configured subscriptions",
@@ -239,6 +250,65 @@ var outputListCmd = &cobra.Command{
 	},
 }
 
+var eventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "inspect the recent events received by the running subscriptions",
+}
+
+var eventsShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "show the recent events",
+	Annotations: map[string]string{
+		"--target":       "TARGET",
+		"--subscription": "SUBSCRIPTION",
+	},
+	RunE: func(_ *cobra.Command, _ []string) error {
+		evs := gApp.PromptEvents(eventsTarget, eventsSubscription, eventsLast)
+		if len(evs) == 0 {
+			fmt.Println("no events received")
+			return nil
+		}
+		b, err := json.MarshalIndent(evs, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	},
+	PostRun: resetEventsFlags,
+}
+
+var eventsJQCmd = &cobra.Command{
+	Use:   "jq <expression>",
+	Short: "run a jq expression against the recent events",
+	Annotations: map[string]string{
+		"--target":       "TARGET",
+		"--subscription": "SUBSCRIPTION",
+	},
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		results, err := gApp.PromptEventsJQ(strings.Join(args, " "), eventsTarget, eventsSubscription, eventsLast)
+		if err != nil {
+			return err
+		}
+		for _, r := range results {
+			b, err := json.MarshalIndent(r, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(b))
+		}
+		return nil
+	},
+	PostRun: resetEventsFlags,
+}
+
+func resetEventsFlags(_ *cobra.Command, _ []string) {
+	eventsTarget = ""
+	eventsSubscription = ""
+	eventsLast = defaultEventsLast
+}
+
 func renderTable(tabData [][]string, header []string) {
 	table := tablewriter.NewWriter(os.Stdout)
 	table.SetHeader(header)
@@ -340,6 +410,14 @@ func subscriptionTable(scs map[string]*types.SubscriptionConfig, list bool) [][]
 
 var name string
 
+const defaultEventsLast = 10
+
+var (
+	eventsTarget       string
+	eventsSubscription string
+	eventsLast         = defaultEventsLast
+)
+
 func findMatchedXPATH(entry *yang.Entry, input string, prefixPresent bool) []goprompt.Suggest {
 	if strings.HasPrefix(input, ":") {
 		return nil
@@ -576,6 +654,10 @@ func findDynamicSuggestions(annotation string, doc goprompt.Document) []goprompt
 			for _, entry := range gApp.SchemaTree.Dir {
 				suggestions = append(suggestions, findMatchedXPATH(entry, word, false)...)
 			}
+			// and from the paths found in the received events
+			for _, p := range gApp.PromptEventPaths(word) {
+				suggestions = append(suggestions, goprompt.Suggest{Text: p, Description: "received path"})
+			}
 		}
 		sort.Slice(suggestions, func(i, j int) bool {
 			if suggestions[i].Text == suggestions[j].Text {
@@ -861,6 +943,7 @@ func initPromptCmds() {
 
 	targetCmd.AddCommand(targetListCmd)
 	targetCmd.AddCommand(targetShowCmd)
+	targetCmd.AddCommand(targetStatusCmd)
 	targetShowCmd.Flags().StringVarP(&name, "name", "", "", "target name")
 
 	subscriptionCmd.AddCommand(subscriptionListCmd)
@@ -869,6 +952,15 @@ func initPromptCmds() {
 
 	outputCmd.AddCommand(outputListCmd)
 
+	gApp.RootCmd.AddCommand(eventsCmd)
+	eventsCmd.AddCommand(eventsShowCmd)
+	eventsCmd.AddCommand(eventsJQCmd)
+	for _, c := range []*cobra.Command{eventsShowCmd, eventsJQCmd} {
+		c.Flags().StringVarP(&eventsTarget, "target", "", "", "only consider events received from this target")
+		c.Flags().StringVarP(&eventsSubscription, "subscription", "", "", "only consider events received by this subscription")
+		c.Flags().IntVarP(&eventsLast, "last", "", defaultEventsLast, "number of recent events to consider, 0 for all buffered events")
+	}
+
 	gApp.RootCmd.RemoveCommand(promptModeCmd)
 }
 
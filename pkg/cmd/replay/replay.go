@@ -0,0 +1,34 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package replay
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/openconfig/gnmic/pkg/app"
+)
+
+// New create the replay command.
+func New(gApp *app.App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "replay",
+		Short: "replay recorded gnmi subscribe responses",
+		Annotations: map[string]string{
+			"--file": "FILE",
+		},
+		PreRunE: gApp.ReplayPreRunE,
+		RunE:    gApp.ReplayRunE,
+		PostRun: func(cmd *cobra.Command, args []string) {
+			gApp.CleanupPlugins()
+		},
+		SilenceUsage: true,
+	}
+	gApp.InitReplayFlags(cmd)
+	return cmd
+}
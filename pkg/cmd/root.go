@@ -19,7 +19,9 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/openconfig/gnmic/pkg/app"
+	"github.com/openconfig/gnmic/pkg/cmd/bench"
 	"github.com/openconfig/gnmic/pkg/cmd/capabilities"
+	"github.com/openconfig/gnmic/pkg/cmd/config"
 	"github.com/openconfig/gnmic/pkg/cmd/diff"
 	"github.com/openconfig/gnmic/pkg/cmd/generate"
 	"github.com/openconfig/gnmic/pkg/cmd/get"
@@ -28,8 +30,12 @@ import (
 	"github.com/openconfig/gnmic/pkg/cmd/path"
 	"github.com/openconfig/gnmic/pkg/cmd/processor"
 	"github.com/openconfig/gnmic/pkg/cmd/proxy"
+	"github.com/openconfig/gnmic/pkg/cmd/record"
+	"github.com/openconfig/gnmic/pkg/cmd/replay"
+	"github.com/openconfig/gnmic/pkg/cmd/server"
 	"github.com/openconfig/gnmic/pkg/cmd/set"
 	"github.com/openconfig/gnmic/pkg/cmd/subscribe"
+	"github.com/openconfig/gnmic/pkg/cmd/template"
 	"github.com/openconfig/gnmic/pkg/cmd/version"
 )
 
@@ -79,6 +85,12 @@ func newRootCmd() *cobra.Command {
 	gApp.RootCmd.AddCommand(version.New(gApp))
 	gApp.RootCmd.AddCommand(proxy.New(gApp))
 	gApp.RootCmd.AddCommand(processor.New(gApp))
+	gApp.RootCmd.AddCommand(record.New(gApp))
+	gApp.RootCmd.AddCommand(replay.New(gApp))
+	gApp.RootCmd.AddCommand(bench.New(gApp))
+	gApp.RootCmd.AddCommand(server.New(gApp))
+	gApp.RootCmd.AddCommand(template.New(gApp))
+	gApp.RootCmd.AddCommand(config.New(gApp))
 	return gApp.RootCmd
 }
 
@@ -88,7 +100,8 @@ func Execute() {
 	setupCloseHandler(gApp.Cfn)
 	if err := newRootCmd().Execute(); err != nil {
 		//fmt.Println(err)
-		os.Exit(1)
+		gApp.ReportError(err)
+		os.Exit(app.ExitCode(err))
 	}
 	if gApp.PromptMode {
 		ExecutePrompt()
@@ -116,7 +129,17 @@ func setupCloseHandler(cancelFn context.CancelFunc) {
 	go func() {
 		sig := <-c
 		fmt.Printf("\nreceived signal '%s'. terminating...\n", sig.String())
+		go func() {
+			sig := <-c
+			fmt.Printf("\nreceived signal '%s'. exiting without draining...\n", sig.String())
+			os.Exit(1)
+		}()
+		r := gApp.Shutdown(gApp.Config.DrainTimeout)
+		if !r.Lossless() {
+			fmt.Fprintf(os.Stderr, "shutdown did not drain the pipeline: %s\n", r)
+		}
 		gApp.CleanupPlugins()
+		gApp.StopRecorder()
 		cancelFn()
 		os.Exit(0)
 	}()
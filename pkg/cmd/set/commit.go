@@ -0,0 +1,41 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package set
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/openconfig/gnmic/pkg/app"
+)
+
+// newConfirmCmd creates the set confirm command.
+func newConfirmCmd(gApp *app.App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "confirm",
+		Short:        "confirm a commit confirmed transaction",
+		PreRunE:      gApp.SetCommitPreRunE,
+		RunE:         gApp.SetConfirmRunE,
+		SilenceUsage: true,
+	}
+	gApp.InitSetCommitFlags(cmd)
+	return cmd
+}
+
+// newCancelCmd creates the set cancel command.
+func newCancelCmd(gApp *app.App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "cancel",
+		Short:        "cancel a commit confirmed transaction",
+		PreRunE:      gApp.SetCommitPreRunE,
+		RunE:         gApp.SetCancelRunE,
+		SilenceUsage: true,
+	}
+	gApp.InitSetCommitFlags(cmd)
+	return cmd
+}
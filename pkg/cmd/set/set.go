@@ -33,5 +33,7 @@ func New(gApp *app.App) *cobra.Command {
 		SilenceUsage: true,
 	}
 	gApp.InitSetFlags(cmd)
+	cmd.AddCommand(newConfirmCmd(gApp))
+	cmd.AddCommand(newCancelCmd(gApp))
 	return cmd
 }
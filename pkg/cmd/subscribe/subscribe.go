@@ -53,5 +53,19 @@ func New(gApp *app.App) *cobra.Command {
 		SilenceUsage: true,
 	}
 	gApp.InitSubscribeFlags(cmd)
+	cmd.AddCommand(newPollCmd(gApp))
+	return cmd
+}
+
+// newPollCmd creates the subscribe poll command.
+func newPollCmd(gApp *app.App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "poll",
+		Short:        "trigger a poll of a poll subscription run by a gnmic instance",
+		PreRunE:      gApp.SubscribePollPreRunE,
+		RunE:         gApp.SubscribePollRunE,
+		SilenceUsage: true,
+	}
+	gApp.InitSubscribePollFlags(cmd)
 	return cmd
 }
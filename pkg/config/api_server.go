@@ -9,11 +9,13 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"time"
 
 	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/cert"
 )
 
 const (
@@ -23,11 +25,21 @@ const (
 )
 
 type APIServer struct {
-	Address       string           `mapstructure:"address,omitempty" json:"address,omitempty"`
-	Timeout       time.Duration    `mapstructure:"timeout,omitempty" json:"timeout,omitempty"`
-	TLS           *types.TLSConfig `mapstructure:"tls,omitempty" json:"tls,omitempty"`
+	Address string           `mapstructure:"address,omitempty" json:"address,omitempty"`
+	Timeout time.Duration    `mapstructure:"timeout,omitempty" json:"timeout,omitempty"`
+	TLS     *types.TLSConfig `mapstructure:"tls,omitempty" json:"tls,omitempty"`
+	// certificate obtained from an ACME CA, mutually exclusive with TLS.
+	ACME          *cert.ACMEConfig `mapstructure:"acme,omitempty" json:"acme,omitempty"`
 	EnableMetrics bool             `mapstructure:"enable-metrics,omitempty" json:"enable-metrics,omitempty"`
 	Debug         bool             `mapstructure:"debug,omitempty" json:"debug,omitempty"`
+	EnableUI      bool             `mapstructure:"enable-ui,omitempty" json:"enable-ui,omitempty"`
+	// file persisting the components disabled at runtime
+	ComponentsStateFile string `mapstructure:"components-state-file,omitempty" json:"components-state-file,omitempty"`
+	// admin tokens, giving access to all the API endpoints and namespaces
+	Tokens []string `mapstructure:"tokens,omitempty" json:"-"`
+	// allows the targets and outputs configurations sent through the API
+	// to set a credentials-command, run by gnmic.
+	AllowCredentialsCommand bool `mapstructure:"allow-credentials-command,omitempty" json:"allow-credentials-command,omitempty"`
 }
 
 func (c *Config) GetAPIServer() error {
@@ -50,9 +62,28 @@ func (c *Config) GetAPIServer() error {
 			return fmt.Errorf("api-server TLS config error: %w", err)
 		}
 	}
+	if c.FileConfig.IsSet("api-server/acme") {
+		if c.APIServer.TLS != nil {
+			return errors.New("api-server: `tls` and `acme` are mutually exclusive")
+		}
+		c.APIServer.ACME = c.getACMEConfig("api-server/acme")
+		if err := c.APIServer.ACME.SetDefaults(); err != nil {
+			return fmt.Errorf("api-server: %w", err)
+		}
+	}
 
 	c.APIServer.EnableMetrics = os.ExpandEnv(c.FileConfig.GetString("api-server/enable-metrics")) == trueString
 	c.APIServer.Debug = os.ExpandEnv(c.FileConfig.GetString("api-server/debug")) == trueString
+	c.APIServer.EnableUI = os.ExpandEnv(c.FileConfig.GetString("api-server/enable-ui")) == trueString
+	c.APIServer.ComponentsStateFile = os.ExpandEnv(c.FileConfig.GetString("api-server/components-state-file"))
+	c.APIServer.AllowCredentialsCommand = os.ExpandEnv(c.FileConfig.GetString("api-server/allow-credentials-command")) == trueString
+	for _, tk := range c.FileConfig.GetStringSlice("api-server/tokens") {
+		tk = os.ExpandEnv(tk)
+		if tk == "" {
+			return fmt.Errorf("api-server: empty token")
+		}
+		c.APIServer.Tokens = append(c.APIServer.Tokens, tk)
+	}
 	c.setAPIServerDefaults()
 	return nil
 }
@@ -65,3 +96,27 @@ func (c *Config) setAPIServerDefaults() {
 		c.APIServer.Timeout = defaultAPIServerTimeout
 	}
 }
+
+func (c *Config) getACMEConfig(key string) *cert.ACMEConfig {
+	ac := &cert.ACMEConfig{
+		Domains:      c.FileConfig.GetStringSlice(key + "/domains"),
+		Email:        os.ExpandEnv(c.FileConfig.GetString(key + "/email")),
+		DirectoryURL: os.ExpandEnv(c.FileConfig.GetString(key + "/directory-url")),
+		AcceptTOS:    os.ExpandEnv(c.FileConfig.GetString(key+"/accept-tos")) == trueString,
+		Challenge:    os.ExpandEnv(c.FileConfig.GetString(key + "/challenge")),
+		HTTPAddress:  os.ExpandEnv(c.FileConfig.GetString(key + "/http-address")),
+		CacheDir:     os.ExpandEnv(c.FileConfig.GetString(key + "/cache-dir")),
+		RenewBefore:  c.FileConfig.GetDuration(key + "/renew-before"),
+	}
+	for i := range ac.Domains {
+		ac.Domains[i] = os.ExpandEnv(ac.Domains[i])
+	}
+	if c.FileConfig.IsSet(key + "/dns") {
+		ac.DNS = &cert.ACMEDNS{
+			Command:          os.ExpandEnv(c.FileConfig.GetString(key + "/dns/command")),
+			PropagationDelay: c.FileConfig.GetDuration(key + "/dns/propagation-delay"),
+			Timeout:          c.FileConfig.GetDuration(key + "/dns/timeout"),
+		}
+	}
+	return ac
+}
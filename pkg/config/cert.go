@@ -0,0 +1,159 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+const (
+	certStoreFile   = "file"
+	certStoreLocker = "locker"
+
+	defaultCertCAValidity    = 10 * 365 * 24 * time.Hour
+	defaultCertValidity      = 90 * 24 * time.Hour
+	defaultCertRenewBefore   = 30 * 24 * time.Hour
+	defaultCertCheckInterval = time.Hour
+)
+
+type certConfig struct {
+	// where the generated certificates are stored, one of `file` or `locker`.
+	// `locker` stores them in the clustering locker KV store,
+	// so that all the cluster members share the same CA.
+	Store string `mapstructure:"store,omitempty" json:"store,omitempty"`
+	// passphrase used to encrypt the private keys written to the locker.
+	EncryptionKey string `mapstructure:"encryption-key,omitempty" json:"-"`
+	// store the private keys in the locker in clear,
+	// required with store `locker` if encryption-key is not set.
+	AllowUnencryptedKeys bool `mapstructure:"allow-unencrypted-keys,omitempty" json:"allow-unencrypted-keys,omitempty"`
+	// directory where the certificates are written when store is `file`.
+	Dir string  `mapstructure:"dir,omitempty" json:"dir,omitempty"`
+	CA  *CertCA `mapstructure:"ca,omitempty" json:"ca,omitempty"`
+	// servers certificates attributes
+	Organization []string      `mapstructure:"organization,omitempty" json:"organization,omitempty"`
+	Validity     time.Duration `mapstructure:"validity,omitempty" json:"validity,omitempty"`
+	// the certificates are renewed when they expire in less than renew-before.
+	RenewBefore   time.Duration `mapstructure:"renew-before,omitempty" json:"renew-before,omitempty"`
+	CheckInterval time.Duration `mapstructure:"check-interval,omitempty" json:"check-interval,omitempty"`
+	// certificate usage per server, a nil value means the server does not use
+	// the generated certificates.
+	APIServer    *CertServer `mapstructure:"api-server,omitempty" json:"api-server,omitempty"`
+	GNMIServer   *CertServer `mapstructure:"gnmi-server,omitempty" json:"gnmi-server,omitempty"`
+	TunnelServer *CertServer `mapstructure:"tunnel-server,omitempty" json:"tunnel-server,omitempty"`
+}
+
+// CertCA defines the generated CA attributes.
+type CertCA struct {
+	CommonName   string        `mapstructure:"common-name,omitempty" json:"common-name,omitempty"`
+	Organization []string      `mapstructure:"organization,omitempty" json:"organization,omitempty"`
+	Validity     time.Duration `mapstructure:"validity,omitempty" json:"validity,omitempty"`
+}
+
+// CertServer defines the SANs of a server certificate.
+type CertServer struct {
+	// DNS names and IP addresses added to the certificate,
+	// in addition to the hostname, localhost and the loopback addresses.
+	Hosts []string `mapstructure:"hosts,omitempty" json:"hosts,omitempty"`
+}
+
+func (c *Config) GetCert() error {
+	if !c.FileConfig.IsSet("cert") {
+		return nil
+	}
+	c.Cert = new(certConfig)
+	c.Cert.Store = os.ExpandEnv(c.FileConfig.GetString("cert/store"))
+	c.Cert.Dir = os.ExpandEnv(c.FileConfig.GetString("cert/dir"))
+	c.Cert.EncryptionKey = os.ExpandEnv(c.FileConfig.GetString("cert/encryption-key"))
+	c.Cert.AllowUnencryptedKeys = c.FileConfig.GetBool("cert/allow-unencrypted-keys")
+	c.Cert.Organization = c.FileConfig.GetStringSlice("cert/organization")
+	c.Cert.Validity = c.FileConfig.GetDuration("cert/validity")
+	c.Cert.RenewBefore = c.FileConfig.GetDuration("cert/renew-before")
+	c.Cert.CheckInterval = c.FileConfig.GetDuration("cert/check-interval")
+	c.Cert.CA = &CertCA{
+		CommonName:   os.ExpandEnv(c.FileConfig.GetString("cert/ca/common-name")),
+		Organization: c.FileConfig.GetStringSlice("cert/ca/organization"),
+		Validity:     c.FileConfig.GetDuration("cert/ca/validity"),
+	}
+	c.Cert.APIServer = c.getCertServer("api-server")
+	c.Cert.GNMIServer = c.getCertServer("gnmi-server")
+	c.Cert.TunnelServer = c.getCertServer("tunnel-server")
+	return c.setCertDefaults()
+}
+
+func (c *Config) getCertServer(server string) *CertServer {
+	key := fmt.Sprintf("cert/%s", server)
+	if !c.FileConfig.IsSet(key) {
+		return nil
+	}
+	cs := new(CertServer)
+	cs.Hosts = c.FileConfig.GetStringSlice(key + "/hosts")
+	for i := range cs.Hosts {
+		cs.Hosts[i] = os.ExpandEnv(cs.Hosts[i])
+	}
+	return cs
+}
+
+func (c *Config) setCertDefaults() error {
+	switch c.Cert.Store {
+	case "":
+		c.Cert.Store = certStoreFile
+	case certStoreFile:
+	case certStoreLocker:
+		if !c.FileConfig.IsSet("clustering") {
+			return errors.New("cert: store `locker` requires a clustering configuration")
+		}
+		if c.Cert.EncryptionKey == "" && !c.Cert.AllowUnencryptedKeys {
+			return errors.New("cert: store `locker` requires an `encryption-key`, or `allow-unencrypted-keys` to store the private keys in clear")
+		}
+	default:
+		return fmt.Errorf("cert: unknown store %q", c.Cert.Store)
+	}
+	if c.Cert.Store == certStoreFile {
+		if c.Cert.Dir == "" {
+			home, err := homedir.Dir()
+			if err != nil {
+				return err
+			}
+			c.Cert.Dir = filepath.Join(home, ".gnmic", "certs")
+		}
+		var err error
+		c.Cert.Dir, err = homedir.Expand(c.Cert.Dir)
+		if err != nil {
+			return fmt.Errorf("cert: dir %q: %v", c.Cert.Dir, err)
+		}
+	}
+	if c.Cert.Validity <= 0 {
+		c.Cert.Validity = defaultCertValidity
+	}
+	if c.Cert.CA.Validity <= 0 {
+		c.Cert.CA.Validity = defaultCertCAValidity
+	}
+	if c.Cert.RenewBefore <= 0 {
+		c.Cert.RenewBefore = defaultCertRenewBefore
+	}
+	if c.Cert.CheckInterval <= 0 {
+		c.Cert.CheckInterval = defaultCertCheckInterval
+	}
+	if c.Cert.RenewBefore >= c.Cert.Validity || c.Cert.RenewBefore >= c.Cert.CA.Validity {
+		return fmt.Errorf("cert: renew-before (%s) must be shorter than the certificates validity", c.Cert.RenewBefore)
+	}
+	return nil
+}
+
+// UsesLocker returns true if the generated certificates
+// are stored in the clustering locker.
+func (cc *certConfig) UsesLocker() bool {
+	return cc != nil && cc.Store == certStoreLocker
+}
@@ -0,0 +1,90 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGetCertStore(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		wantErr bool
+	}{
+		{
+			name: "file",
+			in: `
+cert:
+  dir: /tmp/certs
+`,
+		},
+		{
+			name: "locker_without_clustering",
+			in: `
+cert:
+  store: locker
+  encryption-key: secret
+`,
+			wantErr: true,
+		},
+		{
+			name: "locker_encrypted",
+			in: `
+clustering:
+  cluster-name: c1
+cert:
+  store: locker
+  encryption-key: secret
+`,
+		},
+		{
+			name: "locker_unencrypted",
+			in: `
+clustering:
+  cluster-name: c1
+cert:
+  store: locker
+  allow-unencrypted-keys: true
+`,
+		},
+		{
+			name: "locker_no_encryption_key",
+			in: `
+clustering:
+  cluster-name: c1
+cert:
+  store: locker
+`,
+			wantErr: true,
+		},
+		{
+			name: "unknown_store",
+			in: `
+cert:
+  store: vault
+`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := New()
+			cfg.FileConfig.SetConfigType("yaml")
+			if err := cfg.FileConfig.ReadConfig(bytes.NewBufferString(tt.in)); err != nil {
+				t.Fatal(err)
+			}
+			err := cfg.GetCert()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -20,18 +20,44 @@ const (
 	defaultTargetAssignmentTimeout = 10 * time.Second
 	defaultServicesWatchTimer      = 1 * time.Minute
 	defaultLeaderWaitTimer         = 5 * time.Second
+	defaultLocksWatchTimer         = 5 * time.Second
+	//
+	defaultHealthProbeInterval         = 30 * time.Second
+	defaultHealthProbeTimeout          = 5 * time.Second
+	defaultHealthProbeStaleTimeout     = 2 * time.Minute
+	defaultHealthProbeFailureThreshold = 3
 )
 
 type clustering struct {
-	ClusterName             string                 `mapstructure:"cluster-name,omitempty" json:"cluster-name,omitempty" yaml:"cluster-name,omitempty"`
-	InstanceName            string                 `mapstructure:"instance-name,omitempty" json:"instance-name,omitempty" yaml:"instance-name,omitempty"`
-	ServiceAddress          string                 `mapstructure:"service-address,omitempty" json:"service-address,omitempty" yaml:"service-address,omitempty"`
-	ServicesWatchTimer      time.Duration          `mapstructure:"services-watch-timer,omitempty" json:"services-watch-timer,omitempty" yaml:"services-watch-timer,omitempty"`
-	TargetsWatchTimer       time.Duration          `mapstructure:"targets-watch-timer,omitempty" json:"targets-watch-timer,omitempty" yaml:"targets-watch-timer,omitempty"`
-	TargetAssignmentTimeout time.Duration          `mapstructure:"target-assignment-timeout,omitempty" json:"target-assignment-timeout,omitempty" yaml:"target-assignment-timeout,omitempty"`
-	LeaderWaitTimer         time.Duration          `mapstructure:"leader-wait-timer,omitempty" json:"leader-wait-timer,omitempty" yaml:"leader-wait-timer,omitempty"`
-	Tags                    []string               `mapstructure:"tags,omitempty" json:"tags,omitempty" yaml:"tags,omitempty"`
-	Locker                  map[string]interface{} `mapstructure:"locker,omitempty" json:"locker,omitempty" yaml:"locker,omitempty"`
+	ClusterName             string        `mapstructure:"cluster-name,omitempty" json:"cluster-name,omitempty" yaml:"cluster-name,omitempty"`
+	InstanceName            string        `mapstructure:"instance-name,omitempty" json:"instance-name,omitempty" yaml:"instance-name,omitempty"`
+	ServiceAddress          string        `mapstructure:"service-address,omitempty" json:"service-address,omitempty" yaml:"service-address,omitempty"`
+	ServicesWatchTimer      time.Duration `mapstructure:"services-watch-timer,omitempty" json:"services-watch-timer,omitempty" yaml:"services-watch-timer,omitempty"`
+	TargetsWatchTimer       time.Duration `mapstructure:"targets-watch-timer,omitempty" json:"targets-watch-timer,omitempty" yaml:"targets-watch-timer,omitempty"`
+	TargetAssignmentTimeout time.Duration `mapstructure:"target-assignment-timeout,omitempty" json:"target-assignment-timeout,omitempty" yaml:"target-assignment-timeout,omitempty"`
+	LeaderWaitTimer         time.Duration `mapstructure:"leader-wait-timer,omitempty" json:"leader-wait-timer,omitempty" yaml:"leader-wait-timer,omitempty"`
+	// interval between two listings of the targets locks, for the lockers not able to watch them.
+	LocksWatchTimer time.Duration `mapstructure:"locks-watch-timer,omitempty" json:"locks-watch-timer,omitempty" yaml:"locks-watch-timer,omitempty"`
+	// max number of locker operations per second run by the leader while dispatching targets.
+	LockerRateLimit int `mapstructure:"locker-rate-limit,omitempty" json:"locker-rate-limit,omitempty" yaml:"locker-rate-limit,omitempty"`
+	// members health probing run by the leader
+	HealthProbe *ClusterHealthProbe    `mapstructure:"health-probe,omitempty" json:"health-probe,omitempty" yaml:"health-probe,omitempty"`
+	Tags        []string               `mapstructure:"tags,omitempty" json:"tags,omitempty" yaml:"tags,omitempty"`
+	Locker      map[string]interface{} `mapstructure:"locker,omitempty" json:"locker,omitempty" yaml:"locker,omitempty"`
+}
+
+// ClusterHealthProbe configures the leader probing of the cluster members health.
+type ClusterHealthProbe struct {
+	// interval between two probes of the cluster members
+	Interval time.Duration `mapstructure:"interval,omitempty" json:"interval,omitempty" yaml:"interval,omitempty"`
+	// probe request timeout
+	Timeout time.Duration `mapstructure:"timeout,omitempty" json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// number of consecutive failed probes after which a member is degraded
+	FailureThreshold int `mapstructure:"failure-threshold,omitempty" json:"failure-threshold,omitempty" yaml:"failure-threshold,omitempty"`
+	// max subscription errors per second before a member is degraded, 0 means no limit
+	MaxErrorRate float64 `mapstructure:"max-error-rate,omitempty" json:"max-error-rate,omitempty" yaml:"max-error-rate,omitempty"`
+	// a target subscription without notifications for this duration is stale
+	StaleTimeout time.Duration `mapstructure:"stale-timeout,omitempty" json:"stale-timeout,omitempty" yaml:"stale-timeout,omitempty"`
 }
 
 func (c *Config) GetClustering() error {
@@ -46,6 +72,17 @@ func (c *Config) GetClustering() error {
 	c.Clustering.TargetAssignmentTimeout = c.FileConfig.GetDuration("clustering/target-assignment-timeout")
 	c.Clustering.ServicesWatchTimer = c.FileConfig.GetDuration("clustering/services-watch-timer")
 	c.Clustering.LeaderWaitTimer = c.FileConfig.GetDuration("clustering/leader-wait-timer")
+	c.Clustering.LocksWatchTimer = c.FileConfig.GetDuration("clustering/locks-watch-timer")
+	c.Clustering.LockerRateLimit = c.FileConfig.GetInt("clustering/locker-rate-limit")
+	if c.FileConfig.IsSet("clustering/health-probe") {
+		c.Clustering.HealthProbe = &ClusterHealthProbe{
+			Interval:         c.FileConfig.GetDuration("clustering/health-probe/interval"),
+			Timeout:          c.FileConfig.GetDuration("clustering/health-probe/timeout"),
+			FailureThreshold: c.FileConfig.GetInt("clustering/health-probe/failure-threshold"),
+			MaxErrorRate:     c.FileConfig.GetFloat64("clustering/health-probe/max-error-rate"),
+			StaleTimeout:     c.FileConfig.GetDuration("clustering/health-probe/stale-timeout"),
+		}
+	}
 	c.Clustering.Tags = c.FileConfig.GetStringSlice("clustering/tags")
 	for i := range c.Clustering.Tags {
 		c.Clustering.Tags[i] = os.ExpandEnv(c.Clustering.Tags[i])
@@ -86,4 +123,27 @@ func (c *Config) setClusteringDefaults() {
 	if c.Clustering.LeaderWaitTimer <= defaultLeaderWaitTimer {
 		c.Clustering.LeaderWaitTimer = defaultLeaderWaitTimer
 	}
+	if c.Clustering.LocksWatchTimer <= 0 {
+		c.Clustering.LocksWatchTimer = defaultLocksWatchTimer
+	}
+	if c.Clustering.LockerRateLimit < 0 {
+		c.Clustering.LockerRateLimit = 0
+	}
+	if c.Clustering.HealthProbe != nil {
+		if c.Clustering.HealthProbe.Interval <= 0 {
+			c.Clustering.HealthProbe.Interval = defaultHealthProbeInterval
+		}
+		if c.Clustering.HealthProbe.Timeout <= 0 {
+			c.Clustering.HealthProbe.Timeout = defaultHealthProbeTimeout
+		}
+		if c.Clustering.HealthProbe.FailureThreshold <= 0 {
+			c.Clustering.HealthProbe.FailureThreshold = defaultHealthProbeFailureThreshold
+		}
+		if c.Clustering.HealthProbe.MaxErrorRate < 0 {
+			c.Clustering.HealthProbe.MaxErrorRate = 0
+		}
+		if c.Clustering.HealthProbe.StaleTimeout <= 0 {
+			c.Clustering.HealthProbe.StaleTimeout = defaultHealthProbeStaleTimeout
+		}
+	}
 }
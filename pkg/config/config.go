@@ -23,7 +23,6 @@ import (
 	"time"
 
 	"github.com/adrg/xdg"
-	"github.com/itchyny/gojq"
 	"github.com/mitchellh/go-homedir"
 	"github.com/openconfig/gnmi/proto/gnmi"
 	"github.com/spf13/cobra"
@@ -36,6 +35,7 @@ import (
 	"github.com/openconfig/gnmic/pkg/api/types"
 	"github.com/openconfig/gnmic/pkg/api/utils"
 	gfile "github.com/openconfig/gnmic/pkg/file"
+	"github.com/openconfig/gnmic/pkg/formatters"
 )
 
 const (
@@ -53,17 +53,26 @@ type Config struct {
 	LocalFlags  `mapstructure:",squash"`
 	FileConfig  *viper.Viper `mapstructure:"-" json:"-" yaml:"-" `
 
-	Targets       map[string]*types.TargetConfig       `mapstructure:"targets,omitempty" json:"targets,omitempty" yaml:"targets,omitempty"`
-	Subscriptions map[string]*types.SubscriptionConfig `mapstructure:"subscriptions,omitempty" json:"subscriptions,omitempty" yaml:"subscriptions,omitempty"`
-	Outputs       map[string]map[string]interface{}    `mapstructure:"outputs,omitempty" json:"outputs,omitempty" yaml:"outputs,omitempty"`
-	Inputs        map[string]map[string]interface{}    `mapstructure:"inputs,omitempty" json:"inputs,omitempty" yaml:"inputs,omitempty"`
-	Processors    map[string]map[string]interface{}    `mapstructure:"processors,omitempty" json:"processors,omitempty" yaml:"processors,omitempty"`
-	Clustering    *clustering                          `mapstructure:"clustering,omitempty" json:"clustering,omitempty" yaml:"clustering,omitempty"`
-	GnmiServer    *gnmiServer                          `mapstructure:"gnmi-server,omitempty" json:"gnmi-server,omitempty" yaml:"gnmi-server,omitempty"`
-	APIServer     *APIServer                           `mapstructure:"api-server,omitempty" json:"api-server,omitempty" yaml:"api-server,omitempty"`
-	Loader        map[string]interface{}               `mapstructure:"loader,omitempty" json:"loader,omitempty" yaml:"loader,omitempty"`
-	Actions       map[string]map[string]interface{}    `mapstructure:"actions,omitempty" json:"actions,omitempty" yaml:"actions,omitempty"`
-	TunnelServer  *tunnelServer                        `mapstructure:"tunnel-server,omitempty" json:"tunnel-server,omitempty" yaml:"tunnel-server,omitempty"`
+	Targets         map[string]*types.TargetConfig       `mapstructure:"targets,omitempty" json:"targets,omitempty" yaml:"targets,omitempty"`
+	TargetGroups    map[string]map[string]interface{}    `mapstructure:"target-groups,omitempty" json:"target-groups,omitempty" yaml:"target-groups,omitempty"`
+	Subscriptions   map[string]*types.SubscriptionConfig `mapstructure:"subscriptions,omitempty" json:"subscriptions,omitempty" yaml:"subscriptions,omitempty"`
+	Outputs         map[string]map[string]interface{}    `mapstructure:"outputs,omitempty" json:"outputs,omitempty" yaml:"outputs,omitempty"`
+	Inputs          map[string]map[string]interface{}    `mapstructure:"inputs,omitempty" json:"inputs,omitempty" yaml:"inputs,omitempty"`
+	Processors      map[string]map[string]interface{}    `mapstructure:"processors,omitempty" json:"processors,omitempty" yaml:"processors,omitempty"`
+	Clustering      *clustering                          `mapstructure:"clustering,omitempty" json:"clustering,omitempty" yaml:"clustering,omitempty"`
+	GnmiServer      *gnmiServer                          `mapstructure:"gnmi-server,omitempty" json:"gnmi-server,omitempty" yaml:"gnmi-server,omitempty"`
+	APIServer       *APIServer                           `mapstructure:"api-server,omitempty" json:"api-server,omitempty" yaml:"api-server,omitempty"`
+	Loader          map[string]interface{}               `mapstructure:"loader,omitempty" json:"loader,omitempty" yaml:"loader,omitempty"`
+	Actions         map[string]map[string]interface{}    `mapstructure:"actions,omitempty" json:"actions,omitempty" yaml:"actions,omitempty"`
+	TunnelServer    *tunnelServer                        `mapstructure:"tunnel-server,omitempty" json:"tunnel-server,omitempty" yaml:"tunnel-server,omitempty"`
+	Spiffe          *spiffeConfig                        `mapstructure:"spiffe,omitempty" json:"spiffe,omitempty" yaml:"spiffe,omitempty"`
+	Cert            *certConfig                          `mapstructure:"cert,omitempty" json:"cert,omitempty" yaml:"cert,omitempty"`
+	Namespaces      map[string]*Namespace                `mapstructure:"namespaces,omitempty" json:"namespaces,omitempty" yaml:"namespaces,omitempty"`
+	Policies        map[string]*Policy                   `mapstructure:"policies,omitempty" json:"policies,omitempty" yaml:"policies,omitempty"`
+	SLOs            map[string]*SLO                      `mapstructure:"slos,omitempty" json:"slos,omitempty" yaml:"slos,omitempty"`
+	SessionTracking *SessionTracking                     `mapstructure:"session-tracking,omitempty" json:"session-tracking,omitempty" yaml:"session-tracking,omitempty"`
+	Inventory       *Inventory                           `mapstructure:"inventory,omitempty" json:"inventory,omitempty" yaml:"inventory,omitempty"`
+	QoS             *QoS                                 `mapstructure:"qos,omitempty" json:"qos,omitempty" yaml:"qos,omitempty"`
 	//
 	logger             *log.Logger
 	setRequestTemplate []*template.Template
@@ -100,6 +109,9 @@ type GlobalFlags struct {
 	LogMaxBackups int           `mapstructure:"log-max-backups,omitempty" json:"log-max-backups,omitempty" yaml:"log-max-backups,omitempty"`
 	LogCompress   bool          `mapstructure:"log-compress,omitempty" json:"log-compress,omitempty" yaml:"log-compress,omitempty"`
 	MaxMsgSize    int           `mapstructure:"max-msg-size,omitempty" json:"max-msg-size,omitempty" yaml:"max-msg-size,omitempty"`
+	DrainTimeout  time.Duration `mapstructure:"drain-timeout,omitempty" json:"drain-timeout,omitempty" yaml:"drain-timeout,omitempty"`
+	ExportWorkers int           `mapstructure:"export-workers,omitempty" json:"export-workers,omitempty" yaml:"export-workers,omitempty"`
+	MaxProcs      int           `mapstructure:"max-procs,omitempty" json:"max-procs,omitempty" yaml:"max-procs,omitempty"`
 	//PrometheusAddress string        `mapstructure:"prometheus-address,omitempty" json:"prometheus-address,omitempty" yaml:"prometheus-address,omitempty"`
 	PrintRequest     bool          `mapstructure:"print-request,omitempty" json:"print-request,omitempty" yaml:"print-request,omitempty"`
 	Retry            time.Duration `mapstructure:"retry,omitempty" json:"retry,omitempty" yaml:"retry,omitempty"`
@@ -118,14 +130,36 @@ type GlobalFlags struct {
 	UseTunnelServer  bool          `mapstructure:"use-tunnel-server,omitempty" json:"use-tunnel-server,omitempty" yaml:"use-tunnel-server,omitempty"`
 	AuthScheme       string        `mapstructure:"auth-scheme,omitempty" json:"auth-scheme,omitempty" yaml:"auth-scheme,omitempty"`
 	CalculateLatency bool          `mapstructure:"calculate-latency,omitempty" json:"calculate-latency,omitempty" yaml:"calculate-latency,omitempty"`
+	EncodingFallback []string      `mapstructure:"encoding-fallback,omitempty" json:"encoding-fallback,omitempty" yaml:"encoding-fallback,omitempty"`
+	ElectionID       string        `mapstructure:"election-id,omitempty" json:"election-id,omitempty" yaml:"election-id,omitempty"`
+	ElectionRole     string        `mapstructure:"election-role,omitempty" json:"election-role,omitempty" yaml:"election-role,omitempty"`
+	MaxSendMsgSize   int           `mapstructure:"max-send-msg-size,omitempty" json:"max-send-msg-size,omitempty" yaml:"max-send-msg-size,omitempty"`
+	// file only, per RPC deadlines and retry policy
+	Deadlines   *types.RPCDeadlines `mapstructure:"deadlines,omitempty" json:"deadlines,omitempty" yaml:"deadlines,omitempty"`
+	RetryPolicy *types.RetryPolicy  `mapstructure:"retry-policy,omitempty" json:"retry-policy,omitempty" yaml:"retry-policy,omitempty"`
+	// file only, targets clock skew handling
+	ClockSkew *types.ClockSkew `mapstructure:"clock-skew,omitempty" json:"clock-skew,omitempty" yaml:"clock-skew,omitempty"`
+	// file only, external command returning the targets credentials
+	CredentialsCommand *types.CredentialsCommand `mapstructure:"credentials-command,omitempty" json:"credentials-command,omitempty" yaml:"credentials-command,omitempty"`
+	// gRPC connections sharing between targets
+	MaxStreamsPerConnection int    `mapstructure:"max-streams-per-connection,omitempty" json:"max-streams-per-connection,omitempty" yaml:"max-streams-per-connection,omitempty"`
+	Compression             string `mapstructure:"compression,omitempty" json:"compression,omitempty" yaml:"compression,omitempty"`
 
 	Metadata             map[string]string `mapstructure:"metadata,omitempty" json:"metadata,omitempty" yaml:"metadata,omitempty"`
 	PluginProcessorsPath string            `mapstructure:"plugin-processors-path,omitempty" yaml:"plugin-processors-path,omitempty" json:"plugin-processors-path,omitempty"`
+	ErrorFormat          string            `mapstructure:"error-format,omitempty" yaml:"error-format,omitempty" json:"error-format,omitempty"`
+	// multi-target execution
+	TargetsFilter  string `mapstructure:"targets-filter,omitempty" yaml:"targets-filter,omitempty" json:"targets-filter,omitempty"`
+	MaxConcurrency int    `mapstructure:"max-concurrency,omitempty" yaml:"max-concurrency,omitempty" json:"max-concurrency,omitempty"`
+	FailFast       bool   `mapstructure:"fail-fast,omitempty" yaml:"fail-fast,omitempty" json:"fail-fast,omitempty"`
+	Summary        bool   `mapstructure:"summary,omitempty" yaml:"summary,omitempty" json:"summary,omitempty"`
 }
 
 type LocalFlags struct {
 	// Capabilities
-	CapabilitiesVersion bool `mapstructure:"capabilities-version,omitempty" json:"capabilities-version,omitempty" yaml:"capabilities-version,omitempty"`
+	CapabilitiesVersion      bool   `mapstructure:"capabilities-version,omitempty" json:"capabilities-version,omitempty" yaml:"capabilities-version,omitempty"`
+	CapabilitiesAggregate    bool   `mapstructure:"capabilities-aggregate,omitempty" json:"capabilities-aggregate,omitempty" yaml:"capabilities-aggregate,omitempty"`
+	CapabilitiesReportFormat string `mapstructure:"capabilities-report-format,omitempty" json:"capabilities-report-format,omitempty" yaml:"capabilities-report-format,omitempty"`
 	// Get
 	GetPath       []string `mapstructure:"get-path,omitempty" json:"get-path,omitempty" yaml:"get-path,omitempty"`
 	GetPrefix     string   `mapstructure:"get-prefix,omitempty" json:"get-prefix,omitempty" yaml:"get-prefix,omitempty"`
@@ -177,10 +211,12 @@ type LocalFlags struct {
 	SubscribeHeartbeatInterval time.Duration `mapstructure:"subscribe-heartbeat-interval,omitempty" json:"subscribe-heartbeat-interval,omitempty" yaml:"subscribe-heartbeat-interval,omitempty"`
 	SubscribeModel             []string      `mapstructure:"subscribe-model,omitempty" json:"subscribe-model,omitempty" yaml:"subscribe-model,omitempty"`
 	SubscribeQuiet             bool          `mapstructure:"subscribe-quiet,omitempty" json:"subscribe-quiet,omitempty" yaml:"subscribe-quiet,omitempty"`
+	SubscribeLive              bool          `mapstructure:"subscribe-live,omitempty" json:"subscribe-live,omitempty" yaml:"subscribe-live,omitempty"`
 	SubscribeTarget            string        `mapstructure:"subscribe-target,omitempty" json:"subscribe-target,omitempty" yaml:"subscribe-target,omitempty"`
 	SubscribeSetTarget         bool          `mapstructure:"subscribe-set-target,omitempty" json:"subscribe-set-target,omitempty" yaml:"subscribe-set-target,omitempty"`
 	SubscribeName              []string      `mapstructure:"subscribe-name,omitempty" json:"subscribe-name,omitempty" yaml:"subscribe-name,omitempty"`
 	SubscribeOutput            []string      `mapstructure:"subscribe-output,omitempty" json:"subscribe-output,omitempty" yaml:"subscribe-output,omitempty"`
+	SubscribeStreamTo          []string      `mapstructure:"subscribe-stream-to,omitempty" json:"subscribe-stream-to,omitempty" yaml:"subscribe-stream-to,omitempty"`
 	SubscribeWatchConfig       bool          `mapstructure:"subscribe-watch-config,omitempty" json:"subscribe-watch-config,omitempty" yaml:"subscribe-watch-config,omitempty"`
 	SubscribeBackoff           time.Duration `mapstructure:"subscribe-backoff,omitempty" json:"subscribe-backoff,omitempty" yaml:"subscribe-backoff,omitempty"`
 	SubscribeLockRetry         time.Duration `mapstructure:"subscribe-lock-retry,omitempty" json:"subscribe-lock-retry,omitempty" yaml:"subscribe-lock-retry,omitempty"`
@@ -188,6 +224,11 @@ type LocalFlags struct {
 	SubscribeHistoryStart      string        `mapstructure:"subscribe-history-start,omitempty" json:"subscribe-history-start,omitempty" yaml:"subscribe-history-start,omitempty"`
 	SubscribeHistoryEnd        string        `mapstructure:"subscribe-history-end,omitempty" json:"subscribe-history-end,omitempty" yaml:"subscribe-history-end,omitempty"`
 	SubscribeDepth             uint32        `mapstructure:"subscribe-depth,omitempty" yaml:"subscribe-depth,omitempty" json:"subscribe-depth,omitempty"`
+	// Sub Poll
+	PollAPIAddress string `mapstructure:"poll-api-address,omitempty" json:"poll-api-address,omitempty" yaml:"poll-api-address,omitempty"`
+	PollAPIToken   string `mapstructure:"poll-api-token,omitempty" json:"-" yaml:"-"`
+	PollTarget     string `mapstructure:"poll-target,omitempty" json:"poll-target,omitempty" yaml:"poll-target,omitempty"`
+	PollName       string `mapstructure:"poll-name,omitempty" json:"poll-name,omitempty" yaml:"poll-name,omitempty"`
 	// Path
 	PathPathType   string `mapstructure:"path-path-type,omitempty" json:"path-path-type,omitempty" yaml:"path-path-type,omitempty"`
 	PathWithDescr  bool   `mapstructure:"path-descr,omitempty" json:"path-descr,omitempty" yaml:"path-descr,omitempty"`
@@ -196,6 +237,8 @@ type LocalFlags struct {
 	PathSearch     bool   `mapstructure:"path-search,omitempty" json:"path-search,omitempty" yaml:"path-search,omitempty"`
 	PathState      bool   `mapstructure:"path-state,omitempty" json:"path-state,omitempty" yaml:"path-state,omitempty"`
 	PathConfig     bool   `mapstructure:"path-config,omitempty" json:"path-config,omitempty" yaml:"path-config,omitempty"`
+	PathLookup     string `mapstructure:"path-lookup,omitempty" json:"path-lookup,omitempty" yaml:"path-lookup,omitempty"`
+	PathJSONSchema bool   `mapstructure:"path-json-schema,omitempty" json:"path-json-schema,omitempty" yaml:"path-json-schema,omitempty"`
 	// Prompt
 	PromptFile                  []string `mapstructure:"prompt-file,omitempty" json:"prompt-file,omitempty" yaml:"prompt-file,omitempty"`
 	PromptExclude               []string `mapstructure:"prompt-exclude,omitempty" json:"prompt-exclude,omitempty" yaml:"prompt-exclude,omitempty"`
@@ -208,6 +251,7 @@ type LocalFlags struct {
 	PromptDescriptionWithPrefix bool     `mapstructure:"prompt-description-with-prefix,omitempty" json:"prompt-description-with-prefix,omitempty" yaml:"prompt-description-with-prefix,omitempty"`
 	PromptDescriptionWithTypes  bool     `mapstructure:"prompt-description-with-types,omitempty" json:"prompt-description-with-types,omitempty" yaml:"prompt-description-with-types,omitempty"`
 	PromptSuggestWithOrigin     bool     `mapstructure:"prompt-suggest-with-origin,omitempty" json:"prompt-suggest-with-origin,omitempty" yaml:"prompt-suggest-with-origin,omitempty"`
+	PromptEventsBufferSize      int      `mapstructure:"prompt-events-buffer-size,omitempty" json:"prompt-events-buffer-size,omitempty" yaml:"prompt-events-buffer-size,omitempty"`
 	// Listen
 	ListenMaxConcurrentStreams uint32 `mapstructure:"listen-max-concurrent-streams,omitempty" json:"listen-max-concurrent-streams,omitempty" yaml:"listen-max-concurrent-streams,omitempty"`
 	ListenPrometheusAddress    string `mapstructure:"listen-prometheus-address,omitempty" json:"listen-prometheus-address,omitempty" yaml:"listen-prometheus-address,omitempty"`
@@ -266,6 +310,48 @@ type LocalFlags struct {
 	ProcessorInputDelimiter string   `mapstructure:"processor-input-delimiter,omitempty" yaml:"processor-input-delimiter,omitempty" json:"processor-input-delimiter,omitempty"`
 	ProcessorName           []string `mapstructure:"processor-name,omitempty" yaml:"processor-name,omitempty" json:"processor-name,omitempty"`
 	ProcessorOutput         string   `mapstructure:"processor-output,omitempty" yaml:"processor-output,omitempty" json:"processor-output,omitempty"`
+	// Processor test
+	ProcessorTestInput     string `mapstructure:"processor-test-input,omitempty" yaml:"processor-test-input,omitempty" json:"processor-test-input,omitempty"`
+	ProcessorTestDelimiter string `mapstructure:"processor-test-delimiter,omitempty" yaml:"processor-test-delimiter,omitempty" json:"processor-test-delimiter,omitempty"`
+	ProcessorTestPipeline  string `mapstructure:"processor-test-pipeline,omitempty" yaml:"processor-test-pipeline,omitempty" json:"processor-test-pipeline,omitempty"`
+	ProcessorTestGolden    string `mapstructure:"processor-test-golden,omitempty" yaml:"processor-test-golden,omitempty" json:"processor-test-golden,omitempty"`
+	ProcessorTestUpdate    bool   `mapstructure:"processor-test-update,omitempty" yaml:"processor-test-update,omitempty" json:"processor-test-update,omitempty"`
+	// Template test
+	TemplateTestTemplate      string        `mapstructure:"template-test-template,omitempty" yaml:"template-test-template,omitempty" json:"template-test-template,omitempty"`
+	TemplateTestTemplateFile  string        `mapstructure:"template-test-template-file,omitempty" yaml:"template-test-template-file,omitempty" json:"template-test-template-file,omitempty"`
+	TemplateTestOutput        string        `mapstructure:"template-test-output,omitempty" yaml:"template-test-output,omitempty" json:"template-test-output,omitempty"`
+	TemplateTestField         string        `mapstructure:"template-test-field,omitempty" yaml:"template-test-field,omitempty" json:"template-test-field,omitempty"`
+	TemplateTestInput         string        `mapstructure:"template-test-input,omitempty" yaml:"template-test-input,omitempty" json:"template-test-input,omitempty"`
+	TemplateTestTimeout       time.Duration `mapstructure:"template-test-timeout,omitempty" yaml:"template-test-timeout,omitempty" json:"template-test-timeout,omitempty"`
+	TemplateTestMaxOutputSize int           `mapstructure:"template-test-max-output-size,omitempty" yaml:"template-test-max-output-size,omitempty" json:"template-test-max-output-size,omitempty"`
+	// Config export and import
+	ConfigExportOutput        string `mapstructure:"config-export-output,omitempty" yaml:"config-export-output,omitempty" json:"config-export-output,omitempty"`
+	ConfigExportLoaderTargets bool   `mapstructure:"config-export-loader-targets,omitempty" yaml:"config-export-loader-targets,omitempty" json:"config-export-loader-targets,omitempty"`
+	ConfigImportFile          string `mapstructure:"config-import-file,omitempty" yaml:"config-import-file,omitempty" json:"config-import-file,omitempty"`
+	ConfigAPIToken            string `mapstructure:"config-api-token,omitempty" yaml:"config-api-token,omitempty" json:"config-api-token,omitempty"`
+	// Record
+	RecordFile string `mapstructure:"record-file,omitempty" yaml:"record-file,omitempty" json:"record-file,omitempty"`
+	// Replay
+	ReplayFile  string  `mapstructure:"replay-file,omitempty" yaml:"replay-file,omitempty" json:"replay-file,omitempty"`
+	ReplaySpeed float64 `mapstructure:"replay-speed,omitempty" yaml:"replay-speed,omitempty" json:"replay-speed,omitempty"`
+	ReplayStart string  `mapstructure:"replay-start,omitempty" yaml:"replay-start,omitempty" json:"replay-start,omitempty"`
+	ReplayEnd   string  `mapstructure:"replay-end,omitempty" yaml:"replay-end,omitempty" json:"replay-end,omitempty"`
+	ReplayLoop  bool    `mapstructure:"replay-loop,omitempty" yaml:"replay-loop,omitempty" json:"replay-loop,omitempty"`
+	// Bench
+	BenchAddress  string        `mapstructure:"bench-listen,omitempty" yaml:"bench-listen,omitempty" json:"bench-listen,omitempty"`
+	BenchRate     int           `mapstructure:"bench-rate,omitempty" yaml:"bench-rate,omitempty" json:"bench-rate,omitempty"`
+	BenchUpdates  int           `mapstructure:"bench-updates,omitempty" yaml:"bench-updates,omitempty" json:"bench-updates,omitempty"`
+	BenchDepth    int           `mapstructure:"bench-depth,omitempty" yaml:"bench-depth,omitempty" json:"bench-depth,omitempty"`
+	BenchKeys     int           `mapstructure:"bench-keys,omitempty" yaml:"bench-keys,omitempty" json:"bench-keys,omitempty"`
+	BenchTargets  int           `mapstructure:"bench-targets,omitempty" yaml:"bench-targets,omitempty" json:"bench-targets,omitempty"`
+	BenchDuration time.Duration `mapstructure:"bench-duration,omitempty" yaml:"bench-duration,omitempty" json:"bench-duration,omitempty"`
+	BenchOutput   []string      `mapstructure:"bench-output,omitempty" yaml:"bench-output,omitempty" json:"bench-output,omitempty"`
+	// Server
+	ServerAddress        string        `mapstructure:"server-address,omitempty" yaml:"server-address,omitempty" json:"server-address,omitempty"`
+	ServerMock           bool          `mapstructure:"server-mock,omitempty" yaml:"server-mock,omitempty" json:"server-mock,omitempty"`
+	ServerFixtures       string        `mapstructure:"server-fixtures,omitempty" yaml:"server-fixtures,omitempty" json:"server-fixtures,omitempty"`
+	ServerJitter         float64       `mapstructure:"server-jitter,omitempty" yaml:"server-jitter,omitempty" json:"server-jitter,omitempty"`
+	ServerSampleInterval time.Duration `mapstructure:"server-sample-interval,omitempty" yaml:"server-sample-interval,omitempty" json:"server-sample-interval,omitempty"`
 }
 
 func New() *Config {
@@ -274,6 +360,7 @@ func New() *Config {
 		LocalFlags{},
 		viper.NewWithOptions(viper.KeyDelimiter("/")),
 		make(map[string]*types.TargetConfig),
+		nil,
 		make(map[string]*types.SubscriptionConfig),
 		make(map[string]map[string]interface{}),
 		make(map[string]map[string]interface{}),
@@ -284,6 +371,14 @@ func New() *Config {
 		nil,
 		nil,
 		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
 		log.New(io.Discard, configLogPrefix, utils.DefaultLoggingFlags),
 		nil,
 		make(map[string]interface{}),
@@ -461,7 +556,14 @@ func (c *Config) CreateGetRequest(tc *types.TargetConfig) (*gnmi.GetRequest, err
 	if c.LocalFlags.GetDepth > 0 {
 		gnmiOpts = append(gnmiOpts, api.Extension_Depth(c.LocalFlags.GetDepth))
 	}
-	return api.NewGetRequest(gnmiOpts...)
+	req, err := api.NewGetRequest(gnmiOpts...)
+	if err != nil {
+		return nil, err
+	}
+	if p := targetProfile(tc); p != nil {
+		req.Prefix = p.applyPaths(req.GetPrefix(), req.GetPath()...)
+	}
+	return req, nil
 }
 
 func (c *Config) CreateGASGetRequest() (*gnmi.GetRequest, error) {
@@ -523,11 +625,7 @@ func (c *Config) execPathTemplate(tplString string, input interface{}) (string,
 		return "", nil
 	}
 	tplString = os.ExpandEnv(tplString)
-	q, err := gojq.Parse(tplString)
-	if err != nil {
-		return "", err
-	}
-	code, err := gojq.Compile(q)
+	code, err := formatters.CompileJQ(tplString)
 	if err != nil {
 		return "", err
 	}
@@ -563,11 +661,7 @@ func (c *Config) execValueTemplate(tplString string, input interface{}) (string,
 		return "", nil
 	}
 	tplString = os.ExpandEnv(tplString)
-	q, err := gojq.Parse(tplString)
-	if err != nil {
-		return "", err
-	}
-	code, err := gojq.Compile(q)
+	code, err := formatters.CompileJQ(tplString)
 	if err != nil {
 		return "", err
 	}
@@ -943,7 +1037,7 @@ func ExpandOSPaths(paths []string) ([]string, error) {
 }
 
 func expandOSPath(p string) (string, error) {
-	if p == "-" || p == "" {
+	if p == "-" || p == "" || utils.IsKeyURI(p) {
 		return p, nil
 	}
 	if strings.HasPrefix(p, "http://") ||
@@ -38,7 +38,7 @@ var createGetRequestTestSet = map[string]struct {
 				Encoding: "dummy",
 			},
 			LocalFlags{},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: nil,
 		err: api.ErrInvalidValue,
@@ -51,7 +51,7 @@ var createGetRequestTestSet = map[string]struct {
 			LocalFlags{
 				GetPrefix: "/invalid/]prefix",
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: nil,
 		err: api.ErrInvalidValue,
@@ -64,7 +64,7 @@ var createGetRequestTestSet = map[string]struct {
 			LocalFlags{
 				GetPrefix: "/invalid/]path",
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: nil,
 		err: api.ErrInvalidValue,
@@ -78,7 +78,7 @@ var createGetRequestTestSet = map[string]struct {
 				GetPrefix: "/valid/path",
 				GetType:   "dummy",
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: nil,
 		err: api.ErrInvalidValue,
@@ -91,7 +91,7 @@ var createGetRequestTestSet = map[string]struct {
 			LocalFlags{
 				GetPath: []string{"/valid/path"},
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: &gnmi.GetRequest{
 			Path: []*gnmi.Path{
@@ -114,7 +114,7 @@ var createGetRequestTestSet = map[string]struct {
 				GetPath: []string{"/valid/path"},
 				GetType: "state",
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: &gnmi.GetRequest{
 			Path: []*gnmi.Path{
@@ -137,7 +137,7 @@ var createGetRequestTestSet = map[string]struct {
 			LocalFlags{
 				GetPath: []string{"/valid/path"},
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: &gnmi.GetRequest{
 			Path: []*gnmi.Path{
@@ -161,7 +161,7 @@ var createGetRequestTestSet = map[string]struct {
 				GetPrefix: "/valid/prefix",
 				GetPath:   []string{"/valid/path"},
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: &gnmi.GetRequest{
 			Prefix: &gnmi.Path{
@@ -193,7 +193,7 @@ var createGetRequestTestSet = map[string]struct {
 					"/valid/path2",
 				},
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: &gnmi.GetRequest{
 			Path: []*gnmi.Path{
@@ -228,7 +228,7 @@ var createSetRequestTestSet = map[string]struct {
 				SetDelimiter: ":::",
 				SetUpdate:    []string{"/valid/path:::json:::value"},
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: &gnmi.SetRequest{
 			Update: []*gnmi.Update{
@@ -256,7 +256,7 @@ var createSetRequestTestSet = map[string]struct {
 				SetDelimiter: ":::",
 				SetReplace:   []string{"/valid/path:::json:::value"},
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: &gnmi.SetRequest{
 			Replace: []*gnmi.Update{
@@ -283,7 +283,7 @@ var createSetRequestTestSet = map[string]struct {
 			LocalFlags{
 				SetDelete: []string{"/valid/path"},
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: &gnmi.SetRequest{
 			Delete: []*gnmi.Path{
@@ -307,7 +307,7 @@ var createSetRequestTestSet = map[string]struct {
 					"/valid/path2:::json_ietf:::value2",
 				},
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: &gnmi.SetRequest{
 			Update: []*gnmi.Update{
@@ -351,7 +351,7 @@ var createSetRequestTestSet = map[string]struct {
 					"/valid/path2:::json_ietf:::value2",
 				},
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: &gnmi.SetRequest{
 			Replace: []*gnmi.Update{
@@ -394,7 +394,7 @@ var createSetRequestTestSet = map[string]struct {
 					"/valid/path2",
 				},
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: &gnmi.SetRequest{
 			Delete: []*gnmi.Path{
@@ -423,7 +423,7 @@ var createSetRequestTestSet = map[string]struct {
 				SetReplace:   []string{"/valid/path2:::json:::value2"},
 				SetDelete:    []string{"/valid/path"},
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: &gnmi.SetRequest{
 			Update: []*gnmi.Update{
@@ -476,7 +476,7 @@ var createSetRequestTestSet = map[string]struct {
 				SetUpdatePath:  []string{"/valid/path"},
 				SetUpdateValue: []string{"value"},
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: &gnmi.SetRequest{
 			Update: []*gnmi.Update{
@@ -506,7 +506,7 @@ var createSetRequestTestSet = map[string]struct {
 				SetReplacePath:  []string{"/valid/path"},
 				SetReplaceValue: []string{"value"},
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: &gnmi.SetRequest{
 			Replace: []*gnmi.Update{
@@ -536,7 +536,7 @@ var createSetRequestTestSet = map[string]struct {
 				SetUnionReplacePath:  []string{"/valid/path"},
 				SetUnionReplaceValue: []string{"value"},
 			},
-			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+			nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
 		},
 		out: &gnmi.SetRequest{
 			UnionReplace: []*gnmi.Update{
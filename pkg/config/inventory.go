@@ -0,0 +1,58 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+const (
+	InventoryKindInterfaces = "interfaces"
+	InventoryKindLAGs       = "lags"
+	InventoryKindOptics     = "optics"
+)
+
+var defaultInventoryKinds = []string{InventoryKindInterfaces, InventoryKindLAGs, InventoryKindOptics}
+
+// Inventory builds a normalized inventory of the targets
+// from the received interfaces and components state paths.
+type Inventory struct {
+	// kinds of items in the inventory: interfaces, lags and/or optics
+	Kinds []string `mapstructure:"kinds,omitempty" json:"kinds,omitempty"`
+}
+
+// GetInventory reads and validates the inventory section.
+func (c *Config) GetInventory() error {
+	if !c.FileConfig.IsSet("inventory") {
+		return nil
+	}
+	inv := new(Inventory)
+	err := mapstructure.Decode(c.FileConfig.Get("inventory"), inv)
+	if err != nil {
+		return fmt.Errorf("inventory: %v", err)
+	}
+	if len(inv.Kinds) == 0 {
+		inv.Kinds = slices.Clone(defaultInventoryKinds)
+	}
+	for _, k := range inv.Kinds {
+		if !slices.Contains(defaultInventoryKinds, k) {
+			return fmt.Errorf("inventory: unknown kind %q", k)
+		}
+	}
+	c.Inventory = inv
+	return nil
+}
+
+// Includes returns true if the items of kind are in the inventory.
+func (inv *Inventory) Includes(kind string) bool {
+	return inv != nil && slices.Contains(inv.Kinds, kind)
+}
@@ -0,0 +1,52 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+)
+
+// GetJQLibraries reads the jq-libraries section and makes the libraries
+// available to the jq expressions used in processors, conditions and filters.
+func (c *Config) GetJQLibraries() error {
+	libsDef := c.FileConfig.GetStringMap("jq-libraries")
+	if len(libsDef) == 0 {
+		return nil
+	}
+	libs := make(map[string]*formatters.JQLibrary, len(libsDef))
+	for name, libDef := range libsDef {
+		lib := new(formatters.JQLibrary)
+		switch libDef := convert(libDef).(type) {
+		case string:
+			lib.Source = libDef
+		case map[string]interface{}:
+			err := formatters.DecodeConfig(libDef, lib)
+			if err != nil {
+				return fmt.Errorf("jq library %q: %v", name, err)
+			}
+		default:
+			return fmt.Errorf("jq library %q: unexpected config format %T", name, libDef)
+		}
+		if lib.File != "" {
+			if lib.Source != "" {
+				return fmt.Errorf("jq library %q: only one of 'source' or 'file' can be set", name)
+			}
+			b, err := os.ReadFile(os.ExpandEnv(lib.File))
+			if err != nil {
+				return fmt.Errorf("jq library %q: %v", name, err)
+			}
+			lib.Source = string(b)
+		}
+		libs[name] = lib
+	}
+	return formatters.SetJQLibraries(libs)
+}
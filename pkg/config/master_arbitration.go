@@ -0,0 +1,93 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/api"
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+// electionIDAuto makes gNMIc generate an election ID once per session,
+// higher than the IDs of the previous sessions.
+const electionIDAuto = "auto"
+
+var (
+	autoElectionIDOnce sync.Once
+	autoElectionID     string
+)
+
+// resolveElectionID validates the election ID value id,
+// and replaces it with the session election ID if set to "auto".
+func resolveElectionID(id string) (string, error) {
+	if strings.ToLower(id) == electionIDAuto {
+		autoElectionIDOnce.Do(func() {
+			autoElectionID = strconv.FormatInt(time.Now().UnixNano(), 10)
+		})
+		return autoElectionID, nil
+	}
+	_, _, err := ParseElectionID(id)
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// ParseElectionID parses an election ID in the format [HIGH:]LOW,
+// where HIGH and LOW are the uint64 high and low parts of the 128 bits ID.
+func ParseElectionID(id string) (uint64, uint64, error) {
+	var high uint64
+	var err error
+	lowStr := id
+	if h, l, ok := strings.Cut(id, ":"); ok {
+		high, err = strconv.ParseUint(strings.TrimSpace(h), 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid election-id %q: %v", id, err)
+		}
+		lowStr = l
+	}
+	low, err := strconv.ParseUint(strings.TrimSpace(lowStr), 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid election-id %q: %v", id, err)
+	}
+	if high == 0 && low == 0 {
+		return 0, 0, fmt.Errorf("invalid election-id %q: must be greater than 0", id)
+	}
+	return high, low, nil
+}
+
+// MasterArbitrationOpt returns the master arbitration extension option
+// to add to the Set and Subscribe requests sent to target tc,
+// nil if no election ID is set.
+func (c *Config) MasterArbitrationOpt(tc *types.TargetConfig) (api.GNMIOption, error) {
+	electionID, role := c.ElectionID, c.ElectionRole
+	if tc != nil && tc.ElectionID != "" {
+		electionID = tc.ElectionID
+	}
+	if tc != nil && tc.ElectionRole != "" {
+		role = tc.ElectionRole
+	}
+	if electionID == "" {
+		return nil, nil
+	}
+	electionID, err := resolveElectionID(electionID)
+	if err != nil {
+		return nil, err
+	}
+	high, low, err := ParseElectionID(electionID)
+	if err != nil {
+		return nil, err
+	}
+	return api.Extension_MasterArbitration(high, low, role), nil
+}
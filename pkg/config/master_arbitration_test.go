@@ -0,0 +1,102 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"testing"
+
+	"github.com/AlekSi/pointer"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+func TestParseElectionID(t *testing.T) {
+	tests := []struct {
+		id       string
+		wantHigh uint64
+		wantLow  uint64
+		wantErr  bool
+	}{
+		{id: "1", wantLow: 1},
+		{id: "2:3", wantHigh: 2, wantLow: 3},
+		{id: "1:0", wantHigh: 1},
+		{id: "0", wantErr: true},
+		{id: "0:0", wantErr: true},
+		{id: "-1", wantErr: true},
+		{id: "a:1", wantErr: true},
+		{id: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			high, low, err := ParseElectionID(tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if high != tt.wantHigh || low != tt.wantLow {
+				t.Errorf("got %d:%d, want %d:%d", high, low, tt.wantHigh, tt.wantLow)
+			}
+		})
+	}
+}
+
+func TestResolveElectionIDAuto(t *testing.T) {
+	id1, err := resolveElectionID("auto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id2, err := resolveElectionID("AUTO")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id1 != id2 {
+		t.Errorf("expected the same session election ID, got %q and %q", id1, id2)
+	}
+	if _, _, err := ParseElectionID(id1); err != nil {
+		t.Errorf("invalid auto election ID %q: %v", id1, err)
+	}
+}
+
+func TestCreateSubscribeRequestMasterArbitration(t *testing.T) {
+	c := &Config{GlobalFlags: GlobalFlags{ElectionID: "1:2", ElectionRole: "r1"}}
+	sc := &types.SubscriptionConfig{
+		Name:     "sub1",
+		Paths:    []string{"interface"},
+		Encoding: pointer.ToString("json"),
+	}
+	tests := []struct {
+		name     string
+		tc       *types.TargetConfig
+		wantHigh uint64
+		wantLow  uint64
+		wantRole string
+	}{
+		{name: "global", tc: &types.TargetConfig{Name: "t1"}, wantHigh: 1, wantLow: 2, wantRole: "r1"},
+		{name: "target", tc: &types.TargetConfig{Name: "t1", ElectionID: "5", ElectionRole: "r2"}, wantLow: 5, wantRole: "r2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := c.CreateSubscribeRequest(sc, tt.tc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(req.GetExtension()) != 1 {
+				t.Fatalf("expected 1 extension, got %v", req.GetExtension())
+			}
+			ma := req.GetExtension()[0].GetMasterArbitration()
+			if ma.GetElectionId().GetHigh() != tt.wantHigh || ma.GetElectionId().GetLow() != tt.wantLow || ma.GetRole().GetId() != tt.wantRole {
+				t.Errorf("unexpected master arbitration extension: %v", ma)
+			}
+		})
+	}
+	c.ElectionID = "invalid"
+	_, err := c.CreateSubscribeRequest(sc, nil)
+	if err == nil {
+		t.Errorf("expected an error with an invalid election ID")
+	}
+}
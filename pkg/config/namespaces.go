@@ -0,0 +1,181 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+// Namespace groups targets, subscriptions and outputs.
+// The API tokens of a namespace only give access to the namespace resources.
+type Namespace struct {
+	Tokens []string `mapstructure:"tokens,omitempty" json:"-"`
+	// name of the policy applied to the namespace data
+	Policy string `mapstructure:"policy,omitempty" json:"policy,omitempty"`
+}
+
+// GetNamespaces reads the namespaces and validates that an API token
+// does not belong to multiple namespaces or to the api-server admin tokens.
+func (c *Config) GetNamespaces() error {
+	if !c.FileConfig.IsSet("namespaces") {
+		return nil
+	}
+	nss := c.FileConfig.GetStringMap("namespaces")
+	c.Namespaces = make(map[string]*Namespace, len(nss))
+	tokens := make(map[string]string)
+	if c.APIServer != nil {
+		for _, tk := range c.APIServer.Tokens {
+			tokens[tk] = ""
+		}
+	}
+	for name, nsCfg := range nss {
+		ns := new(Namespace)
+		if nsCfg != nil {
+			if err := mapstructure.Decode(nsCfg, ns); err != nil {
+				return fmt.Errorf("namespace %q: %v", name, err)
+			}
+		}
+		for i, tk := range ns.Tokens {
+			tk = os.ExpandEnv(tk)
+			if tk == "" {
+				return fmt.Errorf("namespace %q: empty token", name)
+			}
+			if other, ok := tokens[tk]; ok {
+				if other == "" {
+					return fmt.Errorf("namespace %q: token is also an api-server admin token", name)
+				}
+				return fmt.Errorf("namespace %q: token is also used by namespace %q", name, other)
+			}
+			tokens[tk] = name
+			ns.Tokens[i] = tk
+		}
+		c.Namespaces[name] = ns
+	}
+	// the cluster members call each other's API with an admin token.
+	if c.Clustering != nil && len(c.Namespaces) > 0 && (c.APIServer == nil || len(c.APIServer.Tokens) == 0) {
+		return errors.New("namespaces with clustering require an api-server admin token")
+	}
+	return nil
+}
+
+// APIAuthEnabled returns true if the API requests must carry a token.
+func (c *Config) APIAuthEnabled() bool {
+	return len(c.Namespaces) > 0 || (c.APIServer != nil && len(c.APIServer.Tokens) > 0)
+}
+
+// TokenNamespace returns the namespace the API token tk gives access to.
+// all is true if tk is an api-server admin token giving access to all the namespaces.
+// ok is false if the token is unknown.
+func (c *Config) TokenNamespace(tk string) (ns string, all bool, ok bool) {
+	if tk == "" {
+		return "", false, false
+	}
+	if c.APIServer != nil {
+		for _, t := range c.APIServer.Tokens {
+			if tokenEqual(t, tk) {
+				return "", true, true
+			}
+		}
+	}
+	for name, n := range c.Namespaces {
+		for _, t := range n.Tokens {
+			if tokenEqual(t, tk) {
+				return name, false, true
+			}
+		}
+	}
+	return "", false, false
+}
+
+// tokenEqual compares the tokens in constant time.
+func tokenEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// OutputNamespace returns the namespace of the output configuration outCfg.
+func OutputNamespace(outCfg map[string]interface{}) string {
+	ns, _ := outCfg["namespace"].(string)
+	return ns
+}
+
+// ValidateNamespaces checks that the targets, subscriptions and outputs namespaces are defined
+// and that targets, subscriptions and outputs only reference subscriptions and outputs of their own namespace.
+func (c *Config) ValidateNamespaces() error {
+	for name, sc := range c.Subscriptions {
+		if err := c.checkNamespace("subscription", name, sc.Namespace); err != nil {
+			return err
+		}
+		if err := c.checkOutputsNamespace("subscription", name, sc.Namespace, sc.Outputs); err != nil {
+			return err
+		}
+	}
+	for name, outCfg := range c.Outputs {
+		if err := c.checkNamespace("output", name, OutputNamespace(outCfg)); err != nil {
+			return err
+		}
+		if err := c.checkOutputsNamespace("output", name, OutputNamespace(outCfg), outputs.Members(outCfg)); err != nil {
+			return err
+		}
+	}
+	for _, tc := range c.Targets {
+		if err := c.ValidateTargetNamespace(tc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateTargetNamespace checks that the target namespace is defined
+// and that its subscriptions and outputs belong to the same namespace.
+func (c *Config) ValidateTargetNamespace(tc *types.TargetConfig) error {
+	if err := c.checkNamespace("target", tc.Name, tc.Namespace); err != nil {
+		return err
+	}
+	for _, sub := range tc.Subscriptions {
+		sc, ok := c.Subscriptions[sub]
+		if !ok {
+			continue
+		}
+		if sc.Namespace != tc.Namespace {
+			return fmt.Errorf("target %q: subscription %q does not belong to namespace %q", tc.Name, sub, tc.Namespace)
+		}
+	}
+	return c.checkOutputsNamespace("target", tc.Name, tc.Namespace, tc.Outputs)
+}
+
+func (c *Config) checkNamespace(kind, name, ns string) error {
+	if ns == "" {
+		return nil
+	}
+	if _, ok := c.Namespaces[ns]; !ok {
+		return fmt.Errorf("%s %q: unknown namespace %q", kind, name, ns)
+	}
+	return nil
+}
+
+func (c *Config) checkOutputsNamespace(kind, name, ns string, outs []string) error {
+	for _, out := range outs {
+		outCfg, ok := c.Outputs[out]
+		if !ok {
+			continue
+		}
+		if OutputNamespace(outCfg) != ns {
+			return fmt.Errorf("%s %q: output %q does not belong to namespace %q", kind, name, out, ns)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+func TestGetNamespaces(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			in: `
+api-server:
+  tokens: [admin]
+namespaces:
+  team1:
+    tokens: [tk1, tk2]
+  team2:
+    tokens: [tk3]
+`,
+		},
+		{
+			name: "token_shared_by_namespaces",
+			in: `
+namespaces:
+  team1:
+    tokens: [tk1]
+  team2:
+    tokens: [tk1]
+`,
+			wantErr: true,
+		},
+		{
+			name: "admin_token_in_namespace",
+			in: `
+api-server:
+  tokens: [admin]
+namespaces:
+  team1:
+    tokens: [admin]
+`,
+			wantErr: true,
+		},
+		{
+			name: "clustering_without_admin_token",
+			in: `
+clustering:
+  cluster-name: c1
+  locker:
+    type: consul
+namespaces:
+  team1:
+    tokens: [tk1]
+`,
+			wantErr: true,
+		},
+		{
+			name: "empty_token",
+			in: `
+namespaces:
+  team1:
+    tokens: [""]
+`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := New()
+			cfg.FileConfig.SetConfigType("yaml")
+			if err := cfg.FileConfig.ReadConfig(bytes.NewBufferString(tt.in)); err != nil {
+				t.Fatal(err)
+			}
+			if err := cfg.GetClustering(); err != nil {
+				t.Fatal(err)
+			}
+			if err := cfg.GetAPIServer(); err != nil {
+				t.Fatal(err)
+			}
+			err := cfg.GetNamespaces()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err != nil {
+				return
+			}
+			if !cfg.APIAuthEnabled() {
+				t.Errorf("expected API auth to be enabled")
+			}
+			for tk, want := range map[string]string{"tk1": "team1", "tk2": "team1", "tk3": "team2"} {
+				ns, all, ok := cfg.TokenNamespace(tk)
+				if !ok || all || ns != want {
+					t.Errorf("token %q: got namespace %q, all=%v, ok=%v", tk, ns, all, ok)
+				}
+			}
+			if _, all, ok := cfg.TokenNamespace("admin"); !ok || !all {
+				t.Errorf("expected admin token to have access to all namespaces")
+			}
+			if _, _, ok := cfg.TokenNamespace("unknown"); ok {
+				t.Errorf("expected unknown token to be rejected")
+			}
+		})
+	}
+}
+
+func TestValidateNamespaces(t *testing.T) {
+	newConfig := func() *Config {
+		c := New()
+		c.Namespaces = map[string]*Namespace{"team1": {}, "team2": {}}
+		c.Subscriptions = map[string]*types.SubscriptionConfig{
+			"sub1": {Name: "sub1", Namespace: "team1"},
+			"sub2": {Name: "sub2", Namespace: "team2"},
+		}
+		c.Outputs = map[string]map[string]interface{}{
+			"out1": {"type": "file", "namespace": "team1"},
+			"out2": {"type": "file", "namespace": "team2"},
+		}
+		return c
+	}
+	tests := []struct {
+		name    string
+		tc      *types.TargetConfig
+		wantErr bool
+	}{
+		{
+			name: "same_namespace",
+			tc:   &types.TargetConfig{Name: "t1", Namespace: "team1", Subscriptions: []string{"sub1"}, Outputs: []string{"out1"}},
+		},
+		{
+			name:    "unknown_namespace",
+			tc:      &types.TargetConfig{Name: "t1", Namespace: "team3"},
+			wantErr: true,
+		},
+		{
+			name:    "subscription_of_another_namespace",
+			tc:      &types.TargetConfig{Name: "t1", Namespace: "team1", Subscriptions: []string{"sub2"}},
+			wantErr: true,
+		},
+		{
+			name:    "output_of_another_namespace",
+			tc:      &types.TargetConfig{Name: "t1", Namespace: "team1", Outputs: []string{"out2"}},
+			wantErr: true,
+		},
+		{
+			name:    "no_namespace",
+			tc:      &types.TargetConfig{Name: "t1", Subscriptions: []string{"sub1"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newConfig()
+			c.Targets = map[string]*types.TargetConfig{tt.tc.Name: tt.tc}
+			err := c.ValidateNamespaces()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+	c := newConfig()
+	c.Subscriptions["sub1"].Outputs = []string{"out2"}
+	if err := c.ValidateNamespaces(); err == nil {
+		t.Errorf("expected an error with a subscription referencing an output of another namespace")
+	}
+	c = newConfig()
+	c.Outputs["mirror1"] = map[string]interface{}{
+		"type":      "mirror",
+		"namespace": "team1",
+		"outputs":   []interface{}{map[string]interface{}{"name": "out2"}},
+	}
+	if err := c.ValidateNamespaces(); err == nil {
+		t.Errorf("expected an error with an output forwarding to an output of another namespace")
+	}
+	c.Outputs["mirror1"]["outputs"] = []interface{}{map[string]interface{}{"name": "out1"}}
+	if err := c.ValidateNamespaces(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -10,23 +10,33 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sort"
 
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
 	"github.com/openconfig/gnmic/pkg/outputs"
 	_ "github.com/openconfig/gnmic/pkg/outputs/all"
 )
 
+const defaultStdoutOutputName = "default-stdout"
+
 func (c *Config) GetOutputs() (map[string]map[string]interface{}, error) {
 	outDef := c.FileConfig.GetStringMap("outputs")
-	if len(outDef) == 0 && !c.FileConfig.GetBool("subscribe-quiet") {
+	streamTo := c.FileConfig.GetStringSlice("subscribe-stream-to")
+	if (len(outDef) == 0 || len(streamTo) > 0) && !c.FileConfig.GetBool("subscribe-quiet") {
 		stdoutConfig := map[string]interface{}{
 			"type":              "file",
 			"file-type":         "stdout",
 			"format":            c.FileConfig.GetString("format"),
 			"calculate-latency": c.FileConfig.GetBool("calculate-latency"),
 		}
-		outDef["default-stdout"] = stdoutConfig
+		if c.FileConfig.GetBool("subscribe-live") {
+			stdoutConfig["table-live"] = true
+		}
+		outDef[defaultStdoutOutputName] = stdoutConfig
 	}
 	for name, outputCfg := range outDef {
 		outputCfgconv := convert(outputCfg)
@@ -56,6 +66,13 @@ func (c *Config) GetOutputs() (map[string]map[string]interface{}, error) {
 		expandMapEnv(c.Outputs[n], "msg-template", "target-template")
 	}
 	namedOutputs := c.FileConfig.GetStringSlice("subscribe-output")
+	if len(streamTo) > 0 {
+		// the outputs referenced with --stream-to are used alongside stdout.
+		namedOutputs = append(namedOutputs, streamTo...)
+		if _, ok := c.Outputs[defaultStdoutOutputName]; ok {
+			namedOutputs = append(namedOutputs, defaultStdoutOutputName)
+		}
+	}
 	if len(namedOutputs) == 0 {
 		if c.Debug {
 			c.logger.Printf("outputs: %+v", c.Outputs)
@@ -146,3 +163,93 @@ func (c *Config) GetOutputsConfigs() [][]string {
 	}
 	return outList
 }
+
+// ValidateOutputConfig checks the configuration cfg of an output added at runtime
+// and sets its default format.
+// The environment variables are not expanded since the configuration is not read from the file.
+func (c *Config) ValidateOutputConfig(name string, cfg map[string]interface{}) error {
+	outType, _ := cfg["type"].(string)
+	if outType == "" {
+		return fmt.Errorf("output %q: missing output type", name)
+	}
+	if _, ok := outputs.Outputs[outType]; !ok {
+		return fmt.Errorf("output %q: unknown output type: %q", name, outType)
+	}
+	if format, _ := cfg["format"].(string); format == "" {
+		cfg["format"] = c.FileConfig.GetString("format")
+	}
+	if err := c.checkNamespace("output", name, OutputNamespace(cfg)); err != nil {
+		return err
+	}
+	if err := c.checkOutputsNamespace("output", name, OutputNamespace(cfg), outputs.Members(cfg)); err != nil {
+		return err
+	}
+	if p := OutputPolicy(cfg); p != "" {
+		if _, ok := c.Policies[p]; !ok {
+			return fmt.Errorf("output %q: unknown policy %q", name, p)
+		}
+	}
+	return nil
+}
+
+// GetOutputTargetSelector returns the target selector of the output configuration outCfg,
+// nil if it has none.
+func GetOutputTargetSelector(outCfg map[string]interface{}) (types.TargetSelector, error) {
+	switch sel := outCfg["target-selector"].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return types.ParseTargetSelector([]string{sel})
+	case []string:
+		return types.ParseTargetSelector(sel)
+	case []interface{}:
+		exprs := make([]string, 0, len(sel))
+		for _, e := range sel {
+			expr, ok := e.(string)
+			if !ok {
+				return nil, fmt.Errorf("target-selector: unexpected expression type %T", e)
+			}
+			exprs = append(exprs, expr)
+		}
+		return types.ParseTargetSelector(exprs)
+	default:
+		return nil, fmt.Errorf("target-selector: unexpected format %T", sel)
+	}
+}
+
+// ValidateOutputsTargetSelectors checks the target selector of the outputs.
+func (c *Config) ValidateOutputsTargetSelectors() error {
+	for name, outCfg := range c.Outputs {
+		if _, err := GetOutputTargetSelector(outCfg); err != nil {
+			return fmt.Errorf("output %q: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// GetOutputCPUQuota returns the cpu-quota of the output configuration outCfg:
+// the maximum number of concurrent writes to the output, 0 if unlimited.
+func GetOutputCPUQuota(outCfg map[string]interface{}) (int, error) {
+	v, ok := outCfg["cpu-quota"]
+	if !ok || v == nil {
+		return 0, nil
+	}
+	var quota int
+	if err := mapstructure.WeakDecode(v, &quota); err != nil {
+		return 0, fmt.Errorf("cpu-quota: %v", err)
+	}
+	if quota < 0 {
+		return 0, errors.New("cpu-quota cannot be negative")
+	}
+	return quota, nil
+}
+
+// ValidateOutputsCPUQuotas checks the cpu-quota of the outputs.
+func (c *Config) ValidateOutputsCPUQuotas() error {
+	for name, outCfg := range c.Outputs {
+		if _, err := GetOutputCPUQuota(outCfg); err != nil {
+			return fmt.Errorf("output %q: %v", name, err)
+		}
+	}
+	return nil
+}
@@ -68,6 +68,49 @@ outputs:
 			},
 		},
 	},
+	"stream_to": {
+		in: []byte(`
+subscribe-stream-to:
+  - output2
+outputs:
+  output1:
+    type: file
+    file-type: stdout
+  output2:
+    type: nats
+`),
+		out: map[string]map[string]interface{}{
+			"default-stdout": {
+				"type":              "file",
+				"file-type":         "stdout",
+				"format":            "",
+				"calculate-latency": false,
+			},
+			"output2": {
+				"type":   "nats",
+				"format": "",
+			},
+		},
+	},
+	"stream_to_quiet": {
+		in: []byte(`
+subscribe-quiet: true
+subscribe-stream-to:
+  - output2
+outputs:
+  output1:
+    type: file
+    file-type: stdout
+  output2:
+    type: nats
+`),
+		out: map[string]map[string]interface{}{
+			"output2": {
+				"type":   "nats",
+				"format": "",
+			},
+		},
+	},
 }
 
 func TestGetOutputs(t *testing.T) {
@@ -102,3 +145,30 @@ func TestGetOutputs(t *testing.T) {
 		})
 	}
 }
+
+func TestGetOutputCPUQuota(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      map[string]interface{}
+		want    int
+		wantErr bool
+	}{
+		{name: "not_set", in: map[string]interface{}{"type": "file"}},
+		{name: "int", in: map[string]interface{}{"type": "file", "cpu-quota": 2}, want: 2},
+		{name: "float", in: map[string]interface{}{"type": "file", "cpu-quota": float64(4)}, want: 4},
+		{name: "string", in: map[string]interface{}{"type": "file", "cpu-quota": "1"}, want: 1},
+		{name: "negative", in: map[string]interface{}{"type": "file", "cpu-quota": -1}, wantErr: true},
+		{name: "invalid", in: map[string]interface{}{"type": "file", "cpu-quota": "two"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetOutputCPUQuota(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
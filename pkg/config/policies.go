@@ -0,0 +1,124 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+const (
+	PolicyAggregationFirst = "first"
+	PolicyAggregationLast  = "last"
+	PolicyAggregationAvg   = "avg"
+	PolicyAggregationMin   = "min"
+	PolicyAggregationMax   = "max"
+)
+
+// Policy down-samples, aggregates or truncates the data written to an output.
+// It applies to the outputs referencing it and to the outputs of the namespaces referencing it.
+type Policy struct {
+	// per path, minimum interval between two values written to the output
+	SampleInterval time.Duration `mapstructure:"sample-interval,omitempty" json:"sample-interval,omitempty"`
+	// value written for each sample interval: first, last, avg, min or max
+	Aggregation string `mapstructure:"aggregation,omitempty" json:"aggregation,omitempty"`
+	// values older than max-age are dropped
+	MaxAge time.Duration `mapstructure:"max-age,omitempty" json:"max-age,omitempty"`
+	// maximum number of updates per notification, or values per event
+	MaxUpdates int `mapstructure:"max-updates,omitempty" json:"max-updates,omitempty"`
+}
+
+// GetPolicies reads and validates the data policies.
+func (c *Config) GetPolicies() error {
+	if !c.FileConfig.IsSet("policies") {
+		return nil
+	}
+	pols := c.FileConfig.GetStringMap("policies")
+	c.Policies = make(map[string]*Policy, len(pols))
+	for name, polCfg := range pols {
+		p := new(Policy)
+		decoder, err := mapstructure.NewDecoder(
+			&mapstructure.DecoderConfig{
+				DecodeHook: mapstructure.StringToTimeDurationHookFunc(),
+				Result:     p,
+			})
+		if err != nil {
+			return err
+		}
+		if err = decoder.Decode(polCfg); err != nil {
+			return fmt.Errorf("policy %q: %v", name, err)
+		}
+		if err = p.validate(); err != nil {
+			return fmt.Errorf("policy %q: %v", name, err)
+		}
+		c.Policies[name] = p
+	}
+	return nil
+}
+
+func (p *Policy) validate() error {
+	if p.SampleInterval < 0 || p.MaxAge < 0 || p.MaxUpdates < 0 {
+		return fmt.Errorf("sample-interval, max-age and max-updates cannot be negative")
+	}
+	switch p.Aggregation {
+	case "":
+		p.Aggregation = PolicyAggregationFirst
+	case PolicyAggregationFirst, PolicyAggregationLast,
+		PolicyAggregationAvg, PolicyAggregationMin, PolicyAggregationMax:
+	default:
+		return fmt.Errorf("unknown aggregation %q", p.Aggregation)
+	}
+	if p.Aggregation != PolicyAggregationFirst && p.SampleInterval == 0 {
+		return fmt.Errorf("aggregation %q requires a sample-interval", p.Aggregation)
+	}
+	return nil
+}
+
+// OutputPolicy returns the name of the policy of the output configuration outCfg.
+func OutputPolicy(outCfg map[string]interface{}) string {
+	p, _ := outCfg["policy"].(string)
+	return p
+}
+
+// DataPolicy returns the policy applied to the data of namespace ns written to the output.
+// The output policy takes precedence over the namespace policy.
+// It returns nil if no policy applies.
+func (c *Config) DataPolicy(output, ns string) *Policy {
+	name := OutputPolicy(c.Outputs[output])
+	if name == "" {
+		if n, ok := c.Namespaces[ns]; ok {
+			name = n.Policy
+		}
+	}
+	if name == "" {
+		return nil
+	}
+	return c.Policies[name]
+}
+
+// ValidatePolicies checks that the policies referenced by the outputs and the namespaces are defined.
+func (c *Config) ValidatePolicies() error {
+	for name, outCfg := range c.Outputs {
+		if p := OutputPolicy(outCfg); p != "" {
+			if _, ok := c.Policies[p]; !ok {
+				return fmt.Errorf("output %q: unknown policy %q", name, p)
+			}
+		}
+	}
+	for name, ns := range c.Namespaces {
+		if ns.Policy != "" {
+			if _, ok := c.Policies[ns.Policy]; !ok {
+				return fmt.Errorf("namespace %q: unknown policy %q", name, ns.Policy)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestGetPolicies(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    *Policy
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			in: `
+policies:
+  p1:
+    sample-interval: 1m
+`,
+			want: &Policy{SampleInterval: time.Minute, Aggregation: PolicyAggregationFirst},
+		},
+		{
+			name: "aggregation",
+			in: `
+policies:
+  p1:
+    sample-interval: 30s
+    aggregation: avg
+    max-age: 5m
+    max-updates: 10
+`,
+			want: &Policy{SampleInterval: 30 * time.Second, Aggregation: PolicyAggregationAvg, MaxAge: 5 * time.Minute, MaxUpdates: 10},
+		},
+		{
+			name: "unknown_aggregation",
+			in: `
+policies:
+  p1:
+    sample-interval: 1m
+    aggregation: median
+`,
+			wantErr: true,
+		},
+		{
+			name: "aggregation_without_interval",
+			in: `
+policies:
+  p1:
+    aggregation: max
+`,
+			wantErr: true,
+		},
+		{
+			name: "negative_max_updates",
+			in: `
+policies:
+  p1:
+    max-updates: -1
+`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := New()
+			cfg.FileConfig.SetConfigType("yaml")
+			if err := cfg.FileConfig.ReadConfig(bytes.NewBufferString(tt.in)); err != nil {
+				t.Fatal(err)
+			}
+			err := cfg.GetPolicies()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err != nil {
+				return
+			}
+			if got := cfg.Policies["p1"]; *got != *tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDataPolicy(t *testing.T) {
+	c := New()
+	c.Policies = map[string]*Policy{"p1": {}, "p2": {}}
+	c.Namespaces = map[string]*Namespace{"team1": {Policy: "p1"}, "team2": {}}
+	c.Outputs = map[string]map[string]interface{}{
+		"out1": {"type": "file"},
+		"out2": {"type": "file", "policy": "p2"},
+	}
+	if err := c.ValidatePolicies(); err != nil {
+		t.Fatal(err)
+	}
+	if p := c.DataPolicy("out1", "team1"); p != c.Policies["p1"] {
+		t.Errorf("expected the namespace policy, got %+v", p)
+	}
+	if p := c.DataPolicy("out2", "team1"); p != c.Policies["p2"] {
+		t.Errorf("expected the output policy, got %+v", p)
+	}
+	if p := c.DataPolicy("out1", "team2"); p != nil {
+		t.Errorf("expected no policy, got %+v", p)
+	}
+	c.Outputs["out3"] = map[string]interface{}{"type": "file", "policy": "p3"}
+	if err := c.ValidatePolicies(); err == nil {
+		t.Errorf("expected an error with an unknown output policy")
+	}
+}
@@ -39,7 +39,7 @@ func (c *Config) GetEventProcessors() (map[string]map[string]interface{}, error)
 		c.Processors[n] = es
 	}
 	for n := range c.Processors {
-		expandMapEnv(c.Processors[n], "expression", "condition")
+		expandMapEnv(c.Processors[n], "expression", "condition", "when")
 	}
 	if c.Debug {
 		c.logger.Printf("processors: %+v", c.Processors)
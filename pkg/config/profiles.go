@@ -0,0 +1,142 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+// TargetProfile holds the defaults and the quirks handling of a network OS,
+// selected per target with the `profile` field.
+type TargetProfile struct {
+	Name string `json:"name,omitempty"`
+	// encoding used by the target if it does not set one.
+	Encoding string `json:"encoding,omitempty"`
+	// encodings retried if the target rejects the encoding.
+	EncodingFallback []string `json:"encoding-fallback,omitempty"`
+	// origin set on the requests paths without one.
+	Origin string `json:"origin,omitempty"`
+	// removes the origin from the requests paths.
+	StripOrigin bool `json:"strip-origin,omitempty"`
+	// prefix target set on the requests without one.
+	Target string `json:"target,omitempty"`
+	// moves the keys embedded in the received path elements names to the elements keys,
+	// e.g: "interface[name='ge-0/0/0']", and removes the keys values quotes.
+	NormalizeKeys bool `json:"normalize-keys,omitempty"`
+}
+
+var targetProfiles = map[string]*TargetProfile{
+	"sonic": {
+		Name:             "sonic",
+		Encoding:         "json_ietf",
+		EncodingFallback: []string{"json"},
+		StripOrigin:      true,
+		// the SONiC telemetry server selects the data source with the prefix target.
+		Target: "OC-YANG",
+	},
+	"sros": {
+		Name:             "sros",
+		Encoding:         "json_ietf",
+		EncodingFallback: []string{"json"},
+	},
+	"eos": {
+		Name:             "eos",
+		Encoding:         "json",
+		EncodingFallback: []string{"json_ietf"},
+	},
+	"junos": {
+		Name:             "junos",
+		Encoding:         "proto",
+		EncodingFallback: []string{"json"},
+		StripOrigin:      true,
+		NormalizeKeys:    true,
+	},
+	"iosxr": {
+		Name:             "iosxr",
+		Encoding:         "json_ietf",
+		EncodingFallback: []string{"proto"},
+	},
+}
+
+// GetTargetProfile returns the built-in profile with the given name.
+func GetTargetProfile(name string) (*TargetProfile, bool) {
+	p, ok := targetProfiles[name]
+	return p, ok
+}
+
+// TargetProfiles returns the names of the built-in profiles, sorted.
+func TargetProfiles() []string {
+	names := make([]string, 0, len(targetProfiles))
+	for n := range targetProfiles {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// targetProfile returns the profile of target tc, nil if it does not set one.
+func targetProfile(tc *types.TargetConfig) *TargetProfile {
+	if tc == nil || tc.Profile == "" {
+		return nil
+	}
+	return targetProfiles[tc.Profile]
+}
+
+// setTargetProfileDefaults sets the target tc encoding and encoding fallback from its profile,
+// unless set in the target configuration.
+func setTargetProfileDefaults(tc *types.TargetConfig) error {
+	if tc.Profile == "" {
+		return nil
+	}
+	p, ok := targetProfiles[tc.Profile]
+	if !ok {
+		return fmt.Errorf("target %q: unknown profile %q, must be one of %v", tc.Name, tc.Profile, TargetProfiles())
+	}
+	if tc.Encoding == nil && p.Encoding != "" {
+		enc := p.Encoding
+		tc.Encoding = &enc
+	}
+	if tc.EncodingFallback == nil && len(p.EncodingFallback) > 0 {
+		tc.EncodingFallback = append(make([]string, 0, len(p.EncodingFallback)), p.EncodingFallback...)
+	}
+	return nil
+}
+
+// applyPaths applies the profile origin and target quirks to a request prefix and paths.
+// It returns the prefix, allocated if the profile sets a target and prefix is nil.
+func (p *TargetProfile) applyPaths(prefix *gnmi.Path, paths ...*gnmi.Path) *gnmi.Path {
+	if p == nil {
+		return prefix
+	}
+	if p.Target != "" {
+		if prefix == nil {
+			prefix = new(gnmi.Path)
+		}
+		if prefix.Target == "" {
+			prefix.Target = p.Target
+		}
+	}
+	for _, pt := range append(paths, prefix) {
+		if pt == nil {
+			continue
+		}
+		switch {
+		case p.StripOrigin:
+			pt.Origin = ""
+		case p.Origin != "" && pt.Origin == "":
+			pt.Origin = p.Origin
+		}
+	}
+	return prefix
+}
@@ -0,0 +1,125 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/AlekSi/pointer"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+func TestTargetProfileDefaults(t *testing.T) {
+	in := `
+targets:
+  sonic1:
+    address: 10.0.0.1
+    profile: sonic
+  sonic2:
+    address: 10.0.0.2
+    profile: sonic
+    encoding: proto
+    encoding-fallback: []
+`
+	cfg := New()
+	cfg.FileConfig.SetConfigType("yaml")
+	if err := cfg.FileConfig.ReadConfig(bytes.NewBufferString(in)); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.FileConfig.Unmarshal(cfg); err != nil {
+		t.Fatal(err)
+	}
+	tcs, err := cfg.GetTargets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enc := tcs["sonic1"].Encoding; enc == nil || *enc != "json_ietf" {
+		t.Errorf("sonic1: expected the profile encoding, got %v", enc)
+	}
+	if fb := tcs["sonic1"].EncodingFallback; len(fb) != 1 || fb[0] != "json" {
+		t.Errorf("sonic1: expected the profile encoding-fallback, got %v", fb)
+	}
+	if enc := tcs["sonic2"].Encoding; enc == nil || *enc != "proto" {
+		t.Errorf("sonic2: expected the target encoding, got %v", enc)
+	}
+	if fb := tcs["sonic2"].EncodingFallback; len(fb) != 0 {
+		t.Errorf("sonic2: expected the target encoding-fallback, got %v", fb)
+	}
+}
+
+func TestTargetProfileUnknown(t *testing.T) {
+	c := New()
+	err := c.SetTargetConfigDefaults(&types.TargetConfig{Name: "t1", Address: "10.0.0.1:57400", Profile: "foo"})
+	if err == nil {
+		t.Errorf("expected an unknown profile error")
+	}
+}
+
+func TestTargetProfileSubscribeRequest(t *testing.T) {
+	sc := &types.SubscriptionConfig{
+		Name:     "sub1",
+		Paths:    []string{"openconfig:/interfaces/interface"},
+		Encoding: pointer.ToString("json_ietf"),
+	}
+	tests := []struct {
+		name       string
+		tc         *types.TargetConfig
+		sc         *types.SubscriptionConfig
+		wantTarget string
+		wantOrigin string
+	}{
+		{
+			name:       "no_profile",
+			tc:         &types.TargetConfig{Name: "t1"},
+			sc:         sc,
+			wantOrigin: "openconfig",
+		},
+		{
+			name:       "sonic",
+			tc:         &types.TargetConfig{Name: "t1", Profile: "sonic"},
+			sc:         sc,
+			wantTarget: "OC-YANG",
+		},
+		{
+			name: "sonic_subscription_target",
+			tc:   &types.TargetConfig{Name: "t1", Profile: "sonic"},
+			sc: &types.SubscriptionConfig{
+				Name:     "sub2",
+				Paths:    []string{"COUNTERS/Ethernet*"},
+				Target:   "COUNTERS_DB",
+				Encoding: pointer.ToString("json_ietf"),
+			},
+			wantTarget: "COUNTERS_DB",
+		},
+		{
+			name:       "sros",
+			tc:         &types.TargetConfig{Name: "t1", Profile: "sros"},
+			sc:         sc,
+			wantOrigin: "openconfig",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+			req, err := c.CreateSubscribeRequest(tt.sc, tt.tc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			subList := req.GetSubscribe()
+			if got := subList.GetPrefix().GetTarget(); got != tt.wantTarget {
+				t.Errorf("got prefix target %q, want %q", got, tt.wantTarget)
+			}
+			if got := subList.GetSubscription()[0].GetPath().GetOrigin(); got != tt.wantOrigin {
+				t.Errorf("got path origin %q, want %q", got, tt.wantOrigin)
+			}
+		})
+	}
+}
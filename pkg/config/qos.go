@@ -0,0 +1,102 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// QoS classifies the received notifications in priority classes,
+// the high priority ones are processed ahead of the bulk ones under backpressure.
+type QoS struct {
+	// rules classifying a notification as high priority,
+	// the notifications not matching any rule are bulk.
+	HighPriority []*QoSRule `mapstructure:"high-priority,omitempty" json:"high-priority,omitempty"`
+}
+
+// QoSRule matches the notifications of the listed subscriptions
+// with an update or a delete path matching one of the paths regular expressions.
+// An empty list matches all the subscriptions or paths.
+type QoSRule struct {
+	Subscriptions []string `mapstructure:"subscriptions,omitempty" json:"subscriptions,omitempty"`
+	Paths         []string `mapstructure:"paths,omitempty" json:"paths,omitempty"`
+
+	paths []*regexp.Regexp
+}
+
+// GetQoS reads and validates the qos section.
+func (c *Config) GetQoS() error {
+	if !c.FileConfig.IsSet("qos") {
+		return nil
+	}
+	q := new(QoS)
+	err := mapstructure.Decode(c.FileConfig.Get("qos"), q)
+	if err != nil {
+		return fmt.Errorf("qos: %v", err)
+	}
+	for i, r := range q.HighPriority {
+		if r == nil || len(r.Subscriptions) == 0 && len(r.Paths) == 0 {
+			return fmt.Errorf("qos: high-priority rule %d: missing subscriptions or paths", i)
+		}
+		for _, p := range r.Paths {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return fmt.Errorf("qos: high-priority rule %d: %v", i, err)
+			}
+			r.paths = append(r.paths, re)
+		}
+	}
+	c.QoS = q
+	return nil
+}
+
+// ValidateQoS checks that the subscriptions referenced by the qos rules are defined.
+func (c *Config) ValidateQoS() error {
+	if c.QoS == nil {
+		return nil
+	}
+	for i, r := range c.QoS.HighPriority {
+		for _, s := range r.Subscriptions {
+			if _, ok := c.Subscriptions[s]; !ok {
+				return fmt.Errorf("qos: high-priority rule %d: unknown subscription %q", i, s)
+			}
+		}
+	}
+	return nil
+}
+
+// MatchesSubscription returns true if the rule applies to the subscription sub.
+func (r *QoSRule) MatchesSubscription(sub string) bool {
+	if len(r.Subscriptions) == 0 {
+		return true
+	}
+	for _, s := range r.Subscriptions {
+		if s == sub {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesPath returns true if the path p matches one of the rule paths,
+// or if the rule has no paths.
+func (r *QoSRule) MatchesPath(p string) bool {
+	if len(r.paths) == 0 {
+		return true
+	}
+	for _, re := range r.paths {
+		if re.MatchString(p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+func TestGetQoS(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		wantErr bool
+	}{
+		{
+			name: "not_set",
+			in:   "targets: {}\n",
+		},
+		{
+			name: "rules",
+			in: `
+qos:
+  high-priority:
+    - paths:
+        - /session-state$
+    - subscriptions: [alarms]
+`,
+		},
+		{
+			name: "empty_rule",
+			in: `
+qos:
+  high-priority:
+    - {}
+`,
+			wantErr: true,
+		},
+		{
+			name: "invalid_regex",
+			in: `
+qos:
+  high-priority:
+    - paths: ["/state[("]
+`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := New()
+			cfg.FileConfig.SetConfigType("yaml")
+			if err := cfg.FileConfig.ReadConfig(bytes.NewBufferString(tt.in)); err != nil {
+				t.Fatal(err)
+			}
+			err := cfg.GetQoS()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestQoSRuleMatches(t *testing.T) {
+	cfg := New()
+	cfg.FileConfig.SetConfigType("yaml")
+	in := `
+qos:
+  high-priority:
+    - subscriptions: [bgp]
+      paths:
+        - /session-state$
+`
+	if err := cfg.FileConfig.ReadConfig(bytes.NewBufferString(in)); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.GetQoS(); err != nil {
+		t.Fatal(err)
+	}
+	r := cfg.QoS.HighPriority[0]
+	if !r.MatchesSubscription("bgp") || r.MatchesSubscription("counters") {
+		t.Errorf("unexpected subscription match")
+	}
+	if !r.MatchesPath("/network-instance[name=default]/protocols/bgp/neighbors/neighbor[neighbor-address=10.0.0.1]/state/session-state") {
+		t.Errorf("expected the session-state path to match")
+	}
+	if r.MatchesPath("/interfaces/interface[name=1/1/1]/state/counters/in-octets") {
+		t.Errorf("expected the counters path not to match")
+	}
+
+	if err := cfg.ValidateQoS(); err == nil {
+		t.Errorf("expected an unknown subscription error")
+	}
+	cfg.Subscriptions = map[string]*types.SubscriptionConfig{"bgp": {Name: "bgp"}}
+	if err := cfg.ValidateQoS(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+const (
+	SessionProtocolBGP  = "bgp"
+	SessionProtocolISIS = "isis"
+)
+
+var defaultSessionProtocols = []string{SessionProtocolBGP, SessionProtocolISIS}
+
+// SessionTracking builds a table of the targets protocol sessions
+// from the received session state paths.
+type SessionTracking struct {
+	// protocols tracked: bgp and/or isis
+	Protocols []string `mapstructure:"protocols,omitempty" json:"protocols,omitempty"`
+	// outputs the sessions up/down events are written to
+	Outputs []string `mapstructure:"outputs,omitempty" json:"outputs,omitempty"`
+}
+
+// GetSessionTracking reads and validates the session-tracking section.
+func (c *Config) GetSessionTracking() error {
+	if !c.FileConfig.IsSet("session-tracking") {
+		return nil
+	}
+	st := new(SessionTracking)
+	err := mapstructure.Decode(c.FileConfig.Get("session-tracking"), st)
+	if err != nil {
+		return fmt.Errorf("session-tracking: %v", err)
+	}
+	if len(st.Protocols) == 0 {
+		st.Protocols = slices.Clone(defaultSessionProtocols)
+	}
+	for _, p := range st.Protocols {
+		if !slices.Contains(defaultSessionProtocols, p) {
+			return fmt.Errorf("session-tracking: unknown protocol %q", p)
+		}
+	}
+	c.SessionTracking = st
+	return nil
+}
+
+// Tracks returns true if the sessions of protocol are tracked.
+func (st *SessionTracking) Tracks(protocol string) bool {
+	return st != nil && slices.Contains(st.Protocols, protocol)
+}
+
+// ValidateSessionTracking checks that the outputs referenced by the session tracking are defined.
+func (c *Config) ValidateSessionTracking() error {
+	if c.SessionTracking == nil {
+		return nil
+	}
+	for _, o := range c.SessionTracking.Outputs {
+		if _, ok := c.Outputs[o]; !ok {
+			return fmt.Errorf("session-tracking: unknown output %q", o)
+		}
+	}
+	return nil
+}
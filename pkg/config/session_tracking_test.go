@@ -0,0 +1,72 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestGetSessionTracking(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    *SessionTracking
+		wantErr bool
+	}{
+		{
+			name: "not_set",
+			in:   "targets: {}\n",
+		},
+		{
+			name: "defaults",
+			in: `
+session-tracking:
+  outputs: [out1]
+`,
+			want: &SessionTracking{Protocols: []string{"bgp", "isis"}, Outputs: []string{"out1"}},
+		},
+		{
+			name: "bgp_only",
+			in: `
+session-tracking:
+  protocols: [bgp]
+`,
+			want: &SessionTracking{Protocols: []string{"bgp"}},
+		},
+		{
+			name: "unknown_protocol",
+			in: `
+session-tracking:
+  protocols: [ospf]
+`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := New()
+			cfg.FileConfig.SetConfigType("yaml")
+			if err := cfg.FileConfig.ReadConfig(bytes.NewBufferString(tt.in)); err != nil {
+				t.Fatal(err)
+			}
+			err := cfg.GetSessionTracking()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(cfg.SessionTracking, tt.want) {
+				t.Errorf("got %+v, want %+v", cfg.SessionTracking, tt.want)
+			}
+		})
+	}
+}
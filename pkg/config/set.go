@@ -241,24 +241,24 @@ func (c *Config) CreateSetRequestFromFile(targetName string) ([]*gnmi.SetRequest
 			case commitActionRequest:
 				gnmiOpts = append(gnmiOpts,
 					api.Extension_CommitRequest(
-						c.LocalFlags.SetCommitId,
-						c.LocalFlags.SetCommitRollbackDuration,
+						reqFile.CommitID,
+						reqFile.RollbackDuration,
 					))
 			case commitActionCancel:
 				gnmiOpts = append(gnmiOpts,
 					api.Extension_CommitCancel(
-						c.LocalFlags.SetCommitId,
+						reqFile.CommitID,
 					))
 			case commitActionConfirm:
 				gnmiOpts = append(gnmiOpts,
 					api.Extension_CommitConfirm(
-						c.LocalFlags.SetCommitId,
+						reqFile.CommitID,
 					))
 			case commitActionSetRollbackDuration:
 				gnmiOpts = append(gnmiOpts,
 					api.Extension_CommitSetRollbackDuration(
-						c.LocalFlags.SetCommitId,
-						c.LocalFlags.SetCommitRollbackDuration,
+						reqFile.CommitID,
+						reqFile.RollbackDuration,
 					))
 			default:
 				return nil, fmt.Errorf("unknown commit action %s", reqFile.CommitAction)
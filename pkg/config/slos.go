@@ -0,0 +1,125 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+)
+
+const (
+	defaultSLOWindow            = 30 * 24 * time.Hour
+	defaultSLOBurnRateThreshold = 1
+)
+
+var defaultSLOBurnRateWindows = []time.Duration{time.Hour, 6 * time.Hour}
+
+// SLO is a service level objective evaluated over the received values.
+// Each value selected by ValueNames is a sample, it is good if Condition is true.
+type SLO struct {
+	// regular expressions selecting the event values evaluated
+	ValueNames []string `mapstructure:"value-names,omitempty" json:"value-names,omitempty"`
+	// jq condition, true for good samples
+	Condition string `mapstructure:"condition,omitempty" json:"condition,omitempty"`
+	// percentage of good samples over the window
+	Objective float64 `mapstructure:"objective,omitempty" json:"objective,omitempty"`
+	// compliance period
+	Window time.Duration `mapstructure:"window,omitempty" json:"window,omitempty"`
+	// windows the error budget burn rate is computed over
+	BurnRateWindows []time.Duration `mapstructure:"burn-rate-windows,omitempty" json:"burn-rate-windows,omitempty"`
+	// burn rate above which an event is written to the outputs
+	BurnRateThreshold float64 `mapstructure:"burn-rate-threshold,omitempty" json:"burn-rate-threshold,omitempty"`
+	// outputs the SLO events are written to
+	Outputs []string `mapstructure:"outputs,omitempty" json:"outputs,omitempty"`
+}
+
+// GetSLOs reads and validates the service level objectives.
+func (c *Config) GetSLOs() error {
+	if !c.FileConfig.IsSet("slos") {
+		return nil
+	}
+	slos := c.FileConfig.GetStringMap("slos")
+	c.SLOs = make(map[string]*SLO, len(slos))
+	for name, sloCfg := range slos {
+		s := new(SLO)
+		decoder, err := mapstructure.NewDecoder(
+			&mapstructure.DecoderConfig{
+				DecodeHook: mapstructure.StringToTimeDurationHookFunc(),
+				Result:     s,
+			})
+		if err != nil {
+			return err
+		}
+		if err = decoder.Decode(sloCfg); err != nil {
+			return fmt.Errorf("slo %q: %v", name, err)
+		}
+		if err = s.validate(); err != nil {
+			return fmt.Errorf("slo %q: %v", name, err)
+		}
+		c.SLOs[name] = s
+	}
+	return nil
+}
+
+func (s *SLO) validate() error {
+	if len(s.ValueNames) == 0 {
+		return errors.New("missing value-names")
+	}
+	for _, vn := range s.ValueNames {
+		if _, err := regexp.Compile(vn); err != nil {
+			return fmt.Errorf("invalid value-names regex %q: %v", vn, err)
+		}
+	}
+	if s.Condition == "" {
+		return errors.New("missing condition")
+	}
+	if _, err := formatters.CompileJQ(s.Condition); err != nil {
+		return fmt.Errorf("invalid condition: %v", err)
+	}
+	if s.Objective <= 0 || s.Objective >= 100 {
+		return fmt.Errorf("objective must be a percentage between 0 and 100 excluded, got %v", s.Objective)
+	}
+	if s.Window < 0 || s.BurnRateThreshold < 0 {
+		return errors.New("window and burn-rate-threshold cannot be negative")
+	}
+	if s.Window == 0 {
+		s.Window = defaultSLOWindow
+	}
+	if s.BurnRateThreshold == 0 {
+		s.BurnRateThreshold = defaultSLOBurnRateThreshold
+	}
+	if len(s.BurnRateWindows) == 0 {
+		s.BurnRateWindows = slices.Clone(defaultSLOBurnRateWindows)
+	}
+	for _, w := range s.BurnRateWindows {
+		if w <= 0 || w > s.Window {
+			return fmt.Errorf("burn-rate-windows must be positive and not longer than the window %s, got %s", s.Window, w)
+		}
+	}
+	return nil
+}
+
+// ValidateSLOs checks that the outputs referenced by the SLOs are defined.
+func (c *Config) ValidateSLOs() error {
+	for name, s := range c.SLOs {
+		for _, o := range s.Outputs {
+			if _, ok := c.Outputs[o]; !ok {
+				return fmt.Errorf("slo %q: unknown output %q", name, o)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGetSLOs(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    *SLO
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			in: `
+slos:
+  s1:
+    value-names: ["in-errors$"]
+    condition: '.values[] == 0'
+    objective: 99
+`,
+			want: &SLO{
+				ValueNames:        []string{"in-errors$"},
+				Condition:         ".values[] == 0",
+				Objective:         99,
+				Window:            30 * 24 * time.Hour,
+				BurnRateWindows:   []time.Duration{time.Hour, 6 * time.Hour},
+				BurnRateThreshold: 1,
+			},
+		},
+		{
+			name: "windows",
+			in: `
+slos:
+  s1:
+    value-names: ["oper-status$"]
+    condition: '.values[] == "UP"'
+    objective: 99.9
+    window: 168h
+    burn-rate-windows: [5m, 1h]
+    burn-rate-threshold: 14.4
+    outputs: [out1]
+`,
+			want: &SLO{
+				ValueNames:        []string{"oper-status$"},
+				Condition:         `.values[] == "UP"`,
+				Objective:         99.9,
+				Window:            168 * time.Hour,
+				BurnRateWindows:   []time.Duration{5 * time.Minute, time.Hour},
+				BurnRateThreshold: 14.4,
+				Outputs:           []string{"out1"},
+			},
+		},
+		{
+			name: "missing_condition",
+			in: `
+slos:
+  s1:
+    value-names: ["in-errors$"]
+    objective: 99
+`,
+			wantErr: true,
+		},
+		{
+			name: "invalid_condition",
+			in: `
+slos:
+  s1:
+    value-names: ["in-errors$"]
+    condition: '.values[] =='
+    objective: 99
+`,
+			wantErr: true,
+		},
+		{
+			name: "objective_out_of_range",
+			in: `
+slos:
+  s1:
+    value-names: ["in-errors$"]
+    condition: '.values[] == 0'
+    objective: 100
+`,
+			wantErr: true,
+		},
+		{
+			name: "burn_rate_window_longer_than_window",
+			in: `
+slos:
+  s1:
+    value-names: ["in-errors$"]
+    condition: '.values[] == 0'
+    objective: 99
+    window: 1h
+    burn-rate-windows: [6h]
+`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := New()
+			cfg.FileConfig.SetConfigType("yaml")
+			if err := cfg.FileConfig.ReadConfig(bytes.NewBufferString(tt.in)); err != nil {
+				t.Fatal(err)
+			}
+			err := cfg.GetSLOs()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err != nil {
+				return
+			}
+			if got := cfg.SLOs["s1"]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSLOs(t *testing.T) {
+	c := New()
+	c.Outputs = map[string]map[string]interface{}{"out1": {"type": "file"}}
+	c.SLOs = map[string]*SLO{"s1": {Outputs: []string{"out1"}}}
+	if err := c.ValidateSLOs(); err != nil {
+		t.Fatal(err)
+	}
+	c.SLOs["s2"] = &SLO{Outputs: []string{"out2"}}
+	if err := c.ValidateSLOs(); err == nil {
+		t.Errorf("expected an error with an unknown SLO output")
+	}
+}
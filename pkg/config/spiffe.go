@@ -0,0 +1,111 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+)
+
+const (
+	defaultSpiffeTimeout = 30 * time.Second
+)
+
+type spiffeConfig struct {
+	// SPIFFE Workload API socket address, e.g: unix:///run/spire/sockets/agent.sock
+	// if empty, the value of env var SPIFFE_ENDPOINT_SOCKET is used.
+	SocketPath string `mapstructure:"socket-path,omitempty" json:"socket-path,omitempty"`
+	// trust domain used to authorize peers when no explicit
+	// SPIFFE IDs are configured.
+	TrustDomain string `mapstructure:"trust-domain,omitempty" json:"trust-domain,omitempty"`
+	// max time to wait for the first X509-SVID to be received
+	// from the Workload API.
+	Timeout time.Duration `mapstructure:"timeout,omitempty" json:"timeout,omitempty"`
+	// SVID usage per component, a nil value means the component does not use
+	// the Workload API SVIDs.
+	APIServer  *SpiffePeers `mapstructure:"api-server,omitempty" json:"api-server,omitempty"`
+	GNMIServer *SpiffePeers `mapstructure:"gnmi-server,omitempty" json:"gnmi-server,omitempty"`
+	Targets    *SpiffePeers `mapstructure:"targets,omitempty" json:"targets,omitempty"`
+}
+
+// SpiffePeers defines the SPIFFE IDs a component
+// authorizes on the other end of a TLS connection.
+type SpiffePeers struct {
+	// list of authorized SPIFFE IDs, if empty any SPIFFE ID
+	// member of the configured trust domain is authorized.
+	AuthorizedIDs []string `mapstructure:"authorized-ids,omitempty" json:"authorized-ids,omitempty"`
+}
+
+func (c *Config) GetSpiffe() error {
+	if !c.FileConfig.IsSet("spiffe") {
+		return nil
+	}
+	c.Spiffe = new(spiffeConfig)
+	c.Spiffe.SocketPath = os.ExpandEnv(c.FileConfig.GetString("spiffe/socket-path"))
+	c.Spiffe.TrustDomain = os.ExpandEnv(c.FileConfig.GetString("spiffe/trust-domain"))
+	c.Spiffe.Timeout = c.FileConfig.GetDuration("spiffe/timeout")
+
+	var err error
+	c.Spiffe.APIServer, err = c.getSpiffePeers("api-server")
+	if err != nil {
+		return err
+	}
+	c.Spiffe.GNMIServer, err = c.getSpiffePeers("gnmi-server")
+	if err != nil {
+		return err
+	}
+	c.Spiffe.Targets, err = c.getSpiffePeers("targets")
+	if err != nil {
+		return err
+	}
+	if c.Spiffe.TrustDomain != "" {
+		if _, err = spiffeid.TrustDomainFromString(c.Spiffe.TrustDomain); err != nil {
+			return fmt.Errorf("spiffe: invalid trust-domain %q: %w", c.Spiffe.TrustDomain, err)
+		}
+	}
+	if c.Spiffe.Timeout <= 0 {
+		c.Spiffe.Timeout = defaultSpiffeTimeout
+	}
+	return nil
+}
+
+func (c *Config) getSpiffePeers(component string) (*SpiffePeers, error) {
+	key := fmt.Sprintf("spiffe/%s", component)
+	if !c.FileConfig.IsSet(key) {
+		return nil, nil
+	}
+	sp := new(SpiffePeers)
+	sp.AuthorizedIDs = c.FileConfig.GetStringSlice(key + "/authorized-ids")
+	for i := range sp.AuthorizedIDs {
+		sp.AuthorizedIDs[i] = os.ExpandEnv(sp.AuthorizedIDs[i])
+		if _, err := spiffeid.FromString(sp.AuthorizedIDs[i]); err != nil {
+			return nil, fmt.Errorf("spiffe: %s: invalid authorized ID %q: %w", component, sp.AuthorizedIDs[i], err)
+		}
+	}
+	return sp, nil
+}
+
+// IDs returns the parsed list of authorized SPIFFE IDs.
+func (sp *SpiffePeers) IDs() []spiffeid.ID {
+	if sp == nil {
+		return nil
+	}
+	ids := make([]spiffeid.ID, 0, len(sp.AuthorizedIDs))
+	for _, s := range sp.AuthorizedIDs {
+		id, err := spiffeid.FromString(s)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
@@ -0,0 +1,113 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGetSpiffe(t *testing.T) {
+	t.Setenv("SPIFFE_TEST_ID", "spiffe://example.org/router")
+	tests := []struct {
+		name    string
+		in      string
+		want    *spiffeConfig
+		wantErr bool
+	}{
+		{
+			name: "not_set",
+			in:   "targets: {}\n",
+		},
+		{
+			name: "defaults",
+			in: `
+spiffe:
+  trust-domain: example.org
+  api-server: {}
+`,
+			want: &spiffeConfig{
+				TrustDomain: "example.org",
+				Timeout:     defaultSpiffeTimeout,
+				APIServer:   &SpiffePeers{},
+			},
+		},
+		{
+			name: "authorized_ids",
+			in: `
+spiffe:
+  socket-path: unix:///run/spire/sockets/agent.sock
+  timeout: 5s
+  gnmi-server:
+    authorized-ids:
+      - spiffe://example.org/collector
+  targets:
+    authorized-ids:
+      - ${SPIFFE_TEST_ID}
+`,
+			want: &spiffeConfig{
+				SocketPath: "unix:///run/spire/sockets/agent.sock",
+				Timeout:    5 * time.Second,
+				GNMIServer: &SpiffePeers{AuthorizedIDs: []string{"spiffe://example.org/collector"}},
+				Targets:    &SpiffePeers{AuthorizedIDs: []string{"spiffe://example.org/router"}},
+			},
+		},
+		{
+			name: "invalid_trust_domain",
+			in: `
+spiffe:
+  trust-domain: "Example Org"
+`,
+			wantErr: true,
+		},
+		{
+			name: "invalid_authorized_id",
+			in: `
+spiffe:
+  targets:
+    authorized-ids:
+      - https://example.org/router
+`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := New()
+			cfg.FileConfig.SetConfigType("yaml")
+			if err := cfg.FileConfig.ReadConfig(bytes.NewBufferString(tt.in)); err != nil {
+				t.Fatal(err)
+			}
+			err := cfg.GetSpiffe()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(cfg.Spiffe, tt.want) {
+				t.Errorf("got %+v, want %+v", cfg.Spiffe, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpiffePeersIDs(t *testing.T) {
+	var sp *SpiffePeers
+	if ids := sp.IDs(); ids != nil {
+		t.Errorf("expected no IDs for nil peers, got %v", ids)
+	}
+	sp = &SpiffePeers{AuthorizedIDs: []string{"spiffe://example.org/a", "not-an-id", "spiffe://example.org/b"}}
+	ids := sp.IDs()
+	if len(ids) != 2 || ids[0].String() != "spiffe://example.org/a" || ids[1].String() != "spiffe://example.org/b" {
+		t.Errorf("unexpected IDs: %v", ids)
+	}
+}
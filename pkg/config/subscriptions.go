@@ -24,12 +24,18 @@ import (
 
 	"github.com/openconfig/gnmic/pkg/api"
 	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/cron"
 )
 
 const (
 	subscriptionDefaultMode       = "STREAM"
 	subscriptionDefaultStreamMode = "TARGET_DEFINED"
 	subscriptionDefaultEncoding   = "JSON"
+
+	adaptiveSamplingDefaultMaxIntervalFactor = 10
+	adaptiveSamplingDefaultEvaluationFactor  = 5
+	adaptiveSamplingDefaultLowChangeRatio    = 0.1
+	adaptiveSamplingDefaultHighChangeRatio   = 0.5
 )
 
 var ErrConfig = errors.New("config error")
@@ -246,7 +252,19 @@ func (c *Config) CreateSubscribeRequest(sc *types.SubscriptionConfig, tc *types.
 	if err != nil {
 		return nil, err
 	}
-	return api.NewSubscribeRequest(gnmiOpts...)
+	req, err := api.NewSubscribeRequest(gnmiOpts...)
+	if err != nil {
+		return nil, err
+	}
+	if p := targetProfile(tc); p != nil {
+		subList := req.GetSubscribe()
+		paths := make([]*gnmi.Path, 0, len(subList.GetSubscription()))
+		for _, sub := range subList.GetSubscription() {
+			paths = append(paths, sub.GetPath())
+		}
+		subList.Prefix = p.applyPaths(subList.GetPrefix(), paths...)
+	}
+	return req, nil
 }
 
 func (c *Config) subscriptionOpts(sc *types.SubscriptionConfig, tc *types.TargetConfig) ([]api.GNMIOption, error) {
@@ -296,7 +314,7 @@ func (c *Config) subscriptionOpts(sc *types.SubscriptionConfig, tc *types.Target
 	// multiple stream subscriptions
 	if len(sc.StreamSubscriptions) > 0 {
 		for _, ssc := range sc.StreamSubscriptions {
-			streamGNMIOpts, err := streamSubscriptionOpts(ssc)
+			streamGNMIOpts, err := streamSubscriptionOpts(ssc, tc)
 			if err != nil {
 				return nil, err
 			}
@@ -308,24 +326,11 @@ func (c *Config) subscriptionOpts(sc *types.SubscriptionConfig, tc *types.Target
 		subGnmiOpts := make([]api.GNMIOption, 0, 2)
 		switch gnmi.SubscriptionList_Mode(gnmi.SubscriptionList_Mode_value[strings.ToUpper(sc.Mode)]) {
 		case gnmi.SubscriptionList_STREAM:
-			switch gnmi.SubscriptionMode(gnmi.SubscriptionMode_value[strings.Replace(strings.ToUpper(sc.StreamMode), "-", "_", -1)]) {
-			case gnmi.SubscriptionMode_ON_CHANGE:
-				if sc.HeartbeatInterval != nil {
-					subGnmiOpts = append(subGnmiOpts, api.HeartbeatInterval(*sc.HeartbeatInterval))
-				}
-				subGnmiOpts = append(subGnmiOpts, api.SubscriptionMode(sc.StreamMode))
-			case gnmi.SubscriptionMode_SAMPLE, gnmi.SubscriptionMode_TARGET_DEFINED:
-				if sc.SampleInterval != nil {
-					subGnmiOpts = append(subGnmiOpts, api.SampleInterval(*sc.SampleInterval))
-				}
-				subGnmiOpts = append(subGnmiOpts, api.SuppressRedundant(sc.SuppressRedundant))
-				if sc.SuppressRedundant && sc.HeartbeatInterval != nil {
-					subGnmiOpts = append(subGnmiOpts, api.HeartbeatInterval(*sc.HeartbeatInterval))
-				}
-				subGnmiOpts = append(subGnmiOpts, api.SubscriptionMode(sc.StreamMode))
-			default:
-				return nil, fmt.Errorf("%w: subscription %s unknown stream subscription mode %s", ErrConfig, sc.Name, sc.StreamMode)
+			streamOpts, err := streamModeOpts(sc, tc)
+			if err != nil {
+				return nil, err
 			}
+			subGnmiOpts = append(subGnmiOpts, streamOpts...)
 		default:
 			// poll and once subscription modes
 		}
@@ -340,32 +345,24 @@ func (c *Config) subscriptionOpts(sc *types.SubscriptionConfig, tc *types.Target
 	if sc.Depth > 0 {
 		gnmiOpts = append(gnmiOpts, api.Extension_Depth(sc.Depth))
 	}
+	// master arbitration extension
+	maOpt, err := c.MasterArbitrationOpt(tc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrConfig, err)
+	}
+	if maOpt != nil {
+		gnmiOpts = append(gnmiOpts, maOpt)
+	}
 	return gnmiOpts, nil
 }
 
-func streamSubscriptionOpts(sc *types.SubscriptionConfig) ([]api.GNMIOption, error) {
+func streamSubscriptionOpts(sc *types.SubscriptionConfig, tc *types.TargetConfig) ([]api.GNMIOption, error) {
 	gnmiOpts := make([]api.GNMIOption, 0)
 	for _, p := range sc.Paths {
-		subGnmiOpts := make([]api.GNMIOption, 0, 2)
-		switch gnmi.SubscriptionMode(gnmi.SubscriptionMode_value[strings.Replace(strings.ToUpper(sc.StreamMode), "-", "_", -1)]) {
-		case gnmi.SubscriptionMode_ON_CHANGE:
-			if sc.HeartbeatInterval != nil {
-				subGnmiOpts = append(subGnmiOpts, api.HeartbeatInterval(*sc.HeartbeatInterval))
-			}
-			subGnmiOpts = append(subGnmiOpts, api.SubscriptionMode(sc.StreamMode))
-		case gnmi.SubscriptionMode_SAMPLE, gnmi.SubscriptionMode_TARGET_DEFINED:
-			if sc.SampleInterval != nil {
-				subGnmiOpts = append(subGnmiOpts, api.SampleInterval(*sc.SampleInterval))
-			}
-			subGnmiOpts = append(subGnmiOpts, api.SuppressRedundant(sc.SuppressRedundant))
-			if sc.SuppressRedundant && sc.HeartbeatInterval != nil {
-				subGnmiOpts = append(subGnmiOpts, api.HeartbeatInterval(*sc.HeartbeatInterval))
-			}
-			subGnmiOpts = append(subGnmiOpts, api.SubscriptionMode(sc.StreamMode))
-		default:
-			return nil, fmt.Errorf("%w: subscription %s unknown stream subscription mode %s", ErrConfig, sc.Name, sc.StreamMode)
+		subGnmiOpts, err := streamModeOpts(sc, tc)
+		if err != nil {
+			return nil, err
 		}
-
 		subGnmiOpts = append(subGnmiOpts, api.Path(p))
 		gnmiOpts = append(gnmiOpts,
 			api.Subscription(subGnmiOpts...),
@@ -374,6 +371,70 @@ func streamSubscriptionOpts(sc *types.SubscriptionConfig) ([]api.GNMIOption, err
 	return gnmiOpts, nil
 }
 
+// streamModeOpts returns the gNMI options of a stream subscription path
+// based on the subscription stream mode.
+func streamModeOpts(sc *types.SubscriptionConfig, tc *types.TargetConfig) ([]api.GNMIOption, error) {
+	suppressRedundant, heartbeatInterval := SuppressRedundantAndHeartbeat(sc, tc)
+	subGnmiOpts := make([]api.GNMIOption, 0, 4)
+	switch gnmi.SubscriptionMode(gnmi.SubscriptionMode_value[strings.Replace(strings.ToUpper(sc.StreamMode), "-", "_", -1)]) {
+	case gnmi.SubscriptionMode_ON_CHANGE:
+		if heartbeatInterval != nil {
+			subGnmiOpts = append(subGnmiOpts, api.HeartbeatInterval(*heartbeatInterval))
+		}
+	case gnmi.SubscriptionMode_SAMPLE, gnmi.SubscriptionMode_TARGET_DEFINED:
+		if sc.SampleInterval != nil {
+			subGnmiOpts = append(subGnmiOpts, api.SampleInterval(*sc.SampleInterval))
+		}
+		subGnmiOpts = append(subGnmiOpts, api.SuppressRedundant(suppressRedundant))
+		if heartbeatInterval != nil {
+			subGnmiOpts = append(subGnmiOpts, api.HeartbeatInterval(*heartbeatInterval))
+		}
+	default:
+		return nil, fmt.Errorf("%w: subscription %s unknown stream subscription mode %s", ErrConfig, sc.Name, sc.StreamMode)
+	}
+	return append(subGnmiOpts, api.SubscriptionMode(sc.StreamMode)), nil
+}
+
+// SuppressRedundantAndHeartbeat returns the suppress-redundant and heartbeat-interval
+// values of subscription sc, overridden by the target config tc values if set.
+func SuppressRedundantAndHeartbeat(sc *types.SubscriptionConfig, tc *types.TargetConfig) (bool, *time.Duration) {
+	suppressRedundant, heartbeatInterval := sc.SuppressRedundant, sc.HeartbeatInterval
+	if tc == nil {
+		return suppressRedundant, heartbeatInterval
+	}
+	if tc.SuppressRedundant != nil {
+		suppressRedundant = *tc.SuppressRedundant
+	}
+	if tc.HeartbeatInterval != nil {
+		heartbeatInterval = tc.HeartbeatInterval
+	}
+	return suppressRedundant, heartbeatInterval
+}
+
+// SubscriptionWarnings returns the suppress-redundant and heartbeat-interval
+// settings of subscription sc that do not apply to its mode, and are ignored.
+func SubscriptionWarnings(sc *types.SubscriptionConfig, tc *types.TargetConfig) []string {
+	warnings := make([]string, 0)
+	subs := sc.StreamSubscriptions
+	if len(subs) == 0 {
+		subs = []*types.SubscriptionConfig{sc}
+	}
+	for _, ssc := range subs {
+		suppressRedundant, heartbeatInterval := SuppressRedundantAndHeartbeat(ssc, tc)
+		if !suppressRedundant && heartbeatInterval == nil {
+			continue
+		}
+		if len(sc.StreamSubscriptions) == 0 && strings.ToUpper(sc.Mode) != "STREAM" {
+			warnings = append(warnings, fmt.Sprintf("suppress-redundant and heartbeat-interval are ignored with mode %s", strings.ToLower(sc.Mode)))
+			continue
+		}
+		if suppressRedundant && strings.ToUpper(strings.ReplaceAll(ssc.StreamMode, "-", "_")) == "ON_CHANGE" {
+			warnings = append(warnings, "suppress-redundant is ignored with stream-mode on-change")
+		}
+	}
+	return warnings
+}
+
 func validateAndSetDefaults(sc *types.SubscriptionConfig) error {
 	numPaths := len(sc.Paths)
 	numStreamSubs := len(sc.StreamSubscriptions)
@@ -397,6 +458,15 @@ func validateAndSetDefaults(sc *types.SubscriptionConfig) error {
 	default:
 		return fmt.Errorf("%w: subscription %s: unknown subscription mode %q", ErrConfig, sc.Name, sc.Mode)
 	}
+	if sc.AdaptiveSampling != nil && (strings.ToUpper(sc.Mode) != "STREAM" || numStreamSubs > 0) {
+		return fmt.Errorf("%w: subscription %q: 'adaptive-sampling' requires a stream subscription with 'paths'", ErrConfig, sc.Name)
+	}
+	if err := validateSchedule(sc); err != nil {
+		return err
+	}
+	if err := types.ValidateTargetSelector(sc.TargetSelector); err != nil {
+		return fmt.Errorf("%w: subscription %q: %v", ErrConfig, sc.Name, err)
+	}
 	// validate encoding
 	if sc.Encoding != nil {
 		switch strings.ToUpper(strings.ReplaceAll(*sc.Encoding, "-", "_")) {
@@ -428,7 +498,7 @@ func validateAndSetDefaults(sc *types.SubscriptionConfig) error {
 			default:
 				return fmt.Errorf("%w: subscription %s: unknown stream-mode type %q", ErrConfig, sc.Name, sc.StreamMode)
 			}
-			return nil
+			return validateAdaptiveSampling(sc)
 		}
 
 		// stream subscriptions
@@ -478,6 +548,68 @@ func validateAndSetDefaults(sc *types.SubscriptionConfig) error {
 	return nil
 }
 
+func validateSchedule(sc *types.SubscriptionConfig) error {
+	sch := sc.Schedule
+	if sch == nil {
+		return nil
+	}
+	switch strings.ToUpper(sc.Mode) {
+	case "ONCE", "POLL":
+	default:
+		return fmt.Errorf("%w: subscription %q: 'schedule' requires a once or poll subscription", ErrConfig, sc.Name)
+	}
+	if (sch.Interval > 0) == (sch.Cron != "") {
+		return fmt.Errorf("%w: subscription %q: schedule requires one of 'interval' or 'cron'", ErrConfig, sc.Name)
+	}
+	if sch.Interval < 0 || sch.Splay < 0 {
+		return fmt.Errorf("%w: subscription %q: schedule interval and splay cannot be negative", ErrConfig, sc.Name)
+	}
+	if sch.Cron != "" {
+		if _, err := cron.Parse(sch.Cron); err != nil {
+			return fmt.Errorf("%w: subscription %q: schedule: %v", ErrConfig, sc.Name, err)
+		}
+	}
+	return nil
+}
+
+func validateAdaptiveSampling(sc *types.SubscriptionConfig) error {
+	as := sc.AdaptiveSampling
+	if as == nil {
+		return nil
+	}
+	if strings.ToUpper(strings.ReplaceAll(sc.StreamMode, "-", "_")) != "SAMPLE" {
+		return fmt.Errorf("%w: subscription %q: 'adaptive-sampling' requires stream-mode sample", ErrConfig, sc.Name)
+	}
+	if sc.SampleInterval == nil || *sc.SampleInterval <= 0 {
+		return fmt.Errorf("%w: subscription %q: 'adaptive-sampling' requires a sample-interval", ErrConfig, sc.Name)
+	}
+	if as.MinInterval <= 0 {
+		as.MinInterval = *sc.SampleInterval
+	}
+	if as.MaxInterval <= 0 {
+		as.MaxInterval = adaptiveSamplingDefaultMaxIntervalFactor * *sc.SampleInterval
+	}
+	if as.EvaluationInterval <= 0 {
+		as.EvaluationInterval = adaptiveSamplingDefaultEvaluationFactor * as.MaxInterval
+	}
+	if as.HighChangeRatio <= 0 {
+		if as.LowChangeRatio <= 0 {
+			as.LowChangeRatio = adaptiveSamplingDefaultLowChangeRatio
+		}
+		as.HighChangeRatio = adaptiveSamplingDefaultHighChangeRatio
+	}
+	if as.MinInterval > *sc.SampleInterval || *sc.SampleInterval > as.MaxInterval {
+		return fmt.Errorf("%w: subscription %q: sample-interval must be between the adaptive-sampling min-interval and max-interval", ErrConfig, sc.Name)
+	}
+	if as.EvaluationInterval < as.MaxInterval {
+		return fmt.Errorf("%w: subscription %q: adaptive-sampling evaluation-interval cannot be lower than max-interval", ErrConfig, sc.Name)
+	}
+	if as.LowChangeRatio < 0 || as.HighChangeRatio > 1 || as.LowChangeRatio >= as.HighChangeRatio {
+		return fmt.Errorf("%w: subscription %q: adaptive-sampling change ratios must satisfy 0 <= low-change-ratio < high-change-ratio <= 1", ErrConfig, sc.Name)
+	}
+	return nil
+}
+
 func validateSubscriptionsConfig(subs map[string]*types.SubscriptionConfig) error {
 	var hasPoll bool
 	var hasOnce bool
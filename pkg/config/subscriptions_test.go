@@ -924,6 +924,94 @@ func TestConfig_CreateSubscribeRequest(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "adaptive_sampling_on_change",
+			args: args{
+				sc: &types.SubscriptionConfig{
+					Paths:            []string{"interface"},
+					Mode:             "stream",
+					StreamMode:       "on-change",
+					AdaptiveSampling: &types.AdaptiveSampling{},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "adaptive_sampling_interval_out_of_bounds",
+			args: args{
+				sc: &types.SubscriptionConfig{
+					Paths:          []string{"interface"},
+					Mode:           "stream",
+					StreamMode:     "sample",
+					SampleInterval: pointer.ToDuration(5 * time.Second),
+					AdaptiveSampling: &types.AdaptiveSampling{
+						MinInterval: 10 * time.Second,
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "schedule_stream",
+			args: args{
+				sc: &types.SubscriptionConfig{
+					Paths:    []string{"interface"},
+					Mode:     "stream",
+					Schedule: &types.SubscriptionSchedule{Interval: time.Hour},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "schedule_interval_and_cron",
+			args: args{
+				sc: &types.SubscriptionConfig{
+					Paths:    []string{"interface"},
+					Mode:     "once",
+					Schedule: &types.SubscriptionSchedule{Interval: time.Hour, Cron: "@daily"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "schedule_invalid_cron",
+			args: args{
+				sc: &types.SubscriptionConfig{
+					Paths:    []string{"interface"},
+					Mode:     "poll",
+					Schedule: &types.SubscriptionSchedule{Cron: "0 25 * * *"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "schedule_once_cron",
+			args: args{
+				sc: &types.SubscriptionConfig{
+					Paths:    []string{"interface"},
+					Mode:     "once",
+					Encoding: pointer.ToString("json_ietf"),
+					Schedule: &types.SubscriptionSchedule{Cron: "0 */6 * * *", Splay: time.Minute},
+				},
+			},
+			want: &gnmi.SubscribeRequest{
+				Request: &gnmi.SubscribeRequest_Subscribe{
+					Subscribe: &gnmi.SubscriptionList{
+						Subscription: []*gnmi.Subscription{
+							{
+								Path: &gnmi.Path{
+									Elem: []*gnmi.PathElem{{
+										Name: "interface",
+									}},
+								},
+							},
+						},
+						Mode:     gnmi.SubscriptionList_ONCE,
+						Encoding: gnmi.Encoding_JSON_IETF,
+					},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -965,3 +1053,101 @@ func TestConfig_CreateSubscribeRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateSubscribeRequestTargetStreamOptions(t *testing.T) {
+	sc := &types.SubscriptionConfig{
+		Name:              "sub1",
+		Paths:             []string{"interface"},
+		Encoding:          pointer.ToString("json"),
+		StreamMode:        "sample",
+		SampleInterval:    pointer.ToDuration(10 * time.Second),
+		SuppressRedundant: true,
+		HeartbeatInterval: pointer.ToDuration(time.Minute),
+	}
+	tests := []struct {
+		name          string
+		target        *types.TargetConfig
+		wantSuppress  bool
+		wantHeartbeat time.Duration
+	}{
+		{
+			name:          "subscription_values",
+			target:        &types.TargetConfig{Name: "t1"},
+			wantSuppress:  true,
+			wantHeartbeat: time.Minute,
+		},
+		{
+			name: "target_overrides",
+			target: &types.TargetConfig{
+				Name:              "t1",
+				SuppressRedundant: pointer.ToBool(false),
+				HeartbeatInterval: pointer.ToDuration(0),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{}
+			req, err := c.CreateSubscribeRequest(sc, tt.target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sub := req.GetSubscribe().GetSubscription()[0]
+			if sub.GetSuppressRedundant() != tt.wantSuppress {
+				t.Errorf("got suppress_redundant %v, want %v", sub.GetSuppressRedundant(), tt.wantSuppress)
+			}
+			if got := time.Duration(sub.GetHeartbeatInterval()); got != tt.wantHeartbeat {
+				t.Errorf("got heartbeat_interval %s, want %s", got, tt.wantHeartbeat)
+			}
+		})
+	}
+}
+
+func TestSubscriptionWarnings(t *testing.T) {
+	tests := []struct {
+		name string
+		sc   *types.SubscriptionConfig
+		tc   *types.TargetConfig
+		want int
+	}{
+		{
+			name: "sample_with_suppress_redundant",
+			sc:   &types.SubscriptionConfig{Mode: "stream", StreamMode: "sample", SuppressRedundant: true},
+			want: 0,
+		},
+		{
+			name: "on_change_with_suppress_redundant",
+			sc:   &types.SubscriptionConfig{Mode: "stream", StreamMode: "on-change", SuppressRedundant: true},
+			want: 1,
+		},
+		{
+			name: "on_change_with_suppress_redundant_disabled_by_target",
+			sc:   &types.SubscriptionConfig{Mode: "stream", StreamMode: "on-change", SuppressRedundant: true},
+			tc:   &types.TargetConfig{SuppressRedundant: pointer.ToBool(false)},
+			want: 0,
+		},
+		{
+			name: "once_with_heartbeat",
+			sc:   &types.SubscriptionConfig{Mode: "once", HeartbeatInterval: pointer.ToDuration(time.Minute)},
+			want: 1,
+		},
+		{
+			name: "stream_subscriptions",
+			sc: &types.SubscriptionConfig{
+				StreamSubscriptions: []*types.SubscriptionConfig{
+					{StreamMode: "on-change", SuppressRedundant: true},
+					{StreamMode: "sample", SuppressRedundant: true},
+				},
+			},
+			want: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SubscriptionWarnings(tt.sc, tt.tc)
+			if len(got) != tt.want {
+				t.Errorf("got warnings %v, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+// applyTargetGroups sets the unset fields of tc from the target groups it references.
+// The groups are applied from the last one listed to the first one,
+// so that a group takes precedence over the groups listed before it.
+// The global defaults are applied afterwards to the fields that remain unset.
+func (c *Config) applyTargetGroups(tc *types.TargetConfig) error {
+	for i := len(tc.Groups) - 1; i >= 0; i-- {
+		gc, err := c.targetGroup(tc.Groups[i])
+		if err != nil {
+			return fmt.Errorf("target %q: %v", tc.Name, err)
+		}
+		mergeTargetConfig(tc, gc)
+	}
+	return nil
+}
+
+// targetGroup decodes the target group name into a new target configuration,
+// so that the targets of a group do not share its pointer, slice and map fields.
+func (c *Config) targetGroup(name string) (*types.TargetConfig, error) {
+	g, ok := c.TargetGroups[name]
+	if !ok {
+		// viper lowercases the map keys read from the config file.
+		g, ok = c.TargetGroups[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown target group %q", name)
+		}
+		name = strings.ToLower(name)
+	}
+	gc := new(types.TargetConfig)
+	decoder, err := mapstructure.NewDecoder(
+		&mapstructure.DecoderConfig{
+			DecodeHook: mapstructure.StringToTimeDurationHookFunc(),
+			Result:     gc,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	err = decoder.Decode(convert(g))
+	if err != nil {
+		return nil, fmt.Errorf("target group %q: %v", name, err)
+	}
+	if gc.Name != "" || gc.Address != "" || len(gc.Groups) > 0 {
+		return nil, fmt.Errorf("target group %q: name, address and groups cannot be set in a group", name)
+	}
+	if c.FileConfig != nil {
+		// read the password as a string to maintain its case, see setTargets.
+		pass := c.FileConfig.GetString(fmt.Sprintf("target-groups/%s/password", name))
+		if pass != "" {
+			gc.Password = &pass
+		}
+	}
+	return gc, nil
+}
+
+// mergeTargetConfig sets the unset fields of dst from src,
+// the maps are merged without overwriting the keys already set in dst.
+func mergeTargetConfig(dst, src *types.TargetConfig) {
+	dv := reflect.ValueOf(dst).Elem()
+	sv := reflect.ValueOf(src).Elem()
+	for i := 0; i < dv.NumField(); i++ {
+		df := dv.Field(i)
+		sf := sv.Field(i)
+		if !df.CanSet() || sf.IsZero() {
+			continue
+		}
+		switch {
+		case df.IsZero():
+			df.Set(sf)
+		case df.Kind() == reflect.Map:
+			iter := sf.MapRange()
+			for iter.Next() {
+				if !df.MapIndex(iter.Key()).IsValid() {
+					df.SetMapIndex(iter.Key(), iter.Value())
+				}
+			}
+		}
+	}
+}
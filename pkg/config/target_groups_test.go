@@ -0,0 +1,127 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+func TestTargetGroups(t *testing.T) {
+	in := `
+username: admin
+password: admin
+timeout: 5s
+insecure: true
+
+target-groups:
+  tls:
+    insecure: false
+    skip-verify: true
+    tls-min-version: "1.2"
+  dc1:
+    username: dc1-user
+    password: dc1-pass
+    timeout: 20s
+    event-tags:
+      site: dc1
+      role: leaf
+  spines:
+    event-tags:
+      role: spine
+
+targets:
+  leaf1:
+    address: 10.0.0.1:57400
+    groups: [tls, dc1]
+  spine1:
+    address: 10.0.0.2:57400
+    groups: [dc1, spines]
+    username: spine-user
+    event-tags:
+      rack: r1
+  other:
+    address: 10.0.0.3:57400
+`
+	cfg := New()
+	cfg.FileConfig.SetConfigType("yaml")
+	if err := cfg.FileConfig.ReadConfig(bytes.NewBufferString(in)); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.FileConfig.Unmarshal(cfg); err != nil {
+		t.Fatal(err)
+	}
+	tcs, err := cfg.GetTargets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf1 := tcs["leaf1"]
+	if *leaf1.Insecure || !*leaf1.SkipVerify || leaf1.TLSMinVersion != "1.2" {
+		t.Errorf("leaf1: expected the tls group TLS settings, got %s", leaf1)
+	}
+	if *leaf1.Username != "dc1-user" || *leaf1.Password != "dc1-pass" || leaf1.Timeout != 20*time.Second {
+		t.Errorf("leaf1: expected the dc1 group credentials and timeout, got %s", leaf1)
+	}
+	spine1 := tcs["spine1"]
+	if *spine1.Username != "spine-user" || *spine1.Password != "dc1-pass" {
+		t.Errorf("spine1: expected the target username and the dc1 group password, got %s", spine1)
+	}
+	wantTags := map[string]string{"site": "dc1", "role": "spine", "rack": "r1"}
+	if len(spine1.EventTags) != len(wantTags) {
+		t.Errorf("spine1: expected event-tags %v, got %v", wantTags, spine1.EventTags)
+	}
+	for k, v := range wantTags {
+		if spine1.EventTags[k] != v {
+			t.Errorf("spine1: expected event-tags %v, got %v", wantTags, spine1.EventTags)
+		}
+	}
+	other := tcs["other"]
+	if !*other.Insecure || *other.Username != "admin" || other.Timeout != 5*time.Second {
+		t.Errorf("other: expected the global defaults, got %s", other)
+	}
+	// the groups fields are not shared between targets
+	*leaf1.Username = "changed"
+	if *spine1.Username != "spine-user" || *tcs["leaf1"].Password != *spine1.Password {
+		t.Errorf("unexpected shared group fields")
+	}
+	if spine1.Password == leaf1.Password {
+		t.Errorf("targets share the group password pointer")
+	}
+}
+
+func TestTargetGroupsErrors(t *testing.T) {
+	tests := map[string]struct {
+		groups map[string]map[string]interface{}
+		tc     *types.TargetConfig
+	}{
+		"unknown_group": {
+			tc: &types.TargetConfig{Name: "t1", Address: "10.0.0.1:57400", Groups: []string{"foo"}},
+		},
+		"group_address": {
+			groups: map[string]map[string]interface{}{"g1": {"address": "10.0.0.2:57400"}},
+			tc:     &types.TargetConfig{Name: "t1", Address: "10.0.0.1:57400", Groups: []string{"g1"}},
+		},
+		"nested_groups": {
+			groups: map[string]map[string]interface{}{"g1": {"groups": []interface{}{"g2"}}, "g2": {}},
+			tc:     &types.TargetConfig{Name: "t1", Address: "10.0.0.1:57400", Groups: []string{"g1"}},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := New()
+			c.TargetGroups = tt.groups
+			if err := c.SetTargetConfigDefaults(tt.tc); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
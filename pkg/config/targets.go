@@ -18,6 +18,8 @@ import (
 	"strings"
 
 	"github.com/mitchellh/mapstructure"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"gopkg.in/yaml.v2"
 
 	"github.com/openconfig/gnmic/pkg/api/types"
 )
@@ -46,7 +48,7 @@ func (c *Config) GetTargets() (map[string]*types.TargetConfig, error) {
 		if c.Debug {
 			c.logger.Printf("targets: %v", c.Targets)
 		}
-		return c.Targets, nil
+		return c.filterTargets()
 	}
 	// case targets is defined in config file
 	targetsInt := c.FileConfig.Get("targets")
@@ -66,7 +68,38 @@ func (c *Config) GetTargets() (map[string]*types.TargetConfig, error) {
 	if len(targetsMap) == 0 {
 		return nil, ErrNoTargetsFound
 	}
+	return c.setTargets(targetsMap)
+}
 
+// GetTargetsFromFile reads the targets configuration from the `targets-file`,
+// a YAML or JSON map of target names to target configurations.
+// It allows sending unary RPCs to the targets of a file loader file.
+func (c *Config) GetTargetsFromFile() (map[string]*types.TargetConfig, error) {
+	if c.GlobalFlags.TargetsFile == "" {
+		return nil, ErrNoTargetsFound
+	}
+	b, err := os.ReadFile(c.GlobalFlags.TargetsFile)
+	if err != nil {
+		return nil, err
+	}
+	var v map[string]interface{}
+	err = yaml.Unmarshal(b, &v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse targets file %q: %v", c.GlobalFlags.TargetsFile, err)
+	}
+	targetsMap := make(map[string]interface{}, len(v))
+	for n, t := range v {
+		targetsMap[n] = convert(t)
+	}
+	if len(targetsMap) == 0 {
+		return nil, ErrNoTargetsFound
+	}
+	return c.setTargets(targetsMap)
+}
+
+// setTargets decodes the targets configurations, sets their defaults and applies the targets filter.
+func (c *Config) setTargets(targetsMap map[string]interface{}) (map[string]*types.TargetConfig, error) {
+	var err error
 	newTargetsConfig := make(map[string]*types.TargetConfig)
 	for name, t := range targetsMap {
 		tc := new(types.TargetConfig)
@@ -124,7 +157,7 @@ func (c *Config) GetTargets() (map[string]*types.TargetConfig, error) {
 		if c.Debug {
 			c.logger.Printf("targets: %v", c.Targets)
 		}
-		return c.Targets, nil
+		return c.filterTargets()
 	}
 	for n := range c.Targets {
 		c.Targets[n].Subscriptions = subNames
@@ -132,32 +165,33 @@ func (c *Config) GetTargets() (map[string]*types.TargetConfig, error) {
 	if c.Debug {
 		c.logger.Printf("targets: %v", c.Targets)
 	}
-	return c.Targets, nil
+	return c.filterTargets()
 }
 
 func (c *Config) SetTargetConfigDefaults(tc *types.TargetConfig) error {
+	if err := c.applyTargetGroups(tc); err != nil {
+		return err
+	}
 	defGrpcPort := c.FileConfig.GetString("port")
-	if !strings.HasPrefix(tc.Address, "unix://") {
-		addrList := strings.Split(tc.Address, ",")
-		addrs := make([]string, 0, len(addrList))
-		for _, addr := range addrList {
-			addr = strings.TrimSpace(addr)
-			if !c.UseTunnelServer {
-				_, _, err := net.SplitHostPort(addr)
-				if err != nil {
-					if strings.Contains(err.Error(), "missing port in address") ||
-						strings.Contains(err.Error(), "too many colons in address") {
-						addr = net.JoinHostPort(addr, defGrpcPort)
-					} else {
-						c.logger.Printf("error parsing address '%s': %v", addr, err)
-						return fmt.Errorf("error parsing address '%s': %v", addr, err)
-					}
+	addrList := strings.Split(tc.Address, ",")
+	addrs := make([]string, 0, len(addrList))
+	for _, addr := range addrList {
+		addr = strings.TrimSpace(addr)
+		if !c.UseTunnelServer && !strings.HasPrefix(addr, "unix://") {
+			_, _, err := net.SplitHostPort(addr)
+			if err != nil {
+				if strings.Contains(err.Error(), "missing port in address") ||
+					strings.Contains(err.Error(), "too many colons in address") {
+					addr = net.JoinHostPort(addr, defGrpcPort)
+				} else {
+					c.logger.Printf("error parsing address '%s': %v", addr, err)
+					return fmt.Errorf("error parsing address '%s': %v", addr, err)
 				}
 			}
-			addrs = append(addrs, addr)
 		}
-		tc.Address = strings.Join(addrs, ",")
+		addrs = append(addrs, addr)
 	}
+	tc.Address = strings.Join(addrs, ",")
 	if tc.Username == nil {
 		tc.Username = &c.Username
 	}
@@ -213,9 +247,88 @@ func (c *Config) SetTargetConfigDefaults(tc *types.TargetConfig) error {
 	if tc.Gzip == nil {
 		tc.Gzip = &c.Gzip
 	}
+	if tc.Compression == "" {
+		tc.Compression = c.Compression
+	}
+	if _, err := tc.Compressor(); err != nil {
+		return fmt.Errorf("target %q: %v", tc.Name, err)
+	}
+	if err := setTargetProfileDefaults(tc); err != nil {
+		return err
+	}
+	if tc.EncodingFallback == nil && len(c.EncodingFallback) > 0 {
+		tc.EncodingFallback = append(make([]string, 0, len(c.EncodingFallback)), c.EncodingFallback...)
+	}
+	for _, enc := range tc.EncodingFallback {
+		if _, ok := gnmi.Encoding_value[strings.ToUpper(strings.ReplaceAll(enc, "-", "_"))]; !ok {
+			return fmt.Errorf("target %q: invalid encoding-fallback %q", tc.Name, enc)
+		}
+	}
+	if tc.ElectionID == "" {
+		tc.ElectionID = c.ElectionID
+	}
+	if tc.ElectionRole == "" {
+		tc.ElectionRole = c.ElectionRole
+	}
+	if tc.ElectionID != "" {
+		electionID, err := resolveElectionID(tc.ElectionID)
+		if err != nil {
+			return fmt.Errorf("target %q: %v", tc.Name, err)
+		}
+		tc.ElectionID = electionID
+	}
+	if tc.MaxRecvMsgSize == 0 {
+		tc.MaxRecvMsgSize = c.MaxMsgSize
+	}
+	if tc.MaxSendMsgSize == 0 {
+		tc.MaxSendMsgSize = c.MaxSendMsgSize
+	}
+	if tc.MaxRecvMsgSize < 0 || tc.MaxSendMsgSize < 0 {
+		return fmt.Errorf("target %q: max-recv-msg-size and max-send-msg-size cannot be negative", tc.Name)
+	}
+	if tc.MaxStreamsPerConnection == 0 {
+		tc.MaxStreamsPerConnection = c.MaxStreamsPerConnection
+	}
+	if tc.MaxStreamsPerConnection < 0 {
+		return fmt.Errorf("target %q: max-streams-per-connection cannot be negative", tc.Name)
+	}
+	if tc.Deadlines == nil && c.Deadlines != nil {
+		deadlines := *c.Deadlines
+		tc.Deadlines = &deadlines
+	}
+	if tc.RetryPolicy == nil && c.RetryPolicy != nil {
+		rp := *c.RetryPolicy
+		rp.RetryableStatusCodes = append([]string(nil), c.RetryPolicy.RetryableStatusCodes...)
+		tc.RetryPolicy = &rp
+	}
+	if tc.RetryPolicy != nil {
+		if err := tc.RetryPolicy.Validate(); err != nil {
+			return fmt.Errorf("target %q: %v", tc.Name, err)
+		}
+	}
+	if tc.ClockSkew == nil && c.ClockSkew != nil {
+		cs := *c.ClockSkew
+		tc.ClockSkew = &cs
+	}
+	if tc.ClockSkew != nil {
+		if err := tc.ClockSkew.Validate(); err != nil {
+			return fmt.Errorf("target %q: %v", tc.Name, err)
+		}
+	}
+	if tc.CredentialsCommand == nil && c.CredentialsCommand != nil {
+		cc := *c.CredentialsCommand
+		cc.Args = append([]string(nil), c.CredentialsCommand.Args...)
+		tc.CredentialsCommand = &cc
+	}
+	if err := tc.CredentialsCommand.Validate(); err != nil {
+		return fmt.Errorf("target %q: %v", tc.Name, err)
+	}
 	if tc.BufferSize == 0 {
 		tc.BufferSize = defaultTargetBufferSize
 	}
+	if tc.SourceAddress != "" && net.ParseIP(tc.SourceAddress) == nil {
+		return fmt.Errorf("target %q: invalid source-address %q", tc.Name, tc.SourceAddress)
+	}
 	if tc.Metadata == nil && c.Metadata != nil {
 		tc.Metadata = make(map[string]string)
 		maps.Copy(tc.Metadata, c.Metadata)
@@ -294,6 +407,8 @@ func expandTargetEnv(tc *types.TargetConfig) {
 	tc.TLSMinVersion = os.ExpandEnv(tc.TLSMinVersion)
 	tc.TLSMaxVersion = os.ExpandEnv(tc.TLSMaxVersion)
 	tc.TLSVersion = os.ExpandEnv(tc.TLSVersion)
+	tc.SourceAddress = os.ExpandEnv(tc.SourceAddress)
+	tc.SourceInterface = os.ExpandEnv(tc.SourceInterface)
 	for i := range tc.ProtoFiles {
 		tc.ProtoFiles[i] = os.ExpandEnv(tc.ProtoFiles[i])
 	}
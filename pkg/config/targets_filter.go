@@ -0,0 +1,85 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/itchyny/gojq"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/formatters"
+)
+
+// filterTargets removes the targets not matching the `targets-filter` jq expression.
+// The expression is evaluated against each target configuration, e.g:
+// `.name | startswith("leaf")` or `.tags | index("spine")`.
+func (c *Config) filterTargets() (map[string]*types.TargetConfig, error) {
+	expr := c.FileConfig.GetString("targets-filter")
+	if expr == "" {
+		return c.Targets, nil
+	}
+	code, err := compileTargetsFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+	for n, tc := range c.Targets {
+		ok, err := matchTarget(code, tc)
+		if err != nil {
+			return nil, fmt.Errorf("targets-filter: target %q: %v", n, err)
+		}
+		if !ok {
+			delete(c.Targets, n)
+		}
+	}
+	if len(c.Targets) == 0 {
+		return nil, fmt.Errorf("no target matches the targets-filter %q", expr)
+	}
+	if c.Debug {
+		c.logger.Printf("filtered targets: %v", c.Targets)
+	}
+	return c.Targets, nil
+}
+
+func compileTargetsFilter(expr string) (*gojq.Code, error) {
+	code, err := formatters.CompileJQ(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid targets-filter: %v", err)
+	}
+	return code, nil
+}
+
+// matchTarget returns true if the filter evaluates to a truthy value,
+// i.e anything but false and null.
+func matchTarget(code *gojq.Code, tc *types.TargetConfig) (bool, error) {
+	b, err := json.Marshal(tc)
+	if err != nil {
+		return false, err
+	}
+	var input interface{}
+	err = json.Unmarshal(b, &input)
+	if err != nil {
+		return false, err
+	}
+	iter := code.Run(input)
+	v, ok := iter.Next()
+	if !ok {
+		return false, nil
+	}
+	switch v := v.(type) {
+	case error:
+		return false, v
+	case nil:
+		return false, nil
+	case bool:
+		return v, nil
+	}
+	return true, nil
+}
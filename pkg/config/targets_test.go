@@ -14,10 +14,12 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/AlekSi/pointer"
 
 	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/formatters"
 )
 
 var getTargetsTestSet = map[string]struct {
@@ -317,6 +319,219 @@ targets:
 		},
 		outErr: nil,
 	},
+	"target_with_rpc_policies": {
+		in: []byte(`
+port: 57400
+max-msg-size: 1024
+deadlines:
+  get: 30s
+retry-policy:
+  max-attempts: 3
+targets:
+  target1:
+    username: admin
+    password: admin
+    address: 10.1.1.1
+    max-send-msg-size: 512
+    deadlines:
+      set: 1m
+  target2:
+    username: admin
+    password: admin
+    address: 10.1.1.2
+    max-recv-msg-size: 2048
+    retry-policy:
+      max-attempts: 4
+      initial-backoff: 1s
+      max-backoff: 5s
+      backoff-multiplier: 1.5
+      retryable-status-codes:
+        - unavailable
+        - resource-exhausted
+`),
+		out: map[string]*types.TargetConfig{
+			"target1": {
+				Address:        "10.1.1.1:57400",
+				Name:           "target1",
+				Password:       pointer.ToString("admin"),
+				Username:       pointer.ToString("admin"),
+				Token:          pointer.ToString(""),
+				TLSCert:        pointer.ToString(""),
+				TLSKey:         pointer.ToString(""),
+				LogTLSSecret:   pointer.ToBool(false),
+				Insecure:       pointer.ToBool(false),
+				SkipVerify:     pointer.ToBool(false),
+				Gzip:           pointer.ToBool(false),
+				BufferSize:     uint(100),
+				MaxRecvMsgSize: 1024,
+				MaxSendMsgSize: 512,
+				Deadlines:      &types.RPCDeadlines{Set: time.Minute},
+				RetryPolicy: &types.RetryPolicy{
+					MaxAttempts:          3,
+					InitialBackoff:       100 * time.Millisecond,
+					MaxBackoff:           time.Second,
+					BackoffMultiplier:    2,
+					RetryableStatusCodes: []string{"UNAVAILABLE"},
+				},
+			},
+			"target2": {
+				Address:        "10.1.1.2:57400",
+				Name:           "target2",
+				Password:       pointer.ToString("admin"),
+				Username:       pointer.ToString("admin"),
+				Token:          pointer.ToString(""),
+				TLSCert:        pointer.ToString(""),
+				TLSKey:         pointer.ToString(""),
+				LogTLSSecret:   pointer.ToBool(false),
+				Insecure:       pointer.ToBool(false),
+				SkipVerify:     pointer.ToBool(false),
+				Gzip:           pointer.ToBool(false),
+				BufferSize:     uint(100),
+				MaxRecvMsgSize: 2048,
+				Deadlines:      &types.RPCDeadlines{Get: 30 * time.Second},
+				RetryPolicy: &types.RetryPolicy{
+					MaxAttempts:          4,
+					InitialBackoff:       time.Second,
+					MaxBackoff:           5 * time.Second,
+					BackoffMultiplier:    1.5,
+					RetryableStatusCodes: []string{"UNAVAILABLE", "RESOURCE_EXHAUSTED"},
+				},
+			},
+		},
+		outErr: nil,
+	},
+	"target_with_clock_skew": {
+		in: []byte(`
+port: 57400
+clock-skew:
+  threshold: 10s
+targets:
+  target1:
+    username: admin
+    password: admin
+    address: 10.1.1.1
+  target2:
+    username: admin
+    password: admin
+    address: 10.1.1.2
+    clock-skew:
+      threshold: 1m
+      correct: true
+`),
+		out: map[string]*types.TargetConfig{
+			"target1": {
+				Address:      "10.1.1.1:57400",
+				Name:         "target1",
+				Password:     pointer.ToString("admin"),
+				Username:     pointer.ToString("admin"),
+				Token:        pointer.ToString(""),
+				TLSCert:      pointer.ToString(""),
+				TLSKey:       pointer.ToString(""),
+				LogTLSSecret: pointer.ToBool(false),
+				Insecure:     pointer.ToBool(false),
+				SkipVerify:   pointer.ToBool(false),
+				Gzip:         pointer.ToBool(false),
+				BufferSize:   uint(100),
+				ClockSkew:    &types.ClockSkew{Threshold: 10 * time.Second},
+			},
+			"target2": {
+				Address:      "10.1.1.2:57400",
+				Name:         "target2",
+				Password:     pointer.ToString("admin"),
+				Username:     pointer.ToString("admin"),
+				Token:        pointer.ToString(""),
+				TLSCert:      pointer.ToString(""),
+				TLSKey:       pointer.ToString(""),
+				LogTLSSecret: pointer.ToBool(false),
+				Insecure:     pointer.ToBool(false),
+				SkipVerify:   pointer.ToBool(false),
+				Gzip:         pointer.ToBool(false),
+				BufferSize:   uint(100),
+				ClockSkew:    &types.ClockSkew{Threshold: time.Minute, Correct: true},
+			},
+		},
+		outErr: nil,
+	},
+	"target_with_compression": {
+		in: []byte(`
+port: 57400
+compression: zstd
+targets:
+  target1:
+    username: admin
+    password: admin
+    address: 10.1.1.1
+  target2:
+    username: admin
+    password: admin
+    address: 10.1.1.2
+    compression: none
+`),
+		out: map[string]*types.TargetConfig{
+			"target1": {
+				Address:      "10.1.1.1:57400",
+				Name:         "target1",
+				Password:     pointer.ToString("admin"),
+				Username:     pointer.ToString("admin"),
+				Token:        pointer.ToString(""),
+				TLSCert:      pointer.ToString(""),
+				TLSKey:       pointer.ToString(""),
+				LogTLSSecret: pointer.ToBool(false),
+				Insecure:     pointer.ToBool(false),
+				SkipVerify:   pointer.ToBool(false),
+				Gzip:         pointer.ToBool(false),
+				BufferSize:   uint(100),
+				Compression:  "zstd",
+			},
+			"target2": {
+				Address:      "10.1.1.2:57400",
+				Name:         "target2",
+				Password:     pointer.ToString("admin"),
+				Username:     pointer.ToString("admin"),
+				Token:        pointer.ToString(""),
+				TLSCert:      pointer.ToString(""),
+				TLSKey:       pointer.ToString(""),
+				LogTLSSecret: pointer.ToBool(false),
+				Insecure:     pointer.ToBool(false),
+				SkipVerify:   pointer.ToBool(false),
+				Gzip:         pointer.ToBool(false),
+				BufferSize:   uint(100),
+				Compression:  "none",
+			},
+		},
+		outErr: nil,
+	},
+	"target_with_unix_and_source_address": {
+		in: []byte(`
+port: 57400
+targets:
+  target1:
+    username: admin
+    password: admin
+    address: unix:///var/run/gnmi.sock,10.1.1.2
+    source-address: 192.168.1.10
+    source-interface: mgmt
+`),
+		out: map[string]*types.TargetConfig{
+			"target1": {
+				Address:         "unix:///var/run/gnmi.sock,10.1.1.2:57400",
+				Name:            "target1",
+				Password:        pointer.ToString("admin"),
+				Username:        pointer.ToString("admin"),
+				Token:           pointer.ToString(""),
+				TLSCert:         pointer.ToString(""),
+				TLSKey:          pointer.ToString(""),
+				LogTLSSecret:    pointer.ToBool(false),
+				Insecure:        pointer.ToBool(false),
+				SkipVerify:      pointer.ToBool(false),
+				Gzip:            pointer.ToBool(false),
+				BufferSize:      uint(100),
+				SourceAddress:   "192.168.1.10",
+				SourceInterface: "mgmt",
+			},
+		},
+		outErr: nil,
+	},
 }
 
 func TestGetTargets(t *testing.T) {
@@ -356,3 +571,108 @@ func TestGetTargets(t *testing.T) {
 		})
 	}
 }
+
+func TestGetTargetsFilter(t *testing.T) {
+	in := []byte(`
+targets-filter: '.name | startswith("leaf")'
+targets:
+  leaf1:
+    address: 10.1.1.1:57400
+  leaf2:
+    address: 10.1.1.2:57400
+  spine1:
+    address: 10.1.1.3:57400
+`)
+	cfg := New()
+	cfg.SetLogger()
+	cfg.FileConfig.SetConfigType("yaml")
+	err := cfg.FileConfig.ReadConfig(bytes.NewBuffer(in))
+	if err != nil {
+		t.Fatalf("failed reading config: %v", err)
+	}
+	err = cfg.FileConfig.Unmarshal(cfg)
+	if err != nil {
+		t.Fatalf("failed fileConfig.Unmarshal: %v", err)
+	}
+	outs, err := cfg.GetTargets()
+	if err != nil {
+		t.Fatalf("failed getting targets: %v", err)
+	}
+	if len(outs) != 2 || outs["leaf1"] == nil || outs["leaf2"] == nil {
+		t.Errorf("unexpected filtered targets: %v", outs)
+	}
+
+	cfg.FileConfig.Set("targets-filter", `.name == "leaf3"`)
+	_, err = cfg.GetTargets()
+	if err == nil {
+		t.Errorf("expected an error when no target matches the filter")
+	}
+}
+
+func TestGetTargetsFromFile(t *testing.T) {
+	f, err := os.CreateTemp("", "targets-*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(`
+leaf1:
+  address: 10.1.1.1:57400
+  tags: [leaf]
+spine1:
+  address: 10.1.1.2:57400
+  tags: [spine]
+`)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := New()
+	cfg.SetLogger()
+	cfg.GlobalFlags.TargetsFile = f.Name()
+	cfg.FileConfig.Set("targets-filter", `.tags | index("spine")`)
+	outs, err := cfg.GetTargetsFromFile()
+	if err != nil {
+		t.Fatalf("failed getting targets: %v", err)
+	}
+	if len(outs) != 1 || outs["spine1"] == nil || outs["spine1"].Address != "10.1.1.2:57400" {
+		t.Errorf("unexpected targets: %v", outs)
+	}
+}
+
+func TestGetTargetsFilterJQLibrary(t *testing.T) {
+	in := []byte(`
+jq-libraries:
+  select: |
+    def is_leaf: .name | startswith("leaf");
+targets-filter: 'import "select" as sel; sel::is_leaf'
+targets:
+  leaf1:
+    address: 10.1.1.1:57400
+  spine1:
+    address: 10.1.1.3:57400
+`)
+	cfg := New()
+	cfg.SetLogger()
+	cfg.FileConfig.SetConfigType("yaml")
+	err := cfg.FileConfig.ReadConfig(bytes.NewBuffer(in))
+	if err != nil {
+		t.Fatalf("failed reading config: %v", err)
+	}
+	err = cfg.FileConfig.Unmarshal(cfg)
+	if err != nil {
+		t.Fatalf("failed fileConfig.Unmarshal: %v", err)
+	}
+	err = cfg.GetJQLibraries()
+	if err != nil {
+		t.Fatalf("failed getting jq libraries: %v", err)
+	}
+	defer formatters.SetJQLibraries(nil)
+	outs, err := cfg.GetTargets()
+	if err != nil {
+		t.Fatalf("failed getting targets: %v", err)
+	}
+	if len(outs) != 1 || outs["leaf1"] == nil {
+		t.Errorf("unexpected filtered targets: %v", outs)
+	}
+}
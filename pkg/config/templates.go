@@ -0,0 +1,35 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+
+	"github.com/openconfig/gnmic/pkg/gtemplate"
+)
+
+// GetTemplateLimits reads the template-limits section and sets the
+// limits applied to the outputs templates execution.
+func (c *Config) GetTemplateLimits() error {
+	if !c.FileConfig.IsSet("template-limits") {
+		return nil
+	}
+	l := gtemplate.Limits{
+		Timeout:       c.FileConfig.GetDuration("template-limits/timeout"),
+		MaxOutputSize: c.FileConfig.GetInt("template-limits/max-output-size"),
+	}
+	if l.Timeout < 0 {
+		return fmt.Errorf("template-limits: invalid timeout %s", l.Timeout)
+	}
+	if l.MaxOutputSize < 0 {
+		return fmt.Errorf("template-limits: invalid max-output-size %d", l.MaxOutputSize)
+	}
+	gtemplate.SetDefaultLimits(l)
+	return nil
+}
@@ -0,0 +1,161 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+const (
+	TimestampsSkewActionReject = "reject"
+	TimestampsSkewActionClamp  = "clamp"
+)
+
+// named Go time layouts accepted by the timestamps layout.
+var timestampLayouts = map[string]string{
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RubyDate":    time.RubyDate,
+	"Kitchen":     time.Kitchen,
+	"Stamp":       time.Stamp,
+	"StampMilli":  time.StampMilli,
+	"StampMicro":  time.StampMicro,
+	"StampNano":   time.StampNano,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+}
+
+// OutputTimestamps configures the timestamps of the data written to an output:
+// a human-readable timestamp added next to the epoch one,
+// and the bounds of the accepted timestamps.
+type OutputTimestamps struct {
+	// name of the event tag set to the formatted timestamp
+	Field string `mapstructure:"field,omitempty" json:"field,omitempty"`
+	// Go time layout or layout name, defaults to RFC3339Nano
+	Layout string `mapstructure:"layout,omitempty" json:"layout,omitempty"`
+	// IANA time zone name, "Local" or "UTC", defaults to UTC
+	Timezone string `mapstructure:"timezone,omitempty" json:"timezone,omitempty"`
+	// timestamps older or further in the future than now
+	// by more than these durations are considered skewed
+	MaxPast   time.Duration `mapstructure:"max-past,omitempty" json:"max-past,omitempty"`
+	MaxFuture time.Duration `mapstructure:"max-future,omitempty" json:"max-future,omitempty"`
+	// reject or clamp, defaults to reject
+	SkewAction string `mapstructure:"skew-action,omitempty" json:"skew-action,omitempty"`
+
+	layout   string
+	location *time.Location
+}
+
+// GetOutputTimestamps returns the timestamps configuration of the output configuration outCfg,
+// nil if it has none.
+func GetOutputTimestamps(outCfg map[string]interface{}) (*OutputTimestamps, error) {
+	tsCfg, ok := outCfg["timestamps"]
+	if !ok || tsCfg == nil {
+		return nil, nil
+	}
+	ts := new(OutputTimestamps)
+	decoder, err := mapstructure.NewDecoder(
+		&mapstructure.DecoderConfig{
+			DecodeHook: mapstructure.StringToTimeDurationHookFunc(),
+			Result:     ts,
+		})
+	if err != nil {
+		return nil, err
+	}
+	if err = decoder.Decode(tsCfg); err != nil {
+		return nil, fmt.Errorf("timestamps: %v", err)
+	}
+	if err = ts.validate(); err != nil {
+		return nil, fmt.Errorf("timestamps: %v", err)
+	}
+	return ts, nil
+}
+
+func (ts *OutputTimestamps) validate() error {
+	if ts.MaxPast < 0 || ts.MaxFuture < 0 {
+		return errors.New("max-past and max-future cannot be negative")
+	}
+	switch ts.SkewAction {
+	case "":
+		ts.SkewAction = TimestampsSkewActionReject
+	case TimestampsSkewActionReject, TimestampsSkewActionClamp:
+	default:
+		return fmt.Errorf("unknown skew-action %q", ts.SkewAction)
+	}
+	ts.layout = time.RFC3339Nano
+	if ts.Layout != "" {
+		ts.layout = ts.Layout
+		if l, ok := timestampLayouts[ts.Layout]; ok {
+			ts.layout = l
+		}
+	}
+	ts.location = time.UTC
+	if ts.Timezone != "" {
+		loc, err := time.LoadLocation(ts.Timezone)
+		if err != nil {
+			return fmt.Errorf("timezone: %v", err)
+		}
+		ts.location = loc
+	}
+	return nil
+}
+
+// Format returns the timestamp t, in nanoseconds since the epoch,
+// formatted with the configured layout and time zone.
+func (ts *OutputTimestamps) Format(t int64) string {
+	return time.Unix(0, t).In(ts.location).Format(ts.layout)
+}
+
+// Bound checks the timestamp t against the configured bounds.
+// It returns the timestamp to use and true if t is within the bounds,
+// now if t is skewed and the skew action is clamp,
+// and false if t is skewed and the skew action is reject.
+func (ts *OutputTimestamps) Bound(t int64, now time.Time) (int64, bool) {
+	if ts.InBounds(t, now) {
+		return t, true
+	}
+	if ts.SkewAction == TimestampsSkewActionClamp {
+		return now.UnixNano(), true
+	}
+	return t, false
+}
+
+// InBounds returns true if the timestamp t is not skewed.
+func (ts *OutputTimestamps) InBounds(t int64, now time.Time) bool {
+	n := now.UnixNano()
+	if ts.MaxPast > 0 && n-t > ts.MaxPast.Nanoseconds() {
+		return false
+	}
+	if ts.MaxFuture > 0 && t-n > ts.MaxFuture.Nanoseconds() {
+		return false
+	}
+	return true
+}
+
+// ValidateOutputsTimestamps checks the timestamps configuration of the outputs.
+func (c *Config) ValidateOutputsTimestamps() error {
+	for name, outCfg := range c.Outputs {
+		if _, err := GetOutputTimestamps(outCfg); err != nil {
+			return fmt.Errorf("output %q: %v", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetOutputTimestamps(t *testing.T) {
+	ts := time.Date(2024, 3, 1, 10, 20, 30, 500, time.UTC).UnixNano()
+	tests := []struct {
+		name       string
+		in         map[string]interface{}
+		wantErr    bool
+		wantNil    bool
+		wantFormat string
+		wantAction string
+	}{
+		{
+			name:    "none",
+			in:      map[string]interface{}{"type": "file"},
+			wantNil: true,
+		},
+		{
+			name: "defaults",
+			in: map[string]interface{}{
+				"timestamps": map[string]interface{}{"field": "time"},
+			},
+			wantFormat: "2024-03-01T10:20:30.0000005Z",
+			wantAction: TimestampsSkewActionReject,
+		},
+		{
+			name: "layout_name_and_timezone",
+			in: map[string]interface{}{
+				"timestamps": map[string]interface{}{
+					"layout":      "RFC3339",
+					"timezone":    "Europe/Paris",
+					"skew-action": "clamp",
+				},
+			},
+			wantFormat: "2024-03-01T11:20:30+01:00",
+			wantAction: TimestampsSkewActionClamp,
+		},
+		{
+			name: "go_layout",
+			in: map[string]interface{}{
+				"timestamps": map[string]interface{}{"layout": "2006/01/02 15:04"},
+			},
+			wantFormat: "2024/03/01 10:20",
+			wantAction: TimestampsSkewActionReject,
+		},
+		{
+			name: "unknown_timezone",
+			in: map[string]interface{}{
+				"timestamps": map[string]interface{}{"timezone": "Mars/Olympus"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown_action",
+			in: map[string]interface{}{
+				"timestamps": map[string]interface{}{"skew-action": "ignore"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative_bound",
+			in: map[string]interface{}{
+				"timestamps": map[string]interface{}{"max-past": "-1m"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetOutputTimestamps(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantNil {
+				if got != nil {
+					t.Fatalf("expected nil, got %+v", got)
+				}
+				return
+			}
+			if f := got.Format(ts); f != tt.wantFormat {
+				t.Errorf("format: got %q, want %q", f, tt.wantFormat)
+			}
+			if got.SkewAction != tt.wantAction {
+				t.Errorf("skew-action: got %q, want %q", got.SkewAction, tt.wantAction)
+			}
+		})
+	}
+}
+
+func TestOutputTimestampsBound(t *testing.T) {
+	now := time.Unix(1000, 0)
+	ts := &OutputTimestamps{MaxPast: time.Hour, MaxFuture: time.Minute, SkewAction: TimestampsSkewActionReject}
+	tests := []struct {
+		name   string
+		t      time.Time
+		action string
+		want   int64
+		wantOK bool
+	}{
+		{name: "in_bounds", t: now.Add(-time.Minute), action: TimestampsSkewActionReject, want: now.Add(-time.Minute).UnixNano(), wantOK: true},
+		{name: "past_reject", t: now.Add(-2 * time.Hour), action: TimestampsSkewActionReject, want: now.Add(-2 * time.Hour).UnixNano()},
+		{name: "future_reject", t: now.Add(2 * time.Minute), action: TimestampsSkewActionReject, want: now.Add(2 * time.Minute).UnixNano()},
+		{name: "past_clamp", t: now.Add(-2 * time.Hour), action: TimestampsSkewActionClamp, want: now.UnixNano(), wantOK: true},
+		{name: "future_clamp", t: now.Add(2 * time.Minute), action: TimestampsSkewActionClamp, want: now.UnixNano(), wantOK: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts.SkewAction = tt.action
+			got, ok := ts.Bound(tt.t.UnixNano(), now)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("got (%d, %v), want (%d, %v)", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
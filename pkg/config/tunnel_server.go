@@ -11,6 +11,9 @@ package config
 import (
 	"fmt"
 	"os"
+	"regexp"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/mitchellh/mapstructure"
@@ -37,16 +40,36 @@ type tunnelServer struct {
 }
 
 type targetMatch struct {
+	// an optional name identifying the group of targets matching
+	// this entry, used in logs and metrics labels.
+	Name string `mapstructure:"name,omitempty" json:"name,omitempty"`
 	// target Type as reported by the tunnel.Target to the Tunnel Server
 	Type string `mapstructure:"type,omitempty" json:"type,omitempty"`
 	// a Regex pattern to check the target ID as reported by
 	// the tunnel.Target to the Tunnel Server
 	ID string `mapstructure:"id,omitempty" json:"id,omitempty"`
+	// action applied to the targets matching the above Type and ID,
+	// one of "accept" or "reject", defaults to "accept".
+	Action string `mapstructure:"action,omitempty" json:"action,omitempty"`
+	// a list of Regex patterns, the subscriptions with a name matching
+	// one of them are assigned to the targets of this group.
+	Subscriptions []string `mapstructure:"subscriptions,omitempty" json:"subscriptions,omitempty"`
 	// Optional gnmic.Target Configuration that will be assigned to the target with
 	// an ID matching the above regex
 	Config types.TargetConfig `mapstructure:"config,omitempty" json:"config,omitempty"`
 }
 
+const (
+	tunnelTargetActionAccept = "accept"
+	tunnelTargetActionReject = "reject"
+)
+
+// Reject returns true if the targets matching this entry
+// should be refused by the tunnel server.
+func (tm *targetMatch) Reject() bool {
+	return tm.Action == tunnelTargetActionReject
+}
+
 func (c *Config) GetTunnelServer() error {
 	if !c.FileConfig.IsSet("tunnel-server") {
 		return nil
@@ -79,6 +102,9 @@ func (c *Config) GetTunnelServer() error {
 			if err != nil {
 				return err
 			}
+			if err = tm.validate(); err != nil {
+				return fmt.Errorf("tunnel-server target match %d: %w", len(c.TunnelServer.Targets), err)
+			}
 			c.TunnelServer.Targets = append(c.TunnelServer.Targets, tm)
 		}
 	case nil:
@@ -98,3 +124,45 @@ func (c *Config) setTunnelServerDefaults() {
 		c.TunnelServer.TargetWaitTime = defaultTargetWaitTime
 	}
 }
+
+func (tm *targetMatch) validate() error {
+	tm.Action = strings.ToLower(tm.Action)
+	switch tm.Action {
+	case "":
+		tm.Action = tunnelTargetActionAccept
+	case tunnelTargetActionAccept, tunnelTargetActionReject:
+	default:
+		return fmt.Errorf("unknown action %q", tm.Action)
+	}
+	if _, err := regexp.Compile(tm.Type); err != nil {
+		return fmt.Errorf("invalid type regex %q: %w", tm.Type, err)
+	}
+	if _, err := regexp.Compile(tm.ID); err != nil {
+		return fmt.Errorf("invalid id regex %q: %w", tm.ID, err)
+	}
+	for _, sub := range tm.Subscriptions {
+		if _, err := regexp.Compile(sub); err != nil {
+			return fmt.Errorf("invalid subscription regex %q: %w", sub, err)
+		}
+	}
+	return nil
+}
+
+// TunnelTargetSubscriptions returns the names of the configured subscriptions
+// matching the subscriptions regexes of the given target match entry.
+func (c *Config) TunnelTargetSubscriptions(tm *targetMatch) []string {
+	if len(tm.Subscriptions) == 0 {
+		return nil
+	}
+	subs := make([]string, 0)
+	for name := range c.Subscriptions {
+		for _, re := range tm.Subscriptions {
+			if ok, _ := regexp.MatchString(re, name); ok {
+				subs = append(subs, name)
+				break
+			}
+		}
+	}
+	sort.Strings(subs)
+	return subs
+}
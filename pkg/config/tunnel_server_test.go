@@ -0,0 +1,159 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+func TestGetTunnelServerTargets(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []*targetMatch
+		wantErr bool
+	}{
+		{
+			name: "no_targets",
+			in: `
+tunnel-server:
+  address: :57401
+`,
+			want: []*targetMatch{},
+		},
+		{
+			name: "default_action",
+			in: `
+tunnel-server:
+  targets:
+    - type: GNMI_GNOI
+      id: leaf.*
+`,
+			want: []*targetMatch{{Type: "GNMI_GNOI", ID: "leaf.*", Action: "accept"}},
+		},
+		{
+			name: "reject",
+			in: `
+tunnel-server:
+  targets:
+    - name: lab
+      id: lab.*
+      action: REJECT
+    - id: .*
+      subscriptions: [sub.*]
+`,
+			want: []*targetMatch{
+				{Name: "lab", ID: "lab.*", Action: "reject"},
+				{ID: ".*", Action: "accept", Subscriptions: []string{"sub.*"}},
+			},
+		},
+		{
+			name: "unknown_action",
+			in: `
+tunnel-server:
+  targets:
+    - id: .*
+      action: drop
+`,
+			wantErr: true,
+		},
+		{
+			name: "invalid_type_regex",
+			in: `
+tunnel-server:
+  targets:
+    - type: "GNMI_(GNOI"
+`,
+			wantErr: true,
+		},
+		{
+			name: "invalid_id_regex",
+			in: `
+tunnel-server:
+  targets:
+    - id: "leaf[1"
+`,
+			wantErr: true,
+		},
+		{
+			name: "invalid_subscription_regex",
+			in: `
+tunnel-server:
+  targets:
+    - id: .*
+      subscriptions: ["sub(1"]
+`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := New()
+			cfg.FileConfig.SetConfigType("yaml")
+			if err := cfg.FileConfig.ReadConfig(bytes.NewBufferString(tt.in)); err != nil {
+				t.Fatal(err)
+			}
+			err := cfg.GetTunnelServer()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(cfg.TunnelServer.Targets, tt.want) {
+				t.Errorf("got %+v, want %+v", cfg.TunnelServer.Targets, tt.want)
+			}
+		})
+	}
+}
+
+func TestTunnelTargetSubscriptions(t *testing.T) {
+	cfg := New()
+	cfg.Subscriptions = map[string]*types.SubscriptionConfig{
+		"sub1":   {Name: "sub1"},
+		"sub2":   {Name: "sub2"},
+		"alarms": {Name: "alarms"},
+	}
+	tests := []struct {
+		name string
+		subs []string
+		want []string
+	}{
+		{
+			name: "none",
+		},
+		{
+			name: "prefix",
+			subs: []string{"^sub"},
+			want: []string{"sub1", "sub2"},
+		},
+		{
+			name: "several_regexes",
+			subs: []string{"sub1$", "alarm", "sub."},
+			want: []string{"alarms", "sub1", "sub2"},
+		},
+		{
+			name: "no_match",
+			subs: []string{"^interfaces$"},
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cfg.TunnelTargetSubscriptions(&targetMatch{Subscriptions: tt.subs})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package cron parses the standard 5 fields cron expressions
+// and computes their activation times.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression:
+// minute, hour, day of month, month and day of week.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// set if the day of month or the day of week field is not a wildcard
+	domRestricted, dowRestricted bool
+}
+
+// searchLimit bounds the search of the next activation time.
+const searchLimit = 5 * 366 * 24 * time.Hour
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+type fieldBounds struct {
+	name     string
+	min, max int
+}
+
+var bounds = []fieldBounds{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// Parse parses a cron expression made of 5 space separated fields:
+// minute, hour, day of month, month and day of week (0 or 7 is Sunday),
+// or one of the descriptors @yearly, @monthly, @weekly, @daily and @hourly.
+// Each field is a wildcard (*), a value, a range (1-5) or a list of them (1,3,5),
+// optionally followed by a step (*/15, 0-30/10).
+func Parse(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if d, ok := descriptors[expr]; ok {
+		expr = d
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != len(bounds) {
+		return nil, fmt.Errorf("invalid cron expression %q: expecting %d fields, got %d", expr, len(bounds), len(fields))
+	}
+	values := make([]uint64, len(fields))
+	for i, f := range fields {
+		v, err := parseField(f, bounds[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %v", expr, err)
+		}
+		values[i] = v
+	}
+	s := &Schedule{
+		minute:        values[0],
+		hour:          values[1],
+		dom:           values[2],
+		month:         values[3],
+		dow:           values[4],
+		domRestricted: fields[2] != "*",
+		dowRestricted: fields[4] != "*",
+	}
+	// Sunday is either 0 or 7
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	return s, nil
+}
+
+func parseField(f string, b fieldBounds) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(f, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepStr)
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("%s: invalid step %q", b.name, stepStr)
+			}
+		}
+		start, end := b.min, b.max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			lo, hi, _ := strings.Cut(rng, "-")
+			var err error
+			start, err = parseValue(lo, b)
+			if err != nil {
+				return 0, err
+			}
+			end, err = parseValue(hi, b)
+			if err != nil {
+				return 0, err
+			}
+			if start > end {
+				return 0, fmt.Errorf("%s: invalid range %q", b.name, rng)
+			}
+		default:
+			v, err := parseValue(rng, b)
+			if err != nil {
+				return 0, err
+			}
+			start = v
+			if !hasStep {
+				end = v
+			}
+		}
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(s string, b fieldBounds) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil || v < b.min || v > b.max {
+		return 0, fmt.Errorf("%s: invalid value %q, must be between %d and %d", b.name, s, b.min, b.max)
+	}
+	return v, nil
+}
+
+// Next returns the first activation time strictly after t,
+// in the location of t.
+// It returns the zero time if there is none within 5 years.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(searchLimit)
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches applies the cron rule: if both the day of month and the day of week
+// are restricted, a day matching either of them matches.
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domRestricted && s.dowRestricted {
+		return dom || dow
+	}
+	return dom && dow
+}
@@ -0,0 +1,68 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	// a Wednesday
+	from := time.Date(2024, 1, 31, 10, 17, 30, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{expr: "* * * * *", want: time.Date(2024, 1, 31, 10, 18, 0, 0, time.UTC)},
+		{expr: "*/15 * * * *", want: time.Date(2024, 1, 31, 10, 30, 0, 0, time.UTC)},
+		{expr: "0 */6 * * *", want: time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)},
+		{expr: "5,10 9-11 * * *", want: time.Date(2024, 1, 31, 11, 5, 0, 0, time.UTC)},
+		{expr: "@daily", want: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{expr: "@hourly", want: time.Date(2024, 1, 31, 11, 0, 0, 0, time.UTC)},
+		{expr: "30 2 * * 0", want: time.Date(2024, 2, 4, 2, 30, 0, 0, time.UTC)},
+		{expr: "30 2 * * 7", want: time.Date(2024, 2, 4, 2, 30, 0, 0, time.UTC)},
+		{expr: "0 0 29 2 *", want: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 31 * *", want: time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)},
+		// day of month or day of week
+		{expr: "0 0 15 * 5", want: time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 30 2 *", want: time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := s.Next(from); !got.Equal(tt.want) {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"@every",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("%q: expected an error", expr)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package file
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// ErrNotModified is returned by ReadFileIfModified
+// if the remote file did not change since it was last read.
+var ErrNotModified = errors.New("not modified")
+
+// Validators are the cache validators of a remote file.
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+// IsRemote returns true if the path is read with an HTTP(S), FTP, SFTP or S3 client.
+func IsRemote(path string) bool {
+	for _, p := range []string{"http://", "https://", "ftp://", "sftp://", "s3://"} {
+		if strings.HasPrefix(path, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadFileIfModified reads a file like ReadFile, it also supports s3://bucket/key paths.
+// For HTTP(S) and S3 paths, the validators v of the previous read are sent with the request:
+// ErrNotModified is returned if the file did not change, otherwise the file bytes
+// are returned with the new validators.
+func ReadFileIfModified(ctx context.Context, path string, v *Validators) ([]byte, *Validators, error) {
+	switch {
+	case strings.HasPrefix(path, "https://"), strings.HasPrefix(path, "http://"):
+		return readHTTPFileIfModified(ctx, path, v)
+	case strings.HasPrefix(path, "s3://"):
+		return readS3FileIfModified(ctx, path, v)
+	default:
+		b, err := ReadFile(ctx, path)
+		return b, nil, err
+	}
+}
+
+func readHTTPFileIfModified(ctx context.Context, path string, v *Validators) ([]byte, *Validators, error) {
+	client := new(http.Client)
+	if strings.HasPrefix(path, "https://") {
+		client.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if v != nil {
+		if v.ETag != "" {
+			req.Header.Set("If-None-Match", v.ETag)
+		}
+		if v.LastModified != "" {
+			req.Header.Set("If-Modified-Since", v.LastModified)
+		}
+	}
+	r, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer r.Body.Close()
+	switch r.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return nil, v, ErrNotModified
+	default:
+		return nil, nil, fmt.Errorf("unexpected HTTP status code %d, GET from %s", r.StatusCode, path)
+	}
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return b, &Validators{
+		ETag:         r.Header.Get("ETag"),
+		LastModified: r.Header.Get("Last-Modified"),
+	}, nil
+}
+
+// readS3FileIfModified reads an object from S3, the path format is s3://bucket/key.
+// The region and credentials are taken from the AWS environment and shared config,
+// the region can be overridden with a `region` query parameter.
+func readS3FileIfModified(ctx context.Context, path string, v *Validators) ([]byte, *Validators, error) {
+	u, err := url.Parse(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	key := strings.TrimPrefix(u.Path, "/")
+	if u.Host == "" || key == "" {
+		return nil, nil, fmt.Errorf("invalid S3 path %q, expected s3://bucket/key", path)
+	}
+	cfg := aws.NewConfig()
+	if region := u.Query().Get("region"); region != "" {
+		cfg = cfg.WithRegion(region)
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *cfg,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	in := &s3.GetObjectInput{
+		Bucket: aws.String(u.Host),
+		Key:    aws.String(key),
+	}
+	if v != nil {
+		if v.ETag != "" {
+			in.IfNoneMatch = aws.String(v.ETag)
+		}
+		if v.LastModified != "" {
+			if t, err := http.ParseTime(v.LastModified); err == nil {
+				in.IfModifiedSince = aws.Time(t)
+			}
+		}
+	}
+	out, err := s3.New(sess).GetObjectWithContext(ctx, in)
+	if err != nil {
+		var rf awserr.RequestFailure
+		if errors.As(err, &rf) && rf.StatusCode() == http.StatusNotModified {
+			return nil, v, ErrNotModified
+		}
+		return nil, nil, err
+	}
+	defer out.Body.Close()
+	b, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	nv := &Validators{ETag: aws.StringValue(out.ETag)}
+	if out.LastModified != nil {
+		nv.LastModified = out.LastModified.UTC().Format(http.TimeFormat)
+	}
+	return b, nv, nil
+}
@@ -13,21 +13,31 @@ import (
 	_ "github.com/openconfig/gnmic/pkg/formatters/event_allow"
 	_ "github.com/openconfig/gnmic/pkg/formatters/event_combine"
 	_ "github.com/openconfig/gnmic/pkg/formatters/event_convert"
+	_ "github.com/openconfig/gnmic/pkg/formatters/event_copy_fields"
 	_ "github.com/openconfig/gnmic/pkg/formatters/event_data_convert"
 	_ "github.com/openconfig/gnmic/pkg/formatters/event_date_string"
 	_ "github.com/openconfig/gnmic/pkg/formatters/event_delete"
+	_ "github.com/openconfig/gnmic/pkg/formatters/event_dom_thresholds"
 	_ "github.com/openconfig/gnmic/pkg/formatters/event_drop"
 	_ "github.com/openconfig/gnmic/pkg/formatters/event_duration_convert"
 	_ "github.com/openconfig/gnmic/pkg/formatters/event_extract_tags"
 	_ "github.com/openconfig/gnmic/pkg/formatters/event_group_by"
+	_ "github.com/openconfig/gnmic/pkg/formatters/event_histogram"
+	_ "github.com/openconfig/gnmic/pkg/formatters/event_join"
 	_ "github.com/openconfig/gnmic/pkg/formatters/event_jq"
 	_ "github.com/openconfig/gnmic/pkg/formatters/event_merge"
 	_ "github.com/openconfig/gnmic/pkg/formatters/event_override_ts"
+	_ "github.com/openconfig/gnmic/pkg/formatters/event_path_normalize"
 	_ "github.com/openconfig/gnmic/pkg/formatters/event_rate_limit"
+	_ "github.com/openconfig/gnmic/pkg/formatters/event_sample"
+	_ "github.com/openconfig/gnmic/pkg/formatters/event_silence"
+	_ "github.com/openconfig/gnmic/pkg/formatters/event_split"
 	_ "github.com/openconfig/gnmic/pkg/formatters/event_starlark"
 	_ "github.com/openconfig/gnmic/pkg/formatters/event_strings"
 	_ "github.com/openconfig/gnmic/pkg/formatters/event_to_tag"
 	_ "github.com/openconfig/gnmic/pkg/formatters/event_trigger"
+	_ "github.com/openconfig/gnmic/pkg/formatters/event_units"
 	_ "github.com/openconfig/gnmic/pkg/formatters/event_value_tag"
 	_ "github.com/openconfig/gnmic/pkg/formatters/event_write"
+	_ "github.com/openconfig/gnmic/pkg/formatters/event_yang_types"
 )
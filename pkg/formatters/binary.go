@@ -0,0 +1,142 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package formatters
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/ugorji/go/codec"
+)
+
+var (
+	cborHandle    = newCBORHandle()
+	msgpackHandle = newMsgpackHandle()
+)
+
+func newCBORHandle() *codec.CborHandle {
+	h := new(codec.CborHandle)
+	h.MapType = reflect.TypeOf(map[string]interface{}(nil))
+	return h
+}
+
+func newMsgpackHandle() *codec.MsgpackHandle {
+	h := new(codec.MsgpackHandle)
+	h.MapType = reflect.TypeOf(map[string]interface{}(nil))
+	h.WriteExt = true
+	h.RawToString = true
+	return h
+}
+
+// IsEventFormat returns true if the format serializes events:
+// `event` (JSON), `cbor` or `msgpack`.
+func IsEventFormat(format string) bool {
+	switch format {
+	case "event", "cbor", "msgpack":
+		return true
+	}
+	return false
+}
+
+// IsBinaryFormat returns true if the format is a binary events encoding.
+func IsBinaryFormat(format string) bool {
+	return format == "cbor" || format == "msgpack"
+}
+
+func binaryHandle(format string) (codec.Handle, error) {
+	switch format {
+	case "cbor":
+		return cborHandle, nil
+	case "msgpack":
+		return msgpackHandle, nil
+	}
+	return nil, fmt.Errorf("unknown binary format %q", format)
+}
+
+func marshalBinary(format string, v interface{}) ([]byte, error) {
+	h, err := binaryHandle(format)
+	if err != nil {
+		return nil, err
+	}
+	var b []byte
+	err = codec.NewEncoderBytes(&b, h).Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// binaryEvents holds the fields of both an EventEnvelope and a single EventMsg,
+// so that a binary map is decoded in a single pass.
+type binaryEvents struct {
+	Version *int         `codec:"version"`
+	Events  *[]*EventMsg `codec:"events"`
+
+	Name      string                 `codec:"name"`
+	Timestamp int64                  `codec:"timestamp"`
+	Tags      map[string]string      `codec:"tags"`
+	Values    map[string]interface{} `codec:"values"`
+	Deletes   []string               `codec:"deletes"`
+}
+
+// DecodeEventsFormat decodes events serialized in the given format, one of
+// `event`, `cbor` or `msgpack`.
+// Like DecodeEvents, it accepts an EventEnvelope, an array of events or a single event.
+func DecodeEventsFormat(format string, b []byte) ([]*EventMsg, error) {
+	if !IsBinaryFormat(format) {
+		return DecodeEvents(b)
+	}
+	if len(b) == 0 {
+		return nil, nil
+	}
+	h, err := binaryHandle(format)
+	if err != nil {
+		return nil, err
+	}
+	if isBinaryArray(format, b[0]) {
+		evs := make([]*EventMsg, 0)
+		err = codec.NewDecoderBytes(b, h).Decode(&evs)
+		if err != nil {
+			return nil, err
+		}
+		return evs, nil
+	}
+	be := new(binaryEvents)
+	err = codec.NewDecoderBytes(b, h).Decode(be)
+	if err != nil {
+		return nil, err
+	}
+	if be.Events == nil {
+		// single event without envelope
+		return []*EventMsg{{
+			Name:      be.Name,
+			Timestamp: be.Timestamp,
+			Tags:      be.Tags,
+			Values:    be.Values,
+			Deletes:   be.Deletes,
+		}}, nil
+	}
+	if be.Version == nil || *be.Version < 1 {
+		return nil, errors.New("invalid events envelope: missing version")
+	}
+	return *be.Events, nil
+}
+
+// isBinaryArray returns true if the first byte of a CBOR or MessagePack item
+// designates an array.
+func isBinaryArray(format string, b byte) bool {
+	switch format {
+	case "cbor":
+		return b>>5 == 4 // major type 4
+	case "msgpack":
+		return b&0xf0 == 0x90 || b == 0xdc || b == 0xdd // fixarray, array 16, array 32
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package formatters
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBinaryEventsRoundTrip(t *testing.T) {
+	for _, format := range []string{"cbor", "msgpack"} {
+		for _, mo := range []*MarshalOptions{
+			{Format: format},
+			{Format: format, EventEnvelope: true, EventSchemaURL: "https://example.com/event.json"},
+		} {
+			b, err := mo.MarshalEvents(envelopeTestEvents)
+			if err != nil {
+				t.Fatalf("%s: unexpected marshal error: %v", format, err)
+			}
+			got, err := DecodeEventsFormat(format, b)
+			if err != nil {
+				t.Fatalf("%s: unexpected decode error: %v", format, err)
+			}
+			if !reflect.DeepEqual(got, envelopeTestEvents) {
+				t.Errorf("%s envelope=%v: got %+v, want %+v", format, mo.EventEnvelope, got[0], envelopeTestEvents[0])
+			}
+			// single event
+			b, err = mo.MarshalEvent(envelopeTestEvents[0])
+			if err != nil {
+				t.Fatalf("%s: unexpected marshal error: %v", format, err)
+			}
+			got, err = DecodeEventsFormat(format, b)
+			if err != nil {
+				t.Fatalf("%s: unexpected decode error: %v", format, err)
+			}
+			if !reflect.DeepEqual(got, envelopeTestEvents) {
+				t.Errorf("%s envelope=%v single: got %+v, want %+v", format, mo.EventEnvelope, got[0], envelopeTestEvents[0])
+			}
+		}
+	}
+}
+
+func TestBinaryEventsSize(t *testing.T) {
+	jb, err := (&MarshalOptions{Format: "event"}).MarshalEvents(envelopeTestEvents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range []string{"cbor", "msgpack"} {
+		b, err := (&MarshalOptions{Format: format}).MarshalEvents(envelopeTestEvents)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(b) >= len(jb) {
+			t.Errorf("%s: encoded size %d not smaller than JSON size %d", format, len(b), len(jb))
+		}
+	}
+}
+
+func TestDecodeEventsFormatInvalid(t *testing.T) {
+	// a msgpack envelope without version
+	b, err := marshalBinary("msgpack", map[string]interface{}{"events": []interface{}{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = DecodeEventsFormat("msgpack", b)
+	if err == nil {
+		t.Errorf("expected an error decoding an envelope without version")
+	}
+	_, err = DecodeEventsFormat("cbor", []byte{0xff})
+	if err == nil {
+		t.Errorf("expected an error decoding an invalid CBOR payload")
+	}
+}
@@ -0,0 +1,194 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package formatters
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/itchyny/gojq"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+// fields available to all processor types, they are handled by the pipeline
+// and removed from the configuration passed to the processor Init.
+const (
+	whenField           = "when"
+	elseProcessorsField = "else-processors"
+)
+
+// conditional applies a processor to the events matching a jq condition,
+// the other events go through the else processors, if any.
+type conditional struct {
+	name      string
+	when      *gojq.Code
+	proc      EventProcessor
+	elseProcs []EventProcessor
+
+	logger *log.Logger
+}
+
+// NewEventProcessor creates and initializes the processor called name from its definition in ps.
+// If the processor config includes a `when` condition, the returned processor applies only to the matching events,
+// the others are passed through the processors listed under `else-processors`.
+func NewEventProcessor(name string, ps map[string]map[string]interface{}, opts ...Option) (EventProcessor, error) {
+	return newEventProcessor(name, ps, nil, opts...)
+}
+
+func newEventProcessor(name string, ps map[string]map[string]interface{}, parents []string, opts ...Option) (EventProcessor, error) {
+	for _, p := range parents {
+		if p == name {
+			return nil, fmt.Errorf("processor %q references itself: %s", name, strings.Join(append(parents, name), " -> "))
+		}
+	}
+	epCfg, ok := ps[name]
+	if !ok {
+		return nil, fmt.Errorf("%q event processor not found", name)
+	}
+	epType := ""
+	for k := range epCfg {
+		epType = k
+		break
+	}
+	in, ok := EventProcessors[epType]
+	if !ok {
+		return nil, fmt.Errorf("%q event processor has an unknown type=%q", name, epType)
+	}
+	cfg, when, elseNames, err := conditionalConfig(epCfg[epType])
+	if err != nil {
+		return nil, fmt.Errorf("failed initializing event processor '%s' of type='%s': %w", name, epType, err)
+	}
+	ep := in()
+	err = ep.Init(cfg, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed initializing event processor '%s' of type='%s': %w", name, epType, err)
+	}
+	if when == "" {
+		return ep, nil
+	}
+	c := &conditional{
+		name:      name,
+		proc:      ep,
+		elseProcs: make([]EventProcessor, 0, len(elseNames)),
+		logger:    log.New(io.Discard, "", 0),
+	}
+	c.when, err = CompileJQ(when)
+	if err != nil {
+		return nil, fmt.Errorf("event processor %q: invalid %q condition: %w", name, whenField, err)
+	}
+	for _, en := range elseNames {
+		elseProc, err := newEventProcessor(en, ps, append(parents, name), opts...)
+		if err != nil {
+			return nil, err
+		}
+		c.elseProcs = append(c.elseProcs, elseProc)
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// conditionalConfig extracts the `when` and `else-processors` fields from a processor config.
+func conditionalConfig(cfg interface{}) (interface{}, string, []string, error) {
+	m, ok := cfg.(map[string]interface{})
+	if !ok {
+		return cfg, "", nil, nil
+	}
+	_, hasWhen := m[whenField]
+	_, hasElse := m[elseProcessorsField]
+	if !hasWhen && !hasElse {
+		return cfg, "", nil, nil
+	}
+	when := ""
+	elseNames := make([]string, 0)
+	ncfg := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		switch k {
+		case whenField:
+			s, ok := v.(string)
+			if !ok {
+				return nil, "", nil, fmt.Errorf("%q must be a string, got %T", whenField, v)
+			}
+			when = strings.TrimSpace(s)
+		case elseProcessorsField:
+			switch v := v.(type) {
+			case string:
+				elseNames = append(elseNames, v)
+			case []interface{}:
+				for _, n := range v {
+					s, ok := n.(string)
+					if !ok {
+						return nil, "", nil, fmt.Errorf("%q must be a list of processor names, got %T", elseProcessorsField, n)
+					}
+					elseNames = append(elseNames, s)
+				}
+			case []string:
+				elseNames = append(elseNames, v...)
+			default:
+				return nil, "", nil, fmt.Errorf("%q must be a list of processor names, got %T", elseProcessorsField, v)
+			}
+		default:
+			ncfg[k] = v
+		}
+	}
+	if when == "" && len(elseNames) > 0 {
+		return nil, "", nil, fmt.Errorf("%q requires a %q condition", elseProcessorsField, whenField)
+	}
+	return ncfg, when, elseNames, nil
+}
+
+func (c *conditional) Init(interface{}, ...Option) error { return nil }
+
+func (c *conditional) Apply(es ...*EventMsg) []*EventMsg {
+	in := make([]*EventMsg, 0, len(es))
+	out := make([]*EventMsg, 0, len(es))
+	for _, e := range es {
+		ok, err := CheckCondition(c.when, e)
+		if err != nil {
+			c.logger.Printf("processor %q condition check failed: %v", c.name, err)
+		}
+		if ok {
+			in = append(in, e)
+			continue
+		}
+		out = append(out, e)
+	}
+	if len(in) > 0 {
+		in = c.proc.Apply(in...)
+	}
+	if len(out) > 0 {
+		for _, ep := range c.elseProcs {
+			out = ep.Apply(out...)
+		}
+	}
+	res := append(in, out...)
+	if len(in) > 0 && len(out) > 0 {
+		sort.SliceStable(res, func(i, j int) bool {
+			return res[i].Timestamp < res[j].Timestamp
+		})
+	}
+	return res
+}
+
+func (c *conditional) WithTargets(map[string]*types.TargetConfig) {}
+
+func (c *conditional) WithLogger(l *log.Logger) {
+	if l != nil {
+		c.logger = l
+	}
+}
+
+func (c *conditional) WithActions(map[string]map[string]interface{}) {}
+
+func (c *conditional) WithProcessors(map[string]map[string]any) {}
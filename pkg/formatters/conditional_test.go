@@ -0,0 +1,95 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package formatters_test
+
+import (
+	"testing"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+	_ "github.com/openconfig/gnmic/pkg/formatters/all"
+)
+
+func addTagProc(tag, value string, extra map[string]interface{}) map[string]interface{} {
+	cfg := map[string]interface{}{
+		"value-names": []interface{}{".*"},
+		"add":         map[string]interface{}{tag: value},
+	}
+	for k, v := range extra {
+		cfg[k] = v
+	}
+	return map[string]interface{}{"event-add-tag": cfg}
+}
+
+func TestConditionalProcessor(t *testing.T) {
+	ps := map[string]map[string]interface{}{
+		"core": addTagProc("role", "core", map[string]interface{}{
+			"when":            `.tags.source == "r1"`,
+			"else-processors": []interface{}{"edge"},
+		}),
+		"edge": addTagProc("role", "edge", map[string]interface{}{
+			"when":            `.tags.source == "r2"`,
+			"else-processors": []interface{}{"other"},
+		}),
+		"other": addTagProc("role", "other", nil),
+	}
+	ep, err := formatters.NewEventProcessor("core", ps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	es := []*formatters.EventMsg{
+		{Timestamp: 1, Tags: map[string]string{"source": "r1"}, Values: map[string]interface{}{"v": 1}},
+		{Timestamp: 2, Tags: map[string]string{"source": "r2"}, Values: map[string]interface{}{"v": 1}},
+		{Timestamp: 3, Tags: map[string]string{"source": "r3"}, Values: map[string]interface{}{"v": 1}},
+	}
+	res := ep.Apply(es...)
+	want := []string{"core", "edge", "other"}
+	if len(res) != len(want) {
+		t.Fatalf("expected %d events, got %d", len(want), len(res))
+	}
+	for i, e := range res {
+		if e.Timestamp != int64(i+1) {
+			t.Errorf("event #%d: unexpected timestamp %d", i, e.Timestamp)
+		}
+		if e.Tags["role"] != want[i] {
+			t.Errorf("event #%d: expected role %q, got %q", i, want[i], e.Tags["role"])
+		}
+	}
+	// the wrapped processor config must not be modified
+	if _, ok := ps["core"]["event-add-tag"].(map[string]interface{})["when"]; !ok {
+		t.Errorf("processor config was modified")
+	}
+}
+
+func TestConditionalProcessorErrors(t *testing.T) {
+	tests := map[string]map[string]map[string]interface{}{
+		"loop": {
+			"p1": addTagProc("a", "b", map[string]interface{}{"when": "true", "else-processors": []interface{}{"p2"}}),
+			"p2": addTagProc("a", "b", map[string]interface{}{"when": "true", "else-processors": []interface{}{"p1"}}),
+		},
+		"else_without_when": {
+			"p1": addTagProc("a", "b", map[string]interface{}{"else-processors": []interface{}{"p2"}}),
+			"p2": addTagProc("a", "b", nil),
+		},
+		"unknown_else": {
+			"p1": addTagProc("a", "b", map[string]interface{}{"when": "true", "else-processors": []interface{}{"p3"}}),
+		},
+		"invalid_when": {
+			"p1": addTagProc("a", "b", map[string]interface{}{"when": ".tags["}),
+		},
+	}
+	for name, ps := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := formatters.NewEventProcessor("p1", ps)
+			if err == nil {
+				t.Errorf("expected an error")
+			}
+			t.Log(err)
+		})
+	}
+}
@@ -0,0 +1,111 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package formatters
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// EventEnvelopeVersion is the version of the events envelope written by this gNMIc version.
+//
+// Version 0 designates the events written without an envelope:
+// a JSON array of events or a single JSON event object.
+const EventEnvelopeVersion = 1
+
+// EventEnvelope wraps the serialized events with the version of their format.
+// Decoders ignore the fields they do not know,
+// so events written by a newer gNMIc version are still decoded.
+type EventEnvelope struct {
+	Version   int         `json:"version"`
+	SchemaURL string      `json:"schema-url,omitempty"`
+	Events    []*EventMsg `json:"events"`
+}
+
+// MarshalEvents marshals the events to JSON, CBOR or MessagePack depending on the format,
+// wrapped in an EventEnvelope if enabled.
+func (o *MarshalOptions) MarshalEvents(evs []*EventMsg) ([]byte, error) {
+	var v interface{} = evs
+	if o.EventEnvelope {
+		v = &EventEnvelope{
+			Version:   EventEnvelopeVersion,
+			SchemaURL: o.EventSchemaURL,
+			Events:    evs,
+		}
+	}
+	return o.marshalEvents(v)
+}
+
+// MarshalEvent marshals a single event, used when the events are split.
+// If the envelope is enabled, the event is wrapped in its own EventEnvelope.
+func (o *MarshalOptions) MarshalEvent(ev *EventMsg) ([]byte, error) {
+	if o.EventEnvelope {
+		return o.MarshalEvents([]*EventMsg{ev})
+	}
+	return o.marshalEvents(ev)
+}
+
+func (o *MarshalOptions) marshalEvents(v interface{}) ([]byte, error) {
+	if IsBinaryFormat(o.Format) {
+		return marshalBinary(o.Format, v)
+	}
+	if o.Multiline {
+		return json.MarshalIndent(v, "", o.Indent)
+	}
+	return json.Marshal(v)
+}
+
+// DecodeEvents decodes events serialized by any gNMIc version:
+// an EventEnvelope, a JSON array of events or a single JSON event object.
+func DecodeEvents(b []byte) ([]*EventMsg, error) {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 {
+		return nil, nil
+	}
+	switch b[0] {
+	case '[':
+		evs := make([]*EventMsg, 0)
+		err := json.Unmarshal(b, &evs)
+		if err != nil {
+			return nil, err
+		}
+		return evs, nil
+	case '{':
+		env := new(struct {
+			Version *int            `json:"version"`
+			Events  json.RawMessage `json:"events"`
+		})
+		err := json.Unmarshal(b, env)
+		if err != nil {
+			return nil, err
+		}
+		if env.Events == nil {
+			// single event without envelope
+			ev := new(EventMsg)
+			err = json.Unmarshal(b, ev)
+			if err != nil {
+				return nil, err
+			}
+			return []*EventMsg{ev}, nil
+		}
+		if env.Version == nil || *env.Version < 1 {
+			return nil, errors.New("invalid events envelope: missing version")
+		}
+		evs := make([]*EventMsg, 0)
+		err = json.Unmarshal(env.Events, &evs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid events envelope version %d: %v", *env.Version, err)
+		}
+		return evs, nil
+	default:
+		return nil, fmt.Errorf("unexpected events encoding starting with %q", b[0])
+	}
+}
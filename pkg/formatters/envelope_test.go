@@ -0,0 +1,90 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package formatters
+
+import (
+	"reflect"
+	"testing"
+)
+
+var envelopeTestEvents = []*EventMsg{
+	{
+		Name:      "sub1",
+		Timestamp: 42,
+		Tags:      map[string]string{"source": "router1"},
+		Values:    map[string]interface{}{"/interface/oper-state": "up"},
+	},
+}
+
+func TestMarshalEventsEnvelope(t *testing.T) {
+	mo := &MarshalOptions{EventEnvelope: true, EventSchemaURL: "https://example.com/event.json"}
+	b, err := mo.MarshalEvents(envelopeTestEvents)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"version":1,"schema-url":"https://example.com/event.json","events":[{"name":"sub1","timestamp":42,"tags":{"source":"router1"},"values":{"/interface/oper-state":"up"}}]}`
+	if string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+}
+
+func TestDecodeEvents(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []*EventMsg
+		wantErr bool
+	}{
+		{
+			name:  "array",
+			input: `[{"name":"sub1","timestamp":42,"tags":{"source":"router1"},"values":{"/interface/oper-state":"up"}}]`,
+			want:  envelopeTestEvents,
+		},
+		{
+			name:  "single_event",
+			input: ` {"name":"sub1","timestamp":42,"tags":{"source":"router1"},"values":{"/interface/oper-state":"up"}}`,
+			want:  envelopeTestEvents,
+		},
+		{
+			name:  "envelope_v1",
+			input: `{"version":1,"events":[{"name":"sub1","timestamp":42,"tags":{"source":"router1"},"values":{"/interface/oper-state":"up"}}]}`,
+			want:  envelopeTestEvents,
+		},
+		{
+			name:  "envelope_newer_version",
+			input: `{"version":2,"new-field":"x","events":[{"name":"sub1","timestamp":42,"tags":{"source":"router1"},"values":{"/interface/oper-state":"up"},"new-event-field":1}]}`,
+			want:  envelopeTestEvents,
+		},
+		{
+			name:  "empty",
+			input: "  ",
+		},
+		{
+			name:    "envelope_without_version",
+			input:   `{"events":[]}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid",
+			input:   `"sub1"`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeEvents([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeEvents() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DecodeEvents() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
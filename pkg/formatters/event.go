@@ -34,6 +34,36 @@ func (e *EventMsg) String() string {
 	return string(b)
 }
 
+// Clone returns a copy of the event with its own tags, values and deletes,
+// so that it can be modified by processors while e is used concurrently.
+// The values themselves are not deep copied.
+func (e *EventMsg) Clone() *EventMsg {
+	if e == nil {
+		return nil
+	}
+	c := &EventMsg{
+		Name:      e.Name,
+		Timestamp: e.Timestamp,
+	}
+	if e.Tags != nil {
+		c.Tags = make(map[string]string, len(e.Tags))
+		for k, v := range e.Tags {
+			c.Tags[k] = v
+		}
+	}
+	if e.Values != nil {
+		c.Values = make(map[string]interface{}, len(e.Values))
+		for k, v := range e.Values {
+			c.Values[k] = v
+		}
+	}
+	if e.Deletes != nil {
+		c.Deletes = make([]string, len(e.Deletes))
+		copy(c.Deletes, e.Deletes)
+	}
+	return c
+}
+
 // ResponseToEventMsgs //
 func ResponseToEventMsgs(name string, rsp *gnmi.SubscribeResponse, meta map[string]string, eps ...EventProcessor) ([]*EventMsg, error) {
 	if rsp == nil {
@@ -395,9 +425,13 @@ func num64(n interface{}) interface{} {
 	return nil
 }
 
+// MetaVarPrefix is the prefix of the metadata keys holding the target vars.
+// They are not added to the events tags.
+const MetaVarPrefix = "var:"
+
 func addMetaTags(e *EventMsg, meta map[string]string) {
 	for k, v := range meta {
-		if k == "format" {
+		if k == "format" || strings.HasPrefix(k, MetaVarPrefix) {
 			continue
 		}
 		if _, ok := e.Tags[k]; ok {
@@ -67,11 +67,7 @@ func (p *addTag) Init(cfg interface{}, opts ...formatters.Option) error {
 	}
 	if p.Condition != "" {
 		p.Condition = strings.TrimSpace(p.Condition)
-		q, err := gojq.Parse(p.Condition)
-		if err != nil {
-			return err
-		}
-		p.code, err = gojq.Compile(q)
+		p.code, err = formatters.CompileJQ(p.Condition)
 		if err != nil {
 			return err
 		}
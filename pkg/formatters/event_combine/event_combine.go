@@ -78,39 +78,22 @@ func (p *combine) Init(cfg any, opts ...formatters.Option) error {
 		// init condition if it's set
 		if proc.Condition != "" {
 			proc.Condition = strings.TrimSpace(proc.Condition)
-			q, err := gojq.Parse(proc.Condition)
-			if err != nil {
-				return err
-			}
-			proc.condition, err = gojq.Compile(q)
+			proc.condition, err = formatters.CompileJQ(proc.Condition)
 			if err != nil {
 				return err
 			}
 		}
 		// init subprocessors
-		if epCfg, ok := p.processorsDefinitions[proc.Name]; ok {
-			epType := ""
-			for k := range epCfg {
-				epType = k
-				break
-			}
-			if in, ok := formatters.EventProcessors[epType]; ok {
-				proc.proc = in()
-				err := proc.proc.Init(epCfg[epType],
-					formatters.WithLogger(p.logger),
-					formatters.WithTargets(p.targetsConfigs),
-					formatters.WithActions(p.actionsDefinitions),
-					formatters.WithProcessors(p.processorsDefinitions),
-				)
-				if err != nil {
-					return fmt.Errorf("failed initializing event processor '%s' of type='%s': %v", proc.Name, epType, err)
-				}
-				p.logger.Printf("added event processor '%s' of type=%s to combine processor", proc.Name, epType)
-				continue
-			}
-			return fmt.Errorf("%q event processor has an unknown type=%q", proc.Name, epType)
+		proc.proc, err = formatters.NewEventProcessor(proc.Name, p.processorsDefinitions,
+			formatters.WithLogger(p.logger),
+			formatters.WithTargets(p.targetsConfigs),
+			formatters.WithActions(p.actionsDefinitions),
+			formatters.WithProcessors(p.processorsDefinitions),
+		)
+		if err != nil {
+			return err
 		}
-		return fmt.Errorf("%q event processor not found", proc.Name)
+		p.logger.Printf("added event processor '%s' to combine processor", proc.Name)
 	}
 	if p.logger.Writer() != io.Discard {
 		b, err := json.Marshal(p)
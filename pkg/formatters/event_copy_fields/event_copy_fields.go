@@ -0,0 +1,304 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package event_copy_fields
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/api/utils"
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/gtemplate"
+)
+
+const (
+	processorType = "event-copy-fields"
+	loggingPrefix = "[" + processorType + "] "
+)
+
+const (
+	sectionTags   = "tags"
+	sectionValues = "values"
+)
+
+// copyFields copies or moves the tags and values with a name matching
+// one of the rules regular expressions to the tags or values section,
+// optionally renaming them and converting their type.
+type copyFields struct {
+	Fields []*field `mapstructure:"fields,omitempty" json:"fields,omitempty"`
+	Debug  bool     `mapstructure:"debug,omitempty" json:"debug,omitempty"`
+
+	logger *log.Logger
+}
+
+type field struct {
+	// section the fields are copied from, tags or values
+	From string `mapstructure:"from,omitempty" json:"from,omitempty"`
+	// section the fields are copied to, tags or values
+	To string `mapstructure:"to,omitempty" json:"to,omitempty"`
+	// regular expressions matching the fields names
+	Names []string `mapstructure:"names,omitempty" json:"names,omitempty"`
+	// template of the new field name, the field name is kept if empty
+	Rename string `mapstructure:"rename,omitempty" json:"rename,omitempty"`
+	// type of the copied value: int, uint, float, bool or string.
+	// Tags are always strings.
+	Type string `mapstructure:"type,omitempty" json:"type,omitempty"`
+	// delete the source field after copying it
+	Move bool `mapstructure:"move,omitempty" json:"move,omitempty"`
+	// do not overwrite an existing field with the same name
+	KeepExisting bool `mapstructure:"keep-existing,omitempty" json:"keep-existing,omitempty"`
+
+	names  []*regexp.Regexp
+	rename *template.Template
+}
+
+// renameInput is the input of the rename template.
+type renameInput struct {
+	// name of the matched field
+	Name string
+	// the matched field value
+	Value interface{}
+	// the name submatches of the regular expression
+	Groups []string
+	// the event tags
+	Tags map[string]string
+}
+
+func init() {
+	formatters.Register(processorType, func() formatters.EventProcessor {
+		return &copyFields{
+			logger: log.New(io.Discard, "", 0),
+		}
+	})
+}
+
+func (c *copyFields) Init(cfg interface{}, opts ...formatters.Option) error {
+	err := formatters.DecodeConfig(cfg, c)
+	if err != nil {
+		return err
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	for i, f := range c.Fields {
+		err = f.init()
+		if err != nil {
+			return fmt.Errorf("field %d: %v", i, err)
+		}
+	}
+	if c.logger.Writer() != io.Discard {
+		b, err := json.Marshal(c)
+		if err != nil {
+			c.logger.Printf("initialized processor '%s': %+v", processorType, c)
+			return nil
+		}
+		c.logger.Printf("initialized processor '%s': %s", processorType, string(b))
+	}
+	return nil
+}
+
+func (f *field) init() error {
+	f.From = strings.ToLower(f.From)
+	f.To = strings.ToLower(f.To)
+	for _, s := range []string{f.From, f.To} {
+		if s != sectionTags && s != sectionValues {
+			return fmt.Errorf("invalid section %q, expecting %q or %q", s, sectionTags, sectionValues)
+		}
+	}
+	if len(f.Names) == 0 {
+		return fmt.Errorf("missing names")
+	}
+	switch f.Type {
+	case "", "int", "uint", "float", "bool", "string":
+	default:
+		return fmt.Errorf("unknown type %q, expecting one of int, uint, float, bool or string", f.Type)
+	}
+	if f.From == f.To && f.Rename == "" && f.Type == "" {
+		return fmt.Errorf("copying fields to the same section requires a rename template or a type")
+	}
+	f.names = make([]*regexp.Regexp, 0, len(f.Names))
+	for _, reg := range f.Names {
+		re, err := regexp.Compile(reg)
+		if err != nil {
+			return err
+		}
+		f.names = append(f.names, re)
+	}
+	if f.Rename != "" {
+		var err error
+		f.rename, err = gtemplate.CreateTemplate("rename", f.Rename)
+		if err != nil {
+			return fmt.Errorf("failed to parse rename template: %v", err)
+		}
+	}
+	return nil
+}
+
+func (c *copyFields) Apply(es ...*formatters.EventMsg) []*formatters.EventMsg {
+	for _, e := range es {
+		if e == nil {
+			continue
+		}
+		for _, f := range c.Fields {
+			c.apply(e, f)
+		}
+	}
+	return es
+}
+
+// apply copies the event fields matching f.
+// The matching fields are gathered before copying them,
+// so that a field copied to its own section is not matched again.
+func (c *copyFields) apply(e *formatters.EventMsg, f *field) {
+	src := make(map[string]interface{})
+	if f.From == sectionTags {
+		for k, v := range e.Tags {
+			src[k] = v
+		}
+	} else {
+		for k, v := range e.Values {
+			src[k] = v
+		}
+	}
+	names := make([]string, 0, len(src))
+	groups := make(map[string][]string)
+	for k := range src {
+		for _, re := range f.names {
+			if m := re.FindStringSubmatch(k); m != nil {
+				names = append(names, k)
+				groups[k] = m
+				break
+			}
+		}
+	}
+	// deterministic result when several fields are renamed to the same name
+	sort.Strings(names)
+	for _, k := range names {
+		v := src[k]
+		name := k
+		if f.rename != nil {
+			b := new(strings.Builder)
+			err := f.rename.Execute(b, &renameInput{Name: k, Value: v, Groups: groups[k], Tags: e.Tags})
+			if err != nil {
+				c.logger.Printf("failed to rename field %q: %v", k, err)
+				continue
+			}
+			name = b.String()
+			if name == "" {
+				c.logger.Printf("field %q renamed to an empty name, skipping", k)
+				continue
+			}
+		}
+		if f.Type != "" {
+			cv, err := convert(v, f.Type)
+			if err != nil {
+				c.logger.Printf("failed to convert field %q: %v", k, err)
+				continue
+			}
+			v = cv
+		}
+		if f.To == sectionTags {
+			if _, ok := e.Tags[name]; ok && f.KeepExisting {
+				continue
+			}
+			if e.Tags == nil {
+				e.Tags = make(map[string]string)
+			}
+			e.Tags[name] = toString(v)
+		} else {
+			if _, ok := e.Values[name]; ok && f.KeepExisting {
+				continue
+			}
+			if e.Values == nil {
+				e.Values = make(map[string]interface{})
+			}
+			e.Values[name] = v
+		}
+		c.logger.Printf("copied %s %q to %s %q", f.From, k, f.To, name)
+		if f.Move && (f.From != f.To || name != k) {
+			if f.From == sectionTags {
+				delete(e.Tags, k)
+			} else {
+				delete(e.Values, k)
+			}
+		}
+	}
+}
+
+func (c *copyFields) WithLogger(l *log.Logger) {
+	if c.Debug && l != nil {
+		c.logger = log.New(l.Writer(), loggingPrefix, l.Flags())
+	} else if c.Debug {
+		c.logger = log.New(os.Stderr, loggingPrefix, utils.DefaultLoggingFlags)
+	}
+}
+
+func (c *copyFields) WithTargets(tcs map[string]*types.TargetConfig) {}
+
+func (c *copyFields) WithActions(act map[string]map[string]interface{}) {}
+
+func (c *copyFields) WithProcessors(procs map[string]map[string]any) {}
+
+func toString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// convert converts v to the type typ,
+// floats are truncated when converted to integers.
+func convert(v interface{}, typ string) (interface{}, error) {
+	s := strings.TrimSpace(toString(v))
+	switch typ {
+	case "int":
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err == nil {
+			return i, nil
+		}
+		fv, ferr := strconv.ParseFloat(s, 64)
+		if ferr != nil {
+			return nil, err
+		}
+		return int64(fv), nil
+	case "uint":
+		u, err := strconv.ParseUint(s, 10, 64)
+		if err == nil {
+			return u, nil
+		}
+		fv, ferr := strconv.ParseFloat(s, 64)
+		if ferr != nil || fv < 0 {
+			return nil, err
+		}
+		return uint64(fv), nil
+	case "float":
+		return strconv.ParseFloat(s, 64)
+	case "bool":
+		return strconv.ParseBool(s)
+	default:
+		return s, nil
+	}
+}
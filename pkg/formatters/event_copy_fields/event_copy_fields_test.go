@@ -0,0 +1,194 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package event_copy_fields
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+)
+
+type item struct {
+	input  []*formatters.EventMsg
+	output []*formatters.EventMsg
+}
+
+var testset = map[string]struct {
+	processor map[string]interface{}
+	tests     []item
+}{
+	"value_to_tag_with_rename": {
+		processor: map[string]interface{}{
+			"fields": []interface{}{
+				map[string]interface{}{
+					"from":   "values",
+					"to":     "tags",
+					"names":  []string{"^/interface/(description)$"},
+					"rename": `if_{{ index .Groups 1 }}`,
+				},
+			},
+		},
+		tests: []item{
+			{
+				input:  nil,
+				output: nil,
+			},
+			{
+				input: []*formatters.EventMsg{
+					{
+						Values: map[string]interface{}{
+							"/interface/description": "uplink",
+							"/interface/mtu":         9212,
+						},
+					},
+				},
+				output: []*formatters.EventMsg{
+					{
+						Tags: map[string]string{"if_description": "uplink"},
+						Values: map[string]interface{}{
+							"/interface/description": "uplink",
+							"/interface/mtu":         9212,
+						},
+					},
+				},
+			},
+		},
+	},
+	"tag_to_value_move_with_type": {
+		processor: map[string]interface{}{
+			"fields": []interface{}{
+				map[string]interface{}{
+					"from":  "tags",
+					"to":    "values",
+					"names": []string{"^queue_id$"},
+					"type":  "int",
+					"move":  true,
+				},
+			},
+		},
+		tests: []item{
+			{
+				input: []*formatters.EventMsg{
+					{
+						Tags:   map[string]string{"queue_id": "3", "source": "r1"},
+						Values: map[string]interface{}{"dropped": 1},
+					},
+				},
+				output: []*formatters.EventMsg{
+					{
+						Tags:   map[string]string{"source": "r1"},
+						Values: map[string]interface{}{"dropped": 1, "queue_id": int64(3)},
+					},
+				},
+			},
+		},
+	},
+	"value_rename_in_place": {
+		processor: map[string]interface{}{
+			"fields": []interface{}{
+				map[string]interface{}{
+					"from":   "values",
+					"to":     "values",
+					"names":  []string{"^/interface/statistics/.*$"},
+					"rename": `{{ .Name | path.Base }}`,
+					"type":   "uint",
+					"move":   true,
+				},
+			},
+		},
+		tests: []item{
+			{
+				input: []*formatters.EventMsg{
+					{
+						Values: map[string]interface{}{
+							"/interface/statistics/in-octets": "42",
+							"/interface/oper-state":           "up",
+						},
+					},
+				},
+				output: []*formatters.EventMsg{
+					{
+						Values: map[string]interface{}{
+							"in-octets":             uint64(42),
+							"/interface/oper-state": "up",
+						},
+					},
+				},
+			},
+		},
+	},
+	"keep_existing": {
+		processor: map[string]interface{}{
+			"fields": []interface{}{
+				map[string]interface{}{
+					"from":          "values",
+					"to":            "tags",
+					"names":         []string{"^name$"},
+					"keep-existing": true,
+				},
+			},
+		},
+		tests: []item{
+			{
+				input: []*formatters.EventMsg{
+					{
+						Tags:   map[string]string{"name": "a"},
+						Values: map[string]interface{}{"name": "b"},
+					},
+				},
+				output: []*formatters.EventMsg{
+					{
+						Tags:   map[string]string{"name": "a"},
+						Values: map[string]interface{}{"name": "b"},
+					},
+				},
+			},
+		},
+	},
+}
+
+func TestEventCopyFields(t *testing.T) {
+	for name, ts := range testset {
+		p := formatters.EventProcessors[processorType]()
+		err := p.Init(ts.processor)
+		if err != nil {
+			t.Errorf("%s: failed to initialize processor: %v", name, err)
+			continue
+		}
+		for i, item := range ts.tests {
+			t.Run(name, func(t *testing.T) {
+				outs := p.Apply(item.input...)
+				for j := range outs {
+					if !reflect.DeepEqual(outs[j], item.output[j]) {
+						t.Logf("failed at %s, item %d, index %d", name, i, j)
+						t.Logf("expected: %#v", item.output[j])
+						t.Logf("     got: %#v", outs[j])
+						t.Fail()
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestEventCopyFieldsInitErrors(t *testing.T) {
+	for name, cfg := range map[string]map[string]interface{}{
+		"bad_section":  {"from": "meta", "to": "tags", "names": []string{"a"}},
+		"no_names":     {"from": "values", "to": "tags"},
+		"bad_type":     {"from": "tags", "to": "values", "names": []string{"a"}, "type": "date"},
+		"same_section": {"from": "tags", "to": "tags", "names": []string{"a"}},
+	} {
+		p := formatters.EventProcessors[processorType]()
+		err := p.Init(map[string]interface{}{"fields": []interface{}{cfg}})
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,460 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package event_dom_thresholds
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/api/utils"
+	"github.com/openconfig/gnmic/pkg/formatters"
+)
+
+const (
+	processorType = "event-dom-thresholds"
+	loggingPrefix = "[" + processorType + "] "
+
+	alarmEventName = "dom-alarm"
+
+	severityAlarm   = "alarm"
+	severityWarning = "warning"
+
+	directionHigh = "high"
+	directionLow  = "low"
+)
+
+// metrics evaluated by the processor.
+const (
+	metricRxPower     = "rx-power"
+	metricTxPower     = "tx-power"
+	metricLaserBias   = "laser-bias"
+	metricTemperature = "temperature"
+	metricVoltage     = "voltage"
+)
+
+// defaultValueNames match the OpenConfig transceiver DOM leaves,
+// against the value name without keys and module prefixes.
+var defaultValueNames = map[string][]string{
+	metricRxPower:     {`/transceiver/physical-channels/channel/state/input-power/instant$`},
+	metricTxPower:     {`/transceiver/physical-channels/channel/state/output-power/instant$`},
+	metricLaserBias:   {`/transceiver/physical-channels/channel/state/laser-bias-current/instant$`},
+	metricTemperature: {`^/components/component/state/temperature/instant$`},
+	metricVoltage:     {`/transceiver/state/supply-voltage/instant$`},
+}
+
+// thresholdLeaves maps the OpenConfig transceiver thresholds leaves prefixes to a metric.
+var thresholdLeaves = map[string]string{
+	"input-power":        metricRxPower,
+	"output-power":       metricTxPower,
+	"laser-bias-current": metricLaserBias,
+	"module-temperature": metricTemperature,
+	"supply-voltage":     metricVoltage,
+}
+
+var thresholdLeafRegex = regexp.MustCompile(`/transceiver/thresholds/threshold/state/([a-z-]+)-(upper|lower)$`)
+
+// domThresholds evaluates transceiver DOM values against thresholds
+// learned from the device or configured and emits alarm events on transitions.
+type domThresholds struct {
+	// static thresholds per metric, used for the levels the device did not report.
+	Thresholds map[string]*thresholds `mapstructure:"thresholds,omitempty" json:"thresholds,omitempty"`
+	// value names regexes per metric, replacing the OpenConfig defaults.
+	ValueNames map[string][]string `mapstructure:"value-names,omitempty" json:"value-names,omitempty"`
+	// learn the thresholds from the OpenConfig transceiver thresholds leaves.
+	Learn *bool `mapstructure:"learn,omitempty" json:"learn,omitempty"`
+	// tags identifying the component and the channel.
+	ComponentTag string `mapstructure:"component-tag,omitempty" json:"component-tag,omitempty"`
+	ChannelTag   string `mapstructure:"channel-tag,omitempty" json:"channel-tag,omitempty"`
+	// drop the events the alarms were computed from.
+	DropMeasurements bool `mapstructure:"drop-measurements,omitempty" json:"drop-measurements,omitempty"`
+	Debug            bool `mapstructure:"debug,omitempty" json:"debug,omitempty"`
+
+	valueNames map[string][]*regexp.Regexp
+
+	m sync.Mutex
+	// learned thresholds indexed by source/component, metric and device severity.
+	learned map[string]map[string]map[string]*thresholds
+	// components a transceiver value or threshold was received for.
+	transceivers map[string]struct{}
+	// raised alarms indexed by source/component/channel/metric.
+	alarms map[string]*alarm
+
+	logger *log.Logger
+}
+
+type thresholds struct {
+	HighAlarm   *float64 `mapstructure:"high-alarm,omitempty" json:"high-alarm,omitempty"`
+	HighWarning *float64 `mapstructure:"high-warning,omitempty" json:"high-warning,omitempty"`
+	LowWarning  *float64 `mapstructure:"low-warning,omitempty" json:"low-warning,omitempty"`
+	LowAlarm    *float64 `mapstructure:"low-alarm,omitempty" json:"low-alarm,omitempty"`
+}
+
+type alarm struct {
+	severity  string
+	direction string
+}
+
+func init() {
+	formatters.Register(processorType, func() formatters.EventProcessor {
+		return &domThresholds{
+			logger: log.New(io.Discard, "", 0),
+		}
+	})
+}
+
+func (p *domThresholds) Init(cfg interface{}, opts ...formatters.Option) error {
+	err := formatters.DecodeConfig(cfg, p)
+	if err != nil {
+		return err
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.Learn == nil {
+		learn := true
+		p.Learn = &learn
+	}
+	if p.ComponentTag == "" {
+		p.ComponentTag = "component_name"
+	}
+	if p.ChannelTag == "" {
+		p.ChannelTag = "channel_index"
+	}
+	for m, th := range p.Thresholds {
+		if _, ok := defaultValueNames[m]; !ok {
+			return fmt.Errorf("unknown metric %q", m)
+		}
+		if th == nil {
+			continue
+		}
+		if th.HighWarning != nil && th.HighAlarm != nil && *th.HighWarning > *th.HighAlarm {
+			return fmt.Errorf("metric %q: high-warning is above high-alarm", m)
+		}
+		if th.LowWarning != nil && th.LowAlarm != nil && *th.LowWarning < *th.LowAlarm {
+			return fmt.Errorf("metric %q: low-warning is below low-alarm", m)
+		}
+	}
+	p.valueNames = make(map[string][]*regexp.Regexp, len(defaultValueNames))
+	for m, regs := range defaultValueNames {
+		if custom, ok := p.ValueNames[m]; ok {
+			regs = custom
+		}
+		for _, reg := range regs {
+			re, err := regexp.Compile(reg)
+			if err != nil {
+				return fmt.Errorf("metric %q: %v", m, err)
+			}
+			p.valueNames[m] = append(p.valueNames[m], re)
+		}
+	}
+	for m := range p.ValueNames {
+		if _, ok := defaultValueNames[m]; !ok {
+			return fmt.Errorf("unknown metric %q", m)
+		}
+	}
+	p.learned = make(map[string]map[string]map[string]*thresholds)
+	p.transceivers = make(map[string]struct{})
+	p.alarms = make(map[string]*alarm)
+	if p.logger.Writer() != io.Discard {
+		b, err := json.Marshal(p)
+		if err != nil {
+			p.logger.Printf("initialized processor '%s': %+v", processorType, p)
+			return nil
+		}
+		p.logger.Printf("initialized processor '%s': %s", processorType, string(b))
+	}
+	return nil
+}
+
+func (p *domThresholds) Apply(es ...*formatters.EventMsg) []*formatters.EventMsg {
+	p.m.Lock()
+	defer p.m.Unlock()
+	// learn the thresholds first, a response can carry both
+	// the thresholds and the values.
+	if *p.Learn {
+		for _, e := range es {
+			if e == nil {
+				continue
+			}
+			p.learn(e)
+		}
+	}
+	res := make([]*formatters.EventMsg, 0, len(es))
+	for _, e := range es {
+		if e == nil {
+			continue
+		}
+		alarms, measured := p.evaluate(e)
+		if !measured || !p.DropMeasurements {
+			res = append(res, e)
+		}
+		res = append(res, alarms...)
+	}
+	return res
+}
+
+func (p *domThresholds) WithLogger(l *log.Logger) {
+	if p.Debug && l != nil {
+		p.logger = log.New(l.Writer(), loggingPrefix, l.Flags())
+	} else if p.Debug {
+		p.logger = log.New(os.Stderr, loggingPrefix, utils.DefaultLoggingFlags)
+	}
+}
+
+func (p *domThresholds) WithTargets(tcs map[string]*types.TargetConfig) {}
+
+func (p *domThresholds) WithActions(act map[string]map[string]interface{}) {}
+
+func (p *domThresholds) WithProcessors(procs map[string]map[string]any) {}
+
+// learn stores the thresholds leaves of event e.
+func (p *domThresholds) learn(e *formatters.EventMsg) {
+	severity := strings.ToUpper(stripPrefix(e.Tags["threshold_severity"]))
+	if severity == "" {
+		return
+	}
+	comp := p.componentKey(e)
+	for k, v := range e.Values {
+		sm := thresholdLeafRegex.FindStringSubmatch(formatters.SchemaPath(k))
+		if sm == nil {
+			continue
+		}
+		metric, ok := thresholdLeaves[sm[1]]
+		if !ok {
+			continue
+		}
+		f, err := toFloat64(v)
+		if err != nil {
+			p.logger.Printf("value %q: %v", k, err)
+			continue
+		}
+		if p.learned[comp] == nil {
+			p.learned[comp] = make(map[string]map[string]*thresholds)
+		}
+		if p.learned[comp][metric] == nil {
+			p.learned[comp][metric] = make(map[string]*thresholds)
+		}
+		th := p.learned[comp][metric][severity]
+		if th == nil {
+			th = new(thresholds)
+			p.learned[comp][metric][severity] = th
+		}
+		switch sm[2] {
+		case "upper":
+			th.HighAlarm = &f
+		case "lower":
+			th.LowAlarm = &f
+		}
+		p.transceivers[comp] = struct{}{}
+		p.logger.Printf("learned %s %s %s threshold %s=%v", comp, metric, severity, sm[2], f)
+	}
+}
+
+// evaluate compares the DOM values of event e to their thresholds
+// and returns the alarm events of the transitions.
+// It also reports whether the event carries DOM values.
+func (p *domThresholds) evaluate(e *formatters.EventMsg) ([]*formatters.EventMsg, bool) {
+	comp := p.componentKey(e)
+	measured := false
+	var res []*formatters.EventMsg
+	// sort the value names for a deterministic alarms order.
+	names := make([]string, 0, len(e.Values))
+	for k := range e.Values {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		metric := p.metric(k)
+		if metric == "" {
+			continue
+		}
+		if metric != metricTemperature {
+			p.transceivers[comp] = struct{}{}
+		} else if _, ok := p.transceivers[comp]; !ok {
+			// the temperature leaf is common to all components,
+			// only evaluate it for the transceivers.
+			continue
+		}
+		measured = true
+		v, err := toFloat64(e.Values[k])
+		if err != nil {
+			p.logger.Printf("value %q: %v", k, err)
+			continue
+		}
+		th := p.thresholds(comp, metric)
+		if th == nil {
+			continue
+		}
+		severity, direction, limit := th.level(v)
+		key := comp + "/" + e.Tags[p.ChannelTag] + "/" + metric
+		prev := p.alarms[key]
+		switch {
+		case severity == "" && prev == nil:
+			continue
+		case severity == "":
+			delete(p.alarms, key)
+			res = append(res, p.alarmEvent(e, metric, prev.severity, prev.direction, v, nil, false))
+		case prev != nil && prev.severity == severity && prev.direction == direction:
+			continue
+		default:
+			p.alarms[key] = &alarm{severity: severity, direction: direction}
+			res = append(res, p.alarmEvent(e, metric, severity, direction, v, &limit, true))
+		}
+	}
+	return res, measured
+}
+
+func (p *domThresholds) metric(name string) string {
+	sp := formatters.SchemaPath(name)
+	for m, res := range p.valueNames {
+		for _, re := range res {
+			if re.MatchString(sp) {
+				return m
+			}
+		}
+	}
+	return ""
+}
+
+// thresholds returns the thresholds of the metric for a component,
+// the learned levels take precedence over the configured ones.
+func (p *domThresholds) thresholds(comp, metric string) *thresholds {
+	th := new(thresholds)
+	if cfg := p.Thresholds[metric]; cfg != nil {
+		*th = *cfg
+	}
+	if learned := p.learned[comp][metric]; learned != nil {
+		// the device alarm levels are MAJOR or CRITICAL,
+		// its warning levels are WARNING or MINOR.
+		if lt := firstOf(learned, "MAJOR", "CRITICAL"); lt != nil {
+			th.HighAlarm = pick(lt.HighAlarm, th.HighAlarm)
+			th.LowAlarm = pick(lt.LowAlarm, th.LowAlarm)
+		}
+		if lt := firstOf(learned, "WARNING", "MINOR"); lt != nil {
+			th.HighWarning = pick(lt.HighAlarm, th.HighWarning)
+			th.LowWarning = pick(lt.LowAlarm, th.LowWarning)
+		}
+	}
+	if th.HighAlarm == nil && th.HighWarning == nil && th.LowWarning == nil && th.LowAlarm == nil {
+		return nil
+	}
+	return th
+}
+
+// level returns the most severe threshold crossed by v.
+func (th *thresholds) level(v float64) (string, string, float64) {
+	switch {
+	case th.HighAlarm != nil && v >= *th.HighAlarm:
+		return severityAlarm, directionHigh, *th.HighAlarm
+	case th.LowAlarm != nil && v <= *th.LowAlarm:
+		return severityAlarm, directionLow, *th.LowAlarm
+	case th.HighWarning != nil && v >= *th.HighWarning:
+		return severityWarning, directionHigh, *th.HighWarning
+	case th.LowWarning != nil && v <= *th.LowWarning:
+		return severityWarning, directionLow, *th.LowWarning
+	}
+	return "", "", 0
+}
+
+func (p *domThresholds) alarmEvent(e *formatters.EventMsg, metric, severity, direction string, v float64, limit *float64, active bool) *formatters.EventMsg {
+	ev := &formatters.EventMsg{
+		Name:      alarmEventName,
+		Timestamp: e.Timestamp,
+		Tags: map[string]string{
+			"metric":    metric,
+			"severity":  severity,
+			"direction": direction,
+		},
+		Values: map[string]interface{}{
+			"value":  v,
+			"active": active,
+		},
+	}
+	for _, t := range []string{"source", "subscription-name", p.ComponentTag, p.ChannelTag} {
+		if tv, ok := e.Tags[t]; ok {
+			ev.Tags[t] = tv
+		}
+	}
+	if limit != nil {
+		ev.Values["threshold"] = *limit
+	}
+	p.logger.Printf("%s %s %s alarm on %s: active=%v, value=%v", severity, direction, metric, ev.Tags[p.ComponentTag], active, v)
+	return ev
+}
+
+func (p *domThresholds) componentKey(e *formatters.EventMsg) string {
+	return e.Tags["source"] + "/" + e.Tags[p.ComponentTag]
+}
+
+func firstOf(m map[string]*thresholds, keys ...string) *thresholds {
+	for _, k := range keys {
+		if th, ok := m[k]; ok {
+			return th
+		}
+	}
+	return nil
+}
+
+func pick(a, b *float64) *float64 {
+	if a != nil {
+		return a
+	}
+	return b
+}
+
+// stripPrefix removes the YANG module prefix of an identityref value.
+func stripPrefix(s string) string {
+	if idx := strings.LastIndex(s, ":"); idx >= 0 {
+		return s[idx+1:]
+	}
+	return s
+}
+
+func toFloat64(v any) (float64, error) {
+	switch v := v.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int8:
+		return float64(v), nil
+	case int16:
+		return float64(v), nil
+	case int32:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case uint:
+		return float64(v), nil
+	case uint8:
+		return float64(v), nil
+	case uint16:
+		return float64(v), nil
+	case uint32:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case json.Number:
+		return v.Float64()
+	case string:
+		return strconv.ParseFloat(strings.TrimSpace(v), 64)
+	}
+	return 0, fmt.Errorf("cannot convert %T to a number", v)
+}
@@ -0,0 +1,198 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package event_dom_thresholds
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+)
+
+const (
+	rxPowerPath     = "/openconfig-platform:components/component/openconfig-platform-transceiver:transceiver/physical-channels/channel/state/input-power/instant"
+	temperaturePath = "/components/component/state/temperature/instant"
+	thresholdPath   = "/components/component/transceiver/thresholds/threshold/state/"
+)
+
+func rxPower(comp string, v any) *formatters.EventMsg {
+	return &formatters.EventMsg{
+		Name:      "optics",
+		Timestamp: 1,
+		Tags: map[string]string{
+			"source":         "r1",
+			"component_name": comp,
+			"channel_index":  "0",
+		},
+		Values: map[string]interface{}{rxPowerPath: v},
+	}
+}
+
+func alarmStates(es []*formatters.EventMsg) []string {
+	var res []string
+	for _, e := range es {
+		if e.Name != alarmEventName {
+			continue
+		}
+		state := "cleared"
+		if e.Values["active"].(bool) {
+			state = "raised"
+		}
+		res = append(res, e.Tags["metric"]+" "+e.Tags["severity"]+" "+e.Tags["direction"]+" "+state)
+	}
+	return res
+}
+
+func newProcessor(t *testing.T, cfg map[string]interface{}) formatters.EventProcessor {
+	p := formatters.EventProcessors[processorType]()
+	err := p.Init(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestConfiguredThresholds(t *testing.T) {
+	p := newProcessor(t, map[string]interface{}{
+		"thresholds": map[string]interface{}{
+			"rx-power": map[string]interface{}{
+				"high-alarm":   2.0,
+				"low-warning":  -12.0,
+				"low-alarm":    -14.0,
+				"high-warning": 0.0,
+			},
+		},
+	})
+	steps := []struct {
+		value any
+		want  []string
+	}{
+		{value: -5.0},
+		{value: "-12.5", want: []string{"rx-power warning low raised"}},
+		{value: -13.0},
+		{value: -20.0, want: []string{"rx-power alarm low raised"}},
+		{value: 3.0, want: []string{"rx-power alarm high raised"}},
+		{value: -1.0, want: []string{"rx-power alarm high cleared"}},
+	}
+	for i, s := range steps {
+		got := p.Apply(rxPower("port-1", s.value))
+		if !reflect.DeepEqual(alarmStates(got), s.want) {
+			t.Errorf("step %d: got %v, want %v", i, alarmStates(got), s.want)
+		}
+		if got[0].Name != "optics" {
+			t.Errorf("step %d: measurement event not kept", i)
+		}
+	}
+}
+
+func TestLearnedThresholds(t *testing.T) {
+	p := newProcessor(t, map[string]interface{}{
+		"thresholds": map[string]interface{}{
+			"rx-power": map[string]interface{}{
+				"low-alarm":  -30.0,
+				"high-alarm": 10.0,
+			},
+			"temperature": map[string]interface{}{
+				"high-alarm": 70.0,
+			},
+		},
+	})
+	p.Apply(&formatters.EventMsg{
+		Name: "optics",
+		Tags: map[string]string{
+			"source":             "r1",
+			"component_name":     "port-1",
+			"threshold_severity": "openconfig-alarm-types:MAJOR",
+		},
+		Values: map[string]interface{}{
+			thresholdPath + "input-power-lower":        "-10.5",
+			thresholdPath + "module-temperature-upper": 75.0,
+		},
+	})
+	// the learned lower level replaces the configured one,
+	// the configured upper level is kept.
+	got := alarmStates(p.Apply(rxPower("port-1", -11.0)))
+	if !reflect.DeepEqual(got, []string{"rx-power alarm low raised"}) {
+		t.Errorf("unexpected alarms: %v", got)
+	}
+	got = alarmStates(p.Apply(rxPower("port-2", -11.0)))
+	if len(got) != 0 {
+		t.Errorf("unexpected alarms for a component without learned thresholds: %v", got)
+	}
+	temp := func(comp string) *formatters.EventMsg {
+		return &formatters.EventMsg{
+			Name:   "optics",
+			Tags:   map[string]string{"source": "r1", "component_name": comp},
+			Values: map[string]interface{}{temperaturePath: 72.0},
+		}
+	}
+	got = alarmStates(p.Apply(temp("port-1")))
+	if len(got) != 0 {
+		t.Errorf("unexpected temperature alarms: %v", got)
+	}
+	// not a transceiver.
+	got = alarmStates(p.Apply(temp("cpu-0")))
+	if len(got) != 0 {
+		t.Errorf("unexpected alarms for a non transceiver component: %v", got)
+	}
+}
+
+func TestDropMeasurements(t *testing.T) {
+	p := newProcessor(t, map[string]interface{}{
+		"drop-measurements": true,
+		"thresholds": map[string]interface{}{
+			"rx-power": map[string]interface{}{"low-alarm": -14.0},
+		},
+	})
+	other := &formatters.EventMsg{
+		Name:   "interfaces",
+		Values: map[string]interface{}{"/interfaces/interface/state/oper-status": "UP"},
+	}
+	got := p.Apply(rxPower("port-1", -15.0), other)
+	if len(got) != 2 || got[0].Name != alarmEventName || got[1] != other {
+		t.Fatalf("unexpected events: %v", got)
+	}
+	want := map[string]string{
+		"source":         "r1",
+		"component_name": "port-1",
+		"channel_index":  "0",
+		"metric":         "rx-power",
+		"severity":       "alarm",
+		"direction":      "low",
+	}
+	if !reflect.DeepEqual(got[0].Tags, want) {
+		t.Errorf("unexpected alarm tags: %v", got[0].Tags)
+	}
+	if got[0].Values["threshold"] != -14.0 || got[0].Values["value"] != -15.0 {
+		t.Errorf("unexpected alarm values: %v", got[0].Values)
+	}
+}
+
+func TestInitErrors(t *testing.T) {
+	for name, cfg := range map[string]map[string]interface{}{
+		"unknown_metric": {
+			"thresholds": map[string]interface{}{"snr": map[string]interface{}{"low-alarm": 1.0}},
+		},
+		"inverted_levels": {
+			"thresholds": map[string]interface{}{
+				"tx-power": map[string]interface{}{"high-alarm": 1.0, "high-warning": 2.0},
+			},
+		},
+		"bad_regex": {
+			"value-names": map[string]interface{}{"rx-power": []string{"("}},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := formatters.EventProcessors[processorType]()
+			if err := p.Init(cfg); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -62,11 +62,7 @@ func (d *drop) Init(cfg interface{}, opts ...formatters.Option) error {
 		opt(d)
 	}
 	d.Condition = strings.TrimSpace(d.Condition)
-	q, err := gojq.Parse(d.Condition)
-	if err != nil {
-		return err
-	}
-	d.code, err = gojq.Compile(q)
+	d.code, err = formatters.CompileJQ(d.Condition)
 	if err != nil {
 		return err
 	}
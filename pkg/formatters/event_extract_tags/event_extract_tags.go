@@ -25,14 +25,17 @@ const (
 	loggingPrefix = "[" + processorType + "] "
 )
 
-// extractTags extracts tags from a value, a value name, a tag name or a tag value using regex named groups
+// extractTags extracts tags from a value, a value name, a tag name or a tag value using regex named groups,
+// optionally removing the matched portion from the source string.
 type extractTags struct {
 	Tags       []string `mapstructure:"tags,omitempty" json:"tags,omitempty"`
 	Values     []string `mapstructure:"values,omitempty" json:"values,omitempty"`
 	TagNames   []string `mapstructure:"tag-names,omitempty" json:"tag-names,omitempty"`
 	ValueNames []string `mapstructure:"value-names,omitempty" json:"value-names,omitempty"`
 	Overwrite  bool     `mapstructure:"overwrite,omitempty" json:"overwrite,omitempty"`
-	Debug      bool     `mapstructure:"debug,omitempty" json:"debug,omitempty"`
+	// remove the matched portion of the value, value name, tag name or tag value
+	RemoveMatch bool `mapstructure:"remove-match,omitempty" json:"remove-match,omitempty"`
+	Debug       bool `mapstructure:"debug,omitempty" json:"debug,omitempty"`
 
 	tags       []*regexp.Regexp
 	values     []*regexp.Regexp
@@ -110,22 +113,57 @@ func (p *extractTags) Apply(es ...*formatters.EventMsg) []*formatters.EventMsg {
 		if e == nil {
 			continue
 		}
+		// value names renamed after removing their matched portion
+		var renames map[string]string
 		for k, v := range e.Values {
+			name := k
 			for _, re := range p.valueNames {
-				p.addTags(e, re, k)
+				name = p.addTags(e, re, name)
 			}
-			for _, re := range p.values {
-				if vs, ok := v.(string); ok {
-					p.addTags(e, re, vs)
+			if vs, ok := v.(string); ok {
+				nvs := vs
+				for _, re := range p.values {
+					nvs = p.addTags(e, re, nvs)
 				}
+				if nvs != vs {
+					e.Values[k] = nvs
+				}
+			}
+			if name != k && name != "" {
+				if renames == nil {
+					renames = make(map[string]string)
+				}
+				renames[k] = name
 			}
 		}
+		for k, name := range renames {
+			e.Values[name] = e.Values[k]
+			delete(e.Values, k)
+		}
+		// the tags are processed as they were before the extraction,
+		// the extracted tags are not matched again.
+		tags := make(map[string]string, len(e.Tags))
 		for k, v := range e.Tags {
+			tags[k] = v
+		}
+		for k, v := range tags {
+			name := k
 			for _, re := range p.tagNames {
-				p.addTags(e, re, k)
+				name = p.addTags(e, re, name)
 			}
+			nv := v
 			for _, re := range p.tags {
-				p.addTags(e, re, v)
+				nv = p.addTags(e, re, nv)
+			}
+			if name != k && name != "" {
+				if e.Tags[k] == v {
+					delete(e.Tags, k)
+				}
+				e.Tags[name] = nv
+				continue
+			}
+			if nv != v {
+				e.Tags[k] = nv
 			}
 		}
 	}
@@ -146,30 +184,35 @@ func (p *extractTags) WithActions(act map[string]map[string]interface{}) {}
 
 func (p *extractTags) WithProcessors(procs map[string]map[string]any) {}
 
-func (p *extractTags) addTags(e *formatters.EventMsg, re *regexp.Regexp, s string) {
-	if e.Tags == nil {
-		e.Tags = make(map[string]string)
-	}
-
-	matches := re.FindStringSubmatch(s)
+// addTags adds a tag per named group of re matching s.
+// Groups that did not participate in the match are skipped.
+// It returns s without the matched portion if RemoveMatch is set, s otherwise.
+func (p *extractTags) addTags(e *formatters.EventMsg, re *regexp.Regexp, s string) string {
+	loc := re.FindStringSubmatchIndex(s)
 	if p.Debug {
-		p.logger.Printf("matches: %+v", matches)
+		p.logger.Printf("matches: %+v", loc)
 	}
-	if len(matches) != len(re.SubexpNames()) {
-		return
+	if loc == nil {
+		return s
+	}
+	if e.Tags == nil {
+		e.Tags = make(map[string]string)
 	}
 	for i, name := range re.SubexpNames() {
-		if i != 0 && name != "" {
-			if p.Debug {
-				p.logger.Printf("adding: name=%s, value=%s", name, matches[i])
-			}
-			if p.Overwrite {
-				e.Tags[name] = matches[i]
-				continue
-			}
-			if _, ok := e.Tags[matches[i]]; !ok {
-				e.Tags[name] = matches[i]
-			}
+		if i == 0 || name == "" || loc[2*i] < 0 {
+			continue
+		}
+		value := s[loc[2*i]:loc[2*i+1]]
+		if p.Debug {
+			p.logger.Printf("adding: name=%s, value=%s", name, value)
 		}
+		if _, ok := e.Tags[name]; ok && !p.Overwrite {
+			continue
+		}
+		e.Tags[name] = value
+	}
+	if !p.RemoveMatch {
+		return s
 	}
+	return s[:loc[0]] + s[loc[1]:]
 }
@@ -179,6 +179,78 @@ var testset = map[string]struct {
 			},
 		},
 	},
+	"match_tag_values_optional_group": {
+		processorType: processorType,
+		processor: map[string]interface{}{
+			"tags": []string{
+				`^ethernet-(?P<slot>\d+)/(?P<port>\d+)(/(?P<channel>\d+))?$`,
+			},
+		},
+		tests: []item{
+			{
+				input: []*formatters.EventMsg{
+					{
+						Tags: map[string]string{"interface_name": "ethernet-1/2/3"},
+					},
+					{
+						Tags: map[string]string{"interface_name": "ethernet-1/4"},
+					},
+				},
+				output: []*formatters.EventMsg{
+					{
+						Tags: map[string]string{
+							"interface_name": "ethernet-1/2/3",
+							"slot":           "1",
+							"port":           "2",
+							"channel":        "3",
+						},
+					},
+					{
+						Tags: map[string]string{
+							"interface_name": "ethernet-1/4",
+							"slot":           "1",
+							"port":           "4",
+						},
+					},
+				},
+			},
+		},
+	},
+	"remove_match": {
+		processorType: processorType,
+		processor: map[string]interface{}{
+			"remove-match": true,
+			"value-names": []string{
+				`^/interface\[name=(?P<interface_name>[^\]]+)\]`,
+			},
+			"values": []string{
+				` \(code (?P<code>\d+)\)$`,
+			},
+		},
+		tests: []item{
+			{
+				input: []*formatters.EventMsg{
+					{
+						Tags: map[string]string{"interface_name": "mgmt0"},
+						Values: map[string]interface{}{
+							"/interface[name=ethernet-1/1]/oper-down-reason": "port-admin-disabled (code 4)",
+						},
+					},
+				},
+				output: []*formatters.EventMsg{
+					{
+						Tags: map[string]string{
+							"interface_name": "mgmt0",
+							"code":           "4",
+						},
+						Values: map[string]interface{}{
+							"/oper-down-reason": "port-admin-disabled",
+						},
+					},
+				},
+			},
+		},
+	},
 }
 
 func TestEventAddTag(t *testing.T) {
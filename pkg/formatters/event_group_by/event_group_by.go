@@ -10,11 +10,15 @@ package event_group_by
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"log"
 	"os"
+	"regexp"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/openconfig/gnmic/pkg/api/types"
 	"github.com/openconfig/gnmic/pkg/api/utils"
@@ -24,6 +28,8 @@ import (
 const (
 	processorType = "event-group-by"
 	loggingPrefix = "[" + processorType + "] "
+
+	defaultTimeout = 30 * time.Second
 )
 
 // groupBy groups values from different event messages in the same event message
@@ -32,14 +38,41 @@ type groupBy struct {
 	Tags   []string `mapstructure:"tags,omitempty" json:"tags,omitempty"`
 	ByName bool     `mapstructure:"by-name,omitempty" json:"by-name,omitempty"`
 	Debug  bool     `mapstructure:"debug,omitempty" json:"debug,omitempty"`
+	// the following fields buffer the groups across the processor runs
+	// until one of the emission triggers fires.
+	// regular expressions, the group is complete once each of them matched a value name.
+	ExpectedValues []string `mapstructure:"expected-values,omitempty" json:"expected-values,omitempty"`
+	// number of values after which the group is emitted.
+	Size int `mapstructure:"size,omitempty" json:"size,omitempty"`
+	// regular expression, the group is emitted when a value with a matching name is received.
+	Marker string `mapstructure:"marker,omitempty" json:"marker,omitempty"`
+	// maximum time a group is buffered.
+	Timeout time.Duration `mapstructure:"timeout,omitempty" json:"timeout,omitempty"`
+	// drop the groups reaching the timeout instead of emitting them.
+	DropIncomplete bool `mapstructure:"drop-incomplete,omitempty" json:"drop-incomplete,omitempty"`
+
+	expected []*regexp.Regexp
+	marker   *regexp.Regexp
+
+	m       sync.Mutex
+	pending map[string]*pendingGroup
+	now     func() time.Time
 
 	logger *log.Logger
 }
 
+type pendingGroup struct {
+	ev      *formatters.EventMsg
+	created time.Time
+	// expected values regexes matched so far.
+	matched []bool
+}
+
 func init() {
 	formatters.Register(processorType, func() formatters.EventProcessor {
 		return &groupBy{
 			logger: log.New(io.Discard, "", 0),
+			now:    time.Now,
 		}
 	})
 }
@@ -52,7 +85,31 @@ func (p *groupBy) Init(cfg interface{}, opts ...formatters.Option) error {
 	for _, opt := range opts {
 		opt(p)
 	}
-
+	for _, reg := range p.ExpectedValues {
+		re, err := regexp.Compile(reg)
+		if err != nil {
+			return fmt.Errorf("expected-values: %v", err)
+		}
+		p.expected = append(p.expected, re)
+	}
+	if p.Marker != "" {
+		p.marker, err = regexp.Compile(p.Marker)
+		if err != nil {
+			return fmt.Errorf("marker: %v", err)
+		}
+	}
+	if p.Size < 0 {
+		return fmt.Errorf("invalid size %d", p.Size)
+	}
+	if p.buffered() {
+		if len(p.Tags) == 0 {
+			return fmt.Errorf("buffered grouping requires tags")
+		}
+		if p.Timeout <= 0 {
+			p.Timeout = defaultTimeout
+		}
+		p.pending = make(map[string]*pendingGroup)
+	}
 	if p.logger.Writer() != io.Discard {
 		b, err := json.Marshal(p)
 		if err != nil {
@@ -65,6 +122,9 @@ func (p *groupBy) Init(cfg interface{}, opts ...formatters.Option) error {
 }
 
 func (p *groupBy) Apply(es ...*formatters.EventMsg) []*formatters.EventMsg {
+	if p.buffered() {
+		return p.applyBuffered(es)
+	}
 	result := make([]*formatters.EventMsg, 0, len(es))
 	if p.Debug {
 		p.logger.Printf("before: %+v", es)
@@ -162,3 +222,145 @@ func (p *groupBy) byTags(es []*formatters.EventMsg) []*formatters.EventMsg {
 	}
 	return result
 }
+
+// buffered reports whether the groups are kept across the processor runs.
+func (p *groupBy) buffered() bool {
+	return len(p.ExpectedValues) > 0 || p.Size > 0 || p.Marker != "" || p.Timeout > 0
+}
+
+// applyBuffered adds the events to their pending group and returns the events
+// without the group tags, the groups whose emission trigger fired
+// and the groups that reached the timeout.
+func (p *groupBy) applyBuffered(es []*formatters.EventMsg) []*formatters.EventMsg {
+	p.m.Lock()
+	defer p.m.Unlock()
+	now := p.now()
+	result := make([]*formatters.EventMsg, 0, len(es))
+	emitted := make(map[string]*formatters.EventMsg)
+	for _, e := range es {
+		if e == nil || e.Tags == nil || e.Values == nil {
+			continue
+		}
+		key, ok := p.groupKey(e)
+		if !ok {
+			result = append(result, e)
+			continue
+		}
+		g, ok := p.pending[key]
+		if !ok {
+			g = &pendingGroup{
+				ev: &formatters.EventMsg{
+					Name:   e.Name,
+					Tags:   make(map[string]string),
+					Values: make(map[string]interface{}),
+				},
+				created: now,
+				matched: make([]bool, len(p.expected)),
+			}
+			p.pending[key] = g
+		}
+		if e.Timestamp > g.ev.Timestamp {
+			g.ev.Timestamp = e.Timestamp
+		}
+		for k, v := range e.Tags {
+			g.ev.Tags[k] = v
+		}
+		marked := false
+		for k, v := range e.Values {
+			g.ev.Values[k] = v
+			for i, re := range p.expected {
+				if !g.matched[i] && re.MatchString(k) {
+					g.matched[i] = true
+				}
+			}
+			if p.marker != nil && p.marker.MatchString(k) {
+				marked = true
+			}
+		}
+		g.ev.Deletes = append(g.ev.Deletes, e.Deletes...)
+		if marked || g.complete() || (p.Size > 0 && len(g.ev.Values) >= p.Size) {
+			delete(p.pending, key)
+			emitted[key] = g.ev
+		}
+	}
+	for key, g := range p.pending {
+		if now.Sub(g.created) < p.Timeout {
+			continue
+		}
+		delete(p.pending, key)
+		if p.DropIncomplete {
+			if p.Debug {
+				p.logger.Printf("dropping incomplete group %q", key)
+			}
+			continue
+		}
+		emitted[key] = g.ev
+	}
+	keys := make([]string, 0, len(emitted))
+	for k := range emitted {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		result = append(result, emitted[k])
+	}
+	if p.Debug {
+		p.logger.Printf("emitted %d group(s), %d pending", len(emitted), len(p.pending))
+	}
+	return result
+}
+
+// Flush returns the pending groups, unless drop-incomplete is set.
+func (p *groupBy) Flush() []*formatters.EventMsg {
+	if !p.buffered() {
+		return nil
+	}
+	p.m.Lock()
+	defer p.m.Unlock()
+	keys := make([]string, 0, len(p.pending))
+	for k := range p.pending {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	result := make([]*formatters.EventMsg, 0, len(keys))
+	for _, k := range keys {
+		if !p.DropIncomplete {
+			result = append(result, p.pending[k].ev)
+		}
+		delete(p.pending, k)
+	}
+	if p.Debug {
+		p.logger.Printf("flushed %d pending group(s)", len(keys))
+	}
+	return result
+}
+
+func (p *groupBy) groupKey(e *formatters.EventMsg) (string, bool) {
+	var key strings.Builder
+	if p.ByName {
+		key.WriteString(e.Name)
+	}
+	for _, t := range p.Tags {
+		v, ok := e.Tags[t]
+		if !ok {
+			return "", false
+		}
+		key.WriteString("\x00")
+		key.WriteString(v)
+	}
+	return key.String(), true
+}
+
+// complete reports whether all the expected values were received,
+// a group without expected values is never complete.
+func (g *pendingGroup) complete() bool {
+	if len(g.matched) == 0 {
+		return false
+	}
+	for _, m := range g.matched {
+		if !m {
+			return false
+		}
+	}
+	return true
+}
@@ -11,6 +11,7 @@ package event_group_by
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/openconfig/gnmic/pkg/formatters"
 )
@@ -431,3 +432,124 @@ func TestEventGroupBy(t *testing.T) {
 		}
 	}
 }
+
+func rowEvent(ts int64, intf string, values map[string]interface{}) *formatters.EventMsg {
+	return &formatters.EventMsg{
+		Name:      "sub1",
+		Timestamp: ts,
+		Tags:      map[string]string{"source": "r1", "interface_name": intf},
+		Values:    values,
+	}
+}
+
+func newBufferedGroupBy(t *testing.T, cfg map[string]interface{}) (*groupBy, *time.Time) {
+	p := formatters.EventProcessors[processorType]().(*groupBy)
+	now := time.Unix(0, 0)
+	p.now = func() time.Time { return now }
+	err := p.Init(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p, &now
+}
+
+func TestEventGroupByBuffered(t *testing.T) {
+	p, now := newBufferedGroupBy(t, map[string]interface{}{
+		"tags":            []string{"source", "interface_name"},
+		"expected-values": []string{"oper-status$", "admin-status$"},
+		"timeout":         "10s",
+	})
+	other := &formatters.EventMsg{Name: "sub1", Tags: map[string]string{"source": "r1"}, Values: map[string]interface{}{"cpu": 1}}
+	got := p.Apply(rowEvent(1, "e1", map[string]interface{}{"/interface/oper-status": "UP"}), other)
+	if len(got) != 1 || got[0] != other {
+		t.Fatalf("expected only the event without the group tags, got %v", got)
+	}
+	got = p.Apply(
+		rowEvent(2, "e2", map[string]interface{}{"/interface/oper-status": "DOWN"}),
+		rowEvent(3, "e1", map[string]interface{}{"/interface/admin-status": "ENABLE"}),
+	)
+	want := []*formatters.EventMsg{
+		{
+			Name:      "sub1",
+			Timestamp: 3,
+			Tags:      map[string]string{"source": "r1", "interface_name": "e1"},
+			Values: map[string]interface{}{
+				"/interface/oper-status":  "UP",
+				"/interface/admin-status": "ENABLE",
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected events:\n got: %+v\nwant: %+v", got, want)
+	}
+	// the incomplete group is emitted on timeout.
+	*now = now.Add(11 * time.Second)
+	got = p.Apply()
+	if len(got) != 1 || got[0].Tags["interface_name"] != "e2" || len(got[0].Values) != 1 {
+		t.Fatalf("unexpected events after the timeout: %v", got)
+	}
+	if len(p.pending) != 0 {
+		t.Errorf("expected no pending groups, got %d", len(p.pending))
+	}
+}
+
+func TestEventGroupByTriggers(t *testing.T) {
+	p, now := newBufferedGroupBy(t, map[string]interface{}{
+		"tags":            []string{"interface_name"},
+		"size":            3,
+		"marker":          "last-change$",
+		"drop-incomplete": true,
+	})
+	got := p.Apply(
+		rowEvent(1, "e1", map[string]interface{}{"a": 1, "b": 2}),
+		rowEvent(1, "e2", map[string]interface{}{"a": 1}),
+		rowEvent(2, "e1", map[string]interface{}{"c": 3}),
+	)
+	if len(got) != 1 || len(got[0].Values) != 3 {
+		t.Fatalf("expected the group reaching the size, got %v", got)
+	}
+	got = p.Apply(
+		rowEvent(1, "e3", map[string]interface{}{"a": 1}),
+		rowEvent(2, "e3", map[string]interface{}{"last-change": 10}),
+	)
+	if len(got) != 1 || got[0].Tags["interface_name"] != "e3" {
+		t.Fatalf("expected the group with the marker, got %v", got)
+	}
+	// e2 is dropped after the default timeout.
+	*now = now.Add(defaultTimeout)
+	if got = p.Apply(); len(got) != 0 {
+		t.Fatalf("expected the incomplete group to be dropped, got %v", got)
+	}
+	if len(p.pending) != 0 {
+		t.Errorf("expected no pending groups, got %d", len(p.pending))
+	}
+}
+
+func TestEventGroupByFlush(t *testing.T) {
+	cfg := map[string]interface{}{
+		"tags":            []string{"interface_name"},
+		"expected-values": []string{"oper-status$", "admin-status$"},
+	}
+	p, _ := newBufferedGroupBy(t, cfg)
+	p.Apply(
+		rowEvent(1, "e2", map[string]interface{}{"/interface/oper-status": "UP"}),
+		rowEvent(2, "e1", map[string]interface{}{"/interface/admin-status": "ENABLE"}),
+	)
+	got := p.Flush()
+	if len(got) != 2 || got[0].Tags["interface_name"] != "e1" || got[1].Tags["interface_name"] != "e2" {
+		t.Fatalf("expected the pending groups sorted by key, got %v", got)
+	}
+	if len(p.pending) != 0 {
+		t.Errorf("expected no pending groups, got %d", len(p.pending))
+	}
+
+	cfg["drop-incomplete"] = true
+	p, _ = newBufferedGroupBy(t, cfg)
+	p.Apply(rowEvent(1, "e1", map[string]interface{}{"/interface/oper-status": "UP"}))
+	if got = p.Flush(); len(got) != 0 {
+		t.Fatalf("expected the incomplete groups to be dropped, got %v", got)
+	}
+	if len(p.pending) != 0 {
+		t.Errorf("expected no pending groups, got %d", len(p.pending))
+	}
+}
@@ -0,0 +1,228 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package event_histogram
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/api/utils"
+	"github.com/openconfig/gnmic/pkg/formatters"
+)
+
+const (
+	processorType = "event-histogram"
+	loggingPrefix = "[" + processorType + "] "
+	minSchema     = -4
+	maxSchema     = 8
+)
+
+// histogram aggregates the values with a key matching one of the regexes
+// into histograms, one per event name, tags and value name.
+// The matching values are replaced with the histogram.
+type histogram struct {
+	Values        []string      `mapstructure:"value-names,omitempty" json:"value-names,omitempty"`
+	Buckets       []float64     `mapstructure:"buckets,omitempty" json:"buckets,omitempty"`
+	NativeSchema  *int32        `mapstructure:"native-schema,omitempty" json:"native-schema,omitempty"`
+	ZeroThreshold float64       `mapstructure:"zero-threshold,omitempty" json:"zero-threshold,omitempty"`
+	Expiration    time.Duration `mapstructure:"expiration,omitempty" json:"expiration,omitempty"`
+	Debug         bool          `mapstructure:"debug,omitempty" json:"debug,omitempty"`
+
+	values []*regexp.Regexp
+	logger *log.Logger
+
+	m      *sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	h        *formatters.Histogram
+	lastSeen time.Time
+}
+
+func init() {
+	formatters.Register(processorType, func() formatters.EventProcessor {
+		return &histogram{
+			logger: log.New(io.Discard, "", 0),
+			m:      new(sync.Mutex),
+			series: make(map[string]*series),
+		}
+	})
+}
+
+func (p *histogram) Init(cfg interface{}, opts ...formatters.Option) error {
+	err := formatters.DecodeConfig(cfg, p)
+	if err != nil {
+		return err
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if len(p.Values) == 0 {
+		return errors.New("missing value-names")
+	}
+	if len(p.Buckets) == 0 && p.NativeSchema == nil {
+		return errors.New("at least one of buckets or native-schema must be set")
+	}
+	if p.NativeSchema != nil && (*p.NativeSchema < minSchema || *p.NativeSchema > maxSchema) {
+		return fmt.Errorf("native-schema must be between %d and %d", minSchema, maxSchema)
+	}
+	if p.ZeroThreshold < 0 {
+		return fmt.Errorf("invalid zero-threshold %f", p.ZeroThreshold)
+	}
+	p.values = make([]*regexp.Regexp, 0, len(p.Values))
+	for _, reg := range p.Values {
+		re, err := regexp.Compile(reg)
+		if err != nil {
+			return err
+		}
+		p.values = append(p.values, re)
+	}
+	if p.logger.Writer() != io.Discard {
+		b, err := json.Marshal(p)
+		if err != nil {
+			p.logger.Printf("initialized processor '%s': %+v", processorType, p)
+			return nil
+		}
+		p.logger.Printf("initialized processor '%s': %s", processorType, string(b))
+	}
+	return nil
+}
+
+func (p *histogram) Apply(es ...*formatters.EventMsg) []*formatters.EventMsg {
+	p.m.Lock()
+	defer p.m.Unlock()
+	now := time.Now()
+	p.expire(now)
+	for _, e := range es {
+		if e == nil {
+			continue
+		}
+		for k, v := range e.Values {
+			if !p.matches(k) {
+				continue
+			}
+			fv, err := toFloat(v)
+			if err != nil {
+				p.logger.Printf("key '%s': %v", k, err)
+				continue
+			}
+			key := seriesKey(e, k)
+			s, ok := p.series[key]
+			if !ok {
+				s = &series{h: formatters.NewHistogram(p.Buckets, p.NativeSchema, p.ZeroThreshold)}
+				p.series[key] = s
+			}
+			s.h.Observe(fv)
+			s.lastSeen = now
+			// the outputs get a snapshot, later observations do not alter it.
+			e.Values[k] = s.h.Clone()
+			p.logger.Printf("key '%s', value %v added to histogram, count=%d", k, v, s.h.Count)
+		}
+	}
+	return es
+}
+
+func (p *histogram) matches(k string) bool {
+	for _, re := range p.values {
+		if re.MatchString(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// expire drops the histograms not updated for longer than the expiration.
+func (p *histogram) expire(now time.Time) {
+	if p.Expiration <= 0 {
+		return
+	}
+	for k, s := range p.series {
+		if now.Sub(s.lastSeen) > p.Expiration {
+			delete(p.series, k)
+		}
+	}
+}
+
+func seriesKey(e *formatters.EventMsg, valueName string) string {
+	tagNames := make([]string, 0, len(e.Tags))
+	for k := range e.Tags {
+		tagNames = append(tagNames, k)
+	}
+	sort.Strings(tagNames)
+	sb := strings.Builder{}
+	sb.WriteString(e.Name)
+	sb.WriteString("\n")
+	for _, k := range tagNames {
+		sb.WriteString(k)
+		sb.WriteString("=")
+		sb.WriteString(e.Tags[k])
+		sb.WriteString("\n")
+	}
+	sb.WriteString(valueName)
+	return sb.String()
+}
+
+func toFloat(v interface{}) (float64, error) {
+	switch i := v.(type) {
+	case float64:
+		return i, nil
+	case float32:
+		return float64(i), nil
+	case int64:
+		return float64(i), nil
+	case int32:
+		return float64(i), nil
+	case int16:
+		return float64(i), nil
+	case int8:
+		return float64(i), nil
+	case int:
+		return float64(i), nil
+	case uint64:
+		return float64(i), nil
+	case uint32:
+		return float64(i), nil
+	case uint16:
+		return float64(i), nil
+	case uint8:
+		return float64(i), nil
+	case uint:
+		return float64(i), nil
+	case string:
+		return strconv.ParseFloat(i, 64)
+	default:
+		return 0, fmt.Errorf("cannot convert %v of type %T to float", v, v)
+	}
+}
+
+func (p *histogram) WithLogger(l *log.Logger) {
+	if p.Debug && l != nil {
+		p.logger = log.New(l.Writer(), loggingPrefix, l.Flags())
+	} else if p.Debug {
+		p.logger = log.New(os.Stderr, loggingPrefix, utils.DefaultLoggingFlags)
+	}
+}
+
+func (p *histogram) WithTargets(tcs map[string]*types.TargetConfig) {}
+
+func (p *histogram) WithActions(act map[string]map[string]interface{}) {}
+
+func (p *histogram) WithProcessors(procs map[string]map[string]any) {}
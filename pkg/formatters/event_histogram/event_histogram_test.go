@@ -0,0 +1,99 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package event_histogram
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+)
+
+func newProcessor(t *testing.T, cfg map[string]interface{}) *histogram {
+	p := formatters.EventProcessors[processorType]().(*histogram)
+	if err := p.Init(cfg); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func latencyEvent(source string, v interface{}) *formatters.EventMsg {
+	return &formatters.EventMsg{
+		Name:   "sub",
+		Tags:   map[string]string{"source": source},
+		Values: map[string]interface{}{"latency": v, "other": 1},
+	}
+}
+
+func TestHistogramApply(t *testing.T) {
+	p := newProcessor(t, map[string]interface{}{
+		"value-names":   []string{"^latency$"},
+		"buckets":       []interface{}{1, 10},
+		"native-schema": 3,
+	})
+	var last *formatters.EventMsg
+	for _, v := range []interface{}{0.5, "5", uint64(50)} {
+		es := p.Apply(latencyEvent("r1", v))
+		last = es[0]
+	}
+	// a different source gets its own histogram
+	r2 := p.Apply(latencyEvent("r2", 2))[0]
+
+	h, ok := last.Values["latency"].(*formatters.Histogram)
+	if !ok {
+		t.Fatalf("expected a histogram value, got %T", last.Values["latency"])
+	}
+	if h.Count != 3 || h.Sum != 55.5 {
+		t.Errorf("unexpected count=%d sum=%f", h.Count, h.Sum)
+	}
+	if h.Buckets[0].Count != 1 || h.Buckets[1].Count != 2 {
+		t.Errorf("unexpected buckets: %+v", h.Buckets)
+	}
+	if !h.IsNative() || *h.Schema != 3 || len(h.PositiveBuckets) != 3 {
+		t.Errorf("unexpected native buckets: %+v", h)
+	}
+	if v := last.Values["other"]; v != 1 {
+		t.Errorf("non matching value changed: %v", v)
+	}
+	if h2 := r2.Values["latency"].(*formatters.Histogram); h2.Count != 1 {
+		t.Errorf("expected a single observation for source r2, got %d", h2.Count)
+	}
+}
+
+func TestHistogramExpiration(t *testing.T) {
+	p := newProcessor(t, map[string]interface{}{
+		"value-names": []string{"^latency$"},
+		"buckets":     []interface{}{1},
+		"expiration":  "1m",
+	})
+	p.Apply(latencyEvent("r1", 0.5))
+	for _, s := range p.series {
+		s.lastSeen = time.Now().Add(-2 * time.Minute)
+	}
+	ev := p.Apply(latencyEvent("r1", 0.5))[0]
+	if h := ev.Values["latency"].(*formatters.Histogram); h.Count != 1 {
+		t.Errorf("expected the expired histogram to be reset, got count %d", h.Count)
+	}
+}
+
+func TestHistogramInit(t *testing.T) {
+	for name, cfg := range map[string]map[string]interface{}{
+		"missing_value_names": {"buckets": []interface{}{1}},
+		"missing_buckets":     {"value-names": []string{"."}},
+		"invalid_schema":      {"value-names": []string{"."}, "native-schema": 9},
+		"invalid_regex":       {"value-names": []string{"("}, "buckets": []interface{}{1}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := formatters.EventProcessors[processorType]()
+			if err := p.Init(cfg); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package event_join
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/itchyny/gojq"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/api/utils"
+	"github.com/openconfig/gnmic/pkg/formatters"
+)
+
+const (
+	processorType = "event-join"
+	loggingPrefix = "[" + processorType + "] "
+
+	defaultName   = "join"
+	defaultWindow = time.Minute
+)
+
+// join correlates the events of different sources sharing the values
+// of the key tags and emits a combined event once all the sources
+// were received within the window.
+type join struct {
+	// tags whose values identify the joined events.
+	On []string `mapstructure:"on,omitempty" json:"on,omitempty"`
+	// the joined events sources, at least 2.
+	Sources []*source `mapstructure:"sources,omitempty" json:"sources,omitempty"`
+	// maximum time between the joined events timestamps.
+	Window time.Duration `mapstructure:"window,omitempty" json:"window,omitempty"`
+	// name of the combined events.
+	Name string `mapstructure:"name,omitempty" json:"name,omitempty"`
+	// drop the events matching a source instead of passing them through.
+	DropSources bool `mapstructure:"drop-sources,omitempty" json:"drop-sources,omitempty"`
+	Debug       bool `mapstructure:"debug,omitempty" json:"debug,omitempty"`
+
+	m sync.Mutex
+	// latest event per key and source.
+	pending map[string][]*formatters.EventMsg
+	// highest timestamp seen, used to expire the pending events.
+	latest    int64
+	lastSweep int64
+
+	logger *log.Logger
+}
+
+type source struct {
+	Name string `mapstructure:"name,omitempty" json:"name,omitempty"`
+	// jq expression selecting the source events.
+	Condition string `mapstructure:"condition,omitempty" json:"condition,omitempty"`
+	// regular expressions selecting the joined values, defaults to all of them.
+	ValueNames []string `mapstructure:"value-names,omitempty" json:"value-names,omitempty"`
+
+	condition  *gojq.Code
+	valueNames []*regexp.Regexp
+}
+
+func init() {
+	formatters.Register(processorType, func() formatters.EventProcessor {
+		return &join{
+			logger: log.New(io.Discard, "", 0),
+		}
+	})
+}
+
+func (p *join) Init(cfg interface{}, opts ...formatters.Option) error {
+	err := formatters.DecodeConfig(cfg, p)
+	if err != nil {
+		return err
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if len(p.On) == 0 {
+		return errors.New("missing join tags")
+	}
+	if len(p.Sources) < 2 {
+		return errors.New("at least 2 sources are required")
+	}
+	for i, s := range p.Sources {
+		if s == nil {
+			return fmt.Errorf("missing source(#%d) definition", i)
+		}
+		if s.Condition == "" {
+			return fmt.Errorf("source(#%d) %q: missing condition", i, s.Name)
+		}
+		if s.Name == "" {
+			s.Name = fmt.Sprintf("source%d", i)
+		}
+		s.condition, err = formatters.CompileJQ(strings.TrimSpace(s.Condition))
+		if err != nil {
+			return fmt.Errorf("source %q: %v", s.Name, err)
+		}
+		for _, reg := range s.ValueNames {
+			re, err := regexp.Compile(reg)
+			if err != nil {
+				return fmt.Errorf("source %q: %v", s.Name, err)
+			}
+			s.valueNames = append(s.valueNames, re)
+		}
+	}
+	if p.Window <= 0 {
+		p.Window = defaultWindow
+	}
+	if p.Name == "" {
+		p.Name = defaultName
+	}
+	p.pending = make(map[string][]*formatters.EventMsg)
+	if p.logger.Writer() != io.Discard {
+		b, err := json.Marshal(p)
+		if err != nil {
+			p.logger.Printf("initialized processor '%s': %+v", processorType, p)
+			return nil
+		}
+		p.logger.Printf("initialized processor '%s': %s", processorType, string(b))
+	}
+	return nil
+}
+
+func (p *join) Apply(es ...*formatters.EventMsg) []*formatters.EventMsg {
+	p.m.Lock()
+	defer p.m.Unlock()
+	res := make([]*formatters.EventMsg, 0, len(es))
+	for _, e := range es {
+		if e == nil {
+			continue
+		}
+		matched := false
+		var joined []*formatters.EventMsg
+		for i, s := range p.Sources {
+			ok, err := formatters.CheckCondition(s.condition, e)
+			if err != nil {
+				p.logger.Printf("source %q condition check failed: %v", s.Name, err)
+				continue
+			}
+			if !ok {
+				continue
+			}
+			matched = true
+			if j := p.add(i, e); j != nil {
+				joined = append(joined, j)
+			}
+		}
+		if !matched || !p.DropSources {
+			res = append(res, e)
+		}
+		res = append(res, joined...)
+	}
+	p.sweep()
+	return res
+}
+
+func (p *join) WithLogger(l *log.Logger) {
+	if p.Debug && l != nil {
+		p.logger = log.New(l.Writer(), loggingPrefix, l.Flags())
+	} else if p.Debug {
+		p.logger = log.New(os.Stderr, loggingPrefix, utils.DefaultLoggingFlags)
+	}
+}
+
+func (p *join) WithTargets(tcs map[string]*types.TargetConfig) {}
+
+func (p *join) WithActions(act map[string]map[string]interface{}) {}
+
+func (p *join) WithProcessors(procs map[string]map[string]any) {}
+
+// add stores event e as the latest event of source i for its key
+// and returns the combined event if all the sources are within the window.
+func (p *join) add(i int, e *formatters.EventMsg) *formatters.EventMsg {
+	key, ok := p.key(e)
+	if !ok {
+		return nil
+	}
+	if e.Timestamp > p.latest {
+		p.latest = e.Timestamp
+	}
+	evs, ok := p.pending[key]
+	if !ok {
+		evs = make([]*formatters.EventMsg, len(p.Sources))
+		p.pending[key] = evs
+	}
+	evs[i] = e
+	window := p.Window.Nanoseconds()
+	for _, ev := range evs {
+		if ev == nil {
+			return nil
+		}
+		if abs(e.Timestamp-ev.Timestamp) > window {
+			return nil
+		}
+	}
+	j := &formatters.EventMsg{
+		Name:      p.Name,
+		Timestamp: e.Timestamp,
+		Tags:      make(map[string]string),
+		Values:    make(map[string]interface{}),
+	}
+	for si, ev := range evs {
+		for k, v := range ev.Tags {
+			j.Tags[k] = v
+		}
+		for k, v := range ev.Values {
+			if p.Sources[si].selected(k) {
+				j.Values[k] = v
+			}
+		}
+	}
+	if p.Debug {
+		p.logger.Printf("joined %d events for key %q", len(evs), key)
+	}
+	return j
+}
+
+func (p *join) key(e *formatters.EventMsg) (string, bool) {
+	vals := make([]string, 0, len(p.On))
+	for _, t := range p.On {
+		v, ok := e.Tags[t]
+		if !ok {
+			return "", false
+		}
+		vals = append(vals, v)
+	}
+	return strings.Join(vals, "\x00"), true
+}
+
+// sweep removes, at most once per window, the keys
+// whose events are all older than the window.
+func (p *join) sweep() {
+	window := p.Window.Nanoseconds()
+	if p.latest-p.lastSweep < window {
+		return
+	}
+	p.lastSweep = p.latest
+	for key, evs := range p.pending {
+		expired := true
+		for _, ev := range evs {
+			if ev != nil && p.latest-ev.Timestamp <= window {
+				expired = false
+				break
+			}
+		}
+		if expired {
+			delete(p.pending, key)
+		}
+	}
+}
+
+func (s *source) selected(name string) bool {
+	if len(s.valueNames) == 0 {
+		return true
+	}
+	for _, re := range s.valueNames {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func abs(d int64) int64 {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,171 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package event_join
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+)
+
+func newJoin(t *testing.T, cfg map[string]interface{}) formatters.EventProcessor {
+	p := formatters.EventProcessors[processorType]()
+	err := p.Init(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func ifState(ts int64, intf, state string) *formatters.EventMsg {
+	return &formatters.EventMsg{
+		Name:      "interfaces",
+		Timestamp: ts,
+		Tags: map[string]string{
+			"source":            "r1",
+			"subscription-name": "interfaces",
+			"interface_name":    intf,
+		},
+		Values: map[string]interface{}{
+			"/interfaces/interface/state/oper-status": state,
+			"/interfaces/interface/state/mtu":         int64(9000),
+		},
+	}
+}
+
+func lldp(ts int64, intf, neighbor string) *formatters.EventMsg {
+	return &formatters.EventMsg{
+		Name:      "lldp",
+		Timestamp: ts,
+		Tags: map[string]string{
+			"source":            "r1",
+			"subscription-name": "lldp",
+			"interface_name":    intf,
+			"neighbor_id":       "n1",
+		},
+		Values: map[string]interface{}{
+			"/lldp/interfaces/interface/neighbors/neighbor/state/system-name": neighbor,
+		},
+	}
+}
+
+var testConfig = map[string]interface{}{
+	"on":     []string{"source", "interface_name"},
+	"window": "30s",
+	"name":   "interface-neighbor",
+	"sources": []interface{}{
+		map[string]interface{}{
+			"name":        "state",
+			"condition":   `.tags["subscription-name"] == "interfaces"`,
+			"value-names": []string{"oper-status$"},
+		},
+		map[string]interface{}{
+			"name":      "lldp",
+			"condition": `.tags["subscription-name"] == "lldp"`,
+		},
+	},
+}
+
+func TestJoin(t *testing.T) {
+	p := newJoin(t, testConfig)
+	sec := int64(time.Second)
+	got := p.Apply(ifState(1*sec, "e1", "UP"), lldp(2*sec, "e2", "spine2"))
+	if len(got) != 2 {
+		t.Fatalf("expected the 2 source events only, got %d", len(got))
+	}
+	got = p.Apply(lldp(10*sec, "e1", "spine1"))
+	if len(got) != 2 {
+		t.Fatalf("expected the source and joined events, got %d", len(got))
+	}
+	want := &formatters.EventMsg{
+		Name:      "interface-neighbor",
+		Timestamp: 10 * sec,
+		Tags: map[string]string{
+			"source":            "r1",
+			"subscription-name": "lldp",
+			"interface_name":    "e1",
+			"neighbor_id":       "n1",
+		},
+		Values: map[string]interface{}{
+			"/interfaces/interface/state/oper-status":                         "UP",
+			"/lldp/interfaces/interface/neighbors/neighbor/state/system-name": "spine1",
+		},
+	}
+	if !reflect.DeepEqual(got[1], want) {
+		t.Errorf("unexpected joined event:\n got: %+v\nwant: %+v", got[1], want)
+	}
+	// an update of a source joins with the latest event of the others.
+	got = p.Apply(ifState(20*sec, "e1", "DOWN"))
+	if len(got) != 2 || got[1].Values["/interfaces/interface/state/oper-status"] != "DOWN" {
+		t.Fatalf("unexpected events: %v", got)
+	}
+	// outside of the window.
+	got = p.Apply(ifState(60*sec, "e2", "UP"))
+	if len(got) != 1 {
+		t.Fatalf("unexpected join outside the window: %v", got)
+	}
+}
+
+func TestJoinDropSources(t *testing.T) {
+	cfg := make(map[string]interface{})
+	for k, v := range testConfig {
+		cfg[k] = v
+	}
+	cfg["drop-sources"] = true
+	p := newJoin(t, cfg)
+	other := &formatters.EventMsg{Name: "cpu", Tags: map[string]string{"subscription-name": "cpu"}}
+	got := p.Apply(ifState(1, "e1", "UP"), other, lldp(2, "e1", "spine1"))
+	if len(got) != 2 || got[0] != other || got[1].Name != "interface-neighbor" {
+		t.Errorf("unexpected events: %v", got)
+	}
+	// events without the key tags are not joined.
+	e := lldp(3, "e1", "spine1")
+	delete(e.Tags, "interface_name")
+	if got := p.Apply(e); len(got) != 0 {
+		t.Errorf("unexpected events: %v", got)
+	}
+}
+
+func TestJoinSweep(t *testing.T) {
+	p := newJoin(t, testConfig).(*join)
+	sec := int64(time.Second)
+	p.Apply(ifState(1*sec, "e1", "UP"))
+	p.Apply(ifState(100*sec, "e2", "UP"))
+	if len(p.pending) != 1 {
+		t.Errorf("expected the expired key to be removed, got %d keys", len(p.pending))
+	}
+}
+
+func TestJoinInitErrors(t *testing.T) {
+	for name, cfg := range map[string]map[string]interface{}{
+		"no_keys": {
+			"sources": testConfig["sources"],
+		},
+		"single_source": {
+			"on":      []string{"source"},
+			"sources": []interface{}{map[string]interface{}{"condition": "true"}},
+		},
+		"missing_condition": {
+			"on": []string{"source"},
+			"sources": []interface{}{
+				map[string]interface{}{"condition": "true"},
+				map[string]interface{}{"name": "b"},
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := formatters.EventProcessors[processorType]()
+			if err := p.Init(cfg); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
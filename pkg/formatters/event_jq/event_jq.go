@@ -58,21 +58,13 @@ func (p *jq) Init(cfg interface{}, opts ...formatters.Option) error {
 	}
 	p.setDefaults()
 	p.Condition = strings.TrimSpace(p.Condition)
-	q, err := gojq.Parse(p.Condition)
-	if err != nil {
-		return err
-	}
-	p.cond, err = gojq.Compile(q)
+	p.cond, err = formatters.CompileJQ(p.Condition)
 	if err != nil {
 		return err
 	}
 
 	p.Expression = strings.TrimSpace(p.Expression)
-	q, err = gojq.Parse(p.Expression)
-	if err != nil {
-		return err
-	}
-	p.expr, err = gojq.Compile(q)
+	p.expr, err = formatters.CompileJQ(p.Expression)
 	if err != nil {
 		return err
 	}
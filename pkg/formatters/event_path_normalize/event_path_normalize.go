@@ -0,0 +1,243 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package event_path_normalize
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/api/utils"
+	gfile "github.com/openconfig/gnmic/pkg/file"
+	"github.com/openconfig/gnmic/pkg/formatters"
+)
+
+const (
+	processorType = "event-path-normalize"
+	loggingPrefix = "[" + processorType + "] "
+)
+
+// pathNormalize normalizes the paths of the values names and deletes,
+// to make the events of different vendors alike.
+type pathNormalize struct {
+	ValueNames          []string          `mapstructure:"value-names,omitempty" json:"value-names,omitempty"`
+	Mappings            map[string]string `mapstructure:"mappings,omitempty" json:"mappings,omitempty"`
+	MappingFile         string            `mapstructure:"mapping-file,omitempty" json:"mapping-file,omitempty"`
+	StripOrigin         bool              `mapstructure:"strip-origin,omitempty" json:"strip-origin,omitempty"`
+	AddOrigin           string            `mapstructure:"add-origin,omitempty" json:"add-origin,omitempty"`
+	StripModulePrefixes bool              `mapstructure:"strip-module-prefixes,omitempty" json:"strip-module-prefixes,omitempty"`
+	Debug               bool              `mapstructure:"debug,omitempty" json:"debug,omitempty"`
+
+	valueNames []*regexp.Regexp
+	// mappings sources sorted by decreasing length,
+	// for the longest prefix to match first.
+	sources []string
+	logger  *log.Logger
+}
+
+func init() {
+	formatters.Register(processorType, func() formatters.EventProcessor {
+		return &pathNormalize{
+			logger: log.New(io.Discard, "", 0),
+		}
+	})
+}
+
+func (p *pathNormalize) Init(cfg interface{}, opts ...formatters.Option) error {
+	err := formatters.DecodeConfig(cfg, p)
+	if err != nil {
+		return err
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	err = p.readMappings()
+	if err != nil {
+		return err
+	}
+	if len(p.Mappings) == 0 && !p.StripOrigin && p.AddOrigin == "" && !p.StripModulePrefixes {
+		return errors.New("at least one of mappings, mapping-file, strip-origin, add-origin or strip-module-prefixes must be set")
+	}
+	p.valueNames = make([]*regexp.Regexp, 0, len(p.ValueNames))
+	for _, reg := range p.ValueNames {
+		re, err := regexp.Compile(reg)
+		if err != nil {
+			return err
+		}
+		p.valueNames = append(p.valueNames, re)
+	}
+	if p.logger.Writer() != io.Discard {
+		b, err := json.Marshal(p)
+		if err != nil {
+			p.logger.Printf("initialized processor '%s': %+v", processorType, p)
+			return nil
+		}
+		p.logger.Printf("initialized processor '%s': %s", processorType, string(b))
+	}
+	return nil
+}
+
+// readMappings merges the mapping file entries with the inline mappings,
+// the inline ones take precedence.
+func (p *pathNormalize) readMappings() error {
+	mappings := make(map[string]string)
+	if p.MappingFile != "" {
+		b, err := gfile.ReadFile(context.TODO(), p.MappingFile)
+		if err != nil {
+			return err
+		}
+		err = yaml.Unmarshal(b, &mappings)
+		if err != nil {
+			return err
+		}
+	}
+	for k, v := range p.Mappings {
+		mappings[k] = v
+	}
+	p.Mappings = make(map[string]string, len(mappings))
+	p.sources = make([]string, 0, len(mappings))
+	for k, v := range mappings {
+		k = strings.TrimSuffix(k, "/")
+		p.Mappings[k] = strings.TrimSuffix(v, "/")
+		p.sources = append(p.sources, k)
+	}
+	sort.Slice(p.sources, func(i, j int) bool {
+		if len(p.sources[i]) == len(p.sources[j]) {
+			return p.sources[i] < p.sources[j]
+		}
+		return len(p.sources[i]) > len(p.sources[j])
+	})
+	return nil
+}
+
+func (p *pathNormalize) Apply(es ...*formatters.EventMsg) []*formatters.EventMsg {
+	for _, e := range es {
+		if e == nil {
+			continue
+		}
+		// renamed values are set after the loop,
+		// for a new name not to be normalized again.
+		renamed := make(map[string]interface{})
+		for k, v := range e.Values {
+			if !p.selected(k) {
+				continue
+			}
+			nk := p.normalize(k)
+			if nk == k {
+				continue
+			}
+			p.logger.Printf("value name '%s' normalized to '%s'", k, nk)
+			delete(e.Values, k)
+			renamed[nk] = v
+		}
+		for k, v := range renamed {
+			e.Values[k] = v
+		}
+		for i, d := range e.Deletes {
+			if p.selected(d) {
+				e.Deletes[i] = p.normalize(d)
+			}
+		}
+	}
+	return es
+}
+
+func (p *pathNormalize) selected(name string) bool {
+	if len(p.valueNames) == 0 {
+		return true
+	}
+	for _, re := range p.valueNames {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize applies the mappings, then the origin and module prefixes changes to the path name.
+func (p *pathNormalize) normalize(name string) string {
+	name = p.mapPath(name)
+	origin, pth := splitOrigin(name)
+	if p.StripOrigin {
+		origin = ""
+	}
+	if p.StripModulePrefixes {
+		pth = stripModulePrefixes(pth)
+	}
+	if p.AddOrigin != "" && origin == "" {
+		origin = p.AddOrigin
+	}
+	if origin == "" {
+		return pth
+	}
+	return origin + ":" + pth
+}
+
+// mapPath replaces the longest mapping source prefix of name with its destination.
+func (p *pathNormalize) mapPath(name string) string {
+	for _, src := range p.sources {
+		if name == src {
+			return p.Mappings[src]
+		}
+		if strings.HasPrefix(name, src+"/") {
+			return p.Mappings[src] + name[len(src):]
+		}
+	}
+	return name
+}
+
+// splitOrigin splits a path name formatted as origin:/path.
+func splitOrigin(name string) (string, string) {
+	if strings.HasPrefix(name, "/") {
+		return "", name
+	}
+	idx := strings.Index(name, ":/")
+	if idx < 0 || strings.Contains(name[:idx], "/") {
+		return "", name
+	}
+	return name[:idx], name[idx+1:]
+}
+
+// stripModulePrefixes removes the YANG module prefixes of the path elements,
+// e.g: /openconfig-interfaces:interfaces/interface becomes /interfaces/interface.
+func stripModulePrefixes(pth string) string {
+	if !strings.Contains(pth, ":") {
+		return pth
+	}
+	elems := strings.Split(pth, "/")
+	for i, pe := range elems {
+		if idx := strings.Index(pe, ":"); idx >= 0 {
+			elems[i] = pe[idx+1:]
+		}
+	}
+	return strings.Join(elems, "/")
+}
+
+func (p *pathNormalize) WithLogger(l *log.Logger) {
+	if p.Debug && l != nil {
+		p.logger = log.New(l.Writer(), loggingPrefix, l.Flags())
+	} else if p.Debug {
+		p.logger = log.New(os.Stderr, loggingPrefix, utils.DefaultLoggingFlags)
+	}
+}
+
+func (p *pathNormalize) WithTargets(tcs map[string]*types.TargetConfig) {}
+
+func (p *pathNormalize) WithActions(act map[string]map[string]interface{}) {}
+
+func (p *pathNormalize) WithProcessors(procs map[string]map[string]any) {}
@@ -0,0 +1,167 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package event_path_normalize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+)
+
+var testset = map[string]struct {
+	processor map[string]interface{}
+	input     *formatters.EventMsg
+	output    *formatters.EventMsg
+}{
+	"strip_origin": {
+		processor: map[string]interface{}{"strip-origin": true},
+		input: &formatters.EventMsg{
+			Values:  map[string]interface{}{"openconfig:/interfaces/interface/state/oper-status": "UP"},
+			Deletes: []string{"openconfig:/interfaces/interface"},
+		},
+		output: &formatters.EventMsg{
+			Values:  map[string]interface{}{"/interfaces/interface/state/oper-status": "UP"},
+			Deletes: []string{"/interfaces/interface"},
+		},
+	},
+	"add_origin": {
+		processor: map[string]interface{}{"add-origin": "openconfig"},
+		input: &formatters.EventMsg{
+			Values: map[string]interface{}{
+				"/interfaces/interface/state/oper-status": "UP",
+				"native:/system/name":                     "r1",
+			},
+		},
+		output: &formatters.EventMsg{
+			Values: map[string]interface{}{
+				"openconfig:/interfaces/interface/state/oper-status": "UP",
+				"native:/system/name":                                "r1",
+			},
+		},
+	},
+	"strip_module_prefixes": {
+		processor: map[string]interface{}{"strip-module-prefixes": true},
+		input: &formatters.EventMsg{
+			Values: map[string]interface{}{
+				"openconfig:/openconfig-interfaces:interfaces/interface/openconfig-if-ethernet:ethernet/state/counters/in-crc-errors": 1,
+			},
+		},
+		output: &formatters.EventMsg{
+			Values: map[string]interface{}{
+				"openconfig:/interfaces/interface/ethernet/state/counters/in-crc-errors": 1,
+			},
+		},
+	},
+	"mappings": {
+		processor: map[string]interface{}{
+			"mappings": map[string]string{
+				"Cisco-IOS-XR-infra-statsd-oper:/infra-statistics/interfaces/interface/latest/generic-counters":                "/interfaces/interface/state/counters",
+				"Cisco-IOS-XR-infra-statsd-oper:/infra-statistics/interfaces/interface/latest/generic-counters/bytes-received": "/interfaces/interface/state/counters/in-octets",
+				// a mapping destination is not mapped again
+				"/interfaces/interface/state/counters": "/other",
+			},
+		},
+		input: &formatters.EventMsg{
+			Values: map[string]interface{}{
+				"Cisco-IOS-XR-infra-statsd-oper:/infra-statistics/interfaces/interface/latest/generic-counters/bytes-received": 1,
+				"Cisco-IOS-XR-infra-statsd-oper:/infra-statistics/interfaces/interface/latest/generic-counters/crc-errors":     2,
+				"Cisco-IOS-XR-infra-statsd-oper:/infra-statistics/interfaces/interface/latest/generic-counters-x":              3,
+			},
+		},
+		output: &formatters.EventMsg{
+			Values: map[string]interface{}{
+				"/interfaces/interface/state/counters/in-octets":                                                  1,
+				"/interfaces/interface/state/counters/crc-errors":                                                 2,
+				"Cisco-IOS-XR-infra-statsd-oper:/infra-statistics/interfaces/interface/latest/generic-counters-x": 3,
+			},
+		},
+	},
+	"value_names": {
+		processor: map[string]interface{}{
+			"strip-origin": true,
+			"value-names":  []string{"interfaces"},
+		},
+		input: &formatters.EventMsg{
+			Values: map[string]interface{}{
+				"openconfig:/interfaces/interface/state/oper-status": "UP",
+				"openconfig:/system/state/hostname":                  "r1",
+			},
+		},
+		output: &formatters.EventMsg{
+			Values: map[string]interface{}{
+				"/interfaces/interface/state/oper-status": "UP",
+				"openconfig:/system/state/hostname":       "r1",
+			},
+		},
+	},
+}
+
+func TestPathNormalize(t *testing.T) {
+	for name, ts := range testset {
+		t.Run(name, func(t *testing.T) {
+			p := formatters.EventProcessors[processorType]()
+			if err := p.Init(ts.processor); err != nil {
+				t.Fatal(err)
+			}
+			got := p.Apply(ts.input)
+			if !cmp.Equal(got[0], ts.output) {
+				t.Errorf("unexpected output: %s", cmp.Diff(ts.output, got[0]))
+			}
+		})
+	}
+}
+
+func TestPathNormalizeMappingFile(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "mappings.yaml")
+	err := os.WriteFile(f, []byte(`
+/interfaces-state/interface/statistics/in-octets: /interfaces/interface/state/counters/in-octets
+/interfaces-state/interface/statistics/out-octets: /interfaces/interface/state/counters/out-octets
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := formatters.EventProcessors[processorType]()
+	err = p.Init(map[string]interface{}{
+		"mapping-file": f,
+		"mappings": map[string]string{
+			"/interfaces-state/interface/statistics/out-octets": "/out",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := p.Apply(&formatters.EventMsg{
+		Values: map[string]interface{}{
+			"/interfaces-state/interface/statistics/in-octets":  1,
+			"/interfaces-state/interface/statistics/out-octets": 2,
+		},
+	})
+	want := map[string]interface{}{
+		"/interfaces/interface/state/counters/in-octets": 1,
+		"/out": 2,
+	}
+	if !cmp.Equal(got[0].Values, want) {
+		t.Errorf("unexpected values: %s", cmp.Diff(want, got[0].Values))
+	}
+}
+
+func TestPathNormalizeInit(t *testing.T) {
+	p := formatters.EventProcessors[processorType]()
+	if err := p.Init(map[string]interface{}{}); err == nil {
+		t.Errorf("expected an error without normalization settings")
+	}
+	p = formatters.EventProcessors[processorType]()
+	if err := p.Init(map[string]interface{}{"mapping-file": "/does/not/exist.yaml"}); err == nil {
+		t.Errorf("expected an error for a missing mapping file")
+	}
+}
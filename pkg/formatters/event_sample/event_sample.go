@@ -0,0 +1,283 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package event_sample
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/api/utils"
+	"github.com/openconfig/gnmic/pkg/formatters"
+)
+
+const (
+	processorType = "event-sample"
+	loggingPrefix = "[" + processorType + "] "
+
+	modeProbabilistic = "probabilistic"
+	modeEveryNth      = "every-nth"
+	modeReservoir     = "reservoir"
+
+	defaultCacheSize = 1000
+	defaultInterval  = 10 * time.Second
+)
+
+// sample keeps a sample of the events: a random fraction of them,
+// one every N events per tag set, or a fixed size random sample per tag set and interval.
+type sample struct {
+	Mode string `mapstructure:"mode,omitempty" json:"mode,omitempty"`
+	// probabilistic mode, fraction of the events kept.
+	Rate float64 `mapstructure:"rate,omitempty" json:"rate,omitempty"`
+	// every-nth mode, one event out of N is kept per tag set.
+	N int `mapstructure:"n,omitempty" json:"n,omitempty"`
+	// reservoir mode, number of events kept per tag set and interval.
+	Size     int           `mapstructure:"size,omitempty" json:"size,omitempty"`
+	Interval time.Duration `mapstructure:"interval,omitempty" json:"interval,omitempty"`
+	// names of the tags identifying a tag set, all the event tags if empty.
+	Tags []string `mapstructure:"tags,omitempty" json:"tags,omitempty"`
+	// regular expressions, events with a matching value name are never dropped.
+	PassThrough []string `mapstructure:"pass-through,omitempty" json:"pass-through,omitempty"`
+	// every-nth mode, maximum number of tag sets tracked.
+	CacheSize int  `mapstructure:"cache-size,omitempty" json:"cache-size,omitempty"`
+	Debug     bool `mapstructure:"debug,omitempty" json:"debug,omitempty"`
+
+	passThrough []*regexp.Regexp
+
+	m          sync.Mutex
+	rand       *rand.Rand
+	counters   *lru.Cache[string, int]
+	reservoirs map[string]*reservoir
+	now        func() time.Time
+
+	logger *log.Logger
+}
+
+// reservoir holds a uniform random sample of the events of a tag set
+// received since start.
+type reservoir struct {
+	start time.Time
+	seen  int
+	evs   []*formatters.EventMsg
+}
+
+func init() {
+	formatters.Register(processorType, func() formatters.EventProcessor {
+		return &sample{
+			logger: log.New(io.Discard, "", 0),
+			rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+			now:    time.Now,
+		}
+	})
+}
+
+func (p *sample) Init(cfg interface{}, opts ...formatters.Option) error {
+	err := formatters.DecodeConfig(cfg, p)
+	if err != nil {
+		return err
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.Mode == "" {
+		p.Mode = modeProbabilistic
+	}
+	switch p.Mode {
+	case modeProbabilistic:
+		if p.Rate <= 0 || p.Rate > 1 {
+			return fmt.Errorf("invalid rate %v, must be in (0, 1]", p.Rate)
+		}
+	case modeEveryNth:
+		if p.N <= 0 {
+			return fmt.Errorf("invalid n %d, must be greater than 0", p.N)
+		}
+		if p.CacheSize <= 0 {
+			p.CacheSize = defaultCacheSize
+		}
+		p.counters, err = lru.New[string, int](p.CacheSize)
+		if err != nil {
+			return fmt.Errorf("failed to initialize cache: %w", err)
+		}
+	case modeReservoir:
+		if p.Size <= 0 {
+			return fmt.Errorf("invalid size %d, must be greater than 0", p.Size)
+		}
+		if p.Interval <= 0 {
+			p.Interval = defaultInterval
+		}
+		p.reservoirs = make(map[string]*reservoir)
+	default:
+		return fmt.Errorf("unknown mode %q, expecting one of %s, %s or %s",
+			p.Mode, modeProbabilistic, modeEveryNth, modeReservoir)
+	}
+	for _, reg := range p.PassThrough {
+		re, err := regexp.Compile(reg)
+		if err != nil {
+			return fmt.Errorf("pass-through: %v", err)
+		}
+		p.passThrough = append(p.passThrough, re)
+	}
+	if p.logger.Writer() != io.Discard {
+		b, err := json.Marshal(p)
+		if err != nil {
+			p.logger.Printf("initialized processor '%s': %+v", processorType, p)
+			return nil
+		}
+		p.logger.Printf("initialized processor '%s': %s", processorType, string(b))
+	}
+	return nil
+}
+
+func (p *sample) Apply(es ...*formatters.EventMsg) []*formatters.EventMsg {
+	p.m.Lock()
+	defer p.m.Unlock()
+	result := make([]*formatters.EventMsg, 0, len(es))
+	now := p.now()
+	for _, e := range es {
+		if e == nil {
+			continue
+		}
+		if p.passes(e) {
+			result = append(result, e)
+			continue
+		}
+		switch p.Mode {
+		case modeProbabilistic:
+			if p.rand.Float64() < p.Rate {
+				result = append(result, e)
+			}
+		case modeEveryNth:
+			key := p.tagSet(e)
+			n, _ := p.counters.Get(key)
+			if n%p.N == 0 {
+				result = append(result, e)
+			}
+			p.counters.Add(key, (n+1)%p.N)
+		case modeReservoir:
+			key := p.tagSet(e)
+			r, ok := p.reservoirs[key]
+			if !ok {
+				r = &reservoir{start: now, evs: make([]*formatters.EventMsg, 0, p.Size)}
+				p.reservoirs[key] = r
+			}
+			r.add(e, p.Size, p.rand)
+		}
+	}
+	if p.Mode == modeReservoir {
+		result = append(result, p.expired(now)...)
+	}
+	if p.Debug {
+		p.logger.Printf("kept %d out of %d event(s)", len(result), len(es))
+	}
+	return result
+}
+
+// add adds e to the reservoir using the algorithm R:
+// the i-th event replaces a random sampled event with probability size/i.
+func (r *reservoir) add(e *formatters.EventMsg, size int, rnd *rand.Rand) {
+	r.seen++
+	if len(r.evs) < size {
+		r.evs = append(r.evs, e)
+		return
+	}
+	if j := rnd.Intn(r.seen); j < size {
+		r.evs[j] = e
+	}
+}
+
+// expired returns the samples of the reservoirs whose interval ended,
+// sorted by timestamp.
+func (p *sample) expired(now time.Time) []*formatters.EventMsg {
+	var evs []*formatters.EventMsg
+	for key, r := range p.reservoirs {
+		if now.Sub(r.start) < p.Interval {
+			continue
+		}
+		delete(p.reservoirs, key)
+		evs = append(evs, r.evs...)
+	}
+	sort.SliceStable(evs, func(i, j int) bool {
+		return evs[i].Timestamp < evs[j].Timestamp
+	})
+	return evs
+}
+
+// Flush returns the events sampled by the reservoirs.
+func (p *sample) Flush() []*formatters.EventMsg {
+	if p.Mode != modeReservoir {
+		return nil
+	}
+	p.m.Lock()
+	defer p.m.Unlock()
+	var evs []*formatters.EventMsg
+	for key, r := range p.reservoirs {
+		delete(p.reservoirs, key)
+		evs = append(evs, r.evs...)
+	}
+	sort.SliceStable(evs, func(i, j int) bool {
+		return evs[i].Timestamp < evs[j].Timestamp
+	})
+	return evs
+}
+
+func (p *sample) passes(e *formatters.EventMsg) bool {
+	for _, re := range p.passThrough {
+		for k := range e.Values {
+			if re.MatchString(k) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tagSet returns the key identifying the tag set of e.
+func (p *sample) tagSet(e *formatters.EventMsg) string {
+	names := p.Tags
+	if len(names) == 0 {
+		names = make([]string, 0, len(e.Tags))
+		for k := range e.Tags {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+	}
+	var key strings.Builder
+	for _, k := range names {
+		key.WriteString(k)
+		key.WriteString("=")
+		key.WriteString(e.Tags[k])
+		key.WriteString("\n")
+	}
+	return key.String()
+}
+
+func (p *sample) WithLogger(l *log.Logger) {
+	if p.Debug && l != nil {
+		p.logger = log.New(l.Writer(), loggingPrefix, l.Flags())
+	} else if p.Debug {
+		p.logger = log.New(os.Stderr, loggingPrefix, utils.DefaultLoggingFlags)
+	}
+}
+
+func (p *sample) WithTargets(tcs map[string]*types.TargetConfig) {}
+
+func (p *sample) WithActions(act map[string]map[string]interface{}) {}
+
+func (p *sample) WithProcessors(procs map[string]map[string]any) {}
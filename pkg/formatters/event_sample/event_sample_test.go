@@ -0,0 +1,127 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package event_sample
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+)
+
+func newSample(t *testing.T, cfg map[string]interface{}) *sample {
+	p := formatters.EventProcessors[processorType]().(*sample)
+	p.rand = rand.New(rand.NewSource(1))
+	err := p.Init(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func events(n int, tags map[string]string, value string) []*formatters.EventMsg {
+	evs := make([]*formatters.EventMsg, 0, n)
+	for i := 0; i < n; i++ {
+		evs = append(evs, &formatters.EventMsg{
+			Timestamp: int64(i),
+			Tags:      tags,
+			Values:    map[string]interface{}{value: i},
+		})
+	}
+	return evs
+}
+
+func TestSampleProbabilistic(t *testing.T) {
+	p := newSample(t, map[string]interface{}{"rate": 0.1})
+	n := len(p.Apply(events(10000, nil, "v")...))
+	if n < 800 || n > 1200 {
+		t.Errorf("kept %d events out of 10000 with a 0.1 rate", n)
+	}
+}
+
+func TestSampleEveryNth(t *testing.T) {
+	p := newSample(t, map[string]interface{}{
+		"mode": "every-nth",
+		"n":    3,
+		"tags": []string{"interface"},
+	})
+	evs := append(events(7, map[string]string{"interface": "e1", "queue": "1"}, "v"),
+		events(2, map[string]string{"interface": "e2"}, "v")...)
+	out := p.Apply(evs...)
+	// e1: events 0, 3 and 6, e2: event 0
+	if len(out) != 4 {
+		t.Fatalf("kept %d events, want 4", len(out))
+	}
+	for i, want := range []int64{0, 3, 6, 0} {
+		if out[i].Timestamp != want {
+			t.Errorf("event %d: got timestamp %d, want %d", i, out[i].Timestamp, want)
+		}
+	}
+	// the counter carries over to the next run
+	out = p.Apply(events(3, map[string]string{"interface": "e1"}, "v")...)
+	if len(out) != 1 || out[0].Timestamp != 2 {
+		t.Errorf("unexpected events kept on the second run: %v", out)
+	}
+}
+
+func TestSampleReservoir(t *testing.T) {
+	now := time.Unix(0, 0)
+	p := newSample(t, map[string]interface{}{
+		"mode":     "reservoir",
+		"size":     2,
+		"interval": "10s",
+	})
+	p.now = func() time.Time { return now }
+	out := p.Apply(append(events(10, map[string]string{"if": "e1"}, "v"),
+		events(1, map[string]string{"if": "e2"}, "v")...)...)
+	if len(out) != 0 {
+		t.Fatalf("events emitted before the end of the interval: %v", out)
+	}
+	now = now.Add(10 * time.Second)
+	out = p.Apply()
+	if len(out) != 3 {
+		t.Fatalf("emitted %d events, want 3", len(out))
+	}
+	if evs := p.Flush(); len(evs) != 0 {
+		t.Errorf("reservoirs not reset after being emitted: %v", evs)
+	}
+	p.Apply(events(5, map[string]string{"if": "e1"}, "v")...)
+	if evs := p.Flush(); len(evs) != 2 {
+		t.Errorf("flushed %d events, want 2", len(evs))
+	}
+}
+
+func TestSamplePassThrough(t *testing.T) {
+	p := newSample(t, map[string]interface{}{
+		"mode":         "every-nth",
+		"n":            100,
+		"pass-through": []string{"oper-state$"},
+	})
+	evs := append(events(10, nil, "/interface/statistics/in-octets"),
+		events(10, nil, "/interface/oper-state")...)
+	if n := len(p.Apply(evs...)); n != 11 {
+		t.Errorf("kept %d events, want 11", n)
+	}
+}
+
+func TestSampleInitErrors(t *testing.T) {
+	for name, cfg := range map[string]map[string]interface{}{
+		"no_rate":      {},
+		"rate_above_1": {"rate": 2},
+		"no_n":         {"mode": "every-nth"},
+		"no_size":      {"mode": "reservoir"},
+		"unknown_mode": {"mode": "first"},
+	} {
+		err := formatters.EventProcessors[processorType]().Init(cfg)
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package event_silence
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/api/utils"
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/silences"
+)
+
+const (
+	processorType = "event-silence"
+	loggingPrefix = "[" + processorType + "] "
+
+	actionDrop = "drop"
+	actionTag  = "tag"
+
+	defaultTagName        = "silenced"
+	defaultReloadInterval = 30 * time.Second
+)
+
+// silence drops or tags the events matching an active silence,
+// configured in the processor, loaded from a file or managed through the API.
+type silence struct {
+	Silences []*silences.Silence `mapstructure:"silences,omitempty" json:"silences,omitempty"`
+	// file with a YAML or JSON list of silences, reloaded when modified.
+	File           string        `mapstructure:"file,omitempty" json:"file,omitempty"`
+	ReloadInterval time.Duration `mapstructure:"reload-interval,omitempty" json:"reload-interval,omitempty"`
+	// apply the silences managed through the API.
+	APISilences bool `mapstructure:"api-silences,omitempty" json:"api-silences,omitempty"`
+	// drop or tag
+	Action string `mapstructure:"action,omitempty" json:"action,omitempty"`
+	// tag added with the silence ID as value when the action is tag.
+	TagName string `mapstructure:"tag-name,omitempty" json:"tag-name,omitempty"`
+	Debug   bool   `mapstructure:"debug,omitempty" json:"debug,omitempty"`
+
+	static *silences.Store
+
+	m          sync.Mutex
+	file       *silences.Store
+	lastCheck  time.Time
+	fileMod    time.Time
+	now        func() time.Time
+	apiSilence *silences.Store
+
+	logger *log.Logger
+}
+
+func init() {
+	formatters.Register(processorType, func() formatters.EventProcessor {
+		return &silence{
+			logger: log.New(io.Discard, "", 0),
+			now:    time.Now,
+		}
+	})
+}
+
+func (p *silence) Init(cfg interface{}, opts ...formatters.Option) error {
+	err := formatters.DecodeConfig(cfg, p)
+	if err != nil {
+		return err
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	switch p.Action {
+	case "":
+		p.Action = actionDrop
+	case actionDrop, actionTag:
+	default:
+		return fmt.Errorf("unknown action %q, expecting %q or %q", p.Action, actionDrop, actionTag)
+	}
+	if p.TagName == "" {
+		p.TagName = defaultTagName
+	}
+	if p.ReloadInterval <= 0 {
+		p.ReloadInterval = defaultReloadInterval
+	}
+	if len(p.Silences) == 0 && p.File == "" && !p.APISilences {
+		return fmt.Errorf("one of silences, file or api-silences must be set")
+	}
+	p.static = silences.NewStore()
+	err = p.static.Replace(p.Silences)
+	if err != nil {
+		return err
+	}
+	if p.File != "" {
+		p.file = silences.NewStore()
+		err = p.reload()
+		if err != nil {
+			return err
+		}
+	}
+	if p.APISilences {
+		p.apiSilence = silences.Default()
+	}
+	if p.logger.Writer() != io.Discard {
+		b, err := json.Marshal(p)
+		if err != nil {
+			p.logger.Printf("initialized processor '%s': %+v", processorType, p)
+			return nil
+		}
+		p.logger.Printf("initialized processor '%s': %s", processorType, string(b))
+	}
+	return nil
+}
+
+func (p *silence) Apply(es ...*formatters.EventMsg) []*formatters.EventMsg {
+	p.checkFile()
+	result := make([]*formatters.EventMsg, 0, len(es))
+	for _, e := range es {
+		if e == nil {
+			continue
+		}
+		s := p.match(e)
+		if s == nil {
+			result = append(result, e)
+			continue
+		}
+		if p.Debug {
+			p.logger.Printf("event %q silenced by %q", e.Name, s.ID)
+		}
+		if p.Action == actionDrop {
+			continue
+		}
+		if e.Tags == nil {
+			e.Tags = make(map[string]string)
+		}
+		e.Tags[p.TagName] = s.ID
+		result = append(result, e)
+	}
+	return result
+}
+
+func (p *silence) match(e *formatters.EventMsg) *silences.Silence {
+	for _, st := range []*silences.Store{p.static, p.file, p.apiSilence} {
+		if st == nil {
+			continue
+		}
+		if s := st.Match(e.Tags, e.Values); s != nil {
+			return s
+		}
+	}
+	return nil
+}
+
+// checkFile reloads the silences file if it was modified,
+// at most once per reload interval.
+func (p *silence) checkFile() {
+	if p.file == nil {
+		return
+	}
+	p.m.Lock()
+	defer p.m.Unlock()
+	now := p.now()
+	if now.Sub(p.lastCheck) < p.ReloadInterval {
+		return
+	}
+	p.lastCheck = now
+	err := p.reload()
+	if err != nil {
+		p.logger.Printf("failed to reload silences file: %v", err)
+	}
+}
+
+func (p *silence) reload() error {
+	fi, err := os.Stat(p.File)
+	if err != nil {
+		return err
+	}
+	if fi.ModTime().Equal(p.fileMod) {
+		return nil
+	}
+	ss, err := silences.ReadFile(p.File)
+	if err != nil {
+		return err
+	}
+	err = p.file.Replace(ss)
+	if err != nil {
+		return err
+	}
+	p.fileMod = fi.ModTime()
+	p.lastCheck = p.now()
+	if p.Debug {
+		p.logger.Printf("loaded %d silence(s) from %s", len(ss), p.File)
+	}
+	return nil
+}
+
+func (p *silence) WithLogger(l *log.Logger) {
+	if p.Debug && l != nil {
+		p.logger = log.New(l.Writer(), loggingPrefix, l.Flags())
+	} else if p.Debug {
+		p.logger = log.New(os.Stderr, loggingPrefix, utils.DefaultLoggingFlags)
+	}
+}
+
+func (p *silence) WithTargets(tcs map[string]*types.TargetConfig) {}
+
+func (p *silence) WithActions(act map[string]map[string]interface{}) {}
+
+func (p *silence) WithProcessors(procs map[string]map[string]any) {}
@@ -0,0 +1,116 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package event_silence
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/silences"
+)
+
+func testEvents() []*formatters.EventMsg {
+	return []*formatters.EventMsg{
+		{
+			Tags:   map[string]string{"source": "leaf1"},
+			Values: map[string]interface{}{"/interface/oper-state": "down"},
+		},
+		{
+			Tags:   map[string]string{"source": "leaf2"},
+			Values: map[string]interface{}{"/interface/oper-state": "down"},
+		},
+	}
+}
+
+func TestEventSilenceStatic(t *testing.T) {
+	p := formatters.EventProcessors[processorType]()
+	err := p.Init(map[string]interface{}{
+		"action": "tag",
+		"silences": []interface{}{
+			map[string]interface{}{
+				"id": "leaf1-upgrade",
+				"matchers": []interface{}{
+					map[string]interface{}{"name": "source", "value": "leaf1"},
+					map[string]interface{}{"name": "__path__", "value": "/interface/.*", "is-regex": true},
+				},
+				"ends-at": time.Now().Add(time.Hour).Format(time.RFC3339),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := p.Apply(testEvents()...)
+	if len(out) != 2 {
+		t.Fatalf("got %d events, want 2", len(out))
+	}
+	if out[0].Tags[defaultTagName] != "leaf1-upgrade" {
+		t.Errorf("silenced event not tagged: %v", out[0].Tags)
+	}
+	if _, ok := out[1].Tags[defaultTagName]; ok {
+		t.Errorf("event tagged without a matching silence: %v", out[1].Tags)
+	}
+}
+
+func TestEventSilenceFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "silences.yaml")
+	err := os.WriteFile(path, []byte(`
+- matchers:
+    - name: source
+      value: leaf2
+  ends-at: 2100-01-01T00:00:00Z
+`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := formatters.EventProcessors[processorType]().(*silence)
+	now := time.Now()
+	p.now = func() time.Time { return now }
+	err = p.Init(map[string]interface{}{"file": path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := p.Apply(testEvents()...)
+	if len(out) != 1 || out[0].Tags["source"] != "leaf1" {
+		t.Fatalf("unexpected events: %v", out)
+	}
+	// the file is reloaded once modified and the reload interval elapsed
+	err = os.WriteFile(path, []byte("[]"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(path, now.Add(time.Minute), now.Add(time.Minute))
+	now = now.Add(defaultReloadInterval)
+	if out := p.Apply(testEvents()...); len(out) != 2 {
+		t.Errorf("silences file not reloaded: got %d events", len(out))
+	}
+}
+
+func TestEventSilenceAPI(t *testing.T) {
+	p := formatters.EventProcessors[processorType]()
+	err := p.Init(map[string]interface{}{"api-silences": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = silences.Default().Set(&silences.Silence{
+		ID:       "api",
+		Matchers: []*silences.Matcher{{Name: "source", Value: "leaf.*", IsRegex: true}},
+		EndsAt:   time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer silences.Default().Delete("api")
+	if out := p.Apply(testEvents()...); len(out) != 0 {
+		t.Errorf("got %d events, want 0", len(out))
+	}
+}
@@ -0,0 +1,208 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package event_split
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/api/utils"
+	"github.com/openconfig/gnmic/pkg/formatters"
+)
+
+const (
+	processorType = "event-split"
+	loggingPrefix = "[" + processorType + "] "
+)
+
+// split breaks an event into multiple events, one per group of values.
+// A group is the part of the value names matched by a prefix or a regular expression.
+type split struct {
+	// path prefixes, values are grouped by prefix followed by
+	// the characters up to the next '/', e.g: a list index or a neighbor address.
+	Prefixes []string `mapstructure:"prefixes,omitempty" json:"prefixes,omitempty"`
+	// tag name set to the characters following the prefix
+	PrefixTag string `mapstructure:"prefix-tag,omitempty" json:"prefix-tag,omitempty"`
+	// regular expressions, values are grouped by the matched part of their name,
+	// named capture groups are added as tags.
+	ValueNames []string `mapstructure:"value-names,omitempty" json:"value-names,omitempty"`
+	// remove the group from the value names of the new events
+	Trim  bool `mapstructure:"trim,omitempty" json:"trim,omitempty"`
+	Debug bool `mapstructure:"debug,omitempty" json:"debug,omitempty"`
+
+	prefixes   []*regexp.Regexp
+	valueNames []*regexp.Regexp
+	logger     *log.Logger
+}
+
+func init() {
+	formatters.Register(processorType, func() formatters.EventProcessor {
+		return &split{
+			logger: log.New(io.Discard, "", 0),
+		}
+	})
+}
+
+func (p *split) Init(cfg interface{}, opts ...formatters.Option) error {
+	err := formatters.DecodeConfig(cfg, p)
+	if err != nil {
+		return err
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.prefixes = make([]*regexp.Regexp, 0, len(p.Prefixes))
+	for _, prefix := range p.Prefixes {
+		expr := "^" + regexp.QuoteMeta(prefix) + "[^/]*"
+		if p.PrefixTag != "" {
+			expr = "^" + regexp.QuoteMeta(prefix) + "(?P<" + p.PrefixTag + ">[^/]*)"
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return err
+		}
+		p.prefixes = append(p.prefixes, re)
+	}
+	p.valueNames = make([]*regexp.Regexp, 0, len(p.ValueNames))
+	for _, reg := range p.ValueNames {
+		re, err := regexp.Compile(reg)
+		if err != nil {
+			return err
+		}
+		p.valueNames = append(p.valueNames, re)
+	}
+
+	if p.logger.Writer() != io.Discard {
+		b, err := json.Marshal(p)
+		if err != nil {
+			p.logger.Printf("initialized processor '%s': %+v", processorType, p)
+			return nil
+		}
+		p.logger.Printf("initialized processor '%s': %s", processorType, string(b))
+	}
+	return nil
+}
+
+func (p *split) Apply(es ...*formatters.EventMsg) []*formatters.EventMsg {
+	res := make([]*formatters.EventMsg, 0, len(es))
+	for _, e := range es {
+		if e == nil {
+			continue
+		}
+		res = append(res, p.split(e)...)
+	}
+	return res
+}
+
+// split returns the events built from the matching values of e,
+// followed by e itself if it still has values or deletes.
+func (p *split) split(e *formatters.EventMsg) []*formatters.EventMsg {
+	groups := make(map[string]*formatters.EventMsg)
+	for k, v := range e.Values {
+		group, tags, ok := p.match(k)
+		if !ok {
+			continue
+		}
+		ge, ok := groups[group]
+		if !ok {
+			ge = &formatters.EventMsg{
+				Name:      e.Name,
+				Timestamp: e.Timestamp,
+				Tags:      make(map[string]string, len(e.Tags)+len(tags)),
+				Values:    make(map[string]interface{}),
+			}
+			for tk, tv := range e.Tags {
+				ge.Tags[tk] = tv
+			}
+			for tk, tv := range tags {
+				ge.Tags[tk] = tv
+			}
+			groups[group] = ge
+		}
+		name := k
+		if p.Trim {
+			name = strings.TrimLeft(strings.TrimPrefix(k, group), "/")
+		}
+		ge.Values[name] = v
+		delete(e.Values, k)
+	}
+	if len(groups) == 0 {
+		return []*formatters.EventMsg{e}
+	}
+	// sort the groups to produce the events in a stable order
+	names := make([]string, 0, len(groups))
+	for group := range groups {
+		names = append(names, group)
+	}
+	sort.Strings(names)
+	res := make([]*formatters.EventMsg, 0, len(groups)+1)
+	for _, group := range names {
+		if p.Debug {
+			p.logger.Printf("group %q: %d value(s)", group, len(groups[group].Values))
+		}
+		res = append(res, groups[group])
+	}
+	if len(e.Values) > 0 || len(e.Deletes) > 0 {
+		res = append(res, e)
+	}
+	return res
+}
+
+// match returns the group of the value name and the tags extracted from it,
+// the prefixes are checked first then the value-names regular expressions.
+func (p *split) match(name string) (string, map[string]string, bool) {
+	for _, re := range p.prefixes {
+		if group, tags, ok := matchRegexp(re, name); ok {
+			if p.PrefixTag != "" {
+				tags[p.PrefixTag] = strings.TrimLeft(tags[p.PrefixTag], ".")
+			}
+			return group, tags, true
+		}
+	}
+	for _, re := range p.valueNames {
+		if group, tags, ok := matchRegexp(re, name); ok {
+			return group, tags, true
+		}
+	}
+	return "", nil, false
+}
+
+func matchRegexp(re *regexp.Regexp, name string) (string, map[string]string, bool) {
+	matches := re.FindStringSubmatch(name)
+	if matches == nil {
+		return "", nil, false
+	}
+	tags := make(map[string]string)
+	for i, n := range re.SubexpNames() {
+		if i != 0 && n != "" {
+			tags[n] = matches[i]
+		}
+	}
+	return matches[0], tags, true
+}
+
+func (p *split) WithLogger(l *log.Logger) {
+	if p.Debug && l != nil {
+		p.logger = log.New(l.Writer(), loggingPrefix, l.Flags())
+	} else if p.Debug {
+		p.logger = log.New(os.Stderr, loggingPrefix, utils.DefaultLoggingFlags)
+	}
+}
+
+func (p *split) WithTargets(tcs map[string]*types.TargetConfig) {}
+
+func (p *split) WithActions(act map[string]map[string]interface{}) {}
+
+func (p *split) WithProcessors(procs map[string]map[string]any) {}
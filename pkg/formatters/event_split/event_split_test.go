@@ -0,0 +1,148 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package event_split
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+)
+
+type item struct {
+	input  []*formatters.EventMsg
+	output []*formatters.EventMsg
+}
+
+var testset = map[string]struct {
+	processorType string
+	processor     map[string]interface{}
+	tests         []item
+}{
+	"prefix": {
+		processorType: processorType,
+		processor: map[string]interface{}{
+			"prefixes":   []string{"/qos/queues/queue"},
+			"prefix-tag": "queue",
+			"trim":       true,
+		},
+		tests: []item{
+			{
+				input:  nil,
+				output: make([]*formatters.EventMsg, 0),
+			},
+			{
+				input: []*formatters.EventMsg{
+					{
+						Name:      "sub1",
+						Timestamp: 42,
+						Tags:      map[string]string{"source": "r1"},
+						Values: map[string]interface{}{
+							"/qos/queues/queue.0/name":        "q0",
+							"/qos/queues/queue.0/counters/tx": 1,
+							"/qos/queues/queue.1/name":        "q1",
+							"/qos/queues/queue.1/counters/tx": 2,
+							"/qos/enabled":                    true,
+						},
+					},
+				},
+				output: []*formatters.EventMsg{
+					{
+						Name:      "sub1",
+						Timestamp: 42,
+						Tags:      map[string]string{"source": "r1", "queue": "0"},
+						Values:    map[string]interface{}{"name": "q0", "counters/tx": 1},
+					},
+					{
+						Name:      "sub1",
+						Timestamp: 42,
+						Tags:      map[string]string{"source": "r1", "queue": "1"},
+						Values:    map[string]interface{}{"name": "q1", "counters/tx": 2},
+					},
+					{
+						Name:      "sub1",
+						Timestamp: 42,
+						Tags:      map[string]string{"source": "r1"},
+						Values:    map[string]interface{}{"/qos/enabled": true},
+					},
+				},
+			},
+		},
+	},
+	"value_names": {
+		processorType: processorType,
+		processor: map[string]interface{}{
+			"value-names": []string{`^/bgp/neighbors/(?P<neighbor>[^/]+)`},
+		},
+		tests: []item{
+			{
+				input: []*formatters.EventMsg{
+					{
+						Values: map[string]interface{}{
+							"/bgp/neighbors/10.0.0.1/state": "up",
+							"/bgp/neighbors/10.0.0.2/state": "down",
+						},
+					},
+				},
+				output: []*formatters.EventMsg{
+					{
+						Tags:   map[string]string{"neighbor": "10.0.0.1"},
+						Values: map[string]interface{}{"/bgp/neighbors/10.0.0.1/state": "up"},
+					},
+					{
+						Tags:   map[string]string{"neighbor": "10.0.0.2"},
+						Values: map[string]interface{}{"/bgp/neighbors/10.0.0.2/state": "down"},
+					},
+				},
+			},
+			{
+				input: []*formatters.EventMsg{
+					{
+						Tags:   map[string]string{"source": "r1"},
+						Values: map[string]interface{}{"/system/name": "r1"},
+					},
+				},
+				output: []*formatters.EventMsg{
+					{
+						Tags:   map[string]string{"source": "r1"},
+						Values: map[string]interface{}{"/system/name": "r1"},
+					},
+				},
+			},
+		},
+	},
+}
+
+func TestEventSplit(t *testing.T) {
+	for name, ts := range testset {
+		if pi, ok := formatters.EventProcessors[ts.processorType]; ok {
+			p := pi()
+			err := p.Init(ts.processor)
+			if err != nil {
+				t.Errorf("failed to initialize processors: %v", err)
+				return
+			}
+			for i, item := range ts.tests {
+				t.Run(name, func(t *testing.T) {
+					t.Logf("running test item %d", i)
+					outs := p.Apply(item.input...)
+					if !reflect.DeepEqual(outs, item.output) {
+						t.Errorf("failed at %s item %d", name, i)
+						for _, o := range outs {
+							t.Errorf("got: %+v", o)
+						}
+						for _, o := range item.output {
+							t.Errorf("want: %+v", o)
+						}
+					}
+				})
+			}
+		}
+	}
+}
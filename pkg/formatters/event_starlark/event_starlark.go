@@ -11,14 +11,12 @@ package event_starlark
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
 	"log"
 	"os"
 	"sync"
+	"time"
 
-	"go.starlark.net/lib/math"
-	"go.starlark.net/lib/time"
 	"go.starlark.net/starlark"
 	"go.starlark.net/syntax"
 
@@ -32,17 +30,34 @@ const (
 	loggingPrefix = "[" + processorType + "] "
 )
 
+var fileOptions = &syntax.FileOptions{
+	Set:            true,
+	GlobalReassign: true,
+	Recursion:      true,
+}
+
 // starlarkProc runs a starlark script on the received events
 type starlarkProc struct {
 	Script string `mapstructure:"script,omitempty" json:"script,omitempty"`
 	Source string `mapstructure:"source,omitempty" json:"source,omitempty"`
 	Debug  bool   `mapstructure:"debug,omitempty" json:"debug,omitempty"`
+	// directory the modules are loaded from.
+	ModulesDir  string        `mapstructure:"modules-dir,omitempty" json:"modules-dir,omitempty"`
+	State       *stateConfig  `mapstructure:"state,omitempty" json:"state,omitempty"`
+	HTTPTimeout time.Duration `mapstructure:"http-timeout,omitempty" json:"http-timeout,omitempty"`
 
 	// this mutex ensures batches of events are processed in sequence
-	m       sync.Mutex
-	thread  *starlark.Thread
-	applyFn starlark.Value
-	logger  *log.Logger
+	m        sync.Mutex
+	thread   *starlark.Thread
+	applyFn  starlark.Value
+	builtins starlark.StringDict
+	modules  map[string]*loadedModule
+	logger   *log.Logger
+
+	state      *stateDict
+	store      stateStore
+	savedState []byte
+	lastSave   time.Time
 }
 
 func init() {
@@ -71,19 +86,23 @@ func (p *starlarkProc) Init(cfg interface{}, opts ...formatters.Option) error {
 		Print: func(_ *starlark.Thread, msg string) {
 			p.logger.Printf("print(): %v", msg)
 		},
-		Load: func(_ *starlark.Thread, module string) (starlark.StringDict, error) {
-			return loadModule(module)
-		},
+		Load: p.loadModule,
+	}
+	err = p.initState()
+	if err != nil {
+		return err
 	}
 	// sourceProgram
-	builtins := starlark.StringDict{}
-	builtins["Event"] = starlark.NewBuiltin("Event", newEvent)
-	builtins["copy_event"] = starlark.NewBuiltin("copy_event", copyEvent)
-	prog, err := p.sourceProgram(builtins)
+	p.builtins = starlark.StringDict{}
+	p.builtins["Event"] = starlark.NewBuiltin("Event", newEvent)
+	p.builtins["copy_event"] = starlark.NewBuiltin("copy_event", copyEvent)
+	p.builtins["state"] = p.state
+	p.modules = make(map[string]*loadedModule)
+	prog, err := p.sourceProgram(p.builtins)
 	if err != nil {
 		return err
 	}
-	globals, err := prog.Init(p.thread, builtins)
+	globals, err := prog.Init(p.thread, p.builtins)
 	if err != nil {
 		return err
 	}
@@ -113,12 +132,16 @@ func (p *starlarkProc) validate() error {
 	if p.Source != "" && p.Script != "" {
 		return errors.New("only one of 'script' or 'source' can be set")
 	}
+	if p.State != nil {
+		return p.State.validate()
+	}
 	return nil
 }
 
 func (p *starlarkProc) Apply(es ...*formatters.EventMsg) []*formatters.EventMsg {
 	p.m.Lock()
 	defer p.m.Unlock()
+	defer p.saveState()
 	numMsgs := len(es)
 	if numMsgs == 0 {
 		return es
@@ -201,26 +224,6 @@ func (p *starlarkProc) sourceProgram(builtins starlark.StringDict) (*starlark.Pr
 	if p.Source != "" {
 		src = p.Source
 	}
-	options := &syntax.FileOptions{
-		Set:            true,
-		GlobalReassign: true,
-		Recursion:      true,
-	}
-	_, program, err := starlark.SourceProgramOptions(options, p.Script, src, builtins.Has)
+	_, program, err := starlark.SourceProgramOptions(fileOptions, p.Script, src, builtins.Has)
 	return program, err
 }
-
-func loadModule(module string) (starlark.StringDict, error) {
-	switch module {
-	case "math.star":
-		return starlark.StringDict{
-			"math": math.Module,
-		}, nil
-	case "time.star":
-		return starlark.StringDict{
-			"time": time.Module,
-		}, nil
-	default:
-		return nil, fmt.Errorf("module %q unknown", module)
-	}
-}
@@ -9,11 +9,18 @@
 package event_starlark
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
+	"go.starlark.net/starlark"
+
 	"github.com/openconfig/gnmic/pkg/formatters"
 )
 
@@ -766,3 +773,156 @@ def apply(*events):
 		})
 	}
 }
+
+func newTestProc(t *testing.T, cfg map[string]interface{}) *starlarkProc {
+	t.Helper()
+	p := formatters.EventProcessors[processorType]().(*starlarkProc)
+	err := p.Init(cfg, formatters.WithLogger(log.New(os.Stderr, loggingPrefix, log.LstdFlags)))
+	if err != nil {
+		t.Fatalf("failed to init processor: %v", err)
+	}
+	return p
+}
+
+func Test_starlarkProc_ModulesDir(t *testing.T) {
+	dir := t.TempDir()
+	err := os.MkdirAll(filepath.Join(dir, "lib"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(dir, "lib", "tags.star"), []byte(`
+load("lib/names.star", "tag_name")
+
+def add_tag(e):
+  e.tags[tag_name] = "v"
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(dir, "lib", "names.star"), []byte(`tag_name = "from_module"`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := newTestProc(t, map[string]interface{}{
+		"modules-dir": dir,
+		"source": `
+load("lib/tags.star", "add_tag")
+
+def apply(*events):
+  for e in events:
+    add_tag(e)
+  return events
+`,
+	})
+	got := p.Apply(&formatters.EventMsg{Name: "ev1"})
+	if len(got) != 1 || got[0].Tags["from_module"] != "v" {
+		t.Errorf("unexpected result: %+v", got)
+	}
+
+	// modules outside of the modules directory cannot be loaded.
+	p = formatters.EventProcessors[processorType]().(*starlarkProc)
+	err = p.Init(map[string]interface{}{
+		"modules-dir": filepath.Join(dir, "lib"),
+		"source": `
+load("../lib/names.star", "tag_name")
+
+def apply(*events):
+  return events
+`,
+	})
+	if err == nil {
+		t.Errorf("expected an error loading a module outside of the modules directory")
+	}
+}
+
+func Test_starlarkProc_State(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	cfg := map[string]interface{}{
+		"state": map[string]interface{}{
+			"path":          path,
+			"save-interval": "1ns",
+		},
+		"source": `
+def apply(*events):
+  for e in events:
+    state[e.name] = state.get(e.name, 0) + 1
+    e.values["count"] = state[e.name]
+  return events
+`,
+	}
+	p := newTestProc(t, cfg)
+	p.Apply(&formatters.EventMsg{Name: "ev1"})
+	p.Apply(&formatters.EventMsg{Name: "ev1"})
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"ev1":2}` {
+		t.Errorf("unexpected stored state: %s", b)
+	}
+	// a new processor instance restores the state.
+	p = newTestProc(t, cfg)
+	got := p.Apply(&formatters.EventMsg{Name: "ev1"})
+	if len(got) != 1 || got[0].Values["count"] != int64(3) {
+		t.Errorf("unexpected result: %+v", got)
+	}
+}
+
+type testKV map[string][]byte
+
+func (kv testKV) Get(_ context.Context, key string) ([]byte, error) { return kv[key], nil }
+
+func (kv testKV) Put(_ context.Context, key string, val []byte) error {
+	kv[key] = val
+	return nil
+}
+
+func Test_starlarkProc_StateLocker(t *testing.T) {
+	cfg := map[string]interface{}{
+		"state": map[string]interface{}{
+			"store": "locker",
+			"key":   "gnmic/starlark/proc1",
+		},
+		"source": `
+def apply(*events):
+  return events
+`,
+	}
+	p := formatters.EventProcessors[processorType]().(*starlarkProc)
+	if err := p.Init(cfg); err == nil {
+		t.Fatalf("expected an error without a KV store")
+	}
+	kv := testKV{"gnmic/starlark/proc1": []byte(`{"k":"v"}`)}
+	formatters.SetKV(kv)
+	defer formatters.SetKV(nil)
+	p = newTestProc(t, cfg)
+	v, found, err := p.state.Get(starlark.String("k"))
+	if err != nil || !found || v != starlark.String("v") {
+		t.Errorf("unexpected restored state: %v %v %v", v, found, err)
+	}
+}
+
+func Test_starlarkProc_HTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"site": %q}`, r.Header.Get("X-Device"))
+	}))
+	defer srv.Close()
+	p := newTestProc(t, map[string]interface{}{
+		"source": fmt.Sprintf(`
+load("http.star", "http")
+load("json.star", "json")
+
+def apply(*events):
+  for e in events:
+    rsp = http.get("%s", headers={"X-Device": e.tags["source"]})
+    if rsp["status_code"] == 200:
+      e.tags["site"] = json.decode(rsp["body"])["site"]
+  return events
+`, srv.URL),
+	})
+	got := p.Apply(&formatters.EventMsg{Name: "ev1", Tags: map[string]string{"source": "router1"}})
+	if len(got) != 1 || got[0].Tags["site"] != "router1" {
+		t.Errorf("unexpected result: %+v", got)
+	}
+}
@@ -0,0 +1,160 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package event_starlark
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.starlark.net/lib/json"
+	"go.starlark.net/lib/math"
+	starlarktime "go.starlark.net/lib/time"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+const (
+	defaultHTTPTimeout = 5 * time.Second
+	// maximum size of an http.star response body.
+	maxHTTPBodySize = 10 * 1024 * 1024
+)
+
+// loadedModule is the result of loading a module from the modules directory,
+// a nil globals and error means the module is being loaded.
+type loadedModule struct {
+	globals starlark.StringDict
+	err     error
+}
+
+// loadModule returns the builtin module called module,
+// or loads it from the modules directory.
+func (p *starlarkProc) loadModule(thread *starlark.Thread, module string) (starlark.StringDict, error) {
+	switch module {
+	case "math.star":
+		return starlark.StringDict{
+			"math": math.Module,
+		}, nil
+	case "time.star":
+		return starlark.StringDict{
+			"time": starlarktime.Module,
+		}, nil
+	case "json.star":
+		return starlark.StringDict{
+			"json": json.Module,
+		}, nil
+	case "http.star":
+		return starlark.StringDict{
+			"http": p.httpModule(),
+		}, nil
+	}
+	if p.ModulesDir == "" {
+		return nil, fmt.Errorf("module %q unknown", module)
+	}
+	// modules cannot be loaded from outside the modules directory.
+	path := filepath.Join(p.ModulesDir, filepath.Clean("/"+module))
+	if lm, ok := p.modules[path]; ok {
+		if lm == nil {
+			return nil, fmt.Errorf("cycle in load graph while loading module %q", module)
+		}
+		return lm.globals, lm.err
+	}
+	p.modules[path] = nil
+	src, err := os.ReadFile(path)
+	if err != nil {
+		p.modules[path] = &loadedModule{err: err}
+		return nil, err
+	}
+	_, prog, err := starlark.SourceProgramOptions(fileOptions, path, src, p.builtins.Has)
+	if err == nil {
+		var globals starlark.StringDict
+		globals, err = prog.Init(thread, p.builtins)
+		if err == nil {
+			globals.Freeze()
+			p.modules[path] = &loadedModule{globals: globals}
+			return globals, nil
+		}
+	}
+	err = fmt.Errorf("failed to load module %q: %w", module, err)
+	p.modules[path] = &loadedModule{err: err}
+	return nil, err
+}
+
+// httpModule returns the http.star module,
+// its functions return a dict with keys status_code, body and headers.
+func (p *starlarkProc) httpModule() *starlarkstruct.Module {
+	timeout := p.HTTPTimeout
+	if timeout <= 0 {
+		timeout = defaultHTTPTimeout
+	}
+	client := &http.Client{Timeout: timeout}
+	return &starlarkstruct.Module{
+		Name: "http",
+		Members: starlark.StringDict{
+			"get":  starlark.NewBuiltin("http.get", httpDo(client, http.MethodGet)),
+			"post": starlark.NewBuiltin("http.post", httpDo(client, http.MethodPost)),
+		},
+	}
+}
+
+func httpDo(client *http.Client, method string) func(*starlark.Thread, *starlark.Builtin, starlark.Tuple, []starlark.Tuple) (starlark.Value, error) {
+	return func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var url, body string
+		var headers *starlark.Dict
+		var err error
+		switch method {
+		case http.MethodPost:
+			err = starlark.UnpackArgs(b.Name(), args, kwargs, "url", &url, "body?", &body, "headers?", &headers)
+		default:
+			err = starlark.UnpackArgs(b.Name(), args, kwargs, "url", &url, "headers?", &headers)
+		}
+		if err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequest(method, url, strings.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", b.Name(), err)
+		}
+		if headers != nil {
+			for _, item := range headers.Items() {
+				k, ok := starlark.AsString(item[0])
+				if !ok {
+					return nil, fmt.Errorf("%s: header name must be a string, got %s", b.Name(), item[0].Type())
+				}
+				v, ok := starlark.AsString(item[1])
+				if !ok {
+					return nil, fmt.Errorf("%s: header %q value must be a string, got %s", b.Name(), k, item[1].Type())
+				}
+				req.Header.Set(k, v)
+			}
+		}
+		rsp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", b.Name(), err)
+		}
+		defer rsp.Body.Close()
+		rb, err := io.ReadAll(io.LimitReader(rsp.Body, maxHTTPBodySize))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", b.Name(), err)
+		}
+		rspHeaders := starlark.NewDict(len(rsp.Header))
+		for k := range rsp.Header {
+			rspHeaders.SetKey(starlark.String(k), starlark.String(rsp.Header.Get(k)))
+		}
+		result := starlark.NewDict(3)
+		result.SetKey(starlark.String("status_code"), starlark.MakeInt(rsp.StatusCode))
+		result.SetKey(starlark.String("body"), starlark.String(rb))
+		result.SetKey(starlark.String("headers"), rspHeaders)
+		return result, nil
+	}
+}
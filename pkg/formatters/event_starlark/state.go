@@ -0,0 +1,182 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package event_starlark
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.starlark.net/lib/json"
+	"go.starlark.net/starlark"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+)
+
+const (
+	stateStoreFile           = "file"
+	stateStoreLocker         = "locker"
+	defaultStateSaveInterval = 10 * time.Second
+	stateStoreTimeout        = 10 * time.Second
+)
+
+type stateConfig struct {
+	// file or locker
+	Store string `mapstructure:"store,omitempty" json:"store,omitempty"`
+	// file path, if store is file.
+	Path string `mapstructure:"path,omitempty" json:"path,omitempty"`
+	// locker key, if store is locker.
+	Key          string        `mapstructure:"key,omitempty" json:"key,omitempty"`
+	SaveInterval time.Duration `mapstructure:"save-interval,omitempty" json:"save-interval,omitempty"`
+}
+
+// stateDict is the dict exposed to the script as `state`,
+// it is never frozen so that its content can be modified across apply calls.
+type stateDict struct {
+	*starlark.Dict
+}
+
+func (s *stateDict) Freeze() {}
+
+type stateStore interface {
+	load() ([]byte, error)
+	save([]byte) error
+}
+
+type fileStateStore struct {
+	path string
+}
+
+func (s *fileStateStore) load() ([]byte, error) {
+	b, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return b, err
+}
+
+func (s *fileStateStore) save(b []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), s.path)
+}
+
+type kvStateStore struct {
+	kv  formatters.KV
+	key string
+}
+
+func (s *kvStateStore) load() ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), stateStoreTimeout)
+	defer cancel()
+	return s.kv.Get(ctx, s.key)
+}
+
+func (s *kvStateStore) save(b []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), stateStoreTimeout)
+	defer cancel()
+	return s.kv.Put(ctx, s.key, b)
+}
+
+func (c *stateConfig) validate() error {
+	switch c.Store {
+	case "":
+		c.Store = stateStoreFile
+		fallthrough
+	case stateStoreFile:
+		if c.Path == "" {
+			return errors.New("state: missing path")
+		}
+	case stateStoreLocker:
+		if c.Key == "" {
+			return errors.New("state: missing key")
+		}
+	default:
+		return fmt.Errorf("state: unknown store %q, must be %q or %q", c.Store, stateStoreFile, stateStoreLocker)
+	}
+	if c.SaveInterval <= 0 {
+		c.SaveInterval = defaultStateSaveInterval
+	}
+	return nil
+}
+
+// initState creates the state dict, restoring its content from the store if configured.
+func (p *starlarkProc) initState() error {
+	p.state = &stateDict{Dict: starlark.NewDict(0)}
+	if p.State == nil {
+		return nil
+	}
+	switch p.State.Store {
+	case stateStoreFile:
+		p.store = &fileStateStore{path: p.State.Path}
+	case stateStoreLocker:
+		kv := formatters.GetKV()
+		if kv == nil {
+			return errors.New("state: store locker requires a clustering locker able to store values")
+		}
+		p.store = &kvStateStore{kv: kv, key: p.State.Key}
+	}
+	b, err := p.store.load()
+	if err != nil {
+		return fmt.Errorf("state: failed to load: %v", err)
+	}
+	p.lastSave = time.Now()
+	if len(b) == 0 {
+		return nil
+	}
+	v, err := starlark.Call(p.thread, json.Module.Members["decode"], starlark.Tuple{starlark.String(b)}, nil)
+	if err != nil {
+		return fmt.Errorf("state: failed to decode: %v", err)
+	}
+	d, ok := v.(*starlark.Dict)
+	if !ok {
+		return fmt.Errorf("state: unexpected stored type %s", v.Type())
+	}
+	p.state.Dict = d
+	p.savedState = b
+	return nil
+}
+
+// saveState writes the state to the store if it changed
+// and the save interval elapsed since the last save.
+func (p *starlarkProc) saveState() {
+	if p.store == nil || time.Since(p.lastSave) < p.State.SaveInterval {
+		return
+	}
+	p.lastSave = time.Now()
+	v, err := starlark.Call(p.thread, json.Module.Members["encode"], starlark.Tuple{p.state.Dict}, nil)
+	if err != nil {
+		p.logger.Printf("state: failed to encode: %v", err)
+		return
+	}
+	b := []byte(v.(starlark.String))
+	if bytes.Equal(b, p.savedState) {
+		return
+	}
+	err = p.store.save(b)
+	if err != nil {
+		p.logger.Printf("state: failed to save: %v", err)
+		return
+	}
+	p.savedState = b
+}
@@ -135,6 +135,24 @@ func TestFromMap(t *testing.T) {
 	}
 }
 
+func TestClone(t *testing.T) {
+	for name, items := range eventMsgtestSet {
+		for i, item := range items {
+			c := item.ev.Clone()
+			if !reflect.DeepEqual(c, item.ev) {
+				t.Errorf("%q item %d: expected %+v, got %+v", name, i, item.ev, c)
+			}
+		}
+	}
+	e := &EventMsg{Tags: map[string]string{"a": "1"}, Values: map[string]interface{}{"v": 1}}
+	c := e.Clone()
+	c.Tags["b"] = "2"
+	c.Values["w"] = 2
+	if len(e.Tags) != 1 || len(e.Values) != 1 {
+		t.Errorf("clone shares its maps with the original event: %+v", e)
+	}
+}
+
 func TestTagsFromGNMIPath(t *testing.T) {
 	type args struct {
 		p *gnmi.Path
@@ -378,6 +396,47 @@ func TestResponseToEventMsgs(t *testing.T) {
 			want:    []*EventMsg{},
 			wantErr: false,
 		},
+		{
+			name: "single_update_with_meta",
+			args: args{
+				name: "sub1",
+				rsp: &gnmi.SubscribeResponse{
+					Response: &gnmi.SubscribeResponse_Update{
+						Update: &gnmi.Notification{
+							Timestamp: 42,
+							Update: []*gnmi.Update{
+								{
+									Path: &gnmi.Path{
+										Elem: []*gnmi.PathElem{{Name: "oper-state"}},
+									},
+									Val: &gnmi.TypedValue{
+										Value: &gnmi.TypedValue_AsciiVal{AsciiVal: "up"},
+									},
+								},
+							},
+						},
+					},
+				},
+				meta: map[string]string{
+					"format":               "event",
+					"source":               "router1",
+					MetaVarPrefix + "site": "paris",
+				},
+			},
+			want: []*EventMsg{
+				{
+					Name:      "sub1",
+					Timestamp: 42,
+					Tags: map[string]string{
+						"source": "router1",
+					},
+					Values: map[string]interface{}{
+						"/oper-state": "up",
+					},
+				},
+			},
+			wantErr: false,
+		},
 		{
 			name: "single_update_ascii_value",
 			args: args{
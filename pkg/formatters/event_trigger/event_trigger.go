@@ -29,6 +29,7 @@ import (
 	"github.com/openconfig/gnmic/pkg/api/utils"
 	gfile "github.com/openconfig/gnmic/pkg/file"
 	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/silences"
 )
 
 const (
@@ -48,12 +49,18 @@ type trigger struct {
 	VarsFile       string                 `mapstructure:"vars-file,omitempty"`
 	Debug          bool                   `mapstructure:"debug,omitempty"`
 	Async          bool                   `mapstructure:"async,omitempty"`
+	// run the actions even if the event matches an API silence.
+	IgnoreSilences bool `mapstructure:"ignore-silences,omitempty"`
+	// record the actions that would run instead of running them.
+	DryRun bool `mapstructure:"dry-run,omitempty"`
 
 	occurrencesTimes []time.Time
 	lastTrigger      time.Time
 	code             *gojq.Code
 	actions          []actions.Action
 	vars             map[string]interface{}
+	silences         *silences.Store
+	recorded         []string
 
 	targets map[string]*types.TargetConfig
 	acts    map[string]map[string]interface{}
@@ -63,7 +70,8 @@ type trigger struct {
 func init() {
 	formatters.Register(processorType, func() formatters.EventProcessor {
 		return &trigger{
-			logger: log.New(io.Discard, "", 0),
+			logger:   log.New(io.Discard, "", 0),
+			silences: silences.Default(),
 		}
 	})
 }
@@ -83,11 +91,7 @@ func (p *trigger) Init(cfg interface{}, opts ...formatters.Option) error {
 	}
 
 	p.Condition = strings.TrimSpace(p.Condition)
-	q, err := gojq.Parse(p.Condition)
-	if err != nil {
-		return err
-	}
-	p.code, err = gojq.Compile(q)
+	p.code, err = formatters.CompileJQ(p.Condition)
 	if err != nil {
 		return err
 	}
@@ -128,7 +132,13 @@ func (p *trigger) Apply(es ...*formatters.EventMsg) []*formatters.EventMsg {
 		}
 		if res {
 			if p.evalOccurrencesWithinWindow(now) {
-				if p.Async {
+				if s := p.silenced(e); s != nil {
+					p.logger.Printf("actions not triggered, event silenced by %q", s.ID)
+					continue
+				}
+				if p.DryRun {
+					p.recordActions()
+				} else if p.Async {
 					go p.triggerActions(e)
 				} else {
 					p.triggerActions(e)
@@ -230,6 +240,14 @@ func (p *trigger) readVars() error {
 	return nil
 }
 
+// silenced returns the API silence matching the event, if any.
+func (p *trigger) silenced(e *formatters.EventMsg) *silences.Silence {
+	if p.IgnoreSilences || p.silences == nil {
+		return nil
+	}
+	return p.silences.Match(e.Tags, e.Values)
+}
+
 func (p *trigger) triggerActions(e *formatters.EventMsg) {
 	actx := &actions.Context{Input: e, Env: make(map[string]interface{}), Vars: p.vars}
 	for _, act := range p.actions {
@@ -243,6 +261,19 @@ func (p *trigger) triggerActions(e *formatters.EventMsg) {
 	}
 }
 
+// recordActions records the names of the actions triggered in dry-run mode.
+func (p *trigger) recordActions() {
+	for _, act := range p.actions {
+		p.recorded = append(p.recorded, act.NName())
+	}
+}
+
+func (p *trigger) RecordedActions() []string {
+	recorded := p.recorded
+	p.recorded = nil
+	return recorded
+}
+
 func (p *trigger) evalOccurrencesWithinWindow(now time.Time) bool {
 	if p.occurrencesTimes == nil {
 		p.occurrencesTimes = make([]time.Time, 0)
@@ -9,14 +9,17 @@
 package event_trigger
 
 import (
+	"context"
 	"log"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/gnmic/pkg/actions"
 	"github.com/openconfig/gnmic/pkg/api/utils"
 	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/silences"
 )
 
 type item struct {
@@ -366,3 +369,81 @@ func TestOccurrenceTrigger(t *testing.T) {
 		})
 	}
 }
+
+type countAction struct {
+	actions.Action
+	runs int
+}
+
+func (a *countAction) Run(context.Context, *actions.Context) (interface{}, error) {
+	a.runs++
+	return nil, nil
+}
+
+func (a *countAction) NName() string { return "count" }
+
+func TestSilencedTrigger(t *testing.T) {
+	st := silences.NewStore()
+	err := st.Set(&silences.Silence{
+		ID:       "maintenance",
+		Matchers: []*silences.Matcher{{Name: "source", Value: "r1"}},
+		EndsAt:   time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, tc := range map[string]struct {
+		ignore bool
+		source string
+		runs   int
+	}{
+		"silenced":        {source: "r1", runs: 0},
+		"not_silenced":    {source: "r2", runs: 1},
+		"ignore_silences": {source: "r1", ignore: true, runs: 1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			act := new(countAction)
+			p := formatters.EventProcessors[processorType]().(*trigger)
+			err := p.Init(map[string]interface{}{"ignore-silences": tc.ignore})
+			if err != nil {
+				t.Fatal(err)
+			}
+			p.silences = st
+			p.actions = []actions.Action{act}
+			p.Apply(&formatters.EventMsg{Tags: map[string]string{"source": tc.source}})
+			if act.runs != tc.runs {
+				t.Errorf("action ran %d time(s), want %d", act.runs, tc.runs)
+			}
+		})
+	}
+}
+
+func TestDryRunTrigger(t *testing.T) {
+	act := new(countAction)
+	p := formatters.EventProcessors[processorType]().(*trigger)
+	err := p.Init(map[string]interface{}{
+		"condition":       `.tags.source == "r1"`,
+		"dry-run":         true,
+		"max-occurrences": 3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.silences = silences.NewStore()
+	p.actions = []actions.Action{act}
+	p.Apply(
+		&formatters.EventMsg{Tags: map[string]string{"source": "r1"}},
+		&formatters.EventMsg{Tags: map[string]string{"source": "r2"}},
+		&formatters.EventMsg{Tags: map[string]string{"source": "r1"}},
+	)
+	if act.runs != 0 {
+		t.Errorf("action ran %d time(s) in dry-run mode", act.runs)
+	}
+	want := []string{"count", "count"}
+	if got := p.RecordedActions(); !cmp.Equal(got, want) {
+		t.Errorf("got recorded actions %v, want %v", got, want)
+	}
+	if got := p.RecordedActions(); len(got) != 0 {
+		t.Errorf("recorded actions not cleared: %v", got)
+	}
+}
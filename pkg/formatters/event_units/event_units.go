@@ -0,0 +1,354 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package event_units
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/api/utils"
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+const (
+	processorType = "event-units"
+	loggingPrefix = "[" + processorType + "] "
+	unitTagSuffix = "_unit"
+)
+
+// unitConverter converts the values between units,
+// the source unit is set by a rule or read from the YANG units statements.
+type unitConverter struct {
+	Rules []*rule `mapstructure:"rules,omitempty" json:"rules,omitempty"`
+	// YANG files whose leaves units statements are used
+	// as source unit by the rules without `from`.
+	YANGFiles []string `mapstructure:"yang-files,omitempty" json:"yang-files,omitempty"`
+	YANGDirs  []string `mapstructure:"yang-dirs,omitempty" json:"yang-dirs,omitempty"`
+	Debug     bool     `mapstructure:"debug,omitempty" json:"debug,omitempty"`
+
+	// YANG units indexed by schema path.
+	leafUnits map[string]string
+	logger    *log.Logger
+}
+
+type rule struct {
+	ValueNames []string `mapstructure:"value-names,omitempty" json:"value-names,omitempty"`
+	From       string   `mapstructure:"from,omitempty" json:"from,omitempty"`
+	To         string   `mapstructure:"to,omitempty" json:"to,omitempty"`
+	// decode the value as an IEEE-754 float32 before the conversion,
+	// the value is either 4 bytes, their base64 encoding or an uint32.
+	IEEEFloat32 bool `mapstructure:"ieeefloat32,omitempty" json:"ieeefloat32,omitempty"`
+
+	valueNames []*regexp.Regexp
+	from       *unit
+	to         *unit
+}
+
+func init() {
+	formatters.Register(processorType, func() formatters.EventProcessor {
+		return &unitConverter{
+			logger: log.New(io.Discard, "", 0),
+		}
+	})
+}
+
+func (p *unitConverter) Init(cfg interface{}, opts ...formatters.Option) error {
+	err := formatters.DecodeConfig(cfg, p)
+	if err != nil {
+		return err
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if len(p.Rules) == 0 {
+		return errors.New("missing rules")
+	}
+	for i, r := range p.Rules {
+		err = r.init(len(p.YANGFiles) > 0)
+		if err != nil {
+			return fmt.Errorf("rule %d: %v", i, err)
+		}
+	}
+	if len(p.YANGFiles) > 0 {
+		leaves, err := formatters.YANGLeaves(p.YANGFiles, p.YANGDirs)
+		if err != nil {
+			return err
+		}
+		p.leafUnits = make(map[string]string)
+		for pth, e := range leaves {
+			if u := leafUnits(e); u != "" {
+				p.leafUnits[pth] = u
+			}
+		}
+		p.logger.Printf("found %d YANG leaves with units", len(p.leafUnits))
+	}
+	if p.logger.Writer() != io.Discard {
+		b, err := json.Marshal(p)
+		if err != nil {
+			p.logger.Printf("initialized processor '%s': %+v", processorType, p)
+			return nil
+		}
+		p.logger.Printf("initialized processor '%s': %s", processorType, string(b))
+	}
+	return nil
+}
+
+func (r *rule) init(withYANG bool) error {
+	var err error
+	if r.To == "" && !r.IEEEFloat32 {
+		return errors.New("one of 'to' or 'ieeefloat32' must be set")
+	}
+	if r.To != "" {
+		if r.From == "" && !withYANG {
+			return errors.New("'from' must be set if no YANG files are configured")
+		}
+		r.to, err = lookupUnit(r.To)
+		if err != nil {
+			return err
+		}
+	}
+	if r.From != "" {
+		if r.To == "" {
+			return errors.New("'from' requires 'to'")
+		}
+		r.from, err = lookupUnit(r.From)
+		if err != nil {
+			return err
+		}
+		if r.from.dimension != r.to.dimension {
+			return fmt.Errorf("cannot convert %s to %s", r.From, r.To)
+		}
+	}
+	r.valueNames = make([]*regexp.Regexp, 0, len(r.ValueNames))
+	for _, reg := range r.ValueNames {
+		re, err := regexp.Compile(reg)
+		if err != nil {
+			return err
+		}
+		r.valueNames = append(r.valueNames, re)
+	}
+	return nil
+}
+
+func (r *rule) selected(name string) bool {
+	if len(r.valueNames) == 0 {
+		return true
+	}
+	for _, re := range r.valueNames {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *unitConverter) Apply(es ...*formatters.EventMsg) []*formatters.EventMsg {
+	for _, e := range es {
+		if e == nil {
+			continue
+		}
+		for k, v := range e.Values {
+			for _, r := range p.Rules {
+				if !r.selected(k) {
+					continue
+				}
+				applied, err := p.applyRule(r, e, k, v)
+				if err != nil {
+					p.logger.Printf("value %q: %v", k, err)
+					break
+				}
+				if applied {
+					break
+				}
+			}
+		}
+	}
+	return es
+}
+
+// applyRule converts the value k of event e.
+// It returns false if the rule does not apply to the value unit.
+func (p *unitConverter) applyRule(r *rule, e *formatters.EventMsg, k string, v any) (bool, error) {
+	var from *unit
+	if r.to != nil {
+		from = r.from
+		if from == nil {
+			name, ok := p.leafUnits[formatters.SchemaPath(k)]
+			if !ok {
+				return false, nil
+			}
+			var err error
+			from, err = lookupUnit(name)
+			if err != nil {
+				return false, nil
+			}
+		}
+		if from.dimension != r.to.dimension {
+			return false, nil
+		}
+	}
+	var err error
+	if r.IEEEFloat32 {
+		v, err = decodeIEEEFloat32(v)
+		if err != nil {
+			return false, err
+		}
+	}
+	orig := v
+	if r.to == nil {
+		e.Values[k] = v
+		return true, nil
+	}
+	f, err := toFloat64(v)
+	if err != nil {
+		return false, err
+	}
+	f, err = convert(f, from, r.to)
+	if err != nil {
+		return false, err
+	}
+	e.Values[k] = keepIntegerType(orig, f)
+	if e.Tags == nil {
+		e.Tags = make(map[string]string)
+	}
+	e.Tags[path.Base(k)+unitTagSuffix] = r.To
+	return true, nil
+}
+
+func (p *unitConverter) WithLogger(l *log.Logger) {
+	if p.Debug && l != nil {
+		p.logger = log.New(l.Writer(), loggingPrefix, l.Flags())
+	} else if p.Debug {
+		p.logger = log.New(os.Stderr, loggingPrefix, utils.DefaultLoggingFlags)
+	}
+}
+
+func (p *unitConverter) WithTargets(tcs map[string]*types.TargetConfig) {}
+
+func (p *unitConverter) WithActions(act map[string]map[string]interface{}) {}
+
+func (p *unitConverter) WithProcessors(procs map[string]map[string]any) {}
+
+func decodeIEEEFloat32(v any) (any, error) {
+	switch v := v.(type) {
+	case float32:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case []byte:
+		if len(v) != 4 {
+			return nil, fmt.Errorf("expecting 4 bytes for an IEEE-754 float32, got %d", len(v))
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(v))), nil
+	case string:
+		b, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, err
+		}
+		return decodeIEEEFloat32(b)
+	case uint32:
+		return float64(math.Float32frombits(v)), nil
+	case uint64:
+		if v > math.MaxUint32 {
+			return nil, fmt.Errorf("%d overflows uint32", v)
+		}
+		return float64(math.Float32frombits(uint32(v))), nil
+	case int64:
+		if v < 0 || v > math.MaxUint32 {
+			return nil, fmt.Errorf("%d overflows uint32", v)
+		}
+		return float64(math.Float32frombits(uint32(v))), nil
+	}
+	return nil, fmt.Errorf("cannot decode %T as an IEEE-754 float32", v)
+}
+
+func toFloat64(v any) (float64, error) {
+	switch v := v.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int8:
+		return float64(v), nil
+	case int16:
+		return float64(v), nil
+	case int32:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case uint:
+		return float64(v), nil
+	case uint8:
+		return float64(v), nil
+	case uint16:
+		return float64(v), nil
+	case uint32:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	case json.Number:
+		return v.Float64()
+	case string:
+		return strconv.ParseFloat(strings.TrimSpace(v), 64)
+	}
+	return 0, fmt.Errorf("cannot convert %T to a number", v)
+}
+
+// keepIntegerType returns f with the integer type of orig,
+// if orig is an integer and f has no fractional part.
+func keepIntegerType(orig any, f float64) any {
+	if f != math.Trunc(f) {
+		return f
+	}
+	switch orig.(type) {
+	case int, int8, int16, int32, int64:
+		if f >= math.MinInt64 && f < math.MaxInt64 {
+			return int64(f)
+		}
+	case uint, uint8, uint16, uint32, uint64:
+		if f >= 0 && f < math.MaxUint64 {
+			return uint64(f)
+		}
+	}
+	return f
+}
+
+// leafUnits returns the units statement of a YANG leaf or leaf-list,
+// falling back to the statement node when the entry does not carry it.
+func leafUnits(e *yang.Entry) string {
+	if e.Units != "" {
+		return e.Units
+	}
+	switch n := e.Node.(type) {
+	case *yang.Leaf:
+		if n.Units != nil {
+			return n.Units.Name
+		}
+	case *yang.LeafList:
+		if n.Units != nil {
+			return n.Units.Name
+		}
+	}
+	return ""
+}
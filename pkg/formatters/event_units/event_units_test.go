@@ -0,0 +1,180 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package event_units
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+)
+
+func TestUnitsRules(t *testing.T) {
+	p := formatters.EventProcessors[processorType]()
+	err := p.Init(map[string]interface{}{
+		"rules": []interface{}{
+			map[string]interface{}{
+				"value-names": []string{"octets$"},
+				"from":        "bytes",
+				"to":          "bits",
+			},
+			map[string]interface{}{
+				"value-names": []string{"output-power$"},
+				"from":        "dBm",
+				"to":          "mW",
+			},
+			map[string]interface{}{
+				"value-names": []string{"temperature$"},
+				"from":        "milli-celsius",
+				"to":          "celsius",
+			},
+			map[string]interface{}{
+				"value-names": []string{"bias-current$"},
+				"ieeefloat32": true,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := p.Apply(&formatters.EventMsg{
+		Name: "sub1",
+		Values: map[string]interface{}{
+			"/interface/statistics/in-octets":  int64(100),
+			"/optics/output-power":             "10",
+			"/platform/temperature":            int64(42500),
+			"/optics/bias-current":             []byte{0x40, 0x49, 0x0f, 0xdb},
+			"/interface/statistics/in-packets": int64(3),
+		},
+	})
+	want := map[string]interface{}{
+		"/interface/statistics/in-octets":  int64(800),
+		"/optics/output-power":             10.0,
+		"/platform/temperature":            42.5,
+		"/optics/bias-current":             float64(float32(math.Pi)),
+		"/interface/statistics/in-packets": int64(3),
+	}
+	if !reflect.DeepEqual(got[0].Values, want) {
+		t.Errorf("unexpected values:\n got: %v\nwant: %v", got[0].Values, want)
+	}
+	wantTags := map[string]string{
+		"in-octets_unit":    "bits",
+		"output-power_unit": "mW",
+		"temperature_unit":  "celsius",
+	}
+	if !reflect.DeepEqual(got[0].Tags, wantTags) {
+		t.Errorf("unexpected tags: %v", got[0].Tags)
+	}
+}
+
+const testModule = `
+module test-optics {
+  namespace "urn:test:optics";
+  prefix to;
+
+  container optics {
+    leaf input-power {
+      type decimal64 {
+        fraction-digits 2;
+      }
+      units dBm;
+    }
+    leaf temperature {
+      type int32;
+      units milli-celsius;
+    }
+  }
+}
+`
+
+func TestUnitsYANG(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "test-optics.yang"), []byte(testModule), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := formatters.EventProcessors[processorType]()
+	err = p.Init(map[string]interface{}{
+		"yang-files": []string{dir},
+		"rules": []interface{}{
+			map[string]interface{}{"to": "mW"},
+			map[string]interface{}{"to": "celsius"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := p.Apply(&formatters.EventMsg{
+		Name: "sub1",
+		Values: map[string]interface{}{
+			"/test-optics:optics/input-power": "-10",
+			"/optics/temperature":             int64(30000),
+		},
+	})
+	want := map[string]interface{}{
+		"/test-optics:optics/input-power": 0.1,
+		"/optics/temperature":             int64(30),
+	}
+	for k, v := range want {
+		f, ok := got[0].Values[k].(float64)
+		if ok && math.Abs(f-v.(float64)) < 1e-9 {
+			continue
+		}
+		if !reflect.DeepEqual(got[0].Values[k], v) {
+			t.Errorf("value %q: got %#v, want %#v", k, got[0].Values[k], v)
+		}
+	}
+}
+
+func TestUnitsInitErrors(t *testing.T) {
+	for name, cfg := range map[string]map[string]interface{}{
+		"no_rules":     {},
+		"unknown_unit": {"rules": []interface{}{map[string]interface{}{"from": "bytes", "to": "furlongs"}}},
+		"dimensions":   {"rules": []interface{}{map[string]interface{}{"from": "bytes", "to": "celsius"}}},
+		"missing_from": {"rules": []interface{}{map[string]interface{}{"to": "bits"}}},
+		"missing_to":   {"rules": []interface{}{map[string]interface{}{"from": "bits"}}},
+	} {
+		p := formatters.EventProcessors[processorType]()
+		if err := p.Init(cfg); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestLookupUnit(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		factor float64
+	}{
+		{"kbps", 1e3},
+		{"Gbps", 1e9},
+		{"MB", 8e6},
+		{"kilobytes", 8e3},
+		{"mW", 1e-3},
+		{"milliwatts", 1e-3},
+		{"centi-celsius", 1e-2},
+		{"ms", 1e-3},
+		{"nanoseconds", 1e-9},
+	} {
+		u, err := lookupUnit(tt.name)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if math.Abs(u.factor-tt.factor) > tt.factor*1e-9 {
+			t.Errorf("%s: got factor %v, want %v", tt.name, u.factor, tt.factor)
+		}
+	}
+	if _, err := lookupUnit("dB"); err == nil {
+		t.Errorf("dB should not be a known unit")
+	}
+}
@@ -0,0 +1,147 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package event_units
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// unit is defined by its dimension and its factor to the dimension base unit,
+// or by a conversion function for the logarithmic units.
+type unit struct {
+	name      string
+	dimension string
+	factor    float64
+	// set for logarithmic units
+	toBase   func(float64) float64
+	fromBase func(float64) float64
+}
+
+const (
+	dimData        = "data"
+	dimDataRate    = "data-rate"
+	dimPower       = "power"
+	dimTemperature = "temperature"
+	dimVoltage     = "voltage"
+	dimCurrent     = "current"
+	dimTime        = "time"
+	dimFrequency   = "frequency"
+)
+
+// base units and their aliases,
+// the data base unit is the bit and the power base unit is the watt.
+var baseUnits = []struct {
+	unit
+	aliases []string
+}{
+	{unit{name: "bits", dimension: dimData, factor: 1}, []string{"bit", "b"}},
+	{unit{name: "bytes", dimension: dimData, factor: 8}, []string{"byte", "B", "octets", "octet"}},
+	{unit{name: "bits-per-second", dimension: dimDataRate, factor: 1}, []string{"bps", "bits/s", "bit/s"}},
+	{unit{name: "bytes-per-second", dimension: dimDataRate, factor: 8}, []string{"Bps", "bytes/s", "octets-per-second"}},
+	{unit{name: "watts", dimension: dimPower, factor: 1}, []string{"watt", "W"}},
+	{unit{
+		name:      "dBm",
+		dimension: dimPower,
+		toBase:    func(v float64) float64 { return math.Pow(10, v/10) / 1000 },
+		fromBase:  func(v float64) float64 { return 10 * math.Log10(v*1000) },
+	}, []string{"dbm"}},
+	{unit{name: "celsius", dimension: dimTemperature, factor: 1}, []string{"degrees-celsius", "C", "degC"}},
+	{unit{name: "volts", dimension: dimVoltage, factor: 1}, []string{"volt", "V"}},
+	{unit{name: "amps", dimension: dimCurrent, factor: 1}, []string{"amp", "amperes", "ampere", "A"}},
+	{unit{name: "seconds", dimension: dimTime, factor: 1}, []string{"second", "sec", "s"}},
+	{unit{name: "hertz", dimension: dimFrequency, factor: 1}, []string{"Hz"}},
+}
+
+// long and short SI prefixes, the short ones only apply to the short unit names.
+var prefixes = []struct {
+	long, short string
+	factor      float64
+}{
+	{"nano", "n", 1e-9},
+	{"micro", "u", 1e-6},
+	{"milli", "m", 1e-3},
+	{"centi", "c", 1e-2},
+	{"deci", "d", 1e-1},
+	{"kilo", "k", 1e3},
+	{"mega", "M", 1e6},
+	{"giga", "G", 1e9},
+	{"tera", "T", 1e12},
+}
+
+var knownUnits = buildUnits()
+
+func buildUnits() map[string]*unit {
+	m := make(map[string]*unit)
+	for _, bu := range baseUnits {
+		u := bu.unit
+		m[u.name] = &u
+		for _, a := range bu.aliases {
+			m[a] = &u
+		}
+		if u.toBase != nil {
+			continue
+		}
+		for _, p := range prefixes {
+			if p.factor < 1 && (u.dimension == dimData || u.dimension == dimDataRate) {
+				// no fractions of bits, and dB is not a decibyte.
+				continue
+			}
+			for _, n := range append([]string{u.name}, bu.aliases...) {
+				name := p.long + n
+				if len(n) <= 3 {
+					// short forms: mW, kbps, ms, ...
+					name = p.short + n
+				}
+				if _, ok := m[name]; ok {
+					continue
+				}
+				m[name] = &unit{name: name, dimension: u.dimension, factor: u.factor * p.factor}
+				if len(n) > 3 {
+					// also accept milli-celsius, kilo-bytes, ...
+					m[p.long+"-"+n] = m[name]
+				}
+			}
+		}
+	}
+	return m
+}
+
+func lookupUnit(name string) (*unit, error) {
+	name = strings.TrimSpace(name)
+	if u, ok := knownUnits[name]; ok {
+		return u, nil
+	}
+	if u, ok := knownUnits[strings.ToLower(name)]; ok {
+		return u, nil
+	}
+	return nil, fmt.Errorf("unknown unit %q", name)
+}
+
+// convert converts v from unit from to unit to.
+func convert(v float64, from, to *unit) (float64, error) {
+	if from.dimension != to.dimension {
+		return 0, fmt.Errorf("cannot convert %s to %s", from.name, to.name)
+	}
+	if from.toBase != nil {
+		v = from.toBase(v)
+	} else {
+		v = v * from.factor
+	}
+	if to.fromBase != nil {
+		v = to.fromBase(v)
+	} else {
+		v = v / to.factor
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("cannot convert %s to %s: invalid result", from.name, to.name)
+	}
+	return v, nil
+}
@@ -66,11 +66,7 @@ func (p *write) Init(cfg interface{}, opts ...formatters.Option) error {
 		opt(p)
 	}
 	p.Condition = strings.TrimSpace(p.Condition)
-	q, err := gojq.Parse(p.Condition)
-	if err != nil {
-		return err
-	}
-	p.code, err = gojq.Compile(q)
+	p.code, err = formatters.CompileJQ(p.Condition)
 	if err != nil {
 		return err
 	}
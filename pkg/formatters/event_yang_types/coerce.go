@@ -0,0 +1,190 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package event_yang_types
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+// coerce converts v to the Go type matching the YANG type t:
+// int64 for signed integers, uint64 for unsigned integers, float64 for decimal64,
+// bool for booleans and string for strings, enumerations and identities.
+// Values of other types are returned unchanged.
+func coerce(t *yang.YangType, v any) (any, error) {
+	if vs, ok := v.([]any); ok {
+		// leaf-list
+		res := make([]any, 0, len(vs))
+		for _, item := range vs {
+			nv, err := coerce(t, item)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, nv)
+		}
+		return res, nil
+	}
+	switch t.Kind {
+	case yang.Yint8, yang.Yint16, yang.Yint32, yang.Yint64:
+		return toInt64(v)
+	case yang.Yuint8, yang.Yuint16, yang.Yuint32, yang.Yuint64:
+		return toUint64(v)
+	case yang.Ydecimal64:
+		return toFloat64(v)
+	case yang.Ybool:
+		return toBool(v)
+	case yang.Ystring, yang.Yenum, yang.Yidentityref:
+		return toString(v), nil
+	case yang.Yunion:
+		return coerceUnion(t, v)
+	}
+	return v, nil
+}
+
+// coerceUnion tries the union member types in order, the string-like ones last,
+// so that a numeric string matches a numeric member type.
+func coerceUnion(t *yang.YangType, v any) (any, error) {
+	var stringLike *yang.YangType
+	for _, mt := range t.Type {
+		switch mt.Kind {
+		case yang.Ystring, yang.Yenum, yang.Yidentityref:
+			if stringLike == nil {
+				stringLike = mt
+			}
+			continue
+		}
+		nv, err := coerce(mt, v)
+		if err == nil {
+			return nv, nil
+		}
+	}
+	if stringLike != nil {
+		return toString(v), nil
+	}
+	return nil, fmt.Errorf("%v does not match any of the union %q types", v, t.Name)
+}
+
+func toInt64(v any) (any, error) {
+	switch v := v.(type) {
+	case int:
+		return int64(v), nil
+	case int8:
+		return int64(v), nil
+	case int16:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case uint, uint8, uint16, uint32, uint64:
+		u, _ := toUint64(v)
+		if u.(uint64) > math.MaxInt64 {
+			return nil, fmt.Errorf("%v overflows int64", v)
+		}
+		return int64(u.(uint64)), nil
+	case float32:
+		return floatToInt64(float64(v))
+	case float64:
+		return floatToInt64(v)
+	case json.Number:
+		return strconv.ParseInt(v.String(), 10, 64)
+	case string:
+		return strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+	}
+	return nil, fmt.Errorf("cannot convert %T to int64", v)
+}
+
+func floatToInt64(f float64) (any, error) {
+	if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return nil, fmt.Errorf("%v is not an int64", f)
+	}
+	return int64(f), nil
+}
+
+func toUint64(v any) (any, error) {
+	switch v := v.(type) {
+	case uint:
+		return uint64(v), nil
+	case uint8:
+		return uint64(v), nil
+	case uint16:
+		return uint64(v), nil
+	case uint32:
+		return uint64(v), nil
+	case uint64:
+		return v, nil
+	case int, int8, int16, int32, int64:
+		i, _ := toInt64(v)
+		if i.(int64) < 0 {
+			return nil, fmt.Errorf("%v is negative", v)
+		}
+		return uint64(i.(int64)), nil
+	case float32:
+		return floatToUint64(float64(v))
+	case float64:
+		return floatToUint64(v)
+	case json.Number:
+		return strconv.ParseUint(v.String(), 10, 64)
+	case string:
+		return strconv.ParseUint(strings.TrimSpace(v), 10, 64)
+	}
+	return nil, fmt.Errorf("cannot convert %T to uint64", v)
+}
+
+func floatToUint64(f float64) (any, error) {
+	if f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 {
+		return nil, fmt.Errorf("%v is not an uint64", f)
+	}
+	return uint64(f), nil
+}
+
+func toFloat64(v any) (any, error) {
+	switch v := v.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int, int8, int16, int32, int64:
+		i, _ := toInt64(v)
+		return float64(i.(int64)), nil
+	case uint, uint8, uint16, uint32, uint64:
+		u, _ := toUint64(v)
+		return float64(u.(uint64)), nil
+	case json.Number:
+		return v.Float64()
+	case string:
+		return strconv.ParseFloat(strings.TrimSpace(v), 64)
+	}
+	return nil, fmt.Errorf("cannot convert %T to float64", v)
+}
+
+func toBool(v any) (any, error) {
+	switch v := v.(type) {
+	case bool:
+		return v, nil
+	case string:
+		return strconv.ParseBool(strings.TrimSpace(v))
+	}
+	return nil, fmt.Errorf("cannot convert %T to bool", v)
+}
+
+func toString(v any) any {
+	switch v := v.(type) {
+	case string:
+		return v
+	case nil:
+		return v
+	}
+	return fmt.Sprint(v)
+}
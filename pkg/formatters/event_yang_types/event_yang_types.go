@@ -0,0 +1,170 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package event_yang_types
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"regexp"
+
+	"github.com/openconfig/goyang/pkg/yang"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/api/utils"
+	"github.com/openconfig/gnmic/pkg/formatters"
+)
+
+const (
+	processorType = "event-yang-types"
+	loggingPrefix = "[" + processorType + "] "
+)
+
+// yangTypes converts the event values to the Go type matching
+// their YANG leaf type, e.g: a uint64 counter sent as a string becomes an uint64.
+type yangTypes struct {
+	// YANG files or directories containing YANG files, globs are accepted.
+	Files []string `mapstructure:"files,omitempty" json:"files,omitempty"`
+	// directories searched for the imported and included YANG modules.
+	Dirs       []string `mapstructure:"dirs,omitempty" json:"dirs,omitempty"`
+	ValueNames []string `mapstructure:"value-names,omitempty" json:"value-names,omitempty"`
+	Debug      bool     `mapstructure:"debug,omitempty" json:"debug,omitempty"`
+
+	valueNames []*regexp.Regexp
+	// leaf types indexed by their schema path without module prefixes,
+	// e.g: /interfaces/interface/state/counters/in-octets
+	types  map[string]*yang.YangType
+	logger *log.Logger
+}
+
+func init() {
+	formatters.Register(processorType, func() formatters.EventProcessor {
+		return &yangTypes{
+			logger: log.New(io.Discard, "", 0),
+		}
+	})
+}
+
+func (p *yangTypes) Init(cfg interface{}, opts ...formatters.Option) error {
+	err := formatters.DecodeConfig(cfg, p)
+	if err != nil {
+		return err
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if len(p.Files) == 0 {
+		return errors.New("missing YANG files")
+	}
+	p.valueNames = make([]*regexp.Regexp, 0, len(p.ValueNames))
+	for _, reg := range p.ValueNames {
+		re, err := regexp.Compile(reg)
+		if err != nil {
+			return err
+		}
+		p.valueNames = append(p.valueNames, re)
+	}
+	err = p.loadTypes()
+	if err != nil {
+		return err
+	}
+	if p.logger.Writer() != io.Discard {
+		b, err := json.Marshal(p)
+		if err != nil {
+			p.logger.Printf("initialized processor '%s': %+v", processorType, p)
+			return nil
+		}
+		p.logger.Printf("initialized processor '%s': %s", processorType, string(b))
+	}
+	return nil
+}
+
+func (p *yangTypes) Apply(es ...*formatters.EventMsg) []*formatters.EventMsg {
+	for _, e := range es {
+		if e == nil {
+			continue
+		}
+		for k, v := range e.Values {
+			if !p.selected(k) {
+				continue
+			}
+			t, ok := p.types[formatters.SchemaPath(k)]
+			if !ok {
+				continue
+			}
+			nv, err := coerce(t, v)
+			if err != nil {
+				p.logger.Printf("value %q: %v", k, err)
+				continue
+			}
+			e.Values[k] = nv
+		}
+	}
+	return es
+}
+
+func (p *yangTypes) WithLogger(l *log.Logger) {
+	if p.Debug && l != nil {
+		p.logger = log.New(l.Writer(), loggingPrefix, l.Flags())
+	} else if p.Debug {
+		p.logger = log.New(os.Stderr, loggingPrefix, utils.DefaultLoggingFlags)
+	}
+}
+
+func (p *yangTypes) WithTargets(tcs map[string]*types.TargetConfig) {}
+
+func (p *yangTypes) WithActions(act map[string]map[string]interface{}) {}
+
+func (p *yangTypes) WithProcessors(procs map[string]map[string]any) {}
+
+func (p *yangTypes) selected(name string) bool {
+	if len(p.valueNames) == 0 {
+		return true
+	}
+	for _, re := range p.valueNames {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadTypes reads the YANG modules and indexes their leaves types.
+func (p *yangTypes) loadTypes() error {
+	leaves, err := formatters.YANGLeaves(p.Files, p.Dirs)
+	if err != nil {
+		return err
+	}
+	p.types = make(map[string]*yang.YangType, len(leaves))
+	for pth, e := range leaves {
+		if t := leafType(e); t != nil {
+			p.types[pth] = t
+		}
+	}
+	p.logger.Printf("indexed %d YANG leaves types", len(p.types))
+	return nil
+}
+
+// leafType returns the type of a leaf, resolving leafrefs to the referenced leaf type.
+func leafType(e *yang.Entry) *yang.YangType {
+	t := e.Type
+	for i := 0; t != nil && t.Kind == yang.Yleafref && i < 8; i++ {
+		target := e.Find(t.Path)
+		if target == nil {
+			return nil
+		}
+		e, t = target, target.Type
+	}
+	if t != nil && t.Kind == yang.Yleafref {
+		return nil
+	}
+	return t
+}
@@ -0,0 +1,147 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package event_yang_types
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+)
+
+const testModule = `
+module test-interfaces {
+  namespace "urn:test:interfaces";
+  prefix tif;
+
+  typedef counter64 {
+    type uint64;
+  }
+
+  container interfaces {
+    list interface {
+      key name;
+      leaf name {
+        type string;
+      }
+      leaf mtu {
+        type uint16;
+      }
+      leaf enabled {
+        type boolean;
+      }
+      leaf oper-status {
+        type enumeration {
+          enum UP;
+          enum DOWN;
+        }
+      }
+      leaf temperature {
+        type decimal64 {
+          fraction-digits 2;
+        }
+      }
+      leaf offset {
+        type int32;
+      }
+      leaf speed {
+        type union {
+          type uint32;
+          type string;
+        }
+      }
+      leaf parent {
+        type leafref {
+          path "../mtu";
+        }
+      }
+      container counters {
+        leaf in-octets {
+          type counter64;
+        }
+      }
+      choice mode {
+        case routed {
+          leaf vrf {
+            type string;
+          }
+        }
+      }
+    }
+  }
+}
+`
+
+func TestYangTypes(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "test-interfaces.yang"), []byte(testModule), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := formatters.EventProcessors[processorType]()
+	err = p.Init(map[string]interface{}{
+		"files": []string{dir},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := &formatters.EventMsg{
+		Name: "sub1",
+		Values: map[string]interface{}{
+			"/interfaces/interface/counters/in-octets":                "18446744073709551615",
+			"/test-interfaces:interfaces/interface/mtu":               float64(1500),
+			"/interfaces/interface[name=ethernet-1/1]/enabled":        "true",
+			"/interfaces/interface/oper-status":                       "UP",
+			"/interfaces/interface/temperature":                       "42.5",
+			"/interfaces/interface/offset":                            "-3",
+			"/interfaces/interface/speed":                             "100000",
+			"/interfaces/interface/parent":                            "9000",
+			"/interfaces/interface/vrf":                               int64(1),
+			"/interfaces/interface/name":                              "ethernet-1/1",
+			"/interfaces/interface/unknown":                           "10",
+			"openconfig:/interfaces/interface/counters/in-octets-bad": "10",
+		},
+	}
+	want := map[string]interface{}{
+		"/interfaces/interface/counters/in-octets":                uint64(18446744073709551615),
+		"/test-interfaces:interfaces/interface/mtu":               uint64(1500),
+		"/interfaces/interface[name=ethernet-1/1]/enabled":        true,
+		"/interfaces/interface/oper-status":                       "UP",
+		"/interfaces/interface/temperature":                       42.5,
+		"/interfaces/interface/offset":                            int64(-3),
+		"/interfaces/interface/speed":                             uint64(100000),
+		"/interfaces/interface/parent":                            uint64(9000),
+		"/interfaces/interface/vrf":                               "1",
+		"/interfaces/interface/name":                              "ethernet-1/1",
+		"/interfaces/interface/unknown":                           "10",
+		"openconfig:/interfaces/interface/counters/in-octets-bad": "10",
+	}
+	got := p.Apply(in)
+	if len(got) != 1 {
+		t.Fatalf("unexpected number of events: %d", len(got))
+	}
+	for k, v := range want {
+		if !reflect.DeepEqual(got[0].Values[k], v) {
+			t.Errorf("value %q: got %#v, want %#v", k, got[0].Values[k], v)
+		}
+	}
+}
+
+func TestCoerceErrors(t *testing.T) {
+	for _, v := range []any{"abc", float64(1.5), int64(-1)} {
+		if _, err := toUint64(v); err == nil {
+			t.Errorf("expected an error converting %#v to uint64", v)
+		}
+	}
+	if _, err := toBool("maybe"); err == nil {
+		t.Errorf("expected an error converting a string to bool")
+	}
+}
@@ -10,7 +10,6 @@ package formatters
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
 	"sort"
 	"time"
@@ -29,6 +28,9 @@ type MarshalOptions struct {
 	OverrideTS       bool
 	ValuesOnly       bool
 	CalculateLatency bool
+	// wrap the events in a versioned EventEnvelope, format event only.
+	EventEnvelope  bool
+	EventSchemaURL string
 }
 
 // Marshal //
@@ -43,7 +45,7 @@ func (o *MarshalOptions) Marshal(msg proto.Message, meta map[string]string, eps
 		return protojson.MarshalOptions{Multiline: o.Multiline, Indent: o.Indent}.Marshal(msg)
 	case "prototext":
 		return prototext.MarshalOptions{Multiline: o.Multiline, Indent: o.Indent}.Marshal(msg)
-	case "event":
+	case "event", "cbor", "msgpack":
 		b := make([]byte, 0)
 		switch msg := msg.ProtoReflect().Interface().(type) {
 		case *gnmi.SubscribeResponse:
@@ -61,11 +63,7 @@ func (o *MarshalOptions) Marshal(msg proto.Message, meta map[string]string, eps
 				if len(events) == 0 {
 					return nil, nil
 				}
-				if o.Multiline {
-					b, err = json.MarshalIndent(events, "", o.Indent)
-				} else {
-					b, err = json.Marshal(events)
-				}
+				b, err = o.MarshalEvents(events)
 				if err != nil {
 					return nil, fmt.Errorf("failed marshaling format 'event': %v", err)
 				}
@@ -77,11 +75,7 @@ func (o *MarshalOptions) Marshal(msg proto.Message, meta map[string]string, eps
 				return nil, fmt.Errorf("failed converting response to events: %v", err)
 			}
 
-			if o.Multiline {
-				b, err = json.MarshalIndent(events, "", o.Indent)
-			} else {
-				b, err = json.Marshal(events)
-			}
+			b, err = o.MarshalEvents(events)
 			if err != nil {
 				return nil, fmt.Errorf("failed marshaling format 'event': %v", err)
 			}
@@ -89,6 +83,8 @@ func (o *MarshalOptions) Marshal(msg proto.Message, meta map[string]string, eps
 		default:
 			return nil, fmt.Errorf("format 'event' not supported for msg type %T", msg.ProtoReflect().Interface())
 		}
+	case "table":
+		return o.formatTable(msg, meta)
 	case "flat":
 		flatMsg, err := responseFlat(msg)
 		if err != nil {
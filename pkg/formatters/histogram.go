@@ -0,0 +1,171 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package formatters
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+)
+
+// DefaultNativeHistogramZeroThreshold is the zero bucket width of native histograms
+// when not configured, it matches the Prometheus client default.
+const DefaultNativeHistogramZeroThreshold = 2.938735877055719e-39 // 2^-128
+
+// Histogram is an event value holding a distribution of observations.
+// It carries classic buckets, native (exponential) buckets, or both.
+type Histogram struct {
+	Count uint64  `json:"count"`
+	Sum   float64 `json:"sum"`
+	// classic buckets, sorted by upper bound, with cumulative counts.
+	// the +Inf bucket is implicit, its count is Count.
+	Buckets []HistogramBucket `json:"buckets,omitempty"`
+	// native buckets, set if Schema is not nil.
+	// bucket i of schema s covers the range (b^(i-1), b^i] with b = 2^(2^-s).
+	Schema          *int32         `json:"schema,omitempty"`
+	ZeroThreshold   float64        `json:"zero-threshold,omitempty"`
+	ZeroCount       uint64         `json:"zero-count,omitempty"`
+	PositiveBuckets map[int]uint64 `json:"positive-buckets,omitempty"`
+	NegativeBuckets map[int]uint64 `json:"negative-buckets,omitempty"`
+}
+
+type HistogramBucket struct {
+	UpperBound float64 `json:"le"`
+	Count      uint64  `json:"count"`
+}
+
+// NewHistogram returns an empty histogram with classic buckets
+// at the given upper bounds and, if schema is not nil, native buckets.
+func NewHistogram(bounds []float64, schema *int32, zeroThreshold float64) *Histogram {
+	h := &Histogram{}
+	if len(bounds) > 0 {
+		sorted := make([]float64, len(bounds))
+		copy(sorted, bounds)
+		sort.Float64s(sorted)
+		h.Buckets = make([]HistogramBucket, 0, len(sorted))
+		for _, b := range sorted {
+			if math.IsInf(b, 1) {
+				continue
+			}
+			h.Buckets = append(h.Buckets, HistogramBucket{UpperBound: b})
+		}
+	}
+	if schema != nil {
+		s := *schema
+		h.Schema = &s
+		h.ZeroThreshold = zeroThreshold
+		if h.ZeroThreshold <= 0 {
+			h.ZeroThreshold = DefaultNativeHistogramZeroThreshold
+		}
+		h.PositiveBuckets = make(map[int]uint64)
+		h.NegativeBuckets = make(map[int]uint64)
+	}
+	return h
+}
+
+// IsNative returns true if the histogram has native buckets.
+func (h *Histogram) IsNative() bool {
+	return h.Schema != nil
+}
+
+// Observe adds the value v to the histogram.
+func (h *Histogram) Observe(v float64) {
+	if math.IsNaN(v) {
+		return
+	}
+	h.Count++
+	h.Sum += v
+	for i := range h.Buckets {
+		if v <= h.Buckets[i].UpperBound {
+			h.Buckets[i].Count++
+		}
+	}
+	if h.Schema == nil {
+		return
+	}
+	switch {
+	case math.Abs(v) <= h.ZeroThreshold:
+		h.ZeroCount++
+	case v > 0:
+		h.PositiveBuckets[nativeBucketIndex(v, *h.Schema)]++
+	default:
+		h.NegativeBuckets[nativeBucketIndex(-v, *h.Schema)]++
+	}
+}
+
+// nativeBucketIndex returns the index of the native bucket of schema s holding v > 0.
+func nativeBucketIndex(v float64, s int32) int {
+	if math.IsInf(v, 1) {
+		v = math.MaxFloat64
+	}
+	return int(math.Ceil(math.Log2(v) * math.Exp2(float64(s))))
+}
+
+// Clone returns a deep copy of the histogram.
+func (h *Histogram) Clone() *Histogram {
+	nh := &Histogram{
+		Count:         h.Count,
+		Sum:           h.Sum,
+		ZeroThreshold: h.ZeroThreshold,
+		ZeroCount:     h.ZeroCount,
+	}
+	if h.Buckets != nil {
+		nh.Buckets = make([]HistogramBucket, len(h.Buckets))
+		copy(nh.Buckets, h.Buckets)
+	}
+	if h.Schema != nil {
+		s := *h.Schema
+		nh.Schema = &s
+	}
+	nh.PositiveBuckets = cloneBuckets(h.PositiveBuckets)
+	nh.NegativeBuckets = cloneBuckets(h.NegativeBuckets)
+	return nh
+}
+
+func cloneBuckets(m map[int]uint64) map[int]uint64 {
+	if m == nil {
+		return nil
+	}
+	nm := make(map[int]uint64, len(m))
+	for k, v := range m {
+		nm[k] = v
+	}
+	return nm
+}
+
+// ToHistogram returns the histogram held by the event value v.
+// Besides *Histogram values, it accepts the map form of a histogram
+// received as JSON, i.e with a "count" and either "buckets" or "schema".
+func ToHistogram(v interface{}) (*Histogram, bool) {
+	switch v := v.(type) {
+	case *Histogram:
+		return v, v != nil
+	case Histogram:
+		return &v, true
+	case map[string]interface{}:
+		if _, ok := v["count"]; !ok {
+			return nil, false
+		}
+		_, hasBuckets := v["buckets"]
+		_, hasSchema := v["schema"]
+		if !hasBuckets && !hasSchema {
+			return nil, false
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, false
+		}
+		h := new(Histogram)
+		if err := json.Unmarshal(b, h); err != nil {
+			return nil, false
+		}
+		return h, true
+	}
+	return nil, false
+}
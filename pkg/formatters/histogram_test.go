@@ -0,0 +1,99 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package formatters
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestHistogramObserveClassic(t *testing.T) {
+	h := NewHistogram([]float64{10, 1, 5}, nil, 0)
+	for _, v := range []float64{0.5, 1, 3, 7, 20} {
+		h.Observe(v)
+	}
+	want := &Histogram{
+		Count: 5,
+		Sum:   31.5,
+		Buckets: []HistogramBucket{
+			{UpperBound: 1, Count: 2},
+			{UpperBound: 5, Count: 3},
+			{UpperBound: 10, Count: 4},
+		},
+	}
+	if !cmp.Equal(h, want) {
+		t.Errorf("unexpected histogram: %s", cmp.Diff(want, h))
+	}
+	if h.IsNative() {
+		t.Errorf("expected a classic histogram")
+	}
+}
+
+func TestHistogramObserveNative(t *testing.T) {
+	schema := int32(0)
+	h := NewHistogram(nil, &schema, 0)
+	for _, v := range []float64{0, 1, 2, 3, 4, -3} {
+		h.Observe(v)
+	}
+	if !h.IsNative() {
+		t.Fatalf("expected a native histogram")
+	}
+	if h.ZeroThreshold != DefaultNativeHistogramZeroThreshold || h.ZeroCount != 1 {
+		t.Errorf("unexpected zero bucket: threshold=%g count=%d", h.ZeroThreshold, h.ZeroCount)
+	}
+	// schema 0 buckets: (0.5,1] -> 0, (1,2] -> 1, (2,4] -> 2
+	wantPositive := map[int]uint64{0: 1, 1: 1, 2: 2}
+	if !cmp.Equal(h.PositiveBuckets, wantPositive) {
+		t.Errorf("unexpected positive buckets: %s", cmp.Diff(wantPositive, h.PositiveBuckets))
+	}
+	wantNegative := map[int]uint64{2: 1}
+	if !cmp.Equal(h.NegativeBuckets, wantNegative) {
+		t.Errorf("unexpected negative buckets: %s", cmp.Diff(wantNegative, h.NegativeBuckets))
+	}
+}
+
+func TestHistogramClone(t *testing.T) {
+	schema := int32(2)
+	h := NewHistogram([]float64{1}, &schema, 0)
+	h.Observe(0.5)
+	c := h.Clone()
+	h.Observe(0.7)
+	if c.Count != 1 || c.Buckets[0].Count != 1 || len(c.PositiveBuckets) != 1 {
+		t.Errorf("clone changed by a later observation: %+v", c)
+	}
+}
+
+func TestToHistogram(t *testing.T) {
+	schema := int32(1)
+	h := NewHistogram([]float64{1, 2}, &schema, 0)
+	h.Observe(1.5)
+	b, err := json.Marshal(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := make(map[string]interface{})
+	if err := json.Unmarshal(b, &m); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := ToHistogram(m)
+	if !ok {
+		t.Fatalf("map %v not recognized as a histogram", m)
+	}
+	if !cmp.Equal(got, h, cmpopts.EquateEmpty()) {
+		t.Errorf("unexpected histogram: %s", cmp.Diff(h, got))
+	}
+	for _, v := range []interface{}{1, "1", map[string]interface{}{"count": 1}, (*Histogram)(nil)} {
+		if _, ok := ToHistogram(v); ok {
+			t.Errorf("value %v recognized as a histogram", v)
+		}
+	}
+}
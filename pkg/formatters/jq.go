@@ -0,0 +1,106 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package formatters
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/itchyny/gojq"
+)
+
+// JQLibrary is a set of jq function definitions, shared by all the jq expressions.
+type JQLibrary struct {
+	// jq function definitions, e.g: `def is_up: .values["oper-state"] == "UP";`
+	Source string `mapstructure:"source,omitempty" json:"source,omitempty"`
+	// path to a file containing the function definitions,
+	// read into Source when the config is loaded.
+	File string `mapstructure:"file,omitempty" json:"file,omitempty"`
+	// if true, the library functions are available to all jq expressions
+	// without an import or include statement.
+	Global bool `mapstructure:"global,omitempty" json:"global,omitempty"`
+}
+
+// jqModuleLoader implements the gojq module loader interface,
+// it returns the libraries parsed when they are set.
+type jqModuleLoader struct {
+	modules map[string]*gojq.Query
+	init    []*gojq.Query
+}
+
+func (l *jqModuleLoader) LoadModule(name string) (*gojq.Query, error) {
+	if q, ok := l.modules[name]; ok {
+		return q, nil
+	}
+	return nil, fmt.Errorf("jq library %q not found", name)
+}
+
+func (l *jqModuleLoader) LoadInitModules() ([]*gojq.Query, error) {
+	return l.init, nil
+}
+
+var (
+	jqMu     sync.RWMutex
+	jqLoader = &jqModuleLoader{modules: map[string]*gojq.Query{}}
+)
+
+// SetJQLibraries parses the jq libraries and makes them available to
+// the expressions compiled with CompileJQ, using `import "name" as name;`
+// or `include "name";`.
+func SetJQLibraries(libs map[string]*JQLibrary) error {
+	l := &jqModuleLoader{modules: make(map[string]*gojq.Query, len(libs))}
+	names := make([]string, 0, len(libs))
+	for name := range libs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		lib := libs[name]
+		if lib == nil || lib.Source == "" {
+			return fmt.Errorf("jq library %q: missing source", name)
+		}
+		q, err := gojq.Parse(lib.Source)
+		if err != nil {
+			return fmt.Errorf("jq library %q: %v", name, err)
+		}
+		l.modules[name] = q
+		if lib.Global {
+			l.init = append(l.init, q)
+		}
+	}
+	// compile each library to report errors such as undefined functions
+	// before the libraries are used.
+	for _, name := range names {
+		q, err := gojq.Parse(fmt.Sprintf("import %q as lib; .", name))
+		if err == nil {
+			_, err = gojq.Compile(q, gojq.WithModuleLoader(l))
+		}
+		if err != nil {
+			return fmt.Errorf("jq library %q: %v", name, err)
+		}
+	}
+	jqMu.Lock()
+	defer jqMu.Unlock()
+	jqLoader = l
+	return nil
+}
+
+// CompileJQ parses and compiles the jq expression,
+// with access to the configured jq libraries.
+func CompileJQ(expr string) (*gojq.Code, error) {
+	q, err := gojq.Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	jqMu.RLock()
+	l := jqLoader
+	jqMu.RUnlock()
+	return gojq.Compile(q, gojq.WithModuleLoader(l))
+}
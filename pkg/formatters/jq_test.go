@@ -0,0 +1,63 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package formatters
+
+import (
+	"testing"
+)
+
+func TestCompileJQLibraries(t *testing.T) {
+	defer SetJQLibraries(nil)
+	err := SetJQLibraries(map[string]*JQLibrary{
+		"net": {Source: `def is_mgmt: .tags.interface_name | startswith("mgmt");`},
+		"num": {Source: `def double: . * 2;`, Global: true},
+		"ops": {Source: `include "num"; def quad: double | double;`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		expr  string
+		input any
+		want  any
+	}{
+		{expr: `import "net" as net; net::is_mgmt`, input: map[string]any{"tags": map[string]any{"interface_name": "mgmt0"}}, want: true},
+		{expr: `include "net"; is_mgmt`, input: map[string]any{"tags": map[string]any{"interface_name": "ethernet-1/1"}}, want: false},
+		{expr: `double`, input: 2, want: 4},
+		{expr: `import "ops" as ops; ops::quad`, input: 2, want: 8},
+	}
+	for _, tt := range tests {
+		code, err := CompileJQ(tt.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.expr, err)
+		}
+		got, _ := code.Run(tt.input).Next()
+		if got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+	_, err = CompileJQ(`include "unknown"; .`)
+	if err == nil {
+		t.Errorf("expected an error including an unknown library")
+	}
+}
+
+func TestSetJQLibrariesErrors(t *testing.T) {
+	defer SetJQLibraries(nil)
+	for name, lib := range map[string]*JQLibrary{
+		"empty":     {},
+		"syntax":    {Source: `def f: .[;`},
+		"undefined": {Source: `def f: nosuchfn;`},
+	} {
+		err := SetJQLibraries(map[string]*JQLibrary{name: lib})
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package formatters
+
+import (
+	"context"
+	"sync"
+)
+
+// KV stores arbitrary values shared by the gNMIc instances,
+// it is implemented by the clustering lockers able to do so.
+type KV interface {
+	// Get returns the value of the given key, or nil if it does not exist.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Put sets the value of the given key.
+	Put(ctx context.Context, key string, val []byte) error
+}
+
+var (
+	kvMu sync.RWMutex
+	kv   KV
+)
+
+// SetKV sets the KV store available to the event processors.
+func SetKV(s KV) {
+	kvMu.Lock()
+	defer kvMu.Unlock()
+	kv = s
+}
+
+// GetKV returns the KV store available to the event processors,
+// or nil if none is configured.
+func GetKV() KV {
+	kvMu.RLock()
+	defer kvMu.RUnlock()
+	return kv
+}
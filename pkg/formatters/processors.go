@@ -12,6 +12,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/itchyny/gojq"
 	"github.com/mitchellh/mapstructure"
@@ -25,6 +26,7 @@ var EventProcessorTypes = []string{
 	"event-add-tag",
 	"event-allow",
 	"event-convert",
+	"event-copy-fields",
 	"event-date-string",
 	"event-delete",
 	"event-drop",
@@ -33,6 +35,8 @@ var EventProcessorTypes = []string{
 	"event-merge",
 	"event-override-ts",
 	"event-rate-limit",
+	"event-sample",
+	"event-silence",
 	"event-strings",
 	"event-to-tag",
 	"event-trigger",
@@ -42,6 +46,11 @@ var EventProcessorTypes = []string{
 	"event-value-tag",
 	"event-starlark",
 	"event-combine",
+	"event-split",
+	"event-histogram",
+	"event-path-normalize",
+	"event-yang-types",
+	"event-units",
 }
 
 type Initializer func() EventProcessor
@@ -61,11 +70,52 @@ type EventProcessor interface {
 	WithProcessors(procs map[string]map[string]any)
 }
 
+// Flusher is implemented by the event processors keeping events
+// across their runs. Flush returns and forgets the buffered events.
+type Flusher interface {
+	Flush() []*EventMsg
+}
+
+// ActionsRecorder is implemented by the event processors running actions.
+// In dry-run mode they record the actions they would run instead of running them,
+// RecordedActions returns and forgets the names of the recorded actions.
+type ActionsRecorder interface {
+	RecordedActions() []string
+}
+
+// FlushEventProcessors flushes the buffered events of the processors chain evps in order,
+// the events flushed by a processor are applied to the processors following it,
+// which may buffer them until they are flushed in turn.
+func FlushEventProcessors(evps []EventProcessor) []*EventMsg {
+	var evs []*EventMsg
+	for i, p := range evps {
+		f, ok := p.(Flusher)
+		if !ok {
+			continue
+		}
+		fevs := f.Flush()
+		if len(fevs) == 0 {
+			continue
+		}
+		for _, np := range evps[i+1:] {
+			if len(fevs) == 0 {
+				break
+			}
+			fevs = np.Apply(fevs...)
+		}
+		evs = append(evs, fevs...)
+	}
+	return evs
+}
+
 func DecodeConfig(src, dst interface{}) error {
 	decoder, err := mapstructure.NewDecoder(
 		&mapstructure.DecoderConfig{
-			DecodeHook: mapstructure.StringToTimeDurationHookFunc(),
-			Result:     dst,
+			DecodeHook: mapstructure.ComposeDecodeHookFunc(
+				mapstructure.StringToTimeDurationHookFunc(),
+				mapstructure.StringToTimeHookFunc(time.RFC3339),
+			),
+			Result: dst,
 		},
 	)
 	if err != nil {
@@ -142,30 +192,19 @@ func MakeEventProcessors(
 ) ([]EventProcessor, error) {
 	evps := make([]EventProcessor, len(processorNames))
 	for i, epName := range processorNames {
-		if epCfg, ok := ps[epName]; ok {
-			epType := ""
-			for k := range epCfg {
-				epType = k
-				break
-			}
-			if in, ok := EventProcessors[epType]; ok {
-				ep := in()
-				err := ep.Init(epCfg[epType],
-					WithLogger(logger),
-					WithTargets(tcs),
-					WithActions(acts),
-					WithProcessors(ps),
-				)
-				if err != nil {
-					return nil, fmt.Errorf("failed initializing event processor '%s' of type='%s': %w", epName, epType, err)
-				}
-				evps[i] = ep
-				logger.Printf("added event processor '%s' of type=%s to output", epName, epType)
-				continue
-			}
-			return nil, fmt.Errorf("%q event processor has an unknown type=%q", epName, epType)
+		ep, err := NewEventProcessor(epName, ps,
+			WithLogger(logger),
+			WithTargets(tcs),
+			WithActions(acts),
+			WithProcessors(ps),
+		)
+		if err != nil {
+			return nil, err
+		}
+		evps[i] = ep
+		for epType := range ps[epName] {
+			logger.Printf("added event processor '%s' of type=%s to output", epName, epType)
 		}
-		return nil, fmt.Errorf("%q event processor not found", epName)
 	}
 	return evps, nil
 }
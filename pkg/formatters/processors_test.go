@@ -9,10 +9,13 @@
 package formatters
 
 import (
+	"log"
 	"testing"
 	"time"
 
 	"github.com/itchyny/gojq"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
 )
 
 var testset = map[string]struct {
@@ -74,3 +77,65 @@ func TestCheckCondition(t *testing.T) {
 		})
 	}
 }
+
+// bufferProcessor buffers the events with the tag buffer
+// and tags the events it passes through with its name.
+type bufferProcessor struct {
+	name    string
+	flusher bool
+	pending []*EventMsg
+}
+
+func (p *bufferProcessor) Init(interface{}, ...Option) error { return nil }
+func (p *bufferProcessor) Apply(evs ...*EventMsg) []*EventMsg {
+	res := make([]*EventMsg, 0, len(evs))
+	for _, ev := range evs {
+		if _, ok := ev.Tags["buffer"]; ok && p.flusher {
+			p.pending = append(p.pending, ev)
+			continue
+		}
+		ev.Tags[p.name] = "applied"
+		res = append(res, ev)
+	}
+	return res
+}
+func (p *bufferProcessor) Flush() []*EventMsg {
+	evs := p.pending
+	p.pending = nil
+	for _, ev := range evs {
+		delete(ev.Tags, "buffer")
+	}
+	return evs
+}
+func (p *bufferProcessor) WithTargets(map[string]*types.TargetConfig)    {}
+func (p *bufferProcessor) WithLogger(*log.Logger)                        {}
+func (p *bufferProcessor) WithActions(map[string]map[string]interface{}) {}
+func (p *bufferProcessor) WithProcessors(map[string]map[string]any)      {}
+
+// notFlusher hides the Flush method of the wrapped processor.
+type notFlusher struct{ EventProcessor }
+
+func TestFlushEventProcessors(t *testing.T) {
+	p1 := &bufferProcessor{name: "p1", flusher: true}
+	p2 := notFlusher{&bufferProcessor{name: "p2"}}
+	p3 := &bufferProcessor{name: "p3", flusher: true}
+	evps := []EventProcessor{p1, p2, p3}
+
+	p1.Apply(&EventMsg{Name: "e1", Tags: map[string]string{"buffer": ""}})
+	p3.Apply(&EventMsg{Name: "e0", Tags: map[string]string{"buffer": ""}})
+	// the events flushed by p1 go through p2 and p3,
+	// the events flushed by p3 do not go through p2.
+	evs := FlushEventProcessors(evps)
+	if len(evs) != 2 {
+		t.Fatalf("expected 2 flushed events, got %v", evs)
+	}
+	if evs[0].Name != "e1" || evs[0].Tags["p2"] != "applied" || evs[0].Tags["p3"] != "applied" {
+		t.Errorf("unexpected first event: %+v", evs[0])
+	}
+	if evs[1].Name != "e0" || evs[1].Tags["p2"] != "" {
+		t.Errorf("unexpected second event: %+v", evs[1])
+	}
+	if evs := FlushEventProcessors(evps); len(evs) != 0 {
+		t.Errorf("expected no events after the flush, got %v", evs)
+	}
+}
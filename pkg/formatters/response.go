@@ -0,0 +1,202 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package formatters
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+// tags added from the subscription metadata, not from path keys.
+var metaTags = map[string]struct{}{
+	"source":            {},
+	"subscription-name": {},
+	"target":            {},
+}
+
+// EventMsgToResponse converts an event back to a SubscribeResponse, the reverse of ResponseToEventMsgs.
+// The update and delete paths are rebuilt from the values names and deletes,
+// the tags named after a path element followed by '_' and a key name are added as keys to that element.
+// The returned meta holds the source and subscription-name tags.
+func EventMsgToResponse(e *EventMsg) (*gnmi.SubscribeResponse, map[string]string, error) {
+	if e == nil {
+		return nil, nil, nil
+	}
+	notif := &gnmi.Notification{
+		Timestamp: e.Timestamp,
+		Update:    make([]*gnmi.Update, 0, len(e.Values)),
+	}
+	meta := make(map[string]string)
+	if e.Name != "" {
+		meta["subscription-name"] = e.Name
+	}
+	for _, k := range []string{"source", "subscription-name"} {
+		if v, ok := e.Tags[k]; ok {
+			meta[k] = v
+		}
+	}
+	if target, ok := e.Tags["target"]; ok {
+		notif.Prefix = &gnmi.Path{Target: target}
+	}
+	// sort the values to produce the updates in a stable order
+	names := make([]string, 0, len(e.Values))
+	for k := range e.Values {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if e.Values[name] == nil {
+			continue
+		}
+		tv, err := typedValue(e.Values[name])
+		if err != nil {
+			return nil, nil, fmt.Errorf("value %q: %v", name, err)
+		}
+		notif.Update = append(notif.Update, &gnmi.Update{
+			Path: pathFromName(name, e.Tags),
+			Val:  tv,
+		})
+	}
+	for _, name := range e.Deletes {
+		notif.Delete = append(notif.Delete, pathFromName(name, e.Tags))
+	}
+	return &gnmi.SubscribeResponse{
+		Response: &gnmi.SubscribeResponse_Update{Update: notif},
+	}, meta, nil
+}
+
+// pathFromName builds a gNMI path from an event value name, e.g: origin:/elem1/elem2,
+// adding the keys found in tags.
+func pathFromName(name string, tags map[string]string) *gnmi.Path {
+	p := new(gnmi.Path)
+	if !strings.HasPrefix(name, "/") {
+		if i := strings.Index(name, ":/"); i > 0 {
+			p.Origin = name[:i]
+			name = name[i+1:]
+		}
+	}
+	for _, elem := range strings.Split(strings.Trim(name, "/"), "/") {
+		if elem == "" {
+			continue
+		}
+		p.Elem = append(p.Elem, &gnmi.PathElem{Name: elem})
+	}
+	for k, v := range tags {
+		if _, ok := metaTags[k]; ok {
+			continue
+		}
+		// tags added for conflicting keys are prefixed with the path
+		if strings.Contains(k, "/") {
+			continue
+		}
+		elem, key := keyElem(p.Elem, k)
+		if elem == nil {
+			continue
+		}
+		if elem.Key == nil {
+			elem.Key = make(map[string]string)
+		}
+		elem.Key[key] = v
+	}
+	return p
+}
+
+// keyElem returns the path element a tag belongs to and the key name,
+// the element with the longest matching name is selected, the deepest one on a tie.
+func keyElem(elems []*gnmi.PathElem, tag string) (*gnmi.PathElem, string) {
+	var match *gnmi.PathElem
+	var key string
+	for _, elem := range elems {
+		// tags use the element name without module prefix
+		name := elem.Name
+		if i := strings.LastIndex(name, ":"); i >= 0 {
+			name = name[i+1:]
+		}
+		if !strings.HasPrefix(tag, name+"_") || len(tag) == len(name)+1 {
+			continue
+		}
+		if match != nil && len(key) < len(tag)-len(name)-1 {
+			continue
+		}
+		match = elem
+		key = tag[len(name)+1:]
+	}
+	return match, key
+}
+
+func typedValue(v interface{}) (*gnmi.TypedValue, error) {
+	switch v := v.(type) {
+	case string:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_StringVal{StringVal: v}}, nil
+	case bool:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_BoolVal{BoolVal: v}}, nil
+	case int:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_IntVal{IntVal: int64(v)}}, nil
+	case int8:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_IntVal{IntVal: int64(v)}}, nil
+	case int16:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_IntVal{IntVal: int64(v)}}, nil
+	case int32:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_IntVal{IntVal: int64(v)}}, nil
+	case int64:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_IntVal{IntVal: v}}, nil
+	case uint:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: uint64(v)}}, nil
+	case uint8:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: uint64(v)}}, nil
+	case uint16:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: uint64(v)}}, nil
+	case uint32:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: uint64(v)}}, nil
+	case uint64:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: v}}, nil
+	case float32:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_DoubleVal{DoubleVal: float64(v)}}, nil
+	case float64:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_DoubleVal{DoubleVal: v}}, nil
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return &gnmi.TypedValue{Value: &gnmi.TypedValue_IntVal{IntVal: i}}, nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_DoubleVal{DoubleVal: f}}, nil
+	case []byte:
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_BytesVal{BytesVal: v}}, nil
+	case []interface{}:
+		elems := make([]*gnmi.TypedValue, 0, len(v))
+		for _, ev := range v {
+			tv, err := typedValue(ev)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := tv.Value.(*gnmi.TypedValue_JsonVal); ok {
+				// not a leaf-list
+				return jsonValue(v)
+			}
+			elems = append(elems, tv)
+		}
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_LeaflistVal{LeaflistVal: &gnmi.ScalarArray{Element: elems}}}, nil
+	}
+	return jsonValue(v)
+}
+
+func jsonValue(v interface{}) (*gnmi.TypedValue, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonVal{JsonVal: b}}, nil
+}
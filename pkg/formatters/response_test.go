@@ -0,0 +1,196 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package formatters
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestEventMsgToResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		ev       *EventMsg
+		want     *gnmi.SubscribeResponse
+		wantMeta map[string]string
+	}{
+		{
+			name: "nil",
+		},
+		{
+			name: "keys",
+			ev: &EventMsg{
+				Name:      "sub1",
+				Timestamp: 42,
+				Tags: map[string]string{
+					"source":                 "router1:57400",
+					"subscription-name":      "sub1",
+					"target":                 "router1",
+					"interface_name":         "ethernet-1/1",
+					"subinterface_index":     "0",
+					"/interface/other_index": "ignored",
+				},
+				Values: map[string]interface{}{
+					"srl_nokia-interfaces:/interface/subinterface/admin-state": "enable",
+					"/interface/subinterface/statistics/in-octets":             json.Number("100"),
+				},
+				Deletes: []string{"/interface/subinterface/description"},
+			},
+			want: &gnmi.SubscribeResponse{
+				Response: &gnmi.SubscribeResponse_Update{
+					Update: &gnmi.Notification{
+						Timestamp: 42,
+						Prefix:    &gnmi.Path{Target: "router1"},
+						Update: []*gnmi.Update{
+							{
+								Path: &gnmi.Path{Elem: []*gnmi.PathElem{
+									{Name: "interface", Key: map[string]string{"name": "ethernet-1/1"}},
+									{Name: "subinterface", Key: map[string]string{"index": "0"}},
+									{Name: "statistics"},
+									{Name: "in-octets"},
+								}},
+								Val: &gnmi.TypedValue{Value: &gnmi.TypedValue_IntVal{IntVal: 100}},
+							},
+							{
+								Path: &gnmi.Path{Origin: "srl_nokia-interfaces", Elem: []*gnmi.PathElem{
+									{Name: "interface", Key: map[string]string{"name": "ethernet-1/1"}},
+									{Name: "subinterface", Key: map[string]string{"index": "0"}},
+									{Name: "admin-state"},
+								}},
+								Val: &gnmi.TypedValue{Value: &gnmi.TypedValue_StringVal{StringVal: "enable"}},
+							},
+						},
+						Delete: []*gnmi.Path{
+							{Elem: []*gnmi.PathElem{
+								{Name: "interface", Key: map[string]string{"name": "ethernet-1/1"}},
+								{Name: "subinterface", Key: map[string]string{"index": "0"}},
+								{Name: "description"},
+							}},
+						},
+					},
+				},
+			},
+			wantMeta: map[string]string{
+				"source":            "router1:57400",
+				"subscription-name": "sub1",
+			},
+		},
+		{
+			name: "values",
+			ev: &EventMsg{
+				Name:      "sub1",
+				Timestamp: 1,
+				Values: map[string]interface{}{
+					"/a": 1.5,
+					"/b": []interface{}{"x", "y"},
+					"/c": map[string]interface{}{"k": "v"},
+					"/d": true,
+					"/e": uint64(7),
+					"/f": nil,
+				},
+			},
+			want: &gnmi.SubscribeResponse{
+				Response: &gnmi.SubscribeResponse_Update{
+					Update: &gnmi.Notification{
+						Timestamp: 1,
+						Update: []*gnmi.Update{
+							{
+								Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "a"}}},
+								Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_DoubleVal{DoubleVal: 1.5}},
+							},
+							{
+								Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "b"}}},
+								Val: &gnmi.TypedValue{Value: &gnmi.TypedValue_LeaflistVal{LeaflistVal: &gnmi.ScalarArray{
+									Element: []*gnmi.TypedValue{
+										{Value: &gnmi.TypedValue_StringVal{StringVal: "x"}},
+										{Value: &gnmi.TypedValue_StringVal{StringVal: "y"}},
+									},
+								}}},
+							},
+							{
+								Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "c"}}},
+								Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonVal{JsonVal: []byte(`{"k":"v"}`)}},
+							},
+							{
+								Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "d"}}},
+								Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_BoolVal{BoolVal: true}},
+							},
+							{
+								Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "e"}}},
+								Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_UintVal{UintVal: 7}},
+							},
+						},
+					},
+				},
+			},
+			wantMeta: map[string]string{"subscription-name": "sub1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, meta, err := EventMsgToResponse(tt.ev)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !proto.Equal(got, tt.want) {
+				t.Errorf("got:  %v", got)
+				t.Errorf("want: %v", tt.want)
+			}
+			if len(meta) != len(tt.wantMeta) {
+				t.Fatalf("meta: got %v, want %v", meta, tt.wantMeta)
+			}
+			for k, v := range tt.wantMeta {
+				if meta[k] != v {
+					t.Errorf("meta %q: got %q, want %q", k, meta[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestEventMsgToResponseRoundTrip(t *testing.T) {
+	rsp := &gnmi.SubscribeResponse{
+		Response: &gnmi.SubscribeResponse_Update{
+			Update: &gnmi.Notification{
+				Timestamp: 10,
+				Prefix:    &gnmi.Path{Target: "t1"},
+				Update: []*gnmi.Update{
+					{
+						Path: &gnmi.Path{Elem: []*gnmi.PathElem{
+							{Name: "network-instance", Key: map[string]string{"name": "default"}},
+							{Name: "protocols"},
+							{Name: "bgp"},
+							{Name: "neighbor", Key: map[string]string{"peer-address": "10.0.0.1"}},
+							{Name: "session-state"},
+						}},
+						Val: &gnmi.TypedValue{Value: &gnmi.TypedValue_StringVal{StringVal: "established"}},
+					},
+				},
+			},
+		},
+	}
+	evs, err := ResponseToEventMsgs("sub1", rsp, map[string]string{"source": "t1:57400"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evs) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(evs))
+	}
+	got, _, err := EventMsgToResponse(evs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(got, rsp) {
+		t.Errorf("got:  %v", got)
+		t.Errorf("want: %v", rsp)
+	}
+}
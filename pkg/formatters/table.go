@@ -0,0 +1,145 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package formatters
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openconfig/gnmic/pkg/api/path"
+)
+
+// TableRow is a leaf of a gNMI notification, rendered as a row by the `table` format.
+type TableRow struct {
+	Source    string
+	Path      string
+	Value     string
+	Type      string
+	Timestamp int64
+}
+
+// ResponseTableRows flattens the notifications of a gNMI Get or Subscribe response into table rows, sorted by path.
+// Deleted paths are returned with the type `delete` and an empty value.
+func ResponseTableRows(msg proto.Message, meta map[string]string) ([]*TableRow, error) {
+	var notifications []*gnmi.Notification
+	switch msg := msg.ProtoReflect().Interface().(type) {
+	case *gnmi.GetResponse:
+		notifications = msg.GetNotification()
+	case *gnmi.SubscribeResponse:
+		if n := msg.GetUpdate(); n != nil {
+			notifications = []*gnmi.Notification{n}
+		}
+	default:
+		return nil, errors.New("unsupported message type")
+	}
+	rows := make([]*TableRow, 0)
+	for _, n := range notifications {
+		prefix := path.GnmiPathToXPath(n.GetPrefix(), false)
+		for _, u := range n.GetUpdate() {
+			p := filepath.Join(prefix, path.GnmiPathToXPath(u.GetPath(), false))
+			vmap, err := getValueFlat(p, u.GetVal())
+			if err != nil {
+				return nil, err
+			}
+			typ := typedValueType(u.GetVal())
+			if len(vmap) == 0 {
+				rows = append(rows, &TableRow{
+					Source:    meta["source"],
+					Path:      p,
+					Value:     "{}",
+					Type:      typ,
+					Timestamp: n.GetTimestamp(),
+				})
+				continue
+			}
+			for vp, v := range vmap {
+				rows = append(rows, &TableRow{
+					Source:    meta["source"],
+					Path:      vp,
+					Value:     fmt.Sprintf("%v", v),
+					Type:      typ,
+					Timestamp: n.GetTimestamp(),
+				})
+			}
+		}
+		for _, d := range n.GetDelete() {
+			rows = append(rows, &TableRow{
+				Source:    meta["source"],
+				Path:      filepath.Join(prefix, path.GnmiPathToXPath(d, false)),
+				Type:      "delete",
+				Timestamp: n.GetTimestamp(),
+			})
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].Path < rows[j].Path
+	})
+	return rows, nil
+}
+
+// RenderTable writes the rows as aligned columns: path, value, type and timestamp.
+// If withSource is true, the rows source is added as the first column.
+func RenderTable(w io.Writer, rows []*TableRow, withSource bool) {
+	table := tablewriter.NewWriter(w)
+	header := []string{"PATH", "VALUE", "TYPE", "TIMESTAMP"}
+	if withSource {
+		header = append([]string{"SOURCE"}, header...)
+	}
+	table.SetHeader(header)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAutoFormatHeaders(false)
+	table.SetAutoWrapText(false)
+	for _, r := range rows {
+		ts := ""
+		if r.Timestamp > 0 {
+			ts = time.Unix(0, r.Timestamp).Format(time.RFC3339Nano)
+		}
+		row := []string{r.Path, r.Value, r.Type, ts}
+		if withSource {
+			row = append([]string{r.Source}, row...)
+		}
+		table.Append(row)
+	}
+	table.Render()
+}
+
+func (o *MarshalOptions) formatTable(msg proto.Message, meta map[string]string) ([]byte, error) {
+	rows, err := ResponseTableRows(msg, meta)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	buf := new(bytes.Buffer)
+	RenderTable(buf, rows, false)
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// typedValueType returns the name of the TypedValue set field, e.g: int_val, json_ietf_val.
+func typedValueType(tv *gnmi.TypedValue) string {
+	if tv == nil {
+		return ""
+	}
+	m := tv.ProtoReflect()
+	fd := m.WhichOneof(m.Descriptor().Oneofs().ByName("value"))
+	if fd == nil {
+		return ""
+	}
+	return string(fd.Name())
+}
@@ -0,0 +1,90 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package formatters
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+)
+
+func TestResponseTableRows(t *testing.T) {
+	rsp := &gnmi.SubscribeResponse{
+		Response: &gnmi.SubscribeResponse_Update{
+			Update: &gnmi.Notification{
+				Timestamp: 42,
+				Prefix: &gnmi.Path{Elem: []*gnmi.PathElem{
+					{Name: "interface", Key: map[string]string{"name": "ethernet-1/1"}},
+				}},
+				Update: []*gnmi.Update{
+					{
+						Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "oper-state"}}},
+						Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_StringVal{StringVal: "up"}},
+					},
+					{
+						Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "counters"}}},
+						Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonIetfVal{JsonIetfVal: []byte(`{"in-octets":"10"}`)}},
+					},
+				},
+				Delete: []*gnmi.Path{
+					{Elem: []*gnmi.PathElem{{Name: "description"}}},
+				},
+			},
+		},
+	}
+	rows, err := ResponseTableRows(rsp, map[string]string{"source": "router1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []*TableRow{
+		{Source: "router1", Path: "interface[name=ethernet-1/1]/counters/in-octets", Value: "10", Type: "json_ietf_val", Timestamp: 42},
+		{Source: "router1", Path: "interface[name=ethernet-1/1]/description", Type: "delete", Timestamp: 42},
+		{Source: "router1", Path: "interface[name=ethernet-1/1]/oper-state", Value: "up", Type: "string_val", Timestamp: 42},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		for _, r := range rows {
+			t.Logf("got: %+v", r)
+		}
+		t.Errorf("unexpected rows")
+	}
+}
+
+func TestMarshalTable(t *testing.T) {
+	rsp := &gnmi.GetResponse{
+		Notification: []*gnmi.Notification{
+			{
+				Update: []*gnmi.Update{
+					{
+						Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "system"}, {Name: "name"}}},
+						Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_AsciiVal{AsciiVal: "sr1"}},
+					},
+				},
+			},
+		},
+	}
+	mo := &MarshalOptions{Format: "table"}
+	b, err := mo.Marshal(rsp, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(string(b), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("unexpected table:\n%s", b)
+	}
+	for _, s := range []string{"PATH", "VALUE", "TYPE", "TIMESTAMP"} {
+		if !strings.Contains(lines[1], s) {
+			t.Errorf("header %q missing in %q", s, lines[1])
+		}
+	}
+	if !strings.Contains(lines[3], "system/name") || !strings.Contains(lines[3], "sr1") || !strings.Contains(lines[3], "ascii_val") {
+		t.Errorf("unexpected row %q", lines[3])
+	}
+}
@@ -0,0 +1,125 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package formatters
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/openconfig/goyang/pkg/yang"
+)
+
+// YANGLeaves reads the YANG files, or the YANG files under the given directories,
+// and returns their leaves and leaf-lists indexed by their schema path
+// without module prefixes, e.g: /interfaces/interface/state/counters/in-octets.
+// dirs are searched for the imported and included modules.
+func YANGLeaves(files, dirs []string) (map[string]*yang.Entry, error) {
+	ms := yang.NewModules()
+	for _, dir := range dirs {
+		paths, err := yang.PathsWithModules(dir)
+		if err != nil {
+			return nil, err
+		}
+		ms.AddPath(paths...)
+	}
+	yfiles, err := yangFiles(files)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range yfiles {
+		if err = ms.Read(f); err != nil {
+			return nil, err
+		}
+	}
+	if errs := ms.Process(); len(errs) > 0 {
+		return nil, fmt.Errorf("YANG processing failed: %w", errors.Join(errs...))
+	}
+	leaves := make(map[string]*yang.Entry)
+	done := make(map[string]struct{})
+	for _, m := range ms.Modules {
+		if _, ok := done[m.Name]; ok {
+			continue
+		}
+		done[m.Name] = struct{}{}
+		for _, e := range yang.ToEntry(m).Dir {
+			indexLeaves(leaves, e, "")
+		}
+	}
+	return leaves, nil
+}
+
+func indexLeaves(leaves map[string]*yang.Entry, e *yang.Entry, prefix string) {
+	pth := prefix
+	// choice and case nodes are not part of the data tree.
+	if !e.IsChoice() && !e.IsCase() {
+		pth = prefix + "/" + e.Name
+	}
+	if e.IsLeaf() || e.IsLeafList() {
+		leaves[pth] = e
+		return
+	}
+	for _, c := range e.Dir {
+		indexLeaves(leaves, c, pth)
+	}
+}
+
+// yangFiles expands the globs and directories to a list of YANG files.
+func yangFiles(patterns []string) ([]string, error) {
+	files := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no YANG file matches %q", pattern)
+		}
+		for _, m := range matches {
+			err = filepath.WalkDir(m, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if !d.IsDir() && filepath.Ext(path) == ".yang" {
+					files = append(files, path)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return files, nil
+}
+
+var predicatesRegex = regexp.MustCompile(`\[[^\]]*\]`)
+
+// SchemaPath returns the value name without its origin, keys and module prefixes,
+// as indexed by YANGLeaves.
+func SchemaPath(name string) string {
+	if !strings.HasPrefix(name, "/") {
+		if idx := strings.Index(name, ":/"); idx >= 0 && !strings.Contains(name[:idx], "/") {
+			name = name[idx+1:]
+		}
+	}
+	if strings.Contains(name, "[") {
+		name = predicatesRegex.ReplaceAllString(name, "")
+	}
+	elems := strings.Split(strings.Trim(name, "/"), "/")
+	for i, pe := range elems {
+		if idx := strings.Index(pe, ":"); idx >= 0 {
+			elems[i] = pe[idx+1:]
+		}
+	}
+	return "/" + strings.Join(elems, "/")
+}
@@ -0,0 +1,250 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gtemplate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	defaultTimeout       = 5 * time.Second
+	defaultMaxOutputSize = 16 * 1024 * 1024
+	// maximum number of timed out executions still running,
+	// the executions with a timeout fail once it is reached.
+	maxAbandoned = 64
+)
+
+var (
+	ErrTimeout          = errors.New("template execution timeout")
+	ErrOutputTooLarge   = errors.New("template output exceeds the maximum size")
+	ErrTooManyAbandoned = errors.New("too many timed out template executions still running")
+)
+
+// number of timed out executions still running
+var abandoned atomic.Int64
+
+var abandonedExecutions = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "template",
+	Name:      "abandoned_executions_total",
+	Help:      "Total number of template executions abandoned after a timeout",
+})
+
+var runningAbandonedExecutions = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+	Namespace: "gnmic",
+	Subsystem: "template",
+	Name:      "running_abandoned_executions",
+	Help:      "Number of template executions abandoned after a timeout and still running",
+}, func() float64 { return float64(abandoned.Load()) })
+
+// Collectors returns the template execution metrics.
+func Collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		abandonedExecutions,
+		runningAbandonedExecutions,
+	}
+}
+
+// Limits bounds the execution of a template,
+// so that a faulty template cannot block an output worker or exhaust the memory.
+type Limits struct {
+	// maximum execution time.
+	Timeout time.Duration `mapstructure:"timeout,omitempty" json:"timeout,omitempty"`
+	// maximum size of the rendered text, in bytes.
+	MaxOutputSize int `mapstructure:"max-output-size,omitempty" json:"max-output-size,omitempty"`
+}
+
+var (
+	limitsMu      = new(sync.RWMutex)
+	defaultLimits = Limits{
+		Timeout:       defaultTimeout,
+		MaxOutputSize: defaultMaxOutputSize,
+	}
+)
+
+// SetDefaultLimits sets the limits used by Execute,
+// zero values are replaced by the built-in defaults.
+func SetDefaultLimits(l Limits) {
+	if l.Timeout <= 0 {
+		l.Timeout = defaultTimeout
+	}
+	if l.MaxOutputSize <= 0 {
+		l.MaxOutputSize = defaultMaxOutputSize
+	}
+	limitsMu.Lock()
+	defer limitsMu.Unlock()
+	defaultLimits = l
+}
+
+// DefaultLimits returns the limits used by Execute.
+func DefaultLimits() Limits {
+	limitsMu.RLock()
+	defer limitsMu.RUnlock()
+	return defaultLimits
+}
+
+// Execute applies the template to data and writes the result to w,
+// within the default limits.
+func Execute(w io.Writer, tpl *template.Template, data interface{}) error {
+	return ExecuteWithLimits(w, tpl, data, DefaultLimits())
+}
+
+// ExecuteWithLimits applies the template to data and writes the result to w.
+// The output is written to w only if the execution succeeds.
+// Without timeout, the template is executed in the calling goroutine.
+// On timeout, the execution is aborted at the template's next write,
+// a template looping without writing keeps running in the background until it ends.
+// At most maxAbandoned such executions run in the background, further executions
+// with a timeout fail with ErrTooManyAbandoned until some of them end.
+func ExecuteWithLimits(w io.Writer, tpl *template.Template, data interface{}, l Limits) error {
+	lw := &limitedWriter{max: l.MaxOutputSize}
+	if l.Timeout <= 0 {
+		err := execute(lw, tpl, data)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(lw.buf.Bytes())
+		return err
+	}
+	if abandoned.Load() >= maxAbandoned {
+		return fmt.Errorf("template %q: %w (%d)", tpl.Name(), ErrTooManyAbandoned, maxAbandoned)
+	}
+	done := make(chan error, 1)
+	go func() {
+		err := execute(lw, tpl, data)
+		lw.finish()
+		done <- err
+	}()
+	timer := time.NewTimer(l.Timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(lw.buf.Bytes())
+		return err
+	case <-timer.C:
+		lw.abort()
+		return fmt.Errorf("template %q: %w (%s)", tpl.Name(), ErrTimeout, l.Timeout)
+	}
+}
+
+// execute applies the template to data, a panic is returned as an error.
+func execute(w io.Writer, tpl *template.Template, data interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("template %q panicked: %v", tpl.Name(), r)
+		}
+	}()
+	return tpl.Execute(w, data)
+}
+
+// limitedWriter buffers the template output,
+// it fails once the maximum size is reached or the execution is aborted.
+type limitedWriter struct {
+	m        sync.Mutex
+	buf      bytes.Buffer
+	max      int
+	aborted  bool
+	finished bool
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	w.m.Lock()
+	defer w.m.Unlock()
+	if w.aborted {
+		return 0, ErrTimeout
+	}
+	if w.max > 0 && w.buf.Len()+len(p) > w.max {
+		return 0, fmt.Errorf("%w (%d bytes)", ErrOutputTooLarge, w.max)
+	}
+	return w.buf.Write(p)
+}
+
+// abort fails the next writes of the execution,
+// it is counted as abandoned until it ends.
+func (w *limitedWriter) abort() {
+	w.m.Lock()
+	defer w.m.Unlock()
+	if w.finished {
+		return
+	}
+	w.aborted = true
+	abandoned.Add(1)
+	abandonedExecutions.Inc()
+}
+
+// finish marks the end of the execution.
+func (w *limitedWriter) finish() {
+	w.m.Lock()
+	defer w.m.Unlock()
+	w.finished = true
+	if w.aborted {
+		abandoned.Add(-1)
+	}
+}
+
+// template errors are prefixed with `template: name:line:` or `template: name:line:col:`
+var errPositionRegex = regexp.MustCompile(`template: [^:]*:(\d+)(?::(\d+))?:`)
+
+// ErrorPosition returns the line, starting at 1, and the byte offset in that line,
+// starting at 0, a template parse or execution error refers to.
+// col is -1 if the error only refers to a line.
+func ErrorPosition(err error) (line, col int, ok bool) {
+	if err == nil {
+		return 0, 0, false
+	}
+	m := errPositionRegex.FindStringSubmatch(err.Error())
+	if m == nil {
+		return 0, 0, false
+	}
+	line, _ = strconv.Atoi(m[1])
+	col = -1
+	if m[2] != "" {
+		col, _ = strconv.Atoi(m[2])
+	}
+	return line, col, true
+}
+
+// FormatError returns the error followed by the template line it refers to,
+// with a caret under the column if known.
+func FormatError(text string, err error) string {
+	line, col, ok := ErrorPosition(err)
+	lines := strings.Split(text, "\n")
+	if !ok || line < 1 || line > len(lines) {
+		return err.Error() + "\n"
+	}
+	sb := new(strings.Builder)
+	sb.WriteString(err.Error())
+	sb.WriteString("\n")
+	prefix := fmt.Sprintf("%4d | ", line)
+	sb.WriteString(prefix)
+	sb.WriteString(lines[line-1])
+	sb.WriteString("\n")
+	if col >= 0 {
+		sb.WriteString(strings.Repeat(" ", len(prefix)-2))
+		sb.WriteString("| ")
+		sb.WriteString(strings.Repeat(" ", col))
+		sb.WriteString("^\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,148 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gtemplate
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecuteWithLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		data   interface{}
+		limits Limits
+		want   string
+		err    error
+	}{
+		{
+			name:   "ok",
+			text:   `{{ .source }}`,
+			data:   map[string]interface{}{"source": "router1"},
+			limits: Limits{Timeout: time.Second, MaxOutputSize: 100},
+			want:   "router1",
+		},
+		{
+			name:   "output_too_large",
+			text:   `{{ range $i := seq 1 1000 }}xxxxxxxxxx{{ end }}`,
+			limits: Limits{Timeout: time.Second, MaxOutputSize: 100},
+			err:    ErrOutputTooLarge,
+		},
+		{
+			name: "no_timeout",
+			text: `{{ .source }}`,
+			data: map[string]interface{}{"source": "router1"},
+			want: "router1",
+		},
+		{
+			name:   "no_timeout_output_too_large",
+			text:   `{{ range $i := seq 1 1000 }}xxxxxxxxxx{{ end }}`,
+			limits: Limits{MaxOutputSize: 100},
+			err:    ErrOutputTooLarge,
+		},
+		{
+			name:   "timeout",
+			text:   `{{ range seq 1 1000 }}{{ range seq 1 100000 }}x{{ end }}{{ end }}`,
+			limits: Limits{Timeout: 10 * time.Millisecond},
+			err:    ErrTimeout,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tpl, err := CreateTemplate(tt.name, tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sb := new(strings.Builder)
+			err = ExecuteWithLimits(sb, tpl, tt.data, tt.limits)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected error %v, got %v", tt.err, err)
+				}
+				if sb.Len() != 0 {
+					t.Errorf("expected no output on error, got %d bytes", sb.Len())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sb.String() != tt.want {
+				t.Errorf("got %q, want %q", sb.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteAbandoned(t *testing.T) {
+	tpl, err := CreateTemplate("slow", `{{ range seq 1 1000 }}{{ range seq 1 100000 }}x{{ end }}{{ end }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ExecuteWithLimits(new(strings.Builder), tpl, nil, Limits{Timeout: time.Millisecond})
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("expected error %v, got %v", ErrTimeout, err)
+	}
+	// the abandoned execution ends at its next write
+	deadline := time.Now().Add(5 * time.Second)
+	for abandoned.Load() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("abandoned execution still running: %d", abandoned.Load())
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	abandoned.Add(maxAbandoned)
+	defer abandoned.Add(-maxAbandoned)
+	fast, err := CreateTemplate("fast", `{{ .source }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := map[string]interface{}{"source": "router1"}
+	err = ExecuteWithLimits(new(strings.Builder), fast, data, Limits{Timeout: time.Second})
+	if !errors.Is(err, ErrTooManyAbandoned) {
+		t.Fatalf("expected error %v, got %v", ErrTooManyAbandoned, err)
+	}
+	// executions without timeout are not affected
+	sb := new(strings.Builder)
+	err = ExecuteWithLimits(sb, fast, data, Limits{})
+	if err != nil || sb.String() != "router1" {
+		t.Fatalf("unexpected result %q: %v", sb.String(), err)
+	}
+}
+
+func TestFormatError(t *testing.T) {
+	text := "line1\n  {{ .a.b }}"
+	tpl, err := CreateTemplate("t", text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tpl.Execute(new(strings.Builder), map[string]interface{}{"a": 1})
+	if err == nil {
+		t.Fatal("expected an execution error")
+	}
+	line, col, ok := ErrorPosition(err)
+	if !ok || line != 2 || col < 0 {
+		t.Fatalf("unexpected position: line=%d col=%d ok=%v", line, col, ok)
+	}
+	got := FormatError(text, err)
+	want := "   2 |   {{ .a.b }}\n     | " + strings.Repeat(" ", col) + "^\n"
+	if !strings.HasSuffix(got, want) {
+		t.Errorf("unexpected formatted error:\n%s", got)
+	}
+
+	_, err = CreateTemplate("t", "{{ .a | nosuchfn }}")
+	line, col, ok = ErrorPosition(err)
+	if !ok || line != 1 || col != -1 {
+		t.Fatalf("unexpected position: line=%d col=%d ok=%v", line, col, ok)
+	}
+}
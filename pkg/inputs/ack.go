@@ -0,0 +1,88 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package inputs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+const DefaultAckTimeout = 30 * time.Second
+
+// ErrAckTimeout is reported when the outputs do not
+// confirm the writes of a message within the ack timeout.
+var ErrAckTimeout = errors.New("timeout waiting for outputs acknowledgement")
+
+// CheckAckOutputs returns an error if any of the outputs
+// is not able to acknowledge its writes.
+func CheckAckOutputs(outs []outputs.Output) error {
+	if len(outs) == 0 {
+		return errors.New("end-to-end acknowledgements require at least one output")
+	}
+	for _, o := range outs {
+		if _, ok := o.(outputs.Acknowledger); !ok {
+			return fmt.Errorf("output %T does not support end-to-end acknowledgements", o)
+		}
+	}
+	return nil
+}
+
+// WriteEventsAck writes the events to each of the outputs,
+// done is called with nil once all the outputs confirmed all the writes,
+// with the first write error, or with ErrAckTimeout after timeout.
+func WriteEventsAck(ctx context.Context, outs []outputs.Output, evs []*formatters.EventMsg, timeout time.Duration, done outputs.AckFunc) {
+	ack := newAckGroup(len(outs)*len(evs), timeout, done)
+	for _, o := range outs {
+		for _, ev := range evs {
+			if ao, ok := o.(outputs.Acknowledger); ok {
+				ao.WriteEventAck(ctx, ev, ack)
+				continue
+			}
+			o.WriteEvent(ctx, ev)
+			ack(nil)
+		}
+	}
+}
+
+// WriteAck writes the message to each of the outputs,
+// done is called as described in WriteEventsAck.
+func WriteAck(ctx context.Context, outs []outputs.Output, m proto.Message, meta outputs.Meta, timeout time.Duration, done outputs.AckFunc) {
+	ack := newAckGroup(len(outs), timeout, done)
+	for _, o := range outs {
+		if ao, ok := o.(outputs.Acknowledger); ok {
+			ao.WriteAck(ctx, m, meta, ack)
+			continue
+		}
+		o.Write(ctx, m, meta)
+		ack(nil)
+	}
+}
+
+func newAckGroup(n int, timeout time.Duration, done outputs.AckFunc) outputs.AckFunc {
+	var once sync.Once
+	finish := func(err error) {
+		once.Do(func() { done(err) })
+	}
+	if timeout <= 0 || n <= 0 {
+		return outputs.NewAckGroup(n, finish)
+	}
+	timer := time.AfterFunc(timeout, func() { finish(ErrAckTimeout) })
+	return outputs.NewAckGroup(n, func(err error) {
+		timer.Stop()
+		finish(err)
+	})
+}
@@ -0,0 +1,126 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package inputs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+// testAckOutput acknowledges the writes with err,
+// or never if hang is set.
+type testAckOutput struct {
+	outputs.Output
+	err    error
+	hang   bool
+	writes int
+}
+
+func (o *testAckOutput) WriteAck(_ context.Context, _ proto.Message, _ outputs.Meta, ack outputs.AckFunc) {
+	o.ack(ack)
+}
+
+func (o *testAckOutput) WriteEventAck(_ context.Context, _ *formatters.EventMsg, ack outputs.AckFunc) {
+	o.ack(ack)
+}
+
+func (o *testAckOutput) ack(ack outputs.AckFunc) {
+	o.writes++
+	if o.hang {
+		return
+	}
+	go ack(o.err)
+}
+
+func waitAck(t *testing.T, res chan error) error {
+	t.Helper()
+	select {
+	case err := <-res:
+		return err
+	case <-time.After(time.Second):
+		t.Fatal("done was not called")
+		return nil
+	}
+}
+
+func TestWriteEventsAck(t *testing.T) {
+	evs := []*formatters.EventMsg{{Name: "a"}, {Name: "b"}}
+	errWrite := errors.New("write failed")
+
+	tests := []struct {
+		name    string
+		outs    []*testAckOutput
+		evs     []*formatters.EventMsg
+		wantErr error
+	}{
+		{
+			name: "all_acked",
+			outs: []*testAckOutput{{}, {}},
+			evs:  evs,
+		},
+		{
+			name:    "one_failed",
+			outs:    []*testAckOutput{{}, {err: errWrite}},
+			evs:     evs,
+			wantErr: errWrite,
+		},
+		{
+			name:    "timeout",
+			outs:    []*testAckOutput{{}, {hang: true}},
+			evs:     evs,
+			wantErr: ErrAckTimeout,
+		},
+		{
+			name: "no_events",
+			outs: []*testAckOutput{{hang: true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outs := make([]outputs.Output, 0, len(tt.outs))
+			for _, o := range tt.outs {
+				outs = append(outs, o)
+			}
+			res := make(chan error, 2)
+			WriteEventsAck(context.TODO(), outs, tt.evs, 50*time.Millisecond, func(err error) { res <- err })
+			if err := waitAck(t, res); !errors.Is(err, tt.wantErr) {
+				t.Errorf("got %v, want %v", err, tt.wantErr)
+			}
+			for i, o := range tt.outs {
+				if o.writes != len(tt.evs) {
+					t.Errorf("output %d: got %d writes, want %d", i, o.writes, len(tt.evs))
+				}
+			}
+			// done is called once
+			time.Sleep(100 * time.Millisecond)
+			if len(res) != 0 {
+				t.Errorf("done called more than once")
+			}
+		})
+	}
+}
+
+func TestCheckAckOutputs(t *testing.T) {
+	if err := CheckAckOutputs(nil); err == nil {
+		t.Error("expected an error without outputs")
+	}
+	if err := CheckAckOutputs([]outputs.Output{&testAckOutput{}}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := CheckAckOutputs([]outputs.Output{&testAckOutput{}, &testOutput{}}); err == nil {
+		t.Error("expected an error for an output not supporting acknowledgements")
+	}
+}
@@ -9,7 +9,10 @@
 package all
 
 import (
+	_ "github.com/openconfig/gnmic/pkg/inputs/flow_input"
 	_ "github.com/openconfig/gnmic/pkg/inputs/kafka_input"
 	_ "github.com/openconfig/gnmic/pkg/inputs/nats_input"
+	_ "github.com/openconfig/gnmic/pkg/inputs/relay_input"
 	_ "github.com/openconfig/gnmic/pkg/inputs/stan_input"
+	_ "github.com/openconfig/gnmic/pkg/inputs/syslog_input"
 )
@@ -0,0 +1,113 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package inputs
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// DefaultProtoMessageType is the message type the inputs
+// decode the messages received in proto format into.
+const DefaultProtoMessageType = "gnmi.SubscribeResponse"
+
+var protoTypesMu sync.RWMutex
+
+var protoMessageTypes = map[string]func() proto.Message{
+	"gnmi.SubscribeResponse": func() proto.Message { return new(gnmi.SubscribeResponse) },
+	"gnmi.GetResponse":       func() proto.Message { return new(gnmi.GetResponse) },
+	"gnmi.Notification":      func() proto.Message { return new(gnmi.Notification) },
+}
+
+// RegisterProtoMessageType registers a concrete message type
+// the inputs proto payloads can be decoded into.
+func RegisterProtoMessageType(name string, newFn func() proto.Message) {
+	protoTypesMu.Lock()
+	defer protoTypesMu.Unlock()
+	protoMessageTypes[name] = newFn
+}
+
+// ProtoDecoder decodes the proto payloads received by an input
+// into a concrete message type.
+type ProtoDecoder struct {
+	newMsg func() proto.Message
+}
+
+// NewProtoDecoder returns a decoder of the message type msgType.
+// The type is looked up in the protoset file if set, then in the registered types
+// and in the types compiled into gNMIc. It defaults to gnmi.SubscribeResponse.
+func NewProtoDecoder(msgType, protosetFile string) (*ProtoDecoder, error) {
+	if msgType == "" {
+		msgType = DefaultProtoMessageType
+	}
+	if protosetFile != "" {
+		mt, err := protosetMessageType(protosetFile, msgType)
+		if err != nil {
+			return nil, err
+		}
+		return &ProtoDecoder{newMsg: func() proto.Message { return mt.New().Interface() }}, nil
+	}
+	protoTypesMu.RLock()
+	newFn, ok := protoMessageTypes[msgType]
+	protoTypesMu.RUnlock()
+	if ok {
+		return &ProtoDecoder{newMsg: newFn}, nil
+	}
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(msgType))
+	if err != nil {
+		return nil, fmt.Errorf("unknown proto message type %q: %v", msgType, err)
+	}
+	return &ProtoDecoder{newMsg: func() proto.Message { return mt.New().Interface() }}, nil
+}
+
+// Decode unmarshals b into a new message.
+func (d *ProtoDecoder) Decode(b []byte) (proto.Message, error) {
+	msg := d.newMsg()
+	err := proto.Unmarshal(b, msg)
+	if err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// protosetMessageType returns the type of the message msgType
+// defined in the protoset file, the file must include the messages dependencies.
+func protosetMessageType(file, msgType string) (protoreflect.MessageType, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	fds := new(descriptorpb.FileDescriptorSet)
+	err = proto.Unmarshal(b, fds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse protoset file %q: %v", file, err)
+	}
+	files, err := protodesc.NewFiles(fds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load protoset file %q: %v", file, err)
+	}
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(msgType))
+	if err != nil {
+		return nil, fmt.Errorf("message type %q not found in protoset file %q: %v", msgType, file, err)
+	}
+	md, ok := desc.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%q in protoset file %q is not a message", msgType, file)
+	}
+	return dynamicpb.NewMessageType(md), nil
+}
@@ -0,0 +1,110 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package inputs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+var testResponse = &gnmi.SubscribeResponse{
+	Response: &gnmi.SubscribeResponse_Update{
+		Update: &gnmi.Notification{
+			Timestamp: 42,
+			Prefix:    &gnmi.Path{Target: "router1"},
+		},
+	},
+}
+
+func TestProtoDecoder(t *testing.T) {
+	b, err := proto.Marshal(testResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := NewProtoDecoder("", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	msg, err := d.Decode(b)
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	rsp, ok := msg.(*gnmi.SubscribeResponse)
+	if !ok {
+		t.Fatalf("got message type %T, want *gnmi.SubscribeResponse", msg)
+	}
+	if !proto.Equal(rsp, testResponse) {
+		t.Errorf("got %v, want %v", rsp, testResponse)
+	}
+
+	if _, err := NewProtoDecoder("unknown.Message", ""); err == nil {
+		t.Error("expected an error for an unknown message type")
+	}
+}
+
+func TestProtoDecoderProtoset(t *testing.T) {
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("counter.proto"),
+		Package: proto.String("test"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Counter"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{
+					Name:     proto.String("name"),
+					JsonName: proto.String("name"),
+					Number:   proto.Int32(1),
+					Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+					Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				},
+				{
+					Name:     proto.String("value"),
+					JsonName: proto.String("value"),
+					Number:   proto.Int32(2),
+					Type:     descriptorpb.FieldDescriptorProto_TYPE_INT64.Enum(),
+					Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				},
+			},
+		}},
+	}
+	b, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{fdp}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), "counter.protoset")
+	if err := os.WriteFile(file, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := NewProtoDecoder("test.Counter", file)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// name: "ifInOctets", value: 42
+	msg, err := d.Decode([]byte{0x0a, 0x0a, 'i', 'f', 'I', 'n', 'O', 'c', 't', 'e', 't', 's', 0x10, 0x2a})
+	if err != nil {
+		t.Fatalf("failed to decode: %v", err)
+	}
+	fields := msg.ProtoReflect().Descriptor().Fields()
+	if got := msg.ProtoReflect().Get(fields.ByName("name")).String(); got != "ifInOctets" {
+		t.Errorf("got name %q, want %q", got, "ifInOctets")
+	}
+	if got := msg.ProtoReflect().Get(fields.ByName("value")).Int(); got != 42 {
+		t.Errorf("got value %d, want 42", got)
+	}
+
+	if _, err := NewProtoDecoder("test.Unknown", file); err == nil {
+		t.Error("expected an error for a message type missing from the protoset")
+	}
+}
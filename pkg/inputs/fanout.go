@@ -0,0 +1,56 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package inputs
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+const (
+	// FanOutBroadcast writes each message to all the input outputs.
+	FanOutBroadcast = "broadcast"
+	// FanOutRoundRobin writes each message to a single output,
+	// the outputs are selected in turn.
+	FanOutRoundRobin = "round-robin"
+)
+
+// FanOut selects the outputs a received message is written to.
+type FanOut struct {
+	policy  string
+	outputs []outputs.Output
+	next    atomic.Uint64
+}
+
+// NewFanOut returns a FanOut distributing the messages to outs using policy,
+// the policy defaults to broadcast.
+func NewFanOut(policy string, outs []outputs.Output) (*FanOut, error) {
+	switch policy {
+	case "":
+		policy = FanOutBroadcast
+	case FanOutBroadcast, FanOutRoundRobin:
+	default:
+		return nil, fmt.Errorf("unknown fan-out policy %q, must be one of %q or %q", policy, FanOutBroadcast, FanOutRoundRobin)
+	}
+	return &FanOut{
+		policy:  policy,
+		outputs: outs,
+	}, nil
+}
+
+// Outputs returns the outputs the next message is written to.
+func (f *FanOut) Outputs() []outputs.Output {
+	if f.policy != FanOutRoundRobin || len(f.outputs) < 2 {
+		return f.outputs
+	}
+	i := (f.next.Add(1) - 1) % uint64(len(f.outputs))
+	return f.outputs[i : i+1]
+}
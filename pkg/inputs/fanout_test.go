@@ -0,0 +1,52 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package inputs
+
+import (
+	"testing"
+
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+// testOutput embeds the outputs.Output interface,
+// the fan-out only compares the outputs identity.
+type testOutput struct {
+	outputs.Output
+	id int
+}
+
+func TestFanOut(t *testing.T) {
+	outs := []outputs.Output{&testOutput{id: 0}, &testOutput{id: 1}, &testOutput{id: 2}}
+
+	f, err := NewFanOut("", outs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := f.Outputs(); len(got) != len(outs) {
+		t.Errorf("broadcast: got %d outputs, want %d", len(got), len(outs))
+	}
+
+	f, err = NewFanOut(FanOutRoundRobin, outs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := 0; i < 2*len(outs); i++ {
+		got := f.Outputs()
+		if len(got) != 1 {
+			t.Fatalf("round-robin: got %d outputs, want 1", len(got))
+		}
+		if id := got[0].(*testOutput).id; id != i%len(outs) {
+			t.Errorf("round-robin message %d: got output %d, want %d", i, id, i%len(outs))
+		}
+	}
+
+	if _, err := NewFanOut("random", outs); err == nil {
+		t.Error("expected an error for an unknown policy")
+	}
+}
@@ -0,0 +1,259 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package flow_input
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/api/utils"
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/inputs"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+const (
+	loggingPrefix         = "[flow_input] "
+	defaultAddress        = ":6343"
+	defaultMaxDatagramLen = 65535
+
+	sflowVersion = 5
+	ipfixVersion = 10
+
+	flowEventName     = "flow"
+	countersEventName = "interface-counters"
+)
+
+func init() {
+	inputs.Register("flow", func() inputs.Input {
+		return &FlowInput{
+			Cfg:       &Config{},
+			logger:    log.New(io.Discard, loggingPrefix, utils.DefaultLoggingFlags),
+			templates: newTemplateCache(),
+		}
+	})
+}
+
+// FlowInput receives sFlow v5 and IPFIX datagrams,
+// decodes their samples and records into events
+// and writes them to its outputs.
+type FlowInput struct {
+	Cfg    *Config
+	cfn    context.CancelFunc
+	logger *log.Logger
+
+	conn    net.PacketConn
+	name    string
+	outputs []outputs.Output
+	fanOut  *inputs.FanOut
+	evps    []formatters.EventProcessor
+
+	// IPFIX templates per exporter and observation domain
+	templates *templateCache
+}
+
+// Config //
+type Config struct {
+	Name string `mapstructure:"name,omitempty" json:"name,omitempty"`
+	// UDP address the input listens on.
+	Address string `mapstructure:"address,omitempty" json:"address,omitempty"`
+	// size in bytes of the socket receive buffer.
+	ReadBufferSize int `mapstructure:"read-buffer-size,omitempty" json:"read-buffer-size,omitempty"`
+	// IPFIX templates not refreshed within this duration are removed.
+	TemplateTimeout time.Duration `mapstructure:"template-timeout,omitempty" json:"template-timeout,omitempty"`
+	Debug           bool          `mapstructure:"debug,omitempty" json:"debug,omitempty"`
+	Outputs         []string      `mapstructure:"outputs,omitempty" json:"outputs,omitempty"`
+	FanOut          string        `mapstructure:"fan-out,omitempty" json:"fan-out,omitempty"`
+	EventProcessors []string      `mapstructure:"event-processors,omitempty" json:"event-processors,omitempty"`
+}
+
+// Start //
+func (f *FlowInput) Start(ctx context.Context, name string, cfg map[string]interface{}, opts ...inputs.Option) error {
+	err := outputs.DecodeConfig(cfg, f.Cfg)
+	if err != nil {
+		return err
+	}
+	f.name = name
+	if f.Cfg.Name == "" {
+		f.Cfg.Name = name
+	}
+	for _, opt := range opts {
+		if err := opt(f); err != nil {
+			return err
+		}
+	}
+	f.setDefaults()
+	f.fanOut, err = inputs.NewFanOut(f.Cfg.FanOut, f.outputs)
+	if err != nil {
+		return err
+	}
+	f.conn, err = net.ListenPacket("udp", f.Cfg.Address)
+	if err != nil {
+		return err
+	}
+	if f.Cfg.ReadBufferSize > 0 {
+		if uc, ok := f.conn.(*net.UDPConn); ok {
+			if err := uc.SetReadBuffer(f.Cfg.ReadBufferSize); err != nil {
+				f.logger.Printf("failed to set read buffer size: %v", err)
+			}
+		}
+	}
+	ctx, f.cfn = context.WithCancel(ctx)
+	f.logger.Printf("input starting with config: %+v", f.Cfg)
+	go f.receive(ctx)
+	return nil
+}
+
+func (f *FlowInput) receive(ctx context.Context) {
+	buf := make([]byte, defaultMaxDatagramLen)
+	for {
+		n, addr, err := f.conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return
+			}
+			f.logger.Printf("failed to read datagram: %v", err)
+			continue
+		}
+		inputs.MessageReceived(f.name)
+		evs, err := f.decode(addr, buf[:n], time.Now())
+		if err != nil {
+			inputs.MessageError(f.name, inputs.ErrorReasonDecode)
+			f.logger.Printf("failed to decode datagram from %s: %v", addr, err)
+			continue
+		}
+		inputs.MessageDecoded(f.name)
+		if f.Cfg.Debug {
+			f.logger.Printf("decoded %d event(s) from %s", len(evs), addr)
+		}
+		f.dispatch(ctx, evs)
+	}
+}
+
+// decode detects the datagram protocol from its version field
+// and decodes it into events.
+func (f *FlowInput) decode(addr net.Addr, b []byte, now time.Time) ([]*formatters.EventMsg, error) {
+	if len(b) < 4 {
+		return nil, fmt.Errorf("datagram too short: %d bytes", len(b))
+	}
+	switch {
+	case binary.BigEndian.Uint16(b) == ipfixVersion:
+		return f.templates.decodeIPFIX(exporterIP(addr), b, now, f.Cfg.TemplateTimeout)
+	case binary.BigEndian.Uint32(b) == sflowVersion:
+		return decodeSFlow(b, now)
+	}
+	return nil, fmt.Errorf("unsupported datagram version %d", binary.BigEndian.Uint16(b))
+}
+
+func (f *FlowInput) dispatch(ctx context.Context, evs []*formatters.EventMsg) {
+	if len(evs) == 0 {
+		return
+	}
+	for _, p := range f.evps {
+		evs = p.Apply(evs...)
+	}
+	for _, o := range f.fanOut.Outputs() {
+		for _, ev := range evs {
+			o.WriteEvent(ctx, ev)
+		}
+	}
+}
+
+// Close //
+func (f *FlowInput) Close() error {
+	if f.cfn != nil {
+		f.cfn()
+	}
+	if f.conn != nil {
+		return f.conn.Close()
+	}
+	return nil
+}
+
+// SetLogger //
+func (f *FlowInput) SetLogger(logger *log.Logger) {
+	if logger != nil && f.logger != nil {
+		f.logger.SetOutput(logger.Writer())
+		f.logger.SetFlags(logger.Flags())
+	}
+}
+
+// SetOutputs //
+func (f *FlowInput) SetOutputs(outs map[string]outputs.Output) {
+	if len(f.Cfg.Outputs) == 0 {
+		for _, o := range outs {
+			f.outputs = append(f.outputs, o)
+		}
+		return
+	}
+	for _, name := range f.Cfg.Outputs {
+		if o, ok := outs[name]; ok {
+			f.outputs = append(f.outputs, o)
+		}
+	}
+}
+
+func (f *FlowInput) SetName(name string) {
+	sb := strings.Builder{}
+	if name != "" {
+		sb.WriteString(name)
+		sb.WriteString("-")
+	}
+	sb.WriteString(f.Cfg.Name)
+	sb.WriteString("-flow")
+	f.Cfg.Name = sb.String()
+}
+
+func (f *FlowInput) SetEventProcessors(ps map[string]map[string]interface{}, logger *log.Logger, tcs map[string]*types.TargetConfig, acts map[string]map[string]interface{}) error {
+	var err error
+	f.evps, err = formatters.MakeEventProcessors(
+		logger,
+		f.Cfg.EventProcessors,
+		ps,
+		tcs,
+		acts,
+	)
+	return err
+}
+
+func (f *FlowInput) setDefaults() {
+	if f.Cfg.Name == "" {
+		f.Cfg.Name = "gnmic-" + uuid.New().String()
+	}
+	if f.Cfg.Address == "" {
+		f.Cfg.Address = defaultAddress
+	}
+	if f.Cfg.TemplateTimeout <= 0 {
+		f.Cfg.TemplateTimeout = defaultTemplateTimeout
+	}
+}
+
+func exporterIP(addr net.Addr) string {
+	if ua, ok := addr.(*net.UDPAddr); ok {
+		return ua.IP.String()
+	}
+	if addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
@@ -0,0 +1,250 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package flow_input
+
+import (
+	"encoding/binary"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type builder struct {
+	b []byte
+}
+
+func (b *builder) u8(v uint8) *builder {
+	b.b = append(b.b, v)
+	return b
+}
+
+func (b *builder) u16(v uint16) *builder {
+	b.b = binary.BigEndian.AppendUint16(b.b, v)
+	return b
+}
+
+func (b *builder) u32(v uint32) *builder {
+	b.b = binary.BigEndian.AppendUint32(b.b, v)
+	return b
+}
+
+func (b *builder) u64(v uint64) *builder {
+	b.b = binary.BigEndian.AppendUint64(b.b, v)
+	return b
+}
+
+func (b *builder) bytes(v []byte) *builder {
+	b.b = append(b.b, v...)
+	return b
+}
+
+// xdr appends a format, length and data structure.
+func (b *builder) xdr(format uint32, data []byte) *builder {
+	return b.u32(format).u32(uint32(len(data))).bytes(data)
+}
+
+func packetHeader() []byte {
+	h := new(builder)
+	h.bytes([]byte{0, 1, 2, 3, 4, 5}).bytes([]byte{6, 7, 8, 9, 10, 11})
+	// 802.1Q tag, vlan 100
+	h.u16(0x8100).u16(100).u16(0x0800)
+	// IPv4 header, TCP
+	h.u8(0x45).u8(0).u16(40).u32(0).u8(64).u8(6).u16(0)
+	h.bytes(net.ParseIP("10.0.0.1").To4()).bytes(net.ParseIP("10.0.0.2").To4())
+	// TCP ports
+	h.u16(33000).u16(443)
+	return h.b
+}
+
+func sflowDatagram() []byte {
+	header := packetHeader()
+	raw := new(builder).u32(sflowHeaderProtoEthernet).u32(1500).u32(4).u32(uint32(len(header))).bytes(header)
+	flow := new(builder).
+		u32(1).        // sequence number
+		u32(3).        // source ID
+		u32(1000).     // sampling rate
+		u32(0).u32(0). // pool, drops
+		u32(3).u32(7). // input, output
+		u32(1).xdr(sflowRawPacketHeader, raw.b)
+	counters := new(builder).u32(1).u32(3).u32(1)
+	ifc := new(builder).
+		u32(3).u32(6).u64(10_000_000_000).u32(1).u32(3).
+		u64(1000).u32(10).u32(2).u32(1).u32(0).u32(5).u32(0).
+		u64(2000).u32(20).u32(3).u32(2).u32(1).u32(0).u32(0)
+	counters.xdr(sflowGenericIfCounters, ifc.b)
+	d := new(builder).u32(sflowVersion).u32(1).bytes(net.ParseIP("192.0.2.1").To4()).
+		u32(0).u32(1).u32(1000).u32(2)
+	d.xdr(sflowFlowSample, flow.b)
+	d.xdr(sflowCountersSample, counters.b)
+	// enterprise sample, skipped
+	d.xdr(1<<12|1, []byte{0, 0, 0, 0})
+	return d.b
+}
+
+func TestDecodeSFlow(t *testing.T) {
+	f := newTestInput()
+	now := time.Unix(100, 0)
+	evs, err := f.decode(&net.UDPAddr{IP: net.ParseIP("198.51.100.1")}, sflowDatagram(), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evs) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(evs))
+	}
+	flow := evs[0]
+	if flow.Name != flowEventName || flow.Timestamp != now.UnixNano() {
+		t.Errorf("unexpected flow event: %+v", flow)
+	}
+	wantTags := map[string]string{
+		"source":       "192.0.2.1",
+		"flow_type":    "sflow",
+		"in_if_index":  "3",
+		"out_if_index": "7",
+		"src_mac":      "06:07:08:09:0a:0b",
+		"dst_mac":      "00:01:02:03:04:05",
+		"vlan":         "100",
+		"src_ip":       "10.0.0.1",
+		"dst_ip":       "10.0.0.2",
+		"protocol":     "tcp",
+		"src_port":     "33000",
+		"dst_port":     "443",
+	}
+	if !reflect.DeepEqual(flow.Tags, wantTags) {
+		t.Errorf("unexpected flow tags:\n got: %v\nwant: %v", flow.Tags, wantTags)
+	}
+	wantValues := map[string]interface{}{
+		"sampling-rate": uint64(1000),
+		"frame-length":  uint64(1500),
+		"bytes":         uint64(1500000),
+		"packets":       uint64(1000),
+	}
+	if !reflect.DeepEqual(flow.Values, wantValues) {
+		t.Errorf("unexpected flow values:\n got: %v\nwant: %v", flow.Values, wantValues)
+	}
+	counters := evs[1]
+	if counters.Name != countersEventName || counters.Tags["if_index"] != "3" {
+		t.Errorf("unexpected counters event: %+v", counters)
+	}
+	for k, v := range map[string]interface{}{
+		"speed":            uint64(10_000_000_000),
+		"admin-status":     "UP",
+		"oper-status":      "UP",
+		"in-octets":        uint64(1000),
+		"in-errors":        uint64(5),
+		"out-octets":       uint64(2000),
+		"out-unicast-pkts": uint64(20),
+		"out-discards":     uint64(1),
+	} {
+		if counters.Values[k] != v {
+			t.Errorf("value %q: got %v, want %v", k, counters.Values[k], v)
+		}
+	}
+}
+
+func ipfixMessage(sets ...[]byte) []byte {
+	m := new(builder).u16(ipfixVersion).u16(0).u32(100).u32(1).u32(42)
+	for _, s := range sets {
+		m.u16(binary.BigEndian.Uint16(s)).u16(uint16(len(s) + 2)).bytes(s[2:])
+	}
+	binary.BigEndian.PutUint16(m.b[2:], uint16(len(m.b)))
+	return m.b
+}
+
+// set returns a set ID followed by the set content,
+// the length is inserted by ipfixMessage.
+func set(id uint16, content []byte) []byte {
+	return append(binary.BigEndian.AppendUint16(nil, id), content...)
+}
+
+func TestDecodeIPFIX(t *testing.T) {
+	f := newTestInput()
+	addr := &net.UDPAddr{IP: net.ParseIP("198.51.100.1")}
+	now := time.Unix(100, 0)
+	data := new(builder).
+		bytes(net.ParseIP("10.0.0.1").To4()).bytes(net.ParseIP("10.0.0.2").To4()).
+		u8(17).u16(53).u16(5353).u32(3).u64(4096).u64(4).
+		u8(3).bytes([]byte("abc")). // variable length field
+		u16(0)                      // padding
+	dataSet := set(256, data.b)
+	// data records before their template are skipped.
+	evs, err := f.decode(addr, ipfixMessage(dataSet), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evs) != 0 {
+		t.Fatalf("expected no events, got %d", len(evs))
+	}
+	tmpl := new(builder).u16(256).u16(9).
+		u16(8).u16(4).u16(12).u16(4).u16(4).u16(1).u16(7).u16(2).u16(11).u16(2).
+		u16(10).u16(4).u16(1).u16(8).u16(2).u16(8).
+		u16(0x8000 | 5).u16(ipfixVariableLength).u32(4242)
+	evs, err = f.decode(addr, ipfixMessage(set(ipfixTemplateSet, tmpl.b), dataSet), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evs) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(evs))
+	}
+	wantTags := map[string]string{
+		"source":      "198.51.100.1",
+		"flow_type":   "ipfix",
+		"src_ip":      "10.0.0.1",
+		"dst_ip":      "10.0.0.2",
+		"protocol":    "udp",
+		"src_port":    "53",
+		"dst_port":    "5353",
+		"in_if_index": "3",
+	}
+	if !reflect.DeepEqual(evs[0].Tags, wantTags) {
+		t.Errorf("unexpected tags:\n got: %v\nwant: %v", evs[0].Tags, wantTags)
+	}
+	wantValues := map[string]interface{}{
+		"bytes":     uint64(4096),
+		"packets":   uint64(4),
+		"ie-4242-5": uint64(0x616263),
+	}
+	if !reflect.DeepEqual(evs[0].Values, wantValues) {
+		t.Errorf("unexpected values:\n got: %v\nwant: %v", evs[0].Values, wantValues)
+	}
+	// the templates are scoped to the exporter.
+	evs, err = f.decode(&net.UDPAddr{IP: net.ParseIP("198.51.100.2")}, ipfixMessage(dataSet), now)
+	if err != nil || len(evs) != 0 {
+		t.Errorf("unexpected result for another exporter: %v, %v", evs, err)
+	}
+	// expired template
+	evs, err = f.decode(addr, ipfixMessage(dataSet), now.Add(2*defaultTemplateTimeout))
+	if err != nil || len(evs) != 0 {
+		t.Errorf("unexpected result for an expired template: %v, %v", evs, err)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	f := newTestInput()
+	for name, b := range map[string][]byte{
+		"short":           {0, 0},
+		"unknown_version": {0, 0, 0, 9},
+		"truncated_sflow": sflowDatagram()[:60],
+		"bad_ipfix_len":   new(builder).u16(ipfixVersion).u16(200).u32(0).u32(0).u32(0).b,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := f.decode(nil, b, time.Now())
+			if err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func newTestInput() *FlowInput {
+	return &FlowInput{
+		Cfg:       &Config{TemplateTimeout: defaultTemplateTimeout},
+		templates: newTemplateCache(),
+	}
+}
@@ -0,0 +1,296 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package flow_input
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+)
+
+const (
+	defaultTemplateTimeout = 30 * time.Minute
+
+	ipfixTemplateSet        = 2
+	ipfixOptionsTemplateSet = 3
+	ipfixMinDataSetID       = 256
+	ipfixVariableLength     = 65535
+)
+
+// ipfixTags maps the IANA information elements to the event tags.
+var ipfixTags = map[uint16]string{
+	4:  "protocol",
+	7:  "src_port",
+	8:  "src_ip",
+	10: "in_if_index",
+	11: "dst_port",
+	12: "dst_ip",
+	14: "out_if_index",
+	15: "next_hop",
+	16: "src_as",
+	17: "dst_as",
+	27: "src_ip",
+	28: "dst_ip",
+	56: "src_mac",
+	58: "vlan",
+	61: "flow_direction",
+	62: "next_hop",
+	80: "dst_mac",
+}
+
+// ipfixValues maps the IANA information elements to the event values names.
+var ipfixValues = map[uint16]string{
+	1:   "bytes",
+	2:   "packets",
+	5:   "tos",
+	6:   "tcp-flags",
+	9:   "src-prefix-length",
+	13:  "dst-prefix-length",
+	85:  "bytes",
+	86:  "packets",
+	136: "flow-end-reason",
+	150: "flow-start-seconds",
+	151: "flow-end-seconds",
+	152: "flow-start-milliseconds",
+	153: "flow-end-milliseconds",
+}
+
+type templateKey struct {
+	exporter string
+	domain   uint32
+	id       uint16
+}
+
+type templateField struct {
+	id         uint16
+	length     uint16
+	enterprise uint32
+}
+
+type template struct {
+	fields  []templateField
+	options bool
+	updated time.Time
+}
+
+// templateCache stores the IPFIX templates announced by the exporters.
+type templateCache struct {
+	m         sync.Mutex
+	templates map[templateKey]*template
+}
+
+func newTemplateCache() *templateCache {
+	return &templateCache{templates: make(map[templateKey]*template)}
+}
+
+func (c *templateCache) set(k templateKey, t *template) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if t == nil {
+		delete(c.templates, k)
+		return
+	}
+	c.templates[k] = t
+}
+
+// get returns the template k if it was refreshed within timeout.
+func (c *templateCache) get(k templateKey, now time.Time, timeout time.Duration) *template {
+	c.m.Lock()
+	defer c.m.Unlock()
+	t, ok := c.templates[k]
+	if !ok {
+		return nil
+	}
+	if timeout > 0 && now.Sub(t.updated) > timeout {
+		delete(c.templates, k)
+		return nil
+	}
+	return t
+}
+
+// decodeIPFIX decodes an IPFIX message, the template sets update the
+// exporter templates and each data record becomes a flow event.
+// The data records of unknown templates are skipped.
+func (c *templateCache) decodeIPFIX(exporter string, b []byte, now time.Time, timeout time.Duration) ([]*formatters.EventMsg, error) {
+	r := newReader(b)
+	r.u16() // version
+	length := int(r.u16())
+	r.u32() // export time
+	r.u32() // sequence number
+	domain := r.u32()
+	if r.err != nil {
+		return nil, r.err
+	}
+	if length < r.off || length > len(b) {
+		return nil, fmt.Errorf("invalid IPFIX message length %d", length)
+	}
+	r.b = b[:length]
+	ts := now.UnixNano()
+	var evs []*formatters.EventMsg
+	for r.remaining() > 0 {
+		setID := r.u16()
+		setLen := int(r.u16())
+		if setLen < 4 {
+			return nil, fmt.Errorf("invalid IPFIX set length %d", setLen)
+		}
+		set := newReader(r.next(setLen - 4))
+		if r.err != nil {
+			return nil, r.err
+		}
+		switch {
+		case setID == ipfixTemplateSet || setID == ipfixOptionsTemplateSet:
+			err := c.decodeTemplates(set, exporter, domain, setID == ipfixOptionsTemplateSet, now)
+			if err != nil {
+				return nil, err
+			}
+		case setID >= ipfixMinDataSetID:
+			t := c.get(templateKey{exporter: exporter, domain: domain, id: setID}, now, timeout)
+			if t == nil {
+				continue
+			}
+			for set.remaining() >= t.minLength() {
+				ev := decodeIPFIXRecord(set, t)
+				if set.err != nil {
+					return nil, fmt.Errorf("template %d record: %v", setID, set.err)
+				}
+				if t.options {
+					continue
+				}
+				ev.Timestamp = ts
+				ev.Tags["source"] = exporter
+				ev.Tags["flow_type"] = "ipfix"
+				evs = append(evs, ev)
+			}
+		}
+	}
+	return evs, nil
+}
+
+func (c *templateCache) decodeTemplates(r *reader, exporter string, domain uint32, options bool, now time.Time) error {
+	// a set is padded to a 4 bytes boundary.
+	for r.remaining() >= 4 {
+		id := r.u16()
+		count := int(r.u16())
+		k := templateKey{exporter: exporter, domain: domain, id: id}
+		if count == 0 {
+			// template withdrawal
+			c.set(k, nil)
+			continue
+		}
+		if options {
+			r.u16() // scope field count
+		}
+		t := &template{
+			fields:  make([]templateField, 0, count),
+			options: options,
+			updated: now,
+		}
+		for i := 0; i < count; i++ {
+			f := templateField{id: r.u16(), length: r.u16()}
+			if f.id&0x8000 != 0 {
+				f.id &= 0x7fff
+				f.enterprise = r.u32()
+			}
+			t.fields = append(t.fields, f)
+		}
+		if r.err != nil {
+			return fmt.Errorf("template %d: %v", id, r.err)
+		}
+		c.set(k, t)
+	}
+	return nil
+}
+
+// minLength is the length of a record with empty variable length fields.
+func (t *template) minLength() int {
+	n := 0
+	for _, f := range t.fields {
+		if f.length == ipfixVariableLength {
+			n++
+			continue
+		}
+		n += int(f.length)
+	}
+	if n == 0 {
+		return 1
+	}
+	return n
+}
+
+func decodeIPFIXRecord(r *reader, t *template) *formatters.EventMsg {
+	ev := &formatters.EventMsg{
+		Name:   flowEventName,
+		Tags:   make(map[string]string),
+		Values: make(map[string]interface{}),
+	}
+	for _, f := range t.fields {
+		length := int(f.length)
+		if f.length == ipfixVariableLength {
+			length = int(r.u8())
+			if length == 255 {
+				length = int(r.u16())
+			}
+		}
+		b := r.next(length)
+		if r.err != nil {
+			return nil
+		}
+		if t.options {
+			continue
+		}
+		if f.enterprise == 0 {
+			if tag, ok := ipfixTags[f.id]; ok {
+				ev.Tags[tag] = ipfixTagValue(f.id, b)
+				continue
+			}
+			if name, ok := ipfixValues[f.id]; ok && len(b) <= 8 {
+				ev.Values[name] = uintValue(b)
+				continue
+			}
+		}
+		name := "ie-" + strconv.Itoa(int(f.id))
+		if f.enterprise != 0 {
+			name = "ie-" + strconv.FormatUint(uint64(f.enterprise), 10) + "-" + strconv.Itoa(int(f.id))
+		}
+		if len(b) <= 8 {
+			ev.Values[name] = uintValue(b)
+		} else {
+			ev.Values[name] = hex.EncodeToString(b)
+		}
+	}
+	return ev
+}
+
+func ipfixTagValue(id uint16, b []byte) string {
+	switch id {
+	case 8, 12, 15, 27, 28, 62:
+		return net.IP(b).String()
+	case 56, 80:
+		return net.HardwareAddr(b).String()
+	case 4:
+		if len(b) == 1 {
+			return protocolName(b[0])
+		}
+	case 58:
+		return strconv.FormatUint(uintValue(b)&0x0fff, 10)
+	case 61:
+		switch uintValue(b) {
+		case 0:
+			return "ingress"
+		case 1:
+			return "egress"
+		}
+	}
+	return strconv.FormatUint(uintValue(b), 10)
+}
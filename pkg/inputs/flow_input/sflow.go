@@ -0,0 +1,285 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package flow_input
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+)
+
+// sFlow v5 standard sample and record formats.
+const (
+	sflowFlowSample             = 1
+	sflowCountersSample         = 2
+	sflowExpandedFlowSample     = 3
+	sflowExpandedCountersSample = 4
+	sflowRawPacketHeader        = 1
+	sflowExtendedSwitch         = 1001
+	sflowGenericIfCounters      = 1
+	sflowHeaderProtoEthernet    = 1
+	sflowIfIndexUnknown         = 0x3fffffff
+	sflowInterfaceFormatSingle  = 0
+)
+
+// decodeSFlow decodes an sFlow v5 datagram,
+// each flow sample becomes a flow event and each
+// generic interface counters record an interface-counters event.
+func decodeSFlow(b []byte, now time.Time) ([]*formatters.EventMsg, error) {
+	r := newReader(b)
+	r.u32() // version
+	var agent net.IP
+	switch r.u32() {
+	case 1:
+		agent = net.IP(r.next(4))
+	case 2:
+		agent = net.IP(r.next(16))
+	default:
+		return nil, fmt.Errorf("unknown sFlow agent address type")
+	}
+	r.u32() // sub agent ID
+	r.u32() // sequence number
+	r.u32() // uptime
+	numSamples := r.u32()
+	if r.err != nil {
+		return nil, r.err
+	}
+	ts := now.UnixNano()
+	source := agent.String()
+	evs := make([]*formatters.EventMsg, 0, numSamples)
+	for i := uint32(0); i < numSamples; i++ {
+		format := r.u32()
+		sample := newReader(r.next(int(r.u32())))
+		if r.err != nil {
+			return nil, r.err
+		}
+		// skip the enterprise specific samples.
+		if format>>12 != 0 {
+			continue
+		}
+		var sevs []*formatters.EventMsg
+		switch format {
+		case sflowFlowSample, sflowExpandedFlowSample:
+			sevs = decodeSFlowFlowSample(sample, format == sflowExpandedFlowSample)
+		case sflowCountersSample, sflowExpandedCountersSample:
+			sevs = decodeSFlowCountersSample(sample, format == sflowExpandedCountersSample)
+		}
+		if sample.err != nil {
+			return nil, fmt.Errorf("sample %d: %v", i, sample.err)
+		}
+		for _, ev := range sevs {
+			ev.Timestamp = ts
+			ev.Tags["source"] = source
+			ev.Tags["flow_type"] = "sflow"
+		}
+		evs = append(evs, sevs...)
+	}
+	return evs, nil
+}
+
+func decodeSFlowFlowSample(r *reader, expanded bool) []*formatters.EventMsg {
+	ev := &formatters.EventMsg{
+		Name:   flowEventName,
+		Tags:   make(map[string]string),
+		Values: make(map[string]interface{}),
+	}
+	var samplingRate, input, output uint32
+	var inputFormat, outputFormat uint32
+	r.u32() // sequence number
+	if expanded {
+		r.u32() // source ID type
+		r.u32() // source ID index
+	} else {
+		r.u32() // source ID
+	}
+	samplingRate = r.u32()
+	r.u32() // sample pool
+	r.u32() // drops
+	if expanded {
+		inputFormat, input = r.u32(), r.u32()
+		outputFormat, output = r.u32(), r.u32()
+	} else {
+		in, out := r.u32(), r.u32()
+		inputFormat, input = in>>30, in&sflowIfIndexUnknown
+		outputFormat, output = out>>30, out&sflowIfIndexUnknown
+	}
+	if inputFormat == sflowInterfaceFormatSingle && input != 0 && input != sflowIfIndexUnknown {
+		ev.Tags["in_if_index"] = strconv.FormatUint(uint64(input), 10)
+	}
+	if outputFormat == sflowInterfaceFormatSingle && output != 0 && output != sflowIfIndexUnknown {
+		ev.Tags["out_if_index"] = strconv.FormatUint(uint64(output), 10)
+	}
+	ev.Values["sampling-rate"] = uint64(samplingRate)
+	numRecords := r.u32()
+	for i := uint32(0); i < numRecords && r.err == nil; i++ {
+		format := r.u32()
+		rec := newReader(r.next(int(r.u32())))
+		if r.err != nil {
+			break
+		}
+		switch format {
+		case sflowRawPacketHeader:
+			proto := rec.u32()
+			frameLength := rec.u32()
+			rec.u32() // stripped
+			header := rec.next(int(rec.u32()))
+			if rec.err != nil {
+				r.err = rec.err
+				break
+			}
+			ev.Values["frame-length"] = uint64(frameLength)
+			ev.Values["bytes"] = uint64(frameLength) * uint64(samplingRate)
+			ev.Values["packets"] = uint64(samplingRate)
+			if proto == sflowHeaderProtoEthernet {
+				parseEthernet(header, ev.Tags)
+			}
+		case sflowExtendedSwitch:
+			vlan := rec.u32()
+			if rec.err == nil && vlan != 0 {
+				ev.Tags["vlan"] = strconv.FormatUint(uint64(vlan), 10)
+			}
+		}
+	}
+	return []*formatters.EventMsg{ev}
+}
+
+func decodeSFlowCountersSample(r *reader, expanded bool) []*formatters.EventMsg {
+	r.u32() // sequence number
+	if expanded {
+		r.u32() // source ID type
+		r.u32() // source ID index
+	} else {
+		r.u32() // source ID
+	}
+	var evs []*formatters.EventMsg
+	numRecords := r.u32()
+	for i := uint32(0); i < numRecords && r.err == nil; i++ {
+		format := r.u32()
+		rec := newReader(r.next(int(r.u32())))
+		if r.err != nil || format != sflowGenericIfCounters {
+			continue
+		}
+		ev := &formatters.EventMsg{
+			Name:   countersEventName,
+			Tags:   make(map[string]string),
+			Values: make(map[string]interface{}),
+		}
+		ev.Tags["if_index"] = strconv.FormatUint(uint64(rec.u32()), 10)
+		rec.u32() // ifType
+		ev.Values["speed"] = rec.u64()
+		rec.u32() // ifDirection
+		status := rec.u32()
+		ev.Values["admin-status"] = upDown(status&1 != 0)
+		ev.Values["oper-status"] = upDown(status&2 != 0)
+		ev.Values["in-octets"] = rec.u64()
+		for _, name := range []string{
+			"in-unicast-pkts", "in-multicast-pkts", "in-broadcast-pkts",
+			"in-discards", "in-errors", "in-unknown-protos",
+		} {
+			ev.Values[name] = uint64(rec.u32())
+		}
+		ev.Values["out-octets"] = rec.u64()
+		for _, name := range []string{
+			"out-unicast-pkts", "out-multicast-pkts", "out-broadcast-pkts",
+			"out-discards", "out-errors",
+		} {
+			ev.Values[name] = uint64(rec.u32())
+		}
+		if rec.err != nil {
+			r.err = rec.err
+			break
+		}
+		evs = append(evs, ev)
+	}
+	return evs
+}
+
+func upDown(up bool) string {
+	if up {
+		return "UP"
+	}
+	return "DOWN"
+}
+
+// parseEthernet sets the MAC, VLAN, IP and transport tags
+// found in a sampled packet header.
+func parseEthernet(b []byte, tags map[string]string) {
+	if len(b) < 14 {
+		return
+	}
+	tags["dst_mac"] = net.HardwareAddr(b[0:6]).String()
+	tags["src_mac"] = net.HardwareAddr(b[6:12]).String()
+	etherType := binary.BigEndian.Uint16(b[12:14])
+	b = b[14:]
+	// 802.1Q and 802.1ad tags
+	for (etherType == 0x8100 || etherType == 0x88a8) && len(b) >= 4 {
+		if _, ok := tags["vlan"]; !ok {
+			tags["vlan"] = strconv.Itoa(int(binary.BigEndian.Uint16(b) & 0x0fff))
+		}
+		etherType = binary.BigEndian.Uint16(b[2:4])
+		b = b[4:]
+	}
+	var proto uint8
+	switch etherType {
+	case 0x0800:
+		if len(b) < 20 {
+			return
+		}
+		proto = b[9]
+		tags["src_ip"] = net.IP(b[12:16]).String()
+		tags["dst_ip"] = net.IP(b[16:20]).String()
+		ihl := int(b[0]&0x0f) * 4
+		if ihl < 20 || len(b) < ihl {
+			return
+		}
+		b = b[ihl:]
+	case 0x86dd:
+		if len(b) < 40 {
+			return
+		}
+		proto = b[6]
+		tags["src_ip"] = net.IP(b[8:24]).String()
+		tags["dst_ip"] = net.IP(b[24:40]).String()
+		b = b[40:]
+	default:
+		return
+	}
+	tags["protocol"] = protocolName(proto)
+	switch proto {
+	case 6, 17, 132:
+		if len(b) >= 4 {
+			tags["src_port"] = strconv.Itoa(int(binary.BigEndian.Uint16(b[0:2])))
+			tags["dst_port"] = strconv.Itoa(int(binary.BigEndian.Uint16(b[2:4])))
+		}
+	}
+}
+
+var protocolNames = map[uint8]string{
+	1:   "icmp",
+	2:   "igmp",
+	6:   "tcp",
+	17:  "udp",
+	47:  "gre",
+	50:  "esp",
+	58:  "icmpv6",
+	89:  "ospf",
+	112: "vrrp",
+	132: "sctp",
+}
+
+func protocolName(p uint8) string {
+	if name, ok := protocolNames[p]; ok {
+		return name
+	}
+	return strconv.Itoa(int(p))
+}
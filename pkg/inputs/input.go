@@ -31,6 +31,9 @@ var InputTypes = []string{
 	"nats",
 	"stan",
 	"kafka",
+	"relay",
+	"flow",
+	"syslog",
 }
 
 var Inputs = map[string]Initializer{}
@@ -67,3 +70,33 @@ func WithEventProcessors(eps map[string]map[string]interface{}, log *log.Logger,
 		return i.SetEventProcessors(eps, log, tcs, acts)
 	}
 }
+
+// PartitionOffset is the consumption position of a topic partition.
+type PartitionOffset struct {
+	Topic     string `json:"topic"`
+	Partition int32  `json:"partition"`
+	// next offset to be consumed
+	Offset int64 `json:"offset"`
+	// offset of the next message to be produced to the partition
+	HighWaterMark int64 `json:"high-water-mark"`
+	Lag           int64 `json:"lag"`
+	// consumer group member the partition is assigned to
+	Member string `json:"member,omitempty"`
+}
+
+// OffsetSeek moves the consumption position of one or all
+// the partitions of a topic.
+type OffsetSeek struct {
+	Topic string `json:"topic"`
+	// all the partitions assigned to this instance if nil
+	Partition *int32 `json:"partition,omitempty"`
+	// an offset number, "oldest" or "newest"
+	Offset string `json:"offset"`
+}
+
+// OffsetsController is implemented by inputs
+// whose consumption position can be inspected and moved.
+type OffsetsController interface {
+	Offsets() ([]*PartitionOffset, error)
+	SeekOffsets(*OffsetSeek) error
+}
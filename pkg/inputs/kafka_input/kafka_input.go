@@ -9,9 +9,7 @@
 package kafka_input
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -41,13 +39,12 @@ const (
 	defaultRecoveryWaitTime  = 2 * time.Second
 	defaultAddress           = "localhost:9092"
 	defaultGroupID           = "gnmic-consumers"
+	defaultRebalanceStrategy = "range"
+	defaultInitialOffset     = "newest"
 )
 
 var defaultVersion = sarama.V2_5_0_0
 
-var openSquareBracket = []byte("[")
-var openCurlyBrace = []byte("{")
-
 func init() {
 	inputs.Register("kafka", func() inputs.Input {
 		return &KafkaInput{
@@ -64,8 +61,15 @@ type KafkaInput struct {
 	cfn     context.CancelFunc
 	logger  sarama.StdLogger
 	wg      *sync.WaitGroup
+	name    string
 	outputs []outputs.Output
+	fanOut  *inputs.FanOut
+	decoder *inputs.ProtoDecoder
 	evps    []formatters.EventProcessor
+
+	m sync.RWMutex
+	// active consumer of each worker
+	consumers []*consumer
 }
 
 // Config //
@@ -76,6 +80,9 @@ type Config struct {
 	SASL              *types.SASL      `mapstructure:"sasl,omitempty"`
 	TLS               *types.TLSConfig `mapstructure:"tls,omitempty"`
 	GroupID           string           `mapstructure:"group-id,omitempty"`
+	GroupInstanceID   string           `mapstructure:"group-instance-id,omitempty"`
+	RebalanceStrategy string           `mapstructure:"rebalance-strategy,omitempty"`
+	InitialOffset     string           `mapstructure:"initial-offset,omitempty"`
 	SessionTimeout    time.Duration    `mapstructure:"session-timeout,omitempty"`
 	HeartbeatInterval time.Duration    `mapstructure:"heartbeat-interval,omitempty"`
 	RecoveryWaitTime  time.Duration    `mapstructure:"recovery-wait-time,omitempty"`
@@ -84,7 +91,12 @@ type Config struct {
 	Debug             bool             `mapstructure:"debug,omitempty"`
 	NumWorkers        int              `mapstructure:"num-workers,omitempty"`
 	Outputs           []string         `mapstructure:"outputs,omitempty"`
+	FanOut            string           `mapstructure:"fan-out,omitempty"`
+	MessageType       string           `mapstructure:"message-type,omitempty"`
+	ProtosetFile      string           `mapstructure:"protoset-file,omitempty"`
 	EventProcessors   []string         `mapstructure:"event-processors,omitempty"`
+	EndToEndAck       bool             `mapstructure:"end-to-end-ack,omitempty"`
+	AckTimeout        time.Duration    `mapstructure:"ack-timeout,omitempty"`
 
 	kafkaVersion sarama.KafkaVersion
 }
@@ -94,6 +106,7 @@ func (k *KafkaInput) Start(ctx context.Context, name string, cfg map[string]inte
 	if err != nil {
 		return err
 	}
+	k.name = name
 	if k.Cfg.Name == "" {
 		k.Cfg.Name = name
 	}
@@ -106,44 +119,112 @@ func (k *KafkaInput) Start(ctx context.Context, name string, cfg map[string]inte
 	if err != nil {
 		return err
 	}
+	k.fanOut, err = inputs.NewFanOut(k.Cfg.FanOut, k.outputs)
+	if err != nil {
+		return err
+	}
+	if k.Cfg.EndToEndAck {
+		err = inputs.CheckAckOutputs(k.outputs)
+		if err != nil {
+			return err
+		}
+	}
+	if k.Cfg.Format == "proto" {
+		k.decoder, err = inputs.NewProtoDecoder(k.Cfg.MessageType, k.Cfg.ProtosetFile)
+		if err != nil {
+			return err
+		}
+	}
 	config, err := k.createConfig()
 	if err != nil {
 		return err
 	}
+	ctx, k.cfn = context.WithCancel(ctx)
+	k.consumers = make([]*consumer, k.Cfg.NumWorkers)
 	k.wg.Add(k.Cfg.NumWorkers)
 	for i := 0; i < k.Cfg.NumWorkers; i++ {
 		cfg := *config
 		cfg.ClientID = fmt.Sprintf("%s-%d", config.ClientID, i)
+		if k.Cfg.GroupInstanceID != "" && k.Cfg.NumWorkers > 1 {
+			// each worker is a distinct static member of the group
+			cfg.Consumer.Group.InstanceId = fmt.Sprintf("%s-%d", k.Cfg.GroupInstanceID, i)
+		}
 		go k.worker(ctx, i, &cfg)
 	}
 	return nil
 }
 
+// newConsumerGroup creates a client and a consumer group using it,
+// the client is closed if the consumer group cannot be created.
+func (k *KafkaInput) newConsumerGroup(config *sarama.Config) (sarama.Client, sarama.ConsumerGroup, error) {
+	client, err := sarama.NewClient(strings.Split(k.Cfg.Address, ","), config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	consumerGrp, err := sarama.NewConsumerGroupFromClient(k.Cfg.GroupID, client)
+	if err != nil {
+		client.Close()
+		return nil, nil, fmt.Errorf("failed to create consumer group: %w", err)
+	}
+	return client, consumerGrp, nil
+}
+
 func (k *KafkaInput) worker(ctx context.Context, idx int, config *sarama.Config) {
 	defer k.wg.Done()
 
 	workerLogPrefix := fmt.Sprintf("worker-%d", idx)
-START:
+	for {
+		err := k.consume(ctx, workerLogPrefix, idx, config)
+		if ctx.Err() != nil {
+			return
+		}
+		k.logger.Printf("%s %v", workerLogPrefix, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(k.Cfg.RecoveryWaitTime):
+		}
+	}
+}
+
+// consume creates a consumer group and handles its messages until ctx is done
+// or the consumer group fails, the client and consumer group are closed on return.
+func (k *KafkaInput) consume(ctx context.Context, workerLogPrefix string, idx int, config *sarama.Config) error {
 	k.logger.Printf("%s starting consumer group %s", workerLogPrefix, k.Cfg.GroupID)
-	consumerGrp, err := sarama.NewConsumerGroup(strings.Split(k.Cfg.Address, ","), k.Cfg.GroupID, config)
+	client, consumerGrp, err := k.newConsumerGroup(config)
 	if err != nil {
-		k.logger.Printf("%s failed to create consumer group: %v", workerLogPrefix, err)
-		time.Sleep(k.Cfg.RecoveryWaitTime)
-		goto START
+		return err
 	}
-	k.logger.Printf("%s started consumer group %s", workerLogPrefix, k.Cfg.GroupID)
+	defer client.Close()
 	defer consumerGrp.Close()
+	// stops the sessions of this consumer group on return
+	gctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	k.logger.Printf("%s started consumer group %s", workerLogPrefix, k.Cfg.GroupID)
 	cons := &consumer{
 		ready:   make(chan bool),
-		msgChan: make(chan *sarama.ConsumerMessage),
+		msgChan: make(chan *consumedMessage),
+		client:  client,
+		logger:  k.logger,
+	}
+	if k.Cfg.EndToEndAck {
+		cons.ackRetryWait = k.Cfg.RecoveryWaitTime
 	}
+	k.m.Lock()
+	k.consumers[idx] = cons
+	k.m.Unlock()
 	go func() {
 		var err error
 		for {
-			if ctx.Err() != nil {
+			if gctx.Err() != nil {
 				return
 			}
-			err = consumerGrp.Consume(ctx, strings.Split(k.Cfg.Topics, ","), cons)
+			// a session is ended before its context when its offsets are moved,
+			// the claims of the next session start from the committed offsets.
+			sctx, cancel := context.WithCancel(gctx)
+			cons.setCancelSession(cancel)
+			err = consumerGrp.Consume(sctx, strings.Split(k.Cfg.Topics, ","), cons)
+			cancel()
 			if err != nil {
 				if k.Cfg.Debug {
 					k.logger.Printf("%s failed to start consumer, topics=%q, group=%q : %v", workerLogPrefix, k.Cfg.Topics, k.Cfg.GroupID, err)
@@ -153,44 +234,51 @@ START:
 			cons.ready = make(chan bool)
 		}
 	}()
-	<-cons.ready
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-cons.ready:
+	}
 	k.logger.Printf("%s kafka consumer ready", workerLogPrefix)
 	for {
 		select {
 		case <-ctx.Done():
-			return
+			return nil
 		case m := <-cons.msgChan:
 			if len(m.Value) == 0 {
+				m.done(nil)
 				continue
 			}
+			inputs.MessageReceived(k.name)
 			if k.Cfg.Debug {
 				k.logger.Printf("%s client=%s received msg, topic=%s, partition=%d, key=%q, length=%d, value=%s", workerLogPrefix, config.ClientID, m.Topic, m.Partition, string(m.Key), len(m.Value), string(m.Value))
 			}
 			switch k.Cfg.Format {
-			case "event":
-				m.Value = bytes.TrimSpace(m.Value)
-				evMsgs := make([]*formatters.EventMsg, 1)
-				switch {
-				case len(m.Value) == 0:
-					continue
-				case m.Value[0] == openSquareBracket[0]:
-					err = json.Unmarshal(m.Value, &evMsgs)
-				case m.Value[0] == openCurlyBrace[0]:
-					err = json.Unmarshal(m.Value, evMsgs[0])
-				}
+			case "event", "cbor", "msgpack":
+				var evMsgs []*formatters.EventMsg
+				evMsgs, err = formatters.DecodeEventsFormat(k.Cfg.Format, m.Value)
 				if err != nil {
+					inputs.MessageError(k.name, inputs.ErrorReasonDecode)
 					if k.Cfg.Debug {
 						k.logger.Printf("%s failed to unmarshal event msg: %v", workerLogPrefix, err)
 					}
+					// an undecodable message is acknowledged, it would fail again if redelivered.
+					m.done(nil)
 					continue
 				}
+				inputs.MessageDecoded(k.name)
 
 				for _, p := range k.evps {
 					evMsgs = p.Apply(evMsgs...)
 				}
 
+				outs := k.fanOut.Outputs()
+				if m.ack != nil {
+					go inputs.WriteEventsAck(ctx, outs, evMsgs, k.Cfg.AckTimeout, m.ack)
+					continue
+				}
 				go func() {
-					for _, o := range k.outputs {
+					for _, o := range outs {
 						for _, ev := range evMsgs {
 							o.WriteEvent(ctx, ev)
 						}
@@ -198,24 +286,30 @@ START:
 				}()
 			case "proto":
 				var protoMsg proto.Message
-				err = proto.Unmarshal(m.Value, protoMsg)
+				protoMsg, err = k.decoder.Decode(m.Value)
 				if err != nil {
+					inputs.MessageError(k.name, inputs.ErrorReasonDecode)
 					if k.Cfg.Debug {
 						k.logger.Printf("%s failed to unmarshal proto msg: %v", workerLogPrefix, err)
 					}
+					m.done(nil)
 					continue
 				}
+				inputs.MessageDecoded(k.name)
 				meta := outputs.Meta{}
+				outs := k.fanOut.Outputs()
+				if m.ack != nil {
+					go inputs.WriteAck(ctx, outs, protoMsg, meta, k.Cfg.AckTimeout, m.ack)
+					continue
+				}
 				go func() {
-					for _, o := range k.outputs {
+					for _, o := range outs {
 						o.Write(ctx, protoMsg, meta)
 					}
 				}()
 			}
 		case err := <-consumerGrp.Errors():
-			k.logger.Printf("%s client=%s, consumer-group=%s error: %v", workerLogPrefix, config.ClientID, k.Cfg.GroupID, err)
-			time.Sleep(k.Cfg.RecoveryWaitTime)
-			goto START
+			return fmt.Errorf("client=%s, consumer-group=%s error: %v", config.ClientID, k.Cfg.GroupID, err)
 		}
 	}
 }
@@ -289,7 +383,7 @@ func (k *KafkaInput) setDefaults() error {
 	if k.Cfg.Format == "" {
 		k.Cfg.Format = defaultFormat
 	}
-	if !(strings.ToLower(k.Cfg.Format) == "event" || strings.ToLower(k.Cfg.Format) == "proto") {
+	if !(formatters.IsEventFormat(strings.ToLower(k.Cfg.Format)) || strings.ToLower(k.Cfg.Format) == "proto") {
 		return fmt.Errorf("unsupported input format")
 	}
 	if k.Cfg.Topics == "" {
@@ -313,6 +407,28 @@ func (k *KafkaInput) setDefaults() error {
 	if k.Cfg.RecoveryWaitTime <= 0 {
 		k.Cfg.RecoveryWaitTime = defaultRecoveryWaitTime
 	}
+	if k.Cfg.EndToEndAck && k.Cfg.AckTimeout <= 0 {
+		k.Cfg.AckTimeout = inputs.DefaultAckTimeout
+	}
+	if k.Cfg.GroupInstanceID != "" && !k.Cfg.kafkaVersion.IsAtLeast(sarama.V2_3_0_0) {
+		return fmt.Errorf("group-instance-id requires kafka version 2.3.0 or higher, got %s", k.Cfg.kafkaVersion)
+	}
+	k.Cfg.RebalanceStrategy = strings.ToLower(k.Cfg.RebalanceStrategy)
+	switch k.Cfg.RebalanceStrategy {
+	case "":
+		k.Cfg.RebalanceStrategy = defaultRebalanceStrategy
+	case "range", "roundrobin", "sticky":
+	default:
+		return fmt.Errorf("unsupported rebalance strategy %q", k.Cfg.RebalanceStrategy)
+	}
+	k.Cfg.InitialOffset = strings.ToLower(k.Cfg.InitialOffset)
+	switch k.Cfg.InitialOffset {
+	case "":
+		k.Cfg.InitialOffset = defaultInitialOffset
+	case "oldest", "newest":
+	default:
+		return fmt.Errorf("unsupported initial offset %q", k.Cfg.InitialOffset)
+	}
 	if k.Cfg.Name == "" {
 		k.Cfg.Name = "gnmic-" + uuid.New().String()
 	}
@@ -327,6 +443,10 @@ func (k *KafkaInput) setDefaults() error {
 		if k.Cfg.SASL.TokenURL == "" {
 			return errors.New("missing token-url for kafka SASL mechanism OAUTHBEARER")
 		}
+	case pkgutils.SASLTypeAWSMSKIAM:
+		if k.Cfg.TLS == nil {
+			return errors.New("kafka SASL mechanism AWS_MSK_IAM requires TLS")
+		}
 	}
 	return nil
 }
@@ -337,7 +457,18 @@ func (k *KafkaInput) createConfig() (*sarama.Config, error) {
 	cfg.Consumer.Return.Errors = true
 	cfg.Consumer.Group.Session.Timeout = k.Cfg.SessionTimeout
 	cfg.Consumer.Group.Heartbeat.Interval = k.Cfg.HeartbeatInterval
-	cfg.Consumer.Group.Rebalance.Strategy = sarama.NewBalanceStrategyRange()
+	cfg.Consumer.Group.InstanceId = k.Cfg.GroupInstanceID
+	switch k.Cfg.RebalanceStrategy {
+	case "roundrobin":
+		cfg.Consumer.Group.Rebalance.GroupStrategies = []sarama.BalanceStrategy{sarama.NewBalanceStrategyRoundRobin()}
+	case "sticky":
+		cfg.Consumer.Group.Rebalance.GroupStrategies = []sarama.BalanceStrategy{sarama.NewBalanceStrategySticky()}
+	default:
+		cfg.Consumer.Group.Rebalance.GroupStrategies = []sarama.BalanceStrategy{sarama.NewBalanceStrategyRange()}
+	}
+	if k.Cfg.InitialOffset == "oldest" {
+		cfg.Consumer.Offsets.Initial = sarama.OffsetOldest
+	}
 	// SASL_PLAINTEXT or SASL_SSL
 	if k.Cfg.SASL != nil {
 		cfg.Net.SASL.Enable = true
@@ -355,6 +486,14 @@ func (k *KafkaInput) createConfig() (*sarama.Config, error) {
 			}
 		case sarama.SASLTypeOAuth:
 			cfg.Net.SASL.TokenProvider = pkgutils.NewTokenProvider(cfg.Net.SASL.User, cfg.Net.SASL.Password, k.Cfg.SASL.TokenURL)
+		case pkgutils.SASLTypeAWSMSKIAM:
+			// MSK IAM tokens are carried over OAUTHBEARER
+			tp, err := pkgutils.NewMSKIAMTokenProvider(k.Cfg.SASL.Region)
+			if err != nil {
+				return nil, err
+			}
+			cfg.Net.SASL.Mechanism = sarama.SASLTypeOAuth
+			cfg.Net.SASL.TokenProvider = tp
 		}
 	}
 	// SSL or SASL_SSL
@@ -380,11 +519,35 @@ func (k *KafkaInput) createConfig() (*sarama.Config, error) {
 // consumer represents a Sarama consumer group consumer
 type consumer struct {
 	ready   chan bool
-	msgChan chan *sarama.ConsumerMessage
+	msgChan chan *consumedMessage
+	client  sarama.Client
+	logger  sarama.StdLogger
+	// set when end-to-end acknowledgements are enabled,
+	// wait time before a session is ended after a failed acknowledgement.
+	ackRetryWait time.Duration
+
+	m             sync.Mutex
+	session       sarama.ConsumerGroupSession
+	cancelSession context.CancelFunc
+	// set when the session offsets are moved,
+	// stops marking consumed messages until the next session.
+	seeking bool
+	// per topic, per partition position
+	positions map[string]map[int32]*position
+}
+
+type position struct {
+	offset        int64
+	highWaterMark int64
 }
 
 // Setup is run at the beginning of a new session, before ConsumeClaim
-func (consumer *consumer) Setup(sarama.ConsumerGroupSession) error {
+func (consumer *consumer) Setup(session sarama.ConsumerGroupSession) error {
+	consumer.m.Lock()
+	consumer.session = session
+	consumer.seeking = false
+	consumer.positions = make(map[string]map[int32]*position)
+	consumer.m.Unlock()
 	// Mark the consumer as ready
 	close(consumer.ready)
 	return nil
@@ -392,14 +555,99 @@ func (consumer *consumer) Setup(sarama.ConsumerGroupSession) error {
 
 // Cleanup is run at the end of a session, once all ConsumeClaim goroutines have exited
 func (consumer *consumer) Cleanup(sarama.ConsumerGroupSession) error {
+	consumer.m.Lock()
+	defer consumer.m.Unlock()
+	consumer.session = nil
+	consumer.positions = nil
 	return nil
 }
 
 // ConsumeClaim must start a consumer loop of ConsumerGroupClaim's Messages().
 func (consumer *consumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	consumer.setPosition(claim.Topic(), claim.Partition(), claim.InitialOffset(), claim.HighWaterMarkOffset())
 	for message := range claim.Messages() {
-		consumer.msgChan <- message
+		if consumer.ackRetryWait > 0 {
+			if !consumer.deliverAck(session, message) {
+				return nil
+			}
+		} else {
+			consumer.msgChan <- &consumedMessage{ConsumerMessage: message}
+		}
+		consumer.m.Lock()
+		if consumer.seeking {
+			consumer.m.Unlock()
+			return nil
+		}
 		session.MarkMessage(message, "")
+		consumer.setPositionLocked(message.Topic, message.Partition, message.Offset+1, claim.HighWaterMarkOffset())
+		consumer.m.Unlock()
 	}
 	return nil
 }
+
+// deliverAck sends the message to the worker and waits for the outputs to acknowledge it.
+// It returns false if the message was not acknowledged, the session is then ended
+// and the next one consumes the claim again from the last marked offset.
+func (consumer *consumer) deliverAck(session sarama.ConsumerGroupSession, m *sarama.ConsumerMessage) bool {
+	res := make(chan error, 1)
+	cm := &consumedMessage{
+		ConsumerMessage: m,
+		ack:             func(err error) { res <- err },
+	}
+	select {
+	case consumer.msgChan <- cm:
+	case <-session.Context().Done():
+		return false
+	}
+	select {
+	case err := <-res:
+		if err == nil {
+			return true
+		}
+		consumer.logger.Printf("message topic=%s, partition=%d, offset=%d not acknowledged by the outputs: %v",
+			m.Topic, m.Partition, m.Offset, err)
+	case <-session.Context().Done():
+		return false
+	}
+	select {
+	case <-time.After(consumer.ackRetryWait):
+	case <-session.Context().Done():
+	}
+	return false
+}
+
+func (consumer *consumer) setCancelSession(cfn context.CancelFunc) {
+	consumer.m.Lock()
+	defer consumer.m.Unlock()
+	consumer.cancelSession = cfn
+}
+
+func (consumer *consumer) setPosition(topic string, partition int32, offset, hwm int64) {
+	consumer.m.Lock()
+	defer consumer.m.Unlock()
+	consumer.setPositionLocked(topic, partition, offset, hwm)
+}
+
+func (consumer *consumer) setPositionLocked(topic string, partition int32, offset, hwm int64) {
+	if consumer.positions == nil {
+		return
+	}
+	if _, ok := consumer.positions[topic]; !ok {
+		consumer.positions[topic] = make(map[int32]*position)
+	}
+	consumer.positions[topic][partition] = &position{offset: offset, highWaterMark: hwm}
+}
+
+// consumedMessage is a message delivered to the input workers,
+// ack is set when end-to-end acknowledgements are enabled.
+type consumedMessage struct {
+	*sarama.ConsumerMessage
+	ack outputs.AckFunc
+}
+
+func (m *consumedMessage) done(err error) {
+	if m.ack == nil {
+		return
+	}
+	m.ack(err)
+}
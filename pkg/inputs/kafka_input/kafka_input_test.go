@@ -0,0 +1,79 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kafka_input
+
+import (
+	"testing"
+)
+
+func TestSetDefaults(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    *Config
+		expErr bool
+		check  func(*Config) bool
+	}{
+		{
+			name: "defaults",
+			cfg:  &Config{},
+			check: func(c *Config) bool {
+				return c.RebalanceStrategy == defaultRebalanceStrategy &&
+					c.InitialOffset == defaultInitialOffset &&
+					c.kafkaVersion == defaultVersion
+			},
+		},
+		{
+			name: "case_insensitive",
+			cfg:  &Config{RebalanceStrategy: "RoundRobin", InitialOffset: "OLDEST"},
+			check: func(c *Config) bool {
+				return c.RebalanceStrategy == "roundrobin" && c.InitialOffset == "oldest"
+			},
+		},
+		{
+			name: "group_instance_id",
+			cfg:  &Config{GroupInstanceID: "gnmic-1", Version: "2.3.0"},
+			check: func(c *Config) bool {
+				return c.GroupInstanceID == "gnmic-1"
+			},
+		},
+		{
+			name:   "group_instance_id_old_version",
+			cfg:    &Config{GroupInstanceID: "gnmic-1", Version: "2.2.0"},
+			expErr: true,
+		},
+		{
+			name:   "invalid_rebalance_strategy",
+			cfg:    &Config{RebalanceStrategy: "cooperative"},
+			expErr: true,
+		},
+		{
+			name:   "invalid_initial_offset",
+			cfg:    &Config{InitialOffset: "latest"},
+			expErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := &KafkaInput{Cfg: tt.cfg}
+			err := k.setDefaults()
+			if tt.expErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tt.check(k.Cfg) {
+				t.Errorf("unexpected config: %+v", k.Cfg)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kafka_input
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/IBM/sarama"
+
+	"github.com/openconfig/gnmic/pkg/inputs"
+)
+
+// Offsets returns the position of the partitions assigned to this input's workers.
+func (k *KafkaInput) Offsets() ([]*inputs.PartitionOffset, error) {
+	k.m.RLock()
+	defer k.m.RUnlock()
+	offsets := make([]*inputs.PartitionOffset, 0)
+	for _, c := range k.consumers {
+		if c == nil {
+			continue
+		}
+		offsets = append(offsets, c.offsets()...)
+	}
+	sort.Slice(offsets, func(i, j int) bool {
+		if offsets[i].Topic == offsets[j].Topic {
+			return offsets[i].Partition < offsets[j].Partition
+		}
+		return offsets[i].Topic < offsets[j].Topic
+	})
+	return offsets, nil
+}
+
+// SeekOffsets moves the committed offset of the partitions assigned to this input's workers,
+// the sessions of the affected workers are restarted to resume consumption from the new offsets.
+func (k *KafkaInput) SeekOffsets(s *inputs.OffsetSeek) error {
+	if s == nil || s.Topic == "" {
+		return errors.New("missing topic")
+	}
+	offset, err := parseOffset(s.Offset)
+	if err != nil {
+		return err
+	}
+	k.m.RLock()
+	defer k.m.RUnlock()
+	numSeeks := 0
+	for _, c := range k.consumers {
+		if c == nil {
+			continue
+		}
+		n, err := c.seek(s.Topic, s.Partition, offset)
+		if err != nil {
+			return err
+		}
+		numSeeks += n
+	}
+	if numSeeks == 0 {
+		if s.Partition != nil {
+			return fmt.Errorf("topic %q partition %d is not assigned to this instance", s.Topic, *s.Partition)
+		}
+		return fmt.Errorf("no partition of topic %q is assigned to this instance", s.Topic)
+	}
+	return nil
+}
+
+func parseOffset(s string) (int64, error) {
+	switch strings.ToLower(s) {
+	case "oldest":
+		return sarama.OffsetOldest, nil
+	case "newest":
+		return sarama.OffsetNewest, nil
+	}
+	offset, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid offset %q, expecting a positive number, \"oldest\" or \"newest\"", s)
+	}
+	return offset, nil
+}
+
+func (consumer *consumer) offsets() []*inputs.PartitionOffset {
+	consumer.m.Lock()
+	defer consumer.m.Unlock()
+	if consumer.session == nil {
+		return nil
+	}
+	offsets := make([]*inputs.PartitionOffset, 0)
+	for topic, partitions := range consumer.positions {
+		for partition, pos := range partitions {
+			po := &inputs.PartitionOffset{
+				Topic:         topic,
+				Partition:     partition,
+				Offset:        pos.offset,
+				HighWaterMark: pos.highWaterMark,
+				Member:        consumer.session.MemberID(),
+			}
+			if pos.offset >= 0 && pos.highWaterMark > pos.offset {
+				po.Lag = pos.highWaterMark - pos.offset
+			}
+			offsets = append(offsets, po)
+		}
+	}
+	return offsets
+}
+
+// seek moves the offset of the claimed partitions matching topic and partition,
+// it returns the number of moved partitions.
+func (consumer *consumer) seek(topic string, partition *int32, offset int64) (int, error) {
+	consumer.m.Lock()
+	defer consumer.m.Unlock()
+	if consumer.session == nil {
+		return 0, nil
+	}
+	numSeeks := 0
+	for _, p := range consumer.session.Claims()[topic] {
+		if partition != nil && *partition != p {
+			continue
+		}
+		off := offset
+		if offset < 0 {
+			var err error
+			off, err = consumer.client.GetOffset(topic, p, offset)
+			if err != nil {
+				return numSeeks, fmt.Errorf("failed to get offset of topic %q partition %d: %w", topic, p, err)
+			}
+		}
+		// ResetOffset only moves the offset backwards and MarkOffset only forward
+		consumer.session.ResetOffset(topic, p, off, "")
+		consumer.session.MarkOffset(topic, p, off, "")
+		numSeeks++
+	}
+	if numSeeks == 0 {
+		return 0, nil
+	}
+	consumer.seeking = true
+	consumer.session.Commit()
+	if consumer.cancelSession != nil {
+		consumer.cancelSession()
+	}
+	return numSeeks, nil
+}
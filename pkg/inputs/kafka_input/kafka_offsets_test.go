@@ -0,0 +1,238 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kafka_input
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/IBM/sarama"
+
+	"github.com/openconfig/gnmic/pkg/inputs"
+)
+
+// mockSession records the offset operations of a consumer group session.
+type mockSession struct {
+	claims  map[string][]int32
+	resets  []string
+	marks   []string
+	commits int
+}
+
+func (s *mockSession) Claims() map[string][]int32 { return s.claims }
+func (s *mockSession) MemberID() string           { return "member-1" }
+func (s *mockSession) GenerationID() int32        { return 1 }
+func (s *mockSession) Commit()                    { s.commits++ }
+func (s *mockSession) Context() context.Context   { return context.Background() }
+
+func (s *mockSession) MarkOffset(topic string, partition int32, offset int64, _ string) {
+	s.marks = append(s.marks, fmt.Sprintf("%s/%d@%d", topic, partition, offset))
+}
+
+func (s *mockSession) ResetOffset(topic string, partition int32, offset int64, _ string) {
+	s.resets = append(s.resets, fmt.Sprintf("%s/%d@%d", topic, partition, offset))
+}
+
+func (s *mockSession) MarkMessage(msg *sarama.ConsumerMessage, metadata string) {
+	s.MarkOffset(msg.Topic, msg.Partition, msg.Offset+1, metadata)
+}
+
+// mockClient resolves the oldest and newest offsets of any partition.
+type mockClient struct {
+	sarama.Client
+	err error
+}
+
+func (c *mockClient) GetOffset(_ string, _ int32, offset int64) (int64, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	if offset == sarama.OffsetOldest {
+		return 10, nil
+	}
+	return 100, nil
+}
+
+func newTestConsumer(claims map[string][]int32, client sarama.Client) (*consumer, *mockSession, *int) {
+	s := &mockSession{claims: claims}
+	cancels := new(int)
+	c := &consumer{
+		ready:         make(chan bool),
+		client:        client,
+		cancelSession: func() { *cancels++ },
+	}
+	c.Setup(s)
+	return c, s, cancels
+}
+
+func TestParseOffset(t *testing.T) {
+	tests := []struct {
+		in    string
+		exp   int64
+		isErr bool
+	}{
+		{in: "oldest", exp: sarama.OffsetOldest},
+		{in: "NEWEST", exp: sarama.OffsetNewest},
+		{in: "0", exp: 0},
+		{in: "42", exp: 42},
+		{in: "-1", isErr: true},
+		{in: "", isErr: true},
+		{in: "latest", isErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseOffset(tt.in)
+			if tt.isErr {
+				if err == nil {
+					t.Fatalf("expected an error, got offset %d", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.exp {
+				t.Errorf("got %d, want %d", got, tt.exp)
+			}
+		})
+	}
+}
+
+func TestOffsets(t *testing.T) {
+	c1, _, _ := newTestConsumer(nil, nil)
+	c1.setPosition("b", 0, 5, 5)
+	c1.setPosition("a", 1, 3, 10)
+	c2, _, _ := newTestConsumer(nil, nil)
+	c2.setPosition("a", 0, sarama.OffsetNewest, 7)
+	// a consumer between two sessions has no offsets
+	c3, _, _ := newTestConsumer(nil, nil)
+	c3.Cleanup(nil)
+
+	k := &KafkaInput{consumers: []*consumer{c1, nil, c2, c3}}
+	got, err := k.Offsets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []*inputs.PartitionOffset{
+		{Topic: "a", Partition: 0, Offset: sarama.OffsetNewest, HighWaterMark: 7, Member: "member-1"},
+		{Topic: "a", Partition: 1, Offset: 3, HighWaterMark: 10, Lag: 7, Member: "member-1"},
+		{Topic: "b", Partition: 0, Offset: 5, HighWaterMark: 5, Member: "member-1"},
+	}
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("unexpected offsets:")
+		for _, po := range got {
+			t.Logf("%+v", po)
+		}
+	}
+}
+
+func TestSeekOffsets(t *testing.T) {
+	p1 := int32(1)
+	p5 := int32(5)
+	tests := []struct {
+		name      string
+		seek      *inputs.OffsetSeek
+		clientErr error
+		expErr    bool
+		// expected resets and marks, both move to the same offsets
+		expMoves []string
+	}{
+		{
+			name:     "all_partitions",
+			seek:     &inputs.OffsetSeek{Topic: "t", Offset: "42"},
+			expMoves: []string{"t/0@42", "t/1@42"},
+		},
+		{
+			name:     "one_partition",
+			seek:     &inputs.OffsetSeek{Topic: "t", Partition: &p1, Offset: "42"},
+			expMoves: []string{"t/1@42"},
+		},
+		{
+			name:     "oldest",
+			seek:     &inputs.OffsetSeek{Topic: "t", Partition: &p1, Offset: "oldest"},
+			expMoves: []string{"t/1@10"},
+		},
+		{
+			name:     "newest",
+			seek:     &inputs.OffsetSeek{Topic: "t", Offset: "newest"},
+			expMoves: []string{"t/0@100", "t/1@100"},
+		},
+		{
+			name:   "missing_topic",
+			seek:   &inputs.OffsetSeek{Offset: "42"},
+			expErr: true,
+		},
+		{
+			name:   "invalid_offset",
+			seek:   &inputs.OffsetSeek{Topic: "t", Offset: "x"},
+			expErr: true,
+		},
+		{
+			name:   "unassigned_topic",
+			seek:   &inputs.OffsetSeek{Topic: "other", Offset: "42"},
+			expErr: true,
+		},
+		{
+			name:   "unassigned_partition",
+			seek:   &inputs.OffsetSeek{Topic: "t", Partition: &p5, Offset: "42"},
+			expErr: true,
+		},
+		{
+			name:      "get_offset_failure",
+			seek:      &inputs.OffsetSeek{Topic: "t", Offset: "oldest"},
+			clientErr: errors.New("broker down"),
+			expErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, s, cancels := newTestConsumer(map[string][]int32{"t": {0, 1}}, &mockClient{err: tt.clientErr})
+			k := &KafkaInput{consumers: []*consumer{c}}
+			err := k.SeekOffsets(tt.seek)
+			if tt.expErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if s.commits != 0 || *cancels != 0 || c.seeking {
+					t.Errorf("session changed by a failed seek: commits=%d cancels=%d seeking=%v", s.commits, *cancels, c.seeking)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(s.resets, tt.expMoves) || !reflect.DeepEqual(s.marks, tt.expMoves) {
+				t.Errorf("got resets %v and marks %v, want %v", s.resets, s.marks, tt.expMoves)
+			}
+			// the moved offsets are committed and the session restarted
+			if s.commits != 1 || *cancels != 1 || !c.seeking {
+				t.Errorf("got commits=%d cancels=%d seeking=%v", s.commits, *cancels, c.seeking)
+			}
+			// the next session resumes marking the consumed messages
+			c.Cleanup(s)
+			c.ready = make(chan bool)
+			c.Setup(s)
+			if c.seeking {
+				t.Errorf("seeking not reset by the next session")
+			}
+		})
+	}
+}
+
+func TestSeekWithoutSession(t *testing.T) {
+	c, _, cancels := newTestConsumer(map[string][]int32{"t": {0}}, nil)
+	c.Cleanup(nil)
+	n, err := c.seek("t", nil, 42)
+	if err != nil || n != 0 || *cancels != 0 {
+		t.Errorf("got n=%d err=%v cancels=%d", n, err, *cancels)
+	}
+}
@@ -0,0 +1,62 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package inputs
+
+import "github.com/prometheus/client_golang/prometheus"
+
+const (
+	// ErrorReasonDecode is the reason of the errors counted
+	// when a received message cannot be decoded.
+	ErrorReasonDecode = "decode"
+)
+
+var ReceivedMessages = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "input",
+	Name:      "received_messages_total",
+	Help:      "Total number of messages received by the input",
+}, []string{"input"})
+
+var DecodedMessages = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "input",
+	Name:      "decoded_messages_total",
+	Help:      "Total number of messages successfully decoded by the input",
+}, []string{"input"})
+
+var Errors = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "input",
+	Name:      "errors_total",
+	Help:      "Total number of messages the input failed to process per reason",
+}, []string{"input", "reason"})
+
+// Collectors returns the inputs metrics.
+func Collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		ReceivedMessages,
+		DecodedMessages,
+		Errors,
+	}
+}
+
+// MessageReceived counts a message received by input name.
+func MessageReceived(name string) {
+	ReceivedMessages.WithLabelValues(name).Inc()
+}
+
+// MessageDecoded counts a message decoded by input name.
+func MessageDecoded(name string) {
+	DecodedMessages.WithLabelValues(name).Inc()
+}
+
+// MessageError counts a message input name failed to process.
+func MessageError(name, reason string) {
+	Errors.WithLabelValues(name, reason).Inc()
+}
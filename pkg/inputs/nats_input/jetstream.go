@@ -0,0 +1,139 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package nats_input
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+// JetStreamConfig makes the input consume from a JetStream stream
+// instead of a core NATS subject.
+type JetStreamConfig struct {
+	// stream to bind to, looked up using the subject if empty
+	Stream string `mapstructure:"stream,omitempty" json:"stream,omitempty"`
+	// use an ordered consumer: an ephemeral, flow controlled consumer
+	// recreated by the client when a gap in the delivered sequence is detected.
+	OrderedConsumer bool `mapstructure:"ordered-consumer,omitempty" json:"ordered-consumer,omitempty"`
+	// one of all, last, new, last-per-subject
+	DeliverPolicy string `mapstructure:"deliver-policy,omitempty" json:"deliver-policy,omitempty"`
+}
+
+var deliverPolicies = map[string]func() nats.SubOpt{
+	"all":              nats.DeliverAll,
+	"last":             nats.DeliverLast,
+	"new":              nats.DeliverNew,
+	"last-per-subject": nats.DeliverLastPerSubject,
+}
+
+func (c *JetStreamConfig) validate() error {
+	c.DeliverPolicy = strings.ToLower(c.DeliverPolicy)
+	if c.DeliverPolicy == "" {
+		return nil
+	}
+	if _, ok := deliverPolicies[c.DeliverPolicy]; !ok {
+		return fmt.Errorf("unknown jetstream deliver-policy %q", c.DeliverPolicy)
+	}
+	return nil
+}
+
+func (n *NatsInput) jetStreamSubscribe(nc *nats.Conn, msgChan chan *nats.Msg) (*nats.Subscription, error) {
+	js, err := nc.JetStream()
+	if err != nil {
+		return nil, err
+	}
+	opts := make([]nats.SubOpt, 0, 3)
+	if n.Cfg.JetStream.Stream != "" {
+		opts = append(opts, nats.BindStream(n.Cfg.JetStream.Stream))
+	}
+	if fn, ok := deliverPolicies[n.Cfg.JetStream.DeliverPolicy]; ok {
+		opts = append(opts, fn())
+	}
+	if n.Cfg.JetStream.OrderedConsumer {
+		opts = append(opts, nats.OrderedConsumer())
+		return js.ChanSubscribe(n.Cfg.Subject, msgChan, opts...)
+	}
+	// durable consumer shared by the workers using the queue name
+	return js.ChanQueueSubscribe(n.Cfg.Subject, n.Cfg.Queue, msgChan, opts...)
+}
+
+// gapDetector tracks the sequence numbers of the messages
+// delivered by a JetStream consumer.
+type gapDetector struct {
+	lastConsumerSeq uint64
+	lastStreamSeq   uint64
+}
+
+// check records the sequences of a delivered message, it returns the number of messages
+// missed since the previous delivery and whether the consumer was recreated,
+// in which case its sequence restarted.
+func (g *gapDetector) check(md *nats.MsgMetadata) (missed uint64, reset bool) {
+	switch {
+	case g.lastConsumerSeq == 0:
+	case md.Sequence.Consumer <= g.lastConsumerSeq:
+		reset = true
+	case md.Sequence.Consumer > g.lastConsumerSeq+1:
+		missed = md.Sequence.Consumer - g.lastConsumerSeq - 1
+	}
+	g.lastConsumerSeq = md.Sequence.Consumer
+	g.lastStreamSeq = md.Sequence.Stream
+	return missed, reset
+}
+
+// reportGaps logs the gaps detected in the delivered sequence.
+func (n *NatsInput) reportGaps(prefix string, g *gapDetector, m *nats.Msg) {
+	md, err := m.Metadata()
+	if err != nil {
+		if n.Cfg.Debug {
+			n.logger.Printf("%s failed to get jetstream message metadata: %v", prefix, err)
+		}
+		return
+	}
+	lastStreamSeq := g.lastStreamSeq
+	missed, reset := g.check(md)
+	switch {
+	case reset:
+		n.logger.Printf("%s jetstream consumer was reset, resumed at stream sequence %d, last received stream sequence %d",
+			prefix, md.Sequence.Stream, lastStreamSeq)
+	case missed > 0:
+		n.logger.Printf("%s jetstream consumer sequence gap detected: %d message(s) missed between stream sequences %d and %d",
+			prefix, missed, lastStreamSeq, md.Sequence.Stream)
+	}
+}
+
+// msgAck returns the function acknowledging m to the JetStream server once
+// the outputs confirmed its writes. If the writes failed, m is negatively
+// acknowledged and redelivered by the server after connect-time-wait.
+func (n *NatsInput) msgAck(prefix string, m *nats.Msg) outputs.AckFunc {
+	return func(err error) {
+		if err == nil {
+			if err = m.Ack(); err != nil {
+				n.logger.Printf("%s failed to acknowledge jetstream message: %v", prefix, err)
+			}
+			return
+		}
+		n.logger.Printf("%s jetstream message not acknowledged by the outputs: %v", prefix, err)
+		if err = m.NakWithDelay(n.Cfg.ConnectTimeWait); err != nil {
+			n.logger.Printf("%s failed to negatively acknowledge jetstream message: %v", prefix, err)
+		}
+	}
+}
+
+// terminate stops the redelivery of a message that will never be written,
+// when end-to-end acknowledgements are enabled.
+func (n *NatsInput) terminate(m *nats.Msg) {
+	if !n.Cfg.EndToEndAck {
+		return
+	}
+	m.Term()
+}
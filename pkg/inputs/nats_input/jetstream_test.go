@@ -0,0 +1,61 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package nats_input
+
+import (
+	"testing"
+
+	"github.com/nats-io/nats.go"
+)
+
+func TestGapDetector(t *testing.T) {
+	type result struct {
+		missed uint64
+		reset  bool
+	}
+	tests := []struct {
+		name     string
+		seqs     [][2]uint64 // consumer, stream
+		expected []result
+	}{
+		{
+			name:     "contiguous",
+			seqs:     [][2]uint64{{1, 10}, {2, 12}, {3, 13}},
+			expected: []result{{}, {}, {}},
+		},
+		{
+			name:     "gap",
+			seqs:     [][2]uint64{{1, 10}, {2, 11}, {5, 20}},
+			expected: []result{{}, {}, {missed: 2}},
+		},
+		{
+			name:     "reset",
+			seqs:     [][2]uint64{{1, 10}, {2, 11}, {1, 12}, {2, 13}},
+			expected: []result{{}, {}, {reset: true}, {}},
+		},
+		{
+			name:     "first message",
+			seqs:     [][2]uint64{{7, 100}},
+			expected: []result{{}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := new(gapDetector)
+			for i, seq := range tt.seqs {
+				missed, reset := g.check(&nats.MsgMetadata{
+					Sequence: nats.SequencePair{Consumer: seq[0], Stream: seq[1]},
+				})
+				if missed != tt.expected[i].missed || reset != tt.expected[i].reset {
+					t.Errorf("message %d: expected %+v, got missed=%d reset=%v", i, tt.expected[i], missed, reset)
+				}
+			}
+		})
+	}
+}
@@ -10,7 +10,6 @@ package nats_input
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"log"
@@ -59,7 +58,10 @@ type NatsInput struct {
 	logger *log.Logger
 
 	wg      *sync.WaitGroup
+	name    string
 	outputs []outputs.Output
+	fanOut  *inputs.FanOut
+	decoder *inputs.ProtoDecoder
 	evps    []formatters.EventProcessor
 }
 
@@ -78,7 +80,13 @@ type Config struct {
 	NumWorkers      int              `mapstructure:"num-workers,omitempty"`
 	BufferSize      int              `mapstructure:"buffer-size,omitempty"`
 	Outputs         []string         `mapstructure:"outputs,omitempty"`
+	FanOut          string           `mapstructure:"fan-out,omitempty"`
+	MessageType     string           `mapstructure:"message-type,omitempty"`
+	ProtosetFile    string           `mapstructure:"protoset-file,omitempty"`
 	EventProcessors []string         `mapstructure:"event-processors,omitempty"`
+	JetStream       *JetStreamConfig `mapstructure:"jetstream,omitempty" json:"jetstream,omitempty"`
+	EndToEndAck     bool             `mapstructure:"end-to-end-ack,omitempty"`
+	AckTimeout      time.Duration    `mapstructure:"ack-timeout,omitempty"`
 }
 
 // Init //
@@ -87,6 +95,7 @@ func (n *NatsInput) Start(ctx context.Context, name string, cfg map[string]inter
 	if err != nil {
 		return err
 	}
+	n.name = name
 	if n.Cfg.Name == "" {
 		n.Cfg.Name = name
 	}
@@ -99,16 +108,33 @@ func (n *NatsInput) Start(ctx context.Context, name string, cfg map[string]inter
 	if err != nil {
 		return err
 	}
+	n.fanOut, err = inputs.NewFanOut(n.Cfg.FanOut, n.outputs)
+	if err != nil {
+		return err
+	}
+	if n.Cfg.EndToEndAck {
+		err = inputs.CheckAckOutputs(n.outputs)
+		if err != nil {
+			return err
+		}
+	}
+	if n.Cfg.Format == "proto" {
+		n.decoder, err = inputs.NewProtoDecoder(n.Cfg.MessageType, n.Cfg.ProtosetFile)
+		if err != nil {
+			return err
+		}
+	}
 	n.ctx, n.cfn = context.WithCancel(ctx)
 	n.logger.Printf("input starting with config: %+v", n.Cfg)
 	n.wg.Add(n.Cfg.NumWorkers)
 	for i := 0; i < n.Cfg.NumWorkers; i++ {
-		go n.worker(ctx, i)
+		go n.worker(n.ctx, i)
 	}
 	return nil
 }
 
 func (n *NatsInput) worker(ctx context.Context, idx int) {
+	defer n.wg.Done()
 	var nc *nats.Conn
 	var err error
 	var msgChan chan *nats.Msg
@@ -125,7 +151,14 @@ START:
 	}
 	defer nc.Close()
 	msgChan = make(chan *nats.Msg, n.Cfg.BufferSize)
-	sub, err := nc.ChanQueueSubscribe(n.Cfg.Subject, n.Cfg.Queue, msgChan)
+	var sub *nats.Subscription
+	var gaps *gapDetector
+	if n.Cfg.JetStream != nil {
+		gaps = new(gapDetector)
+		sub, err = n.jetStreamSubscribe(nc, msgChan)
+	} else {
+		sub, err = nc.ChanQueueSubscribe(n.Cfg.Subject, n.Cfg.Queue, msgChan)
+	}
 	if err != nil {
 		n.logger.Printf("%s failed to create NATS subscription: %v", workerLogPrefix, err)
 		time.Sleep(n.Cfg.ConnectTimeWait)
@@ -133,7 +166,13 @@ START:
 		goto START
 	}
 	defer close(msgChan)
-	defer sub.Unsubscribe()
+	if n.Cfg.JetStream == nil {
+		defer sub.Unsubscribe()
+	} else {
+		// unsubscribing deletes the jetstream consumer created by the subscription,
+		// closing the connection stops the deliveries and keeps it for the next start.
+		defer nc.Close()
+	}
 
 	for {
 		select {
@@ -146,30 +185,46 @@ START:
 				nc.Close()
 				goto START
 			}
+			if gaps != nil {
+				n.reportGaps(workerLogPrefix, gaps, m)
+				if !n.Cfg.JetStream.OrderedConsumer && !n.Cfg.EndToEndAck {
+					m.Ack()
+				}
+			}
 			if len(m.Data) == 0 {
+				n.terminate(m)
 				continue
 			}
+			inputs.MessageReceived(n.name)
 			if n.Cfg.Debug {
 				n.logger.Printf("received msg, subject=%s, queue=%s, len=%d, data=%s", m.Subject, m.Sub.Queue, len(m.Data), string(m.Data))
 			}
 
 			switch n.Cfg.Format {
-			case "event":
-				evMsgs := make([]*formatters.EventMsg, 1)
-				err = json.Unmarshal(m.Data, &evMsgs)
+			case "event", "cbor", "msgpack":
+				var evMsgs []*formatters.EventMsg
+				evMsgs, err = formatters.DecodeEventsFormat(n.Cfg.Format, m.Data)
 				if err != nil {
+					inputs.MessageError(n.name, inputs.ErrorReasonDecode)
 					if n.Cfg.Debug {
 						n.logger.Printf("%s failed to unmarshal event msg: %v", workerLogPrefix, err)
 					}
+					n.terminate(m)
 					continue
 				}
+				inputs.MessageDecoded(n.name)
 
 				for _, p := range n.evps {
 					evMsgs = p.Apply(evMsgs...)
 				}
 
+				outs := n.fanOut.Outputs()
+				if n.Cfg.EndToEndAck {
+					go inputs.WriteEventsAck(ctx, outs, evMsgs, n.Cfg.AckTimeout, n.msgAck(workerLogPrefix, m))
+					continue
+				}
 				go func() {
-					for _, o := range n.outputs {
+					for _, o := range outs {
 						for _, ev := range evMsgs {
 							o.WriteEvent(ctx, ev)
 						}
@@ -177,21 +232,29 @@ START:
 				}()
 			case "proto":
 				var protoMsg proto.Message
-				err = proto.Unmarshal(m.Data, protoMsg)
+				protoMsg, err = n.decoder.Decode(m.Data)
 				if err != nil {
+					inputs.MessageError(n.name, inputs.ErrorReasonDecode)
 					if n.Cfg.Debug {
 						n.logger.Printf("failed to unmarshal proto msg: %v", err)
 					}
+					n.terminate(m)
 					continue
 				}
+				inputs.MessageDecoded(n.name)
 				meta := outputs.Meta{}
 				subjectSections := strings.SplitN(m.Subject, ".", 3)
 				if len(subjectSections) == 3 {
 					meta["source"] = strings.ReplaceAll(subjectSections[1], "-", ".")
 					meta["subscription-name"] = subjectSections[2]
 				}
+				outs := n.fanOut.Outputs()
+				if n.Cfg.EndToEndAck {
+					go inputs.WriteAck(ctx, outs, protoMsg, meta, n.Cfg.AckTimeout, n.msgAck(workerLogPrefix, m))
+					continue
+				}
 				go func() {
-					for _, o := range n.outputs {
+					for _, o := range outs {
 						o.Write(ctx, protoMsg, meta)
 					}
 				}()
@@ -263,7 +326,7 @@ func (n *NatsInput) setDefaults() error {
 	if n.Cfg.Format == "" {
 		n.Cfg.Format = defaultFormat
 	}
-	if !(strings.ToLower(n.Cfg.Format) == "event" || strings.ToLower(n.Cfg.Format) == "proto") {
+	if !(formatters.IsEventFormat(strings.ToLower(n.Cfg.Format)) || strings.ToLower(n.Cfg.Format) == "proto") {
 		return fmt.Errorf("unsupported input format")
 	}
 	if n.Cfg.Name == "" {
@@ -287,6 +350,24 @@ func (n *NatsInput) setDefaults() error {
 	if n.Cfg.BufferSize <= 0 {
 		n.Cfg.BufferSize = defaultBufferSize
 	}
+	if n.Cfg.JetStream != nil {
+		if err := n.Cfg.JetStream.validate(); err != nil {
+			return err
+		}
+		// an ordered consumer delivers all the messages to a single subscriber
+		if n.Cfg.JetStream.OrderedConsumer && n.Cfg.NumWorkers > 1 {
+			n.logger.Printf("jetstream ordered consumer requires a single worker, setting num-workers to 1")
+			n.Cfg.NumWorkers = 1
+		}
+	}
+	if n.Cfg.EndToEndAck {
+		if n.Cfg.JetStream == nil || n.Cfg.JetStream.OrderedConsumer {
+			return fmt.Errorf("end-to-end-ack requires a jetstream durable consumer")
+		}
+		if n.Cfg.AckTimeout <= 0 {
+			n.Cfg.AckTimeout = inputs.DefaultAckTimeout
+		}
+	}
 	return nil
 }
 
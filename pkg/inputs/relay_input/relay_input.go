@@ -0,0 +1,352 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package relay_input
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	_ "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/api/utils"
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/inputs"
+	"github.com/openconfig/gnmic/pkg/outputs"
+	"github.com/openconfig/gnmic/pkg/relay"
+)
+
+const (
+	loggingPrefix  = "[relay_input] "
+	defaultAddress = ":57401"
+	// a sender without an open stream is forgotten after this duration,
+	// the batches it replays after reconnecting within it are discarded.
+	senderRetention = time.Hour
+)
+
+func init() {
+	inputs.Register("relay", func() inputs.Input {
+		return &RelayInput{
+			Cfg:     &Config{},
+			logger:  log.New(io.Discard, loggingPrefix, utils.DefaultLoggingFlags),
+			senders: make(map[string]*senderState),
+		}
+	})
+}
+
+// RelayInput receives the messages streamed by relay outputs
+// and writes them to its outputs.
+type RelayInput struct {
+	Cfg    *Config
+	cfn    context.CancelFunc
+	logger *log.Logger
+
+	grpcSrv *grpc.Server
+	name    string
+	outputs []outputs.Output
+	fanOut  *inputs.FanOut
+	evps    []formatters.EventProcessor
+
+	m sync.Mutex
+	// state per sender ID
+	senders map[string]*senderState
+}
+
+type senderState struct {
+	// last processed batch sequence number
+	seq uint64
+	// number of open streams
+	streams int
+	// time the last stream was closed
+	closedAt time.Time
+}
+
+// Config //
+type Config struct {
+	Name            string           `mapstructure:"name,omitempty" json:"name,omitempty"`
+	Address         string           `mapstructure:"address,omitempty" json:"address,omitempty"`
+	TLS             *types.TLSConfig `mapstructure:"tls,omitempty" json:"tls,omitempty"`
+	MaxRecvMsgSize  int              `mapstructure:"max-recv-msg-size,omitempty" json:"max-recv-msg-size,omitempty"`
+	Debug           bool             `mapstructure:"debug,omitempty" json:"debug,omitempty"`
+	Outputs         []string         `mapstructure:"outputs,omitempty" json:"outputs,omitempty"`
+	FanOut          string           `mapstructure:"fan-out,omitempty" json:"fan-out,omitempty"`
+	EventProcessors []string         `mapstructure:"event-processors,omitempty" json:"event-processors,omitempty"`
+}
+
+// Start //
+func (r *RelayInput) Start(ctx context.Context, name string, cfg map[string]interface{}, opts ...inputs.Option) error {
+	err := outputs.DecodeConfig(cfg, r.Cfg)
+	if err != nil {
+		return err
+	}
+	r.name = name
+	if r.Cfg.Name == "" {
+		r.Cfg.Name = name
+	}
+	for _, opt := range opts {
+		if err := opt(r); err != nil {
+			return err
+		}
+	}
+	r.setDefaults()
+	r.fanOut, err = inputs.NewFanOut(r.Cfg.FanOut, r.outputs)
+	if err != nil {
+		return err
+	}
+	srvOpts, err := r.serverOpts()
+	if err != nil {
+		return err
+	}
+	l, err := net.Listen("tcp", r.Cfg.Address)
+	if err != nil {
+		return err
+	}
+	ctx, r.cfn = context.WithCancel(ctx)
+	r.grpcSrv = grpc.NewServer(srvOpts...)
+	relay.RegisterRelayServer(r.grpcSrv, &server{ctx: ctx, input: r})
+	r.logger.Printf("input starting with config: %+v", r.Cfg)
+	go func() {
+		err := r.grpcSrv.Serve(l)
+		if err != nil {
+			r.logger.Printf("relay server stopped: %v", err)
+		}
+	}()
+	return nil
+}
+
+func (r *RelayInput) serverOpts() ([]grpc.ServerOption, error) {
+	opts := make([]grpc.ServerOption, 0, 2)
+	if r.Cfg.MaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(r.Cfg.MaxRecvMsgSize))
+	}
+	if r.Cfg.TLS == nil {
+		return opts, nil
+	}
+	tlscfg, err := utils.NewTLSConfig(
+		r.Cfg.TLS.CaFile,
+		r.Cfg.TLS.CertFile,
+		r.Cfg.TLS.KeyFile,
+		r.Cfg.TLS.ClientAuth,
+		false,
+		true,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if tlscfg != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlscfg)))
+	}
+	return opts, nil
+}
+
+// server implements relay.RelayServer.
+type server struct {
+	relay.UnimplementedRelayServer
+	ctx   context.Context
+	input *RelayInput
+}
+
+func (s *server) Publish(stream relay.Relay_PublishServer) error {
+	var sender string
+	if md, ok := metadata.FromIncomingContext(stream.Context()); ok {
+		if vs := md.Get(relay.SenderMetadataKey); len(vs) > 0 {
+			sender = vs[0]
+		}
+	}
+	if sender == "" {
+		return status.Errorf(codes.InvalidArgument, "missing metadata key %q", relay.SenderMetadataKey)
+	}
+	s.input.logger.Printf("relay stream from sender %q started", sender)
+	s.input.openStream(sender, time.Now())
+	defer func() { s.input.closeStream(sender, time.Now()) }()
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			s.input.logger.Printf("relay stream from sender %q failed: %v", sender, err)
+			return err
+		}
+		if s.input.accept(sender, req.Sequence) {
+			s.input.dispatch(s.ctx, req)
+		} else if s.input.Cfg.Debug {
+			s.input.logger.Printf("sender %q: discarding already processed batch %d", sender, req.Sequence)
+		}
+		err = stream.Send(&relay.PublishResponse{Sequence: req.Sequence})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// openStream records a stream opened by sender,
+// and forgets the senders without an open stream for longer than senderRetention.
+func (r *RelayInput) openStream(sender string, now time.Time) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	for id, st := range r.senders {
+		if st.streams == 0 && now.Sub(st.closedAt) > senderRetention {
+			delete(r.senders, id)
+		}
+	}
+	st, ok := r.senders[sender]
+	if !ok {
+		st = new(senderState)
+		r.senders[sender] = st
+	}
+	st.streams++
+}
+
+func (r *RelayInput) closeStream(sender string, now time.Time) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	st, ok := r.senders[sender]
+	if !ok {
+		return
+	}
+	st.streams--
+	st.closedAt = now
+}
+
+// accept returns true if the batch with sequence number seq
+// was not processed yet, batches are replayed by a sender
+// when the acknowledgement was lost.
+func (r *RelayInput) accept(sender string, seq uint64) bool {
+	r.m.Lock()
+	defer r.m.Unlock()
+	st, ok := r.senders[sender]
+	if !ok {
+		st = new(senderState)
+		r.senders[sender] = st
+	}
+	if seq <= st.seq {
+		return false
+	}
+	st.seq = seq
+	return true
+}
+
+// dispatch writes the batch messages to the outputs,
+// the batch is acknowledged once all the outputs accepted its messages.
+func (r *RelayInput) dispatch(ctx context.Context, req *relay.PublishRequest) {
+	if r.Cfg.Debug {
+		r.logger.Printf("received batch %d with %d message(s)", req.Sequence, len(req.Messages))
+	}
+	outs := r.fanOut.Outputs()
+	evs := make([]*formatters.EventMsg, 0, len(req.Messages))
+	for _, m := range req.Messages {
+		inputs.MessageReceived(r.name)
+		switch {
+		case m.Event != nil:
+			ev, err := m.Event.EventMsg()
+			if err != nil {
+				inputs.MessageError(r.name, inputs.ErrorReasonDecode)
+				r.logger.Printf("failed to convert event: %v", err)
+				continue
+			}
+			inputs.MessageDecoded(r.name)
+			evs = append(evs, ev)
+		case m.Response != nil:
+			inputs.MessageDecoded(r.name)
+			for _, o := range outs {
+				o.Write(ctx, m.Response, m.Meta)
+			}
+		}
+	}
+	if len(evs) == 0 {
+		return
+	}
+	for _, p := range r.evps {
+		evs = p.Apply(evs...)
+	}
+	for _, o := range outs {
+		for _, ev := range evs {
+			o.WriteEvent(ctx, ev)
+		}
+	}
+}
+
+// Close //
+func (r *RelayInput) Close() error {
+	if r.cfn != nil {
+		r.cfn()
+	}
+	if r.grpcSrv != nil {
+		r.grpcSrv.Stop()
+	}
+	return nil
+}
+
+// SetLogger //
+func (r *RelayInput) SetLogger(logger *log.Logger) {
+	if logger != nil && r.logger != nil {
+		r.logger.SetOutput(logger.Writer())
+		r.logger.SetFlags(logger.Flags())
+	}
+}
+
+// SetOutputs //
+func (r *RelayInput) SetOutputs(outs map[string]outputs.Output) {
+	if len(r.Cfg.Outputs) == 0 {
+		for _, o := range outs {
+			r.outputs = append(r.outputs, o)
+		}
+		return
+	}
+	for _, name := range r.Cfg.Outputs {
+		if o, ok := outs[name]; ok {
+			r.outputs = append(r.outputs, o)
+		}
+	}
+}
+
+func (r *RelayInput) SetName(name string) {
+	sb := strings.Builder{}
+	if name != "" {
+		sb.WriteString(name)
+		sb.WriteString("-")
+	}
+	sb.WriteString(r.Cfg.Name)
+	sb.WriteString("-relay")
+	r.Cfg.Name = sb.String()
+}
+
+func (r *RelayInput) SetEventProcessors(ps map[string]map[string]interface{}, logger *log.Logger, tcs map[string]*types.TargetConfig, acts map[string]map[string]interface{}) error {
+	var err error
+	r.evps, err = formatters.MakeEventProcessors(
+		logger,
+		r.Cfg.EventProcessors,
+		ps,
+		tcs,
+		acts,
+	)
+	return err
+}
+
+func (r *RelayInput) setDefaults() {
+	if r.Cfg.Name == "" {
+		r.Cfg.Name = "gnmic-" + uuid.New().String()
+	}
+	if r.Cfg.Address == "" {
+		r.Cfg.Address = defaultAddress
+	}
+}
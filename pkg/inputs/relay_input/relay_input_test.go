@@ -0,0 +1,45 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package relay_input
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/inputs"
+)
+
+func TestSenders(t *testing.T) {
+	r := inputs.Inputs["relay"]().(*RelayInput)
+	now := time.Now()
+
+	r.openStream("s1", now)
+	if !r.accept("s1", 1) || !r.accept("s1", 2) {
+		t.Fatalf("new batches not accepted")
+	}
+	if r.accept("s1", 2) {
+		t.Errorf("replayed batch accepted")
+	}
+	// a reconnecting sender replays the unacknowledged batches
+	r.closeStream("s1", now)
+	r.openStream("s1", now.Add(time.Minute))
+	if r.accept("s1", 2) {
+		t.Errorf("batch replayed after a reconnection accepted")
+	}
+	r.closeStream("s1", now.Add(time.Minute))
+
+	// a sender gone for longer than the retention is forgotten
+	r.openStream("s2", now.Add(senderRetention+2*time.Minute))
+	if _, ok := r.senders["s1"]; ok {
+		t.Errorf("sender s1 not removed")
+	}
+	if _, ok := r.senders["s2"]; !ok {
+		t.Errorf("sender s2 removed")
+	}
+}
@@ -60,7 +60,10 @@ type StanInput struct {
 	logger *log.Logger
 
 	wg      *sync.WaitGroup
+	name    string
 	outputs []outputs.Output
+	fanOut  *inputs.FanOut
+	decoder *inputs.ProtoDecoder
 	evps    []formatters.EventProcessor
 }
 
@@ -81,6 +84,9 @@ type Config struct {
 	Debug           bool             `mapstructure:"debug,omitempty"`
 	NumWorkers      int              `mapstructure:"num-workers,omitempty"`
 	Outputs         []string         `mapstructure:"outputs,omitempty"`
+	FanOut          string           `mapstructure:"fan-out,omitempty"`
+	MessageType     string           `mapstructure:"message-type,omitempty"`
+	ProtosetFile    string           `mapstructure:"protoset-file,omitempty"`
 	EventProcessors []string         `mapstructure:"event-processors,omitempty"`
 }
 
@@ -89,6 +95,7 @@ func (s *StanInput) Start(ctx context.Context, name string, cfg map[string]inter
 	if err != nil {
 		return err
 	}
+	s.name = name
 	if s.Cfg.Name == "" {
 		s.Cfg.Name = name
 	}
@@ -101,10 +108,20 @@ func (s *StanInput) Start(ctx context.Context, name string, cfg map[string]inter
 	if err != nil {
 		return err
 	}
+	s.fanOut, err = inputs.NewFanOut(s.Cfg.FanOut, s.outputs)
+	if err != nil {
+		return err
+	}
+	if s.Cfg.Format == "proto" {
+		s.decoder, err = inputs.NewProtoDecoder(s.Cfg.MessageType, s.Cfg.ProtosetFile)
+		if err != nil {
+			return err
+		}
+	}
 	s.ctx, s.cfn = context.WithCancel(ctx)
 	s.wg.Add(s.Cfg.NumWorkers)
 	for i := 0; i < s.Cfg.NumWorkers; i++ {
-		go s.worker(ctx, i)
+		go s.worker(s.ctx, i)
 	}
 	return nil
 }
@@ -136,8 +153,9 @@ START:
 		time.Sleep(s.Cfg.ConnectTimeWait)
 		goto START
 	}
+	// closing the subscription keeps the durable subscription
+	// position for the next start, unsubscribing would remove it.
 	defer sub.Close()
-	defer sub.Unsubscribe()
 	<-ctx.Done()
 }
 
@@ -289,27 +307,31 @@ func (s *StanInput) handleMsg(m *stan.Msg) {
 	if m == nil || len(m.Data) == 0 {
 		return
 	}
+	inputs.MessageReceived(s.name)
 	if s.Cfg.Debug {
 		s.logger.Printf("received msg, subject=%q, queue=%q, len=%d, data=%s", m.Subject, s.Cfg.Queue, len(m.Data), string(m.Data))
 	}
 	var err error
 	switch s.Cfg.Format {
 	case "event":
-		evMsgs := make([]*formatters.EventMsg, 1)
-		err = json.Unmarshal(m.Data, &evMsgs)
+		var evMsgs []*formatters.EventMsg
+		evMsgs, err = formatters.DecodeEvents(m.Data)
 		if err != nil {
+			inputs.MessageError(s.name, inputs.ErrorReasonDecode)
 			if s.Cfg.Debug {
 				s.logger.Printf("failed to unmarshal event msg: %v", err)
 			}
 			return
 		}
+		inputs.MessageDecoded(s.name)
 
 		for _, p := range s.evps {
 			evMsgs = p.Apply(evMsgs...)
 		}
 
+		outs := s.fanOut.Outputs()
 		go func() {
-			for _, o := range s.outputs {
+			for _, o := range outs {
 				for _, ev := range evMsgs {
 					o.WriteEvent(s.ctx, ev)
 				}
@@ -317,21 +339,24 @@ func (s *StanInput) handleMsg(m *stan.Msg) {
 		}()
 	case "proto":
 		var protoMsg proto.Message
-		err = proto.Unmarshal(m.Data, protoMsg)
+		protoMsg, err = s.decoder.Decode(m.Data)
 		if err != nil {
+			inputs.MessageError(s.name, inputs.ErrorReasonDecode)
 			if s.Cfg.Debug {
 				s.logger.Printf("failed to unmarshal proto msg: %v", err)
 			}
 			return
 		}
+		inputs.MessageDecoded(s.name)
 		meta := outputs.Meta{}
 		subjectSections := strings.SplitN(m.Subject, ".", 3)
 		if len(subjectSections) == 3 {
 			meta["source"] = strings.ReplaceAll(subjectSections[1], "-", ".")
 			meta["subscription-name"] = subjectSections[2]
 		}
+		outs := s.fanOut.Outputs()
 		go func() {
-			for _, o := range s.outputs {
+			for _, o := range outs {
 				o.Write(s.ctx, protoMsg, meta)
 			}
 		}()
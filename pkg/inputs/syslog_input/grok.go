@@ -0,0 +1,141 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package syslog_input
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const maxGrokDepth = 10
+
+// grokPatterns are the built-in grok patterns.
+var grokPatterns = map[string]string{
+	"WORD":         `\b\w+\b`,
+	"NOTSPACE":     `\S+`,
+	"SPACE":        `\s*`,
+	"DATA":         `.*?`,
+	"GREEDYDATA":   `.*`,
+	"INT":          `[+-]?\d+`,
+	"NUMBER":       `[+-]?(?:\d+(?:\.\d*)?|\.\d+)`,
+	"USERNAME":     `[a-zA-Z0-9._-]+`,
+	"QUOTEDSTRING": `"(?:[^"\\]|\\.)*"`,
+	"IPV4":         `(?:\d{1,3}\.){3}\d{1,3}`,
+	"IPV6":         `[0-9A-Fa-f]{0,4}(?::[0-9A-Fa-f]{0,4}){2,7}(?:%\w+)?`,
+	"IP":           `(?:%{IPV4}|%{IPV6})`,
+	"HOSTNAME":     `\b[0-9A-Za-z][0-9A-Za-z-]{0,62}(?:\.[0-9A-Za-z][0-9A-Za-z-]{0,62})*\.?\b`,
+	"IPORHOST":     `(?:%{IP}|%{HOSTNAME})`,
+	"MAC":          `(?:(?:[0-9A-Fa-f]{2}[:-]){5}[0-9A-Fa-f]{2}|(?:[0-9A-Fa-f]{4}\.){2}[0-9A-Fa-f]{4})`,
+	"INTERFACE":    `[A-Za-z][\w-]*\d+(?:[/:.]\d+)*`,
+}
+
+var grokRegex = regexp.MustCompile(`%\{(\w+)(?::([\w.-]+))?(?::(int|float))?\}`)
+
+// extractor extracts the fields of a message with a regular expression,
+// the fields are the named groups and the named grok patterns.
+type extractor struct {
+	re *regexp.Regexp
+	// field name and type per capture group name.
+	fields map[string]field
+}
+
+type field struct {
+	name string
+	typ  string
+}
+
+// newExtractor compiles a pattern mixing regular expressions
+// and grok patterns `%{PATTERN[:field[:type]]}`.
+func newExtractor(pattern string, custom map[string]string) (*extractor, error) {
+	e := &extractor{fields: make(map[string]field)}
+	expanded, err := e.expand(pattern, custom, 0)
+	if err != nil {
+		return nil, err
+	}
+	e.re, err = regexp.Compile(expanded)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range e.re.SubexpNames() {
+		if name == "" {
+			continue
+		}
+		if _, ok := e.fields[name]; !ok {
+			e.fields[name] = field{name: name}
+		}
+	}
+	return e, nil
+}
+
+func (e *extractor) expand(pattern string, custom map[string]string, depth int) (string, error) {
+	if depth > maxGrokDepth {
+		return "", fmt.Errorf("grok patterns nested more than %d levels", maxGrokDepth)
+	}
+	var err error
+	res := grokRegex.ReplaceAllStringFunc(pattern, func(s string) string {
+		if err != nil {
+			return ""
+		}
+		sm := grokRegex.FindStringSubmatch(s)
+		p, ok := custom[sm[1]]
+		if !ok {
+			p, ok = grokPatterns[sm[1]]
+		}
+		if !ok {
+			err = fmt.Errorf("unknown grok pattern %q", sm[1])
+			return ""
+		}
+		p, err = e.expand(p, custom, depth+1)
+		if err != nil {
+			return ""
+		}
+		if sm[2] == "" {
+			return "(?:" + p + ")"
+		}
+		// grok field names are not restricted to the
+		// regular expression group names characters.
+		group := "grok" + strconv.Itoa(len(e.fields))
+		e.fields[group] = field{name: sm[2], typ: sm[3]}
+		return "(?P<" + group + ">" + p + ")"
+	})
+	return res, err
+}
+
+// extract returns the fields of msg, or false if the pattern does not match.
+func (e *extractor) extract(msg string) (map[string]interface{}, bool) {
+	sm := e.re.FindStringSubmatch(msg)
+	if sm == nil {
+		return nil, false
+	}
+	res := make(map[string]interface{})
+	for i, group := range e.re.SubexpNames() {
+		f, ok := e.fields[group]
+		if !ok || i >= len(sm) || sm[i] == "" {
+			continue
+		}
+		res[f.name] = convert(sm[i], f.typ)
+	}
+	return res, true
+}
+
+func convert(s, typ string) interface{} {
+	switch typ {
+	case "int":
+		if v, err := strconv.ParseInt(strings.TrimPrefix(s, "+"), 10, 64); err == nil {
+			return v
+		}
+	case "float":
+		if v, err := strconv.ParseFloat(s, 64); err == nil {
+			return v
+		}
+	}
+	return s
+}
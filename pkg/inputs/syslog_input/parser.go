@@ -0,0 +1,212 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package syslog_input
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var facilities = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+var severities = []string{
+	"emergency", "alert", "critical", "error", "warning", "notice", "informational", "debug",
+}
+
+// message is a parsed syslog message,
+// the fields absent from the message are empty.
+type message struct {
+	facility  int
+	severity  int
+	timestamp time.Time
+	hostname  string
+	appName   string
+	procID    string
+	msgID     string
+	// structured data parameters indexed by SD-ID and parameter name.
+	structuredData map[string]map[string]string
+	msg            string
+}
+
+// parse parses an RFC5424 or RFC3164 message, the RFC3164 timestamps
+// have no year and are set in the current year of now, in location loc.
+func parse(b []byte, now time.Time, loc *time.Location) (*message, error) {
+	s := strings.TrimRight(string(b), "\r\n\x00")
+	if !strings.HasPrefix(s, "<") {
+		return nil, errors.New("missing priority")
+	}
+	end := strings.IndexByte(s, '>')
+	if end < 2 || end > 4 {
+		return nil, errors.New("invalid priority")
+	}
+	pri, err := strconv.Atoi(s[1:end])
+	if err != nil || pri > 191 {
+		return nil, fmt.Errorf("invalid priority %q", s[1:end])
+	}
+	m := &message{
+		facility: pri / 8,
+		severity: pri % 8,
+	}
+	s = s[end+1:]
+	if strings.HasPrefix(s, "1 ") {
+		err = m.parseRFC5424(s[2:])
+	} else {
+		err = m.parseRFC3164(s, now, loc)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// parseRFC5424 parses the message after its version:
+// TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA [MSG]
+func (m *message) parseRFC5424(s string) error {
+	fields := make([]string, 5)
+	for i := range fields {
+		var ok bool
+		fields[i], s, ok = strings.Cut(s, " ")
+		if !ok && i < len(fields)-1 {
+			return errors.New("truncated RFC5424 header")
+		}
+		if fields[i] == "-" {
+			fields[i] = ""
+		}
+	}
+	if fields[0] != "" {
+		ts, err := time.Parse(time.RFC3339Nano, fields[0])
+		if err != nil {
+			return fmt.Errorf("invalid timestamp: %v", err)
+		}
+		m.timestamp = ts
+	}
+	m.hostname, m.appName, m.procID, m.msgID = fields[1], fields[2], fields[3], fields[4]
+	if strings.HasPrefix(s, "-") {
+		s = s[1:]
+	} else {
+		var err error
+		s, err = m.parseStructuredData(s)
+		if err != nil {
+			return err
+		}
+	}
+	s = strings.TrimPrefix(s, " ")
+	m.msg = strings.TrimPrefix(s, "\ufeff")
+	return nil
+}
+
+// parseStructuredData parses the SD-ELEMENTs at the start of s
+// and returns the rest of s.
+func (m *message) parseStructuredData(s string) (string, error) {
+	m.structuredData = make(map[string]map[string]string)
+	for strings.HasPrefix(s, "[") {
+		s = s[1:]
+		idEnd := strings.IndexAny(s, " ]")
+		if idEnd <= 0 {
+			return "", errors.New("invalid structured data ID")
+		}
+		id := s[:idEnd]
+		params := make(map[string]string)
+		m.structuredData[id] = params
+		s = s[idEnd:]
+		for {
+			s = strings.TrimLeft(s, " ")
+			if strings.HasPrefix(s, "]") {
+				s = s[1:]
+				break
+			}
+			name, rest, ok := strings.Cut(s, "=\"")
+			if !ok || name == "" {
+				return "", fmt.Errorf("invalid structured data parameter in %q", id)
+			}
+			var sb strings.Builder
+			i := 0
+			for ; i < len(rest); i++ {
+				c := rest[i]
+				if c == '\\' && i+1 < len(rest) && strings.IndexByte(`"\]`, rest[i+1]) >= 0 {
+					i++
+					sb.WriteByte(rest[i])
+					continue
+				}
+				if c == '"' {
+					break
+				}
+				sb.WriteByte(c)
+			}
+			if i == len(rest) {
+				return "", fmt.Errorf("unterminated structured data parameter %q", name)
+			}
+			params[name] = sb.String()
+			s = rest[i+1:]
+		}
+	}
+	return s, nil
+}
+
+// parseRFC3164 parses the message after its priority:
+// Mmm dd hh:mm:ss [HOSTNAME] TAG[PID]: MSG
+func (m *message) parseRFC3164(s string, now time.Time, loc *time.Location) error {
+	if len(s) >= len(time.Stamp) {
+		ts, err := time.ParseInLocation(time.Stamp, s[:len(time.Stamp)], loc)
+		if err == nil {
+			m.timestamp = ts.AddDate(now.In(loc).Year(), 0, 0)
+			// messages from the last days of December received in January.
+			if m.timestamp.After(now.Add(24 * time.Hour)) {
+				m.timestamp = m.timestamp.AddDate(-1, 0, 0)
+			}
+			s = strings.TrimPrefix(s[len(time.Stamp):], " ")
+		}
+	}
+	// the hostname is optional, the tag ends with ':' or '['.
+	first, rest, _ := strings.Cut(s, " ")
+	if first != "" && !strings.ContainsAny(first, ":[") {
+		m.hostname = first
+		s = rest
+	}
+	tagEnd := strings.IndexAny(s, ":[ ")
+	if tagEnd > 0 && tagEnd <= 48 {
+		m.appName = s[:tagEnd]
+		rest := s[tagEnd:]
+		if strings.HasPrefix(rest, "[") {
+			if pidEnd := strings.IndexByte(rest, ']'); pidEnd > 0 {
+				m.procID = rest[1:pidEnd]
+				rest = rest[pidEnd+1:]
+			}
+		}
+		if strings.HasPrefix(rest, ":") {
+			s = strings.TrimPrefix(rest[1:], " ")
+		} else {
+			// no tag
+			m.appName, m.procID = "", ""
+		}
+	}
+	m.msg = s
+	return nil
+}
+
+func facilityName(f int) string {
+	if f >= 0 && f < len(facilities) {
+		return facilities[f]
+	}
+	return strconv.Itoa(f)
+}
+
+func severityName(s int) string {
+	if s >= 0 && s < len(severities) {
+		return severities[s]
+	}
+	return strconv.Itoa(s)
+}
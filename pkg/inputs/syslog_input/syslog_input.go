@@ -0,0 +1,418 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package syslog_input
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/api/utils"
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/inputs"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+const (
+	loggingPrefix         = "[syslog_input] "
+	defaultAddress        = ":514"
+	defaultProtocol       = "udp"
+	defaultMaxMessageSize = 64 * 1024
+
+	eventName = "syslog"
+)
+
+func init() {
+	inputs.Register("syslog", func() inputs.Input {
+		return &SyslogInput{
+			Cfg:    &Config{},
+			logger: log.New(io.Discard, loggingPrefix, utils.DefaultLoggingFlags),
+		}
+	})
+}
+
+// SyslogInput receives RFC5424 and RFC3164 syslog messages,
+// extracts their fields and writes them as events to its outputs.
+type SyslogInput struct {
+	Cfg    *Config
+	cfn    context.CancelFunc
+	logger *log.Logger
+
+	conn     net.PacketConn
+	listener net.Listener
+	name     string
+	outputs  []outputs.Output
+	fanOut   *inputs.FanOut
+	evps     []formatters.EventProcessor
+
+	loc        *time.Location
+	extractors []*extractor
+	tagNames   map[string]struct{}
+}
+
+// Config //
+type Config struct {
+	Name string `mapstructure:"name,omitempty" json:"name,omitempty"`
+	// address the input listens on.
+	Address string `mapstructure:"address,omitempty" json:"address,omitempty"`
+	// udp or tcp.
+	Protocol       string `mapstructure:"protocol,omitempty" json:"protocol,omitempty"`
+	MaxMessageSize int    `mapstructure:"max-message-size,omitempty" json:"max-message-size,omitempty"`
+	// location of the RFC3164 timestamps, which have no time zone.
+	Timezone string `mapstructure:"timezone,omitempty" json:"timezone,omitempty"`
+	// regular expressions or grok patterns extracting fields from the messages,
+	// the first matching pattern is used.
+	Patterns     []string          `mapstructure:"patterns,omitempty" json:"patterns,omitempty"`
+	GrokPatterns map[string]string `mapstructure:"grok-patterns,omitempty" json:"grok-patterns,omitempty"`
+	// extracted fields added as tags instead of values.
+	TagNames        []string `mapstructure:"tag-names,omitempty" json:"tag-names,omitempty"`
+	Debug           bool     `mapstructure:"debug,omitempty" json:"debug,omitempty"`
+	Outputs         []string `mapstructure:"outputs,omitempty" json:"outputs,omitempty"`
+	FanOut          string   `mapstructure:"fan-out,omitempty" json:"fan-out,omitempty"`
+	EventProcessors []string `mapstructure:"event-processors,omitempty" json:"event-processors,omitempty"`
+}
+
+// Start //
+func (s *SyslogInput) Start(ctx context.Context, name string, cfg map[string]interface{}, opts ...inputs.Option) error {
+	err := outputs.DecodeConfig(cfg, s.Cfg)
+	if err != nil {
+		return err
+	}
+	s.name = name
+	if s.Cfg.Name == "" {
+		s.Cfg.Name = name
+	}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return err
+		}
+	}
+	err = s.setDefaults()
+	if err != nil {
+		return err
+	}
+	err = s.init()
+	if err != nil {
+		return err
+	}
+	s.fanOut, err = inputs.NewFanOut(s.Cfg.FanOut, s.outputs)
+	if err != nil {
+		return err
+	}
+	ctx, s.cfn = context.WithCancel(ctx)
+	s.logger.Printf("input starting with config: %+v", s.Cfg)
+	switch s.Cfg.Protocol {
+	case "udp":
+		s.conn, err = net.ListenPacket("udp", s.Cfg.Address)
+		if err != nil {
+			return err
+		}
+		go s.receiveUDP(ctx)
+	case "tcp":
+		s.listener, err = net.Listen("tcp", s.Cfg.Address)
+		if err != nil {
+			return err
+		}
+		go s.acceptTCP(ctx)
+	}
+	return nil
+}
+
+func (s *SyslogInput) init() error {
+	var err error
+	s.loc = time.Local
+	if s.Cfg.Timezone != "" {
+		s.loc, err = time.LoadLocation(s.Cfg.Timezone)
+		if err != nil {
+			return err
+		}
+	}
+	s.extractors = make([]*extractor, 0, len(s.Cfg.Patterns))
+	for i, p := range s.Cfg.Patterns {
+		e, err := newExtractor(p, s.Cfg.GrokPatterns)
+		if err != nil {
+			return fmt.Errorf("pattern %d: %v", i, err)
+		}
+		s.extractors = append(s.extractors, e)
+	}
+	s.tagNames = make(map[string]struct{}, len(s.Cfg.TagNames))
+	for _, n := range s.Cfg.TagNames {
+		s.tagNames[n] = struct{}{}
+	}
+	return nil
+}
+
+func (s *SyslogInput) receiveUDP(ctx context.Context) {
+	buf := make([]byte, s.Cfg.MaxMessageSize)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return
+			}
+			s.logger.Printf("failed to read datagram: %v", err)
+			continue
+		}
+		s.handle(ctx, addr, buf[:n])
+	}
+}
+
+func (s *SyslogInput) acceptTCP(ctx context.Context) {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return
+			}
+			s.logger.Printf("failed to accept connection: %v", err)
+			continue
+		}
+		go s.receiveTCP(ctx, conn)
+	}
+}
+
+func (s *SyslogInput) receiveTCP(ctx context.Context, conn net.Conn) {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		conn.Close()
+	}()
+	err := readFrames(bufio.NewReader(conn), s.Cfg.MaxMessageSize, func(b []byte) {
+		s.handle(ctx, conn.RemoteAddr(), b)
+	})
+	if err != nil && ctx.Err() == nil && !errors.Is(err, net.ErrClosed) {
+		s.logger.Printf("connection from %s closed: %v", conn.RemoteAddr(), err)
+	}
+}
+
+// readFrames reads the messages of a TCP stream, framed by octet counting
+// or terminated by a newline (RFC6587), and calls fn for each of them.
+func readFrames(r *bufio.Reader, maxSize int, fn func([]byte)) error {
+	for {
+		c, err := r.Peek(1)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if c[0] >= '0' && c[0] <= '9' {
+			l, err := r.ReadString(' ')
+			if err != nil {
+				return err
+			}
+			n, err := strconv.Atoi(strings.TrimSuffix(l, " "))
+			if err != nil || n <= 0 || n > maxSize {
+				return fmt.Errorf("invalid message length %q", l)
+			}
+			b := make([]byte, n)
+			_, err = io.ReadFull(r, b)
+			if err != nil {
+				return err
+			}
+			fn(b)
+			continue
+		}
+		b, err := r.ReadBytes('\n')
+		if len(b) > maxSize {
+			return fmt.Errorf("message exceeds %d bytes", maxSize)
+		}
+		if len(strings.TrimSpace(string(b))) > 0 {
+			fn(b)
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+func (s *SyslogInput) handle(ctx context.Context, addr net.Addr, b []byte) {
+	inputs.MessageReceived(s.name)
+	ev, err := s.event(addr, b, time.Now())
+	if err != nil {
+		inputs.MessageError(s.name, inputs.ErrorReasonDecode)
+		s.logger.Printf("failed to parse message from %s: %v", addr, err)
+		return
+	}
+	inputs.MessageDecoded(s.name)
+	evs := []*formatters.EventMsg{ev}
+	for _, p := range s.evps {
+		evs = p.Apply(evs...)
+	}
+	for _, o := range s.fanOut.Outputs() {
+		for _, ev := range evs {
+			o.WriteEvent(ctx, ev)
+		}
+	}
+}
+
+// event parses message b into an event.
+func (s *SyslogInput) event(addr net.Addr, b []byte, now time.Time) (*formatters.EventMsg, error) {
+	m, err := parse(b, now, s.loc)
+	if err != nil {
+		return nil, err
+	}
+	ts := m.timestamp
+	if ts.IsZero() {
+		ts = now
+	}
+	ev := &formatters.EventMsg{
+		Name:      eventName,
+		Timestamp: ts.UnixNano(),
+		Tags: map[string]string{
+			"source":   m.hostname,
+			"facility": facilityName(m.facility),
+			"severity": severityName(m.severity),
+		},
+		Values: map[string]interface{}{
+			"message": m.msg,
+		},
+	}
+	if sender := senderIP(addr); sender != "" {
+		ev.Tags["sender"] = sender
+		if m.hostname == "" {
+			ev.Tags["source"] = sender
+		}
+	}
+	for k, v := range map[string]string{
+		"app_name": m.appName,
+		"proc_id":  m.procID,
+		"msg_id":   m.msgID,
+	} {
+		if v != "" {
+			ev.Tags[k] = v
+		}
+	}
+	for id, params := range m.structuredData {
+		for k, v := range params {
+			ev.Tags[id+"_"+k] = v
+		}
+	}
+	for _, e := range s.extractors {
+		fields, ok := e.extract(m.msg)
+		if !ok {
+			continue
+		}
+		for k, v := range fields {
+			if _, ok := s.tagNames[k]; ok {
+				ev.Tags[k] = fmt.Sprint(v)
+				continue
+			}
+			ev.Values[k] = v
+		}
+		break
+	}
+	return ev, nil
+}
+
+// Close //
+func (s *SyslogInput) Close() error {
+	if s.cfn != nil {
+		s.cfn()
+	}
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	return nil
+}
+
+// SetLogger //
+func (s *SyslogInput) SetLogger(logger *log.Logger) {
+	if logger != nil && s.logger != nil {
+		s.logger.SetOutput(logger.Writer())
+		s.logger.SetFlags(logger.Flags())
+	}
+}
+
+// SetOutputs //
+func (s *SyslogInput) SetOutputs(outs map[string]outputs.Output) {
+	if len(s.Cfg.Outputs) == 0 {
+		for _, o := range outs {
+			s.outputs = append(s.outputs, o)
+		}
+		return
+	}
+	for _, name := range s.Cfg.Outputs {
+		if o, ok := outs[name]; ok {
+			s.outputs = append(s.outputs, o)
+		}
+	}
+}
+
+func (s *SyslogInput) SetName(name string) {
+	sb := strings.Builder{}
+	if name != "" {
+		sb.WriteString(name)
+		sb.WriteString("-")
+	}
+	sb.WriteString(s.Cfg.Name)
+	sb.WriteString("-syslog")
+	s.Cfg.Name = sb.String()
+}
+
+func (s *SyslogInput) SetEventProcessors(ps map[string]map[string]interface{}, logger *log.Logger, tcs map[string]*types.TargetConfig, acts map[string]map[string]interface{}) error {
+	var err error
+	s.evps, err = formatters.MakeEventProcessors(
+		logger,
+		s.Cfg.EventProcessors,
+		ps,
+		tcs,
+		acts,
+	)
+	return err
+}
+
+func (s *SyslogInput) setDefaults() error {
+	if s.Cfg.Name == "" {
+		s.Cfg.Name = "gnmic-" + uuid.New().String()
+	}
+	if s.Cfg.Address == "" {
+		s.Cfg.Address = defaultAddress
+	}
+	if s.Cfg.Protocol == "" {
+		s.Cfg.Protocol = defaultProtocol
+	}
+	if s.Cfg.Protocol != "udp" && s.Cfg.Protocol != "tcp" {
+		return fmt.Errorf("unknown protocol %q, must be one of udp or tcp", s.Cfg.Protocol)
+	}
+	if s.Cfg.MaxMessageSize <= 0 {
+		s.Cfg.MaxMessageSize = defaultMaxMessageSize
+	}
+	return nil
+}
+
+func senderIP(addr net.Addr) string {
+	switch addr := addr.(type) {
+	case *net.UDPAddr:
+		return addr.IP.String()
+	case *net.TCPAddr:
+		return addr.IP.String()
+	}
+	return ""
+}
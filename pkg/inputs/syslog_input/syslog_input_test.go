@@ -0,0 +1,187 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package syslog_input
+
+import (
+	"bufio"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRFC5424(t *testing.T) {
+	b := []byte(`<165>1 2024-03-10T22:14:15.003Z router1 bgpd 1234 ID47 [origin ip="10.0.0.1" x="a \"b\" \]"][meta seq="7"] BGP neighbor 10.0.0.2 Down` + "\n")
+	m, err := parse(b, time.Now(), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &message{
+		facility:  20,
+		severity:  5,
+		timestamp: time.Date(2024, 3, 10, 22, 14, 15, 3000000, time.UTC),
+		hostname:  "router1",
+		appName:   "bgpd",
+		procID:    "1234",
+		msgID:     "ID47",
+		structuredData: map[string]map[string]string{
+			"origin": {"ip": "10.0.0.1", "x": `a "b" ]`},
+			"meta":   {"seq": "7"},
+		},
+		msg: "BGP neighbor 10.0.0.2 Down",
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("unexpected message:\n got: %+v\nwant: %+v", m, want)
+	}
+	m, err = parse([]byte("<14>1 - - - - - -"), time.Now(), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.hostname != "" || m.msg != "" || !m.timestamp.IsZero() {
+		t.Errorf("unexpected message: %+v", m)
+	}
+}
+
+func TestParseRFC3164(t *testing.T) {
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		in   string
+		want message
+	}{
+		"hostname_and_tag": {
+			in: "<34>Oct 11 22:14:15 mymachine su[42]: 'su root' failed",
+			want: message{
+				facility:  4,
+				severity:  2,
+				timestamp: time.Date(2023, 10, 11, 22, 14, 15, 0, time.UTC),
+				hostname:  "mymachine",
+				appName:   "su",
+				procID:    "42",
+				msg:       "'su root' failed",
+			},
+		},
+		"no_hostname": {
+			in: "<187>Jan  1 10:00:00 %LINK-3-UPDOWN: Interface Gi0/1, changed state to down",
+			want: message{
+				facility:  23,
+				severity:  3,
+				timestamp: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+				appName:   "%LINK-3-UPDOWN",
+				msg:       "Interface Gi0/1, changed state to down",
+			},
+		},
+		"no_timestamp": {
+			in: "<13>host1 plain message",
+			want: message{
+				facility: 1,
+				severity: 5,
+				hostname: "host1",
+				msg:      "plain message",
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			m, err := parse([]byte(tt.in), now, time.UTC)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*m, tt.want) {
+				t.Errorf("unexpected message:\n got: %+v\nwant: %+v", *m, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, in := range []string{"no priority", "<>1 -", "<999>1 -", "<14>1 notatime h a p m -", "<14>1 - h a p m [id x=\"y]"} {
+		if _, err := parse([]byte(in), time.Now(), time.UTC); err == nil {
+			t.Errorf("%q: expected an error", in)
+		}
+	}
+}
+
+func TestEventExtraction(t *testing.T) {
+	s := &SyslogInput{Cfg: &Config{
+		Patterns: []string{
+			`^BGP neighbor %{IP:peer-address} (?P<state>\w+)`,
+			`^Interface %{INTERFACE:interface}, errors %{INT:errors:int}, load %{NUMBER:load:float}`,
+		},
+		TagNames: []string{"peer-address", "interface"},
+		Timezone: "UTC",
+	}}
+	if err := s.init(); err != nil {
+		t.Fatal(err)
+	}
+	addr := &net.UDPAddr{IP: net.ParseIP("192.0.2.1")}
+	now := time.Unix(100, 0)
+	ev, err := s.event(addr, []byte("<165>1 - - bgpd - - - BGP neighbor 2001:db8::1 Down"), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantTags := map[string]string{
+		"source":       "192.0.2.1",
+		"sender":       "192.0.2.1",
+		"facility":     "local4",
+		"severity":     "notice",
+		"app_name":     "bgpd",
+		"peer-address": "2001:db8::1",
+	}
+	if !reflect.DeepEqual(ev.Tags, wantTags) {
+		t.Errorf("unexpected tags:\n got: %v\nwant: %v", ev.Tags, wantTags)
+	}
+	wantValues := map[string]interface{}{
+		"message": "BGP neighbor 2001:db8::1 Down",
+		"state":   "Down",
+	}
+	if !reflect.DeepEqual(ev.Values, wantValues) {
+		t.Errorf("unexpected values:\n got: %v\nwant: %v", ev.Values, wantValues)
+	}
+	if ev.Timestamp != now.UnixNano() {
+		t.Errorf("unexpected timestamp %d", ev.Timestamp)
+	}
+	ev, err = s.event(addr, []byte("<14>Mar  1 10:00:00 r2 ifmgr: Interface ethernet-1/1, errors 12, load 0.75"), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.Tags["interface"] != "ethernet-1/1" || ev.Tags["source"] != "r2" {
+		t.Errorf("unexpected tags: %v", ev.Tags)
+	}
+	if ev.Values["errors"] != int64(12) || ev.Values["load"] != 0.75 {
+		t.Errorf("unexpected values: %v", ev.Values)
+	}
+}
+
+func TestExtractorErrors(t *testing.T) {
+	for _, p := range []string{"%{UNKNOWN:x}", "(", "%{LOOP}"} {
+		if _, err := newExtractor(p, map[string]string{"LOOP": "%{LOOP}"}); err == nil {
+			t.Errorf("%q: expected an error", p)
+		}
+	}
+}
+
+func TestReadFrames(t *testing.T) {
+	in := "11 <14>1 - a b5 <14>c\n<14>line one\n\n<14>last"
+	var got []string
+	err := readFrames(bufio.NewReader(strings.NewReader(in)), 1024, func(b []byte) {
+		got = append(got, string(b))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"<14>1 - a b", "<14>c", "<14>line one\n", "<14>last"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected frames: %q", got)
+	}
+	err = readFrames(bufio.NewReader(strings.NewReader("2000 <14>")), 1024, func([]byte) {})
+	if err == nil {
+		t.Error("expected an error for an oversized frame")
+	}
+}
@@ -404,6 +404,8 @@ SRV:
 		}
 		tc.Address = net.JoinHostPort(tc.Address, strconv.Itoa(se.Service.Port))
 		tc.Name = se.Service.ID
+		// service meta are added to the target vars
+		loaders.AddVars(tc, se.Service.Meta)
 		return tc, nil
 	}
 
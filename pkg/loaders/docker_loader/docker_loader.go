@@ -337,6 +337,8 @@ func (d *dockerLoader) getTargets(ctx context.Context) (map[string]*types.Target
 					if len(cont.Names) > 0 {
 						tc.Name = strings.TrimLeft(cont.Names[0], "/")
 					}
+					// container labels are added to the target vars
+					loaders.AddVars(tc, cont.Labels)
 					// discover target address and port
 					switch strings.ToLower(cont.HostConfig.NetworkMode) {
 					case "host":
@@ -17,6 +17,9 @@ import (
 	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"text/template"
 	"time"
@@ -43,14 +46,15 @@ func init() {
 			cfg:         &cfg{},
 			m:           new(sync.RWMutex),
 			lastTargets: make(map[string]*types.TargetConfig),
+			remote:      make(map[string]*remoteFile),
 			logger:      log.New(io.Discard, loggingPrefix, utils.DefaultLoggingFlags),
 		}
 	})
 }
 
 // fileLoader implements the loaders.Loader interface.
-// it reads a configured file (local, ftp, sftp, http) periodically,
-// expects the file to contain a dictionary of types.TargetConfig.
+// it reads the configured files (local, ftp, sftp, http, s3) periodically,
+// expects each file to contain a dictionary of types.TargetConfig.
 // It then adds new targets to gNMIc's targets and deletes the removed ones.
 type fileLoader struct {
 	cfg            *cfg
@@ -58,6 +62,8 @@ type fileLoader struct {
 	lastTargets    map[string]*types.TargetConfig
 	targetConfigFn func(*types.TargetConfig) error
 	logger         *log.Logger
+	// last read bytes and validators of the remote files
+	remote map[string]*remoteFile
 	//
 	tpl           *template.Template
 	vars          map[string]interface{}
@@ -67,10 +73,21 @@ type fileLoader struct {
 	numActions    int
 }
 
+// remoteFile is the last read content of a remote file
+// and the validators used to check if it changed.
+type remoteFile struct {
+	b          []byte
+	validators *gfile.Validators
+}
+
 type cfg struct {
 	// path the the file, if remote,
 	// must include the proper protocol prefix ftp://, sftp://, http://
+	// a local path can be a directory or a glob pattern.
+	// s3://bucket/key paths are also supported.
 	Path string `json:"path,omitempty" mapstructure:"path,omitempty"`
+	// additional paths, the targets read from all the paths are merged.
+	Paths []string `json:"paths,omitempty" mapstructure:"paths,omitempty"`
 	// the interval at which the file will be re read to load new targets
 	// or delete removed ones.
 	Interval time.Duration `json:"interval,omitempty" mapstructure:"interval,omitempty"`
@@ -103,7 +120,7 @@ func (f *fileLoader) Init(ctx context.Context, cfg map[string]interface{}, logge
 	for _, o := range opts {
 		o(f)
 	}
-	if f.cfg.Path == "" {
+	if f.cfg.Path == "" && len(f.cfg.Paths) == 0 {
 		return errors.New("missing file path")
 	}
 	if f.cfg.Interval <= 0 {
@@ -215,26 +232,153 @@ func (f *fileLoader) update(ctx context.Context, opChan chan *loaders.TargetOper
 func (f *fileLoader) getTargets(ctx context.Context) (map[string]*types.TargetConfig, error) {
 	fileLoaderFileReadTotal.WithLabelValues(loaderType).Add(1)
 	start := time.Now()
-	// read file bytes based on the path prefix
+	paths, err := f.expandPaths()
+	if err != nil {
+		fileLoaderFailedFileRead.WithLabelValues(loaderType, fmt.Sprintf("%v", err)).Add(1)
+		return nil, err
+	}
+	// read files bytes based on the path prefix
 	ctx, cancel := context.WithTimeout(ctx, f.cfg.Interval/2)
 	defer cancel()
-	b, err := gfile.ReadFile(ctx, f.cfg.Path)
+	result := make(map[string]*types.TargetConfig)
+	origin := make(map[string]string)
+	for _, p := range paths {
+		b, err := f.readFile(ctx, p)
+		if err != nil {
+			fileLoaderFailedFileRead.WithLabelValues(loaderType, fmt.Sprintf("%v", err)).Add(1)
+			return nil, err
+		}
+		tcs, err := f.parseTargets(b)
+		if err != nil {
+			fileLoaderFailedFileRead.WithLabelValues(loaderType, fmt.Sprintf("%v", err)).Add(1)
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		for n, t := range tcs {
+			if op, ok := origin[n]; ok {
+				f.logger.Printf("target %q from %q already defined in %q, ignoring it", n, p, op)
+				continue
+			}
+			origin[n] = p
+			result[n] = t
+		}
+	}
 	fileLoaderFileReadDuration.WithLabelValues(loaderType).Set(float64(time.Since(start).Nanoseconds()))
+	if f.cfg.Debug {
+		f.logger.Printf("result: %s", result)
+	}
+	return result, nil
+}
+
+// expandPaths returns the list of files to read.
+// Local directories are expanded to the YAML and JSON files they contain
+// and local glob patterns to the files they match.
+// An error is returned if a directory or a pattern matches no file,
+// to avoid deleting the targets defined in it.
+func (f *fileLoader) expandPaths() ([]string, error) {
+	paths := make([]string, 0, 1+len(f.cfg.Paths))
+	if f.cfg.Path != "" {
+		paths = append(paths, f.cfg.Path)
+	}
+	paths = append(paths, f.cfg.Paths...)
+	result := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if gfile.IsRemote(p) {
+			result = append(result, p)
+			continue
+		}
+		var matches []string
+		var err error
+		switch {
+		case strings.ContainsAny(p, "*?["):
+			matches, err = filepath.Glob(p)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			fi, err := os.Stat(p)
+			if err != nil {
+				return nil, err
+			}
+			if !fi.IsDir() {
+				result = append(result, p)
+				continue
+			}
+			matches, err = dirFiles(p)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no targets files found in %q", p)
+		}
+		sort.Strings(matches)
+		result = append(result, matches...)
+	}
+	return result, nil
+}
+
+// dirFiles returns the YAML and JSON files in directory dir.
+func dirFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
 	if err != nil {
-		fileLoaderFailedFileRead.WithLabelValues(loaderType, fmt.Sprintf("%v", err)).Add(1)
 		return nil, err
 	}
+	files := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		switch filepath.Ext(e.Name()) {
+		case ".yaml", ".yml", ".json":
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+	return files, nil
+}
+
+// readFile reads the file in path p.
+// Remote files are read only if they changed since the last read,
+// otherwise the last read bytes are returned.
+func (f *fileLoader) readFile(ctx context.Context, p string) ([]byte, error) {
+	if !gfile.IsRemote(p) {
+		return gfile.ReadFile(ctx, p)
+	}
+	f.m.RLock()
+	rf := f.remote[p]
+	f.m.RUnlock()
+	var validators *gfile.Validators
+	if rf != nil {
+		validators = rf.validators
+	}
+	b, v, err := gfile.ReadFileIfModified(ctx, p, validators)
+	if errors.Is(err, gfile.ErrNotModified) && rf != nil {
+		if f.cfg.Debug {
+			f.logger.Printf("file %q not modified", p)
+		}
+		return rf.b, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	f.m.Lock()
+	f.remote[p] = &remoteFile{b: b, validators: v}
+	f.m.Unlock()
+	return b, nil
+}
+
+// parseTargets applies the template if any to the file bytes b
+// and unmarshals the result into a map of target configs.
+func (f *fileLoader) parseTargets(b []byte) (map[string]*types.TargetConfig, error) {
+	var err error
 	if f.tpl != nil {
 		var input interface{}
 		err = json.Unmarshal(b, input)
 		if err != nil {
-			fileLoaderFailedFileRead.WithLabelValues(loaderType, fmt.Sprintf("%v", err)).Add(1)
 			return nil, err
 		}
 		buf := new(bytes.Buffer)
 		err = f.tpl.Execute(buf, input)
 		if err != nil {
-			fileLoaderFailedFileRead.WithLabelValues(loaderType, fmt.Sprintf("%v", err)).Add(1)
 			return nil, err
 		}
 		b = buf.Bytes()
@@ -243,7 +387,6 @@ func (f *fileLoader) getTargets(ctx context.Context) (map[string]*types.TargetCo
 	// unmarshal the bytes into a map of targetConfigs
 	err = yaml.Unmarshal(b, result)
 	if err != nil {
-		fileLoaderFailedFileRead.WithLabelValues(loaderType, fmt.Sprintf("%v", err)).Add(1)
 		return nil, err
 	}
 	// properly initialize address and name if not set
@@ -262,9 +405,6 @@ func (f *fileLoader) getTargets(ctx context.Context) (map[string]*types.TargetCo
 			t.Address = n
 		}
 	}
-	if f.cfg.Debug {
-		f.logger.Printf("result: %s", result)
-	}
 	return result, nil
 }
 
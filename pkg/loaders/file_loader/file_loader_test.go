@@ -0,0 +1,133 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package file_loader
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+func newTestLoader(t *testing.T, c map[string]interface{}) *fileLoader {
+	t.Helper()
+	f := &fileLoader{
+		cfg:         &cfg{},
+		m:           new(sync.RWMutex),
+		lastTargets: make(map[string]*types.TargetConfig),
+		remote:      make(map[string]*remoteFile),
+		logger:      log.New(io.Discard, loggingPrefix, 0),
+	}
+	if err := f.Init(context.TODO(), c, nil); err != nil {
+		t.Fatalf("failed to init loader: %v", err)
+	}
+	return f
+}
+
+func targetNames[T any](m map[string]T) []string {
+	names := make([]string, 0, len(m))
+	for n := range m {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func writeFile(t *testing.T, p, content string) {
+	t.Helper()
+	if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetTargetsDirAndGlob(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.yaml"), "r1:\nr2:\n  address: 10.0.0.2:57400\n")
+	writeFile(t, filepath.Join(dir, "b.yml"), "r2:\n  address: 10.0.0.22:57400\nr3:\n")
+	writeFile(t, filepath.Join(dir, "notes.txt"), "not targets")
+
+	for name, path := range map[string]string{
+		"dir":  dir,
+		"glob": filepath.Join(dir, "*.y*ml"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			f := newTestLoader(t, map[string]interface{}{"path": path})
+			tcs, err := f.getTargets(context.TODO())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := targetNames(tcs)
+			want := []string{"r1", "r2", "r3"}
+			if len(got) != len(want) {
+				t.Fatalf("got targets %v, want %v", got, want)
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Fatalf("got targets %v, want %v", got, want)
+				}
+			}
+			// the first file wins
+			if tcs["r2"].Address != "10.0.0.2:57400" {
+				t.Errorf("got r2 address %q, want %q", tcs["r2"].Address, "10.0.0.2:57400")
+			}
+		})
+	}
+}
+
+func TestGetTargetsNoMatch(t *testing.T) {
+	dir := t.TempDir()
+	f := newTestLoader(t, map[string]interface{}{
+		"paths": []string{filepath.Join(dir, "*.yaml")},
+	})
+	_, err := f.getTargets(context.TODO())
+	if err == nil {
+		t.Fatal("expected an error for a pattern without matching files")
+	}
+}
+
+func TestGetTargetsRemoteETag(t *testing.T) {
+	var served, notModified int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		served++
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("r1:\nr2:\n"))
+	}))
+	defer srv.Close()
+
+	f := newTestLoader(t, map[string]interface{}{
+		"path":     srv.URL + "/targets.yaml",
+		"interval": time.Second,
+	})
+	for i := 0; i < 3; i++ {
+		tcs, err := f.getTargets(context.TODO())
+		if err != nil {
+			t.Fatalf("read %d: unexpected error: %v", i, err)
+		}
+		if len(tcs) != 2 {
+			t.Fatalf("read %d: got %d targets, want 2", i, len(tcs))
+		}
+	}
+	if served != 1 || notModified != 2 {
+		t.Errorf("got %d full and %d not modified responses, want 1 and 2", served, notModified)
+	}
+}
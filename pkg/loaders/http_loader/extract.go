@@ -0,0 +1,165 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package http_loader
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+
+	"github.com/itchyny/gojq"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+const defaultMaxPages = 100
+
+type paginationConfig struct {
+	// a jq expression evaluated on each response body,
+	// it should return the URL of the next page or null on the last page.
+	NextPage string `json:"next-page,omitempty" mapstructure:"next-page,omitempty"`
+	// if true, the next page URL is read from the response Link header (rel="next").
+	LinkHeader bool `json:"link-header,omitempty" mapstructure:"link-header,omitempty"`
+	// maximum number of pages to query.
+	MaxPages int `json:"max-pages,omitempty" mapstructure:"max-pages,omitempty"`
+}
+
+var linkNextRegex = regexp.MustCompile(`<([^>]*)>[^,]*;\s*rel="?next"?`)
+
+func compileJQ(expr string) (*gojq.Code, error) {
+	q, err := gojq.Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	return gojq.Compile(q)
+}
+
+// runJQ runs the jq code on the JSON document b and returns its outputs.
+func runJQ(code *gojq.Code, b []byte) ([]interface{}, error) {
+	var input interface{}
+	err := json.Unmarshal(b, &input)
+	if err != nil {
+		return nil, err
+	}
+	var outputs []interface{}
+	iter := code.Run(input)
+	for {
+		v, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, ok := v.(error); ok {
+			return nil, err
+		}
+		outputs = append(outputs, v)
+	}
+	return outputs, nil
+}
+
+// extractTargets runs the configured jq expression on the response body b.
+// Each output must be either an object of target configs indexed by name
+// or an array of target configs.
+func (h *httpLoader) extractTargets(b []byte) (map[string]*types.TargetConfig, error) {
+	outputs, err := runJQ(h.jq, b)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]*types.TargetConfig)
+	for _, o := range outputs {
+		switch o := o.(type) {
+		case nil:
+		case map[string]interface{}:
+			tcs := make(map[string]*types.TargetConfig)
+			if err := convert(o, &tcs); err != nil {
+				return nil, err
+			}
+			for n, tc := range tcs {
+				result[n] = tc
+			}
+		case []interface{}:
+			tcs := make([]*types.TargetConfig, 0, len(o))
+			if err := convert(o, &tcs); err != nil {
+				return nil, err
+			}
+			for _, tc := range tcs {
+				if tc == nil {
+					continue
+				}
+				n := tc.Name
+				if n == "" {
+					n = tc.Address
+				}
+				if n == "" {
+					return nil, fmt.Errorf("jq output target has no name nor address")
+				}
+				result[n] = tc
+			}
+		default:
+			return nil, fmt.Errorf("unexpected jq output type %T, expecting an object or an array", o)
+		}
+	}
+	return result, nil
+}
+
+func convert(in, out interface{}) error {
+	b, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
+
+// nextPageURL returns the URL of the page following the one read from u,
+// or an empty string if there is no next page.
+func (h *httpLoader) nextPageURL(u string, header http.Header, b []byte) (string, error) {
+	if h.cfg.Pagination == nil {
+		return "", nil
+	}
+	var next string
+	switch {
+	case h.nextPage != nil:
+		outputs, err := runJQ(h.nextPage, b)
+		if err != nil {
+			return "", err
+		}
+		if len(outputs) == 0 {
+			return "", nil
+		}
+		switch o := outputs[0].(type) {
+		case nil:
+			return "", nil
+		case string:
+			next = o
+		default:
+			return "", fmt.Errorf("unexpected next page jq output type %T, expecting a string", o)
+		}
+	case h.cfg.Pagination.LinkHeader:
+		for _, l := range header.Values("Link") {
+			if m := linkNextRegex.FindStringSubmatch(l); m != nil {
+				next = m[1]
+				break
+			}
+		}
+	}
+	if next == "" {
+		return "", nil
+	}
+	// resolve relative links
+	base, err := url.Parse(u)
+	if err != nil {
+		return "", err
+	}
+	ref, err := url.Parse(next)
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(ref).String(), nil
+}
@@ -23,6 +23,7 @@ import (
 	"gopkg.in/yaml.v2"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/itchyny/gojq"
 
 	"github.com/openconfig/gnmic/pkg/actions"
 	"github.com/openconfig/gnmic/pkg/api/types"
@@ -58,6 +59,8 @@ type httpLoader struct {
 	logger         *log.Logger
 	//
 	tpl           *template.Template
+	jq            *gojq.Code
+	nextPage      *gojq.Code
 	vars          map[string]interface{}
 	actionsConfig map[string]map[string]interface{}
 	addActions    []actions.Action
@@ -91,6 +94,12 @@ type cfg struct {
 	// a Go text template that can be used to transform the targets format
 	// read from the remote http server to match gNMIc's expected format.
 	TemplateFile string `json:"template-file,omitempty" mapstructure:"template-file,omitempty"`
+	// a jq expression that extracts the targets from the response body,
+	// it should return an object of targets indexed by name or an array of targets.
+	JQ string `json:"jq,omitempty" mapstructure:"jq,omitempty"`
+	// pagination config, if set the next pages are queried
+	// and their targets are merged.
+	Pagination *paginationConfig `json:"pagination,omitempty" mapstructure:"pagination,omitempty"`
 	// time to wait before the first http query
 	StartDelay time.Duration `json:"start-delay,omitempty" mapstructure:"start-delay,omitempty"`
 	// if true, registers httpLoader prometheus metrics with the provided
@@ -137,6 +146,18 @@ func (h *httpLoader) Init(ctx context.Context, cfg map[string]interface{}, logge
 			return err
 		}
 	}
+	if h.cfg.JQ != "" {
+		h.jq, err = compileJQ(h.cfg.JQ)
+		if err != nil {
+			return fmt.Errorf("failed to compile jq expression: %v", err)
+		}
+	}
+	if h.cfg.Pagination != nil && h.cfg.Pagination.NextPage != "" {
+		h.nextPage, err = compileJQ(h.cfg.Pagination.NextPage)
+		if err != nil {
+			return fmt.Errorf("failed to compile pagination next-page jq expression: %v", err)
+		}
+	}
 	err = h.readVars(ctx)
 	if err != nil {
 		return err
@@ -224,6 +245,12 @@ func (h *httpLoader) setDefaults() error {
 	if h.cfg.Timeout <= 0 {
 		h.cfg.Timeout = defaultTimeout
 	}
+	if h.cfg.JQ != "" && (h.cfg.Template != "" || h.cfg.TemplateFile != "") {
+		return errors.New("jq and template are mutually exclusive")
+	}
+	if h.cfg.Pagination != nil && h.cfg.Pagination.MaxPages <= 0 {
+		h.cfg.Pagination.MaxPages = defaultMaxPages
+	}
 	return nil
 }
 
@@ -249,40 +276,84 @@ func (h *httpLoader) getTargets() (map[string]*types.TargetConfig, error) {
 	if h.cfg.AuthScheme != "" {
 		c.SetAuthScheme(h.cfg.AuthScheme)
 	}
-	start := time.Now()
-	httpLoaderGetRequestsTotal.WithLabelValues(loaderType).Add(1)
-	rsp, err := c.R().SetHeader("Accept", "application/json").Get(h.cfg.URL)
-	if err != nil {
-		return nil, err
-	}
-	httpLoaderGetRequestDuration.WithLabelValues(loaderType).Set(float64(time.Since(start).Nanoseconds()))
-	if rsp.StatusCode() != 200 {
-		httpLoaderFailedGetRequests.WithLabelValues(loaderType, rsp.Status())
-		return nil, fmt.Errorf("failed request, code=%d", rsp.StatusCode())
-	}
-	b := rsp.Body()
-	if h.tpl != nil {
-		var input interface{}
-		err = json.Unmarshal(b, &input)
+	result := make(map[string]*types.TargetConfig)
+	visited := make(map[string]struct{})
+	u := h.cfg.URL
+	for page := 1; u != ""; page++ {
+		if h.cfg.Pagination != nil && page > h.cfg.Pagination.MaxPages {
+			return nil, fmt.Errorf("reached the maximum number of pages %d", h.cfg.Pagination.MaxPages)
+		}
+		visited[u] = struct{}{}
+		start := time.Now()
+		httpLoaderGetRequestsTotal.WithLabelValues(loaderType).Add(1)
+		rsp, err := c.R().SetHeader("Accept", "application/json").Get(u)
+		if err != nil {
+			return nil, err
+		}
+		httpLoaderGetRequestDuration.WithLabelValues(loaderType).Set(float64(time.Since(start).Nanoseconds()))
+		if rsp.StatusCode() != 200 {
+			httpLoaderFailedGetRequests.WithLabelValues(loaderType, rsp.Status())
+			return nil, fmt.Errorf("failed request, code=%d", rsp.StatusCode())
+		}
+		tcs, err := h.parseTargets(rsp.Body())
 		if err != nil {
 			httpLoaderFailedGetRequests.WithLabelValues(loaderType, fmt.Sprintf("%v", err)).Add(1)
 			return nil, err
 		}
-		buf := new(bytes.Buffer)
-		err = h.tpl.Execute(buf, input)
+		for n, t := range tcs {
+			if _, ok := result[n]; ok {
+				h.logger.Printf("target %q from page %d already defined, ignoring it", n, page)
+				continue
+			}
+			result[n] = t
+		}
+		u, err = h.nextPageURL(u, rsp.Header(), rsp.Body())
 		if err != nil {
 			httpLoaderFailedGetRequests.WithLabelValues(loaderType, fmt.Sprintf("%v", err)).Add(1)
 			return nil, err
 		}
-		b = buf.Bytes()
+		if _, ok := visited[u]; ok {
+			h.logger.Printf("next page %q already queried, stopping pagination", u)
+			break
+		}
 	}
+	if h.cfg.Debug {
+		h.logger.Printf("result: %s", result)
+	}
+	return result, nil
+}
 
-	result := make(map[string]*types.TargetConfig)
-	// unmarshal the bytes into a map of targetConfigs
-	err = json.Unmarshal(b, &result)
-	if err != nil {
-		httpLoaderFailedGetRequests.WithLabelValues(loaderType, fmt.Sprintf("%v", err)).Add(1)
-		return nil, err
+// parseTargets transforms the response body b using the configured jq expression
+// or template and unmarshals the result into a map of target configs.
+func (h *httpLoader) parseTargets(b []byte) (map[string]*types.TargetConfig, error) {
+	var result map[string]*types.TargetConfig
+	var err error
+	switch {
+	case h.jq != nil:
+		result, err = h.extractTargets(b)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		if h.tpl != nil {
+			var input interface{}
+			err = json.Unmarshal(b, &input)
+			if err != nil {
+				return nil, err
+			}
+			buf := new(bytes.Buffer)
+			err = h.tpl.Execute(buf, input)
+			if err != nil {
+				return nil, err
+			}
+			b = buf.Bytes()
+		}
+		result = make(map[string]*types.TargetConfig)
+		// unmarshal the bytes into a map of targetConfigs
+		err = json.Unmarshal(b, &result)
+		if err != nil {
+			return nil, err
+		}
 	}
 	// properly initialize address and name if not set
 	for n, t := range result {
@@ -300,9 +371,6 @@ func (h *httpLoader) getTargets() (map[string]*types.TargetConfig, error) {
 			t.Address = n
 		}
 	}
-	if h.cfg.Debug {
-		h.logger.Printf("result: %s", result)
-	}
 	return result, nil
 }
 
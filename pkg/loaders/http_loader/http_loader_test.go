@@ -0,0 +1,121 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package http_loader
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+)
+
+func newTestLoader(t *testing.T, c map[string]interface{}) *httpLoader {
+	t.Helper()
+	h := &httpLoader{
+		cfg:         &cfg{},
+		m:           new(sync.RWMutex),
+		lastTargets: make(map[string]*types.TargetConfig),
+		logger:      log.New(io.Discard, loggingPrefix, 0),
+	}
+	if err := h.Init(context.TODO(), c, nil); err != nil {
+		t.Fatalf("failed to init loader: %v", err)
+	}
+	return h
+}
+
+var devicesPages = map[string]string{
+	"1": `{"items":[{"hostname":"r1","ip":"10.0.0.1"},{"hostname":"r2","ip":"10.0.0.2"}],"next":"/devices?page=2"}`,
+	"2": `{"items":[{"hostname":"r3","ip":"10.0.0.3"}],"next":null}`,
+}
+
+func TestGetTargetsJQPagination(t *testing.T) {
+	tests := []struct {
+		name       string
+		pagination map[string]interface{}
+		handler    http.HandlerFunc
+	}{
+		{
+			name:       "next-page",
+			pagination: map[string]interface{}{"next-page": ".next"},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				p := r.URL.Query().Get("page")
+				if p == "" {
+					p = "1"
+				}
+				fmt.Fprint(w, devicesPages[p])
+			},
+		},
+		{
+			name:       "link-header",
+			pagination: map[string]interface{}{"link-header": true},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				p := r.URL.Query().Get("page")
+				if p == "" {
+					p = "1"
+					w.Header().Set("Link", `</devices?page=1>; rel="first", </devices?page=2>; rel="next"`)
+				}
+				fmt.Fprint(w, devicesPages[p])
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.handler)
+			defer srv.Close()
+			h := newTestLoader(t, map[string]interface{}{
+				"url":        srv.URL + "/devices",
+				"jq":         `[.items[] | {name: .hostname, address: (.ip + ":57400")}]`,
+				"pagination": tt.pagination,
+			})
+			tcs, err := h.getTargets()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(tcs) != 3 {
+				t.Fatalf("got %d targets, want 3: %v", len(tcs), tcs)
+			}
+			for i := 1; i <= 3; i++ {
+				n := fmt.Sprintf("r%d", i)
+				tc, ok := tcs[n]
+				if !ok {
+					t.Fatalf("missing target %q", n)
+				}
+				want := fmt.Sprintf("10.0.0.%d:57400", i)
+				if tc.Address != want {
+					t.Errorf("target %q: got address %q, want %q", n, tc.Address, want)
+				}
+			}
+		})
+	}
+}
+
+func TestGetTargetsMaxPages(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"items":{},"next":"/devices?page=%s0"}`, r.URL.Query().Get("page"))
+	}))
+	defer srv.Close()
+	h := newTestLoader(t, map[string]interface{}{
+		"url": srv.URL + "/devices",
+		"jq":  ".items",
+		"pagination": map[string]interface{}{
+			"next-page": ".next",
+			"max-pages": 3,
+		},
+	})
+	_, err := h.getTargets()
+	if err == nil {
+		t.Fatal("expected an error when exceeding max-pages")
+	}
+}
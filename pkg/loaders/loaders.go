@@ -101,3 +101,43 @@ func Diff(m1, m2 map[string]*types.TargetConfig) *TargetOperation {
 	}
 	return result
 }
+
+// Merge adds the operation n, received after o, to o.
+// A target added then deleted is removed from o.Add, it is added to o.Del
+// only if it was not added by o.
+func (o *TargetOperation) Merge(n *TargetOperation) {
+	if o.Add == nil {
+		o.Add = make(map[string]*types.TargetConfig)
+	}
+	for _, name := range n.Del {
+		if _, ok := o.Add[name]; ok {
+			delete(o.Add, name)
+			continue
+		}
+		o.Del = append(o.Del, name)
+	}
+	for name, tc := range n.Add {
+		o.Add[name] = tc
+	}
+}
+
+// Len returns the number of targets added or deleted by the operation.
+func (o *TargetOperation) Len() int {
+	return len(o.Add) + len(o.Del)
+}
+
+// AddVars adds the discovered key/values to the target vars,
+// without overwriting the ones already set from the loader config.
+func AddVars(tc *types.TargetConfig, vars map[string]string) {
+	if len(vars) == 0 {
+		return
+	}
+	if tc.Vars == nil {
+		tc.Vars = make(map[string]string, len(vars))
+	}
+	for k, v := range vars {
+		if _, ok := tc.Vars[k]; !ok {
+			tc.Vars[k] = v
+		}
+	}
+}
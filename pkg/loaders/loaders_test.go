@@ -9,6 +9,7 @@
 package loaders
 
 import (
+	"sort"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -178,3 +179,51 @@ func TestGetInstancesTagsMatches(t *testing.T) {
 		})
 	}
 }
+
+func opNames(op *TargetOperation) ([]string, []string) {
+	adds := make([]string, 0, len(op.Add))
+	for n := range op.Add {
+		adds = append(adds, n)
+	}
+	sort.Strings(adds)
+	return adds, op.Del
+}
+
+func TestTargetOperationMerge(t *testing.T) {
+	op := &TargetOperation{
+		Add: map[string]*types.TargetConfig{
+			"target1": {Name: "target1"},
+		},
+		Del: []string{"target2"},
+	}
+	// target1 added then deleted, target2 deleted then re-added,
+	// target3 deleted
+	op.Merge(&TargetOperation{
+		Add: map[string]*types.TargetConfig{
+			"target2": {Name: "target2"},
+		},
+		Del: []string{"target1", "target3"},
+	})
+	adds, dels := opNames(op)
+	if !cmp.Equal(adds, []string{"target2"}) || !cmp.Equal(dels, []string{"target2", "target3"}) {
+		t.Errorf("unexpected merged operation: add=%v, del=%v", adds, dels)
+	}
+	// target2 deleted again, it is deleted once
+	op.Merge(&TargetOperation{Del: []string{"target2"}})
+	adds, dels = opNames(op)
+	if len(adds) != 0 || !cmp.Equal(dels, []string{"target2", "target3"}) {
+		t.Errorf("unexpected merged operation: add=%v, del=%v", adds, dels)
+	}
+	if op.Len() != 2 {
+		t.Errorf("unexpected operation length %d", op.Len())
+	}
+}
+
+func TestAddVars(t *testing.T) {
+	tc := &types.TargetConfig{Name: "target1", Vars: map[string]string{"site": "paris"}}
+	AddVars(tc, map[string]string{"site": "lyon", "role": "spine"})
+	want := map[string]string{"site": "paris", "role": "spine"}
+	if !cmp.Equal(tc.Vars, want) {
+		t.Errorf("got vars %v, want %v", tc.Vars, want)
+	}
+}
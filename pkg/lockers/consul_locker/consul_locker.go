@@ -264,3 +264,21 @@ func (c *ConsulLocker) String() string {
 	}
 	return string(b)
 }
+
+func (c *ConsulLocker) Get(ctx context.Context, key string) ([]byte, error) {
+	qOpts := &api.QueryOptions{}
+	kv, _, err := c.client.KV().Get(key, qOpts.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if kv == nil {
+		return nil, nil
+	}
+	return kv.Value, nil
+}
+
+func (c *ConsulLocker) Put(ctx context.Context, key string, val []byte) error {
+	wrOpts := &api.WriteOptions{}
+	_, err := c.client.KV().Put(&api.KVPair{Key: key, Value: val}, wrOpts.WithContext(ctx))
+	return err
+}
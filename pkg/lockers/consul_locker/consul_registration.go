@@ -195,3 +195,52 @@ func (c *ConsulLocker) List(ctx context.Context, prefix string) (map[string]stri
 	}
 	return rs, nil
 }
+
+func (c *ConsulLocker) WatchPrefix(ctx context.Context, prefix string, ch chan<- map[string]string, watchTimeout time.Duration) error {
+	if watchTimeout <= 0 {
+		watchTimeout = defaultWatchTimeout
+	}
+	qOpts := &api.QueryOptions{
+		WaitTime: watchTimeout,
+	}
+	// long blocking watch
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		kvs, meta, err := c.client.KV().List(prefix, qOpts.WithContext(ctx))
+		if err != nil {
+			c.logger.Printf("prefix %q watch failed: %v", prefix, err)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(c.Cfg.RetryTimer):
+			}
+			continue
+		}
+		if meta.LastIndex == qOpts.WaitIndex {
+			if c.Cfg.Debug {
+				c.logger.Printf("prefix=%q did not change, lastIndex=%d", prefix, meta.LastIndex)
+			}
+			continue
+		}
+		// reset WaitIndex if the returned index decreases
+		// https://www.consul.io/api-docs/features/blocking#implementation-details
+		if meta.LastIndex < qOpts.WaitIndex {
+			qOpts.WaitIndex = 0
+		} else {
+			qOpts.WaitIndex = meta.LastIndex
+		}
+		rs := make(map[string]string, len(kvs))
+		for _, kv := range kvs {
+			rs[kv.Key] = string(kv.Value)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ch <- rs:
+		}
+	}
+}
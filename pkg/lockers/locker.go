@@ -57,6 +57,23 @@ type Locker interface {
 	List(ctx context.Context, prefix string) (map[string]string, error)
 }
 
+// KV is implemented by the lockers able to store
+// arbitrary values, e.g: generated certificates.
+type KV interface {
+	// Get returns the value of the given key, or nil if it does not exist.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Put sets the value of the given key.
+	Put(ctx context.Context, key string, val []byte) error
+}
+
+// Watcher is implemented by the lockers able to watch
+// the keys under a prefix without periodically listing them.
+type Watcher interface {
+	// WatchPrefix sends the keys under prefix and their values
+	// each time they change, until ctx is done.
+	WatchPrefix(ctx context.Context, prefix string, ch chan<- map[string]string, watchTimeout time.Duration) error
+}
+
 type Initializer func() Locker
 
 var Lockers = map[string]Initializer{}
@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -256,3 +257,15 @@ func (k *redisLocker) genRandValue() (string, error) {
 	}
 	return base64.StdEncoding.EncodeToString(b), nil
 }
+
+func (k *redisLocker) Get(ctx context.Context, key string) ([]byte, error) {
+	b, err := k.client.Get(ctx, key).Bytes()
+	if errors.Is(err, goredislib.Nil) {
+		return nil, nil
+	}
+	return b, err
+}
+
+func (k *redisLocker) Put(ctx context.Context, key string, val []byte) error {
+	return k.client.Set(ctx, key, val, 0).Err()
+}
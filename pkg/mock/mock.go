@@ -0,0 +1,450 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package mock implements a gNMI target serving
+// Capabilities, Get and Subscribe RPCs from fixture files.
+//
+// A fixture file is a YAML or JSON document listing the target models
+// and updates:
+//
+//	target: router1
+//	models:
+//	  - name: openconfig-interfaces
+//	    organization: OpenConfig working group
+//	    version: 3.0.0
+//	updates:
+//	  - path: /interfaces/interface[name=ethernet-1/1]/state/counters/in-octets
+//	    value: 1000
+//	    jitter: 0.1
+//
+// Numeric values are randomly varied by up to +/- jitter (a ratio)
+// each time they are sent.
+package mock
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	gvalue "github.com/openconfig/gnmi/value"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/yaml.v2"
+
+	"github.com/openconfig/gnmic/pkg/api/path"
+	"github.com/openconfig/gnmic/pkg/api/utils"
+)
+
+const (
+	gnmiVersion = "0.10.0"
+
+	defaultSampleInterval = 10 * time.Second
+)
+
+// Fixture is the content of a fixture file.
+type Fixture struct {
+	// target name, if set the fixture is only served
+	// to requests without target or with the same target in their prefix.
+	Target  string    `yaml:"target,omitempty" json:"target,omitempty"`
+	Models  []*Model  `yaml:"models,omitempty" json:"models,omitempty"`
+	Updates []*Update `yaml:"updates,omitempty" json:"updates,omitempty"`
+}
+
+// Model is a model returned in the Capabilities response.
+type Model struct {
+	Name         string `yaml:"name,omitempty" json:"name,omitempty"`
+	Organization string `yaml:"organization,omitempty" json:"organization,omitempty"`
+	Version      string `yaml:"version,omitempty" json:"version,omitempty"`
+}
+
+// Update is a path and its value.
+type Update struct {
+	Path  string      `yaml:"path,omitempty" json:"path,omitempty"`
+	Value interface{} `yaml:"value,omitempty" json:"value,omitempty"`
+	// overrides the global jitter
+	Jitter *float64 `yaml:"jitter,omitempty" json:"jitter,omitempty"`
+
+	path *gnmi.Path
+}
+
+// LoadFixtures reads the fixture files (.yaml, .yml and .json) found in dir.
+// dir can also be the path to a single fixture file.
+func LoadFixtures(dir string) ([]*Fixture, error) {
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{dir}
+	if fi.IsDir() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		files = make([]string, 0, len(entries))
+		for _, e := range entries {
+			if e.IsDir() {
+				continue
+			}
+			switch strings.ToLower(filepath.Ext(e.Name())) {
+			case ".yaml", ".yml", ".json":
+				files = append(files, filepath.Join(dir, e.Name()))
+			}
+		}
+		sort.Strings(files)
+	}
+	fixtures := make([]*Fixture, 0, len(files))
+	for _, file := range files {
+		f, err := readFixture(file)
+		if err != nil {
+			return nil, fmt.Errorf("fixture %q: %v", file, err)
+		}
+		fixtures = append(fixtures, f)
+	}
+	if len(fixtures) == 0 {
+		return nil, fmt.Errorf("no fixture files found in %q", dir)
+	}
+	return fixtures, nil
+}
+
+func readFixture(file string) (*Fixture, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	f := new(Fixture)
+	// YAML is a superset of JSON
+	err = yaml.Unmarshal(b, f)
+	if err != nil {
+		return nil, err
+	}
+	for i, u := range f.Updates {
+		u.path, err = path.ParsePath(u.Path)
+		if err != nil {
+			return nil, fmt.Errorf("update %d: invalid path %q: %v", i, u.Path, err)
+		}
+		u.Value = utils.Convert(u.Value)
+		if u.Jitter != nil && *u.Jitter < 0 {
+			return nil, fmt.Errorf("update %d: jitter must be a positive number", i)
+		}
+	}
+	return f, nil
+}
+
+// Target serves gNMI RPCs from a set of fixtures.
+type Target struct {
+	fixtures       []*Fixture
+	jitter         float64
+	sampleInterval time.Duration
+
+	m    *sync.Mutex
+	rand *rand.Rand
+}
+
+// New creates a mock Target.
+// jitter is the default ratio by which numeric values are varied,
+// sampleInterval is used for SAMPLE subscriptions without a sample interval.
+func New(fixtures []*Fixture, jitter float64, sampleInterval time.Duration) *Target {
+	if sampleInterval <= 0 {
+		sampleInterval = defaultSampleInterval
+	}
+	return &Target{
+		fixtures:       fixtures,
+		jitter:         jitter,
+		sampleInterval: sampleInterval,
+		m:              new(sync.Mutex),
+		rand:           rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Capabilities returns the models found in the fixtures.
+func (t *Target) Capabilities(ctx context.Context, req *gnmi.CapabilityRequest) (*gnmi.CapabilityResponse, error) {
+	rsp := &gnmi.CapabilityResponse{
+		GNMIVersion: gnmiVersion,
+		SupportedEncodings: []gnmi.Encoding{
+			gnmi.Encoding_JSON,
+			gnmi.Encoding_JSON_IETF,
+		},
+	}
+	seen := make(map[Model]struct{})
+	for _, f := range t.fixtures {
+		for _, m := range f.Models {
+			if _, ok := seen[*m]; ok {
+				continue
+			}
+			seen[*m] = struct{}{}
+			rsp.SupportedModels = append(rsp.SupportedModels, &gnmi.ModelData{
+				Name:         m.Name,
+				Organization: m.Organization,
+				Version:      m.Version,
+			})
+		}
+	}
+	return rsp, nil
+}
+
+// Get returns the fixture updates matching the requested paths.
+func (t *Target) Get(ctx context.Context, req *gnmi.GetRequest) (*gnmi.GetResponse, error) {
+	paths := req.GetPath()
+	if len(paths) == 0 {
+		paths = []*gnmi.Path{{}}
+	}
+	rsp := &gnmi.GetResponse{}
+	for _, p := range paths {
+		notifs, err := t.notifications(req.GetPrefix(), p, req.GetEncoding())
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		rsp.Notification = append(rsp.Notification, notifs...)
+	}
+	if len(rsp.Notification) == 0 {
+		return nil, status.Error(codes.NotFound, "no fixture matches the requested paths")
+	}
+	return rsp, nil
+}
+
+// Subscribe serves ONCE, POLL and STREAM subscriptions.
+// STREAM SAMPLE and TARGET_DEFINED subscriptions are sent periodically,
+// ON_CHANGE subscriptions are only sent once before the sync response.
+func (t *Target) Subscribe(req *gnmi.SubscribeRequest, stream gnmi.GNMI_SubscribeServer) error {
+	sl := req.GetSubscribe()
+	sm := new(sync.Mutex)
+	send := func(subs ...*gnmi.Subscription) error {
+		sm.Lock()
+		defer sm.Unlock()
+		for _, sub := range subs {
+			notifs, err := t.notifications(sl.GetPrefix(), sub.GetPath(), sl.GetEncoding())
+			if err != nil {
+				return status.Error(codes.Internal, err.Error())
+			}
+			for _, n := range notifs {
+				err = stream.Send(&gnmi.SubscribeResponse{
+					Response: &gnmi.SubscribeResponse_Update{Update: n},
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	sendSync := func() error {
+		sm.Lock()
+		defer sm.Unlock()
+		return stream.Send(&gnmi.SubscribeResponse{
+			Response: &gnmi.SubscribeResponse_SyncResponse{SyncResponse: true},
+		})
+	}
+
+	switch sl.GetMode() {
+	case gnmi.SubscriptionList_ONCE:
+		err := send(sl.GetSubscription()...)
+		if err != nil {
+			return err
+		}
+		return sendSync()
+	case gnmi.SubscriptionList_POLL:
+		err := send(sl.GetSubscription()...)
+		if err != nil {
+			return err
+		}
+		err = sendSync()
+		if err != nil {
+			return err
+		}
+		for {
+			r, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if r.GetPoll() == nil {
+				return status.Error(codes.InvalidArgument, "expected a poll request")
+			}
+			err = send(sl.GetSubscription()...)
+			if err != nil {
+				return err
+			}
+			err = sendSync()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	// STREAM
+	if !sl.GetUpdatesOnly() {
+		err := send(sl.GetSubscription()...)
+		if err != nil {
+			return err
+		}
+	}
+	err := sendSync()
+	if err != nil {
+		return err
+	}
+	ctx := stream.Context()
+	errCh := make(chan error, len(sl.GetSubscription()))
+	for _, sub := range sl.GetSubscription() {
+		if sub.GetMode() == gnmi.SubscriptionMode_ON_CHANGE {
+			continue
+		}
+		interval := time.Duration(sub.GetSampleInterval())
+		if interval <= 0 {
+			interval = t.sampleInterval
+		}
+		go func(sub *gnmi.Subscription) {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					err := send(sub)
+					if err != nil {
+						errCh <- err
+						return
+					}
+				}
+			}
+		}(sub)
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-errCh:
+		return err
+	}
+}
+
+// notifications builds one notification per fixture with updates matching prefix+p.
+func (t *Target) notifications(prefix, p *gnmi.Path, encoding gnmi.Encoding) ([]*gnmi.Notification, error) {
+	reqTarget := prefix.GetTarget()
+	reqPath := &gnmi.Path{
+		Origin: prefix.GetOrigin(),
+		Elem:   path.PathElems(prefix, p),
+	}
+	if reqPath.Origin == "" {
+		reqPath.Origin = p.GetOrigin()
+	}
+	now := time.Now().UnixNano()
+	notifs := make([]*gnmi.Notification, 0)
+	for _, f := range t.fixtures {
+		if reqTarget != "" && f.Target != "" && reqTarget != f.Target {
+			continue
+		}
+		var n *gnmi.Notification
+		for _, u := range f.Updates {
+			if !matchPath(reqPath, u.path) {
+				continue
+			}
+			v, err := t.typedValue(u, encoding)
+			if err != nil {
+				return nil, fmt.Errorf("path %q: %v", u.Path, err)
+			}
+			if n == nil {
+				target := f.Target
+				if target == "" {
+					target = reqTarget
+				}
+				n = &gnmi.Notification{Timestamp: now}
+				if target != "" {
+					n.Prefix = &gnmi.Path{Target: target}
+				}
+			}
+			n.Update = append(n.Update, &gnmi.Update{Path: u.path, Val: v})
+		}
+		if n != nil {
+			notifs = append(notifs, n)
+		}
+	}
+	return notifs, nil
+}
+
+func (t *Target) typedValue(u *Update, encoding gnmi.Encoding) (*gnmi.TypedValue, error) {
+	switch v := u.Value.(type) {
+	case map[string]interface{}, []interface{}:
+		b := new(bytes.Buffer)
+		enc := json.NewEncoder(b)
+		enc.SetEscapeHTML(false)
+		err := enc.Encode(v)
+		if err != nil {
+			return nil, err
+		}
+		if encoding == gnmi.Encoding_JSON {
+			return &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonVal{JsonVal: bytes.TrimSpace(b.Bytes())}}, nil
+		}
+		return &gnmi.TypedValue{Value: &gnmi.TypedValue_JsonIetfVal{JsonIetfVal: bytes.TrimSpace(b.Bytes())}}, nil
+	}
+	jitter := t.jitter
+	if u.Jitter != nil {
+		jitter = *u.Jitter
+	}
+	return gvalue.FromScalar(t.applyJitter(u.Value, jitter))
+}
+
+// applyJitter varies numeric values by a random ratio in [-jitter, jitter].
+func (t *Target) applyJitter(v interface{}, jitter float64) interface{} {
+	if jitter <= 0 {
+		return v
+	}
+	t.m.Lock()
+	f := 1 + (t.rand.Float64()*2-1)*jitter
+	t.m.Unlock()
+	switch v := v.(type) {
+	case int:
+		return int(math.Round(float64(v) * f))
+	case int64:
+		return int64(math.Round(float64(v) * f))
+	case uint64:
+		return uint64(math.Max(0, math.Round(float64(v)*f)))
+	case float64:
+		return v * f
+	}
+	return v
+}
+
+// matchPath returns true if p is equal to, or a child of, the requested path.
+// The requested path can contain wildcard names and key values.
+func matchPath(req, p *gnmi.Path) bool {
+	if req.GetOrigin() != "" && p.GetOrigin() != "" && req.GetOrigin() != p.GetOrigin() {
+		return false
+	}
+	relems := req.GetElem()
+	if len(relems) > len(p.GetElem()) {
+		return false
+	}
+	for i, re := range relems {
+		e := p.GetElem()[i]
+		if re.GetName() != "*" && re.GetName() != e.GetName() {
+			return false
+		}
+		for k, v := range re.GetKey() {
+			if v == "*" {
+				continue
+			}
+			if ev, ok := e.GetKey()[k]; !ok || ev != v {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,168 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package mock
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openconfig/gnmic/pkg/api/path"
+)
+
+const testYAMLFixture = `
+target: router1
+models:
+  - name: openconfig-interfaces
+    organization: OpenConfig working group
+    version: 3.0.0
+updates:
+  - path: /interfaces/interface[name=ethernet-1/1]/state/counters/in-octets
+    value: 1000
+  - path: /interfaces/interface[name=ethernet-1/2]/state/counters/in-octets
+    value: 2000
+    jitter: 0
+  - path: /system/config
+    value:
+      hostname: router1
+`
+
+const testJSONFixture = `{
+  "target": "router2",
+  "updates": [
+    {"path": "/interfaces/interface[name=ethernet-1/1]/state/oper-status", "value": "UP"}
+  ]
+}`
+
+func writeFixtures(t *testing.T) string {
+	dir := t.TempDir()
+	files := map[string]string{
+		"router1.yaml": testYAMLFixture,
+		"router2.json": testJSONFixture,
+		"README.md":    "not a fixture",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadFixtures(t *testing.T) {
+	fixtures, err := LoadFixtures(writeFixtures(t))
+	if err != nil {
+		t.Fatalf("failed to load fixtures: %v", err)
+	}
+	if len(fixtures) != 2 {
+		t.Fatalf("expected 2 fixtures, got %d", len(fixtures))
+	}
+	if fixtures[0].Target != "router1" || len(fixtures[0].Updates) != 3 {
+		t.Fatalf("unexpected first fixture: %+v", fixtures[0])
+	}
+	if _, ok := fixtures[0].Updates[2].Value.(map[string]interface{}); !ok {
+		t.Fatalf("expected a map value, got %T", fixtures[0].Updates[2].Value)
+	}
+	if fixtures[1].Target != "router2" || len(fixtures[1].Updates) != 1 {
+		t.Fatalf("unexpected second fixture: %+v", fixtures[1])
+	}
+}
+
+func TestGet(t *testing.T) {
+	fixtures, err := LoadFixtures(writeFixtures(t))
+	if err != nil {
+		t.Fatalf("failed to load fixtures: %v", err)
+	}
+	mt := New(fixtures, 0, 0)
+	tests := []struct {
+		name        string
+		target      string
+		path        string
+		numNotifs   int
+		numUpdates  int
+		expNotFound bool
+	}{
+		{name: "all", path: "/", numNotifs: 2, numUpdates: 4},
+		{name: "wildcard_key", path: "/interfaces/interface[name=*]/state", numNotifs: 2, numUpdates: 3},
+		{name: "key", path: "/interfaces/interface[name=ethernet-1/2]", numNotifs: 1, numUpdates: 1},
+		{name: "target", target: "router2", path: "/interfaces", numNotifs: 1, numUpdates: 1},
+		{name: "not_found", path: "/network-instances", expNotFound: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := path.ParsePath(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rsp, err := mt.Get(context.TODO(), &gnmi.GetRequest{
+				Prefix: &gnmi.Path{Target: tt.target},
+				Path:   []*gnmi.Path{p},
+			})
+			if tt.expNotFound {
+				if status.Code(err) != codes.NotFound {
+					t.Fatalf("expected NotFound, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(rsp.GetNotification()) != tt.numNotifs {
+				t.Fatalf("expected %d notifications, got %d", tt.numNotifs, len(rsp.GetNotification()))
+			}
+			numUpdates := 0
+			for _, n := range rsp.GetNotification() {
+				numUpdates += len(n.GetUpdate())
+			}
+			if numUpdates != tt.numUpdates {
+				t.Fatalf("expected %d updates, got %d", tt.numUpdates, numUpdates)
+			}
+		})
+	}
+}
+
+func TestJitter(t *testing.T) {
+	fixtures, err := LoadFixtures(writeFixtures(t))
+	if err != nil {
+		t.Fatalf("failed to load fixtures: %v", err)
+	}
+	mt := New(fixtures, 0.1, time.Second)
+	p, _ := path.ParsePath("/interfaces/interface/state/counters")
+	varied := false
+	for i := 0; i < 100; i++ {
+		rsp, err := mt.Get(context.TODO(), &gnmi.GetRequest{
+			Prefix: &gnmi.Path{Target: "router1"},
+			Path:   []*gnmi.Path{p},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		upds := rsp.GetNotification()[0].GetUpdate()
+		v := upds[0].GetVal().GetIntVal()
+		if v < 900 || v > 1100 {
+			t.Fatalf("value %d out of the jitter range", v)
+		}
+		if v != 1000 {
+			varied = true
+		}
+		// per update jitter override
+		if v := upds[1].GetVal().GetIntVal(); v != 2000 {
+			t.Fatalf("expected value 2000, got %d", v)
+		}
+	}
+	if !varied {
+		t.Fatal("expected the value to vary")
+	}
+}
@@ -0,0 +1,56 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package outputs
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+)
+
+// AckFunc reports the result of a write: nil once the message is
+// confirmed by the output destination, or the error that made the write fail.
+type AckFunc func(error)
+
+// Acknowledger is implemented by outputs able to confirm
+// that a message was written to their destination.
+// The ack function is called exactly once per written message.
+type Acknowledger interface {
+	WriteAck(ctx context.Context, m proto.Message, meta Meta, ack AckFunc)
+	WriteEventAck(ctx context.Context, ev *formatters.EventMsg, ack AckFunc)
+}
+
+// NewAckGroup returns an AckFunc expecting n calls.
+// ack is called once: with the first non nil error, or with nil
+// after n successful calls. If n is zero, ack is called immediately.
+func NewAckGroup(n int, ack AckFunc) AckFunc {
+	var once sync.Once
+	done := func(err error) {
+		once.Do(func() { ack(err) })
+	}
+	if n <= 0 {
+		done(nil)
+		return func(error) {}
+	}
+	remaining := new(atomic.Int64)
+	remaining.Store(int64(n))
+	return func(err error) {
+		if err != nil {
+			done(err)
+			return
+		}
+		if remaining.Add(-1) == 0 {
+			done(nil)
+		}
+	}
+}
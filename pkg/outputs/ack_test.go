@@ -0,0 +1,50 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package outputs
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewAckGroup(t *testing.T) {
+	var calls int
+	var got error
+	ack := func(err error) {
+		calls++
+		got = err
+	}
+
+	NewAckGroup(0, ack)
+	if calls != 1 || got != nil {
+		t.Fatalf("empty group: got %d calls, err=%v", calls, got)
+	}
+
+	calls = 0
+	g := NewAckGroup(3, ack)
+	g(nil)
+	g(nil)
+	if calls != 0 {
+		t.Fatalf("group acked before all the calls")
+	}
+	g(nil)
+	if calls != 1 || got != nil {
+		t.Fatalf("complete group: got %d calls, err=%v", calls, got)
+	}
+
+	calls = 0
+	errWrite := errors.New("write failed")
+	g = NewAckGroup(3, ack)
+	g(nil)
+	g(errWrite)
+	g(nil)
+	if calls != 1 || !errors.Is(got, errWrite) {
+		t.Fatalf("failed group: got %d calls, err=%v", calls, got)
+	}
+}
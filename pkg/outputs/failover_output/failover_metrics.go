@@ -0,0 +1,50 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package failover_output
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var failoverActiveOutput = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "gnmic",
+	Subsystem: "failover_output",
+	Name:      "active_output_index",
+	Help:      "Index of the output currently receiving the messages, 0 is the primary output",
+}, []string{"name"})
+
+var failoverSwitchCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "failover_output",
+	Name:      "switch_count_total",
+	Help:      "Number of times the active output changed",
+}, []string{"name", "output"})
+
+var failoverReplayBufferSize = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "gnmic",
+	Subsystem: "failover_output",
+	Name:      "replay_buffer_size",
+	Help:      "Number of messages waiting to be replayed to the primary output",
+}, []string{"name"})
+
+func registerMetrics(reg *prometheus.Registry) error {
+	for _, c := range []prometheus.Collector{failoverActiveOutput, failoverSwitchCount, failoverReplayBufferSize} {
+		if err := reg.Register(c); err != nil {
+			var are prometheus.AlreadyRegisteredError
+			// multiple failover outputs share the same collectors
+			if errors.As(err, &are) {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
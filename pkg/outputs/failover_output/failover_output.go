@@ -0,0 +1,297 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package failover_output
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/api/utils"
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+const (
+	Type                       = "failover"
+	loggingPrefix              = "[failover_output:%s] "
+	defaultHealthCheckInterval = 5 * time.Second
+	defaultReplayBufferSize    = 10000
+)
+
+func init() {
+	outputs.Register(Type, func() outputs.Output {
+		return &failoverOutput{
+			cfg:    &Config{},
+			logger: log.New(io.Discard, loggingPrefix, utils.DefaultLoggingFlags),
+		}
+	})
+	outputs.RegisterMembers(Type, func(cfg map[string]interface{}) []string {
+		c := new(Config)
+		if err := outputs.DecodeConfig(cfg, c); err != nil {
+			return nil
+		}
+		return c.Outputs
+	})
+}
+
+// failoverOutput writes to the first healthy output of an ordered list of outputs.
+type failoverOutput struct {
+	cfg      *Config
+	name     string
+	logger   *log.Logger
+	cancelFn context.CancelFunc
+
+	all     map[string]outputs.Output
+	members []outputs.Output
+
+	m      *sync.Mutex
+	active int
+	// messages written to a fallback output,
+	// replayed to the primary output when it recovers.
+	replay  []*bufferedMsg
+	dropped uint64
+}
+
+type Config struct {
+	// ordered list of output names, the first one is the primary output.
+	Outputs             []string      `mapstructure:"outputs,omitempty" json:"outputs,omitempty"`
+	HealthCheckInterval time.Duration `mapstructure:"health-check-interval,omitempty" json:"health-check-interval,omitempty"`
+	Replay              bool          `mapstructure:"replay,omitempty" json:"replay,omitempty"`
+	ReplayBufferSize    int           `mapstructure:"replay-buffer-size,omitempty" json:"replay-buffer-size,omitempty"`
+	EnableMetrics       bool          `mapstructure:"enable-metrics,omitempty" json:"enable-metrics,omitempty"`
+	Debug               bool          `mapstructure:"debug,omitempty" json:"debug,omitempty"`
+}
+
+type bufferedMsg struct {
+	msg  proto.Message
+	meta outputs.Meta
+	ev   *formatters.EventMsg
+}
+
+func (f *failoverOutput) Init(ctx context.Context, name string, cfg map[string]interface{}, opts ...outputs.Option) error {
+	err := outputs.DecodeConfig(cfg, f.cfg)
+	if err != nil {
+		return err
+	}
+	f.name = name
+	f.m = new(sync.Mutex)
+	f.logger.SetPrefix(fmt.Sprintf(loggingPrefix, name))
+	for _, opt := range opts {
+		if err := opt(f); err != nil {
+			return err
+		}
+	}
+	if len(f.cfg.Outputs) < 2 {
+		return errors.New("a failover output requires at least 2 outputs")
+	}
+	if f.cfg.HealthCheckInterval <= 0 {
+		f.cfg.HealthCheckInterval = defaultHealthCheckInterval
+	}
+	if f.cfg.ReplayBufferSize <= 0 {
+		f.cfg.ReplayBufferSize = defaultReplayBufferSize
+	}
+	f.members = make([]outputs.Output, 0, len(f.cfg.Outputs))
+	for _, n := range f.cfg.Outputs {
+		if n == name {
+			return fmt.Errorf("output %q cannot be a member of itself", n)
+		}
+		o, ok := f.all[n]
+		if !ok {
+			return fmt.Errorf("unknown output %q", n)
+		}
+		f.members = append(f.members, o)
+	}
+	f.all = nil
+	f.checkHealth(ctx)
+
+	ctx, f.cancelFn = context.WithCancel(ctx)
+	go f.healthCheck(ctx)
+	f.logger.Printf("initialized failover output: %s", f.String())
+	return nil
+}
+
+func (f *failoverOutput) Write(ctx context.Context, m proto.Message, meta outputs.Meta) {
+	if m == nil {
+		return
+	}
+	f.write(ctx, &bufferedMsg{msg: m, meta: meta})
+}
+
+func (f *failoverOutput) WriteEvent(ctx context.Context, ev *formatters.EventMsg) {
+	if ev == nil {
+		return
+	}
+	f.write(ctx, &bufferedMsg{ev: ev})
+}
+
+func (f *failoverOutput) write(ctx context.Context, bm *bufferedMsg) {
+	f.m.Lock()
+	active := f.active
+	if active != 0 && f.cfg.Replay {
+		if len(f.replay) >= f.cfg.ReplayBufferSize {
+			f.replay = f.replay[1:]
+			f.dropped++
+		}
+		f.replay = append(f.replay, bm)
+		if f.cfg.EnableMetrics {
+			failoverReplayBufferSize.WithLabelValues(f.name).Set(float64(len(f.replay)))
+		}
+	}
+	f.m.Unlock()
+	writeTo(ctx, f.members[active], bm)
+}
+
+func writeTo(ctx context.Context, o outputs.Output, bm *bufferedMsg) {
+	if bm.ev != nil {
+		o.WriteEvent(ctx, bm.ev)
+		return
+	}
+	o.Write(ctx, bm.msg, bm.meta)
+}
+
+func (f *failoverOutput) healthCheck(ctx context.Context) {
+	ticker := time.NewTicker(f.cfg.HealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			f.checkHealth(ctx)
+		}
+	}
+}
+
+// checkHealth selects the first healthy member as the active output,
+// the last member is used if none of them is healthy.
+func (f *failoverOutput) checkHealth(ctx context.Context) {
+	next := len(f.members) - 1
+	for i, o := range f.members {
+		err := outputs.Healthy(o)
+		if err == nil {
+			next = i
+			break
+		}
+		if f.cfg.Debug {
+			f.logger.Printf("output %q is unhealthy: %v", f.cfg.Outputs[i], err)
+		}
+	}
+
+	f.m.Lock()
+	prev := f.active
+	f.active = next
+	var replay []*bufferedMsg
+	var dropped uint64
+	if next == 0 && prev != 0 {
+		replay = f.replay
+		dropped = f.dropped
+		f.replay = nil
+		f.dropped = 0
+	}
+	f.m.Unlock()
+
+	if f.cfg.EnableMetrics {
+		failoverActiveOutput.WithLabelValues(f.name).Set(float64(next))
+		if next == 0 {
+			failoverReplayBufferSize.WithLabelValues(f.name).Set(0)
+		}
+	}
+	if next == prev {
+		return
+	}
+	f.logger.Printf("switching active output from %q to %q", f.cfg.Outputs[prev], f.cfg.Outputs[next])
+	if f.cfg.EnableMetrics {
+		failoverSwitchCount.WithLabelValues(f.name, f.cfg.Outputs[next]).Inc()
+	}
+	if len(replay) == 0 {
+		return
+	}
+	if dropped > 0 {
+		f.logger.Printf("replay buffer overflowed, %d message(s) were not kept", dropped)
+	}
+	f.logger.Printf("replaying %d message(s) to output %q", len(replay), f.cfg.Outputs[0])
+	go func() {
+		for _, bm := range replay {
+			if ctx.Err() != nil {
+				return
+			}
+			writeTo(ctx, f.members[0], bm)
+		}
+	}()
+}
+
+func (f *failoverOutput) Close() error {
+	if f.cancelFn != nil {
+		f.cancelFn()
+	}
+	return nil
+}
+
+// Healthy returns nil if at least one of the members is healthy.
+func (f *failoverOutput) Healthy() error {
+	errs := make([]error, 0, len(f.members))
+	for i, o := range f.members {
+		err := outputs.Healthy(o)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", f.cfg.Outputs[i], err))
+	}
+	return errors.Join(errs...)
+}
+
+func (f *failoverOutput) RegisterMetrics(reg *prometheus.Registry) {
+	if !f.cfg.EnableMetrics {
+		return
+	}
+	if reg == nil {
+		f.logger.Printf("ERR: output metrics enabled but main registry is not initialized, enable main metrics under `api-server`")
+		return
+	}
+	if err := registerMetrics(reg); err != nil {
+		f.logger.Printf("failed to register metrics: %v", err)
+	}
+}
+
+func (f *failoverOutput) String() string {
+	b, err := json.Marshal(f.cfg)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func (f *failoverOutput) SetLogger(logger *log.Logger) {
+	if logger != nil && f.logger != nil {
+		f.logger.SetOutput(logger.Writer())
+		f.logger.SetFlags(logger.Flags())
+	}
+}
+
+// SetEventProcessors is a noop, the event processors
+// of the member outputs are applied.
+func (f *failoverOutput) SetEventProcessors(map[string]map[string]interface{}, *log.Logger, map[string]*types.TargetConfig, map[string]map[string]interface{}) error {
+	return nil
+}
+
+func (f *failoverOutput) SetOutputs(outs map[string]outputs.Output) { f.all = outs }
+
+func (f *failoverOutput) SetName(string)                                  {}
+func (f *failoverOutput) SetClusterName(string)                           {}
+func (f *failoverOutput) SetTargetsConfig(map[string]*types.TargetConfig) {}
@@ -0,0 +1,110 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package failover_output
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+type testOutput struct {
+	m      sync.Mutex
+	msgs   int
+	health outputs.Health
+}
+
+func (o *testOutput) Init(context.Context, string, map[string]interface{}, ...outputs.Option) error {
+	return nil
+}
+func (o *testOutput) Write(context.Context, proto.Message, outputs.Meta) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	o.msgs++
+}
+func (o *testOutput) WriteEvent(context.Context, *formatters.EventMsg) {}
+func (o *testOutput) Close() error                                     { return nil }
+func (o *testOutput) RegisterMetrics(*prometheus.Registry)             {}
+func (o *testOutput) String() string                                   { return "" }
+func (o *testOutput) SetLogger(*log.Logger)                            {}
+func (o *testOutput) SetEventProcessors(map[string]map[string]interface{}, *log.Logger, map[string]*types.TargetConfig, map[string]map[string]interface{}) error {
+	return nil
+}
+func (o *testOutput) SetName(string)                                  {}
+func (o *testOutput) SetClusterName(string)                           {}
+func (o *testOutput) SetTargetsConfig(map[string]*types.TargetConfig) {}
+func (o *testOutput) Healthy() error                                  { return o.health.Get() }
+
+func (o *testOutput) count() int {
+	o.m.Lock()
+	defer o.m.Unlock()
+	return o.msgs
+}
+
+func TestFailover(t *testing.T) {
+	primary, fallback := new(testOutput), new(testOutput)
+	f := outputs.Outputs[Type]()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := f.Init(ctx, "group1", map[string]interface{}{
+		"outputs":               []string{"primary", "fallback"},
+		"health-check-interval": "1h",
+		"replay":                true,
+	}, outputs.WithOutputs(map[string]outputs.Output{"primary": primary, "fallback": fallback}))
+	if err != nil {
+		t.Fatalf("failed to init: %v", err)
+	}
+	fo := f.(*failoverOutput)
+	msg := &gnmi.SubscribeResponse{}
+
+	f.Write(ctx, msg, nil)
+	if primary.count() != 1 || fallback.count() != 0 {
+		t.Fatalf("expected the primary output to be used, got %d/%d", primary.count(), fallback.count())
+	}
+
+	primary.health.Set(errors.New("down"))
+	fo.checkHealth(ctx)
+	f.Write(ctx, msg, nil)
+	f.Write(ctx, msg, nil)
+	if primary.count() != 1 || fallback.count() != 2 {
+		t.Fatalf("expected the fallback output to be used, got %d/%d", primary.count(), fallback.count())
+	}
+
+	primary.health.Set(nil)
+	fo.checkHealth(ctx)
+	// the 2 messages written to the fallback are replayed
+	deadline := time.Now().Add(time.Second)
+	for primary.count() != 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if primary.count() != 3 {
+		t.Fatalf("expected the fallback messages to be replayed, got %d", primary.count())
+	}
+}
+
+func TestInitUnknownOutput(t *testing.T) {
+	f := outputs.Outputs[Type]()
+	err := f.Init(context.Background(), "group1", map[string]interface{}{
+		"outputs": []string{"primary", "missing"},
+	}, outputs.WithOutputs(map[string]outputs.Output{"primary": new(testOutput)}))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+}
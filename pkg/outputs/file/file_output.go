@@ -58,6 +58,7 @@ type File struct {
 
 	targetTpl *template.Template
 	msgTpl    *template.Template
+	live      *liveTable
 }
 
 // Config //
@@ -78,6 +79,7 @@ type Config struct {
 	EnableMetrics      bool     `mapstructure:"enable-metrics,omitempty"`
 	Debug              bool     `mapstructure:"debug,omitempty"`
 	CalculateLatency   bool     `mapstructure:"calculate-latency,omitempty"`
+	TableLive          bool     `mapstructure:"table-live,omitempty"`
 }
 
 func (f *File) String() string {
@@ -130,9 +132,16 @@ func (f *File) Init(ctx context.Context, name string, cfg map[string]interface{}
 	if f.cfg.Format == "proto" {
 		return fmt.Errorf("proto format not supported in output type 'file'")
 	}
-	if f.cfg.Separator == "" {
+	// binary formats are self-delimiting, they are written without separator by default.
+	if f.cfg.Separator == "" && !formatters.IsBinaryFormat(f.cfg.Format) {
 		f.cfg.Separator = defaultSeparator
 	}
+	if f.cfg.TableLive && f.cfg.Format != "table" {
+		return fmt.Errorf("table-live requires format 'table'")
+	}
+	if f.cfg.MsgTemplate != "" && formatters.IsBinaryFormat(f.cfg.Format) {
+		return fmt.Errorf("msg-template is not supported with format '%s'", f.cfg.Format)
+	}
 	if f.cfg.FileName == "" && f.cfg.FileType == "" {
 		f.cfg.FileType = "stdout"
 	}
@@ -198,6 +207,10 @@ func (f *File) Init(ctx context.Context, name string, cfg map[string]interface{}
 		f.msgTpl = f.msgTpl.Funcs(outputs.TemplateFuncs)
 	}
 
+	if f.cfg.TableLive {
+		f.live = newLiveTable(f.file)
+		go f.live.start(ctx)
+	}
 	f.logger.Printf("initialized file output: %s", f.String())
 	go func() {
 		<-ctx.Done()
@@ -226,6 +239,16 @@ func (f *File) Write(ctx context.Context, rsp proto.Message, meta outputs.Meta)
 	if err != nil {
 		f.logger.Printf("failed to add target to the response: %v", err)
 	}
+	if f.live != nil {
+		err = f.live.update(rsp, meta)
+		if err != nil {
+			if f.cfg.Debug {
+				f.logger.Printf("failed updating live table: %v", err)
+			}
+			numberOfFailWriteMsgs.WithLabelValues(f.file.Name(), "marshal_error").Inc()
+		}
+		return
+	}
 	bb, err := outputs.Marshal(rsp, meta, f.mo, f.cfg.SplitEvents, f.evps...)
 	if err != nil {
 		if f.cfg.Debug {
@@ -275,13 +298,7 @@ func (f *File) WriteEvent(ctx context.Context, ev *formatters.EventMsg) {
 	toWrite := []byte{}
 	if f.cfg.SplitEvents {
 		for _, pev := range evs {
-			var err error
-			var b []byte
-			if f.cfg.Multiline {
-				b, err = json.MarshalIndent(pev, "", f.cfg.Indent)
-			} else {
-				b, err = json.Marshal(pev)
-			}
+			b, err := f.mo.MarshalEvent(pev)
 			if err != nil {
 				fmt.Printf("failed to WriteEvent: %v", err)
 				numberOfFailWriteMsgs.WithLabelValues(f.file.Name(), "marshal_error").Inc()
@@ -291,13 +308,7 @@ func (f *File) WriteEvent(ctx context.Context, ev *formatters.EventMsg) {
 			toWrite = append(toWrite, []byte(f.cfg.Separator)...)
 		}
 	} else {
-		var err error
-		var b []byte
-		if f.cfg.Multiline {
-			b, err = json.MarshalIndent(evs, "", f.cfg.Indent)
-		} else {
-			b, err = json.Marshal(evs)
-		}
+		b, err := f.mo.MarshalEvents(evs)
 		if err != nil {
 			fmt.Printf("failed to WriteEvent: %v", err)
 			numberOfFailWriteMsgs.WithLabelValues(f.file.Name(), "marshal_error").Inc()
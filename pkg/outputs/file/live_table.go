@@ -0,0 +1,121 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package file
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+)
+
+const (
+	defaultTableRefresh = time.Second
+	// move the cursor home and clear the screen
+	clearScreen = "\033[H\033[2J"
+)
+
+// liveTable keeps the latest value of each received path
+// and periodically redraws them as a single table.
+type liveTable struct {
+	w       io.Writer
+	refresh time.Duration
+
+	m     *sync.Mutex
+	rows  map[string]*formatters.TableRow // key: source + path
+	dirty bool
+}
+
+func newLiveTable(w io.Writer) *liveTable {
+	return &liveTable{
+		w:       w,
+		refresh: defaultTableRefresh,
+		m:       new(sync.Mutex),
+		rows:    make(map[string]*formatters.TableRow),
+	}
+}
+
+func (t *liveTable) update(msg proto.Message, meta map[string]string) error {
+	rows, err := formatters.ResponseTableRows(msg, meta)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	t.m.Lock()
+	defer t.m.Unlock()
+	for _, r := range rows {
+		if r.Type == "delete" {
+			t.delete(r.Source, r.Path)
+			continue
+		}
+		t.rows[r.Source+r.Path] = r
+	}
+	t.dirty = true
+	return nil
+}
+
+// delete removes the path and its children.
+// must be called with the lock held.
+func (t *liveTable) delete(source, p string) {
+	for k, r := range t.rows {
+		if r.Source != source {
+			continue
+		}
+		if r.Path == p || strings.HasPrefix(r.Path, strings.TrimSuffix(p, "/")+"/") {
+			delete(t.rows, k)
+		}
+	}
+}
+
+func (t *liveTable) start(ctx context.Context) {
+	ticker := time.NewTicker(t.refresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.render()
+		}
+	}
+}
+
+func (t *liveTable) render() {
+	t.m.Lock()
+	if !t.dirty {
+		t.m.Unlock()
+		return
+	}
+	rows := make([]*formatters.TableRow, 0, len(t.rows))
+	for _, r := range t.rows {
+		rows = append(rows, r)
+	}
+	t.dirty = false
+	t.m.Unlock()
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Source == rows[j].Source {
+			return rows[i].Path < rows[j].Path
+		}
+		return rows[i].Source < rows[j].Source
+	})
+	buf := new(bytes.Buffer)
+	buf.WriteString(clearScreen)
+	formatters.RenderTable(buf, rows, true)
+	t.w.Write(buf.Bytes())
+}
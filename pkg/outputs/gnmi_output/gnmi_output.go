@@ -27,6 +27,7 @@ import (
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/openconfig/gnmic/pkg/api/path"
 	"github.com/openconfig/gnmic/pkg/api/types"
 	"github.com/openconfig/gnmic/pkg/api/utils"
 	"github.com/openconfig/gnmic/pkg/formatters"
@@ -55,6 +56,9 @@ type gNMIOutput struct {
 	cfg       *config
 	logger    *log.Logger
 	targetTpl *template.Template
+	// rewrite templates of the cached notifications target and prefix.
+	targetRewriteTpl *template.Template
+	prefixRewriteTpl *template.Template
 	//
 	srv     *server
 	grpcSrv *grpc.Server
@@ -66,6 +70,8 @@ type config struct {
 	//Name             string `mapstructure:"name,omitempty"`
 	Address          string           `mapstructure:"address,omitempty"`
 	TargetTemplate   string           `mapstructure:"target-template,omitempty"`
+	TargetRewrite    string           `mapstructure:"target-rewrite,omitempty"`
+	PrefixRewrite    string           `mapstructure:"prefix-rewrite,omitempty"`
 	MaxSubscriptions int64            `mapstructure:"max-subscriptions,omitempty"`
 	MaxUnaryRPC      int64            `mapstructure:"max-unary-rpc,omitempty"`
 	TLS              *types.TLSConfig `mapstructure:"tls,omitempty"`
@@ -98,6 +104,20 @@ func (g *gNMIOutput) Init(ctx context.Context, name string, cfg map[string]inter
 			return err
 		}
 	}
+	if g.cfg.TargetRewrite != "" {
+		g.targetRewriteTpl, err = gtemplate.CreateTemplate(fmt.Sprintf("%s-target-rewrite", name), g.cfg.TargetRewrite)
+		if err != nil {
+			return err
+		}
+		g.targetRewriteTpl = g.targetRewriteTpl.Funcs(outputs.TemplateFuncs)
+	}
+	if g.cfg.PrefixRewrite != "" {
+		g.prefixRewriteTpl, err = gtemplate.CreateTemplate(fmt.Sprintf("%s-prefix-rewrite", name), g.cfg.PrefixRewrite)
+		if err != nil {
+			return err
+		}
+		g.prefixRewriteTpl = g.prefixRewriteTpl.Funcs(outputs.TemplateFuncs)
+	}
 	err = g.startGRPCServer()
 	if err != nil {
 		return err
@@ -116,6 +136,11 @@ func (g *gNMIOutput) Write(ctx context.Context, rsp proto.Message, meta outputs.
 	case *gnmi.SubscribeResponse:
 		switch rsp := rsp.Response.(type) {
 		case *gnmi.SubscribeResponse_Update:
+			err = g.rewrite(rsp.Update, meta)
+			if err != nil {
+				g.logger.Printf("failed to rewrite the response target and prefix: %v", err)
+				return
+			}
 			target := rsp.Update.GetPrefix().GetTarget()
 			if target == "" {
 				g.logger.Printf("response missing target")
@@ -138,7 +163,58 @@ func (g *gNMIOutput) Write(ctx context.Context, rsp proto.Message, meta outputs.
 	}
 }
 
-func (g *gNMIOutput) WriteEvent(context.Context, *formatters.EventMsg) {}
+// rewrite sets the notification prefix target and prepends the prefix path
+// from the target-rewrite and prefix-rewrite templates, if configured.
+// The templates input is the response metadata, with the current prefix target under `target`.
+func (g *gNMIOutput) rewrite(notif *gnmi.Notification, meta outputs.Meta) error {
+	if g.targetRewriteTpl == nil && g.prefixRewriteTpl == nil {
+		return nil
+	}
+	if notif.Prefix == nil {
+		notif.Prefix = new(gnmi.Path)
+	}
+	input := make(map[string]string, len(meta)+1)
+	for k, v := range meta {
+		input[k] = v
+	}
+	input["target"] = notif.Prefix.GetTarget()
+	if g.prefixRewriteTpl != nil {
+		sb := new(strings.Builder)
+		err := gtemplate.Execute(sb, g.prefixRewriteTpl, input)
+		if err != nil {
+			return err
+		}
+		if s := strings.TrimSpace(sb.String()); s != "" {
+			p, err := path.ParsePath(s)
+			if err != nil {
+				return fmt.Errorf("invalid prefix-rewrite result %q: %v", s, err)
+			}
+			notif.Prefix.Elem = append(p.GetElem(), notif.Prefix.GetElem()...)
+			if p.GetOrigin() != "" {
+				notif.Prefix.Origin = p.GetOrigin()
+			}
+		}
+	}
+	if g.targetRewriteTpl != nil {
+		sb := new(strings.Builder)
+		err := gtemplate.Execute(sb, g.targetRewriteTpl, input)
+		if err != nil {
+			return err
+		}
+		if s := strings.TrimSpace(sb.String()); s != "" {
+			notif.Prefix.Target = s
+		}
+	}
+	return nil
+}
+
+// WriteEvent converts the event back to a gNMI notification and writes it to the cache.
+func (g *gNMIOutput) WriteEvent(ctx context.Context, ev *formatters.EventMsg) {
+	err := outputs.WriteEventAsResponse(ctx, g, ev)
+	if err != nil {
+		g.logger.Printf("%v", err)
+	}
+}
 
 func (g *gNMIOutput) Close() error {
 	//g.teardown()
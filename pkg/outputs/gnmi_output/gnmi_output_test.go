@@ -0,0 +1,121 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package gnmi_output
+
+import (
+	"io"
+	"log"
+	"testing"
+	"text/template"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openconfig/gnmic/pkg/gtemplate"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+func TestRewrite(t *testing.T) {
+	tests := []struct {
+		name          string
+		targetRewrite string
+		prefixRewrite string
+		meta          outputs.Meta
+		in            *gnmi.Notification
+		want          *gnmi.Notification
+		wantErr       bool
+	}{
+		{
+			name: "no_templates",
+			in:   &gnmi.Notification{Prefix: &gnmi.Path{Target: "r1"}},
+			want: &gnmi.Notification{Prefix: &gnmi.Path{Target: "r1"}},
+		},
+		{
+			name:          "target_rewrite",
+			targetRewrite: `{{ .site }}/{{ .target }}`,
+			meta:          outputs.Meta{"site": "dc1"},
+			in:            &gnmi.Notification{Prefix: &gnmi.Path{Target: "r1"}},
+			want:          &gnmi.Notification{Prefix: &gnmi.Path{Target: "dc1/r1"}},
+		},
+		{
+			name:          "empty_target_rewrite_result",
+			targetRewrite: `{{ .site }}`,
+			in:            &gnmi.Notification{Prefix: &gnmi.Path{Target: "r1"}},
+			want:          &gnmi.Notification{Prefix: &gnmi.Path{Target: "r1"}},
+		},
+		{
+			name:          "prefix_rewrite",
+			prefixRewrite: `/sites/site[name={{ .site }}]`,
+			meta:          outputs.Meta{"site": "dc1"},
+			in: &gnmi.Notification{Prefix: &gnmi.Path{
+				Target: "r1",
+				Elem:   []*gnmi.PathElem{{Name: "interfaces"}},
+			}},
+			want: &gnmi.Notification{Prefix: &gnmi.Path{
+				Target: "r1",
+				Elem: []*gnmi.PathElem{
+					{Name: "sites"},
+					{Name: "site", Key: map[string]string{"name": "dc1"}},
+					{Name: "interfaces"},
+				},
+			}},
+		},
+		{
+			name:          "prefix_rewrite_with_origin",
+			prefixRewrite: `agg:/sites/site[name={{ .site }}]`,
+			targetRewrite: `aggregator`,
+			meta:          outputs.Meta{"site": "dc1"},
+			in:            &gnmi.Notification{},
+			want: &gnmi.Notification{Prefix: &gnmi.Path{
+				Origin: "agg",
+				Target: "aggregator",
+				Elem: []*gnmi.PathElem{
+					{Name: "sites"},
+					{Name: "site", Key: map[string]string{"name": "dc1"}},
+				},
+			}},
+		},
+		{
+			name:          "invalid_prefix_rewrite_result",
+			prefixRewrite: `/sites/site[name={{ .site }}`,
+			meta:          outputs.Meta{"site": "dc1"},
+			in:            &gnmi.Notification{},
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &gNMIOutput{logger: log.New(io.Discard, "", 0)}
+			if tt.targetRewrite != "" {
+				g.targetRewriteTpl = mustTemplate(t, tt.targetRewrite)
+			}
+			if tt.prefixRewrite != "" {
+				g.prefixRewriteTpl = mustTemplate(t, tt.prefixRewrite)
+			}
+			err := g.rewrite(tt.in, tt.meta)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !proto.Equal(tt.in, tt.want) {
+				t.Errorf("got %v, want %v", tt.in, tt.want)
+			}
+		})
+	}
+}
+
+func mustTemplate(t *testing.T, text string) *template.Template {
+	tpl, err := gtemplate.CreateTemplate(t.Name(), text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tpl.Funcs(outputs.TemplateFuncs)
+}
@@ -0,0 +1,79 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package outputs
+
+import "sync"
+
+// HealthChecker is implemented by outputs able to report whether
+// they can currently deliver messages.
+// Outputs not implementing it are always considered healthy.
+type HealthChecker interface {
+	// Healthy returns nil if the output is healthy,
+	// otherwise the error that made it unhealthy.
+	Healthy() error
+}
+
+// Healthy returns nil if the output o is healthy.
+func Healthy(o Output) error {
+	if hc, ok := o.(HealthChecker); ok {
+		return hc.Healthy()
+	}
+	return nil
+}
+
+// Health stores the result of the last health check or delivery attempt of an output,
+// its zero value is healthy.
+type Health struct {
+	m   sync.RWMutex
+	err error
+}
+
+// Set records the result of the last health check or delivery attempt.
+func (h *Health) Set(err error) {
+	h.m.Lock()
+	defer h.m.Unlock()
+	h.err = err
+}
+
+// Get returns the recorded error, nil if healthy.
+func (h *Health) Get() error {
+	h.m.RLock()
+	defer h.m.RUnlock()
+	return h.err
+}
+
+// OutputsSetter is implemented by outputs that forward messages to other outputs.
+type OutputsSetter interface {
+	SetOutputs(map[string]Output)
+}
+
+// MembersFunc returns the names of the outputs referenced by an output configuration.
+type MembersFunc func(cfg map[string]interface{}) []string
+
+var membersFuncs = map[string]MembersFunc{}
+
+// RegisterMembers registers the function returning the outputs
+// an output of type name forwards messages to.
+func RegisterMembers(name string, fn MembersFunc) {
+	membersFuncs[name] = fn
+}
+
+// Members returns the names of the outputs the output configured with cfg
+// forwards messages to, nil if it does not forward messages.
+func Members(cfg map[string]interface{}) []string {
+	outType, ok := cfg["type"].(string)
+	if !ok {
+		return nil
+	}
+	fn, ok := membersFuncs[outType]
+	if !ok {
+		return nil
+	}
+	return fn(cfg)
+}
@@ -0,0 +1,326 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package http_output
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/api/utils"
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/gtemplate"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+// max number of response body bytes logged in debug mode
+const maxErrBodySize = 512
+
+type action string
+
+const (
+	actionSuccess action = "success"
+	actionRetry   action = "retry"
+	actionDrop    action = "drop"
+)
+
+// statusActions maps response status codes to the action taken for the batch.
+type statusActions struct {
+	codes   map[int]action
+	classes map[int]action
+}
+
+func newStatusActions(cfg map[string]string) (*statusActions, error) {
+	sa := &statusActions{
+		codes:   make(map[int]action),
+		classes: make(map[int]action),
+	}
+	for k, v := range cfg {
+		a := action(strings.ToLower(v))
+		switch a {
+		case actionSuccess, actionRetry, actionDrop:
+		default:
+			return nil, fmt.Errorf("status code %q: unknown action %q, expecting one of success, retry or drop", k, v)
+		}
+		k = strings.ToLower(k)
+		if len(k) == 3 && strings.HasSuffix(k, "xx") && k[0] >= '1' && k[0] <= '5' {
+			sa.classes[int(k[0]-'0')] = a
+			continue
+		}
+		code, err := strconv.Atoi(k)
+		if err != nil || code < 100 || code > 599 {
+			return nil, fmt.Errorf("invalid status code %q, expecting a code like 503 or a class like 5xx", k)
+		}
+		sa.codes[code] = a
+	}
+	return sa, nil
+}
+
+// action returns the action for a status code: a configured code
+// takes precedence over a configured class.
+// By default 2xx codes are successful, 429 and 5xx are retried, others are dropped.
+func (sa *statusActions) action(code int) action {
+	if a, ok := sa.codes[code]; ok {
+		return a
+	}
+	if a, ok := sa.classes[code/100]; ok {
+		return a
+	}
+	switch {
+	case code >= 200 && code < 300:
+		return actionSuccess
+	case code == http.StatusTooManyRequests || code >= 500:
+		return actionRetry
+	}
+	return actionDrop
+}
+
+func (h *httpOutput) createHTTPClient() error {
+	c := &http.Client{
+		Timeout: h.cfg.Timeout,
+	}
+	if h.cfg.TLS != nil {
+		tlsCfg, err := utils.NewTLSConfig(
+			h.cfg.TLS.CaFile,
+			h.cfg.TLS.CertFile,
+			h.cfg.TLS.KeyFile,
+			"",
+			h.cfg.TLS.SkipVerify,
+			false,
+		)
+		if err != nil {
+			return err
+		}
+		c.Transport = &http.Transport{
+			TLSClientConfig: tlsCfg,
+		}
+	}
+	h.httpClient = c
+	return nil
+}
+
+// send groups the batch events by URL and sends a request per URL.
+func (h *httpOutput) send(ctx context.Context, batch []*formatters.EventMsg) {
+	urls := make([]string, 0, 1)
+	groups := make(map[string][]interface{})
+	for _, ev := range batch {
+		in, err := outputs.TemplateInput(ev)
+		if err != nil {
+			h.logger.Printf("failed to convert event: %v", err)
+			h.failed(1, "marshal_error")
+			continue
+		}
+		u := new(strings.Builder)
+		err = gtemplate.Execute(u, h.urlTpl, in)
+		if err != nil {
+			h.logger.Printf("failed to execute url template: %v", err)
+			h.failed(1, "url_template_error")
+			continue
+		}
+		url := u.String()
+		if _, ok := groups[url]; !ok {
+			urls = append(urls, url)
+		}
+		groups[url] = append(groups[url], in)
+	}
+	for _, url := range urls {
+		evs := groups[url]
+		body, err := h.body(evs)
+		if err != nil {
+			h.logger.Printf("failed to build request body: %v", err)
+			h.failed(len(evs), "body_template_error")
+			continue
+		}
+		reason, err := h.do(ctx, url, body)
+		h.health.Set(err)
+		if err != nil {
+			h.logger.Printf("failed to send %d event(s) to %s: %v", len(evs), url, err)
+			h.failed(len(evs), reason)
+			continue
+		}
+		if h.cfg.EnableMetrics {
+			httpNumberOfSentEvents.WithLabelValues(h.cfg.Name).Add(float64(len(evs)))
+		}
+	}
+}
+
+// body builds the request body, the JSON array of the events
+// if no body template is configured.
+func (h *httpOutput) body(evs []interface{}) ([]byte, error) {
+	if h.bodyTpl == nil {
+		return json.Marshal(evs)
+	}
+	b := new(bytes.Buffer)
+	err := gtemplate.Execute(b, h.bodyTpl, evs)
+	if err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// do sends the request, retrying with an exponential backoff
+// as long as the response status code action is retry.
+// A Retry-After response header overrides the backoff, up to max-backoff.
+// On failure, it returns the reason used as metric label.
+func (h *httpOutput) do(ctx context.Context, url string, body []byte) (string, error) {
+	if h.errors != nil {
+		return h.doWithPolicy(ctx, url, body)
+	}
+	backoff := h.cfg.InitialBackoff
+	var reauth bool
+	for attempt := 0; ; attempt++ {
+		code, retryAfter, err := h.request(ctx, url, body)
+		// rejected credentials-command credentials are renewed once
+		if err == nil && code == http.StatusUnauthorized && h.credentials != nil && !reauth {
+			h.credentials.Invalidate()
+			reauth = true
+			continue
+		}
+		act := actionRetry
+		if err == nil {
+			act = h.actions.action(code)
+		}
+		switch act {
+		case actionSuccess:
+			return "", nil
+		case actionDrop:
+			return fmt.Sprintf("status_code=%d", code), fmt.Errorf("dropped after status code %d", code)
+		}
+		if err == nil {
+			err = fmt.Errorf("status code %d", code)
+		}
+		if attempt >= *h.cfg.MaxRetries {
+			return "max_retries", fmt.Errorf("giving up after %d attempt(s): %w", attempt+1, err)
+		}
+		wait := backoff
+		if retryAfter > 0 {
+			wait = retryAfter
+		}
+		if wait > h.cfg.MaxBackoff {
+			wait = h.cfg.MaxBackoff
+		}
+		if h.cfg.Debug {
+			h.logger.Printf("attempt %d to %s failed: %v, retrying in %s", attempt+1, url, err, wait)
+		}
+		select {
+		case <-ctx.Done():
+			return "canceled", ctx.Err()
+		case <-time.After(wait):
+		}
+		backoff *= 2
+		if backoff > h.cfg.MaxBackoff {
+			backoff = h.cfg.MaxBackoff
+		}
+	}
+}
+
+// doWithPolicy sends the request applying the error-policy,
+// status codes with a success action are not considered as errors.
+// Auth errors renew the credentials-command credentials.
+func (h *httpOutput) doWithPolicy(ctx context.Context, url string, body []byte) (string, error) {
+	var reauth func()
+	if h.credentials != nil {
+		reauth = h.credentials.Invalidate
+	}
+	err := h.errors.Do(ctx, func(ctx context.Context) error {
+		code, _, err := h.request(ctx, url, body)
+		if err != nil {
+			return err
+		}
+		if h.actions.action(code) == actionSuccess {
+			return nil
+		}
+		return &outputs.StatusError{Code: code}
+	}, reauth)
+	if err != nil {
+		return "error_class=" + string(outputs.ClassifyError(err)), err
+	}
+	return "", nil
+}
+
+// request sends a single request and returns the response status code and Retry-After duration.
+func (h *httpOutput) request(ctx context.Context, url string, body []byte) (int, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, h.cfg.Method, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create HTTP request: %v", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Content-Type", h.cfg.ContentType)
+	if h.cfg.Authentication != nil {
+		req.SetBasicAuth(h.cfg.Authentication.Username, h.cfg.Authentication.Password)
+	}
+	if h.cfg.Authorization != nil && h.cfg.Authorization.Type != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("%s %s", h.cfg.Authorization.Type, h.cfg.Authorization.Credentials))
+	}
+	if h.credentials != nil {
+		creds, err := h.credentials.Get(ctx)
+		if err != nil {
+			return 0, 0, err
+		}
+		if creds.Username != "" || creds.Password != "" {
+			req.SetBasicAuth(creds.Username, creds.Password)
+		}
+		if creds.Token != "" {
+			authType := "Bearer"
+			if h.cfg.Authorization != nil && h.cfg.Authorization.Type != "" {
+				authType = h.cfg.Authorization.Type
+			}
+			req.Header.Set("Authorization", fmt.Sprintf("%s %s", authType, creds.Token))
+		}
+	}
+	for k, v := range h.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	start := time.Now()
+	rsp, err := h.httpClient.Do(req)
+	if err != nil {
+		if h.cfg.EnableMetrics {
+			httpNumberOfRequests.WithLabelValues(h.cfg.Name, "client_failure").Inc()
+		}
+		return 0, 0, err
+	}
+	defer rsp.Body.Close()
+	if h.cfg.EnableMetrics {
+		httpNumberOfRequests.WithLabelValues(h.cfg.Name, strconv.Itoa(rsp.StatusCode)).Inc()
+		httpRequestDuration.WithLabelValues(h.cfg.Name).Set(float64(time.Since(start).Nanoseconds()))
+	}
+	if h.cfg.Debug {
+		msg, _ := io.ReadAll(io.LimitReader(rsp.Body, maxErrBodySize))
+		h.logger.Printf("got response from %s: status=%s, body=%s", url, rsp.Status, string(msg))
+	}
+	// drain the body to reuse the connection
+	io.Copy(io.Discard, rsp.Body)
+	return rsp.StatusCode, parseRetryAfter(rsp.Header.Get("Retry-After")), nil
+}
+
+// parseRetryAfter parses a Retry-After header value in seconds or as an HTTP date.
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if s, err := strconv.Atoi(v); err == nil {
+		return time.Duration(s) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+func (h *httpOutput) failed(n int, reason string) {
+	if h.cfg.EnableMetrics {
+		httpNumberOfFailedEvents.WithLabelValues(h.cfg.Name, reason).Add(float64(n))
+	}
+}
@@ -0,0 +1,57 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package http_output
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var httpNumberOfSentEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "http_output",
+	Name:      "number_of_sent_events_total",
+	Help:      "Number of events successfully sent by gnmic http output",
+}, []string{"name"})
+
+var httpNumberOfFailedEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "http_output",
+	Name:      "number_of_failed_events_total",
+	Help:      "Number of events gnmic http output failed to send",
+}, []string{"name", "reason"})
+
+var httpNumberOfRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "http_output",
+	Name:      "number_of_requests_total",
+	Help:      "Number of requests sent by gnmic http output per response status code",
+}, []string{"name", "status_code"})
+
+var httpRequestDuration = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "gnmic",
+	Subsystem: "http_output",
+	Name:      "request_duration_ns",
+	Help:      "gnmic http output last request duration in ns",
+}, []string{"name"})
+
+func registerMetrics(reg *prometheus.Registry) error {
+	for _, c := range []prometheus.Collector{httpNumberOfSentEvents, httpNumberOfFailedEvents, httpNumberOfRequests, httpRequestDuration} {
+		if err := reg.Register(c); err != nil {
+			var are prometheus.AlreadyRegisteredError
+			// multiple http outputs share the same collectors
+			if errors.As(err, &are) {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
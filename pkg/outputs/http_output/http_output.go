@@ -0,0 +1,380 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package http_output
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/api/utils"
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/gtemplate"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+const (
+	Type                  = "http"
+	loggingPrefix         = "[http_output:%s] "
+	defaultMethod         = http.MethodPost
+	defaultContentType    = "application/json"
+	defaultTimeout        = 10 * time.Second
+	defaultBatchSize      = 100
+	defaultFlushInterval  = 5 * time.Second
+	defaultBufferSize     = 1000
+	defaultNumWorkers     = 1
+	defaultMaxRetries     = 3
+	defaultInitialBackoff = 500 * time.Millisecond
+	defaultMaxBackoff     = 30 * time.Second
+	userAgent             = "gNMIc http output"
+)
+
+func init() {
+	outputs.Register(Type, func() outputs.Output {
+		return &httpOutput{
+			cfg:    &Config{},
+			logger: log.New(io.Discard, loggingPrefix, utils.DefaultLoggingFlags),
+		}
+	})
+}
+
+// httpOutput sends batches of events to a webhook.
+type httpOutput struct {
+	cfg    *Config
+	logger *log.Logger
+	cfn    context.CancelFunc
+
+	httpClient *http.Client
+	eventCh    chan *formatters.EventMsg
+	evps       []formatters.EventProcessor
+
+	targetTpl *template.Template
+	urlTpl    *template.Template
+	bodyTpl   *template.Template
+	actions   *statusActions
+	health    outputs.Health
+	// credentials returned by the credentials-command, nil if not set.
+	credentials *types.CredentialsCache
+	// applies the error-policy, nil if not set.
+	errors *outputs.ErrorHandler
+}
+
+type Config struct {
+	Name   string `mapstructure:"name,omitempty" json:"name,omitempty"`
+	URL    string `mapstructure:"url,omitempty" json:"url,omitempty"`
+	Method string `mapstructure:"method,omitempty" json:"method,omitempty"`
+	// static headers added to each request
+	Headers        map[string]string `mapstructure:"headers,omitempty" json:"headers,omitempty"`
+	ContentType    string            `mapstructure:"content-type,omitempty" json:"content-type,omitempty"`
+	BodyTemplate   string            `mapstructure:"body-template,omitempty" json:"body-template,omitempty"`
+	Timeout        time.Duration     `mapstructure:"timeout,omitempty" json:"timeout,omitempty"`
+	Authentication *auth             `mapstructure:"authentication,omitempty" json:"authentication,omitempty"`
+	Authorization  *authorization    `mapstructure:"authorization,omitempty" json:"authorization,omitempty"`
+	// external command returning the username and password or token
+	CredentialsCommand *types.CredentialsCommand `mapstructure:"credentials-command,omitempty" json:"credentials-command,omitempty"`
+	TLS                *types.TLSConfig          `mapstructure:"tls,omitempty" json:"tls,omitempty"`
+	BatchSize          int                       `mapstructure:"batch-size,omitempty" json:"batch-size,omitempty"`
+	FlushInterval      time.Duration             `mapstructure:"flush-interval,omitempty" json:"flush-interval,omitempty"`
+	BufferSize         int                       `mapstructure:"buffer-size,omitempty" json:"buffer-size,omitempty"`
+	NumWorkers         int                       `mapstructure:"num-workers,omitempty" json:"num-workers,omitempty"`
+	MaxRetries         *int                      `mapstructure:"max-retries,omitempty" json:"max-retries,omitempty"`
+	InitialBackoff     time.Duration             `mapstructure:"initial-backoff,omitempty" json:"initial-backoff,omitempty"`
+	MaxBackoff         time.Duration             `mapstructure:"max-backoff,omitempty" json:"max-backoff,omitempty"`
+	// action per status code ("503") or class ("5xx"): success, retry or drop
+	StatusCodes map[string]string `mapstructure:"status-codes,omitempty" json:"status-codes,omitempty"`
+	// per error class actions, replaces max-retries, backoffs and the status codes retry and drop actions if set
+	ErrorPolicy     *outputs.ErrorPolicy `mapstructure:"error-policy,omitempty" json:"error-policy,omitempty"`
+	AddTarget       string               `mapstructure:"add-target,omitempty" json:"add-target,omitempty"`
+	TargetTemplate  string               `mapstructure:"target-template,omitempty" json:"target-template,omitempty"`
+	EventProcessors []string             `mapstructure:"event-processors,omitempty" json:"event-processors,omitempty"`
+	EnableMetrics   bool                 `mapstructure:"enable-metrics,omitempty" json:"enable-metrics,omitempty"`
+	Debug           bool                 `mapstructure:"debug,omitempty" json:"debug,omitempty"`
+}
+
+type auth struct {
+	Username string `mapstructure:"username,omitempty" json:"username,omitempty"`
+	Password string `mapstructure:"password,omitempty" json:"password,omitempty"`
+}
+
+type authorization struct {
+	Type        string `mapstructure:"type,omitempty" json:"type,omitempty"`
+	Credentials string `mapstructure:"credentials,omitempty" json:"credentials,omitempty"`
+}
+
+func (h *httpOutput) Init(ctx context.Context, name string, cfg map[string]interface{}, opts ...outputs.Option) error {
+	err := outputs.DecodeConfig(cfg, h.cfg)
+	if err != nil {
+		return err
+	}
+	if h.cfg.Name == "" {
+		h.cfg.Name = name
+	}
+	h.logger.SetPrefix(fmt.Sprintf(loggingPrefix, h.cfg.Name))
+	for _, opt := range opts {
+		if err := opt(h); err != nil {
+			return err
+		}
+	}
+	err = h.setDefaults()
+	if err != nil {
+		return err
+	}
+	h.urlTpl, err = gtemplate.CreateTemplate("url", h.cfg.URL)
+	if err != nil {
+		return fmt.Errorf("failed to parse url template: %v", err)
+	}
+	h.urlTpl = h.urlTpl.Funcs(outputs.TemplateFuncs)
+	if h.cfg.BodyTemplate != "" {
+		h.bodyTpl, err = gtemplate.CreateTemplate("body-template", h.cfg.BodyTemplate)
+		if err != nil {
+			return fmt.Errorf("failed to parse body template: %v", err)
+		}
+		h.bodyTpl = h.bodyTpl.Funcs(outputs.TemplateFuncs)
+	}
+	if h.cfg.TargetTemplate == "" {
+		h.targetTpl = outputs.DefaultTargetTemplate
+	} else if h.cfg.AddTarget != "" {
+		h.targetTpl, err = gtemplate.CreateTemplate("target-template", h.cfg.TargetTemplate)
+		if err != nil {
+			return err
+		}
+		h.targetTpl = h.targetTpl.Funcs(outputs.TemplateFuncs)
+	}
+	h.actions, err = newStatusActions(h.cfg.StatusCodes)
+	if err != nil {
+		return err
+	}
+	h.errors, err = outputs.NewErrorHandler(h.cfg.Name, h.cfg.ErrorPolicy, h.logger)
+	if err != nil {
+		return err
+	}
+	if h.cfg.CredentialsCommand != nil {
+		if err := h.cfg.CredentialsCommand.Validate(); err != nil {
+			return err
+		}
+		h.credentials = types.NewCredentialsCache(h.cfg.CredentialsCommand, h.cfg.URL,
+			"GNMIC_OUTPUT_NAME="+h.cfg.Name,
+		)
+	}
+	err = h.createHTTPClient()
+	if err != nil {
+		return err
+	}
+
+	h.eventCh = make(chan *formatters.EventMsg, h.cfg.BufferSize)
+	ctx, h.cfn = context.WithCancel(ctx)
+	for i := 0; i < h.cfg.NumWorkers; i++ {
+		go h.worker(ctx)
+	}
+	h.logger.Printf("initialized http output: %s", h.String())
+	return nil
+}
+
+func (h *httpOutput) setDefaults() error {
+	if h.cfg.URL == "" {
+		return errors.New("missing url field")
+	}
+	if h.cfg.Method == "" {
+		h.cfg.Method = defaultMethod
+	}
+	h.cfg.Method = strings.ToUpper(h.cfg.Method)
+	if h.cfg.ContentType == "" {
+		h.cfg.ContentType = defaultContentType
+	}
+	if h.cfg.Timeout <= 0 {
+		h.cfg.Timeout = defaultTimeout
+	}
+	if h.cfg.BatchSize <= 0 {
+		h.cfg.BatchSize = defaultBatchSize
+	}
+	if h.cfg.FlushInterval <= 0 {
+		h.cfg.FlushInterval = defaultFlushInterval
+	}
+	if h.cfg.BufferSize <= 0 {
+		h.cfg.BufferSize = defaultBufferSize
+	}
+	if h.cfg.NumWorkers <= 0 {
+		h.cfg.NumWorkers = defaultNumWorkers
+	}
+	if h.cfg.MaxRetries == nil {
+		maxRetries := defaultMaxRetries
+		h.cfg.MaxRetries = &maxRetries
+	} else if *h.cfg.MaxRetries < 0 {
+		*h.cfg.MaxRetries = 0
+	}
+	if h.cfg.InitialBackoff <= 0 {
+		h.cfg.InitialBackoff = defaultInitialBackoff
+	}
+	if h.cfg.MaxBackoff <= 0 {
+		h.cfg.MaxBackoff = defaultMaxBackoff
+	}
+	if h.cfg.MaxBackoff < h.cfg.InitialBackoff {
+		h.cfg.MaxBackoff = h.cfg.InitialBackoff
+	}
+	return nil
+}
+
+func (h *httpOutput) Write(ctx context.Context, rsp proto.Message, meta outputs.Meta) {
+	if rsp == nil {
+		return
+	}
+	switch rsp := rsp.(type) {
+	case *gnmi.SubscribeResponse:
+		measName := "default"
+		if subName, ok := meta["subscription-name"]; ok {
+			measName = subName
+		}
+		var err error
+		rsp, err = outputs.AddSubscriptionTarget(rsp, meta, h.cfg.AddTarget, h.targetTpl)
+		if err != nil {
+			h.logger.Printf("failed to add target to the response: %v", err)
+		}
+		events, err := formatters.ResponseToEventMsgs(measName, rsp, meta, h.evps...)
+		if err != nil {
+			h.logger.Printf("failed to convert message to event: %v", err)
+			return
+		}
+		for _, ev := range events {
+			h.enqueue(ctx, ev)
+		}
+	}
+}
+
+func (h *httpOutput) WriteEvent(ctx context.Context, ev *formatters.EventMsg) {
+	if ev == nil {
+		return
+	}
+	evs := []*formatters.EventMsg{ev}
+	for _, proc := range h.evps {
+		evs = proc.Apply(evs...)
+	}
+	for _, pev := range evs {
+		h.enqueue(ctx, pev)
+	}
+}
+
+func (h *httpOutput) enqueue(ctx context.Context, ev *formatters.EventMsg) {
+	select {
+	case <-ctx.Done():
+	case h.eventCh <- ev:
+	default:
+		if h.cfg.Debug {
+			h.logger.Printf("buffer full, dropping event")
+		}
+		if h.cfg.EnableMetrics {
+			httpNumberOfFailedEvents.WithLabelValues(h.cfg.Name, "buffer_full").Inc()
+		}
+	}
+}
+
+// worker accumulates events and sends them when the batch size
+// or the flush interval is reached.
+func (h *httpOutput) worker(ctx context.Context) {
+	ticker := time.NewTicker(h.cfg.FlushInterval)
+	defer ticker.Stop()
+	batch := make([]*formatters.EventMsg, 0, h.cfg.BatchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev := <-h.eventCh:
+			batch = append(batch, ev)
+			if len(batch) < h.cfg.BatchSize {
+				continue
+			}
+			h.send(ctx, batch)
+			batch = make([]*formatters.EventMsg, 0, h.cfg.BatchSize)
+			ticker.Reset(h.cfg.FlushInterval)
+		case <-ticker.C:
+			if len(batch) == 0 {
+				continue
+			}
+			h.send(ctx, batch)
+			batch = make([]*formatters.EventMsg, 0, h.cfg.BatchSize)
+		}
+	}
+}
+
+func (h *httpOutput) Close() error {
+	if h.cfn != nil {
+		h.cfn()
+	}
+	return nil
+}
+
+// Healthy returns the error of the last failed batch,
+// nil if the last batch was delivered.
+func (h *httpOutput) Healthy() error {
+	return h.health.Get()
+}
+
+func (h *httpOutput) RegisterMetrics(reg *prometheus.Registry) {
+	if !h.cfg.EnableMetrics {
+		return
+	}
+	if reg == nil {
+		h.logger.Printf("ERR: output metrics enabled but main registry is not initialized, enable main metrics under `api-server`")
+		return
+	}
+	if err := registerMetrics(reg); err != nil {
+		h.logger.Printf("failed to register metrics: %v", err)
+	}
+}
+
+func (h *httpOutput) String() string {
+	b, err := json.Marshal(h.cfg)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func (h *httpOutput) SetLogger(logger *log.Logger) {
+	if logger != nil && h.logger != nil {
+		h.logger.SetOutput(logger.Writer())
+		h.logger.SetFlags(logger.Flags())
+	}
+}
+
+func (h *httpOutput) SetEventProcessors(ps map[string]map[string]interface{},
+	logger *log.Logger,
+	tcs map[string]*types.TargetConfig,
+	acts map[string]map[string]interface{}) error {
+	var err error
+	h.evps, err = formatters.MakeEventProcessors(
+		logger,
+		h.cfg.EventProcessors,
+		ps,
+		tcs,
+		acts,
+	)
+	return err
+}
+
+func (h *httpOutput) SetName(name string) {
+	if h.cfg.Name == "" {
+		h.cfg.Name = name
+	}
+}
+
+func (h *httpOutput) SetClusterName(string) {}
+
+func (h *httpOutput) SetTargetsConfig(map[string]*types.TargetConfig) {}
@@ -0,0 +1,260 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package http_output
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+func TestStatusActions(t *testing.T) {
+	sa, err := newStatusActions(map[string]string{"4xx": "retry", "404": "drop", "503": "success"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[int]action{
+		200: actionSuccess,
+		204: actionSuccess,
+		400: actionRetry,
+		404: actionDrop,
+		500: actionRetry,
+		503: actionSuccess,
+		301: actionDrop,
+	}
+	for code, expected := range tests {
+		if a := sa.action(code); a != expected {
+			t.Errorf("code %d: expected %q, got %q", code, expected, a)
+		}
+	}
+	for _, cfg := range []map[string]string{{"6xx": "drop"}, {"abc": "drop"}, {"500": "ignore"}} {
+		if _, err := newStatusActions(cfg); err == nil {
+			t.Errorf("expected an error for %v", cfg)
+		}
+	}
+}
+
+func TestHTTPOutput(t *testing.T) {
+	var m sync.Mutex
+	var attempts int
+	bodies := make(map[string][]string)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.Lock()
+		defer m.Unlock()
+		attempts++
+		// fail the first request to exercise the retries
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("X-Token") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		b, _ := io.ReadAll(r.Body)
+		bodies[r.URL.Path] = append(bodies[r.URL.Path], string(b))
+	}))
+	defer srv.Close()
+
+	o := outputs.Outputs[Type]()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := o.Init(ctx, "webhook", map[string]interface{}{
+		"url":             srv.URL + `/{{ index .tags "source" }}`,
+		"headers":         map[string]string{"X-Token": "secret"},
+		"body-template":   `{{ range . }}{{ .name }}={{ .timestamp }};{{ end }}`,
+		"batch-size":      2,
+		"flush-interval":  "1h",
+		"initial-backoff": "1ms",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	o.WriteEvent(ctx, &formatters.EventMsg{Name: "sub1", Timestamp: 1704164645123456789, Tags: map[string]string{"source": "r1"}})
+	o.WriteEvent(ctx, &formatters.EventMsg{Name: "sub2", Timestamp: 2, Tags: map[string]string{"source": "r2"}})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		m.Lock()
+		n := len(bodies)
+		m.Unlock()
+		if n == 2 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	m.Lock()
+	defer m.Unlock()
+	expected := map[string][]string{
+		"/r1": {"sub1=1704164645123456789;"},
+		"/r2": {"sub2=2;"},
+	}
+	b1, _ := json.Marshal(bodies)
+	b2, _ := json.Marshal(expected)
+	if string(b1) != string(b2) {
+		t.Fatalf("expected %s, got %s", b2, b1)
+	}
+	if err := o.(*httpOutput).Healthy(); err != nil {
+		t.Fatalf("expected the output to be healthy: %v", err)
+	}
+}
+
+func TestHTTPOutputCredentialsCommand(t *testing.T) {
+	var m sync.Mutex
+	var tokens []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.Lock()
+		defer m.Unlock()
+		tokens = append(tokens, r.Header.Get("Authorization"))
+		if r.Header.Get("Authorization") != "Bearer 2" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+
+	// the helper returns the number of times it ran as token
+	dir := t.TempDir()
+	script := filepath.Join(dir, "helper.sh")
+	err := os.WriteFile(script, []byte(`#!/bin/sh
+n=$(cat "`+dir+`/count" 2>/dev/null || echo 0)
+n=$((n+1))
+echo $n > "`+dir+`/count"
+echo "{\"token\":\"$n\"}"
+`), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o := outputs.Outputs[Type]()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err = o.Init(ctx, "webhook", map[string]interface{}{
+		"url":                 srv.URL,
+		"credentials-command": map[string]interface{}{"command": script},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the rejected token is renewed before the request is retried
+	if _, err := o.(*httpOutput).do(ctx, srv.URL, []byte("{}")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.(*httpOutput).do(ctx, srv.URL, []byte("{}")); err != nil {
+		t.Fatal(err)
+	}
+	m.Lock()
+	defer m.Unlock()
+	if len(tokens) != 3 || tokens[0] != "Bearer 1" || tokens[2] != "Bearer 2" {
+		t.Errorf("unexpected authorization headers: %v", tokens)
+	}
+}
+
+func TestHTTPOutputErrorPolicy(t *testing.T) {
+	var m sync.Mutex
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.Lock()
+		defer m.Unlock()
+		requests++
+		switch requests {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	o := outputs.Outputs[Type]()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := o.Init(ctx, "webhook", map[string]interface{}{
+		"url": srv.URL,
+		"error-policy": map[string]interface{}{
+			"permanent":       "alarm",
+			"initial-backoff": "1ms",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 503 is retried, 400 is dropped without retry
+	reason, err := o.(*httpOutput).do(ctx, srv.URL, []byte("{}"))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if reason != "error_class=permanent" {
+		t.Errorf("unexpected failure reason: %s", reason)
+	}
+	if _, err := o.(*httpOutput).do(ctx, srv.URL, []byte("{}")); err != nil {
+		t.Fatal(err)
+	}
+	m.Lock()
+	defer m.Unlock()
+	if requests != 3 {
+		t.Errorf("got %d requests, want 3", requests)
+	}
+}
+
+func TestHTTPOutputMaxRetries(t *testing.T) {
+	for name, tc := range map[string]struct {
+		maxRetries interface{}
+		requests   int
+	}{
+		"default":  {requests: defaultMaxRetries + 1},
+		"disabled": {maxRetries: 0, requests: 1},
+		"negative": {maxRetries: -1, requests: 1},
+		"one":      {maxRetries: 1, requests: 2},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var m sync.Mutex
+			var requests int
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				m.Lock()
+				defer m.Unlock()
+				requests++
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer srv.Close()
+
+			cfg := map[string]interface{}{
+				"url":             srv.URL,
+				"initial-backoff": "1ms",
+			}
+			if tc.maxRetries != nil {
+				cfg["max-retries"] = tc.maxRetries
+			}
+			o := outputs.Outputs[Type]()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			err := o.Init(ctx, "webhook", cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := o.(*httpOutput).do(ctx, srv.URL, []byte("{}")); err == nil {
+				t.Fatal("expected an error")
+			}
+			m.Lock()
+			defer m.Unlock()
+			if requests != tc.requests {
+				t.Errorf("got %d requests, want %d", requests, tc.requests)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package influxdb_output
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var influxdbRetriedPoints = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "influxdb_output",
+	Name:      "retried_points_total",
+	Help:      "Number of points gnmic influxdb output scheduled for a write retry",
+}, []string{"name"})
+
+var influxdbSpilledPoints = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "influxdb_output",
+	Name:      "spilled_points_total",
+	Help:      "Number of points gnmic influxdb output spilled to disk after exhausting write retries",
+}, []string{"name"})
+
+var influxdbReplayedPoints = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "influxdb_output",
+	Name:      "replayed_points_total",
+	Help:      "Number of spilled points gnmic influxdb output successfully replayed",
+}, []string{"name"})
+
+var influxdbDroppedPoints = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "influxdb_output",
+	Name:      "dropped_points_total",
+	Help:      "Number of points gnmic influxdb output dropped after exhausting write retries",
+}, []string{"name", "reason"})
+
+func registerMetrics(reg *prometheus.Registry) error {
+	for _, c := range []prometheus.Collector{influxdbRetriedPoints, influxdbSpilledPoints, influxdbReplayedPoints, influxdbDroppedPoints} {
+		if err := reg.Register(c); err != nil {
+			var are prometheus.AlreadyRegisteredError
+			// multiple influxdb outputs share the same collectors
+			if errors.As(err, &are) {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -23,6 +23,7 @@ import (
 	"google.golang.org/protobuf/proto"
 
 	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
+	http2 "github.com/influxdata/influxdb-client-go/v2/api/http"
 	"github.com/openconfig/gnmi/proto/gnmi"
 	"github.com/prometheus/client_golang/prometheus"
 
@@ -41,6 +42,13 @@ const (
 	minHealthCheckPeriod   = 30 * time.Second
 	defaultCacheFlushTimer = 5 * time.Second
 
+	defaultMaxRetries       = 5
+	defaultRetryInterval    = 5 * time.Second
+	defaultMaxRetryInterval = 125 * time.Second
+	defaultMaxRetryTime     = 3 * time.Minute
+	defaultExponentialBase  = 2
+	defaultRetryBufferLimit = 50000
+
 	numWorkers     = 1
 	loggingPrefix  = "[influxdb_output:%s] "
 	deleteTagValue = "true"
@@ -53,51 +61,67 @@ func init() {
 			eventChan: make(chan *formatters.EventMsg),
 			reset:     make(chan struct{}),
 			startSig:  make(chan struct{}),
+			flushReq:  make(chan chan struct{}),
 			logger:    log.New(io.Discard, loggingPrefix, utils.DefaultLoggingFlags),
 		}
 	})
 }
 
 type influxDBOutput struct {
-	Cfg       *Config
-	client    influxdb2.Client
-	logger    *log.Logger
-	cancelFn  context.CancelFunc
-	eventChan chan *formatters.EventMsg
-	reset     chan struct{}
-	startSig  chan struct{}
-	wasUP     bool
-	evps      []formatters.EventProcessor
-	dbVersion string
+	Cfg        *Config
+	name       string
+	client     influxdb2.Client
+	logger     *log.Logger
+	cancelFn   context.CancelFunc
+	eventChan  chan *formatters.EventMsg
+	reset      chan struct{}
+	startSig   chan struct{}
+	flushReq   chan chan struct{}
+	wasUP      bool
+	lastHealth outputs.Health
+	evps       []formatters.EventProcessor
+	dbVersion  string
 
 	targetTpl *template.Template
 
 	gnmiCache   cache.Cache
 	cacheTicker *time.Ticker
 	done        chan struct{}
+
+	spill *spiller
 }
 
 type Config struct {
-	URL                string           `mapstructure:"url,omitempty"`
-	Org                string           `mapstructure:"org,omitempty"`
-	Bucket             string           `mapstructure:"bucket,omitempty"`
-	Token              string           `mapstructure:"token,omitempty"`
-	BatchSize          uint             `mapstructure:"batch-size,omitempty"`
-	FlushTimer         time.Duration    `mapstructure:"flush-timer,omitempty"`
-	UseGzip            bool             `mapstructure:"use-gzip,omitempty"`
-	EnableTLS          bool             `mapstructure:"enable-tls,omitempty"`
-	TLS                *types.TLSConfig `mapstructure:"tls,omitempty" json:"tls,omitempty"`
-	HealthCheckPeriod  time.Duration    `mapstructure:"health-check-period,omitempty"`
-	Debug              bool             `mapstructure:"debug,omitempty"`
-	AddTarget          string           `mapstructure:"add-target,omitempty"`
-	TargetTemplate     string           `mapstructure:"target-template,omitempty"`
-	EventProcessors    []string         `mapstructure:"event-processors,omitempty"`
-	EnableMetrics      bool             `mapstructure:"enable-metrics,omitempty"`
-	OverrideTimestamps bool             `mapstructure:"override-timestamps,omitempty"`
-	TimestampPrecision string           `mapstructure:"timestamp-precision,omitempty"`
-	CacheConfig        *cache.Config    `mapstructure:"cache,omitempty"`
-	CacheFlushTimer    time.Duration    `mapstructure:"cache-flush-timer,omitempty"`
-	DeleteTag          string           `mapstructure:"delete-tag,omitempty"`
+	URL    string `mapstructure:"url,omitempty"`
+	Org    string `mapstructure:"org,omitempty"`
+	Bucket string `mapstructure:"bucket,omitempty"`
+	Token  string `mapstructure:"token,omitempty"`
+	// external command returning the token
+	CredentialsCommand *types.CredentialsCommand `mapstructure:"credentials-command,omitempty"`
+	BatchSize          uint                      `mapstructure:"batch-size,omitempty"`
+	FlushTimer         time.Duration             `mapstructure:"flush-timer,omitempty"`
+	UseGzip            bool                      `mapstructure:"use-gzip,omitempty"`
+	EnableTLS          bool                      `mapstructure:"enable-tls,omitempty"`
+	TLS                *types.TLSConfig          `mapstructure:"tls,omitempty" json:"tls,omitempty"`
+	HealthCheckPeriod  time.Duration             `mapstructure:"health-check-period,omitempty"`
+	Debug              bool                      `mapstructure:"debug,omitempty"`
+	AddTarget          string                    `mapstructure:"add-target,omitempty"`
+	TargetTemplate     string                    `mapstructure:"target-template,omitempty"`
+	EventProcessors    []string                  `mapstructure:"event-processors,omitempty"`
+	EnableMetrics      bool                      `mapstructure:"enable-metrics,omitempty"`
+	OverrideTimestamps bool                      `mapstructure:"override-timestamps,omitempty"`
+	TimestampPrecision string                    `mapstructure:"timestamp-precision,omitempty"`
+	CacheConfig        *cache.Config             `mapstructure:"cache,omitempty"`
+	CacheFlushTimer    time.Duration             `mapstructure:"cache-flush-timer,omitempty"`
+	DeleteTag          string                    `mapstructure:"delete-tag,omitempty"`
+
+	MaxRetries       uint          `mapstructure:"max-retries,omitempty"`
+	RetryInterval    time.Duration `mapstructure:"retry-interval,omitempty"`
+	MaxRetryInterval time.Duration `mapstructure:"max-retry-interval,omitempty"`
+	MaxRetryTime     time.Duration `mapstructure:"max-retry-time,omitempty"`
+	ExponentialBase  uint          `mapstructure:"exponential-base,omitempty"`
+	RetryBufferLimit uint          `mapstructure:"retry-buffer-limit,omitempty"`
+	Spill            *spillConfig  `mapstructure:"spill,omitempty" json:"spill,omitempty"`
 }
 
 func (k *influxDBOutput) String() string {
@@ -138,6 +162,7 @@ func (i *influxDBOutput) Init(ctx context.Context, name string, cfg map[string]i
 	if err != nil {
 		return err
 	}
+	i.name = name
 	i.logger.SetPrefix(fmt.Sprintf(loggingPrefix, name))
 
 	for _, opt := range opts {
@@ -147,6 +172,13 @@ func (i *influxDBOutput) Init(ctx context.Context, name string, cfg map[string]i
 	}
 	i.setDefaults()
 
+	if i.Cfg.Spill != nil {
+		i.spill, err = newSpiller(i.Cfg.Spill)
+		if err != nil {
+			return fmt.Errorf("failed to initialize spill directory: %w", err)
+		}
+	}
+
 	if i.Cfg.CacheConfig != nil {
 		err = i.initCache(ctx, name)
 		if err != nil {
@@ -169,13 +201,34 @@ func (i *influxDBOutput) Init(ctx context.Context, name string, cfg map[string]i
 	if err != nil {
 		return err
 	}
+	var credentials *types.CredentialsCache
+	if i.Cfg.CredentialsCommand != nil {
+		if err := i.Cfg.CredentialsCommand.Validate(); err != nil {
+			return err
+		}
+		credentials = types.NewCredentialsCache(i.Cfg.CredentialsCommand, i.Cfg.URL, "GNMIC_OUTPUT_NAME="+name)
+	}
 CRCLIENT:
-	i.client = influxdb2.NewClientWithOptions(i.Cfg.URL, i.Cfg.Token, influxOpts)
+	token := i.Cfg.Token
+	if credentials != nil {
+		creds, err := credentials.Get(ctx)
+		if err != nil {
+			i.logger.Printf("failed to get influxdb token: %v", err)
+			time.Sleep(10 * time.Second)
+			goto CRCLIENT
+		}
+		token = creds.Token
+	}
+	i.client = influxdb2.NewClientWithOptions(i.Cfg.URL, token, influxOpts)
 	// start influx health check
 	if i.Cfg.HealthCheckPeriod > 0 {
 		err = i.health(ctx)
 		if err != nil {
 			i.logger.Printf("failed to check influxdb health: %v", err)
+			if credentials != nil {
+				// get a new token from the credentials-command
+				credentials.Invalidate()
+			}
 			time.Sleep(10 * time.Second)
 			goto CRCLIENT
 		}
@@ -187,6 +240,9 @@ CRCLIENT:
 	for k := 0; k < numWorkers; k++ {
 		go i.worker(ctx, k)
 	}
+	if i.spill != nil {
+		go i.replayLoop(ctx)
+	}
 	go func() {
 		<-ctx.Done()
 		i.Close()
@@ -212,6 +268,34 @@ func (i *influxDBOutput) setDefaults() {
 			i.Cfg.CacheFlushTimer = defaultCacheFlushTimer
 		}
 	}
+	if i.Cfg.MaxRetries == 0 {
+		i.Cfg.MaxRetries = defaultMaxRetries
+	}
+	if i.Cfg.RetryInterval <= 0 {
+		i.Cfg.RetryInterval = defaultRetryInterval
+	}
+	if i.Cfg.MaxRetryInterval <= 0 {
+		i.Cfg.MaxRetryInterval = defaultMaxRetryInterval
+	}
+	if i.Cfg.MaxRetryInterval < i.Cfg.RetryInterval {
+		i.Cfg.MaxRetryInterval = i.Cfg.RetryInterval
+	}
+	if i.Cfg.MaxRetryTime <= 0 {
+		i.Cfg.MaxRetryTime = defaultMaxRetryTime
+	}
+	if i.Cfg.ExponentialBase < 2 {
+		i.Cfg.ExponentialBase = defaultExponentialBase
+	}
+	if i.Cfg.RetryBufferLimit == 0 {
+		i.Cfg.RetryBufferLimit = defaultRetryBufferLimit
+	}
+	// the client keeps RetryBufferLimit/BatchSize batches for retry
+	if i.Cfg.RetryBufferLimit < i.Cfg.BatchSize {
+		i.Cfg.RetryBufferLimit = i.Cfg.BatchSize
+	}
+	if i.Cfg.Spill != nil {
+		i.Cfg.Spill.setDefaults()
+	}
 }
 
 func (i *influxDBOutput) Write(ctx context.Context, rsp proto.Message, meta outputs.Meta) {
@@ -267,6 +351,36 @@ func (i *influxDBOutput) WriteEvent(ctx context.Context, ev *formatters.EventMsg
 	}
 }
 
+// Drain writes the cached updates and the events buffered by the output processors,
+// then flushes the client write buffer.
+func (i *influxDBOutput) Drain(ctx context.Context) (int, error) {
+	if i.gnmiCache != nil {
+		i.readCache(ctx, i.name)
+	}
+	evs := formatters.FlushEventProcessors(i.evps)
+	for n, ev := range evs {
+		select {
+		case <-ctx.Done():
+			return len(evs) - n, ctx.Err()
+		case i.eventChan <- ev:
+		}
+	}
+	// the flush is done by the worker,
+	// after it wrote the events received so far.
+	done := make(chan struct{})
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case i.flushReq <- done:
+	}
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case <-done:
+		return 0, nil
+	}
+}
+
 func (i *influxDBOutput) Close() error {
 	i.logger.Printf("closing client...")
 	if i.Cfg.CacheConfig != nil {
@@ -276,7 +390,23 @@ func (i *influxDBOutput) Close() error {
 	i.logger.Printf("closed.")
 	return nil
 }
-func (i *influxDBOutput) RegisterMetrics(reg *prometheus.Registry) {}
+
+func (i *influxDBOutput) RegisterMetrics(reg *prometheus.Registry) {
+	if !i.Cfg.EnableMetrics {
+		return
+	}
+	if reg == nil {
+		i.logger.Printf("ERR: output metrics enabled but main registry is not initialized, enable main metrics under `api-server`")
+		return
+	}
+	if err := registerMetrics(reg); err != nil {
+		i.logger.Printf("failed to register metrics: %v", err)
+	}
+}
+
+// Healthy returns the error of the last health check,
+// health checks are only run if health-check-period is set.
+func (i *influxDBOutput) Healthy() error { return i.lastHealth.Get() }
 
 func (i *influxDBOutput) healthCheck(ctx context.Context) {
 	ticker := time.NewTicker(i.Cfg.HealthCheckPeriod)
@@ -292,6 +422,7 @@ func (i *influxDBOutput) healthCheck(ctx context.Context) {
 
 func (i *influxDBOutput) health(ctx context.Context) error {
 	res, err := i.client.Health(ctx)
+	i.lastHealth.Set(err)
 	if err != nil {
 		i.logger.Printf("failed health check: %v", err)
 		if i.wasUP {
@@ -336,6 +467,7 @@ START:
 	}
 	i.logger.Printf("starting worker-%d", idx)
 	writer := i.client.WriteAPI(i.Cfg.Org, i.Cfg.Bucket)
+	writer.SetWriteFailedCallback(i.writeFailed)
 	//defer writer.Flush()
 	for {
 		select {
@@ -368,7 +500,7 @@ START:
 				i.convertUints(ev)
 				writer.WritePoint(influxdb2.NewPoint(ev.Name, ev.Tags, ev.Values, time.Unix(0, ev.Timestamp)))
 			}
-			
+
 			if len(ev.Deletes) > 0 && i.Cfg.DeleteTag != "" {
 				tags := make(map[string]string, len(ev.Tags))
 				for k, v := range ev.Tags {
@@ -387,6 +519,9 @@ START:
 			goto START
 		case err := <-writer.Errors():
 			i.logger.Printf("worker-%d write error: %v", idx, err)
+		case done := <-i.flushReq:
+			writer.Flush()
+			close(done)
 		}
 	}
 }
@@ -419,7 +554,13 @@ func (i *influxDBOutput) clientOpts() (*influxdb2.Options, error) {
 	iopts := influxdb2.DefaultOptions().
 		SetUseGZip(i.Cfg.UseGzip).
 		SetBatchSize(i.Cfg.BatchSize).
-		SetFlushInterval(uint(i.Cfg.FlushTimer.Milliseconds()))
+		SetFlushInterval(uint(i.Cfg.FlushTimer.Milliseconds())).
+		SetMaxRetries(i.Cfg.MaxRetries).
+		SetRetryInterval(uint(i.Cfg.RetryInterval.Milliseconds())).
+		SetMaxRetryInterval(uint(i.Cfg.MaxRetryInterval.Milliseconds())).
+		SetMaxRetryTime(uint(i.Cfg.MaxRetryTime.Milliseconds())).
+		SetExponentialBase(i.Cfg.ExponentialBase).
+		SetRetryBufferLimit(i.Cfg.RetryBufferLimit)
 	if i.Cfg.TLS != nil {
 		tlsConfig, err := utils.NewTLSConfig(
 			i.Cfg.TLS.CaFile, i.Cfg.TLS.CertFile, i.Cfg.TLS.KeyFile, "", i.Cfg.TLS.SkipVerify,
@@ -447,3 +588,58 @@ func (i *influxDBOutput) clientOpts() (*influxdb2.Options, error) {
 	}
 	return iopts, nil
 }
+
+// writeFailed is called by the client's write API each time a batch write fails
+// with a retryable error. It keeps the batch for retrying until max-retries is reached,
+// after which the batch is spilled to disk if configured, or dropped.
+func (i *influxDBOutput) writeFailed(batch string, err http2.Error, retryAttempts uint) bool {
+	numPoints := float64(countPoints(batch))
+	if retryAttempts < i.Cfg.MaxRetries {
+		influxdbRetriedPoints.WithLabelValues(i.name).Add(numPoints)
+		return true
+	}
+	if i.spill == nil {
+		i.logger.Printf("dropping batch of %.0f point(s) after %d retries: %v", numPoints, retryAttempts, err.Error())
+		influxdbDroppedPoints.WithLabelValues(i.name, "max-retries").Add(numPoints)
+		return false
+	}
+	if serr := i.spill.spill(batch); serr != nil {
+		i.logger.Printf("failed to spill batch of %.0f point(s): %v", numPoints, serr)
+		influxdbDroppedPoints.WithLabelValues(i.name, "spill-failed").Add(numPoints)
+		return false
+	}
+	if i.Cfg.Debug {
+		i.logger.Printf("spilled batch of %.0f point(s) after %d retries: %v", numPoints, retryAttempts, err.Error())
+	}
+	influxdbSpilledPoints.WithLabelValues(i.name).Add(numPoints)
+	return false
+}
+
+// replayLoop periodically writes the spilled batches back to the server.
+// If health checks are enabled, replay is skipped while the server is unhealthy.
+func (i *influxDBOutput) replayLoop(ctx context.Context) {
+	ticker := time.NewTicker(i.Cfg.Spill.ReplayInterval)
+	defer ticker.Stop()
+	writer := i.client.WriteAPIBlocking(i.Cfg.Org, i.Cfg.Bucket)
+	write := func(ctx context.Context, batch string) error {
+		return writer.WriteRecord(ctx, batch)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if i.Cfg.HealthCheckPeriod > 0 && i.Healthy() != nil {
+				continue
+			}
+			n, err := i.spill.replay(ctx, write)
+			if n > 0 {
+				i.logger.Printf("replayed %d spilled point(s)", n)
+				influxdbReplayedPoints.WithLabelValues(i.name).Add(float64(n))
+			}
+			if err != nil && ctx.Err() == nil {
+				i.logger.Printf("failed to replay spilled batches: %v", err)
+			}
+		}
+	}
+}
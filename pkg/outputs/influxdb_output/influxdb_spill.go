@@ -0,0 +1,163 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package influxdb_output
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultSpillMaxSize        = 100 * 1024 * 1024 // 100MiB
+	defaultSpillReplayInterval = 30 * time.Second
+
+	spillFileSuffix = ".lp"
+	spillTmpSuffix  = ".tmp"
+)
+
+var errSpillFull = errors.New("spill directory max size reached")
+
+// spillConfig configures the on-disk storage of batches
+// that could not be written after exhausting the write retries.
+type spillConfig struct {
+	Directory      string        `mapstructure:"directory,omitempty" json:"directory,omitempty"`
+	MaxSize        int64         `mapstructure:"max-size,omitempty" json:"max-size,omitempty"`
+	ReplayInterval time.Duration `mapstructure:"replay-interval,omitempty" json:"replay-interval,omitempty"`
+}
+
+func (c *spillConfig) setDefaults() {
+	if c.MaxSize <= 0 {
+		c.MaxSize = defaultSpillMaxSize
+	}
+	if c.ReplayInterval <= 0 {
+		c.ReplayInterval = defaultSpillReplayInterval
+	}
+}
+
+// spiller stores failed batches as line protocol files,
+// one file per batch, named so that lexical order is write order.
+type spiller struct {
+	cfg *spillConfig
+
+	m    sync.Mutex
+	size int64
+	seq  uint64
+}
+
+func newSpiller(cfg *spillConfig) (*spiller, error) {
+	if cfg.Directory == "" {
+		return nil, errors.New("spill directory is not set")
+	}
+	if err := os.MkdirAll(cfg.Directory, 0o750); err != nil {
+		return nil, err
+	}
+	s := &spiller{cfg: cfg}
+	files, err := s.files()
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		fi, err := os.Stat(f)
+		if err != nil {
+			continue
+		}
+		s.size += fi.Size()
+	}
+	return s, nil
+}
+
+// spill writes the batch to a new file in the spill directory.
+// The file is written under a temporary name first and renamed once complete
+// so that a replay never picks up a partially written batch.
+func (s *spiller) spill(batch string) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.size+int64(len(batch)) > s.cfg.MaxSize {
+		return errSpillFull
+	}
+	s.seq++
+	name := filepath.Join(s.cfg.Directory, fmt.Sprintf("%020d-%06d%s", time.Now().UnixNano(), s.seq, spillFileSuffix))
+	tmp := name + spillTmpSuffix
+	if err := os.WriteFile(tmp, []byte(batch), 0o640); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, name); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	s.size += int64(len(batch))
+	return nil
+}
+
+// files returns the spilled batch files, oldest first.
+func (s *spiller) files() ([]string, error) {
+	entries, err := os.ReadDir(s.cfg.Directory)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), spillFileSuffix) {
+			continue
+		}
+		files = append(files, filepath.Join(s.cfg.Directory, e.Name()))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// replay writes the spilled batches using the write function, oldest first.
+// A successfully written batch file is removed, replay stops at the first failure.
+// It returns the number of replayed points.
+func (s *spiller) replay(ctx context.Context, write func(context.Context, string) error) (int, error) {
+	files, err := s.files()
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	for _, f := range files {
+		if ctx.Err() != nil {
+			return n, ctx.Err()
+		}
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return n, err
+		}
+		if err := write(ctx, string(b)); err != nil {
+			return n, err
+		}
+		if err := os.Remove(f); err != nil {
+			return n, err
+		}
+		s.m.Lock()
+		s.size -= int64(len(b))
+		s.m.Unlock()
+		n += countPoints(string(b))
+	}
+	return n, nil
+}
+
+// countPoints returns the number of points in a line protocol batch.
+func countPoints(batch string) int {
+	var n int
+	for _, l := range strings.Split(batch, "\n") {
+		if strings.TrimSpace(l) != "" {
+			n++
+		}
+	}
+	return n
+}
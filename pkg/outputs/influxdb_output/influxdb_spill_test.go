@@ -0,0 +1,153 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package influxdb_output
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"testing"
+
+	http2 "github.com/influxdata/influxdb-client-go/v2/api/http"
+)
+
+func TestCountPoints(t *testing.T) {
+	tests := map[string]int{
+		"":                       0,
+		"m1 v=1 1\n":             1,
+		"m1 v=1 1\nm2 v=2 2":     2,
+		"m1 v=1 1\n\nm2 v=2 2\n": 2,
+	}
+	for batch, want := range tests {
+		if got := countPoints(batch); got != want {
+			t.Errorf("countPoints(%q) = %d, want %d", batch, got, want)
+		}
+	}
+}
+
+func TestSpillerReplayOrder(t *testing.T) {
+	s, err := newSpiller(&spillConfig{Directory: t.TempDir(), MaxSize: 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	batches := []string{"m v=1 1\nm v=2 2", "m v=3 3", "m v=4 4"}
+	for _, b := range batches {
+		if err := s.spill(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var got []string
+	n, err := s.replay(context.TODO(), func(_ context.Context, b string) error {
+		got = append(got, b)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 4 {
+		t.Errorf("replayed %d points, want 4", n)
+	}
+	if len(got) != len(batches) {
+		t.Fatalf("replayed %d batches, want %d", len(got), len(batches))
+	}
+	for idx := range batches {
+		if got[idx] != batches[idx] {
+			t.Errorf("batch %d: got %q, want %q", idx, got[idx], batches[idx])
+		}
+	}
+	files, _ := s.files()
+	if len(files) != 0 || s.size != 0 {
+		t.Errorf("expected empty spill directory, got %d files, size %d", len(files), s.size)
+	}
+}
+
+func TestSpillerReplayStopsOnError(t *testing.T) {
+	s, err := newSpiller(&spillConfig{Directory: t.TempDir(), MaxSize: 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range []string{"m v=1 1", "m v=2 2"} {
+		if err := s.spill(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	calls := 0
+	n, err := s.replay(context.TODO(), func(context.Context, string) error {
+		calls++
+		if calls == 2 {
+			return errors.New("unavailable")
+		}
+		return nil
+	})
+	if err == nil {
+		t.Fatal("expected replay error")
+	}
+	if n != 1 {
+		t.Errorf("replayed %d points, want 1", n)
+	}
+	files, _ := s.files()
+	if len(files) != 1 {
+		t.Errorf("expected 1 remaining file, got %d", len(files))
+	}
+}
+
+func TestSpillerMaxSize(t *testing.T) {
+	dir := t.TempDir()
+	s, err := newSpiller(&spillConfig{Directory: dir, MaxSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.spill("m v=1 1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.spill("m v=2 2"); !errors.Is(err, errSpillFull) {
+		t.Fatalf("expected errSpillFull, got %v", err)
+	}
+	// a new spiller picks up the existing directory size
+	s2, err := newSpiller(&spillConfig{Directory: dir, MaxSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s2.size != 7 {
+		t.Errorf("expected size 7, got %d", s2.size)
+	}
+}
+
+func TestWriteFailed(t *testing.T) {
+	dir := t.TempDir()
+	s, err := newSpiller(&spillConfig{Directory: dir, MaxSize: 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := &influxDBOutput{
+		Cfg:    &Config{MaxRetries: 2},
+		name:   "test",
+		logger: log.New(io.Discard, "", 0),
+		spill:  s,
+	}
+	perr := http2.Error{StatusCode: 503, Message: "unavailable"}
+	if !i.writeFailed("m v=1 1", perr, 0) {
+		t.Error("expected batch to be retried on attempt 0")
+	}
+	if !i.writeFailed("m v=1 1", perr, 1) {
+		t.Error("expected batch to be retried on attempt 1")
+	}
+	if i.writeFailed("m v=1 1", perr, 2) {
+		t.Error("expected batch to be discarded after max retries")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 spilled file, got %d", len(entries))
+	}
+}
@@ -0,0 +1,94 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kafka_output
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/IBM/sarama"
+)
+
+// TraceparentInterceptor is the name of the interceptor adding
+// a W3C trace context `traceparent` header to each message.
+const TraceparentInterceptor = "traceparent"
+
+var (
+	interceptorsMu sync.RWMutex
+	interceptors   = map[string]func() sarama.ProducerInterceptor{
+		TraceparentInterceptor: func() sarama.ProducerInterceptor { return traceparent{} },
+	}
+)
+
+// RegisterInterceptor makes a producer interceptor available to the
+// kafka outputs `interceptors` under name, e.g to add tracing headers.
+func RegisterInterceptor(name string, fn func() sarama.ProducerInterceptor) {
+	interceptorsMu.Lock()
+	defer interceptorsMu.Unlock()
+	interceptors[name] = fn
+}
+
+// producerInterceptors returns the interceptors configured for the output,
+// the static headers interceptor runs first.
+func (k *kafkaOutput) producerInterceptors() ([]sarama.ProducerInterceptor, error) {
+	res := make([]sarama.ProducerInterceptor, 0, len(k.cfg.Interceptors)+1)
+	if len(k.cfg.Headers) > 0 {
+		res = append(res, newHeaders(k.cfg.Headers))
+	}
+	interceptorsMu.RLock()
+	defer interceptorsMu.RUnlock()
+	for _, name := range k.cfg.Interceptors {
+		fn, ok := interceptors[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown interceptor %q", name)
+		}
+		res = append(res, fn())
+	}
+	return res, nil
+}
+
+// headers adds static headers to the messages.
+type headers []sarama.RecordHeader
+
+func newHeaders(m map[string]string) headers {
+	names := make([]string, 0, len(m))
+	for n := range m {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	h := make(headers, 0, len(m))
+	for _, n := range names {
+		h = append(h, sarama.RecordHeader{Key: []byte(n), Value: []byte(m[n])})
+	}
+	return h
+}
+
+func (h headers) OnSend(msg *sarama.ProducerMessage) {
+	msg.Headers = append(msg.Headers, h...)
+}
+
+// traceparent starts a new sampled trace per message.
+type traceparent struct{}
+
+func (traceparent) OnSend(msg *sarama.ProducerMessage) {
+	for _, h := range msg.Headers {
+		if string(h.Key) == "traceparent" {
+			return
+		}
+	}
+	ids := make([]byte, 24)
+	if _, err := rand.Read(ids); err != nil {
+		return
+	}
+	v := "00-" + hex.EncodeToString(ids[:16]) + "-" + hex.EncodeToString(ids[16:]) + "-01"
+	msg.Headers = append(msg.Headers, sarama.RecordHeader{Key: []byte("traceparent"), Value: []byte(v)})
+}
@@ -0,0 +1,86 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kafka_output
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+type markInterceptor struct{}
+
+func (markInterceptor) OnSend(msg *sarama.ProducerMessage) {
+	msg.Headers = append(msg.Headers, sarama.RecordHeader{Key: []byte("mark"), Value: []byte("1")})
+}
+
+func TestProducerConfig(t *testing.T) {
+	RegisterInterceptor("test-mark", func() sarama.ProducerInterceptor { return markInterceptor{} })
+	level := 3
+	k := &kafkaOutput{cfg: &config{
+		Name:             "k1",
+		CompressionCodec: "zstd",
+		CompressionLevel: &level,
+		FlushBytes:       1 << 20,
+		FlushMessages:    1000,
+		MaxMessageBytes:  4 << 20,
+		Headers:          map[string]string{"env": "prod", "dc": "dc1"},
+		Interceptors:     []string{TraceparentInterceptor, "test-mark"},
+	}}
+	if err := k.setDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := k.createConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Producer.Compression != sarama.CompressionZSTD || cfg.Producer.CompressionLevel != 3 {
+		t.Errorf("unexpected compression: %v, level %d", cfg.Producer.Compression, cfg.Producer.CompressionLevel)
+	}
+	if cfg.Producer.Flush.Bytes != 1<<20 || cfg.Producer.Flush.Messages != 1000 || cfg.Producer.MaxMessageBytes != 4<<20 {
+		t.Errorf("unexpected flush config: %+v, max message bytes %d", cfg.Producer.Flush, cfg.Producer.MaxMessageBytes)
+	}
+	if len(cfg.Producer.Interceptors) != 3 {
+		t.Fatalf("expected 3 interceptors, got %d", len(cfg.Producer.Interceptors))
+	}
+	msg := &sarama.ProducerMessage{Topic: "telemetry"}
+	for _, i := range cfg.Producer.Interceptors {
+		i.OnSend(msg)
+	}
+	var keys []string
+	for _, h := range msg.Headers {
+		keys = append(keys, string(h.Key))
+	}
+	if len(keys) != 4 || keys[0] != "dc" || keys[1] != "env" || keys[2] != "traceparent" || keys[3] != "mark" {
+		t.Errorf("unexpected headers: %v", keys)
+	}
+	if !regexp.MustCompile(`^00-[0-9a-f]{32}-[0-9a-f]{16}-01$`).Match(msg.Headers[2].Value) {
+		t.Errorf("invalid traceparent %q", msg.Headers[2].Value)
+	}
+	// an existing traceparent is kept.
+	traceparent{}.OnSend(msg)
+	if len(msg.Headers) != 4 {
+		t.Errorf("unexpected headers count %d", len(msg.Headers))
+	}
+}
+
+func TestProducerConfigErrors(t *testing.T) {
+	k := &kafkaOutput{cfg: &config{CompressionCodec: "brotli"}}
+	if err := k.setDefaults(); err == nil {
+		t.Error("expected an error for an unknown compression codec")
+	}
+	k = &kafkaOutput{cfg: &config{Interceptors: []string{"unknown"}}}
+	if err := k.setDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := k.createConfig(); err == nil {
+		t.Error("expected an error for an unknown interceptor")
+	}
+}
@@ -68,9 +68,14 @@ type kafkaOutput struct {
 	msgChan  chan *outputs.ProtoMsg
 	wg       *sync.WaitGroup
 	evps     []formatters.EventProcessor
+	health   outputs.Health
 
 	targetTpl *template.Template
 	msgTpl    *template.Template
+	topicTpl  *template.Template
+	keyTpl    *template.Template
+
+	topicAdmin *topicAdmin
 }
 
 // config //
@@ -78,6 +83,9 @@ type config struct {
 	Address            string           `mapstructure:"address,omitempty"`
 	Topic              string           `mapstructure:"topic,omitempty"`
 	TopicPrefix        string           `mapstructure:"topic-prefix,omitempty"`
+	TopicTemplate      string           `mapstructure:"topic-template,omitempty"`
+	KeyTemplate        string           `mapstructure:"key-template,omitempty"`
+	CreateTopics       *topicCreation   `mapstructure:"create-topics,omitempty"`
 	Name               string           `mapstructure:"name,omitempty"`
 	SASL               *types.SASL      `mapstructure:"sasl,omitempty"`
 	TLS                *types.TLSConfig `mapstructure:"tls,omitempty"`
@@ -88,6 +96,8 @@ type config struct {
 	SyncProducer       bool             `mapstructure:"sync-producer,omitempty"`
 	RequiredAcks       string           `mapstructure:"required-acks,omitempty"`
 	Format             string           `mapstructure:"format,omitempty"`
+	EventEnvelope      bool             `mapstructure:"event-envelope,omitempty"`
+	EventSchemaURL     string           `mapstructure:"event-schema-url,omitempty"`
 	InsertKey          bool             `mapstructure:"insert-key,omitempty"`
 	AddTarget          string           `mapstructure:"add-target,omitempty"`
 	TargetTemplate     string           `mapstructure:"target-template,omitempty"`
@@ -95,12 +105,22 @@ type config struct {
 	SplitEvents        bool             `mapstructure:"split-events,omitempty"`
 	NumWorkers         int              `mapstructure:"num-workers,omitempty"`
 	CompressionCodec   string           `mapstructure:"compression-codec,omitempty"`
+	CompressionLevel   *int             `mapstructure:"compression-level,omitempty"`
+	FlushBytes         int              `mapstructure:"flush-bytes,omitempty"`
+	FlushMessages      int              `mapstructure:"flush-messages,omitempty"`
+	FlushMaxMessages   int              `mapstructure:"flush-max-messages,omitempty"`
+	MaxMessageBytes    int              `mapstructure:"max-message-bytes,omitempty"`
 	KafkaVersion       string           `mapstructure:"kafka-version,omitempty"`
 	Debug              bool             `mapstructure:"debug,omitempty"`
 	BufferSize         int              `mapstructure:"buffer-size,omitempty"`
 	OverrideTimestamps bool             `mapstructure:"override-timestamps,omitempty"`
 	EnableMetrics      bool             `mapstructure:"enable-metrics,omitempty"`
 	EventProcessors    []string         `mapstructure:"event-processors,omitempty"`
+
+	// static headers added to the produced messages.
+	Headers map[string]string `mapstructure:"headers,omitempty"`
+	// names of the producer interceptors applied to the produced messages.
+	Interceptors []string `mapstructure:"interceptors,omitempty"`
 }
 
 func (k *kafkaOutput) String() string {
@@ -156,8 +176,10 @@ func (k *kafkaOutput) Init(ctx context.Context, name string, cfg map[string]inte
 	}
 	k.msgChan = make(chan *outputs.ProtoMsg, uint(k.cfg.BufferSize))
 	k.mo = &formatters.MarshalOptions{
-		Format:     k.cfg.Format,
-		OverrideTS: k.cfg.OverrideTimestamps,
+		Format:         k.cfg.Format,
+		OverrideTS:     k.cfg.OverrideTimestamps,
+		EventEnvelope:  k.cfg.EventEnvelope,
+		EventSchemaURL: k.cfg.EventSchemaURL,
 	}
 
 	if k.cfg.TargetTemplate == "" {
@@ -177,11 +199,28 @@ func (k *kafkaOutput) Init(ctx context.Context, name string, cfg map[string]inte
 		}
 		k.msgTpl = k.msgTpl.Funcs(outputs.TemplateFuncs)
 	}
+	if k.cfg.TopicTemplate != "" {
+		k.topicTpl, err = gtemplate.CreateTemplate("topic-template", k.cfg.TopicTemplate)
+		if err != nil {
+			return err
+		}
+		k.topicTpl = k.topicTpl.Funcs(outputs.TemplateFuncs)
+	}
+	if k.cfg.KeyTemplate != "" {
+		k.keyTpl, err = gtemplate.CreateTemplate("key-template", k.cfg.KeyTemplate)
+		if err != nil {
+			return err
+		}
+		k.keyTpl = k.keyTpl.Funcs(outputs.TemplateFuncs)
+	}
 
 	config, err := k.createConfig()
 	if err != nil {
 		return err
 	}
+	if k.cfg.CreateTopics != nil {
+		k.topicAdmin = newTopicAdmin(k.cfg.CreateTopics, strings.Split(k.cfg.Address, ","), config)
+	}
 	ctx, k.cancelFn = context.WithCancel(ctx)
 	k.wg.Add(k.cfg.NumWorkers)
 	for i := 0; i < k.cfg.NumWorkers; i++ {
@@ -200,9 +239,15 @@ func (k *kafkaOutput) setDefaults() error {
 	if k.cfg.Format == "" {
 		k.cfg.Format = defaultFormat
 	}
-	if !(k.cfg.Format == "event" || k.cfg.Format == "protojson" || k.cfg.Format == "prototext" || k.cfg.Format == "proto" || k.cfg.Format == "json") {
+	if !(formatters.IsEventFormat(k.cfg.Format) || k.cfg.Format == "protojson" || k.cfg.Format == "prototext" || k.cfg.Format == "proto" || k.cfg.Format == "json") {
 		return fmt.Errorf("unsupported output format '%s' for output type kafka", k.cfg.Format)
 	}
+	if k.cfg.MsgTemplate != "" && formatters.IsBinaryFormat(k.cfg.Format) {
+		return fmt.Errorf("msg-template is not supported with format '%s'", k.cfg.Format)
+	}
+	if (k.cfg.TopicTemplate != "" || k.cfg.KeyTemplate != "") && !formatters.IsEventFormat(k.cfg.Format) {
+		return fmt.Errorf("topic-template and key-template require an event format, got '%s'", k.cfg.Format)
+	}
 	if k.cfg.Address == "" {
 		k.cfg.Address = defaultAddress
 	}
@@ -224,6 +269,14 @@ func (k *kafkaOutput) setDefaults() error {
 	if k.cfg.Name == "" {
 		k.cfg.Name = "gnmic-" + uuid.New().String()
 	}
+	switch k.cfg.CompressionCodec {
+	case "", "none", "gzip", "snappy", "zstd", "lz4":
+	default:
+		return fmt.Errorf("unknown `compression-codec` value %s: must be one of none, gzip, snappy, zstd or lz4", k.cfg.CompressionCodec)
+	}
+	if k.cfg.FlushBytes < 0 || k.cfg.FlushMessages < 0 || k.cfg.FlushMaxMessages < 0 || k.cfg.MaxMessageBytes < 0 {
+		return errors.New("flush-bytes, flush-messages, flush-max-messages and max-message-bytes must be positive")
+	}
 	if k.cfg.SASL == nil {
 		return nil
 	}
@@ -254,14 +307,44 @@ func (k *kafkaOutput) Write(ctx context.Context, rsp proto.Message, meta outputs
 	if rsp == nil {
 		return
 	}
+	k.enqueue(ctx, outputs.NewProtoMsg(rsp, meta))
+}
+
+func (k *kafkaOutput) WriteEvent(ctx context.Context, ev *formatters.EventMsg) {
+	err := outputs.WriteEventAsResponse(ctx, k, ev)
+	if err != nil {
+		k.logger.Printf("%v", err)
+	}
+}
+
+// WriteAck writes the message and calls ack once
+// it is acknowledged by the Kafka brokers.
+func (k *kafkaOutput) WriteAck(ctx context.Context, rsp proto.Message, meta outputs.Meta, ack outputs.AckFunc) {
+	if rsp == nil {
+		ack(nil)
+		return
+	}
+	k.enqueue(ctx, outputs.NewProtoMsgWithAck(rsp, meta, ack))
+}
+
+func (k *kafkaOutput) WriteEventAck(ctx context.Context, ev *formatters.EventMsg, ack outputs.AckFunc) {
+	rsp, meta, err := formatters.EventMsgToResponse(ev)
+	if err != nil {
+		ack(fmt.Errorf("failed to convert event to gNMI notification: %v", err))
+		return
+	}
+	k.WriteAck(ctx, rsp, meta, ack)
+}
 
+func (k *kafkaOutput) enqueue(ctx context.Context, m *outputs.ProtoMsg) {
 	wctx, cancel := context.WithTimeout(ctx, k.cfg.Timeout)
 	defer cancel()
 
 	select {
 	case <-ctx.Done():
+		m.Ack(ctx.Err())
 		return
-	case k.msgChan <- outputs.NewProtoMsg(rsp, meta):
+	case k.msgChan <- m:
 	case <-wctx.Done():
 		if k.cfg.Debug {
 			k.logger.Printf("writing expired after %s, Kafka output might not be initialized", k.cfg.Timeout)
@@ -269,19 +352,36 @@ func (k *kafkaOutput) Write(ctx context.Context, rsp proto.Message, meta outputs
 		if k.cfg.EnableMetrics {
 			kafkaNumberOfFailSendMsgs.WithLabelValues(k.cfg.Name, "timeout").Inc()
 		}
+		m.Ack(fmt.Errorf("writing expired after %s", k.cfg.Timeout))
 		return
 	}
 }
 
-func (k *kafkaOutput) WriteEvent(ctx context.Context, ev *formatters.EventMsg) {}
-
 // Close //
 func (k *kafkaOutput) Close() error {
 	k.cancelFn()
 	k.wg.Wait()
+	if k.topicAdmin != nil {
+		k.topicAdmin.close()
+	}
 	return nil
 }
 
+// Drain waits for the workers to pick up the queued messages,
+// the messages buffered by the producers are flushed when they are closed.
+func (k *kafkaOutput) Drain(ctx context.Context) (int, error) {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for len(k.msgChan) > 0 {
+		select {
+		case <-ctx.Done():
+			return len(k.msgChan), ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return 0, nil
+}
+
 // Metrics //
 func (k *kafkaOutput) RegisterMetrics(reg *prometheus.Registry) {
 	if !k.cfg.EnableMetrics {
@@ -314,6 +414,7 @@ CRPROD:
 	producer, err = sarama.NewAsyncProducer(strings.Split(k.cfg.Address, ","), config)
 	if err != nil {
 		k.logger.Printf("%s failed to create kafka producer: %v", workerLogPrefix, err)
+		k.health.Set(err)
 		time.Sleep(k.cfg.RecoveryWaitTime)
 		goto CRPROD
 	}
@@ -329,10 +430,12 @@ CRPROD:
 				if !ok {
 					return
 				}
+				k.health.Set(nil)
+				pm, _ := msg.Metadata.(*producerMeta)
+				pm.done(nil)
 				if k.cfg.EnableMetrics {
-					start, ok := msg.Metadata.(time.Time)
-					if ok {
-						kafkaSendDuration.WithLabelValues(config.ClientID).Set(float64(time.Since(start).Nanoseconds()))
+					if pm != nil && !pm.start.IsZero() {
+						kafkaSendDuration.WithLabelValues(config.ClientID).Set(float64(time.Since(pm.start).Nanoseconds()))
 					}
 					kafkaNumberOfSentMsgs.WithLabelValues(config.ClientID).Inc()
 					kafkaNumberOfSentBytes.WithLabelValues(config.ClientID).Add(float64(msg.Value.Length()))
@@ -341,6 +444,9 @@ CRPROD:
 				if !ok {
 					return
 				}
+				k.health.Set(err.Err)
+				pm, _ := err.Msg.Metadata.(*producerMeta)
+				pm.done(err.Err)
 				if k.cfg.Debug {
 					k.logger.Printf("%s failed to send a kafka msg to topic '%s': %v", workerLogPrefix, err.Msg.Topic, err.Err)
 				}
@@ -357,12 +463,7 @@ CRPROD:
 			k.logger.Printf("%s shutting down", workerLogPrefix)
 			return
 		case m := <-k.msgChan:
-			pmsg := m.GetMsg()
-			pmsg, err = outputs.AddSubscriptionTarget(pmsg, m.GetMeta(), k.cfg.AddTarget, k.targetTpl)
-			if err != nil {
-				k.logger.Printf("failed to add target to the response: %v", err)
-			}
-			bb, err := outputs.Marshal(pmsg, m.GetMeta(), k.mo, k.cfg.SplitEvents, k.evps...)
+			msgs, err := k.producerMessages(m, config.ClientID)
 			if err != nil {
 				if k.cfg.Debug {
 					k.logger.Printf("%s failed marshaling proto msg: %v", workerLogPrefix, err)
@@ -370,36 +471,17 @@ CRPROD:
 				if k.cfg.EnableMetrics {
 					kafkaNumberOfFailSendMsgs.WithLabelValues(config.ClientID, "marshal_error").Inc()
 				}
+				m.Ack(err)
 				continue
 			}
-			if len(bb) == 0 {
-				continue
-			}
-			for _, b := range bb {
-				if k.msgTpl != nil {
-					b, err = outputs.ExecTemplate(b, k.msgTpl)
-					if err != nil {
-						if k.cfg.Debug {
-							log.Printf("failed to execute template: %v", err)
-						}
-						kafkaNumberOfFailSendMsgs.WithLabelValues(config.ClientID, "template_error").Inc()
-						continue
-					}
-				}
-
-				topic := k.selectTopic(m.GetMeta())
-				msg := &sarama.ProducerMessage{
-					Topic: topic,
-					Value: sarama.ByteEncoder(b),
-				}
-				if k.cfg.InsertKey {
-					msg.Key = sarama.ByteEncoder(k.partitionKey(m.GetMeta()))
-				}
-				var start time.Time
+			ack := outputs.NewAckGroup(len(msgs), m.Ack)
+			for _, msg := range msgs {
+				k.ensureTopic(msg.Topic)
+				pm := &producerMeta{ack: ack}
 				if k.cfg.EnableMetrics {
-					start = time.Now()
-					msg.Metadata = start
+					pm.start = time.Now()
 				}
+				msg.Metadata = pm
 				producer.Input() <- msg
 			}
 		}
@@ -416,6 +498,7 @@ CRPROD:
 	producer, err = sarama.NewSyncProducer(strings.Split(k.cfg.Address, ","), config)
 	if err != nil {
 		k.logger.Printf("%s failed to create kafka producer: %v", workerLogPrefix, err)
+		k.health.Set(err)
 		time.Sleep(k.cfg.RecoveryWaitTime)
 		goto CRPROD
 	}
@@ -427,12 +510,7 @@ CRPROD:
 			k.logger.Printf("%s shutting down", workerLogPrefix)
 			return
 		case m := <-k.msgChan:
-			pmsg := m.GetMsg()
-			pmsg, err = outputs.AddSubscriptionTarget(pmsg, m.GetMeta(), k.cfg.AddTarget, k.targetTpl)
-			if err != nil {
-				k.logger.Printf("failed to add target to the response: %v", err)
-			}
-			bb, err := outputs.Marshal(pmsg, m.GetMeta(), k.mo, k.cfg.SplitEvents, k.evps...)
+			msgs, err := k.producerMessages(m, config.ClientID)
 			if err != nil {
 				if k.cfg.Debug {
 					k.logger.Printf("%s failed marshaling proto msg: %v", workerLogPrefix, err)
@@ -440,39 +518,22 @@ CRPROD:
 				if k.cfg.EnableMetrics {
 					kafkaNumberOfFailSendMsgs.WithLabelValues(config.ClientID, "marshal_error").Inc()
 				}
+				m.Ack(err)
 				continue
 			}
-			if len(bb) == 0 {
-				continue
-			}
-			for _, b := range bb {
-				if k.msgTpl != nil {
-					b, err = outputs.ExecTemplate(b, k.msgTpl)
-					if err != nil {
-						if k.cfg.Debug {
-							log.Printf("failed to execute template: %v", err)
-						}
-						kafkaNumberOfFailSendMsgs.WithLabelValues(config.ClientID, "template_error").Inc()
-						continue
-					}
-				}
-
-				topic := k.selectTopic(m.GetMeta())
-				msg := &sarama.ProducerMessage{
-					Topic: topic,
-					Value: sarama.ByteEncoder(b),
-				}
-				if k.cfg.InsertKey {
-					msg.Key = sarama.ByteEncoder(k.partitionKey(m.GetMeta()))
-				}
+			ack := outputs.NewAckGroup(len(msgs), m.Ack)
+			for _, msg := range msgs {
+				k.ensureTopic(msg.Topic)
 				var start time.Time
 				if k.cfg.EnableMetrics {
 					start = time.Now()
 				}
 				_, _, err = producer.SendMessage(msg)
+				k.health.Set(err)
+				ack(err)
 				if err != nil {
 					if k.cfg.Debug {
-						k.logger.Printf("%s failed to send a kafka msg to topic '%s': %v", workerLogPrefix, topic, err)
+						k.logger.Printf("%s failed to send a kafka msg to topic '%s': %v", workerLogPrefix, msg.Topic, err)
 					}
 					if k.cfg.EnableMetrics {
 						kafkaNumberOfFailSendMsgs.WithLabelValues(config.ClientID, "send_error").Inc()
@@ -484,13 +545,26 @@ CRPROD:
 				if k.cfg.EnableMetrics {
 					kafkaSendDuration.WithLabelValues(config.ClientID).Set(float64(time.Since(start).Nanoseconds()))
 					kafkaNumberOfSentMsgs.WithLabelValues(config.ClientID).Inc()
-					kafkaNumberOfSentBytes.WithLabelValues(config.ClientID).Add(float64(len(b)))
+					kafkaNumberOfSentBytes.WithLabelValues(config.ClientID).Add(float64(msg.Value.Length()))
 				}
 			}
 		}
 	}
 }
 
+// producerMeta is attached to the messages sent by the async producer.
+type producerMeta struct {
+	start time.Time
+	ack   outputs.AckFunc
+}
+
+func (pm *producerMeta) done(err error) {
+	if pm == nil || pm.ack == nil {
+		return
+	}
+	pm.ack(err)
+}
+
 func (k *kafkaOutput) SetName(name string) {
 	sb := strings.Builder{}
 	if name != "" {
@@ -504,6 +578,10 @@ func (k *kafkaOutput) SetName(name string) {
 
 func (k *kafkaOutput) SetClusterName(name string) {}
 
+// Healthy returns the error of the last failed attempt to create a producer or send a message,
+// nil if the last attempt succeeded.
+func (k *kafkaOutput) Healthy() error { return k.health.Get() }
+
 func (k *kafkaOutput) SetTargetsConfig(map[string]*types.TargetConfig) {}
 
 func (k *kafkaOutput) createConfig() (*sarama.Config, error) {
@@ -555,6 +633,17 @@ func (k *kafkaOutput) createConfig() (*sarama.Config, error) {
 	cfg.Producer.Return.Successes = true
 	cfg.Producer.Timeout = k.cfg.Timeout
 	cfg.Producer.Flush.Frequency = k.cfg.FlushFrequency
+	cfg.Producer.Flush.Bytes = k.cfg.FlushBytes
+	cfg.Producer.Flush.Messages = k.cfg.FlushMessages
+	cfg.Producer.Flush.MaxMessages = k.cfg.FlushMaxMessages
+	if k.cfg.MaxMessageBytes > 0 {
+		cfg.Producer.MaxMessageBytes = k.cfg.MaxMessageBytes
+	}
+	var err error
+	cfg.Producer.Interceptors, err = k.producerInterceptors()
+	if err != nil {
+		return nil, err
+	}
 	switch k.cfg.RequiredAcks {
 	case requiredAcksNoResponse:
 	case requiredAcksWaitForLocal:
@@ -577,6 +666,9 @@ func (k *kafkaOutput) createConfig() (*sarama.Config, error) {
 	default:
 		cfg.Producer.Compression = defaultCompressionCodec
 	}
+	if k.cfg.CompressionLevel != nil {
+		cfg.Producer.CompressionLevel = *k.cfg.CompressionLevel
+	}
 
 	return cfg, nil
 }
@@ -587,6 +679,17 @@ func (k *kafkaOutput) partitionKey(m outputs.Meta) []byte {
 	return b.Bytes()
 }
 
+// ensureTopic creates the topic if the topics creation is enabled.
+func (k *kafkaOutput) ensureTopic(topic string) {
+	if k.topicAdmin == nil {
+		return
+	}
+	err := k.topicAdmin.ensure(topic)
+	if err != nil {
+		k.logger.Printf("failed to create topic '%s': %v", topic, err)
+	}
+}
+
 func (k *kafkaOutput) selectTopic(m outputs.Meta) string {
 	if k.cfg.TopicPrefix == "" {
 		return k.cfg.Topic
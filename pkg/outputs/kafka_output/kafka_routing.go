@@ -0,0 +1,241 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kafka_output
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/IBM/sarama"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+const (
+	defaultTopicPartitions        = 1
+	defaultTopicReplicationFactor = 1
+	maxTopicLength                = 249
+)
+
+// topicCreation configures the creation of the topics the output produces to.
+type topicCreation struct {
+	Partitions        int32 `mapstructure:"partitions,omitempty" json:"partitions,omitempty"`
+	ReplicationFactor int16 `mapstructure:"replication-factor,omitempty" json:"replication-factor,omitempty"`
+}
+
+// topicAdmin creates the topics not created yet.
+type topicAdmin struct {
+	m       sync.Mutex
+	cfg     *topicCreation
+	addrs   []string
+	sConfig *sarama.Config
+	admin   sarama.ClusterAdmin
+	created map[string]struct{}
+}
+
+// producerMessages marshals m into the messages to produce,
+// the topic and key are set per event if a topic or key template is configured.
+func (k *kafkaOutput) producerMessages(m *outputs.ProtoMsg, clientID string) ([]*sarama.ProducerMessage, error) {
+	pmsg, err := outputs.AddSubscriptionTarget(m.GetMsg(), m.GetMeta(), k.cfg.AddTarget, k.targetTpl)
+	if err != nil {
+		k.logger.Printf("failed to add target to the response: %v", err)
+	}
+	if k.topicTpl != nil || k.keyTpl != nil {
+		return k.eventProducerMessages(pmsg, m.GetMeta(), clientID)
+	}
+	bb, err := outputs.Marshal(pmsg, m.GetMeta(), k.mo, k.cfg.SplitEvents, k.evps...)
+	if err != nil {
+		return nil, err
+	}
+	msgs := make([]*sarama.ProducerMessage, 0, len(bb))
+	for _, b := range bb {
+		b, ok := k.execMsgTemplate(b, clientID)
+		if !ok {
+			continue
+		}
+		msg := &sarama.ProducerMessage{
+			Topic: k.selectTopic(m.GetMeta()),
+			Value: sarama.ByteEncoder(b),
+		}
+		if k.cfg.InsertKey {
+			msg.Key = sarama.ByteEncoder(k.partitionKey(m.GetMeta()))
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, nil
+}
+
+// eventProducerMessages produces a message per event,
+// with the topic and key rendered from the event.
+func (k *kafkaOutput) eventProducerMessages(pmsg proto.Message, meta outputs.Meta, clientID string) ([]*sarama.ProducerMessage, error) {
+	rsp, ok := pmsg.(*gnmi.SubscribeResponse)
+	if !ok {
+		return nil, fmt.Errorf("unexpected message type: %T", pmsg)
+	}
+	if _, ok := rsp.GetResponse().(*gnmi.SubscribeResponse_Update); !ok {
+		return nil, nil
+	}
+	subscriptionName, ok := meta["subscription-name"]
+	if !ok {
+		subscriptionName = "default"
+	}
+	events, err := formatters.ResponseToEventMsgs(subscriptionName, rsp, meta, k.evps...)
+	if err != nil {
+		return nil, fmt.Errorf("failed converting response to events: %v", err)
+	}
+	msgs := make([]*sarama.ProducerMessage, 0, len(events))
+	for _, ev := range events {
+		b, err := k.mo.MarshalEvent(ev)
+		if err != nil {
+			return nil, err
+		}
+		b, ok := k.execMsgTemplate(b, clientID)
+		if !ok {
+			continue
+		}
+		msg, err := k.eventMessage(ev, meta, b)
+		if err != nil {
+			if k.cfg.Debug {
+				k.logger.Printf("failed to execute topic or key template: %v", err)
+			}
+			if k.cfg.EnableMetrics {
+				kafkaNumberOfFailSendMsgs.WithLabelValues(clientID, "template_error").Inc()
+			}
+			continue
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, nil
+}
+
+func (k *kafkaOutput) eventMessage(ev *formatters.EventMsg, meta outputs.Meta, b []byte) (*sarama.ProducerMessage, error) {
+	msg := &sarama.ProducerMessage{
+		Topic: k.selectTopic(meta),
+		Value: sarama.ByteEncoder(b),
+	}
+	if k.topicTpl != nil {
+		topic, err := execEventTemplate(k.topicTpl, ev)
+		if err != nil {
+			return nil, err
+		}
+		if topic = sanitizeTopic(topic); topic != "" {
+			msg.Topic = topic
+		}
+	}
+	switch {
+	case k.keyTpl != nil:
+		key, err := execEventTemplate(k.keyTpl, ev)
+		if err != nil {
+			return nil, err
+		}
+		if key != "" {
+			msg.Key = sarama.StringEncoder(key)
+		}
+	case k.cfg.InsertKey:
+		msg.Key = sarama.ByteEncoder(k.partitionKey(meta))
+	}
+	return msg, nil
+}
+
+// execMsgTemplate applies the msg-template to b, if configured.
+func (k *kafkaOutput) execMsgTemplate(b []byte, clientID string) ([]byte, bool) {
+	if k.msgTpl == nil {
+		return b, true
+	}
+	b, err := outputs.ExecTemplate(b, k.msgTpl)
+	if err != nil {
+		if k.cfg.Debug {
+			log.Printf("failed to execute template: %v", err)
+		}
+		kafkaNumberOfFailSendMsgs.WithLabelValues(clientID, "template_error").Inc()
+		return nil, false
+	}
+	return b, true
+}
+
+func execEventTemplate(tpl *template.Template, ev *formatters.EventMsg) (string, error) {
+	buf := new(bytes.Buffer)
+	err := tpl.Execute(buf, ev)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// sanitizeTopic replaces the characters not allowed in a topic name with '_'.
+func sanitizeTopic(topic string) string {
+	topic = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			return r
+		}
+		return '_'
+	}, topic)
+	if len(topic) > maxTopicLength {
+		topic = topic[:maxTopicLength]
+	}
+	return topic
+}
+
+func newTopicAdmin(cfg *topicCreation, addrs []string, sConfig *sarama.Config) *topicAdmin {
+	if cfg.Partitions <= 0 {
+		cfg.Partitions = defaultTopicPartitions
+	}
+	if cfg.ReplicationFactor <= 0 {
+		cfg.ReplicationFactor = defaultTopicReplicationFactor
+	}
+	return &topicAdmin{
+		cfg:     cfg,
+		addrs:   addrs,
+		sConfig: sConfig,
+		created: make(map[string]struct{}),
+	}
+}
+
+// ensure creates topic if it was not created or found yet.
+func (a *topicAdmin) ensure(topic string) error {
+	a.m.Lock()
+	defer a.m.Unlock()
+	if _, ok := a.created[topic]; ok {
+		return nil
+	}
+	if a.admin == nil {
+		admin, err := sarama.NewClusterAdmin(a.addrs, a.sConfig)
+		if err != nil {
+			return err
+		}
+		a.admin = admin
+	}
+	err := a.admin.CreateTopic(topic, &sarama.TopicDetail{
+		NumPartitions:     a.cfg.Partitions,
+		ReplicationFactor: a.cfg.ReplicationFactor,
+	}, false)
+	if err != nil && !errors.Is(err, sarama.ErrTopicAlreadyExists) {
+		return err
+	}
+	a.created[topic] = struct{}{}
+	return nil
+}
+
+func (a *topicAdmin) close() {
+	a.m.Lock()
+	defer a.m.Unlock()
+	if a.admin != nil {
+		a.admin.Close()
+		a.admin = nil
+	}
+}
@@ -0,0 +1,138 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kafka_output
+
+import (
+	"io"
+	"log"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/gtemplate"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+func testResponse() *gnmi.SubscribeResponse {
+	upd := func(name string, v int64) *gnmi.Update {
+		return &gnmi.Update{
+			Path: &gnmi.Path{Elem: []*gnmi.PathElem{
+				{Name: "interfaces"},
+				{Name: "interface", Key: map[string]string{"name": name}},
+				{Name: "state"},
+				{Name: "mtu"},
+			}},
+			Val: &gnmi.TypedValue{Value: &gnmi.TypedValue_IntVal{IntVal: v}},
+		}
+	}
+	return &gnmi.SubscribeResponse{
+		Response: &gnmi.SubscribeResponse_Update{
+			Update: &gnmi.Notification{
+				Timestamp: 42,
+				Update:    []*gnmi.Update{upd("ethernet-1/1", 9000), upd("ethernet-1/2", 1500)},
+			},
+		},
+	}
+}
+
+func newTestOutput(t *testing.T, cfg *config) *kafkaOutput {
+	k := &kafkaOutput{
+		cfg:    cfg,
+		logger: log.New(io.Discard, "", 0),
+		mo:     &formatters.MarshalOptions{Format: "event"},
+	}
+	var err error
+	if cfg.TopicTemplate != "" {
+		k.topicTpl, err = gtemplate.CreateTemplate("topic-template", cfg.TopicTemplate)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if cfg.KeyTemplate != "" {
+		k.keyTpl, err = gtemplate.CreateTemplate("key-template", cfg.KeyTemplate)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return k
+}
+
+func keyString(t *testing.T, e sarama.Encoder) string {
+	if e == nil {
+		return ""
+	}
+	b, err := e.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestEventRouting(t *testing.T) {
+	k := newTestOutput(t, &config{
+		Topic:         "telemetry",
+		TopicTemplate: `{{ .Tags.source }}.{{ .Name }}`,
+		KeyTemplate:   `{{ index .Tags "interface_name" }}`,
+	})
+	meta := outputs.Meta{"source": "r1:57400", "subscription-name": "sub1"}
+	msgs, err := k.producerMessages(outputs.NewProtoMsg(testResponse(), meta), "c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 2 {
+		t.Fatalf("expected a message per event, got %d", len(msgs))
+	}
+	for i, key := range []string{"ethernet-1/1", "ethernet-1/2"} {
+		if msgs[i].Topic != "r1_57400.sub1" {
+			t.Errorf("message %d: unexpected topic %q", i, msgs[i].Topic)
+		}
+		if got := keyString(t, msgs[i].Key); got != key {
+			t.Errorf("message %d: unexpected key %q", i, got)
+		}
+	}
+}
+
+func TestEventRoutingFallbacks(t *testing.T) {
+	k := newTestOutput(t, &config{
+		Topic:       "telemetry",
+		KeyTemplate: `{{ index .Tags "missing" }}`,
+	})
+	msgs, err := k.producerMessages(outputs.NewProtoMsg(testResponse(), outputs.Meta{"source": "r1"}), "c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 2 || msgs[0].Topic != "telemetry" || msgs[0].Key != nil {
+		t.Errorf("unexpected messages: %+v", msgs)
+	}
+	// without templates, a single message per response.
+	k = newTestOutput(t, &config{Topic: "telemetry", InsertKey: true})
+	msgs, err = k.producerMessages(outputs.NewProtoMsg(testResponse(), outputs.Meta{"source": "r1", "subscription-name": "sub1"}), "c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 1 || keyString(t, msgs[0].Key) != "r1_sub1" {
+		t.Errorf("unexpected messages: %+v", msgs)
+	}
+}
+
+func TestSanitizeTopic(t *testing.T) {
+	for in, want := range map[string]string{
+		"telemetry.r1":        "telemetry.r1",
+		"r1:57400/sub 1":      "r1_57400_sub_1",
+		"dc-1_leaf.1":         "dc-1_leaf.1",
+		"":                    "",
+		"ethernet-1/1@router": "ethernet-1_1_router",
+	} {
+		if got := sanitizeTopic(in); got != want {
+			t.Errorf("%q: got %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package mirror_output
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var mirrorNumberOfSentMsgs = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "mirror_output",
+	Name:      "number_of_sent_msgs_total",
+	Help:      "Number of messages written to a child output",
+}, []string{"name", "output"})
+
+var mirrorNumberOfDroppedMsgs = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "mirror_output",
+	Name:      "number_of_dropped_msgs_total",
+	Help:      "Number of messages dropped because the child output buffer was full",
+}, []string{"name", "output"})
+
+var mirrorWriteDuration = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "gnmic",
+	Subsystem: "mirror_output",
+	Name:      "write_duration_ns",
+	Help:      "Duration of the last write to a child output in ns",
+}, []string{"name", "output"})
+
+func registerMetrics(reg *prometheus.Registry) error {
+	for _, c := range []prometheus.Collector{mirrorNumberOfSentMsgs, mirrorNumberOfDroppedMsgs, mirrorWriteDuration} {
+		if err := reg.Register(c); err != nil {
+			var are prometheus.AlreadyRegisteredError
+			// multiple mirror outputs share the same collectors
+			if errors.As(err, &are) {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
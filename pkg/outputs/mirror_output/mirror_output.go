@@ -0,0 +1,286 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package mirror_output
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/api/utils"
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+const (
+	Type              = "mirror"
+	loggingPrefix     = "[mirror_output:%s] "
+	defaultBufferSize = 1000
+	defaultSample     = 100
+)
+
+func init() {
+	outputs.Register(Type, func() outputs.Output {
+		return &mirrorOutput{
+			cfg:    &Config{},
+			logger: log.New(io.Discard, loggingPrefix, utils.DefaultLoggingFlags),
+		}
+	})
+	outputs.RegisterMembers(Type, func(cfg map[string]interface{}) []string {
+		c := new(Config)
+		if err := decodeConfig(cfg, c); err != nil {
+			return nil
+		}
+		names := make([]string, 0, len(c.Outputs))
+		for _, child := range c.Outputs {
+			names = append(names, child.Name)
+		}
+		return names
+	})
+}
+
+// mirrorOutput duplicates the messages it receives to all its child outputs,
+// each child has its own buffer so that a slow or failing child does not affect the others.
+type mirrorOutput struct {
+	cfg      *Config
+	name     string
+	logger   *log.Logger
+	cancelFn context.CancelFunc
+
+	all      map[string]outputs.Output
+	children []*child
+}
+
+type Config struct {
+	Outputs []*ChildConfig `mapstructure:"outputs,omitempty" json:"outputs,omitempty"`
+	// default buffer size of the children
+	BufferSize    int  `mapstructure:"buffer-size,omitempty" json:"buffer-size,omitempty"`
+	EnableMetrics bool `mapstructure:"enable-metrics,omitempty" json:"enable-metrics,omitempty"`
+	Debug         bool `mapstructure:"debug,omitempty" json:"debug,omitempty"`
+}
+
+type ChildConfig struct {
+	Name string `mapstructure:"name,omitempty" json:"name,omitempty"`
+	// percentage of the messages sent to this output
+	Sample     *float64 `mapstructure:"sample,omitempty" json:"sample,omitempty"`
+	BufferSize int      `mapstructure:"buffer-size,omitempty" json:"buffer-size,omitempty"`
+}
+
+type child struct {
+	cfg    *ChildConfig
+	out    outputs.Output
+	buffer chan *mirroredMsg
+}
+
+type mirroredMsg struct {
+	msg  proto.Message
+	meta outputs.Meta
+	ev   *formatters.EventMsg
+}
+
+// decodeConfig decodes cfg into c, children can be configured
+// with their name only or with a name and options.
+func decodeConfig(cfg map[string]interface{}, c *Config) error {
+	if children, ok := cfg["outputs"].([]interface{}); ok {
+		normalized := make([]interface{}, 0, len(children))
+		for _, ch := range children {
+			if n, ok := ch.(string); ok {
+				normalized = append(normalized, map[string]interface{}{"name": n})
+				continue
+			}
+			normalized = append(normalized, ch)
+		}
+		ncfg := make(map[string]interface{}, len(cfg))
+		for k, v := range cfg {
+			ncfg[k] = v
+		}
+		ncfg["outputs"] = normalized
+		cfg = ncfg
+	}
+	return outputs.DecodeConfig(cfg, c)
+}
+
+func (m *mirrorOutput) Init(ctx context.Context, name string, cfg map[string]interface{}, opts ...outputs.Option) error {
+	err := decodeConfig(cfg, m.cfg)
+	if err != nil {
+		return err
+	}
+	m.name = name
+	m.logger.SetPrefix(fmt.Sprintf(loggingPrefix, name))
+	for _, opt := range opts {
+		if err := opt(m); err != nil {
+			return err
+		}
+	}
+	if len(m.cfg.Outputs) == 0 {
+		return errors.New("a mirror output requires at least 1 output")
+	}
+	if m.cfg.BufferSize <= 0 {
+		m.cfg.BufferSize = defaultBufferSize
+	}
+	ctx, m.cancelFn = context.WithCancel(ctx)
+	m.children = make([]*child, 0, len(m.cfg.Outputs))
+	for _, cc := range m.cfg.Outputs {
+		if cc.Name == name {
+			return fmt.Errorf("output %q cannot be a child of itself", cc.Name)
+		}
+		o, ok := m.all[cc.Name]
+		if !ok {
+			return fmt.Errorf("unknown output %q", cc.Name)
+		}
+		if cc.Sample == nil {
+			s := float64(defaultSample)
+			cc.Sample = &s
+		}
+		if *cc.Sample < 0 || *cc.Sample > 100 {
+			return fmt.Errorf("output %q: sample must be a percentage between 0 and 100", cc.Name)
+		}
+		if cc.BufferSize <= 0 {
+			cc.BufferSize = m.cfg.BufferSize
+		}
+		c := &child{
+			cfg:    cc,
+			out:    o,
+			buffer: make(chan *mirroredMsg, cc.BufferSize),
+		}
+		m.children = append(m.children, c)
+		go m.worker(ctx, c)
+	}
+	m.all = nil
+	m.logger.Printf("initialized mirror output: %s", m.String())
+	return nil
+}
+
+func (m *mirrorOutput) Write(ctx context.Context, msg proto.Message, meta outputs.Meta) {
+	if msg == nil {
+		return
+	}
+	m.mirror(ctx, &mirroredMsg{msg: msg, meta: meta})
+}
+
+func (m *mirrorOutput) WriteEvent(ctx context.Context, ev *formatters.EventMsg) {
+	if ev == nil {
+		return
+	}
+	m.mirror(ctx, &mirroredMsg{ev: ev})
+}
+
+func (m *mirrorOutput) mirror(ctx context.Context, mm *mirroredMsg) {
+	for _, c := range m.children {
+		if *c.cfg.Sample < 100 && rand.Float64()*100 >= *c.cfg.Sample {
+			continue
+		}
+		cm := mm
+		if mm.ev != nil {
+			// each child gets its own copy of the event,
+			// its event processors may modify it.
+			cm = &mirroredMsg{ev: mm.ev.Clone()}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case c.buffer <- cm:
+		default:
+			// the child is not keeping up, drop the message for this child only
+			if m.cfg.Debug {
+				m.logger.Printf("buffer of output %q is full, dropping message", c.cfg.Name)
+			}
+			if m.cfg.EnableMetrics {
+				mirrorNumberOfDroppedMsgs.WithLabelValues(m.name, c.cfg.Name).Inc()
+			}
+		}
+	}
+}
+
+func (m *mirrorOutput) worker(ctx context.Context, c *child) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case mm := <-c.buffer:
+			start := time.Now()
+			m.writeChild(ctx, c, mm)
+			if m.cfg.EnableMetrics {
+				mirrorNumberOfSentMsgs.WithLabelValues(m.name, c.cfg.Name).Inc()
+				mirrorWriteDuration.WithLabelValues(m.name, c.cfg.Name).Set(float64(time.Since(start).Nanoseconds()))
+			}
+		}
+	}
+}
+
+// writeChild writes the message to the child output,
+// recovering from a panic so that the other children are not affected.
+func (m *mirrorOutput) writeChild(ctx context.Context, c *child, mm *mirroredMsg) {
+	defer func() {
+		if r := recover(); r != nil {
+			m.logger.Printf("output %q failed to write message: %v", c.cfg.Name, r)
+		}
+	}()
+	if mm.ev != nil {
+		c.out.WriteEvent(ctx, mm.ev)
+		return
+	}
+	c.out.Write(ctx, mm.msg, mm.meta)
+}
+
+func (m *mirrorOutput) Close() error {
+	if m.cancelFn != nil {
+		m.cancelFn()
+	}
+	return nil
+}
+
+func (m *mirrorOutput) RegisterMetrics(reg *prometheus.Registry) {
+	if !m.cfg.EnableMetrics {
+		return
+	}
+	if reg == nil {
+		m.logger.Printf("ERR: output metrics enabled but main registry is not initialized, enable main metrics under `api-server`")
+		return
+	}
+	if err := registerMetrics(reg); err != nil {
+		m.logger.Printf("failed to register metrics: %v", err)
+	}
+}
+
+func (m *mirrorOutput) String() string {
+	b, err := json.Marshal(m.cfg)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func (m *mirrorOutput) SetLogger(logger *log.Logger) {
+	if logger != nil && m.logger != nil {
+		m.logger.SetOutput(logger.Writer())
+		m.logger.SetFlags(logger.Flags())
+	}
+}
+
+// SetEventProcessors is a noop, the event processors
+// of the child outputs are applied.
+func (m *mirrorOutput) SetEventProcessors(map[string]map[string]interface{}, *log.Logger, map[string]*types.TargetConfig, map[string]map[string]interface{}) error {
+	return nil
+}
+
+func (m *mirrorOutput) SetOutputs(outs map[string]outputs.Output) { m.all = outs }
+
+func (m *mirrorOutput) SetName(string)                                  {}
+func (m *mirrorOutput) SetClusterName(string)                           {}
+func (m *mirrorOutput) SetTargetsConfig(map[string]*types.TargetConfig) {}
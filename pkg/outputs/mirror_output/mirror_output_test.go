@@ -0,0 +1,162 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package mirror_output
+
+import (
+	"context"
+	"log"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+type testOutput struct {
+	m     sync.Mutex
+	name  string
+	msgs  int
+	evs   []*formatters.EventMsg
+	panic bool
+}
+
+func (o *testOutput) Init(context.Context, string, map[string]interface{}, ...outputs.Option) error {
+	return nil
+}
+func (o *testOutput) Write(context.Context, proto.Message, outputs.Meta) {
+	if o.panic {
+		panic("write failed")
+	}
+	o.m.Lock()
+	defer o.m.Unlock()
+	o.msgs++
+}
+func (o *testOutput) WriteEvent(_ context.Context, ev *formatters.EventMsg) {
+	ev.Tags["output"] = o.name
+	o.m.Lock()
+	defer o.m.Unlock()
+	o.evs = append(o.evs, ev)
+}
+func (o *testOutput) Close() error                         { return nil }
+func (o *testOutput) RegisterMetrics(*prometheus.Registry) {}
+func (o *testOutput) String() string                       { return "" }
+func (o *testOutput) SetLogger(*log.Logger)                {}
+func (o *testOutput) SetEventProcessors(map[string]map[string]interface{}, *log.Logger, map[string]*types.TargetConfig, map[string]map[string]interface{}) error {
+	return nil
+}
+func (o *testOutput) SetName(string)                                  {}
+func (o *testOutput) SetClusterName(string)                           {}
+func (o *testOutput) SetTargetsConfig(map[string]*types.TargetConfig) {}
+
+func (o *testOutput) count() int {
+	o.m.Lock()
+	defer o.m.Unlock()
+	return o.msgs
+}
+
+func (o *testOutput) events() []*formatters.EventMsg {
+	o.m.Lock()
+	defer o.m.Unlock()
+	return o.evs
+}
+
+func TestMirror(t *testing.T) {
+	prod, candidate, skipped, failing := new(testOutput), new(testOutput), new(testOutput), &testOutput{panic: true}
+	cfg := map[string]interface{}{
+		"outputs": []interface{}{
+			"prod",
+			map[string]interface{}{"name": "candidate", "sample": 50},
+			map[string]interface{}{"name": "skipped", "sample": 0},
+			"failing",
+		},
+	}
+	members := outputs.Members(map[string]interface{}{"type": Type, "outputs": cfg["outputs"]})
+	if len(members) != 4 || members[0] != "prod" || members[1] != "candidate" {
+		t.Fatalf("unexpected members: %v", members)
+	}
+	m := outputs.Outputs[Type]()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := m.Init(ctx, "mirror1", cfg, outputs.WithOutputs(map[string]outputs.Output{
+		"prod":      prod,
+		"candidate": candidate,
+		"skipped":   skipped,
+		"failing":   failing,
+	}))
+	if err != nil {
+		t.Fatalf("failed to init: %v", err)
+	}
+	numMsgs := 200
+	for i := 0; i < numMsgs; i++ {
+		m.Write(ctx, &gnmi.SubscribeResponse{}, nil)
+	}
+	deadline := time.Now().Add(time.Second)
+	for prod.count() != numMsgs && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if prod.count() != numMsgs {
+		t.Fatalf("expected %d messages, got %d", numMsgs, prod.count())
+	}
+	if c := candidate.count(); c == 0 || c == numMsgs {
+		t.Fatalf("expected about half of the messages to be sampled, got %d", c)
+	}
+	if c := skipped.count(); c != 0 {
+		t.Fatalf("expected no messages, got %d", c)
+	}
+}
+
+func TestInitInvalidSample(t *testing.T) {
+	m := outputs.Outputs[Type]()
+	err := m.Init(context.Background(), "mirror1", map[string]interface{}{
+		"outputs": []interface{}{map[string]interface{}{"name": "prod", "sample": 150}},
+	}, outputs.WithOutputs(map[string]outputs.Output{"prod": new(testOutput)}))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestMirrorEvents(t *testing.T) {
+	prod, candidate := &testOutput{name: "prod"}, &testOutput{name: "candidate"}
+	m := outputs.Outputs[Type]()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := m.Init(ctx, "mirror1", map[string]interface{}{"outputs": []interface{}{"prod", "candidate"}},
+		outputs.WithOutputs(map[string]outputs.Output{"prod": prod, "candidate": candidate}))
+	if err != nil {
+		t.Fatalf("failed to init: %v", err)
+	}
+	ev := &formatters.EventMsg{
+		Name:   "sub1",
+		Tags:   map[string]string{"source": "r1"},
+		Values: map[string]interface{}{"counter": 1},
+	}
+	m.WriteEvent(ctx, ev)
+	deadline := time.Now().Add(time.Second)
+	for (len(prod.events()) == 0 || len(candidate.events()) == 0) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	for _, o := range []*testOutput{prod, candidate} {
+		evs := o.events()
+		if len(evs) != 1 {
+			t.Fatalf("output %s: expected 1 event, got %d", o.name, len(evs))
+		}
+		if evs[0].Tags["output"] != o.name || evs[0].Tags["source"] != "r1" {
+			t.Errorf("output %s: unexpected event tags %v", o.name, evs[0].Tags)
+		}
+	}
+	if _, ok := ev.Tags["output"]; ok {
+		t.Errorf("the written event was modified by the children: %v", ev.Tags)
+	}
+}
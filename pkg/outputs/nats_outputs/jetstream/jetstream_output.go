@@ -79,10 +79,13 @@ type config struct {
 	ConnectTimeWait    time.Duration       `mapstructure:"connect-time-wait,omitempty" json:"connect-time-wait,omitempty"`
 	TLS                *types.TLSConfig    `mapstructure:"tls,omitempty" json:"tls,omitempty"`
 	Format             string              `mapstructure:"format,omitempty" json:"format,omitempty"`
+	EventEnvelope      bool                `mapstructure:"event-envelope,omitempty" json:"event-envelope,omitempty"`
+	EventSchemaURL     string              `mapstructure:"event-schema-url,omitempty" json:"event-schema-url,omitempty"`
 	SplitEvents        bool                `mapstructure:"split-events,omitempty"`
 	AddTarget          string              `mapstructure:"add-target,omitempty" json:"add-target,omitempty"`
 	TargetTemplate     string              `mapstructure:"target-template,omitempty" json:"target-template,omitempty"`
 	MsgTemplate        string              `mapstructure:"msg-template,omitempty" json:"msg-template,omitempty"`
+	MsgIDTemplate      string              `mapstructure:"msg-id-template,omitempty" json:"msg-id-template,omitempty"`
 	OverrideTimestamps bool                `mapstructure:"override-timestamps,omitempty" json:"override-timestamps,omitempty"`
 	NumWorkers         int                 `mapstructure:"num-workers,omitempty" json:"num-workers,omitempty"`
 	WriteTimeout       time.Duration       `mapstructure:"write-timeout,omitempty" json:"write-timeout,omitempty"`
@@ -99,6 +102,8 @@ type createStreamConfig struct {
 	MaxBytes    int64         `mapstructure:"max-bytes,omitempty" json:"max-bytes,omitempty"`
 	MaxAge      time.Duration `mapstructure:"max-age,omitempty" json:"max-age,omitempty"`
 	MaxMsgSize  int32         `mapstructure:"max-msg-size,omitempty" json:"max-msg-size,omitempty"`
+	// window within which messages with the same Nats-Msg-Id are discarded
+	DuplicateWindow time.Duration `mapstructure:"duplicate-window,omitempty" json:"duplicate-window,omitempty"`
 }
 
 // jetstreamOutput //
@@ -114,6 +119,7 @@ type jetstreamOutput struct {
 
 	targetTpl *template.Template
 	msgTpl    *template.Template
+	msgIDTpl  *template.Template
 }
 
 func (n *jetstreamOutput) Init(ctx context.Context, name string, cfg map[string]interface{}, opts ...outputs.Option) error {
@@ -139,8 +145,10 @@ func (n *jetstreamOutput) Init(ctx context.Context, name string, cfg map[string]
 	n.msgChan = make(chan *outputs.ProtoMsg)
 	initMetrics()
 	n.mo = &formatters.MarshalOptions{
-		Format:     n.Cfg.Format,
-		OverrideTS: n.Cfg.OverrideTimestamps,
+		Format:         n.Cfg.Format,
+		OverrideTS:     n.Cfg.OverrideTimestamps,
+		EventEnvelope:  n.Cfg.EventEnvelope,
+		EventSchemaURL: n.Cfg.EventSchemaURL,
 	}
 	if n.Cfg.TargetTemplate == "" {
 		n.targetTpl = outputs.DefaultTargetTemplate
@@ -160,6 +168,14 @@ func (n *jetstreamOutput) Init(ctx context.Context, name string, cfg map[string]
 		n.msgTpl = n.msgTpl.Funcs(outputs.TemplateFuncs)
 	}
 
+	if n.Cfg.MsgIDTemplate != "" {
+		n.msgIDTpl, err = gtemplate.CreateTemplate("msg-id-template", n.Cfg.MsgIDTemplate)
+		if err != nil {
+			return err
+		}
+		n.msgIDTpl = n.msgIDTpl.Funcs(outputs.TemplateFuncs)
+	}
+
 	n.ctx, n.cancelFn = context.WithCancel(ctx)
 
 	n.wg.Add(n.Cfg.NumWorkers)
@@ -229,7 +245,41 @@ func (n *jetstreamOutput) setDefaults() error {
 }
 
 func (n *jetstreamOutput) Write(ctx context.Context, rsp proto.Message, meta outputs.Meta) {
-	if rsp == nil || n.mo == nil {
+	if rsp == nil {
+		return
+	}
+	n.enqueue(ctx, outputs.NewProtoMsg(rsp, meta))
+}
+
+func (n *jetstreamOutput) WriteEvent(ctx context.Context, ev *formatters.EventMsg) {
+	err := outputs.WriteEventAsResponse(ctx, n, ev)
+	if err != nil {
+		n.logger.Printf("%v", err)
+	}
+}
+
+// WriteAck writes the message and calls ack once all the resulting
+// messages are acknowledged by the JetStream server.
+func (n *jetstreamOutput) WriteAck(ctx context.Context, rsp proto.Message, meta outputs.Meta, ack outputs.AckFunc) {
+	if rsp == nil {
+		ack(nil)
+		return
+	}
+	n.enqueue(ctx, outputs.NewProtoMsgWithAck(rsp, meta, ack))
+}
+
+func (n *jetstreamOutput) WriteEventAck(ctx context.Context, ev *formatters.EventMsg, ack outputs.AckFunc) {
+	rsp, meta, err := formatters.EventMsgToResponse(ev)
+	if err != nil {
+		ack(fmt.Errorf("failed to convert event to gNMI notification: %v", err))
+		return
+	}
+	n.WriteAck(ctx, rsp, meta, ack)
+}
+
+func (n *jetstreamOutput) enqueue(ctx context.Context, m *outputs.ProtoMsg) {
+	if n.mo == nil {
+		m.Ack(errors.New("output not initialized"))
 		return
 	}
 	wctx, cancel := context.WithTimeout(ctx, n.Cfg.WriteTimeout)
@@ -237,8 +287,9 @@ func (n *jetstreamOutput) Write(ctx context.Context, rsp proto.Message, meta out
 
 	select {
 	case <-ctx.Done():
+		m.Ack(ctx.Err())
 		return
-	case n.msgChan <- outputs.NewProtoMsg(rsp, meta):
+	case n.msgChan <- m:
 	case <-wctx.Done():
 		if n.Cfg.Debug {
 			n.logger.Printf("writing expired after %s, JetStream output might not be initialized", n.Cfg.WriteTimeout)
@@ -246,12 +297,11 @@ func (n *jetstreamOutput) Write(ctx context.Context, rsp proto.Message, meta out
 		if n.Cfg.EnableMetrics {
 			jetStreamNumberOfFailSendMsgs.WithLabelValues(n.Cfg.Name, "timeout").Inc()
 		}
+		m.Ack(fmt.Errorf("writing expired after %s", n.Cfg.WriteTimeout))
 		return
 	}
 }
 
-func (n *jetstreamOutput) WriteEvent(ctx context.Context, ev *formatters.EventMsg) {}
-
 func (n *jetstreamOutput) Close() error {
 	n.cancelFn()
 	n.wg.Wait()
@@ -375,6 +425,8 @@ CRCONN:
 			n.logger.Printf("%s shutting down", workerLogPrefix)
 			return
 		case m := <-n.msgChan:
+			// first error of the message writes, reported to its ack function
+			var werr error
 			pmsg := m.GetMsg()
 			pmsg, err = outputs.AddSubscriptionTarget(pmsg, m.GetMeta(), n.Cfg.AddTarget, n.targetTpl)
 			if err != nil {
@@ -402,12 +454,18 @@ CRCONN:
 					if n.Cfg.EnableMetrics {
 						jetStreamNumberOfFailSendMsgs.WithLabelValues(cfg.Name, "marshal_error").Inc()
 					}
+					if werr == nil {
+						werr = err
+					}
 					continue
 				}
 				if len(bb) == 0 {
 					continue
 				}
 				for _, b := range bb {
+					// the message ID is computed from the formatted message,
+					// before the msg-template is applied.
+					msgID := n.msgID(b, cfg)
 					if n.msgTpl != nil {
 						b, err = outputs.ExecTemplate(b, n.msgTpl)
 						if err != nil {
@@ -415,6 +473,9 @@ CRCONN:
 								log.Printf("failed to execute template: %v", err)
 							}
 							jetStreamNumberOfFailSendMsgs.WithLabelValues(cfg.Name, "template_error").Inc()
+							if werr == nil {
+								werr = err
+							}
 							continue
 						}
 					}
@@ -427,13 +488,20 @@ CRCONN:
 						if n.Cfg.EnableMetrics {
 							jetStreamNumberOfFailSendMsgs.WithLabelValues(cfg.Name, "subject_name_error").Inc()
 						}
+						if werr == nil {
+							werr = err
+						}
 						continue
 					}
 					var start time.Time
 					if n.Cfg.EnableMetrics {
 						start = time.Now()
 					}
-					_, err = js.Publish(subject, b)
+					var pubOpts []nats.PubOpt
+					if msgID != "" {
+						pubOpts = append(pubOpts, nats.MsgId(msgID))
+					}
+					_, err = js.Publish(subject, b, pubOpts...)
 					if err != nil {
 						if n.Cfg.Debug {
 							n.logger.Printf("%s failed to write to subject '%s': %v", workerLogPrefix, subject, err)
@@ -441,6 +509,7 @@ CRCONN:
 						if n.Cfg.EnableMetrics {
 							jetStreamNumberOfFailSendMsgs.WithLabelValues(cfg.Name, "publish_error").Inc()
 						}
+						m.Ack(err)
 						natsConn.Close()
 						time.Sleep(cfg.ConnectTimeWait)
 						goto CRCONN
@@ -452,8 +521,42 @@ CRCONN:
 					}
 				}
 			}
+			m.Ack(werr)
+		}
+	}
+}
+
+// msgID executes the msg-id-template using the formatted message as input,
+// it returns an empty string if the template is not set or fails,
+// in which case the message is published without a Nats-Msg-Id header.
+func (n *jetstreamOutput) msgID(b []byte, cfg *config) string {
+	if n.msgIDTpl == nil {
+		return ""
+	}
+	id, err := execMsgIDTemplate(b, n.msgIDTpl)
+	if err != nil {
+		if n.Cfg.Debug {
+			n.logger.Printf("failed to execute msg-id template: %v", err)
+		}
+		if n.Cfg.EnableMetrics {
+			jetStreamNumberOfFailSendMsgs.WithLabelValues(cfg.Name, "msg_id_template_error").Inc()
 		}
+		return ""
+	}
+	return id
+}
+
+func execMsgIDTemplate(b []byte, tpl *template.Template) (string, error) {
+	input, err := outputs.DecodeTemplateInput(b)
+	if err != nil {
+		return "", fmt.Errorf("failed to unmarshal input: %v", err)
+	}
+	id := new(strings.Builder)
+	err = gtemplate.Execute(id, tpl, input)
+	if err != nil {
+		return "", err
 	}
+	return strings.TrimSpace(id.String()), nil
 }
 
 // Dial //
@@ -534,7 +637,7 @@ func (n *jetstreamOutput) subjectName(m proto.Message, meta outputs.Meta) (strin
 			sb.WriteString(n.Cfg.Subject)
 			sb.WriteString(".")
 		}
-		err := n.targetTpl.Execute(sb, meta)
+		err := gtemplate.Execute(sb, n.targetTpl, meta)
 		if err != nil {
 			return "", err
 		}
@@ -547,7 +650,7 @@ func (n *jetstreamOutput) subjectName(m proto.Message, meta outputs.Meta) (strin
 			sb.WriteString(sub)
 			sb.WriteString(".")
 		}
-		err := n.targetTpl.Execute(sb, meta)
+		err := gtemplate.Execute(sb, n.targetTpl, meta)
 		if err != nil {
 			return "", err
 		}
@@ -556,7 +659,7 @@ func (n *jetstreamOutput) subjectName(m proto.Message, meta outputs.Meta) (strin
 			sb.WriteString(sub)
 			sb.WriteString(".")
 		}
-		err := n.targetTpl.Execute(sb, meta)
+		err := gtemplate.Execute(sb, n.targetTpl, meta)
 		if err != nil {
 			return "", err
 		}
@@ -587,7 +690,7 @@ func (n *jetstreamOutput) subjectName(m proto.Message, meta outputs.Meta) (strin
 			sb.WriteString(sub)
 			sb.WriteString(".")
 		}
-		err := n.targetTpl.Execute(sb, meta)
+		err := gtemplate.Execute(sb, n.targetTpl, meta)
 		if err != nil {
 			return "", err
 		}
@@ -720,6 +823,7 @@ func (n *jetstreamOutput) createStream(js nats.JetStreamContext) error {
 		MaxBytes:    n.Cfg.CreateStream.MaxBytes,
 		MaxAge:      n.Cfg.CreateStream.MaxAge,
 		MaxMsgSize:  n.Cfg.CreateStream.MaxMsgSize,
+		Duplicates:  n.Cfg.CreateStream.DuplicateWindow,
 	}
 	_, err = js.AddStream(streamConfig)
 	return err
@@ -0,0 +1,144 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package jetstream_output
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/gtemplate"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+func TestExecMsgIDTemplate(t *testing.T) {
+	tpl, err := gtemplate.CreateTemplate("msg-id-template", `{{ .name }}-{{ .timestamp }}-{{ index .tags "source" }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := execMsgIDTemplate([]byte(`{"name":"sub1","timestamp":1704164645123456789,"tags":{"source":"r1"}}`), tpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "sub1-1704164645123456789-r1" {
+		t.Fatalf("unexpected message ID: %q", id)
+	}
+	_, err = execMsgIDTemplate([]byte("not json"), tpl)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func runJetStreamServer(t *testing.T) *server.Server {
+	ns, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoSigs:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ns.Start()
+	if !ns.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server not ready")
+	}
+	t.Cleanup(ns.Shutdown)
+	return ns
+}
+
+func waitAck(t *testing.T, ch chan error) error {
+	select {
+	case err := <-ch:
+		return err
+	case <-time.After(10 * time.Second):
+		t.Fatal("write not acknowledged")
+	}
+	return nil
+}
+
+func TestWriteEventAck(t *testing.T) {
+	ns := runJetStreamServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	o := outputs.Outputs["jetstream"]().(*jetstreamOutput)
+	err := o.Init(ctx, "js1", map[string]interface{}{
+		"address": ns.ClientURL(),
+		"stream":  "telemetry",
+		"subject": "gnmic",
+		"create-stream": map[string]interface{}{
+			"subjects": []string{"telemetry.>"},
+			"storage":  "memory",
+		},
+		"connect-time-wait": "100ms",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var _ outputs.Acknowledger = o
+
+	ev := &formatters.EventMsg{
+		Name:      "sub1",
+		Timestamp: time.Now().UnixNano(),
+		Tags:      map[string]string{"source": "r1"},
+		Values:    map[string]interface{}{"cpu": 10},
+	}
+	acks := make(chan error, 1)
+	o.WriteEventAck(ctx, ev, func(err error) { acks <- err })
+	if err := waitAck(t, acks); err != nil {
+		t.Fatalf("unexpected ack error: %v", err)
+	}
+	nc, err := nats.Connect(ns.ClientURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nc.Close()
+	js, err := nc.JetStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	si, err := js.StreamInfo("telemetry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if si.State.Msgs != 1 {
+		t.Fatalf("got %d messages in the stream, want 1", si.State.Msgs)
+	}
+
+	// a subject not bound to a stream is not acknowledged by the server
+	o2 := outputs.Outputs["jetstream"]().(*jetstreamOutput)
+	err = o2.Init(ctx, "js2", map[string]interface{}{
+		"address":           ns.ClientURL(),
+		"stream":            "missing",
+		"subject":           "gnmic",
+		"connect-time-wait": "100ms",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	o2.WriteEventAck(ctx, ev, func(err error) { acks <- err })
+	if err := waitAck(t, acks); err == nil {
+		t.Fatal("expected an ack error")
+	}
+
+	// the ack function is called when the context is done
+	cctx, ccancel := context.WithCancel(ctx)
+	ccancel()
+	o3 := &jetstreamOutput{Cfg: &config{WriteTimeout: time.Second}}
+	o3.WriteEventAck(cctx, ev, func(err error) { acks <- err })
+	if err := waitAck(t, acks); err == nil {
+		t.Fatal("expected an ack error")
+	}
+}
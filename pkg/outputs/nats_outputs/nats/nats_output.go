@@ -79,6 +79,8 @@ type Config struct {
 	ConnectTimeWait    time.Duration    `mapstructure:"connect-time-wait,omitempty"`
 	TLS                *types.TLSConfig `mapstructure:"tls,omitempty" json:"tls,omitempty"`
 	Format             string           `mapstructure:"format,omitempty"`
+	EventEnvelope      bool             `mapstructure:"event-envelope,omitempty"`
+	EventSchemaURL     string           `mapstructure:"event-schema-url,omitempty"`
 	SplitEvents        bool             `mapstructure:"split-events,omitempty"`
 	AddTarget          string           `mapstructure:"add-target,omitempty"`
 	TargetTemplate     string           `mapstructure:"target-template,omitempty"`
@@ -148,8 +150,10 @@ func (n *NatsOutput) Init(ctx context.Context, name string, cfg map[string]inter
 	n.msgChan = make(chan *outputs.ProtoMsg)
 	initMetrics()
 	n.mo = &formatters.MarshalOptions{
-		Format:     n.Cfg.Format,
-		OverrideTS: n.Cfg.OverrideTimestamps,
+		Format:         n.Cfg.Format,
+		OverrideTS:     n.Cfg.OverrideTimestamps,
+		EventEnvelope:  n.Cfg.EventEnvelope,
+		EventSchemaURL: n.Cfg.EventSchemaURL,
 	}
 	if n.Cfg.TargetTemplate == "" {
 		n.targetTpl = outputs.DefaultTargetTemplate
@@ -188,9 +192,12 @@ func (n *NatsOutput) setDefaults() error {
 	if n.Cfg.Format == "" {
 		n.Cfg.Format = defaultFormat
 	}
-	if !(n.Cfg.Format == "event" || n.Cfg.Format == "protojson" || n.Cfg.Format == "proto" || n.Cfg.Format == "json") {
+	if !(formatters.IsEventFormat(n.Cfg.Format) || n.Cfg.Format == "protojson" || n.Cfg.Format == "proto" || n.Cfg.Format == "json") {
 		return fmt.Errorf("unsupported output format '%s' for output type NATS", n.Cfg.Format)
 	}
+	if n.Cfg.MsgTemplate != "" && formatters.IsBinaryFormat(n.Cfg.Format) {
+		return fmt.Errorf("msg-template is not supported with format '%s'", n.Cfg.Format)
+	}
 	if n.Cfg.Address == "" {
 		n.Cfg.Address = defaultAddress
 	}
@@ -236,7 +243,12 @@ func (n *NatsOutput) Write(ctx context.Context, rsp proto.Message, meta outputs.
 	}
 }
 
-func (n *NatsOutput) WriteEvent(ctx context.Context, ev *formatters.EventMsg) {}
+func (n *NatsOutput) WriteEvent(ctx context.Context, ev *formatters.EventMsg) {
+	err := outputs.WriteEventAsResponse(ctx, n, ev)
+	if err != nil {
+		n.logger.Printf("%v", err)
+	}
+}
 
 // Close //
 func (n *NatsOutput) Close() error {
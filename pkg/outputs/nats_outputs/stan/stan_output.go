@@ -81,6 +81,8 @@ type Config struct {
 	PingInterval       int           `mapstructure:"ping-interval,omitempty"`
 	PingRetry          int           `mapstructure:"ping-retry,omitempty"`
 	Format             string        `mapstructure:"format,omitempty"`
+	EventEnvelope      bool          `mapstructure:"event-envelope,omitempty"`
+	EventSchemaURL     string        `mapstructure:"event-schema-url,omitempty"`
 	AddTarget          string        `mapstructure:"add-target,omitempty"`
 	TargetTemplate     string        `mapstructure:"target-template,omitempty"`
 	OverrideTimestamps bool          `mapstructure:"override-timestamps,omitempty"`
@@ -146,8 +148,10 @@ func (s *StanOutput) Init(ctx context.Context, name string, cfg map[string]inter
 	s.msgChan = make(chan *outputs.ProtoMsg)
 
 	s.mo = &formatters.MarshalOptions{
-		Format:     s.Cfg.Format,
-		OverrideTS: s.Cfg.OverrideTimestamps,
+		Format:         s.Cfg.Format,
+		OverrideTS:     s.Cfg.OverrideTimestamps,
+		EventEnvelope:  s.Cfg.EventEnvelope,
+		EventSchemaURL: s.Cfg.EventSchemaURL,
 	}
 
 	if s.Cfg.TargetTemplate == "" {
@@ -236,7 +240,12 @@ func (s *StanOutput) Write(ctx context.Context, rsp protoreflect.ProtoMessage, m
 	}
 }
 
-func (s *StanOutput) WriteEvent(ctx context.Context, ev *formatters.EventMsg) {}
+func (s *StanOutput) WriteEvent(ctx context.Context, ev *formatters.EventMsg) {
+	err := outputs.WriteEventAsResponse(ctx, s, ev)
+	if err != nil {
+		s.logger.Printf("%v", err)
+	}
+}
 
 // Metrics //
 func (s *StanOutput) RegisterMetrics(reg *prometheus.Registry) {
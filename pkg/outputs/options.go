@@ -60,3 +60,14 @@ func WithTargetsConfig(tcs map[string]*types.TargetConfig) Option {
 		return nil
 	}
 }
+
+// WithOutputs gives access to the already initialized outputs
+// to the outputs that forward messages to other outputs.
+func WithOutputs(outs map[string]Output) Option {
+	return func(o Output) error {
+		if s, ok := o.(OutputsSetter); ok {
+			s.SetOutputs(outs)
+		}
+		return nil
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/openconfig/gnmic/pkg/api/utils"
 	"github.com/openconfig/gnmic/pkg/formatters"
 	_ "github.com/openconfig/gnmic/pkg/formatters/all"
+	"github.com/openconfig/gnmic/pkg/gtemplate"
 )
 
 type Output interface {
@@ -44,6 +45,13 @@ type Output interface {
 	SetTargetsConfig(map[string]*types.TargetConfig)
 }
 
+// Drainer is implemented by outputs queuing messages before writing them.
+// Drain is called before Close on shutdown, it writes the queued messages
+// and returns the number of messages still queued when ctx is done.
+type Drainer interface {
+	Drain(ctx context.Context) (int, error)
+}
+
 type Initializer func() Output
 
 var Outputs = map[string]Initializer{}
@@ -62,6 +70,11 @@ var OutputTypes = map[string]struct{}{
 	"jetstream":        {},
 	"snmp":             {},
 	"asciigraph":       {},
+	"failover":         {},
+	"mirror":           {},
+	"http":             {},
+	"relay":            {},
+	"syslog":           {},
 }
 
 func Register(name string, initFn Initializer) {
@@ -70,6 +83,20 @@ func Register(name string, initFn Initializer) {
 
 type Meta map[string]string
 
+// WriteEventAsResponse converts an event back to a gNMI notification and writes it using the output Write method.
+// It is used by the outputs handling only gNMI messages.
+func WriteEventAsResponse(ctx context.Context, o Output, ev *formatters.EventMsg) error {
+	rsp, meta, err := formatters.EventMsgToResponse(ev)
+	if err != nil {
+		return fmt.Errorf("failed to convert event to gNMI notification: %v", err)
+	}
+	if rsp == nil {
+		return nil
+	}
+	o.Write(ctx, rsp, meta)
+	return nil
+}
+
 func DecodeConfig(src, dst interface{}) error {
 	decoder, err := mapstructure.NewDecoder(
 		&mapstructure.DecoderConfig{
@@ -101,7 +128,7 @@ func AddSubscriptionTarget(msg proto.Message, meta Meta, addTarget string, tpl *
 			switch addTarget {
 			case "overwrite":
 				sb := new(strings.Builder)
-				err := tpl.Execute(sb, meta)
+				err := gtemplate.Execute(sb, tpl, meta)
 				if err != nil {
 					return nil, err
 				}
@@ -110,7 +137,7 @@ func AddSubscriptionTarget(msg proto.Message, meta Meta, addTarget string, tpl *
 			case "if-not-present":
 				if rrsp.Update.Prefix.Target == "" {
 					sb := new(strings.Builder)
-					err := tpl.Execute(sb, meta)
+					err := gtemplate.Execute(sb, tpl, meta)
 					if err != nil {
 						return nil, err
 					}
@@ -130,7 +157,7 @@ func ExecTemplate(content []byte, tpl *template.Template) ([]byte, error) {
 		return nil, fmt.Errorf("failed to marshal input: %v", err)
 	}
 	bf := new(bytes.Buffer)
-	err = tpl.Execute(bf, input)
+	err = gtemplate.Execute(bf, tpl, input)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute msg template: %v", err)
 	}
@@ -159,7 +186,7 @@ const (
 
 func Marshal(pmsg protoreflect.ProtoMessage, meta map[string]string, mo *formatters.MarshalOptions, splitEvents bool, evps ...formatters.EventProcessor) ([][]byte, error) {
 	switch mo.Format {
-	case "event":
+	case "event", "cbor", "msgpack":
 		if splitEvents {
 			return marshalSplit(pmsg, meta, mo, evps...)
 		}
@@ -192,14 +219,8 @@ func marshalSplit(pmsg protoreflect.ProtoMessage, meta map[string]string, mo *fo
 				return nil, nil
 			}
 			rs := make([][]byte, 0, numEvents)
-			marshalFn := json.Marshal
-			if mo.Multiline {
-				marshalFn = func(v any) ([]byte, error) {
-					return json.MarshalIndent(v, "", mo.Indent)
-				}
-			}
 			for _, ev := range events {
-				b, err := marshalFn(ev)
+				b, err := mo.MarshalEvent(ev)
 				if err != nil {
 					return nil, err
 				}
@@ -48,6 +48,8 @@ type PromMetric struct {
 
 	labels []prompb.Label
 	value  float64
+	// set if the metric is a histogram
+	histogram *formatters.Histogram
 }
 
 // Metric
@@ -90,7 +92,11 @@ func (p *PromMetric) String() string {
 		}
 		sb.WriteString("],")
 	}
-	sb.WriteString(fmt.Sprintf("value=%f,", p.value))
+	if p.histogram != nil {
+		sb.WriteString(fmt.Sprintf("histogram=%+v,", *p.histogram))
+	} else {
+		sb.WriteString(fmt.Sprintf("value=%f,", p.value))
+	}
 	sb.WriteString("time=")
 	if p.Time != nil {
 		sb.WriteString(p.Time.String())
@@ -114,8 +120,12 @@ func (p *PromMetric) Desc() *prometheus.Desc {
 
 // Write implements prometheus.Metric
 func (p *PromMetric) Write(out *dto.Metric) error {
-	out.Untyped = &dto.Untyped{
-		Value: &p.value,
+	if p.histogram != nil {
+		out.Histogram = dtoHistogram(p.histogram)
+	} else {
+		out.Untyped = &dto.Untyped{
+			Value: &p.value,
+		}
 	}
 	out.Label = make([]*dto.LabelPair, 0, len(p.labels))
 	for i := range p.labels {
@@ -133,6 +143,17 @@ func (mb *MetricBuilder) MetricsFromEvent(ev *formatters.EventMsg, now time.Time
 	pms := make([]*PromMetric, 0, len(ev.Values))
 	labels := mb.GetLabels(ev)
 	for vName, val := range ev.Values {
+		if h, ok := formatters.ToHistogram(val); ok {
+			pm := &PromMetric{
+				Name:      mb.MetricName(ev.Name, vName),
+				labels:    labels,
+				histogram: h,
+				AddedAt:   now,
+			}
+			mb.setTime(pm, ev, now)
+			pms = append(pms, pm)
+			continue
+		}
 		v, err := toFloat(val)
 		if err != nil {
 			if !mb.StringsAsLabels {
@@ -146,18 +167,61 @@ func (mb *MetricBuilder) MetricsFromEvent(ev *formatters.EventMsg, now time.Time
 			value:   v,
 			AddedAt: now,
 		}
-		if mb.OverrideTimestamps && mb.ExportTimestamps {
-			ev.Timestamp = now.UnixNano()
-		}
-		if mb.ExportTimestamps {
-			tm := time.Unix(0, ev.Timestamp)
-			pm.Time = &tm
-		}
+		mb.setTime(pm, ev, now)
 		pms = append(pms, pm)
 	}
 	return pms
 }
 
+func (mb *MetricBuilder) setTime(pm *PromMetric, ev *formatters.EventMsg, now time.Time) {
+	if mb.OverrideTimestamps && mb.ExportTimestamps {
+		ev.Timestamp = now.UnixNano()
+	}
+	if mb.ExportTimestamps {
+		tm := time.Unix(0, ev.Timestamp)
+		pm.Time = &tm
+	}
+}
+
+// DeletedMetricsMatcher returns a function matching the metrics removed by the delete event ev,
+// i.e the metrics of the deleted paths or of their children, with all the event labels.
+// It returns nil if ev has no deletes.
+func (m *MetricBuilder) DeletedMetricsMatcher(ev *formatters.EventMsg) func(pm *PromMetric) bool {
+	if len(ev.Deletes) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(ev.Deletes))
+	for _, d := range ev.Deletes {
+		names = append(names, m.MetricName(ev.Name, d))
+	}
+	delLabels := m.GetLabels(ev)
+	return func(pm *PromMetric) bool {
+		matched := false
+		for _, n := range names {
+			if pm.Name == n || strings.HasPrefix(pm.Name, n+"_") {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	LABELS:
+		for _, dl := range delLabels {
+			for _, l := range pm.labels {
+				if l.Name == dl.Name {
+					if l.Value != dl.Value {
+						return false
+					}
+					continue LABELS
+				}
+			}
+			return false
+		}
+		return true
+	}
+}
+
 type MetricBuilder struct {
 	Prefix                 string
 	AppendSubscriptionName bool
@@ -262,6 +326,8 @@ func (m *MetricBuilder) MetricName(measName, valueName string) string {
 
 type NamedTimeSeries struct {
 	Name string
+	// metric family type, used in the remote write metadata
+	Type prompb.MetricMetadata_MetricType
 	TS   *prompb.TimeSeries
 }
 
@@ -270,6 +336,10 @@ func (m *MetricBuilder) TimeSeriesFromEvent(ev *formatters.EventMsg) []*NamedTim
 	tsLabels := m.GetLabels(ev)
 	timestamp := ev.Timestamp / int64(time.Millisecond)
 	for k, v := range ev.Values {
+		if h, ok := formatters.ToHistogram(v); ok {
+			promTS = append(promTS, m.histogramTimeSeries(m.MetricName(ev.Name, k), tsLabels, timestamp, h)...)
+			continue
+		}
 		fv, err := toFloat(v)
 		if err != nil {
 			if !m.StringsAsLabels {
@@ -278,24 +348,11 @@ func (m *MetricBuilder) TimeSeriesFromEvent(ev *formatters.EventMsg) []*NamedTim
 			fv = 1.0
 		}
 		tsName := m.MetricName(ev.Name, k)
-		tsLabelsWithName := make([]prompb.Label, 0, len(tsLabels)+1)
-		tsLabelsWithName = append(tsLabelsWithName, tsLabels...)
-		tsLabelsWithName = append(tsLabelsWithName,
-			prompb.Label{
-				Name:  labels.MetricName,
-				Value: tsName,
-			})
-
-		// The prometheus spec requires label names to be sorted
-		// https://prometheus.io/docs/concepts/remote_write_spec/
-		slices.SortFunc(tsLabelsWithName, func(a prompb.Label, b prompb.Label) int {
-			return cmp.Compare(a.Name, b.Name)
-		})
-
 		nts := &NamedTimeSeries{
 			Name: tsName,
+			Type: prompb.MetricMetadata_COUNTER,
 			TS: &prompb.TimeSeries{
-				Labels: tsLabelsWithName,
+				Labels: sortedLabels(tsLabels, tsName),
 				Samples: []prompb.Sample{
 					{
 						Value:     fv,
@@ -308,3 +365,170 @@ func (m *MetricBuilder) TimeSeriesFromEvent(ev *formatters.EventMsg) []*NamedTim
 	}
 	return promTS
 }
+
+// sortedLabels returns the labels lbs with the metric name label set to name.
+func sortedLabels(lbs []prompb.Label, name string, extra ...prompb.Label) []prompb.Label {
+	tsLabels := make([]prompb.Label, 0, len(lbs)+len(extra)+1)
+	tsLabels = append(tsLabels, lbs...)
+	tsLabels = append(tsLabels, extra...)
+	tsLabels = append(tsLabels,
+		prompb.Label{
+			Name:  labels.MetricName,
+			Value: name,
+		})
+
+	// The prometheus spec requires label names to be sorted
+	// https://prometheus.io/docs/concepts/remote_write_spec/
+	slices.SortFunc(tsLabels, func(a prompb.Label, b prompb.Label) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return tsLabels
+}
+
+// histogramTimeSeries returns the time series of histogram h:
+// a native histogram series if h has native buckets,
+// and the classic _bucket, _sum and _count series if h has classic buckets.
+func (m *MetricBuilder) histogramTimeSeries(name string, lbs []prompb.Label, timestamp int64, h *formatters.Histogram) []*NamedTimeSeries {
+	promTS := make([]*NamedTimeSeries, 0, len(h.Buckets)+3)
+	if h.IsNative() {
+		pSpans, pDeltas := nativeSpans(h.PositiveBuckets)
+		nSpans, nDeltas := nativeSpans(h.NegativeBuckets)
+		promTS = append(promTS, &NamedTimeSeries{
+			Name: name,
+			Type: prompb.MetricMetadata_HISTOGRAM,
+			TS: &prompb.TimeSeries{
+				Labels: sortedLabels(lbs, name),
+				Histograms: []prompb.Histogram{
+					{
+						Count:          &prompb.Histogram_CountInt{CountInt: h.Count},
+						Sum:            h.Sum,
+						Schema:         *h.Schema,
+						ZeroThreshold:  h.ZeroThreshold,
+						ZeroCount:      &prompb.Histogram_ZeroCountInt{ZeroCountInt: h.ZeroCount},
+						NegativeSpans:  promSpans(nSpans),
+						NegativeDeltas: nDeltas,
+						PositiveSpans:  promSpans(pSpans),
+						PositiveDeltas: pDeltas,
+						Timestamp:      timestamp,
+					},
+				},
+			},
+		})
+	}
+	if len(h.Buckets) == 0 {
+		return promTS
+	}
+	sample := func(n string, v float64, extra ...prompb.Label) *NamedTimeSeries {
+		return &NamedTimeSeries{
+			Name: name,
+			Type: prompb.MetricMetadata_HISTOGRAM,
+			TS: &prompb.TimeSeries{
+				Labels:  sortedLabels(lbs, n, extra...),
+				Samples: []prompb.Sample{{Value: v, Timestamp: timestamp}},
+			},
+		}
+	}
+	for _, b := range h.Buckets {
+		promTS = append(promTS, sample(name+"_bucket", float64(b.Count),
+			prompb.Label{Name: "le", Value: strconv.FormatFloat(b.UpperBound, 'f', -1, 64)}))
+	}
+	promTS = append(promTS,
+		sample(name+"_bucket", float64(h.Count), prompb.Label{Name: "le", Value: "+Inf"}),
+		sample(name+"_sum", h.Sum),
+		sample(name+"_count", float64(h.Count)),
+	)
+	return promTS
+}
+
+type nativeSpan struct {
+	offset int32
+	length uint32
+}
+
+// nativeSpans returns the spans and the count deltas of the native buckets,
+// as expected by the Prometheus native histograms encoding.
+func nativeSpans(buckets map[int]uint64) ([]nativeSpan, []int64) {
+	if len(buckets) == 0 {
+		return nil, nil
+	}
+	idx := make([]int, 0, len(buckets))
+	for i := range buckets {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+	spans := make([]nativeSpan, 0, 1)
+	deltas := make([]int64, 0, len(idx))
+	var prevCount int64
+	for j, i := range idx {
+		switch {
+		case j == 0:
+			spans = append(spans, nativeSpan{offset: int32(i), length: 1})
+		case i == idx[j-1]+1:
+			spans[len(spans)-1].length++
+		default:
+			spans = append(spans, nativeSpan{offset: int32(i - idx[j-1] - 1), length: 1})
+		}
+		c := int64(buckets[i])
+		deltas = append(deltas, c-prevCount)
+		prevCount = c
+	}
+	return spans, deltas
+}
+
+func promSpans(spans []nativeSpan) []prompb.BucketSpan {
+	if len(spans) == 0 {
+		return nil
+	}
+	r := make([]prompb.BucketSpan, 0, len(spans))
+	for _, s := range spans {
+		r = append(r, prompb.BucketSpan{Offset: s.offset, Length: s.length})
+	}
+	return r
+}
+
+// dtoHistogram returns the scrape representation of histogram h.
+func dtoHistogram(h *formatters.Histogram) *dto.Histogram {
+	count := h.Count
+	sum := h.Sum
+	dh := &dto.Histogram{
+		SampleCount: &count,
+		SampleSum:   &sum,
+	}
+	for i := range h.Buckets {
+		dh.Bucket = append(dh.Bucket, &dto.Bucket{
+			CumulativeCount: &h.Buckets[i].Count,
+			UpperBound:      &h.Buckets[i].UpperBound,
+		})
+	}
+	if !h.IsNative() {
+		return dh
+	}
+	schema := *h.Schema
+	zeroThreshold := h.ZeroThreshold
+	zeroCount := h.ZeroCount
+	dh.Schema = &schema
+	dh.ZeroThreshold = &zeroThreshold
+	dh.ZeroCount = &zeroCount
+	pSpans, pDeltas := nativeSpans(h.PositiveBuckets)
+	nSpans, nDeltas := nativeSpans(h.NegativeBuckets)
+	dh.PositiveSpan, dh.PositiveDelta = dtoSpans(pSpans), pDeltas
+	dh.NegativeSpan, dh.NegativeDelta = dtoSpans(nSpans), nDeltas
+	if len(dh.PositiveSpan) == 0 && len(dh.NegativeSpan) == 0 && zeroCount == 0 {
+		// an empty span marks the histogram as native
+		// when it has no populated bucket.
+		dh.PositiveSpan = []*dto.BucketSpan{{Offset: new(int32), Length: new(uint32)}}
+	}
+	return dh
+}
+
+func dtoSpans(spans []nativeSpan) []*dto.BucketSpan {
+	if len(spans) == 0 {
+		return nil
+	}
+	r := make([]*dto.BucketSpan, 0, len(spans))
+	for _, s := range spans {
+		offset, length := s.offset, s.length
+		r = append(r, &dto.BucketSpan{Offset: &offset, Length: &length})
+	}
+	return r
+}
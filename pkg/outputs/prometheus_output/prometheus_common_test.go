@@ -12,8 +12,10 @@ import (
 	"cmp"
 	"slices"
 	"testing"
+	"time"
 
 	"github.com/openconfig/gnmic/pkg/formatters"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/prompb"
 )
@@ -147,3 +149,162 @@ func BenchmarkMetricName(b *testing.B) {
 		})
 	}
 }
+
+func TestDeletedMetricsMatcher(t *testing.T) {
+	mb := &MetricBuilder{Prefix: "gnmic"}
+	if mb.DeletedMetricsMatcher(&formatters.EventMsg{Name: "sub"}) != nil {
+		t.Fatalf("expected a nil matcher for an event without deletes")
+	}
+	del := &formatters.EventMsg{
+		Name:    "sub",
+		Tags:    map[string]string{"source": "r1", "interface_name": "eth1"},
+		Deletes: []string{"/interfaces/interface/state"},
+	}
+	match := mb.DeletedMetricsMatcher(del)
+	tcs := map[string]struct {
+		ev   *formatters.EventMsg
+		want bool
+	}{
+		"child_path": {
+			ev: &formatters.EventMsg{
+				Name:   "sub",
+				Tags:   map[string]string{"source": "r1", "interface_name": "eth1"},
+				Values: map[string]interface{}{"/interfaces/interface/state/counters/in-octets": 1},
+			},
+			want: true,
+		},
+		"extra_labels": {
+			ev: &formatters.EventMsg{
+				Name:   "sub",
+				Tags:   map[string]string{"source": "r1", "interface_name": "eth1", "subscription-name": "sub"},
+				Values: map[string]interface{}{"/interfaces/interface/state/oper-status": 1},
+			},
+			want: true,
+		},
+		"other_key": {
+			ev: &formatters.EventMsg{
+				Name:   "sub",
+				Tags:   map[string]string{"source": "r1", "interface_name": "eth2"},
+				Values: map[string]interface{}{"/interfaces/interface/state/counters/in-octets": 1},
+			},
+			want: false,
+		},
+		"other_path": {
+			ev: &formatters.EventMsg{
+				Name:   "sub",
+				Tags:   map[string]string{"source": "r1", "interface_name": "eth1"},
+				Values: map[string]interface{}{"/interfaces/interface/statex": 1},
+			},
+			want: false,
+		},
+	}
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			for _, pm := range mb.MetricsFromEvent(tc.ev, time.Now()) {
+				if got := match(pm); got != tc.want {
+					t.Errorf("metric %q: expected %v, got %v", pm.Name, tc.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestNativeSpans(t *testing.T) {
+	spans, deltas := nativeSpans(map[int]uint64{-2: 3, -1: 1, 2: 4})
+	wantSpans := []nativeSpan{{offset: -2, length: 2}, {offset: 2, length: 1}}
+	wantDeltas := []int64{3, -2, 3}
+	if !slices.Equal(spans, wantSpans) {
+		t.Errorf("expected spans %v, got %v", wantSpans, spans)
+	}
+	if !slices.Equal(deltas, wantDeltas) {
+		t.Errorf("expected deltas %v, got %v", wantDeltas, deltas)
+	}
+}
+
+func histogramEvent() *formatters.EventMsg {
+	schema := int32(0)
+	h := formatters.NewHistogram([]float64{1, 10}, &schema, 0)
+	for _, v := range []float64{0.5, 2, 20} {
+		h.Observe(v)
+	}
+	return &formatters.EventMsg{
+		Name:      "sub",
+		Timestamp: 12345 * int64(time.Millisecond),
+		Tags:      map[string]string{"source": "r1"},
+		Values:    map[string]interface{}{"latency": h},
+	}
+}
+
+func TestTimeSeriesFromHistogramEvent(t *testing.T) {
+	mb := &MetricBuilder{}
+	series := make(map[string]*prompb.TimeSeries)
+	for _, nts := range mb.TimeSeriesFromEvent(histogramEvent()) {
+		if nts.Name != "latency" || nts.Type != prompb.MetricMetadata_HISTOGRAM {
+			t.Errorf("unexpected name %q or type %v", nts.Name, nts.Type)
+		}
+		key := ""
+		for _, l := range nts.TS.Labels {
+			key += l.Name + "=" + l.Value + ","
+		}
+		series[key] = nts.TS
+	}
+	wantSamples := map[string]float64{
+		"__name__=latency_bucket,le=1,source=r1,":    1,
+		"__name__=latency_bucket,le=10,source=r1,":   2,
+		"__name__=latency_bucket,le=+Inf,source=r1,": 3,
+		"__name__=latency_sum,source=r1,":            22.5,
+		"__name__=latency_count,source=r1,":          3,
+	}
+	for k, v := range wantSamples {
+		ts, ok := series[k]
+		if !ok {
+			t.Errorf("missing series %s", k)
+			continue
+		}
+		if len(ts.Samples) != 1 || ts.Samples[0].Value != v || ts.Samples[0].Timestamp != 12345 {
+			t.Errorf("series %s: unexpected samples %v", k, ts.Samples)
+		}
+	}
+	native, ok := series["__name__=latency,source=r1,"]
+	if !ok || len(native.Histograms) != 1 {
+		t.Fatalf("missing native histogram series")
+	}
+	nh := native.Histograms[0]
+	if nh.GetCountInt() != 3 || nh.Sum != 22.5 || nh.Schema != 0 || nh.Timestamp != 12345 {
+		t.Errorf("unexpected native histogram: %+v", nh)
+	}
+	// 0.5 -> bucket -1, 2 -> bucket 1, 20 -> bucket 5
+	wantSpans := []prompb.BucketSpan{{Offset: -1, Length: 1}, {Offset: 1, Length: 1}, {Offset: 3, Length: 1}}
+	spansEqual := slices.EqualFunc(nh.PositiveSpans, wantSpans, func(a, b prompb.BucketSpan) bool {
+		return a.Offset == b.Offset && a.Length == b.Length
+	})
+	if !spansEqual || !slices.Equal(nh.PositiveDeltas, []int64{1, 0, 0}) {
+		t.Errorf("unexpected positive buckets: %v %v", nh.PositiveSpans, nh.PositiveDeltas)
+	}
+	if len(series) != len(wantSamples)+1 {
+		t.Errorf("unexpected number of series: %d", len(series))
+	}
+}
+
+func TestHistogramMetricWrite(t *testing.T) {
+	mb := &MetricBuilder{}
+	pms := mb.MetricsFromEvent(histogramEvent(), time.Now())
+	if len(pms) != 1 {
+		t.Fatalf("expected 1 metric, got %d", len(pms))
+	}
+	out := new(dto.Metric)
+	if err := pms[0].Write(out); err != nil {
+		t.Fatal(err)
+	}
+	h := out.GetHistogram()
+	if h == nil || out.Untyped != nil {
+		t.Fatalf("expected a histogram metric, got %v", out)
+	}
+	if h.GetSampleCount() != 3 || h.GetSampleSum() != 22.5 || len(h.GetBucket()) != 2 {
+		t.Errorf("unexpected classic histogram: %v", h)
+	}
+	if h.GetSchema() != 0 || h.GetZeroThreshold() != formatters.DefaultNativeHistogramZeroThreshold ||
+		len(h.GetPositiveSpan()) != 3 || len(h.GetPositiveDelta()) != 3 {
+		t.Errorf("unexpected native histogram: %v", h)
+	}
+}
@@ -31,6 +31,14 @@ var prometheusNumberOfCachedMetrics = prometheus.NewGauge(
 		Help:      "Number of metrics cached by the prometheus output",
 	})
 
+var prometheusEvictedMetrics = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "evicted_metrics_total",
+		Help:      "Number of metrics evicted by the prometheus output per reason",
+	}, []string{"reason"})
+
 func (p *prometheusOutput) initMetrics() {
 	if p.cfg.CacheConfig == nil {
 		prometheusNumberOfMetrics.Set(0)
@@ -42,6 +50,9 @@ func (p *prometheusOutput) initMetrics() {
 func (p *prometheusOutput) registerMetrics(reg *prometheus.Registry) error {
 	p.initMetrics()
 	if p.cfg.CacheConfig == nil {
+		if err := reg.Register(prometheusEvictedMetrics); err != nil {
+			return err
+		}
 		return reg.Register(prometheusNumberOfMetrics)
 	}
 	return reg.Register(prometheusNumberOfCachedMetrics)
@@ -9,9 +9,11 @@
 package prometheus_output
 
 import (
+	"container/list"
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -34,6 +36,7 @@ import (
 	"github.com/openconfig/gnmic/pkg/api/types"
 	"github.com/openconfig/gnmic/pkg/api/utils"
 	"github.com/openconfig/gnmic/pkg/cache"
+	"github.com/openconfig/gnmic/pkg/cert"
 	"github.com/openconfig/gnmic/pkg/formatters"
 	"github.com/openconfig/gnmic/pkg/gtemplate"
 	"github.com/openconfig/gnmic/pkg/outputs"
@@ -55,12 +58,14 @@ const (
 func init() {
 	outputs.Register(outputType, func() outputs.Output {
 		return &prometheusOutput{
-			cfg:       &config{},
-			eventChan: make(chan *formatters.EventMsg),
-			msgChan:   make(chan *outputs.ProtoMsg),
-			wg:        new(sync.WaitGroup),
-			entries:   make(map[uint64]*promcom.PromMetric),
-			logger:    log.New(io.Discard, loggingPrefix, utils.DefaultLoggingFlags),
+			cfg:         &config{},
+			eventChan:   make(chan *formatters.EventMsg),
+			msgChan:     make(chan *outputs.ProtoMsg),
+			wg:          new(sync.WaitGroup),
+			entries:     make(map[uint64]*list.Element),
+			lru:         list.New(),
+			expirations: make(map[string]time.Duration),
+			logger:      log.New(io.Discard, loggingPrefix, utils.DefaultLoggingFlags),
 		}
 	})
 }
@@ -74,7 +79,11 @@ type prometheusOutput struct {
 	wg     *sync.WaitGroup
 	server *http.Server
 	sync.Mutex
-	entries map[uint64]*promcom.PromMetric
+	entries map[uint64]*list.Element
+	// stored metrics, from the least to the most recently updated
+	lru *list.List
+	// expiration per metric name
+	expirations map[string]time.Duration
 
 	mb           *promcom.MetricBuilder
 	evps         []formatters.EventProcessor
@@ -90,8 +99,11 @@ type config struct {
 	Name                   string               `mapstructure:"name,omitempty" json:"name,omitempty"`
 	Listen                 string               `mapstructure:"listen,omitempty" json:"listen,omitempty"`
 	TLS                    *types.TLSConfig     `mapstructure:"tls,omitempty" json:"tls,omitempty"`
+	ACME                   *cert.ACMEConfig     `mapstructure:"acme,omitempty" json:"acme,omitempty"`
 	Path                   string               `mapstructure:"path,omitempty" json:"path,omitempty"`
 	Expiration             time.Duration        `mapstructure:"expiration,omitempty" json:"expiration,omitempty"`
+	ExpirationGroups       []*expirationGroup   `mapstructure:"expiration-groups,omitempty" json:"expiration-groups,omitempty"`
+	MaxSeries              int                  `mapstructure:"max-series,omitempty" json:"max-series,omitempty"`
 	MetricPrefix           string               `mapstructure:"metric-prefix,omitempty" json:"metric-prefix,omitempty"`
 	AppendSubscriptionName bool                 `mapstructure:"append-subscription-name,omitempty" json:"append-subscription-name,omitempty"`
 	ExportTimestamps       bool                 `mapstructure:"export-timestamps,omitempty" json:"export-timestamps,omitempty"`
@@ -174,6 +186,22 @@ func (p *prometheusOutput) Init(ctx context.Context, name string, cfg map[string
 	if err != nil {
 		return err
 	}
+	if p.cfg.TLS != nil && p.cfg.ACME != nil {
+		return errors.New("`tls` and `acme` are mutually exclusive")
+	}
+	if p.cfg.ACME != nil {
+		err = p.cfg.ACME.SetDefaults()
+		if err != nil {
+			return err
+		}
+	}
+	if p.cfg.MaxSeries < 0 {
+		return fmt.Errorf("invalid max-series value %d", p.cfg.MaxSeries)
+	}
+	err = p.initExpirationGroups()
+	if err != nil {
+		return err
+	}
 
 	p.mb = &promcom.MetricBuilder{
 		Prefix:                 p.cfg.MetricPrefix,
@@ -212,9 +240,18 @@ func (p *prometheusOutput) Init(ctx context.Context, name string, cfg map[string
 		Handler: mux,
 	}
 
+	wctx, wcancel := context.WithCancel(ctx)
 	// create tcp listener
 	var listener net.Listener
 	switch {
+	case p.cfg.ACME != nil:
+		am := cert.NewACME(p.cfg.ACME, p.logger)
+		err = am.Start(wctx)
+		if err != nil {
+			wcancel()
+			return err
+		}
+		listener, err = tls.Listen("tcp", p.cfg.Listen, am.TLSConfig())
 	case p.cfg.TLS == nil:
 		listener, err = net.Listen("tcp", p.cfg.Listen)
 	default:
@@ -228,16 +265,17 @@ func (p *prometheusOutput) Init(ctx context.Context, name string, cfg map[string
 			true,
 		)
 		if err != nil {
+			wcancel()
 			return err
 		}
 		listener, err = tls.Listen("tcp", p.cfg.Listen, tlsConfig)
 	}
 	if err != nil {
+		wcancel()
 		return err
 	}
 	// start worker
 	p.wg.Add(1 + p.cfg.NumWorkers)
-	wctx, wcancel := context.WithCancel(ctx)
 	for i := 0; i < p.cfg.NumWorkers; i++ {
 		go p.worker(wctx)
 	}
@@ -348,12 +386,12 @@ func (p *prometheusOutput) Collect(ch chan<- prometheus.Metric) {
 	ctx, cancel := context.WithTimeout(context.Background(), p.cfg.Timeout)
 	defer cancel()
 
-	for _, entry := range p.entries {
+	for elem := p.lru.Front(); elem != nil; elem = elem.Next() {
 		select {
 		case <-ctx.Done():
 			p.logger.Printf("collection context terminated: %v", ctx.Err())
 			return
-		case ch <- entry:
+		case ch <- elem.Value.(*storeEntry).metric:
 		}
 	}
 }
@@ -409,47 +447,24 @@ func (p *prometheusOutput) workerHandleEvent(ev *formatters.EventMsg) {
 	}
 	p.Lock()
 	defer p.Unlock()
-	for _, pm := range p.mb.MetricsFromEvent(ev, time.Now()) {
-		key := pm.CalculateKey()
-		e, ok := p.entries[key]
-		// if the entry key is not present add it to the map.
-		// if present add it only if the entry timestamp is newer than the
-		// existing one.
-		if !ok || pm.Time == nil || (ok && pm.Time != nil && e.Time.Before(*pm.Time)) {
-			p.entries[key] = pm
-			if p.cfg.Debug {
-				p.logger.Printf("saved key=%d, metric: %+v", key, pm)
-			}
-		}
+	// delete notifications remove the series
+	if match := p.mb.DeletedMetricsMatcher(ev); match != nil {
+		p.deleteMetrics(match)
 	}
-}
-
-func (p *prometheusOutput) expireMetrics() {
-	if p.cfg.Expiration <= 0 {
-		return
-	}
-	expiry := time.Now().Add(-p.cfg.Expiration)
-	for k, e := range p.entries {
-		if p.cfg.ExportTimestamps {
-			if e.Time.Before(expiry) {
-				delete(p.entries, k)
-			}
-			continue
-		}
-		if e.AddedAt.Before(expiry) {
-			delete(p.entries, k)
-		}
+	for _, pm := range p.mb.MetricsFromEvent(ev, time.Now()) {
+		p.storeMetric(pm)
 	}
 }
 
 func (p *prometheusOutput) expireMetricsPeriodic(ctx context.Context) {
-	if p.cfg.Expiration <= 0 {
+	interval := p.expiryInterval()
+	if interval <= 0 {
 		return
 	}
 	p.Lock()
 	prometheusNumberOfMetrics.Set(float64(len(p.entries)))
 	p.Unlock()
-	ticker := time.NewTicker(p.cfg.Expiration)
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 	for {
 		select {
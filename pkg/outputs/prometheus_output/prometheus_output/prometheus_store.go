@@ -0,0 +1,166 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheus_output
+
+import (
+	"container/list"
+	"fmt"
+	"regexp"
+	"time"
+
+	promcom "github.com/openconfig/gnmic/pkg/outputs/prometheus_output"
+)
+
+const (
+	evictionReasonExpired   = "expired"
+	evictionReasonDeleted   = "deleted"
+	evictionReasonMaxSeries = "max-series"
+)
+
+// expirationGroup sets the expiration of the metrics with a name matching a regular expression.
+type expirationGroup struct {
+	Match      string        `mapstructure:"match,omitempty" json:"match,omitempty"`
+	Expiration time.Duration `mapstructure:"expiration,omitempty" json:"expiration,omitempty"`
+
+	re *regexp.Regexp
+}
+
+// storeEntry is a metric stored by the output.
+type storeEntry struct {
+	key    uint64
+	metric *promcom.PromMetric
+	// a zero or negative value disables the expiration
+	expiration time.Duration
+}
+
+func (p *prometheusOutput) initExpirationGroups() error {
+	for i, eg := range p.cfg.ExpirationGroups {
+		if eg.Match == "" {
+			return fmt.Errorf("expiration-groups[%d]: missing match regex", i)
+		}
+		if eg.Expiration == 0 {
+			return fmt.Errorf("expiration-groups[%d]: missing expiration", i)
+		}
+		var err error
+		eg.re, err = regexp.Compile(eg.Match)
+		if err != nil {
+			return fmt.Errorf("expiration-groups[%d]: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// metricExpiration returns the expiration of the metric name,
+// set by the first matching expiration group or by the output expiration.
+// It assumes the output lock is acquired.
+func (p *prometheusOutput) metricExpiration(name string) time.Duration {
+	if len(p.cfg.ExpirationGroups) == 0 {
+		return p.cfg.Expiration
+	}
+	if exp, ok := p.expirations[name]; ok {
+		return exp
+	}
+	exp := p.cfg.Expiration
+	for _, eg := range p.cfg.ExpirationGroups {
+		if eg.re.MatchString(name) {
+			exp = eg.Expiration
+			break
+		}
+	}
+	p.expirations[name] = exp
+	return exp
+}
+
+// expiryInterval returns the period of the metrics expiration,
+// the shortest of the configured expirations.
+func (p *prometheusOutput) expiryInterval() time.Duration {
+	interval := p.cfg.Expiration
+	for _, eg := range p.cfg.ExpirationGroups {
+		if eg.Expiration > 0 && (interval <= 0 || eg.Expiration < interval) {
+			interval = eg.Expiration
+		}
+	}
+	return interval
+}
+
+// storeMetric adds or updates the metric pm.
+// The least recently updated metric is evicted if the number of stored metrics reaches max-series.
+// It assumes the output lock is acquired.
+func (p *prometheusOutput) storeMetric(pm *promcom.PromMetric) {
+	key := pm.CalculateKey()
+	if elem, ok := p.entries[key]; ok {
+		e := elem.Value.(*storeEntry)
+		// if present update it only if the entry timestamp is newer than the
+		// existing one.
+		if pm.Time == nil || (e.metric.Time != nil && e.metric.Time.Before(*pm.Time)) {
+			e.metric = pm
+			p.lru.MoveToBack(elem)
+			if p.cfg.Debug {
+				p.logger.Printf("saved key=%d, metric: %+v", key, pm)
+			}
+		}
+		return
+	}
+	if p.cfg.MaxSeries > 0 {
+		for len(p.entries) >= p.cfg.MaxSeries {
+			p.evict(p.lru.Front(), evictionReasonMaxSeries)
+		}
+	}
+	p.entries[key] = p.lru.PushBack(&storeEntry{
+		key:        key,
+		metric:     pm,
+		expiration: p.metricExpiration(pm.Name),
+	})
+	if p.cfg.Debug {
+		p.logger.Printf("saved key=%d, metric: %+v", key, pm)
+	}
+}
+
+// deleteMetrics evicts the metrics matching match.
+// It assumes the output lock is acquired.
+func (p *prometheusOutput) deleteMetrics(match func(pm *promcom.PromMetric) bool) {
+	for elem := p.lru.Front(); elem != nil; {
+		next := elem.Next()
+		if match(elem.Value.(*storeEntry).metric) {
+			p.evict(elem, evictionReasonDeleted)
+		}
+		elem = next
+	}
+}
+
+// expireMetrics evicts the metrics older than their expiration.
+// It assumes the output lock is acquired.
+func (p *prometheusOutput) expireMetrics() {
+	now := time.Now()
+	for elem := p.lru.Front(); elem != nil; {
+		next := elem.Next()
+		e := elem.Value.(*storeEntry)
+		if e.expiration > 0 {
+			t := e.metric.AddedAt
+			if p.cfg.ExportTimestamps && e.metric.Time != nil {
+				t = *e.metric.Time
+			}
+			if t.Before(now.Add(-e.expiration)) {
+				p.evict(elem, evictionReasonExpired)
+			}
+		}
+		elem = next
+	}
+}
+
+// evict removes the metric stored in elem.
+// It assumes the output lock is acquired.
+func (p *prometheusOutput) evict(elem *list.Element, reason string) {
+	e := p.lru.Remove(elem).(*storeEntry)
+	delete(p.entries, e.key)
+	prometheusEvictedMetrics.WithLabelValues(reason).Inc()
+	if p.cfg.Debug {
+		p.logger.Printf("evicted key=%d, reason=%s, metric: %+v", e.key, reason, e.metric)
+	}
+}
@@ -0,0 +1,174 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheus_output
+
+import (
+	"container/list"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+	promcom "github.com/openconfig/gnmic/pkg/outputs/prometheus_output"
+)
+
+func newTestOutput(t *testing.T, cfg *config) *prometheusOutput {
+	p := &prometheusOutput{
+		cfg:         cfg,
+		entries:     make(map[uint64]*list.Element),
+		lru:         list.New(),
+		expirations: make(map[string]time.Duration),
+		logger:      log.New(io.Discard, "", 0),
+		mb:          &promcom.MetricBuilder{},
+	}
+	if err := p.initExpirationGroups(); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func storedNames(p *prometheusOutput) []string {
+	names := make([]string, 0, p.lru.Len())
+	for elem := p.lru.Front(); elem != nil; elem = elem.Next() {
+		names = append(names, elem.Value.(*storeEntry).metric.Name)
+	}
+	return names
+}
+
+func valueEvent(iface, path string) *formatters.EventMsg {
+	return &formatters.EventMsg{
+		Name:      "sub",
+		Timestamp: time.Now().UnixNano(),
+		Tags:      map[string]string{"source": "r1", "interface_name": iface},
+		Values:    map[string]interface{}{path: 1},
+	}
+}
+
+func TestPrometheusOutputDeletes(t *testing.T) {
+	p := newTestOutput(t, &config{})
+	p.workerHandleEvent(valueEvent("eth1", "/interfaces/interface/state/counters/in-octets"))
+	p.workerHandleEvent(valueEvent("eth1", "/interfaces/interface/state/oper-status"))
+	p.workerHandleEvent(valueEvent("eth2", "/interfaces/interface/state/oper-status"))
+	if len(p.entries) != 3 {
+		t.Fatalf("expected 3 stored metrics, got %d", len(p.entries))
+	}
+	p.workerHandleEvent(&formatters.EventMsg{
+		Name:    "sub",
+		Tags:    map[string]string{"source": "r1", "interface_name": "eth1"},
+		Deletes: []string{"/interfaces/interface/state"},
+	})
+	names := storedNames(p)
+	if len(names) != 1 || len(p.entries) != 1 {
+		t.Fatalf("expected 1 stored metric after the delete, got %v", names)
+	}
+	e := p.lru.Front().Value.(*storeEntry)
+	if pm := p.mb.MetricsFromEvent(valueEvent("eth2", "/interfaces/interface/state/oper-status"), time.Now())[0]; pm.CalculateKey() != e.key {
+		t.Fatalf("unexpected remaining metric: %v", e.metric)
+	}
+}
+
+func TestPrometheusOutputMaxSeries(t *testing.T) {
+	p := newTestOutput(t, &config{MaxSeries: 2})
+	p.workerHandleEvent(valueEvent("eth1", "/a"))
+	p.workerHandleEvent(valueEvent("eth1", "/b"))
+	// update the first metric, making "b" the least recently updated one
+	p.workerHandleEvent(valueEvent("eth1", "/a"))
+	p.workerHandleEvent(valueEvent("eth1", "/c"))
+	got := storedNames(p)
+	want := []string{"a", "c"}
+	if len(got) != len(want) || len(p.entries) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestPrometheusOutputExpirationGroups(t *testing.T) {
+	p := newTestOutput(t, &config{
+		Expiration: time.Hour,
+		ExpirationGroups: []*expirationGroup{
+			{Match: "^fast_", Expiration: time.Minute},
+			{Match: "^never_", Expiration: -1},
+		},
+	})
+	if got := p.expiryInterval(); got != time.Minute {
+		t.Fatalf("expected expiry interval %s, got %s", time.Minute, got)
+	}
+	now := time.Now()
+	for name, addedAt := range map[string]time.Time{
+		"fast_1":  now,
+		"fast_2":  now.Add(-2 * time.Minute),
+		"slow":    now.Add(-2 * time.Minute),
+		"never_1": now.Add(-2 * time.Hour),
+	} {
+		p.storeMetric(&promcom.PromMetric{Name: name, AddedAt: addedAt})
+	}
+	p.expireMetrics()
+	got := make(map[string]struct{})
+	for _, n := range storedNames(p) {
+		got[n] = struct{}{}
+	}
+	for _, n := range []string{"fast_1", "slow", "never_1"} {
+		if _, ok := got[n]; !ok {
+			t.Errorf("expected metric %q to be kept", n)
+		}
+	}
+	if _, ok := got["fast_2"]; ok || len(got) != 3 {
+		t.Errorf("expected metric %q to be expired, got %v", "fast_2", got)
+	}
+}
+
+func TestPrometheusOutputExpirationGroupsInvalid(t *testing.T) {
+	for name, eg := range map[string]*expirationGroup{
+		"missing_match":      {Expiration: time.Minute},
+		"missing_expiration": {Match: "^a"},
+		"invalid_regex":      {Match: "(", Expiration: time.Minute},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := &prometheusOutput{cfg: &config{ExpirationGroups: []*expirationGroup{eg}}}
+			if err := p.initExpirationGroups(); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestPrometheusOutputHistogram(t *testing.T) {
+	p := newTestOutput(t, &config{Timeout: time.Second})
+	schema := int32(3)
+	h := formatters.NewHistogram([]float64{1, 10}, &schema, 0)
+	h.Observe(5)
+	p.workerHandleEvent(&formatters.EventMsg{
+		Name:   "sub",
+		Tags:   map[string]string{"source": "r1"},
+		Values: map[string]interface{}{"latency": h, "value": 1},
+	})
+	reg := prometheus.NewRegistry()
+	if err := reg.Register(p); err != nil {
+		t.Fatal(err)
+	}
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	types := make(map[string]dto.MetricType)
+	for _, mf := range mfs {
+		types[mf.GetName()] = mf.GetType()
+	}
+	if types["latency"] != dto.MetricType_HISTOGRAM || types["value"] != dto.MetricType_UNTYPED {
+		t.Errorf("unexpected metric types: %v", types)
+	}
+}
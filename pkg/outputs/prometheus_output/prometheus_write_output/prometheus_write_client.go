@@ -23,6 +23,7 @@ import (
 	"github.com/prometheus/prometheus/prompb"
 
 	"github.com/openconfig/gnmic/pkg/api/utils"
+	"github.com/openconfig/gnmic/pkg/outputs"
 )
 
 var (
@@ -106,7 +107,7 @@ WRITE:
 	}
 	// sort timeSeries by timestamp
 	sort.Slice(pts, func(i, j int) bool {
-		return pts[i].Samples[0].Timestamp < pts[j].Samples[0].Timestamp
+		return tsTimestamp(pts[i]) < tsTimestamp(pts[j])
 	})
 	chunk := make([]prompb.TimeSeries, 0, p.cfg.MaxTimeSeriesPerWrite)
 	for i, pt := range pts {
@@ -141,23 +142,48 @@ WRITE:
 	}
 }
 
+// tsTimestamp returns the timestamp of the time series ts,
+// carried by its sample or by its native histogram.
+func tsTimestamp(ts prompb.TimeSeries) int64 {
+	if len(ts.Samples) > 0 {
+		return ts.Samples[0].Timestamp
+	}
+	if len(ts.Histograms) > 0 {
+		return ts.Histograms[0].Timestamp
+	}
+	return 0
+}
+
 // writeRequest marshals the supplied prompb.WriteRequest,
 // creates an HTTP request with the proper configured options (Authentication, Headers,...),
 // sends the request and checks the returned response status code.
 // It returns an error if the status code is >=300.
 func (p *promWriteOutput) writeRequest(ctx context.Context, wr *prompb.WriteRequest) error {
-	httpReq, err := p.makeHTTPRequest(ctx, wr)
-	if err != nil {
+	if p.errors != nil {
+		err := p.errors.Do(ctx, func(ctx context.Context) error {
+			return p.send(ctx, wr)
+		}, nil)
+		if err != nil {
+			prometheusWriteNumberOfFailSendMsgs.WithLabelValues("error_class=" + string(outputs.ClassifyError(err))).Inc()
+		}
 		return err
 	}
-
 	// send request with retries
 	retries := 0
 RETRY:
-	rsp, err := p.httpClient.Do(httpReq)
+	err := p.send(ctx, wr)
 	if err != nil {
+		var se *outputs.StatusError
+		if errors.As(err, &se) {
+			prometheusWriteNumberOfFailSendMsgs.WithLabelValues(fmt.Sprintf("status_code=%d", se.Code)).Inc()
+			return fmt.Errorf("write response failed, code=%d, body=%s", se.Code, se.Body)
+		}
+		// marshal and request creation errors are not retried
+		var ce *outputs.ClassifiedError
+		if errors.As(err, &ce) {
+			return err
+		}
 		retries++
-		err = fmt.Errorf("failed to write to remote: %w", err)
 		p.logger.Print(err)
 		if retries < p.cfg.MaxRetries {
 			time.Sleep(backoff)
@@ -166,18 +192,31 @@ RETRY:
 		prometheusWriteNumberOfFailSendMsgs.WithLabelValues("client_failure").Inc()
 		return err
 	}
+	return nil
+}
+
+// send sends a single write request, it returns an *outputs.StatusError
+// if the response status code is >=300.
+func (p *promWriteOutput) send(ctx context.Context, wr *prompb.WriteRequest) error {
+	httpReq, err := p.makeHTTPRequest(ctx, wr)
+	if err != nil {
+		return err
+	}
+	rsp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to write to remote: %w", err)
+	}
 	defer rsp.Body.Close()
 
 	if p.cfg.Debug {
 		p.logger.Printf("got response from remote: status=%s", rsp.Status)
 	}
 	if rsp.StatusCode >= 300 {
-		prometheusWriteNumberOfFailSendMsgs.WithLabelValues(fmt.Sprintf("status_code=%d", rsp.StatusCode)).Inc()
 		msg, err := io.ReadAll(rsp.Body)
 		if err != nil {
 			return err
 		}
-		return fmt.Errorf("write response failed, code=%d, body=%s", rsp.StatusCode, string(msg))
+		return &outputs.StatusError{Code: rsp.StatusCode, Body: string(msg)}
 	}
 	return nil
 }
@@ -267,12 +306,12 @@ func (p *promWriteOutput) makeHTTPRequest(ctx context.Context, wr *prompb.WriteR
 	b, err := gogoproto.Marshal(wr)
 	if err != nil {
 		prometheusWriteNumberOfFailSendMsgs.WithLabelValues("marshal_error").Inc()
-		return nil, fmt.Errorf("marshal error: %w", err)
+		return nil, outputs.NewClassifiedError(outputs.ErrorClassPermanent, fmt.Errorf("%w: %v", ErrMarshal, err))
 	}
 	compBytes := snappy.Encode(nil, b)
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.URL, bytes.NewBuffer(compBytes))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP request: %v", err)
+		return nil, outputs.NewClassifiedError(outputs.ErrorClassPermanent, fmt.Errorf("failed to create HTTP request: %v", err))
 	}
 	httpReq.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
 	httpReq.Header.Set("Content-Encoding", "snappy")
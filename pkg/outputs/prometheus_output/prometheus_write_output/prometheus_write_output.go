@@ -82,6 +82,8 @@ type promWriteOutput struct {
 	evps      []formatters.EventProcessor
 	targetTpl *template.Template
 	cfn       context.CancelFunc
+	// applies the error-policy, nil if not set.
+	errors *outputs.ErrorHandler
 	// TODO:
 	// gnmiCache *cache.GnmiOutputCache
 }
@@ -98,8 +100,10 @@ type config struct {
 	BufferSize            int               `mapstructure:"buffer-size,omitempty" json:"buffer-size,omitempty"`
 	MaxTimeSeriesPerWrite int               `mapstructure:"max-time-series-per-write,omitempty" json:"max-time-series-per-write,omitempty"`
 	MaxRetries            int               `mapstructure:"max-retries,omitempty" json:"max-retries,omitempty"`
-	Metadata              *metadata         `mapstructure:"metadata,omitempty" json:"metadata,omitempty"`
-	Debug                 bool              `mapstructure:"debug,omitempty" json:"debug,omitempty"`
+	// per error class actions, replaces max-retries if set
+	ErrorPolicy *outputs.ErrorPolicy `mapstructure:"error-policy,omitempty" json:"error-policy,omitempty"`
+	Metadata    *metadata            `mapstructure:"metadata,omitempty" json:"metadata,omitempty"`
+	Debug       bool                 `mapstructure:"debug,omitempty" json:"debug,omitempty"`
 	//
 	MetricPrefix           string   `mapstructure:"metric-prefix,omitempty" json:"metric-prefix,omitempty"`
 	AppendSubscriptionName bool     `mapstructure:"append-subscription-name,omitempty" json:"append-subscription-name,omitempty"`
@@ -165,6 +169,10 @@ func (p *promWriteOutput) Init(ctx context.Context, name string, cfg map[string]
 	if err != nil {
 		return err
 	}
+	p.errors, err = outputs.NewErrorHandler(p.cfg.Name, p.cfg.ErrorPolicy, p.logger)
+	if err != nil {
+		return err
+	}
 
 	p.mb = &promcom.MetricBuilder{
 		Prefix:                 p.cfg.MetricPrefix,
@@ -343,7 +351,7 @@ func (p *promWriteOutput) workerHandleEvent(ev *formatters.EventMsg) {
 			p.logger.Printf("saving metrics metadata")
 		}
 		p.metadataCache[pts.Name] = prompb.MetricMetadata{
-			Type:             prompb.MetricMetadata_COUNTER,
+			Type:             pts.Type,
 			MetricFamilyName: pts.Name,
 			Help:             defaultMetricHelp,
 		}
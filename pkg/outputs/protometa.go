@@ -15,6 +15,7 @@ import (
 type ProtoMsg struct {
 	m    proto.Message
 	meta Meta
+	ack  AckFunc
 }
 
 func NewProtoMsg(m proto.Message, meta Meta) *ProtoMsg {
@@ -24,6 +25,16 @@ func NewProtoMsg(m proto.Message, meta Meta) *ProtoMsg {
 	}
 }
 
+// NewProtoMsgWithAck returns a ProtoMsg carrying the function
+// used to report the result of its write.
+func NewProtoMsgWithAck(m proto.Message, meta Meta, ack AckFunc) *ProtoMsg {
+	return &ProtoMsg{
+		m:    m,
+		meta: meta,
+		ack:  ack,
+	}
+}
+
 func (m *ProtoMsg) GetMsg() proto.Message {
 	if m == nil {
 		return nil
@@ -37,3 +48,12 @@ func (m *ProtoMsg) GetMeta() Meta {
 	}
 	return m.meta
 }
+
+// Ack reports the result of the message write,
+// it is a noop if the message was created without an AckFunc.
+func (m *ProtoMsg) Ack(err error) {
+	if m == nil || m.ack == nil {
+		return
+	}
+	m.ack(err)
+}
@@ -0,0 +1,50 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package relay_output
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var relayNumberOfSentMsgs = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "relay_output",
+	Name:      "number_of_sent_msgs_total",
+	Help:      "Number of messages sent by gnmic relay output and acknowledged by the relay input",
+}, []string{"name"})
+
+var relayNumberOfDroppedMsgs = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "relay_output",
+	Name:      "number_of_dropped_msgs_total",
+	Help:      "Number of messages dropped by gnmic relay output because its buffer was full",
+}, []string{"name"})
+
+var relayInFlightBatches = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "gnmic",
+	Subsystem: "relay_output",
+	Name:      "in_flight_batches",
+	Help:      "Number of batches sent by gnmic relay output and not yet acknowledged",
+}, []string{"name"})
+
+func registerMetrics(reg *prometheus.Registry) error {
+	for _, c := range []prometheus.Collector{relayNumberOfSentMsgs, relayNumberOfDroppedMsgs, relayInFlightBatches} {
+		if err := reg.Register(c); err != nil {
+			var are prometheus.AlreadyRegisteredError
+			// multiple relay outputs share the same collectors
+			if errors.As(err, &are) {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
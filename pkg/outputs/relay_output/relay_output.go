@@ -0,0 +1,567 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package relay_output
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/api/utils"
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/gtemplate"
+	"github.com/openconfig/gnmic/pkg/outputs"
+	"github.com/openconfig/gnmic/pkg/relay"
+)
+
+const (
+	Type                 = "relay"
+	loggingPrefix        = "[relay_output:%s] "
+	defaultFormat        = "event"
+	defaultBatchSize     = 100
+	defaultFlushInterval = time.Second
+	defaultBufferSize    = 1000
+	defaultMaxInFlight   = 10
+	defaultAckTimeout    = 30 * time.Second
+	defaultRetryTimer    = 2 * time.Second
+	defaultWriteTimeout  = 5 * time.Second
+)
+
+func init() {
+	outputs.Register(Type, func() outputs.Output {
+		return &relayOutput{
+			cfg:    &Config{},
+			logger: log.New(io.Discard, loggingPrefix, utils.DefaultLoggingFlags),
+		}
+	})
+}
+
+// relayOutput streams batches of messages to a relay input,
+// at most max-in-flight batches are sent before being acknowledged.
+type relayOutput struct {
+	cfg    *Config
+	logger *log.Logger
+	cfn    context.CancelFunc
+
+	// unique ID of this output instance, sent to the relay input
+	// to discard replayed batches it already processed.
+	senderID  string
+	msgCh     chan *outboundMessage
+	evps      []formatters.EventProcessor
+	targetTpl *template.Template
+	health    outputs.Health
+
+	m sync.Mutex
+	// last batch sequence number
+	seq uint64
+	// sent but not acknowledged batches
+	pending []*pendingBatch
+	// messages of the batch being built and their ack functions,
+	// only accessed by the run goroutine.
+	batch     []*relay.Message
+	batchAcks []outputs.AckFunc
+}
+
+type pendingBatch struct {
+	req    *relay.PublishRequest
+	sentAt time.Time
+	// called once the batch is acknowledged by the relay input
+	acks []outputs.AckFunc
+}
+
+// outboundMessage is a message waiting to be batched,
+// ack is set if it was written with WriteAck or WriteEventAck.
+type outboundMessage struct {
+	msg *relay.Message
+	ack outputs.AckFunc
+}
+
+type Config struct {
+	Name            string           `mapstructure:"name,omitempty" json:"name,omitempty"`
+	Address         string           `mapstructure:"address,omitempty" json:"address,omitempty"`
+	TLS             *types.TLSConfig `mapstructure:"tls,omitempty" json:"tls,omitempty"`
+	Compression     string           `mapstructure:"compression,omitempty" json:"compression,omitempty"`
+	Format          string           `mapstructure:"format,omitempty" json:"format,omitempty"`
+	BatchSize       int              `mapstructure:"batch-size,omitempty" json:"batch-size,omitempty"`
+	FlushInterval   time.Duration    `mapstructure:"flush-interval,omitempty" json:"flush-interval,omitempty"`
+	BufferSize      int              `mapstructure:"buffer-size,omitempty" json:"buffer-size,omitempty"`
+	MaxInFlight     int              `mapstructure:"max-in-flight,omitempty" json:"max-in-flight,omitempty"`
+	AckTimeout      time.Duration    `mapstructure:"ack-timeout,omitempty" json:"ack-timeout,omitempty"`
+	RetryTimer      time.Duration    `mapstructure:"retry-timer,omitempty" json:"retry-timer,omitempty"`
+	WriteTimeout    time.Duration    `mapstructure:"write-timeout,omitempty" json:"write-timeout,omitempty"`
+	AddTarget       string           `mapstructure:"add-target,omitempty" json:"add-target,omitempty"`
+	TargetTemplate  string           `mapstructure:"target-template,omitempty" json:"target-template,omitempty"`
+	EventProcessors []string         `mapstructure:"event-processors,omitempty" json:"event-processors,omitempty"`
+	EnableMetrics   bool             `mapstructure:"enable-metrics,omitempty" json:"enable-metrics,omitempty"`
+	Debug           bool             `mapstructure:"debug,omitempty" json:"debug,omitempty"`
+}
+
+func (r *relayOutput) Init(ctx context.Context, name string, cfg map[string]interface{}, opts ...outputs.Option) error {
+	err := outputs.DecodeConfig(cfg, r.cfg)
+	if err != nil {
+		return err
+	}
+	if r.cfg.Name == "" {
+		r.cfg.Name = name
+	}
+	r.logger.SetPrefix(fmt.Sprintf(loggingPrefix, r.cfg.Name))
+	for _, opt := range opts {
+		if err := opt(r); err != nil {
+			return err
+		}
+	}
+	err = r.setDefaults()
+	if err != nil {
+		return err
+	}
+	if r.cfg.TargetTemplate == "" {
+		r.targetTpl = outputs.DefaultTargetTemplate
+	} else if r.cfg.AddTarget != "" {
+		r.targetTpl, err = gtemplate.CreateTemplate("target-template", r.cfg.TargetTemplate)
+		if err != nil {
+			return err
+		}
+		r.targetTpl = r.targetTpl.Funcs(outputs.TemplateFuncs)
+	}
+	dialOpts, err := r.dialOpts()
+	if err != nil {
+		return err
+	}
+	r.senderID = fmt.Sprintf("%s-%s", r.cfg.Name, uuid.New().String())
+	r.msgCh = make(chan *outboundMessage, r.cfg.BufferSize)
+	r.health.Set(errors.New("not connected"))
+	ctx, r.cfn = context.WithCancel(ctx)
+	go r.run(ctx, dialOpts)
+	r.logger.Printf("initialized relay output: %s", r.String())
+	return nil
+}
+
+func (r *relayOutput) setDefaults() error {
+	if r.cfg.Address == "" {
+		return errors.New("missing address field")
+	}
+	r.cfg.Format = strings.ToLower(r.cfg.Format)
+	switch r.cfg.Format {
+	case "":
+		r.cfg.Format = defaultFormat
+	case "event", "proto":
+	default:
+		return fmt.Errorf("unsupported format %q, expecting event or proto", r.cfg.Format)
+	}
+	r.cfg.Compression = strings.ToLower(r.cfg.Compression)
+	switch r.cfg.Compression {
+	case "", gzip.Name:
+	default:
+		return fmt.Errorf("unsupported compression %q", r.cfg.Compression)
+	}
+	if r.cfg.BatchSize <= 0 {
+		r.cfg.BatchSize = defaultBatchSize
+	}
+	if r.cfg.FlushInterval <= 0 {
+		r.cfg.FlushInterval = defaultFlushInterval
+	}
+	if r.cfg.BufferSize <= 0 {
+		r.cfg.BufferSize = defaultBufferSize
+	}
+	if r.cfg.MaxInFlight <= 0 {
+		r.cfg.MaxInFlight = defaultMaxInFlight
+	}
+	if r.cfg.AckTimeout <= 0 {
+		r.cfg.AckTimeout = defaultAckTimeout
+	}
+	if r.cfg.RetryTimer <= 0 {
+		r.cfg.RetryTimer = defaultRetryTimer
+	}
+	if r.cfg.WriteTimeout <= 0 {
+		r.cfg.WriteTimeout = defaultWriteTimeout
+	}
+	return nil
+}
+
+func (r *relayOutput) dialOpts() ([]grpc.DialOption, error) {
+	opts := make([]grpc.DialOption, 0, 2)
+	if r.cfg.Compression != "" {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.UseCompressor(r.cfg.Compression)))
+	}
+	if r.cfg.TLS == nil {
+		return append(opts, grpc.WithTransportCredentials(insecure.NewCredentials())), nil
+	}
+	tlsCfg, err := utils.NewTLSConfig(
+		r.cfg.TLS.CaFile,
+		r.cfg.TLS.CertFile,
+		r.cfg.TLS.KeyFile,
+		"",
+		r.cfg.TLS.SkipVerify,
+		false,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if tlsCfg == nil {
+		return append(opts, grpc.WithTransportCredentials(insecure.NewCredentials())), nil
+	}
+	return append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsCfg))), nil
+}
+
+func (r *relayOutput) Write(ctx context.Context, rsp proto.Message, meta outputs.Meta) {
+	r.write(ctx, rsp, meta, nil)
+}
+
+// WriteAck writes the message and calls ack once the batches
+// carrying it are acknowledged by the relay input.
+func (r *relayOutput) WriteAck(ctx context.Context, rsp proto.Message, meta outputs.Meta, ack outputs.AckFunc) {
+	r.write(ctx, rsp, meta, ack)
+}
+
+func (r *relayOutput) write(ctx context.Context, rsp proto.Message, meta outputs.Meta, ack outputs.AckFunc) {
+	switch rsp := rsp.(type) {
+	case *gnmi.SubscribeResponse:
+		var err error
+		rsp, err = outputs.AddSubscriptionTarget(rsp, meta, r.cfg.AddTarget, r.targetTpl)
+		if err != nil {
+			r.logger.Printf("failed to add target to the response: %v", err)
+		}
+		if r.cfg.Format == "proto" {
+			r.enqueue(ctx, &outboundMessage{msg: &relay.Message{Response: rsp, Meta: meta}, ack: ack})
+			return
+		}
+		measName := "default"
+		if subName, ok := meta["subscription-name"]; ok {
+			measName = subName
+		}
+		events, err := formatters.ResponseToEventMsgs(measName, rsp, meta, r.evps...)
+		if err != nil {
+			r.logger.Printf("failed to convert message to event: %v", err)
+			callAck(ack, err)
+			return
+		}
+		r.writeEvents(ctx, events, ack)
+	default:
+		callAck(ack, nil)
+	}
+}
+
+func (r *relayOutput) WriteEvent(ctx context.Context, ev *formatters.EventMsg) {
+	r.WriteEventAck(ctx, ev, nil)
+}
+
+// WriteEventAck writes the event and calls ack once the batches
+// carrying it are acknowledged by the relay input.
+func (r *relayOutput) WriteEventAck(ctx context.Context, ev *formatters.EventMsg, ack outputs.AckFunc) {
+	if ev == nil {
+		callAck(ack, nil)
+		return
+	}
+	evs := []*formatters.EventMsg{ev}
+	for _, proc := range r.evps {
+		evs = proc.Apply(evs...)
+	}
+	r.writeEvents(ctx, evs, ack)
+}
+
+func (r *relayOutput) writeEvents(ctx context.Context, evs []*formatters.EventMsg, ack outputs.AckFunc) {
+	if ack != nil {
+		ack = outputs.NewAckGroup(len(evs), ack)
+	}
+	for _, ev := range evs {
+		rev, err := relay.NewEvent(ev)
+		if err != nil {
+			r.logger.Printf("failed to convert event: %v", err)
+			callAck(ack, err)
+			continue
+		}
+		r.enqueue(ctx, &outboundMessage{msg: &relay.Message{Event: rev}, ack: ack})
+	}
+}
+
+func (r *relayOutput) enqueue(ctx context.Context, m *outboundMessage) {
+	wctx, cancel := context.WithTimeout(ctx, r.cfg.WriteTimeout)
+	defer cancel()
+	select {
+	case <-ctx.Done():
+		callAck(m.ack, ctx.Err())
+	case r.msgCh <- m:
+	case <-wctx.Done():
+		if r.cfg.Debug {
+			r.logger.Printf("writing expired after %s, dropping message", r.cfg.WriteTimeout)
+		}
+		if r.cfg.EnableMetrics {
+			relayNumberOfDroppedMsgs.WithLabelValues(r.cfg.Name).Inc()
+		}
+		callAck(m.ack, fmt.Errorf("writing expired after %s", r.cfg.WriteTimeout))
+	}
+}
+
+// callAck calls ack with err if it is set.
+func callAck(ack outputs.AckFunc, err error) {
+	if ack != nil {
+		ack(err)
+	}
+}
+
+// run maintains the stream to the relay input, reconnecting after failures.
+func (r *relayOutput) run(ctx context.Context, dialOpts []grpc.DialOption) {
+	for {
+		err := r.connect(ctx, dialOpts)
+		if ctx.Err() != nil {
+			return
+		}
+		r.health.Set(err)
+		r.logger.Printf("relay stream to %s failed: %v, retrying in %s", r.cfg.Address, err, r.cfg.RetryTimer)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.cfg.RetryTimer):
+		}
+	}
+}
+
+func (r *relayOutput) connect(ctx context.Context, dialOpts []grpc.DialOption) error {
+	conn, err := grpc.NewClient(r.cfg.Address, dialOpts...)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	sctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	sctx = metadata.AppendToOutgoingContext(sctx, relay.SenderMetadataKey, r.senderID)
+	stream, err := relay.NewRelayClient(conn).Publish(sctx)
+	if err != nil {
+		return err
+	}
+	r.logger.Printf("relay stream to %s established", r.cfg.Address)
+	return r.session(sctx, stream)
+}
+
+// session sends the batches over the stream until it fails.
+// The batches not acknowledged when a session starts are sent again first.
+func (r *relayOutput) session(ctx context.Context, stream relay.Relay_PublishClient) error {
+	acks := make(chan uint64)
+	errCh := make(chan error, 1)
+	go func() {
+		for {
+			rsp, err := stream.Recv()
+			if err != nil {
+				errCh <- err
+				return
+			}
+			select {
+			case acks <- rsp.Sequence:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	r.m.Lock()
+	for _, pb := range r.pending {
+		if err := stream.Send(pb.req); err != nil {
+			r.m.Unlock()
+			return err
+		}
+		pb.sentAt = time.Now()
+	}
+	r.m.Unlock()
+	r.health.Set(nil)
+
+	ticker := time.NewTicker(r.cfg.FlushInterval)
+	defer ticker.Stop()
+	for {
+		// stop reading new messages while the in-flight window is full
+		msgCh := r.msgCh
+		if r.numPending() >= r.cfg.MaxInFlight {
+			msgCh = nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-errCh:
+			return err
+		case seq := <-acks:
+			r.ack(seq)
+		case m := <-msgCh:
+			r.batch = append(r.batch, m.msg)
+			if m.ack != nil {
+				r.batchAcks = append(r.batchAcks, m.ack)
+			}
+			if len(r.batch) < r.cfg.BatchSize {
+				continue
+			}
+			if err := r.send(stream); err != nil {
+				return err
+			}
+		case <-ticker.C:
+			if err := r.checkAckTimeout(); err != nil {
+				return err
+			}
+			if len(r.batch) == 0 || r.numPending() >= r.cfg.MaxInFlight {
+				continue
+			}
+			if err := r.send(stream); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// send adds the current batch to the pending batches then sends it,
+// a batch that failed to be sent is sent again by the next session.
+func (r *relayOutput) send(stream relay.Relay_PublishClient) error {
+	batch := r.batch
+	r.batch = make([]*relay.Message, 0, r.cfg.BatchSize)
+	acks := r.batchAcks
+	r.batchAcks = nil
+	r.m.Lock()
+	r.seq++
+	pb := &pendingBatch{
+		req:    &relay.PublishRequest{Sequence: r.seq, Messages: batch},
+		sentAt: time.Now(),
+		acks:   acks,
+	}
+	r.pending = append(r.pending, pb)
+	numPending := len(r.pending)
+	r.m.Unlock()
+	if r.cfg.EnableMetrics {
+		relayInFlightBatches.WithLabelValues(r.cfg.Name).Set(float64(numPending))
+	}
+	if r.cfg.Debug {
+		r.logger.Printf("sending batch %d with %d message(s)", pb.req.Sequence, len(batch))
+	}
+	return stream.Send(pb.req)
+}
+
+// ack removes the batches acknowledged by sequence number seq from the pending batches
+// and calls the ack functions of their messages.
+func (r *relayOutput) ack(seq uint64) {
+	r.m.Lock()
+	i := 0
+	numMsgs := 0
+	var acks []outputs.AckFunc
+	for ; i < len(r.pending); i++ {
+		if r.pending[i].req.Sequence > seq {
+			break
+		}
+		numMsgs += len(r.pending[i].req.Messages)
+		acks = append(acks, r.pending[i].acks...)
+	}
+	r.pending = r.pending[i:]
+	numPending := len(r.pending)
+	r.m.Unlock()
+	for _, ack := range acks {
+		ack(nil)
+	}
+	if r.cfg.Debug {
+		r.logger.Printf("batches up to %d acknowledged", seq)
+	}
+	if r.cfg.EnableMetrics {
+		relayNumberOfSentMsgs.WithLabelValues(r.cfg.Name).Add(float64(numMsgs))
+		relayInFlightBatches.WithLabelValues(r.cfg.Name).Set(float64(numPending))
+	}
+}
+
+func (r *relayOutput) numPending() int {
+	r.m.Lock()
+	defer r.m.Unlock()
+	return len(r.pending)
+}
+
+func (r *relayOutput) checkAckTimeout() error {
+	r.m.Lock()
+	defer r.m.Unlock()
+	if len(r.pending) == 0 {
+		return nil
+	}
+	if since := time.Since(r.pending[0].sentAt); since > r.cfg.AckTimeout {
+		return fmt.Errorf("batch %d not acknowledged after %s", r.pending[0].req.Sequence, since.Truncate(time.Millisecond))
+	}
+	return nil
+}
+
+func (r *relayOutput) Close() error {
+	if r.cfn != nil {
+		r.cfn()
+	}
+	return nil
+}
+
+// Healthy returns nil if the stream to the relay input is established.
+func (r *relayOutput) Healthy() error {
+	return r.health.Get()
+}
+
+func (r *relayOutput) RegisterMetrics(reg *prometheus.Registry) {
+	if !r.cfg.EnableMetrics {
+		return
+	}
+	if reg == nil {
+		r.logger.Printf("ERR: output metrics enabled but main registry is not initialized, enable main metrics under `api-server`")
+		return
+	}
+	if err := registerMetrics(reg); err != nil {
+		r.logger.Printf("failed to register metrics: %v", err)
+	}
+}
+
+func (r *relayOutput) String() string {
+	b, err := json.Marshal(r.cfg)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func (r *relayOutput) SetLogger(logger *log.Logger) {
+	if logger != nil && r.logger != nil {
+		r.logger.SetOutput(logger.Writer())
+		r.logger.SetFlags(logger.Flags())
+	}
+}
+
+func (r *relayOutput) SetEventProcessors(ps map[string]map[string]interface{},
+	logger *log.Logger,
+	tcs map[string]*types.TargetConfig,
+	acts map[string]map[string]interface{}) error {
+	var err error
+	r.evps, err = formatters.MakeEventProcessors(
+		logger,
+		r.cfg.EventProcessors,
+		ps,
+		tcs,
+		acts,
+	)
+	return err
+}
+
+func (r *relayOutput) SetName(name string) {
+	if r.cfg.Name == "" {
+		r.cfg.Name = name
+	}
+}
+
+func (r *relayOutput) SetClusterName(string) {}
+
+func (r *relayOutput) SetTargetsConfig(map[string]*types.TargetConfig) {}
@@ -0,0 +1,233 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package relay_output
+
+import (
+	"context"
+	"log"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/inputs"
+	_ "github.com/openconfig/gnmic/pkg/inputs/relay_input"
+	"github.com/openconfig/gnmic/pkg/outputs"
+	"github.com/openconfig/gnmic/pkg/relay"
+)
+
+type testOutput struct {
+	m    sync.Mutex
+	rsps []proto.Message
+	evs  []*formatters.EventMsg
+}
+
+func (o *testOutput) Init(context.Context, string, map[string]interface{}, ...outputs.Option) error {
+	return nil
+}
+func (o *testOutput) Write(_ context.Context, rsp proto.Message, _ outputs.Meta) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	o.rsps = append(o.rsps, rsp)
+}
+func (o *testOutput) WriteEvent(_ context.Context, ev *formatters.EventMsg) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	o.evs = append(o.evs, ev)
+}
+func (o *testOutput) Close() error                         { return nil }
+func (o *testOutput) RegisterMetrics(*prometheus.Registry) {}
+func (o *testOutput) String() string                       { return "" }
+func (o *testOutput) SetLogger(*log.Logger)                {}
+func (o *testOutput) SetEventProcessors(map[string]map[string]interface{}, *log.Logger, map[string]*types.TargetConfig, map[string]map[string]interface{}) error {
+	return nil
+}
+func (o *testOutput) SetName(string)                                  {}
+func (o *testOutput) SetClusterName(string)                           {}
+func (o *testOutput) SetTargetsConfig(map[string]*types.TargetConfig) {}
+
+func (o *testOutput) count() (int, int) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	return len(o.rsps), len(o.evs)
+}
+
+func freeAddress(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().String()
+}
+
+func TestRelay(t *testing.T) {
+	for _, format := range []string{"event", "proto"} {
+		t.Run(format, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			addr := freeAddress(t)
+
+			out := &testOutput{}
+			in := inputs.Inputs["relay"]()
+			err := in.Start(ctx, "core", map[string]interface{}{"address": addr},
+				inputs.WithOutputs(map[string]outputs.Output{"test": out}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer in.Close()
+
+			o := outputs.Outputs[Type]()
+			err = o.Init(ctx, "edge", map[string]interface{}{
+				"address":        addr,
+				"format":         format,
+				"compression":    "gzip",
+				"flush-interval": "10ms",
+				"batch-size":     3,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer o.Close()
+
+			rsp := &gnmi.SubscribeResponse{
+				Response: &gnmi.SubscribeResponse_Update{
+					Update: &gnmi.Notification{
+						Timestamp: 42,
+						Update: []*gnmi.Update{{
+							Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "counter"}}},
+							Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_IntVal{IntVal: 1}},
+						}},
+					},
+				},
+			}
+			for i := 0; i < 5; i++ {
+				o.Write(ctx, rsp, outputs.Meta{"source": "r1", "subscription-name": "sub1"})
+			}
+
+			wantRsps, wantEvs := 0, 5
+			if format == "proto" {
+				wantRsps, wantEvs = 5, 0
+			}
+			deadline := time.Now().Add(5 * time.Second)
+			for {
+				rsps, evs := out.count()
+				if rsps == wantRsps && evs == wantEvs {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("got %d responses and %d events, want %d and %d", rsps, evs, wantRsps, wantEvs)
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+			if err := o.(*relayOutput).Healthy(); err != nil {
+				t.Errorf("unexpected health error: %v", err)
+			}
+			if n := o.(*relayOutput).numPending(); n != 0 {
+				t.Errorf("got %d pending batches, want 0", n)
+			}
+			if format == "event" {
+				ev := out.evs[0]
+				if ev.Name != "sub1" || ev.Timestamp != 42 || ev.Tags["source"] != "r1" {
+					t.Errorf("unexpected event: %+v", ev)
+				}
+				if _, ok := ev.Values["/counter"]; !ok {
+					t.Errorf("missing value in event: %+v", ev)
+				}
+			}
+		})
+	}
+}
+
+func TestAck(t *testing.T) {
+	r := &relayOutput{cfg: &Config{}, logger: log.New(log.Writer(), "", 0)}
+	acked := make([]bool, 5)
+	for i := uint64(1); i <= 4; i++ {
+		i := i
+		r.pending = append(r.pending, &pendingBatch{
+			req:  &relay.PublishRequest{Sequence: i, Messages: make([]*relay.Message, 1)},
+			acks: []outputs.AckFunc{func(error) { acked[i] = true }},
+		})
+	}
+	r.ack(2)
+	if len(r.pending) != 2 || r.pending[0].req.Sequence != 3 {
+		t.Fatalf("unexpected pending batches after ack 2: %d", len(r.pending))
+	}
+	if !acked[1] || !acked[2] || acked[3] {
+		t.Fatalf("unexpected acknowledged messages after ack 2: %v", acked[1:])
+	}
+	// an old acknowledgement does not remove anything
+	r.ack(1)
+	if len(r.pending) != 2 {
+		t.Fatalf("unexpected pending batches after ack 1: %d", len(r.pending))
+	}
+	r.ack(4)
+	if len(r.pending) != 0 {
+		t.Fatalf("unexpected pending batches after ack 4: %d", len(r.pending))
+	}
+}
+
+func TestWriteEventAck(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	addr := freeAddress(t)
+
+	out := &testOutput{}
+	in := inputs.Inputs["relay"]()
+	err := in.Start(ctx, "core", map[string]interface{}{"address": addr},
+		inputs.WithOutputs(map[string]outputs.Output{"test": out}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+
+	o := outputs.Outputs[Type]()
+	err = o.Init(ctx, "edge", map[string]interface{}{
+		"address":        addr,
+		"flush-interval": "10ms",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer o.Close()
+	err = inputs.CheckAckOutputs([]outputs.Output{o})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acks := make(chan error, 1)
+	ev := &formatters.EventMsg{Name: "sub1", Timestamp: 42, Values: map[string]interface{}{"counter": 1}}
+	o.(outputs.Acknowledger).WriteEventAck(ctx, ev, func(err error) { acks <- err })
+	select {
+	case err := <-acks:
+		if err != nil {
+			t.Fatalf("unexpected ack error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("event not acknowledged")
+	}
+	if _, evs := out.count(); evs != 1 {
+		t.Errorf("got %d events, want 1", evs)
+	}
+
+	// a write that is not queued reports the context error
+	cctx, ccancel := context.WithCancel(ctx)
+	ccancel()
+	r := &relayOutput{cfg: &Config{WriteTimeout: time.Second}, msgCh: make(chan *outboundMessage)}
+	r.WriteEventAck(cctx, ev, func(err error) { acks <- err })
+	if err := <-acks; err == nil {
+		t.Fatal("expected an ack error")
+	}
+}
@@ -38,10 +38,12 @@ const (
 	loggingPrefix           = "[snmp_output:%s] "
 	defaultPort             = 162
 	defaultCommunity        = "public"
+	defaultVersion          = "2c"
 	minStartDelay           = 5 * time.Second
 	initialEventsBufferSize = 1000
 	//
 	sysUpTimeInstanceOID = "1.3.6.1.2.1.1.3.0"
+	snmpTrapOID          = "1.3.6.1.6.3.1.1.4.1.0"
 )
 
 func init() {
@@ -66,12 +68,18 @@ type snmpOutput struct {
 
 	cache     cache.Cache
 	startTime time.Time
+
+	// SNMPv3 message flags and security parameters
+	msgFlags g.SnmpV3MsgFlags
+	usp      *g.UsmSecurityParameters
 }
 
 type Config struct {
 	Address         string        `mapstructure:"address,omitempty" json:"address,omitempty"`
 	Port            uint16        `mapstructure:"port,omitempty" json:"port,omitempty"`
 	Community       string        `mapstructure:"community,omitempty" json:"community,omitempty"`
+	Version         string        `mapstructure:"version,omitempty" json:"version,omitempty"`
+	V3              *v3Config     `mapstructure:"v3,omitempty" json:"v3,omitempty"`
 	StartDelay      time.Duration `mapstructure:"start-delay,omitempty" json:"start-delay,omitempty"`
 	Traps           []*trap       `mapstructure:"traps,omitempty" json:"traps,omitempty"`
 	AddTarget       string        `mapstructure:"add-target,omitempty" json:"add-target,omitempty"`
@@ -93,6 +101,7 @@ type binding struct {
 
 type trap struct {
 	InformPDU bool       `mapstructure:"inform,omitempty" json:"inform,omitempty"`
+	TrapOID   string     `mapstructure:"trap-oid,omitempty" json:"trap-oid,omitempty"`
 	Trigger   *binding   `mapstructure:"trigger,omitempty" json:"trigger,omitempty"`
 	Bindings  []*binding `mapstructure:"bindings,omitempty" json:"bindings,omitempty"`
 }
@@ -136,10 +145,9 @@ func (s *snmpOutput) Init(ctx context.Context, name string, cfg map[string]inter
 		}
 	}
 
-	s.setDefaults()
-
-	if len(s.cfg.Traps) == 0 {
-		return errors.New("missing traps definition")
+	err = s.setDefaults()
+	if err != nil {
+		return err
 	}
 	for i, trap := range s.cfg.Traps {
 		if trap.Trigger == nil {
@@ -235,7 +243,22 @@ func (s *snmpOutput) Write(ctx context.Context, m proto.Message, meta outputs.Me
 	}
 }
 
-func (s *snmpOutput) WriteEvent(ctx context.Context, ev *formatters.EventMsg) {}
+func (s *snmpOutput) WriteEvent(ctx context.Context, ev *formatters.EventMsg) {
+	if ev == nil {
+		return
+	}
+	evs := []*formatters.EventMsg{ev}
+	for _, proc := range s.evps {
+		evs = proc.Apply(evs...)
+	}
+	for _, pev := range evs {
+		select {
+		case <-ctx.Done():
+			return
+		case s.eventChan <- pev:
+		}
+	}
+}
 
 func (s *snmpOutput) Close() error {
 	s.cancelFn()
@@ -292,24 +315,64 @@ func (s *snmpOutput) SetName(name string)                               {}
 func (s *snmpOutput) SetClusterName(name string)                        {}
 func (s *snmpOutput) SetTargetsConfig(c map[string]*types.TargetConfig) {}
 
-func (s *snmpOutput) setDefaults() {
+func (s *snmpOutput) setDefaults() error {
+	if len(s.cfg.Traps) == 0 {
+		return errors.New("missing traps definition")
+	}
 	if s.cfg.Port <= 0 {
 		s.cfg.Port = defaultPort
 	}
-	if s.cfg.Community == "" {
-		s.cfg.Community = defaultCommunity
-	}
 	if s.cfg.StartDelay < minStartDelay {
 		s.cfg.StartDelay = minStartDelay
 	}
+	switch strings.ToLower(s.cfg.Version) {
+	case "":
+		s.cfg.Version = defaultVersion
+		fallthrough
+	case "2c":
+		if s.cfg.Community == "" {
+			s.cfg.Community = defaultCommunity
+		}
+	case "3":
+		if s.cfg.V3 == nil {
+			return errors.New("missing v3 config")
+		}
+		// traps are sent using the local engine ID while informs
+		// use the receiver engine ID, the two cannot be mixed.
+		numInforms := 0
+		for _, trap := range s.cfg.Traps {
+			if trap.InformPDU {
+				numInforms++
+			}
+		}
+		if numInforms > 0 && numInforms < len(s.cfg.Traps) {
+			return errors.New("SNMPv3 traps and informs cannot be mixed in the same output")
+		}
+		var err error
+		s.msgFlags, s.usp, err = s.cfg.V3.securityParameters(s.name, numInforms > 0)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported SNMP version %q, expecting 2c or 3", s.cfg.Version)
+	}
+	return nil
 }
 
 func (s *snmpOutput) createSNMPHandler() {
 	s.snmpClient = g.NewHandler()
 	s.snmpClient.SetTarget(s.cfg.Address)
-	s.snmpClient.SetCommunity(s.cfg.Community)
 	s.snmpClient.SetPort(s.cfg.Port)
-	s.snmpClient.SetVersion(g.Version2c)
+	if s.usp != nil {
+		s.snmpClient.SetVersion(g.Version3)
+		s.snmpClient.SetSecurityModel(g.UserSecurityModel)
+		s.snmpClient.SetMsgFlags(s.msgFlags)
+		s.snmpClient.SetSecurityParameters(s.usp)
+		s.snmpClient.SetContextName(s.cfg.V3.ContextName)
+	} else {
+		s.snmpClient.SetCommunity(s.cfg.Community)
+		s.snmpClient.SetVersion(g.Version2c)
+	}
 CONN:
 	err := s.snmpClient.Connect()
 	if err != nil {
@@ -361,11 +424,7 @@ func pduType(typ string) g.Asn1BER {
 }
 
 func parseJQ(code string) (*gojq.Code, error) {
-	q, err := gojq.Parse(strings.TrimSpace(code))
-	if err != nil {
-		return nil, err
-	}
-	return gojq.Compile(q)
+	return formatters.CompileJQ(strings.TrimSpace(code))
 }
 
 func (s *snmpOutput) runJQ(code *gojq.Code, ev map[string]interface{}) (interface{}, error) {
@@ -414,6 +473,14 @@ func (s *snmpOutput) handleEvent(ev *formatters.EventMsg, idx int) error {
 		Value: uint32(time.Since(s.startTime).Seconds()),
 	})
 
+	if trap.TrapOID != "" {
+		pdus = append(pdus, g.SnmpPDU{
+			Name:  snmpTrapOID,
+			Type:  g.ObjectIdentifier,
+			Value: trap.TrapOID,
+		})
+	}
+
 	pdu, err := s.buildTriggerPDU(trap.Trigger, target, ev)
 	if err != nil {
 		err = fmt.Errorf("failed to build PDU from trigger: %v", err)
@@ -436,6 +503,10 @@ func (s *snmpOutput) handleEvent(ev *formatters.EventMsg, idx int) error {
 	}
 	//
 	snmpNumberOfSentTraps.WithLabelValues(s.name, fmt.Sprintf("%d", idx)).Add(1)
+	if s.usp != nil && s.usp.AuthoritativeEngineID != "" {
+		// the local engine time is checked by the receiver
+		s.usp.AuthoritativeEngineTime = uint32(time.Since(s.startTime).Seconds())
+	}
 	_, err = s.snmpClient.SendTrap(g.SnmpTrap{
 		Variables: pdus,
 		IsInform:  trap.InformPDU,
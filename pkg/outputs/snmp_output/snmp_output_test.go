@@ -0,0 +1,184 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package snmpoutput
+
+import (
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	g "github.com/gosnmp/gosnmp"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+)
+
+func TestEngineID(t *testing.T) {
+	c := &v3Config{}
+	id, err := c.engineID("out1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "\x80\x00\x19\x7f\x04gnmic-out1"
+	if id != want {
+		t.Errorf("got %x, want %x", id, want)
+	}
+	c.EngineID = "80:00:19:7f:04:61:62"
+	id, err = c.engineID("out1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "\x80\x00\x19\x7f\x04ab" {
+		t.Errorf("unexpected engine ID %x", id)
+	}
+	c.EngineID = "8000"
+	if _, err = c.engineID("out1"); err == nil {
+		t.Errorf("expected an error for a too short engine ID")
+	}
+}
+
+func TestSetDefaultsV3(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *Config
+		wantErr bool
+	}{
+		{
+			name: "authPriv",
+			cfg: &Config{Version: "3", Traps: []*trap{{}}, V3: &v3Config{
+				Username: "user1", SecurityLevel: "authPriv",
+				AuthProtocol: "sha256", AuthPassphrase: "authpass",
+				PrivProtocol: "aes", PrivPassphrase: "privpass",
+			}},
+		},
+		{
+			name: "missing passphrase",
+			cfg: &Config{Version: "3", Traps: []*trap{{}}, V3: &v3Config{
+				Username: "user1", SecurityLevel: "authNoPriv", AuthProtocol: "sha",
+			}},
+			wantErr: true,
+		},
+		{
+			name: "mixed traps and informs",
+			cfg: &Config{Version: "3", Traps: []*trap{{}, {InformPDU: true}}, V3: &v3Config{
+				Username: "user1",
+			}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown version",
+			cfg:     &Config{Version: "1", Traps: []*trap{{}}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &snmpOutput{name: "out1", cfg: tt.cfg}
+			err := s.setDefaults()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSendV3Trap(t *testing.T) {
+	l, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.LocalAddr().(*net.UDPAddr)
+	l.Close()
+
+	cfg := &Config{
+		Address: "127.0.0.1",
+		Port:    uint16(addr.Port),
+		Version: "3",
+		V3: &v3Config{
+			Username:       "user1",
+			SecurityLevel:  "authPriv",
+			AuthProtocol:   "SHA",
+			AuthPassphrase: "authpass",
+			PrivProtocol:   "AES",
+			PrivPassphrase: "privpass",
+		},
+		Traps: []*trap{{
+			TrapOID: "1.3.6.1.6.3.1.1.5.3",
+			Trigger: &binding{
+				Path:  "/interface/oper-state",
+				OID:   `"1.3.6.1.2.1.2.2.1.8"`,
+				Type:  "int",
+				Value: `if .values."/interface/oper-state" == "up" then 1 else 2 end`,
+			},
+		}},
+	}
+	s := &snmpOutput{
+		name:      "out1",
+		cfg:       cfg,
+		logger:    log.New(io.Discard, "", 0),
+		startTime: time.Now(),
+	}
+	if err := s.setDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	s.cfg.Traps[0].Trigger.oidTemplate, _ = parseJQ(s.cfg.Traps[0].Trigger.OID)
+	s.cfg.Traps[0].Trigger.valTemplate, _ = parseJQ(s.cfg.Traps[0].Trigger.Value)
+
+	received := make(chan *g.SnmpPacket, 1)
+	tl := g.NewTrapListener()
+	tl.Params = &g.GoSNMP{
+		Version:       g.Version3,
+		SecurityModel: g.UserSecurityModel,
+		MsgFlags:      g.AuthPriv,
+		SecurityParameters: &g.UsmSecurityParameters{
+			UserName:                 "user1",
+			AuthoritativeEngineID:    s.usp.AuthoritativeEngineID,
+			AuthenticationProtocol:   g.SHA,
+			AuthenticationPassphrase: "authpass",
+			PrivacyProtocol:          g.AES,
+			PrivacyPassphrase:        "privpass",
+		},
+		Logger: g.NewLogger(log.New(io.Discard, "", 0)),
+	}
+	tl.OnNewTrap = func(p *g.SnmpPacket, _ *net.UDPAddr) {
+		received <- p
+	}
+	go tl.Listen(net.JoinHostPort("127.0.0.1", strconv.Itoa(addr.Port)))
+	defer tl.Close()
+	<-tl.Listening()
+
+	s.createSNMPHandler()
+	defer s.snmpClient.Close()
+	err = s.handleEvent(&formatters.EventMsg{
+		Name: "sub1",
+		Tags: map[string]string{"source": "router1"},
+		Values: map[string]interface{}{
+			"/interface/oper-state": "down",
+		},
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case p := <-received:
+		if len(p.Variables) != 3 {
+			t.Fatalf("got %d variables, want 3", len(p.Variables))
+		}
+		if p.Variables[1].Name != "."+snmpTrapOID || p.Variables[1].Value != ".1.3.6.1.6.3.1.1.5.3" {
+			t.Errorf("unexpected snmpTrapOID binding: %+v", p.Variables[1])
+		}
+		if p.Variables[2].Value != 2 {
+			t.Errorf("unexpected trigger binding: %+v", p.Variables[2])
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("trap not received")
+	}
+}
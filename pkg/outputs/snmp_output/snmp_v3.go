@@ -0,0 +1,141 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package snmpoutput
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	g "github.com/gosnmp/gosnmp"
+)
+
+const (
+	// Nokia private enterprise number, used to build the default engine ID
+	enterpriseNumber = 6527
+	maxEngineIDLen   = 32
+)
+
+type v3Config struct {
+	Username string `mapstructure:"username,omitempty" json:"username,omitempty"`
+	// one of noAuthNoPriv, authNoPriv or authPriv
+	SecurityLevel  string `mapstructure:"security-level,omitempty" json:"security-level,omitempty"`
+	AuthProtocol   string `mapstructure:"auth-protocol,omitempty" json:"auth-protocol,omitempty"`
+	AuthPassphrase string `mapstructure:"auth-passphrase,omitempty" json:"-"`
+	PrivProtocol   string `mapstructure:"priv-protocol,omitempty" json:"priv-protocol,omitempty"`
+	PrivPassphrase string `mapstructure:"priv-passphrase,omitempty" json:"-"`
+	// hex encoded local engine ID, used to send traps
+	EngineID    string `mapstructure:"engine-id,omitempty" json:"engine-id,omitempty"`
+	ContextName string `mapstructure:"context-name,omitempty" json:"context-name,omitempty"`
+}
+
+var authProtocols = map[string]g.SnmpV3AuthProtocol{
+	"MD5":    g.MD5,
+	"SHA":    g.SHA,
+	"SHA224": g.SHA224,
+	"SHA256": g.SHA256,
+	"SHA384": g.SHA384,
+	"SHA512": g.SHA512,
+}
+
+var privProtocols = map[string]g.SnmpV3PrivProtocol{
+	"DES":     g.DES,
+	"AES":     g.AES,
+	"AES192":  g.AES192,
+	"AES256":  g.AES256,
+	"AES192C": g.AES192C,
+	"AES256C": g.AES256C,
+}
+
+// securityParameters validates the v3 config and returns the message flags
+// and USM parameters. If isInform is true, the engine ID is discovered
+// from the receiver, otherwise the local engine ID is used.
+func (c *v3Config) securityParameters(name string, isInform bool) (g.SnmpV3MsgFlags, *g.UsmSecurityParameters, error) {
+	if c.Username == "" {
+		return 0, nil, errors.New("missing v3 username")
+	}
+	sp := &g.UsmSecurityParameters{
+		UserName:               c.Username,
+		AuthenticationProtocol: g.NoAuth,
+		PrivacyProtocol:        g.NoPriv,
+	}
+	var flags g.SnmpV3MsgFlags
+	switch strings.ToLower(c.SecurityLevel) {
+	case "", "noauthnopriv":
+		flags = g.NoAuthNoPriv
+	case "authnopriv":
+		flags = g.AuthNoPriv
+	case "authpriv":
+		flags = g.AuthPriv
+	default:
+		return 0, nil, fmt.Errorf("unknown v3 security-level %q, expecting one of noAuthNoPriv, authNoPriv or authPriv", c.SecurityLevel)
+	}
+	if flags&g.AuthNoPriv != 0 {
+		p, ok := authProtocols[strings.ToUpper(c.AuthProtocol)]
+		if !ok {
+			return 0, nil, fmt.Errorf("unknown v3 auth-protocol %q", c.AuthProtocol)
+		}
+		if c.AuthPassphrase == "" {
+			return 0, nil, errors.New("missing v3 auth-passphrase")
+		}
+		sp.AuthenticationProtocol = p
+		sp.AuthenticationPassphrase = c.AuthPassphrase
+	}
+	if flags&g.AuthPriv == g.AuthPriv {
+		p, ok := privProtocols[strings.ToUpper(c.PrivProtocol)]
+		if !ok {
+			return 0, nil, fmt.Errorf("unknown v3 priv-protocol %q", c.PrivProtocol)
+		}
+		if c.PrivPassphrase == "" {
+			return 0, nil, errors.New("missing v3 priv-passphrase")
+		}
+		sp.PrivacyProtocol = p
+		sp.PrivacyPassphrase = c.PrivPassphrase
+	}
+	if isInform {
+		if c.EngineID != "" {
+			return 0, nil, errors.New("v3 engine-id must not be set when sending informs, it is discovered from the receiver")
+		}
+		return flags, sp, nil
+	}
+	engineID, err := c.engineID(name)
+	if err != nil {
+		return 0, nil, err
+	}
+	sp.AuthoritativeEngineID = engineID
+	// the local engine is the authoritative engine of the traps
+	sp.AuthoritativeEngineBoots = 1
+	return flags, sp, nil
+}
+
+// engineID returns the configured engine ID,
+// or an engine ID built from the output name in the RFC 3411 text format.
+func (c *v3Config) engineID(name string) (string, error) {
+	if c.EngineID != "" {
+		b, err := hex.DecodeString(strings.TrimPrefix(strings.ReplaceAll(c.EngineID, ":", ""), "0x"))
+		if err != nil {
+			return "", fmt.Errorf("invalid v3 engine-id %q: %v", c.EngineID, err)
+		}
+		if len(b) < 5 || len(b) > maxEngineIDLen {
+			return "", fmt.Errorf("invalid v3 engine-id %q: must be 5 to %d bytes long", c.EngineID, maxEngineIDLen)
+		}
+		return string(b), nil
+	}
+	b := make([]byte, 4, maxEngineIDLen)
+	binary.BigEndian.PutUint32(b, 0x80000000|enterpriseNumber)
+	// text format
+	b = append(b, 4)
+	b = append(b, "gnmic-"+name...)
+	if len(b) > maxEngineIDLen {
+		b = b[:maxEngineIDLen]
+	}
+	return string(b), nil
+}
@@ -0,0 +1,55 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package outputs
+
+import (
+	"strconv"
+	"time"
+)
+
+// SyslogTimestampFormat is the RFC 5424 timestamp format,
+// RFC 5424 timestamps have at most microsecond precision.
+const SyslogTimestampFormat = "2006-01-02T15:04:05.000000Z07:00"
+
+// SyslogHeader holds the header fields of an RFC 5424 message.
+type SyslogHeader struct {
+	Facility  int
+	Severity  int
+	Timestamp time.Time
+	Hostname  string
+	AppName   string
+	ProcID    string
+	MsgID     string
+}
+
+// AppendSyslogHeader appends the RFC 5424 header h followed by a space to b:
+// <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID
+// The empty fields are replaced with the NILVALUE.
+func AppendSyslogHeader(b []byte, h *SyslogHeader) []byte {
+	b = append(b, '<')
+	b = strconv.AppendInt(b, int64(h.Facility*8+h.Severity), 10)
+	b = append(b, ">1 "...)
+	b = h.Timestamp.UTC().AppendFormat(b, SyslogTimestampFormat)
+	for _, f := range []string{h.Hostname, h.AppName, h.ProcID, h.MsgID} {
+		b = append(b, ' ')
+		if f == "" {
+			f = "-"
+		}
+		b = append(b, f...)
+	}
+	return append(b, ' ')
+}
+
+// OctetCounting prepends the message length followed by a space, as defined in RFC 6587.
+func OctetCounting(b []byte) []byte {
+	fb := make([]byte, 0, len(b)+8)
+	fb = strconv.AppendInt(fb, int64(len(b)), 10)
+	fb = append(fb, ' ')
+	return append(fb, b...)
+}
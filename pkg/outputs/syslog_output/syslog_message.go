@@ -0,0 +1,301 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package syslog_output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/api/utils"
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/gtemplate"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+const (
+	// RFC 5424 header fields max lengths
+	maxHostnameLen = 255
+	maxAppNameLen  = 48
+	maxMsgIDLen    = 32
+	maxSDNameLen   = 32
+)
+
+var severities = map[string]int{
+	"emerg":   0,
+	"alert":   1,
+	"crit":    2,
+	"err":     3,
+	"error":   3,
+	"warning": 4,
+	"warn":    4,
+	"notice":  5,
+	"info":    6,
+	"debug":   7,
+}
+
+// formatter builds RFC 5424 messages from events.
+type formatter struct {
+	facility    int
+	severity    int
+	appName     string
+	procID      string
+	localHost   string
+	severityTpl *template.Template
+	hostnameTpl *template.Template
+	msgIDTpl    *template.Template
+	msgTpl      *template.Template
+	sd          []*sdElement
+}
+
+type sdElement struct {
+	id     string
+	params []*sdParam
+}
+
+type sdParam struct {
+	name string
+	tpl  *template.Template
+}
+
+func newFormatter(cfg *Config) (*formatter, error) {
+	f := &formatter{
+		facility:  cfg.Facility,
+		severity:  cfg.Severity,
+		appName:   headerField(cfg.AppName, maxAppNameLen),
+		procID:    strconv.Itoa(os.Getpid()),
+		localHost: headerField(localHostname(), maxHostnameLen),
+	}
+	var err error
+	if cfg.SeverityTemplate != "" {
+		f.severityTpl, err = parseTemplate("severity-template", cfg.SeverityTemplate)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Hostname != "" {
+		f.hostnameTpl, err = parseTemplate("hostname", cfg.Hostname)
+		if err != nil {
+			return nil, err
+		}
+	}
+	f.msgIDTpl, err = parseTemplate("msg-id", cfg.MsgID)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MessageTemplate != "" {
+		f.msgTpl, err = parseTemplate("message-template", cfg.MessageTemplate)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// sort the SD elements and params to produce stable messages
+	ids := make([]string, 0, len(cfg.StructuredData))
+	for id := range cfg.StructuredData {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if err := validateSDName(id); err != nil {
+			return nil, fmt.Errorf("invalid SD-ID %q: %v", id, err)
+		}
+		el := &sdElement{id: id}
+		names := make([]string, 0, len(cfg.StructuredData[id]))
+		for name := range cfg.StructuredData[id] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := validateSDName(name); err != nil {
+				return nil, fmt.Errorf("invalid SD-ID %q param name %q: %v", id, name, err)
+			}
+			tpl, err := parseTemplate(id+"."+name, cfg.StructuredData[id][name])
+			if err != nil {
+				return nil, err
+			}
+			el.params = append(el.params, &sdParam{name: name, tpl: tpl})
+		}
+		f.sd = append(f.sd, el)
+	}
+	return f, nil
+}
+
+func parseTemplate(name, text string) (*template.Template, error) {
+	tpl, err := gtemplate.CreateTemplate(name, text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s template: %v", name, err)
+	}
+	return tpl.Funcs(outputs.TemplateFuncs), nil
+}
+
+// format returns the RFC 5424 message for an event:
+// <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+func (f *formatter) format(ev *formatters.EventMsg) ([]byte, error) {
+	in, err := outputs.TemplateInput(ev)
+	if err != nil {
+		return nil, err
+	}
+	severity := f.severity
+	if f.severityTpl != nil {
+		s, err := execTemplate(f.severityTpl, in)
+		if err != nil {
+			return nil, err
+		}
+		if s != "" {
+			severity, err = parseSeverity(s)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	hostname, err := f.hostname(ev, in)
+	if err != nil {
+		return nil, err
+	}
+	msgID, err := execTemplate(f.msgIDTpl, in)
+	if err != nil {
+		return nil, err
+	}
+	ts := time.Now()
+	if ev.Timestamp > 0 {
+		ts = time.Unix(0, ev.Timestamp)
+	}
+
+	b := bytes.NewBuffer(outputs.AppendSyslogHeader(make([]byte, 0, 256), &outputs.SyslogHeader{
+		Facility:  f.facility,
+		Severity:  severity,
+		Timestamp: ts,
+		Hostname:  hostname,
+		AppName:   f.appName,
+		ProcID:    f.procID,
+		MsgID:     headerField(msgID, maxMsgIDLen),
+	}))
+	err = f.structuredData(b, in)
+	if err != nil {
+		return nil, err
+	}
+	b.WriteByte(' ')
+	if f.msgTpl == nil {
+		msg, err := json.Marshal(ev)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(msg)
+		return b.Bytes(), nil
+	}
+	err = gtemplate.Execute(b, f.msgTpl, in)
+	if err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// hostname returns the configured hostname template output,
+// the event source host or the local host name.
+func (f *formatter) hostname(ev *formatters.EventMsg, in interface{}) (string, error) {
+	if f.hostnameTpl != nil {
+		h, err := execTemplate(f.hostnameTpl, in)
+		if err != nil {
+			return "", err
+		}
+		return headerField(h, maxHostnameLen), nil
+	}
+	if source := ev.Tags["source"]; source != "" {
+		return headerField(utils.GetHost(source), maxHostnameLen), nil
+	}
+	return f.localHost, nil
+}
+
+func (f *formatter) structuredData(b *bytes.Buffer, in interface{}) error {
+	if len(f.sd) == 0 {
+		b.WriteByte('-')
+		return nil
+	}
+	for _, el := range f.sd {
+		b.WriteByte('[')
+		b.WriteString(el.id)
+		for _, p := range el.params {
+			v, err := execTemplate(p.tpl, in)
+			if err != nil {
+				return err
+			}
+			b.WriteByte(' ')
+			b.WriteString(p.name)
+			b.WriteString(`="`)
+			b.WriteString(escapeParamValue(v))
+			b.WriteByte('"')
+		}
+		b.WriteByte(']')
+	}
+	return nil
+}
+
+func execTemplate(tpl *template.Template, in interface{}) (string, error) {
+	sb := new(strings.Builder)
+	err := gtemplate.Execute(sb, tpl, in)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// parseSeverity parses a severity number or keyword.
+func parseSeverity(s string) (int, error) {
+	if sev, ok := severities[strings.ToLower(s)]; ok {
+		return sev, nil
+	}
+	sev, err := strconv.Atoi(s)
+	if err != nil || sev < 0 || sev > 7 {
+		return 0, fmt.Errorf("invalid severity %q", s)
+	}
+	return sev, nil
+}
+
+// headerField replaces the characters not allowed in a header field
+// with an underscore and truncates it to max bytes.
+// An empty field is replaced with the NILVALUE.
+func headerField(s string, max int) string {
+	if s == "" {
+		return "-"
+	}
+	b := []byte(s)
+	for i, c := range b {
+		if c < 33 || c > 126 {
+			b[i] = '_'
+		}
+	}
+	if len(b) > max {
+		b = b[:max]
+	}
+	return string(b)
+}
+
+func validateSDName(s string) error {
+	if s == "" || len(s) > maxSDNameLen {
+		return fmt.Errorf("must be 1 to %d characters long", maxSDNameLen)
+	}
+	for _, c := range []byte(s) {
+		if c < 33 || c > 126 || c == '=' || c == ']' || c == '"' {
+			return fmt.Errorf("must only contain printable characters other than '=', ']' and '\"'")
+		}
+	}
+	return nil
+}
+
+var paramValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+func escapeParamValue(s string) string {
+	return paramValueEscaper.Replace(s)
+}
@@ -0,0 +1,414 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package syslog_output
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openconfig/gnmic/pkg/api/types"
+	"github.com/openconfig/gnmic/pkg/api/utils"
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/gtemplate"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+const (
+	Type                 = "syslog"
+	loggingPrefix        = "[syslog_output:%s] "
+	defaultNetwork       = "udp"
+	defaultFacility      = 1 // user-level messages
+	defaultSeverity      = 6 // informational
+	defaultAppName       = "gnmic"
+	defaultMsgIDTemplate = "{{ .name }}"
+	defaultBufferSize    = 1000
+	defaultRetryInterval = 2 * time.Second
+	defaultDialTimeout   = 10 * time.Second
+)
+
+func init() {
+	outputs.Register(Type, func() outputs.Output {
+		return &syslogOutput{
+			cfg:    &Config{},
+			logger: log.New(io.Discard, loggingPrefix, utils.DefaultLoggingFlags),
+		}
+	})
+}
+
+// syslogOutput sends events as RFC 5424 syslog messages.
+type syslogOutput struct {
+	cfg    *Config
+	logger *log.Logger
+	cfn    context.CancelFunc
+
+	eventCh   chan *formatters.EventMsg
+	evps      []formatters.EventProcessor
+	tlsConfig *tls.Config
+	health    outputs.Health
+
+	targetTpl *template.Template
+	fmt       *formatter
+}
+
+type Config struct {
+	Name    string           `mapstructure:"name,omitempty" json:"name,omitempty"`
+	Address string           `mapstructure:"address,omitempty" json:"address,omitempty"`
+	Network string           `mapstructure:"network,omitempty" json:"network,omitempty"`
+	TLS     *types.TLSConfig `mapstructure:"tls,omitempty" json:"tls,omitempty"`
+	// syslog header
+	Facility         int    `mapstructure:"facility,omitempty" json:"facility,omitempty"`
+	Severity         int    `mapstructure:"severity,omitempty" json:"severity,omitempty"`
+	SeverityTemplate string `mapstructure:"severity-template,omitempty" json:"severity-template,omitempty"`
+	Hostname         string `mapstructure:"hostname,omitempty" json:"hostname,omitempty"`
+	AppName          string `mapstructure:"app-name,omitempty" json:"app-name,omitempty"`
+	MsgID            string `mapstructure:"msg-id,omitempty" json:"msg-id,omitempty"`
+	// SD-ID to SD-PARAM name to SD-PARAM value template
+	StructuredData  map[string]map[string]string `mapstructure:"structured-data,omitempty" json:"structured-data,omitempty"`
+	MessageTemplate string                       `mapstructure:"message-template,omitempty" json:"message-template,omitempty"`
+	BufferSize      int                          `mapstructure:"buffer-size,omitempty" json:"buffer-size,omitempty"`
+	RetryInterval   time.Duration                `mapstructure:"retry-interval,omitempty" json:"retry-interval,omitempty"`
+	DialTimeout     time.Duration                `mapstructure:"dial-timeout,omitempty" json:"dial-timeout,omitempty"`
+	AddTarget       string                       `mapstructure:"add-target,omitempty" json:"add-target,omitempty"`
+	TargetTemplate  string                       `mapstructure:"target-template,omitempty" json:"target-template,omitempty"`
+	EventProcessors []string                     `mapstructure:"event-processors,omitempty" json:"event-processors,omitempty"`
+	EnableMetrics   bool                         `mapstructure:"enable-metrics,omitempty" json:"enable-metrics,omitempty"`
+	Debug           bool                         `mapstructure:"debug,omitempty" json:"debug,omitempty"`
+}
+
+func (s *syslogOutput) Init(ctx context.Context, name string, cfg map[string]interface{}, opts ...outputs.Option) error {
+	err := outputs.DecodeConfig(cfg, s.cfg)
+	if err != nil {
+		return err
+	}
+	if s.cfg.Name == "" {
+		s.cfg.Name = name
+	}
+	s.logger.SetPrefix(fmt.Sprintf(loggingPrefix, s.cfg.Name))
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return err
+		}
+	}
+	err = s.setDefaults()
+	if err != nil {
+		return err
+	}
+	s.fmt, err = newFormatter(s.cfg)
+	if err != nil {
+		return err
+	}
+	if s.cfg.TargetTemplate == "" {
+		s.targetTpl = outputs.DefaultTargetTemplate
+	} else if s.cfg.AddTarget != "" {
+		s.targetTpl, err = gtemplate.CreateTemplate("target-template", s.cfg.TargetTemplate)
+		if err != nil {
+			return err
+		}
+		s.targetTpl = s.targetTpl.Funcs(outputs.TemplateFuncs)
+	}
+	if s.cfg.Network == "tls" {
+		tlsCfg := s.cfg.TLS
+		if tlsCfg == nil {
+			tlsCfg = new(types.TLSConfig)
+		}
+		s.tlsConfig, err = utils.NewTLSConfig(
+			tlsCfg.CaFile,
+			tlsCfg.CertFile,
+			tlsCfg.KeyFile,
+			"",
+			tlsCfg.SkipVerify,
+			false,
+		)
+		if err != nil {
+			return err
+		}
+		if s.tlsConfig == nil {
+			// use the system CAs
+			s.tlsConfig = new(tls.Config)
+		}
+	}
+	s.eventCh = make(chan *formatters.EventMsg, s.cfg.BufferSize)
+	ctx, s.cfn = context.WithCancel(ctx)
+	go s.start(ctx)
+	s.logger.Printf("initialized syslog output: %s", s.String())
+	return nil
+}
+
+func (s *syslogOutput) setDefaults() error {
+	if s.cfg.Address == "" {
+		return errors.New("missing address field")
+	}
+	s.cfg.Network = strings.ToLower(s.cfg.Network)
+	switch s.cfg.Network {
+	case "":
+		s.cfg.Network = defaultNetwork
+	case "udp", "tcp", "tls":
+	default:
+		return fmt.Errorf("unsupported network %q, expecting one of udp, tcp or tls", s.cfg.Network)
+	}
+	if s.cfg.TLS != nil && s.cfg.Network != "tls" {
+		return fmt.Errorf("tls is only supported with network tls")
+	}
+	if s.cfg.Facility < 0 || s.cfg.Facility > 23 {
+		return fmt.Errorf("invalid facility %d, expecting a value between 0 and 23", s.cfg.Facility)
+	}
+	if s.cfg.Facility == 0 {
+		s.cfg.Facility = defaultFacility
+	}
+	if s.cfg.Severity < 0 || s.cfg.Severity > 7 {
+		return fmt.Errorf("invalid severity %d, expecting a value between 0 and 7", s.cfg.Severity)
+	}
+	if s.cfg.Severity == 0 {
+		s.cfg.Severity = defaultSeverity
+	}
+	if s.cfg.AppName == "" {
+		s.cfg.AppName = defaultAppName
+	}
+	if s.cfg.MsgID == "" {
+		s.cfg.MsgID = defaultMsgIDTemplate
+	}
+	if s.cfg.BufferSize <= 0 {
+		s.cfg.BufferSize = defaultBufferSize
+	}
+	if s.cfg.RetryInterval <= 0 {
+		s.cfg.RetryInterval = defaultRetryInterval
+	}
+	if s.cfg.DialTimeout <= 0 {
+		s.cfg.DialTimeout = defaultDialTimeout
+	}
+	return nil
+}
+
+func (s *syslogOutput) Write(ctx context.Context, rsp proto.Message, meta outputs.Meta) {
+	if rsp == nil {
+		return
+	}
+	switch rsp := rsp.(type) {
+	case *gnmi.SubscribeResponse:
+		measName := "default"
+		if subName, ok := meta["subscription-name"]; ok {
+			measName = subName
+		}
+		var err error
+		rsp, err = outputs.AddSubscriptionTarget(rsp, meta, s.cfg.AddTarget, s.targetTpl)
+		if err != nil {
+			s.logger.Printf("failed to add target to the response: %v", err)
+		}
+		events, err := formatters.ResponseToEventMsgs(measName, rsp, meta, s.evps...)
+		if err != nil {
+			s.logger.Printf("failed to convert message to event: %v", err)
+			return
+		}
+		for _, ev := range events {
+			s.enqueue(ctx, ev)
+		}
+	}
+}
+
+func (s *syslogOutput) WriteEvent(ctx context.Context, ev *formatters.EventMsg) {
+	if ev == nil {
+		return
+	}
+	evs := []*formatters.EventMsg{ev}
+	for _, proc := range s.evps {
+		evs = proc.Apply(evs...)
+	}
+	for _, pev := range evs {
+		s.enqueue(ctx, pev)
+	}
+}
+
+func (s *syslogOutput) enqueue(ctx context.Context, ev *formatters.EventMsg) {
+	select {
+	case <-ctx.Done():
+	case s.eventCh <- ev:
+	default:
+		if s.cfg.Debug {
+			s.logger.Printf("buffer full, dropping event")
+		}
+		s.failed("buffer_full")
+	}
+}
+
+// start sends the buffered events, reconnecting after failures.
+// A message that failed to be sent is sent again after reconnecting.
+func (s *syslogOutput) start(ctx context.Context) {
+	var pending []byte
+	for {
+		conn, err := s.dial(ctx)
+		if err == nil {
+			s.health.Set(nil)
+			pending, err = s.send(ctx, conn, pending)
+			conn.Close()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		s.health.Set(err)
+		s.logger.Printf("connection to %s failed: %v, retrying in %s", s.cfg.Address, err, s.cfg.RetryInterval)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.cfg.RetryInterval):
+		}
+	}
+}
+
+func (s *syslogOutput) dial(ctx context.Context) (net.Conn, error) {
+	d := &net.Dialer{Timeout: s.cfg.DialTimeout}
+	switch s.cfg.Network {
+	case "tls":
+		td := &tls.Dialer{
+			NetDialer: d,
+			Config:    s.tlsConfig,
+		}
+		return td.DialContext(ctx, "tcp", s.cfg.Address)
+	default:
+		return d.DialContext(ctx, s.cfg.Network, s.cfg.Address)
+	}
+}
+
+// send writes the pending message if any, then the buffered events,
+// until a write fails. It returns the message that failed to be written.
+func (s *syslogOutput) send(ctx context.Context, conn net.Conn, pending []byte) ([]byte, error) {
+	if pending != nil {
+		if err := s.write(conn, pending); err != nil {
+			return pending, err
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case ev := <-s.eventCh:
+			b, err := s.fmt.format(ev)
+			if err != nil {
+				s.logger.Printf("failed to format event: %v", err)
+				s.failed("template_error")
+				continue
+			}
+			if s.cfg.Debug {
+				s.logger.Printf("sending: %s", b)
+			}
+			if err := s.write(conn, b); err != nil {
+				return b, err
+			}
+		}
+	}
+}
+
+// write sends a message, framed using octet counting (RFC 6587)
+// over TCP and TLS, as a single datagram over UDP.
+func (s *syslogOutput) write(conn net.Conn, b []byte) error {
+	if s.cfg.Network != "udp" {
+		b = outputs.OctetCounting(b)
+	}
+	_, err := conn.Write(b)
+	if err != nil {
+		s.failed("write_error")
+		return err
+	}
+	if s.cfg.EnableMetrics {
+		syslogNumberOfSentMsgs.WithLabelValues(s.cfg.Name).Inc()
+	}
+	return nil
+}
+
+func (s *syslogOutput) failed(reason string) {
+	if s.cfg.EnableMetrics {
+		syslogNumberOfFailedMsgs.WithLabelValues(s.cfg.Name, reason).Inc()
+	}
+}
+
+func (s *syslogOutput) Close() error {
+	if s.cfn != nil {
+		s.cfn()
+	}
+	return nil
+}
+
+// Healthy returns the last connection or write error.
+func (s *syslogOutput) Healthy() error {
+	return s.health.Get()
+}
+
+func (s *syslogOutput) RegisterMetrics(reg *prometheus.Registry) {
+	if !s.cfg.EnableMetrics {
+		return
+	}
+	if reg == nil {
+		s.logger.Printf("ERR: output metrics enabled but main registry is not initialized, enable main metrics under `api-server`")
+		return
+	}
+	if err := registerMetrics(reg); err != nil {
+		s.logger.Printf("failed to register metrics: %v", err)
+	}
+}
+
+func (s *syslogOutput) String() string {
+	b, err := json.Marshal(s.cfg)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func (s *syslogOutput) SetLogger(logger *log.Logger) {
+	if logger != nil && s.logger != nil {
+		s.logger.SetOutput(logger.Writer())
+		s.logger.SetFlags(logger.Flags())
+	}
+}
+
+func (s *syslogOutput) SetEventProcessors(ps map[string]map[string]interface{},
+	logger *log.Logger,
+	tcs map[string]*types.TargetConfig,
+	acts map[string]map[string]interface{}) error {
+	var err error
+	s.evps, err = formatters.MakeEventProcessors(
+		logger,
+		s.cfg.EventProcessors,
+		ps,
+		tcs,
+		acts,
+	)
+	return err
+}
+
+func (s *syslogOutput) SetName(name string) {
+	if s.cfg.Name == "" {
+		s.cfg.Name = name
+	}
+}
+
+func (s *syslogOutput) SetClusterName(string) {}
+
+func (s *syslogOutput) SetTargetsConfig(map[string]*types.TargetConfig) {}
+
+func localHostname() string {
+	h, err := os.Hostname()
+	if err != nil {
+		return ""
+	}
+	return h
+}
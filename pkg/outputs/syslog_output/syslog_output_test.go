@@ -0,0 +1,141 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package syslog_output
+
+import (
+	"context"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+var testEvent = &formatters.EventMsg{
+	Name:      "ifaces",
+	Timestamp: time.Date(2024, 3, 1, 10, 20, 30, 123456789, time.UTC).UnixNano(),
+	Tags: map[string]string{
+		"source":         "router1:57400",
+		"interface_name": "ethernet-1/1",
+	},
+	Values: map[string]interface{}{
+		"/interface/oper-state": "down",
+	},
+}
+
+func TestFormat(t *testing.T) {
+	pid := strconv.Itoa(os.Getpid())
+	tests := []struct {
+		name string
+		cfg  *Config
+		want string
+	}{
+		{
+			name: "defaults",
+			cfg:  &Config{Address: "localhost:514"},
+			want: "<14>1 2024-03-01T10:20:30.123456Z router1 gnmic " + pid + " ifaces - " +
+				`{"name":"ifaces","timestamp":1709288430123456789,"tags":{"interface_name":"ethernet-1/1","source":"router1:57400"},"values":{"/interface/oper-state":"down"}}`,
+		},
+		{
+			name: "templates",
+			cfg: &Config{
+				Address:          "localhost:514",
+				Facility:         16,
+				SeverityTemplate: `{{ if eq (index .values "/interface/oper-state") "down" }}warning{{ end }}`,
+				Hostname:         `{{ index .tags "interface_name" }} x`,
+				MsgID:            "IF_STATE",
+				StructuredData: map[string]map[string]string{
+					"iface@53683": {
+						"state": `{{ index .values "/interface/oper-state" }}`,
+						"name":  `{{ index .tags "interface_name" }}]"`,
+					},
+				},
+				MessageTemplate: `interface {{ index .tags "interface_name" }} is {{ index .values "/interface/oper-state" }}`,
+			},
+			want: "<132>1 2024-03-01T10:20:30.123456Z ethernet-1/1_x gnmic " + pid + " IF_STATE " +
+				`[iface@53683 name="ethernet-1/1\]\"" state="down"] interface ethernet-1/1 is down`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &syslogOutput{cfg: tt.cfg}
+			if err := s.setDefaults(); err != nil {
+				t.Fatal(err)
+			}
+			f, err := newFormatter(s.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := f.format(testEvent)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalidSDName(t *testing.T) {
+	_, err := newFormatter(&Config{
+		MsgID:          defaultMsgIDTemplate,
+		StructuredData: map[string]map[string]string{"bad id": {"p": "v"}},
+	})
+	if err == nil {
+		t.Errorf("expected an error for an SD-ID with a space")
+	}
+}
+
+func TestSendTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	o := outputs.Outputs[Type]()
+	err = o.Init(ctx, "test", map[string]interface{}{
+		"address": l.Addr().String(),
+		"network": "tcp",
+		"msg-id":  "TEST",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer o.Close()
+	o.WriteEvent(ctx, testEvent)
+
+	conn, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	b := make([]byte, 4096)
+	n, err := conn.Read(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	length, msg, ok := strings.Cut(string(b[:n]), " ")
+	if !ok {
+		t.Fatalf("missing octet count: %q", b[:n])
+	}
+	if l, _ := strconv.Atoi(length); l != len(msg) {
+		t.Errorf("octet count %s does not match message length %d", length, len(msg))
+	}
+	if !strings.HasPrefix(msg, "<14>1 ") || !strings.Contains(msg, " TEST - ") {
+		t.Errorf("unexpected message: %q", msg)
+	}
+}
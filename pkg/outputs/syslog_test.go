@@ -0,0 +1,36 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package outputs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAppendSyslogHeader(t *testing.T) {
+	h := &SyslogHeader{
+		Facility:  1,
+		Severity:  6,
+		Timestamp: time.Unix(1704164645, 123456789),
+		Hostname:  "r1",
+		AppName:   "gnmic",
+		ProcID:    "42",
+	}
+	got := string(AppendSyslogHeader([]byte("x"), h))
+	want := "x<14>1 2024-01-02T03:04:05.123456Z r1 gnmic 42 - "
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestOctetCounting(t *testing.T) {
+	if got := string(OctetCounting([]byte("hello"))); got != "5 hello" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,126 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tcp_output
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+const (
+	framingNewline       = "newline"
+	framingLengthPrefix  = "length-prefix"
+	framingOctetCounting = "octet-counting"
+	framingSyslog        = "syslog"
+
+	defaultSyslogFacility = 1 // user-level messages
+	defaultSyslogSeverity = 6 // informational
+	defaultSyslogAppName  = "gnmic"
+)
+
+// SyslogConfig sets the header fields of the RFC 5424 messages
+// sent when framing is syslog.
+type SyslogConfig struct {
+	Facility int    `mapstructure:"facility,omitempty" json:"facility,omitempty"`
+	Severity int    `mapstructure:"severity,omitempty" json:"severity,omitempty"`
+	Hostname string `mapstructure:"hostname,omitempty" json:"hostname,omitempty"`
+	AppName  string `mapstructure:"app-name,omitempty" json:"app-name,omitempty"`
+	MsgID    string `mapstructure:"msg-id,omitempty" json:"msg-id,omitempty"`
+}
+
+// framer wraps a marshaled message in the configured framing.
+type framer func(b []byte) []byte
+
+func (t *tcpOutput) newFramer() (framer, error) {
+	switch strings.ToLower(t.cfg.Framing) {
+	case "":
+		// no framing, the optional delimiter is appended to each message
+		delimiter := []byte(t.cfg.Delimiter)
+		return func(b []byte) []byte {
+			return append(b, delimiter...)
+		}, nil
+	case framingNewline:
+		return func(b []byte) []byte {
+			return append(b, '\n')
+		}, nil
+	case framingLengthPrefix:
+		return lengthPrefix, nil
+	case framingOctetCounting:
+		return outputs.OctetCounting, nil
+	case framingSyslog:
+		if t.cfg.Syslog == nil {
+			t.cfg.Syslog = new(SyslogConfig)
+		}
+		err := t.cfg.Syslog.setDefaults()
+		if err != nil {
+			return nil, err
+		}
+		return func(b []byte) []byte {
+			return outputs.OctetCounting(t.cfg.Syslog.message(time.Now(), b))
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown framing %q, expecting one of %s, %s, %s or %s",
+		t.cfg.Framing, framingNewline, framingLengthPrefix, framingOctetCounting, framingSyslog)
+}
+
+// lengthPrefix prepends the message length as a 4 bytes big endian integer.
+func lengthPrefix(b []byte) []byte {
+	fb := make([]byte, 4, 4+len(b))
+	binary.BigEndian.PutUint32(fb, uint32(len(b)))
+	return append(fb, b...)
+}
+
+func (s *SyslogConfig) setDefaults() error {
+	if s.Facility < 0 || s.Facility > 23 {
+		return fmt.Errorf("invalid syslog facility %d, expecting a value between 0 and 23", s.Facility)
+	}
+	if s.Facility == 0 {
+		s.Facility = defaultSyslogFacility
+	}
+	if s.Severity < 0 || s.Severity > 7 {
+		return fmt.Errorf("invalid syslog severity %d, expecting a value between 0 and 7", s.Severity)
+	}
+	if s.Severity == 0 {
+		s.Severity = defaultSyslogSeverity
+	}
+	if s.Hostname == "" {
+		s.Hostname, _ = os.Hostname()
+	}
+	if s.AppName == "" {
+		s.AppName = defaultSyslogAppName
+	}
+	for name, v := range map[string]string{"hostname": s.Hostname, "app-name": s.AppName, "msg-id": s.MsgID} {
+		if strings.ContainsAny(v, " \t\r\n") {
+			return fmt.Errorf("invalid syslog %s %q: must not contain spaces", name, v)
+		}
+	}
+	return nil
+}
+
+// message builds an RFC 5424 syslog message with b as message body.
+func (s *SyslogConfig) message(ts time.Time, b []byte) []byte {
+	m := outputs.AppendSyslogHeader(make([]byte, 0, 128+len(b)), &outputs.SyslogHeader{
+		Facility:  s.Facility,
+		Severity:  s.Severity,
+		Timestamp: ts,
+		Hostname:  s.Hostname,
+		AppName:   s.AppName,
+		ProcID:    strconv.Itoa(os.Getpid()),
+		MsgID:     s.MsgID,
+	})
+	// no structured data
+	m = append(m, "- "...)
+	return append(m, b...)
+}
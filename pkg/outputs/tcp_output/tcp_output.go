@@ -10,6 +10,7 @@ package tcp_output
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -30,9 +31,11 @@ import (
 )
 
 const (
-	defaultRetryTimer = 2 * time.Second
-	defaultNumWorkers = 1
-	loggingPrefix     = "[tcp_output:%s] "
+	defaultRetryTimer  = 2 * time.Second
+	defaultNumWorkers  = 1
+	defaultBufferSize  = 1000
+	defaultDialTimeout = 10 * time.Second
+	loggingPrefix      = "[tcp_output:%s] "
 )
 
 func init() {
@@ -55,24 +58,29 @@ type tcpOutput struct {
 	evps     []formatters.EventProcessor
 
 	targetTpl *template.Template
-	delimiter []byte
+	frame     framer
+	tlsConfig *tls.Config
 }
 
 type config struct {
-	Address            string        `mapstructure:"address,omitempty"` // ip:port
-	Rate               time.Duration `mapstructure:"rate,omitempty"`
-	BufferSize         uint          `mapstructure:"buffer-size,omitempty"`
-	Format             string        `mapstructure:"format,omitempty"`
-	AddTarget          string        `mapstructure:"add-target,omitempty"`
-	TargetTemplate     string        `mapstructure:"target-template,omitempty"`
-	OverrideTimestamps bool          `mapstructure:"override-timestamps,omitempty"`
-	SplitEvents        bool          `mapstructure:"split-events,omitempty"`
-	Delimiter          string        `mapstructure:"delimiter,omitempty"`
-	KeepAlive          time.Duration `mapstructure:"keep-alive,omitempty"`
-	RetryInterval      time.Duration `mapstructure:"retry-interval,omitempty"`
-	NumWorkers         int           `mapstructure:"num-workers,omitempty"`
-	EnableMetrics      bool          `mapstructure:"enable-metrics,omitempty"`
-	EventProcessors    []string      `mapstructure:"event-processors,omitempty"`
+	Address            string           `mapstructure:"address,omitempty"` // ip:port
+	TLS                *types.TLSConfig `mapstructure:"tls,omitempty" json:"tls,omitempty"`
+	Rate               time.Duration    `mapstructure:"rate,omitempty"`
+	BufferSize         uint             `mapstructure:"buffer-size,omitempty"`
+	Format             string           `mapstructure:"format,omitempty"`
+	AddTarget          string           `mapstructure:"add-target,omitempty"`
+	TargetTemplate     string           `mapstructure:"target-template,omitempty"`
+	OverrideTimestamps bool             `mapstructure:"override-timestamps,omitempty"`
+	SplitEvents        bool             `mapstructure:"split-events,omitempty"`
+	Framing            string           `mapstructure:"framing,omitempty"`
+	Syslog             *SyslogConfig    `mapstructure:"syslog,omitempty" json:"syslog,omitempty"`
+	Delimiter          string           `mapstructure:"delimiter,omitempty"`
+	KeepAlive          time.Duration    `mapstructure:"keep-alive,omitempty"`
+	DialTimeout        time.Duration    `mapstructure:"dial-timeout,omitempty"`
+	RetryInterval      time.Duration    `mapstructure:"retry-interval,omitempty"`
+	NumWorkers         int              `mapstructure:"num-workers,omitempty"`
+	EnableMetrics      bool             `mapstructure:"enable-metrics,omitempty"`
+	EventProcessors    []string         `mapstructure:"event-processors,omitempty"`
 }
 
 func (t *tcpOutput) SetLogger(logger *log.Logger) {
@@ -116,6 +124,9 @@ func (t *tcpOutput) Init(ctx context.Context, name string, cfg map[string]interf
 	if err != nil {
 		return fmt.Errorf("wrong address format: %v", err)
 	}
+	if t.cfg.BufferSize == 0 {
+		t.cfg.BufferSize = defaultBufferSize
+	}
 	t.buffer = make(chan []byte, t.cfg.BufferSize)
 	if t.cfg.Rate > 0 {
 		t.limiter = time.NewTicker(t.cfg.Rate)
@@ -126,8 +137,28 @@ func (t *tcpOutput) Init(ctx context.Context, name string, cfg map[string]interf
 	if t.cfg.NumWorkers < 1 {
 		t.cfg.NumWorkers = defaultNumWorkers
 	}
-	if len(t.cfg.Delimiter) > 0 {
-		t.delimiter = []byte(t.cfg.Delimiter)
+	if t.cfg.DialTimeout <= 0 {
+		t.cfg.DialTimeout = defaultDialTimeout
+	}
+	if t.cfg.Delimiter != "" && t.cfg.Framing != "" {
+		return fmt.Errorf("delimiter and framing are mutually exclusive")
+	}
+	t.frame, err = t.newFramer()
+	if err != nil {
+		return err
+	}
+	if t.cfg.TLS != nil {
+		t.tlsConfig, err = utils.NewTLSConfig(
+			t.cfg.TLS.CaFile,
+			t.cfg.TLS.CertFile,
+			t.cfg.TLS.KeyFile,
+			"",
+			t.cfg.TLS.SkipVerify,
+			false,
+		)
+		if err != nil {
+			return err
+		}
 	}
 	t.mo = &formatters.MarshalOptions{
 		Format:     t.cfg.Format,
@@ -173,12 +204,21 @@ func (t *tcpOutput) Write(ctx context.Context, m proto.Message, meta outputs.Met
 			return
 		}
 		for _, b := range bb {
-			t.buffer <- b
+			select {
+			case <-ctx.Done():
+				return
+			case t.buffer <- b:
+			}
 		}
 	}
 }
 
-func (t *tcpOutput) WriteEvent(ctx context.Context, ev *formatters.EventMsg) {}
+func (t *tcpOutput) WriteEvent(ctx context.Context, ev *formatters.EventMsg) {
+	err := outputs.WriteEventAsResponse(ctx, t, ev)
+	if err != nil {
+		t.logger.Printf("%v", err)
+	}
+}
 
 func (t *tcpOutput) Close() error {
 	t.cancelFn()
@@ -197,43 +237,70 @@ func (t *tcpOutput) String() string {
 	return string(b)
 }
 
+// start sends the buffered messages, reconnecting after failures.
+// Messages are buffered while the connection is down,
+// a message that failed to be sent is sent again after reconnecting.
 func (t *tcpOutput) start(ctx context.Context, idx int) {
 	workerLogPrefix := fmt.Sprintf("worker-%d", idx)
-START:
-	tcpAddr, err := net.ResolveTCPAddr("tcp", t.cfg.Address)
-	if err != nil {
-		t.logger.Printf("%s failed to resolve address: %v", workerLogPrefix, err)
-		time.Sleep(t.cfg.RetryInterval)
-		goto START
+	var pending []byte
+	for {
+		conn, err := t.dial(ctx)
+		if err == nil {
+			pending, err = t.send(ctx, conn, pending)
+			conn.Close()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		t.logger.Printf("%s connection to %s failed: %v, retrying in %s", workerLogPrefix, t.cfg.Address, err, t.cfg.RetryInterval)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(t.cfg.RetryInterval):
+		}
 	}
-	conn, err := net.DialTCP("tcp", nil, tcpAddr)
-	if err != nil {
-		t.logger.Printf("%s failed to dial TCP: %v", workerLogPrefix, err)
-		time.Sleep(t.cfg.RetryInterval)
-		goto START
+}
+
+func (t *tcpOutput) dial(ctx context.Context) (net.Conn, error) {
+	d := &net.Dialer{
+		Timeout: t.cfg.DialTimeout,
+		// keepalive is disabled unless configured
+		KeepAlive: -1,
 	}
-	defer conn.Close()
 	if t.cfg.KeepAlive > 0 {
-		conn.SetKeepAlive(true)
-		conn.SetKeepAlivePeriod(t.cfg.KeepAlive)
+		d.KeepAlive = t.cfg.KeepAlive
+	}
+	if t.tlsConfig == nil {
+		return d.DialContext(ctx, "tcp", t.cfg.Address)
+	}
+	td := &tls.Dialer{
+		NetDialer: d,
+		Config:    t.tlsConfig,
+	}
+	return td.DialContext(ctx, "tcp", t.cfg.Address)
+}
+
+// send writes the pending message if any, then the buffered messages,
+// until a write fails. It returns the framed message that failed to be written.
+func (t *tcpOutput) send(ctx context.Context, conn net.Conn, pending []byte) ([]byte, error) {
+	if pending != nil {
+		_, err := conn.Write(pending)
+		if err != nil {
+			return pending, err
+		}
 	}
-	defer t.Close()
 	for {
 		select {
 		case <-ctx.Done():
-			return
+			return nil, ctx.Err()
 		case b := <-t.buffer:
 			if t.limiter != nil {
 				<-t.limiter.C
 			}
-			// append delimiter
-			b = append(b, t.delimiter...)
-			_, err = conn.Write(b)
+			b = t.frame(b)
+			_, err := conn.Write(b)
 			if err != nil {
-				t.logger.Printf("%s failed sending tcp bytes: %v", workerLogPrefix, err)
-				conn.Close()
-				time.Sleep(t.cfg.RetryInterval)
-				goto START
+				return b, err
 			}
 		}
 	}
@@ -0,0 +1,155 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tcp_output
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+
+	"github.com/openconfig/gnmic/pkg/outputs"
+)
+
+func TestFraming(t *testing.T) {
+	msg := []byte(`{"name":"sub1"}`)
+	tests := []struct {
+		name string
+		cfg  *config
+		want []byte
+	}{
+		{
+			name: "none",
+			cfg:  &config{},
+			want: msg,
+		},
+		{
+			name: "delimiter",
+			cfg:  &config{Delimiter: "\r\n"},
+			want: append(append([]byte{}, msg...), '\r', '\n'),
+		},
+		{
+			name: "newline",
+			cfg:  &config{Framing: "newline"},
+			want: append(append([]byte{}, msg...), '\n'),
+		},
+		{
+			name: "length-prefix",
+			cfg:  &config{Framing: "length-prefix"},
+			want: append([]byte{0, 0, 0, byte(len(msg))}, msg...),
+		},
+		{
+			name: "octet-counting",
+			cfg:  &config{Framing: "octet-counting"},
+			want: append([]byte(strconv.Itoa(len(msg))+" "), msg...),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &tcpOutput{cfg: tt.cfg}
+			f, err := o.newFramer()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := f(append([]byte{}, msg...))
+			if string(got) != string(tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyslogMessage(t *testing.T) {
+	s := &SyslogConfig{Facility: 16, Hostname: "gnmic1", MsgID: "telemetry"}
+	if err := s.setDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	ts := time.Date(2024, 3, 1, 10, 20, 30, 123456789, time.UTC)
+	got := string(s.message(ts, []byte("msg")))
+	want := "<134>1 2024-03-01T10:20:30.123456Z gnmic1 gnmic " + strconv.Itoa(os.Getpid()) + " telemetry - msg"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	s = &SyslogConfig{AppName: "gnmic collector"}
+	if err := s.setDefaults(); err == nil {
+		t.Errorf("expected an error for an app-name with spaces")
+	}
+}
+
+// TestReconnect checks that the messages written while the server is down
+// are sent once it is reachable again.
+func TestReconnect(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	o := outputs.Outputs["tcp"]()
+	err = o.Init(ctx, "test", map[string]interface{}{
+		"address":        addr,
+		"format":         "json",
+		"framing":        "length-prefix",
+		"retry-interval": "50ms",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer o.Close()
+	rsp := &gnmi.SubscribeResponse{
+		Response: &gnmi.SubscribeResponse_Update{
+			Update: &gnmi.Notification{
+				Timestamp: 42,
+				Update: []*gnmi.Update{{
+					Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "counter"}}},
+					Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_IntVal{IntVal: 1}},
+				}},
+			},
+		},
+	}
+	for i := 0; i < 3; i++ {
+		o.Write(ctx, rsp, outputs.Meta{})
+	}
+
+	l, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	conn, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	r := bufio.NewReader(conn)
+	for i := 0; i < 3; i++ {
+		lb := make([]byte, 4)
+		if _, err := io.ReadFull(r, lb); err != nil {
+			t.Fatalf("message %d: %v", i, err)
+		}
+		b := make([]byte, binary.BigEndian.Uint32(lb))
+		if _, err := io.ReadFull(r, b); err != nil {
+			t.Fatalf("message %d: %v", i, err)
+		}
+		if len(b) == 0 || b[0] != '{' {
+			t.Errorf("message %d: unexpected content %q", i, b)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package outputs
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// TemplateInput converts v to the generic form used as template input,
+// see DecodeTemplateInput.
+func TemplateInput(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeTemplateInput(b)
+}
+
+// DecodeTemplateInput decodes the JSON document b to be used as template input,
+// numbers are decoded as json.Number so that nanosecond timestamps keep their precision.
+func DecodeTemplateInput(b []byte) (interface{}, error) {
+	var in interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	err := dec.Decode(&in)
+	return in, err
+}
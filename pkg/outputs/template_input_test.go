@@ -0,0 +1,28 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package outputs
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestTemplateInput(t *testing.T) {
+	in, err := TemplateInput(map[string]interface{}{"timestamp": int64(1704164645123456789)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts, ok := in.(map[string]interface{})["timestamp"].(json.Number)
+	if !ok || ts.String() != "1704164645123456789" {
+		t.Errorf("unexpected timestamp %#v", in)
+	}
+	if _, err := DecodeTemplateInput([]byte("{")); err == nil {
+		t.Errorf("expected an error")
+	}
+}
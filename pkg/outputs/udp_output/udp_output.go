@@ -166,7 +166,12 @@ func (u *UDPSock) Write(ctx context.Context, m proto.Message, meta outputs.Meta)
 	}
 }
 
-func (u *UDPSock) WriteEvent(ctx context.Context, ev *formatters.EventMsg) {}
+func (u *UDPSock) WriteEvent(ctx context.Context, ev *formatters.EventMsg) {
+	err := outputs.WriteEventAsResponse(ctx, u, ev)
+	if err != nil {
+		u.logger.Printf("%v", err)
+	}
+}
 
 func (u *UDPSock) Close() error {
 	u.cancelFn()
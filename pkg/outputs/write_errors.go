@@ -0,0 +1,363 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package outputs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorClass is the class of an output write error.
+type ErrorClass string
+
+const (
+	// the server could not be reached or the connection was lost
+	ErrorClassNetwork ErrorClass = "network"
+	// the credentials were rejected
+	ErrorClassAuth ErrorClass = "auth"
+	// the server failed to handle the write, it may succeed later
+	ErrorClassTransient ErrorClass = "transient"
+	// the server rejected the written data, it fails again if retried
+	ErrorClassPermanent ErrorClass = "permanent"
+)
+
+// ErrorAction is the action taken on an output write error.
+type ErrorAction string
+
+const (
+	// retry the write with an exponential backoff
+	ErrorActionRetry ErrorAction = "retry"
+	// renew the credentials and retry the write once
+	ErrorActionReauth ErrorAction = "reauth"
+	// drop the written data
+	ErrorActionDrop ErrorAction = "drop"
+	// drop the written data and raise an alarm until the next successful write
+	ErrorActionAlarm ErrorAction = "alarm"
+)
+
+const (
+	defaultErrorMaxRetries     = 3
+	defaultErrorInitialBackoff = 100 * time.Millisecond
+	defaultErrorMaxBackoff     = 5 * time.Second
+	defaultRetryBudget         = 0.2
+	// retries available when the budget is full
+	retryBudgetBurst = 10
+)
+
+// StatusError is a write error carrying the HTTP status code returned by the server.
+type StatusError struct {
+	Code int
+	Body string
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("status code %d", e.Code)
+	}
+	return fmt.Sprintf("status code %d: %s", e.Code, e.Body)
+}
+
+// ClassifiedError is a write error classified by the output itself.
+type ClassifiedError struct {
+	Class ErrorClass
+	Err   error
+}
+
+// NewClassifiedError wraps err, setting its class.
+func NewClassifiedError(class ErrorClass, err error) error {
+	return &ClassifiedError{Class: class, Err: err}
+}
+
+func (e *ClassifiedError) Error() string { return e.Err.Error() }
+
+func (e *ClassifiedError) Unwrap() error { return e.Err }
+
+// ClassifyError returns the class of an output write error.
+// Unknown errors are considered transient.
+func ClassifyError(err error) ErrorClass {
+	var ce *ClassifiedError
+	if errors.As(err, &ce) {
+		return ce.Class
+	}
+	var se *StatusError
+	if errors.As(err, &se) {
+		switch {
+		case se.Code == http.StatusUnauthorized || se.Code == http.StatusForbidden:
+			return ErrorClassAuth
+		case se.Code == http.StatusRequestTimeout || se.Code == http.StatusTooManyRequests || se.Code >= 500:
+			return ErrorClassTransient
+		default:
+			return ErrorClassPermanent
+		}
+	}
+	if st, ok := status.FromError(err); ok {
+		switch st.Code() {
+		case codes.Unauthenticated, codes.PermissionDenied:
+			return ErrorClassAuth
+		case codes.Unavailable:
+			return ErrorClassNetwork
+		case codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.FailedPrecondition,
+			codes.OutOfRange, codes.Unimplemented:
+			return ErrorClassPermanent
+		default:
+			return ErrorClassTransient
+		}
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorClassTransient
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) {
+		return ErrorClassNetwork
+	}
+	return ErrorClassTransient
+}
+
+// ErrorPolicy sets the action taken per class of output write errors.
+type ErrorPolicy struct {
+	Network   ErrorAction `mapstructure:"network,omitempty" json:"network,omitempty"`
+	Auth      ErrorAction `mapstructure:"auth,omitempty" json:"auth,omitempty"`
+	Transient ErrorAction `mapstructure:"transient,omitempty" json:"transient,omitempty"`
+	Permanent ErrorAction `mapstructure:"permanent,omitempty" json:"permanent,omitempty"`
+	// maximum number of retries of a single write
+	MaxRetries     int           `mapstructure:"max-retries,omitempty" json:"max-retries,omitempty"`
+	InitialBackoff time.Duration `mapstructure:"initial-backoff,omitempty" json:"initial-backoff,omitempty"`
+	MaxBackoff     time.Duration `mapstructure:"max-backoff,omitempty" json:"max-backoff,omitempty"`
+	// maximum ratio of retries to writes: each write adds RetryBudget retries
+	// to a budget holding up to 10 retries, each retry uses one.
+	RetryBudget float64 `mapstructure:"retry-budget,omitempty" json:"retry-budget,omitempty"`
+}
+
+func (p *ErrorPolicy) setDefaults() error {
+	for _, a := range []*ErrorAction{&p.Network, &p.Auth, &p.Transient, &p.Permanent} {
+		switch *a {
+		case "", ErrorActionRetry, ErrorActionReauth, ErrorActionDrop, ErrorActionAlarm:
+		default:
+			return fmt.Errorf("error-policy: unknown action %q, expecting one of retry, reauth, drop or alarm", *a)
+		}
+	}
+	if p.Network == "" {
+		p.Network = ErrorActionRetry
+	}
+	if p.Auth == "" {
+		p.Auth = ErrorActionReauth
+	}
+	if p.Transient == "" {
+		p.Transient = ErrorActionRetry
+	}
+	if p.Permanent == "" {
+		p.Permanent = ErrorActionDrop
+	}
+	if p.MaxRetries < 0 || p.InitialBackoff < 0 || p.MaxBackoff < 0 || p.RetryBudget < 0 {
+		return errors.New("error-policy: max-retries, initial-backoff, max-backoff and retry-budget cannot be negative")
+	}
+	if p.MaxRetries == 0 {
+		p.MaxRetries = defaultErrorMaxRetries
+	}
+	if p.InitialBackoff == 0 {
+		p.InitialBackoff = defaultErrorInitialBackoff
+	}
+	if p.MaxBackoff == 0 {
+		p.MaxBackoff = defaultErrorMaxBackoff
+	}
+	if p.MaxBackoff < p.InitialBackoff {
+		p.MaxBackoff = p.InitialBackoff
+	}
+	if p.RetryBudget == 0 {
+		p.RetryBudget = defaultRetryBudget
+	}
+	return nil
+}
+
+func (p *ErrorPolicy) action(class ErrorClass) ErrorAction {
+	switch class {
+	case ErrorClassNetwork:
+		return p.Network
+	case ErrorClassAuth:
+		return p.Auth
+	case ErrorClassPermanent:
+		return p.Permanent
+	default:
+		return p.Transient
+	}
+}
+
+var outputWriteErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "output",
+	Name:      "write_errors_total",
+	Help:      "Total number of output write errors per class and action taken",
+}, []string{"output", "class", "action"})
+var outputRetryBudgetExhausted = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "gnmic",
+	Subsystem: "output",
+	Name:      "retry_budget_exhausted_total",
+	Help:      "Total number of output write retries skipped because the retry budget was exhausted",
+}, []string{"output"})
+var outputErrorAlarm = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "gnmic",
+	Subsystem: "output",
+	Name:      "error_alarm",
+	Help:      "Set to 1 while an output write error alarm is raised, per class",
+}, []string{"output", "class"})
+
+// Collectors returns the outputs write errors metrics.
+func Collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		outputWriteErrors,
+		outputRetryBudgetExhausted,
+		outputErrorAlarm,
+	}
+}
+
+// ErrorHandler applies an ErrorPolicy to the writes of an output.
+type ErrorHandler struct {
+	name   string
+	policy *ErrorPolicy
+	logger *log.Logger
+
+	m      sync.Mutex
+	budget float64
+	alarms map[ErrorClass]struct{}
+}
+
+// NewErrorHandler validates the policy p and sets its defaults,
+// it returns nil if p is nil.
+func NewErrorHandler(name string, p *ErrorPolicy, logger *log.Logger) (*ErrorHandler, error) {
+	if p == nil {
+		return nil, nil
+	}
+	err := p.setDefaults()
+	if err != nil {
+		return nil, err
+	}
+	return &ErrorHandler{
+		name:   name,
+		policy: p,
+		logger: logger,
+		budget: retryBudgetBurst,
+		alarms: make(map[ErrorClass]struct{}),
+	}, nil
+}
+
+// Do calls write until it succeeds or the action of its error is not a retry,
+// and returns the last error.
+// reauth renews the output credentials, auth errors raise an alarm if it is nil
+// or if the write fails again after it.
+func (h *ErrorHandler) Do(ctx context.Context, write func(context.Context) error, reauth func()) error {
+	h.deposit()
+	backoff := h.policy.InitialBackoff
+	var reauthenticated bool
+	for attempt := 0; ; attempt++ {
+		err := write(ctx)
+		if err == nil {
+			h.clearAlarms()
+			return nil
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		class := ClassifyError(err)
+		action := h.policy.action(class)
+		switch action {
+		case ErrorActionReauth:
+			if reauth == nil || reauthenticated {
+				action = ErrorActionAlarm
+				break
+			}
+			outputWriteErrors.WithLabelValues(h.name, string(class), string(action)).Inc()
+			reauth()
+			reauthenticated = true
+			continue
+		case ErrorActionRetry:
+			if attempt >= h.policy.MaxRetries {
+				action = ErrorActionDrop
+				break
+			}
+			if !h.withdraw() {
+				outputRetryBudgetExhausted.WithLabelValues(h.name).Inc()
+				action = ErrorActionDrop
+				break
+			}
+			outputWriteErrors.WithLabelValues(h.name, string(class), string(action)).Inc()
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+			if backoff > h.policy.MaxBackoff {
+				backoff = h.policy.MaxBackoff
+			}
+			continue
+		}
+		outputWriteErrors.WithLabelValues(h.name, string(class), string(action)).Inc()
+		if action == ErrorActionAlarm {
+			h.raiseAlarm(class, err)
+		}
+		return err
+	}
+}
+
+// deposit adds the retries earned by a write to the budget.
+func (h *ErrorHandler) deposit() {
+	h.m.Lock()
+	defer h.m.Unlock()
+	h.budget += h.policy.RetryBudget
+	if h.budget > retryBudgetBurst {
+		h.budget = retryBudgetBurst
+	}
+}
+
+// withdraw takes a retry from the budget, it returns false if the budget is exhausted.
+func (h *ErrorHandler) withdraw() bool {
+	h.m.Lock()
+	defer h.m.Unlock()
+	if h.budget < 1 {
+		return false
+	}
+	h.budget--
+	return true
+}
+
+func (h *ErrorHandler) raiseAlarm(class ErrorClass, err error) {
+	h.m.Lock()
+	h.alarms[class] = struct{}{}
+	h.m.Unlock()
+	outputErrorAlarm.WithLabelValues(h.name, string(class)).Set(1)
+	if h.logger != nil {
+		h.logger.Printf("ALARM: %s write error, data dropped: %v", class, err)
+	}
+}
+
+func (h *ErrorHandler) clearAlarms() {
+	h.m.Lock()
+	defer h.m.Unlock()
+	for class := range h.alarms {
+		outputErrorAlarm.WithLabelValues(h.name, string(class)).Set(0)
+		delete(h.alarms, class)
+	}
+}
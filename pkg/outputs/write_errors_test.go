@@ -0,0 +1,179 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package outputs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want ErrorClass
+	}{
+		{&StatusError{Code: 401}, ErrorClassAuth},
+		{&StatusError{Code: 403}, ErrorClassAuth},
+		{&StatusError{Code: 429}, ErrorClassTransient},
+		{&StatusError{Code: 503}, ErrorClassTransient},
+		{&StatusError{Code: 400}, ErrorClassPermanent},
+		{fmt.Errorf("write: %w", &StatusError{Code: 404}), ErrorClassPermanent},
+		{status.Error(codes.Unauthenticated, "bad token"), ErrorClassAuth},
+		{status.Error(codes.Unavailable, "down"), ErrorClassNetwork},
+		{status.Error(codes.InvalidArgument, "bad data"), ErrorClassPermanent},
+		{status.Error(codes.ResourceExhausted, "slow down"), ErrorClassTransient},
+		{context.DeadlineExceeded, ErrorClassTransient},
+		{&net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, ErrorClassNetwork},
+		{fmt.Errorf("write: %w", syscall.EPIPE), ErrorClassNetwork},
+		{io.EOF, ErrorClassNetwork},
+		{NewClassifiedError(ErrorClassAuth, errors.New("nats: authorization violation")), ErrorClassAuth},
+		{errors.New("unknown"), ErrorClassTransient},
+	}
+	for _, tt := range tests {
+		if got := ClassifyError(tt.err); got != tt.want {
+			t.Errorf("ClassifyError(%v): got %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestErrorPolicyDefaults(t *testing.T) {
+	h, err := NewErrorHandler("o", nil, nil)
+	if h != nil || err != nil {
+		t.Fatalf("nil policy: got %v, %v", h, err)
+	}
+	_, err = NewErrorHandler("o", &ErrorPolicy{Network: "ignore"}, nil)
+	if err == nil {
+		t.Fatal("expected an unknown action error")
+	}
+	p := &ErrorPolicy{}
+	_, err = NewErrorHandler("o", p, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Network != ErrorActionRetry || p.Transient != ErrorActionRetry ||
+		p.Auth != ErrorActionReauth || p.Permanent != ErrorActionDrop {
+		t.Errorf("unexpected default actions: %+v", p)
+	}
+}
+
+func TestErrorHandlerDo(t *testing.T) {
+	ctx := context.Background()
+	newHandler := func(name string, p *ErrorPolicy) *ErrorHandler {
+		p.InitialBackoff = time.Millisecond
+		h, err := NewErrorHandler(name, p, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+	failing := func(errs ...error) (func(context.Context) error, *int) {
+		calls := new(int)
+		return func(context.Context) error {
+			*calls++
+			if *calls <= len(errs) {
+				return errs[*calls-1]
+			}
+			return nil
+		}, calls
+	}
+
+	t.Run("retry", func(t *testing.T) {
+		h := newHandler("retry", &ErrorPolicy{MaxRetries: 2})
+		write, calls := failing(syscall.ECONNRESET, &StatusError{Code: 503})
+		if err := h.Do(ctx, write, nil); err != nil {
+			t.Fatal(err)
+		}
+		if *calls != 3 {
+			t.Errorf("got %d calls, want 3", *calls)
+		}
+		if n := testutil.ToFloat64(outputWriteErrors.WithLabelValues("retry", "network", "retry")); n != 1 {
+			t.Errorf("got %v network retries, want 1", n)
+		}
+	})
+	t.Run("max_retries", func(t *testing.T) {
+		h := newHandler("max_retries", &ErrorPolicy{MaxRetries: 1})
+		write, calls := failing(io.EOF, io.EOF, io.EOF)
+		if err := h.Do(ctx, write, nil); err == nil {
+			t.Fatal("expected an error")
+		}
+		if *calls != 2 {
+			t.Errorf("got %d calls, want 2", *calls)
+		}
+		if n := testutil.ToFloat64(outputWriteErrors.WithLabelValues("max_retries", "network", "drop")); n != 1 {
+			t.Errorf("got %v network drops, want 1", n)
+		}
+	})
+	t.Run("permanent", func(t *testing.T) {
+		h := newHandler("permanent", &ErrorPolicy{})
+		write, calls := failing(&StatusError{Code: 400})
+		if err := h.Do(ctx, write, nil); err == nil {
+			t.Fatal("expected an error")
+		}
+		if *calls != 1 {
+			t.Errorf("permanent error retried: got %d calls", *calls)
+		}
+	})
+	t.Run("reauth", func(t *testing.T) {
+		h := newHandler("reauth", &ErrorPolicy{})
+		write, calls := failing(&StatusError{Code: 401})
+		var reauths int
+		if err := h.Do(ctx, write, func() { reauths++ }); err != nil {
+			t.Fatal(err)
+		}
+		if *calls != 2 || reauths != 1 {
+			t.Errorf("got %d calls and %d reauth, want 2 and 1", *calls, reauths)
+		}
+	})
+	t.Run("alarm", func(t *testing.T) {
+		h := newHandler("alarm", &ErrorPolicy{})
+		// credentials rejected again after the reauth
+		write, _ := failing(&StatusError{Code: 401}, &StatusError{Code: 401})
+		if err := h.Do(ctx, write, func() {}); err == nil {
+			t.Fatal("expected an error")
+		}
+		if n := testutil.ToFloat64(outputErrorAlarm.WithLabelValues("alarm", "auth")); n != 1 {
+			t.Fatalf("alarm not raised")
+		}
+		write, _ = failing()
+		if err := h.Do(ctx, write, nil); err != nil {
+			t.Fatal(err)
+		}
+		if n := testutil.ToFloat64(outputErrorAlarm.WithLabelValues("alarm", "auth")); n != 0 {
+			t.Errorf("alarm not cleared after a successful write")
+		}
+	})
+	t.Run("budget", func(t *testing.T) {
+		h := newHandler("budget", &ErrorPolicy{MaxRetries: 100, RetryBudget: 0.1})
+		errs := make([]error, 100)
+		for i := range errs {
+			errs[i] = io.EOF
+		}
+		write, calls := failing(errs...)
+		if err := h.Do(ctx, write, nil); err == nil {
+			t.Fatal("expected the retry budget to be exhausted")
+		}
+		// the full budget and the write deposit allow 10 retries
+		if *calls != retryBudgetBurst+1 {
+			t.Errorf("got %d calls, want %d", *calls, retryBudgetBurst+1)
+		}
+		if n := testutil.ToFloat64(outputRetryBudgetExhausted.WithLabelValues("budget")); n != 1 {
+			t.Errorf("got %v exhausted budget, want 1", n)
+		}
+	})
+}
@@ -0,0 +1,396 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package recorder implements the file format used to record
+// gNMI SubscribeResponses and replay them later.
+//
+// A record file is made of a header, a sequence of records, an index and a footer:
+//
+//	header: magic "GNMICREC" | version (uint16)
+//	record: length (uint32) | timestamp (int64, unix nano) |
+//	        target length (uint16) | target | subscription length (uint16) | subscription |
+//	        SubscribeResponse (protobuf)
+//	index:  magic "GNMICIDX" | number of records (uint64) | number of entries (uint64) |
+//	        entries: timestamp (int64) | offset (int64)
+//	footer: index offset (uint64) | magic "GNMICEND"
+//
+// All integers are big endian.
+// The index holds one entry every IndexInterval records, it is written when the Writer is closed.
+// A file without index (e.g: the recording process was killed) is still readable sequentially.
+package recorder
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// IndexInterval is the number of records between two index entries.
+	IndexInterval = 100
+
+	version uint16 = 1
+
+	headerSize = 10
+	footerSize = 16
+
+	// MaxRecordSize is the maximum length of an encoded record,
+	// it bounds the memory allocated to read a record.
+	MaxRecordSize = 256 << 20
+)
+
+var (
+	headerMagic = []byte("GNMICREC")
+	indexMagic  = []byte("GNMICIDX")
+	footerMagic = []byte("GNMICEND")
+)
+
+var (
+	ErrInvalidFile = errors.New("not a gnmic record file")
+	ErrClosed      = errors.New("record file closed")
+	// ErrRecordTooLarge is returned for a record longer than MaxRecordSize.
+	ErrRecordTooLarge = errors.New("record too large")
+)
+
+// Record is a single recorded SubscribeResponse.
+type Record struct {
+	// time at which the response was received
+	Timestamp time.Time
+	// name of the target the response was received from
+	Target string
+	// name of the subscription the response belongs to
+	Subscription string
+	Response     *gnmi.SubscribeResponse
+}
+
+type indexEntry struct {
+	timestamp int64
+	offset    int64
+}
+
+// Writer writes records to a record file.
+// It is safe for concurrent use.
+type Writer struct {
+	m      *sync.Mutex
+	f      *os.File
+	w      *bufio.Writer
+	offset int64
+	count  uint64
+	index  []indexEntry
+	closed bool
+}
+
+// NewWriter creates (or truncates) the file at path and writes the record file header.
+func NewWriter(path string) (*Writer, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w := &Writer{
+		m:     new(sync.Mutex),
+		f:     f,
+		w:     bufio.NewWriter(f),
+		index: make([]indexEntry, 0),
+	}
+	hdr := make([]byte, headerSize)
+	copy(hdr, headerMagic)
+	binary.BigEndian.PutUint16(hdr[8:], version)
+	n, err := w.w.Write(hdr)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	w.offset = int64(n)
+	return w, nil
+}
+
+// Write appends a record to the file.
+func (w *Writer) Write(r *Record) error {
+	b, err := proto.Marshal(r.Response)
+	if err != nil {
+		return err
+	}
+	if len(r.Target) > 0xffff || len(r.Subscription) > 0xffff {
+		return errors.New("target or subscription name too long")
+	}
+	l := 8 + 2 + len(r.Target) + 2 + len(r.Subscription) + len(b)
+	if l > MaxRecordSize {
+		return fmt.Errorf("%w: %d bytes", ErrRecordTooLarge, l)
+	}
+	buf := make([]byte, 0, 4+l)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(cap(buf)-4))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(r.Timestamp.UnixNano()))
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(r.Target)))
+	buf = append(buf, r.Target...)
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(r.Subscription)))
+	buf = append(buf, r.Subscription...)
+	buf = append(buf, b...)
+
+	w.m.Lock()
+	defer w.m.Unlock()
+	if w.closed {
+		return ErrClosed
+	}
+	if w.count%IndexInterval == 0 {
+		w.index = append(w.index, indexEntry{timestamp: r.Timestamp.UnixNano(), offset: w.offset})
+	}
+	n, err := w.w.Write(buf)
+	w.offset += int64(n)
+	if err != nil {
+		return err
+	}
+	w.count++
+	return nil
+}
+
+// Close writes the index and the footer then closes the file.
+// Calling Close more than once has no effect.
+func (w *Writer) Close() error {
+	w.m.Lock()
+	defer w.m.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	indexOffset := w.offset
+	buf := make([]byte, 0, len(indexMagic)+16+16*len(w.index)+footerSize)
+	buf = append(buf, indexMagic...)
+	buf = binary.BigEndian.AppendUint64(buf, w.count)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(len(w.index)))
+	for _, e := range w.index {
+		buf = binary.BigEndian.AppendUint64(buf, uint64(e.timestamp))
+		buf = binary.BigEndian.AppendUint64(buf, uint64(e.offset))
+	}
+	buf = binary.BigEndian.AppendUint64(buf, uint64(indexOffset))
+	buf = append(buf, footerMagic...)
+	_, err := w.w.Write(buf)
+	if err != nil {
+		w.f.Close()
+		return err
+	}
+	err = w.w.Flush()
+	if err != nil {
+		w.f.Close()
+		return err
+	}
+	return w.f.Close()
+}
+
+// Reader reads records from a record file.
+type Reader struct {
+	f *os.File
+	r *bufio.Reader
+	// current offset
+	offset int64
+	// offset at which the records end,
+	// the file size if the index is missing.
+	end   int64
+	count uint64
+	index []indexEntry
+}
+
+// NewReader opens the record file at path and loads its index if present.
+func NewReader(path string) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &Reader{f: f}
+	err = r.init()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Reader) init() error {
+	fi, err := r.f.Stat()
+	if err != nil {
+		return err
+	}
+	size := fi.Size()
+	hdr := make([]byte, headerSize)
+	if _, err = io.ReadFull(r.f, hdr); err != nil {
+		return ErrInvalidFile
+	}
+	if !bytes.Equal(hdr[:8], headerMagic) {
+		return ErrInvalidFile
+	}
+	if v := binary.BigEndian.Uint16(hdr[8:]); v != version {
+		return fmt.Errorf("unsupported record file version %d", v)
+	}
+	r.end = size
+	if size >= headerSize+footerSize {
+		err = r.readIndex(size)
+		if err != nil {
+			return err
+		}
+	}
+	return r.seek(headerSize)
+}
+
+func (r *Reader) readIndex(size int64) error {
+	footer := make([]byte, footerSize)
+	if _, err := r.f.ReadAt(footer, size-footerSize); err != nil {
+		return err
+	}
+	if !bytes.Equal(footer[8:], footerMagic) {
+		// no index, the file was not properly closed
+		return nil
+	}
+	indexOffset := int64(binary.BigEndian.Uint64(footer[:8]))
+	if indexOffset < headerSize || indexOffset > size-footerSize {
+		return fmt.Errorf("%w: invalid index offset", ErrInvalidFile)
+	}
+	b := make([]byte, size-footerSize-indexOffset)
+	if _, err := r.f.ReadAt(b, indexOffset); err != nil {
+		return err
+	}
+	if len(b) < len(indexMagic)+16 || !bytes.Equal(b[:len(indexMagic)], indexMagic) {
+		return fmt.Errorf("%w: invalid index", ErrInvalidFile)
+	}
+	b = b[len(indexMagic):]
+	r.count = binary.BigEndian.Uint64(b)
+	numEntries := binary.BigEndian.Uint64(b[8:])
+	b = b[16:]
+	if uint64(len(b)) != numEntries*16 {
+		return fmt.Errorf("%w: invalid index length", ErrInvalidFile)
+	}
+	r.index = make([]indexEntry, 0, numEntries)
+	for i := uint64(0); i < numEntries; i++ {
+		r.index = append(r.index, indexEntry{
+			timestamp: int64(binary.BigEndian.Uint64(b[i*16:])),
+			offset:    int64(binary.BigEndian.Uint64(b[i*16+8:])),
+		})
+	}
+	r.end = indexOffset
+	return nil
+}
+
+func (r *Reader) seek(offset int64) error {
+	_, err := r.f.Seek(offset, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	r.offset = offset
+	r.r = bufio.NewReader(io.LimitReader(r.f, r.end-offset))
+	return nil
+}
+
+// Indexed returns true if the file has an index.
+func (r *Reader) Indexed() bool {
+	return r.index != nil
+}
+
+// Count returns the number of records in the file,
+// it returns 0 if the file has no index.
+func (r *Reader) Count() uint64 {
+	return r.count
+}
+
+// Rewind sets the reader back to the first record.
+func (r *Reader) Rewind() error {
+	return r.seek(headerSize)
+}
+
+// SeekTime positions the reader so that the next call to Next returns
+// a record at or shortly before time t.
+// Without index, the reader is set to the first record.
+func (r *Reader) SeekTime(t time.Time) error {
+	if len(r.index) == 0 {
+		return r.Rewind()
+	}
+	ts := t.UnixNano()
+	// first entry with a timestamp after t
+	i := sort.Search(len(r.index), func(i int) bool {
+		return r.index[i].timestamp > ts
+	})
+	if i == 0 {
+		return r.Rewind()
+	}
+	return r.seek(r.index[i-1].offset)
+}
+
+// Next returns the next record, it returns io.EOF when all records were read.
+// A truncated last record, as written by an interrupted recording, is treated as the end of the file.
+func (r *Reader) Next() (*Record, error) {
+	lb := make([]byte, 4)
+	_, err := io.ReadFull(r.r, lb)
+	if err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+	l := binary.BigEndian.Uint32(lb)
+	if l > MaxRecordSize {
+		return nil, fmt.Errorf("%w: %d bytes at offset %d", ErrRecordTooLarge, l, r.offset)
+	}
+	if int64(l) > r.end-r.offset-4 {
+		if r.index != nil {
+			return nil, fmt.Errorf("%w: record at offset %d overlaps the index", ErrInvalidFile, r.offset)
+		}
+		// truncated last record
+		return nil, io.EOF
+	}
+	b := make([]byte, l)
+	_, err = io.ReadFull(r.r, b)
+	if err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+	r.offset += int64(4 + l)
+	return decodeRecord(b)
+}
+
+// Close closes the underlying file.
+func (r *Reader) Close() error {
+	return r.f.Close()
+}
+
+func decodeRecord(b []byte) (*Record, error) {
+	if len(b) < 12 {
+		return nil, fmt.Errorf("%w: record too short", ErrInvalidFile)
+	}
+	rec := &Record{
+		Timestamp: time.Unix(0, int64(binary.BigEndian.Uint64(b))),
+	}
+	b = b[8:]
+	tl := int(binary.BigEndian.Uint16(b))
+	b = b[2:]
+	if len(b) < tl+2 {
+		return nil, fmt.Errorf("%w: invalid target length", ErrInvalidFile)
+	}
+	rec.Target = string(b[:tl])
+	b = b[tl:]
+	sl := int(binary.BigEndian.Uint16(b))
+	b = b[2:]
+	if len(b) < sl {
+		return nil, fmt.Errorf("%w: invalid subscription length", ErrInvalidFile)
+	}
+	rec.Subscription = string(b[:sl])
+	rec.Response = new(gnmi.SubscribeResponse)
+	err := proto.Unmarshal(b[sl:], rec.Response)
+	if err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
@@ -0,0 +1,202 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package recorder
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openconfig/gnmi/proto/gnmi"
+	"google.golang.org/protobuf/proto"
+)
+
+func testRecord(i int, t0 time.Time) *Record {
+	return &Record{
+		Timestamp:    t0.Add(time.Duration(i) * time.Second),
+		Target:       "router1",
+		Subscription: "sub1",
+		Response: &gnmi.SubscribeResponse{
+			Response: &gnmi.SubscribeResponse_Update{
+				Update: &gnmi.Notification{
+					Timestamp: int64(i),
+					Update: []*gnmi.Update{
+						{
+							Path: &gnmi.Path{Elem: []*gnmi.PathElem{{Name: "counter"}}},
+							Val:  &gnmi.TypedValue{Value: &gnmi.TypedValue_IntVal{IntVal: int64(i)}},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func writeRecords(t *testing.T, path string, n int, t0 time.Time) {
+	w, err := NewWriter(path)
+	if err != nil {
+		t.Fatalf("failed to create writer: %v", err)
+	}
+	for i := 0; i < n; i++ {
+		if err = w.Write(testRecord(i, t0)); err != nil {
+			t.Fatalf("failed to write record %d: %v", i, err)
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatalf("failed to close writer: %v", err)
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rec.gnmic")
+	t0 := time.Unix(1700000000, 0)
+	n := 2*IndexInterval + 5
+	writeRecords(t, path, n, t0)
+
+	r, err := NewReader(path)
+	if err != nil {
+		t.Fatalf("failed to create reader: %v", err)
+	}
+	defer r.Close()
+	if !r.Indexed() {
+		t.Fatal("expected an indexed file")
+	}
+	if r.Count() != uint64(n) {
+		t.Fatalf("expected %d records, got %d", n, r.Count())
+	}
+	for i := 0; i < n; i++ {
+		rec, err := r.Next()
+		if err != nil {
+			t.Fatalf("failed to read record %d: %v", i, err)
+		}
+		exp := testRecord(i, t0)
+		if !rec.Timestamp.Equal(exp.Timestamp) || rec.Target != exp.Target || rec.Subscription != exp.Subscription {
+			t.Fatalf("record %d mismatch: got %+v", i, rec)
+		}
+		if !proto.Equal(rec.Response, exp.Response) {
+			t.Fatalf("record %d response mismatch: got %v", i, rec.Response)
+		}
+	}
+	if _, err = r.Next(); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+}
+
+func TestSeekTime(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rec.gnmic")
+	t0 := time.Unix(1700000000, 0)
+	writeRecords(t, path, 3*IndexInterval, t0)
+
+	r, err := NewReader(path)
+	if err != nil {
+		t.Fatalf("failed to create reader: %v", err)
+	}
+	defer r.Close()
+	target := IndexInterval + 50
+	err = r.SeekTime(t0.Add(time.Duration(target) * time.Second))
+	if err != nil {
+		t.Fatalf("seek failed: %v", err)
+	}
+	rec, err := r.Next()
+	if err != nil {
+		t.Fatalf("failed to read record: %v", err)
+	}
+	// the reader is positioned at the closest index entry before the requested time
+	exp := testRecord(IndexInterval, t0)
+	if !rec.Timestamp.Equal(exp.Timestamp) {
+		t.Fatalf("expected record at %v, got %v", exp.Timestamp, rec.Timestamp)
+	}
+}
+
+func TestReadTruncated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rec.gnmic")
+	t0 := time.Unix(1700000000, 0)
+	writeRecords(t, path, 10, t0)
+
+	// drop the index, the footer and part of the last record
+	r, err := NewReader(path)
+	if err != nil {
+		t.Fatalf("failed to create reader: %v", err)
+	}
+	end := r.end
+	r.Close()
+	if err = os.Truncate(path, end-3); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err = NewReader(path)
+	if err != nil {
+		t.Fatalf("failed to create reader: %v", err)
+	}
+	defer r.Close()
+	if r.Indexed() {
+		t.Fatal("expected a file without index")
+	}
+	numRead := 0
+	for {
+		_, err = r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		numRead++
+	}
+	if numRead != 9 {
+		t.Fatalf("expected 9 records, got %d", numRead)
+	}
+}
+
+func TestInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rec.gnmic")
+	if err := os.WriteFile(path, []byte("not a record file"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := NewReader(path)
+	if !errors.Is(err, ErrInvalidFile) {
+		t.Fatalf("expected ErrInvalidFile, got %v", err)
+	}
+}
+
+func TestReadRecordLength(t *testing.T) {
+	hdr := append(append([]byte{}, headerMagic...), 0, byte(version))
+	tests := []struct {
+		name    string
+		length  uint32
+		wantErr error
+	}{
+		{name: "too_large", length: MaxRecordSize + 1, wantErr: ErrRecordTooLarge},
+		{name: "max_uint32", length: 0xffffffff, wantErr: ErrRecordTooLarge},
+		{name: "past_end_of_file", length: 1 << 20, wantErr: io.EOF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "rec.gnmic")
+			b := binary.BigEndian.AppendUint32(append([]byte{}, hdr...), tt.length)
+			b = append(b, make([]byte, 32)...)
+			if err := os.WriteFile(path, b, 0644); err != nil {
+				t.Fatal(err)
+			}
+			r, err := NewReader(path)
+			if err != nil {
+				t.Fatalf("failed to create reader: %v", err)
+			}
+			defer r.Close()
+			_, err = r.Next()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
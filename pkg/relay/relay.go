@@ -0,0 +1,59 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package relay implements the gNMIc-to-gNMIc relay protocol defined in relay.proto.
+// The messages and the gRPC service are generated with protoc-gen-go and protoc-gen-go-grpc,
+// gnmi.proto is expected under $GOPATH/src/github.com/openconfig/gnmi/proto/gnmi.
+package relay
+
+//go:generate protoc -I . -I $GOPATH/src --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative relay.proto
+
+import (
+	"encoding/json"
+
+	"github.com/openconfig/gnmic/pkg/formatters"
+)
+
+// SenderMetadataKey is the gRPC metadata key carrying the unique ID of a sender,
+// it is used by the receiver to discard the batches replayed after a reconnection.
+const SenderMetadataKey = "x-gnmic-relay-sender"
+
+// NewEvent converts an EventMsg to a relay Event.
+func NewEvent(ev *formatters.EventMsg) (*Event, error) {
+	e := &Event{
+		Name:      ev.Name,
+		Timestamp: ev.Timestamp,
+		Tags:      ev.Tags,
+		Deletes:   ev.Deletes,
+	}
+	if len(ev.Values) > 0 {
+		var err error
+		e.Values, err = json.Marshal(ev.Values)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// EventMsg converts a relay Event back to an EventMsg.
+func (m *Event) EventMsg() (*formatters.EventMsg, error) {
+	ev := &formatters.EventMsg{
+		Name:      m.Name,
+		Timestamp: m.Timestamp,
+		Tags:      m.Tags,
+		Deletes:   m.Deletes,
+	}
+	if len(m.Values) > 0 {
+		err := json.Unmarshal(m.Values, &ev.Values)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ev, nil
+}
@@ -0,0 +1,286 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package silences holds the silences suppressing the events
+// of targets or paths during maintenance windows.
+package silences
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// PathMatcherName is the name of the matchers applying to the event value names
+// instead of a tag.
+const PathMatcherName = "__path__"
+
+// Matcher matches a tag value, or a value name if its name is PathMatcherName.
+type Matcher struct {
+	Name    string `mapstructure:"name,omitempty" yaml:"name,omitempty" json:"name,omitempty"`
+	Value   string `mapstructure:"value,omitempty" yaml:"value,omitempty" json:"value,omitempty"`
+	IsRegex bool   `mapstructure:"is-regex,omitempty" yaml:"is-regex,omitempty" json:"is-regex,omitempty"`
+	// negates the matcher if false, defaults to true.
+	IsEqual *bool `mapstructure:"is-equal,omitempty" yaml:"is-equal,omitempty" json:"is-equal,omitempty"`
+
+	re *regexp.Regexp
+}
+
+// Silence suppresses the events matching all its matchers between StartsAt and EndsAt.
+type Silence struct {
+	ID       string     `mapstructure:"id,omitempty" yaml:"id,omitempty" json:"id,omitempty"`
+	Matchers []*Matcher `mapstructure:"matchers,omitempty" yaml:"matchers,omitempty" json:"matchers,omitempty"`
+	// the silence is active from its creation if not set.
+	StartsAt  time.Time `mapstructure:"starts-at,omitempty" yaml:"starts-at,omitempty" json:"starts-at,omitempty"`
+	EndsAt    time.Time `mapstructure:"ends-at,omitempty" yaml:"ends-at,omitempty" json:"ends-at,omitempty"`
+	CreatedBy string    `mapstructure:"created-by,omitempty" yaml:"created-by,omitempty" json:"created-by,omitempty"`
+	Comment   string    `mapstructure:"comment,omitempty" yaml:"comment,omitempty" json:"comment,omitempty"`
+}
+
+// Validate checks the silence and compiles its regex matchers.
+func (s *Silence) Validate() error {
+	if len(s.Matchers) == 0 {
+		return errors.New("silence has no matchers")
+	}
+	for _, m := range s.Matchers {
+		if m == nil || m.Name == "" {
+			return errors.New("silence matcher has no name")
+		}
+		err := m.compile()
+		if err != nil {
+			return fmt.Errorf("silence matcher %q: %v", m.Name, err)
+		}
+	}
+	if s.EndsAt.IsZero() {
+		return errors.New("silence has no end time")
+	}
+	if !s.StartsAt.IsZero() && !s.EndsAt.After(s.StartsAt) {
+		return errors.New("silence ends before it starts")
+	}
+	return nil
+}
+
+// Active returns true if the silence applies at t.
+func (s *Silence) Active(t time.Time) bool {
+	return !t.Before(s.StartsAt) && t.Before(s.EndsAt)
+}
+
+// Expired returns true if the silence ended before t.
+func (s *Silence) Expired(t time.Time) bool {
+	return !t.Before(s.EndsAt)
+}
+
+// Matches returns true if all the silence matchers match the event tags and values.
+func (s *Silence) Matches(tags map[string]string, values map[string]interface{}) bool {
+	for _, m := range s.Matchers {
+		if !m.matches(tags, values) {
+			return false
+		}
+	}
+	return true
+}
+
+// compile compiles the matcher regular expression, anchored at both ends.
+func (m *Matcher) compile() error {
+	if !m.IsRegex {
+		return nil
+	}
+	var err error
+	m.re, err = regexp.Compile("^(?:" + m.Value + ")$")
+	return err
+}
+
+// ParseMatcher parses a matcher written as name="value", name!="value",
+// name=~"regex" or name!~"regex", the value quotes are optional.
+func ParseMatcher(s string) (*Matcher, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexAny(s, "=!")
+	if i <= 0 {
+		return nil, fmt.Errorf("invalid matcher %q", s)
+	}
+	m := &Matcher{Name: strings.TrimSpace(s[:i])}
+	op := s[i:]
+	equal := true
+	switch {
+	case strings.HasPrefix(op, "=~"):
+		m.IsRegex = true
+		op = op[2:]
+	case strings.HasPrefix(op, "!~"):
+		m.IsRegex = true
+		equal = false
+		op = op[2:]
+	case strings.HasPrefix(op, "!="):
+		equal = false
+		op = op[2:]
+	case strings.HasPrefix(op, "="):
+		op = op[1:]
+	default:
+		return nil, fmt.Errorf("invalid matcher %q", s)
+	}
+	m.IsEqual = &equal
+	m.Value = strings.TrimSpace(op)
+	if len(m.Value) >= 2 && strings.HasPrefix(m.Value, `"`) && strings.HasSuffix(m.Value, `"`) {
+		v, err := strconv.Unquote(m.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid matcher %q: %v", s, err)
+		}
+		m.Value = v
+	}
+	err := m.compile()
+	if err != nil {
+		return nil, fmt.Errorf("invalid matcher %q: %v", s, err)
+	}
+	return m, nil
+}
+
+func (m *Matcher) matches(tags map[string]string, values map[string]interface{}) bool {
+	equal := m.IsEqual == nil || *m.IsEqual
+	if m.Name != PathMatcherName {
+		return m.matchString(tags[m.Name]) == equal
+	}
+	for k := range values {
+		if m.matchString(k) {
+			return equal
+		}
+	}
+	return !equal
+}
+
+func (m *Matcher) matchString(s string) bool {
+	if m.re != nil {
+		return m.re.MatchString(s)
+	}
+	return s == m.Value
+}
+
+// Store holds a set of silences by ID.
+type Store struct {
+	m        sync.RWMutex
+	silences map[string]*Silence
+	now      func() time.Time
+}
+
+func NewStore() *Store {
+	return &Store{
+		silences: make(map[string]*Silence),
+		now:      time.Now,
+	}
+}
+
+var defaultStore = NewStore()
+
+// Default returns the store of the silences managed through the API.
+func Default() *Store {
+	return defaultStore
+}
+
+// Set validates and adds or replaces the silence s,
+// its start time is set to now if it is not set.
+func (st *Store) Set(s *Silence) error {
+	if s.ID == "" {
+		return errors.New("silence has no ID")
+	}
+	if s.StartsAt.IsZero() {
+		s.StartsAt = st.now()
+	}
+	err := s.Validate()
+	if err != nil {
+		return err
+	}
+	st.m.Lock()
+	defer st.m.Unlock()
+	st.silences[s.ID] = s
+	return nil
+}
+
+// Get returns the silence with the given ID.
+func (st *Store) Get(id string) (*Silence, bool) {
+	st.m.RLock()
+	defer st.m.RUnlock()
+	s, ok := st.silences[id]
+	return s, ok
+}
+
+// Delete removes the silence with the given ID.
+func (st *Store) Delete(id string) {
+	st.m.Lock()
+	defer st.m.Unlock()
+	delete(st.silences, id)
+}
+
+// List returns the silences sorted by start time.
+func (st *Store) List() []*Silence {
+	st.m.RLock()
+	defer st.m.RUnlock()
+	ss := make([]*Silence, 0, len(st.silences))
+	for _, s := range st.silences {
+		ss = append(ss, s)
+	}
+	sort.Slice(ss, func(i, j int) bool {
+		if ss[i].StartsAt.Equal(ss[j].StartsAt) {
+			return ss[i].ID < ss[j].ID
+		}
+		return ss[i].StartsAt.Before(ss[j].StartsAt)
+	})
+	return ss
+}
+
+// Replace replaces all the silences of the store.
+func (st *Store) Replace(ss []*Silence) error {
+	m := make(map[string]*Silence, len(ss))
+	for i, s := range ss {
+		if s.ID == "" {
+			s.ID = fmt.Sprintf("%d", i)
+		}
+		err := s.Validate()
+		if err != nil {
+			return fmt.Errorf("silence %q: %v", s.ID, err)
+		}
+		m[s.ID] = s
+	}
+	st.m.Lock()
+	defer st.m.Unlock()
+	st.silences = m
+	return nil
+}
+
+// Match returns the first active silence matching the event tags and values,
+// nil if none matches.
+func (st *Store) Match(tags map[string]string, values map[string]interface{}) *Silence {
+	now := st.now()
+	st.m.RLock()
+	defer st.m.RUnlock()
+	for _, s := range st.silences {
+		if s.Active(now) && s.Matches(tags, values) {
+			return s
+		}
+	}
+	return nil
+}
+
+// ReadFile reads a YAML or JSON list of silences.
+func ReadFile(path string) ([]*Silence, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var ss []*Silence
+	err = yaml.Unmarshal(b, &ss)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse silences file %q: %v", path, err)
+	}
+	return ss, nil
+}
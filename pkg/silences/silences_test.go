@@ -0,0 +1,156 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package silences
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSilenceMatches(t *testing.T) {
+	notEqual := false
+	s := &Silence{
+		Matchers: []*Matcher{
+			{Name: "source", Value: "leaf[12]", IsRegex: true},
+			{Name: "interface_name", Value: "mgmt0", IsEqual: &notEqual},
+			{Name: PathMatcherName, Value: "/interface/.*", IsRegex: true},
+		},
+		EndsAt: time.Now().Add(time.Hour),
+	}
+	if err := s.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		tags   map[string]string
+		values map[string]interface{}
+		want   bool
+	}{
+		{map[string]string{"source": "leaf1", "interface_name": "ethernet-1/1"}, map[string]interface{}{"/interface/oper-state": "up"}, true},
+		// regex matchers are anchored
+		{map[string]string{"source": "leaf11", "interface_name": "ethernet-1/1"}, map[string]interface{}{"/interface/oper-state": "up"}, false},
+		{map[string]string{"source": "leaf1", "interface_name": "mgmt0"}, map[string]interface{}{"/interface/oper-state": "up"}, false},
+		{map[string]string{"source": "leaf1"}, map[string]interface{}{"/system/name": "leaf1"}, false},
+	}
+	for i, tt := range tests {
+		if got := s.Matches(tt.tags, tt.values); got != tt.want {
+			t.Errorf("test %d: got %v, want %v", i, got, tt.want)
+		}
+	}
+}
+
+func TestSilenceValidate(t *testing.T) {
+	now := time.Now()
+	for name, s := range map[string]*Silence{
+		"no_matchers": {EndsAt: now},
+		"no_name":     {Matchers: []*Matcher{{Value: "a"}}, EndsAt: now},
+		"bad_regex":   {Matchers: []*Matcher{{Name: "a", Value: "(", IsRegex: true}}, EndsAt: now},
+		"no_end":      {Matchers: []*Matcher{{Name: "a", Value: "b"}}},
+		"ends_before": {Matchers: []*Matcher{{Name: "a", Value: "b"}}, StartsAt: now, EndsAt: now.Add(-time.Second)},
+	} {
+		if err := s.Validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestParseMatcher(t *testing.T) {
+	tags := map[string]string{"source": "leaf1"}
+	for in, want := range map[string]bool{
+		`source="leaf1"`:    true,
+		`source = leaf1`:    true,
+		`source!="leaf1"`:   false,
+		`source=~"leaf.*"`:  true,
+		`source=~"leaf"`:    false,
+		`source!~"spine.*"`: true,
+		`role="spine"`:      false,
+		`role!="spine"`:     true,
+	} {
+		m, err := ParseMatcher(in)
+		if err != nil {
+			t.Errorf("%s: %v", in, err)
+			continue
+		}
+		if got := m.matches(tags, nil); got != want {
+			t.Errorf("%s: got %v, want %v", in, got, want)
+		}
+	}
+	for _, in := range []string{`source`, `="leaf1"`, `source=~"("`, `source<"a"`} {
+		if _, err := ParseMatcher(in); err == nil {
+			t.Errorf("%s: expected an error", in)
+		}
+	}
+}
+
+func TestStore(t *testing.T) {
+	now := time.Unix(1000, 0)
+	st := NewStore()
+	st.now = func() time.Time { return now }
+	err := st.Set(&Silence{
+		ID:       "s1",
+		Matchers: []*Matcher{{Name: "source", Value: "leaf1"}},
+		EndsAt:   now.Add(time.Minute),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = st.Set(&Silence{
+		ID:       "s2",
+		Matchers: []*Matcher{{Name: "source", Value: "leaf2"}},
+		StartsAt: now.Add(time.Minute),
+		EndsAt:   now.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := st.Match(map[string]string{"source": "leaf1"}, nil); s == nil || s.ID != "s1" {
+		t.Errorf("active silence not matched: %v", s)
+	}
+	if s := st.Match(map[string]string{"source": "leaf2"}, nil); s != nil {
+		t.Errorf("pending silence matched: %v", s)
+	}
+	now = now.Add(2 * time.Minute)
+	if s := st.Match(map[string]string{"source": "leaf1"}, nil); s != nil {
+		t.Errorf("expired silence matched: %v", s)
+	}
+	if ss := st.List(); len(ss) != 2 || ss[0].ID != "s1" {
+		t.Errorf("unexpected silences list: %v", ss)
+	}
+	st.Delete("s1")
+	if _, ok := st.Get("s1"); ok {
+		t.Errorf("silence not deleted")
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "silences.yaml")
+	err := os.WriteFile(path, []byte(`
+- id: upgrade
+  comment: leaf1 upgrade
+  matchers:
+    - name: source
+      value: leaf1
+  starts-at: 2024-06-01T22:00:00Z
+  ends-at: 2024-06-02T02:00:00Z
+`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss, err := ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ss) != 1 || ss[0].ID != "upgrade" || len(ss[0].Matchers) != 1 {
+		t.Fatalf("unexpected silences: %+v", ss)
+	}
+	if want := time.Date(2024, 6, 2, 2, 0, 0, 0, time.UTC); !ss[0].EndsAt.Equal(want) {
+		t.Errorf("got end time %v, want %v", ss[0].EndsAt, want)
+	}
+}
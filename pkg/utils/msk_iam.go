@@ -0,0 +1,94 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+)
+
+const (
+	// SASLTypeAWSMSKIAM is the SASL mechanism name used to authenticate
+	// against AWS MSK clusters using IAM credentials.
+	// It is carried over OAUTHBEARER.
+	SASLTypeAWSMSKIAM = "AWS_MSK_IAM"
+
+	mskIAMService       = "kafka-cluster"
+	mskIAMAction        = "kafka-cluster:Connect"
+	mskIAMTokenLifetime = 15 * time.Minute
+	mskIAMUserAgent     = "gnmic"
+)
+
+// MSKIAMTokenProvider implements sarama.AccessTokenProvider,
+// it generates AWS MSK IAM authentication tokens: a SigV4 presigned
+// kafka-cluster:Connect URL, base64url encoded.
+type MSKIAMTokenProvider struct {
+	region string
+	creds  *credentials.Credentials
+	now    func() time.Time
+}
+
+// NewMSKIAMTokenProvider returns a token provider using the default AWS credentials chain
+// (environment variables, shared config files, web identity or instance role).
+// If region is empty, it is derived from the AWS environment.
+func NewMSKIAMTokenProvider(region string) (sarama.AccessTokenProvider, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if region == "" && sess.Config.Region != nil {
+		region = *sess.Config.Region
+	}
+	return newMSKIAMTokenProvider(region, sess.Config.Credentials)
+}
+
+func newMSKIAMTokenProvider(region string, creds *credentials.Credentials) (*MSKIAMTokenProvider, error) {
+	if region == "" {
+		return nil, errors.New("missing AWS region for kafka SASL mechanism AWS_MSK_IAM")
+	}
+	return &MSKIAMTokenProvider{
+		region: region,
+		creds:  creds,
+		now:    time.Now,
+	}, nil
+}
+
+func (p *MSKIAMTokenProvider) Token() (*sarama.AccessToken, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("https://kafka.%s.amazonaws.com/", p.region), nil)
+	if err != nil {
+		return nil, err
+	}
+	q := req.URL.Query()
+	q.Set("Action", mskIAMAction)
+	req.URL.RawQuery = q.Encode()
+
+	signer := v4.NewSigner(p.creds)
+	_, err = signer.Presign(req, nil, mskIAMService, p.region, mskIAMTokenLifetime, p.now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign MSK IAM token: %w", err)
+	}
+	// the user agent is added after signing, it is not part of the signature
+	q = req.URL.Query()
+	q.Set("User-Agent", mskIAMUserAgent)
+	req.URL.RawQuery = q.Encode()
+
+	return &sarama.AccessToken{
+		Token: base64.RawURLEncoding.EncodeToString([]byte(req.URL.String())),
+	}, nil
+}
@@ -0,0 +1,70 @@
+// © 2024 Nokia.
+//
+// This code is a Contribution to the gNMIc project (“Work”) made under the Google Software Grant and Corporate Contributor License Agreement (“CLA”) and governed by the Apache License 2.0.
+// No other rights or licenses in or to any of Nokia’s intellectual property are granted for any other purpose.
+// This code is provided on an “as is” basis without any warranties of any kind.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package utils
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+)
+
+func TestMSKIAMTokenProvider(t *testing.T) {
+	p, err := newMSKIAMTokenProvider("eu-west-1", credentials.NewStaticCredentials("AKID", "SECRET", "SESSION"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.now = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+	tok, err := p.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := base64.RawURLEncoding.DecodeString(tok.Token)
+	if err != nil {
+		t.Fatalf("token is not base64url encoded: %v", err)
+	}
+	u, err := url.Parse(string(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Host != "kafka.eu-west-1.amazonaws.com" {
+		t.Errorf("unexpected host %q", u.Host)
+	}
+	q := u.Query()
+	expected := map[string]string{
+		"Action":               "kafka-cluster:Connect",
+		"X-Amz-Algorithm":      "AWS4-HMAC-SHA256",
+		"X-Amz-Credential":     "AKID/20240102/eu-west-1/kafka-cluster/aws4_request",
+		"X-Amz-Date":           "20240102T030405Z",
+		"X-Amz-Expires":        "900",
+		"X-Amz-Security-Token": "SESSION",
+		"User-Agent":           mskIAMUserAgent,
+	}
+	for k, v := range expected {
+		if q.Get(k) != v {
+			t.Errorf("query param %s: expected %q, got %q", k, v, q.Get(k))
+		}
+	}
+	if len(q.Get("X-Amz-Signature")) != 64 {
+		t.Errorf("missing signature: %q", q.Get("X-Amz-Signature"))
+	}
+	if strings.Contains(q.Get("X-Amz-SignedHeaders"), "user-agent") {
+		t.Errorf("user agent should not be signed")
+	}
+}
+
+func TestMSKIAMTokenProviderMissingRegion(t *testing.T) {
+	_, err := newMSKIAMTokenProvider("", credentials.NewStaticCredentials("AKID", "SECRET", ""))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+}